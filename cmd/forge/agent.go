@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/consistency"
+	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/editlock"
+	"github.com/entrhq/forge/pkg/agent/environment"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/hooks"
+	"github.com/entrhq/forge/pkg/agent/memory/sqlite"
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/agent/notify"
+	"github.com/entrhq/forge/pkg/agent/seed"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// newAgent creates the session's agent with opts, keeping its history in
+// memory when a named session is open
+func newAgent(provider llm.Provider, opts []agent.AgentOption, tracker *git.ModificationTracker, memory *sqlite.Memory) *agent.DefaultAgent {
+	// Keep a named session's history on disk so it can be resumed
+	if memory != nil {
+		opts = append(opts, agent.WithMemory(memory))
+	}
+
+	// Track the files changed this session for session_changes and /commit
+	opts = append(opts, agent.WithModificationTracker(tracker))
+	return agent.NewDefaultAgent(provider, opts...)
+}
+
+// agentOptions returns the agent's options from config along with the
+// workspace's audit log, nil when it couldn't be opened
+func agentOptions(ctx context.Context, config *Config, guard *workspace.Guard, p *providers) ([]agent.AgentOption, *audit.Log, error) {
+	opts, err := loopOptions(ctx, config, guard, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	observerOpts, auditLog, err := observerOptions(config.WorkspaceDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(opts, observerOpts...), auditLog, nil
+}
+
+// loopOptions returns the agent's prompt, context management, loop budget
+// and checks from config
+func loopOptions(ctx context.Context, config *Config, guard *workspace.Guard, p *providers) ([]agent.AgentOption, error) {
+	contextManager, err := newContextManager(p.summary)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the base prompt version pin, override or variant from config
+	promptOpts, promptPinned, err := basePromptOptions(config.PromptVariant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure base prompt: %w", err)
+	}
+
+	// Create agent with custom system prompt and context manager
+	agentOpts := append([]agent.AgentOption{
+		agent.WithCustomInstructions(systemPrompt(config)),
+		agent.WithContextManager(contextManager),
+		agent.WithRateLimiters(p.limiter, p.utilityLimiter),
+		agent.WithMaxTurns(config.MaxIterations),
+		agent.WithMaxToolCalls(config.MaxToolCalls),
+		agent.WithMaxTurnDuration(config.MaxTurnDuration),
+		agent.WithNoToolCallPolicy(agent.NoToolCallPolicy(config.NoToolCall)),
+	}, promptOpts...)
+	if config.ConsistencyCheck {
+		agentOpts = append(agentOpts, agent.WithConsistencyChecker(consistency.NewChecker(guard)))
+	}
+	// A pinned or replaced base prompt is kept as it was tested, unless -environment asks for the block
+	withEnvironment := !promptPinned
+	if config.Environment != nil {
+		withEnvironment = *config.Environment
+	}
+	if withEnvironment {
+		agentOpts = append(agentOpts, agent.WithEnvironment(environment.Detect(ctx, config.WorkspaceDir).Prompt()))
+	}
+	if config.ToolStats {
+		agentOpts = append(agentOpts, agent.WithToolStats(metrics.NewCollector(nil)))
+	}
+	if config.EditLocks {
+		agentOpts = append(agentOpts, agent.WithEditLocks(editlock.NewRegistry(guard).Session("agent")))
+	}
+	return agentOpts, nil
+}
+
+// newContextManager creates the context manager for long coding sessions,
+// summarizing with provider
+func newContextManager(provider llm.Provider) (*agentcontext.Manager, error) {
+	// Strategy 1: Summarize old tool calls to compress historical operations (with buffering)
+	toolCallStrategy := agentcontext.NewToolCallSummarizationStrategy(
+		defaultToolCallAge,
+		defaultMinToolCalls,
+		defaultMaxToolCallDist,
+	)
+
+	// Strategy 2: Summarize when approaching token limit to prevent exhaustion
+	thresholdStrategy := agentcontext.NewThresholdSummarizationStrategy(
+		defaultThresholdPercent,
+		defaultSummaryBatchSize,
+	)
+
+	// Create context manager with both strategies
+	// Event channel will be set by the agent during initialization
+	contextManager, err := agentcontext.NewManager(
+		provider,
+		defaultMaxTokens,
+		toolCallStrategy,
+		thresholdStrategy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create context manager: %w", err)
+	}
+	return contextManager, nil
+}
+
+// observerOptions returns the agent's hooks, notifier and audit log from the
+// (global and project) config, along with the audit log. An audit log that
+// can't be opened is reported and left out.
+func observerOptions(workspaceDir string) ([]agent.AgentOption, *audit.Log, error) {
+	var opts []agent.AgentOption
+	hookRunner, err := newHookRunner(workspaceDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure hooks: %w", err)
+	}
+	if hookRunner != nil {
+		opts = append(opts, agent.WithHooks(hookRunner))
+	}
+	notifier, err := newNotifier(workspaceDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure notifications: %w", err)
+	}
+	if notifier != nil {
+		opts = append(opts, agent.WithNotifier(notifier))
+	}
+	auditLog, err := openAuditLog(workspaceDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: audit log disabled: %v\n", err)
+		return opts, nil, nil
+	}
+	return append(opts, agent.WithAuditLog(auditLog)), auditLog, nil
+}
+
+// newHookRunner creates the runner for the hooks section of the (global and
+// project) config. Returns nil when no hooks are configured.
+func newHookRunner(workspaceDir string) (*hooks.Runner, error) {
+	section := appconfig.GetHooks()
+	if section == nil {
+		return nil, nil
+	}
+	if err := section.Validate(); err != nil {
+		return nil, err
+	}
+
+	var configured []hooks.Hook
+	for _, event := range appconfig.HookEvents {
+		for _, hook := range section.Hooks(event) {
+			configured = append(configured, hooks.Hook{
+				Event:   hooks.Event(event),
+				Command: hook.Command,
+				Tools:   hook.Tools,
+				Timeout: hook.Timeout,
+			})
+		}
+	}
+	if len(configured) == 0 {
+		return nil, nil
+	}
+	return hooks.NewRunner(workspaceDir, configured), nil
+}
+
+// newNotifier creates the task completion notifier from the notifications
+// section of the (global and project) config. Returns nil when no webhook is set.
+func newNotifier(workspaceDir string) (*notify.Notifier, error) {
+	section := appconfig.GetNotifications()
+	if section == nil {
+		return nil, nil
+	}
+	if err := section.Validate(); err != nil {
+		return nil, err
+	}
+
+	webhookURL, err := section.WebhookURL()
+	if err != nil || webhookURL == "" {
+		return nil, err
+	}
+
+	workspace, err := filepath.Abs(workspaceDir)
+	if err != nil {
+		workspace = workspaceDir
+	}
+	return notify.New(webhookURL, notify.WithFormat(section.Format()), notify.WithWorkspace(workspace)), nil
+}
+
+// openAuditLog opens the workspace's audit log, reporting a log that failed
+// verification and was set aside for a new one
+func openAuditLog(workspaceDir string) (*audit.Log, error) {
+	anchorDir, err := audit.DefaultAnchorDir()
+	if err != nil {
+		return nil, err
+	}
+	auditLog, err := audit.Open(workspaceDir, anchorDir)
+	if err != nil {
+		return nil, err
+	}
+	if aside, reason := auditLog.SetAside(); reason != nil {
+		if aside != "" {
+			fmt.Fprintf(os.Stderr, "Warning: the audit log failed verification (%v); it was moved to %s and a new log started\n", reason, aside)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: the audit log failed verification (%v); a new log was started\n", reason)
+		}
+	}
+	return auditLog, nil
+}
+
+// seedSession imports the -seed document into the agent's conversation,
+// fitted into -seed-share of its context window. Returns nil without one.
+func seedSession(ctx context.Context, ag *agent.DefaultAgent, provider llm.Provider, config *Config) (*seed.Document, error) {
+	if config.Seed == "" {
+		return nil, nil
+	}
+	text, err := os.ReadFile(config.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read -seed document: %w", err)
+	}
+	budget := seed.Budget(ag.GetContextInfo().MaxContextTokens, config.SeedShare)
+	doc, err := seed.NewImporter(provider).Prepare(ctx, config.Seed, string(text), budget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import -seed document: %w", err)
+	}
+	ag.ImportContext(doc.Message())
+	return doc, nil
+}
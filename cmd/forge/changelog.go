@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/entrhq/forge/pkg/agent/changelog"
+	"github.com/entrhq/forge/pkg/tools/coding"
+)
+
+// Changelog output formats
+const (
+	changelogFormatDiff     = "diff"
+	changelogFormatMarkdown = "markdown"
+	changelogFormatJSON     = "json"
+)
+
+// changelogFlags are the options of forge changelog
+type changelogFlags struct {
+	workspace string
+	file      string
+	version   string
+	since     string
+	format    string
+	write     bool
+}
+
+// newChangelogFlags defines the forge changelog flags
+func newChangelogFlags(opts *changelogFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	fs.StringVar(&opts.workspace, "workspace", ".", "Repository whose history is summarized")
+	fs.StringVar(&opts.file, "file", changelog.DefaultFile, "Changelog to update, relative to the workspace")
+	fs.StringVar(&opts.version, "version", "", "Version being released (default: an Unreleased section)")
+	fs.StringVar(&opts.since, "since", "", "Tag or commit to start after (default: the latest tag)")
+	fs.StringVar(&opts.format, "format", changelogFormatDiff, "Output format: diff (of the changelog), markdown (the new section) or json")
+	fs.BoolVar(&opts.write, "write", false, "Write the updated changelog instead of only printing it")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge changelog [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Groups the commits since the last tag by Conventional Commits type into a\n")
+		fmt.Fprintf(os.Stderr, "changelog section and prints the update to the changelog as a diff. No API\n")
+		fmt.Fprintf(os.Stderr, "key is needed. When -version is already tagged at HEAD, the commits since the\n")
+		fmt.Fprintf(os.Stderr, "tag before it are used, so it can run in CI after tagging.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge changelog\n")
+		fmt.Fprintf(os.Stderr, "  forge changelog -version v1.2.0 -write\n")
+		fmt.Fprintf(os.Stderr, "  forge changelog -version v1.2.0 -format markdown > release-notes.md\n")
+	}
+	return fs
+}
+
+// runChangelog implements `forge changelog` and returns the process exit code
+func runChangelog(args []string) int {
+	opts := &changelogFlags{}
+	fs := newChangelogFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	switch opts.format {
+	case changelogFormatDiff, changelogFormatMarkdown, changelogFormatJSON:
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q: use diff, markdown or json\n", opts.format)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	proposal, err := changelog.Propose(ctx, opts.workspace, changelog.Options{
+		File:    opts.file,
+		Version: opts.version,
+		Since:   opts.since,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build changelog: %v\n", err)
+		return 1
+	}
+
+	switch opts.format {
+	case changelogFormatMarkdown:
+		fmt.Print(proposal.Release.Markdown())
+	case changelogFormatJSON:
+		data, err := json.MarshalIndent(proposal.Release, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode changelog: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(data))
+	default:
+		if proposal.Changed() {
+			fmt.Print(coding.GenerateUnifiedDiff(proposal.Original, proposal.Updated, filepath.ToSlash(opts.file)))
+		}
+	}
+
+	if !proposal.Changed() {
+		fmt.Fprintf(os.Stderr, "%s is up to date\n", opts.file)
+		return 0
+	}
+	if opts.write {
+		if err := proposal.Apply(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Updated %s with %d commit(s)\n", opts.file, len(proposal.Release.Entries))
+	}
+	return 0
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is a forge subcommand
+type command struct {
+	name    string
+	summary string
+	flags   func() *flag.FlagSet // Flags for help and completion; nil if the command has none
+	words   []string             // Fixed positional arguments offered by shell completion
+	run     func(args []string) int
+}
+
+// commands returns the forge subcommands in the order they are listed in help
+func commands() []*command {
+	return []*command{
+		{
+			name:    "chat",
+			summary: "Start an interactive session (the default)",
+			flags:   func() *flag.FlagSet { return newChatFlags("chat", &Config{}) },
+			run:     runChat,
+		},
+		{
+			name:    "run",
+			summary: "Run a prompt to completion without a user, for scripts and CI",
+			flags:   func() *flag.FlagSet { return newChatFlags("run", &Config{}) },
+			run:     runPrompt,
+		},
+		{
+			name:    "review",
+			summary: "Review the git diff for a range and report findings",
+			flags:   func() *flag.FlagSet { return newReviewFlags(&reviewFlags{}) },
+			run:     runReview,
+		},
+		{
+			name:    "explain",
+			summary: "Map the workspace's modules and write an architecture document",
+			flags:   func() *flag.FlagSet { return newExplainFlags(&explainFlags{}) },
+			run:     runExplain,
+		},
+		{
+			name:    "changelog",
+			summary: "Update CHANGELOG.md from the commits since the last tag",
+			flags:   func() *flag.FlagSet { return newChangelogFlags(&changelogFlags{}) },
+			run:     runChangelog,
+		},
+		{
+			name:    "release",
+			summary: "Bump the version, write release notes, tag and publish, step by step",
+			flags:   func() *flag.FlagSet { return newReleaseFlags(&releaseFlags{}) },
+			run:     runRelease,
+		},
+		{
+			name:    "eval",
+			summary: "Run eval scenarios against the agent and score the outcomes",
+			flags:   func() *flag.FlagSet { return newEvalFlags(&evalFlags{}) },
+			run:     runEval,
+		},
+		{
+			name:    "workflow",
+			summary: "Run a YAML-defined workflow as a sequence of agent turns, or list them",
+			flags:   func() *flag.FlagSet { return newWorkflowFlags(&Config{}, inputValues{}) },
+			words:   []string{"run", "list"},
+			run:     runWorkflow,
+		},
+		{
+			name:    "pipeline",
+			summary: "Plan, implement and review a task with a separate agent role for each stage",
+			flags:   func() *flag.FlagSet { return newPipelineFlags(&Config{}) },
+			run:     runPipeline,
+		},
+		{
+			name:    "watch",
+			summary: "Run a prompt or workflow each time a path or git ref changes",
+			flags:   func() *flag.FlagSet { return newWatchFlags(&Config{}, &watchFlags{}, inputValues{}) },
+			run:     runWatch,
+		},
+		{
+			name:    "inspect",
+			summary: "Step through a session recorded with -record, event by event",
+			flags:   func() *flag.FlagSet { return newInspectFlags() },
+			run:     runInspect,
+		},
+		{
+			name:    "share",
+			summary: "Serve a read-only live view of a session recorded with -record",
+			flags:   func() *flag.FlagSet { return newShareFlags(&shareFlags{}) },
+			run:     runShare,
+		},
+		{
+			name:    "sessions",
+			summary: "List, show or delete the sessions kept with -session",
+			flags:   func() *flag.FlagSet { return newSessionsFlags(&sessionsFlags{}) },
+			words:   []string{"list", "show", "delete"},
+			run:     runSessions,
+		},
+		{
+			name:    "config",
+			summary: "Print, export or import the configuration, or print its file paths",
+			flags:   func() *flag.FlagSet { return newConfigFlags(&configFlags{}) },
+			words:   []string{"show", "path", "export", "import"},
+			run:     runConfig,
+		},
+		{
+			name:    "models",
+			summary: "List the provider's models with their context window and price",
+			flags:   func() *flag.FlagSet { return newModelsFlags(&modelsFlags{}) },
+			run:     runModels,
+		},
+		{
+			name:    "doctor",
+			summary: "Diagnose configuration problems",
+			flags:   func() *flag.FlagSet { return newDoctorFlags(&doctorFlags{}) },
+			run:     runDoctor,
+		},
+		{
+			name:    "update",
+			summary: "Install the newest release",
+			flags:   func() *flag.FlagSet { return newUpdateFlags(&updateFlags{}) },
+			run:     runUpdate,
+		},
+		{
+			name:    "completion",
+			summary: "Print a shell completion script",
+			words:   completionShells,
+			run:     runCompletion,
+		},
+		{
+			name:    "version",
+			summary: "Print the version",
+			run: func(args []string) int {
+				fmt.Printf("Forge v%s\n", version)
+				return 0
+			},
+		},
+	}
+}
+
+// findCommand returns the subcommand called name, if any
+func findCommand(name string) (*command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return nil, false
+}
+
+// dispatch runs the subcommand named by the first argument and returns the
+// process exit code. Without a subcommand, or when the first argument is a
+// flag, it starts an interactive chat so `forge -model x` keeps working.
+func dispatch(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runChat(args)
+	}
+
+	if args[0] == "help" {
+		if len(args) > 1 {
+			if cmd, ok := findCommand(args[1]); ok && cmd.flags != nil {
+				cmd.flags().Usage()
+				return 0
+			}
+		}
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+	return cmd.run(args[1:])
+}
+
+// printUsage lists the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Forge - A TUI coding agent\n\n")
+	fmt.Fprintf(w, "Usage: forge [options]\n")
+	fmt.Fprintf(w, "       forge <command> [arguments]\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'forge help <command>' or 'forge <command> -h' for a command's options.\n")
+}
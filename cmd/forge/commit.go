@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/entrhq/forge/pkg/agent/git"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/google/uuid"
+)
+
+// commitAttribution returns how /commit attributes commits, from the
+// commit_attribution section and the selected profile. Each session gets a
+// new session ID for the {session} placeholder.
+func commitAttribution(config *Config) (git.Attribution, error) {
+	attribution := git.Attribution{SessionID: uuid.New().String()}
+	if section := appconfig.GetCommitAttribution(); section != nil {
+		if err := section.Validate(); err != nil {
+			return attribution, err
+		}
+		attribution.CommitterName = section.CommitterName()
+		attribution.CommitterEmail = section.CommitterEmail()
+		attribution.Trailers = section.Trailers()
+	}
+	if config.CommitterName != "" {
+		attribution.CommitterName = config.CommitterName
+	}
+	if config.CommitterEmail != "" {
+		attribution.CommitterEmail = config.CommitterEmail
+	}
+	return attribution, nil
+}
+
+// commitMessageStyle returns the style of the messages /commit generates,
+// from the commit_message section
+func commitMessageStyle() (git.MessageStyle, error) {
+	section := appconfig.GetCommitMessage()
+	if section == nil {
+		return git.MessageStyle{}, nil
+	}
+	if err := section.Validate(); err != nil {
+		return git.MessageStyle{}, err
+	}
+	return git.MessageStyle{
+		Style:        section.Style(),
+		Template:     section.Template(),
+		Types:        section.Types(),
+		Scopes:       section.Scopes(),
+		IssuePattern: section.IssuePattern(),
+	}, nil
+}
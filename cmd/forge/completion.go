@@ -0,0 +1,196 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// completionShells are the shells forge completion generates scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// runCompletion implements `forge completion bash|zsh|fish`
+func runCompletion(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: forge completion bash|zsh|fish\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  source <(forge completion bash)                      # bash, current shell\n")
+		fmt.Fprintf(os.Stderr, "  forge completion zsh > \"${fpath[1]}/_forge\"          # zsh\n")
+		fmt.Fprintf(os.Stderr, "  forge completion fish > ~/.config/fish/completions/forge.fish\n")
+		return 2
+	}
+
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(os.Stdout, commands())
+	case "zsh":
+		writeZshCompletion(os.Stdout, commands())
+	case "fish":
+		writeFishCompletion(os.Stdout, commands())
+	default:
+		fmt.Fprintf(os.Stderr, "Unsupported shell %q: use bash, zsh or fish\n", args[0])
+		return 2
+	}
+	return 0
+}
+
+// completionFlag is a flag as offered by shell completion
+type completionFlag struct {
+	name    string
+	usage   string
+	boolean bool // Takes no value
+}
+
+// commandFlags lists the flags of cmd in name order
+func commandFlags(cmd *command) []completionFlag {
+	if cmd.flags == nil {
+		return nil
+	}
+	var flags []completionFlag
+	cmd.flags().VisitAll(func(f *flag.Flag) {
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:    f.Name,
+			usage:   f.Usage,
+			boolean: ok && boolFlag.IsBoolFlag(),
+		})
+	})
+	return flags
+}
+
+// completionWords returns the words completed after cmd: its flags and fixed arguments
+func completionWords(cmd *command) []string {
+	var words []string
+	for _, f := range commandFlags(cmd) {
+		words = append(words, "-"+f.name)
+	}
+	return append(words, cmd.words...)
+}
+
+// writeBashCompletion writes a bash completion script. Unknown positions fall
+// back to file name completion.
+func writeBashCompletion(w io.Writer, cmds []*command) {
+	var names []string
+	var chat *command
+	for _, cmd := range cmds {
+		names = append(names, cmd.name)
+		if cmd.name == "chat" {
+			chat = cmd
+		}
+	}
+	topLevel := append(names, completionWords(chat)...)
+
+	fmt.Fprintf(w, "# bash completion for forge\n")
+	fmt.Fprintf(w, "_forge() {\n")
+	fmt.Fprintf(w, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(w, "    if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=( $(compgen -W %q -- \"$cur\") )\n", strings.Join(topLevel, " "))
+	fmt.Fprintf(w, "        return\n")
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "    case \"${COMP_WORDS[1]}\" in\n")
+	for _, cmd := range cmds {
+		words := completionWords(cmd)
+		if len(words) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "        %s) COMPREPLY=( $(compgen -W %q -- \"$cur\") ) ;;\n", cmd.name, strings.Join(words, " "))
+	}
+	fmt.Fprintf(w, "        -*) COMPREPLY=( $(compgen -W %q -- \"$cur\") ) ;;\n", strings.Join(completionWords(chat), " "))
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -o default -F _forge forge\n")
+}
+
+// zshEscape escapes text for a zsh _arguments or _describe spec
+func zshEscape(s string) string {
+	return strings.NewReplacer(`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+// writeZshCompletion writes a zsh completion script
+func writeZshCompletion(w io.Writer, cmds []*command) {
+	fmt.Fprintf(w, "#compdef forge\n\n")
+	fmt.Fprintf(w, "_forge() {\n")
+	fmt.Fprintf(w, "    local -a commands\n")
+	fmt.Fprintf(w, "    commands=(\n")
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, "        '%s:%s'\n", cmd.name, zshEscape(cmd.summary))
+	}
+	fmt.Fprintf(w, "    )\n\n")
+	fmt.Fprintf(w, "    if (( CURRENT == 2 )) && [[ $words[2] != -* ]]; then\n")
+	fmt.Fprintf(w, "        _describe 'command' commands\n")
+	fmt.Fprintf(w, "        return\n")
+	fmt.Fprintf(w, "    fi\n\n")
+	fmt.Fprintf(w, "    case $words[2] in\n")
+	for _, cmd := range cmds {
+		specs := zshSpecs(cmd)
+		if len(specs) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "        %s)\n", cmd.name)
+		fmt.Fprintf(w, "            shift words; (( CURRENT-- ))\n")
+		fmt.Fprintf(w, "            _arguments %s ;;\n", strings.Join(specs, " "))
+	}
+	for _, cmd := range cmds {
+		if cmd.name == "chat" {
+			fmt.Fprintf(w, "        -*) _arguments %s ;;\n", strings.Join(zshSpecs(cmd), " "))
+		}
+	}
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "}\n\n")
+	fmt.Fprintf(w, "_forge \"$@\"\n")
+}
+
+// zshSpecs returns the _arguments specs for cmd's flags and fixed arguments
+func zshSpecs(cmd *command) []string {
+	var specs []string
+	for _, f := range commandFlags(cmd) {
+		if f.boolean {
+			specs = append(specs, fmt.Sprintf("'-%s[%s]'", f.name, zshEscape(f.usage)))
+		} else {
+			specs = append(specs, fmt.Sprintf("'-%s[%s]:value:_files'", f.name, zshEscape(f.usage)))
+		}
+	}
+	if len(cmd.words) > 0 {
+		specs = append(specs, fmt.Sprintf("'1:argument:(%s)'", strings.Join(cmd.words, " ")))
+	}
+	return specs
+}
+
+// fishEscape escapes text for a single-quoted fish string
+func fishEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+// writeFishCompletion writes a fish completion script
+func writeFishCompletion(w io.Writer, cmds []*command) {
+	var names []string
+	for _, cmd := range cmds {
+		names = append(names, cmd.name)
+	}
+	condition := fmt.Sprintf("__fish_seen_subcommand_from %s", strings.Join(names, " "))
+
+	fmt.Fprintf(w, "# fish completion for forge\n")
+	fmt.Fprintf(w, "complete -c forge -f\n")
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, "complete -c forge -n 'not %s' -a %s -d '%s'\n", condition, cmd.name, fishEscape(cmd.summary))
+	}
+	for _, cmd := range cmds {
+		seen := fmt.Sprintf("__fish_seen_subcommand_from %s", cmd.name)
+		if cmd.name == "chat" {
+			// Chat flags also apply without a subcommand
+			seen = fmt.Sprintf("not %s; or %s", condition, seen)
+		}
+		for _, f := range commandFlags(cmd) {
+			required := " -r -F"
+			if f.boolean {
+				required = ""
+			}
+			fmt.Fprintf(w, "complete -c forge -n '%s' -o %s -d '%s'%s\n", seen, f.name, fishEscape(f.usage), required)
+		}
+		if len(cmd.words) > 0 {
+			fmt.Fprintf(w, "complete -c forge -n '%s' -a '%s'\n", seen, strings.Join(cmd.words, " "))
+		}
+	}
+}
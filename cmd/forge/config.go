@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/workflow"
+)
+
+// configFlags holds the options of forge config
+type configFlags struct {
+	effective bool
+	workspace string
+	profile   string
+	output    string
+	force     bool
+}
+
+// newConfigFlags defines the forge config flags
+func newConfigFlags(opts *configFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	fs.BoolVar(&opts.effective, "effective", false, "Show the merged result of flags, environment, project and global config")
+	fs.StringVar(&opts.workspace, "workspace", ".", "Workspace whose .forge/config.yaml -effective layers over the global config, export bundles or import writes")
+	fs.StringVar(&opts.profile, "profile", "", "Project profile -effective resolves (default: the project's 'profile' setting)")
+	fs.StringVar(&opts.output, "o", "", "File export writes the bundle to (default: standard output)")
+	fs.BoolVar(&opts.force, "force", false, "Let import replace workflows and a project config that differ from the bundle's")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge config show|path|export [options]\n")
+		fmt.Fprintf(os.Stderr, "       forge config import <bundle> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "  show    Print the global configuration, including defaults (-effective: as a session would use it)\n")
+		fmt.Fprintf(os.Stderr, "  path    Print the paths of the global and project configuration files\n")
+		fmt.Fprintf(os.Stderr, "  export  Write a bundle of your settings, workflows and the workspace's project config,\n")
+		fmt.Fprintf(os.Stderr, "          without secrets, for teammates to import\n")
+		fmt.Fprintf(os.Stderr, "  import  Apply a bundle's settings and add its workflows and project config\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runConfig implements `forge config show|path|export|import`
+func runConfig(args []string) int {
+	opts := &configFlags{}
+	fs := newConfigFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	// Options may come before or after the action
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	action := fs.Arg(0)
+	_ = fs.Parse(fs.Args()[1:])
+	if action == "import" {
+		// So may the bundle
+		if fs.NArg() == 0 {
+			fs.Usage()
+			return 2
+		}
+		bundle := fs.Arg(0)
+		_ = fs.Parse(fs.Args()[1:])
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return 2
+		}
+		return importConfig(bundle, opts)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	switch action {
+	case "path":
+		store, err := appconfig.NewFileStore("")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		fmt.Println(store.Path())
+
+		project, err := appconfig.LoadProjectConfig(opts.workspace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load project configuration: %v\n", err)
+			return 1
+		}
+		if project != nil {
+			fmt.Println(project.Path)
+		}
+		return 0
+
+	case "show":
+		if opts.effective {
+			return showEffectiveConfig(opts)
+		}
+		if err := appconfig.Initialize(""); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		return printJSON(map[string]interface{}{
+			"version":  "1.0",
+			"sections": sectionData(),
+		})
+
+	case "export":
+		return exportConfig(opts)
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown config command %q: use show, path, export or import\n", action)
+		return 2
+	}
+}
+
+// showEffectiveConfig prints the settings a chat session in opts.workspace
+// would run with: model settings after flags, environment and the project
+// profile, the extra ignore patterns, and every section with the project
+// config layered over the global config
+func showEffectiveConfig(opts *configFlags) int {
+	config := &Config{}
+	fs := newChatFlags("chat", config)
+	_ = fs.Parse([]string{"-workspace", opts.workspace, "-profile", opts.profile})
+	if err := config.applyProject(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	if err := appconfig.InitializeWithProject("", config.Project); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	store, err := appconfig.NewFileStore("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	sources := []string{store.Path()}
+
+	effective := map[string]interface{}{
+		"version":  "1.0",
+		"model":    config.Model,
+		"base_url": config.BaseURL,
+		"sections": sectionData(),
+	}
+	if config.UtilityModel != "" {
+		effective["utility_model"] = config.UtilityModel
+	}
+	if config.Project != nil {
+		sources = append(sources, config.Project.Path)
+		profile := config.Profile
+		if profile == "" {
+			profile = config.Project.Profile
+		}
+		if profile != "" {
+			effective["profile"] = profile
+		}
+		if len(config.Project.Ignore) > 0 {
+			effective["ignore"] = config.Project.Ignore
+		}
+	}
+	effective["sources"] = sources
+
+	return printJSON(effective)
+}
+
+// applyProject loads the workspace's .forge/config.yaml and applies its model
+// settings (from the selected profile) to every option that was not given on
+// the command line or through an environment variable
+func (c *Config) applyProject(fs *flag.FlagSet) error {
+	project, err := appconfig.LoadProjectConfig(c.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	profile, err := project.ResolveProfile(c.Profile)
+	if err != nil {
+		return err
+	}
+	c.Project = project
+
+	explicit := explicitFlags(fs)
+
+	if profile.Model != "" && !explicit["model"] {
+		c.Model = profile.Model
+	}
+	// Empty unless set by -base-url or OPENAI_BASE_URL
+	if profile.BaseURL != "" && c.BaseURL == "" {
+		c.BaseURL = profile.BaseURL
+	}
+	if profile.UtilityModel != "" && c.UtilityModel == "" {
+		c.UtilityModel = profile.UtilityModel
+	}
+	c.CommitterName = profile.CommitterName
+	c.CommitterEmail = profile.CommitterEmail
+	return nil
+}
+
+// sectionData returns the data of every section of the global configuration
+func sectionData() map[string]map[string]interface{} {
+	sections := make(map[string]map[string]interface{})
+	for _, section := range appconfig.Global().GetSections() {
+		sections[section.ID()] = section.Data()
+	}
+	return sections
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v interface{}) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode configuration: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
+
+// exportConfig writes a bundle of the global settings that differ from the
+// defaults, the user's workflows and the workspace's project config, naming
+// the secrets left out
+func exportConfig(opts *configFlags) int {
+	if err := appconfig.Initialize(""); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	bundle, omitted := appconfig.Global().Bundle()
+
+	workflowDir, err := userWorkflowDir()
+	if err == nil {
+		err = bundle.AddWorkflows(workflowDir)
+	}
+	if err == nil {
+		err = bundle.AddProject(opts.workspace)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	out := os.Stdout
+	if opts.output != "" {
+		out, err = os.Create(opts.output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create bundle: %v\n", err)
+			return 1
+		}
+		defer out.Close()
+	}
+	if err := bundle.Write(out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d settings sections, %d workflows", len(bundle.Sections), len(bundle.Workflows))
+	if bundle.Project != "" {
+		fmt.Fprintf(os.Stderr, " and %s", appconfig.ProjectConfigPath)
+	}
+	fmt.Fprintln(os.Stderr)
+	if len(omitted) > 0 {
+		fmt.Fprintf(os.Stderr, "Left out secrets, which each member sets: %s\n", strings.Join(omitted, ", "))
+	}
+	return 0
+}
+
+// importConfig applies the bundle at path: its settings over the global
+// config, and its workflows and project config where they don't conflict
+// with the user's, or everywhere with -force
+func importConfig(path string, opts *configFlags) int {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to open bundle: %v\n", err)
+		return 1
+	}
+	bundle, err := appconfig.ReadBundle(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+		return 1
+	}
+
+	if err := appconfig.Initialize(""); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	sections, err := appconfig.Global().Apply(bundle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(sections) > 0 {
+		fmt.Printf("Updated settings: %s\n", strings.Join(sections, ", "))
+	}
+
+	workflowDir, err := userWorkflowDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	written, skipped, err := bundle.WriteWorkflows(workflowDir, opts.force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(written) > 0 {
+		fmt.Printf("Added workflows to %s: %s\n", workflowDir, strings.Join(written, ", "))
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Kept your own workflows (use -force to replace them): %s\n", strings.Join(skipped, ", "))
+	}
+
+	wrote, err := bundle.WriteProject(opts.workspace, opts.force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if wrote {
+		fmt.Printf("Wrote %s\n", filepath.Join(opts.workspace, appconfig.ProjectConfigPath))
+	} else if bundle.Project != "" {
+		fmt.Printf("Kept the workspace's %s (use -force to replace it)\n", appconfig.ProjectConfigPath)
+	}
+	return 0
+}
+
+// userWorkflowDir returns ~/.forge/workflows
+func userWorkflowDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, workflow.Dir), nil
+}
+
+// userAgentDir returns ~/.forge/agents
+func userAgentDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, workflow.AgentsDir), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+// headerValues collects repeated -header "Name: value" flags
+type headerValues map[string]string
+
+func (v headerValues) String() string {
+	pairs := make([]string, 0, len(v))
+	for name, value := range v {
+		pairs = append(pairs, name+": "+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func (v headerValues) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("expected \"Name: value\", got %q", s)
+	}
+	v[name] = strings.TrimSpace(value)
+	return nil
+}
+
+// connectionFlags are the flags for how to connect to the provider. Unset
+// flags fall back to the provider config section.
+type connectionFlags struct {
+	timeout  time.Duration
+	proxy    string
+	caBundle string
+	headers  headerValues
+}
+
+// define adds the connection flags to fs
+func (c *connectionFlags) define(fs *flag.FlagSet) {
+	c.headers = headerValues{}
+	fs.DurationVar(&c.timeout, "request-timeout", 0, "How long a provider request waits for a response before failing (default: provider.timeout in config, or no limit)")
+	fs.StringVar(&c.proxy, "proxy", "", "Proxy URL for provider requests (default: provider.proxy in config, or HTTPS_PROXY/HTTP_PROXY)")
+	fs.StringVar(&c.caBundle, "ca-bundle", "", "PEM file of CA certificates to trust for provider requests, e.g. a corporate TLS proxy's")
+	fs.Var(c.headers, "header", "Extra header for provider requests as \"Name: value\" (repeatable)")
+}
+
+// resolve layers the flags over the provider config section, returning the
+// HTTP client to reach the provider with and the extra headers to send, on
+// top of base (a preset's)
+func (c *connectionFlags) resolve(base map[string]string) (*http.Client, map[string]string, error) {
+	cfg := openai.HTTPConfig{Timeout: c.timeout, Proxy: c.proxy, CABundle: c.caBundle}
+	headers := make(map[string]string)
+	for name, value := range base {
+		headers[name] = value
+	}
+
+	if section := appconfig.GetProvider(); section != nil {
+		if cfg.Timeout == 0 {
+			cfg.Timeout = section.Timeout()
+		}
+		if cfg.Proxy == "" {
+			cfg.Proxy = section.Proxy()
+		}
+		if cfg.CABundle == "" {
+			cfg.CABundle = section.CABundle()
+		}
+		for name, value := range section.Headers() {
+			headers[name] = value
+		}
+	}
+	for name, value := range c.headers {
+		headers[name] = value
+	}
+
+	client, err := openai.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, headers, nil
+}
+
+// connect resolves how the provider is reached, the model a local server
+// has loaded when none is configured, and checks the provider with
+// -check-provider
+func connect(ctx context.Context, config *Config) error {
+	// Reach the provider through the configured proxy and CAs, with the extra headers
+	httpClient, headers, err := config.Connection.resolve(config.Headers)
+	if err != nil {
+		return fmt.Errorf("invalid provider connection settings: %w", err)
+	}
+	config.HTTPClient, config.Headers = httpClient, headers
+
+	// Local server presets use whichever model the server has loaded
+	if config.Model == "" {
+		model, err := servedModel(ctx, config.APIKey, config.BaseURL,
+			openai.WithHTTPClient(config.HTTPClient), openai.WithHeaders(config.Headers))
+		if err != nil {
+			return err
+		}
+		config.Model = model
+	}
+
+	// Fail fast on a bad key, base URL or model rather than on the first message.
+	// Replays from a response cache may not reach a provider at all.
+	if config.CheckProvider && config.ResponseCache == "" {
+		return checkProvider(ctx, config)
+	}
+	return nil
+}
+
+// streamUsage returns the provider option for the provider section's
+// stream_usage setting
+func streamUsage() openai.ProviderOption {
+	enabled := true
+	if section := appconfig.GetProvider(); section != nil {
+		enabled = section.StreamUsage()
+	}
+	return openai.WithStreamUsage(enabled)
+}
+
+// checkClient returns client bounded by the doctor's timeout, so checks of
+// an unresponsive provider don't hang
+func checkClient(client *http.Client) *http.Client {
+	bounded := *client
+	bounded.Timeout = 15 * time.Second
+	return &bounded
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/entrhq/forge/pkg/agent/git"
+	appconfig "github.com/entrhq/forge/pkg/config"
+)
+
+// maxDirtyPaths bounds how many uncommitted paths the startup check lists
+const maxDirtyPaths = 5
+
+// checkDirtyWorkspace warns about uncommitted changes in the workspace, or
+// with the confirm check, offers to stash or snapshot them and starts only
+// once the user chooses. The check is -dirty-check, or else the
+// dirty_workspace setting. Workspaces outside git are not checked.
+func checkDirtyWorkspace(ctx context.Context, config *Config) error {
+	check := config.DirtyCheck
+	if section := appconfig.GetDirtyWorkspace(); check == "" && section != nil {
+		check = section.Check()
+	}
+	if check == "" || check == appconfig.DirtyCheckOff || !config.Trusted {
+		return nil // An untrusted workspace is read-only, so nothing can mix
+	}
+
+	changes, err := git.UncommittedChanges(ctx, config.WorkspaceDir)
+	if err != nil || len(changes) == 0 {
+		return nil
+	}
+	summary := describeChanges(changes)
+
+	if check == appconfig.DirtyCheckWarn {
+		fmt.Fprintf(os.Stderr, "Warning: the workspace has %s; the agent's edits will mix with them\n", summary)
+		return nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("the workspace has %s; commit or stash them first, or use -dirty-check=warn", summary)
+	}
+
+	fmt.Printf("The workspace has %s.\n\n", summary)
+	fmt.Println("The agent's edits would mix with them, so you could not tell whose change")
+	fmt.Println("is whose. Put them aside first?")
+	fmt.Println()
+	fmt.Println("  s  Stash them; restore them later with git stash pop")
+	fmt.Println("  n  Snapshot them as a commit under " + git.SnapshotRefPrefix + ", leaving them")
+	fmt.Println("     in place; /changes then shows only the agent's edits")
+	fmt.Println("  c  Continue without putting them aside")
+	fmt.Println("  q  Quit")
+	fmt.Println()
+	fmt.Print("Choice [s/n/c/Q] ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return fmt.Errorf("no choice was made about the uncommitted changes")
+	}
+	label := "Forge: uncommitted changes before the session of " + time.Now().Format("2006-01-02 15:04")
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "s", "stash":
+		if err := git.Stash(ctx, config.WorkspaceDir, label); err != nil {
+			return fmt.Errorf("failed to stash the uncommitted changes: %w", err)
+		}
+		fmt.Println("Stashed. Restore the changes with: git stash pop")
+	case "n", "snapshot":
+		snapshot, err := git.TakeSnapshot(ctx, config.WorkspaceDir)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot the uncommitted changes: %w", err)
+		}
+		ref, err := snapshot.Save(ctx, label)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved the workspace as %s. Restore it with: git checkout %s -- .\n", ref, ref)
+	case "c", "continue":
+	default:
+		return fmt.Errorf("stopped: the workspace has uncommitted changes")
+	}
+	fmt.Println()
+	return nil
+}
+
+// describeChanges summarizes uncommitted changes, e.g.
+// "3 uncommitted changes (main.go, go.mod, notes.txt)"
+func describeChanges(changes []*git.FileModification) string {
+	paths := make([]string, 0, maxDirtyPaths)
+	for _, change := range changes {
+		if len(paths) == maxDirtyPaths {
+			break
+		}
+		paths = append(paths, change.Path)
+	}
+	list := strings.Join(paths, ", ")
+	if len(changes) > maxDirtyPaths {
+		list += fmt.Sprintf(", and %d more", len(changes)-maxDirtyPaths)
+	}
+	noun := "uncommitted changes"
+	if len(changes) == 1 {
+		noun = "uncommitted change"
+	}
+	return fmt.Sprintf("%d %s (%s)", len(changes), noun, list)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/doctor"
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+// doctorFlags are the options of forge doctor
+type doctorFlags struct {
+	doctor.Options
+	preset     string
+	connection connectionFlags
+}
+
+// newDoctorFlags defines the forge doctor flags
+func newDoctorFlags(opts *doctorFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	fs.StringVar(&opts.APIKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
+	fs.StringVar(&opts.BaseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
+	fs.StringVar(&opts.Model, "model", defaultModel, "LLM model to check")
+	fs.StringVar(&opts.preset, "preset", "", presetUsage)
+	fs.StringVar(&opts.WorkspaceDir, "workspace", ".", "Workspace directory to check")
+	opts.connection.define(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge doctor [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Checks the API key, network, model, git, workspace, terminal and config file.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runDoctor implements `forge doctor`: it diagnoses the configuration forge
+// would start with and returns the process exit code
+func runDoctor(args []string) int {
+	opts := &doctorFlags{}
+	fs := newDoctorFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if opts.preset != "" {
+		preset, err := lookupPreset(opts.preset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		applyPreset(preset, explicitFlags(fs), &opts.APIKey, &opts.BaseURL, &opts.Model)
+		opts.Headers = preset.Headers
+	}
+
+	// A broken config file is reported by the config check rather than aborting
+	_ = appconfig.Initialize("")
+
+	client, headers, err := opts.connection.resolve(opts.Headers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid provider connection settings: %v\n", err)
+		return 1
+	}
+	opts.HTTPClient, opts.Headers = checkClient(client), headers
+
+	ctx := context.Background()
+	// An unreachable server is reported by the network check
+	if opts.Model == "" {
+		opts.Model, _ = servedModel(ctx, opts.APIKey, opts.BaseURL,
+			openai.WithHTTPClient(opts.HTTPClient), openai.WithHeaders(opts.Headers))
+	}
+
+	results := doctor.Run(ctx, opts.Options)
+
+	fmt.Printf("Forge v%s doctor\n\n", version)
+	fmt.Print(doctor.Format(results))
+	if doctor.Failed(results) {
+		fmt.Println("\nSome checks failed. Fix the items marked FAIL, then run forge doctor again.")
+		return 1
+	}
+	fmt.Println("\nAll required checks passed.")
+	return 0
+}
+
+// checkProvider validates the API key, base URL and model the session would
+// use, returning an error listing the failed checks with their fixes
+func checkProvider(ctx context.Context, config *Config) error {
+	results := doctor.CheckProvider(ctx, doctor.Options{
+		APIKey:     config.APIKey,
+		BaseURL:    config.BaseURL,
+		Model:      config.Model,
+		Headers:    config.Headers,
+		HTTPClient: checkClient(config.HTTPClient),
+	})
+	if !doctor.Failed(results) {
+		return nil
+	}
+
+	var failed []doctor.Result
+	for _, r := range results {
+		if r.Status == doctor.StatusFail {
+			failed = append(failed, r)
+		}
+	}
+	return fmt.Errorf("provider check failed:\n\n%s\nRun forge doctor for a full diagnosis, or pass -check-provider=false to start anyway", doctor.Format(failed))
+}
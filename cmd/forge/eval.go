@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/eval"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+// Eval output formats
+const (
+	evalFormatMarkdown = "markdown"
+	evalFormatJSON     = "json"
+)
+
+// evalFlags are the options of forge eval
+type evalFlags struct {
+	live    bool
+	apiKey  string
+	baseURL string
+	model   string
+	run     string
+	format  string
+	output  string
+	keep    bool
+}
+
+// newEvalFlags defines the forge eval flags
+func newEvalFlags(opts *evalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	fs.BoolVar(&opts.live, "live", false, "Run against the model instead of the scenarios' scripted responses")
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key for -live (or set OPENAI_API_KEY env var)")
+	fs.StringVar(&opts.baseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL for -live (or set OPENAI_BASE_URL env var)")
+	fs.StringVar(&opts.model, "model", defaultModel, "LLM model for -live")
+	fs.StringVar(&opts.run, "run", "", "Only run scenarios whose name matches this regular expression")
+	fs.StringVar(&opts.format, "format", evalFormatMarkdown, "Output format: markdown or json")
+	fs.StringVar(&opts.output, "output", "", "Write the report to this file instead of stdout")
+	fs.BoolVar(&opts.keep, "keep", false, "Keep each scenario's workspace for inspection")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge eval [options] [scenario files or directories]\n\n")
+		fmt.Fprintf(os.Stderr, "Runs the agent on eval scenarios and scores the outcomes. Without -live, each\n")
+		fmt.Fprintf(os.Stderr, "scenario replays its scripted model responses, and scenarios without any are\n")
+		fmt.Fprintf(os.Stderr, "skipped. Scenarios are read from ./evals by default. Exits 1 if any fails.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge eval\n")
+		fmt.Fprintf(os.Stderr, "  forge eval -live -model gpt-4o -run diff evals\n")
+	}
+	return fs
+}
+
+// runEval implements `forge eval [paths]` and returns the process exit code
+func runEval(args []string) int {
+	opts := &evalFlags{}
+	fs := newEvalFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if opts.format != evalFormatMarkdown && opts.format != evalFormatJSON {
+		fmt.Fprintf(os.Stderr, "Unknown format %q: use markdown or json\n", opts.format)
+		return 2
+	}
+	var filter *regexp.Regexp
+	if opts.run != "" {
+		var err error
+		if filter, err = regexp.Compile(opts.run); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -run pattern: %v\n", err)
+			return 2
+		}
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"evals"}
+	}
+	all, err := eval.LoadScenarios(paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load scenarios: %v\n", err)
+		return 1
+	}
+	var scenarios []*eval.Scenario
+	for _, s := range all {
+		switch {
+		case filter != nil && !filter.MatchString(s.Name):
+		case !opts.live && !s.Scripted():
+			fmt.Fprintf(os.Stderr, "Skipping %s: no scripted responses (run it with -live)\n", s.Name)
+		default:
+			scenarios = append(scenarios, s)
+		}
+	}
+	if len(scenarios) == 0 {
+		fmt.Fprintf(os.Stderr, "No scenarios to run\n")
+		return 1
+	}
+
+	runnerOpts := []eval.Option{
+		eval.WithAgentOptions(agent.WithCustomInstructions(composeSystemPrompt())),
+		eval.WithKeepWorkspaces(opts.keep),
+		eval.WithProgress(os.Stderr),
+	}
+	if opts.live {
+		provider, err := newEvalProvider(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		runnerOpts = append(runnerOpts, eval.WithProvider(func(*eval.Scenario) (llm.Provider, error) {
+			return provider, nil
+		}))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report := eval.NewRunner(runnerOpts...).RunAll(ctx, scenarios)
+
+	output := report.Markdown()
+	if opts.format == evalFormatJSON {
+		data, err := report.JSON()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		output = data + "\n"
+	}
+	if opts.output == "" {
+		fmt.Print(output)
+	} else if err := os.WriteFile(opts.output, []byte(output), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+
+	if !report.Passed() {
+		return 1
+	}
+	return 0
+}
+
+// newEvalProvider creates the OpenAI provider for forge eval -live
+func newEvalProvider(opts *evalFlags) (llm.Provider, error) {
+	if opts.apiKey == "" {
+		return nil, fmt.Errorf("configuration error: API key is required for -live. Set OPENAI_API_KEY environment variable or use -api-key flag")
+	}
+	providerOpts := []openai.ProviderOption{openai.WithModel(opts.model)}
+	if opts.baseURL != "" {
+		providerOpts = append(providerOpts, openai.WithBaseURL(opts.baseURL))
+	}
+	provider, err := openai.NewProvider(opts.apiKey, providerOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM provider: %w", err)
+	}
+	return provider, nil
+}
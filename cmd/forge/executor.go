@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/memory/sqlite"
+	"github.com/entrhq/forge/pkg/agent/seed"
+	"github.com/entrhq/forge/pkg/bridge"
+	"github.com/entrhq/forge/pkg/doctor"
+	"github.com/entrhq/forge/pkg/executor/cli"
+	"github.com/entrhq/forge/pkg/executor/headless"
+	"github.com/entrhq/forge/pkg/executor/tui"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/tools/issues"
+)
+
+// executor runs the session's conversation
+type executor interface {
+	Run(ctx context.Context) error
+}
+
+// newExecutor creates the executor config asks for: a workflow, forge run,
+// a one-shot or accessible CLI session, or the TUI. The returned cleanup
+// releases what the executor holds once it has run.
+//
+// Accessible mode renders sequential plain lines without emoji, box drawing,
+// colors or the alternate screen, so screen readers can follow the session.
+func newExecutor(ag *agent.DefaultAgent, config *Config, p *providers, tuiOpts []tui.ExecutorOption) (executor, func(), error) {
+	switch {
+	case config.Workflow != nil:
+		workflowExec, err := newWorkflowExecutor(ag, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		return workflowExec, func() {}, nil
+	case config.Headless:
+		return headless.NewExecutor(ag, config.Prompt, headless.WithFormat(headless.Format(config.Format))), func() {}, nil
+	case config.Prompt != "":
+		return cli.NewExecutor(ag, cli.WithPrompt(config.Prompt), cli.WithAccessible(config.Accessible)), func() {}, nil
+	case config.Accessible:
+		fmt.Println("Accessible mode: plain-text output")
+		return cli.NewExecutor(ag, cli.WithAccessible(true)), func() {}, nil
+	default:
+		return newTUIExecutor(ag, config, p, tuiOpts)
+	}
+}
+
+// newTUIExecutor creates the TUI executor, serving the IDE bridge with
+// -bridge and recording its events with -record. The returned cleanup stops
+// both.
+func newTUIExecutor(ag *agent.DefaultAgent, config *Config, p *providers, opts []tui.ExecutorOption) (executor, func(), error) {
+	var closers []io.Closer
+	cleanup := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i].Close()
+		}
+	}
+
+	opts = append(opts, tui.WithDiagnostics(doctor.Options{
+		APIKey:       config.APIKey,
+		BaseURL:      config.BaseURL,
+		Model:        config.Model,
+		Headers:      config.Headers,
+		WorkspaceDir: config.WorkspaceDir,
+		HTTPClient:   checkClient(config.HTTPClient),
+	}), tui.WithModels(p.models, config.Model))
+	if config.Bridge {
+		server := bridge.NewServer(config.WorkspaceDir)
+		if err := server.Start(); err != nil {
+			return nil, nil, fmt.Errorf("failed to start IDE bridge: %w", err)
+		}
+		closers = append(closers, server)
+		opts = append(opts, tui.WithBridge(server))
+		fmt.Printf("IDE bridge: %s\n", server.SocketPath())
+	}
+	if config.Record != "" {
+		recording, err := os.Create(config.Record)
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to create event log: %w", err)
+		}
+		closers = append(closers, recording)
+		opts = append(opts, tui.WithEventLog(recording))
+		fmt.Printf("Recording events: %s\n", config.Record)
+	}
+
+	// Create TUI executor with provider and workspace for git operations
+	exec := tui.NewExecutor(ag, p.utility, config.WorkspaceDir, opts...)
+	fmt.Println("\nStarting TUI...")
+	return exec, cleanup, nil
+}
+
+// tuiOptions returns the TUI's options for the session: change tracking,
+// /commit, /review and /issue, and the displaced session's warning.
+// Snapshotting the workspace registers workspace_diff with ag.
+func tuiOptions(ctx context.Context, ag *agent.DefaultAgent, config *Config, guard *workspace.Guard, tracker *git.ModificationTracker, provider llm.Provider, issueTracker issues.Tracker, displaced *workspace.LockInfo) ([]tui.ExecutorOption, error) {
+	// Snapshot the workspace so workspace_diff and /changes can report every
+	// change made during the session, not just those made by the file tools
+	opts := []tui.ExecutorOption{tui.WithModificationTracker(tracker)}
+	snapshot, err := git.TakeSnapshot(ctx, config.WorkspaceDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: workspace snapshot unavailable, change tracking disabled: %v\n", err)
+	} else {
+		if err := ag.RegisterTool(coding.NewWorkspaceDiffTool(guard, snapshot), agent.WithToolTimeout(fileToolTimeout)); err != nil {
+			return nil, fmt.Errorf("failed to register tool: %w", err)
+		}
+		opts = append(opts, tui.WithWorkspaceSnapshot(snapshot))
+	}
+	attribution, err := commitAttribution(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure commit attribution: %w", err)
+	}
+	messageStyle, err := commitMessageStyle()
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit_message configuration: %w", err)
+	}
+	opts = append(opts, tui.WithCommitAttribution(attribution), tui.WithCommitMessageStyle(messageStyle))
+	opts = append(opts, tui.WithReviewer(newReviewer(provider, guard)))
+	if issueTracker != nil {
+		opts = append(opts, tui.WithIssueTracker(issueTracker))
+	}
+	if displaced != nil {
+		opts = append(opts, tui.WithDisplacedSession(*displaced))
+	}
+	if !config.Trusted {
+		opts = append(opts, tui.WithReadOnly())
+	}
+	return append(opts, tui.WithImportShare(config.SeedShare)), nil
+}
+
+// bannerWriter returns where the welcome message goes: stderr for forge run,
+// so that stdout holds only its result
+func bannerWriter(config *Config) io.Writer {
+	if config.Headless {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printBanner writes the welcome message describing the session to w, and
+// warns on stderr of the displaced session
+func printBanner(w io.Writer, config *Config, ag *agent.DefaultAgent, utility llm.Provider, plugins []toolPlugin, memory *sqlite.Memory, seeded *seed.Document, displaced *workspace.LockInfo) {
+	fmt.Fprintf(w, "Forge v%s - Coding Agent\n", version)
+	fmt.Fprintf(w, "Workspace: %s\n", config.WorkspaceDir)
+	if !config.Trusted {
+		fmt.Fprintln(w, "Read-only mode: workspace not trusted (start with -trust to allow edits and commands)")
+	}
+	fmt.Fprintf(w, "Model: %s\n", config.Model)
+	if info := utility.GetModelInfo(); info != nil && info.Name != config.Model {
+		fmt.Fprintf(w, "Utility model: %s\n", info.Name)
+	}
+	fmt.Fprintf(w, "Base prompt: %s\n", ag.BasePromptVersion())
+	if variant := ag.PromptVariant(); variant != "" {
+		fmt.Fprintf(w, "Prompt variant: %s\n", variant)
+	}
+	if config.Project != nil {
+		fmt.Fprintf(w, "Project config: %s\n", config.Project.Path)
+	}
+	if len(plugins) > 0 {
+		names := make([]string, len(plugins))
+		for i, p := range plugins {
+			names[i] = p.Name()
+		}
+		fmt.Fprintf(w, "Plugins: %s\n", strings.Join(names, ", "))
+	}
+	if memory != nil {
+		fmt.Fprintf(w, "Session: %s (%d messages)\n", config.Session, memory.Count())
+	}
+	if seeded != nil {
+		fmt.Fprintf(w, "Seed: %s\n", seeded.Summary())
+	}
+	if displaced != nil {
+		fmt.Fprintf(os.Stderr, "Warning: another Forge session is using this workspace (%s); edits may conflict\n", displaced)
+	}
+}
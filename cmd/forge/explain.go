@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/entrhq/forge/pkg/agent/explain"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding"
+)
+
+// Explain output formats
+const (
+	explainFormatMarkdown = "markdown"
+	explainFormatJSON     = "json"
+)
+
+// explainFlags are the options of forge explain
+type explainFlags struct {
+	apiKey    string
+	baseURL   string
+	model     string
+	workspace string
+	format    string
+	output    string
+	maxSteps  int
+	mapOnly   bool
+}
+
+// newExplainFlags defines the forge explain flags
+func newExplainFlags(opts *explainFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
+	fs.StringVar(&opts.baseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
+	fs.StringVar(&opts.model, "model", defaultModel, "LLM model to explain with")
+	fs.StringVar(&opts.workspace, "workspace", ".", "Workspace to explain")
+	fs.StringVar(&opts.format, "format", explainFormatMarkdown, "Output format: markdown or json")
+	fs.StringVar(&opts.output, "output", "", "Write the document to this file instead of stdout")
+	fs.IntVar(&opts.maxSteps, "max-steps", 0, "Maximum LLM calls spent reading the code (0 for the default)")
+	fs.BoolVar(&opts.mapOnly, "map-only", false, "Only print the module and dependency map, without calling the LLM")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge explain [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Maps the workspace's modules and the imports between them, then lets the agent\n")
+		fmt.Fprintf(os.Stderr, "read the code with read-only tools and writes an architecture document: overview,\n")
+		fmt.Fprintf(os.Stderr, "components, entry points, data flow and conventions. No file is modified.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge explain -output ARCHITECTURE.md\n")
+		fmt.Fprintf(os.Stderr, "  forge explain -map-only -format json\n")
+	}
+	return fs
+}
+
+// runExplain implements `forge explain` and returns the process exit code
+func runExplain(args []string) int {
+	opts := &explainFlags{}
+	fs := newExplainFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	switch opts.format {
+	case explainFormatMarkdown, explainFormatJSON:
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q: use markdown or json\n", opts.format)
+		return 2
+	}
+	if opts.apiKey == "" && !opts.mapOnly {
+		fmt.Fprintf(os.Stderr, "Configuration error: API key is required. Set OPENAI_API_KEY environment variable or use -api-key flag\n")
+		return 1
+	}
+
+	guard, err := workspace.NewGuard(opts.workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create workspace guard: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	m, err := explain.BuildMap(ctx, guard)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Mapping failed: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Mapped %d module(s) in %d file(s)\n", len(m.Modules), m.Files)
+
+	// Without the LLM the document holds only the map
+	doc := &explain.Document{Map: m}
+	if !opts.mapOnly {
+		providerOpts := []openai.ProviderOption{openai.WithModel(opts.model)}
+		if opts.baseURL != "" {
+			providerOpts = append(providerOpts, openai.WithBaseURL(opts.baseURL))
+		}
+		provider, err := openai.NewProvider(opts.apiKey, providerOpts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create LLM provider: %v\n", err)
+			return 1
+		}
+
+		explainer := newExplainer(provider, guard,
+			explain.WithMaxSteps(opts.maxSteps),
+			explain.WithProgress(func(step int, toolName string) {
+				fmt.Fprintf(os.Stderr, "Step %d: %s\n", step, toolName)
+			}),
+		)
+		doc, err = explainer.Explain(ctx, m)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Explain failed: %v\n", err)
+			return 1
+		}
+	}
+
+	var output string
+	switch opts.format {
+	case explainFormatJSON:
+		data, err := doc.JSON()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		output = data + "\n"
+	default:
+		output = doc.Markdown()
+	}
+
+	if opts.output == "" {
+		fmt.Print(output)
+		return 0
+	}
+	if err := os.WriteFile(opts.output, []byte(output), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write document: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Architecture document written to %s\n", opts.output)
+	return 0
+}
+
+// newExplainer creates an explainer that can read the workspace with the
+// read-only coding tools; no tool that writes or runs commands is available
+func newExplainer(provider llm.Provider, guard *workspace.Guard, opts ...explain.Option) *explain.Explainer {
+	opts = append([]explain.Option{explain.WithTools(
+		coding.NewReadFileTool(guard),
+		coding.NewListFilesTool(guard),
+		coding.NewSearchFilesTool(guard),
+	)}, opts...)
+	return explain.NewExplainer(provider, opts...)
+}
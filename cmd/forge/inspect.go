@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/entrhq/forge/pkg/executor/tui"
+)
+
+// newInspectFlags defines the forge inspect flags, which are only -h
+func newInspectFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge inspect <event-log>\n\n")
+		fmt.Fprintf(os.Stderr, "Steps through a session recorded with forge -record, showing the TUI as it was\n")
+		fmt.Fprintf(os.Stderr, "after each event. Use it to reproduce what a user saw from the log they sent.\n\n")
+		fmt.Fprintf(os.Stderr, "Keys:\n")
+		fmt.Fprintf(os.Stderr, "  ←/→  step back or forward one event\n")
+		fmt.Fprintf(os.Stderr, "  [/]  jump to the previous or next turn end\n")
+		fmt.Fprintf(os.Stderr, "  g/G  jump to the start or end\n")
+		fmt.Fprintf(os.Stderr, "  ↑/↓  scroll the conversation\n")
+		fmt.Fprintf(os.Stderr, "  q    quit\n")
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge -record session.jsonl\n")
+		fmt.Fprintf(os.Stderr, "  forge inspect session.jsonl\n")
+	}
+	return fs
+}
+
+// runInspect implements `forge inspect` and returns the process exit code
+func runInspect(args []string) int {
+	fs := newInspectFlags()
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if err := tui.Inspect(fs.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
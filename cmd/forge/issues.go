@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/tools/ci"
+	"github.com/entrhq/forge/pkg/tools/github"
+	"github.com/entrhq/forge/pkg/tools/issues"
+)
+
+// newIssueTracker creates the tracker for get_issue, search_issues and /issue
+// from the issue_tracker section. Without a configured provider, GitHub is used
+// when the origin remote is on github.com; otherwise it returns nil.
+func newIssueTracker(ctx context.Context, workspaceDir string) (issues.Tracker, error) {
+	section := appconfig.GetIssueTracker()
+	if section == nil {
+		section = appconfig.NewIssueTrackerSection()
+	}
+	if err := section.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch section.Provider() {
+	case appconfig.IssueTrackerJira:
+		token := envOr(section.TokenEnv(), "JIRA_API_TOKEN")
+		email := envOr(section.EmailEnv(), "JIRA_EMAIL")
+		return issues.NewJira(section.BaseURL(),
+			issues.WithJiraCredentials(email, token),
+			issues.WithJiraProject(section.Project()),
+		), nil
+	default:
+		repo, client := githubAccess(ctx, section, workspaceDir)
+		if repo == "" && section.Provider() == "" {
+			return nil, nil
+		}
+		return issues.NewGitHub(repo, client), nil
+	}
+}
+
+// newCIProvider creates the GitHub Actions provider for fetch_ci_logs, using
+// the repository and token of the issue_tracker section. Returns nil when the
+// workspace has no GitHub repository.
+func newCIProvider(ctx context.Context, workspaceDir string) (ci.Provider, error) {
+	section := appconfig.GetIssueTracker()
+	if section == nil {
+		section = appconfig.NewIssueTrackerSection()
+	}
+	if err := section.Validate(); err != nil {
+		return nil, err
+	}
+
+	repo, client := githubAccess(ctx, section, workspaceDir)
+	if repo == "" {
+		return nil, nil
+	}
+	return ci.NewGitHubActions(repo, client), nil
+}
+
+// githubAccess returns the GitHub repository from the issue_tracker section
+// and a client for its API endpoint and token. The repository defaults to the
+// origin remote's; when the tracker is Jira, its base_url and token_env are
+// not GitHub's.
+func githubAccess(ctx context.Context, section *appconfig.IssueTrackerSection, workspaceDir string) (string, *github.Client) {
+	repo, apiURL, tokenEnv := "", "", ""
+	if section.Provider() != appconfig.IssueTrackerJira {
+		repo, apiURL, tokenEnv = section.Repository(), section.BaseURL(), section.TokenEnv()
+	}
+	if repo == "" {
+		repo = issues.DetectGitHubRepo(ctx, workspaceDir)
+	}
+
+	token := envOr(tokenEnv, "GITHUB_TOKEN")
+	if token == "" && tokenEnv == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return repo, github.NewClient(github.WithAPI(apiURL), github.WithToken(token))
+}
+
+// envOr returns the value of the environment variable name, or of fallback
+// when name is ""
+func envOr(name, fallback string) string {
+	if name == "" {
+		name = fallback
+	}
+	return os.Getenv(name)
+}
@@ -8,23 +8,29 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent"
-	agentcontext "github.com/entrhq/forge/pkg/agent/context"
-	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/seed"
 	appconfig "github.com/entrhq/forge/pkg/config"
-	"github.com/entrhq/forge/pkg/executor/tui"
+	"github.com/entrhq/forge/pkg/executor/headless"
 	"github.com/entrhq/forge/pkg/llm/openai"
-	"github.com/entrhq/forge/pkg/security/workspace"
-	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/llm/parser"
+	"github.com/entrhq/forge/pkg/workflow"
 )
 
+// version of the Forge coding agent; release builds set it with
+// -ldflags "-X main.version=..."
+var version = "0.1.0"
+
 const (
-	version      = "0.1.0"                       // Version of the Forge coding agent
 	defaultModel = "anthropic/claude-sonnet-4.5" // Default model to use
 
 	// Context management defaults for coding sessions
@@ -35,85 +41,260 @@ const (
 	defaultMinToolCalls     = 10     // Minimum 10 tool calls in buffer before summarizing
 	defaultMaxToolCallDist  = 40     // Force summarization if any tool call is 40+ messages old
 	defaultSummaryBatchSize = 10     // Summarize 10 messages at a time
+
+	// Per-turn loop budget defaults; a turn that exhausts its budget stops and
+	// the user can grant another with /continue
+	defaultMaxIterations   = 100
+	defaultMaxTurnDuration = 30 * time.Minute
+
+	// fileToolTimeout bounds how long a filesystem tool may run before it is canceled
+	fileToolTimeout = 2 * time.Minute
 )
 
 // Config holds the application configuration
 type Config struct {
-	APIKey       string
-	BaseURL      string
-	Model        string
-	WorkspaceDir string
-	SystemPrompt string
-	ShowVersion  bool
+	APIKey            string
+	BaseURL           string
+	Model             string
+	Preset            string            // Built-in gateway preset from -preset; empty for none
+	Headers           map[string]string // Sent with each API request, from the preset and connection settings
+	Connection        connectionFlags   // Timeout, proxy, CA bundle and headers from flags
+	HTTPClient        *http.Client      // Client for provider requests, resolved from Connection and the provider config
+	WorkspaceDir      string
+	SystemPrompt      string
+	PromptVariant     string // Base prompt variant from system_prompt.variants; empty to pick one at random
+	ShowVersion       bool
+	ConsistencyCheck  bool
+	ToolStats         bool  // Report the session's tool failures to the model each turn
+	Environment       *bool // Add the detected OS, runtimes, package managers and test commands to the system prompt; nil unless -environment was given
+	EditLocks         bool  // Refuse writes over file changes the agent hasn't seen
+	MaxIterations     int
+	MaxToolCalls      int
+	MaxTurnDuration   time.Duration
+	NoToolCall        string // What to do with a response without a tool call: error, converse or ask
+	Accessible        bool
+	Bridge            bool   // Serve the IDE bridge socket for editor extensions
+	Record            string // File the TUI records the session's events to, for forge inspect
+	IgnoreLock        bool   // Start even if another session holds the workspace lock
+	DirtyCheck        string // What to do about uncommitted changes at startup; empty for the dirty_workspace setting
+	CheckProvider     bool   // Validate the API key, base URL and model before starting
+	Trust             bool   // Value of -trust, recorded when given
+	Trusted           bool   // Whether the workspace may be edited and run commands in
+	ThinkingTags      string // Comma-separated names of the tags the model's thinking is in
+	HideThinking      bool   // Drop the model's thinking instead of showing it
+	MaxThinkingTokens int    // Thinking shown per response; 0 for no limit
+	UtilityModel      string
+	ResponseCache     string
+	CacheSummaries    bool
+	Prompt            string             // One-shot prompt for forge run; empty for an interactive session
+	Headless          bool               // Run Prompt without reading stdin, rejecting tool calls that aren't auto-approved
+	Format            string             // How a headless run prints its outcome: text or json
+	Session           string             // Name of the session stored in .forge/sessions.db; empty to keep history in memory
+	Seed              string             // Document imported as context before the first turn; empty for none
+	SeedShare         float64            // Share of the context window an imported document may take
+	Workflow          *workflow.Workflow // Workflow for forge workflow run; nil otherwise
+	WorkflowInputs    map[string]string  // The workflow's bound inputs
+	AssumeYes         bool               // Run each workflow step without asking
+	Profile           string             // Project profile to use; empty for the project's default
+	CommitterName     string             // Committer for /commit from the profile; empty for commit_attribution's
+	CommitterEmail    string
+
+	// Project is the workspace's .forge/config.yaml, nil if it has none
+	Project *appconfig.ProjectConfig
 }
 
 func main() {
-	// Parse command line flags
-	config := parseFlags()
+	os.Exit(dispatch(os.Args[1:]))
+}
+
+// runChat implements `forge chat`, the interactive session. It is also what
+// plain `forge [options]` runs.
+func runChat(args []string) int {
+	config := &Config{}
+	fs := newChatFlags("chat", config)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
 
 	// Show version if requested
 	if config.ShowVersion {
 		fmt.Printf("Forge v%s\n", version)
-		return
+		return 0
 	}
 
+	if err := config.resolveTrust(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Workspace trust error: %v\n", err)
+		return 1
+	}
+	// An untrusted workspace's project config could enable hooks or auto-approval
+	if config.Trusted {
+		if err := config.applyProject(fs); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return 1
+		}
+	}
+	if err := config.applyPreset(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	return execute(config)
+}
+
+// runPrompt implements `forge run "task"`: it runs the prompt to completion
+// without a user and exits with a status saying how the turn ended
+func runPrompt(args []string) int {
+	config := &Config{}
+	fs := newChatFlags("run", config)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	config.Headless = true
+	config.Prompt = strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if config.Prompt == "" {
+		fmt.Fprintf(os.Stderr, "forge run: a prompt is required, e.g. forge run \"add tests for parser.go\"\n")
+		return 2
+	}
+
+	if err := config.resolveTrust(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Workspace trust error: %v\n", err)
+		return 1
+	}
+	// An untrusted workspace's project config could enable hooks or auto-approval
+	if config.Trusted {
+		if err := config.applyProject(fs); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return 1
+		}
+	}
+	if err := config.applyPreset(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	return execute(config)
+}
+
+// execute validates config and runs a session until it ends or is interrupted
+func execute(config *Config) int {
 	// Validate configuration
 	if err := config.validate(); err != nil {
-		log.Fatalf("Configuration error: %v", err)
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
 	}
 
 	// Create context with signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// Set up signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
-		fmt.Println("\n\nShutting down gracefully...")
+		fmt.Fprintln(os.Stderr, "\n\nShutting down gracefully...")
 		cancel()
 	}()
 
-	// Run the application
-	if runErr := run(ctx, config); runErr != nil {
-		cancel()
-		log.Fatalf("Application error: %v", runErr)
+	// Run the application; forge run exits with a status for how its turn
+	// ended
+	if err := run(ctx, config); err != nil {
+		fmt.Fprintf(os.Stderr, "Application error: %v\n", err)
+		return headless.ExitCode(err)
 	}
+	return 0
 }
 
-// parseFlags parses command line flags and environment variables
-func parseFlags() *Config {
-	config := &Config{}
+// newChatFlags defines the session flags shared by forge chat, forge run,
+// forge workflow run and forge watch
+func newChatFlags(name string, config *Config) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+
+	fs.StringVar(&config.APIKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
+	fs.StringVar(&config.BaseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
+	fs.StringVar(&config.Model, "model", defaultModel, "LLM model to use")
+	fs.StringVar(&config.Preset, "preset", "", presetUsage)
+	config.Connection.define(fs)
+	fs.StringVar(&config.Profile, "profile", "", "Profile from the workspace's .forge/config.yaml (default: its 'profile' setting)")
+	fs.StringVar(&config.WorkspaceDir, "workspace", ".", "Workspace directory (default: current directory)")
+	fs.StringVar(&config.SystemPrompt, "prompt", "", "Custom instructions for the agent (optional, overrides default)")
+	fs.StringVar(&config.PromptVariant, "prompt-variant", "", "Base prompt variant from system_prompt.variants in config to run (default: picked at random by weight)")
+	fs.IntVar(&config.MaxIterations, "max-iterations", defaultMaxIterations, "Maximum agent loop iterations per turn (0 = unlimited)")
+	fs.IntVar(&config.MaxToolCalls, "max-tool-calls", 0, "Maximum tool calls per turn (0 = unlimited)")
+	fs.DurationVar(&config.MaxTurnDuration, "max-turn-duration", defaultMaxTurnDuration, "Maximum wall-clock time per turn (0 = unlimited)")
+	fs.StringVar(&config.NoToolCall, "no-tool-call", string(agent.NoToolCallError), "What to do when the model replies without a tool call: error to have it retry, converse to accept a reply that looks like a complete answer, or ask to ask you")
+	fs.BoolVar(&config.CheckProvider, "check-provider", true, "Check the API key, base URL and model with the provider before starting")
+	fs.BoolVar(&config.ConsistencyCheck, "consistency-check", false, "At the end of each turn, report references to symbols and files the turn's edits removed")
+	fs.BoolVar(&config.EditLocks, "edit-locks", true, "Refuse to overwrite a file that changed since the agent last read it during a turn")
+	fs.StringVar(&config.ThinkingTags, "thinking-tags", strings.Join(parser.DefaultThinkingTags, ","), "Comma-separated names of the tags the model wraps its thinking in")
+	fs.BoolVar(&config.HideThinking, "hide-thinking", false, "Drop the model's thinking instead of showing it")
+	fs.IntVar(&config.MaxThinkingTokens, "max-thinking-tokens", 0, "Show at most this many tokens of the model's thinking per response (0 = unlimited)")
+	fs.BoolFunc("environment", "Tell the model the OS, shell, runtime versions, package managers and likely test commands detected at startup (default true, unless system_prompt pins a base_version, overrides the base prompt or defines variants)", func(s string) error {
+		enabled, err := strconv.ParseBool(s)
+		config.Environment = &enabled
+		return err
+	})
+	fs.BoolVar(&config.ToolStats, "tool-stats", false, "Show the model which of its tool calls keep failing this session, so it can change approach")
+	fs.StringVar(&config.UtilityModel, "utility-model", "", "Cheaper model for summarization and commit/PR messages (overrides utility_model.model in config)")
+	fs.StringVar(&config.ResponseCache, "response-cache", "", "Directory for a deterministic LLM response cache that replays identical prompts (for demos and tests)")
+	fs.BoolVar(&config.CacheSummaries, "cache-summaries", false, "Reuse context summaries of identical history across sessions (~/.forge/cache/summaries)")
+	fs.BoolVar(&config.Trust, "trust", false, "Trust the workspace, allowing edits, commands and its .forge/config.yaml (-trust=false for read-only); remembered for later sessions")
+	fs.StringVar(&config.Session, "session", "", "Store the conversation in .forge/sessions.db under this name, resuming it if it exists")
+	fs.StringVar(&config.Seed, "seed", "", "Import a document, such as a design doc, as context before the first turn, summarized if it doesn't fit -seed-share")
+	fs.Float64Var(&config.SeedShare, "seed-share", seed.DefaultShare, "Share of the context window a document imported with -seed or /import may take (0-1)")
+	fs.StringVar(&config.DirtyCheck, "dirty-check", "", "Check for uncommitted changes at startup: off, warn, or confirm to offer a stash or snapshot first (default: the dirty_workspace setting, off)")
+	fs.BoolVar(&config.IgnoreLock, "ignore-lock", false, "Start even if another Forge session is using the workspace, taking over its lock")
+	fs.BoolVar(&config.Accessible, "accessible", envBool("FORGE_ACCESSIBLE"), "Plain-text, screen-reader friendly output instead of the TUI (or set FORGE_ACCESSIBLE=1)")
+
+	switch name {
+	case "run":
+		fs.StringVar(&config.Format, "format", string(headless.FormatText), "Output format: text (the result) or json (status, result, denied tool calls and exit status)")
+		fs.Usage = func() {
+			fmt.Fprintf(os.Stderr, "Usage: forge run [options] \"prompt\"\n\n")
+			fmt.Fprintf(os.Stderr, "Runs the prompt to completion without a user, for scripts and CI, then exits\n")
+			fmt.Fprintf(os.Stderr, "with a status saying how the turn ended. Stdin is not read: tool calls the\n")
+			fmt.Fprintf(os.Stderr, "auto_approval config and command_whitelist don't allow are rejected. Progress\n")
+			fmt.Fprintf(os.Stderr, "is logged to stderr and the result is printed to stdout.\n\n")
+			fmt.Fprintf(os.Stderr, "Options:\n")
+			fs.PrintDefaults()
+			fmt.Fprintf(os.Stderr, "\nExamples:\n")
+			fmt.Fprintf(os.Stderr, "  forge run -trust \"fix the failing test in parser_test.go\"\n")
+			fmt.Fprintf(os.Stderr, "  forge run -trust -format json \"update the changelog\" > outcome.json\n")
+		}
+		return fs
+	case "workflow", "watch":
+		return fs // The command adds its own flags and usage
+	}
 
-	flag.StringVar(&config.APIKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
-	flag.StringVar(&config.BaseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
-	flag.StringVar(&config.Model, "model", defaultModel, "LLM model to use")
-	flag.StringVar(&config.WorkspaceDir, "workspace", ".", "Workspace directory (default: current directory)")
-	flag.StringVar(&config.SystemPrompt, "prompt", "", "Custom instructions for the agent (optional, overrides default)")
-	flag.BoolVar(&config.ShowVersion, "version", false, "Show version and exit")
-
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Forge - A TUI coding agent\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: forge [options]\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		flag.PrintDefaults()
+	fs.BoolVar(&config.ShowVersion, "version", false, "Show version and exit")
+	fs.BoolVar(&config.Bridge, "bridge", false, "Serve a local socket for IDE extensions to mirror diffs, open files and send selections")
+	fs.StringVar(&config.Record, "record", "", "Record the session's events to this file, to step through with forge inspect or attach to a bug report")
+	fs.Usage = func() {
+		printUsage(os.Stderr)
+		fmt.Fprintf(os.Stderr, "\nChat options (forge [options] is forge chat [options]):\n")
+		fs.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
 		fmt.Fprintf(os.Stderr, "  OPENAI_API_KEY     OpenAI API key\n")
 		fmt.Fprintf(os.Stderr, "  OPENAI_BASE_URL    OpenAI API base URL (for compatible APIs)\n")
+		fmt.Fprintf(os.Stderr, "  FORGE_ACCESSIBLE   Set to 1 for plain-text, screen-reader friendly output\n")
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  forge                                    # Start in current directory\n")
 		fmt.Fprintf(os.Stderr, "  forge -workspace /path/to/project\n")
 		fmt.Fprintf(os.Stderr, "  forge -model gpt-4-turbo\n")
 		fmt.Fprintf(os.Stderr, "  forge -base-url https://api.openrouter.ai/api/v1\n")
+		fmt.Fprintf(os.Stderr, "  forge -preset groq -model llama-3.3-70b-versatile\n")
+		fmt.Fprintf(os.Stderr, "  forge run \"fix the failing test in parser_test.go\"\n")
 	}
+	return fs
+}
 
-	flag.Parse()
-	return config
+// envBool reports whether the named environment variable is set to a true value
+func envBool(name string) bool {
+	enabled, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && enabled
 }
 
 // validate checks that the configuration is valid
 func (c *Config) validate() error {
 	if c.APIKey == "" {
+		if preset, ok := openai.LookupPreset(c.Preset); ok && !preset.Local() {
+			return fmt.Errorf("API key is required. Set %s or OPENAI_API_KEY environment variable or use -api-key flag", preset.APIKeyEnv)
+		}
 		return fmt.Errorf("API key is required. Set OPENAI_API_KEY environment variable or use -api-key flag")
 	}
 
@@ -126,109 +307,95 @@ func (c *Config) validate() error {
 		return fmt.Errorf("workspace path '%s' is not a directory", c.WorkspaceDir)
 	}
 
+	if _, err := agent.ParseNoToolCallPolicy(c.NoToolCall); err != nil {
+		return fmt.Errorf("invalid -no-tool-call: %w", err)
+	}
+
+	if c.Headless {
+		if _, err := headless.ParseFormat(c.Format); err != nil {
+			return fmt.Errorf("invalid -format: %w", err)
+		}
+	}
+
+	if c.DirtyCheck != "" {
+		if err := appconfig.ValidateDirtyCheck(c.DirtyCheck); err != nil {
+			return fmt.Errorf("invalid -dirty-check: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // run executes the main application logic
 func run(ctx context.Context, config *Config) error {
-	// Initialize global configuration (for auto-approval and command whitelist)
-	if err := appconfig.Initialize(""); err != nil {
+	// Initialize global configuration (for auto-approval and command whitelist),
+	// with the project's settings layered on top
+	if err := appconfig.InitializeWithProject("", config.Project); err != nil {
 		return fmt.Errorf("failed to initialize configuration: %w", err)
 	}
-
-	// Create OpenAI provider with optional base URL
-	providerOpts := []openai.ProviderOption{
-		openai.WithModel(config.Model),
+	if err := connect(ctx, config); err != nil {
+		return err
 	}
+	loadRecoveryLibrary(config)
 
-	// Add base URL if provided
-	if config.BaseURL != "" {
-		providerOpts = append(providerOpts, openai.WithBaseURL(config.BaseURL))
+	lock, err := claimWorkspace(ctx, config)
+	if err != nil {
+		return err
 	}
+	defer lock.Release()
 
-	provider, err := openai.NewProvider(
-		config.APIKey,
-		providerOpts...,
-	)
+	providers, err := newProviders(config)
 	if err != nil {
-		return fmt.Errorf("failed to create LLM provider: %w", err)
-	}
-
-	// Create context summarization strategies for long coding sessions
-	// Strategy 1: Summarize old tool calls to compress historical operations (with buffering)
-	toolCallStrategy := agentcontext.NewToolCallSummarizationStrategy(
-		defaultToolCallAge,
-		defaultMinToolCalls,
-		defaultMaxToolCallDist,
-	)
-
-	// Strategy 2: Summarize when approaching token limit to prevent exhaustion
-	thresholdStrategy := agentcontext.NewThresholdSummarizationStrategy(
-		defaultThresholdPercent,
-		defaultSummaryBatchSize,
-	)
-
-	// Create context manager with both strategies
-	// Event channel will be set by the agent during initialization
-	contextManager, err := agentcontext.NewManager(
-		provider,
-		defaultMaxTokens,
-		toolCallStrategy,
-		thresholdStrategy,
-	)
+		return err
+	}
+	guard, err := newGuard(config)
 	if err != nil {
-		return fmt.Errorf("failed to create context manager: %w", err)
+		return err
 	}
-
-	// Compose the system prompt
-	systemPrompt := composeSystemPrompt()
-	if config.SystemPrompt != "" {
-		systemPrompt = config.SystemPrompt // Override with user-provided prompt
+	agentOpts, auditLog, err := agentOptions(ctx, config, guard, providers)
+	if err != nil {
+		return err
+	}
+	sessionMemory, closeSession, err := openSession(config)
+	if err != nil {
+		return err
 	}
+	defer closeSession()
 
-	// Create workspace security guard
-	guard, err := workspace.NewGuard(config.WorkspaceDir)
+	tracker := git.NewModificationTracker(config.WorkspaceDir)
+	ag := newAgent(providers.main, agentOpts, tracker, sessionMemory)
+	issueTracker, err := registerTools(ctx, ag, config, guard, tracker, auditLog)
 	if err != nil {
-		return fmt.Errorf("failed to create workspace guard: %w", err)
+		return err
 	}
 
-	// Create agent with custom system prompt and context manager
-	ag := agent.NewDefaultAgent(
-		provider,
-		agent.WithCustomInstructions(systemPrompt),
-		agent.WithContextManager(contextManager),
-	)
+	// Tools from plugin executables and WebAssembly modules
+	plugins := registerPlugins(ctx, ag, config.WorkspaceDir, config.Trusted)
+	defer closePlugins(plugins)
 
-	// Register coding tools
-	codingTools := []tools.Tool{
-		coding.NewReadFileTool(guard),
-		coding.NewWriteFileTool(guard),
-		coding.NewListFilesTool(guard),
-		coding.NewSearchFilesTool(guard),
-		coding.NewApplyDiffTool(guard),
-		coding.NewExecuteCommandTool(guard),
+	tuiOpts, err := tuiOptions(ctx, ag, config, guard, tracker, providers.main, issueTracker, lock.Displaced)
+	if err != nil {
+		return err
 	}
 
-	for _, tool := range codingTools {
-		if err := ag.RegisterTool(tool); err != nil {
-			return fmt.Errorf("failed to register tool: %w", err)
-		}
+	// Import the -seed document before the first turn
+	seeded, err := seedSession(ctx, ag, providers.utility, config)
+	if err != nil {
+		return err
 	}
 
-	// Create TUI executor with provider and workspace for git operations
-	executor := tui.NewExecutor(ag, provider, config.WorkspaceDir)
-
-	// Display welcome message
-	fmt.Printf("Forge v%s - Coding Agent\n", version)
-	fmt.Printf("Workspace: %s\n", config.WorkspaceDir)
-	fmt.Printf("Model: %s\n", config.Model)
-	fmt.Println("\nStarting TUI...")
-	fmt.Println()
+	banner := bannerWriter(config)
+	printBanner(banner, config, ag, providers.utility, plugins, sessionMemory, seeded, lock.Displaced)
+	executor, closeExecutor, err := newExecutor(ag, config, providers, tuiOpts)
+	if err != nil {
+		return err
+	}
+	defer closeExecutor()
+	fmt.Fprintln(banner)
 
 	// Run the executor
 	if err := executor.Run(ctx); err != nil {
 		return fmt.Errorf("executor error: %w", err)
 	}
-
-	return nil
+	return sessionSaved(sessionMemory, config.Session)
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+// modelsFlags holds the options of forge models
+type modelsFlags struct {
+	apiKey  string
+	baseURL string
+	model   string
+	preset  string
+
+	connection connectionFlags
+}
+
+// newModelsFlags defines the forge models flags
+func newModelsFlags(opts *modelsFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("models", flag.ExitOnError)
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
+	fs.StringVar(&opts.baseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
+	fs.StringVar(&opts.model, "model", defaultModel, "Model to mark as the one sessions use")
+	fs.StringVar(&opts.preset, "preset", "", presetUsage)
+	opts.connection.define(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge models [options] [filter]\n\n")
+		fmt.Fprintf(os.Stderr, "Lists the models the provider offers, with their context window and price per million tokens where the provider publishes them.\n")
+		fmt.Fprintf(os.Stderr, "A filter keeps models whose ID or name contains it.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runModels implements `forge models`
+func runModels(args []string) int {
+	opts := &modelsFlags{}
+	fs := newModelsFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	// Options may come before or after the filter
+	filter := fs.Arg(0)
+	if fs.NArg() > 0 {
+		_ = fs.Parse(fs.Args()[1:])
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var presetHeaders map[string]string
+	if opts.preset != "" {
+		preset, err := lookupPreset(opts.preset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		applyPreset(preset, explicitFlags(fs), &opts.apiKey, &opts.baseURL, &opts.model)
+		presetHeaders = preset.Headers
+	}
+
+	if err := appconfig.Initialize(""); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	client, headers, err := opts.connection.resolve(presetHeaders)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid provider connection settings: %v\n", err)
+		return 1
+	}
+	providerOpts := []openai.ProviderOption{openai.WithHTTPClient(client), openai.WithHeaders(headers)}
+	if opts.baseURL != "" {
+		providerOpts = append(providerOpts, openai.WithBaseURL(opts.baseURL))
+	}
+	provider, err := openai.NewProvider(opts.apiKey, providerOpts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	models, err := provider.ListModels(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list models: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Run forge doctor to check the API key and base URL.\n")
+		return 1
+	}
+
+	models = llm.FilterModels(models, filter)
+	if len(models) == 0 {
+		fmt.Fprintf(os.Stderr, "No models match %q\n", filter)
+		return 1
+	}
+	printModels(models, opts.model)
+	return 0
+}
+
+// printModels prints one model per line with its context window and prices,
+// marking current
+func printModels(models []llm.Model, current string) {
+	width := len("MODEL")
+	for _, m := range models {
+		width = max(width, len(m.ID))
+	}
+
+	fmt.Printf("  %-*s %8s %9s %9s\n", width, "MODEL", "CONTEXT", "INPUT/M", "OUTPUT/M")
+	for _, m := range models {
+		marker := " "
+		if m.ID == current {
+			marker = "*"
+		}
+		window, input, output := "—", "—", "—"
+		if m.ContextWindow > 0 {
+			window = fmt.Sprintf("%dK", m.ContextWindow/1000)
+		}
+		if m.Priced() {
+			input = fmt.Sprintf("$%.2f", m.InputPrice)
+			output = fmt.Sprintf("$%.2f", m.OutputPrice)
+		}
+		fmt.Printf("%s %-*s %8s %9s %9s\n", marker, width, m.ID, window, input, output)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/workflow"
+)
+
+// newPipelineFlags defines the forge pipeline flags: the session flags of
+// forge run plus -yes
+func newPipelineFlags(config *Config) *flag.FlagSet {
+	fs := newChatFlags("pipeline", config)
+	fs.BoolVar(&config.AssumeYes, "yes", false, "Run each stage without asking first")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge pipeline [options] \"task\"\n\n")
+		fmt.Fprintf(os.Stderr, "Runs a task in three stages, each by an agent with its own role:\n\n")
+		fmt.Fprintf(os.Stderr, "  plan       the architect explores the code and writes a plan, without editing\n")
+		fmt.Fprintf(os.Stderr, "  implement  the implementer carries out the plan and runs the tests\n")
+		fmt.Fprintf(os.Stderr, "  review     the reviewer reviews the diff against the plan and reports findings\n\n")
+		fmt.Fprintf(os.Stderr, "Each stage is given the artifacts of the stages before it, and is shown and\n")
+		fmt.Fprintf(os.Stderr, "confirmed before it runs, so you approve the plan before any code is written.\n")
+		fmt.Fprintf(os.Stderr, "Tool approvals are read from stdin. A role is replaced by a definition file of\n")
+		fmt.Fprintf(os.Stderr, "its name in %s in the workspace, when it is trusted, or your home\n", workflow.AgentsDir)
+		fmt.Fprintf(os.Stderr, "directory; forge workflow list shows the roles in use.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge pipeline \"add rate limiting to the public API\"\n")
+		fmt.Fprintf(os.Stderr, "  forge pipeline -model gpt-4o \"split config.go into one file per section\"\n")
+	}
+	return fs
+}
+
+// runPipeline implements `forge pipeline "task"` and returns the process
+// exit code
+func runPipeline(args []string) int {
+	config := &Config{}
+	fs := newPipelineFlags(config)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	task := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if task == "" {
+		fmt.Fprintf(os.Stderr, "forge pipeline: a task is required, e.g. forge pipeline \"add a /health endpoint\"\n")
+		return 2
+	}
+
+	if err := config.resolveTrust(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Workspace trust error: %v\n", err)
+		return 1
+	}
+	// An untrusted workspace's project config could enable hooks or
+	// auto-approval, and its agent definitions could rewrite the roles
+	if config.Trusted {
+		if err := config.applyProject(fs); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return 1
+		}
+	}
+	if err := config.applyPreset(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	config.Workflow = workflow.Pipeline()
+	config.WorkflowInputs = map[string]string{"task": task}
+	return execute(config)
+}
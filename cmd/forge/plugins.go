@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/tools/plugin"
+)
+
+// toolPlugin is a started plugin executable or WebAssembly module
+type toolPlugin interface {
+	Name() string
+	Tools() []tools.Tool
+	Close() error
+}
+
+// registerPlugins starts the plugins in the workspace's and the user's
+// .forge/plugins directories and registers their tools. Plugins that fail to
+// start and tools whose names are taken are reported and skipped.
+//
+// Plugin executables run with the user's privileges, so they are only
+// started in a trusted workspace. WebAssembly modules get only the
+// capabilities granted to them in the wasm_tools config; in an untrusted
+// workspace only the user's own modules are loaded, and they can't write.
+func registerPlugins(ctx context.Context, ag *agent.DefaultAgent, workspaceDir string, trusted bool) []toolPlugin {
+	var userDir, cacheDir string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		userDir = filepath.Join(homeDir, plugin.Dir)
+		cacheDir = filepath.Join(homeDir, ".forge", "cache", "wasm")
+	}
+	var dirs []string
+	if trusted {
+		dirs = append(dirs, filepath.Join(workspaceDir, plugin.Dir))
+	}
+	if userDir != "" {
+		dirs = append(dirs, userDir)
+	}
+
+	var plugins []toolPlugin
+	if trusted {
+		started, err := plugin.Load(ctx, workspaceDir, dirs...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		for _, p := range started {
+			plugins = append(plugins, p)
+		}
+	}
+
+	modules, err := plugin.LoadWASM(ctx, plugin.WASMConfig{
+		Workspace: workspaceDir,
+		Grants:    wasmGrants(trusted),
+		CacheDir:  cacheDir,
+	}, dirs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, m := range modules {
+		plugins = append(plugins, m)
+	}
+
+	for _, p := range plugins {
+		for _, tool := range p.Tools() {
+			if ag.GetTool(tool.Name()) != nil {
+				fmt.Fprintf(os.Stderr, "Warning: plugin %s: tool %s is already defined, skipping\n", p.Name(), tool.Name())
+				continue
+			}
+			if err := ag.RegisterTool(tool, agent.WithToolTimeout(plugin.Timeout(tool))); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: plugin %s: %v\n", p.Name(), err)
+			}
+		}
+	}
+	return plugins
+}
+
+// wasmGrants returns the capabilities granted to WebAssembly modules in the
+// wasm_tools config. Write access is withheld in an untrusted workspace.
+func wasmGrants(trusted bool) map[string]plugin.Capabilities {
+	section := appconfig.GetWASMTools()
+	if section == nil {
+		return nil
+	}
+
+	grants := make(map[string]plugin.Capabilities)
+	for name, grant := range section.Grants() {
+		grants[name] = plugin.Capabilities{
+			Read:    grant.Read || grant.Write,
+			Write:   grant.Write && trusted,
+			Network: grant.Network,
+		}
+	}
+	return grants
+}
+
+// closePlugins shuts down the plugins started for the session
+func closePlugins(plugins []toolPlugin) {
+	for _, p := range plugins {
+		if err := p.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+// presetUsage describes the -preset flag
+var presetUsage = "Gateway preset setting the base URL, API key variable, headers and model naming: " +
+	strings.Join(openai.PresetNames(), ", ")
+
+// explicitFlags returns the names of the flags given on the command line
+func explicitFlags(fs *flag.FlagSet) map[string]bool {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	return explicit
+}
+
+// lookupPreset returns the built-in preset named name
+func lookupPreset(name string) (openai.Preset, error) {
+	preset, ok := openai.LookupPreset(name)
+	if !ok {
+		return openai.Preset{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(openai.PresetNames(), ", "))
+	}
+	return preset, nil
+}
+
+// applyPreset points the API key, base URL and model at the preset's
+// gateway. The preset's base URL replaces OPENAI_BASE_URL and the profile's
+// unless -base-url was given, and its key variable is used unless -api-key
+// was. Without -model, the default model (still defaultModel after the
+// profile) becomes the preset's, which is empty for local servers; see
+// servedModel. Model names are then qualified the way the gateway names them.
+func applyPreset(preset openai.Preset, explicit map[string]bool, apiKey, baseURL, model *string) {
+	if !explicit["base-url"] {
+		*baseURL = preset.BaseURL
+	}
+	if !explicit["api-key"] {
+		*apiKey = preset.APIKey(*apiKey)
+	}
+	if !explicit["model"] && *model == defaultModel {
+		*model = preset.DefaultModel
+	}
+	*model = preset.Model(*model)
+}
+
+// applyPreset applies the preset named by -preset, if any
+func (c *Config) applyPreset(fs *flag.FlagSet) error {
+	if c.Preset == "" {
+		return nil
+	}
+	preset, err := lookupPreset(c.Preset)
+	if err != nil {
+		return err
+	}
+	applyPreset(preset, explicitFlags(fs), &c.APIKey, &c.BaseURL, &c.Model)
+	c.UtilityModel = preset.Model(c.UtilityModel)
+	c.Headers = preset.Headers
+	return nil
+}
+
+// servedModel returns the model the server at baseURL serves (the first one
+// it lists), for local server presets that have no default model
+func servedModel(ctx context.Context, apiKey, baseURL string, opts ...openai.ProviderOption) (string, error) {
+	provider, err := openai.NewProvider(apiKey, append(opts, openai.WithBaseURL(baseURL))...)
+	if err != nil {
+		return "", err
+	}
+	models, err := provider.ListModels(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to ask %s which model it serves: %w; is the server running? Use -model to choose one", baseURL, err)
+	}
+	if len(models) == 0 {
+		return "", fmt.Errorf("%s serves no model; load one or use -model", baseURL)
+	}
+	return models[0].ID, nil
+}
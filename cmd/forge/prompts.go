@@ -1,6 +1,16 @@
 package main
 
-import "strings"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/agent/prompts"
+	appconfig "github.com/entrhq/forge/pkg/config"
+)
 
 // CodingIdentity defines the core identity and purpose of the agent.
 const CodingIdentity = `
@@ -65,3 +75,127 @@ func composeSystemPrompt() string {
 	builder.WriteString(SecurityPractices)
 	return builder.String()
 }
+
+// systemPrompt returns the session's custom instructions: the composed
+// prompt or the -system-prompt override, with the read-only notice for an
+// untrusted workspace
+func systemPrompt(config *Config) string {
+	prompt := composeSystemPrompt()
+	if config.SystemPrompt != "" {
+		prompt = config.SystemPrompt // Override with user-provided prompt
+	}
+	if !config.Trusted {
+		prompt += ReadOnlyWorkspace
+	}
+	return prompt
+}
+
+// basePromptOptions resolves the base prompt pin or override from config into agent options.
+// With no configuration the agent uses the latest built-in base prompt. When
+// variants are configured, the session runs the one called variant, or one
+// picked at random, and records its turn outcomes for comparison. pinned
+// reports whether the session runs a fixed version, an override, or a
+// variant, rather than the latest base prompt.
+func basePromptOptions(variant string) (opts []agent.AgentOption, pinned bool, err error) {
+	section := appconfig.GetSystemPrompt()
+	if section == nil {
+		if variant != "" {
+			return nil, false, fmt.Errorf("unknown prompt variant %q", variant)
+		}
+		return nil, false, nil
+	}
+
+	picked, err := section.PickVariant(variant)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w (system_prompt.variants defines: %s)", err, variantNames(section.Variants()))
+	}
+	// A control variant runs the section's own base prompt
+	override, version, source := section.Override, section.BaseVersion(), "system_prompt"
+	if picked != nil && !picked.IsControl() {
+		override, version, source = picked.PromptOverride, picked.BaseVersion, "system_prompt.variants["+picked.Name+"]"
+	}
+
+	opts, err = promptOptions(override, version, source)
+	if err != nil {
+		return nil, false, err
+	}
+	if picked == nil {
+		return opts, len(opts) > 0, nil
+	}
+	path, err := section.MetricsFile()
+	if err != nil {
+		return nil, false, err
+	}
+	return append(opts, agent.WithPromptVariant(picked.Name, metrics.NewFileSink(path))), true, nil
+}
+
+// promptOptions returns the agent option for a base prompt override, or
+// else for the base prompt version, with source naming the settings in
+// errors. It returns none when neither is set, leaving the latest version.
+func promptOptions(override func() (string, error), version, source string) ([]agent.AgentOption, error) {
+	text, err := override()
+	if err != nil {
+		return nil, err
+	}
+	if text != "" {
+		return []agent.AgentOption{agent.WithBasePromptOverride(text)}, nil
+	}
+	if version == "" || version == "latest" {
+		return nil, nil
+	}
+
+	basePrompt, err := prompts.GetBasePrompt(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s.base_version: %w", source, err)
+	}
+	return []agent.AgentOption{agent.WithBasePrompt(basePrompt)}, nil
+}
+
+// variantNames lists the variants' names for error messages
+func variantNames(variants []appconfig.PromptVariant) string {
+	if len(variants) == 0 {
+		return "(none)"
+	}
+	names := make([]string, len(variants))
+	for i, v := range variants {
+		names[i] = v.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// loadRecoveryLibrary installs the recovery message templates of the
+// workspace and the user, reporting the templates that are ignored
+func loadRecoveryLibrary(config *Config) {
+	// Recovery messages may be tuned per workspace and per model
+	recovery := prompts.NewRecoveryLibrary(recoveryDirs(config.WorkspaceDir, config.Model, config.Trusted)...)
+	_, recoveryErrs := recovery.Check()
+	for _, err := range recoveryErrs {
+		fmt.Fprintf(os.Stderr, "Warning: recovery template ignored: %v\n", err)
+	}
+	prompts.SetRecoveryLibrary(recovery)
+}
+
+// recoveryDirs returns the directories recovery message templates are read
+// from, in order of precedence: the model's own subdirectory of each, such
+// as .forge/recovery/anthropic/claude-sonnet-4.5, before the directory
+// itself, and the workspace's before the user's. As with workflows, an
+// untrusted workspace's templates are ignored.
+func recoveryDirs(workspaceDir, model string, trusted bool) []string {
+	var bases []string
+	if trusted {
+		bases = append(bases, filepath.Join(workspaceDir, prompts.RecoveryDir))
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		bases = append(bases, filepath.Join(homeDir, prompts.RecoveryDir))
+	}
+
+	var dirs []string
+	for _, base := range bases {
+		// A model name can't reach outside the directory
+		if model != "" && !strings.Contains(model, "..") && !filepath.IsAbs(model) {
+			dirs = append(dirs, filepath.Join(base, model))
+		}
+		dirs = append(dirs, base)
+	}
+	return dirs
+}
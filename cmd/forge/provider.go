@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/middleware"
+	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/llm/parser"
+)
+
+// providers are the LLM providers of a session
+type providers struct {
+	models  *openai.Provider // The main model without middleware, for the TUI's model list
+	main    llm.Provider     // The agent loop's
+	utility llm.Provider     // Background work: summaries, commit and PR messages
+	summary llm.Provider     // Context summaries, reused across sessions with -cache-summaries
+
+	limiter        *llm.RateLimiter // The main model's rate limit
+	utilityLimiter *llm.RateLimiter // The utility model's; the main one's when it is the same model
+}
+
+// newProviders creates the main and utility providers from config, with
+// background calls yielding to the agent loop under a shared rate limit
+func newProviders(config *Config) (*providers, error) {
+	// Track the provider's rate limit so background calls don't starve the agent loop
+	rateLimiter := llm.NewRateLimiter()
+
+	// Create OpenAI provider with optional base URL
+	providerOpts := []openai.ProviderOption{
+		openai.WithModel(config.Model),
+		openai.WithRateLimiter(rateLimiter),
+		openai.WithHTTPClient(config.HTTPClient),
+		openai.WithParserConfig(config.parserConfig()),
+		streamUsage(),
+	}
+
+	// Add base URL if provided
+	if config.BaseURL != "" {
+		providerOpts = append(providerOpts, openai.WithBaseURL(config.BaseURL))
+	}
+	if len(config.Headers) > 0 {
+		providerOpts = append(providerOpts, openai.WithHeaders(config.Headers))
+	}
+
+	openaiProvider, err := openai.NewProvider(
+		config.APIKey,
+		providerOpts...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM provider: %w", err)
+	}
+
+	// Background work (summaries, commit and PR messages) may use a cheaper model
+	utilityProvider, utilityLimiter, err := newUtilityProvider(config, openaiProvider, rateLimiter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create utility model provider: %w", err)
+	}
+
+	// Background calls keep a reserve of the limit and yield to the agent loop
+	var provider llm.Provider = llm.Chain(openaiProvider, middleware.RateLimit(rateLimiter, llm.PriorityForeground))
+	utilityProvider = llm.Chain(utilityProvider, middleware.RateLimit(utilityLimiter, llm.PriorityBackground))
+
+	// Optionally replay identical prompts from disk (demos, tests, replays)
+	if config.ResponseCache != "" {
+		provider = llm.Chain(provider, middleware.Cache(config.ResponseCache))
+		utilityProvider = llm.Chain(utilityProvider, middleware.Cache(config.ResponseCache))
+	}
+
+	// Summarization calls are idempotent, so their results can be reused across sessions
+	summaryProvider := utilityProvider
+	if config.CacheSummaries {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		summaryProvider = llm.Chain(utilityProvider, middleware.Cache(filepath.Join(homeDir, ".forge", "cache", "summaries")))
+	}
+
+	return &providers{
+		models:         openaiProvider,
+		main:           provider,
+		utility:        utilityProvider,
+		summary:        summaryProvider,
+		limiter:        rateLimiter,
+		utilityLimiter: utilityLimiter,
+	}, nil
+}
+
+// newUtilityProvider creates the provider for background work from the
+// -utility-model flag or the utility_model config section, along with the
+// rate limiter tracking its limit. Without either, the main provider and
+// mainLimiter are returned.
+func newUtilityProvider(config *Config, mainProvider llm.Provider, mainLimiter *llm.RateLimiter) (llm.Provider, *llm.RateLimiter, error) {
+	model := config.UtilityModel
+	baseURL := config.BaseURL
+	apiKey := config.APIKey
+
+	if section := appconfig.GetUtilityModel(); section != nil {
+		if model == "" {
+			model = section.Model()
+		}
+		if section.BaseURL() != "" {
+			baseURL = section.BaseURL()
+		}
+		key, err := section.APIKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		if key != "" {
+			apiKey = key
+		}
+	}
+
+	if model == "" || (model == config.Model && baseURL == config.BaseURL) {
+		return mainProvider, mainLimiter, nil
+	}
+
+	// Another model has its own rate limit
+	limiter := llm.NewRateLimiter()
+	opts := []openai.ProviderOption{openai.WithModel(model), openai.WithRateLimiter(limiter), openai.WithHTTPClient(config.HTTPClient), streamUsage()}
+	if baseURL != "" {
+		opts = append(opts, openai.WithBaseURL(baseURL))
+	}
+	// The extra headers are for the main provider's gateway
+	if baseURL == config.BaseURL && len(config.Headers) > 0 {
+		opts = append(opts, openai.WithHeaders(config.Headers))
+	}
+	provider, err := openai.NewProvider(apiKey, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return provider, limiter, nil
+}
+
+// parserConfig returns how the provider splits the model's thinking from its
+// messages
+func (c *Config) parserConfig() parser.Config {
+	cfg := parser.DefaultConfig()
+	cfg.ThinkingTags = nil
+	for _, tag := range strings.Split(c.ThinkingTags, ",") {
+		if tag = strings.Trim(strings.TrimSpace(tag), "<>"); tag != "" {
+			cfg.ThinkingTags = append(cfg.ThinkingTags, tag)
+		}
+	}
+	if c.HideThinking {
+		cfg.Thinking = parser.ThinkingSuppress
+	}
+	cfg.MaxThinkingTokens = c.MaxThinkingTokens
+	return cfg
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/release"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// releaseFlags are the options of forge release
+type releaseFlags struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	workspace  string
+	bump       string
+	changelog  string
+	noCommit   bool
+	github     bool
+	remote     string
+	plainNotes bool
+	yes        bool
+}
+
+// newReleaseFlags defines the forge release flags
+func newReleaseFlags(opts *releaseFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("release", flag.ExitOnError)
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
+	fs.StringVar(&opts.baseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
+	fs.StringVar(&opts.model, "model", defaultModel, "LLM model to write the release notes with")
+	fs.StringVar(&opts.workspace, "workspace", ".", "Repository to release")
+	fs.StringVar(&opts.bump, "bump", "", "Version bump: major, minor or patch (default: from the commit types)")
+	fs.StringVar(&opts.changelog, "changelog", "CHANGELOG.md", "Changelog to update and commit before tagging")
+	fs.BoolVar(&opts.noCommit, "no-commit", false, "Tag HEAD without updating the changelog")
+	fs.BoolVar(&opts.github, "github", false, "Push the branch and tag and create the GitHub release with gh")
+	fs.StringVar(&opts.remote, "remote", release.DefaultRemote, "Remote to push to with -github")
+	fs.BoolVar(&opts.plainNotes, "plain-notes", false, "Use the changelog section as the release notes instead of asking the LLM")
+	fs.BoolVar(&opts.yes, "yes", false, "Run each step without asking first")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge release [options] [version]\n\n")
+		fmt.Fprintf(os.Stderr, "Releases the commits since the latest tag. Without a version, the next one is\n")
+		fmt.Fprintf(os.Stderr, "picked from their Conventional Commits types: major for breaking changes,\n")
+		fmt.Fprintf(os.Stderr, "minor for features, patch otherwise. The LLM writes the release notes, then\n")
+		fmt.Fprintf(os.Stderr, "each step is previewed and confirmed before it runs: commit the changelog\n")
+		fmt.Fprintf(os.Stderr, "update, create an annotated tag and, with -github, push and create the\n")
+		fmt.Fprintf(os.Stderr, "GitHub release.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge release\n")
+		fmt.Fprintf(os.Stderr, "  forge release -github v1.4.0\n")
+		fmt.Fprintf(os.Stderr, "  forge release -bump patch -plain-notes -yes\n")
+	}
+	return fs
+}
+
+// runRelease implements `forge release [version]` and returns the process
+// exit code
+func runRelease(args []string) int {
+	opts := &releaseFlags{}
+	fs := newReleaseFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	switch opts.bump {
+	case "", release.BumpMajor, release.BumpMinor, release.BumpPatch:
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown bump %q: use major, minor or patch\n", opts.bump)
+		return 2
+	}
+
+	var notes *release.NotesGenerator
+	if !opts.plainNotes {
+		if opts.apiKey == "" {
+			fmt.Fprintf(os.Stderr, "Configuration error: API key is required to write release notes. Set OPENAI_API_KEY environment variable, use -api-key flag, or use -plain-notes\n")
+			return 1
+		}
+		providerOpts := []openai.ProviderOption{openai.WithModel(opts.model)}
+		if opts.baseURL != "" {
+			providerOpts = append(providerOpts, openai.WithBaseURL(opts.baseURL))
+		}
+		provider, err := openai.NewProvider(opts.apiKey, providerOpts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create LLM provider: %v\n", err)
+			return 1
+		}
+		notes = release.NewNotesGenerator(providerClient{provider})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Preparing the release...\n")
+	plan, err := release.Prepare(ctx, opts.workspace, notes, release.Options{
+		Version:   fs.Arg(0),
+		Bump:      opts.bump,
+		Changelog: opts.changelog,
+		NoCommit:  opts.noCommit,
+		GitHub:    opts.github,
+		Remote:    opts.remote,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Release failed: %v\n", err)
+		return 1
+	}
+
+	previous := "the first commit"
+	if plan.Previous != "" {
+		previous = plan.Previous
+	}
+	fmt.Printf("Release %s: %d commit(s) since %s\n", plan.Tag, len(plan.Release.Entries), previous)
+
+	stdin := bufio.NewReader(os.Stdin)
+	for i, step := range plan.Steps {
+		fmt.Printf("\nStep %d/%d: %s\n\n%s\n", i+1, len(plan.Steps), step.Title, strings.TrimRight(step.Preview, "\n"))
+		if !opts.yes && !confirmStep(stdin) {
+			fmt.Fprintf(os.Stderr, "Release stopped before %q\n", step.Name)
+			return 1
+		}
+		done, err := step.Run(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Step %q failed: %v\n", step.Name, err)
+			return 1
+		}
+		fmt.Println(done)
+	}
+
+	if !opts.github {
+		fmt.Printf("\nPush the release with: git push --atomic %s HEAD %s\n", opts.remote, plan.Tag)
+	}
+	return 0
+}
+
+// confirmStep asks whether to run the step shown, treating anything but
+// "y" or "yes", including the end of input, as no
+func confirmStep(stdin *bufio.Reader) bool {
+	fmt.Print("\nRun this step? [y/N] ")
+	answer, err := stdin.ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// providerClient adapts an LLM provider to the one-prompt client the git
+// and release generators use
+type providerClient struct {
+	provider llm.Provider
+}
+
+// Generate returns the provider's response to prompt
+func (c providerClient) Generate(ctx context.Context, prompt string) (string, error) {
+	response, err := c.provider.Complete(ctx, []*types.Message{types.NewUserMessage(prompt)})
+	if err != nil {
+		return "", fmt.Errorf("LLM generation failed: %w", err)
+	}
+	return response.Content, nil
+}
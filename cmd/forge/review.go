@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/entrhq/forge/pkg/agent/review"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding"
+)
+
+// Review output formats
+const (
+	reviewFormatMarkdown = "markdown"
+	reviewFormatGitHub   = "github"
+	reviewFormatJSON     = "json"
+)
+
+// reviewFlags are the options of forge review
+type reviewFlags struct {
+	apiKey    string
+	baseURL   string
+	model     string
+	workspace string
+	format    string
+	output    string
+	chunkSize int
+}
+
+// newReviewFlags defines the forge review flags
+func newReviewFlags(opts *reviewFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("review", flag.ExitOnError)
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key (or set OPENAI_API_KEY env var)")
+	fs.StringVar(&opts.baseURL, "base-url", os.Getenv("OPENAI_BASE_URL"), "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
+	fs.StringVar(&opts.model, "model", defaultModel, "LLM model to review with")
+	fs.StringVar(&opts.workspace, "workspace", ".", "Repository to review")
+	fs.StringVar(&opts.format, "format", reviewFormatMarkdown, "Output format: markdown, github (review API payload) or json")
+	fs.StringVar(&opts.output, "output", "", "Write the review to this file instead of stdout")
+	fs.IntVar(&opts.chunkSize, "chunk-size", review.DefaultChunkSize, "Maximum bytes of diff reviewed at once")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge review [options] [base..head]\n\n")
+		fmt.Fprintf(os.Stderr, "Reviews the git diff for a range and reports findings by file, line and severity.\n")
+		fmt.Fprintf(os.Stderr, "The range takes any form git diff accepts. Without one, the current branch is\n")
+		fmt.Fprintf(os.Stderr, "reviewed against main, master or develop, or the uncommitted changes on the base branch.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge review main..HEAD\n")
+		fmt.Fprintf(os.Stderr, "  forge review -format github -output review.json origin/main...HEAD\n")
+	}
+	return fs
+}
+
+// runReview implements `forge review [base..head]` and returns the process exit code
+func runReview(args []string) int {
+	opts := &reviewFlags{}
+	fs := newReviewFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	switch opts.format {
+	case reviewFormatMarkdown, reviewFormatGitHub, reviewFormatJSON:
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q: use markdown, github or json\n", opts.format)
+		return 2
+	}
+	if opts.apiKey == "" {
+		fmt.Fprintf(os.Stderr, "Configuration error: API key is required. Set OPENAI_API_KEY environment variable or use -api-key flag\n")
+		return 1
+	}
+
+	providerOpts := []openai.ProviderOption{openai.WithModel(opts.model)}
+	if opts.baseURL != "" {
+		providerOpts = append(providerOpts, openai.WithBaseURL(opts.baseURL))
+	}
+	provider, err := openai.NewProvider(opts.apiKey, providerOpts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create LLM provider: %v\n", err)
+		return 1
+	}
+	guard, err := workspace.NewGuard(opts.workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create workspace guard: %v\n", err)
+		return 1
+	}
+
+	reviewer := newReviewer(provider, guard,
+		review.WithChunkSize(opts.chunkSize),
+		review.WithProgress(func(done, total int) {
+			fmt.Fprintf(os.Stderr, "Reviewed part %d of %d\n", done, total)
+		}),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := reviewer.ReviewRange(ctx, opts.workspace, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Review failed: %v\n", err)
+		return 1
+	}
+
+	var output string
+	switch opts.format {
+	case reviewFormatGitHub:
+		data, err := json.MarshalIndent(result.GitHubReview(), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode review: %v\n", err)
+			return 1
+		}
+		output = string(data) + "\n"
+	case reviewFormatJSON:
+		data, err := result.JSON()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		output = data + "\n"
+	default:
+		output = result.Markdown()
+	}
+
+	if opts.output == "" {
+		fmt.Print(output)
+		return 0
+	}
+	if err := os.WriteFile(opts.output, []byte(output), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write review: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Review written to %s\n", opts.output)
+	return 0
+}
+
+// newReviewer creates a reviewer that can read the workspace with the
+// read-only coding tools
+func newReviewer(provider llm.Provider, guard *workspace.Guard, opts ...review.Option) *review.Reviewer {
+	opts = append([]review.Option{review.WithTools(
+		coding.NewReadFileTool(guard),
+		coding.NewListFilesTool(guard),
+		coding.NewSearchFilesTool(guard),
+	)}, opts...)
+	return review.NewReviewer(provider, opts...)
+}
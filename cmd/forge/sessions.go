@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/memory/sqlite"
+)
+
+// sessionsFlags holds the options of forge sessions
+type sessionsFlags struct {
+	workspace string
+}
+
+// newSessionsFlags defines the forge sessions flags
+func newSessionsFlags(opts *sessionsFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("sessions", flag.ExitOnError)
+	fs.StringVar(&opts.workspace, "workspace", ".", "Workspace whose .forge/sessions.db holds the sessions")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge sessions [list] [options]\n")
+		fmt.Fprintf(os.Stderr, "       forge sessions show|delete <name> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Manages the sessions kept with forge chat -session NAME.\n\n")
+		fmt.Fprintf(os.Stderr, "  list    List the sessions, most recently updated first (the default)\n")
+		fmt.Fprintf(os.Stderr, "  show    Print a session's conversation\n")
+		fmt.Fprintf(os.Stderr, "  delete  Remove a session and everything stored with it\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge chat -session refactor-auth\n")
+		fmt.Fprintf(os.Stderr, "  forge sessions\n")
+		fmt.Fprintf(os.Stderr, "  forge sessions delete refactor-auth\n")
+	}
+	return fs
+}
+
+// runSessions implements `forge sessions list|show|delete`
+func runSessions(args []string) int {
+	opts := &sessionsFlags{}
+	fs := newSessionsFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+	action, name, ok := sessionsCommand(fs)
+	if !ok {
+		fs.Usage()
+		return 2
+	}
+
+	// Don't create a database just to report that it is empty
+	path := filepath.Join(opts.workspace, sqlite.Path)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if action == "list" {
+			fmt.Println("No sessions. Start one with forge chat -session NAME.")
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "No session named %q\n", name)
+		return 1
+	}
+
+	store, err := sqlite.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	sessions, err := store.Sessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if action == "list" {
+		printSessions(sessions)
+		return 0
+	}
+	if !hasSession(sessions, name) {
+		fmt.Fprintf(os.Stderr, "No session named %q\n", name)
+		return 1
+	}
+	if action == "delete" {
+		if err := store.DeleteSession(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Deleted session %s\n", name)
+		return 0
+	}
+	return showSession(store, name)
+}
+
+// sessionsCommand returns the action and session name of forge sessions,
+// reporting whether the action is known and the arguments fit it. Options may come before
+// or after the action and the session name.
+func sessionsCommand(fs *flag.FlagSet) (action, name string, ok bool) {
+	var positional []string
+	for fs.NArg() > 0 {
+		positional = append(positional, fs.Arg(0))
+		_ = fs.Parse(fs.Args()[1:])
+	}
+
+	action = "list"
+	if len(positional) > 0 {
+		action = positional[0]
+	}
+	var wantArgs int
+	switch action {
+	case "list":
+		wantArgs = 1
+	case "show", "delete":
+		wantArgs = 2
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown sessions command %q: use list, show or delete\n", action)
+		return "", "", false
+	}
+	if len(positional) > wantArgs || (len(positional) < wantArgs && action != "list") {
+		return "", "", false
+	}
+	if wantArgs == 2 {
+		name = positional[1]
+	}
+	return action, name, true
+}
+
+// hasSession reports whether sessions includes the one named name
+func hasSession(sessions []sqlite.SessionInfo, name string) bool {
+	for _, s := range sessions {
+		if s.ID == name {
+			return true
+		}
+	}
+	return false
+}
+
+// printSessions prints one session per line with its message count and
+// when it was last updated
+func printSessions(sessions []sqlite.SessionInfo) {
+	if len(sessions) == 0 {
+		fmt.Println("No sessions. Start one with forge chat -session NAME.")
+		return
+	}
+
+	width := len("SESSION")
+	for _, s := range sessions {
+		width = max(width, len(s.ID))
+	}
+	fmt.Printf("%-*s %8s  %s\n", width, "SESSION", "MESSAGES", "UPDATED")
+	for _, s := range sessions {
+		fmt.Printf("%-*s %8d  %s\n", width, s.ID, s.Messages, s.Updated.Local().Format(time.DateTime))
+	}
+}
+
+// showSession prints the conversation of the session named name, one
+// message after another under its role
+func showSession(store *sqlite.Store, name string) int {
+	mem, err := store.Session(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	for i, msg := range mem.GetAll() {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("[%s]\n%s\n", msg.Role, strings.TrimSpace(msg.Content))
+	}
+	if err := mem.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// openSession opens the session named by -session in the workspace's
+// sessions.db, returning nil without one. The returned close closes the
+// store once the session has run.
+func openSession(config *Config) (*sqlite.Memory, func(), error) {
+	if config.Session == "" {
+		return nil, func() {}, nil
+	}
+	store, err := sqlite.Open(filepath.Join(config.WorkspaceDir, sqlite.Path))
+	if err != nil {
+		return nil, nil, err
+	}
+	memory, err := store.Session(config.Session)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+	return memory, func() { store.Close() }, nil
+}
+
+// sessionSaved reports a named session whose messages were not all written
+// to disk
+func sessionSaved(memory *sqlite.Memory, name string) error {
+	if memory == nil {
+		return nil
+	}
+	if err := memory.Err(); err != nil {
+		return fmt.Errorf("session %s was not fully saved: %w", name, err)
+	}
+	return nil
+}
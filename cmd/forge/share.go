@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/entrhq/forge/pkg/share"
+)
+
+// shareFlags are the options of forge share
+type shareFlags struct {
+	addr   string
+	tunnel string
+}
+
+// newShareFlags defines the forge share flags
+func newShareFlags(opts *shareFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("share", flag.ExitOnError)
+	fs.StringVar(&opts.addr, "addr", "127.0.0.1:0", "Address to serve the view on; port 0 picks a free one")
+	fs.StringVar(&opts.tunnel, "tunnel", "", "Command that exposes the view beyond this machine, run with {port} and {url} replaced")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge share [options] <event-log>\n\n")
+		fmt.Fprintf(os.Stderr, "Serves a read-only live view of a session recorded with forge -record, so a\n")
+		fmt.Fprintf(os.Stderr, "teammate can watch the agent work in a browser. The view follows the log as the\n")
+		fmt.Fprintf(os.Stderr, "session writes it; nothing in it can send messages or answer approvals. The\n")
+		fmt.Fprintf(os.Stderr, "URL printed holds an access token: only share it with people who may read the\n")
+		fmt.Fprintf(os.Stderr, "whole conversation, including the file contents the agent reads.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge -record session.jsonl          # in one terminal\n")
+		fmt.Fprintf(os.Stderr, "  forge share session.jsonl            # in another\n")
+		fmt.Fprintf(os.Stderr, "  forge share -tunnel 'cloudflared tunnel --url {url}' session.jsonl\n")
+		fmt.Fprintf(os.Stderr, "  forge share -tunnel 'ssh -N -R 8080:localhost:{port} bastion' session.jsonl\n")
+	}
+	return fs
+}
+
+// runShare implements `forge share` and returns the process exit code. It
+// serves until interrupted or terminated.
+func runShare(args []string) int {
+	opts := &shareFlags{}
+	fs := newShareFlags(opts)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	server, err := share.New(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	listener, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	if !addr.IP.IsLoopback() {
+		fmt.Fprintf(os.Stderr, "Warning: the view is reachable from other machines on %s; anyone with the URL can read the session\n", addr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Handler: server, ReadHeaderTimeout: 10 * time.Second}
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+
+	base := "http://" + localHost(addr)
+	fmt.Printf("Sharing %s (read-only) at\n\n  %s\n\n", fs.Arg(0), server.URL(base))
+	if opts.tunnel != "" {
+		if err := startTunnel(ctx, opts.tunnel, addr.Port, base); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Add ?token=%s to the tunnel's URL.\n", server.Token())
+	}
+	fmt.Printf("Press Ctrl+C to stop sharing.\n")
+
+	select {
+	case err := <-served:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// Event streams never finish on their own, so they are cut off
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	_ = httpServer.Close()
+	return 0
+}
+
+// localHost returns addr as a browser on this machine reaches it, naming an
+// unspecified address such as 0.0.0.0 as localhost
+func localHost(addr *net.TCPAddr) string {
+	if addr.IP.IsUnspecified() {
+		return net.JoinHostPort("localhost", strconv.Itoa(addr.Port))
+	}
+	return addr.String()
+}
+
+// startTunnel runs the tunnel command in the background, with {port} and
+// {url} replaced by the view's port and base URL, until ctx is done. Its
+// output is passed through, since tunnels print the public URL.
+func startTunnel(ctx context.Context, command string, port int, base string) error {
+	command = strings.NewReplacer("{port}", strconv.Itoa(port), "{url}", base).Replace(command)
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "FORGE_SHARE_PORT="+strconv.Itoa(port), "FORGE_SHARE_URL="+base)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start tunnel: %w", err)
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Tunnel stopped: %v\n", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/ci"
+	"github.com/entrhq/forge/pkg/tools/clipboard"
+	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/tools/issues"
+	"github.com/entrhq/forge/pkg/tools/security"
+	"github.com/entrhq/forge/pkg/tools/snippets"
+)
+
+// registerTools registers the built-in tools with ag, returning the issue
+// tracker get_issue and search_issues read from, nil if there is none.
+// auditLog backs get_recent_commands and may be nil.
+func registerTools(ctx context.Context, ag *agent.DefaultAgent, config *Config, guard *workspace.Guard, tracker *git.ModificationTracker, auditLog *audit.Log) (issues.Tracker, error) {
+	if err := registerCodingTools(ag, config, guard, auditLog); err != nil {
+		return nil, err
+	}
+
+	sessionTools := []tools.Tool{coding.NewSessionChangesTool(tracker), clipboard.NewCopyToClipboardTool()}
+	if dirs := snippetDirs(config.WorkspaceDir, config.Trusted); dirs != nil {
+		sessionTools = append(sessionTools, snippets.NewGetSnippetTool(dirs...))
+	}
+
+	issueTracker, err := newIssueTracker(ctx, config.WorkspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure issue tracker: %w", err)
+	}
+	if issueTracker != nil {
+		sessionTools = append(sessionTools, issues.NewGetIssueTool(issueTracker), issues.NewSearchIssuesTool(issueTracker))
+	}
+
+	ciProvider, err := newCIProvider(ctx, config.WorkspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure CI logs: %w", err)
+	}
+	if ciProvider != nil {
+		sessionTools = append(sessionTools, ci.NewFetchCILogsTool(ciProvider, config.WorkspaceDir))
+	}
+
+	for _, tool := range sessionTools {
+		if err := ag.RegisterTool(tool, agent.WithToolTimeout(fileToolTimeout)); err != nil {
+			return nil, fmt.Errorf("failed to register tool: %w", err)
+		}
+	}
+	return issueTracker, nil
+}
+
+// registerCodingTools registers the file and command tools. Filesystem tools
+// get an execution timeout so a hung call can't stall the agent loop;
+// execute_command, audit_workspace and check_licenses enforce their own
+// timeouts. An untrusted workspace gets only the tools that read.
+func registerCodingTools(ag *agent.DefaultAgent, config *Config, guard *workspace.Guard, auditLog *audit.Log) error {
+	codingTools := []tools.Tool{
+		coding.NewReadFileTool(guard),
+		coding.NewRequestContextTool(guard),
+		coding.NewListFilesTool(guard),
+		coding.NewSearchFilesTool(guard),
+		coding.NewChangedPackagesTool(guard),
+	}
+	policy, err := licensePolicy()
+	if err != nil {
+		return fmt.Errorf("invalid licenses config: %w", err)
+	}
+	if config.Trusted {
+		var editOpts []coding.EditOption
+		if section := appconfig.GetSyntaxCheck(); section != nil {
+			editOpts = append(editOpts, coding.WithSyntaxCheck(coding.SyntaxCheck(section.Mode())))
+		}
+		drafts := coding.NewFileDrafts()
+		codingTools = append(codingTools, coding.NewWriteFileTool(guard, editOpts...), coding.NewApplyDiffTool(guard, editOpts...), coding.NewGenerateDocsTool(guard),
+			coding.NewInsertLicenseHeadersTool(guard, policy),
+			coding.NewBeginFileTool(guard, drafts), coding.NewAppendChunkTool(guard, drafts), coding.NewEndFileTool(guard, drafts, editOpts...))
+	}
+
+	for _, tool := range codingTools {
+		if err := ag.RegisterTool(tool, agent.WithToolTimeout(fileToolTimeout)); err != nil {
+			return fmt.Errorf("failed to register tool: %w", err)
+		}
+	}
+	if !config.Trusted {
+		return nil
+	}
+
+	if err := ag.RegisterTool(coding.NewExecuteCommandTool(guard)); err != nil {
+		return fmt.Errorf("failed to register tool: %w", err)
+	}
+	if auditLog != nil {
+		if err := ag.RegisterTool(coding.NewGetRecentCommandsTool(auditLog), agent.WithToolTimeout(fileToolTimeout)); err != nil {
+			return fmt.Errorf("failed to register tool: %w", err)
+		}
+	}
+	// The scanners run the workspace's build tooling, so they need trust too
+	if err := ag.RegisterTool(security.NewAuditWorkspaceTool(config.WorkspaceDir)); err != nil {
+		return fmt.Errorf("failed to register tool: %w", err)
+	}
+	if err := ag.RegisterTool(coding.NewCheckLicensesTool(guard, policy)); err != nil {
+		return fmt.Errorf("failed to register tool: %w", err)
+	}
+	return nil
+}
+
+// licensePolicy returns the policy of check_licenses and
+// insert_license_headers from the licenses section
+func licensePolicy() (coding.LicensePolicy, error) {
+	section := appconfig.GetLicenses()
+	if section == nil {
+		return coding.LicensePolicy{}, nil
+	}
+	if err := section.Validate(); err != nil {
+		return coding.LicensePolicy{}, err
+	}
+	return coding.LicensePolicy{
+		Header:  section.Header(),
+		Owner:   section.Owner(),
+		Exclude: section.Exclude(),
+		Allowed: section.Allowed(),
+	}, nil
+}
+
+// snippetDirs returns the directories get_snippet reads, the workspace's
+// first, or nil when neither has snippets, so the tool is only offered when
+// there is something to get. As with workflows, an untrusted workspace's
+// snippets are ignored.
+func snippetDirs(workspaceDir string, trusted bool) []string {
+	var dirs []string
+	if trusted {
+		dirs = append(dirs, filepath.Join(workspaceDir, snippets.Dir))
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, snippets.Dir))
+	}
+	found, err := snippets.List(dirs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: snippets unavailable: %v\n", err)
+		return nil
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return dirs
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// ReadOnlyWorkspace tells the agent it is working in an untrusted workspace.
+const ReadOnlyWorkspace = `
+# Read-Only Workspace
+
+The user has not trusted this workspace, so you can read and search its files but cannot edit them or run commands. Answer questions and propose changes as diffs in your reply instead of applying them. If a task needs edits or commands, tell the user they can restart Forge with -trust to allow them.
+`
+
+// resolveTrust decides whether the workspace may be edited and run commands
+// in. An explicit -trust or -trust=false is recorded; otherwise the stored
+// decision is used, and without one the user is asked. When nobody can be
+// asked, because stdin is not a terminal, the workspace is untrusted for
+// this session only.
+func (c *Config) resolveTrust(fs *flag.FlagSet) error {
+	if _, err := os.Stat(c.WorkspaceDir); err != nil {
+		return nil // Reported by validate
+	}
+
+	storePath, err := workspace.DefaultTrustStorePath()
+	if err != nil {
+		return err
+	}
+	store, err := workspace.LoadTrustStore(storePath)
+	if err != nil {
+		return err
+	}
+
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "trust"
+	})
+	if explicit {
+		c.Trusted = c.Trust
+		return store.Set(c.WorkspaceDir, c.Trusted)
+	}
+
+	if decision, ok := store.Lookup(c.WorkspaceDir); ok {
+		c.Trusted = decision.Trusted
+		return nil
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprintf(os.Stderr, "Workspace %s is not trusted; starting in read-only mode. Use -trust to trust it.\n", displayDir(c.WorkspaceDir))
+		return nil
+	}
+
+	trusted, answered := askTrust(c.WorkspaceDir)
+	c.Trusted = trusted
+	if !answered {
+		return nil
+	}
+	return store.Set(c.WorkspaceDir, trusted)
+}
+
+// askTrust asks the user on the terminal whether to trust dir, reporting
+// false without an answer if input ends first
+func askTrust(dir string) (trusted, answered bool) {
+	fmt.Printf("Do you trust the files in %s?\n\n", displayDir(dir))
+	fmt.Println("Forge can edit files and run commands in a trusted workspace, and applies")
+	fmt.Println("its .forge/config.yaml. A repository you don't know could use these to run")
+	fmt.Println("code on your machine. An untrusted workspace starts in read-only mode: the")
+	fmt.Println("agent can read and search files but not edit them or run commands, and the")
+	fmt.Println("project config is ignored.")
+	fmt.Println()
+	fmt.Print("Trust this workspace? [y/N] ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return false, false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", true
+}
+
+// displayDir returns dir as an absolute path for messages
+func displayDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
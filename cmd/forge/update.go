@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/update"
+)
+
+// releasePublicKey is the base64 Ed25519 key that release checksums are
+// signed with. Release builds set it with -ldflags "-X main.releasePublicKey=...";
+// when empty, forge update refuses to install unless -allow-unsigned is given.
+var releasePublicKey string
+
+// updateFlags are the options of forge update
+type updateFlags struct {
+	check         bool
+	channel       string
+	allowUnsigned bool
+}
+
+// newUpdateFlags defines the forge update flags
+func newUpdateFlags(opts *updateFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	fs.BoolVar(&opts.check, "check", false, "Only report whether an update is available")
+	fs.StringVar(&opts.channel, "channel", "", "Release channel: stable or beta (overrides updates.channel in config)")
+	fs.BoolVar(&opts.allowUnsigned, "allow-unsigned", false, "Install a release checked against its checksums alone when this build has no release public key")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge update [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Downloads the newest release, verifies it and replaces this binary.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runUpdate implements `forge update`: it installs the newest release on the
+// configured channel over the running binary and returns the process exit code
+func runUpdate(args []string) int {
+	opts := &updateFlags{}
+	_ = newUpdateFlags(opts).Parse(args) // ExitOnError exits on invalid flags
+
+	if opts.channel == "" {
+		opts.channel = appconfig.ChannelStable
+		if err := appconfig.Initialize(""); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to load configuration, using the stable channel: %v\n", err)
+		} else if updates := appconfig.GetUpdates(); updates != nil {
+			opts.channel = updates.Channel()
+		}
+	}
+	if opts.channel != appconfig.ChannelStable && opts.channel != appconfig.ChannelBeta {
+		fmt.Fprintf(os.Stderr, "Unknown channel %q: use stable or beta\n", opts.channel)
+		return 2
+	}
+
+	var updaterOpts []update.Option
+	switch {
+	case releasePublicKey != "":
+		key, err := update.ParsePublicKey(releasePublicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid release public key in this build: %v\n", err)
+			return 1
+		}
+		updaterOpts = append(updaterOpts, update.WithPublicKey(key))
+	case opts.allowUnsigned:
+		updaterOpts = append(updaterOpts, update.WithoutSignature())
+	case !opts.check:
+		fmt.Fprintf(os.Stderr, "This build has no release public key, so forge update can't verify who published a release.\n")
+		fmt.Fprintf(os.Stderr, "Install an official release, or run forge update -allow-unsigned to trust the release checksums alone.\n")
+		return 1
+	}
+	updater := update.NewUpdater(updaterOpts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	release, err := updater.Latest(ctx, opts.channel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Update check failed: %v\n", err)
+		return 1
+	}
+	if update.CompareVersions(release.TagName, version) <= 0 {
+		fmt.Printf("Forge v%s is up to date (%s channel).\n", version, opts.channel)
+		return 0
+	}
+
+	fmt.Printf("Forge %s is available (installed: v%s, %s channel)\n", release.TagName, version, opts.channel)
+	if release.HTMLURL != "" {
+		fmt.Printf("Release notes: %s\n", release.HTMLURL)
+	}
+	if opts.check {
+		return 0
+	}
+
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot locate the running binary: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Downloading and verifying %s...\n", update.BinaryAssetName(runtime.GOOS, runtime.GOARCH))
+	if err := updater.Apply(ctx, release, executable); err != nil {
+		fmt.Fprintf(os.Stderr, "Update failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Updated %s to %s. Restart forge to use it.\n", executable, release.TagName)
+	return 0
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/watch"
+	"github.com/entrhq/forge/pkg/workflow"
+)
+
+// watchFlags are the options of forge watch beyond the session flags
+type watchFlags struct {
+	path     string
+	ref      string
+	fetch    bool
+	interval time.Duration
+	settle   time.Duration
+	workflow string
+}
+
+// newWatchFlags defines the forge watch flags: the session flags of forge
+// run plus what to watch and what to run
+func newWatchFlags(config *Config, opts *watchFlags, inputs inputValues) *flag.FlagSet {
+	fs := newChatFlags("watch", config)
+	fs.StringVar(&opts.path, "path", "", "Workspace-relative file or directory to watch")
+	fs.StringVar(&opts.ref, "ref", "", "Git ref to watch, e.g. main or origin/main")
+	fs.BoolVar(&opts.fetch, "fetch", false, "Run git fetch before each check of -ref, to see changes pushed upstream")
+	fs.DurationVar(&opts.interval, "interval", watch.DefaultInterval, "How often to check for changes")
+	fs.DurationVar(&opts.settle, "settle", watch.DefaultSettle, "How long a change must stay unchanged before it triggers a run")
+	fs.StringVar(&opts.workflow, "workflow", "", "Workflow to run on each change instead of a prompt")
+	fs.Var(inputs, "set", "Set a workflow input as name=value (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge watch -path PATH|-ref REF [options] \"prompt\"\n")
+		fmt.Fprintf(os.Stderr, "       forge watch -path PATH|-ref REF -workflow NAME [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Watches a path or git ref in the workspace and, each time it changes, runs one\n")
+		fmt.Fprintf(os.Stderr, "agent turn on the prompt or runs the workflow without asking before each step.\n")
+		fmt.Fprintf(os.Stderr, "Runs don't overlap, and a run is postponed while another Forge session holds\n")
+		fmt.Fprintf(os.Stderr, "the workspace lock. Progress is logged to stderr, without timestamps under\n")
+		fmt.Fprintf(os.Stderr, "systemd. Tool approvals are read from stdin; without one, only tools the\n")
+		fmt.Fprintf(os.Stderr, "auto-approval config allows can run.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge watch -trust -path pkg/api \"update docs/api.md for the changes in pkg/api\"\n")
+		fmt.Fprintf(os.Stderr, "  forge watch -trust -ref origin/main -fetch -interval 5m -workflow release-prep -set version=next\n")
+	}
+	return fs
+}
+
+// runWatch implements `forge watch` and returns the process exit code. It
+// runs until interrupted or terminated.
+func runWatch(args []string) int {
+	config := &Config{}
+	opts := &watchFlags{}
+	inputs := inputValues{}
+	fs := newWatchFlags(config, opts, inputs)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	prompt := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	switch {
+	case opts.path == "" && opts.ref == "":
+		fmt.Fprintf(os.Stderr, "forge watch: -path or -ref is required\n")
+		return 2
+	case (prompt == "") == (opts.workflow == ""):
+		fmt.Fprintf(os.Stderr, "forge watch: give either a prompt or -workflow\n")
+		return 2
+	case opts.interval <= 0:
+		fmt.Fprintf(os.Stderr, "forge watch: -interval must be positive\n")
+		return 2
+	}
+
+	if err := config.resolveTrust(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Workspace trust error: %v\n", err)
+		return 1
+	}
+	// An untrusted workspace's project config could enable hooks or auto-approval
+	if config.Trusted {
+		if err := config.applyProject(fs); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return 1
+		}
+	}
+	if err := config.applyPreset(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	if err := config.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	config.Prompt = prompt
+	if opts.workflow != "" {
+		dirs, err := workflowDirs(config.WorkspaceDir, config.Trusted)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		w, err := workflow.Find(opts.workflow, dirs...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		if config.WorkflowInputs, err = w.Bind(inputs); err != nil {
+			fmt.Fprintf(os.Stderr, "%v (use -set name=value)\n", err)
+			return 2
+		}
+		config.Workflow = w
+		config.AssumeYes = true // Nobody is there to confirm each step
+	}
+
+	var sources []watch.Source
+	if opts.path != "" {
+		sources = append(sources, watch.NewPathSource(config.WorkspaceDir, opts.path))
+	}
+	if opts.ref != "" {
+		sources = append(sources, watch.NewRefSource(config.WorkspaceDir, opts.ref, opts.fetch))
+	}
+	logger := newWatchLogger()
+	watcher := watch.NewWatcher(sources,
+		watch.WithInterval(opts.interval),
+		watch.WithSettle(opts.settle),
+		watch.WithLogger(logger),
+	)
+
+	// systemd stops services with SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := watcher.Run(ctx, func(ctx context.Context, change watch.Change) error {
+		runConfig := *config
+		err := run(ctx, &runConfig)
+		var locked *workspace.LockedError
+		if errors.As(err, &locked) {
+			return fmt.Errorf("%w: %v", watch.ErrBusy, locked)
+		}
+		return err
+	})
+	if err != nil {
+		logger.Printf("Failed to watch: %v", err)
+		return 1
+	}
+	logger.Printf("Stopped")
+	return 0
+}
+
+// newWatchLogger logs to stderr with timestamps, except under systemd, where
+// the journal records its own
+func newWatchLogger() *log.Logger {
+	flags := log.LstdFlags
+	if os.Getenv("JOURNAL_STREAM") != "" {
+		flags = 0
+	}
+	return log.New(os.Stderr, "forge watch: ", flags)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/executor/cli"
+	"github.com/entrhq/forge/pkg/workflow"
+)
+
+// inputValues collects repeated -set name=value flags
+type inputValues map[string]string
+
+func (v inputValues) String() string {
+	pairs := make([]string, 0, len(v))
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v inputValues) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// newWorkflowFlags defines the forge workflow flags: the session flags of
+// forge run plus the workflow's inputs
+func newWorkflowFlags(config *Config, inputs inputValues) *flag.FlagSet {
+	fs := newChatFlags("workflow", config)
+	fs.Var(inputs, "set", "Set a workflow input as name=value (repeatable)")
+	fs.BoolVar(&config.AssumeYes, "yes", false, "Run each step without asking first")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: forge workflow run NAME [options]\n")
+		fmt.Fprintf(os.Stderr, "       forge workflow list [-workspace dir]\n\n")
+		fmt.Fprintf(os.Stderr, "Runs a workflow from %s in the workspace or your home directory as a\n", workflow.Dir)
+		fmt.Fprintf(os.Stderr, "sequence of agent turns with plain-text output. Each step is shown and\n")
+		fmt.Fprintf(os.Stderr, "confirmed before it runs, tool approvals are read from stdin, and the run\n")
+		fmt.Fprintf(os.Stderr, "stops at the first step whose success criteria don't hold. The workspace's\n")
+		fmt.Fprintf(os.Stderr, "own workflows are only used when it is trusted.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  forge workflow list\n")
+		fmt.Fprintf(os.Stderr, "  forge workflow run release-prep -set version=1.4.0\n")
+	}
+	return fs
+}
+
+// runWorkflow implements `forge workflow run|list` and returns the process
+// exit code
+func runWorkflow(args []string) int {
+	config := &Config{}
+	inputs := inputValues{}
+	fs := newWorkflowFlags(config, inputs)
+	_ = fs.Parse(args) // ExitOnError exits on invalid flags
+
+	// Options may come before or after the action and the workflow name
+	var positional []string
+	for fs.NArg() > 0 {
+		positional = append(positional, fs.Arg(0))
+		_ = fs.Parse(fs.Args()[1:])
+	}
+
+	switch {
+	case len(positional) == 1 && positional[0] == "list":
+		return listWorkflows(config.WorkspaceDir)
+	case len(positional) == 2 && positional[0] == "run":
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	if err := config.resolveTrust(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Workspace trust error: %v\n", err)
+		return 1
+	}
+	// An untrusted workspace's project config could enable hooks or
+	// auto-approval, and its workflows could run commands in their criteria
+	if config.Trusted {
+		if err := config.applyProject(fs); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			return 1
+		}
+	}
+	if err := config.applyPreset(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+
+	dirs, err := workflowDirs(config.WorkspaceDir, config.Trusted)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	w, err := workflow.Find(positional[1], dirs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if config.WorkflowInputs, err = w.Bind(inputs); err != nil {
+		fmt.Fprintf(os.Stderr, "%v (use -set name=value)\n", err)
+		return 2
+	}
+	config.Workflow = w
+	return execute(config)
+}
+
+// listWorkflows prints the workflows available in workspaceDir
+func listWorkflows(workspaceDir string) int {
+	dirs, err := workflowDirs(workspaceDir, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	workflows, err := workflow.List(dirs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if len(workflows) == 0 {
+		fmt.Printf("No workflows in %s\n", strings.Join(dirs, " or "))
+	}
+	for _, w := range workflows {
+		fmt.Printf("%-20s %s\n", w.Name, w.Description)
+		fmt.Printf("%-20s %d steps, %s\n", "", len(w.Steps), w.Path)
+	}
+	return listAgents(workspaceDir)
+}
+
+// listAgents prints the agent definitions steps can name in workspaceDir
+func listAgents(workspaceDir string) int {
+	dirs, err := agentDirs(workspaceDir, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defs, err := workflow.ListAgents(dirs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	fmt.Printf("\nAgents:\n")
+	for _, def := range defs {
+		source := def.Path
+		if source == "" {
+			source = "built-in"
+		}
+		fmt.Printf("%-20s %s\n", def.Name, def.Description)
+		fmt.Printf("%-20s %s\n", "", source)
+	}
+	return 0
+}
+
+// workflowDirs returns the directories workflows are read from, the
+// workspace's first. An untrusted workspace's workflows are left out.
+func workflowDirs(workspaceDir string, trusted bool) ([]string, error) {
+	userDir, err := userWorkflowDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{userDir}
+	if trusted {
+		dirs = append([]string{filepath.Join(workspaceDir, workflow.Dir)}, dirs...)
+	}
+	return dirs, nil
+}
+
+// agentDirs returns the directories agent definitions are read from, the
+// workspace's first. An untrusted workspace's definitions are left out.
+func agentDirs(workspaceDir string, trusted bool) ([]string, error) {
+	userDir, err := userAgentDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{userDir}
+	if trusted {
+		dirs = append([]string{filepath.Join(workspaceDir, workflow.AgentsDir)}, dirs...)
+	}
+	return dirs, nil
+}
+
+// workflowExecutor runs a workflow with the CLI executor's plain-text output
+// and prompts
+type workflowExecutor struct {
+	agent    *agent.DefaultAgent
+	runner   *workflow.Runner
+	workflow *workflow.Workflow
+	inputs   map[string]string
+	out      io.Writer
+}
+
+// newWorkflowExecutor creates the executor for config.Workflow
+func newWorkflowExecutor(ag *agent.DefaultAgent, config *Config) (*workflowExecutor, error) {
+	dirs, err := agentDirs(config.WorkspaceDir, config.Trusted)
+	if err != nil {
+		return nil, err
+	}
+	display := cli.NewExecutor(ag, cli.WithAccessible(config.Accessible))
+	opts := []workflow.Option{
+		workflow.WithAgentDirs(dirs...),
+		workflow.WithToolApprover(display.PromptApproval),
+		workflow.WithEventHandler(display.RenderEvent),
+		workflow.WithProgress(os.Stdout),
+	}
+	if !config.AssumeYes {
+		opts = append(opts, workflow.WithStepApprover(func(step workflow.Step) bool {
+			fmt.Printf("\nNext step: %s\n", step.Name)
+			if step.Agent != "" {
+				fmt.Printf("Agent: %s\n", step.Agent)
+			}
+			fmt.Printf("%s\n", step.Prompt)
+			if len(step.Tools) > 0 {
+				fmt.Printf("Tools: %s\n", strings.Join(step.Tools, ", "))
+			}
+			return display.Confirm("Run this step?")
+		}))
+	}
+	return &workflowExecutor{
+		agent:    ag,
+		runner:   workflow.NewRunner(config.WorkspaceDir, opts...),
+		workflow: config.Workflow,
+		inputs:   config.WorkflowInputs,
+		out:      os.Stdout,
+	}, nil
+}
+
+// Run runs the workflow and prints a summary of its steps
+func (e *workflowExecutor) Run(ctx context.Context) error {
+	fmt.Fprintf(e.out, "Workflow: %s (%d steps)\n", e.workflow.Name, len(e.workflow.Steps))
+	result, err := e.runner.Run(ctx, e.agent, e.workflow, e.inputs)
+
+	fmt.Fprintf(e.out, "\nWorkflow %s summary:\n", e.workflow.Name)
+	for _, step := range result.Steps {
+		status := "PASS"
+		if !step.Passed {
+			status = "FAIL"
+		}
+		name := step.Name
+		if step.Agent != "" {
+			name += " by " + step.Agent
+		}
+		fmt.Fprintf(e.out, "  %s %s (%s)\n", status, name, step.Duration.Round(time.Second))
+		for _, check := range step.Checks {
+			if !check.Passed {
+				fmt.Fprintf(e.out, "       %s: %s\n", check.Criterion, check.Detail)
+			}
+		}
+	}
+	if skipped := len(e.workflow.Steps) - len(result.Steps); skipped > 0 {
+		fmt.Fprintf(e.out, "  %d steps not run\n", skipped)
+	}
+	return err
+}
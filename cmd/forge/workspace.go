@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// claimWorkspace locks the workspace so two sessions don't edit it at once,
+// then checks it for uncommitted work the agent's edits could mix with. The
+// caller releases the lock.
+func claimWorkspace(ctx context.Context, config *Config) (*workspace.Lock, error) {
+	lock, err := workspace.AcquireLock(config.WorkspaceDir, config.IgnoreLock)
+	if err != nil {
+		var locked *workspace.LockedError
+		if errors.As(err, &locked) {
+			return nil, fmt.Errorf("%w; exit it or use -ignore-lock to start anyway", err)
+		}
+		return nil, fmt.Errorf("failed to lock workspace: %w", err)
+	}
+
+	if err := checkDirtyWorkspace(ctx, config); err != nil {
+		lock.Release()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// newGuard creates the security guard keeping the tools inside the
+// workspace and away from the project's ignored files
+func newGuard(config *Config) (*workspace.Guard, error) {
+	guard, err := workspace.NewGuard(config.WorkspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace guard: %w", err)
+	}
+	if config.Project != nil {
+		guard.AddIgnorePatterns(config.Project.Ignore)
+	}
+	return guard, nil
+}
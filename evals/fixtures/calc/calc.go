@@ -0,0 +1,10 @@
+package calc
+
+// Sum returns the sum of values
+func Sum(values []int) int {
+	total := 0
+	for i := 1; i < len(values); i++ {
+		total += values[i]
+	}
+	return total
+}
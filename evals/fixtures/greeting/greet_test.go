@@ -0,0 +1,9 @@
+package greeting
+
+import "testing"
+
+func TestGreet(t *testing.T) {
+	if got := Greet("Ada"); got != "Hello, Ada!" {
+		t.Errorf("Greet(\"Ada\") = %q, want %q", got, "Hello, Ada!")
+	}
+}
@@ -48,6 +48,11 @@ type Agent interface {
 	// The executor uses these channels to send input and receive output.
 	GetChannels() *types.AgentChannels
 
+	// Subscriptions returns the agent's event subscriptions. Every event sent
+	// on the event channel is first published to them; use Subscribe to
+	// register a handler for one event type.
+	Subscriptions() *types.Subscriptions
+
 	// GetTool retrieves a specific tool by name from the agent's tool registry.
 	// Returns nil if the tool is not found.
 	GetTool(name string) interface{}
@@ -61,11 +66,25 @@ type Agent interface {
 	GetContextInfo() *ContextInfo
 }
 
+// Subscribe registers handler for the typed payload of ag's events of one
+// kind, for example:
+//
+//	unsubscribe := agent.Subscribe(ag, types.EventToolCall, func(p types.ToolCallPayload) {
+//		log.Printf("calling %s", p.ToolName)
+//	})
+//
+// The handler runs on the agent's goroutines before the event is sent on the
+// event channel, so it must return quickly. The channel API is unaffected.
+func Subscribe[P any](ag Agent, kind types.EventKind[P], handler func(P)) (unsubscribe func()) {
+	return types.Subscribe(ag.Subscriptions(), kind, handler)
+}
+
 // ContextInfo contains detailed agent context statistics
 type ContextInfo struct {
 	// System prompt
 	SystemPromptTokens int
 	CustomInstructions bool
+	BasePromptVersion  string
 
 	// Tool system
 	ToolCount  int
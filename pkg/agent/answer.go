@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/core"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// NoToolCallPolicy is what the agent does with a response that has no tool
+// call. Models that sometimes just answer in prose would otherwise spend
+// iterations being told to resend, with a tool, a reply that was complete.
+type NoToolCallPolicy string
+
+const (
+	NoToolCallError    NoToolCallPolicy = "error"    // Tell the model to retry with a tool call (the default)
+	NoToolCallConverse NoToolCallPolicy = "converse" // End the turn with a reply that looks like a complete answer, as if sent with converse
+	NoToolCallAsk      NoToolCallPolicy = "ask"      // Ask the user whether to end the turn with such a reply
+)
+
+// ParseNoToolCallPolicy returns the policy named s
+func ParseNoToolCallPolicy(s string) (NoToolCallPolicy, error) {
+	switch policy := NoToolCallPolicy(s); policy {
+	case NoToolCallError, NoToolCallConverse, NoToolCallAsk:
+		return policy, nil
+	}
+	return "", fmt.Errorf("policy must be %q, %q or %q, got %q", NoToolCallError, NoToolCallConverse, NoToolCallAsk, s)
+}
+
+// WithNoToolCallPolicy sets what the agent does with a response that has no
+// tool call. Only replies that look like a complete answer are accepted;
+// anything else, such as a reply announcing what the model will do next,
+// is still sent back with the no tool call recovery message.
+func WithNoToolCallPolicy(policy NoToolCallPolicy) AgentOption {
+	return func(a *DefaultAgent) {
+		a.noToolCallPolicy = policy
+	}
+}
+
+// announcement matches a sentence in which the model says what it is about
+// to do rather than answering
+var announcement = regexp.MustCompile(`^(?:(?:ok|okay|alright|great|now|next|first|then|so),?\s+)*` +
+	`(?:let me|let's|i'll|i will|i'm going to|i am going to|i need to|i should|i'm now|i will now)\b`)
+
+// looksLikeAnswer reports whether content, a response without a tool call,
+// reads as a complete reply to the user: it isn't empty, isn't a garbled
+// tool call, and doesn't end by announcing more work ("Let me check the
+// tests.") or something that was meant to follow ("Here are the changes:").
+func looksLikeAnswer(content string) bool {
+	text := strings.TrimSpace(content)
+	if text == "" || strings.Contains(text, "<tool") || strings.Contains(text, "tool_name") {
+		return false
+	}
+	if strings.HasSuffix(text, ":") || strings.HasSuffix(text, "...") || strings.HasSuffix(text, "…") {
+		return false
+	}
+	return !announcement.MatchString(lastSentence(text))
+}
+
+// lastSentence returns the last sentence of text's last line, lowercased and
+// with curly apostrophes straightened
+func lastSentence(text string) string {
+	text = strings.ToLower(strings.ReplaceAll(text, "’", "'"))
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:]
+	}
+	text = strings.TrimRight(strings.TrimSpace(text), ".!?")
+	if i := strings.LastIndexAny(text, ".!?"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimLeft(text, " \t*-#>")
+}
+
+// acceptAnswer applies the no tool call policy to a response without a tool
+// call, and reports whether it ends the turn. An accepted reply is rewritten
+// as a converse call before it is recorded, so the history shows the model
+// the format it should have used, and the converse call and result events
+// are emitted. It was shown to the user as it streamed.
+func (a *DefaultAgent) acceptAnswer(ctx context.Context, resp *llmResponse) bool {
+	if a.noToolCallPolicy != NoToolCallConverse && a.noToolCallPolicy != NoToolCallAsk {
+		return false
+	}
+	if ctx.Err() != nil || !looksLikeAnswer(resp.assistantContent) {
+		return false
+	}
+
+	answer := strings.TrimSpace(resp.assistantContent)
+	content := "<server_name>local</server_name><tool_name>converse</tool_name><arguments><message><![CDATA[" +
+		strings.ReplaceAll(answer, "]]>", "]]]]><![CDATA[>") + "]]></message></arguments>"
+
+	call, _, err := tools.ParseToolCall("<tool>" + content + "</tool>")
+	if err != nil {
+		return false
+	}
+	if a.noToolCallPolicy == NoToolCallAsk {
+		approved, _, _ := a.requestApproval(ctx, *call, &tools.ToolPreview{
+			Title:       "End the turn with this reply",
+			Description: "The model replied without calling a tool. Approve to accept the reply as its answer, or reject to have it retry with a tool call.",
+			Content:     answer,
+		})
+		if !approved {
+			return false
+		}
+	}
+
+	agentDebugLog.Printf("Accepted a response without a tool call as the answer (%s policy)", a.noToolCallPolicy)
+	resp.assistantContent = ""
+	resp.toolCalls = []string{content}
+	a.resetErrorTracking()
+	if a.turn != nil {
+		a.turn.outcome = "replied: " + answer
+	}
+
+	// Report the reply as a converse call and result, so executors end the
+	// turn with it as they would a real one
+	a.emitEvent(types.NewToolCallEvent(call.ToolName, toolArguments(*call)))
+	a.emitEvent(core.NewStreamedReplyEvent(call.ToolName, tools.TextResult(answer)))
+	return true
+}
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/core"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestLooksLikeAnswer(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"Hi! How can I help with the project today?", true},
+		{"The parser lives in pkg/llm/parser. It splits thinking from tool calls as the response streams.", true},
+		{"Go's `defer` runs when the function returns:\n\n```go\ndefer f.Close()\n```", true},
+		{"- `forge run` runs one task\n- `forge share` serves a live view", true},
+		{"I'll keep this short. The fix is in parser.go.", true}, // Only the last sentence counts
+		{"", false},
+		{"   \n", false},
+		{"The tests fail. Let me look at the parser.", false},
+		{"I found the bug.\n\nNow I'll fix it.", false},
+		{"Okay, I'm going to read the config first", false},
+		{"I’ll check the logs.", false},
+		{"Here is the plan:", false},
+		{"Checking the files...", false},
+		{"<tool_name>read_file</tool_name>", false},
+		{"<tool>\n<server_name>local</server_name>", false},
+	}
+	for _, tt := range tests {
+		if got := looksLikeAnswer(tt.content); got != tt.want {
+			t.Errorf("looksLikeAnswer(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestParseNoToolCallPolicy(t *testing.T) {
+	for _, s := range []string{"error", "converse", "ask"} {
+		if policy, err := ParseNoToolCallPolicy(s); err != nil || string(policy) != s {
+			t.Errorf("ParseNoToolCallPolicy(%q) = %q, %v", s, policy, err)
+		}
+	}
+	if _, err := ParseNoToolCallPolicy("wrap"); err == nil {
+		t.Error("expected an unknown policy to be rejected")
+	}
+}
+
+func TestAcceptAnswer_Converse(t *testing.T) {
+	a, collected := newRunnerTestAgent(WithNoToolCallPolicy(NoToolCallConverse))
+	a.turn = &turnRecord{}
+
+	resp := &llmResponse{assistantContent: "Use `]]>` to end a CDATA section.\n"}
+	if !a.acceptAnswer(context.Background(), resp) {
+		t.Fatal("expected the answer to be accepted")
+	}
+	if resp.assistantContent != "" || len(resp.toolCalls) != 1 {
+		t.Fatalf("expected the answer to become a tool call, got %+v", resp)
+	}
+	call, _, err := tools.ParseToolCall("<tool>" + resp.toolCalls[0] + "</tool>")
+	if err != nil {
+		t.Fatalf("expected a valid tool call, got %v", err)
+	}
+	msg, err := tools.NewConverseTool().Execute(context.Background(), call.GetArgumentsXML())
+	if call.ToolName != "converse" || err != nil || msg != "Use `]]>` to end a CDATA section." {
+		t.Errorf("expected a converse call with the answer, got %s %q %v", call.ToolName, msg, err)
+	}
+	if a.turn.outcome != "replied: Use `]]>` to end a CDATA section." {
+		t.Errorf("expected the turn's outcome to be the reply, got %q", a.turn.outcome)
+	}
+
+	var result *types.AgentEvent
+	for deadline := time.Now().Add(time.Second); result == nil && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		for _, ev := range collected() {
+			if ev.Type == types.EventTypeToolResult {
+				result = ev
+			}
+		}
+	}
+	if result == nil {
+		t.Fatal("expected a tool result event for the accepted answer")
+	}
+	if output, ok := result.ToolOutput.(*tools.Result); result.ToolName != "converse" || !ok || output.Output != "Use `]]>` to end a CDATA section." {
+		t.Errorf("expected a converse result with the answer, got %s %v", result.ToolName, result.ToolOutput)
+	}
+	if !core.StreamedReply(result) {
+		t.Error("expected the result to be marked as already streamed")
+	}
+
+	resp = &llmResponse{assistantContent: "Let me check the tests."}
+	if a.acceptAnswer(context.Background(), resp) || resp.assistantContent == "" {
+		t.Error("expected an announcement to be left for error recovery")
+	}
+}
+
+func TestAcceptAnswer_Error(t *testing.T) {
+	for _, a := range []*DefaultAgent{NewDefaultAgent(&mockProvider{}), NewDefaultAgent(&mockProvider{}, WithNoToolCallPolicy(NoToolCallError))} {
+		if a.acceptAnswer(context.Background(), &llmResponse{assistantContent: "The answer is 42."}) {
+			t.Error("expected the error policy to accept nothing")
+		}
+	}
+}
+
+func TestAcceptAnswer_Ask(t *testing.T) {
+	for _, decision := range []types.ApprovalDecision{types.ApprovalGranted, types.ApprovalRejected} {
+		a, collected := newRunnerTestAgent(WithNoToolCallPolicy(NoToolCallAsk))
+		go func() {
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				for _, ev := range collected() {
+					if ev.Type == types.EventTypeToolApprovalRequest {
+						a.handleApprovalResponse(types.NewApprovalResponse(ev.ApprovalID, decision))
+						return
+					}
+				}
+			}
+		}()
+
+		resp := &llmResponse{assistantContent: "The answer is 42."}
+		accepted := a.acceptAnswer(context.Background(), resp)
+		if accepted != (decision == types.ApprovalGranted) {
+			t.Errorf("%s: expected accepted = %v", decision, !accepted)
+		}
+		if accepted != (len(resp.toolCalls) == 1) || accepted != !strings.Contains(resp.assistantContent, "42") {
+			t.Errorf("%s: expected the response to be rewritten only when accepted, got %+v", decision, resp)
+		}
+	}
+}
@@ -14,6 +14,13 @@ func (a *DefaultAgent) handleApprovalResponse(response *types.ApprovalResponse)
 
 // handleCommandCancellation processes a command cancellation request
 func (a *DefaultAgent) handleCommandCancellation(req *types.CancellationRequest) {
+	if req.ExecutionID == types.SummarizationExecutionID {
+		if a.contextManager != nil {
+			a.contextManager.Skip()
+		}
+		return
+	}
+
 	// Look up the cancel function for this execution ID
 	if cancelFunc, ok := a.activeCommands.Load(req.ExecutionID); ok {
 		// Cancel the context (cancellation never returns an error)
@@ -26,10 +33,11 @@ func (a *DefaultAgent) handleCommandCancellation(req *types.CancellationRequest)
 }
 
 // requestApproval sends an approval request and waits for user response
-// Returns (approved, timedOut) where:
+// Returns (approved, timedOut, feedback) where:
 //   - approved: true if user approved, false if rejected
 //   - timedOut: true if the request timed out waiting for response
-func (a *DefaultAgent) requestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) (bool, bool) {
+//   - feedback: optional text the user supplied along with their decision
+func (a *DefaultAgent) requestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) (bool, bool, string) {
 	// Delegate all approval logic to the approval manager
 	return a.approvalManager.RequestApproval(ctx, toolCall, preview)
 }
@@ -9,26 +9,34 @@ import (
 // checkAutoApproval checks if the tool or command should be auto-approved
 // Returns (approved, autoApproved) where autoApproved indicates if a decision was made
 func (m *Manager) checkAutoApproval(approvalID string, toolCall tools.ToolCall, argsMap map[string]interface{}) (bool, bool) {
+	if !isAutoApproved(toolCall, argsMap) {
+		return false, false
+	}
+
+	m.emitEvent(types.NewToolApprovalGrantedEvent(approvalID, toolCall.ToolName))
+	return true, true
+}
+
+// AutoApproves reports whether a request for toolCall would currently be
+// approved without asking the user
+func (m *Manager) AutoApproves(toolCall tools.ToolCall) bool {
+	return !m.autoApprovalSuspended() && isAutoApproved(toolCall, parseToolArguments(toolCall))
+}
+
+// isAutoApproved checks the auto-approval settings for a tool call
+func isAutoApproved(toolCall tools.ToolCall, argsMap map[string]interface{}) bool {
 	// Special handling for execute_command: always check command whitelist first
 	// The execute_command tool uses a per-command whitelist, not tool-level auto-approval
 	if toolCall.ToolName == "execute_command" {
-		if m.isCommandWhitelisted(approvalID, argsMap) {
-			return true, true
-		}
-		return false, false
+		return isCommandWhitelisted(argsMap)
 	}
 
 	// For all other tools, check if tool is auto-approved
-	if config.IsToolAutoApproved(toolCall.ToolName) {
-		m.emitEvent(types.NewToolApprovalGrantedEvent(approvalID, toolCall.ToolName))
-		return true, true
-	}
-
-	return false, false
+	return config.IsToolAutoApproved(toolCall.ToolName)
 }
 
 // isCommandWhitelisted checks if a command is whitelisted
-func (m *Manager) isCommandWhitelisted(approvalID string, argsMap map[string]interface{}) bool {
+func isCommandWhitelisted(argsMap map[string]interface{}) bool {
 	cmdInterface, ok := argsMap["command"]
 	if !ok {
 		return false
@@ -39,10 +47,5 @@ func (m *Manager) isCommandWhitelisted(approvalID string, argsMap map[string]int
 		return false
 	}
 
-	if config.IsCommandWhitelisted(cmd) {
-		m.emitEvent(types.NewToolApprovalGrantedEvent(approvalID, "execute_command"))
-		return true
-	}
-
-	return false
+	return config.IsCommandWhitelisted(cmd)
 }
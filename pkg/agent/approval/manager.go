@@ -2,6 +2,7 @@ package approval
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,13 +20,13 @@ type Manager struct {
 	pendingApproval *pendingApproval
 	mu              sync.Mutex
 	emitEvent       EventEmitter
+	autoSuspended   bool // When true, every request needs explicit user approval
 }
 
 // pendingApproval tracks an approval request that is waiting for user response
 type pendingApproval struct {
 	approvalID string
 	toolName   string
-	toolCall   tools.ToolCall
 	response   chan *types.ApprovalResponse
 	closeOnce  sync.Once // Ensures channel is closed exactly once
 }
@@ -39,10 +40,11 @@ func NewManager(timeout time.Duration, emitEvent EventEmitter) *Manager {
 }
 
 // RequestApproval sends an approval request and waits for user response
-// Returns (approved, timedOut) where:
+// Returns (approved, timedOut, feedback) where:
 //   - approved: true if user approved, false if rejected
 //   - timedOut: true if the request timed out waiting for response
-func (m *Manager) RequestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) (bool, bool) {
+//   - feedback: optional text the user supplied along with their decision
+func (m *Manager) RequestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) (bool, bool, string) {
 	// Generate unique approval ID
 	approvalID := uuid.New().String()
 
@@ -50,7 +52,7 @@ func (m *Manager) RequestApproval(ctx context.Context, toolCall tools.ToolCall,
 	responseChannel := make(chan *types.ApprovalResponse, 1)
 
 	// Store pending approval
-	m.setupPendingApproval(approvalID, toolCall, responseChannel)
+	m.setupPendingApproval(approvalID, toolCall.ToolName, responseChannel)
 
 	// Clean up pending approval when done
 	defer m.cleanupPendingApproval(responseChannel)
@@ -58,16 +60,54 @@ func (m *Manager) RequestApproval(ctx context.Context, toolCall tools.ToolCall,
 	// Parse tool input for event
 	argsMap := parseToolArguments(toolCall)
 
-	// Check for auto-approval
-	if approved, autoApproved := m.checkAutoApproval(approvalID, toolCall, argsMap); autoApproved {
-		return approved, false
+	// Check for auto-approval (unless suspended)
+	if !m.autoApprovalSuspended() {
+		if approved, autoApproved := m.checkAutoApproval(approvalID, toolCall, argsMap); autoApproved {
+			return approved, false, ""
+		}
 	}
 
 	// Emit approval request event (tool requires manual approval)
 	m.emitEvent(types.NewToolApprovalRequestEvent(approvalID, toolCall.ToolName, argsMap, preview))
 
 	// Wait for response with timeout
-	return m.waitForResponse(ctx, approvalID, toolCall, responseChannel)
+	return m.waitForResponse(ctx, approvalID, toolCall.ToolName, responseChannel)
+}
+
+// RequestBatchApproval asks the user to approve several tool calls of one
+// response at once, shown together in preview. Auto-approval isn't checked:
+// callers only batch calls that need the user.
+// Returns (approved, timedOut, feedback) as RequestApproval does.
+func (m *Manager) RequestBatchApproval(ctx context.Context, toolCalls []tools.ToolCall, preview *tools.ToolPreview) (bool, bool, string) {
+	approvalID := uuid.New().String()
+	responseChannel := make(chan *types.ApprovalResponse, 1)
+
+	names := make([]string, len(toolCalls))
+	for i, toolCall := range toolCalls {
+		names[i] = toolCall.ToolName
+	}
+	toolName := strings.Join(names, ", ")
+
+	m.setupPendingApproval(approvalID, toolName, responseChannel)
+	defer m.cleanupPendingApproval(responseChannel)
+
+	m.emitEvent(types.NewToolApprovalRequestEvent(approvalID, toolName, map[string]interface{}{"tool_calls": names}, preview))
+	return m.waitForResponse(ctx, approvalID, toolName, responseChannel)
+}
+
+// SuspendAutoApproval disables (or re-enables) auto-approval and the command
+// whitelist, so every tool call that supports approval must be confirmed by the user
+func (m *Manager) SuspendAutoApproval(suspend bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.autoSuspended = suspend
+}
+
+// autoApprovalSuspended reports whether auto-approval is currently suspended
+func (m *Manager) autoApprovalSuspended() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.autoSuspended
 }
 
 // HandleResponse processes an approval response from the user
@@ -93,14 +133,13 @@ func (m *Manager) HandleResponse(response *types.ApprovalResponse) {
 }
 
 // setupPendingApproval stores the pending approval request
-func (m *Manager) setupPendingApproval(approvalID string, toolCall tools.ToolCall, responseChannel chan *types.ApprovalResponse) {
+func (m *Manager) setupPendingApproval(approvalID, toolName string, responseChannel chan *types.ApprovalResponse) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.pendingApproval = &pendingApproval{
 		approvalID: approvalID,
-		toolName:   toolCall.ToolName,
-		toolCall:   toolCall,
+		toolName:   toolName,
 		response:   responseChannel,
 	}
 }
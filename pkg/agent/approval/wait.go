@@ -2,31 +2,33 @@ package approval
 
 import (
 	"context"
+	"strings"
 	"time"
 
-	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/types"
 )
 
 // waitForResponse waits for the user's approval response
-func (m *Manager) waitForResponse(ctx context.Context, approvalID string, toolCall tools.ToolCall, responseChannel chan *types.ApprovalResponse) (bool, bool) {
+// Returns (approved, timedOut, feedback)
+func (m *Manager) waitForResponse(ctx context.Context, approvalID, toolName string, responseChannel chan *types.ApprovalResponse) (bool, bool, string) {
 	timeout := time.NewTimer(m.timeout)
 	defer timeout.Stop()
 
 	select {
 	case <-ctx.Done():
-		return false, false
+		return false, false, ""
 
 	case <-timeout.C:
-		m.emitEvent(types.NewToolApprovalTimeoutEvent(approvalID, toolCall.ToolName))
-		return false, true
+		m.emitEvent(types.NewToolApprovalTimeoutEvent(approvalID, toolName))
+		return false, true, ""
 
 	case response := <-responseChannel:
 		if response.IsGranted() {
-			m.emitEvent(types.NewToolApprovalGrantedEvent(approvalID, toolCall.ToolName))
-			return true, false
+			m.emitEvent(types.NewToolApprovalGrantedEvent(approvalID, toolName))
+			return true, false, strings.TrimSpace(response.Feedback)
 		}
-		m.emitEvent(types.NewToolApprovalRejectedEvent(approvalID, toolCall.ToolName))
-		return false, false
+		feedback := strings.TrimSpace(response.Feedback)
+		m.emitEvent(types.NewToolApprovalRejectedEvent(approvalID, toolName, feedback))
+		return false, false, feedback
 	}
 }
@@ -76,9 +76,11 @@ func TestApprovalSystem_RequestApproval(t *testing.T) {
 		name             string
 		sendResponse     bool
 		responseDecision types.ApprovalDecision
+		responseFeedback string
 		timeout          time.Duration
 		expectApproved   bool
 		expectTimedOut   bool
+		expectFeedback   string
 	}{
 		{
 			name:             "approval granted",
@@ -96,6 +98,16 @@ func TestApprovalSystem_RequestApproval(t *testing.T) {
 			expectApproved:   false,
 			expectTimedOut:   false,
 		},
+		{
+			name:             "approval rejected with feedback",
+			sendResponse:     true,
+			responseDecision: types.ApprovalRejected,
+			responseFeedback: "  keep the existing helper  ",
+			timeout:          1 * time.Second,
+			expectApproved:   false,
+			expectTimedOut:   false,
+			expectFeedback:   "keep the existing helper",
+		},
 		{
 			name:           "approval timeout",
 			sendResponse:   false,
@@ -151,13 +163,13 @@ func TestApprovalSystem_RequestApproval(t *testing.T) {
 					approvalIDMutex.Unlock()
 
 					if id != "" {
-						response := types.NewApprovalResponse(id, tt.responseDecision)
+						response := types.NewApprovalResponseWithFeedback(id, tt.responseDecision, tt.responseFeedback)
 						agent.handleApprovalResponse(response)
 					}
 				}()
 			}
 
-			approved, timedOut := agent.requestApproval(ctx, toolCall, preview)
+			approved, timedOut, feedback := agent.requestApproval(ctx, toolCall, preview)
 
 			if approved != tt.expectApproved {
 				t.Errorf("approved = %v, want %v", approved, tt.expectApproved)
@@ -166,6 +178,10 @@ func TestApprovalSystem_RequestApproval(t *testing.T) {
 			if timedOut != tt.expectTimedOut {
 				t.Errorf("timedOut = %v, want %v", timedOut, tt.expectTimedOut)
 			}
+
+			if feedback != tt.expectFeedback {
+				t.Errorf("feedback = %q, want %q", feedback, tt.expectFeedback)
+			}
 		})
 	}
 }
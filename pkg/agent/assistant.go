@@ -2,14 +2,18 @@ package agent
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/entrhq/forge/pkg/types"
 )
 
 // runAgentLoop executes the agent loop with tools and thinking
-// The loop continues until a loop-breaking tool is used or circuit breaker triggers
+// The loop continues until a loop-breaking tool is used, the circuit breaker
+// triggers, or the turn exhausts its budget (see WithMaxTurns, WithMaxToolCalls
+// and WithMaxTurnDuration)
 func (a *DefaultAgent) runAgentLoop(ctx context.Context) {
 	var errorContext string
+	a.budget = newTurnBudget(a.maxTurns, a.maxToolCalls, a.maxTurnDuration)
 
 	for {
 		// Check if context was canceled (e.g., via /stop command)
@@ -18,11 +22,25 @@ func (a *DefaultAgent) runAgentLoop(ctx context.Context) {
 			// Context canceled - stop the agent loop
 			// Emit a user-friendly message about the cancellation
 			a.memory.Add(types.NewUserMessage("Operation stopped by user."))
+			if a.turn != nil {
+				a.turn.outcome = "stopped by the user"
+			}
 			return
 		default:
 			// Continue with iteration
 		}
 
+		// Stop runaway loops once the turn's budget is spent
+		if event := a.budget.exceeded(); event != nil {
+			a.emitEvent(event)
+			a.memory.Add(types.NewUserMessage(formatBudgetExceeded(event)))
+			if a.turn != nil {
+				a.turn.outcome = fmt.Sprintf("stopped after exhausting the %v budget", event.Metadata["limit"])
+			}
+			return
+		}
+		a.budget.iterations++
+
 		// Execute one iteration with optional error context from previous iteration
 		shouldContinue, nextErrorContext := a.executeIteration(ctx, errorContext)
 		if !shouldContinue {
@@ -54,15 +72,27 @@ func (a *DefaultAgent) executeIteration(ctx context.Context, errorContext string
 		return false, ""
 	}
 
-	// Step 3: Record response (emit tokens, add to memory)
+	// Step 3: A response without a tool call may be a complete answer
+	answered := len(resp.toolCalls) == 0 && a.acceptAnswer(ctx, resp)
+
+	// Step 4: Record response (emit tokens, add to memory)
 	a.recordResponse(pctx, resp)
+	if answered {
+		return false, ""
+	}
 
-	// Step 4: Process the tool call (parse, validate, execute)
-	return a.processToolCall(ctx, resp.toolCallContent)
+	// Step 5: Process the tool calls in order (parse, validate, execute)
+	return a.processToolCalls(ctx, resp.toolCalls)
 }
 
-// emitEvent sends an event on the event channel.
-// This is a blocking send to ensure critical events like TurnEnd are not dropped.
+// emitEvent publishes an event to subscribers and sends it on the event channel.
+// Critical events like TurnEnd wait for the executor to take them, until the
+// agent shuts down. Progress events such as heartbeats are dropped if the
+// executor has fallen behind, so a stalled executor can't hold up a running
+// tool.
 func (a *DefaultAgent) emitEvent(event *types.AgentEvent) {
-	a.channels.Event <- event
+	a.subscriptions.Publish(event)
+	if !types.SendEvent(a.channels.Event, event, a.channels.Shutdown) {
+		agentDebugLog.Printf("Dropped %s event: executor not keeping up or agent shutting down", event.Type)
+	}
 }
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/risk"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// WithAuditLog records every tool call that can change the workspace, with
+// its approval decision and content hashes, in a tamper-evident log
+func WithAuditLog(log *audit.Log) AgentOption {
+	return func(a *DefaultAgent) {
+		a.auditLog = log
+	}
+}
+
+// auditRecord is the audit entry of a tool call in progress. Its methods are
+// no-ops on a nil record, which is what untracked tool calls get.
+type auditRecord struct {
+	log   *audit.Log
+	entry audit.Entry
+}
+
+// beginAudit starts the audit entry for a call of a tool that requires
+// approval, hashing its target file before anything changes. Returns nil when
+// there is no audit log or the tool cannot change the workspace.
+func (a *DefaultAgent) beginAudit(tool tools.Tool, toolCall tools.ToolCall) *auditRecord {
+	if a.auditLog == nil {
+		return nil
+	}
+	if _, mutating := tool.(tools.Previewable); !mutating {
+		return nil
+	}
+
+	var args turnToolArgs
+	_ = tools.UnmarshalXMLWithFallback(toolCall.GetArgumentsXML(), &args)
+
+	rec := &auditRecord{
+		log:   a.auditLog,
+		entry: audit.Entry{Tool: toolCall.ToolName, Action: audit.ActionWrite},
+	}
+	switch {
+	case toolCall.ToolName == "copy_to_clipboard":
+		rec.entry.Action = audit.ActionCopy
+	case args.Command != "":
+		rec.entry.Action = audit.ActionCommand
+		rec.entry.Target = args.Command
+	case args.Path != "":
+		if toolCall.ToolName == "apply_diff" {
+			rec.entry.Action = audit.ActionDiff
+		}
+		rec.entry.Target = args.Path
+		rec.entry.BeforeHash = a.auditLog.HashFile(args.Path)
+	}
+	return rec
+}
+
+// assess records the size and risk score of the change the approver saw
+func (r *auditRecord) assess(a *risk.Assessment) {
+	if r == nil || a == nil {
+		return
+	}
+	r.entry.Added = a.Added
+	r.entry.Removed = a.Removed
+	r.entry.Risk = a.Score
+	r.entry.RiskLevel = a.Level
+}
+
+// attachAssessment adds an assessment of the change a preview shows to its
+// metadata, for the approval UI, and returns it
+func attachAssessment(preview *tools.ToolPreview, assessment *risk.Assessment) *risk.Assessment {
+	if assessment == nil {
+		return nil
+	}
+	if preview.Metadata == nil {
+		preview.Metadata = make(map[string]interface{})
+	}
+	preview.Metadata[risk.MetadataKey] = assessment
+	return assessment
+}
+
+// decide records the approval decision and the user's feedback, if any
+func (r *auditRecord) decide(approval, feedback string) {
+	if r == nil {
+		return
+	}
+	r.entry.Approval = approval
+	r.entry.Feedback = feedback
+}
+
+// finishAudit records a tool call's outcome and appends its entry to the log
+func (a *DefaultAgent) finishAudit(r *auditRecord, executed bool, result string, toolErr error) {
+	if r == nil {
+		return
+	}
+
+	r.entry.Executed = executed
+	if toolErr != nil {
+		r.entry.Error = toolErr.Error()
+	}
+	if executed {
+		switch {
+		case r.entry.Action == audit.ActionCommand:
+			r.entry.OutputHash = audit.HashBytes([]byte(result))
+			r.entry.ExitCode = commandExitCode(result)
+		case r.entry.Target != "":
+			r.entry.AfterHash = r.log.HashFile(r.entry.Target)
+			if r.entry.BeforeHash != "" && r.entry.AfterHash == "" {
+				r.entry.Action = audit.ActionDelete
+			}
+		}
+	}
+
+	if err := r.log.Append(r.entry); err != nil {
+		a.emitEvent(types.NewErrorEvent(fmt.Errorf("failed to record %s in the audit log: %w", r.entry.Tool, err)))
+	}
+}
+
+// exitCodeTrailer matches the exit code execute_command ends its text output with
+var exitCodeTrailer = regexp.MustCompile(`Exit code: (-?\d+)\s*$`)
+
+// commandExitCode extracts a command's exit code from its text or JSON
+// result, or returns nil when the result has none
+func commandExitCode(result string) *int {
+	if match := exitCodeTrailer.FindStringSubmatch(result); match != nil {
+		if code, err := strconv.Atoi(match[1]); err == nil {
+			return &code
+		}
+	}
+	var structured struct {
+		ExitCode *int `json:"exit_code"`
+	}
+	if json.Unmarshal([]byte(result), &structured) == nil {
+		return structured.ExitCode
+	}
+	return nil
+}
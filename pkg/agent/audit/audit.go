@@ -0,0 +1,437 @@
+// Package audit keeps a tamper-evident, append-only log of every action the
+// agent takes that can change the workspace: file writes, diffs, deletions and
+// executed commands, together with the approval decision for each and hashes
+// of the content before and after.
+//
+// Each entry is a JSON line that records the hash of the entry before it, and
+// its own hash covers that link. The hashes are HMAC-SHA256 under a key kept
+// outside the workspace, in the anchor directory, so an entry can't be
+// edited and the chain recomputed without the key. The anchor directory
+// also records each log's last entry, so removing entries from the end is
+// detected too. Editing, removing or reordering an entry breaks the chain
+// from that point on, which VerifyLog reports.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Path is the audit log's location, relative to the workspace root
+const Path = ".forge/audit.log"
+
+// keySize is the length of the key the chain is signed with
+const keySize = 32
+
+// Actions
+const (
+	ActionWrite   = "write"   // A file was written
+	ActionDiff    = "diff"    // Edits were applied to a file
+	ActionDelete  = "delete"  // A file was removed
+	ActionCommand = "command" // A command was executed
+	ActionCopy    = "copy"    // Text was put on the user's clipboard
+)
+
+// Approval decisions
+const (
+	ApprovalAuto     = "auto"      // Auto-approved by settings or the command whitelist
+	ApprovalUser     = "user"      // Approved by the user
+	ApprovalRejected = "rejected"  // Rejected by the user; not executed
+	ApprovalTimedOut = "timed_out" // Nobody answered in time; not executed
+	ApprovalBlocked  = "blocked"   // Denied by a pre_tool hook or an edit lock; not executed
+	ApprovalNone     = "none"      // Executed without approval (no preview was available)
+)
+
+// maxTargetLength caps how much of a command line an entry records
+const maxTargetLength = 2000
+
+// Entry is one action in the log
+type Entry struct {
+	Seq        int       `json:"seq"`
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	Tool       string    `json:"tool"`
+	Target     string    `json:"target"`   // File path or command line
+	Approval   string    `json:"approval"` // One of the Approval* decisions
+	Feedback   string    `json:"feedback,omitempty"`
+	Executed   bool      `json:"executed"`
+	Error      string    `json:"error,omitempty"`
+	BeforeHash string    `json:"before_sha256,omitempty"` // File content before the action ("" = did not exist)
+	AfterHash  string    `json:"after_sha256,omitempty"`  // File content after the action ("" = does not exist)
+	OutputHash string    `json:"output_sha256,omitempty"` // Commands: the command's output
+	ExitCode   *int      `json:"exit_code,omitempty"`     // Commands: the exit code, when the command ran
+	Added      int       `json:"lines_added,omitempty"`   // Edits: lines the change adds
+	Removed    int       `json:"lines_removed,omitempty"` // Edits: lines the change removes
+	Risk       int       `json:"risk_score,omitempty"`    // Edits: heuristic risk score of the change, 0-100
+	RiskLevel  string    `json:"risk_level,omitempty"`    // Edits: "low", "medium" or "high"
+	Prev       string    `json:"prev"`                    // Hash of the previous entry ("" for the first)
+	Hash       string    `json:"hash"`                    // HMAC of this entry under the audit key, covering Prev
+}
+
+// computeHash returns the HMAC-SHA256 under key of the entry with its Hash
+// field cleared
+func (e Entry) computeHash(key []byte) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// head is a log's last entry, as recorded in the anchor directory
+type head struct {
+	Seq  int    `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// Log appends entries to a workspace's audit log
+type Log struct {
+	workDir  string
+	path     string
+	headPath string
+	key      []byte
+	mu       sync.Mutex
+	seq      int
+	last     string
+	session  []Entry // Entries appended since Open
+	aside    string  // Where a log that failed verification was moved
+	asideErr error   // Why it failed
+}
+
+// DefaultAnchorDir returns ~/.forge/audit, where the audit key and the last
+// entry of each workspace's log are kept
+func DefaultAnchorDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".forge", "audit"), nil
+}
+
+// Open opens the audit log of the workspace at workDir, creating it on the
+// first append, with the key and the record of its last entry in anchorDir.
+// An existing log that fails verification is moved aside and a new one is
+// started, so new entries are never chained onto a tampered log; see
+// SetAside.
+func Open(workDir, anchorDir string) (*Log, error) {
+	key, err := loadKey(anchorDir, true)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{
+		workDir:  workDir,
+		path:     filepath.Join(workDir, Path),
+		headPath: headPath(anchorDir, workDir),
+		key:      key,
+	}
+
+	entries, err := Read(l.path)
+	if err == nil {
+		err = l.verify(entries)
+	}
+	if err != nil {
+		if setAsideErr := l.setAside(err); setAsideErr != nil {
+			return nil, setAsideErr
+		}
+		return l, nil
+	}
+	if n := len(entries); n > 0 {
+		l.seq = entries[n-1].Seq
+		l.last = entries[n-1].Hash
+	}
+	return l, nil
+}
+
+// setAside moves a log that failed verification with err out of the way
+// and forgets its last entry, so the next append starts a new chain
+func (l *Log) setAside(err error) error {
+	if _, statErr := os.Stat(l.path); statErr == nil {
+		l.aside = fmt.Sprintf("%s.%s.invalid", l.path, time.Now().UTC().Format("20060102T150405Z"))
+		if renameErr := os.Rename(l.path, l.aside); renameErr != nil {
+			return fmt.Errorf("%s: %w, and it could not be moved aside: %v", l.path, err, renameErr)
+		}
+	}
+	if removeErr := os.Remove(l.headPath); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		return fmt.Errorf("failed to reset the audit log anchor: %w", removeErr)
+	}
+	l.asideErr = err
+	return nil
+}
+
+// SetAside returns why the log failed verification when it was opened and
+// where it was moved ("" if it was removed rather than edited), or a nil
+// error if it was intact
+func (l *Log) SetAside() (string, error) {
+	return l.aside, l.asideErr
+}
+
+// Path returns the log file's location
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append chains entry onto the log and writes it. Seq, Time, Prev and Hash
+// are filled in.
+func (l *Log) Append(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Seq = l.seq + 1
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	if len(entry.Target) > maxTargetLength {
+		entry.Target = entry.Target[:maxTargetLength] + "..."
+	}
+	entry.Prev = l.last
+
+	hash, err := entry.computeHash(l.key)
+	if err != nil {
+		return fmt.Errorf("failed to hash audit entry: %w", err)
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := writeHead(l.headPath, head{Seq: entry.Seq, Hash: entry.Hash}); err != nil {
+		return err
+	}
+
+	l.seq = entry.Seq
+	l.last = entry.Hash
+	l.session = append(l.session, entry)
+	return nil
+}
+
+// Session returns the entries appended since the log was opened, oldest first
+func (l *Log) Session() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Entry(nil), l.session...)
+}
+
+// HashFile returns the SHA-256 of the file at path (relative to the
+// workspace), or "" if it does not exist or is not a regular file
+func (l *Log) HashFile(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.workDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return HashBytes(data)
+}
+
+// HashBytes returns the hex SHA-256 of data
+func HashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Read returns the entries in the log at path, or none if it does not exist
+func Read(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("audit log line %d is corrupt: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// VerifyLog checks entries, read from the log of the workspace at workDir,
+// against the key and the record of the log's last entry in anchorDir
+func VerifyLog(workDir, anchorDir string, entries []Entry) error {
+	key, err := loadKey(anchorDir, false)
+	if err != nil {
+		return err
+	}
+	l := &Log{headPath: headPath(anchorDir, workDir), key: key}
+	return l.verify(entries)
+}
+
+// verify checks the chain of entries and that it ends where the anchor
+// directory says the log ends
+func (l *Log) verify(entries []Entry) error {
+	if err := Verify(entries, l.key); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(l.headPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if len(entries) > 0 {
+			return fmt.Errorf("audit log has %d entries but none were recorded in %s (log replaced)", len(entries), filepath.Dir(l.headPath))
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the audit log anchor: %w", err)
+	}
+	var last head
+	if err := json.Unmarshal(data, &last); err != nil {
+		return fmt.Errorf("audit log anchor %s is corrupt: %w", l.headPath, err)
+	}
+
+	// The log may be ahead of the record if a session stopped between
+	// writing an entry and recording it; the entries after it are still
+	// covered by the keyed chain
+	if len(entries) < last.Seq {
+		return fmt.Errorf("audit log ends at entry %d, but %d entries were written (entries removed from the end)", len(entries), last.Seq)
+	}
+	if last.Seq > 0 && entries[last.Seq-1].Hash != last.Hash {
+		return fmt.Errorf("audit log entry %d: not the entry that was written (log replaced)", last.Seq)
+	}
+	return nil
+}
+
+// Verify checks that every entry's hash is its HMAC under key and that each
+// entry links to the one before it. The error names the first entry that
+// was modified, removed or reordered.
+func Verify(entries []Entry, key []byte) error {
+	prev := ""
+	for i, entry := range entries {
+		if entry.Seq != i+1 {
+			return fmt.Errorf("audit log entry %d: expected sequence number %d, found %d (entries removed or reordered)", i+1, i+1, entry.Seq)
+		}
+		if entry.Prev != prev {
+			return fmt.Errorf("audit log entry %d: does not link to the previous entry", entry.Seq)
+		}
+		hash, err := entry.computeHash(key)
+		if err != nil {
+			return fmt.Errorf("audit log entry %d: %w", entry.Seq, err)
+		}
+		if !hmac.Equal([]byte(hash), []byte(entry.Hash)) {
+			return fmt.Errorf("audit log entry %d: content does not match its hash (entry modified)", entry.Seq)
+		}
+		prev = entry.Hash
+	}
+	return nil
+}
+
+// loadKey returns the key in anchorDir that logs are signed with, creating
+// it if create is set
+func loadKey(anchorDir string, create bool) ([]byte, error) {
+	path := filepath.Join(anchorDir, "key")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("audit key %s is corrupt", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || !create {
+		return nil, fmt.Errorf("failed to read audit key: %w", err)
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate audit key: %w", err)
+	}
+	if err := os.MkdirAll(anchorDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit key directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return loadKey(anchorDir, false) // Another session created it first
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit key: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		return nil, fmt.Errorf("failed to write audit key: %w", err)
+	}
+	return key, nil
+}
+
+// headPath returns where the last entry of workDir's log is recorded in
+// anchorDir, named after a hash of the workspace's absolute path
+func headPath(anchorDir, workDir string) string {
+	if abs, err := filepath.Abs(workDir); err == nil {
+		workDir = abs
+	}
+	if eval, err := filepath.EvalSymlinks(workDir); err == nil {
+		workDir = eval
+	}
+	sum := sha256.Sum256([]byte(workDir))
+	return filepath.Join(anchorDir, hex.EncodeToString(sum[:16])+".head")
+}
+
+// writeHead records the log's last entry, replacing the file atomically
+func writeHead(path string, last head) error {
+	data, err := json.Marshal(last)
+	if err != nil {
+		return fmt.Errorf("failed to encode the audit log anchor: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create the audit log anchor directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".head-*")
+	if err != nil {
+		return fmt.Errorf("failed to write the audit log anchor: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the audit log anchor: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the audit log anchor: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write the audit log anchor: %w", err)
+	}
+	return nil
+}
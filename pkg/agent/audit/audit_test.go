@@ -0,0 +1,249 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLog_AppendAndReopen(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	anchorDir := t.TempDir()
+	log, err := Open(dir, anchorDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := log.Append(Entry{Action: ActionWrite, Tool: "write_file", Target: "main.go", Approval: ApprovalUser, Executed: true, AfterHash: log.HashFile("main.go")}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := log.Append(Entry{Action: ActionCommand, Tool: "execute_command", Target: "rm -rf /", Approval: ApprovalRejected}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// A new session continues the chain
+	reopened, err := Open(dir, anchorDir)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	if err := reopened.Append(Entry{Action: ActionDiff, Tool: "apply_diff", Target: "main.go", Approval: ApprovalAuto, Executed: true}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	entries, err := Read(reopened.Path())
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if err := VerifyLog(dir, anchorDir, entries); err != nil {
+		t.Errorf("expected an intact chain, got %v", err)
+	}
+	if entries[2].Seq != 3 || entries[2].Prev != entries[1].Hash {
+		t.Errorf("expected the reopened log to link to entry 2, got %+v", entries[2])
+	}
+	if entries[0].AfterHash != HashBytes([]byte("package main\n")) {
+		t.Errorf("unexpected content hash %q", entries[0].AfterHash)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	newLog := func(t *testing.T) (string, string, []string) {
+		dir, anchorDir := t.TempDir(), t.TempDir()
+		log, err := Open(dir, anchorDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, target := range []string{"a.go", "b.go", "c.go"} {
+			if err := log.Append(Entry{Action: ActionWrite, Tool: "write_file", Target: target, Approval: ApprovalUser, Executed: true}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := os.ReadFile(log.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dir, anchorDir, strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	// rechain recomputes every hash from entry i on, as someone editing the
+	// log would, with their own key
+	rechain := func(t *testing.T, lines []string, i int, edit func(*Entry)) []string {
+		key := make([]byte, keySize)
+		var prev Entry
+		if err := json.Unmarshal([]byte(lines[i-1]), &prev); err != nil {
+			t.Fatal(err)
+		}
+		for ; i < len(lines); i++ {
+			var entry Entry
+			if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+				t.Fatal(err)
+			}
+			edit(&entry)
+			entry.Prev = prev.Hash
+			entry.Hash, _ = entry.computeHash(key)
+			data, _ := json.Marshal(entry)
+			lines[i], prev = string(data), entry
+			edit = func(*Entry) {}
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, lines []string) []string
+		want   string
+	}{
+		{
+			name: "modified entry",
+			tamper: func(t *testing.T, lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"approval":"user"`, `"approval":"auto"`, 1)
+				return lines
+			},
+			want: "entry 2: content does not match",
+		},
+		{
+			name: "modified entry with the chain recomputed",
+			tamper: func(t *testing.T, lines []string) []string {
+				return rechain(t, lines, 1, func(e *Entry) { e.Approval = ApprovalAuto })
+			},
+			want: "entry 2: content does not match",
+		},
+		{
+			name: "removed entry",
+			tamper: func(t *testing.T, lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			want: "entries removed or reordered",
+		},
+		{
+			name: "truncated",
+			tamper: func(t *testing.T, lines []string) []string {
+				return lines[:2]
+			},
+			want: "entries removed from the end",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, anchorDir, lines := newLog(t)
+			path := filepath.Join(dir, Path)
+			if err := os.WriteFile(path, []byte(strings.Join(tt.tamper(t, lines), "\n")+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			if err := VerifyLog(dir, anchorDir, entries); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+
+			// Opening moves the tampered log aside and starts a new chain
+			log, err := Open(dir, anchorDir)
+			if err != nil {
+				t.Fatalf("expected Open to recover, got %v", err)
+			}
+			aside, reason := log.SetAside()
+			if reason == nil || aside == "" {
+				t.Fatalf("expected the log to be set aside, got %q, %v", aside, reason)
+			}
+			if _, err := os.Stat(aside); err != nil {
+				t.Errorf("expected the tampered log to be kept: %v", err)
+			}
+			if err := log.Append(Entry{Action: ActionWrite, Tool: "write_file", Target: "d.go", Approval: ApprovalUser, Executed: true}); err != nil {
+				t.Fatal(err)
+			}
+			entries, err = Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || VerifyLog(dir, anchorDir, entries) != nil {
+				t.Errorf("expected a new intact log, got %+v", entries)
+			}
+		})
+	}
+}
+
+func TestOpen_CorruptLog(t *testing.T) {
+	dir, anchorDir := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".forge"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, Path), []byte("not json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := Open(dir, anchorDir)
+	if err != nil {
+		t.Fatalf("expected a corrupt log not to prevent opening, got %v", err)
+	}
+	if _, reason := log.SetAside(); reason == nil || !strings.Contains(reason.Error(), "corrupt") {
+		t.Errorf("expected the corrupt log to be set aside, got %v", reason)
+	}
+}
+
+func TestOpen_RemovedLog(t *testing.T) {
+	dir, anchorDir := t.TempDir(), t.TempDir()
+	log, err := Open(dir, anchorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Append(Entry{Action: ActionDelete, Tool: "delete_file", Target: "a.go", Approval: ApprovalUser, Executed: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(log.Path()); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(dir, anchorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aside, reason := reopened.SetAside(); reason == nil || aside != "" {
+		t.Errorf("expected the removal to be reported, got %q, %v", aside, reason)
+	}
+}
+
+func TestLog_Session(t *testing.T) {
+	dir := t.TempDir()
+	anchorDir := t.TempDir()
+	earlier, err := Open(dir, anchorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := earlier.Append(Entry{Action: ActionCommand, Tool: "execute_command", Target: "make", Approval: ApprovalUser, Executed: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := Open(dir, anchorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exitCode := 2
+	if err := log.Append(Entry{Action: ActionCommand, Tool: "execute_command", Target: "go test ./...", Approval: ApprovalAuto, Executed: true, ExitCode: &exitCode}); err != nil {
+		t.Fatal(err)
+	}
+
+	session := log.Session()
+	if len(session) != 1 || session[0].Seq != 2 || *session[0].ExitCode != 2 {
+		t.Errorf("expected only this session's entry, got %+v", session)
+	}
+
+	// The exit code is covered by the hash like any other field
+	entries, err := Read(log.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyLog(dir, anchorDir, entries); err != nil {
+		t.Errorf("expected an intact chain, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/hooks"
+	"github.com/entrhq/forge/pkg/agent/risk"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// auditFileTool is a mutating test tool that overwrites or removes a file
+type auditFileTool struct {
+	summaryWriteTool
+	dir    string
+	remove bool
+}
+
+func (t *auditFileTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	path := filepath.Join(t.dir, "main.go")
+	if t.remove {
+		return "removed", os.Remove(path)
+	}
+	return "written", os.WriteFile(path, []byte("package main // edited\n"), 0644)
+}
+
+// answerApprovals responds to each approval request with decision
+func answerApprovals(a *DefaultAgent, collected func() []*types.AgentEvent, decision types.ApprovalDecision, feedback string) {
+	go func() {
+		answered := make(map[string]bool)
+		for i := 0; i < 200; i++ {
+			for _, ev := range collected() {
+				if ev.Type == types.EventTypeToolApprovalRequest && !answered[ev.ApprovalID] {
+					answered[ev.ApprovalID] = true
+					a.handleApprovalResponse(types.NewApprovalResponseWithFeedback(ev.ApprovalID, decision, feedback))
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+}
+
+func TestExecuteTool_RecordsAuditEntries(t *testing.T) {
+	dir := t.TempDir()
+	original := []byte("package main\n")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), original, 0644); err != nil {
+		t.Fatal(err)
+	}
+	anchorDir := t.TempDir()
+	log, err := audit.Open(dir, anchorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := hooks.NewRunner(dir, []hooks.Hook{{Event: hooks.EventPreTool, Command: "exit 2", Tools: []string{"delete_file"}}})
+	a, collected := newRunnerTestAgent(WithAuditLog(log), WithHooks(runner))
+	a.tools["write_file"] = &auditFileTool{summaryWriteTool: summaryWriteTool{summaryTestTool{name: "write_file"}}, dir: dir}
+	a.tools["delete_file"] = &auditFileTool{summaryWriteTool: summaryWriteTool{summaryTestTool{name: "delete_file"}}, dir: dir, remove: true}
+	a.tools["read_file"] = &summaryTestTool{name: "read_file"}
+
+	answerApprovals(a, collected, types.ApprovalGranted, "")
+	a.executeTool(context.Background(), toolCallWithArgs("write_file", "<path>main.go</path><content>x</content>"))
+	a.executeTool(context.Background(), toolCallWithArgs("read_file", "<path>main.go</path>"))
+	a.executeTool(context.Background(), toolCallWithArgs("delete_file", "<path>main.go</path>"))
+
+	entries, err := audit.Read(log.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := audit.VerifyLog(dir, anchorDir, entries); err != nil {
+		t.Fatalf("expected an intact chain, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected entries for the two mutating calls only, got %d", len(entries))
+	}
+
+	write := entries[0]
+	if write.Action != audit.ActionWrite || write.Target != "main.go" || write.Approval != audit.ApprovalUser || !write.Executed {
+		t.Errorf("unexpected write entry: %+v", write)
+	}
+	if write.BeforeHash != audit.HashBytes(original) || write.AfterHash != audit.HashBytes([]byte("package main // edited\n")) {
+		t.Errorf("expected before and after content hashes, got %q → %q", write.BeforeHash, write.AfterHash)
+	}
+
+	blocked := entries[1]
+	if blocked.Approval != audit.ApprovalBlocked || blocked.Executed || blocked.AfterHash != "" {
+		t.Errorf("expected a blocked, unexecuted entry, got %+v", blocked)
+	}
+}
+
+func TestExecuteTool_AuditsRejectionsAndDeletes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	log, err := audit.Open(dir, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, collected := newRunnerTestAgent(WithAuditLog(log))
+	a.tools["delete_file"] = &auditFileTool{summaryWriteTool: summaryWriteTool{summaryTestTool{name: "delete_file"}}, dir: dir, remove: true}
+
+	answerApprovals(a, collected, types.ApprovalRejected, "keep it")
+	a.executeTool(context.Background(), toolCallWithArgs("delete_file", "<path>main.go</path>"))
+
+	entries, _ := audit.Read(log.Path())
+	if len(entries) != 1 || entries[0].Approval != audit.ApprovalRejected || entries[0].Feedback != "keep it" || entries[0].Executed {
+		t.Fatalf("expected a rejected entry with feedback, got %+v", entries)
+	}
+
+	a2, collected2 := newRunnerTestAgent(WithAuditLog(log))
+	a2.tools["delete_file"] = a.tools["delete_file"]
+	answerApprovals(a2, collected2, types.ApprovalGranted, "")
+	a2.executeTool(context.Background(), toolCallWithArgs("delete_file", "<path>main.go</path>"))
+
+	entries, _ = audit.Read(log.Path())
+	if len(entries) != 2 || entries[1].Action != audit.ActionDelete || entries[1].BeforeHash == "" || entries[1].AfterHash != "" {
+		t.Fatalf("expected a delete entry, got %+v", entries)
+	}
+}
+
+// diffPreviewTool is a mutating test tool previewed as a diff of go.mod
+type diffPreviewTool struct {
+	summaryTestTool
+}
+
+func (d *diffPreviewTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	return &tools.ToolPreview{
+		Type:     tools.PreviewTypeDiff,
+		Content:  "--- go.mod\n+++ go.mod\n@@ -1,1 +1,2 @@\n-go 1.22\n+go 1.24\n+require example.com/x v1.0.0\n",
+		Metadata: map[string]interface{}{"file_path": "go.mod"},
+	}, nil
+}
+
+func TestExecuteTool_AssessesRisk(t *testing.T) {
+	dir := t.TempDir()
+	log, err := audit.Open(dir, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, collected := newRunnerTestAgent(WithAuditLog(log))
+	a.tools["apply_diff"] = &diffPreviewTool{summaryTestTool{name: "apply_diff"}}
+
+	answerApprovals(a, collected, types.ApprovalGranted, "")
+	a.executeTool(context.Background(), toolCallWithArgs("apply_diff", "<path>go.mod</path>"))
+
+	var assessment *risk.Assessment
+	for _, ev := range collected() {
+		if preview, ok := ev.Preview.(*tools.ToolPreview); ok && ev.Type == types.EventTypeToolApprovalRequest {
+			assessment, _ = preview.Metadata[risk.MetadataKey].(*risk.Assessment)
+		}
+	}
+	if assessment == nil || assessment.Added != 2 || assessment.Removed != 1 || !assessment.Dependencies {
+		t.Fatalf("expected the approval request to carry the assessment, got %+v", assessment)
+	}
+
+	entries, _ := audit.Read(log.Path())
+	if len(entries) != 1 || entries[0].Added != 2 || entries[0].Removed != 1 || entries[0].Risk != assessment.Score || entries[0].RiskLevel != assessment.Level {
+		t.Fatalf("expected the audit entry to record the assessment, got %+v", entries)
+	}
+}
+
+func TestCommandExitCode(t *testing.T) {
+	tests := []struct {
+		result string
+		want   int
+		found  bool
+	}{
+		{result: "Command failed with exit code 2\n\nStdout:\n\n\nStderr:\nboom\n\nExit code: 2", want: 2, found: true},
+		{result: "Command completed successfully in 1s\n\nStdout:\nok\n\nExit code: 0", want: 0, found: true},
+		{result: `{"command":"false","status":"failed","exit_code":1,"stdout":"","stderr":""}`, want: 1, found: true},
+		{result: "written"},
+	}
+	for _, tt := range tests {
+		got := commandExitCode(tt.result)
+		if (got != nil) != tt.found || (got != nil && *got != tt.want) {
+			t.Errorf("commandExitCode(%q) = %v, want %d (found %v)", tt.result, got, tt.want, tt.found)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Budget limit names reported in budget exceeded events
+const (
+	budgetLimitIterations = "iterations"
+	budgetLimitToolCalls  = "tool_calls"
+	budgetLimitDuration   = "duration"
+)
+
+// turnBudget tracks how much of its loop budget the current turn has used.
+// A zero limit means that dimension is unlimited.
+type turnBudget struct {
+	maxIterations int
+	maxToolCalls  int
+	maxDuration   time.Duration
+
+	iterations int
+	toolCalls  int
+	start      time.Time
+}
+
+// newTurnBudget creates a budget for a turn starting now
+func newTurnBudget(maxIterations, maxToolCalls int, maxDuration time.Duration) *turnBudget {
+	return &turnBudget{
+		maxIterations: maxIterations,
+		maxToolCalls:  maxToolCalls,
+		maxDuration:   maxDuration,
+		start:         time.Now(),
+	}
+}
+
+// exceeded reports the first exhausted budget, if any, as an event
+func (b *turnBudget) exceeded() *types.AgentEvent {
+	if b.maxIterations > 0 && b.iterations >= b.maxIterations {
+		return types.NewBudgetExceededEvent(budgetLimitIterations, strconv.Itoa(b.iterations), strconv.Itoa(b.maxIterations))
+	}
+	if b.maxToolCalls > 0 && b.toolCalls >= b.maxToolCalls {
+		return types.NewBudgetExceededEvent(budgetLimitToolCalls, strconv.Itoa(b.toolCalls), strconv.Itoa(b.maxToolCalls))
+	}
+	if elapsed := time.Since(b.start); b.maxDuration > 0 && elapsed >= b.maxDuration {
+		return types.NewBudgetExceededEvent(budgetLimitDuration, elapsed.Round(time.Second).String(), b.maxDuration.String())
+	}
+	return nil
+}
+
+// formatBudgetExceeded renders a budget exceeded event as a memory message, so
+// the model knows why it was stopped when the user asks it to continue
+func formatBudgetExceeded(event *types.AgentEvent) string {
+	return fmt.Sprintf("Turn stopped: the %s budget for this turn was exhausted (used %v of %v). "+
+		"If the user asks you to continue, pick up where you left off.",
+		event.Metadata["limit"], event.Metadata["used"], event.Metadata["max"])
+}
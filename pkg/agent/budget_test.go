@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestTurnBudgetExceeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    *turnBudget
+		wantLimit string
+	}{
+		{"unlimited", &turnBudget{iterations: 1000, toolCalls: 1000, start: time.Now().Add(-time.Hour)}, ""},
+		{"under limits", &turnBudget{maxIterations: 5, maxToolCalls: 5, maxDuration: time.Hour, iterations: 4, toolCalls: 4, start: time.Now()}, ""},
+		{"iterations", &turnBudget{maxIterations: 3, iterations: 3, start: time.Now()}, budgetLimitIterations},
+		{"tool calls", &turnBudget{maxToolCalls: 2, toolCalls: 2, start: time.Now()}, budgetLimitToolCalls},
+		{"duration", &turnBudget{maxDuration: time.Minute, start: time.Now().Add(-2 * time.Minute)}, budgetLimitDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.budget.exceeded()
+			if tt.wantLimit == "" {
+				if event != nil {
+					t.Fatalf("exceeded() = %v, want nil", event.Metadata)
+				}
+				return
+			}
+			if event == nil {
+				t.Fatal("exceeded() = nil, want an event")
+			}
+			if event.Type != types.EventTypeBudgetExceeded {
+				t.Errorf("event type = %s, want %s", event.Type, types.EventTypeBudgetExceeded)
+			}
+			if event.Metadata["limit"] != tt.wantLimit {
+				t.Errorf("limit = %v, want %s", event.Metadata["limit"], tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestRunAgentLoop_StopsWhenBudgetExhausted(t *testing.T) {
+	a, collected := newRunnerTestAgent(WithMaxTurnDuration(time.Nanosecond))
+
+	a.runAgentLoop(context.Background())
+
+	// Allow the collector goroutine to drain
+	time.Sleep(20 * time.Millisecond)
+
+	found := false
+	for _, ev := range collected() {
+		if ev.Type == types.EventTypeBudgetExceeded {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a budget exceeded event")
+	}
+
+	history := a.memory.GetAll()
+	if len(history) == 0 || history[len(history)-1].Role != types.RoleUser {
+		t.Fatal("expected a budget note in memory")
+	}
+	if a.budget.iterations != 0 {
+		t.Errorf("iterations = %d, want 0 (no LLM call after the budget ran out)", a.budget.iterations)
+	}
+}
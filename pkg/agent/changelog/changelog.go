@@ -0,0 +1,234 @@
+// Package changelog maintains a Keep a Changelog style CHANGELOG.md from git
+// history. Commits since the last tag are grouped by their Conventional
+// Commits type into a release section, which is merged into the existing
+// changelog as a Proposal the caller previews before applying. No model is
+// involved, so the same changelog is produced locally and in CI.
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultFile is the changelog updated when no other file is given
+const DefaultFile = "CHANGELOG.md"
+
+// Unreleased is the section label used when no version is given
+const Unreleased = "Unreleased"
+
+// otherType groups commits that don't follow Conventional Commits or use a
+// type without a group of its own
+const otherType = "other"
+
+// groups lists the release section's groups in the order they are rendered
+var groups = []struct {
+	commitType string
+	title      string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"revert", "Reverts"},
+	{"refactor", "Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "CI"},
+	{"style", "Style"},
+	{"chore", "Chores"},
+	{otherType, "Other Changes"},
+}
+
+// subjectPattern matches "type(scope)!: description" commit subjects
+var subjectPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// breakingPattern matches a BREAKING CHANGE footer in a commit body
+var breakingPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s*(.+)$`)
+
+// Entry is one commit in a release
+type Entry struct {
+	Hash        string `json:"hash"`
+	Type        string `json:"type"` // Conventional Commits type, lowercased; "other" for other commits
+	Scope       string `json:"scope,omitempty"`
+	Description string `json:"description"`
+	Breaking    string `json:"breaking,omitempty"` // What breaks, for breaking changes
+}
+
+// Release is the changelog section for the commits since a tag
+type Release struct {
+	Version string  `json:"version"` // Section label: a version without its "v" prefix, or Unreleased
+	Date    string  `json:"date,omitempty"`
+	Since   string  `json:"since,omitempty"` // Tag the commits follow; "" when there is none
+	Entries []Entry `json:"entries"`
+}
+
+// ParseCommit turns a commit into an Entry. Subjects that don't follow
+// Conventional Commits, or whose type has no group, are kept whole under
+// "other".
+func ParseCommit(hash, subject, body string) Entry {
+	entry := Entry{Hash: hash, Type: otherType, Description: strings.TrimSpace(subject)}
+
+	match := subjectPattern.FindStringSubmatch(entry.Description)
+	if match != nil && hasGroup(strings.ToLower(match[1])) {
+		entry.Type = strings.ToLower(match[1])
+		entry.Scope = strings.TrimSpace(match[2])
+		entry.Description = strings.TrimSpace(match[4])
+		if match[3] != "" {
+			entry.Breaking = entry.Description
+		}
+	}
+	if footer := breakingPattern.FindStringSubmatch(body); footer != nil {
+		entry.Breaking = strings.TrimSpace(footer[1])
+	}
+	return entry
+}
+
+// hasGroup reports whether commitType has a group of its own
+func hasGroup(commitType string) bool {
+	for _, group := range groups {
+		if group.commitType == commitType && commitType != otherType {
+			return true
+		}
+	}
+	return false
+}
+
+// Markdown renders the release as a changelog section, starting with its
+// "## [version] - date" heading. Breaking changes are listed first and again
+// under their type.
+func (r *Release) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## [" + r.Version + "]")
+	if r.Date != "" {
+		b.WriteString(" - " + r.Date)
+	}
+	b.WriteString("\n")
+
+	if len(r.Entries) == 0 {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+
+	var breaking []string
+	for _, entry := range r.Entries {
+		if entry.Breaking != "" {
+			breaking = append(breaking, formatLine(entry, entry.Breaking))
+		}
+	}
+	if len(breaking) > 0 {
+		b.WriteString("\n### ⚠ BREAKING CHANGES\n\n")
+		b.WriteString(strings.Join(breaking, "\n") + "\n")
+	}
+
+	for _, group := range groups {
+		var lines []string
+		for _, entry := range r.Entries {
+			if entry.Type == group.commitType {
+				lines = append(lines, formatLine(entry, entry.Description))
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "\n### %s\n\n", group.title)
+			b.WriteString(strings.Join(lines, "\n") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// formatLine renders one list item: "- **scope:** text (hash)"
+func formatLine(entry Entry, text string) string {
+	line := "- "
+	if entry.Scope != "" {
+		line += "**" + entry.Scope + ":** "
+	}
+	line += text
+	if entry.Hash != "" {
+		line += " (" + entry.Hash + ")"
+	}
+	return line
+}
+
+// Merge returns existing with the release's section in place. A section with
+// the same label is replaced, so a release can be regenerated; otherwise the
+// section goes above the latest release, below any Unreleased section. An
+// empty changelog gets a Keep a Changelog header.
+func (r *Release) Merge(existing string) string {
+	section := r.Markdown()
+	if strings.TrimSpace(existing) == "" {
+		return "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n" + section
+	}
+
+	lines := strings.SplitAfter(existing, "\n")
+	var headings []int
+	for i, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			headings = append(headings, i)
+		}
+	}
+
+	// Replace the section with the same label, up to the next heading
+	for n, i := range headings {
+		if sectionLabel(lines[i]) != r.Version {
+			continue
+		}
+		end := len(lines)
+		if n+1 < len(headings) {
+			end = headings[n+1]
+		}
+		return join(lines[:i], section+trailingGap(lines[i:end], end < len(lines)), lines[end:])
+	}
+
+	// Insert above the first release, skipping a leading Unreleased section
+	for _, i := range headings {
+		if sectionLabel(lines[i]) == Unreleased && r.Version != Unreleased {
+			continue
+		}
+		return join(lines[:i], section+"\n", lines[i:])
+	}
+
+	if !strings.HasSuffix(existing, "\n") {
+		existing += "\n"
+	}
+	return existing + "\n" + section
+}
+
+// trailingGap returns the blank line kept between a replaced section and
+// what follows it
+func trailingGap(replaced []string, followed bool) string {
+	if followed {
+		return "\n"
+	}
+	// At the end of the file, keep link references such as
+	// "[1.0.0]: https://..." that the replaced section ended with
+	var refs []string
+	for _, line := range replaced {
+		if strings.HasPrefix(line, "[") && strings.Contains(line, "]: ") {
+			refs = append(refs, line)
+		}
+	}
+	if len(refs) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(refs, "")
+}
+
+// sectionLabel returns the label of a "## [label] - date" heading
+func sectionLabel(heading string) string {
+	label := strings.TrimSpace(strings.TrimPrefix(heading, "## "))
+	if strings.HasPrefix(label, "[") {
+		if end := strings.Index(label, "]"); end > 0 {
+			return label[1:end]
+		}
+	}
+	if fields := strings.Fields(label); len(fields) > 0 {
+		return strings.TrimPrefix(fields[0], "v")
+	}
+	return label
+}
+
+// join concatenates the lines before a section, the section and the lines
+// after it
+func join(before []string, section string, after []string) string {
+	return strings.Join(before, "") + section + strings.Join(after, "")
+}
@@ -0,0 +1,157 @@
+package changelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		subject string
+		body    string
+		want    Entry
+	}{
+		{"feat(tui): add /changelog", "", Entry{Type: "feat", Scope: "tui", Description: "add /changelog"}},
+		{"Fix: handle empty diffs", "", Entry{Type: "fix", Description: "handle empty diffs"}},
+		{"refactor!: drop the v1 API", "", Entry{Type: "refactor", Description: "drop the v1 API", Breaking: "drop the v1 API"}},
+		{"feat: new config format", "Details.\n\nBREAKING CHANGE: settings.json is no longer read", Entry{Type: "feat", Description: "new config format", Breaking: "settings.json is no longer read"}},
+		{"Update README", "", Entry{Type: "other", Description: "Update README"}},
+		{"wip: half done", "", Entry{Type: "other", Description: "wip: half done"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseCommit("", tt.subject, tt.body); got != tt.want {
+			t.Errorf("ParseCommit(%q) = %+v, want %+v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestRelease_Markdown(t *testing.T) {
+	release := &Release{Version: "1.2.0", Date: "2026-01-02", Entries: []Entry{
+		{Hash: "a1", Type: "fix", Scope: "git", Description: "quote paths"},
+		{Hash: "b2", Type: "feat", Description: "add export", Breaking: "export replaces dump"},
+		{Hash: "c3", Type: "other", Description: "Tidy up"},
+	}}
+
+	want := `## [1.2.0] - 2026-01-02
+
+### ⚠ BREAKING CHANGES
+
+- export replaces dump (b2)
+
+### Features
+
+- add export (b2)
+
+### Bug Fixes
+
+- **git:** quote paths (a1)
+
+### Other Changes
+
+- Tidy up (c3)
+`
+	if got := release.Markdown(); got != want {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+}
+
+func TestRelease_Merge(t *testing.T) {
+	release := func(version string) *Release {
+		return &Release{Version: version, Entries: []Entry{{Type: "fix", Description: "new fix"}}}
+	}
+	existing := "# Changelog\n\nIntro.\n\n## [Unreleased]\n\n- notes\n\n## [1.0.0]\n\n- old\n\n[1.0.0]: https://example.com/v1.0.0\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		version  string
+		want     string
+	}{
+		{
+			name:     "new file",
+			existing: "",
+			version:  "1.1.0",
+			want:     "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n## [1.1.0]\n\n### Bug Fixes\n\n- new fix\n",
+		},
+		{
+			name:     "version goes below Unreleased",
+			existing: existing,
+			version:  "1.1.0",
+			want:     "# Changelog\n\nIntro.\n\n## [Unreleased]\n\n- notes\n\n## [1.1.0]\n\n### Bug Fixes\n\n- new fix\n\n## [1.0.0]\n\n- old\n\n[1.0.0]: https://example.com/v1.0.0\n",
+		},
+		{
+			name:     "Unreleased is replaced",
+			existing: existing,
+			version:  Unreleased,
+			want:     "# Changelog\n\nIntro.\n\n## [Unreleased]\n\n### Bug Fixes\n\n- new fix\n\n## [1.0.0]\n\n- old\n\n[1.0.0]: https://example.com/v1.0.0\n",
+		},
+		{
+			name:     "last section keeps its link references",
+			existing: existing,
+			version:  "1.0.0",
+			want:     "# Changelog\n\nIntro.\n\n## [Unreleased]\n\n- notes\n\n## [1.0.0]\n\n### Bug Fixes\n\n- new fix\n\n[1.0.0]: https://example.com/v1.0.0\n",
+		},
+		{
+			name:     "no sections",
+			existing: "# Changelog",
+			version:  "1.1.0",
+			want:     "# Changelog\n\n## [1.1.0]\n\n### Bug Fixes\n\n- new fix\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := release(tt.version).Merge(tt.existing); got != tt.want {
+				t.Errorf("unexpected changelog:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestPropose(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"CHANGELOG.md": "# Changelog\n\n## [0.1.0]\n\n- first\n"}, workspacetest.WithCommit("chore: initial"))
+	ws.Git("tag", "v0.1.0")
+	ws.Commit("feat(cli): add changelog")
+	ws.Commit("fix: keep notes")
+	ws.Commit("Bump deps")
+
+	now := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	proposal, err := Propose(context.Background(), ws.Path(""), Options{Version: "v0.2.0", Now: now})
+	if err != nil {
+		t.Fatalf("Propose failed: %v", err)
+	}
+	if proposal.Release.Since != "v0.1.0" || len(proposal.Release.Entries) != 3 {
+		t.Fatalf("expected 3 commits since v0.1.0, got %+v", proposal.Release)
+	}
+	for _, want := range []string{"## [0.2.0] - 2026-03-04", "- **cli:** add changelog", "### Bug Fixes", "### Other Changes\n\n- Bump deps", "## [0.1.0]"} {
+		if !strings.Contains(proposal.Updated, want) {
+			t.Errorf("expected the update to contain %q, got:\n%s", want, proposal.Updated)
+		}
+	}
+	ws.AssertNotContains("CHANGELOG.md", "0.2.0")
+
+	// Once tagged, as in CI, the release still covers the same commits
+	ws.Git("tag", "v0.2.0")
+	tagged, err := Propose(context.Background(), ws.Path(""), Options{Version: "0.2.0", Now: now})
+	if err != nil {
+		t.Fatalf("Propose after tagging failed: %v", err)
+	}
+	if tagged.Updated != proposal.Updated {
+		t.Errorf("expected the same update after tagging, got:\n%s", tagged.Updated)
+	}
+
+	if err := proposal.Apply(); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	ws.AssertFile("CHANGELOG.md", proposal.Updated)
+
+	// A stale proposal doesn't overwrite the file
+	if err := tagged.Apply(); err == nil || !strings.Contains(err.Error(), "changed since") {
+		t.Errorf("expected a stale proposal to be refused, got %v", err)
+	}
+}
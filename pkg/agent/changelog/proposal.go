@@ -0,0 +1,153 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+)
+
+// Separators in the git log format: between fields and between commits
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+)
+
+// Proposal is a changelog update awaiting approval
+type Proposal struct {
+	Path     string // Absolute path of the changelog
+	Original string // Contents when the proposal was made; "" for a new file
+	Updated  string
+	Release  *Release
+}
+
+// Options configure Propose
+type Options struct {
+	File    string    // Changelog path, relative to the workspace; "" = DefaultFile
+	Version string    // Version being released; "" = an Unreleased section
+	Since   string    // Tag or commit to start after; "" = the latest tag
+	Now     time.Time // Release date; zero = today
+}
+
+// Propose builds the release for the commits since the last tag and merges
+// it into the workspace's changelog. When Version is already tagged at HEAD,
+// as in CI after tagging, the commits since the tag before it are used.
+func Propose(ctx context.Context, workingDir string, opts Options) (*Proposal, error) {
+	release, err := Collect(ctx, workingDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	file := opts.File
+	if file == "" {
+		file = DefaultFile
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, file)
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	return &Proposal{
+		Path:     path,
+		Original: string(original),
+		Updated:  release.Merge(string(original)),
+		Release:  release,
+	}, nil
+}
+
+// Changed reports whether applying the proposal would change the file
+func (p *Proposal) Changed() bool {
+	return p.Original != p.Updated
+}
+
+// Apply writes the updated changelog. It refuses when the file changed
+// since the proposal was made, rather than overwriting those edits.
+func (p *Proposal) Apply() error {
+	current, err := os.ReadFile(p.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", p.Path, err)
+	}
+	if string(current) != p.Original {
+		return fmt.Errorf("%s changed since the update was proposed; run it again", filepath.Base(p.Path))
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(p.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(p.Path, []byte(p.Updated), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p.Path, err)
+	}
+	return nil
+}
+
+// Collect returns the release for the commits after opts.Since, or after
+// the latest tag, up to HEAD. Merge commits are left out.
+func Collect(ctx context.Context, workingDir string, opts Options) (*Release, error) {
+	if strings.HasPrefix(opts.Since, "-") {
+		return nil, fmt.Errorf("invalid tag %q", opts.Since)
+	}
+
+	version := strings.TrimPrefix(opts.Version, "v")
+	release := &Release{Version: version, Since: opts.Since}
+	if version == "" {
+		release.Version = Unreleased
+	} else {
+		now := opts.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		release.Date = now.Format("2006-01-02")
+	}
+
+	if release.Since == "" {
+		tag := LatestTag(ctx, workingDir, "HEAD")
+		if tag != "" && version != "" && strings.TrimPrefix(tag, "v") == version {
+			tag = LatestTag(ctx, workingDir, tag+"^")
+		}
+		release.Since = tag
+	}
+
+	logRange := "HEAD"
+	if release.Since != "" {
+		logRange = release.Since + "..HEAD"
+	}
+	output, err := git.Output(ctx, workingDir, nil, "log", "--no-merges", "--format=%h"+fieldSeparator+"%s"+fieldSeparator+"%b"+recordSeparator, logRange, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	release.Entries = []Entry{}
+	for _, record := range strings.Split(output, recordSeparator) {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), fieldSeparator, 3)
+		if len(fields) < 2 {
+			continue
+		}
+		body := ""
+		if len(fields) == 3 {
+			body = fields[2]
+		}
+		release.Entries = append(release.Entries, ParseCommit(fields[0], fields[1], body))
+	}
+	return release, nil
+}
+
+// LatestTag returns the newest tag reachable from rev, or "" when there is
+// none
+func LatestTag(ctx context.Context, workingDir, rev string) string {
+	output, err := git.Output(ctx, workingDir, nil, "describe", "--tags", "--abbrev=0", rev)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
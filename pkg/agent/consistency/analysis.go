@@ -0,0 +1,216 @@
+package consistency
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	kindSymbol = "symbol"
+	kindFile   = "file"
+
+	// maxScanFileSize skips large files (generated code, data) when searching
+	maxScanFileSize = 1 << 20
+)
+
+// definitionPatterns match declarations of named symbols across common
+// languages. The first capture group is the symbol name.
+var definitionPatterns = []*regexp.Regexp{
+	// Go
+	regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)\s*[\[(]`),
+	regexp.MustCompile(`^type\s+([A-Za-z_]\w*)\s`),
+	// Python
+	regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z_]\w*)\s*\(`),
+	regexp.MustCompile(`^class\s+([A-Za-z_]\w*)`),
+	// JavaScript / TypeScript
+	regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+([A-Za-z_$][\w$]*)`),
+	regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?(?:class|interface|enum)\s+([A-Za-z_$][\w$]*)`),
+	regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s*)?(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*=>`),
+	// Rust
+	regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?fn\s+([A-Za-z_]\w*)`),
+	regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|trait)\s+([A-Za-z_]\w*)`),
+}
+
+// ignoredSymbols are names too generic or conventional to report
+var ignoredSymbols = map[string]bool{
+	"main": true,
+	"init": true,
+	"new":  true,
+}
+
+// removedItem is a symbol or file that disappeared in a diff.
+type removedItem struct {
+	kind   string
+	name   string
+	source string         // File the symbol was removed from
+	word   *regexp.Regexp // Whole-word matcher for symbols
+}
+
+// definedSymbol returns the symbol declared on line, if any.
+func definedSymbol(line string) string {
+	line = strings.TrimSpace(line)
+	for _, pattern := range definitionPatterns {
+		if m := pattern.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// analyzeDiff extracts symbols whose definitions were removed (and not re-added
+// anywhere in the diff) and files that were deleted or renamed away.
+func analyzeDiff(diff string) []removedItem {
+	removedDefs := make(map[string]string) // name -> file it was removed from
+	var removedOrder []string
+	addedDefs := make(map[string]bool)
+	var removedFiles []string
+
+	currentFile := ""
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			currentFile = parseDiffHeaderPath(line)
+		case strings.HasPrefix(line, "rename from "):
+			removedFiles = append(removedFiles, strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "deleted file mode"):
+			removedFiles = append(removedFiles, currentFile)
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			// File headers
+		case strings.HasPrefix(line, "-"):
+			if name := definedSymbol(line[1:]); name != "" {
+				if _, seen := removedDefs[name]; !seen {
+					removedDefs[name] = currentFile
+					removedOrder = append(removedOrder, name)
+				}
+			}
+		case strings.HasPrefix(line, "+"):
+			if name := definedSymbol(line[1:]); name != "" {
+				addedDefs[name] = true
+			}
+		}
+	}
+
+	var items []removedItem
+	for _, path := range removedFiles {
+		items = append(items, removedItem{kind: kindFile, name: path})
+	}
+	for _, name := range removedOrder {
+		if addedDefs[name] || ignoredSymbols[strings.ToLower(name)] || len(name) < 3 {
+			continue
+		}
+		items = append(items, removedItem{
+			kind:   kindSymbol,
+			name:   name,
+			source: removedDefs[name],
+			word:   regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`),
+		})
+	}
+
+	return items
+}
+
+// changedFiles returns the files a diff leaves in place with changes, by
+// their post-image paths; deleted files are left out
+func changedFiles(diff string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(diff, "\n") {
+		if !strings.HasPrefix(line, "+++ b/") {
+			continue
+		}
+		path := strings.TrimPrefix(line, "+++ b/")
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// parseDiffHeaderPath extracts the pre-image path from a "diff --git a/x b/x" line.
+func parseDiffHeaderPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if idx := strings.Index(rest, " b/"); idx >= 0 {
+		rest = rest[:idx]
+	}
+	return strings.TrimPrefix(rest, "a/")
+}
+
+// matches reports whether line references the removed item.
+func (r *removedItem) matches(line string) bool {
+	if r.kind == kindSymbol {
+		return r.word.MatchString(line)
+	}
+
+	if strings.Contains(line, filepath.Base(r.name)) {
+		return true
+	}
+	// Catch extensionless references such as import paths ("pkg/util")
+	stem := strings.TrimSuffix(r.name, filepath.Ext(r.name))
+	return strings.Contains(stem, "/") && strings.Contains(line, stem)
+}
+
+// findReferences reads the given workspace-relative files, those the turn
+// changed, and collects references for each removed item. Symbols that are
+// still defined in them (e.g. a same-named method on another type) are
+// dropped.
+func (c *Checker) findReferences(items []removedItem, paths []string) map[int][]Reference {
+	refs := make(map[int][]Reference)
+	stillDefined := make(map[int]bool)
+	root := c.guard.WorkspaceDir()
+
+	for _, relPath := range paths {
+		path := filepath.Join(root, filepath.FromSlash(relPath))
+		if c.guard.ShouldIgnore(path) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxScanFileSize {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || isBinary(content) {
+			continue
+		}
+
+		for lineNum, line := range strings.Split(string(content), "\n") {
+			defined := definedSymbol(line)
+			for i := range items {
+				item := &items[i]
+				if !item.matches(line) {
+					continue
+				}
+				if item.kind == kindSymbol && defined == item.name {
+					stillDefined[i] = true
+					continue
+				}
+				if len(refs[i]) < c.maxReferences {
+					refs[i] = append(refs[i], Reference{Path: relPath, Line: lineNum + 1, Text: line})
+				}
+			}
+		}
+	}
+
+	for i := range stillDefined {
+		delete(refs, i)
+	}
+	return refs
+}
+
+// isBinary reports whether content looks like a binary file.
+func isBinary(content []byte) bool {
+	head := content
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0
+}
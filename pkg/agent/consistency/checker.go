@@ -0,0 +1,138 @@
+// Package consistency provides a post-turn analysis pass that looks for
+// references to symbols and files that the turn's edits removed or renamed.
+// Agents frequently rename a function and miss a couple of call sites; the
+// checker reports those likely dangling references so they can be fed back
+// to the model before its next turn. Only the files the turn changed are
+// searched, which keeps the pass cheap in large workspaces.
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+const (
+	// defaultMaxReferences caps how many references are reported per finding
+	defaultMaxReferences = 5
+
+	// defaultMaxFindings caps how many removed symbols/files are reported per check
+	defaultMaxFindings = 10
+)
+
+// Checker diffs the workspace against a baseline and searches the changed
+// files for references to symbols and files that disappeared in between.
+type Checker struct {
+	guard         *workspace.Guard
+	baseline      *git.Snapshot
+	maxReferences int
+	maxFindings   int
+}
+
+// NewChecker creates a new consistency checker for the guard's workspace.
+func NewChecker(guard *workspace.Guard) *Checker {
+	return &Checker{
+		guard:         guard,
+		maxReferences: defaultMaxReferences,
+		maxFindings:   defaultMaxFindings,
+	}
+}
+
+// Begin records the current workspace state as the baseline for the next Check.
+func (c *Checker) Begin(ctx context.Context) error {
+	snapshot, err := git.TakeSnapshot(ctx, c.guard.WorkspaceDir())
+	if err != nil {
+		return fmt.Errorf("failed to snapshot workspace: %w", err)
+	}
+	c.baseline = snapshot
+	return nil
+}
+
+// Check analyzes changes since the baseline (or the previous Check) and returns
+// a report of likely dangling references. The baseline then advances, so each
+// change is only analyzed once. A nil report means nothing suspicious was found.
+func (c *Checker) Check(ctx context.Context) (*Report, error) {
+	if c.baseline == nil {
+		return nil, c.Begin(ctx)
+	}
+
+	diff, err := c.baseline.DiffAndAdvance(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff workspace: %w", err)
+	}
+	if diff == "" {
+		return nil, nil
+	}
+
+	removed := analyzeDiff(diff)
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	refs := c.findReferences(removed, changedFiles(diff))
+
+	report := &Report{}
+	for i, r := range removed {
+		if len(report.Findings) >= c.maxFindings {
+			break
+		}
+		if len(refs[i]) == 0 {
+			continue
+		}
+
+		report.Findings = append(report.Findings, Finding{
+			Kind:       r.kind,
+			Name:       r.name,
+			Source:     r.source,
+			References: refs[i],
+		})
+	}
+
+	if len(report.Findings) == 0 {
+		return nil, nil
+	}
+	return report, nil
+}
+
+// Report lists likely dangling references found by a Check.
+type Report struct {
+	Findings []Finding
+}
+
+// Finding is a removed symbol or file that is still referenced elsewhere.
+type Finding struct {
+	Kind       string // "symbol" or "file"
+	Name       string // Symbol name or file path
+	Source     string // File the symbol was removed from (empty for files)
+	References []Reference
+}
+
+// Reference is a single location that still mentions a removed symbol or file.
+type Reference struct {
+	Path string
+	Line int
+	Text string
+}
+
+// Format renders the report as a message for the model.
+func (r *Report) Format() string {
+	var builder strings.Builder
+	builder.WriteString("Consistency check: your last turn's edits removed or renamed the following, but references to them remain:\n")
+
+	for _, f := range r.Findings {
+		if f.Kind == kindFile {
+			builder.WriteString(fmt.Sprintf("\n- File '%s' was deleted or moved; still referenced at:\n", f.Name))
+		} else {
+			builder.WriteString(fmt.Sprintf("\n- Symbol '%s' (removed from %s) is still referenced at:\n", f.Name, f.Source))
+		}
+		for _, ref := range f.References {
+			builder.WriteString(fmt.Sprintf("    %s:%d: %s\n", ref.Path, ref.Line, strings.TrimSpace(ref.Text)))
+		}
+	}
+
+	builder.WriteString("\nReview these locations and update any that are now stale before continuing.")
+	return builder.String()
+}
@@ -0,0 +1,123 @@
+package consistency
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+func TestAnalyzeDiff(t *testing.T) {
+	diff := `diff --git a/pkg/util.go b/pkg/util.go
+index 1111111..2222222 100644
+--- a/pkg/util.go
++++ b/pkg/util.go
+@@ -1,5 +1,5 @@
+ package pkg
+ 
+-func ParseConfig(path string) error {
++func LoadConfig(path string) error {
+ 	return nil
+ }
+-func (s *Server) Start() error {
++func (s *Server) Start(ctx context.Context) error {
+diff --git a/old/helpers.py b/old/helpers.py
+deleted file mode 100644
+--- a/old/helpers.py
++++ /dev/null
+@@ -1,2 +0,0 @@
+-def main():
+-    pass
+`
+
+	items := analyzeDiff(diff)
+
+	got := make(map[string]string)
+	for _, item := range items {
+		got[item.name] = item.kind
+	}
+
+	if got["old/helpers.py"] != kindFile {
+		t.Errorf("expected deleted file to be reported, got %v", got)
+	}
+	if got["ParseConfig"] != kindSymbol {
+		t.Errorf("expected renamed symbol ParseConfig to be reported, got %v", got)
+	}
+	if _, ok := got["Start"]; ok {
+		t.Error("signature change should not be reported as a removal")
+	}
+	if _, ok := got["LoadConfig"]; ok {
+		t.Error("added symbol should not be reported")
+	}
+	if _, ok := got["main"]; ok {
+		t.Error("conventional names should be ignored")
+	}
+
+	if files := changedFiles(diff); len(files) != 1 || files[0] != "pkg/util.go" {
+		t.Errorf("expected only the surviving file to be changed, got %v", files)
+	}
+}
+
+func TestChecker_ReportsDanglingReferences(t *testing.T) {
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("git unavailable: %v: %s", err, out)
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	write("lib.go", "package main\n\nfunc computeTotal() int { return 1 }\n")
+	write("caller.go", "package main\n\nfunc run() int { return computeTotal() }\n")
+	write("report.go", "package main\n\nfunc report() int { return computeTotal() }\n")
+
+	guard, err := workspace.NewGuard(dir)
+	if err != nil {
+		t.Fatalf("failed to create guard: %v", err)
+	}
+
+	checker := NewChecker(guard)
+	if beginErr := checker.Begin(context.Background()); beginErr != nil {
+		t.Fatalf("Begin failed: %v", beginErr)
+	}
+
+	// Rename the function and edit one caller without updating its call;
+	// the untouched caller is outside the turn's changes and not searched
+	write("lib.go", "package main\n\nfunc computeSum() int { return 1 }\n")
+	write("caller.go", "package main\n\nfunc run() int { return computeTotal() }\n\nfunc double() int { return 2 * run() }\n")
+
+	report, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report == nil || len(report.Findings) != 1 {
+		t.Fatalf("expected one finding, got %+v", report)
+	}
+
+	finding := report.Findings[0]
+	if finding.Name != "computeTotal" || len(finding.References) != 1 || finding.References[0].Path != "caller.go" {
+		t.Errorf("unexpected finding: %+v", finding)
+	}
+	if !strings.Contains(report.Format(), "caller.go:3") {
+		t.Errorf("formatted report missing reference location:\n%s", report.Format())
+	}
+
+	// The baseline advances, so the same change is not reported twice
+	report, err = checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("second Check failed: %v", err)
+	}
+	if report != nil {
+		t.Errorf("expected no findings on second check, got %+v", report)
+	}
+}
@@ -2,9 +2,11 @@ package context
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/entrhq/forge/pkg/agent/memory"
@@ -28,17 +30,30 @@ func init() {
 
 // Manager orchestrates multiple context summarization strategies,
 // evaluating them in order and emitting events for TUI feedback.
+// Only one summarization runs at a time.
 type Manager struct {
-	strategies   []Strategy
-	llm          llm.Provider
-	tokenizer    *tokenizer.Tokenizer
-	maxTokens    int
-	eventChannel chan<- *types.AgentEvent
+	strategies []Strategy
+	llm        llm.Provider
+	tokenizer  *tokenizer.Tokenizer
+	maxTokens  int
+	emit       func(*types.AgentEvent)
+
+	mu      sync.Mutex
+	running *run // The summarization in progress, if any
+}
+
+// run is a summarization in progress
+type run struct {
+	cancel context.CancelCauseFunc
+	done   chan struct{}
 }
 
+// errSkipped is the cause Skip cancels a summarization with
+var errSkipped = errors.New("context summarization skipped")
+
 // NewManager creates a new context manager with the given strategies.
 // Strategies are evaluated in the order provided.
-// The event channel should be set later via SetEventChannel() once the agent creates it.
+// The event emitter should be set later via SetEventEmitter() once the agent creates it.
 func NewManager(llm llm.Provider, maxTokens int, strategies ...Strategy) (*Manager, error) {
 	// Create tokenizer for accurate token counting
 	tok, err := tokenizer.New()
@@ -47,35 +62,142 @@ func NewManager(llm llm.Provider, maxTokens int, strategies ...Strategy) (*Manag
 	}
 
 	return &Manager{
-		strategies:   strategies,
-		llm:          llm,
-		tokenizer:    tok,
-		maxTokens:    maxTokens,
-		eventChannel: nil, // Will be set by agent during initialization
+		strategies: strategies,
+		llm:        llm,
+		tokenizer:  tok,
+		maxTokens:  maxTokens,
+		emit:       nil, // Will be set by agent during initialization
 	}, nil
 }
 
-// SetEventChannel sets the event channel for emitting summarization events.
-// This is called by the agent during initialization after channels are created.
-// It also propagates the event channel to strategies that support progress events.
-func (m *Manager) SetEventChannel(eventChan chan<- *types.AgentEvent) {
-	m.eventChannel = eventChan
+// SetEventEmitter sets the function used to emit summarization events.
+// This is called by the agent during initialization, so that the events
+// reach its subscribers as well as its event channel. It also propagates
+// the emitter to strategies that support progress events.
+func (m *Manager) SetEventEmitter(emit func(*types.AgentEvent)) {
+	m.emit = emit
 
 	// Propagate to strategies that support event emission
 	for _, strategy := range m.strategies {
-		// Type assert to check if strategy supports SetEventChannel
+		// Type assert to check if strategy supports SetEventEmitter
 		if setter, ok := strategy.(interface {
-			SetEventChannel(chan<- *types.AgentEvent)
+			SetEventEmitter(func(*types.AgentEvent))
 		}); ok {
-			setter.SetEventChannel(eventChan)
+			setter.SetEventEmitter(emit)
+		}
+	}
+}
+
+// SetEventChannel emits summarization events directly on eventChan.
+// Progress events are dropped when the channel is full.
+func (m *Manager) SetEventChannel(eventChan chan<- *types.AgentEvent) {
+	m.SetEventEmitter(func(event *types.AgentEvent) {
+		types.SendEvent(eventChan, event, nil)
+	})
+}
+
+// EvaluateAndSummarize evaluates all strategies and performs summarization if needed,
+// first waiting for any summarization already running to finish. It blocks the
+// caller but emits events to keep the TUI responsive, and returns early if Skip
+// is called. Returns the total number of messages summarized across all strategies.
+func (m *Manager) EvaluateAndSummarize(ctx context.Context, conv memory.Memory, currentTokens int) (int, error) {
+	for {
+		m.mu.Lock()
+		if m.running == nil {
+			r, runCtx := m.begin(ctx)
+			m.mu.Unlock()
+			defer m.end(r)
+			return m.summarize(runCtx, conv, currentTokens)
+		}
+		done := m.running.done
+		m.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// Start runs EvaluateAndSummarize in the background, off the agent's critical
+// path, unless a summarization is already running. The conversation can keep
+// growing meanwhile: each strategy summarizes a snapshot, which then replaces
+// the messages it was taken from. It reports whether a summarization was started.
+func (m *Manager) Start(ctx context.Context, conv memory.Memory, currentTokens int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running != nil {
+		return false
+	}
+
+	r, runCtx := m.begin(ctx)
+	go func() {
+		defer m.end(r)
+		if _, err := m.summarize(runCtx, conv, currentTokens); err != nil {
+			debugLog.Printf("Background summarization failed: %v", err)
 		}
+	}()
+	return true
+}
+
+// Skip cancels the summarization in progress, leaving the conversation as it
+// was. It reports whether a summarization was running.
+func (m *Manager) Skip() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running == nil {
+		return false
+	}
+	m.running.cancel(errSkipped)
+	return true
+}
+
+// Running reports whether a summarization is in progress.
+func (m *Manager) Running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running != nil
+}
+
+// Wait blocks until the summarization in progress, if any, has finished.
+func (m *Manager) Wait(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running == nil {
+		m.mu.Unlock()
+		return nil
 	}
+	done := m.running.done
+	m.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// begin records a new summarization as running. Must be called with m.mu held.
+func (m *Manager) begin(ctx context.Context) (*run, context.Context) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	r := &run{cancel: cancel, done: make(chan struct{})}
+	m.running = r
+	return r, runCtx
+}
+
+// end marks the summarization r as finished
+func (m *Manager) end(r *run) {
+	m.mu.Lock()
+	m.running = nil
+	m.mu.Unlock()
+	r.cancel(nil)
+	close(r.done)
 }
 
-// EvaluateAndSummarize evaluates all strategies and performs summarization if needed.
-// This operation blocks the agent loop but emits events to keep the TUI responsive.
-// Returns the total number of messages summarized across all strategies.
-func (m *Manager) EvaluateAndSummarize(ctx context.Context, conv *memory.ConversationMemory, currentTokens int) (int, error) {
+// summarize runs each strategy that should run on a snapshot of conv and
+// applies its result
+func (m *Manager) summarize(ctx context.Context, conv memory.Memory, currentTokens int) (int, error) {
 	totalSummarized := 0
 
 	// Evaluate each strategy in order
@@ -86,32 +208,48 @@ func (m *Manager) EvaluateAndSummarize(ctx context.Context, conv *memory.Convers
 		}
 
 		// Emit start event
-		if m.eventChannel != nil {
+		if m.emit != nil {
 			debugLog.Printf("Emitting start event for strategy %s", strategy.Name())
-			m.eventChannel <- types.NewContextSummarizationStartEvent(
+			m.emit(types.NewContextSummarizationStartEvent(
 				strategy.Name(),
 				currentTokens,
 				m.maxTokens,
-			)
+			))
 		}
 
 		startTime := time.Now()
 
-		// Execute summarization (blocking operation)
+		// Summarize a snapshot, so messages added meanwhile aren't lost
+		snapshot := conv.GetAll()
+		work := memory.NewConversationMemory()
+		work.AddMultiple(snapshot)
+
 		debugLog.Printf("Executing Summarize() for strategy %s", strategy.Name())
-		summarizedCount, err := strategy.Summarize(ctx, conv, m.llm)
+		summarizedCount, err := strategy.Summarize(ctx, work, m.llm)
+		if errors.Is(context.Cause(ctx), errSkipped) {
+			debugLog.Printf("Strategy %s skipped", strategy.Name())
+			m.emitSkipped(strategy)
+			return totalSummarized, nil
+		}
 		if err != nil {
 			debugLog.Printf("Strategy %s failed with error: %v", strategy.Name(), err)
 			// Emit error event
-			if m.eventChannel != nil {
-				m.eventChannel <- types.NewContextSummarizationErrorEvent(
+			if m.emit != nil {
+				m.emit(types.NewContextSummarizationErrorEvent(
 					strategy.Name(),
 					err,
-				)
+				))
 			}
 			return totalSummarized, fmt.Errorf("strategy %s failed: %w", strategy.Name(), err)
 		}
 
+		if !conv.ReplacePrefix(snapshot, work.GetAll()) {
+			// Cleared or pruned meanwhile, so the summary no longer applies
+			debugLog.Printf("Conversation changed during strategy %s; discarding its summary", strategy.Name())
+			m.emitSkipped(strategy)
+			return totalSummarized, nil
+		}
+
 		duration := time.Since(startTime)
 		totalSummarized += summarizedCount
 		debugLog.Printf("Strategy %s summarized %d messages in %s", strategy.Name(), summarizedCount, duration)
@@ -128,15 +266,15 @@ func (m *Manager) EvaluateAndSummarize(ctx context.Context, conv *memory.Convers
 		durationStr := duration.String()
 
 		// Emit complete event
-		if m.eventChannel != nil {
+		if m.emit != nil {
 			debugLog.Printf("Emitting complete event for strategy %s", strategy.Name())
-			m.eventChannel <- types.NewContextSummarizationCompleteEvent(
+			m.emit(types.NewContextSummarizationCompleteEvent(
 				strategy.Name(),
 				tokensSaved,
 				newTokenCount,
 				summarizedCount,
 				durationStr,
-			)
+			))
 		}
 
 		// Update current tokens for next strategy
@@ -146,6 +284,13 @@ func (m *Manager) EvaluateAndSummarize(ctx context.Context, conv *memory.Convers
 	return totalSummarized, nil
 }
 
+// emitSkipped reports that strategy's summarization was abandoned
+func (m *Manager) emitSkipped(strategy Strategy) {
+	if m.emit != nil {
+		m.emit(types.NewContextSummarizationSkippedEvent(strategy.Name()))
+	}
+}
+
 // AddStrategy adds a new strategy to the manager.
 // The strategy will be evaluated after existing strategies.
 func (m *Manager) AddStrategy(strategy Strategy) {
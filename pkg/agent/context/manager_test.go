@@ -0,0 +1,176 @@
+package context
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+	tiktoken "github.com/pkoukk/tiktoken-go"
+)
+
+// byteBpeLoader stands in for the cl100k vocabulary, which is downloaded on
+// first use, with one token per byte
+type byteBpeLoader struct{}
+
+func (byteBpeLoader) LoadTiktokenBpe(string) (map[string]int, error) {
+	ranks := make(map[string]int)
+	for b := 0; b < 256; b++ {
+		ranks[string([]byte{byte(b)})] = b
+	}
+	return ranks, nil
+}
+
+func TestMain(m *testing.M) {
+	tiktoken.SetBpeLoader(byteBpeLoader{})
+	os.Exit(m.Run())
+}
+
+// blockingStrategy replaces the whole conversation with one summary once
+// release is closed
+type blockingStrategy struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newBlockingStrategy() *blockingStrategy {
+	return &blockingStrategy{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (s *blockingStrategy) Name() string { return "blocking" }
+
+func (s *blockingStrategy) ShouldRun(memory.Memory, int, int) bool { return true }
+
+func (s *blockingStrategy) Summarize(ctx context.Context, conv memory.Memory, _ llm.Provider) (int, error) {
+	s.once.Do(func() { close(s.started) })
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	count := conv.Count()
+	conv.Clear()
+	conv.Add(types.NewAssistantMessage("summary"))
+	return count, nil
+}
+
+// newTestManager returns a manager running strategy and the events it emits
+func newTestManager(t *testing.T, strategy Strategy) (*Manager, func() []types.AgentEventType) {
+	t.Helper()
+	m, err := NewManager(nil, 1000, strategy)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	var mu sync.Mutex
+	var events []types.AgentEventType
+	m.SetEventEmitter(func(event *types.AgentEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event.Type)
+	})
+	return m, func() []types.AgentEventType {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]types.AgentEventType(nil), events...)
+	}
+}
+
+func TestManager_StartKeepsMessagesAddedMeanwhile(t *testing.T) {
+	strategy := newBlockingStrategy()
+	m, events := newTestManager(t, strategy)
+	conv := memory.NewConversationMemory()
+	conv.AddMultiple([]*types.Message{types.NewUserMessage("one"), types.NewAssistantMessage("two")})
+
+	if !m.Start(context.Background(), conv, 100) {
+		t.Fatal("expected a summarization to start")
+	}
+	<-strategy.started
+	if m.Start(context.Background(), conv, 100) {
+		t.Error("expected only one summarization to run at a time")
+	}
+
+	added := types.NewUserMessage("three")
+	conv.Add(added)
+	close(strategy.release)
+	if err := m.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	all := conv.GetAll()
+	if len(all) != 2 || all[0].Content != "summary" || all[1] != added {
+		t.Errorf("expected the summary followed by the added message, got %v", all)
+	}
+	got := events()
+	if len(got) != 2 || got[0] != types.EventTypeContextSummarizationStart || got[1] != types.EventTypeContextSummarizationComplete {
+		t.Errorf("events = %v, want start then complete", got)
+	}
+	if m.Running() {
+		t.Error("expected no summarization to be running")
+	}
+}
+
+func TestManager_Skip(t *testing.T) {
+	strategy := newBlockingStrategy()
+	m, events := newTestManager(t, strategy)
+	conv := memory.NewConversationMemory()
+	conv.Add(types.NewUserMessage("one"))
+
+	done := make(chan int)
+	go func() {
+		count, err := m.EvaluateAndSummarize(context.Background(), conv, 100)
+		if err != nil {
+			t.Errorf("expected a skip not to be an error, got %v", err)
+		}
+		done <- count
+	}()
+	<-strategy.started
+
+	if !m.Skip() {
+		t.Fatal("expected a summarization to be skipped")
+	}
+	select {
+	case count := <-done:
+		if count != 0 {
+			t.Errorf("summarized %d messages, want 0", count)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected EvaluateAndSummarize to return once skipped")
+	}
+
+	if all := conv.GetAll(); len(all) != 1 || all[0].Content != "one" {
+		t.Errorf("expected the conversation to be left as it was, got %v", all)
+	}
+	if got := events(); len(got) != 2 || got[1] != types.EventTypeContextSummarizationSkipped {
+		t.Errorf("events = %v, want start then skipped", got)
+	}
+	if m.Skip() {
+		t.Error("expected nothing to skip once finished")
+	}
+}
+
+func TestManager_DiscardsSummaryOfClearedConversation(t *testing.T) {
+	strategy := newBlockingStrategy()
+	m, events := newTestManager(t, strategy)
+	conv := memory.NewConversationMemory()
+	conv.Add(types.NewUserMessage("one"))
+
+	m.Start(context.Background(), conv, 100)
+	<-strategy.started
+	conv.Clear()
+	close(strategy.release)
+	if err := m.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	if conv.Count() != 0 {
+		t.Errorf("expected the cleared conversation to stay empty, got %v", conv.GetAll())
+	}
+	if got := events(); len(got) != 2 || got[1] != types.EventTypeContextSummarizationSkipped {
+		t.Errorf("events = %v, want start then skipped", got)
+	}
+}
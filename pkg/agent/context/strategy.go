@@ -17,11 +17,11 @@ type Strategy interface {
 	// ShouldRun evaluates whether this strategy should execute on this turn.
 	// It receives the current conversation memory, current token count, and max allowed tokens.
 	// Returns true if the strategy should be applied.
-	ShouldRun(conv *memory.ConversationMemory, currentTokens, maxTokens int) bool
+	ShouldRun(conv memory.Memory, currentTokens, maxTokens int) bool
 
 	// Summarize performs the actual summarization operation.
 	// It receives a context (for cancellation), the conversation memory to summarize,
 	// and an LLM provider for generating summaries.
 	// Returns number of tokens saved and any error. The conversation is modified in place.
-	Summarize(ctx context.Context, conv *memory.ConversationMemory, llm llm.Provider) (int, error)
+	Summarize(ctx context.Context, conv memory.Memory, llm llm.Provider) (int, error)
 }
@@ -49,7 +49,7 @@ func (s *ThresholdSummarizationStrategy) Name() string {
 }
 
 // ShouldRun returns true when current token usage exceeds the threshold
-func (s *ThresholdSummarizationStrategy) ShouldRun(conv *memory.ConversationMemory, currentTokens, maxTokens int) bool {
+func (s *ThresholdSummarizationStrategy) ShouldRun(conv memory.Memory, currentTokens, maxTokens int) bool {
 	if maxTokens <= 0 {
 		return false
 	}
@@ -62,7 +62,7 @@ func (s *ThresholdSummarizationStrategy) ShouldRun(conv *memory.ConversationMemo
 }
 
 // Summarize creates summaries for old messages to free up context space
-func (s *ThresholdSummarizationStrategy) Summarize(ctx context.Context, conv *memory.ConversationMemory, llm llm.Provider) (int, error) {
+func (s *ThresholdSummarizationStrategy) Summarize(ctx context.Context, conv memory.Memory, llm llm.Provider) (int, error) {
 	messages := conv.GetAll()
 	if len(messages) == 0 {
 		return 0, nil
@@ -102,8 +102,8 @@ func (s *ThresholdSummarizationStrategy) collectMessagesToSummarize(messages []*
 	for i := startIdx; i < len(messages) && len(toSummarize) < s.messagesPerSummary; i++ {
 		msg := messages[i]
 
-		// Skip already summarized messages
-		if s.isSummarized(msg) {
+		// Skip already summarized messages and turn summaries, which are kept as anchors
+		if s.isSummarized(msg) || memory.IsTurnSummary(msg) {
 			continue
 		}
 
@@ -152,7 +152,7 @@ func (s *ThresholdSummarizationStrategy) generateSummary(ctx context.Context, to
 }
 
 // replaceMessagesWithSummary removes summarized messages and inserts the summary
-func (s *ThresholdSummarizationStrategy) replaceMessagesWithSummary(conv *memory.ConversationMemory, messages []*types.Message, toSummarize []*types.Message, summary *types.Message) error {
+func (s *ThresholdSummarizationStrategy) replaceMessagesWithSummary(conv memory.Memory, messages []*types.Message, toSummarize []*types.Message, summary *types.Message) error {
 	// Find the index of the first message to summarize
 	firstIdx := s.findMessageIndex(messages, toSummarize[0])
 	if firstIdx == -1 {
@@ -160,7 +160,7 @@ func (s *ThresholdSummarizationStrategy) replaceMessagesWithSummary(conv *memory
 	}
 
 	// Build new message list
-	newMessages := s.buildNewMessageList(messages, firstIdx, len(toSummarize), summary)
+	newMessages := s.buildNewMessageList(messages, firstIdx, toSummarize, summary)
 
 	// Clear and re-add all messages
 	conv.Clear()
@@ -179,19 +179,23 @@ func (s *ThresholdSummarizationStrategy) findMessageIndex(messages []*types.Mess
 	return -1
 }
 
-// buildNewMessageList creates a new message list with the summary replacing the batch
-func (s *ThresholdSummarizationStrategy) buildNewMessageList(messages []*types.Message, firstIdx, replaceCount int, summary *types.Message) []*types.Message {
-	var newMessages []*types.Message
-
-	// Keep messages before the summarized range
-	newMessages = append(newMessages, messages[:firstIdx]...)
-
-	// Add the summary
-	newMessages = append(newMessages, summary)
+// buildNewMessageList creates a new message list with the summary in place of
+// the batch. Messages skipped while collecting the batch (earlier summaries,
+// turn summaries) are kept.
+func (s *ThresholdSummarizationStrategy) buildNewMessageList(messages []*types.Message, firstIdx int, toSummarize []*types.Message, summary *types.Message) []*types.Message {
+	summarized := make(map[*types.Message]bool, len(toSummarize))
+	for _, msg := range toSummarize {
+		summarized[msg] = true
+	}
 
-	// Keep messages after the summarized range
-	if firstIdx+replaceCount < len(messages) {
-		newMessages = append(newMessages, messages[firstIdx+replaceCount:]...)
+	newMessages := make([]*types.Message, 0, len(messages)-len(toSummarize)+1)
+	for i, msg := range messages {
+		if i == firstIdx {
+			newMessages = append(newMessages, summary)
+		}
+		if !summarized[msg] {
+			newMessages = append(newMessages, msg)
+		}
 	}
 
 	return newMessages
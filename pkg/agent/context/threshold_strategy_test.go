@@ -0,0 +1,41 @@
+package context
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestThresholdSummarize_KeepsTurnSummaries tests that turn summaries are skipped
+// when collecting a batch and remain in the conversation
+func TestThresholdSummarize_KeepsTurnSummaries(t *testing.T) {
+	strategy := NewThresholdSummarizationStrategy(80, 3)
+	conv := memory.NewConversationMemory()
+
+	system := types.NewSystemMessage("system")
+	first := types.NewUserMessage("first")
+	turnSummary := types.NewUserMessage("[Turn 1 summary] Request: first").
+		WithMetadata(memory.TurnSummaryKey, true)
+	second := types.NewAssistantMessage("second")
+	third := types.NewUserMessage("third")
+	fourth := types.NewAssistantMessage("fourth")
+	conv.AddMultiple([]*types.Message{system, first, turnSummary, second, third, fourth})
+
+	mockLLM := new(MockLLMProvider)
+	mockLLM.On("Complete", mock.Anything, mock.Anything).Return(types.NewAssistantMessage("Summary"), nil)
+
+	count, err := strategy.Summarize(context.Background(), conv, mockLLM)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	messages := conv.GetAll()
+	assert.Len(t, messages, 4)
+	assert.Equal(t, system, messages[0])
+	assert.True(t, isSummarized(messages[1]), "summary should replace the batch")
+	assert.Equal(t, turnSummary, messages[2])
+	assert.Equal(t, fourth, messages[3])
+}
@@ -30,8 +30,8 @@ type ToolCallSummarizationStrategy struct {
 	// These are typically loop-breaking tools or tools with high semantic value.
 	excludedTools map[string]bool
 
-	// eventChannel is used to emit progress events during parallel summarization
-	eventChannel chan<- *types.AgentEvent
+	// emit is used to emit progress events during parallel summarization
+	emit func(*types.AgentEvent)
 }
 
 // NewToolCallSummarizationStrategy creates a new tool call summarization strategy with buffering.
@@ -70,13 +70,13 @@ func NewToolCallSummarizationStrategy(messagesOldThreshold, minToolCallsToSummar
 		minToolCallsToSummarize: minToolCallsToSummarize,
 		maxToolCallDistance:     maxToolCallDistance,
 		excludedTools:           exclusionMap,
-		eventChannel:            nil, // Will be set by Manager
+		emit:                    nil, // Will be set by Manager
 	}
 }
 
-// SetEventChannel sets the event channel for emitting progress events during summarization.
-func (s *ToolCallSummarizationStrategy) SetEventChannel(eventChan chan<- *types.AgentEvent) {
-	s.eventChannel = eventChan
+// SetEventEmitter sets the function used to emit progress events during summarization.
+func (s *ToolCallSummarizationStrategy) SetEventEmitter(emit func(*types.AgentEvent)) {
+	s.emit = emit
 }
 
 // Name returns the strategy's identifier.
@@ -88,7 +88,7 @@ func (s *ToolCallSummarizationStrategy) Name() string {
 // Returns true if either:
 // 1. Buffer trigger: Buffer contains >= minToolCallsToSummarize tool calls
 // 2. Age trigger: Any tool call is >= maxToolCallDistance messages old
-func (s *ToolCallSummarizationStrategy) ShouldRun(conv *memory.ConversationMemory, currentTokens, maxTokens int) bool {
+func (s *ToolCallSummarizationStrategy) ShouldRun(conv memory.Memory, currentTokens, maxTokens int) bool {
 	messages := conv.GetAll()
 	totalMessages := len(messages)
 
@@ -146,7 +146,7 @@ func (s *ToolCallSummarizationStrategy) ShouldRun(conv *memory.ConversationMemor
 // Summarize compresses buffered tool calls and their results using LLM-based summarization.
 // All tool calls that are >= messagesOldThreshold old will be summarized when triggered,
 // except for tools in the exclusion list.
-func (s *ToolCallSummarizationStrategy) Summarize(ctx context.Context, conv *memory.ConversationMemory, llm llm.Provider) (int, error) {
+func (s *ToolCallSummarizationStrategy) Summarize(ctx context.Context, conv memory.Memory, llm llm.Provider) (int, error) {
 	messages := conv.GetAll()
 	if len(messages) <= s.messagesOldThreshold {
 		return 0, nil
@@ -177,8 +177,8 @@ func (s *ToolCallSummarizationStrategy) Summarize(ctx context.Context, conv *mem
 	excludedGroupMessages := make([]*types.Message, 0)
 
 	for _, msg := range oldMessages {
-		// Always keep system messages
-		if msg.Role == types.RoleSystem {
+		// Always keep system messages and turn summaries
+		if msg.Role == types.RoleSystem || memory.IsTurnSummary(msg) {
 			newMessages = append(newMessages, msg)
 			continue
 		}
@@ -299,14 +299,15 @@ func (s *ToolCallSummarizationStrategy) summarizeGroupsParallel(ctx context.Cont
 
 			resultChan <- result{index: idx, message: summary, tokensSaved: tokensSaved, err: err}
 
-			// Emit progress event if event channel is available
-			if s.eventChannel != nil {
-				s.eventChannel <- types.NewContextSummarizationProgressEvent(
+			// Emit progress event if an emitter is set; the emitter drops
+			// it rather than wait on a consumer that has fallen behind
+			if s.emit != nil {
+				s.emit(types.NewContextSummarizationProgressEvent(
 					s.Name(),
 					idx+1,
 					numGroups,
 					tokensSaved,
-				)
+				))
 			}
 		}(i, group)
 	}
@@ -291,3 +291,27 @@ func TestShouldRun_WithExcludedTools(t *testing.T) {
 	// Should run because there are enough old tool calls (even though they'll be excluded during grouping)
 	assert.True(t, shouldRun, "ShouldRun should trigger based on tool call count")
 }
+
+// TestSummarize_KeepsTurnSummaries tests that turn summaries survive tool call summarization
+func TestSummarize_KeepsTurnSummaries(t *testing.T) {
+	strategy := NewToolCallSummarizationStrategy(5, 1, 20)
+	conv := memory.NewConversationMemory()
+
+	conv.Add(types.NewUserMessage("Fix the parser"))
+	conv.Add(types.NewAssistantMessage(`<tool>{"tool_name": "read_file", "arguments": {}}</tool>`))
+	conv.Add(types.NewToolMessage("File content here"))
+	turnSummary := types.NewUserMessage("[Turn 1 summary] Request: Fix the parser").
+		WithMetadata(memory.TurnSummaryKey, true)
+	conv.Add(turnSummary)
+
+	for i := 0; i < 6; i++ {
+		conv.Add(types.NewUserMessage("Recent message"))
+	}
+
+	mockLLM := new(MockLLMProvider)
+	mockLLM.On("Complete", mock.Anything, mock.Anything).Return(types.NewAssistantMessage("Summary"), nil)
+
+	_, err := strategy.Summarize(context.Background(), conv, mockLLM)
+	assert.NoError(t, err)
+	assert.Contains(t, conv.GetAll(), turnSummary, "turn summary should be kept")
+}
@@ -15,27 +15,35 @@ import (
 type streamState struct {
 	assistantContent string
 	thinkingContent  string
-	toolCallContent  string
-	toolCallBuffer   string // buffer content until tool name is detected
+	toolCalls        []string // content of each complete tool call, in order
+	toolCallBuffer   string   // buffer content until tool name is detected
 	role             string
 	messageStarted   bool
 	thinkingStarted  bool
 	toolCallStarted  bool
 	toolNameDetected bool // tracks if we've detected and emitted the tool name
 	toolNameEmitted  bool // tracks if we've emitted buffered content after tool name
-	toolCallParser   *parser.ToolCallParser
+	toolName         string
+	parser           *parser.Parser
+
+	// The reply of a message tool such as converse, streamed as message content
+	toolMessageStarted bool
+	toolMessageEnded   bool
+	toolMessageSent    int // Bytes of the decoded reply emitted so far
 }
 
 // ProcessStream processes a stream of chunks, emitting events and calling
 // the completion handler when done. This provides reusable stream processing
-// logic that any agent can use.
+// logic that any agent can use. A response may contain several tool calls;
+// onComplete receives the content of each, without its <tool> tags, in order.
 func ProcessStream(
 	stream <-chan *llm.StreamChunk,
 	emitEvent func(*types.AgentEvent),
-	onComplete func(assistantContent, thinkingContent, toolCallContent, role string),
+	onComplete func(assistantContent, thinkingContent string, toolCalls []string, role string),
 ) {
 	state := &streamState{
-		toolCallParser: parser.NewToolCallParser(),
+		// Providers have already split out thinking
+		parser: parser.New(parser.Config{ToolTag: parser.DefaultToolTag, ExtractToolCalls: true}),
 	}
 
 	for chunk := range stream {
@@ -67,6 +75,9 @@ func handleError(err error, state *streamState, emitEvent func(*types.AgentEvent
 	if state.thinkingStarted {
 		emitEvent(types.NewThinkingEndEvent())
 	}
+	if state.toolMessageStarted && !state.toolMessageEnded {
+		emitEvent(types.NewMessageEndEvent())
+	}
 	if state.toolCallStarted {
 		emitEvent(types.NewToolCallEndEvent())
 	}
@@ -76,10 +87,7 @@ func handleError(err error, state *streamState, emitEvent func(*types.AgentEvent
 
 	// Don't emit error events for context cancellation - this is expected when user stops the agent
 	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
-		// Check if error message contains "context canceled" (some wrapped errors don't preserve type)
-		if err.Error() != "stream read error: context canceled" {
-			emitEvent(types.NewErrorEvent(err))
-		}
+		emitEvent(types.NewErrorEvent(err))
 	}
 }
 
@@ -111,37 +119,61 @@ func handleMessageContent(content string, state *streamState, emitEvent func(*ty
 		state.thinkingStarted = false
 	}
 
-	// Parse content for tool calls
-	toolCallContent, regularContent := state.toolCallParser.Parse(content)
+	// Parse content for tool calls, handling the text around them in order
+	handleSegments(state.parser.Parse(content), state, emitEvent)
 
-	// Handle tool call start signal - emit immediately when <tool> is detected
-	if toolCallContent != nil && toolCallContent.Type == "tool_call_start" {
-		// Close any active message before starting tool call
-		if state.messageStarted {
-			emitEvent(types.NewMessageEndEvent())
-			state.messageStarted = false
-		}
+	// Check for tool name in accumulated content after tool call start
+	checkAndEmitToolName(state, emitEvent)
 
-		// Emit the tool call start event for immediate UI feedback
-		if !state.toolCallStarted {
-			emitEvent(types.NewToolCallStartEvent())
-			state.toolCallStarted = true
-		}
-		return
+	// Stream a message tool's reply as it arrives
+	if state.parser.InToolCall() {
+		streamToolMessage(state.parser.ToolContent(), false, state, emitEvent)
 	}
+}
 
-	// Check for tool name in accumulated content after tool call start
-	checkAndEmitToolName(state, emitEvent)
+// handleSegments emits events for parsed message content
+func handleSegments(segments []parser.Segment, state *streamState, emitEvent func(*types.AgentEvent)) {
+	for _, segment := range segments {
+		switch segment.Kind {
+		case parser.SegmentToolCallStart:
+			handleToolCallStart(state, emitEvent)
+		case parser.SegmentToolCall:
+			if segment.Content != "" {
+				handleToolCallContent(segment.Content, state, emitEvent)
+			}
+		case parser.SegmentThinking:
+			handleThinkingContent(segment.Content, state, emitEvent)
+		default:
+			// Whitespace between tool calls isn't a message
+			if segment.Content != "" && (!state.toolCallStarted || strings.TrimSpace(segment.Content) != "") {
+				handleRegularContent(segment.Content, state, emitEvent)
+			}
+		}
+	}
+}
 
-	// Handle complete tool call content (when </tool> is detected)
-	if toolCallContent != nil && toolCallContent.Type == "tool_call" && toolCallContent.Content != "" {
-		handleToolCallContent(toolCallContent.Content, state, emitEvent)
+// handleToolCallStart emits the tool call start as soon as <tool> is
+// detected, for immediate UI feedback
+func handleToolCallStart(state *streamState, emitEvent func(*types.AgentEvent)) {
+	// Close any active message before starting tool call
+	if state.messageStarted {
+		emitEvent(types.NewMessageEndEvent())
+		state.messageStarted = false
 	}
 
-	// Handle regular message content
-	if regularContent != nil && regularContent.Content != "" {
-		handleRegularContent(regularContent.Content, state, emitEvent)
+	// End the previous tool call when another follows it
+	if state.toolCallStarted {
+		emitEvent(types.NewToolCallEndEvent())
 	}
+	emitEvent(types.NewToolCallStartEvent())
+	state.toolCallStarted = true
+	state.toolNameDetected = false
+	state.toolNameEmitted = false
+	state.toolCallBuffer = ""
+	state.toolName = ""
+	state.toolMessageStarted = false
+	state.toolMessageEnded = false
+	state.toolMessageSent = 0
 }
 
 // checkAndEmitToolName checks for tool name in accumulated content and emits early detection event
@@ -150,11 +182,12 @@ func checkAndEmitToolName(state *streamState, emitEvent func(*types.AgentEvent))
 		return
 	}
 
-	accumulatedContent := state.toolCallParser.GetAccumulatedToolContent()
+	accumulatedContent := state.parser.ToolContent()
 	toolName := extractToolNameFromPartial(accumulatedContent)
 
 	if toolName != "" {
 		state.toolNameDetected = true
+		state.toolName = toolName
 		event := types.NewToolCallStartEvent()
 		event.Metadata["tool_name"] = toolName
 		emitEvent(event)
@@ -193,16 +226,17 @@ func handleToolCallContent(content string, state *streamState, emitEvent func(*t
 		state.toolCallStarted = true
 	}
 
-	// Accumulate tool call content
-	state.toolCallContent += content
+	// Record the complete tool call
+	state.toolCalls = append(state.toolCalls, content)
 
 	// If we haven't detected the tool name yet, buffer the content
 	if !state.toolNameDetected {
 		state.toolCallBuffer += content
 
 		// Try to detect tool name from accumulated buffer
-		if toolName := extractToolNameFromPartial(state.toolCallContent); toolName != "" {
+		if toolName := extractToolNameFromPartial(state.toolCallBuffer); toolName != "" {
 			state.toolNameDetected = true
+			state.toolName = toolName
 
 			// Emit EventTypeToolCallStart with the tool name in metadata
 			// This provides early feedback to the UI
@@ -213,6 +247,7 @@ func handleToolCallContent(content string, state *streamState, emitEvent func(*t
 			// Now emit all buffered content at once
 			emitEvent(types.NewToolCallContentEvent(state.toolCallBuffer))
 			state.toolNameEmitted = true
+			streamToolMessage(content, true, state, emitEvent)
 		}
 		// Don't emit content events until we have the tool name
 		return
@@ -220,27 +255,13 @@ func handleToolCallContent(content string, state *streamState, emitEvent func(*t
 
 	// After tool name is detected, emit content normally
 	emitEvent(types.NewToolCallContentEvent(content))
+	streamToolMessage(content, true, state, emitEvent)
 }
 
 // finalize ends the stream processing
-func finalize(state *streamState, emitEvent func(*types.AgentEvent), onComplete func(string, string, string, string)) {
-	// Flush any remaining content from tool call parser
-	toolCallContent, regularContent := state.toolCallParser.Flush()
-	if toolCallContent != nil && toolCallContent.Content != "" {
-		handleToolCallContent(toolCallContent.Content, state, emitEvent)
-	}
-	if regularContent != nil && regularContent.Content != "" {
-		if state.toolCallStarted {
-			emitEvent(types.NewToolCallEndEvent())
-			state.toolCallStarted = false
-		}
-		if !state.messageStarted {
-			emitEvent(types.NewMessageStartEvent())
-			state.messageStarted = true
-		}
-		emitEvent(types.NewMessageContentEvent(regularContent.Content))
-		state.assistantContent += regularContent.Content
-	}
+func finalize(state *streamState, emitEvent func(*types.AgentEvent), onComplete func(string, string, []string, string)) {
+	// Flush any remaining content from the parser
+	handleSegments(state.parser.Flush(), state, emitEvent)
 
 	// End any active sections
 	if state.thinkingStarted {
@@ -257,7 +278,7 @@ func finalize(state *streamState, emitEvent func(*types.AgentEvent), onComplete
 	if role == "" {
 		role = string(types.RoleAssistant)
 	}
-	onComplete(state.assistantContent, state.thinkingContent, state.toolCallContent, role)
+	onComplete(state.assistantContent, state.thinkingContent, state.toolCalls, role)
 }
 
 // extractToolNameFromPartial attempts to extract the tool name from partial XML content.
@@ -1,7 +1,11 @@
 package core
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
 )
 
 func TestExtractToolNameFromPartial(t *testing.T) {
@@ -124,3 +128,113 @@ func TestExtractToolNameFromPartial_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessStream_MultipleToolCalls(t *testing.T) {
+	stream := make(chan *llm.StreamChunk, 4)
+	stream <- &llm.StreamChunk{Content: "Reading it first.<tool><tool_name>read_file</tool_name></tool>\n<to"}
+	stream <- &llm.StreamChunk{Content: "ol><tool_name>apply_diff</tool_name></tool>"}
+	stream <- &llm.StreamChunk{Finished: true}
+	close(stream)
+
+	var events []types.AgentEventType
+	var message string
+	var toolCalls []string
+	ProcessStream(stream, func(event *types.AgentEvent) {
+		events = append(events, event.Type)
+	}, func(content, thinking string, calls []string, role string) {
+		message = content
+		toolCalls = calls
+	})
+
+	if message != "Reading it first." {
+		t.Errorf("message = %q", message)
+	}
+	want := []string{"<tool_name>read_file</tool_name>", "<tool_name>apply_diff</tool_name>"}
+	if !reflect.DeepEqual(toolCalls, want) {
+		t.Errorf("tool calls = %q, want %q", toolCalls, want)
+	}
+
+	var starts, ends int
+	for _, event := range events {
+		switch event {
+		case types.EventTypeToolCallStart:
+			starts++
+		case types.EventTypeToolCallEnd:
+			ends++
+		case types.EventTypeMessageStart:
+			if starts > 0 {
+				t.Error("expected no message for the whitespace between tool calls")
+			}
+		}
+	}
+	// Each call starts once, then again with its name
+	if starts != 4 || ends != 2 {
+		t.Errorf("expected two tool calls started and ended, got %d starts and %d ends: %v", starts, ends, events)
+	}
+}
+
+func TestProcessStream_StreamsConverseMessage(t *testing.T) {
+	chunks := []string{
+		"<tool>\n<server_name>local</server_name>\n<tool_name>converse</tool_name>\n<arguments>\n<message>\n  Use `a &am",
+		"p;&amp; b`, then **run** it.</mess",
+		"age>\n</arguments>\n</tool>",
+	}
+	stream := make(chan *llm.StreamChunk, len(chunks)+1)
+	for _, chunk := range chunks {
+		stream <- &llm.StreamChunk{Content: chunk}
+	}
+	stream <- &llm.StreamChunk{Finished: true}
+	close(stream)
+
+	var deltas []string
+	var starts, ends int
+	var toolName string
+	ProcessStream(stream, func(event *types.AgentEvent) {
+		switch event.Type {
+		case types.EventTypeMessageStart:
+			starts++
+			toolName, _ = event.Metadata["tool_name"].(string)
+		case types.EventTypeMessageContent:
+			deltas = append(deltas, event.Content)
+		case types.EventTypeMessageEnd:
+			ends++
+		}
+	}, func(content, thinking string, calls []string, role string) {
+		if content != "" {
+			t.Errorf("expected the reply to stay out of the assistant content, got %q", content)
+		}
+		if len(calls) != 1 {
+			t.Errorf("expected the converse call, got %q", calls)
+		}
+	})
+
+	want := []string{"Use `a ", "&& b`, then **run** it."}
+	if !reflect.DeepEqual(deltas, want) {
+		t.Errorf("message deltas = %q, want %q", deltas, want)
+	}
+	if starts != 1 || ends != 1 || toolName != "converse" {
+		t.Errorf("expected one message from converse, got %d starts, %d ends, tool %q", starts, ends, toolName)
+	}
+}
+
+func TestElementTextPrefix(t *testing.T) {
+	tests := []struct {
+		content string
+		text    string
+		closed  bool
+	}{
+		{content: "<arguments><message>Hi &lt;b&gt;</message>", text: "Hi <b>", closed: true},
+		{content: "<message>Hi</me", text: "Hi"},
+		{content: "<message>Tom &", text: "Tom "},
+		{content: "<message>Tom & Jerry", text: "Tom & Jerry"},
+		{content: "<message><![CDATA[x < y ]]", text: "x < y "},
+		{content: "<message><![CDATA[</message>]]> ok</message>", text: "</message> ok", closed: true},
+		{content: "<arguments><mess", text: ""},
+	}
+	for _, tt := range tests {
+		text, closed := elementTextPrefix(tt.content, "message")
+		if text != tt.text || closed != tt.closed {
+			t.Errorf("elementTextPrefix(%q) = %q, %v; want %q, %v", tt.content, text, closed, tt.text, tt.closed)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"html"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// messageTools map the tools whose argument is a reply to the user to that
+// argument. The argument is streamed as message content while the call
+// arrives, so the reply reads like a normal message instead of appearing
+// as a tool result at the end.
+var messageTools = map[string]string{
+	"converse": "message",
+}
+
+// StreamsAsMessage reports whether the reply of the named tool is streamed
+// as message content, with the MessageStart event's tool_name metadata set
+// to it. Executors show such a reply as a message rather than as a tool
+// call and result.
+func StreamsAsMessage(toolName string) bool {
+	_, ok := messageTools[toolName]
+	return ok
+}
+
+// StreamedReply reports whether event, a message tool's result, carries a
+// reply that was already streamed as message content without the tool_name
+// metadata, as when the agent accepts a reply without a tool call as a
+// converse call. Executors show it as they would a streamed tool reply.
+func StreamedReply(event *types.AgentEvent) bool {
+	streamed, _ := event.Metadata["streamed_reply"].(bool)
+	return streamed
+}
+
+// NewStreamedReplyEvent creates the result event of a message tool whose
+// reply was already streamed as plain message content
+func NewStreamedReplyEvent(toolName string, output interface{}) *types.AgentEvent {
+	event := types.NewToolResultEvent(toolName, output)
+	event.Metadata["streamed_reply"] = true
+	return event
+}
+
+// maxEntityLength bounds an XML entity such as &#x1F600; a longer run after
+// '&' is a literal ampersand
+const maxEntityLength = 10
+
+// cdataStart opens an XML CDATA section
+const cdataStart = "<![CDATA["
+
+// streamToolMessage emits the part of a message tool's reply that arrived
+// since the last call. content is the tool call so far; complete is set
+// once the call has ended, which ends the message.
+func streamToolMessage(content string, complete bool, state *streamState, emitEvent func(*types.AgentEvent)) {
+	arg, ok := messageTools[state.toolName]
+	if !ok || state.toolMessageEnded {
+		return
+	}
+
+	text, closed := elementTextPrefix(content, arg)
+	text = strings.TrimLeft(text, " \t\r\n")
+	if len(text) > state.toolMessageSent {
+		if !state.toolMessageStarted {
+			event := types.NewMessageStartEvent()
+			event.Metadata["tool_name"] = state.toolName
+			emitEvent(event)
+			state.toolMessageStarted = true
+		}
+		emitEvent(types.NewMessageContentEvent(text[state.toolMessageSent:]))
+		state.toolMessageSent = len(text)
+	}
+
+	if (closed || complete) && state.toolMessageStarted {
+		emitEvent(types.NewMessageEndEvent())
+		state.toolMessageEnded = true
+	}
+}
+
+// elementTextPrefix returns the decoded text of the first <tag> element in
+// content, which may be cut off mid-stream, and whether its closing tag has
+// arrived. Text that could be the start of the closing tag, a CDATA section
+// or an entity is held back until the rest of it arrives.
+func elementTextPrefix(content, tag string) (string, bool) {
+	start := strings.Index(content, "<"+tag+">")
+	if start < 0 {
+		return "", false
+	}
+	body := content[start+len(tag)+2:]
+	closing := "</" + tag + ">"
+
+	var b strings.Builder
+	for body != "" {
+		switch {
+		case strings.HasPrefix(body, cdataStart):
+			rest := body[len(cdataStart):]
+			end := strings.Index(rest, "]]>")
+			if end < 0 {
+				b.WriteString(rest[:len(rest)-partialSuffix(rest, "]]>")])
+				return b.String(), false
+			}
+			b.WriteString(rest[:end])
+			body = rest[end+3:]
+		case strings.HasPrefix(body, closing):
+			return b.String(), true
+		case body[0] == '<':
+			if strings.HasPrefix(closing, body) || strings.HasPrefix(cdataStart, body) {
+				return b.String(), false
+			}
+			// A stray '<', which the lenient argument parser also keeps
+			b.WriteByte('<')
+			body = body[1:]
+		case body[0] == '&':
+			end, complete := entityEnd(body)
+			switch {
+			case end > 0:
+				b.WriteString(html.UnescapeString(body[:end]))
+				body = body[end:]
+			case !complete:
+				return b.String(), false
+			default:
+				// A literal ampersand, which the lenient argument parser also keeps
+				b.WriteByte('&')
+				body = body[1:]
+			}
+		default:
+			end := strings.IndexAny(body, "<&")
+			if end < 0 {
+				end = len(body)
+			}
+			b.WriteString(body[:end])
+			body = body[end:]
+		}
+	}
+	return b.String(), false
+}
+
+// entityEnd returns the length of the entity s starts with, or 0 if s
+// starts with a literal '&'. complete is false when s ends before that can
+// be told.
+func entityEnd(s string) (int, bool) {
+	for i := 1; i < len(s) && i <= maxEntityLength; i++ {
+		switch c := s[i]; {
+		case c == ';':
+			return i + 1, true
+		case c == '#' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+		default:
+			return 0, true
+		}
+	}
+	return 0, len(s) > maxEntityLength
+}
+
+// partialSuffix returns the length of the longest suffix of s that is a
+// proper prefix of marker
+func partialSuffix(s, marker string) int {
+	for n := min(len(marker)-1, len(s)); n > 0; n-- {
+		if strings.HasSuffix(s, marker[:n]) {
+			return n
+		}
+	}
+	return 0
+}
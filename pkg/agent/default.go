@@ -9,8 +9,15 @@ import (
 	"time"
 
 	"github.com/entrhq/forge/pkg/agent/approval"
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/consistency"
 	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/editlock"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/hooks"
 	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/agent/notify"
 	"github.com/entrhq/forge/pkg/agent/prompts"
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/llm"
@@ -38,19 +45,41 @@ type DefaultAgent struct {
 	provider           llm.Provider
 	channels           *types.AgentChannels
 	customInstructions string
-	maxTurns           int
+	roleName           string              // Role set with SetRole ("" = none)
+	roleInstructions   string              // The role's instructions for the system prompt
+	roleMu             sync.RWMutex        // Guards the role, which a workflow changes between turns
+	basePrompt         *prompts.BasePrompt // Pinned base prompt version (nil = latest)
+	basePromptOverride string              // Replaces the base prompt entirely when set
+	environment        string              // Detected environment for the system prompt ("" = none)
+	maxTurns           int                 // Max agent loop iterations per turn (0 = unlimited)
+	maxToolCalls       int                 // Max tool executions per turn (0 = unlimited)
+	maxTurnDuration    time.Duration       // Max wall-clock time per turn (0 = unlimited)
+	noToolCallPolicy   NoToolCallPolicy    // What to do with a response without a tool call ("" = error)
 	bufferSize         int
 	metadata           map[string]interface{}
 
 	// Agent loop components
-	tools   map[string]tools.Tool
-	toolsMu sync.RWMutex
-	memory  memory.Memory
+	tools        map[string]tools.Tool
+	allowedTools map[string]bool   // Custom tools the model may use; nil allows all
+	toolAliases  map[string]string // Deprecated tool names, mapped to the tools' names
+	toolsMu      sync.RWMutex
+	memory       memory.Memory
+
+	// Tool execution limits
+	toolTimeouts       map[string]time.Duration // Per-tool timeouts set at registration
+	defaultToolTimeout time.Duration            // Applied to tools without their own timeout (0 = none)
+	heartbeatInterval  time.Duration            // How often to emit heartbeats while a tool runs
+
+	// Long-output paging shared by tools and the get_more tool
+	resultPager *tools.ResultPager
 
 	// Approval system
 	approvalManager *approval.Manager
 	approvalTimeout time.Duration
 
+	// Handlers subscribed to event types, called alongside the event channel
+	subscriptions types.Subscriptions
+
 	// Control channels
 	cancelMu     sync.Mutex
 	cancelStream context.CancelFunc
@@ -71,6 +100,45 @@ type DefaultAgent struct {
 
 	// Context management
 	contextManager *agentcontext.Manager
+
+	// Rate limiters whose wait and resume events the agent forwards
+	rateLimiters []*llm.RateLimiter
+
+	// End-of-turn consistency analysis (nil = disabled)
+	consistencyChecker *consistency.Checker
+
+	// User-defined lifecycle hooks (nil = none)
+	hooks *hooks.Runner
+
+	// Posts task completion summaries (nil = disabled)
+	notifier *notify.Notifier
+
+	// Prompt variant the session runs and where its turn outcomes go (nil = none)
+	experiment *experiment
+
+	// Tamper-evident log of tool calls that can change the workspace (nil = disabled)
+	auditLog *audit.Log
+
+	// Files changed by the agent's file tools this session (nil = not tracked)
+	tracker *git.ModificationTracker
+
+	// Tool call outcomes reported to the model each turn (nil = disabled)
+	toolStats       *metrics.Collector
+	toolStatsReport string // Report for the current turn
+
+	// Files this agent read and wrote this turn, shared with other agents (nil = not guarded)
+	editLocks *editlock.Session
+
+	// Loop budget usage for the current turn
+	budget *turnBudget
+
+	// Set when a tool result this turn contained suspected embedded instructions
+	injectionSuspected bool
+
+	// Turn summaries appended to memory after each turn
+	turnSummaries bool
+	turn          *turnRecord
+	turnCount     int
 }
 
 // AgentOption is a function that configures an agent
@@ -84,13 +152,68 @@ func WithCustomInstructions(instructions string) AgentOption {
 	}
 }
 
-// WithMaxTurns sets the maximum number of conversation turns
+// WithBasePrompt pins the built-in base system prompt to a specific version
+// (see prompts.GetBasePrompt), so upstream prompt changes don't alter behavior.
+func WithBasePrompt(basePrompt *prompts.BasePrompt) AgentOption {
+	return func(a *DefaultAgent) {
+		a.basePrompt = basePrompt
+	}
+}
+
+// WithBasePromptOverride replaces the built-in base system prompt entirely.
+// Custom instructions and tool schemas are still added around it.
+func WithBasePromptOverride(prompt string) AgentOption {
+	return func(a *DefaultAgent) {
+		a.basePromptOverride = prompt
+	}
+}
+
+// WithEnvironment adds the machine and project tooling the session runs in
+// to the system prompt, usually environment.Detect's Prompt. It is added
+// after a pinned or overridden base prompt too, so only pass it there when
+// the user asked for it.
+func WithEnvironment(environment string) AgentOption {
+	return func(a *DefaultAgent) {
+		a.environment = environment
+	}
+}
+
+// WithMaxTurns sets the maximum number of agent loop iterations (LLM calls)
+// per user turn. When reached, the turn stops with a budget exceeded event.
+// 0 means unlimited.
 func WithMaxTurns(max int) AgentOption {
 	return func(a *DefaultAgent) {
 		a.maxTurns = max
 	}
 }
 
+// WithMaxToolCalls sets the maximum number of tool executions per user turn.
+// 0 means unlimited.
+func WithMaxToolCalls(max int) AgentOption {
+	return func(a *DefaultAgent) {
+		a.maxToolCalls = max
+	}
+}
+
+// WithMaxTurnDuration sets the maximum wall-clock time a user turn may run.
+// The limit is checked between iterations, so a running tool is never cut off.
+// 0 means unlimited.
+func WithMaxTurnDuration(max time.Duration) AgentOption {
+	return func(a *DefaultAgent) {
+		a.maxTurnDuration = max
+	}
+}
+
+// WithTurnSummaries enables or disables the compact summary (files touched,
+// commands run, outcome) appended to memory after each turn. Context
+// strategies keep these summaries as anchors when older history is condensed.
+// Enabled by default.
+func WithTurnSummaries(enabled bool) AgentOption {
+	return func(a *DefaultAgent) {
+		a.turnSummaries = enabled
+	}
+}
+
 // WithBufferSize sets the channel buffer size
 func WithBufferSize(size int) AgentOption {
 	return func(a *DefaultAgent) {
@@ -112,6 +235,17 @@ func WithApprovalTimeout(timeout time.Duration) AgentOption {
 	}
 }
 
+// WithMemory sets the conversation history the agent reads and writes, in
+// place of an in-memory ConversationMemory. A memory that already holds
+// messages, such as a resumed sqlite session, continues that conversation.
+func WithMemory(mem memory.Memory) AgentOption {
+	return func(a *DefaultAgent) {
+		if mem != nil {
+			a.memory = mem
+		}
+	}
+}
+
 // WithContextManager sets a context manager for the agent to handle context summarization
 func WithContextManager(manager *agentcontext.Manager) AgentOption {
 	return func(a *DefaultAgent) {
@@ -119,6 +253,67 @@ func WithContextManager(manager *agentcontext.Manager) AgentOption {
 	}
 }
 
+// WithRateLimiters forwards the rate limit wait and resume events of
+// limiters, such as those scheduling the agent's and the summarizer's
+// provider calls, as agent events
+func WithRateLimiters(limiters ...*llm.RateLimiter) AgentOption {
+	return func(a *DefaultAgent) {
+		a.rateLimiters = append(a.rateLimiters, limiters...)
+	}
+}
+
+// WithConsistencyChecker enables an analysis pass, run once at the end of each
+// turn, that reports references to symbols and files the turn's edits removed
+func WithConsistencyChecker(checker *consistency.Checker) AgentOption {
+	return func(a *DefaultAgent) {
+		a.consistencyChecker = checker
+	}
+}
+
+// WithDefaultToolTimeout sets the execution timeout applied to tools registered
+// without their own timeout. A zero duration disables the default timeout.
+func WithDefaultToolTimeout(timeout time.Duration) AgentOption {
+	return func(a *DefaultAgent) {
+		a.defaultToolTimeout = timeout
+	}
+}
+
+// WithToolHeartbeatInterval sets how often heartbeat events are emitted while a tool is running
+func WithToolHeartbeatInterval(interval time.Duration) AgentOption {
+	return func(a *DefaultAgent) {
+		a.heartbeatInterval = interval
+	}
+}
+
+// ToolOption configures how a tool is registered with the agent
+type ToolOption func(*toolRegistration)
+
+// toolRegistration holds per-tool settings collected from ToolOptions
+type toolRegistration struct {
+	timeout time.Duration
+	aliases []string
+}
+
+// WithToolTimeout sets an execution timeout for the tool being registered.
+// When the timeout elapses the tool's context is canceled and the agent reports
+// the timeout to the LLM instead of waiting indefinitely.
+func WithToolTimeout(timeout time.Duration) ToolOption {
+	return func(r *toolRegistration) {
+		r.timeout = timeout
+	}
+}
+
+// WithToolAliases registers other, deprecated names for the tool being
+// registered, in addition to any it declares by implementing tools.Aliased.
+// Calls under an alias run the tool, and the model is told the tool's name,
+// so prompts that drift from the registered names don't loop on unknown
+// tool errors.
+func WithToolAliases(aliases ...string) ToolOption {
+	return func(r *toolRegistration) {
+		r.aliases = append(r.aliases, aliases...)
+	}
+}
+
 // NewDefaultAgent creates a new DefaultAgent with the given provider and options.
 func NewDefaultAgent(provider llm.Provider, opts ...AgentOption) *DefaultAgent {
 	// Create tokenizer for client-side token counting
@@ -129,11 +324,16 @@ func NewDefaultAgent(provider llm.Provider, opts ...AgentOption) *DefaultAgent {
 	}
 
 	a := &DefaultAgent{
-		provider:   provider,
-		bufferSize: 10, // default buffer size
-		tools:      make(map[string]tools.Tool),
-		memory:     memory.NewConversationMemory(),
-		tokenizer:  tok,
+		provider:          provider,
+		bufferSize:        10, // default buffer size
+		tools:             make(map[string]tools.Tool),
+		toolTimeouts:      make(map[string]time.Duration),
+		toolAliases:       make(map[string]string),
+		heartbeatInterval: defaultHeartbeatInterval,
+		resultPager:       tools.NewResultPager(tools.DefaultPageSize),
+		memory:            memory.NewConversationMemory(),
+		tokenizer:         tok,
+		turnSummaries:     true,
 	}
 
 	// Register built-in tools
@@ -144,6 +344,12 @@ func NewDefaultAgent(provider llm.Provider, opts ...AgentOption) *DefaultAgent {
 		opt(a)
 	}
 
+	// Record the active base prompt version in session metadata
+	if a.metadata == nil {
+		a.metadata = make(map[string]interface{})
+	}
+	a.metadata["base_prompt_version"] = a.BasePromptVersion()
+
 	// Create channels with configured buffer size
 	a.channels = types.NewAgentChannels(a.bufferSize)
 
@@ -151,9 +357,12 @@ func NewDefaultAgent(provider llm.Provider, opts ...AgentOption) *DefaultAgent {
 	a.approvalTimeout = 5 * time.Minute
 	a.approvalManager = approval.NewManager(a.approvalTimeout, a.emitEvent)
 
-	// If context manager was provided, set its event channel now that channels exist
+	// If context manager was provided, route its events through the agent now that channels exist
 	if a.contextManager != nil {
-		a.contextManager.SetEventChannel(a.channels.Event)
+		a.contextManager.SetEventEmitter(a.emitEvent)
+	}
+	for _, limiter := range a.rateLimiters {
+		limiter.SetEventEmitter(a.emitEvent)
 	}
 
 	return a
@@ -164,6 +373,10 @@ func (a *DefaultAgent) RegisterDefaultTools() {
 	a.tools["task_completion"] = tools.NewTaskCompletionTool()
 	a.tools["ask_question"] = tools.NewAskQuestionTool()
 	a.tools["converse"] = tools.NewConverseTool()
+	a.tools["get_more"] = tools.NewGetMoreTool(a.resultPager)
+	for _, tool := range a.tools {
+		a.registerAliases(tool, nil)
+	}
 }
 
 // Start begins the agent's event loop in a goroutine.
@@ -182,7 +395,8 @@ func (a *DefaultAgent) Start(ctx context.Context) error {
 	return nil
 }
 
-// Shutdown gracefully stops the agent.
+// Shutdown gracefully stops the agent. The turn in progress is canceled, and
+// Shutdown returns once it has finished or ctx ends.
 func (a *DefaultAgent) Shutdown(ctx context.Context) error {
 	// Signal shutdown
 	close(a.channels.Shutdown)
@@ -201,6 +415,11 @@ func (a *DefaultAgent) GetChannels() *types.AgentChannels {
 	return a.channels
 }
 
+// Subscriptions returns the handlers subscribed to this agent's events.
+func (a *DefaultAgent) Subscriptions() *types.Subscriptions {
+	return &a.subscriptions
+}
+
 // eventLoop is the main processing loop for the agent.
 func (a *DefaultAgent) eventLoop(ctx context.Context) {
 	defer a.channels.Close()
@@ -210,6 +429,25 @@ func (a *DefaultAgent) eventLoop(ctx context.Context) {
 		a.runMu.Unlock()
 	}()
 
+	// Session hooks; session_end still runs when the session was canceled
+	a.runHooks(ctx, hooks.Payload{Event: hooks.EventSessionStart})
+	defer a.runHooks(context.WithoutCancel(ctx), hooks.Payload{Event: hooks.EventSessionEnd})
+
+	// Inputs are processed under loopCtx, so leaving the loop for any reason,
+	// including Shutdown, cancels the turn in progress. It is waited for
+	// before the channels it emits on are closed.
+	loopCtx, stopInputs := context.WithCancel(ctx)
+	var inputs sync.WaitGroup
+	defer func() {
+		stopInputs()
+		inputs.Wait()
+		if a.contextManager != nil {
+			// Background summarization outlives turns but not the agent
+			a.contextManager.Skip()
+			_ = a.contextManager.Wait(context.Background())
+		}
+	}()
+
 	// Start a separate goroutine to handle cancellation requests
 	// This ensures cancellations are processed even when the main loop is blocked
 	cancelCtx, cancelStop := context.WithCancel(ctx)
@@ -249,12 +487,16 @@ func (a *DefaultAgent) eventLoop(ctx context.Context) {
 
 			// Handle cancellation immediately (synchronously) so it can interrupt ongoing processing
 			if input.IsCancel() {
-				a.processInput(ctx, input)
+				a.processInput(loopCtx, input)
 				continue
 			}
 
 			// Process other inputs asynchronously so eventLoop can continue handling cancel requests
-			go a.processInput(ctx, input)
+			inputs.Add(1)
+			go func() {
+				defer inputs.Done()
+				a.processInput(loopCtx, input)
+			}()
 
 		case approval := <-a.channels.Approval:
 			if approval == nil {
@@ -278,6 +520,9 @@ func (a *DefaultAgent) processInput(ctx context.Context, input *types.Input) {
 			a.cancelStream = nil
 		}
 		a.cancelMu.Unlock()
+		if a.contextManager != nil {
+			a.contextManager.Skip()
+		}
 		return
 	}
 
@@ -297,10 +542,31 @@ func (a *DefaultAgent) processInput(ctx context.Context, input *types.Input) {
 
 // processUserInput processes a user text input using the agent loop.
 func (a *DefaultAgent) processUserInput(ctx context.Context, content string) {
+	a.runHooks(ctx, hooks.Payload{Event: hooks.EventPreTurn, Input: content})
+
 	// Add user message to memory
 	userMsg := types.NewUserMessage(content)
 	a.memory.Add(userMsg)
 
+	// Record what this turn does for its summary
+	a.turn = newTurnRecord(content)
+	a.refreshToolStats()
+	if a.editLocks != nil {
+		a.editLocks.Begin()
+		defer a.editLocks.End()
+	}
+
+	// Injection suspicion only lasts for the turn it was raised in
+	a.injectionSuspected = false
+	a.approvalManager.SuspendAutoApproval(false)
+
+	// Baseline the workspace so this turn's edits can be checked for dangling references
+	if a.consistencyChecker != nil {
+		if err := a.consistencyChecker.Begin(ctx); err != nil {
+			agentDebugLog.Printf("Consistency checker baseline failed: %v", err)
+		}
+	}
+
 	// Create cancellable context for this turn
 	turnCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -322,14 +588,32 @@ func (a *DefaultAgent) processUserInput(ctx context.Context, content string) {
 	// Run agent loop (now in assistant.go)
 	a.runAgentLoop(turnCtx)
 
+	// Look for references the turn's edits left dangling, unless the user stopped it
+	if turnCtx.Err() == nil {
+		a.checkConsistency(ctx)
+	}
+
+	// Anchor the turn in memory before older details get summarized
+	a.recordTurnSummary()
+	a.notifyCompletion(ctx)
+	a.recordOutcome()
+
+	a.runHooks(ctx, hooks.Payload{Event: hooks.EventPostTurn})
+
 	// Emit turn end
 	a.emitEvent(types.NewTurnEndEvent())
+
+	// Get summarization out of the way before the next turn, unless the user stopped this one
+	if turnCtx.Err() == nil {
+		a.summarizeAhead(ctx)
+	}
 }
 
 // RegisterTool adds a custom tool to the agent's tool registry.
-// Built-in tools (task_completion, ask_question, converse) are always available
-// and cannot be overridden.
-func (a *DefaultAgent) RegisterTool(tool tools.Tool) error {
+// Built-in tools (task_completion, ask_question, converse, get_more) are always available
+// and cannot be overridden. Options such as WithToolTimeout configure how the
+// tool is executed.
+func (a *DefaultAgent) RegisterTool(tool tools.Tool, opts ...ToolOption) error {
 	if tool == nil {
 		return fmt.Errorf("tool cannot be nil")
 	}
@@ -340,19 +624,25 @@ func (a *DefaultAgent) RegisterTool(tool tools.Tool) error {
 	}
 
 	// Prevent overriding built-in tools
-	builtIns := map[string]bool{
-		"task_completion": true,
-		"ask_question":    true,
-		"converse":        true,
-	}
-	if builtIns[name] {
+	if builtinTools[name] {
 		return fmt.Errorf("cannot override built-in tool: %s", name)
 	}
 
+	reg := &toolRegistration{}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
 	a.toolsMu.Lock()
 	defer a.toolsMu.Unlock()
 
 	a.tools[name] = tool
+	a.registerAliases(tool, reg.aliases)
+	if reg.timeout > 0 {
+		a.toolTimeouts[name] = reg.timeout
+	} else {
+		delete(a.toolTimeouts, name)
+	}
 	return nil
 }
 
@@ -378,15 +668,20 @@ func (a *DefaultAgent) GetTools() []interface{} {
 	return toolsList
 }
 
+// ImportContext adds msg to the conversation as context for the turns that
+// follow, without starting a turn, such as a document imported with
+// forge -seed or /import. Call it between turns.
+func (a *DefaultAgent) ImportContext(msg *types.Message) {
+	a.memory.Add(msg)
+}
+
 // GetContextInfo returns detailed context information for debugging and display
 func (a *DefaultAgent) GetContextInfo() *ContextInfo {
 	a.toolsMu.RLock()
 	defer a.toolsMu.RUnlock()
 
 	// Build system prompt without tools to calculate base system tokens
-	baseSystemPrompt := prompts.NewPromptBuilder().
-		WithCustomInstructions(a.customInstructions).
-		Build()
+	baseSystemPrompt := a.newPromptBuilder().Build()
 
 	// Build just the tools section to calculate tool tokens
 	toolsSection := ""
@@ -405,9 +700,8 @@ func (a *DefaultAgent) GetContextInfo() *ContextInfo {
 	}
 
 	// Build full system prompt for current context calculation
-	fullSystemPrompt := prompts.NewPromptBuilder().
+	fullSystemPrompt := a.newPromptBuilder().
 		WithTools(a.getToolsList()).
-		WithCustomInstructions(a.customInstructions).
 		Build()
 
 	// Get tool names
@@ -464,6 +758,7 @@ func (a *DefaultAgent) GetContextInfo() *ContextInfo {
 	return &ContextInfo{
 		SystemPromptTokens:    systemPromptTokens,
 		CustomInstructions:    a.customInstructions != "",
+		BasePromptVersion:     a.BasePromptVersion(),
 		ToolCount:             len(a.tools),
 		ToolTokens:            toolTokens,
 		ToolNames:             toolNames,
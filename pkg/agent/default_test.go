@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent/memory"
 	"github.com/entrhq/forge/pkg/llm"
@@ -200,3 +201,72 @@ func TestEmptyMemoryTokenCounting(t *testing.T) {
 		t.Errorf("Expected non-zero system prompt tokens, got 0")
 	}
 }
+
+// blockingProvider holds every completion open until its context ends
+type blockingProvider struct {
+	mockProvider
+	started  chan struct{}
+	canceled chan struct{}
+}
+
+func (b *blockingProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	close(b.started)
+	<-ctx.Done()
+	close(b.canceled)
+	return nil, ctx.Err()
+}
+
+// TestShutdown_CancelsTurn verifies that Shutdown cancels the turn in
+// progress instead of leaving its provider call running
+func TestShutdown_CancelsTurn(t *testing.T) {
+	provider := &blockingProvider{started: make(chan struct{}), canceled: make(chan struct{})}
+	agent := NewDefaultAgent(provider)
+	go func() {
+		for range agent.channels.Event {
+		}
+	}()
+
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	agent.channels.Input <- types.NewUserInput("hello")
+
+	select {
+	case <-provider.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("turn never reached the provider")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := agent.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	select {
+	case <-provider.canceled:
+	default:
+		t.Fatal("provider call outlived Shutdown")
+	}
+}
+
+// TestSubscribe_ReceivesEmittedEvents verifies that subscribers get the typed
+// payload of emitted events, which are still sent on the event channel
+func TestSubscribe_ReceivesEmittedEvents(t *testing.T) {
+	agent := NewDefaultAgent(&mockProvider{})
+
+	var got []types.ToolCallPayload
+	unsubscribe := Subscribe(agent, types.EventToolCall, func(p types.ToolCallPayload) {
+		got = append(got, p)
+	})
+	agent.emitEvent(types.NewToolCallEvent("read_file", map[string]interface{}{"path": "go.mod"}))
+	unsubscribe()
+	agent.emitEvent(types.NewToolCallEvent("list_files", nil))
+
+	if len(got) != 1 || got[0].ToolName != "read_file" || got[0].Input["path"] != "go.mod" {
+		t.Errorf("subscriber got %+v, want one read_file call", got)
+	}
+	if n := len(agent.channels.Event); n != 2 {
+		t.Errorf("event channel holds %d events, want 2", n)
+	}
+}
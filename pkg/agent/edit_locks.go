@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/editlock"
+	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// WithEditLocks guards the files the agent's file tools write during a turn.
+// A write that replaces a whole file is refused if the file changed since the
+// agent last read or wrote it, and any write is refused while another agent
+// of the session's registry holds the file; the model is asked to re-read
+// the file and merge its change instead.
+func WithEditLocks(session *editlock.Session) AgentOption {
+	return func(a *DefaultAgent) {
+		a.editLocks = session
+	}
+}
+
+// claimEditLock takes the file a mutating tool call writes for this agent
+// Returns (shouldExecute, errorContext)
+func (a *DefaultAgent) claimEditLock(tool tools.Tool, toolCall tools.ToolCall) (bool, string) {
+	if a.editLocks == nil {
+		return true, ""
+	}
+	if _, mutating := tool.(tools.Previewable); !mutating {
+		return true, ""
+	}
+	var args turnToolArgs
+	_ = tools.UnmarshalXMLWithFallback(toolCall.GetArgumentsXML(), &args)
+	if args.Path == "" {
+		return true, ""
+	}
+
+	// apply_diff checks its search texts against the current content itself
+	err := a.editLocks.Claim(args.Path, toolCall.ToolName != "apply_diff")
+	var conflict *editlock.ConflictError
+	if !errors.As(err, &conflict) {
+		return true, ""
+	}
+
+	a.emitEvent(types.NewErrorEvent(fmt.Errorf("%s not run: %w", toolCall.ToolName, conflict)))
+	return false, prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+		Type:     prompts.ErrorTypeEditConflict,
+		ToolName: toolCall.ToolName,
+		Error:    conflict,
+		Feedback: conflict.Owner,
+	})
+}
+
+// observeEdit records the version of a file a successful read_file or file
+// tool call read or wrote
+func (a *DefaultAgent) observeEdit(tool tools.Tool, toolCall tools.ToolCall) {
+	if a.editLocks == nil {
+		return
+	}
+	if _, mutating := tool.(tools.Previewable); !mutating && toolCall.ToolName != "read_file" {
+		return
+	}
+	var args turnToolArgs
+	_ = tools.UnmarshalXMLWithFallback(toolCall.GetArgumentsXML(), &args)
+	if args.Path != "" {
+		a.editLocks.Observe(args.Path)
+	}
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/editlock"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestEditLocks_RefusesStaleWrites(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"})
+	a, _ := newRunnerTestAgent(WithEditLocks(editlock.NewRegistry(ws.Guard()).Session("agent")))
+	a.editLocks.Begin()
+	read := &summaryTestTool{name: "read_file"}
+	write := &summaryWriteTool{summaryTestTool{name: "write_file"}}
+	diff := &summaryWriteTool{summaryTestTool{name: "apply_diff"}}
+
+	a.observeEdit(read, toolCallWithArgs("read_file", "<path>main.go</path>"))
+	ws.WriteFile("main.go", "package main\n\nfunc main() {}\n")
+
+	shouldExecute, errCtx := a.claimEditLock(write, toolCallWithArgs("write_file", "<path>main.go</path><content>x</content>"))
+	if shouldExecute {
+		t.Fatal("expected a write over unseen changes to be refused")
+	}
+	if !strings.Contains(errCtx, "main.go changed since it was last read or written") || !strings.Contains(errCtx, "Read the file again") {
+		t.Errorf("unexpected error context: %q", errCtx)
+	}
+
+	if shouldExecute, _ := a.claimEditLock(diff, toolCallWithArgs("apply_diff", "<path>main.go</path>")); !shouldExecute {
+		t.Error("expected apply_diff to check its search texts itself")
+	}
+	if shouldExecute, _ := a.claimEditLock(read, toolCallWithArgs("read_file", "<path>main.go</path>")); !shouldExecute {
+		t.Error("expected reads to be allowed")
+	}
+}
+
+func TestEditLocks_MergePrompt(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"})
+	registry := editlock.NewRegistry(ws.Guard())
+	other := registry.Session("reviewer")
+	other.Begin()
+	if err := other.Claim("main.go", false); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := newRunnerTestAgent(WithEditLocks(registry.Session("agent")))
+	write := &summaryWriteTool{summaryTestTool{name: "write_file"}}
+	shouldExecute, errCtx := a.claimEditLock(write, toolCallWithArgs("write_file", "<path>main.go</path>"))
+	if shouldExecute {
+		t.Fatal("expected a write to a file another agent holds to be refused")
+	}
+	if !strings.Contains(errCtx, "reviewer is editing this file") || !strings.Contains(errCtx, "merge your change") {
+		t.Errorf("expected a merge prompt, got %q", errCtx)
+	}
+}
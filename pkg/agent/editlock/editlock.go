@@ -0,0 +1,129 @@
+// Package editlock guards the files agents write during a turn. Each agent
+// remembers the version of a file it last read or wrote, and an agent that
+// writes a file holds it until its turn ends. A write is refused when the
+// file changed since the agent last saw it (a command, the user or another
+// agent changed it) or when another agent sharing the Registry holds it, so
+// nobody overwrites changes they haven't seen.
+package editlock
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// ConflictError refuses a write to a file that changed since the agent last
+// saw it, or that another agent holds
+type ConflictError struct {
+	Path  string // Workspace-relative path
+	Owner string // Agent holding the file; empty if it changed outside any agent
+}
+
+// Error describes the conflict.
+func (e *ConflictError) Error() string {
+	if e.Owner != "" {
+		return fmt.Sprintf("%s is being edited by %s", e.Path, e.Owner)
+	}
+	return fmt.Sprintf("%s changed since it was last read or written", e.Path)
+}
+
+// Registry holds the files each agent of a workspace wrote in its current
+// turn. Agents working in the same workspace at the same time share one.
+type Registry struct {
+	guard *workspace.Guard
+
+	mu     sync.Mutex
+	owners map[string]string // Absolute path -> owner
+}
+
+// NewRegistry creates a registry for the guard's workspace.
+func NewRegistry(guard *workspace.Guard) *Registry {
+	return &Registry{guard: guard, owners: make(map[string]string)}
+}
+
+// Session returns the view of the registry of the agent named owner
+func (r *Registry) Session(owner string) *Session {
+	return &Session{registry: r, owner: owner, seen: make(map[string][32]byte)}
+}
+
+// Session tracks the files one agent saw and wrote in its current turn. It
+// is used by one agent at a time.
+type Session struct {
+	registry *Registry
+	owner    string
+	seen     map[string][32]byte // Absolute path -> content hash the agent last saw
+}
+
+// Begin starts a turn, releasing the files held since the last one
+func (s *Session) Begin() {
+	s.End()
+	s.seen = make(map[string][32]byte)
+}
+
+// End releases the files the agent wrote this turn
+func (s *Session) End() {
+	r := s.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for path, owner := range r.owners {
+		if owner == s.owner {
+			delete(r.owners, path)
+		}
+	}
+}
+
+// Observe records the current version of path as seen by the agent, after
+// it read or wrote the file
+func (s *Session) Observe(path string) {
+	abs, err := s.registry.guard.ResolvePath(path)
+	if err != nil {
+		return
+	}
+	if sum, ok := hashFile(abs); ok {
+		s.seen[abs] = sum
+	}
+}
+
+// Claim takes path for a write by the agent, which then holds it until its
+// turn ends. It returns a *ConflictError instead if another agent holds the
+// file, or if stale is set (for writes that replace the whole file) and the
+// file changed since the agent last saw it. Files the agent hasn't seen this
+// turn can be claimed.
+func (s *Session) Claim(path string, stale bool) error {
+	abs, err := s.registry.guard.ResolvePath(path)
+	if err != nil {
+		return nil // The tool reports bad paths itself
+	}
+	rel := path
+	if r, relErr := s.registry.guard.MakeRelative(abs); relErr == nil {
+		rel = r
+	}
+
+	if seen, ok := s.seen[abs]; stale && ok {
+		if sum, exists := hashFile(abs); !exists || sum != seen {
+			return &ConflictError{Path: rel}
+		}
+	}
+
+	r := s.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if owner, held := r.owners[abs]; held && owner != s.owner {
+		return &ConflictError{Path: rel, Owner: owner}
+	}
+	r.owners[abs] = s.owner
+	return nil
+}
+
+// hashFile returns the hash of the file's content, and false if it can't be
+// read
+func hashFile(path string) ([32]byte, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [32]byte{}, false
+	}
+	return sha256.Sum256(data), true
+}
@@ -0,0 +1,77 @@
+package editlock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestSession_Stale(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"})
+	s := NewRegistry(ws.Guard()).Session("agent")
+	s.Begin()
+
+	if err := s.Claim("new.go", true); err != nil {
+		t.Errorf("expected an unseen file to be claimable, got %v", err)
+	}
+
+	s.Observe("main.go")
+	if err := s.Claim("main.go", true); err != nil {
+		t.Fatalf("expected an unchanged file to be claimable, got %v", err)
+	}
+
+	ws.WriteFile("main.go", "package main\n\nfunc main() {}\n")
+	var conflict *ConflictError
+	if err := s.Claim("./main.go", true); !errors.As(err, &conflict) {
+		t.Fatalf("expected a ConflictError for a changed file, got %v", err)
+	}
+	if conflict.Path != "main.go" || conflict.Owner != "" {
+		t.Errorf("conflict = %+v, want main.go without an owner", conflict)
+	}
+	if err := s.Claim("main.go", false); err != nil {
+		t.Errorf("expected edits that don't replace the file to be allowed, got %v", err)
+	}
+
+	s.Observe("main.go")
+	if err := s.Claim("main.go", true); err != nil {
+		t.Errorf("expected the file to be claimable once read again, got %v", err)
+	}
+
+	ws.WriteFile("main.go", "package other\n")
+	s.Begin()
+	if err := s.Claim("main.go", true); err != nil {
+		t.Errorf("expected a new turn to forget the versions seen, got %v", err)
+	}
+}
+
+func TestSession_Owners(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"})
+	registry := NewRegistry(ws.Guard())
+	first, second := registry.Session("planner"), registry.Session("coder")
+	first.Begin()
+	second.Begin()
+
+	if err := first.Claim("main.go", true); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if err := first.Claim("main.go", true); err != nil {
+		t.Errorf("expected the holder to claim its file again, got %v", err)
+	}
+
+	var conflict *ConflictError
+	if err := second.Claim("main.go", false); !errors.As(err, &conflict) {
+		t.Fatalf("expected a ConflictError for a held file, got %v", err)
+	}
+	if conflict.Owner != "planner" {
+		t.Errorf("owner = %q, want planner", conflict.Owner)
+	}
+	if want := "main.go is being edited by planner"; conflict.Error() != want {
+		t.Errorf("Error() = %q, want %q", conflict.Error(), want)
+	}
+
+	first.End()
+	if err := second.Claim("main.go", false); err != nil {
+		t.Errorf("expected the file to be released at the end of the turn, got %v", err)
+	}
+}
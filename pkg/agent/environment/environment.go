@@ -0,0 +1,239 @@
+// Package environment detects the machine and project tooling a session runs
+// in: the OS and shell, the Go, Node and Python versions on PATH, the
+// workspace's package managers and the commands that likely run its tests.
+// The agent adds it to the system prompt so the model doesn't guess, for
+// example, npm in a yarn project.
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeTimeout bounds each runtime version probe
+const probeTimeout = 2 * time.Second
+
+// Runtime is a language runtime probed on PATH
+type Runtime struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"` // Empty when not found
+}
+
+// PackageManager is a package manager the workspace uses, by the file that shows it
+type PackageManager struct {
+	Name     string `json:"name"`
+	Evidence string `json:"evidence"` // Lockfile or manifest, relative to the workspace
+}
+
+// Environment is what Detect found
+type Environment struct {
+	OS              string           `json:"os"` // GOOS/GOARCH
+	Shell           string           `json:"shell,omitempty"`
+	Runtimes        []Runtime        `json:"runtimes"`
+	PackageManagers []PackageManager `json:"package_managers,omitempty"`
+	TestCommands    []string         `json:"test_commands,omitempty"` // Guesses, most specific first
+}
+
+// runtimeProbe runs a runtime's version command and extracts the version
+type runtimeProbe struct {
+	name    string
+	command []string
+	version *regexp.Regexp // First group is the version
+}
+
+// runtimeProbes are the runtimes looked for on PATH
+var runtimeProbes = []runtimeProbe{
+	{name: "go", command: []string{"go", "version"}, version: regexp.MustCompile(`go(\d+\.\d+(?:\.\d+)?)`)},
+	{name: "node", command: []string{"node", "--version"}, version: regexp.MustCompile(`v?(\d+\.\d+\.\d+)`)},
+	{name: "python", command: []string{"python3", "--version"}, version: regexp.MustCompile(`Python (\d+\.\d+(?:\.\d+)?)`)},
+}
+
+// Detect probes the machine and workspaceDir. Probes that fail leave their
+// part out; it never returns an error.
+func Detect(ctx context.Context, workspaceDir string) *Environment {
+	return detect(ctx, workspaceDir, runCommand)
+}
+
+// detect is Detect with the command runner replaceable for tests
+func detect(ctx context.Context, workspaceDir string, run func(ctx context.Context, args []string) (string, error)) *Environment {
+	env := &Environment{
+		OS:       runtime.GOOS + "/" + runtime.GOARCH,
+		Shell:    detectShell(),
+		Runtimes: make([]Runtime, len(runtimeProbes)),
+	}
+
+	// Probe the runtimes concurrently so a slow one doesn't delay startup
+	var wg sync.WaitGroup
+	for i, probe := range runtimeProbes {
+		env.Runtimes[i].Name = probe.name
+		wg.Add(1)
+		go func(i int, probe runtimeProbe) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+			defer cancel()
+			output, err := run(probeCtx, probe.command)
+			if err != nil {
+				return
+			}
+			if match := probe.version.FindStringSubmatch(output); match != nil {
+				env.Runtimes[i].Version = match[1]
+			}
+		}(i, probe)
+	}
+
+	env.PackageManagers, env.TestCommands = detectProject(workspaceDir)
+	wg.Wait()
+	return env
+}
+
+// Prompt renders the environment for the system prompt, one fact per line
+func (e *Environment) Prompt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- OS: %s\n", e.OS)
+	if e.Shell != "" {
+		fmt.Fprintf(&b, "- Shell: %s\n", e.Shell)
+	}
+
+	var found, missing []string
+	for _, rt := range e.Runtimes {
+		if rt.Version != "" {
+			found = append(found, rt.Name+" "+rt.Version)
+		} else {
+			missing = append(missing, rt.Name)
+		}
+	}
+	if len(found) > 0 {
+		fmt.Fprintf(&b, "- Runtimes: %s\n", strings.Join(found, ", "))
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(&b, "- Not found on PATH: %s\n", strings.Join(missing, ", "))
+	}
+
+	if len(e.PackageManagers) > 0 {
+		managers := make([]string, len(e.PackageManagers))
+		for i, pm := range e.PackageManagers {
+			managers[i] = fmt.Sprintf("%s (%s)", pm.Name, pm.Evidence)
+		}
+		fmt.Fprintf(&b, "- Package managers: %s\n", strings.Join(managers, ", "))
+	}
+	if len(e.TestCommands) > 0 {
+		fmt.Fprintf(&b, "- Likely test commands: %s\n", strings.Join(e.TestCommands, ", "))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// detectShell returns the name of the user's shell
+func detectShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	if comspec := os.Getenv("ComSpec"); comspec != "" {
+		return filepath.Base(comspec)
+	}
+	return ""
+}
+
+// jsLockfiles map lockfiles to the package manager that wrote them, in the
+// order they are preferred when a workspace has several
+var jsLockfiles = []struct {
+	file, manager string
+}{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lock", "bun"},
+	{"bun.lockb", "bun"},
+	{"package-lock.json", "npm"},
+}
+
+// pythonManagers map lockfiles and manifests to the Python tool that runs
+// the tests, most specific first
+var pythonManagers = []struct {
+	file, manager, test string
+}{
+	{"uv.lock", "uv", "uv run pytest"},
+	{"poetry.lock", "poetry", "poetry run pytest"},
+	{"Pipfile.lock", "pipenv", "pipenv run pytest"},
+	{"requirements.txt", "pip", "python3 -m pytest"},
+	{"pyproject.toml", "pip", "python3 -m pytest"},
+}
+
+// makeTestTarget matches a test target in a Makefile
+var makeTestTarget = regexp.MustCompile(`(?m)^test\s*:`)
+
+// detectProject finds the package managers in the workspace root and
+// guesses the commands that run its tests
+func detectProject(dir string) ([]PackageManager, []string) {
+	var managers []PackageManager
+	var tests []string
+
+	// A Makefile test target is the project's own choice, so it comes first
+	if data, err := os.ReadFile(filepath.Join(dir, "Makefile")); err == nil && makeTestTarget.Match(data) {
+		tests = append(tests, "make test")
+	}
+
+	if exists(dir, "go.mod") {
+		managers = append(managers, PackageManager{Name: "go modules", Evidence: "go.mod"})
+		tests = append(tests, "go test ./...")
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var pkg struct {
+			PackageManager string            `json:"packageManager"`
+			Scripts        map[string]string `json:"scripts"`
+		}
+		_ = json.Unmarshal(data, &pkg)
+
+		// The packageManager field (corepack) wins over lockfiles
+		manager := PackageManager{Name: "npm", Evidence: "package.json"}
+		if name, _, _ := strings.Cut(pkg.PackageManager, "@"); name != "" {
+			manager = PackageManager{Name: name, Evidence: "package.json packageManager"}
+		} else {
+			for _, lock := range jsLockfiles {
+				if exists(dir, lock.file) {
+					manager = PackageManager{Name: lock.manager, Evidence: lock.file}
+					break
+				}
+			}
+		}
+		managers = append(managers, manager)
+		if pkg.Scripts["test"] != "" {
+			tests = append(tests, manager.Name+" test")
+		}
+	}
+
+	for _, python := range pythonManagers {
+		if exists(dir, python.file) {
+			managers = append(managers, PackageManager{Name: python.manager, Evidence: python.file})
+			tests = append(tests, python.test)
+			break
+		}
+	}
+
+	if exists(dir, "Cargo.toml") {
+		managers = append(managers, PackageManager{Name: "cargo", Evidence: "Cargo.toml"})
+		tests = append(tests, "cargo test")
+	}
+
+	return managers, tests
+}
+
+// exists reports whether name exists in dir
+func exists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+// runCommand runs a version command, returning its combined output
+func runCommand(ctx context.Context, args []string) (string, error) {
+	output, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	return string(output), err
+}
@@ -0,0 +1,62 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// fakeVersions answers the version probes for go and node; python3 is missing
+func fakeVersions(ctx context.Context, args []string) (string, error) {
+	switch args[0] {
+	case "go":
+		return "go version go1.24.0 linux/amd64\n", nil
+	case "node":
+		return "v20.11.1\n", nil
+	}
+	return "", errors.New("executable file not found in $PATH")
+}
+
+func TestDetect(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"go.mod":         "module example.com/app\n\ngo 1.24\n",
+		"package.json":   `{"scripts": {"test": "vitest"}}`,
+		"yarn.lock":      "",
+		"Makefile":       "build:\n\tgo build ./...\n\ntest: build\n\tgo test ./...\n",
+		"poetry.lock":    "",
+		"pyproject.toml": "[tool.poetry]\n",
+	})
+	t.Setenv("SHELL", "/usr/bin/zsh")
+
+	env := detect(context.Background(), ws.Path(""), fakeVersions)
+	prompt := env.Prompt()
+	for _, want := range []string{
+		"- Shell: zsh\n",
+		"- Runtimes: go 1.24.0, node 20.11.1\n",
+		"- Not found on PATH: python\n",
+		"- Package managers: go modules (go.mod), yarn (yarn.lock), poetry (poetry.lock)\n",
+		"- Likely test commands: make test, go test ./..., yarn test, poetry run pytest",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestDetect_PackageManagerField(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"package.json":      `{"packageManager": "pnpm@9.1.0"}`,
+		"package-lock.json": "{}",
+	})
+
+	env := detect(context.Background(), ws.Path(""), fakeVersions)
+	if len(env.PackageManagers) != 1 || env.PackageManagers[0].Name != "pnpm" {
+		t.Errorf("expected the packageManager field to win over the lockfile, got %+v", env.PackageManagers)
+	}
+	if len(env.TestCommands) != 0 {
+		t.Errorf("expected no test command without a test script, got %v", env.TestCommands)
+	}
+}
@@ -1,9 +1,14 @@
 package agent
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
 )
 
 func TestErrorTracking(t *testing.T) {
@@ -119,6 +124,63 @@ func TestErrorMessageBuilders(t *testing.T) {
 			t.Error("error message should be detailed")
 		}
 	})
+
+	t.Run("BuildToolExecutionError_OutsideWorkspace", func(t *testing.T) {
+		msg := prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+			Type:     prompts.ErrorTypeToolExecution,
+			ToolName: "read_file",
+			Error:    fmt.Errorf("invalid path: path '/etc/passwd' is %w", types.ErrPathOutsideWorkspace),
+		})
+
+		if !strings.Contains(msg, "inside the workspace") {
+			t.Errorf("should explain the workspace boundary, got:\n%s", msg)
+		}
+	})
+
+	t.Run("BuildToolExecutionError_SearchNotFound", func(t *testing.T) {
+		msg := prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+			Type:     prompts.ErrorTypeToolExecution,
+			ToolName: "apply_diff",
+			Error:    fmt.Errorf("edit 1: %w\n\nLines 3-5 of main.go as they are now", types.ErrSearchNotFound),
+		})
+
+		if !strings.Contains(msg, "Lines 3-5 of main.go") {
+			t.Errorf("should include the file's current lines, got:\n%s", msg)
+		}
+		if !strings.Contains(msg, "don't need to read the file again") {
+			t.Errorf("should tell the model to use the lines shown, got:\n%s", msg)
+		}
+	})
+
+	t.Run("BuildToolRejectedError", func(t *testing.T) {
+		msg := prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+			Type:     prompts.ErrorTypeToolRejected,
+			ToolName: "write_file",
+			Target:   "Create main.go",
+			Feedback: "put this in cmd/ instead",
+		})
+
+		if !strings.Contains(msg, "write_file") || !strings.Contains(msg, "Create main.go") {
+			t.Error("should mention the rejected tool and its target")
+		}
+		if !strings.Contains(msg, "put this in cmd/ instead") {
+			t.Error("should include the user's feedback")
+		}
+	})
+}
+
+func TestFormatRejectionFeedback(t *testing.T) {
+	got := formatRejectionFeedback("apply_diff", "Edit main.go", "keep the old name")
+	want := "User rejected apply_diff (Edit main.go) because: keep the old name"
+	if got != want {
+		t.Errorf("formatRejectionFeedback() = %q, want %q", got, want)
+	}
+
+	got = formatRejectionFeedback("execute_command", "", "don't touch the network")
+	want = "User rejected execute_command because: don't touch the network"
+	if got != want {
+		t.Errorf("formatRejectionFeedback() = %q, want %q", got, want)
+	}
 }
 
 // testError is a simple error implementation for testing
@@ -129,3 +191,68 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+func TestUnknownToolSuggestion(t *testing.T) {
+	available := []tools.Tool{
+		&summaryTestTool{name: "read_file"},
+		&summaryTestTool{name: "write_file"},
+		&summaryTestTool{name: "apply_diff"},
+	}
+	tests := map[string]string{
+		"read_fle":    `Did you mean "read_file"?`,
+		"Write_File":  `Did you mean "write_file"?`,
+		"read":        `Did you mean "read_file"?`,
+		"diff":        `Did you mean "apply_diff"?`,
+		"run_command": "",
+	}
+	for name, want := range tests {
+		msg := prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+			Type:           prompts.ErrorTypeUnknownTool,
+			ToolName:       name,
+			AvailableTools: available,
+		})
+		if want == "" && strings.Contains(msg, "Did you mean") {
+			t.Errorf("%s: expected no suggestion, got:\n%s", name, msg)
+		}
+		if want != "" && !strings.Contains(msg, want) {
+			t.Errorf("%s: expected %s, got:\n%s", name, want, msg)
+		}
+	}
+}
+
+func TestToolAliases(t *testing.T) {
+	a, _, recorder := newBatchTestAgent(t)
+	if err := a.RegisterTool(&batchTool{summaryTestTool: summaryTestTool{name: "search_files"}, recorder: recorder}, WithToolAliases("grep")); err != nil {
+		t.Fatal(err)
+	}
+
+	if shouldContinue, errCtx := a.processToolCall(context.Background(), toolCallContent("grep", "<pattern>x</pattern>")); !shouldContinue || errCtx != "" {
+		t.Fatalf("expected the alias to run the tool, got %v %q", shouldContinue, errCtx)
+	}
+	if got := recorder.calls(); got != "search_files" {
+		t.Errorf("expected search_files to run, got %q", got)
+	}
+
+	var warned bool
+	for _, msg := range a.memory.GetAll() {
+		if strings.Contains(msg.Content, "'grep' is a deprecated name for the 'search_files' tool") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Error("expected the model to be told the tool's name")
+	}
+
+	// Built-in tools declare their own aliases, and aliases don't shadow tools
+	call := toolCallWithArgs("attempt_completion", "")
+	if alias := a.resolveToolAlias(&call); alias != "attempt_completion" || call.ToolName != "task_completion" {
+		t.Errorf("expected attempt_completion to resolve to task_completion, got %q", call.ToolName)
+	}
+	if err := a.RegisterTool(&batchTool{summaryTestTool: summaryTestTool{name: "grep"}, recorder: recorder}); err != nil {
+		t.Fatal(err)
+	}
+	call = toolCallWithArgs("grep", "")
+	if alias := a.resolveToolAlias(&call); alias != "" || call.ToolName != "grep" {
+		t.Errorf("expected a registered tool to take precedence over an alias, got %q", call.ToolName)
+	}
+}
@@ -1,5 +1,11 @@
 package agent
 
+import (
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
 // trackError adds an error to the ring buffer and checks if we've hit the circuit breaker
 // Returns true if the circuit breaker should trigger (5 identical consecutive errors)
 func (a *DefaultAgent) trackError(errMsg string) bool {
@@ -29,3 +35,10 @@ func (a *DefaultAgent) resetErrorTracking() {
 	}
 	a.errorIndex = 0
 }
+
+// circuitBreakerError is the error emitted when the circuit breaker ends a
+// turn after 5 consecutive errors of one kind
+func circuitBreakerError(code types.ErrorCode, kind string) error {
+	err := fmt.Errorf("circuit breaker triggered: 5 consecutive %s errors", kind)
+	return types.WrapError(code, err).WithMetadata("circuit_breaker", true)
+}
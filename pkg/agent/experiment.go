@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/google/uuid"
+)
+
+// experiment tags a session with the prompt variant it runs
+type experiment struct {
+	variant string
+	session string
+	sink    metrics.Sink
+	turns   int
+}
+
+// WithPromptVariant tags the session with the name of the prompt variant it
+// runs and records the outcome of each turn (iterations, cost, approval
+// rejections, whether the task was completed) to sink, to compare variants
+// offline. The variant's base prompt is set with WithBasePrompt or
+// WithBasePromptOverride.
+func WithPromptVariant(variant string, sink metrics.Sink) AgentOption {
+	return func(a *DefaultAgent) {
+		a.experiment = &experiment{variant: variant, session: uuid.New().String(), sink: sink}
+	}
+}
+
+// PromptVariant returns the name of the prompt variant the session runs, or
+// "" outside an experiment
+func (a *DefaultAgent) PromptVariant() string {
+	if a.experiment == nil {
+		return ""
+	}
+	return a.experiment.variant
+}
+
+// recordOutcome records how the current turn went to the experiment's sink.
+// Failures are reported as error events and never fail the turn.
+func (a *DefaultAgent) recordOutcome() {
+	if a.experiment == nil || a.turn == nil {
+		return
+	}
+	a.experiment.turns++
+
+	outcome := metrics.Outcome{
+		Time:               time.Now(),
+		Session:            a.experiment.session,
+		Variant:            a.experiment.variant,
+		Turn:               a.experiment.turns,
+		Completed:          a.turn.result != "",
+		ToolErrors:         a.turn.toolErrors,
+		ApprovalRejections: a.turn.rejections,
+		PromptTokens:       a.turn.promptTokens,
+		CompletionTokens:   a.turn.completionTokens,
+		DurationSeconds:    time.Since(a.turn.start).Seconds(),
+	}
+	if a.budget != nil {
+		outcome.Iterations = a.budget.iterations
+	}
+	outcome.Model, outcome.CostUSD, outcome.Priced = a.turnCost()
+
+	if err := a.experiment.sink.Record(outcome); err != nil {
+		a.emitEvent(types.NewErrorEvent(fmt.Errorf("failed to record prompt variant metrics: %w", err)))
+	}
+}
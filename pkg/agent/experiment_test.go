@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// outcomeSink collects recorded outcomes
+type outcomeSink struct {
+	outcomes []metrics.Outcome
+	err      error
+}
+
+func (s *outcomeSink) Record(outcome metrics.Outcome) error {
+	s.outcomes = append(s.outcomes, outcome)
+	return s.err
+}
+
+func TestRecordOutcome(t *testing.T) {
+	sink := &outcomeSink{}
+	a, _ := newRunnerTestAgent(WithPromptVariant("terse", sink))
+	if a.PromptVariant() != "terse" {
+		t.Errorf("expected the session to be tagged with its variant, got %q", a.PromptVariant())
+	}
+	completion := &summaryTestTool{name: "task_completion", loopBreaking: true}
+
+	a.turn = newTurnRecord("hi")
+	a.budget = &turnBudget{iterations: 1}
+	a.recordOutcome()
+
+	a.turn = newTurnRecord("Add a README")
+	a.turn.recordUsage(&types.TokenUsage{PromptTokens: 100, CompletionTokens: 20})
+	a.turn.rejections = 2
+	a.turn.recordTool(completion, toolCallWithArgs("task_completion", "<result>Added the README</result>"), nil)
+	a.budget = &turnBudget{iterations: 4}
+	a.recordOutcome()
+
+	if len(sink.outcomes) != 2 {
+		t.Fatalf("expected an outcome per turn, got %d", len(sink.outcomes))
+	}
+	first, second := sink.outcomes[0], sink.outcomes[1]
+	if first.Completed || first.Turn != 1 || first.Variant != "terse" {
+		t.Errorf("expected an uncompleted first turn, got %+v", first)
+	}
+	if !second.Completed || second.Turn != 2 || second.Iterations != 4 || second.ApprovalRejections != 2 || second.PromptTokens != 100 {
+		t.Errorf("unexpected outcome of the completed turn: %+v", second)
+	}
+	if first.Session == "" || first.Session != second.Session {
+		t.Errorf("expected both turns to carry the session, got %q and %q", first.Session, second.Session)
+	}
+}
+
+func TestRecordOutcomeFailureIsReported(t *testing.T) {
+	a, collected := newRunnerTestAgent(WithPromptVariant("terse", &outcomeSink{err: errors.New("disk full")}))
+	a.turn = newTurnRecord("hi")
+	a.recordOutcome()
+
+	// Allow the collector goroutine to drain
+	time.Sleep(20 * time.Millisecond)
+
+	events := collected()
+	if len(events) != 1 || events[0].Type != types.EventTypeError {
+		t.Errorf("expected the failure as an error event, got %v", events)
+	}
+}
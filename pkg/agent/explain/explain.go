@@ -0,0 +1,157 @@
+// Package explain runs the agent read-only over a workspace to explain its
+// architecture. The workspace is walked into a module and dependency Map
+// without the model; a dedicated loop then lets the model read the code
+// with the agent's read-only tools before it submits a structured
+// architecture Document, which exports as Markdown or JSON.
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Component is a part of the architecture the model identified
+type Component struct {
+	Name           string `json:"name"`
+	Path           string `json:"path"` // Directory or file, relative to the workspace
+	Responsibility string `json:"responsibility"`
+}
+
+// Document is the architecture document for a workspace
+type Document struct {
+	Overview    string      `json:"overview"`
+	Components  []Component `json:"components"`
+	EntryPoints []string    `json:"entry_points"`
+	DataFlow    string      `json:"data_flow"`
+	Conventions []string    `json:"conventions"`
+	Map         *Map        `json:"map"`
+}
+
+// Markdown renders the document as a Markdown report, followed by the
+// module map and the dependencies between modules
+func (d *Document) Markdown() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Architecture: %s\n\n", d.Map.Name))
+	b.WriteString(d.Map.summaryLine() + "\n")
+
+	if d.Overview != "" {
+		b.WriteString("\n## Overview\n\n" + d.Overview + "\n")
+	}
+
+	if len(d.Components) > 0 {
+		b.WriteString("\n## Components\n")
+		for _, c := range d.Components {
+			heading := c.Name
+			if c.Path != "" {
+				heading = fmt.Sprintf("%s (`%s`)", c.Name, c.Path)
+			}
+			b.WriteString(fmt.Sprintf("\n### %s\n\n%s\n", heading, c.Responsibility))
+		}
+	}
+
+	writeList(&b, "Entry Points", d.EntryPoints)
+	if d.DataFlow != "" {
+		b.WriteString("\n## Data Flow\n\n" + d.DataFlow + "\n")
+	}
+	writeList(&b, "Conventions", d.Conventions)
+
+	b.WriteString("\n## Module Map\n\n```text\n" + d.Map.Tree() + "```\n")
+
+	var dependencies []string
+	for _, module := range d.Map.Modules {
+		if len(module.Imports) > 0 {
+			dependencies = append(dependencies, fmt.Sprintf("`%s` → %s", module.Path, codeList(module.Imports)))
+		}
+	}
+	writeList(&b, "Dependencies", dependencies)
+
+	var external []string
+	for _, dep := range d.Map.externalUsage() {
+		external = append(external, fmt.Sprintf("`%s` (%d module(s))", dep.name, dep.modules))
+	}
+	writeList(&b, "External Dependencies", external)
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// JSON renders the document as indented JSON
+func (d *Document) JSON() (string, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode architecture document: %w", err)
+	}
+	return string(data), nil
+}
+
+// summaryLine summarizes the map, e.g. "12 modules, 80 files, 9000 lines (Go 75, Shell 5)"
+func (m *Map) summaryLine() string {
+	names := make([]string, 0, len(m.Languages))
+	for name := range m.Languages {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if m.Languages[names[i]] != m.Languages[names[j]] {
+			return m.Languages[names[i]] > m.Languages[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, m.Languages[name])
+	}
+
+	line := fmt.Sprintf("%d module(s), %d file(s), %d line(s)", len(m.Modules), m.Files, m.Lines)
+	if len(parts) > 0 {
+		line += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return line
+}
+
+// externalDependency is an external dependency and how many modules import it
+type externalDependency struct {
+	name    string
+	modules int
+}
+
+// externalUsage returns the external dependencies, most used first
+func (m *Map) externalUsage() []externalDependency {
+	counts := make(map[string]int)
+	for _, module := range m.Modules {
+		for _, name := range module.External {
+			counts[name]++
+		}
+	}
+	deps := make([]externalDependency, 0, len(counts))
+	for name, count := range counts {
+		deps = append(deps, externalDependency{name: name, modules: count})
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].modules != deps[j].modules {
+			return deps[i].modules > deps[j].modules
+		}
+		return deps[i].name < deps[j].name
+	})
+	return deps
+}
+
+// writeList writes a section of bullet points, if there are any
+func writeList(b *strings.Builder, heading string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	b.WriteString("\n## " + heading + "\n\n")
+	for _, item := range items {
+		b.WriteString("- " + item + "\n")
+	}
+}
+
+// codeList formats names as a comma-separated list of code spans
+func codeList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
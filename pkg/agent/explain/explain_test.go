@@ -0,0 +1,234 @@
+package explain
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// testTree is a workspace with Go, TypeScript and Python modules
+var testTree = workspacetest.Tree{
+	"go.mod": "module example.com/app\n\ngo 1.22\n",
+	"cmd/app/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/app/pkg/store"
+	"github.com/spf13/cobra"
+)
+
+func main() { fmt.Println(store.Name, cobra.Command{}) }
+`,
+	"pkg/store/store.go":          "package store\n\nconst Name = \"store\"\n",
+	"pkg/README.md":               "# Packages\n",
+	"web/src/index.ts":            "import React from 'react'\nimport { helper } from './util/helpers'\nconst fs = require('node:fs')\n",
+	"web/src/util/helpers.ts":     "export function helper() {}\n",
+	"node_modules/react/index.js": "module.exports = {}\n",
+	"scripts/app/main.py":         "import requests\nfrom .models import User\nfrom scripts.lib import db\n",
+	"scripts/app/models.py":       "class User:\n    pass\n",
+	"scripts/lib/db.py":           "import os\n",
+}
+
+func TestBuildMap(t *testing.T) {
+	ws := workspacetest.New(t, testTree)
+	m, err := BuildMap(context.Background(), ws.Guard())
+	if err != nil {
+		t.Fatalf("BuildMap failed: %v", err)
+	}
+
+	if m.Name != "example.com/app" {
+		t.Errorf("expected the go.mod module path as name, got %q", m.Name)
+	}
+	var paths []string
+	for _, module := range m.Modules {
+		paths = append(paths, module.Path)
+	}
+	want := []string{"cmd/app", "pkg/store", "scripts/app", "scripts/lib", "web/src", "web/src/util"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected modules %v (node_modules ignored, pkg has no source of its own), got %v", want, paths)
+	}
+	if m.Files != 7 || m.Languages["Go"] != 2 || m.Languages["TypeScript"] != 2 || m.Languages["Python"] != 3 {
+		t.Errorf("unexpected counts: %d files, %v", m.Files, m.Languages)
+	}
+
+	cases := []struct {
+		path       string
+		language   string
+		imports    []string
+		importedBy []string
+		external   []string
+	}{
+		{"cmd/app", "Go", []string{"pkg/store"}, nil, []string{"github.com/spf13/cobra"}},
+		{"pkg/store", "Go", nil, []string{"cmd/app"}, nil},
+		{"web/src", "TypeScript", []string{"web/src/util"}, nil, []string{"react"}},
+		{"web/src/util", "TypeScript", nil, []string{"web/src"}, nil},
+		{"scripts/app", "Python", []string{"scripts/lib"}, nil, []string{"requests"}},
+		{"scripts/lib", "Python", nil, []string{"scripts/app"}, []string{"os"}},
+	}
+	for _, tc := range cases {
+		module, ok := m.Module(tc.path)
+		if !ok {
+			t.Errorf("missing module %s", tc.path)
+			continue
+		}
+		if module.Language != tc.language {
+			t.Errorf("%s: expected %s, got %s", tc.path, tc.language, module.Language)
+		}
+		if !reflect.DeepEqual(module.Imports, tc.imports) {
+			t.Errorf("%s: expected imports %v, got %v", tc.path, tc.imports, module.Imports)
+		}
+		if !reflect.DeepEqual(module.ImportedBy, tc.importedBy) {
+			t.Errorf("%s: expected imported by %v, got %v", tc.path, tc.importedBy, module.ImportedBy)
+		}
+		if !reflect.DeepEqual(module.External, tc.external) {
+			t.Errorf("%s: expected external %v, got %v", tc.path, tc.external, module.External)
+		}
+	}
+}
+
+func TestMapNodes(t *testing.T) {
+	m := &Map{Modules: []*Module{
+		{Path: "."},
+		{Path: "pkg/a"},
+		{Path: "pkg/a/b"},
+		{Path: "pkg-tools"},
+	}}
+
+	var got []string
+	for _, node := range m.Nodes() {
+		got = append(got, strings.Repeat("  ", node.Depth)+node.Name)
+	}
+	want := []string{".", "pkg", "  a", "    b", "pkg-tools"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected nodes %q, got %q", want, got)
+	}
+}
+
+func TestDocumentMarkdown(t *testing.T) {
+	ws := workspacetest.New(t, testTree)
+	m, err := BuildMap(context.Background(), ws.Guard())
+	if err != nil {
+		t.Fatalf("BuildMap failed: %v", err)
+	}
+	doc := &Document{
+		Overview:    "A demo app.",
+		Components:  []Component{{Name: "Store", Path: "pkg/store", Responsibility: "Holds data."}},
+		EntryPoints: []string{"cmd/app/main.go"},
+		Map:         m,
+	}
+
+	md := doc.Markdown()
+	for _, want := range []string{
+		"# Architecture: example.com/app",
+		"6 module(s), 7 file(s)",
+		"### Store (`pkg/store`)",
+		"- cmd/app/main.go",
+		"```text\ncmd/\n  app/  Go, 1 files",
+		"- `cmd/app` → `pkg/store`",
+		"- `github.com/spf13/cobra` (1 module(s))",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "## Data Flow") {
+		t.Errorf("empty sections should be omitted:\n%s", md)
+	}
+
+	data, err := doc.JSON()
+	if err != nil || !strings.Contains(data, `"imported_by": [`) {
+		t.Errorf("unexpected JSON (%v):\n%s", err, data)
+	}
+}
+
+// scriptedProvider returns its responses in order
+type scriptedProvider struct {
+	responses []string
+	calls     [][]*types.Message
+}
+
+func (p *scriptedProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	return nil, nil
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	p.calls = append(p.calls, messages)
+	response := p.responses[0]
+	p.responses = p.responses[1:]
+	return types.NewAssistantMessage(response), nil
+}
+
+func (p *scriptedProvider) GetModelInfo() *types.ModelInfo {
+	return nil
+}
+
+// echoTool is a read-only tool that returns a fixed result
+type echoTool struct{}
+
+func (echoTool) Name() string                                    { return "read_file" }
+func (echoTool) Description() string                             { return "Read a file" }
+func (echoTool) Schema() map[string]interface{}                  { return map[string]interface{}{} }
+func (echoTool) IsLoopBreaking() bool                            { return false }
+func (echoTool) Execute(context.Context, []byte) (string, error) { return "func main() {}", nil }
+
+func TestExplainer_Explain(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		`<tool><server_name>local</server_name><tool_name>read_file</tool_name><arguments><path>cmd/app/main.go</path></arguments></tool>`,
+		`<tool><server_name>local</server_name><tool_name>submit_architecture</tool_name><arguments><components/></arguments></tool>`,
+		`<tool><server_name>local</server_name><tool_name>submit_architecture</tool_name><arguments>
+<overview>A CLI over a store.</overview>
+<components>
+  <component><name>CLI</name><path>cmd/app</path><responsibility>Parses flags.</responsibility></component>
+</components>
+<entry_points><entry_point>cmd/app/main.go</entry_point><entry_point> </entry_point></entry_points>
+<data_flow>main calls store.</data_flow>
+</arguments></tool>`,
+	}}
+
+	m := &Map{Name: "example.com/app", Modules: []*Module{{Path: "cmd/app", Language: "Go", Imports: []string{"pkg/store"}}}}
+	var progress []string
+	explainer := NewExplainer(provider, WithTools(echoTool{}), WithProgress(func(step int, toolName string) {
+		progress = append(progress, toolName)
+	}))
+	doc, err := explainer.Explain(context.Background(), m)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+
+	if len(provider.calls) != 3 {
+		t.Fatalf("expected 3 LLM calls, got %d", len(provider.calls))
+	}
+	if first := provider.calls[0][1].Content; !strings.Contains(first, "cmd/app -> pkg/store") {
+		t.Errorf("the module map should be sent to the model, got:\n%s", first)
+	}
+	if last := provider.calls[2][len(provider.calls[2])-1].Content; !strings.Contains(last, "overview is required") {
+		t.Errorf("an invalid submission should get a recovery message, got:\n%s", last)
+	}
+
+	if doc.Overview != "A CLI over a store." || doc.DataFlow != "main calls store." || doc.Map != m {
+		t.Errorf("unexpected document %+v", doc)
+	}
+	if len(doc.Components) != 1 || doc.Components[0] != (Component{Name: "CLI", Path: "cmd/app", Responsibility: "Parses flags."}) {
+		t.Errorf("unexpected components %+v", doc.Components)
+	}
+	if !reflect.DeepEqual(doc.EntryPoints, []string{"cmd/app/main.go"}) {
+		t.Errorf("unexpected entry points %v", doc.EntryPoints)
+	}
+	if !reflect.DeepEqual(progress, []string{"read_file"}) {
+		t.Errorf("unexpected progress reports %v", progress)
+	}
+}
+
+func TestExplainer_GivesUpAfterMaxSteps(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{"thinking", "still thinking"}}
+	_, err := NewExplainer(provider, WithMaxSteps(2)).Explain(context.Background(), &Map{})
+	if err == nil || !strings.Contains(err.Error(), "no architecture submitted after 2 steps") {
+		t.Errorf("expected a max steps error, got %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package explain
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/readonly"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// defaultMaxSteps bounds the LLM calls spent explaining a workspace
+const defaultMaxSteps = 30
+
+// maxMapPromptSize bounds the bytes of module map sent to the model; the
+// model can list the rest of a large workspace itself
+const maxMapPromptSize = 24 * 1024
+
+// submitToolName is the loop-breaking tool that ends the explanation
+const submitToolName = "submit_architecture"
+
+// explainInstructions direct the model to explain instead of edit
+const explainInstructions = `You are explaining the architecture of a codebase to a developer who is new to it, not changing it. Never modify files.
+
+You will be shown a map of the workspace's modules (directories holding source files) and the imports between them. Use the read-only tools to read the entry points, the most depended-on modules and anything else the map alone does not explain, then call submit_architecture exactly once with:
+- overview: what the project is and how it is put together, in a few paragraphs
+- components: the main parts of the system, each with a name, the path it lives at, and its responsibility
+- entry_points: where execution or use starts (binaries, servers, public APIs), each with its path
+- data_flow: how a typical request, command or input moves through the components
+- conventions: patterns a contributor must follow (error handling, configuration, testing, naming)
+
+Base every statement on code you have seen. Prefer a few accurate components over an exhaustive list.`
+
+// Explainer explains a workspace with a read-only loop: it lets the model
+// call read-only tools until it submits the architecture document.
+type Explainer struct {
+	loop     *readonly.Loop
+	toolList []tools.Tool
+	maxSteps int
+	progress func(step int, toolName string)
+}
+
+// Option configures an Explainer
+type Option func(*Explainer)
+
+// WithTools makes tools available to the explainer for reading the code.
+// Only pass tools without side effects (read_file, list_files, search_files).
+func WithTools(toolList ...tools.Tool) Option {
+	return func(e *Explainer) {
+		e.toolList = append(e.toolList, toolList...)
+	}
+}
+
+// WithMaxSteps bounds the LLM calls spent on the explanation.
+func WithMaxSteps(steps int) Option {
+	return func(e *Explainer) {
+		if steps > 0 {
+			e.maxSteps = steps
+		}
+	}
+}
+
+// WithProgress sets a function called before each tool the model calls runs.
+func WithProgress(fn func(step int, toolName string)) Option {
+	return func(e *Explainer) {
+		e.progress = fn
+	}
+}
+
+// NewExplainer creates an explainer that uses provider for the explanation loop.
+func NewExplainer(provider llm.Provider, opts ...Option) *Explainer {
+	e := &Explainer{
+		maxSteps: defaultMaxSteps,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.loop = readonly.NewLoop(provider,
+		readonly.WithTools(e.toolList...),
+		readonly.WithMaxSteps(e.maxSteps),
+		readonly.WithProgress(e.progress),
+	)
+	return e
+}
+
+// Explain runs the explanation loop over the workspace m maps until the
+// model submits the architecture document.
+func (e *Explainer) Explain(ctx context.Context, m *Map) (*Document, error) {
+	submit := &submitArchitectureTool{}
+	task := fmt.Sprintf("Explain the architecture of %s.\n\n%s", m.Name, mapPrompt(m))
+	if err := e.loop.Run(ctx, explainInstructions, task, submit); err != nil {
+		if errors.Is(err, readonly.ErrNotSubmitted) {
+			return nil, fmt.Errorf("no architecture submitted after %d steps", e.maxSteps)
+		}
+		return nil, err
+	}
+	doc := submit.document
+	doc.Map = m
+	return &doc, nil
+}
+
+// mapPrompt describes the module map for the model: the tree, then each
+// module's imports, truncated to maxMapPromptSize
+func mapPrompt(m *Map) string {
+	var b strings.Builder
+	b.WriteString(m.summaryLine() + "\n\nModules:\n" + m.Tree())
+
+	b.WriteString("\nImports between modules:\n")
+	for _, module := range m.Modules {
+		if len(module.Imports) > 0 {
+			b.WriteString(fmt.Sprintf("%s -> %s\n", module.Path, strings.Join(module.Imports, ", ")))
+		}
+	}
+
+	if deps := m.externalUsage(); len(deps) > 0 {
+		b.WriteString("\nExternal dependencies (modules importing them):\n")
+		for _, dep := range deps {
+			b.WriteString(fmt.Sprintf("%s (%d)\n", dep.name, dep.modules))
+		}
+	}
+
+	prompt := b.String()
+	if len(prompt) > maxMapPromptSize {
+		prompt = prompt[:maxMapPromptSize] + "\n[map truncated; use list_files to see the rest]\n"
+	}
+	return prompt
+}
+
+// submitArchitectureTool ends the explanation, holding the document it was
+// called with.
+type submitArchitectureTool struct {
+	document Document
+}
+
+// Name returns the tool name.
+func (t *submitArchitectureTool) Name() string {
+	return submitToolName
+}
+
+// Description returns the tool description.
+func (t *submitArchitectureTool) Description() string {
+	return "Submit the architecture document: an overview, the main components, entry points, data flow and conventions. Call it exactly once, when you understand the codebase."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *submitArchitectureTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"overview": map[string]interface{}{
+				"type":        "string",
+				"description": "What the project is and how it is put together, in a few paragraphs",
+			},
+			"components": map[string]interface{}{
+				"type":        "array",
+				"description": "The main parts of the system",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Short name of the component",
+						},
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Directory or file the component lives at, relative to the workspace",
+						},
+						"responsibility": map[string]interface{}{
+							"type":        "string",
+							"description": "What the component does and what it depends on",
+						},
+					},
+					"required": []string{"name", "path", "responsibility"},
+				},
+			},
+			"entry_points": map[string]interface{}{
+				"type":        "array",
+				"description": "Where execution or use starts, each with its path",
+				"items":       map[string]interface{}{"type": "string"},
+			},
+			"data_flow": map[string]interface{}{
+				"type":        "string",
+				"description": "How a typical request, command or input moves through the components",
+			},
+			"conventions": map[string]interface{}{
+				"type":        "array",
+				"description": "Patterns a contributor must follow",
+				"items":       map[string]interface{}{"type": "string"},
+			},
+		},
+		[]string{"overview", "components"},
+	)
+}
+
+// Execute records the document.
+func (t *submitArchitectureTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	if err := t.parse(argsXML); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Architecture submitted with %d component(s)", len(t.document.Components)), nil
+}
+
+// IsLoopBreaking returns true as submitting ends the explanation.
+func (t *submitArchitectureTool) IsLoopBreaking() bool {
+	return true
+}
+
+// parse reads the document from the tool arguments
+func (t *submitArchitectureTool) parse(argsXML []byte) error {
+	var input struct {
+		XMLName    xml.Name `xml:"arguments"`
+		Overview   string   `xml:"overview"`
+		Components []struct {
+			Name           string `xml:"name"`
+			Path           string `xml:"path"`
+			Responsibility string `xml:"responsibility"`
+		} `xml:"components>component"`
+		EntryPoints []string `xml:"entry_points>entry_point"`
+		DataFlow    string   `xml:"data_flow"`
+		Conventions []string `xml:"conventions>convention"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	overview := strings.TrimSpace(input.Overview)
+	if overview == "" {
+		return fmt.Errorf("overview is required")
+	}
+
+	components := make([]Component, 0, len(input.Components))
+	for _, c := range input.Components {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			return fmt.Errorf("component at %q has no name", c.Path)
+		}
+		components = append(components, Component{
+			Name:           name,
+			Path:           strings.TrimSpace(c.Path),
+			Responsibility: strings.TrimSpace(c.Responsibility),
+		})
+	}
+
+	t.document = Document{
+		Overview:    overview,
+		Components:  components,
+		EntryPoints: trimAll(input.EntryPoints),
+		DataFlow:    strings.TrimSpace(input.DataFlow),
+		Conventions: trimAll(input.Conventions),
+	}
+	return nil
+}
+
+// trimAll trims each item and drops empty ones
+func trimAll(items []string) []string {
+	var trimmed []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
+	}
+	return trimmed
+}
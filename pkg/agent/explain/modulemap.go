@@ -0,0 +1,443 @@
+package explain
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// maxSourceFileSize bounds the files read for imports; larger files still
+// count towards their module but their imports are not parsed
+const maxSourceFileSize = 1 << 20
+
+// languages maps source file extensions to language names. Files with other
+// extensions do not make a directory a module.
+var languages = map[string]string{
+	".go":    "Go",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".cjs":   "JavaScript",
+	".py":    "Python",
+	".rs":    "Rust",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".rb":    "Ruby",
+	".php":   "PHP",
+	".c":     "C",
+	".h":     "C",
+	".cpp":   "C++",
+	".cc":    "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".swift": "Swift",
+	".sh":    "Shell",
+}
+
+var (
+	// jsImportPattern matches import ... from "x", import "x", export ... from "x" and require("x")
+	jsImportPattern = regexp.MustCompile(`(?m)(?:^\s*(?:import|export)\b[^'"\n]*?(?:from\s*)?['"]([^'"\n]+)['"])|(?:\brequire\(\s*['"]([^'"\n]+)['"]\s*\))`)
+
+	// pyImportPattern matches from x import y and import x
+	pyImportPattern = regexp.MustCompile(`^\s*(?:from\s+(\.*[\w.]*)\s+import\b|import\s+([\w.]+))`)
+)
+
+// Module is a directory holding source files
+type Module struct {
+	// Path is the directory, slash-separated and relative to the workspace;
+	// "." for the workspace root
+	Path string `json:"path"`
+
+	// Language is the language most of the module's source files are in
+	Language string `json:"language"`
+
+	Files int `json:"files"`
+	Lines int `json:"lines"`
+
+	// Imports are the modules in the workspace this module imports
+	Imports []string `json:"imports,omitempty"`
+
+	// ImportedBy are the modules in the workspace that import this module
+	ImportedBy []string `json:"imported_by,omitempty"`
+
+	// External are the dependencies from outside the workspace it imports
+	External []string `json:"external,omitempty"`
+}
+
+// Map is the module and dependency map of a workspace
+type Map struct {
+	// Name is the Go module path from go.mod, or the workspace directory's name
+	Name string `json:"name"`
+
+	// Modules are sorted by path
+	Modules []*Module `json:"modules"`
+
+	// Languages counts source files per language
+	Languages map[string]int `json:"languages"`
+
+	Files int `json:"files"`
+	Lines int `json:"lines"`
+}
+
+// importRef is an import found in a source file. Candidate is a workspace
+// path the import may refer to; External names the dependency if the
+// candidate does not resolve to a module. An import with neither, such as
+// a Go standard library package, is not recorded.
+type importRef struct {
+	candidate string
+	external  string
+}
+
+// moduleScan collects a module's files while the workspace is walked
+type moduleScan struct {
+	module    *Module
+	languages map[string]int
+	refs      []importRef
+}
+
+// BuildMap walks the workspace, skipping the paths guard ignores, and maps
+// its modules and the imports between them. Only Go, JavaScript, TypeScript
+// and Python imports are parsed; modules in other languages are listed
+// without dependencies.
+func BuildMap(ctx context.Context, guard *workspace.Guard) (*Map, error) {
+	root := guard.WorkspaceDir()
+	m := &Map{Name: filepath.Base(root), Languages: make(map[string]int)}
+	goModule := readGoModulePath(filepath.Join(root, "go.mod"))
+	if goModule != "" {
+		m.Name = goModule
+	}
+
+	scans := make(map[string]*moduleScan)
+	err := filepath.Walk(root, func(absPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip entries with errors
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if absPath == root {
+			return nil
+		}
+		if guard.ShouldIgnore(absPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		language, ok := languages[strings.ToLower(filepath.Ext(absPath))]
+		if !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(absPath))
+		if err != nil {
+			return nil
+		}
+		dir := filepath.ToSlash(rel)
+
+		scan, ok := scans[dir]
+		if !ok {
+			scan = &moduleScan{module: &Module{Path: dir}, languages: make(map[string]int)}
+			scans[dir] = scan
+		}
+		scan.languages[language]++
+		scan.module.Files++
+		m.Languages[language]++
+		m.Files++
+
+		if info.Size() > maxSourceFileSize {
+			return nil
+		}
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return nil
+		}
+		lines := strings.Count(string(content), "\n")
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			lines++
+		}
+		scan.module.Lines += lines
+		m.Lines += lines
+		scan.refs = append(scan.refs, fileImports(absPath, dir, language, content, goModule)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk workspace: %w", err)
+	}
+
+	for _, scan := range scans {
+		scan.module.Language = mostCommon(scan.languages)
+		m.Modules = append(m.Modules, scan.module)
+	}
+	sort.Slice(m.Modules, func(i, j int) bool { return m.Modules[i].Path < m.Modules[j].Path })
+
+	// Resolve imports once every module is known
+	for _, module := range m.Modules {
+		imports := make(map[string]bool)
+		external := make(map[string]bool)
+		for _, ref := range scans[module.Path].refs {
+			if target, ok := resolveModule(scans, ref.candidate); ok {
+				if target != module.Path {
+					imports[target] = true
+				}
+				continue
+			}
+			if ref.external != "" {
+				external[ref.external] = true
+			}
+		}
+		module.Imports = sortedKeys(imports)
+		module.External = sortedKeys(external)
+		for _, target := range module.Imports {
+			dependency := scans[target].module
+			dependency.ImportedBy = append(dependency.ImportedBy, module.Path)
+		}
+	}
+
+	return m, nil
+}
+
+// Module returns the module at path, if there is one
+func (m *Map) Module(path string) (*Module, bool) {
+	for _, module := range m.Modules {
+		if module.Path == path {
+			return module, true
+		}
+	}
+	return nil, false
+}
+
+// Tree renders the modules as an indented directory tree with their
+// language and size, e.g.
+//
+//	pkg/
+//	  agent/  Go, 12 files, 3400 lines
+func (m *Map) Tree() string {
+	var b strings.Builder
+	for _, node := range m.Nodes() {
+		b.WriteString(strings.Repeat("  ", node.Depth))
+		b.WriteString(node.Name + "/")
+		if node.Module != nil {
+			b.WriteString(fmt.Sprintf("  %s, %d files, %d lines", node.Module.Language, node.Module.Files, node.Module.Lines))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Node is a directory in the map's tree: a module, or a directory that only
+// holds modules
+type Node struct {
+	Path   string  // Slash-separated and relative to the workspace
+	Name   string  // The last path element, or "." for the workspace root
+	Depth  int     // 0 for top-level directories
+	Module *Module // nil for a directory without source files of its own
+}
+
+// Nodes returns the directories of the tree depth-first, each followed by
+// its subdirectories. Directories that lead to modules are included so the
+// tree has no gaps.
+func (m *Map) Nodes() []Node {
+	modules := make(map[string]*Module, len(m.Modules))
+	dirs := make(map[string]bool)
+	for _, module := range m.Modules {
+		modules[module.Path] = module
+		for dir := module.Path; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	paths := sortedKeys(dirs)
+	// Sort by path element so a directory's children follow it directly
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.ReplaceAll(paths[i], "/", "\x00") < strings.ReplaceAll(paths[j], "/", "\x00")
+	})
+
+	var nodes []Node
+	if root, ok := modules["."]; ok {
+		nodes = append(nodes, Node{Path: ".", Name: ".", Module: root})
+	}
+	for _, p := range paths {
+		nodes = append(nodes, Node{
+			Path:   p,
+			Name:   path.Base(p),
+			Depth:  strings.Count(p, "/"),
+			Module: modules[p],
+		})
+	}
+	return nodes
+}
+
+// fileImports returns the imports of a source file in module dir
+func fileImports(absPath, dir, language string, content []byte, goModule string) []importRef {
+	switch language {
+	case "Go":
+		return goImports(absPath, content, goModule)
+	case "JavaScript", "TypeScript":
+		return jsImports(dir, content)
+	case "Python":
+		return pyImports(dir, content)
+	}
+	return nil
+}
+
+// goImports returns a Go file's imports. Packages under goModule are
+// candidates in the workspace; other packages whose path starts with a
+// domain are external; the standard library is skipped.
+func goImports(absPath string, content []byte, goModule string) []importRef {
+	file, err := parser.ParseFile(token.NewFileSet(), absPath, content, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+
+	var refs []importRef
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		switch {
+		case goModule != "" && importPath == goModule:
+			refs = append(refs, importRef{candidate: "."})
+		case goModule != "" && strings.HasPrefix(importPath, goModule+"/"):
+			refs = append(refs, importRef{candidate: strings.TrimPrefix(importPath, goModule+"/")})
+		case strings.Contains(strings.SplitN(importPath, "/", 2)[0], "."):
+			refs = append(refs, importRef{external: importPath})
+		}
+	}
+	return refs
+}
+
+// jsImports returns a JavaScript or TypeScript file's imports. Relative
+// imports are candidates in the workspace; others are external packages,
+// named by their scope and package name.
+func jsImports(dir string, content []byte) []importRef {
+	var refs []importRef
+	for _, match := range jsImportPattern.FindAllSubmatch(content, -1) {
+		spec := string(match[1])
+		if spec == "" {
+			spec = string(match[2])
+		}
+		switch {
+		case strings.HasPrefix(spec, "."):
+			refs = append(refs, importRef{candidate: path.Join(dir, spec)})
+		case strings.HasPrefix(spec, "node:"):
+			// Node built-in module
+		default:
+			parts := strings.SplitN(spec, "/", 3)
+			name := parts[0]
+			if strings.HasPrefix(name, "@") && len(parts) > 1 {
+				name += "/" + parts[1]
+			}
+			refs = append(refs, importRef{external: name})
+		}
+	}
+	return refs
+}
+
+// pyImports returns a Python file's imports. Relative imports are resolved
+// from dir; absolute imports are candidates from the workspace root and
+// otherwise external, named by their top-level package.
+func pyImports(dir string, content []byte) []importRef {
+	var refs []importRef
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		match := pyImportPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		name := match[1]
+		if name == "" {
+			name = match[2]
+		}
+
+		if strings.HasPrefix(name, ".") {
+			base := dir
+			dots := len(name) - len(strings.TrimLeft(name, "."))
+			for i := 1; i < dots; i++ {
+				base = path.Dir(base)
+			}
+			rest := strings.ReplaceAll(strings.TrimLeft(name, "."), ".", "/")
+			refs = append(refs, importRef{candidate: path.Join(base, rest)})
+			continue
+		}
+		refs = append(refs, importRef{
+			candidate: strings.ReplaceAll(name, ".", "/"),
+			external:  strings.SplitN(name, ".", 2)[0],
+		})
+	}
+	return refs
+}
+
+// resolveModule returns the module candidate refers to: the candidate
+// itself if it is a module directory, otherwise the directory of the file it
+// names
+func resolveModule(scans map[string]*moduleScan, candidate string) (string, bool) {
+	if candidate == "" || candidate == ".." || strings.HasPrefix(candidate, "../") {
+		return "", false
+	}
+	if _, ok := scans[candidate]; ok {
+		return candidate, true
+	}
+	if dir := path.Dir(candidate); dir != candidate {
+		if _, ok := scans[dir]; ok {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// readGoModulePath returns the module path declared in a go.mod file, or ""
+func readGoModulePath(goModPath string) string {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// mostCommon returns the key with the highest count, breaking ties by name
+func mostCommon(counts map[string]int) string {
+	best := ""
+	for key, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && key < best) {
+			best = key
+		}
+	}
+	return best
+}
+
+// sortedKeys returns the keys of set in order
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Attribution marks commits as made by the agent: a distinct committer
+// identity and trailer lines appended to the message. The author stays the
+// user's own git identity, so audits can tell agent commits from human ones.
+//
+// Fields may use the placeholders {user} and {email} (the user's git
+// identity) and {session} (SessionID).
+type Attribution struct {
+	CommitterName  string   // e.g. "Forge Agent on behalf of {user}"; "" = git's committer
+	CommitterEmail string   // "" = git's committer email
+	Trailers       []string // e.g. "Co-authored-by: {user} <{email}>", "Session-ID: {session}"
+	SessionID      string
+}
+
+// Message returns message with the attribution's trailers appended
+func (a Attribution) Message(ctx context.Context, workingDir, message string) string {
+	if len(a.Trailers) == 0 {
+		return message
+	}
+
+	expand := a.expander(ctx, workingDir)
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(message, "\n"))
+	b.WriteString("\n\n")
+	for _, trailer := range a.Trailers {
+		b.WriteString(expand(trailer))
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Committer returns the committer identity commits are made with, or "" when
+// git's configured committer is used
+func (a Attribution) Committer(ctx context.Context, workingDir string) string {
+	if a.CommitterName == "" && a.CommitterEmail == "" {
+		return ""
+	}
+
+	expand := a.expander(ctx, workingDir)
+	name, email := expand("{user}"), expand("{email}")
+	if a.CommitterName != "" {
+		name = expand(a.CommitterName)
+	}
+	if a.CommitterEmail != "" {
+		email = expand(a.CommitterEmail)
+	}
+	return name + " <" + email + ">"
+}
+
+// env returns the environment variables that set the committer identity
+func (a Attribution) env(ctx context.Context, workingDir string) []string {
+	expand := a.expander(ctx, workingDir)
+	var env []string
+	if a.CommitterName != "" {
+		env = append(env, "GIT_COMMITTER_NAME="+expand(a.CommitterName))
+	}
+	if a.CommitterEmail != "" {
+		env = append(env, "GIT_COMMITTER_EMAIL="+expand(a.CommitterEmail))
+	}
+	return env
+}
+
+// expander returns a function that fills in the attribution placeholders.
+// The user's identity is looked up at most once, under ctx.
+func (a Attribution) expander(ctx context.Context, workingDir string) func(string) string {
+	var replacer *strings.Replacer
+	return func(s string) string {
+		if !strings.Contains(s, "{") {
+			return s
+		}
+		if replacer == nil {
+			name, email := userIdentity(ctx, workingDir)
+			replacer = strings.NewReplacer("{user}", name, "{email}", email, "{session}", a.SessionID)
+		}
+		return replacer.Replace(s)
+	}
+}
+
+// userIdentity returns the user's git name and email, falling back to $USER
+// for the name
+func userIdentity(ctx context.Context, workingDir string) (string, string) {
+	name := gitConfig(ctx, workingDir, "user.name")
+	if name == "" {
+		name = os.Getenv("USER")
+	}
+	return name, gitConfig(ctx, workingDir, "user.email")
+}
+
+// gitConfig returns a git configuration value, or "" if it is not set
+func gitConfig(ctx context.Context, workingDir, key string) string {
+	cmd := exec.CommandContext(ctx, "git", "config", "--get", key)
+	cmd.Dir = workingDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,44 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestCreateCommit_Attribution(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"}, workspacetest.WithGit())
+	dir := ws.Dir
+	if err := StageFiles(context.Background(), dir, []string{"main.go"}); err != nil {
+		t.Fatalf("StageFiles failed: %v", err)
+	}
+
+	attr := Attribution{
+		CommitterName:  "Forge Agent on behalf of {user}",
+		CommitterEmail: "forge-agent@example.com",
+		Trailers:       []string{"Co-authored-by: {user} <{email}>", "Session-ID: {session}"},
+		SessionID:      "abc-123",
+	}
+	if got := attr.Committer(context.Background(), dir); got != "Forge Agent on behalf of Test <forge-agent@example.com>" {
+		t.Errorf("unexpected committer %q", got)
+	}
+
+	if _, err := CreateCommit(context.Background(), dir, "feat: add main\n", attr); err != nil {
+		t.Fatalf("CreateCommit failed: %v", err)
+	}
+
+	want := "Test <test@example.com>\n" +
+		"Forge Agent on behalf of Test <forge-agent@example.com>\n" +
+		"feat: add main\n\nCo-authored-by: Test <test@example.com>\nSession-ID: abc-123"
+	if got := ws.Git("log", "-1", "--format=%an <%ae>%n%cn <%ce>%n%B"); got != want {
+		t.Errorf("unexpected commit:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAttribution_Zero(t *testing.T) {
+	var attr Attribution
+	if attr.Committer(context.Background(), ".") != "" || attr.Message(context.Background(), ".", "fix: typo") != "fix: typo" {
+		t.Error("expected an empty attribution to leave commits unchanged")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -15,16 +16,31 @@ type CommitInfo struct {
 
 type CommitMessageGenerator struct {
 	llmClient LLMClient
+	style     MessageStyle
 }
 
 type LLMClient interface {
 	Generate(ctx context.Context, prompt string) (string, error)
 }
 
-func NewCommitMessageGenerator(llmClient LLMClient) *CommitMessageGenerator {
-	return &CommitMessageGenerator{
+// CommitMessageOption configures a CommitMessageGenerator
+type CommitMessageOption func(*CommitMessageGenerator)
+
+// WithMessageStyle sets the style generated messages follow.
+func WithMessageStyle(style MessageStyle) CommitMessageOption {
+	return func(g *CommitMessageGenerator) {
+		g.style = style
+	}
+}
+
+func NewCommitMessageGenerator(llmClient LLMClient, opts ...CommitMessageOption) *CommitMessageGenerator {
+	g := &CommitMessageGenerator{
 		llmClient: llmClient,
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 func (g *CommitMessageGenerator) Generate(ctx context.Context, workingDir string, files []string) (string, error) {
@@ -32,24 +48,34 @@ func (g *CommitMessageGenerator) Generate(ctx context.Context, workingDir string
 		return "", fmt.Errorf("no files to commit")
 	}
 
-	diff, err := getDiff(workingDir, files)
+	diff, err := getDiff(ctx, workingDir, files)
 	if err != nil {
 		return "", fmt.Errorf("failed to get diff: %w", err)
 	}
 
-	prompt := buildCommitPrompt(diff, files)
-	message, err := g.llmClient.Generate(ctx, prompt)
+	if !g.style.structured() {
+		message, err := g.llmClient.Generate(ctx, buildCommitPrompt(diff, files))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate commit message: %w", err)
+		}
+		return strings.TrimSpace(message), nil
+	}
+
+	// The model picks the type and writes the description; the style does the rest
+	summary, err := g.llmClient.Generate(ctx, buildTypedCommitPrompt(diff, files, g.style.types()))
 	if err != nil {
 		return "", fmt.Errorf("failed to generate commit message: %w", err)
 	}
+	commitType, description := g.style.parseTypedSummary(summary)
+	branch, _ := getCurrentBranch(ctx, workingDir) // Without a branch there is no issue ID to extract
 
-	return strings.TrimSpace(message), nil
+	return g.style.Format(commitType, description, files, branch), nil
 }
 
-func getDiff(workingDir string, files []string) (string, error) {
+func getDiff(ctx context.Context, workingDir string, files []string) (string, error) {
 	// Try git diff HEAD first (for modified tracked files)
 	args := append([]string{"diff", "HEAD", "--"}, files...)
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -62,7 +88,7 @@ func getDiff(workingDir string, files []string) (string, error) {
 		stderr.Reset()
 
 		args = append([]string{"diff", "--"}, files...)
-		cmd = exec.Command("git", args...)
+		cmd = exec.CommandContext(ctx, "git", args...)
 		cmd.Dir = workingDir
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
@@ -73,7 +99,7 @@ func getDiff(workingDir string, files []string) (string, error) {
 			stderr.Reset()
 
 			args = append([]string{"diff", "--cached", "--"}, files...)
-			cmd = exec.Command("git", args...)
+			cmd = exec.CommandContext(ctx, "git", args...)
 			cmd.Dir = workingDir
 			cmd.Stdout = &stdout
 			cmd.Stderr = &stderr
@@ -90,19 +116,41 @@ func getDiff(workingDir string, files []string) (string, error) {
 func buildCommitPrompt(diff string, files []string) string {
 	var sb strings.Builder
 
-	sb.WriteString("Generate a conventional commit message for these changes.\\n\\n")
-	sb.WriteString("Format: <type>(<scope>): <description>\\n")
-	sb.WriteString("Types: feat, fix, docs, style, refactor, test, chore\\n\\n")
+	sb.WriteString("Generate a conventional commit message for these changes.\n\n")
+	sb.WriteString("Format: <type>(<scope>): <description>\n")
+	sb.WriteString("Types: feat, fix, docs, style, refactor, test, chore\n\n")
 
-	sb.WriteString("Files changed:\\n")
+	sb.WriteString("Files changed:\n")
 	for _, file := range files {
-		sb.WriteString(fmt.Sprintf("- %s\\n", file))
+		sb.WriteString(fmt.Sprintf("- %s\n", file))
 	}
 
-	sb.WriteString("\\nDiff:\\n")
+	sb.WriteString("\nDiff:\n")
 	sb.WriteString(truncateDiff(diff, 3000))
 
-	sb.WriteString("\\n\\nGenerate ONLY the commit message (one line), nothing else.")
+	sb.WriteString("\n\nGenerate ONLY the commit message (one line), nothing else.")
+
+	return sb.String()
+}
+
+// buildTypedCommitPrompt asks for a commit type and description only
+func buildTypedCommitPrompt(diff string, files []string, types []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("Classify these changes and describe them for a commit message.\n\n")
+	sb.WriteString("Format: <type>: <description>\n")
+	sb.WriteString(fmt.Sprintf("Types: %s\n", strings.Join(types, ", ")))
+	sb.WriteString("The description is imperative (\"add\", not \"added\"), starts lowercase, has no trailing period, and is at most 60 characters.\n\n")
+
+	sb.WriteString("Files changed:\n")
+	for _, file := range files {
+		sb.WriteString(fmt.Sprintf("- %s\n", file))
+	}
+
+	sb.WriteString("\nDiff:\n")
+	sb.WriteString(truncateDiff(diff, 3000))
+
+	sb.WriteString("\n\nRespond with ONLY the one line <type>: <description>, nothing else.")
 
 	return sb.String()
 }
@@ -111,13 +159,13 @@ func truncateDiff(diff string, maxChars int) string {
 	if len(diff) <= maxChars {
 		return diff
 	}
-	return diff[:maxChars] + "\\n... (diff truncated)"
+	return diff[:maxChars] + "\n... (diff truncated)"
 }
 
 // GetModifiedFiles returns a list of modified files from git status
-func GetModifiedFiles(workingDir string) ([]string, error) {
+func GetModifiedFiles(ctx context.Context, workingDir string) ([]string, error) {
 	// Get all modified, new, and deleted files
-	cmd := exec.Command("git", "status", "--porcelain")
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
 	cmd.Dir = workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -159,13 +207,13 @@ func GetModifiedFiles(workingDir string) ([]string, error) {
 	return files, nil
 }
 
-func StageFiles(workingDir string, files []string) error {
+func StageFiles(ctx context.Context, workingDir string, files []string) error {
 	if len(files) == 0 {
 		return fmt.Errorf("no files to stage")
 	}
 
 	args := append([]string{"add"}, files...)
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = workingDir
 
 	var stderr bytes.Buffer
@@ -178,9 +226,14 @@ func StageFiles(workingDir string, files []string) error {
 	return nil
 }
 
-func CreateCommit(workingDir, message string) (string, error) {
-	cmd := exec.Command("git", "commit", "-m", message)
+// CreateCommit commits the staged changes with message, attributed as attr
+// describes, and returns the new commit's short hash
+func CreateCommit(ctx context.Context, workingDir, message string, attr Attribution) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "commit", "-m", attr.Message(ctx, workingDir, message))
 	cmd.Dir = workingDir
+	if env := attr.env(ctx, workingDir); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -190,7 +243,7 @@ func CreateCommit(workingDir, message string) (string, error) {
 		return "", fmt.Errorf("git commit failed: %w, stderr: %s", err, stderr.String())
 	}
 
-	hash, err := getLatestCommitHash(workingDir)
+	hash, err := getLatestCommitHash(ctx, workingDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to get commit hash: %w", err)
 	}
@@ -198,8 +251,8 @@ func CreateCommit(workingDir, message string) (string, error) {
 	return hash, nil
 }
 
-func getLatestCommitHash(workingDir string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+func getLatestCommitHash(ctx context.Context, workingDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--short", "HEAD")
 	cmd.Dir = workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -0,0 +1,222 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Commit message styles
+const (
+	StyleFreeform     = "freeform"     // One line written by the model
+	StyleConventional = "conventional" // Conventional Commits: type(scope): description
+	StyleTemplate     = "template"     // A custom template filled from the model's type and description
+)
+
+// DefaultIssuePattern finds Jira-style issue IDs such as PROJ-123 in branch names
+const DefaultIssuePattern = `[A-Z][A-Z0-9]+-[0-9]+`
+
+// conventionalTemplate is the template behind StyleConventional
+const conventionalTemplate = "{type}({scope}): {description}\n\nRefs: {issue}"
+
+// DefaultCommitTypes are the Conventional Commits types
+var DefaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// genericDirs are directory names too broad to be useful as a scope
+var genericDirs = map[string]bool{
+	"pkg": true, "cmd": true, "internal": true, "src": true, "lib": true, "app": true,
+}
+
+// typedSummaryPattern matches "type(scope)!: description" lines from the model
+var typedSummaryPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\([^)]*\))?!?:\s*(.+)$`)
+
+// MessageStyle describes how generated commit messages are formatted, so they
+// pass commit linting. In the conventional and template styles the model
+// only picks a type and writes a description; the scope is inferred from the
+// changed paths and the issue ID is taken from the branch name.
+//
+// Templates may use {type}, {scope}, {description}, {issue} and {branch}.
+// When the scope or issue is unknown, brackets around it are dropped, as are
+// lines whose placeholders are all empty: "{type}({scope}): {description}"
+// becomes "fix: ..." and a "Refs: {issue}" line disappears.
+type MessageStyle struct {
+	Style        string            // One of the Style* values; "" = StyleFreeform
+	Template     string            // Message template for StyleTemplate
+	Types        []string          // Allowed types; nil = DefaultCommitTypes
+	Scopes       map[string]string // Path prefix → scope; nil = the changed files' common directory
+	IssuePattern string            // Regexp for the issue ID in the branch name; "" = DefaultIssuePattern. The first group is used if it has one.
+}
+
+// structured reports whether the model writes only the type and description
+func (s MessageStyle) structured() bool {
+	return s.Style == StyleConventional || s.Style == StyleTemplate
+}
+
+// types returns the allowed commit types
+func (s MessageStyle) types() []string {
+	if len(s.Types) > 0 {
+		return s.Types
+	}
+	return DefaultCommitTypes
+}
+
+// Format builds a commit message from the model's type and description for
+// the given files and branch.
+func (s MessageStyle) Format(commitType, description string, files []string, branch string) string {
+	template := s.Template
+	if s.Style != StyleTemplate || template == "" {
+		template = conventionalTemplate
+	}
+
+	values := map[string]string{
+		"type":        commitType,
+		"scope":       InferScope(files, s.Scopes),
+		"description": description,
+		"issue":       ExtractIssueID(branch, s.IssuePattern),
+		"branch":      branch,
+	}
+
+	// Drop the brackets around unknown values
+	for name, value := range values {
+		if value == "" {
+			for _, brackets := range []string{"(%s)", "[%s]"} {
+				template = strings.ReplaceAll(template, fmt.Sprintf(brackets, "{"+name+"}"), "")
+			}
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(template, "\n") {
+		used, filled := 0, 0
+		for name, value := range values {
+			placeholder := "{" + name + "}"
+			if strings.Contains(line, placeholder) {
+				used++
+				if value != "" {
+					filled++
+				}
+				line = strings.ReplaceAll(line, placeholder, value)
+			}
+		}
+		switch {
+		case used == 0:
+			lines = append(lines, line)
+		case filled > 0:
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+
+	message := strings.Join(lines, "\n")
+	for strings.Contains(message, "\n\n\n") {
+		message = strings.ReplaceAll(message, "\n\n\n", "\n\n")
+	}
+	return strings.TrimSpace(message)
+}
+
+// parseTypedSummary splits a "type: description" line from the model. An
+// unknown or missing type becomes "chore". The description is made to pass
+// common lint rules: lowercase first letter and no trailing period.
+func (s MessageStyle) parseTypedSummary(line string) (string, string) {
+	line = strings.Trim(strings.TrimSpace(strings.SplitN(strings.TrimSpace(line), "\n", 2)[0]), "`\"'")
+
+	commitType, description := "chore", line
+	if match := typedSummaryPattern.FindStringSubmatch(line); match != nil {
+		description = match[2]
+		for _, allowed := range s.types() {
+			if strings.EqualFold(match[1], allowed) {
+				commitType = allowed
+				break
+			}
+		}
+	}
+
+	description = strings.TrimRight(strings.TrimSpace(description), ".")
+	if description != "" && !startsWithAcronym(description) {
+		description = strings.ToLower(description[:1]) + description[1:]
+	}
+	return commitType, description
+}
+
+// startsWithAcronym reports whether s starts with a word of two or more
+// capitals, such as "API" or "TUI", which keeps its case
+func startsWithAcronym(s string) bool {
+	words := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '-' || r == '/' })
+	if len(words) == 0 {
+		return false
+	}
+	word := words[0]
+	return len(word) > 1 && strings.ToUpper(word) == word && strings.ToLower(word) != word
+}
+
+// InferScope returns the commit scope for files. With scopes configured, it
+// is the scope of the longest matching path prefix, if all files share one.
+// Otherwise it is the name of the files' deepest common directory, unless
+// that is the repository root or a generic name such as pkg or src.
+func InferScope(files []string, scopes map[string]string) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	if len(scopes) > 0 {
+		scope := ""
+		for i, file := range files {
+			fileScope, longest := "", -1
+			for prefix, candidate := range scopes {
+				prefix = strings.TrimSuffix(prefix, "/")
+				if (file == prefix || strings.HasPrefix(file, prefix+"/")) && len(prefix) > longest {
+					fileScope, longest = candidate, len(prefix)
+				}
+			}
+			if fileScope == "" || (i > 0 && fileScope != scope) {
+				return ""
+			}
+			scope = fileScope
+		}
+		return scope
+	}
+
+	common := strings.Split(path.Dir(files[0]), "/")
+	for _, file := range files[1:] {
+		dir := strings.Split(path.Dir(file), "/")
+		n := 0
+		for n < len(common) && n < len(dir) && common[n] == dir[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if len(common) == 0 {
+		return ""
+	}
+	name := common[len(common)-1]
+	if name == "." || genericDirs[name] {
+		return ""
+	}
+	return name
+}
+
+// ExtractIssueID finds an issue ID in a branch name with pattern, or
+// DefaultIssuePattern when pattern is empty. If the pattern has a group, the
+// first group is the ID. Returns "" when there is none.
+func ExtractIssueID(branch, pattern string) string {
+	if branch == "" {
+		return ""
+	}
+	if pattern == "" {
+		pattern = DefaultIssuePattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ""
+	}
+
+	match := re.FindStringSubmatch(branch)
+	switch {
+	case match == nil:
+		return ""
+	case len(match) > 1:
+		return match[1]
+	default:
+		return match[0]
+	}
+}
@@ -0,0 +1,136 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestMessageStyle_Format(t *testing.T) {
+	tests := []struct {
+		name   string
+		style  MessageStyle
+		files  []string
+		branch string
+		want   string
+	}{
+		{
+			name:   "conventional with scope and issue",
+			style:  MessageStyle{Style: StyleConventional},
+			files:  []string{"pkg/agent/git/commit.go", "pkg/agent/git/pr.go"},
+			branch: "feature/FORGE-42-commit-styles",
+			want:   "feat(git): add commit styles\n\nRefs: FORGE-42",
+		},
+		{
+			name:   "conventional without scope or issue",
+			style:  MessageStyle{Style: StyleConventional},
+			files:  []string{"README.md", "pkg/agent/agent.go"},
+			branch: "main",
+			want:   "feat: add commit styles",
+		},
+		{
+			name: "template with issue prefix",
+			style: MessageStyle{
+				Style:        StyleTemplate,
+				Template:     "[{issue}] {type}({scope}): {description}",
+				Scopes:       map[string]string{"pkg/executor/tui/": "tui", "pkg/executor": "executor"},
+				IssuePattern: `^(\d+)-`,
+			},
+			files:  []string{"pkg/executor/tui/model.go", "pkg/executor/tui/update.go"},
+			branch: "123-styles",
+			want:   "[123] feat(tui): add commit styles",
+		},
+		{
+			name: "template without issue",
+			style: MessageStyle{
+				Style:    StyleTemplate,
+				Template: "[{issue}] {type}({scope}): {description}",
+				Scopes:   map[string]string{"pkg/executor/tui": "tui", "cmd": "cli"},
+			},
+			files:  []string{"pkg/executor/tui/model.go", "cmd/forge/main.go"},
+			branch: "styles",
+			want:   "feat: add commit styles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.style.Format("feat", "add commit styles", tt.files, tt.branch); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageStyle_ParseTypedSummary(t *testing.T) {
+	style := MessageStyle{Style: StyleConventional}
+	tests := []struct {
+		input, wantType, wantDescription string
+	}{
+		{"fix: Handle empty diffs.", "fix", "handle empty diffs"},
+		{"Feat(tui)!: add review overlay", "feat", "add review overlay"},
+		{"`docs: update README`", "docs", "update README"},
+		{"improve: API retries", "chore", "API retries"},
+		{"Bump dependencies", "chore", "bump dependencies"},
+	}
+
+	for _, tt := range tests {
+		gotType, gotDescription := style.parseTypedSummary(tt.input)
+		if gotType != tt.wantType || gotDescription != tt.wantDescription {
+			t.Errorf("parseTypedSummary(%q) = %q, %q; want %q, %q", tt.input, gotType, gotDescription, tt.wantType, tt.wantDescription)
+		}
+	}
+}
+
+func TestInferScope(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{[]string{"pkg/agent/git/commit.go"}, "git"},
+		{[]string{"pkg/agent/git/commit.go", "pkg/agent/review/review.go"}, "agent"},
+		{[]string{"pkg/a.go", "pkg/b.go"}, ""},
+		{[]string{"README.md"}, ""},
+		{[]string{"cmd/forge/main.go", "pkg/config/config.go"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := InferScope(tt.files, nil); got != tt.want {
+			t.Errorf("InferScope(%v) = %q, want %q", tt.files, got, tt.want)
+		}
+	}
+}
+
+// fixedLLM returns the same response to every prompt
+type fixedLLM struct {
+	response string
+	prompt   string
+}
+
+func (f *fixedLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	f.prompt = prompt
+	return f.response, nil
+}
+
+func TestCommitMessageGenerator_Conventional(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"pkg/parser/parse.go": "package parser\n"},
+		workspacetest.WithBranch("PARSE-7-fix"))
+	ws.Git("commit", "-q", "--allow-empty", "-m", "initial")
+	dir := ws.Dir
+
+	llm := &fixedLLM{response: "fix: Reject empty input."}
+	generator := NewCommitMessageGenerator(llm, WithMessageStyle(MessageStyle{Style: StyleConventional}))
+	message, err := generator.Generate(context.Background(), dir, []string{"pkg/parser/parse.go"})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if want := "fix(parser): reject empty input\n\nRefs: PARSE-7"; message != want {
+		t.Errorf("got %q, want %q", message, want)
+	}
+	if !strings.Contains(llm.prompt, "Types: feat, fix,") {
+		t.Errorf("prompt should list the allowed types:\n%s", llm.prompt)
+	}
+}
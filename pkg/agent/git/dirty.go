@@ -0,0 +1,58 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SnapshotRefPrefix is where saved snapshots are kept, one ref each
+const SnapshotRefPrefix = "refs/forge/snapshots/"
+
+// forgeDir holds Forge's own files, such as the workspace lock and audit
+// log, which are not the user's work
+const forgeDir = ".forge/"
+
+// UncommittedChanges returns the changes in workingDir that are not
+// committed, staged or not, leaving out Forge's own files under .forge. It
+// fails outside a git repository.
+func UncommittedChanges(ctx context.Context, workingDir string) ([]*FileModification, error) {
+	changes, err := worktreeChanges(ctx, workingDir)
+	if err != nil {
+		return nil, err
+	}
+	var work []*FileModification
+	for _, change := range changes {
+		if !strings.HasPrefix(change.Path, forgeDir) {
+			work = append(work, change)
+		}
+	}
+	return work, nil
+}
+
+// Stash stashes the uncommitted changes in workingDir, untracked files
+// included, under message. Forge's own files stay in place.
+func Stash(ctx context.Context, workingDir, message string) error {
+	_, err := runGit(ctx, workingDir, nil, "stash", "push", "--include-untracked", "-m", message, "--", ".", ":(exclude)"+forgeDir)
+	return err
+}
+
+// Save records the snapshot as a commit on top of HEAD under
+// SnapshotRefPrefix, so the workspace as it was can be diffed against and
+// restored after the snapshot itself is gone. It returns the ref.
+func (s *Snapshot) Save(ctx context.Context, message string) (string, error) {
+	args := []string{"commit-tree", s.tree, "-m", message}
+	if head, err := runGit(ctx, s.workingDir, nil, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		args = append(args, "-p", strings.TrimSpace(head))
+	}
+	commit, err := runGit(ctx, s.workingDir, nil, args...)
+	if err != nil {
+		return "", err
+	}
+
+	ref := SnapshotRefPrefix + s.takenAt.Format("20060102-150405")
+	if _, err := runGit(ctx, s.workingDir, nil, "update-ref", ref, strings.TrimSpace(commit)); err != nil {
+		return "", fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return ref, nil
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestUncommittedChanges(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"},
+		workspacetest.WithCommit("initial"),
+		workspacetest.WithChanges(workspacetest.Tree{
+			"main.go":          "package main // edited\n",
+			"notes.txt":        "todo\n",
+			".forge/lock":      "123\n",
+			".forge/audit.log": "{}\n",
+		}))
+	ctx := context.Background()
+
+	changes, err := UncommittedChanges(ctx, ws.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, len(changes))
+	for i, change := range changes {
+		paths[i] = change.Path
+	}
+	if strings.Join(paths, ",") != "main.go,notes.txt" {
+		t.Errorf("expected the user's changes without Forge's files, got %v", paths)
+	}
+
+	if err := Stash(ctx, ws.Dir, "before the session"); err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ := UncommittedChanges(ctx, ws.Dir); len(changes) != 0 {
+		t.Errorf("expected a clean workspace after stashing, got %d changes", len(changes))
+	}
+	ws.AssertExists(".forge/lock")
+	if list := ws.Git("stash", "list"); !strings.Contains(list, "before the session") {
+		t.Errorf("expected the stash to carry its message, got %q", list)
+	}
+}
+
+func TestSnapshotSave(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "v1\n"},
+		workspacetest.WithCommit("initial"),
+		workspacetest.WithChanges(workspacetest.Tree{"main.go": "v2\n", "new.txt": "x\n"}))
+	ctx := context.Background()
+
+	snapshot, err := TakeSnapshot(ctx, ws.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := snapshot.Save(ctx, "Workspace before the session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ref, SnapshotRefPrefix) {
+		t.Errorf("expected a snapshot ref, got %q", ref)
+	}
+
+	// Later edits leave the snapshot as it was
+	ws.WriteFile("main.go", "v3\n")
+	if content := ws.Git("show", ref+":main.go"); content != "v2" {
+		t.Errorf("expected the snapshot to hold the uncommitted content, got %q", content)
+	}
+	if parent := ws.Git("rev-parse", ref+"^"); parent != ws.Git("rev-parse", "HEAD") {
+		t.Error("expected the snapshot to sit on top of HEAD")
+	}
+	if content := ws.Git("show", ref+":new.txt"); content != "x" {
+		t.Errorf("expected the snapshot to hold untracked files, got %q", content)
+	}
+}
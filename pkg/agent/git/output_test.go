@@ -0,0 +1,31 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestOutput(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"})
+	ctx := context.Background()
+
+	hash, err := Output(ctx, ws.Dir, strings.NewReader("package main\n"), "hash-object", "--stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Output(ctx, ws.Dir, nil, "hash-object", "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(hash) == "" || hash != want {
+		t.Errorf("expected stdin to be hashed like main.go, got %q and %q", hash, want)
+	}
+
+	_, err = Output(ctx, ws.Dir, nil, "rev-parse", "--verify", "no-such-ref")
+	if err == nil || !strings.Contains(err.Error(), "git rev-parse failed") || !strings.Contains(err.Error(), "fatal") {
+		t.Errorf("expected the failure with git's stderr, got %v", err)
+	}
+}
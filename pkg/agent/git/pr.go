@@ -123,22 +123,22 @@ func parsePRContent(response string) *PRContent {
 	}
 }
 
-func DetectBaseBranch(workingDir string) (string, error) {
+func DetectBaseBranch(ctx context.Context, workingDir string) (string, error) {
 	baseBranches := []string{"main", "master", "develop"}
 
-	currentBranch, err := getCurrentBranch(workingDir)
+	currentBranch, err := getCurrentBranch(ctx, workingDir)
 	if err != nil {
 		return "", err
 	}
 
 	for _, base := range baseBranches {
-		cmd := exec.Command("git", "rev-parse", "--verify", base)
+		cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", base)
 		cmd.Dir = workingDir
 		if err := cmd.Run(); err != nil {
 			continue
 		}
 
-		cmd = exec.Command("git", "merge-base", base, currentBranch)
+		cmd = exec.CommandContext(ctx, "git", "merge-base", base, currentBranch)
 		cmd.Dir = workingDir
 		output, err := cmd.Output()
 		if err == nil && len(output) > 0 {
@@ -149,8 +149,8 @@ func DetectBaseBranch(workingDir string) (string, error) {
 	return "", fmt.Errorf("could not detect base branch")
 }
 
-func getCurrentBranch(workingDir string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+func getCurrentBranch(ctx context.Context, workingDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -164,8 +164,8 @@ func getCurrentBranch(workingDir string) (string, error) {
 	return strings.TrimSpace(stdout.String()), nil
 }
 
-func GetCommitsSinceBase(workingDir, base, head string) ([]CommitInfo, error) {
-	cmd := exec.Command("git", "log", "--format=%h|%s", fmt.Sprintf("%s..%s", base, head))
+func GetCommitsSinceBase(ctx context.Context, workingDir, base, head string) ([]CommitInfo, error) {
+	cmd := exec.CommandContext(ctx, "git", "log", "--format=%h|%s", fmt.Sprintf("%s..%s", base, head))
 	cmd.Dir = workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -195,8 +195,8 @@ func GetCommitsSinceBase(workingDir, base, head string) ([]CommitInfo, error) {
 	return commits, nil
 }
 
-func GetDiffSummary(workingDir, base, head string) (string, error) {
-	cmd := exec.Command("git", "diff", "--stat", fmt.Sprintf("%s...%s", base, head))
+func GetDiffSummary(ctx context.Context, workingDir, base, head string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--stat", fmt.Sprintf("%s...%s", base, head))
 	cmd.Dir = workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -209,7 +209,7 @@ func GetDiffSummary(workingDir, base, head string) (string, error) {
 
 	stats := stdout.String()
 
-	cmd = exec.Command("git", "diff", fmt.Sprintf("%s...%s", base, head))
+	cmd = exec.CommandContext(ctx, "git", "diff", fmt.Sprintf("%s...%s", base, head))
 	cmd.Dir = workingDir
 
 	stdout.Reset()
@@ -227,9 +227,9 @@ func GetDiffSummary(workingDir, base, head string) (string, error) {
 }
 
 // CreatePR pushes the current branch and creates a PR on GitHub using gh CLI
-func CreatePR(workingDir, title, body, base, head string) (string, error) {
+func CreatePR(ctx context.Context, workingDir, title, body, base, head string) (string, error) {
 	// First, push the current branch to remote
-	pushCmd := exec.Command("git", "push", "-u", "origin", head)
+	pushCmd := exec.CommandContext(ctx, "git", "push", "-u", "origin", head)
 	pushCmd.Dir = workingDir
 
 	var pushStdout, pushStderr bytes.Buffer
@@ -244,7 +244,7 @@ func CreatePR(workingDir, title, body, base, head string) (string, error) {
 	}
 
 	// Create PR using gh CLI
-	prCmd := exec.Command("gh", "pr", "create",
+	prCmd := exec.CommandContext(ctx, "gh", "pr", "create",
 		"--title", title,
 		"--body", body,
 		"--base", base,
@@ -0,0 +1,205 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Snapshot captures the complete workspace state at a point in time as a git
+// tree object. Tracked and untracked files are both included (respecting
+// .gitignore), and the user's index, stash and working tree are left untouched.
+//
+// Diffing against a snapshot reports every change since it was taken,
+// including files changed indirectly by commands or scripts that the
+// ModificationTracker never sees.
+type Snapshot struct {
+	workingDir string
+	tree       string
+	takenAt    time.Time
+}
+
+// FileChange describes a single file that differs from the snapshot.
+type FileChange struct {
+	Path   string `json:"path"`   // Path relative to the snapshot working directory
+	Status string `json:"status"` // "added", "modified", "deleted", "renamed", "type-changed"
+}
+
+// TakeSnapshot records the current state of the workspace.
+func TakeSnapshot(ctx context.Context, workingDir string) (*Snapshot, error) {
+	tree, err := writeWorkspaceTree(ctx, workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		workingDir: workingDir,
+		tree:       tree,
+		takenAt:    time.Now(),
+	}, nil
+}
+
+// TakenAt returns when the snapshot was recorded.
+func (s *Snapshot) TakenAt() time.Time {
+	return s.takenAt
+}
+
+// Diff returns a unified diff of the current workspace against the snapshot.
+// If paths are given, the diff is limited to them.
+func (s *Snapshot) Diff(ctx context.Context, paths ...string) (string, error) {
+	current, err := writeWorkspaceTree(ctx, s.workingDir)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--relative", s.tree, current}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+
+	return runGit(ctx, s.workingDir, nil, args...)
+}
+
+// DiffAndAdvance returns a unified diff of the current workspace against the
+// snapshot and then moves the snapshot forward to the current state, so the
+// next call only reports newer changes.
+func (s *Snapshot) DiffAndAdvance(ctx context.Context) (string, error) {
+	current, err := writeWorkspaceTree(ctx, s.workingDir)
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := runGit(ctx, s.workingDir, nil, "diff", "--no-color", "--no-ext-diff", "--relative", s.tree, current)
+	if err != nil {
+		return "", err
+	}
+
+	s.tree = current
+	s.takenAt = time.Now()
+	return diff, nil
+}
+
+// ChangedFiles returns the files that differ from the snapshot.
+func (s *Snapshot) ChangedFiles(ctx context.Context) ([]FileChange, error) {
+	current, err := writeWorkspaceTree(ctx, s.workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := runGit(ctx, s.workingDir, nil, "diff", "--name-status", "--no-renames", "--relative", s.tree, current)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		changes = append(changes, FileChange{
+			Path:   parts[1],
+			Status: describeStatus(parts[0]),
+		})
+	}
+
+	return changes, nil
+}
+
+// describeStatus maps a git --name-status code to a readable status.
+func describeStatus(code string) string {
+	switch code[0] {
+	case 'A':
+		return "added"
+	case 'D':
+		return "deleted"
+	case 'R':
+		return "renamed"
+	case 'T':
+		return "type-changed"
+	default:
+		return "modified"
+	}
+}
+
+// writeWorkspaceTree writes the full working tree into a git tree object using
+// a throwaway index file, and returns the tree hash.
+func writeWorkspaceTree(ctx context.Context, workingDir string) (string, error) {
+	tmp, err := os.CreateTemp("", "forge-snapshot-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	indexPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(indexPath)
+
+	// Seed the temporary index from the real one so git can reuse cached stat
+	// information; an empty file is not a valid index, so remove it otherwise.
+	if err := seedIndex(ctx, workingDir, indexPath); err != nil {
+		os.Remove(indexPath)
+	}
+
+	// The audit log changes with every tool call; it is not a workspace change
+	env := append(os.Environ(), "GIT_INDEX_FILE="+indexPath)
+	if _, err := runGit(ctx, workingDir, env, "add", "-A", "--", ".", ":(exclude).forge/audit.log"); err != nil {
+		return "", err
+	}
+
+	tree, err := runGit(ctx, workingDir, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(tree), nil
+}
+
+// seedIndex copies the repository's index to dst.
+func seedIndex(ctx context.Context, workingDir, dst string) error {
+	indexPath, err := runGit(ctx, workingDir, nil, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return err
+	}
+	indexPath = strings.TrimSpace(indexPath)
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(workingDir, indexPath)
+	}
+
+	src, err := os.Open(indexPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
+// runGit runs a git command in workingDir and returns its stdout. The
+// command is killed if ctx is canceled.
+func runGit(ctx context.Context, workingDir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = workingDir
+	cmd.Env = env
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w, stderr: %s", args[0], err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
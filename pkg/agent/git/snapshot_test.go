@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestSnapshot_DetectsIndirectChanges(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"tracked.txt":   "original\n",
+		"untracked.txt": "scratch\n",
+		".gitignore":    "ignored.log\n",
+	}, workspacetest.WithGit())
+	dir := ws.Dir
+
+	snapshot, err := TakeSnapshot(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("TakeSnapshot failed: %v", err)
+	}
+
+	// Simulate changes made behind the agent's back
+	ws.Write(workspacetest.Tree{
+		"tracked.txt":   "modified\n",
+		"generated.txt": "new\n",
+		"ignored.log":   "noise\n",
+	})
+	ws.Remove("untracked.txt")
+
+	changes, err := snapshot.ChangedFiles(context.Background())
+	if err != nil {
+		t.Fatalf("ChangedFiles failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, c := range changes {
+		got[c.Path] = c.Status
+	}
+	want := map[string]string{
+		"tracked.txt":   "modified",
+		"generated.txt": "added",
+		"untracked.txt": "deleted",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d changes, got %v", len(want), got)
+	}
+	for path, status := range want {
+		if got[path] != status {
+			t.Errorf("%s: expected %q, got %q", path, status, got[path])
+		}
+	}
+
+	diff, err := snapshot.Diff(context.Background(), "tracked.txt")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if !strings.Contains(diff, "-original") || !strings.Contains(diff, "+modified") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+
+	// The user's index must be untouched
+	for path, status := range ws.Status() {
+		if status[0] == 'A' {
+			t.Errorf("snapshot staged %s in the real index", path)
+		}
+	}
+}
+
+func TestGitHelpers_Canceled(t *testing.T) {
+	dir := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"}, workspacetest.WithGit()).Dir
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := TakeSnapshot(ctx, dir); err == nil {
+		t.Error("TakeSnapshot succeeded with a canceled context")
+	}
+	if _, err := GetModifiedFiles(ctx, dir); err == nil {
+		t.Error("GetModifiedFiles succeeded with a canceled context")
+	}
+	if err := StageFiles(ctx, dir, []string{"main.go"}); err == nil {
+		t.Error("StageFiles succeeded with a canceled context")
+	}
+	if err := NewModificationTracker(dir).Refresh(ctx); err == nil {
+		t.Error("Refresh succeeded with a canceled context")
+	}
+}
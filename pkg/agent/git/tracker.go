@@ -3,40 +3,117 @@
 package git
 
 import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Modification operations. Write and diff are recorded by the agent's file
+// tools; the others are found in git status by Refresh.
+const (
+	OpWrite  = "write"  // File written by the agent
+	OpDiff   = "diff"   // Edits applied by the agent
+	OpCreate = "create" // New file
+	OpModify = "modify" // Content changed
+	OpDelete = "delete" // File removed
+	OpRename = "rename" // File moved; OldPath is where it was
+	OpMode   = "mode"   // Only the file mode changed (e.g. made executable)
+)
+
 // FileModification represents a file that was modified during the session.
 type FileModification struct {
-	Path      string    // Relative path from workspace root
-	Operation string    // "write", "diff", "delete"
-	Timestamp time.Time // When the modification occurred
+	Path      string    `json:"path"`               // Relative path from workspace root
+	OldPath   string    `json:"old_path,omitempty"` // Renames: the previous path
+	Operation string    `json:"operation"`          // One of the Op* operations
+	OldMode   string    `json:"old_mode,omitempty"` // Set when the file mode changed, e.g. "100644"
+	NewMode   string    `json:"new_mode,omitempty"` // e.g. "100755"
+	ByAgent   bool      `json:"by_agent"`           // Changed by one of the agent's file tools
+	Staged    bool      `json:"staged,omitempty"`   // The change is in the git index
+	Timestamp time.Time `json:"timestamp"`          // When the modification was first seen
 }
 
 // ModificationTracker tracks file modifications made during an agent session.
+// Changes made by the agent's file tools are recorded as they happen; Refresh
+// adds everything else git sees in the worktree, including deletions,
+// renames and mode changes made by commands.
 type ModificationTracker struct {
 	mu            sync.RWMutex
+	workingDir    string
 	modifications map[string]*FileModification
 }
 
-// NewModificationTracker creates a new file modification tracker.
-func NewModificationTracker() *ModificationTracker {
+// NewModificationTracker creates a new file modification tracker for the
+// workspace at workingDir.
+func NewModificationTracker(workingDir string) *ModificationTracker {
 	return &ModificationTracker{
+		workingDir:    workingDir,
 		modifications: make(map[string]*FileModification),
 	}
 }
 
-// Track records a file modification.
+// Track records a file modification made by the agent.
 func (t *ModificationTracker) Track(path, operation string) {
+	path = t.relative(path)
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	t.modifications[path] = &FileModification{
+	mod := &FileModification{
 		Path:      path,
 		Operation: operation,
+		ByAgent:   true,
 		Timestamp: time.Now(),
 	}
+	if existing, ok := t.modifications[path]; ok {
+		mod.Timestamp = existing.Timestamp
+	}
+	t.modifications[path] = mod
+}
+
+// relative converts a tool path to a clean path relative to the workspace
+func (t *ModificationTracker) relative(path string) string {
+	if filepath.IsAbs(path) && t.workingDir != "" {
+		if abs, err := filepath.Abs(t.workingDir); err == nil {
+			if rel, err := filepath.Rel(abs, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// Refresh brings the tracker up to date with git status. Files the agent
+// changed keep their ByAgent mark; changes git no longer reports (reverted or
+// committed) are dropped unless the agent made them.
+func (t *ModificationTracker) Refresh(ctx context.Context) error {
+	changes, err := worktreeChanges(ctx, t.workingDir)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	refreshed := make(map[string]*FileModification, len(changes))
+	for _, change := range changes {
+		change.Timestamp = now
+		if existing, ok := t.modifications[change.Path]; ok {
+			change.Timestamp = existing.Timestamp
+			change.ByAgent = existing.ByAgent
+		}
+		refreshed[change.Path] = change
+	}
+	for path, existing := range t.modifications {
+		if _, ok := refreshed[path]; !ok && existing.ByAgent {
+			refreshed[path] = existing
+		}
+	}
+	t.modifications = refreshed
+	return nil
 }
 
 // GetModified returns all modified file paths.
@@ -48,18 +125,23 @@ func (t *ModificationTracker) GetModified() []string {
 	for path := range t.modifications {
 		paths = append(paths, path)
 	}
+	sort.Strings(paths)
 	return paths
 }
 
-// GetModifications returns all file modifications with their metadata.
+// GetModifications returns all file modifications with their metadata, sorted by path.
 func (t *ModificationTracker) GetModifications() []*FileModification {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
 	mods := make([]*FileModification, 0, len(t.modifications))
 	for _, mod := range t.modifications {
-		mods = append(mods, mod)
+		copied := *mod
+		mods = append(mods, &copied)
 	}
+	sort.Slice(mods, func(i, j int) bool {
+		return mods[i].Path < mods[j].Path
+	})
 	return mods
 }
 
@@ -78,3 +160,157 @@ func (t *ModificationTracker) Count() int {
 
 	return len(t.modifications)
 }
+
+// worktreeChanges reads the changes beneath workingDir from git status, with
+// paths relative to workingDir. Unstaged renames, which git reports as a
+// deletion plus an untracked file, are paired up by content.
+func worktreeChanges(ctx context.Context, workingDir string) ([]*FileModification, error) {
+	prefix, err := runGit(ctx, workingDir, nil, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	output, err := runGit(ctx, workingDir, nil, "status", "--porcelain=v2", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*FileModification
+	var untracked []*FileModification
+	deleted := make(map[string]*FileModification)     // Blob hash in HEAD → worktree deletion
+	modeChanged := make(map[*FileModification]string) // Unstaged mode change → blob hash in HEAD
+
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		record := fields[i]
+		if len(record) < 2 {
+			continue
+		}
+
+		switch record[0] {
+		case '?':
+			mod := &FileModification{Path: strings.TrimPrefix(record[2:], prefix), Operation: OpCreate}
+			untracked = append(untracked, mod)
+			changes = append(changes, mod)
+
+		case '1':
+			// 1 XY sub mH mI mW hH hI path
+			parts := strings.SplitN(record, " ", 9)
+			if len(parts) < 9 {
+				continue
+			}
+			xy, hashHead, hashIndex := parts[1], parts[6], parts[7]
+			mod := statusChange(xy, parts[3], parts[4], parts[5], strings.TrimPrefix(parts[8], prefix))
+			switch {
+			case mod.Operation == OpDelete && xy[0] == '.':
+				deleted[hashHead] = mod
+			case mod.OldMode != "" && xy == "M." && hashHead == hashIndex:
+				mod.Operation = OpMode
+			case mod.OldMode != "" && xy == ".M":
+				modeChanged[mod] = hashHead
+			}
+			changes = append(changes, mod)
+
+		case '2':
+			// 2 XY sub mH mI mW hH hI Xscore path, followed by the original path
+			parts := strings.SplitN(record, " ", 10)
+			if len(parts) < 10 || i+1 >= len(fields) {
+				continue
+			}
+			i++
+			mod := statusChange(parts[1], parts[3], parts[4], parts[5], strings.TrimPrefix(parts[9], prefix))
+			mod.Operation = OpRename
+			mod.OldPath = strings.TrimPrefix(fields[i], prefix)
+			changes = append(changes, mod)
+
+		case 'u':
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			parts := strings.SplitN(record, " ", 11)
+			if len(parts) < 11 {
+				continue
+			}
+			changes = append(changes, &FileModification{Path: strings.TrimPrefix(parts[10], prefix), Operation: OpModify})
+		}
+	}
+
+	return resolveByContent(ctx, workingDir, changes, untracked, deleted, modeChanged), nil
+}
+
+// statusChange describes a changed tracked file from its porcelain v2 status
+// code and its modes in HEAD, the index and the worktree
+func statusChange(xy, modeHead, modeIndex, modeWorktree, path string) *FileModification {
+	mod := &FileModification{Path: path, Operation: OpModify, Staged: xy[0] != '.'}
+
+	switch {
+	case xy[0] == 'D' || xy[1] == 'D':
+		mod.Operation = OpDelete
+		return mod
+	case xy[0] == 'A':
+		mod.Operation = OpCreate
+		return mod
+	}
+
+	finalMode := modeWorktree
+	if xy[1] == '.' {
+		finalMode = modeIndex
+	}
+	if modeHead != finalMode {
+		mod.OldMode = modeHead
+		mod.NewMode = finalMode
+	}
+	return mod
+}
+
+// resolveByContent hashes worktree files to refine what git status reports:
+// a deletion whose content reappears in an untracked file becomes a rename,
+// and a mode change whose content matches HEAD becomes a pure mode change.
+func resolveByContent(ctx context.Context, workingDir string, changes, untracked []*FileModification, deleted map[string]*FileModification, modeChanged map[*FileModification]string) []*FileModification {
+	var candidates []*FileModification
+	if len(deleted) > 0 {
+		candidates = append(candidates, untracked...)
+	}
+	for mod := range modeChanged {
+		candidates = append(candidates, mod)
+	}
+	if len(candidates) == 0 {
+		return changes
+	}
+
+	args := []string{"hash-object", "--"}
+	for _, mod := range candidates {
+		args = append(args, mod.Path)
+	}
+	output, err := runGit(ctx, workingDir, nil, args...)
+	if err != nil {
+		return changes
+	}
+	hashes := strings.Fields(output)
+	if len(hashes) != len(candidates) {
+		return changes
+	}
+
+	removed := make(map[*FileModification]bool)
+	for i, mod := range candidates {
+		if headHash, ok := modeChanged[mod]; ok {
+			if hashes[i] == headHash {
+				mod.Operation = OpMode
+			}
+			continue
+		}
+		if source, ok := deleted[hashes[i]]; ok {
+			delete(deleted, hashes[i])
+			removed[source] = true
+			mod.Operation = OpRename
+			mod.OldPath = source.Path
+		}
+	}
+
+	kept := changes[:0]
+	for _, mod := range changes {
+		if !removed[mod] {
+			kept = append(kept, mod)
+		}
+	}
+	return kept
+}
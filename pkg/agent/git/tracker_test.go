@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestModificationTracker_Refresh(t *testing.T) {
+	tree := workspacetest.Tree{"pkg/": ""}
+	for _, name := range []string{"edited.go", "script.sh", "moved.go", "staged.go", "removed.go", "untouched.go"} {
+		tree[name] = "content of " + name + "\n"
+	}
+	ws := workspacetest.New(t, tree, workspacetest.WithCommit("initial"),
+		workspacetest.WithChanges(workspacetest.Tree{"edited.go": "changed\n", "new.go": "package main\n"}),
+		workspacetest.WithRemoved("removed.go"))
+	dir := ws.Dir
+
+	if err := os.Chmod(ws.Path("script.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(ws.Path("moved.go"), ws.Path("pkg/moved.go")); err != nil {
+		t.Fatal(err)
+	}
+	ws.Git("mv", "staged.go", "renamed.go")
+
+	tracker := NewModificationTracker(dir)
+	tracker.Track(filepath.Join(dir, "edited.go"), OpWrite)
+	if err := tracker.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	got := make(map[string]*FileModification)
+	for _, mod := range tracker.GetModifications() {
+		got[mod.Path] = mod
+	}
+
+	expect := func(path, operation, oldPath string) *FileModification {
+		t.Helper()
+		mod, ok := got[path]
+		if !ok {
+			t.Fatalf("expected %s to be tracked, got %v", path, tracker.GetModified())
+		}
+		if mod.Operation != operation || mod.OldPath != oldPath {
+			t.Errorf("%s: expected %s from %q, got %s from %q", path, operation, oldPath, mod.Operation, mod.OldPath)
+		}
+		return mod
+	}
+
+	if edited := expect("edited.go", OpModify, ""); !edited.ByAgent {
+		t.Error("expected edited.go to keep its by-agent mark")
+	}
+	if script := expect("script.sh", OpMode, ""); script.OldMode != "100644" || script.NewMode != "100755" {
+		t.Errorf("expected a 100644 → 100755 mode change, got %s → %s", script.OldMode, script.NewMode)
+	}
+	expect("pkg/moved.go", OpRename, "moved.go")
+	if staged := expect("renamed.go", OpRename, "staged.go"); !staged.Staged {
+		t.Error("expected the git mv rename to be staged")
+	}
+	expect("removed.go", OpDelete, "")
+	if newFile := expect("new.go", OpCreate, ""); newFile.ByAgent {
+		t.Error("expected new.go not to be attributed to the agent")
+	}
+	if _, ok := got["moved.go"]; ok {
+		t.Error("expected the unstaged rename's deletion to be folded into the rename")
+	}
+	if len(got) != 6 {
+		t.Errorf("expected 6 changes, got %v", tracker.GetModified())
+	}
+}
+
+func TestModificationTracker_RefreshInSubdirectory(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"root.go":         "package main\n",
+		"service/main.go": "package main\n",
+	}, workspacetest.WithGit())
+	sub := ws.Path("service")
+
+	tracker := NewModificationTracker(sub)
+	if err := tracker.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if paths := tracker.GetModified(); len(paths) != 1 || paths[0] != "main.go" {
+		t.Errorf("expected only the workspace's file, relative to it, got %v", paths)
+	}
+}
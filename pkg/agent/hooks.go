@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/hooks"
+	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// WithHooks runs user-defined commands on lifecycle events: session start and
+// end, before and after each turn, and before and after each tool call
+func WithHooks(runner *hooks.Runner) AgentOption {
+	return func(a *DefaultAgent) {
+		a.hooks = runner
+	}
+}
+
+// runHooks runs the hooks for payload's event and reports hook failures as
+// error events. Failures never stop the agent; only a blocking pre_tool hook
+// changes what happens next.
+func (a *DefaultAgent) runHooks(ctx context.Context, payload hooks.Payload) hooks.Decision {
+	if !a.hooks.Has(payload.Event) {
+		return hooks.Decision{}
+	}
+
+	decision, err := a.hooks.Run(ctx, payload)
+	if err != nil {
+		a.emitEvent(types.NewErrorEvent(err))
+	}
+	return decision
+}
+
+// runPreToolHooks lets pre_tool hooks deny a tool call before it is approved or run
+// Returns (shouldExecute, errorContext)
+func (a *DefaultAgent) runPreToolHooks(ctx context.Context, toolCall tools.ToolCall) (bool, string) {
+	decision := a.runHooks(ctx, hooks.Payload{
+		Event:     hooks.EventPreTool,
+		Tool:      toolCall.ToolName,
+		Arguments: toolArguments(toolCall),
+	})
+	if !decision.Blocked {
+		return true, ""
+	}
+
+	a.emitEvent(types.NewErrorEvent(fmt.Errorf("%s blocked by hook %q: %s", toolCall.ToolName, decision.Hook, decision.Reason)))
+	a.memory.Add(types.NewUserMessage(fmt.Sprintf("Tool '%s' was blocked by a pre_tool hook: %s", toolCall.ToolName, decision.Reason)))
+	return false, prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+		Type:     prompts.ErrorTypeToolBlocked,
+		ToolName: toolCall.ToolName,
+		Feedback: decision.Reason,
+	})
+}
+
+// runPostToolHooks reports a finished tool call to post_tool hooks
+func (a *DefaultAgent) runPostToolHooks(ctx context.Context, toolCall tools.ToolCall, result string, toolErr error) {
+	payload := hooks.Payload{
+		Event:     hooks.EventPostTool,
+		Tool:      toolCall.ToolName,
+		Arguments: toolArguments(toolCall),
+		Result:    result,
+	}
+	if toolErr != nil {
+		payload.Error = toolErr.Error()
+	}
+	a.runHooks(ctx, payload)
+}
+
+// toolArguments decodes a tool call's arguments for events and hooks
+func toolArguments(toolCall tools.ToolCall) map[string]interface{} {
+	args, err := tools.ArgumentsMap(toolCall.GetArgumentsXML())
+	if err != nil {
+		return make(map[string]interface{})
+	}
+	return args
+}
@@ -0,0 +1,181 @@
+// Package hooks runs user-defined commands on agent lifecycle events. Each
+// hook receives the event as a JSON payload on stdin. A pre_tool hook that
+// exits with BlockExitCode denies the tool call, with its output as the
+// reason, which lets teams add guardrails and integrations without forking.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Event is a point in the agent lifecycle at which hooks run
+type Event string
+
+const (
+	EventSessionStart Event = "session_start" // Before the first turn
+	EventPreTurn      Event = "pre_turn"      // A user message is about to be processed
+	EventPreTool      Event = "pre_tool"      // A tool is about to run, before approval
+	EventPostTool     Event = "post_tool"     // A tool finished, successfully or not
+	EventPostTurn     Event = "post_turn"     // The agent finished responding
+	EventSessionEnd   Event = "session_end"   // The agent is shutting down
+)
+
+const (
+	// BlockExitCode is the exit code with which a pre_tool hook denies the tool call
+	BlockExitCode = 2
+
+	// DefaultTimeout bounds hooks configured without their own timeout
+	DefaultTimeout = 60 * time.Second
+
+	// maxReasonLength caps how much hook output is reported as a denial reason
+	maxReasonLength = 2000
+)
+
+// Hook is a command run on an event
+type Hook struct {
+	Event   Event
+	Command string        // Run with sh -c in the workspace directory
+	Tools   []string      // Tool events only: tools the hook applies to (empty = all)
+	Timeout time.Duration // 0 = DefaultTimeout
+}
+
+// appliesTo reports whether the hook runs for a call of tool
+func (h Hook) appliesTo(tool string) bool {
+	if len(h.Tools) == 0 || tool == "" {
+		return true
+	}
+	for _, name := range h.Tools {
+		if name == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON document written to a hook's stdin
+type Payload struct {
+	Event     Event                  `json:"event"`
+	Workspace string                 `json:"workspace"`
+	Input     string                 `json:"input,omitempty"`     // pre_turn: the user's message
+	Tool      string                 `json:"tool,omitempty"`      // Tool events: the tool name
+	Arguments map[string]interface{} `json:"arguments,omitempty"` // Tool events: the call's arguments
+	Result    string                 `json:"result,omitempty"`    // post_tool: the tool's output
+	Error     string                 `json:"error,omitempty"`     // post_tool: why the tool failed
+}
+
+// Decision is the outcome of running an event's hooks
+type Decision struct {
+	Blocked bool   // A pre_tool hook denied the tool call
+	Hook    string // Command of the blocking hook
+	Reason  string // Output of the blocking hook
+}
+
+// Runner runs the configured hooks for each event
+type Runner struct {
+	workDir string
+	hooks   map[Event][]Hook
+}
+
+// NewRunner creates a runner that runs hooks in workDir
+func NewRunner(workDir string, hooks []Hook) *Runner {
+	r := &Runner{
+		workDir: workDir,
+		hooks:   make(map[Event][]Hook),
+	}
+	for _, hook := range hooks {
+		r.hooks[hook.Event] = append(r.hooks[hook.Event], hook)
+	}
+	return r
+}
+
+// Has reports whether any hook is configured for event
+func (r *Runner) Has(event Event) bool {
+	return r != nil && len(r.hooks[event]) > 0
+}
+
+// Run runs the hooks for payload.Event in configuration order, stopping at
+// the first pre_tool hook that blocks. Hooks that fail in any other way
+// (non-zero exit, timeout, missing shell) don't stop the others; their
+// failures are returned together as the error.
+func (r *Runner) Run(ctx context.Context, payload Payload) (Decision, error) {
+	if !r.Has(payload.Event) {
+		return Decision{}, nil
+	}
+	if payload.Workspace == "" {
+		payload.Workspace = r.workDir
+	}
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+
+	var errs []error
+	for _, hook := range r.hooks[payload.Event] {
+		if !hook.appliesTo(payload.Tool) {
+			continue
+		}
+
+		output, exitCode, err := r.exec(ctx, hook, payload.Event, input)
+		if exitCode == BlockExitCode && payload.Event == EventPreTool {
+			return Decision{Blocked: true, Hook: hook.Command, Reason: truncate(output)}, errors.Join(errs...)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s hook %q failed: %w", payload.Event, hook.Command, err))
+		}
+	}
+	return Decision{}, errors.Join(errs...)
+}
+
+// exec runs one hook with input on stdin and returns its combined output and exit code
+func (r *Runner) exec(ctx context.Context, hook Hook, event Event, input []byte) (string, int, error) {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(execCtx, "sh", "-c", hook.Command)
+	cmd.Dir = r.workDir
+	cmd.Env = append(os.Environ(), "FORGE_HOOK_EVENT="+string(event))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second // Don't wait on children of a killed shell that still hold the output
+
+	err := cmd.Run()
+	text := strings.TrimSpace(output.String())
+	if execCtx.Err() == context.DeadlineExceeded {
+		return text, -1, fmt.Errorf("timed out after %v", timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if text != "" {
+			return text, exitErr.ExitCode(), fmt.Errorf("exit code %d: %s", exitErr.ExitCode(), truncate(text))
+		}
+		return text, exitErr.ExitCode(), fmt.Errorf("exit code %d", exitErr.ExitCode())
+	}
+	if err != nil {
+		return text, -1, err
+	}
+	return text, 0, nil
+}
+
+// truncate shortens hook output to maxReasonLength
+func truncate(s string) string {
+	if len(s) <= maxReasonLength {
+		return s
+	}
+	return s[:maxReasonLength] + "... (truncated)"
+}
@@ -0,0 +1,128 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun_WritesPayloadToStdin(t *testing.T) {
+	dir := t.TempDir()
+	runner := NewRunner(dir, []Hook{{Event: EventPreTurn, Command: `cat > payload.json; echo "$FORGE_HOOK_EVENT" > event.txt`}})
+
+	decision, err := runner.Run(context.Background(), Payload{Event: EventPreTurn, Input: "fix the tests"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if decision.Blocked {
+		t.Error("expected pre_turn hooks not to block")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "payload.json"))
+	if err != nil {
+		t.Fatalf("hook did not run in the workspace: %v", err)
+	}
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("invalid payload %q: %v", data, err)
+	}
+	if payload.Event != EventPreTurn || payload.Input != "fix the tests" || payload.Workspace != dir {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+
+	event, _ := os.ReadFile(filepath.Join(dir, "event.txt"))
+	if strings.TrimSpace(string(event)) != "pre_turn" {
+		t.Errorf("expected FORGE_HOOK_EVENT=pre_turn, got %q", event)
+	}
+}
+
+func TestRun_PreToolBlocksWithExitCode2(t *testing.T) {
+	dir := t.TempDir()
+	runner := NewRunner(dir, []Hook{
+		{Event: EventPreTool, Command: `echo "no force pushes" >&2; exit 2`},
+		{Event: EventPreTool, Command: `touch second-ran`},
+	})
+
+	decision, err := runner.Run(context.Background(), Payload{Event: EventPreTool, Tool: "execute_command"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !decision.Blocked || decision.Reason != "no force pushes" {
+		t.Errorf("expected a block with the hook's output, got %+v", decision)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "second-ran")); err == nil {
+		t.Error("expected hooks after a blocking hook not to run")
+	}
+}
+
+func TestRun_ExitCode2OnlyBlocksPreTool(t *testing.T) {
+	runner := NewRunner(t.TempDir(), []Hook{{Event: EventPostTool, Command: "exit 2"}})
+
+	decision, err := runner.Run(context.Background(), Payload{Event: EventPostTool, Tool: "read_file"})
+	if decision.Blocked {
+		t.Error("expected post_tool hooks not to block")
+	}
+	if err == nil || !strings.Contains(err.Error(), "exit code 2") {
+		t.Errorf("expected the exit code to be reported as a failure, got %v", err)
+	}
+}
+
+func TestRun_FailuresDoNotStopOtherHooks(t *testing.T) {
+	dir := t.TempDir()
+	runner := NewRunner(dir, []Hook{
+		{Event: EventPostTurn, Command: "echo broken; exit 1"},
+		{Event: EventPostTurn, Command: "touch ran"},
+	})
+
+	_, err := runner.Run(context.Background(), Payload{Event: EventPostTurn})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the failure with its output, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "ran")); statErr != nil {
+		t.Error("expected the second hook to run after the first failed")
+	}
+}
+
+func TestRun_FiltersByTool(t *testing.T) {
+	runner := NewRunner(t.TempDir(), []Hook{{Event: EventPreTool, Command: "exit 2", Tools: []string{"execute_command"}}})
+
+	decision, _ := runner.Run(context.Background(), Payload{Event: EventPreTool, Tool: "read_file"})
+	if decision.Blocked {
+		t.Error("expected the hook to skip tools it is not configured for")
+	}
+	decision, _ = runner.Run(context.Background(), Payload{Event: EventPreTool, Tool: "execute_command"})
+	if !decision.Blocked {
+		t.Error("expected the hook to run for its configured tool")
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	runner := NewRunner(t.TempDir(), []Hook{{Event: EventPreTool, Command: "sleep 5", Timeout: 50 * time.Millisecond}})
+
+	start := time.Now()
+	decision, err := runner.Run(context.Background(), Payload{Event: EventPreTool, Tool: "read_file"})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+	if decision.Blocked {
+		t.Error("expected a timed out hook not to block")
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Error("expected the hook to be killed at its timeout")
+	}
+}
+
+func TestHas(t *testing.T) {
+	var nilRunner *Runner
+	if nilRunner.Has(EventPreTool) {
+		t.Error("expected a nil runner to have no hooks")
+	}
+	runner := NewRunner(t.TempDir(), []Hook{{Event: EventSessionStart, Command: "true"}})
+	if !runner.Has(EventSessionStart) || runner.Has(EventSessionEnd) {
+		t.Error("unexpected Has result")
+	}
+}
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/hooks"
+)
+
+func TestExecuteTool_PreToolHookBlocks(t *testing.T) {
+	dir := t.TempDir()
+	runner := hooks.NewRunner(dir, []hooks.Hook{
+		{Event: hooks.EventPreTool, Command: `echo "use make test instead" >&2; exit 2`, Tools: []string{"execute_command"}},
+		{Event: hooks.EventPostTool, Command: "cat > post.json"},
+	})
+	a, _ := newRunnerTestAgent(WithHooks(runner))
+	a.tools["execute_command"] = &summaryTestTool{name: "execute_command"}
+	a.tools["read_file"] = &summaryTestTool{name: "read_file"}
+
+	shouldContinue, errCtx := a.executeTool(context.Background(), toolCallWithArgs("execute_command", "<command>go test ./...</command>"))
+	if !shouldContinue {
+		t.Fatal("expected the loop to continue after a blocked call")
+	}
+	if !strings.Contains(errCtx, "blocked by a project hook") || !strings.Contains(errCtx, "use make test instead") {
+		t.Errorf("expected the block reason in the error context, got %q", errCtx)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post.json")); err == nil {
+		t.Error("expected post_tool hooks not to run for a blocked call")
+	}
+
+	// Other tools still run and are reported to post_tool hooks
+	if shouldContinue, errCtx := a.executeTool(context.Background(), toolCallWithArgs("read_file", "<path>main.go</path>")); !shouldContinue || errCtx != "" {
+		t.Fatalf("expected read_file to run, got %v %q", shouldContinue, errCtx)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "post.json"))
+	if err != nil {
+		t.Fatalf("expected the post_tool hook to run: %v", err)
+	}
+	for _, want := range []string{`"event":"post_tool"`, `"tool":"read_file"`, `"path":"main.go"`, `"result":"ok"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected post_tool payload to contain %s, got %s", want, data)
+		}
+	}
+}
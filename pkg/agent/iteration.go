@@ -3,10 +3,11 @@ package agent
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/entrhq/forge/pkg/agent/core"
-	"github.com/entrhq/forge/pkg/agent/memory"
 	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -20,27 +21,29 @@ type promptContext struct {
 // llmResponse holds the response from the LLM
 type llmResponse struct {
 	assistantContent string
-	toolCallContent  string
+	toolCalls        []string // Content of each tool call, without its <tool> tags
 	completionTokens int
+	usage            *llm.UsageInfo // Usage reported by the provider, if any
 }
 
-// attemptSummarization tries to summarize the conversation if context manager is available
-// Returns true if summarization occurred, false otherwise
+// attemptSummarization tries to summarize the conversation if context manager is available.
+// While the prompt still fits in the context window, summarization runs in the
+// background and the prompt is sent as it is, so the response is never delayed.
+// Only a prompt that doesn't fit waits for it.
+// Returns true if summarization occurred before the prompt was sent, false otherwise
 func (a *DefaultAgent) attemptSummarization(ctx context.Context, promptTokens int) bool {
-	// Early return if no context manager
 	if a.contextManager == nil {
 		return false
 	}
 
-	// Check if memory is the right type
-	convMem, ok := a.memory.(*memory.ConversationMemory)
-	if !ok {
-		agentDebugLog.Printf("Memory is NOT ConversationMemory - type: %T", a.memory)
+	// The background run outlives this turn; Run skips it on exit
+	if promptTokens < a.contextManager.GetMaxTokens() {
+		a.contextManager.Start(context.WithoutCancel(ctx), a.memory, promptTokens)
 		return false
 	}
 
 	// Attempt summarization
-	summarizedCount, err := a.contextManager.EvaluateAndSummarize(ctx, convMem, promptTokens)
+	summarizedCount, err := a.contextManager.EvaluateAndSummarize(ctx, a.memory, promptTokens)
 	if err != nil {
 		agentDebugLog.Printf("Failed to summarize conversation: %v", err)
 		return false
@@ -55,6 +58,21 @@ func (a *DefaultAgent) attemptSummarization(ctx context.Context, promptTokens in
 	return false
 }
 
+// summarizeAhead starts summarizing the conversation in the background once a
+// turn ends, while the user reads the response and writes the next message
+func (a *DefaultAgent) summarizeAhead(ctx context.Context) {
+	if a.contextManager == nil {
+		return
+	}
+
+	var promptTokens int
+	if a.tokenizer != nil {
+		messages := prompts.BuildMessages(a.buildSystemPrompt(), a.memory.GetAll(), "", "")
+		promptTokens = a.tokenizer.CountMessagesTokens(messages)
+	}
+	a.contextManager.Start(context.WithoutCancel(ctx), a.memory, promptTokens)
+}
+
 // preparePrompt builds the prompt, counts tokens, and handles context summarization
 func (a *DefaultAgent) preparePrompt(ctx context.Context, errorContext string) *promptContext {
 	// Build system prompt with tools
@@ -100,7 +118,8 @@ func (a *DefaultAgent) callLLM(ctx context.Context, pctx *promptContext) (*llmRe
 	if a.contextManager != nil {
 		maxTokens = a.contextManager.GetMaxTokens()
 	}
-	a.emitEvent(types.NewApiCallStartEvent("llm", pctx.promptTokens, maxTokens))
+	a.emitEvent(types.NewApiCallStartEvent("llm", pctx.promptTokens, maxTokens).
+		WithMetadata("base_prompt_version", a.BasePromptVersion()))
 
 	// Get response from LLM
 	stream, err := a.provider.StreamCompletion(ctx, pctx.messages)
@@ -114,43 +133,64 @@ func (a *DefaultAgent) callLLM(ctx context.Context, pctx *promptContext) (*llmRe
 		return nil, err
 	}
 
+	// Capture provider-reported usage as it passes through
+	var usage *llm.UsageInfo
+	stream = llm.TapStream(stream, func(chunk *llm.StreamChunk) {
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}, nil)
+
 	// Process stream and collect response
 	var assistantContent string
-	var toolCallContent string
-	core.ProcessStream(stream, a.emitEvent, func(content, thinking, toolCall, role string) {
+	var toolCalls []string
+	core.ProcessStream(stream, a.emitEvent, func(content, thinking string, calls []string, role string) {
 		assistantContent = content
-		toolCallContent = toolCall
+		toolCalls = calls
 	})
 
 	// Count completion tokens if tokenizer is available
 	var completionTokens int
 	if a.tokenizer != nil {
-		fullResponse := assistantContent
-		if toolCallContent != "" {
-			fullResponse += toolCallContent
-		}
-		completionTokens = a.tokenizer.CountTokens(fullResponse)
+		completionTokens = a.tokenizer.CountTokens(assistantContent + strings.Join(toolCalls, ""))
 	}
 
 	return &llmResponse{
 		assistantContent: assistantContent,
-		toolCallContent:  toolCallContent,
+		toolCalls:        toolCalls,
 		completionTokens: completionTokens,
+		usage:            usage,
 	}, nil
 }
 
 // recordResponse handles token usage events and adds the response to memory
 func (a *DefaultAgent) recordResponse(pctx *promptContext, resp *llmResponse) {
-	// Emit token usage event if we have token counts
-	if pctx.promptTokens > 0 || resp.completionTokens > 0 {
+	// Emit token usage event, preferring the provider's counts over local estimates
+	var event *types.AgentEvent
+	if resp.usage != nil {
+		event = types.NewTokenUsageEvent(resp.usage.PromptTokens, resp.usage.CompletionTokens, resp.usage.TotalTokens)
+		event.TokenUsage.CachedTokens = resp.usage.CachedPromptTokens
+	} else if pctx.promptTokens > 0 || resp.completionTokens > 0 {
 		totalTokens := pctx.promptTokens + resp.completionTokens
-		a.emitEvent(types.NewTokenUsageEvent(pctx.promptTokens, resp.completionTokens, totalTokens))
+		event = types.NewTokenUsageEvent(pctx.promptTokens, resp.completionTokens, totalTokens)
+	}
+	if event != nil {
+		if info := a.provider.GetModelInfo(); info != nil {
+			event.TokenUsage.Model = info.Name
+		}
+		if a.turn != nil {
+			a.turn.recordUsage(event.TokenUsage)
+		}
+		a.emitEvent(event)
 	}
 
 	// Add assistant's response to memory
 	fullResponse := resp.assistantContent
-	if resp.toolCallContent != "" {
-		fullResponse += "<tool>" + resp.toolCallContent + "</tool>"
+	for i, toolCall := range resp.toolCalls {
+		if i > 0 {
+			fullResponse += "\n"
+		}
+		fullResponse += "<tool>" + toolCall + "</tool>"
 	}
 	a.memory.Add(&types.Message{
 		Role:    types.RoleAssistant,
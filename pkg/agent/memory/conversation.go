@@ -65,6 +65,57 @@ func (cm *ConversationMemory) GetRecent(n int) []*types.Message {
 	return result
 }
 
+// GetRange returns the messages from index start up to but not including
+// end. Indexes outside the conversation are clamped to it.
+func (cm *ConversationMemory) GetRange(start, end int) []*types.Message {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	start = max(start, 0)
+	end = min(end, len(cm.messages))
+	if start >= end {
+		return []*types.Message{}
+	}
+
+	result := make([]*types.Message, end-start)
+	copy(result, cm.messages[start:end])
+	return result
+}
+
+// Search returns the messages matching query, oldest first. With a positive
+// Limit only the most recent matches are returned.
+func (cm *ConversationMemory) Search(query Query) []*types.Message {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	result := make([]*types.Message, 0)
+	for _, msg := range cm.messages {
+		if query.Matches(msg) {
+			result = append(result, msg)
+		}
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+	return result
+}
+
+// Tag adds tags to msg, which must be in the conversation history, under
+// the TagsKey metadata key. Messages are compared by identity. It returns
+// false and leaves msg unchanged if msg is not in the conversation.
+func (cm *ConversationMemory) Tag(msg *types.Message, tags ...string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	for _, m := range cm.messages {
+		if m == msg {
+			AddTags(msg, tags...)
+			return true
+		}
+	}
+	return false
+}
+
 // Clear removes all messages from the conversation history
 func (cm *ConversationMemory) Clear() {
 	cm.mu.Lock()
@@ -169,6 +220,31 @@ func (cm *ConversationMemory) AddMultiple(messages []*types.Message) {
 	}
 }
 
+// ReplacePrefix replaces prefix, which must be how the conversation starts,
+// with replacement, keeping any messages added after it. Messages are
+// compared by identity. It returns false and leaves the conversation
+// unchanged if the conversation no longer starts with prefix, such as after
+// it was cleared or pruned.
+func (cm *ConversationMemory) ReplacePrefix(prefix, replacement []*types.Message) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if len(prefix) > len(cm.messages) {
+		return false
+	}
+	for i, msg := range prefix {
+		if cm.messages[i] != msg {
+			return false
+		}
+	}
+
+	added := cm.messages[len(prefix):]
+	messages := make([]*types.Message, 0, len(replacement)+len(added))
+	messages = append(messages, replacement...)
+	cm.messages = append(messages, added...)
+	return true
+}
+
 // GetByRole returns all messages with the specified role
 func (cm *ConversationMemory) GetByRole(role types.MessageRole) []*types.Message {
 	cm.mu.RLock()
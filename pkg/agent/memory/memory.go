@@ -1,21 +1,51 @@
 package memory
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/entrhq/forge/pkg/types"
 )
 
 // Memory represents a conversation history management system
 // that can store, retrieve, and prune messages.
+//
+// Context strategies, exports and tools work with any Memory, so an
+// alternative backend only has to implement this interface.
 type Memory interface {
 	// Add appends a message to the conversation history
 	Add(msg *types.Message)
 
+	// AddMultiple appends messages to the conversation history in order
+	AddMultiple(messages []*types.Message)
+
 	// GetAll returns all messages in the conversation history
 	GetAll() []*types.Message
 
 	// GetRecent returns the most recent N messages
 	GetRecent(n int) []*types.Message
 
+	// GetRange returns the messages from index start up to but not including
+	// end, clamped to the conversation
+	GetRange(start, end int) []*types.Message
+
+	// GetByRole returns all messages with the specified role
+	GetByRole(role types.MessageRole) []*types.Message
+
+	// Search returns the messages matching query, oldest first
+	Search(query Query) []*types.Message
+
+	// Tag adds tags to msg, which must be in the conversation history. It
+	// returns false if msg is not.
+	Tag(msg *types.Message, tags ...string) bool
+
+	// ReplacePrefix replaces prefix, which must be how the conversation
+	// starts, with replacement, keeping any messages added after it. It
+	// returns false and leaves the conversation unchanged if the
+	// conversation no longer starts with prefix.
+	ReplacePrefix(prefix, replacement []*types.Message) bool
+
 	// Clear removes all messages from the conversation history
 	Clear()
 
@@ -26,3 +56,110 @@ type Memory interface {
 	// Count returns the number of messages in the conversation history
 	Count() int
 }
+
+// TurnSummaryKey is the metadata key marking a machine-written turn summary.
+// Turn summaries are durable anchors: context strategies keep them rather than
+// summarizing them away with the raw content they describe.
+const TurnSummaryKey = "turn_summary"
+
+// IsTurnSummary reports whether msg is a turn summary.
+func IsTurnSummary(msg *types.Message) bool {
+	if msg == nil || msg.Metadata == nil {
+		return false
+	}
+	marked, ok := msg.Metadata[TurnSummaryKey].(bool)
+	return ok && marked
+}
+
+// TagsKey is the metadata key holding a message's tags as a []string.
+const TagsKey = "tags"
+
+// Tags returns the tags of msg. Tags read back from JSON, as a
+// []interface{}, are accepted too.
+func Tags(msg *types.Message) []string {
+	if msg == nil || msg.Metadata == nil {
+		return nil
+	}
+	switch tags := msg.Metadata[TagsKey].(type) {
+	case []string:
+		return tags
+	case []interface{}:
+		result := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// HasTag reports whether msg is tagged with tag.
+func HasTag(msg *types.Message, tag string) bool {
+	for _, t := range Tags(msg) {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags adds the tags msg doesn't have yet, for Memory implementations of
+// Tag. The tag slice is copied so messages already handed out keep the tags
+// they had.
+func AddTags(msg *types.Message, tags ...string) {
+	merged := append([]string(nil), Tags(msg)...)
+	for _, tag := range tags {
+		if tag != "" && !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	msg.WithMetadata(TagsKey, merged)
+}
+
+// Query selects messages by role, tags, metadata and content. Empty fields
+// match every message; a message must match all the fields that are set.
+type Query struct {
+	// Roles matches messages with any of these roles
+	Roles []types.MessageRole
+
+	// Tags matches messages tagged with all of these tags
+	Tags []string
+
+	// Metadata matches messages whose metadata has each of these keys set
+	// to an equal value. Values are compared as printed, so a count read
+	// back from JSON as 3.0 still matches 3.
+	Metadata map[string]interface{}
+
+	// Text matches messages whose content contains it, ignoring case
+	Text string
+
+	// Limit keeps only the most recent matches when positive
+	Limit int
+}
+
+// Matches reports whether msg matches the query, ignoring Limit.
+func (q Query) Matches(msg *types.Message) bool {
+	if msg == nil {
+		return false
+	}
+	if len(q.Roles) > 0 && !slices.Contains(q.Roles, msg.Role) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !HasTag(msg, tag) {
+			return false
+		}
+	}
+	for key, want := range q.Metadata {
+		got, ok := msg.Metadata[key]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	if q.Text != "" && !strings.Contains(strings.ToLower(msg.Content), strings.ToLower(q.Text)) {
+		return false
+	}
+	return true
+}
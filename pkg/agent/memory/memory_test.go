@@ -294,3 +294,117 @@ func TestConversationMemory_GetAllReturnsACopy(t *testing.T) {
 		t.Errorf("expected 1 message in internal storage, got %d", len(original))
 	}
 }
+
+func TestConversationMemory_ReplacePrefix(t *testing.T) {
+	mem := NewConversationMemory()
+	first, second := types.NewUserMessage("first"), types.NewAssistantMessage("second")
+	mem.AddMultiple([]*types.Message{first, second})
+	prefix := mem.GetAll()
+
+	// A message added while the prefix was being summarized is kept
+	added := types.NewUserMessage("added")
+	mem.Add(added)
+
+	summary := types.NewAssistantMessage("summary")
+	if !mem.ReplacePrefix(prefix, []*types.Message{summary}) {
+		t.Fatal("expected the prefix to be replaced")
+	}
+	all := mem.GetAll()
+	if len(all) != 2 || all[0] != summary || all[1] != added {
+		t.Errorf("unexpected messages after replace: %v", all)
+	}
+
+	// The conversation no longer starts with the old prefix
+	if mem.ReplacePrefix(prefix, nil) {
+		t.Error("expected a stale prefix not to be replaced")
+	}
+	if mem.Count() != 2 {
+		t.Errorf("expected a failed replace to leave the conversation unchanged, got %d messages", mem.Count())
+	}
+}
+
+func TestConversationMemory_GetRange(t *testing.T) {
+	mem := NewConversationMemory()
+	for _, content := range []string{"a", "b", "c", "d"} {
+		mem.Add(types.NewUserMessage(content))
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       string
+	}{
+		{"middle", 1, 3, "bc"},
+		{"clamped", -2, 10, "abcd"},
+		{"empty", 3, 1, ""},
+		{"past the end", 5, 8, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			for _, msg := range mem.GetRange(tt.start, tt.end) {
+				got += msg.Content
+			}
+			if got != tt.want {
+				t.Errorf("GetRange(%d, %d) = %q, want %q", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConversationMemory_Tag(t *testing.T) {
+	mem := NewConversationMemory()
+	msg := types.NewUserMessage("decision")
+	mem.Add(msg)
+
+	if !mem.Tag(msg, "decision", "api") || !mem.Tag(msg, "api", "") {
+		t.Fatal("expected the message to be tagged")
+	}
+	if tags := Tags(msg); len(tags) != 2 || tags[0] != "decision" || tags[1] != "api" {
+		t.Errorf("Tags() = %v, want [decision api]", tags)
+	}
+	if !HasTag(msg, "api") || HasTag(msg, "other") {
+		t.Error("unexpected HasTag result")
+	}
+
+	if mem.Tag(types.NewUserMessage("elsewhere"), "decision") {
+		t.Error("expected a message outside the conversation not to be tagged")
+	}
+}
+
+func TestConversationMemory_Search(t *testing.T) {
+	mem := NewConversationMemory()
+	plan := types.NewAssistantMessage("The Plan: use SQLite").WithMetadata("step", 2)
+	question := types.NewUserMessage("Which plan?")
+	later := types.NewAssistantMessage("Revised plan")
+	mem.AddMultiple([]*types.Message{types.NewSystemMessage("system"), plan, question, later})
+	mem.Tag(plan, "decision")
+	mem.Tag(later, "decision")
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []*types.Message
+	}{
+		{"text ignores case", Query{Text: "PLAN"}, []*types.Message{plan, question, later}},
+		{"role and text", Query{Roles: []types.MessageRole{types.RoleUser}, Text: "plan"}, []*types.Message{question}},
+		{"tag", Query{Tags: []string{"decision"}}, []*types.Message{plan, later}},
+		{"tag and text", Query{Tags: []string{"decision"}, Text: "sqlite"}, []*types.Message{plan}},
+		{"metadata", Query{Metadata: map[string]interface{}{"step": 2.0}}, []*types.Message{plan}},
+		{"limit keeps most recent", Query{Text: "plan", Limit: 2}, []*types.Message{question, later}},
+		{"no match", Query{Tags: []string{"missing"}}, []*types.Message{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mem.Search(tt.query)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search() returned %d messages, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("message %d = %q, want %q", i, got[i].Content, tt.want[i].Content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// IDKey is the metadata key holding a stored message's row ID. Memory sets it
+// on every message it adds or returns and uses it to recognize messages,
+// where ConversationMemory compares them by identity. It is not stored.
+const IDKey = "sqlite_id"
+
+// Memory is the conversation history of one session in a Store. It
+// implements memory.Memory; methods of that interface that can't report an
+// error record the first one for Err.
+type Memory struct {
+	db      *sql.DB
+	session string
+
+	mu  sync.Mutex // Serializes changes, which read before they write
+	err error
+}
+
+// ID returns the session's name
+func (m *Memory) ID() string {
+	return m.session
+}
+
+// Err returns the first error a memory.Memory method failed with, if any
+func (m *Memory) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// fail records err if it is the first error
+func (m *Memory) fail(err error) {
+	if err != nil && m.err == nil {
+		m.err = err
+	}
+}
+
+// Add appends a message to the conversation history
+func (m *Memory) Add(msg *types.Message) {
+	m.AddMultiple([]*types.Message{msg})
+}
+
+// AddMultiple appends messages to the conversation history in order
+func (m *Memory) AddMultiple(messages []*types.Message) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fail(m.inTx(func(tx *sql.Tx) error {
+		var seq int64
+		if err := tx.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM messages WHERE session_id = ?`, m.session).Scan(&seq); err != nil {
+			return err
+		}
+		for _, msg := range messages {
+			if msg == nil {
+				continue
+			}
+			seq++
+			if err := m.insert(tx, msg, seq); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// GetAll returns all messages in the conversation history
+func (m *Memory) GetAll() []*types.Message {
+	return m.query(`SELECT `+columns+` FROM messages WHERE session_id = ? ORDER BY seq`, m.session)
+}
+
+// GetRecent returns the most recent N messages
+func (m *Memory) GetRecent(n int) []*types.Message {
+	if n <= 0 {
+		return []*types.Message{}
+	}
+	return m.query(`SELECT `+columns+` FROM (
+		SELECT seq, `+columns+` FROM messages WHERE session_id = ? ORDER BY seq DESC LIMIT ?
+	) ORDER BY seq`, m.session, n)
+}
+
+// GetRange returns the messages from index start up to but not including
+// end. Indexes outside the conversation are clamped to it.
+func (m *Memory) GetRange(start, end int) []*types.Message {
+	start = max(start, 0)
+	if start >= end {
+		return []*types.Message{}
+	}
+	return m.query(`SELECT `+columns+` FROM messages WHERE session_id = ? ORDER BY seq LIMIT ? OFFSET ?`, m.session, end-start, start)
+}
+
+// GetByRole returns all messages with the specified role
+func (m *Memory) GetByRole(role types.MessageRole) []*types.Message {
+	return m.query(`SELECT `+columns+` FROM messages WHERE session_id = ? AND role = ? ORDER BY seq`, m.session, string(role))
+}
+
+// Search returns the messages matching query, oldest first. With a positive
+// Limit only the most recent matches are returned.
+func (m *Memory) Search(query memory.Query) []*types.Message {
+	result := make([]*types.Message, 0)
+	for _, msg := range m.GetAll() {
+		if query.Matches(msg) {
+			result = append(result, msg)
+		}
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+	return result
+}
+
+// Tag adds tags to msg, which must be a message of this session, and stores
+// them. It returns false and leaves msg unchanged if msg is not.
+func (m *Memory) Tag(msg *types.Message, tags ...string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id, ok := messageID(msg)
+	if !ok {
+		return false
+	}
+	var exists bool
+	if err := m.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM messages WHERE id = ? AND session_id = ?)`, id, m.session).Scan(&exists); err != nil {
+		m.fail(err)
+		return false
+	}
+	if !exists {
+		return false
+	}
+
+	memory.AddTags(msg, tags...)
+	metadata, err := encodeMetadata(msg)
+	if err == nil {
+		_, err = m.db.Exec(`UPDATE messages SET metadata = ? WHERE id = ?`, metadata, id)
+	}
+	m.fail(err)
+	return err == nil
+}
+
+// Clear removes all messages from the conversation history
+func (m *Memory) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fail(m.inTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM messages WHERE session_id = ?`, m.session)
+		return err
+	}))
+}
+
+// Count returns the number of messages in the conversation history
+func (m *Memory) Count() int {
+	var count int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE session_id = ?`, m.session).Scan(&count); err != nil {
+		m.mu.Lock()
+		m.fail(err)
+		m.mu.Unlock()
+	}
+	return count
+}
+
+// Prune reduces the conversation history to fit within a token limit the
+// way ConversationMemory does, deleting the messages it drops.
+func (m *Memory) Prune(maxTokens int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages, err := m.load(`SELECT `+columns+` FROM messages WHERE session_id = ? ORDER BY seq`, m.session)
+	if err != nil {
+		return err
+	}
+	pruned := memory.NewConversationMemory()
+	pruned.AddMultiple(messages)
+	if err := pruned.Prune(maxTokens); err != nil {
+		return err
+	}
+
+	kept := make(map[int64]bool)
+	for _, msg := range pruned.GetAll() {
+		id, _ := messageID(msg)
+		kept[id] = true
+	}
+	return m.inTx(func(tx *sql.Tx) error {
+		for _, msg := range messages {
+			id, _ := messageID(msg)
+			if kept[id] {
+				continue
+			}
+			if _, err := tx.Exec(`DELETE FROM messages WHERE id = ?`, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReplacePrefix replaces prefix, which must be how the conversation starts,
+// with replacement, keeping any messages added after it. Messages are
+// recognized by IDKey. Messages of prefix that are also in replacement keep
+// their rows, and with them their embeddings. It returns false and leaves
+// the conversation unchanged if the conversation no longer starts with
+// prefix.
+func (m *Memory) ReplacePrefix(prefix, replacement []*types.Message) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	replaced := false
+	m.fail(m.inTx(func(tx *sql.Tx) error {
+		// The conversation must still start with prefix
+		rows, err := tx.Query(`SELECT id FROM messages WHERE session_id = ? ORDER BY seq LIMIT ?`, m.session, len(prefix))
+		if err != nil {
+			return err
+		}
+		var current []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			current = append(current, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(current) != len(prefix) {
+			return nil
+		}
+		inPrefix := make(map[int64]bool, len(prefix))
+		for i, msg := range prefix {
+			id, ok := messageID(msg)
+			if !ok || id != current[i] {
+				return nil
+			}
+			inPrefix[id] = true
+		}
+
+		// Number the replacement so it sorts before the messages kept after it
+		var next sql.NullInt64
+		if err := tx.QueryRow(`SELECT MIN(seq) FROM messages WHERE session_id = ? AND id NOT IN (SELECT value FROM json_each(?))`,
+			m.session, idList(current)).Scan(&next); err != nil {
+			return err
+		}
+		seq := int64(1)
+		if next.Valid {
+			seq = next.Int64 - int64(len(replacement))
+		}
+
+		for _, msg := range replacement {
+			if id, ok := messageID(msg); ok && inPrefix[id] {
+				if _, err := tx.Exec(`UPDATE messages SET seq = ? WHERE id = ?`, seq, id); err != nil {
+					return err
+				}
+				delete(inPrefix, id)
+			} else if msg != nil {
+				if err := m.insert(tx, msg, seq); err != nil {
+					return err
+				}
+			}
+			seq++
+		}
+		for id := range inPrefix {
+			if _, err := tx.Exec(`DELETE FROM messages WHERE id = ?`, id); err != nil {
+				return err
+			}
+		}
+		replaced = true
+		return nil
+	}))
+	return replaced
+}
+
+// SetEmbedding stores the embedding of msg, a message of this session, made
+// by model, replacing any it had from that model.
+func (m *Memory) SetEmbedding(msg *types.Message, model string, vector []float32) error {
+	id, ok := messageID(msg)
+	if !ok {
+		return fmt.Errorf("message is not stored")
+	}
+	blob := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(v))
+	}
+	_, err := m.db.Exec(`
+		INSERT INTO embeddings (message_id, model, vector)
+		SELECT id, ?, ? FROM messages WHERE id = ? AND session_id = ?
+		ON CONFLICT(message_id, model) DO UPDATE SET vector = excluded.vector`,
+		model, blob, id, m.session)
+	if err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+	return nil
+}
+
+// Embedding returns the embedding of msg made by model, or nil if none is
+// stored.
+func (m *Memory) Embedding(msg *types.Message, model string) ([]float32, error) {
+	id, ok := messageID(msg)
+	if !ok {
+		return nil, nil
+	}
+	var blob []byte
+	err := m.db.QueryRow(`
+		SELECT e.vector FROM embeddings e JOIN messages m ON m.id = e.message_id
+		WHERE e.message_id = ? AND e.model = ? AND m.session_id = ?`,
+		id, model, m.session).Scan(&blob)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding: %w", err)
+	}
+	vector := make([]float32, len(blob)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:]))
+	}
+	return vector, nil
+}
+
+// columns are the message columns scan reads, in order
+const columns = `id, role, content, metadata, timestamp`
+
+// query returns the messages a query selects, recording any error
+func (m *Memory) query(query string, args ...interface{}) []*types.Message {
+	messages, err := m.load(query, args...)
+	if err != nil {
+		m.mu.Lock()
+		m.fail(err)
+		m.mu.Unlock()
+		return []*types.Message{}
+	}
+	return messages
+}
+
+// load returns the messages a query of columns selects
+func (m *Memory) load(query string, args ...interface{}) ([]*types.Message, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]*types.Message, 0)
+	for rows.Next() {
+		var id, timestamp int64
+		var role, content, metadata string
+		if err := rows.Scan(&id, &role, &content, &metadata, &timestamp); err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		msg := &types.Message{
+			Role:     types.MessageRole(role),
+			Content:  content,
+			Metadata: make(map[string]interface{}),
+		}
+		if timestamp != 0 {
+			msg.Timestamp = time.Unix(0, timestamp)
+		}
+		if err := json.Unmarshal([]byte(metadata), &msg.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to read metadata of message %d: %w", id, err)
+		}
+		msg.Metadata[IDKey] = id
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// insert stores msg at seq and sets its IDKey to the new row
+func (m *Memory) insert(tx *sql.Tx, msg *types.Message, seq int64) error {
+	metadata, err := encodeMetadata(msg)
+	if err != nil {
+		return err
+	}
+	var timestamp int64
+	if !msg.Timestamp.IsZero() {
+		timestamp = msg.Timestamp.UnixNano()
+	}
+	result, err := tx.Exec(`INSERT INTO messages (session_id, seq, role, content, metadata, timestamp) VALUES (?, ?, ?, ?, ?, ?)`,
+		m.session, seq, string(msg.Role), msg.Content, metadata, timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to store message: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	msg.WithMetadata(IDKey, id)
+	return nil
+}
+
+// inTx runs fn in a transaction and marks the session updated
+func (m *Memory) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE sessions SET updated = ? WHERE id = ?`, time.Now().UnixNano(), m.session); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// messageID returns the row ID Memory gave msg
+func messageID(msg *types.Message) (int64, bool) {
+	if msg == nil || msg.Metadata == nil {
+		return 0, false
+	}
+	id, ok := msg.Metadata[IDKey].(int64)
+	return id, ok
+}
+
+// encodeMetadata returns msg's metadata as JSON, without IDKey
+func encodeMetadata(msg *types.Message) (string, error) {
+	metadata := make(map[string]interface{}, len(msg.Metadata))
+	for key, value := range msg.Metadata {
+		if key != IDKey {
+			metadata[key] = value
+		}
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode message metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+// idList returns ids as a JSON array for json_each
+func idList(ids []int64) string {
+	data, _ := json.Marshal(ids)
+	return string(data)
+}
@@ -0,0 +1,201 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// openSession returns a memory in a new store in a temporary directory
+func openSession(t *testing.T) (*Store, *Memory) {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	mem, err := store.Session("test")
+	if err != nil {
+		t.Fatalf("Session failed: %v", err)
+	}
+	return store, mem
+}
+
+// contents returns the content of messages joined by spaces
+func contents(messages []*types.Message) string {
+	result := ""
+	for i, msg := range messages {
+		if i > 0 {
+			result += " "
+		}
+		result += msg.Content
+	}
+	return result
+}
+
+func TestMemory_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	mem, _ := store.Session("work")
+	summary := types.NewAssistantMessage("summary").WithMetadata(memory.TurnSummaryKey, true)
+	mem.AddMultiple([]*types.Message{types.NewUserMessage("hello"), summary})
+	mem.Tag(summary, "decision")
+	other, _ := store.Session("other")
+	other.Add(types.NewUserMessage("elsewhere"))
+	store.Close()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer store.Close()
+	mem, _ = store.Session("work")
+
+	all := mem.GetAll()
+	if got := contents(all); got != "hello summary" {
+		t.Fatalf("resumed messages = %q, want %q", got, "hello summary")
+	}
+	if all[0].Role != types.RoleUser || all[0].Timestamp.IsZero() {
+		t.Errorf("unexpected first message: %+v", all[0])
+	}
+	if !memory.IsTurnSummary(all[1]) || !memory.HasTag(all[1], "decision") {
+		t.Errorf("expected metadata and tags to be kept, got %v", all[1].Metadata)
+	}
+
+	sessions, err := store.Sessions()
+	if err != nil {
+		t.Fatalf("Sessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "other" || sessions[1].Messages != 2 {
+		t.Errorf("unexpected sessions: %+v", sessions)
+	}
+	if err := store.DeleteSession("other"); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if other, _ := store.Session("other"); other.Count() != 0 {
+		t.Error("expected a deleted session to start empty")
+	}
+}
+
+func TestMemory_Retrieval(t *testing.T) {
+	_, mem := openSession(t)
+	mem.AddMultiple([]*types.Message{
+		types.NewSystemMessage("a"),
+		types.NewUserMessage("b"),
+		types.NewAssistantMessage("c"),
+		types.NewUserMessage("d"),
+	})
+
+	if mem.Count() != 4 {
+		t.Errorf("Count() = %d, want 4", mem.Count())
+	}
+	if got := contents(mem.GetRecent(2)); got != "c d" {
+		t.Errorf("GetRecent(2) = %q", got)
+	}
+	if got := contents(mem.GetRange(-1, 2)); got != "a b" {
+		t.Errorf("GetRange(-1, 2) = %q", got)
+	}
+	if got := contents(mem.GetByRole(types.RoleUser)); got != "b d" {
+		t.Errorf("GetByRole(user) = %q", got)
+	}
+	if got := contents(mem.Search(memory.Query{Roles: []types.MessageRole{types.RoleUser}, Limit: 1})); got != "d" {
+		t.Errorf("Search() = %q", got)
+	}
+
+	mem.Clear()
+	if mem.Count() != 0 || len(mem.GetAll()) != 0 {
+		t.Error("expected Clear to remove every message")
+	}
+	if err := mem.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMemory_ReplacePrefix(t *testing.T) {
+	store, mem := openSession(t)
+	mem.AddMultiple([]*types.Message{
+		types.NewUserMessage("first"),
+		types.NewAssistantMessage("second"),
+		types.NewUserMessage("kept"),
+	})
+	prefix := mem.GetAll()
+	for _, msg := range []*types.Message{prefix[0], prefix[2]} {
+		if err := mem.SetEmbedding(msg, "test", []float32{0.5, -1}); err != nil {
+			t.Fatalf("SetEmbedding failed: %v", err)
+		}
+	}
+
+	// A message added while the prefix was being summarized is kept
+	mem.Add(types.NewUserMessage("added"))
+
+	replacement := []*types.Message{types.NewAssistantMessage("summary"), prefix[2]}
+	if !mem.ReplacePrefix(prefix, replacement) {
+		t.Fatal("expected the prefix to be replaced")
+	}
+	if got := contents(mem.GetAll()); got != "summary kept added" {
+		t.Errorf("messages after replace = %q", got)
+	}
+
+	// The kept message keeps its row, and with it its embedding
+	vector, err := mem.Embedding(mem.GetAll()[1], "test")
+	if err != nil || len(vector) != 2 || vector[0] != 0.5 || vector[1] != -1 {
+		t.Errorf("Embedding() = %v, %v; want [0.5 -1]", vector, err)
+	}
+	// The embedding of a replaced message goes with it
+	var embeddings int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM embeddings`).Scan(&embeddings); err != nil || embeddings != 1 {
+		t.Errorf("stored embeddings = %d, %v; want 1", embeddings, err)
+	}
+
+	if mem.ReplacePrefix(prefix, nil) {
+		t.Error("expected a stale prefix not to be replaced")
+	}
+	if mem.Count() != 3 {
+		t.Errorf("expected a failed replace to leave the conversation unchanged, got %d messages", mem.Count())
+	}
+	if err := mem.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMemory_Tag(t *testing.T) {
+	store, mem := openSession(t)
+	msg := types.NewUserMessage("decision")
+	mem.Add(msg)
+
+	if !mem.Tag(msg, "decision") {
+		t.Fatal("expected a stored message to be tagged")
+	}
+	if got := mem.Search(memory.Query{Tags: []string{"decision"}}); len(got) != 1 {
+		t.Errorf("expected the tag to be stored, found %d messages", len(got))
+	}
+
+	other, _ := store.Session("other")
+	if other.Tag(msg, "decision") {
+		t.Error("expected a message of another session not to be tagged")
+	}
+	if mem.Tag(types.NewUserMessage("unstored"), "decision") {
+		t.Error("expected an unstored message not to be tagged")
+	}
+}
+
+func TestMemory_Prune(t *testing.T) {
+	_, mem := openSession(t)
+	mem.AddMultiple([]*types.Message{
+		types.NewSystemMessage("system"),
+		types.NewUserMessage("an old message that no longer fits"),
+		types.NewUserMessage("recent"),
+	})
+
+	if err := mem.Prune(5); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if got := contents(mem.GetAll()); got != "system recent" {
+		t.Errorf("messages after prune = %q, want %q", got, "system recent")
+	}
+}
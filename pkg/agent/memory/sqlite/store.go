@@ -0,0 +1,139 @@
+// Package sqlite persists conversation memory in a SQLite database, so long
+// sessions live on disk rather than in RAM and can be resumed and searched
+// later.
+//
+// A Store holds any number of sessions, each a conversation identified by a
+// name. Store.Session returns a Memory for one of them, which implements
+// memory.Memory and can be given to the agent with agent.WithMemory.
+// Messages are stored with their metadata, so tool results, turn summaries
+// and tags survive a restart, and can have embeddings stored alongside them.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // Registers the pure Go "sqlite" driver
+)
+
+// Path is the session database's location, relative to the workspace root
+const Path = ".forge/sessions.db"
+
+// schema creates the tables on first use. Messages are ordered by seq within
+// their session; seq may go negative when a summary replaces the start of a
+// conversation.
+const schema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id      TEXT PRIMARY KEY,
+	created INTEGER NOT NULL,
+	updated INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS messages (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	seq        INTEGER NOT NULL,
+	role       TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	metadata   TEXT NOT NULL DEFAULT '{}',
+	timestamp  INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS messages_session_seq ON messages(session_id, seq);
+CREATE TABLE IF NOT EXISTS embeddings (
+	message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+	model      TEXT NOT NULL,
+	vector     BLOB NOT NULL,
+	PRIMARY KEY (message_id, model)
+);
+`
+
+// Store is a SQLite database of conversation sessions
+type Store struct {
+	db *sql.DB
+}
+
+// SessionInfo describes a stored session
+type SessionInfo struct {
+	ID       string
+	Messages int
+	Created  time.Time
+	Updated  time.Time
+}
+
+// Open opens the session database at path, creating it and its directory if
+// needed. ":memory:" opens a database that is discarded on Close.
+func Open(path string) (*Store, error) {
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create session database directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session database: %w", err)
+	}
+	// One connection serializes writes and keeps a :memory: database whole
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create session database schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Session returns the memory of the session named id, creating the session
+// if it doesn't exist. Messages are read from the database as they are
+// needed, so resuming a long session doesn't load it.
+func (s *Store) Session(id string) (*Memory, error) {
+	if id == "" {
+		return nil, fmt.Errorf("session id cannot be empty")
+	}
+	now := time.Now().UnixNano()
+	if _, err := s.db.Exec(`INSERT INTO sessions (id, created, updated) VALUES (?, ?, ?) ON CONFLICT(id) DO NOTHING`, id, now, now); err != nil {
+		return nil, fmt.Errorf("failed to create session %s: %w", id, err)
+	}
+	return &Memory{db: s.db, session: id}, nil
+}
+
+// Sessions lists the stored sessions, most recently updated first
+func (s *Store) Sessions() ([]SessionInfo, error) {
+	rows, err := s.db.Query(`
+		SELECT s.id, s.created, s.updated, COUNT(m.id)
+		FROM sessions s LEFT JOIN messages m ON m.session_id = s.id
+		GROUP BY s.id
+		ORDER BY s.updated DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []SessionInfo
+	for rows.Next() {
+		var info SessionInfo
+		var created, updated int64
+		if err := rows.Scan(&info.ID, &created, &updated, &info.Messages); err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		info.Created = time.Unix(0, created)
+		info.Updated = time.Unix(0, updated)
+		sessions = append(sessions, info)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteSession removes the session named id and everything stored with it
+func (s *Store) DeleteSession(id string) error {
+	if _, err := s.db.Exec(`DELETE FROM sessions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", id, err)
+	}
+	return nil
+}
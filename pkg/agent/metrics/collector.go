@@ -0,0 +1,123 @@
+// Package metrics collects per-turn token usage and cost for a session, so
+// expensive turns can be told apart from the cumulative total, and counts
+// tool calls and failures so the agent can see where it keeps failing.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Turn is the usage of one user turn, summed over all of its LLM calls
+type Turn struct {
+	Number           int
+	Model            string
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	CachedTokens     int
+	Cost             float64
+	Priced           bool // False when any call used a model without a known price
+}
+
+// Collector accumulates token usage events into turns and counts tool
+// calls. It is safe for concurrent use.
+type Collector struct {
+	mu     sync.Mutex
+	prices map[string]Price
+	exact  map[string]Price // Prices the provider published, by model ID
+	turns  []*Turn
+	open   bool // Whether the last turn is still receiving usage
+	tools  map[toolKey]*ToolStat
+}
+
+// NewCollector creates a collector that prices calls with prices. A nil map
+// uses DefaultPrices.
+func NewCollector(prices map[string]Price) *Collector {
+	if prices == nil {
+		prices = DefaultPrices
+	}
+	return &Collector{prices: prices}
+}
+
+// SetModelPrice prices calls of exactly model at price, ahead of the
+// collector's prices by name prefix. It is for prices the provider publishes
+// in its model list; calls already recorded keep their cost.
+func (c *Collector) SetModelPrice(model string, price Price) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.exact == nil {
+		c.exact = make(map[string]Price)
+	}
+	c.exact[model] = price
+}
+
+// RecordUsage adds one LLM call to the current turn, starting a new turn if
+// the previous one has ended
+func (c *Collector) RecordUsage(usage *types.TokenUsage) {
+	if usage == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.open {
+		c.turns = append(c.turns, &Turn{Number: len(c.turns) + 1, Priced: true})
+		c.open = true
+	}
+	turn := c.turns[len(c.turns)-1]
+
+	turn.Calls++
+	turn.PromptTokens += usage.PromptTokens
+	turn.CompletionTokens += usage.CompletionTokens
+	turn.CachedTokens += usage.CachedTokens
+	if usage.Model != "" {
+		turn.Model = usage.Model
+	}
+
+	price, ok := c.exact[usage.Model]
+	if !ok {
+		price, ok = LookupPrice(c.prices, usage.Model)
+	}
+	if !ok {
+		turn.Priced = false
+		return
+	}
+	turn.Cost += price.Cost(usage.PromptTokens, usage.CachedTokens, usage.CompletionTokens)
+}
+
+// EndTurn closes the current turn; the next usage starts a new one
+func (c *Collector) EndTurn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.open = false
+}
+
+// Turns returns a copy of the recorded turns, oldest first
+func (c *Collector) Turns() []Turn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	turns := make([]Turn, len(c.turns))
+	for i, turn := range c.turns {
+		turns[i] = *turn
+	}
+	return turns
+}
+
+// Total sums all recorded turns. Cost covers priced calls only; Priced is
+// false if any call could not be priced.
+func (c *Collector) Total() Turn {
+	total := Turn{Priced: true}
+	for _, turn := range c.Turns() {
+		total.Calls += turn.Calls
+		total.PromptTokens += turn.PromptTokens
+		total.CompletionTokens += turn.CompletionTokens
+		total.CachedTokens += turn.CachedTokens
+		total.Cost += turn.Cost
+		total.Priced = total.Priced && turn.Priced
+	}
+	return total
+}
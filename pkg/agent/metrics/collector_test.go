@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+var testPrices = map[string]Price{
+	"test-model":       {Input: 1, CachedInput: 0.1, Output: 2},
+	"test-model-large": {Input: 10, Output: 20},
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestLookupPrice(t *testing.T) {
+	tests := []struct {
+		model string
+		want  float64
+		found bool
+	}{
+		{"test-model", 1, true},
+		{"test-model-large-2025", 10, true},
+		{"gateway/Test-Model-mini", 1, true},
+		{"other-model", 0, false},
+	}
+	for _, tt := range tests {
+		price, ok := LookupPrice(testPrices, tt.model)
+		if ok != tt.found || price.Input != tt.want {
+			t.Errorf("LookupPrice(%q) = %v, %v; want input %v, %v", tt.model, price, ok, tt.want, tt.found)
+		}
+	}
+}
+
+func TestLookupPrice_DefaultPrices(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string // Key of the expected price; empty for none
+	}{
+		{"gpt-4", "gpt-4"},
+		{"gpt-4-0613", "gpt-4"},
+		{"gpt-4-turbo-2024-04-09", "gpt-4-turbo"},
+		{"gpt-4o", "gpt-4o"},
+		{"gpt-4o-2024-08-06", "gpt-4o"},
+		{"openai/gpt-4o-mini", "gpt-4o-mini"},
+		{"gpt-4.1-mini", "gpt-4.1-mini"},
+		{"gpt-4.5-preview", ""},
+		{"anthropic/claude-sonnet-4.5", "claude-sonnet-4.5"},
+		{"claude-sonnet-4-20250514", "claude-sonnet-4"},
+		{"claude-opus-4-5-20251101", "claude-opus-4-5"},
+		{"claude-3-5-sonnet@20240620", "claude-3-5-sonnet"},
+	}
+	for _, tt := range tests {
+		price, ok := LookupPrice(DefaultPrices, tt.model)
+		if want, found := DefaultPrices[tt.want]; ok != found || price != want {
+			t.Errorf("LookupPrice(%q) = %v, %v; want the %q price", tt.model, price, ok, tt.want)
+		}
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	price := testPrices["test-model"]
+	// 900k uncached at $1, 100k cached at $0.10, 500k output at $2
+	got := price.Cost(1_000_000, 100_000, 500_000)
+	if want := 0.9 + 0.01 + 1.0; !approxEqual(got, want) {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+
+	// Without a cached rate, cached tokens are billed as input
+	if got := testPrices["test-model-large"].Cost(1_000_000, 500_000, 0); !approxEqual(got, 10) {
+		t.Errorf("Cost = %v, want 10", got)
+	}
+}
+
+func TestCollectorTurns(t *testing.T) {
+	c := NewCollector(testPrices)
+
+	c.RecordUsage(&types.TokenUsage{PromptTokens: 1000, CompletionTokens: 100, Model: "test-model"})
+	c.RecordUsage(&types.TokenUsage{PromptTokens: 2000, CompletionTokens: 200, CachedTokens: 1000, Model: "test-model"})
+	c.EndTurn()
+	c.RecordUsage(&types.TokenUsage{PromptTokens: 500, CompletionTokens: 50, Model: "unknown"})
+	c.EndTurn()
+
+	turns := c.Turns()
+	if len(turns) != 2 {
+		t.Fatalf("expected 2 turns, got %d", len(turns))
+	}
+
+	first := turns[0]
+	if first.Number != 1 || first.Calls != 2 || first.PromptTokens != 3000 || first.CompletionTokens != 300 || first.CachedTokens != 1000 {
+		t.Errorf("unexpected first turn: %+v", first)
+	}
+	if want := (2000*1 + 1000*0.1 + 300*2) / 1_000_000.0; !first.Priced || !approxEqual(first.Cost, want) {
+		t.Errorf("first turn cost = %v (priced %v), want %v", first.Cost, first.Priced, want)
+	}
+
+	if second := turns[1]; second.Number != 2 || second.Priced || second.Cost != 0 {
+		t.Errorf("unexpected second turn: %+v", second)
+	}
+
+	total := c.Total()
+	if total.Calls != 3 || total.PromptTokens != 3500 || total.Priced || !approxEqual(total.Cost, first.Cost) {
+		t.Errorf("unexpected total: %+v", total)
+	}
+}
+
+func TestCollectorSetModelPrice(t *testing.T) {
+	c := NewCollector(testPrices)
+	c.SetModelPrice("gateway/test-model-large", Price{Input: 5, Output: 5})
+	c.SetModelPrice("unlisted", Price{Input: 1, Output: 1})
+
+	c.RecordUsage(&types.TokenUsage{PromptTokens: 1000, CompletionTokens: 1000, Model: "gateway/test-model-large"})
+	c.RecordUsage(&types.TokenUsage{PromptTokens: 1000, CompletionTokens: 1000, Model: "unlisted"})
+	c.RecordUsage(&types.TokenUsage{PromptTokens: 1000, CompletionTokens: 1000, Model: "test-model-large"})
+
+	total := c.Total()
+	if want := (10000 + 2000 + 30000) / 1_000_000.0; !total.Priced || !approxEqual(total.Cost, want) {
+		t.Errorf("total cost = %v (priced %v), want %v", total.Cost, total.Priced, want)
+	}
+}
+
+func TestCollectorIgnoresNilUsage(t *testing.T) {
+	c := NewCollector(nil)
+	c.RecordUsage(nil)
+	if len(c.Turns()) != 0 {
+		t.Error("expected no turns for nil usage")
+	}
+}
+
+func TestToolReport(t *testing.T) {
+	c := NewCollector(nil)
+	if c.ToolReport(5) != "" {
+		t.Error("expected no report before any tool call")
+	}
+
+	notFound := errors.New("search text not found\nin parser.go")
+	for i := 0; i < 3; i++ {
+		c.RecordToolCall("apply_diff", "parser.go", notFound)
+	}
+	c.RecordToolCall("apply_diff", "lexer.go", nil)
+	c.RecordToolCall("execute_command", "", errors.New("exit status 1"))
+	c.RecordToolCall("execute_command", "", nil)
+	c.RecordToolCall("read_file", "parser.go", nil)
+	c.RecordToolCall("read_file", "lexer.go", nil)
+
+	want := "- apply_diff: 3 of 4 calls failed (3 on parser.go; last error: search text not found in parser.go)\n" +
+		"- execute_command: 1 of 2 calls failed (last error: exit status 1)\n" +
+		"- No failures: read_file (2)"
+	if got := c.ToolReport(5); got != want {
+		t.Errorf("ToolReport() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := c.ToolReport(1); !strings.Contains(got, "- Other tools with failures: 1") || strings.Contains(got, "execute_command") {
+		t.Errorf("expected the report to be limited to one failing tool, got:\n%s", got)
+	}
+
+	targets := c.TargetStats("apply_diff")
+	if len(targets) != 2 || targets[0].Target != "parser.go" || targets[0].Failures != 3 {
+		t.Errorf("unexpected target stats: %+v", targets)
+	}
+}
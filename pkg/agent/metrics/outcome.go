@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outcome is how one turn of a session went, tagged with the prompt variant
+// the session ran, for comparing variants offline
+type Outcome struct {
+	Time               time.Time `json:"time"`
+	Session            string    `json:"session"`
+	Variant            string    `json:"variant"`
+	Model              string    `json:"model,omitempty"`
+	Turn               int       `json:"turn"`
+	Completed          bool      `json:"completed"`  // The agent called task_completion
+	Iterations         int       `json:"iterations"` // LLM calls in the turn's agent loop
+	ToolErrors         int       `json:"tool_errors"`
+	ApprovalRejections int       `json:"approval_rejections"`
+	PromptTokens       int       `json:"prompt_tokens"`
+	CompletionTokens   int       `json:"completion_tokens"`
+	CostUSD            float64   `json:"cost_usd"`
+	Priced             bool      `json:"priced"` // False when the model has no known price, so CostUSD is 0
+	DurationSeconds    float64   `json:"duration_seconds"`
+}
+
+// Sink receives turn outcomes
+type Sink interface {
+	Record(outcome Outcome) error
+}
+
+// FileSink appends outcomes to a JSON Lines file, one object per line, so
+// several sessions can share it. It is safe for concurrent use.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink returns a sink appending to path, which is created with its
+// directory on the first outcome
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Path returns the file outcomes are appended to
+func (s *FileSink) Path() string {
+	return s.path
+}
+
+// Record appends outcome to the file
+func (s *FileSink) Record(outcome Outcome) error {
+	line, err := json.Marshal(outcome)
+	if err != nil {
+		return fmt.Errorf("failed to encode outcome: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open metrics file: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+// Price is the cost of a model in US dollars per million tokens
+type Price struct {
+	Input       float64
+	CachedInput float64 // Prompt tokens read from the provider's cache; zero bills them as Input
+	Output      float64
+}
+
+// DefaultPrices are list prices for common models, keyed by model name prefix
+// (see LookupPrice). Prices change; treat costs computed from them as estimates.
+var DefaultPrices = map[string]Price{
+	"gpt-4":              {Input: 30, Output: 60},
+	"gpt-4-turbo":        {Input: 10, Output: 30},
+	"gpt-4-1106-preview": {Input: 10, Output: 30},
+	"gpt-4-0125-preview": {Input: 10, Output: 30},
+	"gpt-4o":             {Input: 2.50, CachedInput: 1.25, Output: 10},
+	"gpt-4o-mini":        {Input: 0.15, CachedInput: 0.075, Output: 0.60},
+	"gpt-4.1":            {Input: 2, CachedInput: 0.50, Output: 8},
+	"gpt-4.1-mini":       {Input: 0.40, CachedInput: 0.10, Output: 1.60},
+	"gpt-4.1-nano":       {Input: 0.10, CachedInput: 0.025, Output: 0.40},
+	"o3-mini":            {Input: 1.10, CachedInput: 0.55, Output: 4.40},
+	"o4-mini":            {Input: 1.10, CachedInput: 0.275, Output: 4.40},
+	"claude-3-5-haiku":   {Input: 0.80, CachedInput: 0.08, Output: 4},
+	"claude-3-5-sonnet":  {Input: 3, CachedInput: 0.30, Output: 15},
+	"claude-3-7-sonnet":  {Input: 3, CachedInput: 0.30, Output: 15},
+	"claude-sonnet-4":    {Input: 3, CachedInput: 0.30, Output: 15},
+	"claude-sonnet-4.5":  {Input: 3, CachedInput: 0.30, Output: 15},
+	"claude-haiku-4-5":   {Input: 1, CachedInput: 0.10, Output: 5},
+	"claude-haiku-4.5":   {Input: 1, CachedInput: 0.10, Output: 5},
+	"claude-opus-4":      {Input: 15, CachedInput: 1.50, Output: 75},
+	"claude-opus-4.1":    {Input: 15, CachedInput: 1.50, Output: 75},
+	"claude-opus-4-5":    {Input: 5, CachedInput: 0.50, Output: 25},
+	"claude-opus-4.5":    {Input: 5, CachedInput: 0.50, Output: 25},
+}
+
+// LookupPrice finds the price for model in prices. Any gateway prefix such as
+// "anthropic/" is ignored, and the longest key the name starts with wins. A
+// key only matches whole parts of the name, ending at the end or before a
+// '-', ':' or '@': "gpt-4" prices "gpt-4-0613" but not "gpt-4o" or
+// "gpt-4.5-preview", and "claude-sonnet-4" prices
+// "anthropic/claude-sonnet-4-20250514".
+func LookupPrice(prices map[string]Price, model string) (Price, bool) {
+	name := strings.ToLower(model)
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	keys := make([]string, 0, len(prices))
+	for key := range prices {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+
+	for _, key := range keys {
+		if !strings.HasPrefix(name, key) {
+			continue
+		}
+		if len(name) == len(key) || strings.ContainsRune("-:@", rune(name[len(key)])) {
+			return prices[key], true
+		}
+	}
+	return Price{}, false
+}
+
+// Cost returns the dollar cost of a call at this price
+func (p Price) Cost(promptTokens, cachedTokens, completionTokens int) float64 {
+	cachedRate := p.CachedInput
+	if cachedRate == 0 {
+		cachedRate = p.Input
+	}
+	uncached := promptTokens - cachedTokens
+	if uncached < 0 {
+		uncached = 0
+	}
+	return (float64(uncached)*p.Input + float64(cachedTokens)*cachedRate + float64(completionTokens)*p.Output) / 1_000_000
+}
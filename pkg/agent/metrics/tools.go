@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxReportError bounds the error excerpt in a tool report line
+const maxReportError = 120
+
+// ToolStat counts the calls of a tool, or of a tool on one target such as a
+// file path
+type ToolStat struct {
+	Tool      string
+	Target    string // Empty for a tool's totals
+	Calls     int
+	Failures  int
+	LastError string // Error of the most recent failed call
+}
+
+// toolKey identifies a tool's calls on one target
+type toolKey struct {
+	tool, target string
+}
+
+// RecordToolCall counts a call of tool on target (a file path or command, or
+// empty) and whether it failed with err
+func (c *Collector) RecordToolCall(tool, target string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tools == nil {
+		c.tools = make(map[toolKey]*ToolStat)
+	}
+	keys := []toolKey{{tool: tool}}
+	if target != "" {
+		keys = append(keys, toolKey{tool, target})
+	}
+	for _, key := range keys {
+		stat, ok := c.tools[key]
+		if !ok {
+			stat = &ToolStat{Tool: key.tool, Target: key.target}
+			c.tools[key] = stat
+		}
+		stat.Calls++
+		if err != nil {
+			stat.Failures++
+			stat.LastError = err.Error()
+		}
+	}
+}
+
+// ToolStats returns each tool's totals, by name
+func (c *Collector) ToolStats() []ToolStat {
+	return c.toolStats(func(s *ToolStat) bool { return s.Target == "" })
+}
+
+// TargetStats returns the calls of tool on each target, most failures first
+func (c *Collector) TargetStats(tool string) []ToolStat {
+	stats := c.toolStats(func(s *ToolStat) bool { return s.Tool == tool && s.Target != "" })
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Failures > stats[j].Failures })
+	return stats
+}
+
+// toolStats returns copies of the stats keep accepts, by tool and target
+func (c *Collector) toolStats(keep func(*ToolStat) bool) []ToolStat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var stats []ToolStat
+	for _, stat := range c.tools {
+		if keep(stat) {
+			stats = append(stats, *stat)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Tool != stats[j].Tool {
+			return stats[i].Tool < stats[j].Tool
+		}
+		return stats[i].Target < stats[j].Target
+	})
+	return stats
+}
+
+// ToolReport renders the session's tool results as a compact block for the
+// model, e.g. "apply_diff: 3 of 7 calls failed (3 on parser.go; last error:
+// ...)". Tools with failures get a line each, up to maxLines, naming the
+// target that failed most; the rest share one line. It returns "" before any
+// tool has been called.
+func (c *Collector) ToolReport(maxLines int) string {
+	stats := c.ToolStats()
+	if len(stats) == 0 {
+		return ""
+	}
+
+	var failing []ToolStat
+	var clean []string
+	for _, stat := range stats {
+		if stat.Failures > 0 {
+			failing = append(failing, stat)
+		} else {
+			clean = append(clean, fmt.Sprintf("%s (%d)", stat.Tool, stat.Calls))
+		}
+	}
+	sort.SliceStable(failing, func(i, j int) bool { return failing[i].Failures > failing[j].Failures })
+
+	var b strings.Builder
+	for i, stat := range failing {
+		if i == maxLines {
+			fmt.Fprintf(&b, "- Other tools with failures: %d\n", len(failing)-maxLines)
+			break
+		}
+		fmt.Fprintf(&b, "- %s: %d of %d calls failed", stat.Tool, stat.Failures, stat.Calls)
+		var details []string
+		if targets := c.TargetStats(stat.Tool); len(targets) > 0 && targets[0].Failures > 0 {
+			details = append(details, fmt.Sprintf("%d on %s", targets[0].Failures, targets[0].Target))
+		}
+		details = append(details, "last error: "+excerpt(stat.LastError))
+		fmt.Fprintf(&b, " (%s)\n", strings.Join(details, "; "))
+	}
+	if len(clean) > 0 {
+		fmt.Fprintf(&b, "- No failures: %s\n", strings.Join(clean, ", "))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// excerpt collapses an error message to one bounded line
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxReportError {
+		text = string(runes[:maxReportError]) + "…"
+	}
+	return text
+}
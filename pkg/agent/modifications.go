@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// WithModificationTracker records the files the agent's file tools change,
+// so session_changes and /commit can tell them apart from other changes
+func WithModificationTracker(tracker *git.ModificationTracker) AgentOption {
+	return func(a *DefaultAgent) {
+		a.tracker = tracker
+	}
+}
+
+// trackModification records a successful file tool call in the tracker. A
+// tool that writes several files, without a path argument, is tracked by the
+// diffs in its result.
+func (a *DefaultAgent) trackModification(tool tools.Tool, toolCall tools.ToolCall, result *tools.Result) {
+	if a.tracker == nil {
+		return
+	}
+	if _, mutating := tool.(tools.Previewable); !mutating {
+		return
+	}
+
+	var args turnToolArgs
+	_ = tools.UnmarshalXMLWithFallback(toolCall.GetArgumentsXML(), &args)
+	if args.Path == "" {
+		if result == nil {
+			return
+		}
+		for _, artifact := range result.Artifacts {
+			if artifact.Kind == tools.ArtifactDiff {
+				a.tracker.Track(artifact.Path, git.OpDiff)
+			}
+		}
+		return
+	}
+
+	operation := git.OpWrite
+	if toolCall.ToolName == "apply_diff" {
+		operation = git.OpDiff
+	}
+	a.tracker.Track(args.Path, operation)
+}
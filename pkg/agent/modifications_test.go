@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+func TestTrackModification(t *testing.T) {
+	tracker := git.NewModificationTracker(t.TempDir())
+	a, _ := newRunnerTestAgent(WithModificationTracker(tracker))
+	write := &summaryWriteTool{summaryTestTool{name: "write_file"}}
+	docs := &summaryWriteTool{summaryTestTool{name: "generate_docs"}}
+	read := &summaryTestTool{name: "read_file"}
+
+	a.trackModification(write, toolCallWithArgs("write_file", "<path>main.go</path>"), nil)
+	a.trackModification(read, toolCallWithArgs("read_file", "<path>read.go</path>"), nil)
+
+	// A tool writing several files is tracked by the diffs in its result
+	a.trackModification(docs, toolCallWithArgs("generate_docs", "<docs/>"), &tools.Result{Artifacts: []tools.Artifact{
+		{Kind: tools.ArtifactDiff, Path: "cache/cache.go"},
+		{Kind: tools.ArtifactFile, Path: "cache/other.go"},
+		{Kind: tools.ArtifactDiff, Path: "cache/README.md"},
+	}})
+
+	want := []string{"cache/README.md", "cache/cache.go", "main.go"}
+	if got := tracker.GetModified(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v tracked, got %v", want, got)
+	}
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/agent/notify"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// notifyTimeout bounds how long the end of a turn waits for a notification to post
+const notifyTimeout = 10 * time.Second
+
+// WithNotifier posts a summary of each completed task (task, result, files
+// changed, cost and duration) when the agent calls task_completion
+func WithNotifier(notifier *notify.Notifier) AgentOption {
+	return func(a *DefaultAgent) {
+		a.notifier = notifier
+	}
+}
+
+// turnCost returns the model and the current turn's cost, and whether the
+// model has a known price
+func (a *DefaultAgent) turnCost() (string, float64, bool) {
+	info := a.provider.GetModelInfo()
+	if info == nil {
+		return "", 0, false
+	}
+	price, ok := metrics.LookupPrice(metrics.DefaultPrices, info.Name)
+	if !ok {
+		return info.Name, 0, false
+	}
+	return info.Name, price.Cost(a.turn.promptTokens, a.turn.cachedTokens, a.turn.completionTokens), true
+}
+
+// notifyCompletion posts the current turn's summary if it completed a task.
+// Failures are reported as error events and never fail the turn.
+func (a *DefaultAgent) notifyCompletion(ctx context.Context) {
+	if a.notifier == nil || a.turn == nil || a.turn.result == "" {
+		return
+	}
+
+	summary := notify.Summary{
+		Task:             a.turn.request,
+		Result:           a.turn.result,
+		FilesChanged:     append([]string{}, a.turn.modified...),
+		PromptTokens:     a.turn.promptTokens,
+		CompletionTokens: a.turn.completionTokens,
+		DurationSeconds:  time.Since(a.turn.start).Seconds(),
+	}
+	summary.Model, summary.CostUSD, _ = a.turnCost()
+
+	notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+	if err := a.notifier.Notify(notifyCtx, summary); err != nil {
+		a.emitEvent(types.NewErrorEvent(fmt.Errorf("task notification failed: %w", err)))
+	}
+}
@@ -0,0 +1,188 @@
+// Package notify posts summaries of completed agent tasks to a Slack incoming
+// webhook or a generic webhook URL, so teams can follow agent activity across
+// repositories.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Payload formats
+const (
+	FormatAuto  = "auto"  // Slack for hooks.slack.com URLs, JSON otherwise
+	FormatSlack = "slack" // {"text": "..."} for Slack incoming webhooks
+	FormatJSON  = "json"  // The Summary as a JSON object
+)
+
+const (
+	// defaultTimeout bounds how long a notification may take to post
+	defaultTimeout = 10 * time.Second
+
+	// maxListedFiles bounds how many changed files a Slack message lists
+	maxListedFiles = 15
+)
+
+// Summary describes a completed task
+type Summary struct {
+	Project          string   `json:"project"`
+	Workspace        string   `json:"workspace"`
+	Task             string   `json:"task"`
+	Result           string   `json:"result"`
+	FilesChanged     []string `json:"files_changed"`
+	Model            string   `json:"model,omitempty"`
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	CostUSD          float64  `json:"cost_usd,omitempty"` // Estimate; omitted when the model's price is unknown
+	DurationSeconds  float64  `json:"duration_seconds"`
+}
+
+// Notifier posts task summaries to a webhook
+type Notifier struct {
+	url       string
+	format    string
+	client    *http.Client
+	workspace string
+}
+
+// Option configures a Notifier
+type Option func(*Notifier)
+
+// WithFormat sets the payload format (FormatAuto, FormatSlack or FormatJSON)
+func WithFormat(format string) Option {
+	return func(n *Notifier) {
+		if format != "" {
+			n.format = format
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to post notifications
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// WithWorkspace sets the workspace reported in summaries that don't name one;
+// its directory name is the project name
+func WithWorkspace(workspace string) Option {
+	return func(n *Notifier) {
+		n.workspace = workspace
+	}
+}
+
+// New creates a notifier that posts to webhookURL
+func New(webhookURL string, opts ...Option) *Notifier {
+	n := &Notifier{
+		url:    webhookURL,
+		format: FormatAuto,
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Notify posts summary to the webhook
+func (n *Notifier) Notify(ctx context.Context, summary Summary) error {
+	if summary.Workspace == "" {
+		summary.Workspace = n.workspace
+	}
+	if summary.Project == "" && summary.Workspace != "" {
+		summary.Project = filepath.Base(summary.Workspace)
+	}
+
+	var body []byte
+	var err error
+	if n.slack() {
+		body, err = json.Marshal(map[string]string{"text": summary.SlackText()})
+	} else {
+		body, err = json.Marshal(summary)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "forge")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification webhook returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// slack reports whether payloads use the Slack message format
+func (n *Notifier) slack() bool {
+	if n.format == FormatAuto {
+		return strings.Contains(n.url, "hooks.slack.com")
+	}
+	return n.format == FormatSlack
+}
+
+// SlackText renders the summary as a Slack mrkdwn message
+func (s Summary) SlackText() string {
+	var b strings.Builder
+	if s.Project != "" {
+		fmt.Fprintf(&b, ":white_check_mark: *Forge completed a task in %s*\n", s.Project)
+	} else {
+		b.WriteString(":white_check_mark: *Forge completed a task*\n")
+	}
+	fmt.Fprintf(&b, "*Task:* %s\n", excerpt(s.Task, 300))
+	if s.Result != "" {
+		fmt.Fprintf(&b, "*Result:* %s\n", excerpt(s.Result, 500))
+	}
+
+	if len(s.FilesChanged) > 0 {
+		fmt.Fprintf(&b, "*Files changed (%d):*", len(s.FilesChanged))
+		for i, file := range s.FilesChanged {
+			if i == maxListedFiles {
+				fmt.Fprintf(&b, " … and %d more", len(s.FilesChanged)-maxListedFiles)
+				break
+			}
+			fmt.Fprintf(&b, " `%s`", file)
+		}
+		b.WriteString("\n")
+	}
+
+	stats := []string{time.Duration(s.DurationSeconds * float64(time.Second)).Round(time.Second).String()}
+	if s.CostUSD > 0 {
+		stats = append(stats, fmt.Sprintf("~$%.2f", s.CostUSD))
+	}
+	stats = append(stats, fmt.Sprintf("%d tokens", s.PromptTokens+s.CompletionTokens))
+	if s.Model != "" {
+		stats = append(stats, s.Model)
+	}
+	b.WriteString(strings.Join(stats, " · "))
+
+	return b.String()
+}
+
+// excerpt collapses whitespace and truncates s to max bytes
+func excerpt(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "…"
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newWebhook serves a webhook that records request bodies and answers with status
+func newWebhook(t *testing.T, status int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("invalid_token"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+var testSummary = Summary{
+	Task:             "Fix the flaky parser test",
+	Result:           "Fixed the race in the tokenizer",
+	FilesChanged:     []string{"parser.go", "parser_test.go"},
+	Model:            "gpt-4o",
+	PromptTokens:     1200,
+	CompletionTokens: 300,
+	CostUSD:          0.006,
+	DurationSeconds:  95,
+}
+
+func TestNotify_JSON(t *testing.T) {
+	server, bodies := newWebhook(t, http.StatusOK)
+	notifier := New(server.URL, WithWorkspace("/src/forge"))
+
+	if err := notifier.Notify(context.Background(), testSummary); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	var got Summary
+	if err := json.Unmarshal([]byte((*bodies)[0]), &got); err != nil {
+		t.Fatalf("expected a JSON summary, got %q", (*bodies)[0])
+	}
+	if got.Project != "forge" || got.Workspace != "/src/forge" {
+		t.Errorf("expected the project from the workspace, got %q in %q", got.Project, got.Workspace)
+	}
+	if got.Task != testSummary.Task || len(got.FilesChanged) != 2 || got.CostUSD != testSummary.CostUSD {
+		t.Errorf("unexpected summary: %+v", got)
+	}
+}
+
+func TestNotify_Slack(t *testing.T) {
+	server, bodies := newWebhook(t, http.StatusOK)
+	notifier := New(server.URL, WithFormat(FormatSlack), WithWorkspace("/src/forge"))
+
+	if err := notifier.Notify(context.Background(), testSummary); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	var message struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte((*bodies)[0]), &message); err != nil {
+		t.Fatalf("expected a Slack message, got %q", (*bodies)[0])
+	}
+	for _, want := range []string{"task in forge", "Fix the flaky parser test", "`parser_test.go`", "1m35s", "~$0.01", "1500 tokens", "gpt-4o"} {
+		if !strings.Contains(message.Text, want) {
+			t.Errorf("expected message to contain %q, got:\n%s", want, message.Text)
+		}
+	}
+}
+
+func TestNotify_AutoDetectsSlack(t *testing.T) {
+	if !New("https://hooks.slack.com/services/T000/B000/XXX").slack() {
+		t.Error("expected Slack webhooks to use the Slack format")
+	}
+	if New("https://example.com/forge").slack() {
+		t.Error("expected other webhooks to use the JSON format")
+	}
+}
+
+func TestNotify_ErrorStatus(t *testing.T) {
+	server, _ := newWebhook(t, http.StatusForbidden)
+
+	err := New(server.URL).Notify(context.Background(), testSummary)
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("expected the status and response in the error, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/notify"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestNotifyCompletion(t *testing.T) {
+	posted := make(chan notify.Summary, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var summary notify.Summary
+		_ = json.Unmarshal(body, &summary)
+		posted <- summary
+	}))
+	defer server.Close()
+
+	a, _ := newRunnerTestAgent(WithNotifier(notify.New(server.URL)))
+	writeTool := &summaryWriteTool{summaryTestTool{name: "write_file"}}
+	completion := &summaryTestTool{name: "task_completion", loopBreaking: true}
+
+	// A turn that only converses is not a completed task
+	a.turn = newTurnRecord("hi")
+	a.notifyCompletion(context.Background())
+	if len(posted) != 0 {
+		t.Fatal("expected no notification without task_completion")
+	}
+
+	a.turn = newTurnRecord("Add a README")
+	a.turn.recordUsage(&types.TokenUsage{PromptTokens: 100, CompletionTokens: 20})
+	a.turn.recordTool(writeTool, toolCallWithArgs("write_file", "<path>README.md</path><content>x</content>"), nil)
+	a.turn.recordTool(completion, toolCallWithArgs("task_completion", "<result>Added the README</result>"), nil)
+	a.notifyCompletion(context.Background())
+
+	summary := <-posted
+	if summary.Task != "Add a README" || summary.Result != "Added the README" {
+		t.Errorf("unexpected task summary: %+v", summary)
+	}
+	if len(summary.FilesChanged) != 1 || summary.FilesChanged[0] != "README.md" {
+		t.Errorf("expected README.md as the changed file, got %v", summary.FilesChanged)
+	}
+	if summary.PromptTokens != 100 || summary.CompletionTokens != 20 {
+		t.Errorf("expected the turn's token usage, got %d/%d", summary.PromptTokens, summary.CompletionTokens)
+	}
+}
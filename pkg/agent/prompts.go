@@ -4,15 +4,69 @@ import (
 	"github.com/entrhq/forge/pkg/agent/prompts"
 )
 
-// buildSystemPrompt constructs the system prompt with tool schemas and custom instructions
-func (a *DefaultAgent) buildSystemPrompt() string {
-	builder := prompts.NewPromptBuilder().
-		WithTools(a.getToolsList())
+// newPromptBuilder creates a prompt builder configured with the agent's base prompt
+// (pinned version or full override) and custom instructions
+func (a *DefaultAgent) newPromptBuilder() *prompts.PromptBuilder {
+	builder := prompts.NewPromptBuilder()
+
+	if a.basePromptOverride != "" {
+		builder.WithBasePromptOverride(a.basePromptOverride)
+	} else if a.basePrompt != nil {
+		builder.WithBasePrompt(a.basePrompt)
+	}
 
 	// Add user's custom instructions if provided
 	if a.customInstructions != "" {
 		builder.WithCustomInstructions(a.customInstructions)
 	}
 
-	return builder.Build()
+	a.roleMu.RLock()
+	if a.roleName != "" {
+		builder.WithRole(a.roleName, a.roleInstructions)
+	}
+	a.roleMu.RUnlock()
+
+	if a.environment != "" {
+		builder.WithEnvironment(a.environment)
+	}
+
+	return builder
+}
+
+// SetRole gives the agent a role, such as the architect or reviewer of a
+// workflow step: its instructions are added to the system prompt until the
+// role is changed. Calling it with an empty name clears the role.
+func (a *DefaultAgent) SetRole(name, instructions string) {
+	a.roleMu.Lock()
+	defer a.roleMu.Unlock()
+	a.roleName = name
+	a.roleInstructions = instructions
+}
+
+// buildSystemPrompt constructs the system prompt with tool schemas and custom instructions
+func (a *DefaultAgent) buildSystemPrompt() string {
+	return a.newPromptBuilder().
+		WithTools(a.getToolsList()).
+		WithToolStats(a.toolStatsReport).
+		Build()
+}
+
+// SystemPrompt returns the system prompt the model is sent, without the
+// per-turn tool stats
+func (a *DefaultAgent) SystemPrompt() string {
+	return a.newPromptBuilder().
+		WithTools(a.getToolsList()).
+		Build()
+}
+
+// BasePromptVersion returns the version of the base system prompt in use.
+// Returns prompts.CustomBasePromptVersion when the base prompt has been replaced entirely.
+func (a *DefaultAgent) BasePromptVersion() string {
+	if a.basePromptOverride != "" {
+		return prompts.CustomBasePromptVersion
+	}
+	if a.basePrompt != nil {
+		return a.basePrompt.Version
+	}
+	return prompts.LatestBasePromptVersion
 }
@@ -12,6 +12,12 @@ import (
 type PromptBuilder struct {
 	tools              []tools.Tool
 	customInstructions string
+	roleName           string
+	roleInstructions   string
+	basePrompt         *BasePrompt
+	baseOverride       string
+	environment        string
+	toolStats          string
 }
 
 // NewPromptBuilder creates a new prompt builder with default settings
@@ -34,6 +40,44 @@ func (pb *PromptBuilder) WithCustomInstructions(instructions string) *PromptBuil
 	return pb
 }
 
+// WithRole gives the agent a role, such as a workflow stage's architect or
+// reviewer, whose instructions follow the custom instructions
+func (pb *PromptBuilder) WithRole(name, instructions string) *PromptBuilder {
+	pb.roleName = name
+	pb.roleInstructions = instructions
+	return pb
+}
+
+// WithBasePrompt pins the base system prompt to a specific version.
+// Without it the builder uses LatestBasePromptVersion.
+func (pb *PromptBuilder) WithBasePrompt(basePrompt *BasePrompt) *PromptBuilder {
+	pb.basePrompt = basePrompt
+	return pb
+}
+
+// WithBasePromptOverride replaces the built-in base system prompt entirely.
+// Custom instructions and the available tools section are still included.
+func (pb *PromptBuilder) WithBasePromptOverride(prompt string) *PromptBuilder {
+	pb.baseOverride = prompt
+	return pb
+}
+
+// WithEnvironment adds the environment the session runs in, such as
+// environment.Environment's Prompt. It stays the same for the session, so
+// it goes before the per-turn tool stats.
+func (pb *PromptBuilder) WithEnvironment(environment string) *PromptBuilder {
+	pb.environment = environment
+	return pb
+}
+
+// WithToolStats adds a report of the session's tool results, so the model
+// can change strategy where it keeps failing. It goes last, so the rest of
+// the prompt stays a stable prefix for provider prompt caching.
+func (pb *PromptBuilder) WithToolStats(report string) *PromptBuilder {
+	pb.toolStats = report
+	return pb
+}
+
 // Build constructs the complete system prompt by assembling all sections
 func (pb *PromptBuilder) Build() string {
 	var builder strings.Builder
@@ -45,33 +89,107 @@ func (pb *PromptBuilder) Build() string {
 		builder.WriteString("\n</custom_instructions>\n\n")
 	}
 
+	if pb.roleName != "" {
+		fmt.Fprintf(&builder, "<agent_role name=%q>\n", pb.roleName)
+		builder.WriteString(RoleIntro)
+		builder.WriteString("\n\n")
+		builder.WriteString(strings.TrimSpace(pb.roleInstructions))
+		builder.WriteString("\n</agent_role>\n\n")
+	}
+
+	// A full override replaces every built-in section except the tool listing
+	if pb.baseOverride != "" {
+		builder.WriteString(pb.baseOverride)
+		builder.WriteString("\n\n")
+		pb.writeToolsSection(&builder)
+		if pb.environment != "" {
+			pb.writeEnvironment(&builder)
+			builder.WriteString("\n\n")
+		}
+		pb.writeToolStats(&builder)
+		return builder.String()
+	}
+
+	base := pb.basePrompt
+	if base == nil {
+		base = basePrompts[LatestBasePromptVersion]
+	}
+
 	// Add system capabilities
-	builder.WriteString(SystemCapabilitiesPrompt)
+	builder.WriteString(base.SystemCapabilities)
 	builder.WriteString("\n\n")
 
 	// Add agent loop explanation
-	builder.WriteString(AgentLoopPrompt)
+	builder.WriteString(base.AgentLoop)
 	builder.WriteString("\n\n")
 
 	// Add chain of thought instructions
-	builder.WriteString(ChainOfThoughtPrompt)
+	builder.WriteString(base.ChainOfThought)
 	builder.WriteString("\n\n")
 
 	// Add tool calling instructions
-	builder.WriteString(ToolCallingPrompt)
+	builder.WriteString(base.ToolCalling)
 	builder.WriteString("\n\n")
 
 	// Add available tools section
+	pb.writeToolsSection(&builder)
+
+	// Add tool use rules
+	builder.WriteString(base.ToolUseRules)
+
+	// Add untrusted content rules (v2+)
+	if base.UntrustedContent != "" {
+		builder.WriteString("\n\n")
+		builder.WriteString(base.UntrustedContent)
+	}
+
+	// Add multiple tool call rules (v3+)
+	if base.MultipleToolCalls != "" {
+		builder.WriteString("\n\n")
+		builder.WriteString(base.MultipleToolCalls)
+	}
+
+	if pb.environment != "" {
+		builder.WriteString("\n\n")
+		pb.writeEnvironment(&builder)
+	}
+
+	if pb.toolStats != "" {
+		builder.WriteString("\n\n")
+		pb.writeToolStats(&builder)
+	}
+
+	return builder.String()
+}
+
+// writeToolsSection writes the available tools section if any tools are registered
+func (pb *PromptBuilder) writeToolsSection(builder *strings.Builder) {
 	if len(pb.tools) > 0 {
 		builder.WriteString("<available_tools>\n")
 		builder.WriteString(FormatToolSchemas(pb.tools))
 		builder.WriteString("</available_tools>\n\n")
 	}
+}
 
-	// Add tool use rules
-	builder.WriteString(ToolUseRulesPrompt)
+// writeEnvironment writes the environment section
+func (pb *PromptBuilder) writeEnvironment(builder *strings.Builder) {
+	builder.WriteString("<environment>\n")
+	builder.WriteString(EnvironmentIntro)
+	builder.WriteString("\n")
+	builder.WriteString(pb.environment)
+	builder.WriteString("\n</environment>")
+}
 
-	return builder.String()
+// writeToolStats writes the tool results section if a report was given
+func (pb *PromptBuilder) writeToolStats(builder *strings.Builder) {
+	if pb.toolStats == "" {
+		return
+	}
+	builder.WriteString("<session_tool_stats>\n")
+	builder.WriteString(ToolStatsIntro)
+	builder.WriteString("\n")
+	builder.WriteString(pb.toolStats)
+	builder.WriteString("\n</session_tool_stats>")
 }
 
 // BuildMessages creates a complete message list including system prompt and conversation history
@@ -1,7 +1,6 @@
 package prompts
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
@@ -16,6 +15,9 @@ const (
 	ErrorTypeMissingToolName ErrorRecoveryType = "missing_tool_name"
 	ErrorTypeUnknownTool     ErrorRecoveryType = "unknown_tool"
 	ErrorTypeToolExecution   ErrorRecoveryType = "tool_execution"
+	ErrorTypeToolRejected    ErrorRecoveryType = "tool_rejected"
+	ErrorTypeToolBlocked     ErrorRecoveryType = "tool_blocked"
+	ErrorTypeEditConflict    ErrorRecoveryType = "edit_conflict"
 )
 
 // ErrorRecoveryContext contains data needed to build error recovery messages
@@ -25,138 +27,65 @@ type ErrorRecoveryContext struct {
 	ToolName       string
 	Content        string
 	AvailableTools []tools.Tool
+	Target         string // What the rejected tool call would have acted on (e.g. preview title)
+	Feedback       string // User-supplied reason for a rejection, a hook's reason for blocking, or the agent editing a file
 }
 
 // BuildErrorRecoveryMessage creates an error message with recovery instructions
-// based on the error context
+// based on the error context, from the recovery library set with
+// SetRecoveryLibrary or else the built-in templates
 func BuildErrorRecoveryMessage(ctx ErrorRecoveryContext) string {
-	switch ctx.Type {
-	case ErrorTypeNoToolCall:
-		return buildNoToolCallError()
-	case ErrorTypeInvalidXML:
-		return buildParseError(ctx.Error, ctx.Content)
-	case ErrorTypeMissingToolName:
-		return buildMissingToolNameError()
-	case ErrorTypeUnknownTool:
-		return buildUnknownToolError(ctx.ToolName, ctx.AvailableTools)
-	case ErrorTypeToolExecution:
-		return buildToolExecutionError(ctx.ToolName, ctx.Error)
-	default:
-		return fmt.Sprintf("ERROR: An unknown error occurred: %v\n\nPlease try again.", ctx.Error)
-	}
-}
-
-// buildNoToolCallError creates an error message with recovery instructions for missing tool calls
-func buildNoToolCallError() string {
-	return `ERROR: No tool call found in your response.
-
-You MUST use a tool in every response. Available tools include task_completion, ask_question, converse, and any registered custom tools.
-
-CORRECT FORMAT:
-<tool>
-<server_name>local</server_name>
-<tool_name>tool_name_here</tool_name>
-<arguments>
-  <param>value</param>
-</arguments>
-</tool>
-
-Example:
-<tool>
-<server_name>local</server_name>
-<tool_name>task_completion</tool_name>
-<arguments>
-  <result>Task completed successfully</result>
-</arguments>
-</tool>
-
-Please try again with a valid tool call.`
+	return currentRecoveryLibrary().Build(ctx)
 }
 
-// buildParseError creates an error message with recovery instructions for XML parsing errors
-func buildParseError(err error, content string) string {
-	snippet := content
-	if len(snippet) > 300 {
-		snippet = snippet[:300] + "..."
+// closestToolName returns the name most like name, or "" if none is close:
+// within a third of its length in edits (at least two), or sharing a leading
+// or trailing word with it, such as "read" and "read_file"
+func closestToolName(name string, names []string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return ""
 	}
 
-	return fmt.Sprintf(`ERROR: Invalid XML in tool call.
-
-Parse error: %v
-
-Your tool call content: %s
-
-SOLUTION 1 - Use XML Entity Escaping (PREFERRED):
-Escape special characters using standard XML entities:
-  & becomes &amp;
-  < becomes &lt;
-  > becomes &gt;
-  " becomes &quot;
-  ' becomes &apos;
-
-Example:
-<tool>
-<server_name>local</server_name>
-<tool_name>write_to_file</tool_name>
-<arguments>
-  <content>func test() { x := a &amp;&amp; b }</content>
-</arguments>
-</tool>
-
-SOLUTION 2 - Use CDATA (if escaping is complex or fails):
-Wrap complex content in CDATA sections (no escaping needed):
-
-Example:
-<tool>
-<server_name>local</server_name>
-<tool_name>write_to_file</tool_name>
-<arguments>
-  <content><![CDATA[func test() { x := a && b }]]></content>
-</arguments>
-</tool>
-
-Both methods are supported. Try the approach that works best for your content.`, err, snippet)
-}
-
-// buildMissingToolNameError creates an error message for missing tool_name field
-func buildMissingToolNameError() string {
-	return `ERROR: Missing required field "tool_name" in tool call.
-
-The tool_name field is required and must specify which tool to execute.
-
-CORRECT FORMAT:
-<tool>
-<server_name>local</server_name>
-<tool_name>your_tool_here</tool_name>
-<arguments>
-  <param>value</param>
-</arguments>
-</tool>
-
-Please include the tool_name field and try again.`
+	best, bestDistance := "", -1
+	for _, candidate := range names {
+		distance := editDistance(name, strings.ToLower(candidate))
+		if distance > max(2, len(name)/3) && !sharesWord(name, strings.ToLower(candidate)) {
+			continue
+		}
+		if bestDistance < 0 || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
 }
 
-// buildUnknownToolError creates an error message with available tools listed
-func buildUnknownToolError(toolName string, availableTools []tools.Tool) string {
-	var toolNames []string
-	for _, tool := range availableTools {
-		toolNames = append(toolNames, fmt.Sprintf("- %s: %s", tool.Name(), tool.Description()))
+// sharesWord reports whether the shorter name is the first or last
+// underscore-separated word of the longer, e.g. "read" and "read_file"
+func sharesWord(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
 	}
-
-	return fmt.Sprintf(`ERROR: Unknown tool "%s".
-
-Available tools:
-%s
-
-Please use one of the available tools and try again.`, toolName, strings.Join(toolNames, "\n"))
+	return strings.HasPrefix(b, a+"_") || strings.HasSuffix(b, "_"+a)
 }
 
-// buildToolExecutionError creates an error message for tool execution failures
-func buildToolExecutionError(toolName string, err error) string {
-	return fmt.Sprintf(`ERROR: Tool "%s" execution failed.
-
-Error details: %v
-
-Please review the error message, adjust your arguments if needed, and try again.
-If the error persists, consider using a different approach or tool.`, toolName, err)
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
 }
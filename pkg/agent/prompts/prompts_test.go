@@ -1,6 +1,8 @@
 package prompts
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
 	"testing"
 
@@ -118,6 +120,142 @@ func TestPromptBuilder(t *testing.T) {
 			t.Error("should contain custom instructions header")
 		}
 	})
+
+	t.Run("WithRole", func(t *testing.T) {
+		prompt := NewPromptBuilder().
+			WithCustomInstructions("Use tabs.").
+			WithRole("reviewer", "Report problems; don't fix them.\n").
+			Build()
+
+		role := strings.Index(prompt, "<agent_role name=\"reviewer\">")
+		if role < 0 || !strings.Contains(prompt, "Report problems; don't fix them.\n</agent_role>") {
+			t.Fatalf("should contain the role section, got %q", prompt[:200])
+		}
+		if strings.Index(prompt, "</custom_instructions>") > role || strings.Index(prompt, "<system_capabilities>") < role {
+			t.Error("should put the role between the custom instructions and the base prompt")
+		}
+		if strings.Contains(NewPromptBuilder().Build(), "<agent_role") {
+			t.Error("should not contain a role section without a role")
+		}
+	})
+
+	t.Run("WithBasePromptOverride", func(t *testing.T) {
+		override := "You are a terse release bot."
+
+		prompt := NewPromptBuilder().
+			WithTools([]tools.Tool{tools.NewTaskCompletionTool()}).
+			WithBasePromptOverride(override).
+			Build()
+
+		if !strings.Contains(prompt, override) {
+			t.Error("should contain the override prompt")
+		}
+		if strings.Contains(prompt, "<system_capabilities>") {
+			t.Error("override should replace the built-in base prompt")
+		}
+		if !strings.Contains(prompt, "task_completion") {
+			t.Error("override should still list available tools")
+		}
+	})
+
+	t.Run("WithToolStats", func(t *testing.T) {
+		base := NewPromptBuilder().Build()
+		prompt := NewPromptBuilder().WithToolStats("- apply_diff: 3 of 7 calls failed").Build()
+
+		if !strings.HasPrefix(prompt, base) {
+			t.Error("tool stats should follow the rest of the prompt, keeping it a stable prefix")
+		}
+		if !strings.HasSuffix(prompt, "- apply_diff: 3 of 7 calls failed\n</session_tool_stats>") {
+			t.Errorf("expected the prompt to end with the tool stats, got:\n%s", prompt[len(base):])
+		}
+	})
+
+	t.Run("WithEnvironment", func(t *testing.T) {
+		base := NewPromptBuilder().Build()
+		withEnv := NewPromptBuilder().WithEnvironment("- OS: linux/amd64").Build()
+		prompt := NewPromptBuilder().WithEnvironment("- OS: linux/amd64").WithToolStats("- apply_diff: 1 of 2 calls failed").Build()
+
+		if !strings.HasPrefix(withEnv, base) || !strings.HasSuffix(withEnv, "- OS: linux/amd64\n</environment>") {
+			t.Errorf("expected the environment after the base prompt, got:\n%s", withEnv[len(base):])
+		}
+		if !strings.HasPrefix(prompt, withEnv) {
+			t.Error("tool stats should follow the environment, keeping it in the stable prefix")
+		}
+
+		override := NewPromptBuilder().WithBasePromptOverride("You are a release bot.").WithEnvironment("- OS: linux/amd64").Build()
+		if !strings.Contains(override, "<environment>") {
+			t.Error("a base prompt override should keep the environment")
+		}
+	})
+}
+
+func TestGetBasePrompt(t *testing.T) {
+	for _, version := range []string{"", "latest", LatestBasePromptVersion} {
+		bp, err := GetBasePrompt(version)
+		if err != nil {
+			t.Fatalf("GetBasePrompt(%q) error = %v", version, err)
+		}
+		if bp.Version != LatestBasePromptVersion {
+			t.Errorf("GetBasePrompt(%q).Version = %q, want %q", version, bp.Version, LatestBasePromptVersion)
+		}
+	}
+
+	if _, err := GetBasePrompt("v0"); err == nil {
+		t.Error("GetBasePrompt should reject unknown versions")
+	}
+
+	// Pinning the latest version must produce the same prompt as the default
+	bp, _ := GetBasePrompt(LatestBasePromptVersion)
+	if NewPromptBuilder().WithBasePrompt(bp).Build() != NewPromptBuilder().Build() {
+		t.Error("pinned latest version should match the default prompt")
+	}
+}
+
+// TestBasePromptsFrozen fails when the prompt a published version builds
+// changes. Ship the change as a new version instead, and add its hash here.
+func TestBasePromptsFrozen(t *testing.T) {
+	published := map[string]string{
+		"v1": "39503a674e887ca4a3c145e2b8f8524cb1abff1fe1c3589ef89354a398997ceb",
+		"v2": "b1b39d6ceeea2deb0d8e498f60da9e7165fc1cfc08a020bdaee5d380748079ac",
+		"v3": "4d5bc91348f8f40f1d75fad1fc40e059f3d6f5e306e5fb418ce39df05fb632dc",
+	}
+
+	for _, version := range BasePromptVersions() {
+		want, ok := published[version]
+		if !ok {
+			t.Errorf("base prompt %s has no recorded hash; add it to this test", version)
+			continue
+		}
+		bp, _ := GetBasePrompt(version)
+		sum := sha256.Sum256([]byte(NewPromptBuilder().WithBasePrompt(bp).Build()))
+		if got := hex.EncodeToString(sum[:]); got != want {
+			t.Errorf("base prompt %s changed (sha256 %s, want %s); published versions must not be edited", version, got, want)
+		}
+	}
+}
+
+func TestUntrustedContentRulesByVersion(t *testing.T) {
+	v1, _ := GetBasePrompt("v1")
+	if strings.Contains(NewPromptBuilder().WithBasePrompt(v1).Build(), "<untrusted_content_rules>") {
+		t.Error("v1 base prompt must stay unchanged")
+	}
+
+	v2, _ := GetBasePrompt("v2")
+	if !strings.Contains(NewPromptBuilder().WithBasePrompt(v2).Build(), "<untrusted_content_rules>") {
+		t.Error("v2 base prompt should include untrusted content rules")
+	}
+}
+
+func TestMultipleToolCallRulesByVersion(t *testing.T) {
+	v2, _ := GetBasePrompt("v2")
+	if strings.Contains(NewPromptBuilder().WithBasePrompt(v2).Build(), "<multiple_tool_calls>") {
+		t.Error("v2 base prompt must stay unchanged")
+	}
+
+	v3, _ := GetBasePrompt("v3")
+	if !strings.Contains(NewPromptBuilder().WithBasePrompt(v3).Build(), "<multiple_tool_calls>") {
+		t.Error("v3 base prompt should include multiple tool call rules")
+	}
 }
 
 func TestBuildMessages(t *testing.T) {
@@ -0,0 +1,275 @@
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// RecoveryDir is where a workspace's recovery message templates live,
+// relative to its root. The user's own are in the same directory under
+// their home.
+const RecoveryDir = ".forge/recovery"
+
+// maxRecoveryContent bounds the part of a response quoted in invalid_xml
+const maxRecoveryContent = 300
+
+//go:embed recovery/*.tmpl
+var builtinRecoveryFS embed.FS
+
+// builtinRecovery holds the built-in recovery templates by error type
+var builtinRecovery = func() map[ErrorRecoveryType]*template.Template {
+	entries, err := builtinRecoveryFS.ReadDir("recovery")
+	if err != nil {
+		panic(err)
+	}
+	templates := make(map[ErrorRecoveryType]*template.Template, len(entries))
+	for _, entry := range entries {
+		data, err := builtinRecoveryFS.ReadFile("recovery/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		errType := ErrorRecoveryType(strings.TrimSuffix(entry.Name(), ".tmpl"))
+		templates[errType] = template.Must(parseRecoveryTemplate(entry.Name(), string(data)))
+	}
+	return templates
+}()
+
+// RecoveryTypes returns the error types that have a recovery template, in
+// name order
+func RecoveryTypes() []ErrorRecoveryType {
+	errTypes := make([]ErrorRecoveryType, 0, len(builtinRecovery))
+	for errType := range builtinRecovery {
+		errTypes = append(errTypes, errType)
+	}
+	sort.Slice(errTypes, func(i, j int) bool { return errTypes[i] < errTypes[j] })
+	return errTypes
+}
+
+// BuiltinRecoveryTemplate returns the built-in template for errType, to
+// start an override from
+func BuiltinRecoveryTemplate(errType ErrorRecoveryType) (string, bool) {
+	data, err := builtinRecoveryFS.ReadFile("recovery/" + string(errType) + ".tmpl")
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// RecoveryData is what recovery templates are rendered with. Fields that
+// don't apply to an error type are empty.
+type RecoveryData struct {
+	Type       string
+	ToolName   string
+	Error      string         // The error, for invalid_xml, tool_execution and edit_conflict
+	ErrorKind  string         // For tool_execution: path_outside_workspace, search_not_found, timeout, or empty
+	Content    string         // For invalid_xml: the start of the response that failed to parse
+	Tools      []RecoveryTool // For unknown_tool: the tools the model may call
+	Suggestion string         // For unknown_tool: the tool whose name is closest to ToolName, if any is close
+	Target     string         // For tool_rejected: what the call would have acted on
+	Feedback   string         // The user's reason for a rejection, a hook's reason for blocking, or who else is editing the file
+}
+
+// RecoveryTool is a tool listed in RecoveryData
+type RecoveryTool struct {
+	Name        string
+	Description string
+}
+
+// newRecoveryData returns the template data for ctx
+func newRecoveryData(ctx ErrorRecoveryContext) RecoveryData {
+	data := RecoveryData{
+		Type:     string(ctx.Type),
+		ToolName: ctx.ToolName,
+		Error:    fmt.Sprint(ctx.Error),
+		Content:  ctx.Content,
+		Target:   ctx.Target,
+		Feedback: ctx.Feedback,
+	}
+	if len(data.Content) > maxRecoveryContent {
+		data.Content = data.Content[:maxRecoveryContent] + "..."
+	}
+
+	switch {
+	case errors.Is(ctx.Error, types.ErrPathOutsideWorkspace):
+		data.ErrorKind = "path_outside_workspace"
+	case errors.Is(ctx.Error, types.ErrSearchNotFound):
+		data.ErrorKind = "search_not_found"
+	case errors.Is(ctx.Error, types.ErrToolTimeout):
+		data.ErrorKind = "timeout"
+	}
+
+	if ctx.Type == ErrorTypeUnknownTool {
+		names := make([]string, 0, len(ctx.AvailableTools))
+		for _, tool := range ctx.AvailableTools {
+			data.Tools = append(data.Tools, RecoveryTool{Name: tool.Name(), Description: tool.Description()})
+			names = append(names, tool.Name())
+		}
+		data.Suggestion = closestToolName(ctx.ToolName, names)
+	}
+	return data
+}
+
+// RecoveryLibrary renders error recovery messages from templates, one per
+// error type. A template file named after the type, such as
+// invalid_xml.tmpl, in one of the library's directories overrides the
+// built-in one, so recovery guidance can be tuned for a model. Files are
+// checked each time a message is built, so edits apply without a restart.
+// It is safe for concurrent use.
+type RecoveryLibrary struct {
+	dirs []string
+
+	mu     sync.Mutex
+	loaded map[string]*loadedTemplate // Override file → its parsed template
+}
+
+// loadedTemplate is an override as of when its file was last read
+type loadedTemplate struct {
+	modTime time.Time
+	size    int64
+	tmpl    *template.Template
+	err     error // Why the file could not be used
+}
+
+// NewRecoveryLibrary returns a library that reads overrides from dirs, the
+// first taking precedence. Without dirs it renders the built-in templates.
+func NewRecoveryLibrary(dirs ...string) *RecoveryLibrary {
+	return &RecoveryLibrary{dirs: dirs, loaded: make(map[string]*loadedTemplate)}
+}
+
+// Build renders the recovery message for ctx. An override that fails to
+// parse or render is passed over for the next one, and then the built-in
+// template.
+func (l *RecoveryLibrary) Build(ctx ErrorRecoveryContext) string {
+	data := newRecoveryData(ctx)
+	for _, path := range l.overrides(ctx.Type) {
+		if loaded := l.load(path); loaded.err == nil {
+			if msg, err := renderRecovery(loaded.tmpl, data); err == nil {
+				return msg
+			}
+		}
+	}
+	if tmpl, ok := builtinRecovery[ctx.Type]; ok {
+		if msg, err := renderRecovery(tmpl, data); err == nil {
+			return msg
+		}
+	}
+	return fmt.Sprintf("ERROR: An unknown error occurred: %v\n\nPlease try again.", ctx.Error)
+}
+
+// Check returns the override files in use by error type, and an error for
+// each that can't be parsed or rendered and is passed over
+func (l *RecoveryLibrary) Check() (map[ErrorRecoveryType]string, []error) {
+	sample := ErrorRecoveryContext{
+		ToolName:       "read_file",
+		Error:          errors.New("sample error"),
+		Content:        "<tool>",
+		AvailableTools: []tools.Tool{tools.NewTaskCompletionTool()},
+		Target:         "main.go",
+		Feedback:       "sample feedback",
+	}
+
+	inUse := make(map[ErrorRecoveryType]string)
+	var errs []error
+	for _, errType := range RecoveryTypes() {
+		sample.Type = errType
+		for _, path := range l.overrides(errType) {
+			loaded := l.load(path)
+			err := loaded.err
+			if err == nil {
+				_, err = renderRecovery(loaded.tmpl, newRecoveryData(sample))
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			inUse[errType] = path
+			break
+		}
+	}
+	return inUse, errs
+}
+
+// overrides returns the override files for errType that exist, in order of
+// precedence
+func (l *RecoveryLibrary) overrides(errType ErrorRecoveryType) []string {
+	var paths []string
+	for _, dir := range l.dirs {
+		path := filepath.Join(dir, string(errType)+".tmpl")
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// load returns the template in path, reading it again if it changed since
+// it was last read
+func (l *RecoveryLibrary) load(path string) *loadedTemplate {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		delete(l.loaded, path)
+		return &loadedTemplate{err: err}
+	}
+	if loaded, ok := l.loaded[path]; ok && loaded.modTime.Equal(info.ModTime()) && loaded.size == info.Size() {
+		return loaded
+	}
+
+	loaded := &loadedTemplate{modTime: info.ModTime(), size: info.Size()}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		loaded.tmpl, err = parseRecoveryTemplate(filepath.Base(path), string(data))
+	}
+	loaded.err = err
+	l.loaded[path] = loaded
+	return loaded
+}
+
+// parseRecoveryTemplate parses a recovery template. Fields that don't exist
+// are errors, so a typo fails Check instead of rendering as empty.
+func parseRecoveryTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// renderRecovery renders tmpl with data, without surrounding blank lines
+func renderRecovery(tmpl *template.Template, data RecoveryData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+var (
+	recoveryMu      sync.RWMutex
+	recoveryLibrary = NewRecoveryLibrary()
+)
+
+// SetRecoveryLibrary sets the library BuildErrorRecoveryMessage renders
+// with, such as one reading overrides from the workspace's RecoveryDir
+func SetRecoveryLibrary(l *RecoveryLibrary) {
+	recoveryMu.Lock()
+	defer recoveryMu.Unlock()
+	recoveryLibrary = l
+}
+
+// currentRecoveryLibrary returns the library set with SetRecoveryLibrary
+func currentRecoveryLibrary() *RecoveryLibrary {
+	recoveryMu.RLock()
+	defer recoveryMu.RUnlock()
+	return recoveryLibrary
+}
@@ -0,0 +1,104 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// writeTemplate writes an override template and moves its modification
+// time forward, so a rewrite within the file system's timestamp resolution
+// still reads as a change
+func writeTemplate(t *testing.T, dir string, errType ErrorRecoveryType, text string) string {
+	t.Helper()
+	path := filepath.Join(dir, string(errType)+".tmpl")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Duration(len(text)) * time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecoveryLibrary_Builtin(t *testing.T) {
+	lib := NewRecoveryLibrary(t.TempDir())
+	msg := lib.Build(ErrorRecoveryContext{
+		Type:     ErrorTypeToolExecution,
+		ToolName: "apply_diff",
+		Error:    fmt.Errorf("no match: %w", types.ErrSearchNotFound),
+	})
+	if !strings.HasPrefix(msg, `ERROR: Tool "apply_diff" execution failed.`) || !strings.HasSuffix(msg, "unless the text you want to change isn't shown.") {
+		t.Errorf("expected the built-in search guidance, got %q", msg)
+	}
+
+	for _, errType := range RecoveryTypes() {
+		if text, ok := BuiltinRecoveryTemplate(errType); !ok || text == "" {
+			t.Errorf("expected a built-in template for %s", errType)
+		}
+	}
+	if len(RecoveryTypes()) != 8 {
+		t.Errorf("expected a template per error type, got %v", RecoveryTypes())
+	}
+}
+
+func TestRecoveryLibrary_Overrides(t *testing.T) {
+	model, workspace := t.TempDir(), t.TempDir()
+	lib := NewRecoveryLibrary(model, workspace)
+	ctx := ErrorRecoveryContext{Type: ErrorTypeInvalidXML, Error: errors.New("unexpected EOF"), Content: "<tool>"}
+
+	writeTemplate(t, workspace, ErrorTypeInvalidXML, "Fix your XML ({{.Error}}) in {{.Content}}\n")
+	if got := lib.Build(ctx); got != "Fix your XML (unexpected EOF) in <tool>" {
+		t.Errorf("expected the workspace override, got %q", got)
+	}
+
+	// An earlier directory takes precedence, and edits apply on the next build
+	path := writeTemplate(t, model, ErrorTypeInvalidXML, "Use CDATA.")
+	if got := lib.Build(ctx); got != "Use CDATA." {
+		t.Errorf("expected the model override, got %q", got)
+	}
+	writeTemplate(t, model, ErrorTypeInvalidXML, "Use CDATA for {{.Error}}.")
+	if got := lib.Build(ctx); got != "Use CDATA for unexpected EOF." {
+		t.Errorf("expected the edited override, got %q", got)
+	}
+
+	// Removing it falls back to the next directory
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := lib.Build(ctx); !strings.HasPrefix(got, "Fix your XML") {
+		t.Errorf("expected the workspace override after removal, got %q", got)
+	}
+
+	// Other types keep the built-in template
+	if got := lib.Build(ErrorRecoveryContext{Type: ErrorTypeMissingToolName}); !strings.Contains(got, `"tool_name"`) {
+		t.Errorf("expected the built-in template for other types, got %q", got)
+	}
+}
+
+func TestRecoveryLibrary_BrokenOverride(t *testing.T) {
+	dir := t.TempDir()
+	lib := NewRecoveryLibrary(dir)
+	writeTemplate(t, dir, ErrorTypeToolBlocked, "Blocked: {{.Reason}}")
+	writeTemplate(t, dir, ErrorTypeNoToolCall, "{{if}}")
+	writeTemplate(t, dir, ErrorTypeToolRejected, "Rejected: {{.Feedback}}")
+
+	if got := lib.Build(ErrorRecoveryContext{Type: ErrorTypeToolBlocked, ToolName: "x", Feedback: "policy"}); !strings.Contains(got, "Hook output: policy") {
+		t.Errorf("expected a template with an unknown field to fall back to the built-in one, got %q", got)
+	}
+
+	inUse, errs := lib.Check()
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "no_tool_call.tmpl") || !strings.Contains(errs[1].Error(), "tool_blocked.tmpl") {
+		t.Errorf("expected the two broken overrides to be reported, got %v", errs)
+	}
+	if len(inUse) != 1 || inUse[ErrorTypeToolRejected] == "" {
+		t.Errorf("expected only the valid override to be in use, got %v", inUse)
+	}
+}
@@ -0,0 +1,83 @@
+package prompts
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LatestBasePromptVersion is the base prompt version used when none is pinned.
+const LatestBasePromptVersion = "v3"
+
+// CustomBasePromptVersion is reported when the base prompt has been replaced entirely.
+const CustomBasePromptVersion = "custom"
+
+// BasePrompt is a versioned snapshot of the built-in base system prompt.
+// Published versions are never edited; behavior changes ship as a new version
+// so users who pin a version keep the exact prompt they tuned against. Each
+// version's text is its own copy in base_<version>.go, and TestBasePromptsFrozen
+// fails if a published version changes.
+type BasePrompt struct {
+	Version            string
+	SystemCapabilities string
+	AgentLoop          string
+	ChainOfThought     string
+	ToolCalling        string
+	ToolUseRules       string
+	UntrustedContent   string // Added in v2
+	MultipleToolCalls  string // Added in v3
+}
+
+// basePrompts holds every published base prompt version keyed by version
+var basePrompts = map[string]*BasePrompt{
+	"v1": {
+		Version:            "v1",
+		SystemCapabilities: v1SystemCapabilities,
+		AgentLoop:          v1AgentLoop,
+		ChainOfThought:     v1ChainOfThought,
+		ToolCalling:        v1ToolCalling,
+		ToolUseRules:       v1ToolUseRules,
+	},
+	"v2": {
+		Version:            "v2",
+		SystemCapabilities: v2SystemCapabilities,
+		AgentLoop:          v2AgentLoop,
+		ChainOfThought:     v2ChainOfThought,
+		ToolCalling:        v2ToolCalling,
+		ToolUseRules:       v2ToolUseRules,
+		UntrustedContent:   v2UntrustedContent,
+	},
+	"v3": {
+		Version:            "v3",
+		SystemCapabilities: v3SystemCapabilities,
+		AgentLoop:          v3AgentLoop,
+		ChainOfThought:     v3ChainOfThought,
+		ToolCalling:        v3ToolCalling,
+		ToolUseRules:       v3ToolUseRules,
+		UntrustedContent:   v3UntrustedContent,
+		MultipleToolCalls:  v3MultipleToolCalls,
+	},
+}
+
+// GetBasePrompt returns the base prompt for the given version.
+// An empty version or "latest" selects LatestBasePromptVersion.
+func GetBasePrompt(version string) (*BasePrompt, error) {
+	if version == "" || version == "latest" {
+		version = LatestBasePromptVersion
+	}
+
+	bp, ok := basePrompts[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown base prompt version %q (available: %v)", version, BasePromptVersions())
+	}
+	return bp, nil
+}
+
+// BasePromptVersions returns all published base prompt versions in sorted order
+func BasePromptVersions() []string {
+	versions := make([]string, 0, len(basePrompts))
+	for v := range basePrompts {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
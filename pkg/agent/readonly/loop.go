@@ -0,0 +1,159 @@
+// Package readonly provides the bounded tool loop behind Forge's analysis
+// commands, such as review and explain. The model may call only the
+// side-effect-free tools it is given, and the loop ends when it calls a
+// submit tool with its answer or runs out of steps.
+package readonly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/injection"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// DefaultMaxSteps bounds the LLM calls of a loop that sets no limit
+const DefaultMaxSteps = 15
+
+// ErrNotSubmitted is returned by Run when the model did not call the submit
+// tool within the step limit
+var ErrNotSubmitted = errors.New("nothing submitted")
+
+// Loop lets a model call read-only tools until it submits its answer
+type Loop struct {
+	provider llm.Provider
+	tools    map[string]tools.Tool
+	toolList []tools.Tool
+	maxSteps int
+	progress func(step int, toolName string)
+}
+
+// Option configures a Loop
+type Option func(*Loop)
+
+// WithTools makes tools available to the model. Only pass tools without
+// side effects (read_file, list_files, search_files).
+func WithTools(toolList ...tools.Tool) Option {
+	return func(l *Loop) {
+		for _, tool := range toolList {
+			l.tools[tool.Name()] = tool
+			l.toolList = append(l.toolList, tool)
+		}
+	}
+}
+
+// WithMaxSteps bounds the LLM calls of each Run.
+func WithMaxSteps(steps int) Option {
+	return func(l *Loop) {
+		if steps > 0 {
+			l.maxSteps = steps
+		}
+	}
+}
+
+// WithProgress sets a function called before each tool the model calls runs.
+func WithProgress(fn func(step int, toolName string)) Option {
+	return func(l *Loop) {
+		l.progress = fn
+	}
+}
+
+// NewLoop creates a loop that uses provider for its LLM calls.
+func NewLoop(provider llm.Provider, opts ...Option) *Loop {
+	l := &Loop{
+		provider: provider,
+		tools:    make(map[string]tools.Tool),
+		maxSteps: DefaultMaxSteps,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Run sends task to the model, with instructions in the system prompt, and
+// runs the tools it calls until it calls submit. The submit tool's Execute
+// receives the arguments and holds the answer for the caller; if it returns
+// an error the model is asked to correct the call. Run returns
+// ErrNotSubmitted, wrapped, if the step limit is reached first.
+func (l *Loop) Run(ctx context.Context, instructions, task string, submit tools.Tool) error {
+	available := append(append([]tools.Tool{}, l.toolList...), submit)
+	systemPrompt := prompts.NewPromptBuilder().
+		WithTools(available).
+		WithCustomInstructions(instructions).
+		Build()
+
+	messages := []*types.Message{
+		types.NewSystemMessage(systemPrompt),
+		types.NewUserMessage(task),
+	}
+
+	for step := 0; step < l.maxSteps; step++ {
+		response, err := l.provider.Complete(ctx, messages)
+		if err != nil {
+			return err
+		}
+		if response == nil {
+			return fmt.Errorf("provider returned no response")
+		}
+		messages = append(messages, types.NewAssistantMessage(response.Content))
+
+		_, toolCall, _, err := tools.ExtractThinkingAndToolCall(response.Content)
+		switch {
+		case err != nil:
+			messages = append(messages, types.NewUserMessage(prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+				Type:    prompts.ErrorTypeInvalidXML,
+				Error:   err,
+				Content: response.Content,
+			})))
+			continue
+		case toolCall == nil:
+			messages = append(messages, types.NewUserMessage(prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+				Type: prompts.ErrorTypeNoToolCall,
+			})))
+			continue
+		}
+
+		if toolCall.ToolName == submit.Name() {
+			if _, err := submit.Execute(ctx, toolCall.GetArgumentsXML()); err != nil {
+				messages = append(messages, types.NewUserMessage(prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+					Type:     prompts.ErrorTypeToolExecution,
+					ToolName: toolCall.ToolName,
+					Error:    err,
+				})))
+				continue
+			}
+			return nil
+		}
+
+		tool, ok := l.tools[toolCall.ToolName]
+		if !ok {
+			messages = append(messages, types.NewUserMessage(prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+				Type:           prompts.ErrorTypeUnknownTool,
+				ToolName:       toolCall.ToolName,
+				AvailableTools: available,
+			})))
+			continue
+		}
+
+		if l.progress != nil {
+			l.progress(step+1, toolCall.ToolName)
+		}
+		output, err := tool.Execute(ctx, toolCall.GetArgumentsXML())
+		if err != nil {
+			messages = append(messages, types.NewUserMessage(prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+				Type:     prompts.ErrorTypeToolExecution,
+				ToolName: toolCall.ToolName,
+				Error:    err,
+			})))
+			continue
+		}
+		messages = append(messages, types.NewUserMessage(fmt.Sprintf("Tool '%s' result:\n%s", toolCall.ToolName, injection.Wrap(toolCall.ToolName, output))))
+	}
+
+	return fmt.Errorf("%w after %d steps", ErrNotSubmitted, l.maxSteps)
+}
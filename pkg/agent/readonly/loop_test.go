@@ -0,0 +1,114 @@
+package readonly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// scriptedProvider returns its responses in order
+type scriptedProvider struct {
+	responses []string
+	calls     [][]*types.Message
+}
+
+func (p *scriptedProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	return nil, nil
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	p.calls = append(p.calls, messages)
+	response := p.responses[0]
+	p.responses = p.responses[1:]
+	return types.NewAssistantMessage(response), nil
+}
+
+func (p *scriptedProvider) GetModelInfo() *types.ModelInfo {
+	return nil
+}
+
+// echoTool is a read-only tool that returns a fixed result
+type echoTool struct{}
+
+func (echoTool) Name() string                                    { return "read_file" }
+func (echoTool) Description() string                             { return "Read a file" }
+func (echoTool) Schema() map[string]interface{}                  { return map[string]interface{}{} }
+func (echoTool) IsLoopBreaking() bool                            { return false }
+func (echoTool) Execute(context.Context, []byte) (string, error) { return "func run() {}", nil }
+
+// submitTool records the answer it is called with, rejecting an empty one
+type submitTool struct {
+	answer string
+}
+
+func (*submitTool) Name() string                   { return "submit_answer" }
+func (*submitTool) Description() string            { return "Submit the answer" }
+func (*submitTool) Schema() map[string]interface{} { return map[string]interface{}{} }
+func (*submitTool) IsLoopBreaking() bool           { return true }
+
+func (t *submitTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	answer := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(string(argsXML), "<arguments>"), "</arguments>"))
+	if answer == "" {
+		return "", fmt.Errorf("answer is required")
+	}
+	t.answer = answer
+	return "Submitted", nil
+}
+
+// call is a tool call in the format the model writes
+func call(name, args string) string {
+	return fmt.Sprintf("<tool><server_name>local</server_name><tool_name>%s</tool_name><arguments>%s</arguments></tool>", name, args)
+}
+
+func TestLoop_Run(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		call("write_file", "<path>main.go</path>"),
+		call("read_file", "<path>main.go</path>"),
+		call("submit_answer", ""),
+		call("submit_answer", "It runs."),
+	}}
+
+	var progress []string
+	loop := NewLoop(provider, WithTools(echoTool{}), WithProgress(func(step int, toolName string) {
+		progress = append(progress, fmt.Sprintf("%d:%s", step, toolName))
+	}))
+	submit := &submitTool{}
+	if err := loop.Run(context.Background(), "Answer the question.", "What does main.go do?", submit); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if submit.answer != "It runs." {
+		t.Errorf("expected the submitted answer, got %q", submit.answer)
+	}
+	if len(provider.calls) != 4 {
+		t.Fatalf("expected 4 LLM calls, got %d", len(provider.calls))
+	}
+	if system := provider.calls[0][0].Content; !strings.Contains(system, "Answer the question.") || !strings.Contains(system, "submit_answer") {
+		t.Errorf("expected the instructions and submit tool in the system prompt, got:\n%s", system)
+	}
+	if last := provider.calls[1][len(provider.calls[1])-1].Content; !strings.Contains(last, "write_file") {
+		t.Errorf("a tool outside the set should get a recovery message, got:\n%s", last)
+	}
+	if last := provider.calls[2][len(provider.calls[2])-1].Content; !strings.Contains(last, "func run() {}") {
+		t.Errorf("the tool result should be sent back, got:\n%s", last)
+	}
+	if last := provider.calls[3][len(provider.calls[3])-1].Content; !strings.Contains(last, "answer is required") {
+		t.Errorf("a rejected submission should get a recovery message, got:\n%s", last)
+	}
+	if len(progress) != 1 || progress[0] != "2:read_file" {
+		t.Errorf("unexpected progress reports %v", progress)
+	}
+}
+
+func TestLoop_GivesUpAfterMaxSteps(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{"thinking", "still thinking"}}
+	err := NewLoop(provider, WithMaxSteps(2)).Run(context.Background(), "", "task", &submitTool{})
+	if !errors.Is(err, ErrNotSubmitted) || !strings.Contains(err.Error(), "after 2 steps") {
+		t.Errorf("expected ErrNotSubmitted after 2 steps, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+)
+
+// maxStatChars bounds the diff stat included in the notes prompt
+const maxStatChars = 4000
+
+// NotesGenerator writes release notes with the model
+type NotesGenerator struct {
+	llmClient git.LLMClient
+}
+
+// NewNotesGenerator creates a release notes generator
+func NewNotesGenerator(llmClient git.LLMClient) *NotesGenerator {
+	return &NotesGenerator{
+		llmClient: llmClient,
+	}
+}
+
+// Generate writes Markdown release notes for the plan's commits
+func (g *NotesGenerator) Generate(ctx context.Context, workingDir string, plan *Plan) (string, error) {
+	statRange := "HEAD"
+	if plan.Previous != "" {
+		statRange = plan.Previous + "..HEAD"
+	}
+	stat, err := git.Output(ctx, workingDir, nil, "diff", "--stat", statRange)
+	if err != nil && plan.Previous != "" {
+		return "", err
+	}
+
+	response, err := g.llmClient.Generate(ctx, buildNotesPrompt(plan, stat))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate release notes: %w", err)
+	}
+
+	notes := strings.TrimSpace(stripFence(response))
+	if notes == "" {
+		return "", fmt.Errorf("failed to generate release notes: the response was empty")
+	}
+	return notes + "\n", nil
+}
+
+// buildNotesPrompt asks for release notes from the grouped commits and the
+// files they changed
+func buildNotesPrompt(plan *Plan, stat string) string {
+	var sb strings.Builder
+
+	previous := "the first commit"
+	if plan.Previous != "" {
+		previous = plan.Previous
+	}
+	sb.WriteString(fmt.Sprintf("Write the release notes for %s, which covers the commits since %s.\n\n", plan.Tag, previous))
+
+	sb.WriteString("Commits, grouped by Conventional Commits type:\n\n")
+	sb.WriteString(plan.Release.Markdown())
+
+	if stat != "" {
+		if len(stat) > maxStatChars {
+			stat = stat[:maxStatChars] + "\n... (truncated)"
+		}
+		sb.WriteString("\nFiles changed:\n")
+		sb.WriteString(stat)
+	}
+
+	sb.WriteString("\n\nWrite for the project's users, not its developers:\n")
+	sb.WriteString("- Start with one or two sentences on the highlights of the release\n")
+	sb.WriteString("- Then use ### sections such as Breaking Changes, New Features and Bug Fixes, leaving out empty ones\n")
+	sb.WriteString("- Describe what changed for the user in one line per change; merge commits that make one change and leave out purely internal ones (tests, CI, refactoring)\n")
+	sb.WriteString("- Give upgrade instructions for every breaking change\n")
+	sb.WriteString("- Don't invent changes the commits don't show\n\n")
+	sb.WriteString("Respond ONLY with the Markdown notes, without a title or code fences.")
+
+	return sb.String()
+}
+
+// stripFence removes a code fence the model wrapped the notes in
+func stripFence(response string) string {
+	trimmed := strings.TrimSpace(response)
+	if !strings.HasPrefix(trimmed, "```") {
+		return response
+	}
+	_, body, ok := strings.Cut(trimmed, "\n")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSpace(body), "```")
+}
@@ -0,0 +1,301 @@
+// Package release cuts a release from the commits since the last tag. It
+// picks the next version from their Conventional Commits types, has the
+// model write release notes, and returns a Plan of steps: commit the
+// changelog update, create an annotated tag and, optionally, push it and
+// create the GitHub release. Each step carries a preview so the caller can
+// ask for approval before running it.
+package release
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/changelog"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/update"
+)
+
+// Version bumps
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// Step names
+const (
+	StepChangelog = "changelog" // Commit the changelog update
+	StepTag       = "tag"       // Create the annotated tag
+	StepGitHub    = "github"    // Push the branch and tag and create the GitHub release
+)
+
+// DefaultRemote is the remote pushed to for a GitHub release
+const DefaultRemote = "origin"
+
+// Options configure Prepare
+type Options struct {
+	Version   string // Version to release, e.g. "v1.2.0"; "" = bumped from the latest tag
+	Bump      string // One of the Bump* values; "" = from the commits
+	Changelog string // Changelog committed before tagging; "" = changelog.DefaultFile
+	NoCommit  bool   // Tag HEAD without updating the changelog
+	GitHub    bool   // Push and create the GitHub release with gh
+	Remote    string // Remote to push to; "" = DefaultRemote
+	Now       time.Time
+}
+
+// Step is one action of a release, run after its preview is approved
+type Step struct {
+	Name    string
+	Title   string // Short description for the approval prompt
+	Preview string // What the step does; a unified diff when IsDiff
+	IsDiff  bool
+	run     func(ctx context.Context) (string, error)
+}
+
+// Run performs the step and returns a one-line description of what it did
+func (s *Step) Run(ctx context.Context) (string, error) {
+	return s.run(ctx)
+}
+
+// Plan is a release ready to be made step by step
+type Plan struct {
+	Tag      string             // Tag to create, e.g. "v1.2.0"
+	Previous string             // Latest tag before it; "" for a first release
+	Release  *changelog.Release // The commits since Previous, grouped by type
+	Notes    string             // Release notes used for the tag and the GitHub release
+	Steps    []*Step
+}
+
+// Prepare builds the release plan for the commits since the latest tag in
+// workingDir. Release notes are written by notes, or taken from the
+// changelog section when notes is nil. Nothing is changed until the plan's
+// steps are run.
+func Prepare(ctx context.Context, workingDir string, notes *NotesGenerator, opts Options) (*Plan, error) {
+	previous := changelog.LatestTag(ctx, workingDir, "HEAD")
+	rel, err := changelog.Collect(ctx, workingDir, changelog.Options{Since: previous, Now: opts.Now})
+	if err != nil {
+		return nil, err
+	}
+	if len(rel.Entries) == 0 {
+		return nil, fmt.Errorf("no commits since %s to release", previous)
+	}
+
+	tag := opts.Version
+	if tag == "" {
+		if tag, err = NextVersion(previous, rel.Entries, opts.Bump); err != nil {
+			return nil, err
+		}
+	} else if !strings.HasPrefix(tag, "v") && (previous == "" || strings.HasPrefix(previous, "v")) {
+		tag = "v" + tag
+	}
+	if _, _, err := parseVersion(tag); err != nil {
+		return nil, err
+	}
+	if _, err := git.Output(ctx, workingDir, nil, "rev-parse", "-q", "--verify", "refs/tags/"+tag); err == nil {
+		return nil, fmt.Errorf("tag %s already exists", tag)
+	}
+	if previous != "" && update.CompareVersions(tag, previous) <= 0 {
+		return nil, fmt.Errorf("version %s is not newer than %s", tag, previous)
+	}
+
+	rel, err = changelog.Collect(ctx, workingDir, changelog.Options{Version: tag, Since: previous, Now: opts.Now})
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Tag: tag, Previous: previous, Release: rel}
+
+	if notes != nil {
+		if plan.Notes, err = notes.Generate(ctx, workingDir, plan); err != nil {
+			return nil, err
+		}
+	} else {
+		plan.Notes = sectionBody(rel.Markdown())
+	}
+
+	if !opts.NoCommit {
+		step, err := changelogStep(ctx, workingDir, plan, opts)
+		if err != nil {
+			return nil, err
+		}
+		if step != nil {
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+	plan.Steps = append(plan.Steps, tagStep(workingDir, plan, !opts.NoCommit && len(plan.Steps) > 0))
+	if opts.GitHub {
+		plan.Steps = append(plan.Steps, gitHubStep(workingDir, plan, opts.Remote))
+	}
+	return plan, nil
+}
+
+// changelogStep returns the step committing the changelog update, or nil
+// when the changelog already lists the release
+func changelogStep(ctx context.Context, workingDir string, plan *Plan, opts Options) (*Step, error) {
+	proposal, err := changelog.Propose(ctx, workingDir, changelog.Options{
+		File:    opts.Changelog,
+		Version: plan.Tag,
+		Since:   plan.Previous,
+		Now:     opts.Now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !proposal.Changed() {
+		return nil, nil
+	}
+
+	file := opts.Changelog
+	if file == "" {
+		file = changelog.DefaultFile
+	}
+	message := "chore(release): " + plan.Tag
+	return &Step{
+		Name:    StepChangelog,
+		Title:   fmt.Sprintf("Commit %s as %q", file, message),
+		Preview: coding.GenerateUnifiedDiff(proposal.Original, proposal.Updated, filepath.ToSlash(file)),
+		IsDiff:  true,
+		run: func(ctx context.Context) (string, error) {
+			if err := proposal.Apply(); err != nil {
+				return "", err
+			}
+			// Commit only the changelog, leaving anything else staged alone
+			if _, err := git.Output(ctx, workingDir, nil, "add", "--", file); err != nil {
+				return "", err
+			}
+			if _, err := git.Output(ctx, workingDir, nil, "commit", "-q", "-m", message, "--", file); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Committed %s", file), nil
+		},
+	}, nil
+}
+
+// tagStep returns the step creating the annotated tag with the notes
+func tagStep(workingDir string, plan *Plan, afterCommit bool) *Step {
+	target := "HEAD"
+	if afterCommit {
+		target = "the changelog commit"
+	}
+	return &Step{
+		Name:    StepTag,
+		Title:   fmt.Sprintf("Tag %s at %s", plan.Tag, target),
+		Preview: fmt.Sprintf("git tag -a %s -F -\n\n%s", plan.Tag, plan.Notes),
+		run: func(ctx context.Context) (string, error) {
+			if _, err := git.Output(ctx, workingDir, strings.NewReader(plan.Tag+"\n\n"+plan.Notes), "tag", "-a", plan.Tag, "-F", "-"); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Tagged %s", plan.Tag), nil
+		},
+	}
+}
+
+// gitHubStep returns the step pushing the branch and tag and creating the
+// GitHub release with gh
+func gitHubStep(workingDir string, plan *Plan, remote string) *Step {
+	if remote == "" {
+		remote = DefaultRemote
+	}
+	return &Step{
+		Name:  StepGitHub,
+		Title: fmt.Sprintf("Push %s to %s and create the GitHub release", plan.Tag, remote),
+		Preview: fmt.Sprintf("git push --atomic %s HEAD %s\ngh release create %s --title %s --notes-file -\n\n%s",
+			remote, plan.Tag, plan.Tag, plan.Tag, plan.Notes),
+		run: func(ctx context.Context) (string, error) {
+			if _, err := git.Output(ctx, workingDir, nil, "push", "--atomic", remote, "HEAD", "refs/tags/"+plan.Tag); err != nil {
+				return "", err
+			}
+
+			cmd := exec.CommandContext(ctx, "gh", "release", "create", plan.Tag, "--title", plan.Tag, "--notes-file", "-")
+			cmd.Dir = workingDir
+			cmd.Stdin = strings.NewReader(plan.Notes)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+			if err := cmd.Run(); err != nil {
+				return "", fmt.Errorf("failed to create GitHub release: %w, stderr: %s", err, stderr.String())
+			}
+			return fmt.Sprintf("Created the GitHub release %s", strings.TrimSpace(stdout.String())), nil
+		},
+	}
+}
+
+// NextVersion returns the version after previous: a major bump for breaking
+// changes, minor for features and patch otherwise, unless bump says which.
+// Before 1.0.0 breaking changes bump the minor version. Without a previous
+// tag the first version is v0.1.0.
+func NextVersion(previous string, entries []changelog.Entry, bump string) (string, error) {
+	if previous == "" {
+		return "v0.1.0", nil
+	}
+	core, prefix, err := parseVersion(previous)
+	if err != nil {
+		return "", fmt.Errorf("latest tag %s: %w; give the version to release", previous, err)
+	}
+
+	if bump == "" {
+		bump = BumpPatch
+		for _, entry := range entries {
+			switch {
+			case entry.Breaking != "":
+				bump = BumpMajor
+			case entry.Type == "feat" && bump == BumpPatch:
+				bump = BumpMinor
+			}
+		}
+		if bump == BumpMajor && core[0] == 0 {
+			bump = BumpMinor
+		}
+	}
+
+	switch bump {
+	case BumpMajor:
+		core = [3]int{core[0] + 1, 0, 0}
+	case BumpMinor:
+		core = [3]int{core[0], core[1] + 1, 0}
+	case BumpPatch:
+		core[2]++
+	default:
+		return "", fmt.Errorf("unknown bump %q: use major, minor or patch", bump)
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, core[0], core[1], core[2]), nil
+}
+
+// parseVersion parses a "v1.2.3" or "1.2.3" version, ignoring any
+// pre-release or build metadata, and returns its numbers and "v" prefix
+func parseVersion(version string) ([3]int, string, error) {
+	var core [3]int
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+	v := strings.TrimPrefix(version, "v")
+	if idx := strings.IndexAny(v, "-+"); idx >= 0 {
+		v = v[:idx]
+	}
+
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return core, prefix, fmt.Errorf("%q is not a semantic version like v1.2.3", version)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return core, prefix, fmt.Errorf("%q is not a semantic version like v1.2.3", version)
+		}
+		core[i] = n
+	}
+	return core, prefix, nil
+}
+
+// sectionBody returns a changelog section without its heading
+func sectionBody(section string) string {
+	_, body, _ := strings.Cut(section, "\n")
+	return strings.TrimSpace(body) + "\n"
+}
@@ -0,0 +1,162 @@
+package release
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/changelog"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// fixedLLM returns a canned response and records the prompt
+type fixedLLM struct {
+	response string
+	prompt   string
+}
+
+func (f *fixedLLM) Generate(ctx context.Context, prompt string) (string, error) {
+	f.prompt = prompt
+	return f.response, nil
+}
+
+func TestNextVersion(t *testing.T) {
+	fix := changelog.Entry{Type: "fix"}
+	feat := changelog.Entry{Type: "feat"}
+	breaking := changelog.Entry{Type: "fix", Breaking: "removes X"}
+
+	tests := []struct {
+		previous string
+		entries  []changelog.Entry
+		bump     string
+		want     string
+	}{
+		{"", nil, "", "v0.1.0"},
+		{"v1.2.3", []changelog.Entry{fix}, "", "v1.2.4"},
+		{"v1.2.3", []changelog.Entry{fix, feat}, "", "v1.3.0"},
+		{"v1.2.3", []changelog.Entry{feat, breaking, feat}, "", "v2.0.0"},
+		{"v0.4.1", []changelog.Entry{breaking}, "", "v0.5.0"},
+		{"1.2.3", []changelog.Entry{fix}, BumpMajor, "2.0.0"},
+		{"v1.2.3-rc.1", []changelog.Entry{fix}, "", "v1.2.4"},
+	}
+
+	for _, tt := range tests {
+		got, err := NextVersion(tt.previous, tt.entries, tt.bump)
+		if err != nil || got != tt.want {
+			t.Errorf("NextVersion(%q, %s) = %q, %v; want %q", tt.previous, tt.bump, got, err, tt.want)
+		}
+	}
+
+	if _, err := NextVersion("release-1", nil, ""); err == nil {
+		t.Error("expected a tag that isn't a version to be rejected")
+	}
+}
+
+// releaseWorkspace has v1.0.0 tagged and a fix and a feature after it
+func releaseWorkspace(t *testing.T) *workspacetest.Workspace {
+	ws := workspacetest.New(t, workspacetest.Tree{"CHANGELOG.md": "# Changelog\n\n## [1.0.0]\n\n- first\n"}, workspacetest.WithCommit("feat: initial"))
+	ws.Git("tag", "v1.0.0")
+	ws.WriteFile("main.go", "package main\n")
+	ws.Commit("fix(cli): handle flags")
+	ws.Commit("feat: add export")
+	return ws
+}
+
+func TestPrepare(t *testing.T) {
+	ws := releaseWorkspace(t)
+	ws.WriteFile("staged.txt", "not part of the release\n")
+	ws.Git("add", "staged.txt")
+
+	llm := &fixedLLM{response: "```markdown\nExport your data.\n\n### New Features\n\n- Export\n```"}
+	now := time.Date(2026, 5, 6, 0, 0, 0, 0, time.UTC)
+	plan, err := Prepare(context.Background(), ws.Path(""), NewNotesGenerator(llm), Options{Now: now})
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	if plan.Tag != "v1.1.0" || plan.Previous != "v1.0.0" {
+		t.Errorf("expected v1.0.0 → v1.1.0, got %s → %s", plan.Previous, plan.Tag)
+	}
+	if plan.Notes != "Export your data.\n\n### New Features\n\n- Export\n" {
+		t.Errorf("unexpected notes %q", plan.Notes)
+	}
+	for _, want := range []string{"release notes for v1.1.0", "since v1.0.0", "- **cli:** handle flags", "main.go"} {
+		if !strings.Contains(llm.prompt, want) {
+			t.Errorf("expected the prompt to contain %q, got:\n%s", want, llm.prompt)
+		}
+	}
+
+	var names []string
+	for _, step := range plan.Steps {
+		names = append(names, step.Name)
+	}
+	if strings.Join(names, ",") != "changelog,tag" {
+		t.Fatalf("expected changelog and tag steps, got %v", names)
+	}
+	if !strings.Contains(plan.Steps[0].Preview, "+## [1.1.0] - 2026-05-06") {
+		t.Errorf("expected the changelog diff in the preview, got:\n%s", plan.Steps[0].Preview)
+	}
+	ws.AssertNotContains("CHANGELOG.md", "1.1.0")
+
+	for _, step := range plan.Steps {
+		if _, err := step.Run(context.Background()); err != nil {
+			t.Fatalf("step %s failed: %v", step.Name, err)
+		}
+	}
+
+	ws.AssertContains("CHANGELOG.md", "## [1.1.0] - 2026-05-06")
+	if got := ws.Git("log", "-1", "--format=%s"); got != "chore(release): v1.1.0" {
+		t.Errorf("expected the release commit, got %q", got)
+	}
+	if got := ws.Git("show", "--name-only", "--format=", "HEAD"); got != "CHANGELOG.md" {
+		t.Errorf("expected only the changelog committed, got %q", got)
+	}
+	if got := ws.Git("describe", "--tags", "--exact-match", "HEAD"); got != "v1.1.0" {
+		t.Errorf("expected HEAD tagged v1.1.0, got %q", got)
+	}
+	if got := ws.Git("tag", "-l", "--format=%(contents)", "v1.1.0"); !strings.Contains(got, "Export your data.") {
+		t.Errorf("expected the notes in the tag message, got %q", got)
+	}
+}
+
+func TestPrepare_Rejects(t *testing.T) {
+	ws := releaseWorkspace(t)
+	ws.Git("tag", "v1.1.0", "HEAD~1")
+
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Version: "1.1.0"}, "tag v1.1.0 already exists"},
+		{Options{Version: "v0.9.0"}, "v0.9.0 is not newer than v1.1.0"},
+		{Options{Version: "next"}, `"vnext" is not a semantic version`},
+		{Options{Bump: "huge"}, `unknown bump "huge"`},
+	}
+	for _, tt := range tests {
+		_, err := Prepare(context.Background(), ws.Path(""), nil, tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Prepare(%+v): expected error containing %q, got %v", tt.opts, tt.want, err)
+		}
+	}
+}
+
+func TestPrepare_WithoutNotesGenerator(t *testing.T) {
+	ws := releaseWorkspace(t)
+	plan, err := Prepare(context.Background(), ws.Path(""), nil, Options{Bump: BumpMajor, NoCommit: true, GitHub: true})
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if plan.Tag != "v2.0.0" {
+		t.Errorf("expected v2.0.0, got %s", plan.Tag)
+	}
+	if !strings.HasPrefix(plan.Notes, "### Features\n\n- add export") {
+		t.Errorf("expected the changelog section as notes, got %q", plan.Notes)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[0].Name != StepTag || plan.Steps[1].Name != StepGitHub {
+		t.Fatalf("expected tag and github steps, got %d steps", len(plan.Steps))
+	}
+	if !strings.Contains(plan.Steps[1].Preview, "git push --atomic origin HEAD v2.0.0") {
+		t.Errorf("unexpected github preview:\n%s", plan.Steps[1].Preview)
+	}
+}
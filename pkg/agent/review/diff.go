@@ -0,0 +1,238 @@
+package review
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+)
+
+// DefaultChunkSize bounds how many bytes of diff one review step sees
+const DefaultChunkSize = 24000
+
+// Chunk is a part of a diff reviewed in one pass of the review loop. It holds
+// whole files where possible; a file too large for one chunk is split at hunk
+// boundaries, repeating its header.
+type Chunk struct {
+	Files []string // Paths in the new tree
+	Diff  string
+}
+
+// fileDiff is the diff of one file: its header and its hunks
+type fileDiff struct {
+	path   string
+	header string
+	hunks  []string
+}
+
+// DefaultRange returns the range reviewed when none is given: the current
+// branch since it forked from main, master or develop, or the uncommitted
+// changes when on the base branch itself.
+func DefaultRange(ctx context.Context, workingDir string) string {
+	base, err := git.DetectBaseBranch(ctx, workingDir)
+	if err != nil {
+		return "HEAD"
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = workingDir
+	output, err := cmd.Output()
+	if err != nil || strings.TrimSpace(string(output)) == base {
+		return "HEAD"
+	}
+	return base + "...HEAD"
+}
+
+// LoadDiff returns the git diff for rangeSpec, which takes any form git diff
+// accepts: "base..head", "base...head", or a single commit to diff the
+// worktree against.
+func LoadDiff(ctx context.Context, workingDir, rangeSpec string) (string, error) {
+	if strings.HasPrefix(rangeSpec, "-") {
+		return "", fmt.Errorf("invalid range %q", rangeSpec)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "diff", "--no-color", "--no-ext-diff", "--unified=3", rangeSpec, "--")
+	cmd.Dir = workingDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git diff %s failed: %w: %s", rangeSpec, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// SplitDiff divides a unified diff into chunks of at most maxSize bytes,
+// except where a single hunk is larger. A maxSize of 0 uses DefaultChunkSize.
+func SplitDiff(diff string, maxSize int) []Chunk {
+	if maxSize <= 0 {
+		maxSize = DefaultChunkSize
+	}
+
+	var chunks []Chunk
+	var current Chunk
+	flush := func() {
+		if current.Diff != "" {
+			chunks = append(chunks, current)
+		}
+		current = Chunk{}
+	}
+
+	for _, file := range parseFiles(diff) {
+		whole := file.header + strings.Join(file.hunks, "")
+		if len(whole) <= maxSize {
+			if len(current.Diff)+len(whole) > maxSize {
+				flush()
+			}
+			current.Files = append(current.Files, file.path)
+			current.Diff += whole
+			continue
+		}
+
+		// Too large for one chunk: give each run of hunks its own chunk
+		flush()
+		part := file.header
+		for _, hunk := range file.hunks {
+			if part != file.header && len(part)+len(hunk) > maxSize {
+				chunks = append(chunks, Chunk{Files: []string{file.path}, Diff: part})
+				part = file.header
+			}
+			part += hunk
+		}
+		chunks = append(chunks, Chunk{Files: []string{file.path}, Diff: part})
+	}
+	flush()
+
+	return chunks
+}
+
+// parseFiles splits a unified diff into per-file sections
+func parseFiles(diff string) []fileDiff {
+	var files []fileDiff
+	var current *fileDiff
+	inHunks := false
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, fileDiff{path: pathFromGitHeader(line)})
+			current = &files[len(files)-1]
+			inHunks = false
+			current.header += line
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunks = true
+			current.hunks = append(current.hunks, line)
+		case inHunks:
+			current.hunks[len(current.hunks)-1] += line
+		default:
+			if path, ok := strings.CutPrefix(strings.TrimRight(line, "\n"), "+++ b/"); ok {
+				current.path = path
+			}
+			current.header += line
+		}
+	}
+	return files
+}
+
+// pathFromGitHeader extracts the new path from a "diff --git a/x b/y" line
+func pathFromGitHeader(line string) string {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "diff --git "))
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+len(" b/"):]
+	}
+	return line
+}
+
+// commentableLines returns, per file, the new-file line numbers that appear
+// in the diff as added or context lines. Review comments can only be
+// attached to these lines.
+func commentableLines(diff string) map[string]map[int]bool {
+	lines := make(map[string]map[int]bool)
+	for _, file := range parseFiles(diff) {
+		for _, hunk := range file.hunks {
+			forEachNewLine(hunk, func(number int) {
+				if lines[file.path] == nil {
+					lines[file.path] = make(map[int]bool)
+				}
+				lines[file.path][number] = true
+			})
+		}
+	}
+	return lines
+}
+
+// annotate prefixes every added and context line of a diff with its line
+// number in the new file, so the reviewer can cite lines without counting.
+// Removed lines get a blank gutter.
+func annotate(diff string) string {
+	var b strings.Builder
+	for _, file := range parseFiles(diff) {
+		b.WriteString(file.header)
+		for _, hunk := range file.hunks {
+			header, body, _ := strings.Cut(hunk, "\n")
+			b.WriteString(header + "\n")
+			newLine := hunkStart(header)
+			forEachLine(body, func(line string) {
+				switch {
+				case strings.HasPrefix(line, "-"), strings.HasPrefix(line, `\`):
+					b.WriteString(fmt.Sprintf("%6s %s\n", "", line))
+				default:
+					b.WriteString(fmt.Sprintf("%6d %s\n", newLine, line))
+					newLine++
+				}
+			})
+		}
+	}
+	return b.String()
+}
+
+// forEachNewLine calls fn with the new-file line number of each added or
+// context line in a hunk
+func forEachNewLine(hunk string, fn func(number int)) {
+	header, body, _ := strings.Cut(hunk, "\n")
+	number := hunkStart(header)
+	forEachLine(body, func(line string) {
+		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, `\`) {
+			return
+		}
+		fn(number)
+		number++
+	})
+}
+
+// forEachLine calls fn with each line of s, without its newline
+func forEachLine(s string, fn func(line string)) {
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if line != "" {
+			fn(line)
+		}
+	}
+}
+
+// hunkStart returns the first new-file line number from a hunk header such
+// as "@@ -10,6 +12,8 @@ func main() {"
+func hunkStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 1
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		return 1
+	}
+	start, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 1
+	}
+	return start
+}
@@ -0,0 +1,188 @@
+// Package review runs the agent as a code reviewer over a git diff range.
+// The diff is split into chunks, each reviewed by a dedicated loop that may
+// read the surrounding code with the agent's read-only tools before it
+// submits structured findings. Results export as Markdown or as a GitHub
+// pull request review payload.
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Finding severities, most severe first
+const (
+	SeverityCritical = "critical" // Bugs, security holes, data loss
+	SeverityMajor    = "major"    // Incorrect behavior in some cases, missing error handling
+	SeverityMinor    = "minor"    // Maintainability, unclear code, missing tests
+	SeverityNit      = "nit"      // Style and naming
+)
+
+// severityRank orders severities for sorting
+var severityRank = map[string]int{
+	SeverityCritical: 0,
+	SeverityMajor:    1,
+	SeverityMinor:    2,
+	SeverityNit:      3,
+}
+
+// Finding is one review comment
+type Finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"` // Line in the new version of the file; 0 for the file as a whole
+	Severity string `json:"severity"`
+	Comment  string `json:"comment"`
+}
+
+// Result is the outcome of reviewing a diff range
+type Result struct {
+	Range    string    `json:"range"`
+	Summary  []string  `json:"summary"` // One overall assessment per chunk
+	Findings []Finding `json:"findings"`
+
+	// commentable holds the new-file lines present in the diff, per file
+	commentable map[string]map[int]bool
+}
+
+// normalizeSeverity maps a severity to one of the Severity* values,
+// treating anything unrecognized as minor
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	if _, ok := severityRank[severity]; ok {
+		return severity
+	}
+	return SeverityMinor
+}
+
+// sortFindings orders findings by severity, then file and line
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+}
+
+// Counts returns the number of findings per severity
+func (r *Result) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, f := range r.Findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// countsLine summarizes the findings, e.g. "3 findings: 1 critical, 2 minor"
+func (r *Result) countsLine() string {
+	if len(r.Findings) == 0 {
+		return "No findings."
+	}
+	counts := r.Counts()
+	var parts []string
+	for _, severity := range []string{SeverityCritical, SeverityMajor, SeverityMinor, SeverityNit} {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	return fmt.Sprintf("%d finding(s): %s", len(r.Findings), strings.Join(parts, ", "))
+}
+
+// Markdown renders the review as a Markdown report
+func (r *Result) Markdown() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Code Review: `%s`\n\n", r.Range))
+	b.WriteString(r.countsLine() + "\n")
+
+	if len(r.Summary) > 0 {
+		b.WriteString("\n## Summary\n\n")
+		for _, summary := range r.Summary {
+			b.WriteString(summary + "\n\n")
+		}
+	}
+
+	if len(r.Findings) > 0 {
+		b.WriteString("\n## Findings\n")
+		for _, f := range r.Findings {
+			location := f.File
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s:%d", f.File, f.Line)
+			}
+			b.WriteString(fmt.Sprintf("\n### [%s] `%s`\n\n%s\n", f.Severity, location, f.Comment))
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// GitHubReview is the request body for GitHub's "create a review for a pull
+// request" API (POST /repos/{owner}/{repo}/pulls/{pull_number}/reviews)
+type GitHubReview struct {
+	Body     string          `json:"body"`
+	Event    string          `json:"event"`
+	Comments []GitHubComment `json:"comments"`
+}
+
+// GitHubComment is an inline review comment on a line of the new version of a file
+type GitHubComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// GitHubReview converts the review to a GitHub review payload. GitHub only
+// accepts inline comments on lines that appear in the diff, so findings on
+// other lines are listed in the review body instead.
+func (r *Result) GitHubReview() *GitHubReview {
+	review := &GitHubReview{Event: "COMMENT", Comments: []GitHubComment{}}
+
+	var body strings.Builder
+	body.WriteString(r.countsLine())
+	for _, summary := range r.Summary {
+		body.WriteString("\n\n" + summary)
+	}
+
+	var general []Finding
+	for _, f := range r.Findings {
+		if f.Line > 0 && r.commentable[f.File][f.Line] {
+			review.Comments = append(review.Comments, GitHubComment{
+				Path: f.File,
+				Line: f.Line,
+				Side: "RIGHT",
+				Body: fmt.Sprintf("**%s**: %s", f.Severity, f.Comment),
+			})
+			continue
+		}
+		general = append(general, f)
+	}
+
+	if len(general) > 0 {
+		body.WriteString("\n\n**Other findings:**\n")
+		for _, f := range general {
+			location := f.File
+			if f.Line > 0 {
+				location = fmt.Sprintf("%s:%d", f.File, f.Line)
+			}
+			body.WriteString(fmt.Sprintf("\n- **%s** `%s`: %s", f.Severity, location, f.Comment))
+		}
+	}
+
+	review.Body = body.String()
+	return review
+}
+
+// JSON renders the review findings as indented JSON
+func (r *Result) JSON() (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode review: %w", err)
+	}
+	return string(data), nil
+}
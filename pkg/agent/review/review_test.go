@@ -0,0 +1,238 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+const testDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,5 @@
+ package main
+ 
+-func main() {}
++func main() {
++	run()
++}
+@@ -20,2 +21,3 @@ func helper() {
+ 	a := 1
++	b := 2
+ }
+diff --git a/util.go b/util.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/util.go
+@@ -0,0 +1,2 @@
++package main
++func run() {}
+`
+
+func TestSplitDiff(t *testing.T) {
+	chunks := SplitDiff(testDiff, 0)
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	if got := strings.Join(chunks[0].Files, ","); got != "main.go,util.go" {
+		t.Errorf("unexpected files %q", got)
+	}
+	if chunks[0].Diff != testDiff {
+		t.Errorf("chunk should hold the whole diff, got:\n%s", chunks[0].Diff)
+	}
+
+	// Each file on its own, and main.go split between its two hunks
+	chunks = SplitDiff(testDiff, 150)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	for i, want := range []string{"main.go", "main.go", "util.go"} {
+		if len(chunks[i].Files) != 1 || chunks[i].Files[0] != want {
+			t.Errorf("chunk %d: expected %s, got %v", i, want, chunks[i].Files)
+		}
+		if !strings.HasPrefix(chunks[i].Diff, "diff --git a/"+want) {
+			t.Errorf("chunk %d should start with the file header, got:\n%s", i, chunks[i].Diff)
+		}
+	}
+	if !strings.Contains(chunks[1].Diff, "@@ -20,2 +21,3 @@") || strings.Contains(chunks[1].Diff, "@@ -1,4") {
+		t.Errorf("second chunk should hold only the second hunk, got:\n%s", chunks[1].Diff)
+	}
+}
+
+func TestAnnotateAndCommentableLines(t *testing.T) {
+	annotated := annotate(testDiff)
+	for _, want := range []string{
+		"     1  package main",
+		"       -func main() {}",
+		"     3 +func main() {",
+		"    22 +\tb := 2",
+		"     2 +func run() {}",
+	} {
+		if !strings.Contains(annotated, want) {
+			t.Errorf("annotated diff missing %q:\n%s", want, annotated)
+		}
+	}
+
+	lines := commentableLines(testDiff)
+	for _, line := range []int{1, 2, 3, 4, 5, 21, 22, 23} {
+		if !lines["main.go"][line] {
+			t.Errorf("main.go:%d should be commentable", line)
+		}
+	}
+	if lines["main.go"][10] {
+		t.Error("main.go:10 is outside the diff")
+	}
+	if !lines["util.go"][2] {
+		t.Error("util.go:2 should be commentable")
+	}
+}
+
+func TestResultExports(t *testing.T) {
+	result := &Result{
+		Range:   "main..feature",
+		Summary: []string{"Adds a run helper."},
+		Findings: []Finding{
+			{File: "main.go", Line: 22, Severity: SeverityMinor, Comment: "b is unused"},
+			{File: "util.go", Line: 40, Severity: SeverityCritical, Comment: "run never returns"},
+		},
+		commentable: commentableLines(testDiff),
+	}
+	sortFindings(result.Findings)
+	if result.Findings[0].Severity != SeverityCritical {
+		t.Errorf("critical findings should sort first, got %+v", result.Findings)
+	}
+
+	md := result.Markdown()
+	for _, want := range []string{"# Code Review: `main..feature`", "2 finding(s): 1 critical, 1 minor", "### [critical] `util.go:40`", "b is unused"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	gh := result.GitHubReview()
+	if gh.Event != "COMMENT" {
+		t.Errorf("unexpected event %q", gh.Event)
+	}
+	if len(gh.Comments) != 1 || gh.Comments[0].Path != "main.go" || gh.Comments[0].Line != 22 || gh.Comments[0].Side != "RIGHT" {
+		t.Errorf("expected one inline comment on main.go:22, got %+v", gh.Comments)
+	}
+	// util.go:40 is not in the diff, so GitHub would reject it inline
+	if !strings.Contains(gh.Body, "`util.go:40`: run never returns") {
+		t.Errorf("finding outside the diff should be in the body, got:\n%s", gh.Body)
+	}
+}
+
+// scriptedProvider returns its responses in order
+type scriptedProvider struct {
+	responses []string
+	calls     [][]*types.Message
+}
+
+func (p *scriptedProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	return nil, nil
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	p.calls = append(p.calls, messages)
+	response := p.responses[0]
+	p.responses = p.responses[1:]
+	return types.NewAssistantMessage(response), nil
+}
+
+func (p *scriptedProvider) GetModelInfo() *types.ModelInfo {
+	return nil
+}
+
+// echoTool is a read-only tool that returns a fixed result
+type echoTool struct{}
+
+func (echoTool) Name() string                                    { return "read_file" }
+func (echoTool) Description() string                             { return "Read a file" }
+func (echoTool) Schema() map[string]interface{}                  { return map[string]interface{}{} }
+func (echoTool) IsLoopBreaking() bool                            { return false }
+func (echoTool) Execute(context.Context, []byte) (string, error) { return "func run() {}", nil }
+
+func TestReviewer_Review(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		"Let me look at util.go first.",
+		`<tool><server_name>local</server_name><tool_name>read_file</tool_name><arguments><path>util.go</path></arguments></tool>`,
+		`<tool><server_name>local</server_name><tool_name>submit_review</tool_name><arguments>
+<summary>Adds a helper.</summary>
+<findings>
+  <finding><file>main.go</file><line>22</line><severity>Nit</severity><comment>b is unused</comment></finding>
+  <finding><file>util.go</file><line>2</line><severity>urgent</severity><comment>run does nothing</comment></finding>
+</findings>
+</arguments></tool>`,
+	}}
+
+	var progress []int
+	reviewer := NewReviewer(provider, WithTools(echoTool{}), WithProgress(func(done, total int) {
+		progress = append(progress, done, total)
+	}))
+	result, err := reviewer.Review(context.Background(), "HEAD", testDiff)
+	if err != nil {
+		t.Fatalf("Review failed: %v", err)
+	}
+
+	if len(provider.calls) != 3 {
+		t.Fatalf("expected 3 LLM calls, got %d", len(provider.calls))
+	}
+	if last := provider.calls[1][len(provider.calls[1])-1].Content; !strings.Contains(last, "No tool call") {
+		t.Errorf("a response without a tool call should get a recovery message, got:\n%s", last)
+	}
+	if last := provider.calls[2][len(provider.calls[2])-1].Content; !strings.Contains(last, "func run() {}") {
+		t.Errorf("the tool result should be sent back, got:\n%s", last)
+	}
+
+	if len(result.Summary) != 1 || result.Summary[0] != "Adds a helper." {
+		t.Errorf("unexpected summary %v", result.Summary)
+	}
+	want := []Finding{
+		{File: "util.go", Line: 2, Severity: SeverityMinor, Comment: "run does nothing"},
+		{File: "main.go", Line: 22, Severity: SeverityNit, Comment: "b is unused"},
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), result.Findings)
+	}
+	for i := range want {
+		if result.Findings[i] != want[i] {
+			t.Errorf("finding %d: expected %+v, got %+v", i, want[i], result.Findings[i])
+		}
+	}
+	if len(progress) != 2 || progress[0] != 1 || progress[1] != 1 {
+		t.Errorf("unexpected progress reports %v", progress)
+	}
+}
+
+func TestReviewer_GivesUpAfterMaxSteps(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{"thinking", "still thinking"}}
+	_, err := NewReviewer(provider, WithMaxSteps(2)).Review(context.Background(), "HEAD", testDiff)
+	if err == nil || !strings.Contains(err.Error(), "no review submitted after 2 steps") {
+		t.Errorf("expected a max steps error, got %v", err)
+	}
+}
+
+func TestLoadDiff(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"a.txt": "one\n"},
+		workspacetest.WithCommit("initial"),
+		workspacetest.WithChanges(workspacetest.Tree{"a.txt": "two\n"}))
+	dir := ws.Dir
+
+	diff, err := LoadDiff(context.Background(), dir, "HEAD")
+	if err != nil {
+		t.Fatalf("LoadDiff failed: %v", err)
+	}
+	if !strings.Contains(diff, "-one") || !strings.Contains(diff, "+two") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+
+	if _, err := LoadDiff(context.Background(), dir, "--output=/tmp/x"); err == nil {
+		t.Error("ranges that look like options should be rejected")
+	}
+}
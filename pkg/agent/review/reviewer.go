@@ -0,0 +1,253 @@
+package review
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/readonly"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// defaultMaxSteps bounds the LLM calls spent reviewing one chunk
+const defaultMaxSteps = 15
+
+// submitToolName is the loop-breaking tool that ends the review of a chunk
+const submitToolName = "submit_review"
+
+// reviewInstructions direct the model to review instead of edit
+const reviewInstructions = `You are reviewing a code change, not making one. Never modify files.
+
+You will be shown part of a git diff. Each added (+) and context line is prefixed with its line number in the new version of the file. Use the read-only tools to look at surrounding code when the diff alone is not enough to judge a change, then call submit_review exactly once with your findings for this part of the diff.
+
+Report real problems: bugs, security issues, missing error handling, race conditions, broken edge cases, missing tests, and unclear code. Do not restate what the change does, and do not praise it. Each finding needs:
+- file: the path as shown in the diff
+- line: the new-file line number the comment is about (0 if it concerns the whole file)
+- severity: critical (bugs, security holes, data loss), major (wrong in some cases), minor (maintainability, tests), or nit (style)
+- comment: what is wrong and how to fix it
+
+Submit an empty findings list if this part of the diff has no problems.`
+
+// Reviewer reviews diffs with a read-only loop: for each chunk of the diff
+// it lets the model call read-only tools until it submits its findings.
+type Reviewer struct {
+	loop      *readonly.Loop
+	toolList  []tools.Tool
+	maxSteps  int
+	chunkSize int
+	progress  func(done, total int)
+}
+
+// Option configures a Reviewer
+type Option func(*Reviewer)
+
+// WithTools makes tools available to the reviewer for reading context. Only
+// pass tools without side effects (read_file, list_files, search_files).
+func WithTools(toolList ...tools.Tool) Option {
+	return func(r *Reviewer) {
+		r.toolList = append(r.toolList, toolList...)
+	}
+}
+
+// WithMaxSteps bounds the LLM calls spent on each chunk.
+func WithMaxSteps(steps int) Option {
+	return func(r *Reviewer) {
+		if steps > 0 {
+			r.maxSteps = steps
+		}
+	}
+}
+
+// WithChunkSize sets the maximum size in bytes of the diff reviewed at once.
+func WithChunkSize(size int) Option {
+	return func(r *Reviewer) {
+		if size > 0 {
+			r.chunkSize = size
+		}
+	}
+}
+
+// WithProgress sets a function called after each chunk is reviewed.
+func WithProgress(fn func(done, total int)) Option {
+	return func(r *Reviewer) {
+		r.progress = fn
+	}
+}
+
+// NewReviewer creates a reviewer that uses provider for the review loop.
+func NewReviewer(provider llm.Provider, opts ...Option) *Reviewer {
+	r := &Reviewer{
+		maxSteps:  defaultMaxSteps,
+		chunkSize: DefaultChunkSize,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.loop = readonly.NewLoop(provider, readonly.WithTools(r.toolList...), readonly.WithMaxSteps(r.maxSteps))
+	return r
+}
+
+// ReviewRange reviews the diff for rangeSpec in workingDir. An empty
+// rangeSpec reviews DefaultRange.
+func (r *Reviewer) ReviewRange(ctx context.Context, workingDir, rangeSpec string) (*Result, error) {
+	if rangeSpec == "" {
+		rangeSpec = DefaultRange(ctx, workingDir)
+	}
+	diff, err := LoadDiff(ctx, workingDir, rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+	return r.Review(ctx, rangeSpec, diff)
+}
+
+// Review reviews diff chunk by chunk and collects the findings, sorted by
+// severity. rangeSpec labels the result.
+func (r *Reviewer) Review(ctx context.Context, rangeSpec, diff string) (*Result, error) {
+	result := &Result{
+		Range:       rangeSpec,
+		Findings:    []Finding{},
+		commentable: commentableLines(diff),
+	}
+
+	chunks := SplitDiff(diff, r.chunkSize)
+	for i, chunk := range chunks {
+		summary, findings, err := r.reviewChunk(ctx, chunk, i+1, len(chunks))
+		if err != nil {
+			return nil, fmt.Errorf("review of %s failed: %w", strings.Join(chunk.Files, ", "), err)
+		}
+		if summary != "" {
+			result.Summary = append(result.Summary, summary)
+		}
+		result.Findings = append(result.Findings, findings...)
+		if r.progress != nil {
+			r.progress(i+1, len(chunks))
+		}
+	}
+
+	sortFindings(result.Findings)
+	return result, nil
+}
+
+// reviewChunk runs the review loop for one chunk until the model submits its findings
+func (r *Reviewer) reviewChunk(ctx context.Context, chunk Chunk, index, total int) (string, []Finding, error) {
+	submit := &submitReviewTool{}
+	task := fmt.Sprintf("Review part %d of %d of the diff (%s):\n\n%s",
+		index, total, strings.Join(chunk.Files, ", "), annotate(chunk.Diff))
+	if err := r.loop.Run(ctx, reviewInstructions, task, submit); err != nil {
+		if errors.Is(err, readonly.ErrNotSubmitted) {
+			return "", nil, fmt.Errorf("no review submitted after %d steps", r.maxSteps)
+		}
+		return "", nil, err
+	}
+	return submit.summary, submit.findings, nil
+}
+
+// submitReviewTool ends the review of a chunk, holding the findings it was
+// called with.
+type submitReviewTool struct {
+	summary  string
+	findings []Finding
+}
+
+// Name returns the tool name.
+func (t *submitReviewTool) Name() string {
+	return submitToolName
+}
+
+// Description returns the tool description.
+func (t *submitReviewTool) Description() string {
+	return "Submit your review of this part of the diff: a one-paragraph assessment and a list of findings. Call it exactly once, when you are done reviewing."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *submitReviewTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"summary": map[string]interface{}{
+				"type":        "string",
+				"description": "Overall assessment of this part of the change in a few sentences",
+			},
+			"findings": map[string]interface{}{
+				"type":        "array",
+				"description": "Problems found; empty if there are none",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"file": map[string]interface{}{
+							"type":        "string",
+							"description": "Path of the file as shown in the diff",
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line number in the new version of the file (0 for the whole file)",
+						},
+						"severity": map[string]interface{}{
+							"type":        "string",
+							"description": "critical, major, minor, or nit",
+						},
+						"comment": map[string]interface{}{
+							"type":        "string",
+							"description": "What is wrong and how to fix it",
+						},
+					},
+					"required": []string{"file", "line", "severity", "comment"},
+				},
+			},
+		},
+		[]string{"summary", "findings"},
+	)
+}
+
+// Execute records the summary and findings.
+func (t *submitReviewTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	if err := t.parse(argsXML); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Review submitted with %d finding(s)", len(t.findings)), nil
+}
+
+// IsLoopBreaking returns true as submitting ends the review of a chunk.
+func (t *submitReviewTool) IsLoopBreaking() bool {
+	return true
+}
+
+// parse reads the summary and findings from the tool arguments
+func (t *submitReviewTool) parse(argsXML []byte) error {
+	var input struct {
+		XMLName  xml.Name `xml:"arguments"`
+		Summary  string   `xml:"summary"`
+		Findings []struct {
+			File     string `xml:"file"`
+			Line     string `xml:"line"`
+			Severity string `xml:"severity"`
+			Comment  string `xml:"comment"`
+		} `xml:"findings>finding"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	findings := make([]Finding, 0, len(input.Findings))
+	for _, f := range input.Findings {
+		comment := strings.TrimSpace(f.Comment)
+		if comment == "" {
+			return fmt.Errorf("finding for %q has no comment", f.File)
+		}
+		line, _ := strconv.Atoi(strings.TrimSpace(f.Line)) // A missing or unparsable line means the whole file
+		findings = append(findings, Finding{
+			File:     strings.TrimPrefix(strings.TrimSpace(f.File), "b/"),
+			Line:     line,
+			Severity: normalizeSeverity(f.Severity),
+			Comment:  comment,
+		})
+	}
+
+	t.summary = strings.TrimSpace(input.Summary)
+	t.findings = findings
+	return nil
+}
@@ -0,0 +1,312 @@
+// Package risk sizes up a change awaiting approval: the lines it adds and
+// removes, the files it touches, whether those include tests, configuration,
+// CI pipelines or dependencies, and a heuristic score of how far a mistake
+// in it could reach. The score is a prompt for a closer look, not a verdict.
+package risk
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// Levels
+const (
+	LevelLow    = "low"
+	LevelMedium = "medium"
+	LevelHigh   = "high"
+)
+
+// MetadataKey is the preview metadata key an approval's assessment is kept under
+const MetadataKey = "risk"
+
+// Assessment is the size and risk of a change
+type Assessment struct {
+	Files   []string `json:"files"`
+	Added   int      `json:"added"`
+	Removed int      `json:"removed"`
+
+	Tests        bool `json:"tests,omitempty"`        // Touches test files
+	Config       bool `json:"config,omitempty"`       // Touches configuration, such as Dockerfiles and YAML
+	CI           bool `json:"ci,omitempty"`           // Touches CI pipelines
+	Dependencies bool `json:"dependencies,omitempty"` // Touches dependency manifests or lock files
+
+	// Score runs from 0 to 100; Level buckets it
+	Score int    `json:"score"`
+	Level string `json:"level"`
+
+	// Factors are the reasons for the score, e.g. "CI pipeline"
+	Factors []string `json:"factors,omitempty"`
+}
+
+// Assess sizes up the change a preview shows, a diff or a new file. It
+// returns nil for previews of other kinds, such as commands.
+func Assess(preview *tools.ToolPreview) *Assessment {
+	if preview == nil {
+		return nil
+	}
+	c := newCounter()
+	switch preview.Type {
+	case tools.PreviewTypeDiff:
+		c.diff(preview.Content, filePath(preview))
+	case tools.PreviewTypeFileWrite:
+		c.file(filePath(preview), preview.Content)
+	default:
+		return nil
+	}
+	if len(c.files) == 0 {
+		return nil
+	}
+	return c.assessment()
+}
+
+// Combine assesses the changes of several assessments as one, such as a
+// batch of edits approved together. Nil assessments are skipped; it returns
+// nil if all are.
+func Combine(parts ...*Assessment) *Assessment {
+	c := newCounter()
+	for _, a := range parts {
+		if a != nil {
+			c.add(a)
+		}
+	}
+	if len(c.files) == 0 {
+		return nil
+	}
+	return c.assessment()
+}
+
+// filePath returns the file a preview is of, if it names one
+func filePath(preview *tools.ToolPreview) string {
+	p, _ := preview.Metadata["file_path"].(string)
+	return p
+}
+
+// counter tallies the lines and files of a change
+type counter struct {
+	files          map[string]bool
+	added, removed int
+}
+
+func newCounter() *counter {
+	return &counter{files: make(map[string]bool)}
+}
+
+// diff counts a unified diff, naming its files from its headers or, for a
+// diff without them, fallback
+func (c *counter) diff(diff, fallback string) {
+	var oldPath string
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			oldPath = diffPath(line[4:], "a/")
+		case strings.HasPrefix(line, "+++ "):
+			p := diffPath(line[4:], "b/")
+			if p == "" {
+				p = oldPath
+			}
+			if p != "" {
+				c.files[p] = true
+			}
+		case strings.HasPrefix(line, "+"):
+			c.added++
+		case strings.HasPrefix(line, "-"):
+			c.removed++
+		}
+	}
+	if len(c.files) == 0 && fallback != "" && c.added+c.removed > 0 {
+		c.files[fallback] = true
+	}
+}
+
+// diffPath returns the path of a diff header, without git's a/ or b/
+// prefix; /dev/null, for a created or deleted file, is ""
+func diffPath(header, prefix string) string {
+	header = strings.TrimSpace(strings.SplitN(header, "\t", 2)[0])
+	if header == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
+
+// file counts a new file
+func (c *counter) file(p, content string) {
+	if p == "" {
+		return
+	}
+	c.files[p] = true
+	if content != "" {
+		c.added += strings.Count(strings.TrimSuffix(content, "\n"), "\n") + 1
+	}
+}
+
+// add counts another assessment's change
+func (c *counter) add(a *Assessment) {
+	for _, f := range a.Files {
+		c.files[f] = true
+	}
+	c.added += a.Added
+	c.removed += a.Removed
+}
+
+// assessment scores the counted change
+func (c *counter) assessment() *Assessment {
+	a := &Assessment{Added: c.added, Removed: c.removed}
+	allTests := true
+	for f := range c.files {
+		a.Files = append(a.Files, f)
+		switch kind(f) {
+		case kindTest:
+			a.Tests = true
+		case kindCI:
+			a.CI = true
+		case kindDependencies:
+			a.Dependencies = true
+		case kindConfig:
+			a.Config = true
+		}
+		allTests = allTests && kind(f) == kindTest
+	}
+	sort.Strings(a.Files)
+
+	changed := a.Added + a.Removed
+	switch {
+	case changed > 500:
+		a.score(40, fmt.Sprintf("%d lines changed", changed))
+	case changed > 200:
+		a.score(30, fmt.Sprintf("%d lines changed", changed))
+	case changed > 50:
+		a.score(20, fmt.Sprintf("%d lines changed", changed))
+	case changed > 10:
+		a.score(10, "")
+	default:
+		a.score(5, "")
+	}
+	switch n := len(a.Files); {
+	case n >= 10:
+		a.score(20, fmt.Sprintf("%d files", n))
+	case n >= 4:
+		a.score(10, fmt.Sprintf("%d files", n))
+	case n >= 2:
+		a.score(5, "")
+	}
+	if a.CI {
+		a.score(25, "CI pipeline")
+	}
+	if a.Dependencies {
+		a.score(20, "dependencies")
+	}
+	if a.Config {
+		a.score(15, "configuration")
+	}
+	if a.Removed > 20 && a.Removed > 2*a.Added {
+		a.score(10, "mostly deletions")
+	}
+	if allTests {
+		a.Score /= 2
+		a.Factors = append(a.Factors, "tests only")
+	}
+
+	a.Score = min(a.Score, 100)
+	switch {
+	case a.Score >= 50:
+		a.Level = LevelHigh
+	case a.Score >= 25:
+		a.Level = LevelMedium
+	default:
+		a.Level = LevelLow
+	}
+	return a
+}
+
+// score adds points, noting the reason if there is one worth showing
+func (a *Assessment) score(points int, factor string) {
+	a.Score += points
+	if factor != "" {
+		a.Factors = append(a.Factors, factor)
+	}
+}
+
+// Summary describes the assessment in a line, e.g.
+// "+120 −8 in 3 files · tests, dependencies · risk medium (35)"
+func (a *Assessment) Summary() string {
+	files := "1 file"
+	if len(a.Files) != 1 {
+		files = fmt.Sprintf("%d files", len(a.Files))
+	}
+	parts := []string{fmt.Sprintf("+%d −%d in %s", a.Added, a.Removed, files)}
+	if touched := a.Touched(); len(touched) > 0 {
+		parts = append(parts, strings.Join(touched, ", "))
+	}
+	parts = append(parts, fmt.Sprintf("risk %s (%d)", a.Level, a.Score))
+	return strings.Join(parts, " · ")
+}
+
+// Touched names the kinds of sensitive files the change touches
+func (a *Assessment) Touched() []string {
+	var touched []string
+	for _, t := range []struct {
+		name string
+		on   bool
+	}{{"tests", a.Tests}, {"config", a.Config}, {"CI", a.CI}, {"dependencies", a.Dependencies}} {
+		if t.on {
+			touched = append(touched, t.name)
+		}
+	}
+	return touched
+}
+
+// File kinds
+const (
+	kindCode = iota
+	kindTest
+	kindConfig
+	kindCI
+	kindDependencies
+)
+
+// dependencyFiles are manifests and lock files, by base name
+var dependencyFiles = map[string]bool{
+	"go.mod": true, "go.sum": true,
+	"package.json": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"requirements.txt": true, "pipfile": true, "pipfile.lock": true, "poetry.lock": true, "pyproject.toml": true,
+	"cargo.toml": true, "cargo.lock": true, "gemfile": true, "gemfile.lock": true,
+	"pom.xml": true, "build.gradle": true, "build.gradle.kts": true, "composer.json": true, "composer.lock": true,
+}
+
+// configFiles are configuration files by base name; files with
+// configExtensions count too
+var configFiles = map[string]bool{
+	"dockerfile": true, "makefile": true, ".env": true, ".gitignore": true, ".gitattributes": true, ".editorconfig": true,
+}
+
+var configExtensions = map[string]bool{
+	".yaml": true, ".yml": true, ".toml": true, ".ini": true, ".conf": true, ".cfg": true, ".json": true,
+	".tf": true, ".tfvars": true, ".properties": true, ".env": true,
+}
+
+// kind classifies a slash-separated file path
+func kind(p string) int {
+	lower := strings.ToLower(p)
+	base := path.Base(lower)
+	switch {
+	case strings.HasPrefix(lower, ".github/workflows/") || strings.HasPrefix(lower, ".circleci/") ||
+		strings.HasPrefix(lower, ".buildkite/") || base == ".gitlab-ci.yml" || base == "jenkinsfile" ||
+		base == ".travis.yml" || base == "azure-pipelines.yml":
+		return kindCI
+	case dependencyFiles[base] || (strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt")):
+		return kindDependencies
+	case strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py") ||
+		strings.Contains("/"+lower, "/test/") || strings.Contains("/"+lower, "/tests/") || strings.Contains(lower, "__tests__/") ||
+		strings.Contains("/"+lower, "/testdata/"):
+		return kindTest
+	case configFiles[base] || strings.HasPrefix(base, ".env.") || strings.HasPrefix(base, "docker-compose") ||
+		configExtensions[path.Ext(base)]:
+		return kindConfig
+	}
+	return kindCode
+}
@@ -0,0 +1,111 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// diffOf returns a unified diff of path adding and removing lines
+func diffOf(path string, added, removed int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n@@ -1,%d +1,%d @@\n", path, path, removed, added)
+	for i := 0; i < removed; i++ {
+		b.WriteString("-old\n")
+	}
+	for i := 0; i < added; i++ {
+		b.WriteString("+new\n")
+	}
+	return b.String()
+}
+
+func TestAssessDiff(t *testing.T) {
+	a := Assess(&tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: diffOf("pkg/parser/parser.go", 3, 1)})
+	if a == nil || a.Added != 3 || a.Removed != 1 || len(a.Files) != 1 || a.Files[0] != "pkg/parser/parser.go" {
+		t.Fatalf("unexpected assessment %+v", a)
+	}
+	if a.Level != LevelLow || len(a.Touched()) != 0 {
+		t.Errorf("expected a small code change to be low risk, got %+v", a)
+	}
+	if got := a.Summary(); got != "+3 −1 in 1 file · risk low (5)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	// A diff without headers is named from the preview
+	a = Assess(&tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: "@@ -1 +1 @@\n-a\n+b\n", Metadata: map[string]interface{}{"file_path": "Dockerfile"}})
+	if a == nil || a.Files[0] != "Dockerfile" || !a.Config {
+		t.Errorf("expected the preview's file, classified as config, got %+v", a)
+	}
+
+	if Assess(&tools.ToolPreview{Type: tools.PreviewTypeCommand, Content: "rm -rf build"}) != nil {
+		t.Error("expected no assessment of a command")
+	}
+	if Assess(&tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: "No changes"}) != nil {
+		t.Error("expected no assessment of an empty diff")
+	}
+}
+
+func TestAssessFileWrite(t *testing.T) {
+	a := Assess(&tools.ToolPreview{
+		Type:     tools.PreviewTypeFileWrite,
+		Content:  "package parser\n\nfunc TestParse(t *testing.T) {}\n",
+		Metadata: map[string]interface{}{"file_path": "pkg/parser/parser_test.go"},
+	})
+	if a == nil || a.Added != 3 || !a.Tests {
+		t.Fatalf("unexpected assessment %+v", a)
+	}
+	if a.Factors[len(a.Factors)-1] != "tests only" || a.Score != 2 {
+		t.Errorf("expected a test-only change to score half, got %+v", a)
+	}
+}
+
+func TestCombineScoresBlastRadius(t *testing.T) {
+	a := Combine(
+		Assess(&tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: diffOf(".github/workflows/ci.yml", 20, 5)}),
+		Assess(&tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: diffOf("go.mod", 2, 1)}),
+		Assess(&tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: diffOf("internal/server.go", 250, 40)}),
+		nil,
+	)
+	if a == nil || len(a.Files) != 3 || a.Added != 272 || a.Removed != 46 {
+		t.Fatalf("unexpected combined assessment %+v", a)
+	}
+	if !a.CI || !a.Dependencies || a.Tests {
+		t.Errorf("unexpected kinds %+v", a)
+	}
+	if a.Level != LevelHigh || a.Score != 80 {
+		t.Errorf("expected a high score, got %d (%s)", a.Score, a.Level)
+	}
+	want := []string{"318 lines changed", "CI pipeline", "dependencies"}
+	if strings.Join(a.Factors, ",") != strings.Join(want, ",") {
+		t.Errorf("expected factors %v, got %v", want, a.Factors)
+	}
+	if Combine(nil, nil) != nil {
+		t.Error("expected nil for nothing to combine")
+	}
+}
+
+func TestKind(t *testing.T) {
+	for path, want := range map[string]int{
+		"main.go":                  kindCode,
+		"pkg/a/a_test.go":          kindTest,
+		"src/__tests__/app.js":     kindTest,
+		"web/app.spec.ts":          kindTest,
+		"tests/test_api.py":        kindTest,
+		"pkg/x/testdata/in.json":   kindTest,
+		".github/workflows/ci.yml": kindCI,
+		".gitlab-ci.yml":           kindCI,
+		"web/package-lock.json":    kindDependencies,
+		"requirements-dev.txt":     kindDependencies,
+		"Cargo.toml":               kindDependencies,
+		"deploy/values.yaml":       kindConfig,
+		"docker-compose.prod.yml":  kindConfig,
+		".env.local":               kindConfig,
+		"infra/main.tf":            kindConfig,
+	} {
+		if got := kind(path); got != want {
+			t.Errorf("kind(%q) = %d, want %d", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package seed imports an external document, such as a design doc or
+// meeting notes, as context a session starts from, fitted into a share of
+// the model's context window. A document that fits is kept verbatim; a
+// longer one is split into chunks at headings and paragraphs, and each
+// chunk is summarized to its part of the budget.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/tokenizer"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// DefaultShare is the share of the context window an imported document
+	// may take
+	DefaultShare = 0.25
+
+	// defaultBudget is the budget when the context window is unknown
+	defaultBudget = 16000
+
+	// maxChunkTokens bounds the part of a document summarized in one call
+	maxChunkTokens = 8000
+
+	// minSummaryTokens is the shortest summary a chunk is asked for; a
+	// document with more chunks than the budget allows at this length is
+	// summarized again from its chunk summaries
+	minSummaryTokens = 200
+
+	// maxPasses bounds how many times a document is summarized before what
+	// is left is cut short
+	maxPasses = 3
+
+	// charsPerToken estimates tokens when the tokenizer is unavailable
+	charsPerToken = 4
+)
+
+// DocumentKey is the metadata key naming the document an imported message
+// holds
+const DocumentKey = "imported_document"
+
+// summarizePrompt is the system prompt chunks are summarized with
+const summarizePrompt = `You condense documents that a coding agent is given as background for its work.
+Keep decisions, requirements, constraints, names, interfaces, file paths, numbers and open questions.
+Drop repetition, examples that only restate a point, and narrative. Use terse Markdown.
+Reply with the summary only.`
+
+// Document is a document prepared for import
+type Document struct {
+	// Name identifies the document, usually its path
+	Name string
+
+	// Content is the text given to the model: the document, or its summary
+	Content string
+
+	// Tokens is the size of Content
+	Tokens int
+
+	// OriginalTokens is the size of the document as read
+	OriginalTokens int
+
+	// Chunks is the number of chunks summarized, 0 if the document is kept
+	// verbatim
+	Chunks int
+
+	// Truncated reports that the summary was still over budget and was cut
+	// short
+	Truncated bool
+}
+
+// Summarized reports whether the document was summarized to fit
+func (d *Document) Summarized() bool {
+	return d.Chunks > 0
+}
+
+// Summary reports the document's size and how it was fitted, e.g.
+// "notes.md (3000 tokens, summarized from 41000 in 6 chunks)"
+func (d *Document) Summary() string {
+	switch {
+	case d.Truncated:
+		return fmt.Sprintf("%s (%d tokens, summarized and cut short from %d)", d.Name, d.Tokens, d.OriginalTokens)
+	case d.Summarized():
+		return fmt.Sprintf("%s (%d tokens, summarized from %d in %d chunks)", d.Name, d.Tokens, d.OriginalTokens, d.Chunks)
+	default:
+		return fmt.Sprintf("%s (%d tokens)", d.Name, d.Tokens)
+	}
+}
+
+// Message returns the conversation message that imports the document
+func (d *Document) Message() *types.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The user imported %s as background for this session", d.Name)
+	if d.Summarized() {
+		fmt.Fprintf(&b, ". It was summarized from %d tokens to fit the context window, so read the original if you need its details", d.OriginalTokens)
+	}
+	b.WriteString(". Use it as context; it is not a request.\n\n")
+	fmt.Fprintf(&b, "<document name=%q>\n%s\n</document>", d.Name, strings.TrimSpace(d.Content))
+	return types.NewUserMessage(b.String()).WithMetadata(DocumentKey, d.Name)
+}
+
+// Budget returns the tokens a document may take: share of a context window
+// of maxContextTokens, or of a default budget if the window is unknown
+func Budget(maxContextTokens int, share float64) int {
+	if share <= 0 || share > 1 {
+		share = DefaultShare
+	}
+	if maxContextTokens <= 0 {
+		return defaultBudget
+	}
+	return int(float64(maxContextTokens) * share)
+}
+
+// Importer prepares documents for import
+type Importer struct {
+	provider llm.Provider
+	count    func(text string) int
+}
+
+// NewImporter returns an importer that summarizes documents too long to
+// import verbatim with provider. Without a provider they are cut short
+// instead.
+func NewImporter(provider llm.Provider) *Importer {
+	count := func(text string) int {
+		return (len(text) + charsPerToken - 1) / charsPerToken
+	}
+	if tok, err := tokenizer.New(); err == nil {
+		count = tok.CountTokens
+	}
+	return &Importer{provider: provider, count: count}
+}
+
+// Prepare fits the document text, called name, into maxTokens
+func (i *Importer) Prepare(ctx context.Context, name, text string, maxTokens int) (*Document, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+	doc := &Document{Name: name, Content: text, OriginalTokens: i.count(text)}
+	doc.Tokens = doc.OriginalTokens
+
+	for pass := 0; doc.Tokens > maxTokens && pass < maxPasses && i.provider != nil; pass++ {
+		chunks := i.split(doc.Content)
+		if pass > 0 && len(chunks) == 1 && doc.Chunks == 1 {
+			break // A summary of the whole that is still too long is cut short below
+		}
+		target := max(maxTokens/len(chunks), minSummaryTokens)
+		summaries := make([]string, 0, len(chunks))
+		for n, chunk := range chunks {
+			summary, err := i.summarize(ctx, name, chunk, n+1, len(chunks), target)
+			if err != nil {
+				return nil, err
+			}
+			summaries = append(summaries, summary)
+		}
+		doc.Content = strings.Join(summaries, "\n\n")
+		doc.Tokens = i.count(doc.Content)
+		doc.Chunks = max(doc.Chunks, len(chunks))
+	}
+
+	if doc.Tokens > maxTokens {
+		doc.Content = i.truncate(doc.Content, maxTokens)
+		doc.Tokens = i.count(doc.Content)
+		doc.Truncated = true
+		if doc.Chunks == 0 {
+			doc.Chunks = 1 // Not verbatim: the model is told to read the original
+		}
+	}
+	return doc, nil
+}
+
+// summarize asks the provider for a summary of chunk n of total in about
+// target tokens
+func (i *Importer) summarize(ctx context.Context, name, chunk string, n, total, target int) (string, error) {
+	part := ""
+	if total > 1 {
+		part = fmt.Sprintf("part %d of %d of ", n, total)
+	}
+	prompt := fmt.Sprintf("Summarize this %s%s in at most %d words.\n\n%s", part, name, target*3/4, chunk)
+	response, err := i.provider.Complete(ctx, []*types.Message{
+		types.NewSystemMessage(summarizePrompt),
+		types.NewUserMessage(prompt),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize %s: %w", name, err)
+	}
+	return strings.TrimSpace(response.Content), nil
+}
+
+// split divides text into chunks of at most maxChunkTokens, breaking before
+// headings and at blank lines where it can, and between lines where it must
+func (i *Importer) split(text string) []string {
+	var chunks []string
+	var current []string
+	tokens := 0
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.TrimSpace(strings.Join(current, "\n")))
+			current, tokens = nil, 0
+		}
+	}
+
+	for _, block := range blocks(text) {
+		size := i.count(block)
+		if tokens+size > maxChunkTokens || (strings.HasPrefix(block, "#") && tokens > maxChunkTokens/2) {
+			flush()
+		}
+		if size <= maxChunkTokens {
+			current = append(current, block)
+			tokens += size
+			continue
+		}
+		// A block too long for a chunk of its own is split between lines
+		for _, line := range strings.Split(block, "\n") {
+			lineTokens := i.count(line)
+			if tokens+lineTokens > maxChunkTokens {
+				flush()
+			}
+			current = append(current, line)
+			tokens += lineTokens
+		}
+	}
+	flush()
+	return chunks
+}
+
+// blocks splits text into paragraphs at blank lines, each ending with the
+// blank line that followed it
+func blocks(text string) []string {
+	var result []string
+	var current []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				result = append(result, strings.Join(current, "\n")+"\n")
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		result = append(result, strings.Join(current, "\n"))
+	}
+	return result
+}
+
+// truncate keeps the lines of text that fit in maxTokens, noting the cut
+func (i *Importer) truncate(text string, maxTokens int) string {
+	const note = "\n\n[Cut short to fit the import budget]"
+	budget := maxTokens - i.count(note)
+	var kept []string
+	tokens := 0
+	for _, line := range strings.Split(text, "\n") {
+		lineTokens := i.count(line + "\n")
+		if tokens+lineTokens > budget {
+			break
+		}
+		kept = append(kept, line)
+		tokens += lineTokens
+	}
+	return strings.Join(kept, "\n") + note
+}
@@ -0,0 +1,138 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// summaryProvider answers each summary request with a fixed summary
+type summaryProvider struct {
+	summary string
+	err     error
+	prompts []string
+}
+
+func (p *summaryProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	return nil, nil
+}
+
+func (p *summaryProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	p.prompts = append(p.prompts, messages[len(messages)-1].Content)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return types.NewAssistantMessage(p.summary), nil
+}
+
+func (p *summaryProvider) GetModelInfo() *types.ModelInfo {
+	return nil
+}
+
+// newTestImporter returns an importer counting one token per word
+func newTestImporter(provider llm.Provider) *Importer {
+	return &Importer{provider: provider, count: func(text string) int {
+		return len(strings.Fields(text))
+	}}
+}
+
+// document returns a Markdown document of sections of words each
+func document(sections, words int) string {
+	var b strings.Builder
+	for s := 1; s <= sections; s++ {
+		fmt.Fprintf(&b, "## Section %d\n\n%s\n\n", s, strings.TrimSpace(strings.Repeat("word ", words)))
+	}
+	return b.String()
+}
+
+func TestPrepareKeepsDocumentThatFits(t *testing.T) {
+	provider := &summaryProvider{}
+	doc, err := newTestImporter(provider).Prepare(context.Background(), "notes.md", "# Notes\n\nUse Postgres.", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Summarized() || len(provider.prompts) != 0 {
+		t.Errorf("expected a document that fits to be kept verbatim, got %+v", doc)
+	}
+	msg := doc.Message()
+	if !strings.Contains(msg.Content, "<document name=\"notes.md\">\n# Notes\n\nUse Postgres.\n</document>") {
+		t.Errorf("expected the message to hold the document, got %q", msg.Content)
+	}
+	if msg.Role != types.RoleUser || msg.Metadata[DocumentKey] != "notes.md" {
+		t.Errorf("expected a user message naming the document, got %+v", msg)
+	}
+	if doc.Summary() != "notes.md (4 tokens)" {
+		t.Errorf("unexpected summary %q", doc.Summary())
+	}
+}
+
+func TestPrepareSummarizesChunks(t *testing.T) {
+	provider := &summaryProvider{summary: "Short summary."}
+	// Four sections of 5000 words make three chunks of at most maxChunkTokens
+	doc, err := newTestImporter(provider).Prepare(context.Background(), "design.md", document(4, 5000), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Chunks != len(provider.prompts) || doc.Chunks < 3 {
+		t.Fatalf("expected each chunk to be summarized, got %d chunks and %d calls", doc.Chunks, len(provider.prompts))
+	}
+	if !strings.Contains(provider.prompts[0], "part 1 of") || !strings.Contains(provider.prompts[0], "design.md") {
+		t.Errorf("expected the prompt to name the part and document, got %q", provider.prompts[0][:80])
+	}
+	if doc.Tokens > 1000 || doc.Truncated {
+		t.Errorf("expected the summaries to fit, got %+v", doc)
+	}
+	if !strings.Contains(doc.Message().Content, "summarized from") {
+		t.Error("expected the message to say the document was summarized")
+	}
+}
+
+func TestPrepareCutsShortWhatStillDoesNotFit(t *testing.T) {
+	provider := &summaryProvider{summary: strings.Repeat("verbose ", 500)}
+	doc, err := newTestImporter(provider).Prepare(context.Background(), "spec.md", document(1, 2000), 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Truncated || doc.Tokens > 300 {
+		t.Errorf("expected the summary to be cut short to fit, got %d tokens", doc.Tokens)
+	}
+	if !strings.HasSuffix(doc.Content, "[Cut short to fit the import budget]") {
+		t.Error("expected the cut to be noted")
+	}
+
+	// Without a provider the document itself is cut short
+	doc, err = newTestImporter(nil).Prepare(context.Background(), "spec.md", document(1, 2000), 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Truncated || !doc.Summarized() {
+		t.Errorf("expected the document to be cut short and not reported as verbatim, got %+v", doc)
+	}
+}
+
+func TestPrepareErrors(t *testing.T) {
+	provider := &summaryProvider{err: errors.New("rate limited")}
+	if _, err := newTestImporter(provider).Prepare(context.Background(), "a.md", document(2, 500), 100); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("expected the summarization error, got %v", err)
+	}
+	if _, err := newTestImporter(provider).Prepare(context.Background(), "empty.md", " \n", 100); err == nil {
+		t.Error("expected an empty document to be rejected")
+	}
+}
+
+func TestBudget(t *testing.T) {
+	if got := Budget(128000, 0.25); got != 32000 {
+		t.Errorf("expected a quarter of the window, got %d", got)
+	}
+	if got := Budget(128000, 2); got != 32000 {
+		t.Errorf("expected an invalid share to use the default, got %d", got)
+	}
+	if got := Budget(0, 0.5); got != defaultBudget {
+		t.Errorf("expected the default budget for an unknown window, got %d", got)
+	}
+}
@@ -26,6 +26,7 @@ type Handler struct {
 	tracker         *git.ModificationTracker
 	commitGenerator *git.CommitMessageGenerator
 	prGenerator     *git.PRGenerator
+	attribution     git.Attribution
 }
 
 func NewHandler(
@@ -33,15 +34,27 @@ func NewHandler(
 	tracker *git.ModificationTracker,
 	commitGen *git.CommitMessageGenerator,
 	prGen *git.PRGenerator,
+	attribution git.Attribution,
 ) *Handler {
 	return &Handler{
 		workingDir:      workingDir,
 		tracker:         tracker,
 		commitGenerator: commitGen,
 		prGenerator:     prGen,
+		attribution:     attribution,
 	}
 }
 
+// CommitMessage returns message as it will be committed, with attribution trailers
+func (h *Handler) CommitMessage(ctx context.Context, message string) string {
+	return h.attribution.Message(ctx, h.workingDir, message)
+}
+
+// Committer returns the identity commits are made with, or "" for git's configured committer
+func (h *Handler) Committer(ctx context.Context) string {
+	return h.attribution.Committer(ctx, h.workingDir)
+}
+
 func Parse(input string) (*Command, bool) {
 	trimmed := strings.TrimSpace(input)
 	if !strings.HasPrefix(trimmed, "/") {
@@ -73,7 +86,7 @@ func (h *Handler) Execute(ctx context.Context, cmd *Command) (string, error) {
 
 func (h *Handler) handleCommit(ctx context.Context, customMessage string) (string, error) {
 	// Get modified files from git status instead of tracker
-	files, err := git.GetModifiedFiles(h.workingDir)
+	files, err := git.GetModifiedFiles(ctx, h.workingDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to get modified files: %w", err)
 	}
@@ -82,7 +95,7 @@ func (h *Handler) handleCommit(ctx context.Context, customMessage string) (strin
 		return "", fmt.Errorf("no files to commit")
 	}
 
-	if stageErr := git.StageFiles(h.workingDir, files); stageErr != nil {
+	if stageErr := git.StageFiles(ctx, h.workingDir, files); stageErr != nil {
 		return "", stageErr
 	}
 
@@ -97,7 +110,7 @@ func (h *Handler) handleCommit(ctx context.Context, customMessage string) (strin
 		message = customMessage
 	}
 
-	hash, err := git.CreateCommit(h.workingDir, message)
+	hash, err := git.CreateCommit(ctx, h.workingDir, message, h.attribution)
 	if err != nil {
 		return "", err
 	}
@@ -111,17 +124,17 @@ func (h *Handler) handleCommit(ctx context.Context, customMessage string) (strin
 }
 
 func (h *Handler) handlePR(ctx context.Context, customTitle string) (string, error) {
-	base, err := git.DetectBaseBranch(h.workingDir)
+	base, err := git.DetectBaseBranch(ctx, h.workingDir)
 	if err != nil {
 		return "", err
 	}
 
-	head, err := h.getCurrentBranch()
+	head, err := h.getCurrentBranch(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	commits, err := git.GetCommitsSinceBase(h.workingDir, base, head)
+	commits, err := git.GetCommitsSinceBase(ctx, h.workingDir, base, head)
 	if err != nil {
 		return "", err
 	}
@@ -130,7 +143,7 @@ func (h *Handler) handlePR(ctx context.Context, customTitle string) (string, err
 		return "", fmt.Errorf("no commits for PR")
 	}
 
-	diffSummary, err := git.GetDiffSummary(h.workingDir, base, head)
+	diffSummary, err := git.GetDiffSummary(ctx, h.workingDir, base, head)
 	if err != nil {
 		return "", err
 	}
@@ -141,7 +154,7 @@ func (h *Handler) handlePR(ctx context.Context, customTitle string) (string, err
 	}
 
 	// Create the PR on GitHub
-	prURL, err := git.CreatePR(h.workingDir, prContent.Title, prContent.Description, base, head)
+	prURL, err := git.CreatePR(ctx, h.workingDir, prContent.Title, prContent.Description, base, head)
 	if err != nil {
 		return "", fmt.Errorf("failed to create PR: %w", err)
 	}
@@ -155,8 +168,8 @@ func (h *Handler) handlePR(ctx context.Context, customTitle string) (string, err
 	return result.String(), nil
 }
 
-func (h *Handler) getCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+func (h *Handler) getCurrentBranch(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = h.workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/risk"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// processToolCalls handles the tool calls of one response in order. A single
+// call goes through processToolCall. Several are parsed up front, so a
+// malformed one doesn't leave the response half run; the calls that need
+// the user are approved together, then each runs in turn. The batch stops at
+// the first call that doesn't run successfully or ends the loop, and the
+// model is told which calls were skipped.
+// Returns (shouldContinue, errorContext) following the same pattern as executeIteration
+func (a *DefaultAgent) processToolCalls(ctx context.Context, toolCallContents []string) (bool, string) {
+	if len(toolCallContents) <= 1 {
+		var content string
+		if len(toolCallContents) == 1 {
+			content = toolCallContents[0]
+		}
+		return a.processToolCall(ctx, content)
+	}
+
+	if ctx.Err() != nil {
+		return false, "" // Stop silently - user requested cancellation
+	}
+
+	toolCalls := make([]tools.ToolCall, len(toolCallContents))
+	for i, content := range toolCallContents {
+		toolCall, shouldContinue, errCtx := a.parseToolCallXML(content)
+		if shouldContinue && errCtx == "" {
+			shouldContinue, errCtx = a.validateToolCallFields(&toolCall)
+		}
+		if !shouldContinue || errCtx != "" {
+			if shouldContinue {
+				a.memory.Add(types.NewUserMessage(fmt.Sprintf(
+					"None of the %d tool calls in your response were run, because tool call %d is invalid.", len(toolCallContents), i+1)))
+			}
+			return shouldContinue, errCtx
+		}
+		toolCalls[i] = toolCall
+	}
+
+	approved, shouldExecute, rejectionCtx := a.approveToolCalls(ctx, toolCalls)
+	if !shouldExecute {
+		return true, rejectionCtx
+	}
+
+	for i, toolCall := range toolCalls {
+		if i > 0 {
+			if ctx.Err() != nil {
+				return false, ""
+			}
+			// Leave the budget exceeded report to the loop
+			if a.budget != nil && a.budget.exceeded() != nil {
+				a.skipToolCalls(toolCalls[i:], "the turn's budget ran out")
+				return true, ""
+			}
+		}
+
+		executed, shouldContinue, errCtx := a.runToolCall(ctx, toolCall, approved[i])
+		if rest := toolCalls[i+1:]; len(rest) > 0 && (!executed || !shouldContinue) {
+			reason := fmt.Sprintf("'%s' did not complete", toolCall.ToolName)
+			if executed {
+				reason = fmt.Sprintf("'%s' ended the turn", toolCall.ToolName)
+			}
+			a.skipToolCalls(rest, reason)
+		}
+		if !executed || !shouldContinue || errCtx != "" {
+			return shouldContinue, errCtx
+		}
+	}
+	return true, ""
+}
+
+// approveToolCalls asks the user about the calls of a batch that need
+// approval in one request, so a response that edits a file and then runs the
+// tests is one decision rather than two. With fewer than two such calls,
+// each is asked about as it runs. A call whose preview can't be generated
+// yet, for example because an earlier call creates the file it edits, is
+// also asked about as it runs.
+// Returns (approved, shouldExecute, errorContext): approved holds the
+// previews of the calls the user approved, nil for the others; shouldExecute is false if the batch was rejected or
+// timed out, and errorContext carries the user's rejection feedback.
+func (a *DefaultAgent) approveToolCalls(ctx context.Context, toolCalls []tools.ToolCall) ([]*tools.ToolPreview, bool, string) {
+	approved := make([]*tools.ToolPreview, len(toolCalls))
+
+	var batch []tools.ToolCall
+	var previews []*tools.ToolPreview
+	var indexes []int
+	for i, toolCall := range toolCalls {
+		tool, ok := a.getTool(toolCall.ToolName)
+		if !ok {
+			continue
+		}
+		previewable, ok := tool.(tools.Previewable)
+		if !ok || a.approvalManager.AutoApproves(toolCall) {
+			continue
+		}
+		preview, err := previewable.GeneratePreview(ctx, toolCall.GetArgumentsXML())
+		if err != nil {
+			continue
+		}
+		batch = append(batch, toolCall)
+		previews = append(previews, preview)
+		indexes = append(indexes, i)
+	}
+	if len(batch) < 2 {
+		return approved, true, ""
+	}
+
+	preview := batchPreview(batch, previews)
+	assessments := make([]*risk.Assessment, len(previews))
+	for j, p := range previews {
+		assessments[j] = risk.Assess(p)
+	}
+	attachAssessment(preview, risk.Combine(assessments...))
+	if a.injectionSuspected {
+		preview.Description = injectionApprovalNotice + preview.Description
+	}
+
+	ok, timedOut, feedback := a.approvalManager.RequestBatchApproval(ctx, batch, preview)
+	if ok {
+		for j, i := range indexes {
+			approved[i] = previews[j]
+		}
+		return approved, true, ""
+	}
+
+	// None of the batch runs; note the decision for each call in the audit log
+	decision := audit.ApprovalRejected
+	if timedOut {
+		decision = audit.ApprovalTimedOut
+	}
+	for j, toolCall := range batch {
+		tool, _ := a.getTool(toolCall.ToolName)
+		rec := a.beginAudit(tool, toolCall)
+		rec.decide(decision, feedback)
+		rec.assess(assessments[j])
+		a.finishAudit(rec, false, "", nil)
+	}
+
+	names := toolNames(batch)
+	notRun := fmt.Sprintf("None of the %d tool calls in your response were executed.", len(toolCalls))
+	switch {
+	case timedOut:
+		a.memory.Add(types.NewUserMessage(fmt.Sprintf("Approval of %s timed out after %v. %s", names, a.approvalTimeout, notRun)))
+		return nil, false, ""
+	case feedback == "":
+		a.memory.Add(types.NewUserMessage(fmt.Sprintf("Tool calls %s were rejected by user. %s", names, notRun)))
+		return nil, false, ""
+	}
+
+	a.memory.Add(types.NewUserMessage(formatRejectionFeedback(names, preview.Title, feedback) + "\n" + notRun))
+	return nil, false, prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+		Type:     prompts.ErrorTypeToolRejected,
+		ToolName: names,
+		Target:   preview.Title,
+		Feedback: feedback,
+	})
+}
+
+// batchPreview combines the previews of a batch of tool calls into one, in
+// the order the calls run
+func batchPreview(toolCalls []tools.ToolCall, previews []*tools.ToolPreview) *tools.ToolPreview {
+	var description, content strings.Builder
+	description.WriteString("These tool calls run in order if approved:\n")
+	for i, preview := range previews {
+		heading := fmt.Sprintf("%d. %s", i+1, toolCalls[i].ToolName)
+		if preview.Title != "" {
+			heading += ": " + preview.Title
+		}
+		fmt.Fprintf(&description, "%s\n", heading)
+		fmt.Fprintf(&content, "── %s ──\n%s\n\n", heading, strings.TrimRight(preview.Content, "\n"))
+	}
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeBatch,
+		Title:       fmt.Sprintf("%d tool calls", len(previews)),
+		Description: strings.TrimRight(description.String(), "\n"),
+		Content:     strings.TrimRight(content.String(), "\n"),
+	}
+}
+
+// skipToolCalls tells the model which calls of its response weren't run
+func (a *DefaultAgent) skipToolCalls(skipped []tools.ToolCall, reason string) {
+	a.memory.Add(types.NewUserMessage(formatSkippedToolCalls(skipped, reason)))
+}
+
+// formatSkippedToolCalls renders skipped tool calls as a memory message, e.g.
+// "Skipped the remaining 2 tool call(s) of your response (apply_diff,
+// execute_command) because 'read_file' did not complete. ..."
+func formatSkippedToolCalls(skipped []tools.ToolCall, reason string) string {
+	return fmt.Sprintf("Skipped the remaining %d tool call(s) of your response (%s) because %s. "+
+		"Call them again if they are still needed.", len(skipped), toolNames(skipped), reason)
+}
+
+// toolNames lists the names of tool calls, e.g. "apply_diff, execute_command"
+func toolNames(toolCalls []tools.ToolCall) string {
+	names := make([]string, len(toolCalls))
+	for i, toolCall := range toolCalls {
+		names[i] = toolCall.ToolName
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// batchRecorder records the tool calls that ran, in order
+type batchRecorder struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (r *batchRecorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ran = append(r.ran, name)
+}
+
+func (r *batchRecorder) calls() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.ran, ",")
+}
+
+// batchTool is a test tool recording its runs, optionally failing
+type batchTool struct {
+	summaryTestTool
+	recorder *batchRecorder
+	err      error
+}
+
+func (t *batchTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	t.recorder.record(t.name)
+	return "ok", t.err
+}
+
+// batchWriteTool is a batchTool that needs approval
+type batchWriteTool struct {
+	batchTool
+}
+
+func (t *batchWriteTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	return &tools.ToolPreview{Type: tools.PreviewTypeDiff, Title: "Edit main.go", Content: "+edited"}, nil
+}
+
+// newBatchTestAgent creates an agent with read, write, test, failing and
+// loop-breaking tools recording their runs
+func newBatchTestAgent(t *testing.T) (*DefaultAgent, func() []*types.AgentEvent, *batchRecorder) {
+	t.Helper()
+	a, collected := newRunnerTestAgent()
+	recorder := &batchRecorder{}
+	a.tools["read_file"] = &batchTool{summaryTestTool: summaryTestTool{name: "read_file"}, recorder: recorder}
+	a.tools["write_file"] = &batchWriteTool{batchTool{summaryTestTool: summaryTestTool{name: "write_file"}, recorder: recorder}}
+	a.tools["execute_command"] = &batchWriteTool{batchTool{summaryTestTool: summaryTestTool{name: "execute_command"}, recorder: recorder}}
+	a.tools["broken"] = &batchTool{summaryTestTool: summaryTestTool{name: "broken"}, recorder: recorder, err: errors.New("boom")}
+	a.tools["task_completion"] = &batchTool{summaryTestTool: summaryTestTool{name: "task_completion", loopBreaking: true}, recorder: recorder}
+	return a, collected, recorder
+}
+
+func toolCallContent(name, args string) string {
+	return "<server_name>local</server_name><tool_name>" + name + "</tool_name><arguments>" + args + "</arguments>"
+}
+
+// approvalRequests counts the approval requests among events
+func approvalRequests(events []*types.AgentEvent) int {
+	var n int
+	for _, ev := range events {
+		if ev.Type == types.EventTypeToolApprovalRequest {
+			n++
+		}
+	}
+	return n
+}
+
+// lastMemory returns the content of the last message in memory
+func lastMemory(a *DefaultAgent) string {
+	messages := a.memory.GetAll()
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[len(messages)-1].Content
+}
+
+func TestProcessToolCalls_RunsInOrder(t *testing.T) {
+	a, collected, recorder := newBatchTestAgent(t)
+	answerApprovals(a, collected, types.ApprovalGranted, "")
+
+	shouldContinue, errCtx := a.processToolCalls(context.Background(), []string{
+		toolCallContent("read_file", "<path>main.go</path>"),
+		toolCallContent("write_file", "<path>main.go</path>"),
+		toolCallContent("execute_command", "<command>go test</command>"),
+	})
+	if !shouldContinue || errCtx != "" {
+		t.Fatalf("expected the loop to continue, got %v %q", shouldContinue, errCtx)
+	}
+	if got := recorder.calls(); got != "read_file,write_file,execute_command" {
+		t.Errorf("expected every call to run in order, got %q", got)
+	}
+	if n := approvalRequests(collected()); n != 1 {
+		t.Errorf("expected one approval request for the batch, got %d", n)
+	}
+
+	var results int
+	for _, msg := range a.memory.GetAll() {
+		if strings.HasPrefix(msg.Content, "Tool '") {
+			results++
+		}
+	}
+	if results != 3 {
+		t.Errorf("expected a result in memory for each call, got %d", results)
+	}
+}
+
+func TestProcessToolCalls_RejectedBatchRunsNothing(t *testing.T) {
+	a, collected, recorder := newBatchTestAgent(t)
+	answerApprovals(a, collected, types.ApprovalRejected, "not yet")
+
+	shouldContinue, errCtx := a.processToolCalls(context.Background(), []string{
+		toolCallContent("read_file", "<path>main.go</path>"),
+		toolCallContent("write_file", "<path>main.go</path>"),
+		toolCallContent("execute_command", "<command>go test</command>"),
+	})
+	if !shouldContinue || !strings.Contains(errCtx, "not yet") {
+		t.Fatalf("expected the rejection feedback for the next iteration, got %v %q", shouldContinue, errCtx)
+	}
+	if got := recorder.calls(); got != "" {
+		t.Errorf("expected nothing to run, got %q", got)
+	}
+	if got := lastMemory(a); !strings.Contains(got, "None of the 3 tool calls") {
+		t.Errorf("expected the model to be told nothing ran, got %q", got)
+	}
+}
+
+func TestProcessToolCalls_StopsAtFailure(t *testing.T) {
+	a, _, recorder := newBatchTestAgent(t)
+
+	shouldContinue, errCtx := a.processToolCalls(context.Background(), []string{
+		toolCallContent("broken", ""),
+		toolCallContent("read_file", "<path>main.go</path>"),
+	})
+	if !shouldContinue || errCtx == "" {
+		t.Fatalf("expected error context for the failure, got %v %q", shouldContinue, errCtx)
+	}
+	if got := recorder.calls(); got != "broken" {
+		t.Errorf("expected the calls after the failure to be skipped, got %q", got)
+	}
+	if got := lastMemory(a); !strings.Contains(got, "Skipped the remaining 1 tool call(s) of your response (read_file) because 'broken' did not complete") {
+		t.Errorf("expected the model to be told what was skipped, got %q", got)
+	}
+}
+
+func TestProcessToolCalls_StopsAtLoopBreakingTool(t *testing.T) {
+	a, _, recorder := newBatchTestAgent(t)
+
+	shouldContinue, _ := a.processToolCalls(context.Background(), []string{
+		toolCallContent("task_completion", "<result>done</result>"),
+		toolCallContent("read_file", "<path>main.go</path>"),
+	})
+	if shouldContinue {
+		t.Error("expected the loop to end")
+	}
+	if got := recorder.calls(); got != "task_completion" {
+		t.Errorf("expected the calls after the loop-breaking tool to be skipped, got %q", got)
+	}
+}
+
+func TestProcessToolCalls_InvalidCallRunsNothing(t *testing.T) {
+	a, _, recorder := newBatchTestAgent(t)
+
+	shouldContinue, errCtx := a.processToolCalls(context.Background(), []string{
+		toolCallContent("read_file", "<path>main.go</path>"),
+		"<server_name>local</server_name><arguments></arguments>",
+	})
+	if !shouldContinue || errCtx == "" {
+		t.Fatalf("expected error context for the invalid call, got %v %q", shouldContinue, errCtx)
+	}
+	if got := recorder.calls(); got != "" {
+		t.Errorf("expected nothing to run, got %q", got)
+	}
+}
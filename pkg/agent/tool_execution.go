@@ -4,28 +4,45 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/entrhq/forge/pkg/agent/audit"
 	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/risk"
 	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/injection"
 	"github.com/entrhq/forge/pkg/tools/coding"
 	"github.com/entrhq/forge/pkg/types"
 )
 
 // executeToolCall emits events, executes the tool, and handles execution errors
 // Returns (result, shouldContinue, errorContext)
-func (a *DefaultAgent) executeToolCall(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall) (string, bool, string) {
+func (a *DefaultAgent) executeToolCall(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall, rec *auditRecord) (*tools.Result, bool, string) {
 	// Emit tool call event
-	var argsMap map[string]interface{}
-	if err := tools.UnmarshalXMLWithFallback(toolCall.GetArgumentsXML(), &argsMap); err != nil {
-		argsMap = make(map[string]interface{})
+	a.emitEvent(types.NewToolCallEvent(toolCall.ToolName, toolArguments(toolCall)))
+
+	// Count the call against the turn's tool call budget
+	if a.budget != nil {
+		a.budget.toolCalls++
 	}
-	a.emitEvent(types.NewToolCallEvent(toolCall.ToolName, argsMap))
 
 	// Inject event emitter and command registry into context for tools that support streaming events
 	ctxWithEmitter := context.WithValue(ctx, coding.EventEmitterKey, coding.EventEmitter(a.emitEvent))
 	ctxWithRegistry := context.WithValue(ctxWithEmitter, coding.CommandRegistryKey, &a.activeCommands)
 
-	// Execute the tool
-	result, toolErr := tool.Execute(ctxWithRegistry, toolCall.GetArgumentsXML())
+	// Let tools page long output through the shared pager (fetched via get_more)
+	ctxWithPager := tools.WithResultPager(ctxWithRegistry, a.resultPager)
+
+	// Execute the tool under its timeout, emitting heartbeats while it runs
+	result, toolErr := a.runTool(ctxWithPager, tool, toolCall)
+	if a.turn != nil {
+		a.turn.recordTool(tool, toolCall, toolErr)
+	}
+	a.recordToolStats(toolCall, toolErr)
+	a.finishAudit(rec, true, result.String(), toolErr)
+	if toolErr == nil {
+		a.trackModification(tool, toolCall, result)
+		a.observeEdit(tool, toolCall)
+	}
+	a.runPostToolHooks(ctx, toolCall, result.String(), toolErr)
 	if toolErr != nil {
 		a.emitEvent(types.NewToolResultErrorEvent(toolCall.ToolName, toolErr))
 		errMsg := prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
@@ -36,20 +53,22 @@ func (a *DefaultAgent) executeToolCall(ctx context.Context, tool tools.Tool, too
 
 		// Track error and check circuit breaker
 		if a.trackError(errMsg) {
-			a.emitEvent(types.NewErrorEvent(fmt.Errorf("circuit breaker triggered: 5 consecutive tool execution errors")))
-			return "", false, ""
+			a.emitEvent(types.NewErrorEvent(circuitBreakerError(types.ErrorCodeToolFailure, "tool execution")))
+			return nil, false, ""
 		}
 
-		a.emitEvent(types.NewErrorEvent(fmt.Errorf("tool execution failed: %w", toolErr)))
-		return "", true, errMsg
+		err := types.WrapError(types.ErrorCodeToolFailure, fmt.Errorf("tool execution failed: %w", toolErr))
+		a.emitEvent(types.NewErrorEvent(err.WithMetadata("tool", toolCall.ToolName)))
+		return nil, true, errMsg
 	}
 
 	return result, true, ""
 }
 
-// processToolResult handles successful tool execution results
+// processToolResult handles successful tool execution results. The event
+// carries the whole Result for executors; memory gets its Output.
 // Returns (shouldContinue, errorContext)
-func (a *DefaultAgent) processToolResult(tool tools.Tool, toolCall tools.ToolCall, result string) (bool, string) {
+func (a *DefaultAgent) processToolResult(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall, result *tools.Result) (bool, string) {
 	a.emitEvent(types.NewToolResultEvent(toolCall.ToolName, result))
 
 	// Success! Reset error tracking
@@ -60,19 +79,66 @@ func (a *DefaultAgent) processToolResult(tool tools.Tool, toolCall tools.ToolCal
 		return false, ""
 	}
 
-	// For non-breaking tools, add result to memory and continue loop
-	a.memory.Add(types.NewUserMessage(fmt.Sprintf("Tool '%s' result:\n%s", toolCall.ToolName, result)))
+	// For non-breaking tools, add result to memory (delimited as untrusted data)
+	// and continue loop
+	a.memory.Add(types.NewUserMessage(fmt.Sprintf("Tool '%s' result:\n%s", toolCall.ToolName, injection.Wrap(toolCall.ToolName, result.Output))))
+	a.checkInjection(toolCall.ToolName, result.Output)
 	return true, ""
 }
 
+// checkConsistency runs the consistency checker (if enabled) over the turn's
+// changes and records any likely dangling references in memory, where the
+// model sees them on its next turn
+func (a *DefaultAgent) checkConsistency(ctx context.Context) {
+	if a.consistencyChecker == nil {
+		return
+	}
+
+	report, err := a.consistencyChecker.Check(ctx)
+	if err != nil {
+		agentDebugLog.Printf("Consistency check failed: %v", err)
+		return
+	}
+	if report != nil {
+		a.memory.Add(types.NewUserMessage(report.Format()))
+	}
+}
+
+// injectionApprovalNotice prefixes approval previews while injection is suspected
+const injectionApprovalNotice = "⚠ Earlier tool output this turn contained suspected prompt injection; " +
+	"confirm this action is what you asked for.\n"
+
+// checkInjection scans a tool result for embedded instructions. When any are
+// found it warns the user, reminds the model that the output is data, and
+// requires explicit approval for every remaining tool call this turn.
+func (a *DefaultAgent) checkInjection(toolName, result string) {
+	findings := injection.Detect(result)
+	if len(findings) == 0 {
+		return
+	}
+
+	rules := injection.Rules(findings)
+	excerpts := make([]string, len(findings))
+	for i, f := range findings {
+		excerpts[i] = f.Excerpt
+	}
+	a.emitEvent(types.NewInjectionWarningEvent(toolName, rules, excerpts))
+	a.memory.Add(types.NewUserMessage(prompts.BuildInjectionWarningMessage(toolName, rules)))
+
+	a.injectionSuspected = true
+	a.approvalManager.SuspendAutoApproval(true)
+}
+
 // handleToolApproval checks if tool requires approval and handles the approval flow
-// Returns shouldExecute - false if approval was rejected/timed out, true otherwise
-func (a *DefaultAgent) handleToolApproval(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall) bool {
+// Returns (shouldExecute, errorContext) - shouldExecute is false if approval was rejected/timed out,
+// and errorContext carries the user's rejection feedback (if any) into the next iteration.
+// The decision is noted in rec for the audit log.
+func (a *DefaultAgent) handleToolApproval(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall, rec *auditRecord) (bool, string) {
 	// Check if tool requires approval
 	previewable, ok := tool.(tools.Previewable)
 	if !ok {
 		// No approval needed - proceed with execution
-		return true
+		return true, ""
 	}
 
 	// Generate preview
@@ -80,29 +146,73 @@ func (a *DefaultAgent) handleToolApproval(ctx context.Context, tool tools.Tool,
 	if err != nil {
 		// If preview generation fails, log error but continue with execution
 		// (degraded mode - execute without approval)
-		a.emitEvent(types.NewErrorEvent(fmt.Errorf("failed to generate preview for %s: %w", toolCall.ToolName, err)))
-		return true
+		a.emitEvent(types.NewErrorEvent(types.WrapError(types.ErrorCodeToolFailure, fmt.Errorf("failed to generate preview for %s: %w", toolCall.ToolName, err))))
+		rec.decide(audit.ApprovalNone, "")
+		return true, ""
+	}
+
+	// Size up the change for the approver and the audit log
+	rec.assess(attachAssessment(preview, risk.Assess(preview)))
+
+	// Make sure the user knows why a normally auto-approved action is asking
+	if a.injectionSuspected {
+		preview.Description = injectionApprovalNotice + preview.Description
 	}
 
 	// Request approval from user
-	approved, timedOut := a.requestApproval(ctx, toolCall, preview)
+	autoApproved := a.approvalManager.AutoApproves(toolCall)
+	approved, timedOut, feedback := a.requestApproval(ctx, toolCall, preview)
 
 	if timedOut {
+		rec.decide(audit.ApprovalTimedOut, "")
 		// Timeout - treat as rejection and continue loop without executing
 		errMsg := fmt.Sprintf("Tool approval request timed out after %v. The tool was not executed.", a.approvalTimeout)
 		a.memory.Add(types.NewUserMessage(errMsg))
-		return false
+		return false, ""
 	}
 
 	if !approved {
 		// User rejected - continue loop without executing
-		errMsg := fmt.Sprintf("Tool '%s' execution was rejected by user.", toolCall.ToolName)
-		a.memory.Add(types.NewUserMessage(errMsg))
-		return false
+		rec.decide(audit.ApprovalRejected, feedback)
+		if a.turn != nil {
+			a.turn.rejections++
+		}
+		if feedback == "" {
+			errMsg := fmt.Sprintf("Tool '%s' execution was rejected by user.", toolCall.ToolName)
+			a.memory.Add(types.NewUserMessage(errMsg))
+			return false, ""
+		}
+
+		// Record the reason as structured feedback and surface it to the next iteration
+		target := ""
+		if preview != nil {
+			target = preview.Title
+		}
+		a.memory.Add(types.NewUserMessage(formatRejectionFeedback(toolCall.ToolName, target, feedback)))
+		return false, prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+			Type:     prompts.ErrorTypeToolRejected,
+			ToolName: toolCall.ToolName,
+			Target:   target,
+			Feedback: feedback,
+		})
 	}
 
 	// User approved - continue with execution
-	return true
+	if autoApproved {
+		rec.decide(audit.ApprovalAuto, "")
+	} else {
+		rec.decide(audit.ApprovalUser, feedback)
+	}
+	return true, ""
+}
+
+// formatRejectionFeedback renders a rejection reason as a memory message,
+// e.g. "User rejected apply_diff (Apply 2 edit(s) to main.go) because: keep the old name"
+func formatRejectionFeedback(toolName, target, feedback string) string {
+	if target == "" {
+		return fmt.Sprintf("User rejected %s because: %s", toolName, feedback)
+	}
+	return fmt.Sprintf("User rejected %s (%s) because: %s", toolName, target, feedback)
 }
 
 // lookupTool retrieves a tool by name and handles lookup errors
@@ -118,11 +228,12 @@ func (a *DefaultAgent) lookupTool(toolName string) (tools.Tool, bool, string) {
 
 		// Track error and check circuit breaker
 		if a.trackError(errMsg) {
-			a.emitEvent(types.NewErrorEvent(fmt.Errorf("circuit breaker triggered: 5 consecutive unknown tool errors")))
+			a.emitEvent(types.NewErrorEvent(circuitBreakerError(types.ErrorCodeInvalidResponse, "unknown tool")))
 			return nil, false, ""
 		}
 
-		a.emitEvent(types.NewErrorEvent(fmt.Errorf("unknown tool: %s", toolName)))
+		err := types.WrapError(types.ErrorCodeInvalidResponse, fmt.Errorf("%w: %s", types.ErrToolNotFound, toolName))
+		a.emitEvent(types.NewErrorEvent(err.WithMetadata("tool", toolName)))
 		return nil, true, errMsg
 	}
 
@@ -132,24 +243,56 @@ func (a *DefaultAgent) lookupTool(toolName string) (tools.Tool, bool, string) {
 // executeTool handles tool lookup, execution, and result processing
 // Returns (shouldContinue, errorContext) following the same pattern as executeIteration
 func (a *DefaultAgent) executeTool(ctx context.Context, toolCall tools.ToolCall) (bool, string) {
+	_, shouldContinue, errCtx := a.runToolCall(ctx, toolCall, nil)
+	return shouldContinue, errCtx
+}
+
+// runToolCall is executeTool, additionally reporting whether the tool ran
+// successfully. A call with a preApproved preview was already approved by
+// the user as part of a batch (see approveToolCalls), so it isn't asked
+// about again.
+// Returns (executed, shouldContinue, errorContext)
+func (a *DefaultAgent) runToolCall(ctx context.Context, toolCall tools.ToolCall, preApproved *tools.ToolPreview) (bool, bool, string) {
 	// Look up the tool
 	tool, shouldContinue, errCtx := a.lookupTool(toolCall.ToolName)
 	if !shouldContinue || errCtx != "" {
-		return shouldContinue, errCtx
+		return false, shouldContinue, errCtx
+	}
+
+	// Start the audit entry before anything can change the workspace
+	rec := a.beginAudit(tool, toolCall)
+
+	// Let pre_tool hooks deny the call before the user is asked about it
+	if shouldExecute, blockedCtx := a.runPreToolHooks(ctx, toolCall); !shouldExecute {
+		rec.decide(audit.ApprovalBlocked, "")
+		a.finishAudit(rec, false, "", nil)
+		return false, true, blockedCtx
+	}
+
+	// Refuse writes to files that changed unseen or another agent is editing
+	if shouldExecute, conflictCtx := a.claimEditLock(tool, toolCall); !shouldExecute {
+		rec.decide(audit.ApprovalBlocked, "")
+		a.finishAudit(rec, false, "", nil)
+		return false, true, conflictCtx
 	}
 
 	// Handle tool approval if needed
-	if !a.handleToolApproval(ctx, tool, toolCall) {
+	if preApproved != nil {
+		rec.decide(audit.ApprovalUser, "")
+		rec.assess(risk.Assess(preApproved))
+	} else if shouldExecute, rejectionCtx := a.handleToolApproval(ctx, tool, toolCall, rec); !shouldExecute {
 		// Tool approval was rejected or timed out - continue loop without executing
-		return true, ""
+		a.finishAudit(rec, false, "", nil)
+		return false, true, rejectionCtx
 	}
 
 	// Execute the tool call
-	result, shouldContinue, errCtx := a.executeToolCall(ctx, tool, toolCall)
+	result, shouldContinue, errCtx := a.executeToolCall(ctx, tool, toolCall, rec)
 	if !shouldContinue || errCtx != "" {
-		return shouldContinue, errCtx
+		return false, shouldContinue, errCtx
 	}
 
 	// Process the successful result
-	return a.processToolResult(tool, toolCall, result)
+	shouldContinue, errCtx = a.processToolResult(ctx, tool, toolCall, result)
+	return true, shouldContinue, errCtx
 }
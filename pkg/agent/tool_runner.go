@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/google/uuid"
+)
+
+// defaultHeartbeatInterval is how often a heartbeat event is emitted while a tool runs
+const defaultHeartbeatInterval = 2 * time.Second
+
+// minProgressInterval rate-limits tool progress events so chatty tools can't
+// flood the event channel
+const minProgressInterval = 200 * time.Millisecond
+
+// toolOutcome carries the result of a tool execution across goroutines
+type toolOutcome struct {
+	result *tools.Result
+	err    error
+}
+
+// runTool executes a tool under its configured timeout.
+//
+// While the tool runs, heartbeat events are emitted so executors can show elapsed time.
+// The run is registered in activeCommands under a fresh execution ID, so a
+// CancellationRequest carrying that ID stops just this tool and the turn continues.
+// If the tool ignores cancellation (e.g. a hung filesystem call), runTool stops
+// waiting for it and returns an error; the abandoned goroutine finishes in the background.
+func (a *DefaultAgent) runTool(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall) (*tools.Result, error) {
+	timeout := a.getToolTimeout(toolCall.ToolName)
+
+	var execCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		execCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		execCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	executionID := uuid.New().String()
+	a.activeCommands.Store(executionID, cancel)
+	defer a.activeCommands.Delete(executionID)
+
+	// Let the tool describe its progress; descriptions arriving faster than
+	// minProgressInterval are dropped
+	var progressMu sync.Mutex
+	var lastProgress time.Time
+	execCtx = tools.WithProgressReporter(execCtx, func(description string) {
+		progressMu.Lock()
+		if time.Since(lastProgress) < minProgressInterval {
+			progressMu.Unlock()
+			return
+		}
+		lastProgress = time.Now()
+		progressMu.Unlock()
+		a.emitEvent(types.NewToolProgressEvent(toolCall.ToolName, description))
+	})
+
+	done := make(chan toolOutcome, 1)
+	go func() {
+		result, err := tools.Run(execCtx, tool, toolCall.GetArgumentsXML())
+		done <- toolOutcome{result: result, err: err}
+	}()
+
+	interval := a.heartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		select {
+		case outcome := <-done:
+			return outcome.result, outcome.err
+
+		case <-ticker.C:
+			a.emitEvent(types.NewToolHeartbeatEvent(executionID, toolCall.ToolName, time.Since(start), timeout))
+
+		case <-execCtx.Done():
+			// Prefer a result that raced with cancellation
+			select {
+			case outcome := <-done:
+				return outcome.result, outcome.err
+			default:
+			}
+
+			switch {
+			case ctx.Err() != nil:
+				return nil, ctx.Err()
+			case errors.Is(execCtx.Err(), context.DeadlineExceeded):
+				return nil, fmt.Errorf("%w: '%s' ran for %v and was canceled", types.ErrToolTimeout, toolCall.ToolName, timeout)
+			default:
+				return nil, fmt.Errorf("tool '%s' was canceled by user after %v", toolCall.ToolName, time.Since(start).Round(time.Second))
+			}
+		}
+	}
+}
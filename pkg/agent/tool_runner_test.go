@@ -0,0 +1,251 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// hangingTool blocks until released, ignoring context cancellation
+type hangingTool struct {
+	release chan struct{}
+}
+
+func (h *hangingTool) Name() string                   { return "hanging_tool" }
+func (h *hangingTool) Description() string            { return "blocks forever" }
+func (h *hangingTool) Schema() map[string]interface{} { return tools.BaseToolSchema(nil, nil) }
+func (h *hangingTool) IsLoopBreaking() bool           { return false }
+func (h *hangingTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	<-h.release
+	return "finished", nil
+}
+
+// newRunnerTestAgent creates an agent whose events are collected instead of sent to a channel
+func newRunnerTestAgent(opts ...AgentOption) (*DefaultAgent, func() []*types.AgentEvent) {
+	a := NewDefaultAgent(&mockProvider{}, opts...)
+
+	var mu sync.Mutex
+	var events []*types.AgentEvent
+	a.channels.Event = make(chan *types.AgentEvent, 100)
+	go func() {
+		for ev := range a.channels.Event {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		}
+	}()
+
+	return a, func() []*types.AgentEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*types.AgentEvent(nil), events...)
+	}
+}
+
+func TestRunTool_TimesOutHungTool(t *testing.T) {
+	a, collected := newRunnerTestAgent(WithToolHeartbeatInterval(10 * time.Millisecond))
+	tool := &hangingTool{release: make(chan struct{})}
+	defer close(tool.release)
+
+	if err := a.RegisterTool(tool, WithToolTimeout(60*time.Millisecond)); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	_, err := a.runTool(context.Background(), tool, tools.ToolCall{ToolName: tool.Name()})
+	if !errors.Is(err, types.ErrToolTimeout) {
+		t.Fatalf("runTool() error = %v, want timeout error", err)
+	}
+
+	// Allow the collector goroutine to drain
+	time.Sleep(20 * time.Millisecond)
+
+	heartbeats := 0
+	for _, ev := range collected() {
+		if ev.Type == types.EventTypeToolHeartbeat {
+			heartbeats++
+			if ev.ToolExecution == nil || ev.ToolExecution.ExecutionID == "" {
+				t.Error("heartbeat should carry an execution ID")
+			}
+		}
+	}
+	if heartbeats == 0 {
+		t.Error("expected heartbeat events while the tool was running")
+	}
+}
+
+// chattyTool reports progress until released
+type chattyTool struct {
+	release chan struct{}
+}
+
+func (c *chattyTool) Name() string                   { return "chatty_tool" }
+func (c *chattyTool) Description() string            { return "reports progress" }
+func (c *chattyTool) Schema() map[string]interface{} { return tools.BaseToolSchema(nil, nil) }
+func (c *chattyTool) IsLoopBreaking() bool           { return false }
+func (c *chattyTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	for i := 0; ; i++ {
+		select {
+		case <-c.release:
+			return "finished", nil
+		case <-time.After(time.Millisecond):
+			tools.ReportProgress(ctx, "step %d", i)
+		}
+	}
+}
+
+func TestRunTool_StalledConsumer(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{}, WithToolHeartbeatInterval(time.Millisecond))
+	a.channels.Event = make(chan *types.AgentEvent, 1) // Never read
+	tool := &chattyTool{release: make(chan struct{})}
+	if err := a.RegisterTool(tool); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(tool.release)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, err := a.runTool(context.Background(), tool, tools.ToolCall{ToolName: tool.Name()})
+		if err != nil || result.String() != "finished" {
+			t.Errorf("runTool() = %q, %v, want the tool's result", result, err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runTool() blocked on heartbeats and progress nobody was reading")
+	}
+	if len(a.channels.Event) != 1 {
+		t.Errorf("expected the buffered event to stay queued, got %d", len(a.channels.Event))
+	}
+}
+
+func TestRunTool_CancelByExecutionID(t *testing.T) {
+	a, collected := newRunnerTestAgent(WithToolHeartbeatInterval(10 * time.Millisecond))
+	tool := &hangingTool{release: make(chan struct{})}
+	defer close(tool.release)
+
+	if err := a.RegisterTool(tool); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	// Cancel the tool as soon as its first heartbeat reports an execution ID
+	go func() {
+		for {
+			for _, ev := range collected() {
+				if ev.Type == types.EventTypeToolHeartbeat {
+					a.handleCommandCancellation(&types.CancellationRequest{ExecutionID: ev.ToolExecution.ExecutionID})
+					return
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	ctx := context.Background()
+	_, err := a.runTool(ctx, tool, tools.ToolCall{ToolName: tool.Name()})
+	if err == nil || !strings.Contains(err.Error(), "canceled by user") {
+		t.Fatalf("runTool() error = %v, want user cancellation error", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("canceling a tool should not cancel the turn context")
+	}
+}
+
+func TestGetToolTimeout(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{}, WithDefaultToolTimeout(time.Minute))
+	tool := &hangingTool{}
+
+	if got := a.getToolTimeout(tool.Name()); got != time.Minute {
+		t.Errorf("getToolTimeout() = %v, want default %v", got, time.Minute)
+	}
+
+	if err := a.RegisterTool(tool, WithToolTimeout(5*time.Second)); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if got := a.getToolTimeout(tool.Name()); got != 5*time.Second {
+		t.Errorf("getToolTimeout() = %v, want %v", got, 5*time.Second)
+	}
+}
+
+func TestSetAllowedTools(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{})
+	for _, name := range []string{"read_file", "write_file"} {
+		if err := a.RegisterTool(&summaryTestTool{name: name}); err != nil {
+			t.Fatalf("RegisterTool() error = %v", err)
+		}
+	}
+
+	a.SetAllowedTools("read_file")
+	if _, ok := a.getTool("write_file"); ok {
+		t.Error("expected a tool that is not allowed to be unavailable")
+	}
+	for _, name := range []string{"read_file", "task_completion"} {
+		if _, ok := a.getTool(name); !ok {
+			t.Errorf("expected %s to stay available", name)
+		}
+	}
+	for _, tool := range a.getToolsList() {
+		if tool.Name() == "write_file" {
+			t.Error("expected a tool that is not allowed to be left out of the list")
+		}
+	}
+
+	a.SetAllowedTools()
+	if _, ok := a.getTool("write_file"); !ok {
+		t.Error("expected every tool to be allowed again")
+	}
+}
+
+// structuredResultTool returns a structured result
+type structuredResultTool struct {
+	summaryTestTool
+}
+
+func (s *structuredResultTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	return &tools.Result{
+		Success:   true,
+		Summary:   "Read main.go",
+		Output:    "1 | package main",
+		Artifacts: []tools.Artifact{{Kind: tools.ArtifactFile, Path: "main.go", Line: 1}},
+	}, nil
+}
+
+func TestExecuteTool_StructuredResult(t *testing.T) {
+	a, collected := newRunnerTestAgent()
+	a.tools["read_file"] = &structuredResultTool{summaryTestTool{name: "read_file"}}
+
+	if shouldContinue, errCtx := a.executeTool(context.Background(), toolCallWithArgs("read_file", "")); !shouldContinue || errCtx != "" {
+		t.Fatalf("executeTool() = %v, %q", shouldContinue, errCtx)
+	}
+	if got := lastMemory(a); !strings.Contains(got, "1 | package main") || strings.Contains(got, "Read main.go") {
+		t.Errorf("expected memory to hold the result's output only, got %q", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, ev := range collected() {
+			if ev.Type != types.EventTypeToolResult {
+				continue
+			}
+			result, ok := ev.ToolOutput.(*tools.Result)
+			if !ok || result.Summary != "Read main.go" || len(result.Artifacts) != 1 {
+				t.Fatalf("expected the structured result in the event, got %#v", ev.ToolOutput)
+			}
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no tool result event")
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// maxToolStatsLines bounds how many failing tools the tool stats report lists
+const maxToolStatsLines = 5
+
+// WithToolStats counts each tool call and whether it failed in collector, and
+// adds a compact report of the session's tool results to the system prompt
+// each turn (e.g. "apply_diff: 3 of 7 calls failed (3 on parser.go; ...)"),
+// so the model can change strategy where it keeps failing
+func WithToolStats(collector *metrics.Collector) AgentOption {
+	return func(a *DefaultAgent) {
+		a.toolStats = collector
+	}
+}
+
+// recordToolStats counts a tool call, by the path it acted on if any
+func (a *DefaultAgent) recordToolStats(toolCall tools.ToolCall, err error) {
+	if a.toolStats == nil {
+		return
+	}
+	var args turnToolArgs
+	_ = tools.UnmarshalXMLWithFallback(toolCall.GetArgumentsXML(), &args)
+	a.toolStats.RecordToolCall(toolCall.ToolName, args.Path, err)
+}
+
+// refreshToolStats renders the report for the turn that is starting. It stays
+// fixed for the turn so the system prompt doesn't change between iterations,
+// which would defeat provider prompt caching.
+func (a *DefaultAgent) refreshToolStats() {
+	if a.toolStats == nil {
+		return
+	}
+	a.toolStatsReport = a.toolStats.ToolReport(maxToolStatsLines)
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/metrics"
+)
+
+func TestToolStats_ReportedEachTurn(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{}, WithToolStats(metrics.NewCollector(nil)))
+
+	a.recordToolStats(toolCallWithArgs("apply_diff", "<path>parser.go</path><diff>x</diff>"), errors.New("search text not found"))
+	a.recordToolStats(toolCallWithArgs("read_file", "<path>parser.go</path>"), nil)
+	if strings.Contains(a.buildSystemPrompt(), "<session_tool_stats>") {
+		t.Error("expected the report to stay fixed until the next turn starts")
+	}
+
+	a.refreshToolStats()
+	prompt := a.buildSystemPrompt()
+	for _, want := range []string{
+		"- apply_diff: 1 of 1 calls failed (1 on parser.go; last error: search text not found)",
+		"- No failures: read_file (1)",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected the system prompt to contain %q", want)
+		}
+	}
+}
+
+func TestToolStats_Disabled(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{})
+	a.recordToolStats(toolCallWithArgs("apply_diff", "<path>parser.go</path>"), errors.New("failed"))
+	a.refreshToolStats()
+	if strings.Contains(a.buildSystemPrompt(), "<session_tool_stats>") {
+		t.Error("expected no tool stats without WithToolStats")
+	}
+}
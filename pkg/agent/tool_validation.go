@@ -18,14 +18,21 @@ func (a *DefaultAgent) validateToolCallFields(toolCall *tools.ToolCall) (bool, s
 		})
 
 		if a.trackError(errMsg) {
-			a.emitEvent(types.NewErrorEvent(fmt.Errorf("circuit breaker triggered: 5 consecutive missing tool name errors")))
+			a.emitEvent(types.NewErrorEvent(circuitBreakerError(types.ErrorCodeInvalidResponse, "missing tool name")))
 			return false, ""
 		}
 
-		a.emitEvent(types.NewErrorEvent(fmt.Errorf("tool_name is required in tool call")))
+		a.emitEvent(types.NewErrorEvent(types.WrapError(types.ErrorCodeInvalidResponse, fmt.Errorf("tool_name is required in tool call"))))
 		return true, errMsg
 	}
 
+	// Run calls under a deprecated name as the tool, and tell the model its name
+	if alias := a.resolveToolAlias(toolCall); alias != "" {
+		agentDebugLog.Printf("Tool '%s' called by its deprecated name '%s'", toolCall.ToolName, alias)
+		a.memory.Add(types.NewUserMessage(fmt.Sprintf(
+			"Note: '%s' is a deprecated name for the '%s' tool. Call it '%s' from now on.", alias, toolCall.ToolName, toolCall.ToolName)))
+	}
+
 	// Server name defaults to "local" if not specified
 	if toolCall.ServerName == "" {
 		toolCall.ServerName = "local"
@@ -52,11 +59,11 @@ func (a *DefaultAgent) parseToolCallXML(toolCallContent string) (tools.ToolCall,
 		})
 
 		if a.trackError(errMsg) {
-			a.emitEvent(types.NewErrorEvent(fmt.Errorf("circuit breaker triggered: 5 consecutive parse errors")))
+			a.emitEvent(types.NewErrorEvent(circuitBreakerError(types.ErrorCodeInvalidResponse, "parse")))
 			return tools.ToolCall{}, false, ""
 		}
 
-		a.emitEvent(types.NewErrorEvent(fmt.Errorf("failed to parse tool call: %w", err)))
+		a.emitEvent(types.NewErrorEvent(types.WrapError(types.ErrorCodeInvalidResponse, fmt.Errorf("failed to parse tool call: %w", err))))
 		return tools.ToolCall{}, true, errMsg
 	}
 
@@ -85,11 +92,11 @@ func (a *DefaultAgent) validateToolCallContent(ctx context.Context, toolCallCont
 		})
 
 		if a.trackError(errMsg) {
-			a.emitEvent(types.NewErrorEvent(fmt.Errorf("circuit breaker triggered: 5 consecutive no tool call errors")))
+			a.emitEvent(types.NewErrorEvent(circuitBreakerError(types.ErrorCodeInvalidResponse, "no tool call")))
 			return false, ""
 		}
 
-		a.emitEvent(types.NewErrorEvent(fmt.Errorf("no tool call found in response")))
+		a.emitEvent(types.NewErrorEvent(types.WrapError(types.ErrorCodeInvalidResponse, fmt.Errorf("no tool call found in response"))))
 		return true, errMsg
 	}
 
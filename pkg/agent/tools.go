@@ -1,26 +1,111 @@
 package agent
 
 import (
+	"time"
+
 	"github.com/entrhq/forge/pkg/agent/tools"
 )
 
-// getToolsList returns tools as []tools.Tool for internal use
+// builtinTools are always available and cannot be overridden or disallowed
+var builtinTools = map[string]bool{
+	"task_completion": true,
+	"ask_question":    true,
+	"converse":        true,
+	"get_more":        true,
+}
+
+// SetAllowedTools limits the registered tools the model is offered and may
+// call to names, such as for one step of a workflow; built-in tools stay
+// available. Calling it without names allows every tool again. Tools that
+// are not allowed are left out of the system prompt and reported as unknown
+// if called.
+func (a *DefaultAgent) SetAllowedTools(names ...string) {
+	a.toolsMu.Lock()
+	defer a.toolsMu.Unlock()
+
+	if len(names) == 0 {
+		a.allowedTools = nil
+		return
+	}
+	a.allowedTools = make(map[string]bool, len(names))
+	for _, name := range names {
+		a.allowedTools[name] = true
+	}
+}
+
+// toolAllowed reports whether the model may use the tool called name. Must
+// be called with toolsMu held.
+func (a *DefaultAgent) toolAllowed(name string) bool {
+	return a.allowedTools == nil || builtinTools[name] || a.allowedTools[name]
+}
+
+// getToolsList returns the allowed tools as []tools.Tool for internal use
 func (a *DefaultAgent) getToolsList() []tools.Tool {
 	a.toolsMu.RLock()
 	defer a.toolsMu.RUnlock()
 
 	toolsList := make([]tools.Tool, 0, len(a.tools))
-	for _, tool := range a.tools {
-		toolsList = append(toolsList, tool)
+	for name, tool := range a.tools {
+		if a.toolAllowed(name) {
+			toolsList = append(toolsList, tool)
+		}
 	}
 	return toolsList
 }
 
-// getTool retrieves a tool by name (thread-safe)
+// getTool retrieves an allowed tool by name (thread-safe)
 func (a *DefaultAgent) getTool(name string) (tools.Tool, bool) {
 	a.toolsMu.RLock()
 	defer a.toolsMu.RUnlock()
 
+	if !a.toolAllowed(name) {
+		return nil, false
+	}
 	tool, exists := a.tools[name]
 	return tool, exists
 }
+
+// registerAliases maps the aliases tool declares, and those given at
+// registration, to its name. An alias never shadows a built-in tool. Must be
+// called with toolsMu held.
+func (a *DefaultAgent) registerAliases(tool tools.Tool, aliases []string) {
+	if aliased, ok := tool.(tools.Aliased); ok {
+		aliases = append(aliased.Aliases(), aliases...)
+	}
+	for _, alias := range aliases {
+		if alias != "" && alias != tool.Name() && !builtinTools[alias] {
+			a.toolAliases[alias] = tool.Name()
+		}
+	}
+}
+
+// resolveToolAlias renames a call made under a deprecated alias to the
+// tool's name, returning the alias, or "" if the call used no alias. A
+// registered tool of the same name takes precedence over an alias.
+func (a *DefaultAgent) resolveToolAlias(toolCall *tools.ToolCall) string {
+	a.toolsMu.RLock()
+	defer a.toolsMu.RUnlock()
+
+	if _, exists := a.tools[toolCall.ToolName]; exists {
+		return ""
+	}
+	name, ok := a.toolAliases[toolCall.ToolName]
+	if !ok {
+		return ""
+	}
+	alias := toolCall.ToolName
+	toolCall.ToolName = name
+	return alias
+}
+
+// getToolTimeout returns the execution timeout for a tool (thread-safe).
+// Falls back to the agent-wide default; zero means no timeout.
+func (a *DefaultAgent) getToolTimeout(name string) time.Duration {
+	a.toolsMu.RLock()
+	defer a.toolsMu.RUnlock()
+
+	if timeout, ok := a.toolTimeouts[name]; ok {
+		return timeout
+	}
+	return a.defaultToolTimeout
+}
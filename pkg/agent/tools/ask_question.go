@@ -23,6 +23,11 @@ func (t *AskQuestionTool) Name() string {
 	return askQuestionToolName
 }
 
+// Aliases returns the tool's deprecated names, from other agents' prompts.
+func (t *AskQuestionTool) Aliases() []string {
+	return []string{"ask_followup_question"}
+}
+
 // Description returns a description of what this tool does
 func (t *AskQuestionTool) Description() string {
 	return "Ask the user a clarifying question when you need additional information to complete the task. " +
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+const getMoreToolName = "get_more"
+
+// GetMoreTool fetches further pages of a long tool result that was returned
+// with a result_id (see ResultPager).
+type GetMoreTool struct {
+	pager *ResultPager
+}
+
+// NewGetMoreTool creates a new get_more tool backed by the given pager
+func NewGetMoreTool(pager *ResultPager) *GetMoreTool {
+	return &GetMoreTool{
+		pager: pager,
+	}
+}
+
+// Name returns the tool's identifier
+func (t *GetMoreTool) Name() string {
+	return getMoreToolName
+}
+
+// Description returns a description of what this tool does
+func (t *GetMoreTool) Description() string {
+	return "Fetch another page of a long tool result that ended with a result_id. " +
+		"Only call this when the remaining output is actually needed; by default it returns the next page."
+}
+
+// Schema returns the JSON schema for the tool's arguments
+func (t *GetMoreTool) Schema() map[string]interface{} {
+	return BaseToolSchema(
+		map[string]interface{}{
+			"result_id": map[string]interface{}{
+				"type":        "string",
+				"description": "The result_id from the footer of a paged tool result",
+			},
+			"page": map[string]interface{}{
+				"type":        "integer",
+				"description": "Optional 1-based page number to fetch (default: the next page)",
+			},
+		},
+		[]string{"result_id"},
+	)
+}
+
+// Execute returns the requested page of the stored result
+func (t *GetMoreTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var args struct {
+		XMLName  xml.Name `xml:"arguments"`
+		ResultID string   `xml:"result_id"`
+		Page     int      `xml:"page"`
+	}
+
+	if err := UnmarshalXMLWithFallback(argsXML, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments for get_more: %w", err)
+	}
+
+	resultID := strings.TrimSpace(args.ResultID)
+	if resultID == "" {
+		return "", fmt.Errorf("result_id cannot be empty")
+	}
+
+	return t.pager.Page(resultID, args.Page)
+}
+
+// IsLoopBreaking returns false as fetching a page continues the agent loop
+func (t *GetMoreTool) IsLoopBreaking() bool {
+	return false
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultPageSize is the number of lines returned per page of a paged result
+	DefaultPageSize = 200
+
+	// maxStoredResults bounds how many paged results are retained; the oldest
+	// results are evicted first
+	maxStoredResults = 20
+)
+
+// pagerContextKey is the context key type for the result pager
+type pagerContextKey struct{}
+
+// ResultPager implements the paging convention for long tool output: a tool
+// returns the first page plus a result_id, and the model calls get_more with
+// that result_id to fetch further pages only when it needs them.
+type ResultPager struct {
+	mu       sync.Mutex
+	pageSize int
+	nextID   int
+	results  map[string]*pagedResult
+	order    []string // Result IDs, oldest first, for eviction
+}
+
+// pagedResult is a stored tool result split into lines
+type pagedResult struct {
+	lines    []string
+	lastPage int // Last page handed out, so get_more can default to the next one
+}
+
+// NewResultPager creates a pager that splits results into pages of pageSize lines.
+// A non-positive pageSize uses DefaultPageSize.
+func NewResultPager(pageSize int) *ResultPager {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &ResultPager{
+		pageSize: pageSize,
+		results:  make(map[string]*pagedResult),
+	}
+}
+
+// Paginate returns content unchanged if it fits on one page. Otherwise it
+// stores the full content and returns the first page followed by a footer
+// carrying the result_id for get_more.
+func (p *ResultPager) Paginate(content string) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) <= p.pageSize {
+		return content
+	}
+
+	p.mu.Lock()
+	p.nextID++
+	id := fmt.Sprintf("res_%d", p.nextID)
+	p.results[id] = &pagedResult{lines: lines, lastPage: 1}
+	p.order = append(p.order, id)
+	if len(p.order) > maxStoredResults {
+		delete(p.results, p.order[0])
+		p.order = p.order[1:]
+	}
+	p.mu.Unlock()
+
+	return p.render(id, lines, 1)
+}
+
+// Page returns the given 1-based page of a stored result. A page of 0 returns
+// the page after the last one handed out.
+func (p *ResultPager) Page(resultID string, page int) (string, error) {
+	p.mu.Lock()
+	result, ok := p.results[resultID]
+	if !ok {
+		p.mu.Unlock()
+		return "", fmt.Errorf("unknown or expired result_id %q; re-run the original tool call", resultID)
+	}
+
+	if page == 0 {
+		page = result.lastPage + 1
+	}
+	total := p.pageCount(len(result.lines))
+	if page < 1 || page > total {
+		p.mu.Unlock()
+		return "", fmt.Errorf("page %d out of range: result %s has %d page(s)", page, resultID, total)
+	}
+	result.lastPage = page
+	lines := result.lines
+	p.mu.Unlock()
+
+	return p.render(resultID, lines, page), nil
+}
+
+// render formats one page of lines with a footer describing its position
+func (p *ResultPager) render(resultID string, lines []string, page int) string {
+	start := (page - 1) * p.pageSize
+	end := start + p.pageSize
+	if end > len(lines) {
+		end = len(lines)
+	}
+	total := p.pageCount(len(lines))
+
+	var builder strings.Builder
+	builder.WriteString(strings.Join(lines[start:end], "\n"))
+	builder.WriteString(fmt.Sprintf("\n\n[Page %d of %d, lines %d-%d of %d. result_id: %s",
+		page, total, start+1, end, len(lines), resultID))
+	if page < total {
+		builder.WriteString(" - call get_more with this result_id for the next page, only if you need it")
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+// pageCount returns the number of pages needed for n lines
+func (p *ResultPager) pageCount(n int) int {
+	return (n + p.pageSize - 1) / p.pageSize
+}
+
+// WithResultPager returns a context carrying the pager for tools to use
+func WithResultPager(ctx context.Context, pager *ResultPager) context.Context {
+	return context.WithValue(ctx, pagerContextKey{}, pager)
+}
+
+// PaginateResult pages content through the pager in ctx, if any. Tools call
+// this on potentially long text output; without a pager content is returned as-is.
+func PaginateResult(ctx context.Context, content string) string {
+	if pager, ok := ctx.Value(pagerContextKey{}).(*ResultPager); ok && pager != nil {
+		return pager.Paginate(content)
+	}
+	return content
+}
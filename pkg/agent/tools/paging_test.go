@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func numberedContent(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestResultPager(t *testing.T) {
+	t.Run("ShortContentUnchanged", func(t *testing.T) {
+		pager := NewResultPager(10)
+		content := numberedContent(10)
+		if got := pager.Paginate(content); got != content {
+			t.Errorf("expected content unchanged, got %q", got)
+		}
+	})
+
+	t.Run("LongContentPaged", func(t *testing.T) {
+		pager := NewResultPager(10)
+		first := pager.Paginate(numberedContent(25))
+
+		if !strings.Contains(first, "line 10\n") || strings.Contains(first, "line 11") {
+			t.Errorf("first page should contain lines 1-10 only:\n%s", first)
+		}
+		if !strings.Contains(first, "Page 1 of 3") || !strings.Contains(first, "result_id: res_1") {
+			t.Errorf("first page missing footer:\n%s", first)
+		}
+
+		tool := NewGetMoreTool(pager)
+		second, err := tool.Execute(context.Background(), []byte(`<arguments><result_id>res_1</result_id></arguments>`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(second, "line 11\n") || !strings.Contains(second, "Page 2 of 3") {
+			t.Errorf("expected next page by default:\n%s", second)
+		}
+
+		last, err := tool.Execute(context.Background(), []byte(`<arguments><result_id>res_1</result_id><page>3</page></arguments>`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(last, "lines 21-25 of 25") || strings.Contains(last, "get_more") {
+			t.Errorf("unexpected last page:\n%s", last)
+		}
+	})
+
+	t.Run("UnknownResultID", func(t *testing.T) {
+		pager := NewResultPager(10)
+		if _, err := pager.Page("res_42", 0); err == nil {
+			t.Error("expected error for unknown result_id")
+		}
+	})
+
+	t.Run("PageOutOfRange", func(t *testing.T) {
+		pager := NewResultPager(10)
+		pager.Paginate(numberedContent(15))
+		if _, err := pager.Page("res_1", 3); err == nil {
+			t.Error("expected error for page out of range")
+		}
+	})
+
+	t.Run("PaginateResultWithoutPager", func(t *testing.T) {
+		content := numberedContent(DefaultPageSize + 1)
+		if got := PaginateResult(context.Background(), content); got != content {
+			t.Error("expected content unchanged without a pager in context")
+		}
+	})
+}
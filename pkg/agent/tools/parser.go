@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"regexp"
@@ -141,6 +142,85 @@ func UnmarshalXMLWithFallback(data []byte, v interface{}) error {
 	return xml.Unmarshal(escaped, v)
 }
 
+// ArgumentsMap decodes an <arguments> block into a map from argument name to
+// value, for tools whose arguments are not known in advance (events, hooks).
+// Leaf elements become strings, nested elements become maps and repeated
+// elements become lists. Like UnmarshalXMLWithFallback, it retries with
+// unescaped ampersands escaped.
+func ArgumentsMap(data []byte) (map[string]interface{}, error) {
+	args, err := decodeArgumentsMap(data)
+	if err != nil {
+		args, err = decodeArgumentsMap(escapeUnescapedAmpersands(data))
+	}
+	return args, err
+}
+
+// decodeArgumentsMap decodes the children of the document's root element
+func decodeArgumentsMap(data []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode arguments: %w", err)
+		}
+		if _, ok := token.(xml.StartElement); !ok {
+			continue
+		}
+
+		value, err := decodeElementValue(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode arguments: %w", err)
+		}
+		if args, ok := value.(map[string]interface{}); ok {
+			return args, nil
+		}
+		return make(map[string]interface{}), nil
+	}
+}
+
+// decodeElementValue reads the content of the element whose start tag was just
+// read: its text if it has no child elements, otherwise a map of its children
+func decodeElementValue(decoder *xml.Decoder) (interface{}, error) {
+	var text strings.Builder
+	var children map[string]interface{}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := decodeElementValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if children == nil {
+				children = make(map[string]interface{})
+			}
+			name := t.Name.Local
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = value
+			case []interface{}:
+				children[name] = append(existing, value)
+			default:
+				children[name] = []interface{}{existing, value}
+			}
+
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.EndElement:
+			if children != nil {
+				return children, nil
+			}
+			return text.String(), nil
+		}
+	}
+}
+
 // escapeUnescapedAmpersands replaces bare & with &amp; while preserving
 // existing entities (&amp;, &lt;, &gt;, &quot;, &apos;, &#..;)
 func escapeUnescapedAmpersands(data []byte) []byte {
@@ -315,3 +315,39 @@ func TestUnmarshalXMLWithFallback_RealWorldScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestArgumentsMap(t *testing.T) {
+	args, err := ArgumentsMap([]byte(`<arguments><command>go test ./... && echo done</command>` +
+		`<content><![CDATA[x := a < b]]></content><edits><edit><search>a</search></edit><edit><search>b</search></edit></edits></arguments>`))
+	if err != nil {
+		t.Fatalf("ArgumentsMap failed: %v", err)
+	}
+
+	if args["command"] != "go test ./... && echo done" {
+		t.Errorf("expected the unescaped ampersands to be recovered, got %q", args["command"])
+	}
+	if args["content"] != "x := a < b" {
+		t.Errorf("expected CDATA content, got %q", args["content"])
+	}
+	edits, ok := args["edits"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested arguments as a map, got %T", args["edits"])
+	}
+	list, ok := edits["edit"].([]interface{})
+	if !ok || len(list) != 2 {
+		t.Fatalf("expected repeated elements as a list, got %#v", edits["edit"])
+	}
+	if first, _ := list[0].(map[string]interface{}); first["search"] != "a" {
+		t.Errorf("unexpected first edit: %#v", list[0])
+	}
+}
+
+func TestArgumentsMap_Empty(t *testing.T) {
+	args, err := ArgumentsMap([]byte(`<arguments></arguments>`))
+	if err != nil {
+		t.Fatalf("ArgumentsMap failed: %v", err)
+	}
+	if len(args) != 0 {
+		t.Errorf("expected no arguments, got %v", args)
+	}
+}
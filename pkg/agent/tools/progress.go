@@ -0,0 +1,26 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProgressReporter receives phase descriptions from a running tool
+type ProgressReporter func(description string)
+
+// progressContextKey is the context key type for the progress reporter
+type progressContextKey struct{}
+
+// WithProgressReporter returns a context carrying the reporter for tools to use
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, reporter)
+}
+
+// ReportProgress describes what a long-running tool is currently doing, e.g.
+// ReportProgress(ctx, "scanning %d files…", n). Executors show the latest
+// description while the tool runs. Without a reporter in ctx this is a no-op.
+func ReportProgress(ctx context.Context, format string, args ...interface{}) {
+	if reporter, ok := ctx.Value(progressContextKey{}).(ProgressReporter); ok && reporter != nil {
+		reporter(fmt.Sprintf(format, args...))
+	}
+}
@@ -0,0 +1,25 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReportProgress(t *testing.T) {
+	var got []string
+	ctx := WithProgressReporter(context.Background(), func(description string) {
+		got = append(got, description)
+	})
+
+	ReportProgress(ctx, "scanning %d files…", 1204)
+	ReportProgress(ctx, "writing chunk %d/%d", 3, 7)
+
+	if len(got) != 2 || got[0] != "scanning 1204 files…" || got[1] != "writing chunk 3/7" {
+		t.Errorf("unexpected progress descriptions: %q", got)
+	}
+}
+
+func TestReportProgressWithoutReporter(t *testing.T) {
+	// Must not panic when no reporter is installed
+	ReportProgress(context.Background(), "scanning %d files…", 1)
+}
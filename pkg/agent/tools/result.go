@@ -0,0 +1,92 @@
+package tools
+
+import "context"
+
+// Result is a tool's structured result. Output is what the model reads; the
+// other fields let executors, memory and exports present the result without
+// parsing Output: a one-line summary for people, a machine-readable payload,
+// and references to the files and diffs the tool produced.
+type Result struct {
+	// Success reports whether the tool did what it was asked. A tool that ran
+	// but whose action failed, such as a command exiting non-zero, returns a
+	// result with Success false rather than an error, so the model still
+	// reads its output.
+	Success bool
+
+	// Summary is a one-line description for people, e.g. "Wrote 12 lines to
+	// main.go"; empty to let executors derive one
+	Summary string
+
+	// Output is the full result for the model
+	Output string
+
+	// Data is the machine-readable payload, e.g. {"path": "main.go", "created": true}
+	Data map[string]interface{}
+
+	// Artifacts reference the files and diffs the tool read or produced
+	Artifacts []Artifact
+}
+
+// ArtifactKind is the kind of an Artifact
+type ArtifactKind string
+
+const (
+	// ArtifactFile references a file, optionally at a line
+	ArtifactFile ArtifactKind = "file"
+
+	// ArtifactDiff is a change made to a file, as a unified diff
+	ArtifactDiff ArtifactKind = "diff"
+)
+
+// Artifact references something a tool read or produced
+type Artifact struct {
+	Kind ArtifactKind
+
+	// Path is the file's path, relative to the workspace where possible
+	Path string
+
+	// Line is the line of interest in the file, or 0
+	Line int
+
+	// Diff is the unified diff of an ArtifactDiff
+	Diff string
+}
+
+// String returns the result's Output, so results print as the text the
+// model read
+func (r *Result) String() string {
+	if r == nil {
+		return ""
+	}
+	return r.Output
+}
+
+// TextResult wraps the string a tool's Execute returned as a successful Result
+func TextResult(output string) *Result {
+	return &Result{Success: true, Output: output}
+}
+
+// ResultExecutor is an optional interface for tools that return a structured
+// Result. The agent calls ExecuteResult instead of Execute; Execute should
+// return the Result's Output, for callers that only need the text.
+type ResultExecutor interface {
+	// ExecuteResult runs the tool with the given XML arguments
+	ExecuteResult(ctx context.Context, argumentsXML []byte) (*Result, error)
+}
+
+// Run executes tool, through ExecuteResult if it is a ResultExecutor. A
+// plain tool's string result is wrapped with TextResult.
+func Run(ctx context.Context, tool Tool, argumentsXML []byte) (*Result, error) {
+	if executor, ok := tool.(ResultExecutor); ok {
+		result, err := executor.ExecuteResult(ctx, argumentsXML)
+		if err == nil && result == nil {
+			result = TextResult("")
+		}
+		return result, err
+	}
+	output, err := tool.Execute(ctx, argumentsXML)
+	if err != nil {
+		return nil, err
+	}
+	return TextResult(output), nil
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// structuredTool returns a structured result
+type structuredTool struct {
+	TaskCompletionTool
+	result *Result
+}
+
+func (t *structuredTool) ExecuteResult(ctx context.Context, argumentsXML []byte) (*Result, error) {
+	return t.result, nil
+}
+
+func TestRun(t *testing.T) {
+	t.Run("plain tool", func(t *testing.T) {
+		result, err := Run(context.Background(), NewTaskCompletionTool(), []byte(`<arguments><result>done</result></arguments>`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Success || result.Output != "done" {
+			t.Errorf("expected the string result wrapped as a success, got %+v", result)
+		}
+	})
+
+	t.Run("plain tool error", func(t *testing.T) {
+		result, err := Run(context.Background(), NewTaskCompletionTool(), []byte(`<arguments><result></result></arguments>`))
+		if err == nil || result != nil {
+			t.Errorf("expected the tool's error, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("result executor", func(t *testing.T) {
+		want := &Result{Summary: "Ran 3 tests, 1 failed", Output: "FAIL", Artifacts: []Artifact{{Kind: ArtifactFile, Path: "main_test.go", Line: 12}}}
+		result, err := Run(context.Background(), &structuredTool{result: want}, nil)
+		if err != nil || result != want {
+			t.Errorf("expected the tool's structured result, got %+v, %v", result, err)
+		}
+		if got := fmt.Sprint(result); got != "FAIL" {
+			t.Errorf("expected a result to print as its output, got %q", got)
+		}
+	})
+
+	t.Run("nil structured result", func(t *testing.T) {
+		result, err := Run(context.Background(), &structuredTool{}, nil)
+		if err != nil || result == nil || result.Output != "" {
+			t.Errorf("expected an empty result, got %+v, %v", result, err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
+	"strings"
 )
 
 const taskCompletionToolName = "task_completion"
@@ -11,8 +12,59 @@ const taskCompletionToolName = "task_completion"
 // TaskCompletionTool is a loop-breaking tool that allows the agent to signal
 // that it has completed the user's task. This tool should be used when the
 // agent has finished all work and wants to present the final result to the user.
+//
+// Besides the free-text result, the agent can list the files it changed,
+// commands for the user to run and suggested follow-ups. Executors render
+// these as a completion card and exports keep them as data; see
+// CompletionFromResult.
 type TaskCompletionTool struct{}
 
+// Completion is the structured content of a task_completion call
+type Completion struct {
+	Summary       string   `json:"summary,omitempty"` // One-line headline
+	Result        string   `json:"result"`
+	FilesChanged  []string `json:"files_changed,omitempty"`
+	CommandsToRun []string `json:"commands_to_run,omitempty"` // For the user, e.g. to try the change
+	FollowUps     []string `json:"follow_ups,omitempty"`      // Suggested next tasks
+}
+
+// Structured reports whether the completion has more than its result
+func (c *Completion) Structured() bool {
+	return c.Summary != "" || len(c.FilesChanged) > 0 || len(c.CommandsToRun) > 0 || len(c.FollowUps) > 0
+}
+
+// Markdown renders the completion as text: the summary, the result, then a
+// list per structured field
+func (c *Completion) Markdown() string {
+	var b strings.Builder
+	if c.Summary != "" {
+		b.WriteString(c.Summary + "\n\n")
+	}
+	b.WriteString(c.Result)
+	writeList := func(title string, items []string, format string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n\n%s:", title)
+		for _, item := range items {
+			fmt.Fprintf(&b, "\n- "+format, item)
+		}
+	}
+	writeList("Files changed", c.FilesChanged, "%s")
+	writeList("Commands to run", c.CommandsToRun, "`%s`")
+	writeList("Follow-ups", c.FollowUps, "%s")
+	return b.String()
+}
+
+// CompletionFromResult returns the Completion of a task_completion result
+func CompletionFromResult(result *Result) (*Completion, bool) {
+	if result == nil {
+		return nil, false
+	}
+	completion, ok := result.Data["completion"].(*Completion)
+	return completion, ok
+}
+
 // NewTaskCompletionTool creates a new task completion tool
 func NewTaskCompletionTool() *TaskCompletionTool {
 	return &TaskCompletionTool{}
@@ -23,46 +75,131 @@ func (t *TaskCompletionTool) Name() string {
 	return taskCompletionToolName
 }
 
+// Aliases returns the tool's deprecated names, from other agents' prompts.
+func (t *TaskCompletionTool) Aliases() []string {
+	return []string{"attempt_completion"}
+}
+
 // Description returns a description of what this tool does
 func (t *TaskCompletionTool) Description() string {
 	return "Signal that the task is complete and present the final result to the user. " +
 		"Use this when you have finished all work and want to show the outcome. " +
-		"The result should be comprehensive and not require further input from the user."
+		"The result should be comprehensive and not require further input from the user. " +
+		"Fill in the optional summary, files_changed, commands_to_run and follow_ups so the user can scan the outcome at a glance."
 }
 
 // Schema returns the JSON schema for the tool's arguments
 func (t *TaskCompletionTool) Schema() map[string]interface{} {
+	list := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "array",
+			"description": description,
+			"items":       map[string]interface{}{"type": "string"},
+		}
+	}
 	return BaseToolSchema(
 		map[string]interface{}{
 			"result": map[string]interface{}{
 				"type":        "string",
 				"description": "The final result of the task. Should be clear, complete, and not end with questions or offers for further assistance.",
 			},
+			"summary": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional one-line headline of what was done, e.g. \"Added retry with backoff to the HTTP client\".",
+			},
+			"files_changed":   list("Optional workspace-relative paths of the files you created, modified or deleted."),
+			"commands_to_run": list("Optional commands for the user to run next, e.g. to try or verify the change."),
+			"follow_ups":      list("Optional suggested follow-up tasks you did not do."),
 		},
 		[]string{"result"},
 	)
 }
 
+// XMLExample shows the list fields' element names, which the generated
+// example would leave out as optional
+func (t *TaskCompletionTool) XMLExample() string {
+	return `<tool>
+<server_name>local</server_name>
+<tool_name>task_completion</tool_name>
+<arguments>
+  <summary>Added retry with backoff to the HTTP client</summary>
+  <result>Requests that fail with a 5xx or a timeout are now retried up to 3 times with exponential backoff.</result>
+  <files_changed>
+    <file>pkg/client/client.go</file>
+    <file>pkg/client/client_test.go</file>
+  </files_changed>
+  <commands_to_run>
+    <command>go test ./pkg/client/...</command>
+  </commands_to_run>
+  <follow_ups>
+    <follow_up>Make the retry count configurable</follow_up>
+  </follow_ups>
+</arguments>
+</tool>`
+}
+
 // Execute runs the tool and returns the result
 func (t *TaskCompletionTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	result, err := t.ExecuteResult(ctx, argsXML)
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// ExecuteResult returns the completion as text, with the Completion as the
+// data and the changed files as artifacts
+func (t *TaskCompletionTool) ExecuteResult(ctx context.Context, argsXML []byte) (*Result, error) {
 	var args struct {
-		XMLName xml.Name `xml:"arguments"`
-		Result  string   `xml:"result"`
+		XMLName       xml.Name `xml:"arguments"`
+		Result        string   `xml:"result"`
+		Summary       string   `xml:"summary"`
+		FilesChanged  []string `xml:"files_changed>file"`
+		CommandsToRun []string `xml:"commands_to_run>command"`
+		FollowUps     []string `xml:"follow_ups>follow_up"`
 	}
 
 	if err := UnmarshalXMLWithFallback(argsXML, &args); err != nil {
-		return "", fmt.Errorf("invalid arguments for %s: %w", taskCompletionToolName, err)
+		return nil, fmt.Errorf("invalid arguments for %s: %w", taskCompletionToolName, err)
 	}
 
 	if args.Result == "" {
-		return "", fmt.Errorf("result cannot be empty")
+		return nil, fmt.Errorf("result cannot be empty")
+	}
+
+	completion := &Completion{
+		Summary:       strings.TrimSpace(args.Summary),
+		Result:        args.Result,
+		FilesChanged:  nonEmpty(args.FilesChanged),
+		CommandsToRun: nonEmpty(args.CommandsToRun),
+		FollowUps:     nonEmpty(args.FollowUps),
 	}
 
 	// Return the result - this will be presented to the user
-	return args.Result, nil
+	result := &Result{
+		Success: true,
+		Summary: completion.Summary,
+		Output:  completion.Markdown(),
+		Data:    map[string]interface{}{"completion": completion},
+	}
+	for _, path := range completion.FilesChanged {
+		result.Artifacts = append(result.Artifacts, Artifact{Kind: ArtifactFile, Path: path})
+	}
+	return result, nil
 }
 
 // IsLoopBreaking returns true because this tool terminates the agent loop
 func (t *TaskCompletionTool) IsLoopBreaking() bool {
 	return true
 }
+
+// nonEmpty returns the trimmed items that aren't blank
+func nonEmpty(items []string) []string {
+	var kept []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -76,8 +76,20 @@ type Previewable interface {
 	GeneratePreview(ctx context.Context, argumentsXML []byte) (*ToolPreview, error)
 }
 
+// Aliased is an optional interface for tools that answer to other names, such
+// as a name the tool had before it was renamed, or one models tend to use
+// from other agents' prompts. A call under an alias runs the tool, and the
+// model is told to use the tool's name instead.
+type Aliased interface {
+	// Aliases returns the tool's other, deprecated names
+	Aliases() []string
+}
+
 // ToolPreview represents a preview of what a tool will do.
 // It contains enough information to show the user what changes will be made.
+// Executors may render the types they know richly, such as the TUI's
+// renderers for tables and trees, so Content should read on its own for
+// those that don't.
 type ToolPreview struct {
 	// Type indicates the kind of preview (diff, command, file_write, etc.)
 	Type PreviewType
@@ -107,6 +119,22 @@ const (
 
 	// PreviewTypeFileWrite represents a file write/creation preview
 	PreviewTypeFileWrite PreviewType = "file_write"
+
+	// PreviewTypeBatch represents several tool calls approved together
+	PreviewTypeBatch PreviewType = "batch"
+
+	// PreviewTypeClipboard represents text about to replace the user's clipboard
+	PreviewTypeClipboard PreviewType = "clipboard"
+
+	// PreviewTypeTable represents rows of data, such as the rows a database
+	// query would change. Metadata["columns"] ([]string) names the columns
+	// and Metadata["rows"] ([][]string) holds the rows.
+	PreviewTypeTable PreviewType = "table"
+
+	// PreviewTypeTree represents files about to be created, such as
+	// scaffold output. Content lists their slash-separated paths, one per
+	// line; a path ending in '/' is a directory.
+	PreviewTypeTree PreviewType = "tree"
 )
 
 // BaseToolSchema creates a common JSON schema structure for a tool
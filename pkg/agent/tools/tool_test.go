@@ -31,6 +31,34 @@ func TestTaskCompletionTool(t *testing.T) {
 		}
 	})
 
+	t.Run("ExecuteResult_Structured", func(t *testing.T) {
+		args := []byte(`<arguments>
+<summary>Fixed the parser</summary>
+<result>Nested lists parse again.</result>
+<files_changed><file>parser.go</file><file> </file></files_changed>
+<commands_to_run><command>go test ./...</command></commands_to_run>
+<follow_ups><follow_up>Add a fuzz test</follow_up></follow_ups>
+</arguments>`)
+		result, err := tool.ExecuteResult(context.Background(), args)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		completion, ok := CompletionFromResult(result)
+		if !ok || !completion.Structured() {
+			t.Fatalf("expected a structured completion, got %+v", result.Data)
+		}
+		if len(completion.FilesChanged) != 1 || completion.CommandsToRun[0] != "go test ./..." || completion.FollowUps[0] != "Add a fuzz test" {
+			t.Errorf("unexpected completion %+v", completion)
+		}
+		want := "Fixed the parser\n\nNested lists parse again.\n\nFiles changed:\n- parser.go\n\nCommands to run:\n- `go test ./...`\n\nFollow-ups:\n- Add a fuzz test"
+		if result.Output != want {
+			t.Errorf("unexpected output:\n%s", result.Output)
+		}
+		if result.Summary != "Fixed the parser" || len(result.Artifacts) != 1 || result.Artifacts[0].Path != "parser.go" {
+			t.Errorf("unexpected summary or artifacts: %q %+v", result.Summary, result.Artifacts)
+		}
+	})
+
 	t.Run("Execute_EmptyResult", func(t *testing.T) {
 		args := []byte(`<arguments><result></result></arguments>`)
 		_, err := tool.Execute(context.Background(), args)
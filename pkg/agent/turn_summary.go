@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// maxSummaryPaths bounds how many paths each turn summary line lists
+	maxSummaryPaths = 10
+
+	// maxSummaryExcerpt bounds the request and outcome excerpts in a turn summary
+	maxSummaryExcerpt = 200
+)
+
+// turnRecord collects what happened during a turn so a compact summary can be
+// kept in memory after older details have been summarized away
+type turnRecord struct {
+	request    string
+	modified   []string // Paths changed by tools that require approval
+	inspected  []string // Paths read or listed by other tools
+	commands   []string
+	toolErrors int
+	rejections int // Tool calls the user rejected
+	outcome    string
+	result     string // task_completion result, if the task was completed
+
+	// Cost and duration, for completion notifications
+	start            time.Time
+	promptTokens     int
+	cachedTokens     int
+	completionTokens int
+}
+
+// turnToolArgs are the tool arguments a turn summary cares about
+type turnToolArgs struct {
+	XMLName  xml.Name `xml:"arguments"`
+	Path     string   `xml:"path"`
+	Command  string   `xml:"command"`
+	Result   string   `xml:"result"`
+	Question string   `xml:"question"`
+	Message  string   `xml:"message"`
+}
+
+// newTurnRecord starts recording a turn for the given user request
+func newTurnRecord(request string) *turnRecord {
+	return &turnRecord{request: request, start: time.Now()}
+}
+
+// recordTool notes a tool call and whether it failed
+func (r *turnRecord) recordTool(tool tools.Tool, toolCall tools.ToolCall, err error) {
+	if err != nil {
+		r.toolErrors++
+		return
+	}
+
+	var args turnToolArgs
+	_ = tools.UnmarshalXMLWithFallback(toolCall.GetArgumentsXML(), &args)
+
+	switch {
+	case args.Command != "":
+		r.commands = appendUnique(r.commands, args.Command)
+	case args.Path != "":
+		if _, mutating := tool.(tools.Previewable); mutating {
+			r.modified = appendUnique(r.modified, args.Path)
+		} else {
+			r.inspected = appendUnique(r.inspected, args.Path)
+		}
+	}
+
+	if tool.IsLoopBreaking() {
+		switch {
+		case args.Result != "":
+			r.outcome = "completed: " + args.Result
+			r.result = args.Result
+		case args.Question != "":
+			r.outcome = "asked the user: " + args.Question
+		case args.Message != "":
+			r.outcome = "replied: " + args.Message
+		}
+	}
+}
+
+// recordUsage adds an LLM call's token usage to the turn
+func (r *turnRecord) recordUsage(usage *types.TokenUsage) {
+	r.promptTokens += usage.PromptTokens
+	r.cachedTokens += usage.CachedTokens
+	r.completionTokens += usage.CompletionTokens
+}
+
+// empty reports whether the turn touched nothing worth anchoring
+func (r *turnRecord) empty() bool {
+	return len(r.modified) == 0 && len(r.inspected) == 0 && len(r.commands) == 0
+}
+
+// format renders the record as the turn summary message content
+func (r *turnRecord) format(turn int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Turn %d summary] Request: %s\n", turn, excerptLine(r.request))
+	if len(r.modified) > 0 {
+		fmt.Fprintf(&b, "Files modified: %s\n", joinLimited(r.modified, maxSummaryPaths))
+	}
+	if len(r.inspected) > 0 {
+		fmt.Fprintf(&b, "Paths inspected: %s\n", joinLimited(r.inspected, maxSummaryPaths))
+	}
+	if len(r.commands) > 0 {
+		fmt.Fprintf(&b, "Commands run: %s\n", joinLimited(r.commands, maxSummaryPaths))
+	}
+	if r.toolErrors > 0 {
+		fmt.Fprintf(&b, "Tool errors: %d\n", r.toolErrors)
+	}
+	if r.outcome != "" {
+		fmt.Fprintf(&b, "Outcome: %s\n", excerptLine(r.outcome))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// recordTurnSummary appends the current turn's summary to memory, marked so
+// context strategies keep it
+func (a *DefaultAgent) recordTurnSummary() {
+	if !a.turnSummaries || a.turn == nil || a.turn.empty() {
+		return
+	}
+	a.turnCount++
+	summary := types.NewUserMessage(a.turn.format(a.turnCount)).
+		WithMetadata(memory.TurnSummaryKey, true).
+		WithMetadata("turn", a.turnCount)
+	a.memory.Add(summary)
+}
+
+// appendUnique appends value unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// joinLimited joins up to limit values, noting how many were left out
+func joinLimited(values []string, limit int) string {
+	if len(values) <= limit {
+		return strings.Join(values, ", ")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(values[:limit], ", "), len(values)-limit)
+}
+
+// excerptLine collapses text to a single line of bounded length
+func excerptLine(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxSummaryExcerpt {
+		text = string(runes[:maxSummaryExcerpt]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// summaryTestTool is a minimal tool for turn summary tests
+type summaryTestTool struct {
+	name         string
+	loopBreaking bool
+}
+
+func (s *summaryTestTool) Name() string                   { return s.name }
+func (s *summaryTestTool) Description() string            { return "test tool" }
+func (s *summaryTestTool) Schema() map[string]interface{} { return tools.BaseToolSchema(nil, nil) }
+func (s *summaryTestTool) IsLoopBreaking() bool           { return s.loopBreaking }
+func (s *summaryTestTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return "ok", nil
+}
+
+// summaryWriteTool is a mutating test tool
+type summaryWriteTool struct {
+	summaryTestTool
+}
+
+func (s *summaryWriteTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	return &tools.ToolPreview{}, nil
+}
+
+func toolCallWithArgs(name, args string) tools.ToolCall {
+	return tools.ToolCall{ToolName: name, Arguments: tools.ArgumentsBlock{InnerXML: []byte(args)}}
+}
+
+func TestTurnRecord(t *testing.T) {
+	record := newTurnRecord("Fix the flaky   test\nin the parser")
+
+	readTool := &summaryTestTool{name: "read_file"}
+	writeTool := &summaryWriteTool{summaryTestTool{name: "write_file"}}
+	commandTool := &summaryTestTool{name: "execute_command"}
+	completion := &summaryTestTool{name: "task_completion", loopBreaking: true}
+
+	record.recordTool(readTool, toolCallWithArgs("read_file", "<path>parser.go</path>"), nil)
+	record.recordTool(readTool, toolCallWithArgs("read_file", "<path>parser.go</path>"), nil)
+	record.recordTool(writeTool, toolCallWithArgs("write_file", "<path>parser.go</path><content>x</content>"), nil)
+	record.recordTool(commandTool, toolCallWithArgs("execute_command", "<command>go test ./...</command>"), nil)
+	record.recordTool(commandTool, toolCallWithArgs("execute_command", "<command>false</command>"), errors.New("exit 1"))
+	record.recordTool(completion, toolCallWithArgs("task_completion", "<result>Fixed the race in the parser.</result>"), nil)
+
+	got := record.format(3)
+	for _, want := range []string{
+		"[Turn 3 summary] Request: Fix the flaky test in the parser",
+		"Files modified: parser.go",
+		"Paths inspected: parser.go\n",
+		"Commands run: go test ./...",
+		"Tool errors: 1",
+		"Outcome: completed: Fixed the race in the parser.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestTurnRecordEmpty(t *testing.T) {
+	record := newTurnRecord("hello")
+	record.recordTool(&summaryTestTool{name: "converse", loopBreaking: true}, toolCallWithArgs("converse", "<message>Hi!</message>"), nil)
+	if !record.empty() {
+		t.Error("expected a conversation-only turn to need no summary")
+	}
+}
+
+func TestJoinLimited(t *testing.T) {
+	if got := joinLimited([]string{"a", "b", "c"}, 2); got != "a, b (+1 more)" {
+		t.Errorf("unexpected join: %q", got)
+	}
+}
+
+func TestRecordTurnSummary(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{})
+	a.turn = newTurnRecord("list the files")
+	a.turn.recordTool(&summaryTestTool{name: "list_files"}, toolCallWithArgs("list_files", "<path>pkg</path>"), nil)
+
+	a.recordTurnSummary()
+
+	messages := a.memory.GetAll()
+	last := messages[len(messages)-1]
+	if !memory.IsTurnSummary(last) {
+		t.Fatalf("expected last message to be a turn summary, got %+v", last)
+	}
+	if !strings.Contains(last.Content, "[Turn 1 summary]") || !strings.Contains(last.Content, "Paths inspected: pkg") {
+		t.Errorf("unexpected summary content: %q", last.Content)
+	}
+}
+
+func TestRecordTurnSummaryDisabled(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{}, WithTurnSummaries(false))
+	a.turn = newTurnRecord("list the files")
+	a.turn.recordTool(&summaryTestTool{name: "list_files"}, toolCallWithArgs("list_files", "<path>pkg</path>"), nil)
+	before := a.memory.Count()
+
+	a.recordTurnSummary()
+
+	if a.memory.Count() != before {
+		t.Error("expected no turn summary when disabled")
+	}
+}
@@ -0,0 +1,121 @@
+// Package bridge lets an IDE extension follow a Forge session: it mirrors the
+// diffs the agent proposes, opens the files it touches, and sends editor
+// selections into the conversation as context.
+//
+// # Protocol
+//
+// Forge listens on a Unix domain socket, readable only by the current user,
+// and writes its location to .forge/bridge.json in the workspace (see Info).
+// Messages are JSON objects, one per line, in both directions:
+//
+//	{"type": "open_file", "payload": {"path": "/repo/main.go", "line": 12}}
+//
+// On connect Forge sends hello. It then sends open_file, show_diff and
+// diff_resolved as the session progresses. The extension may send hello
+// and selection at any time; unknown types are answered with error and
+// otherwise ignored, so either side can add message types.
+//
+// Paths are absolute. Lines are 1-based, and 0 means no particular line.
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is incremented on incompatible protocol changes
+const ProtocolVersion = 1
+
+// InfoFile is the workspace-relative file that tells extensions where the
+// session's socket is
+const InfoFile = ".forge/bridge.json"
+
+// Messages sent by Forge
+const (
+	TypeHello        = "hello"         // Hello, on connect
+	TypeOpenFile     = "open_file"     // OpenFile, when the agent reads or edits a file
+	TypeShowDiff     = "show_diff"     // ShowDiff, when an edit awaits approval
+	TypeDiffResolved = "diff_resolved" // DiffResolved, when that edit is approved or rejected
+	TypeError        = "error"         // Error, for a message Forge could not handle
+)
+
+// Messages sent by the extension
+const (
+	TypeSelection = "selection" // Selection, to add editor text to the conversation
+)
+
+// Message is one line of the protocol
+type Message struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// NewMessage creates a message with payload encoded as JSON
+func NewMessage(msgType string, payload interface{}) (Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to encode %s payload: %w", msgType, err)
+	}
+	return Message{Type: msgType, Payload: data}, nil
+}
+
+// Decode decodes the message payload into v
+func (m Message) Decode(v interface{}) error {
+	if len(m.Payload) == 0 {
+		return fmt.Errorf("%s message has no payload", m.Type)
+	}
+	if err := json.Unmarshal(m.Payload, v); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", m.Type, err)
+	}
+	return nil
+}
+
+// Hello introduces each side. Forge fills in Workspace; extensions fill in
+// Client, e.g. "vscode".
+type Hello struct {
+	Version   int    `json:"version"`
+	Workspace string `json:"workspace,omitempty"`
+	Client    string `json:"client,omitempty"`
+}
+
+// OpenFile asks the extension to show a file
+type OpenFile struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+}
+
+// ShowDiff carries a proposed edit awaiting approval in the TUI
+type ShowDiff struct {
+	ID       string `json:"id"`   // Matches the DiffResolved for this edit
+	Path     string `json:"path"` // File the edit applies to
+	Title    string `json:"title,omitempty"`
+	Diff     string `json:"diff"` // Unified diff of the edit
+	Language string `json:"language,omitempty"`
+}
+
+// DiffResolved reports that a shown diff was approved or rejected
+type DiffResolved struct {
+	ID       string `json:"id"`
+	Approved bool   `json:"approved"`
+}
+
+// Selection is editor text to add to the conversation
+type Selection struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Text      string `json:"text"`
+	Language  string `json:"language,omitempty"`
+}
+
+// Error reports a message Forge could not handle
+type Error struct {
+	Message string `json:"message"`
+}
+
+// Info is the content of InfoFile
+type Info struct {
+	Socket  string `json:"socket"`
+	PID     int    `json:"pid"`
+	Version int    `json:"version"`
+}
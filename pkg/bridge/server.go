@@ -0,0 +1,344 @@
+package bridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// maxMessageSize caps the size of one message from an extension
+	maxMessageSize = 4 << 20
+
+	// writeTimeout bounds how long a slow extension can hold up a broadcast
+	writeTimeout = 2 * time.Second
+
+	// selectionBuffer is how many selections may wait to be consumed
+	selectionBuffer = 16
+
+	// outboxSize is how many messages may wait to be broadcast before new
+	// ones are dropped
+	outboxSize = 64
+)
+
+// Server accepts IDE extension connections and relays session activity to
+// them. Messages are broadcast in the background, so callers such as the
+// TUI's update loop never wait on a slow extension. All methods are safe for
+// concurrent use.
+type Server struct {
+	workspaceDir string
+	socketPath   string
+	listener     net.Listener
+	selections   chan Selection
+	outbox       chan Message
+
+	mu      sync.Mutex
+	clients map[net.Conn]*sync.Mutex // Connection → write lock
+	closed  bool
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithSocketPath sets the socket to listen on. The default is a file in the
+// temporary directory named after the process, since socket paths are
+// limited to about 100 bytes.
+func WithSocketPath(path string) Option {
+	return func(s *Server) {
+		s.socketPath = path
+	}
+}
+
+// NewServer creates a bridge for the session in workspaceDir
+func NewServer(workspaceDir string, opts ...Option) *Server {
+	s := &Server{
+		workspaceDir: workspaceDir,
+		socketPath:   filepath.Join(os.TempDir(), fmt.Sprintf("forge-bridge-%d.sock", os.Getpid())),
+		selections:   make(chan Selection, selectionBuffer),
+		outbox:       make(chan Message, outboxSize),
+		clients:      make(map[net.Conn]*sync.Mutex),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start listens on the socket and publishes it in the workspace's InfoFile
+func (s *Server) Start() error {
+	// A socket left behind by a crashed session would make Listen fail
+	_ = os.Remove(s.socketPath)
+
+	listener, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.socketPath, err)
+	}
+	if err := os.Chmod(s.socketPath, 0o600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	s.listener = listener
+
+	if err := s.writeInfo(); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go s.accept()
+	go s.deliver()
+	return nil
+}
+
+// SocketPath returns the socket the server listens on
+func (s *Server) SocketPath() string {
+	return s.socketPath
+}
+
+// Selections returns the selections sent by extensions
+func (s *Server) Selections() <-chan Selection {
+	return s.selections
+}
+
+// Close disconnects all extensions and removes the socket and InfoFile
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.outbox)
+	for conn := range s.clients {
+		conn.Close()
+	}
+	s.clients = nil
+	s.mu.Unlock()
+
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+		_ = os.Remove(s.socketPath)
+		s.removeInfo()
+	}
+	return err
+}
+
+// OpenFile asks extensions to show path, relative to the workspace or absolute
+func (s *Server) OpenFile(path string, line int) {
+	s.send(TypeOpenFile, OpenFile{Path: s.absolute(path), Line: line})
+}
+
+// ShowDiff mirrors an edit awaiting approval
+func (s *Server) ShowDiff(diff ShowDiff) {
+	diff.Path = s.absolute(diff.Path)
+	s.send(TypeShowDiff, diff)
+}
+
+// ResolveDiff reports the decision on a diff sent with ShowDiff
+func (s *Server) ResolveDiff(id string, approved bool) {
+	s.send(TypeDiffResolved, DiffResolved{ID: id, Approved: approved})
+}
+
+// absolute resolves a workspace-relative path
+func (s *Server) absolute(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	if abs, err := filepath.Abs(filepath.Join(s.workspaceDir, path)); err == nil {
+		return abs
+	}
+	return filepath.Join(s.workspaceDir, path)
+}
+
+// writeInfo publishes the socket location for extensions
+func (s *Server) writeInfo() error {
+	path := filepath.Join(s.workspaceDir, InfoFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(Info{Socket: s.socketPath, PID: os.Getpid(), Version: ProtocolVersion}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bridge info: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeInfo removes InfoFile unless a later session in the same workspace
+// has replaced it
+func (s *Server) removeInfo() {
+	path := filepath.Join(s.workspaceDir, InfoFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var info Info
+	if json.Unmarshal(data, &info) == nil && info.Socket != s.socketPath {
+		return
+	}
+	_ = os.Remove(path)
+}
+
+// accept serves connections until the listener is closed
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		writeLock := &sync.Mutex{}
+		s.clients[conn] = writeLock
+		s.mu.Unlock()
+
+		go s.serve(conn, writeLock)
+	}
+}
+
+// serve greets a client and handles its messages until it disconnects
+func (s *Server) serve(conn net.Conn, writeLock *sync.Mutex) {
+	defer s.disconnect(conn)
+
+	workspace, err := filepath.Abs(s.workspaceDir)
+	if err != nil {
+		workspace = s.workspaceDir
+	}
+	if hello, err := NewMessage(TypeHello, Hello{Version: ProtocolVersion, Workspace: workspace}); err == nil {
+		if write(conn, writeLock, hello) != nil {
+			return
+		}
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		if reply := s.handle(scanner.Bytes()); reply != nil {
+			if write(conn, writeLock, *reply) != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle processes one message from a client, returning an error reply if
+// it could not be handled
+func (s *Server) handle(line []byte) *Message {
+	var msg Message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return errorReply(fmt.Sprintf("invalid message: %v", err))
+	}
+
+	switch msg.Type {
+	case TypeHello:
+		return nil
+	case TypeSelection:
+		var selection Selection
+		if err := msg.Decode(&selection); err != nil {
+			return errorReply(err.Error())
+		}
+		if selection.Text == "" {
+			return errorReply("selection has no text")
+		}
+		select {
+		case s.selections <- selection:
+			return nil
+		default:
+			return errorReply("too many pending selections")
+		}
+	default:
+		return errorReply(fmt.Sprintf("unknown message type %q", msg.Type))
+	}
+}
+
+// errorReply creates an error message
+func errorReply(text string) *Message {
+	msg, err := NewMessage(TypeError, Error{Message: text})
+	if err != nil {
+		return nil
+	}
+	return &msg
+}
+
+// send queues a message for broadcast, dropping it if the queue is full
+func (s *Server) send(msgType string, payload interface{}) {
+	msg, err := NewMessage(msgType, payload)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.outbox <- msg:
+	default:
+	}
+}
+
+// deliver broadcasts queued messages until the server is closed
+func (s *Server) deliver() {
+	for msg := range s.outbox {
+		s.broadcast(msg)
+	}
+}
+
+// broadcast sends a message to every connected client, dropping clients
+// that fail to receive it
+func (s *Server) broadcast(msg Message) {
+	s.mu.Lock()
+	clients := make(map[net.Conn]*sync.Mutex, len(s.clients))
+	for conn, writeLock := range s.clients {
+		clients[conn] = writeLock
+	}
+	s.mu.Unlock()
+
+	for conn, writeLock := range clients {
+		if err := write(conn, writeLock, msg); err != nil {
+			s.disconnect(conn)
+		}
+	}
+}
+
+// disconnect closes a client connection and forgets it
+func (s *Server) disconnect(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.clients, conn)
+	s.mu.Unlock()
+	conn.Close()
+}
+
+// write sends one message as a line of JSON
+func write(conn net.Conn, writeLock *sync.Mutex, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,161 @@
+package bridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startServer starts a server for a temporary workspace
+func startServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	workspace := t.TempDir()
+	server := NewServer(workspace, WithSocketPath(filepath.Join(t.TempDir(), "bridge.sock")))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server, workspace
+}
+
+// client is a test extension connection
+type client struct {
+	t       *testing.T
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+// connect connects to the server and reads its hello
+func connect(t *testing.T, server *Server) *client {
+	t.Helper()
+	conn, err := net.Dial("unix", server.SocketPath())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := &client{t: t, conn: conn, scanner: bufio.NewScanner(conn)}
+	if msg := c.read(); msg.Type != TypeHello {
+		t.Fatalf("expected hello, got %s", msg.Type)
+	}
+	return c
+}
+
+// read reads the next message
+func (c *client) read() Message {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if !c.scanner.Scan() {
+		c.t.Fatalf("no message: %v", c.scanner.Err())
+	}
+	var msg Message
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		c.t.Fatalf("invalid message %q: %v", c.scanner.Text(), err)
+	}
+	return msg
+}
+
+// write sends a raw line
+func (c *client) write(line string) {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(line + "\n")); err != nil {
+		c.t.Fatalf("write failed: %v", err)
+	}
+}
+
+func TestServer_InfoFile(t *testing.T) {
+	server, workspace := startServer(t)
+
+	data, err := os.ReadFile(filepath.Join(workspace, InfoFile))
+	if err != nil {
+		t.Fatalf("expected an info file: %v", err)
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("invalid info file: %v", err)
+	}
+	if info.Socket != server.SocketPath() || info.PID != os.Getpid() || info.Version != ProtocolVersion {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	fileInfo, err := os.Stat(server.SocketPath())
+	if err != nil || fileInfo.Mode().Perm() != 0o600 {
+		t.Errorf("expected a socket only the user can use, got %v, %v", fileInfo.Mode(), err)
+	}
+
+	server.Close()
+	if _, err := os.Stat(filepath.Join(workspace, InfoFile)); !os.IsNotExist(err) {
+		t.Error("expected Close to remove the info file")
+	}
+	if _, err := os.Stat(server.SocketPath()); !os.IsNotExist(err) {
+		t.Error("expected Close to remove the socket")
+	}
+}
+
+func TestServer_Broadcast(t *testing.T) {
+	server, workspace := startServer(t)
+	first, second := connect(t, server), connect(t, server)
+
+	server.OpenFile("pkg/main.go", 12)
+	server.ShowDiff(ShowDiff{ID: "a1", Path: "pkg/main.go", Diff: "-a\n+b\n"})
+	server.ResolveDiff("a1", true)
+
+	for _, c := range []*client{first, second} {
+		var open OpenFile
+		if err := c.read().Decode(&open); err != nil {
+			t.Fatal(err)
+		}
+		if open.Path != filepath.Join(workspace, "pkg/main.go") || open.Line != 12 {
+			t.Errorf("expected an absolute path and line, got %+v", open)
+		}
+
+		if msg := c.read(); msg.Type != TypeShowDiff {
+			t.Errorf("expected show_diff, got %s", msg.Type)
+		}
+
+		var resolved DiffResolved
+		msg := c.read()
+		if err := msg.Decode(&resolved); err != nil || msg.Type != TypeDiffResolved || resolved != (DiffResolved{ID: "a1", Approved: true}) {
+			t.Errorf("unexpected resolution %s %+v, %v", msg.Type, resolved, err)
+		}
+	}
+}
+
+func TestServer_Selection(t *testing.T) {
+	server, _ := startServer(t)
+	c := connect(t, server)
+
+	c.write(`{"type": "hello", "payload": {"version": 1, "client": "vscode"}}`)
+	c.write(`{"type": "selection", "payload": {"path": "/repo/a.go", "start_line": 3, "end_line": 5, "text": "x := 1"}}`)
+
+	select {
+	case selection := <-server.Selections():
+		if selection.Path != "/repo/a.go" || selection.StartLine != 3 || selection.Text != "x := 1" {
+			t.Errorf("unexpected selection: %+v", selection)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no selection received")
+	}
+}
+
+func TestServer_InvalidMessages(t *testing.T) {
+	server, _ := startServer(t)
+	c := connect(t, server)
+
+	for _, line := range []string{
+		`not json`,
+		`{"type": "launch_missiles"}`,
+		`{"type": "selection", "payload": {"path": "/repo/a.go"}}`,
+	} {
+		c.write(line)
+		var reply Error
+		msg := c.read()
+		if err := msg.Decode(&reply); err != nil || msg.Type != TypeError || reply.Message == "" {
+			t.Errorf("%s: expected an error reply, got %s %+v", line, msg.Type, reply)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// BundleVersion is the format version of configuration bundles
+const BundleVersion = "1"
+
+// Bundle is a shareable set of agent settings, so a team can standardize
+// agent behavior across its members: the global config sections that differ
+// from the defaults, with secrets left out, the user's workflows, and a
+// project config with its profiles. It is written as JSON:
+//
+//	forge config export -o team.json
+//	forge config import team.json
+type Bundle struct {
+	Version string `json:"version"`
+
+	// Sections holds the settings that differ from the defaults, by section ID
+	Sections map[string]map[string]interface{} `json:"sections,omitempty"`
+
+	// Workflows holds the workflow files by file name
+	Workflows map[string]string `json:"workflows,omitempty"`
+
+	// Project is a .forge/config.yaml. It is copied as is: project configs
+	// are meant to be committed, so they keep secrets in the environment.
+	Project string `json:"project,omitempty"`
+}
+
+// secretSection is implemented by sections holding secrets, which are left
+// out of bundles
+type secretSection interface {
+	SecretKeys() []string
+}
+
+// Bundle returns a bundle of the settings in m that differ from the
+// defaults, without secrets. It also returns the secrets that were set and
+// left out, as section.key names.
+func (m *Manager) Bundle() (*Bundle, []string) {
+	defaults := make(map[string]map[string]interface{})
+	for _, section := range defaultSections() {
+		defaults[section.ID()] = section.Data()
+	}
+
+	bundle := &Bundle{Version: BundleVersion, Sections: make(map[string]map[string]interface{})}
+	var omitted []string
+	for _, section := range m.GetSections() {
+		secrets := make(map[string]bool)
+		if s, ok := section.(secretSection); ok {
+			for _, key := range s.SecretKeys() {
+				secrets[key] = true
+			}
+		}
+
+		changed := make(map[string]interface{})
+		for key, value := range section.Data() {
+			if isDefault(value, defaults[section.ID()], key) {
+				continue
+			}
+			if secrets[key] {
+				omitted = append(omitted, section.ID()+"."+key)
+				continue
+			}
+			changed[key] = value
+		}
+		if len(changed) > 0 {
+			bundle.Sections[section.ID()] = changed
+		}
+	}
+	sort.Strings(omitted)
+	return bundle, omitted
+}
+
+// Apply sets the bundle's sections over the settings in m and saves them.
+// Settings the bundle leaves out, secrets among them, keep their value.
+// It returns the IDs of the sections changed.
+func (m *Manager) Apply(b *Bundle) ([]string, error) {
+	ids := make([]string, 0, len(b.Sections))
+	for id := range b.Sections {
+		if _, ok := m.GetSection(id); !ok {
+			return nil, fmt.Errorf("unknown setting %q: the bundle may be from a newer version of Forge", id)
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		section, _ := m.GetSection(id)
+		if err := section.SetData(b.Sections[id]); err != nil {
+			return nil, fmt.Errorf("invalid setting %q: %w", id, err)
+		}
+	}
+	if len(ids) > 0 {
+		if err := m.SaveAll(); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// AddWorkflows adds the workflow files in dir, if it exists
+func (b *Bundle) AddWorkflows(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read workflows: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isWorkflowFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read workflow: %w", err)
+		}
+		if b.Workflows == nil {
+			b.Workflows = make(map[string]string)
+		}
+		b.Workflows[entry.Name()] = string(data)
+	}
+	return nil
+}
+
+// AddProject adds workspaceDir's project config, if it has one
+func (b *Bundle) AddProject(workspaceDir string) error {
+	data, err := os.ReadFile(filepath.Join(workspaceDir, ProjectConfigPath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read project config: %w", err)
+	}
+	b.Project = string(data)
+	return nil
+}
+
+// WriteWorkflows writes the bundle's workflows to dir. Workflows that exist
+// with other content are skipped unless overwrite is set. It returns the
+// files written and skipped.
+func (b *Bundle) WriteWorkflows(dir string, overwrite bool) (written, skipped []string, err error) {
+	names := make([]string, 0, len(b.Workflows))
+	for name := range b.Workflows {
+		if !isWorkflowFile(name) || strings.ContainsAny(name, `/\`) {
+			return nil, nil, fmt.Errorf("invalid workflow file name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, nil, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create workflow directory: %w", err)
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		wrote, err := writeFile(path, b.Workflows[name], overwrite)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to write workflow: %w", err)
+		}
+		if wrote {
+			written = append(written, name)
+		} else {
+			skipped = append(skipped, name)
+		}
+	}
+	return written, skipped, nil
+}
+
+// WriteProject writes the bundle's project config to workspaceDir. An
+// existing project config with other content is kept unless overwrite is
+// set. It reports whether the file was written.
+func (b *Bundle) WriteProject(workspaceDir string, overwrite bool) (bool, error) {
+	if b.Project == "" {
+		return false, nil
+	}
+	path := filepath.Join(workspaceDir, ProjectConfigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	wrote, err := writeFile(path, b.Project, overwrite)
+	if err != nil {
+		return false, fmt.Errorf("failed to write project config: %w", err)
+	}
+	return wrote, nil
+}
+
+// Write writes the bundle as indented JSON
+func (b *Bundle) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadBundle reads a bundle written by Write
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	b := &Bundle{}
+	if err := json.NewDecoder(r).Decode(b); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if b.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %q (expected %q)", b.Version, BundleVersion)
+	}
+	return b, nil
+}
+
+// isDefault reports whether value is the default for key. Keys without a
+// default, such as tools auto-approval learns of at run time, default to
+// their zero value.
+func isDefault(value interface{}, defaults map[string]interface{}, key string) bool {
+	if def, ok := defaults[key]; ok {
+		return reflect.DeepEqual(value, def)
+	}
+	return value == nil || reflect.ValueOf(value).IsZero()
+}
+
+// isWorkflowFile reports whether name is a workflow file name
+func isWorkflowFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// writeFile writes content to path unless the file exists with other
+// content and overwrite is not set. Existing identical files count as
+// written.
+func writeFile(path, content string, overwrite bool) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err == nil && string(existing) != content && !overwrite {
+		return false, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, os.WriteFile(path, []byte(content), 0644)
+}
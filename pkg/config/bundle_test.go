@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestManager returns a manager of the default sections stored in dir
+func newTestManager(t *testing.T, dir string) *Manager {
+	t.Helper()
+	store, err := NewFileStore(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(store)
+	for _, section := range defaultSections() {
+		if err := m.RegisterSection(section); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	m := newTestManager(t, t.TempDir())
+	sections := map[string]map[string]interface{}{
+		"auto_approval": {"read_file": true},
+		"notifications": {"webhook_url": "https://hooks.slack.com/services/secret", "format": "slack"},
+		"provider":      {"headers": map[string]interface{}{"Authorization": "Bearer secret"}, "timeout": "2m"},
+	}
+	for id, data := range sections {
+		section, _ := m.GetSection(id)
+		if err := section.SetData(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundle, omitted := m.Bundle()
+	if len(omitted) != 2 || omitted[0] != "notifications.webhook_url" || omitted[1] != "provider.headers" {
+		t.Errorf("expected the webhook and headers to be left out, got %v", omitted)
+	}
+	if len(bundle.Sections) != 3 || bundle.Sections["notifications"]["format"] != "slack" {
+		t.Errorf("expected only the changed settings, got %v", bundle.Sections)
+	}
+	if _, ok := bundle.Sections["notifications"]["webhook_url"]; ok {
+		t.Error("expected the webhook URL to be left out")
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The importer keeps their own webhook
+	other := newTestManager(t, t.TempDir())
+	notifications, _ := other.GetSection("notifications")
+	if err := notifications.SetData(map[string]interface{}{"webhook_url": "https://example.com/mine"}); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := other.Apply(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 3 {
+		t.Errorf("expected three sections to change, got %v", changed)
+	}
+	data := notifications.Data()
+	if data["webhook_url"] != "https://example.com/mine" || data["format"] != "slack" {
+		t.Errorf("expected the bundle's format and the importer's webhook, got %v", data)
+	}
+
+	read.Sections["unknown"] = map[string]interface{}{"x": 1}
+	if _, err := other.Apply(read); err == nil {
+		t.Error("expected an unknown section to be rejected")
+	}
+}
+
+func TestBundleWriteWorkflows(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "lint.yaml"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bundle := &Bundle{Workflows: map[string]string{"lint.yaml": "theirs", "release.yml": "theirs"}}
+
+	written, skipped, err := bundle.WriteWorkflows(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1 || written[0] != "release.yml" || len(skipped) != 1 || skipped[0] != "lint.yaml" {
+		t.Errorf("expected the differing workflow to be kept, got written %v, skipped %v", written, skipped)
+	}
+	if _, _, err := bundle.WriteWorkflows(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "lint.yaml")); string(data) != "theirs" {
+		t.Errorf("expected -force to replace the workflow, got %q", data)
+	}
+
+	bundle.Workflows = map[string]string{"../escape.yaml": "x"}
+	if _, _, err := bundle.WriteWorkflows(dir, false); err == nil {
+		t.Error("expected a workflow name with a path to be rejected")
+	}
+}
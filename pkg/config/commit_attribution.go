@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// trailerPattern matches a git trailer line such as "Co-authored-by: {user} <{email}>"
+var trailerPattern = regexp.MustCompile(`^[A-Za-z0-9-]+: \S`)
+
+// CommitAttributionSection configures how commits made with /commit are
+// attributed, so agent commits can be told apart from human ones. Values may
+// use the placeholders {user} and {email} (the user's git identity) and
+// {session} (the Forge session ID):
+//
+//	"committer_name": "Forge Agent on behalf of {user}",
+//	"committer_email": "forge-agent@example.com",
+//	"trailers": ["Co-authored-by: {user} <{email}>", "Session-ID: {session}"]
+//
+// The author is always the user's own git identity.
+type CommitAttributionSection struct {
+	committerName  string   // "" = git's configured committer
+	committerEmail string   // "" = git's configured committer email
+	trailers       []string // Lines appended to every commit message
+}
+
+// NewCommitAttributionSection creates a new commit attribution section with no attribution.
+func NewCommitAttributionSection() *CommitAttributionSection {
+	return &CommitAttributionSection{}
+}
+
+// ID returns the section identifier.
+func (s *CommitAttributionSection) ID() string {
+	return "commit_attribution"
+}
+
+// Title returns the section title.
+func (s *CommitAttributionSection) Title() string {
+	return "Commit Attribution"
+}
+
+// Description returns the section description.
+func (s *CommitAttributionSection) Description() string {
+	return "Committer identity and trailer lines for /commit. Use {user}, {email} and {session} for the user's git identity and the session ID."
+}
+
+// Data returns the current configuration data.
+func (s *CommitAttributionSection) Data() map[string]interface{} {
+	trailers := make([]interface{}, len(s.trailers))
+	for i, trailer := range s.trailers {
+		trailers[i] = trailer
+	}
+	return map[string]interface{}{
+		"committer_name":  s.committerName,
+		"committer_email": s.committerEmail,
+		"trailers":        trailers,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *CommitAttributionSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	for key, target := range map[string]*string{
+		"committer_name":  &s.committerName,
+		"committer_email": &s.committerEmail,
+	} {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+
+	if value, ok := data["trailers"]; ok {
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid value type for 'trailers': expected list, got %T", value)
+		}
+		trailers := make([]string, 0, len(list))
+		for _, item := range list {
+			trailer, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("invalid trailer: expected string, got %T", item)
+			}
+			trailers = append(trailers, strings.TrimSpace(trailer))
+		}
+		s.trailers = trailers
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *CommitAttributionSection) Validate() error {
+	if strings.ContainsAny(s.committerName, "<>\n") {
+		return fmt.Errorf("committer_name must not contain '<', '>' or newlines")
+	}
+	if strings.ContainsAny(s.committerEmail, "<> \n") {
+		return fmt.Errorf("committer_email must be a bare address without '<', '>' or spaces")
+	}
+	for _, trailer := range s.trailers {
+		if !trailerPattern.MatchString(trailer) || strings.Contains(trailer, "\n") {
+			return fmt.Errorf("invalid trailer %q: expected a single \"Key: value\" line", trailer)
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (no attribution).
+func (s *CommitAttributionSection) Reset() {
+	s.committerName = ""
+	s.committerEmail = ""
+	s.trailers = nil
+}
+
+// CommitterName returns the committer name template, or "" for git's configured committer.
+func (s *CommitAttributionSection) CommitterName() string {
+	return s.committerName
+}
+
+// CommitterEmail returns the committer email template, or "" for git's configured committer.
+func (s *CommitAttributionSection) CommitterEmail() string {
+	return s.committerEmail
+}
+
+// Trailers returns the trailer line templates appended to commit messages.
+func (s *CommitAttributionSection) Trailers() []string {
+	return s.trailers
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Commit message styles
+const (
+	CommitStyleFreeform     = "freeform"
+	CommitStyleConventional = "conventional"
+	CommitStyleTemplate     = "template"
+)
+
+// CommitMessageSection configures the messages /commit generates. The
+// conventional and template styles have the model pick only a type and
+// description; the scope is inferred from the changed paths (or the scopes
+// map of path prefixes) and the issue ID is taken from the branch name:
+//
+//	"style": "template",
+//	"template": "{type}({scope}): {description}\n\nRefs: {issue}",
+//	"scopes": {"pkg/executor/tui": "tui"},
+//	"issue_pattern": "[A-Z]+-[0-9]+"
+type CommitMessageSection struct {
+	style        string            // One of the CommitStyle* values
+	template     string            // Template for the template style
+	types        []string          // Allowed commit types; empty = the Conventional Commits types
+	scopes       map[string]string // Path prefix → scope; empty = infer from directories
+	issuePattern string            // Regexp for issue IDs in branch names; "" = Jira-style IDs
+}
+
+// NewCommitMessageSection creates a new commit message section with the freeform style.
+func NewCommitMessageSection() *CommitMessageSection {
+	return &CommitMessageSection{
+		style:  CommitStyleFreeform,
+		scopes: make(map[string]string),
+	}
+}
+
+// ID returns the section identifier.
+func (s *CommitMessageSection) ID() string {
+	return "commit_message"
+}
+
+// Title returns the section title.
+func (s *CommitMessageSection) Title() string {
+	return "Commit Messages"
+}
+
+// Description returns the section description.
+func (s *CommitMessageSection) Description() string {
+	return "Style of generated commit messages: freeform, conventional, or a template using {type}, {scope}, {description}, {issue} and {branch}."
+}
+
+// Data returns the current configuration data.
+func (s *CommitMessageSection) Data() map[string]interface{} {
+	types := make([]interface{}, len(s.types))
+	for i, t := range s.types {
+		types[i] = t
+	}
+	scopes := make(map[string]interface{}, len(s.scopes))
+	for prefix, scope := range s.scopes {
+		scopes[prefix] = scope
+	}
+	return map[string]interface{}{
+		"style":         s.style,
+		"template":      s.template,
+		"types":         types,
+		"scopes":        scopes,
+		"issue_pattern": s.issuePattern,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *CommitMessageSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	for key, target := range map[string]*string{
+		"style":         &s.style,
+		"template":      &s.template,
+		"issue_pattern": &s.issuePattern,
+	} {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = str
+	}
+	s.style = strings.ToLower(strings.TrimSpace(s.style))
+	s.issuePattern = strings.TrimSpace(s.issuePattern)
+
+	if value, ok := data["types"]; ok {
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid value type for 'types': expected list, got %T", value)
+		}
+		types := make([]string, 0, len(list))
+		for _, item := range list {
+			t, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("invalid commit type: expected string, got %T", item)
+			}
+			types = append(types, strings.TrimSpace(t))
+		}
+		s.types = types
+	}
+
+	if value, ok := data["scopes"]; ok {
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid value type for 'scopes': expected map, got %T", value)
+		}
+		scopes := make(map[string]string, len(entries))
+		for prefix, entry := range entries {
+			scope, ok := entry.(string)
+			if !ok {
+				return fmt.Errorf("invalid scope for '%s': expected string, got %T", prefix, entry)
+			}
+			scopes[prefix] = strings.TrimSpace(scope)
+		}
+		s.scopes = scopes
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *CommitMessageSection) Validate() error {
+	switch s.style {
+	case CommitStyleFreeform, CommitStyleConventional:
+	case CommitStyleTemplate:
+		if !strings.Contains(s.template, "{description}") {
+			return fmt.Errorf("template style requires a template containing {description}")
+		}
+	default:
+		return fmt.Errorf("invalid style %q: must be freeform, conventional or template", s.style)
+	}
+
+	for _, t := range s.types {
+		if t == "" || strings.ContainsAny(t, " :()") {
+			return fmt.Errorf("invalid commit type %q", t)
+		}
+	}
+	for prefix, scope := range s.scopes {
+		if prefix == "" || scope == "" {
+			return fmt.Errorf("scopes entries need a path prefix and a scope")
+		}
+	}
+	if s.issuePattern != "" {
+		if _, err := regexp.Compile(s.issuePattern); err != nil {
+			return fmt.Errorf("invalid issue_pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (freeform messages).
+func (s *CommitMessageSection) Reset() {
+	s.style = CommitStyleFreeform
+	s.template = ""
+	s.types = nil
+	s.scopes = make(map[string]string)
+	s.issuePattern = ""
+}
+
+// Style returns the configured message style.
+func (s *CommitMessageSection) Style() string {
+	return s.style
+}
+
+// Template returns the message template for the template style.
+func (s *CommitMessageSection) Template() string {
+	return s.template
+}
+
+// Types returns the allowed commit types, or nil for the Conventional Commits types.
+func (s *CommitMessageSection) Types() []string {
+	return s.types
+}
+
+// Scopes returns the path prefix to scope mapping.
+func (s *CommitMessageSection) Scopes() map[string]string {
+	scopes := make(map[string]string, len(s.scopes))
+	for prefix, scope := range s.scopes {
+		scopes[prefix] = scope
+	}
+	return scopes
+}
+
+// IssuePattern returns the regexp for issue IDs in branch names, or "" for the default.
+func (s *CommitMessageSection) IssuePattern() string {
+	return s.issuePattern
+}
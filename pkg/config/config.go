@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"sync"
 )
 
@@ -13,25 +14,45 @@ var (
 // Initialize creates and initializes the global configuration manager.
 // This should be called once at application startup.
 func Initialize(configPath string) error {
+	return InitializeWithProject(configPath, nil)
+}
+
+// InitializeWithProject initializes the global configuration manager with the
+// sections of a project config layered over the global config file. Project
+// values are visible through every section but are not written back to the
+// global file. A nil project is the same as Initialize.
+func InitializeWithProject(configPath string, project *ProjectConfig) error {
 	globalMu.Lock()
 	defer globalMu.Unlock()
 
 	// Create file store
-	store, err := NewFileStore(configPath)
+	fileStore, err := NewFileStore(configPath)
 	if err != nil {
 		return err
 	}
 
+	var store Store = fileStore
+	if project != nil && len(project.Sections) > 0 {
+		store = NewLayeredStore(fileStore, project.Sections)
+	}
+
 	// Create manager
 	manager := NewManager(store)
 
 	// Register default sections
-	if err := manager.RegisterSection(NewAutoApprovalSection()); err != nil {
-		return err
+	for _, section := range defaultSections() {
+		if err := manager.RegisterSection(section); err != nil {
+			return err
+		}
 	}
 
-	if err := manager.RegisterSection(NewCommandWhitelistSection()); err != nil {
-		return err
+	// Catch misspelled section names in the project config
+	if project != nil {
+		for id := range project.Sections {
+			if _, ok := manager.GetSection(id); !ok {
+				return fmt.Errorf("%s: unknown setting %q", project.Path, id)
+			}
+		}
 	}
 
 	// Load configuration
@@ -43,6 +64,28 @@ func Initialize(configPath string) error {
 	return nil
 }
 
+// defaultSections returns the configuration sections, with their defaults,
+// in the order they are displayed
+func defaultSections() []Section {
+	return []Section{
+		NewAutoApprovalSection(),
+		NewCommandWhitelistSection(),
+		NewSystemPromptSection(),
+		NewUtilityModelSection(),
+		NewUpdatesSection(),
+		NewHooksSection(),
+		NewNotificationsSection(),
+		NewCommitAttributionSection(),
+		NewCommitMessageSection(),
+		NewIssueTrackerSection(),
+		NewLicensesSection(),
+		NewWASMToolsSection(),
+		NewProviderSection(),
+		NewDirtyWorkspaceSection(),
+		NewSyntaxCheckSection(),
+	}
+}
+
 // Global returns the global configuration manager.
 // Panics if Initialize has not been called.
 func Global() *Manager {
@@ -103,6 +146,206 @@ func GetCommandWhitelist() *CommandWhitelistSection {
 	return whitelist
 }
 
+// GetSystemPrompt returns the system prompt section from global config.
+// Returns nil if config is not initialized.
+func GetSystemPrompt() *SystemPromptSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("system_prompt")
+	if !ok {
+		return nil
+	}
+
+	systemPrompt, ok := section.(*SystemPromptSection)
+	if !ok {
+		return nil
+	}
+
+	return systemPrompt
+}
+
+// GetUtilityModel returns the utility model section from global config.
+// Returns nil if config is not initialized.
+func GetUtilityModel() *UtilityModelSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("utility_model")
+	if !ok {
+		return nil
+	}
+
+	utilityModel, ok := section.(*UtilityModelSection)
+	if !ok {
+		return nil
+	}
+
+	return utilityModel
+}
+
+// GetProvider returns the provider connection section from global config.
+// Returns nil if config is not initialized.
+func GetProvider() *ProviderSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("provider")
+	if !ok {
+		return nil
+	}
+
+	provider, ok := section.(*ProviderSection)
+	if !ok {
+		return nil
+	}
+
+	return provider
+}
+
+// GetUpdates returns the updates section from global config.
+// Returns nil if config is not initialized.
+func GetUpdates() *UpdatesSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("updates")
+	if !ok {
+		return nil
+	}
+
+	updates, ok := section.(*UpdatesSection)
+	if !ok {
+		return nil
+	}
+
+	return updates
+}
+
+// GetHooks returns the hooks section from global config.
+// Returns nil if config is not initialized.
+func GetHooks() *HooksSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("hooks")
+	if !ok {
+		return nil
+	}
+
+	hooks, ok := section.(*HooksSection)
+	if !ok {
+		return nil
+	}
+
+	return hooks
+}
+
+// GetNotifications returns the notifications section from global config.
+// Returns nil if config is not initialized.
+func GetNotifications() *NotificationsSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("notifications")
+	if !ok {
+		return nil
+	}
+
+	notifications, ok := section.(*NotificationsSection)
+	if !ok {
+		return nil
+	}
+
+	return notifications
+}
+
+// GetCommitAttribution returns the commit attribution section from global config.
+// Returns nil if config is not initialized.
+func GetCommitAttribution() *CommitAttributionSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("commit_attribution")
+	if !ok {
+		return nil
+	}
+
+	attribution, ok := section.(*CommitAttributionSection)
+	if !ok {
+		return nil
+	}
+
+	return attribution
+}
+
+// GetCommitMessage returns the commit message section from global config.
+// Returns nil if config is not initialized.
+func GetCommitMessage() *CommitMessageSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("commit_message")
+	if !ok {
+		return nil
+	}
+
+	commitMessage, ok := section.(*CommitMessageSection)
+	if !ok {
+		return nil
+	}
+
+	return commitMessage
+}
+
+// GetIssueTracker returns the issue tracker section from global config.
+// Returns nil if config is not initialized.
+func GetIssueTracker() *IssueTrackerSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("issue_tracker")
+	if !ok {
+		return nil
+	}
+
+	issueTracker, ok := section.(*IssueTrackerSection)
+	if !ok {
+		return nil
+	}
+
+	return issueTracker
+}
+
+// GetLicenses returns the licenses section from global config.
+// Returns nil if config is not initialized.
+func GetLicenses() *LicensesSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("licenses")
+	if !ok {
+		return nil
+	}
+
+	licenses, ok := section.(*LicensesSection)
+	if !ok {
+		return nil
+	}
+
+	return licenses
+}
+
 // IsToolAutoApproved checks if a tool is configured for auto-approval.
 // Returns false if config is not initialized.
 func IsToolAutoApproved(toolName string) bool {
@@ -122,3 +365,63 @@ func IsCommandWhitelisted(command string) bool {
 	}
 	return whitelist.IsCommandWhitelisted(command)
 }
+
+// GetWASMTools returns the WebAssembly tools section from global config.
+// Returns nil if config is not initialized.
+func GetWASMTools() *WASMToolsSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("wasm_tools")
+	if !ok {
+		return nil
+	}
+
+	wasmTools, ok := section.(*WASMToolsSection)
+	if !ok {
+		return nil
+	}
+
+	return wasmTools
+}
+
+// GetDirtyWorkspace returns the dirty workspace section from global config.
+// Returns nil if config is not initialized.
+func GetDirtyWorkspace() *DirtyWorkspaceSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("dirty_workspace")
+	if !ok {
+		return nil
+	}
+
+	dirtyWorkspace, ok := section.(*DirtyWorkspaceSection)
+	if !ok {
+		return nil
+	}
+
+	return dirtyWorkspace
+}
+
+// GetSyntaxCheck returns the syntax check section from global config.
+// Returns nil if config is not initialized.
+func GetSyntaxCheck() *SyntaxCheckSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection("syntax_check")
+	if !ok {
+		return nil
+	}
+
+	syntaxCheck, ok := section.(*SyntaxCheckSection)
+	if !ok {
+		return nil
+	}
+
+	return syntaxCheck
+}
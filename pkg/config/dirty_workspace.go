@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// What a session does when it starts in a workspace with uncommitted changes
+const (
+	DirtyCheckOff     = "off"     // Nothing
+	DirtyCheckWarn    = "warn"    // Print a warning and start
+	DirtyCheckConfirm = "confirm" // Offer to stash or snapshot the changes, and start only once the user chooses
+)
+
+// DirtyWorkspaceSection configures the startup check for uncommitted
+// changes, which the agent's edits would otherwise mix with. It is usually
+// set per project in .forge/config.yaml, and -dirty-check overrides it.
+type DirtyWorkspaceSection struct {
+	check string
+}
+
+// NewDirtyWorkspaceSection creates a new dirty workspace section with the check off.
+func NewDirtyWorkspaceSection() *DirtyWorkspaceSection {
+	return &DirtyWorkspaceSection{check: DirtyCheckOff}
+}
+
+// ID returns the section identifier.
+func (s *DirtyWorkspaceSection) ID() string {
+	return "dirty_workspace"
+}
+
+// Title returns the section title.
+func (s *DirtyWorkspaceSection) Title() string {
+	return "Uncommitted Changes"
+}
+
+// Description returns the section description.
+func (s *DirtyWorkspaceSection) Description() string {
+	return "Check for uncommitted changes at startup: off, warn, or confirm to be offered a stash or snapshot before the agent edits anything."
+}
+
+// Data returns the current configuration data.
+func (s *DirtyWorkspaceSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"check": s.check,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+func (s *DirtyWorkspaceSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	value, ok := data["check"]
+	if !ok {
+		return nil
+	}
+	check, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid value type for 'check': expected string, got %T", value)
+	}
+	s.check = strings.ToLower(strings.TrimSpace(check))
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *DirtyWorkspaceSection) Validate() error {
+	return ValidateDirtyCheck(s.check)
+}
+
+// Reset resets the section to default configuration (check off).
+func (s *DirtyWorkspaceSection) Reset() {
+	s.check = DirtyCheckOff
+}
+
+// Check returns what a session does when it starts with uncommitted changes.
+func (s *DirtyWorkspaceSection) Check() string {
+	return s.check
+}
+
+// ValidateDirtyCheck reports whether check is one of the DirtyCheck modes.
+func ValidateDirtyCheck(check string) error {
+	switch check {
+	case DirtyCheckOff, DirtyCheckWarn, DirtyCheckConfirm:
+		return nil
+	}
+	return fmt.Errorf("check must be %q, %q or %q, got %q", DirtyCheckOff, DirtyCheckWarn, DirtyCheckConfirm, check)
+}
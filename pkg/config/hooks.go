@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HookEvents are the lifecycle events hooks can run on, in lifecycle order
+var HookEvents = []string{"session_start", "pre_turn", "pre_tool", "post_tool", "post_turn", "session_end"}
+
+// HookConfig is a user-defined command run on a lifecycle event
+type HookConfig struct {
+	Command string        // Shell command; receives the event as JSON on stdin
+	Tools   []string      // Tool events only: tools the hook applies to (empty = all)
+	Timeout time.Duration // 0 = the default hook timeout
+}
+
+// HooksSection configures commands run on agent lifecycle events. Each event
+// maps to a list of hooks:
+//
+//	"pre_tool": [{"command": "./scripts/guard.sh", "tools": ["execute_command"], "timeout": 10}]
+//
+// The timeout is in seconds.
+type HooksSection struct {
+	hooks map[string][]HookConfig
+}
+
+// NewHooksSection creates a new hooks section with no hooks.
+func NewHooksSection() *HooksSection {
+	return &HooksSection{
+		hooks: make(map[string][]HookConfig),
+	}
+}
+
+// ID returns the section identifier.
+func (s *HooksSection) ID() string {
+	return "hooks"
+}
+
+// Title returns the section title.
+func (s *HooksSection) Title() string {
+	return "Hooks"
+}
+
+// Description returns the section description.
+func (s *HooksSection) Description() string {
+	return "Commands run on session_start, pre_turn, pre_tool, post_tool, post_turn and session_end. A pre_tool hook exiting with code 2 denies the tool call."
+}
+
+// Data returns the current configuration data.
+func (s *HooksSection) Data() map[string]interface{} {
+	data := make(map[string]interface{}, len(HookEvents))
+	for _, event := range HookEvents {
+		list := make([]interface{}, 0, len(s.hooks[event]))
+		for _, hook := range s.hooks[event] {
+			entry := map[string]interface{}{"command": hook.Command}
+			if len(hook.Tools) > 0 {
+				tools := make([]interface{}, len(hook.Tools))
+				for i, tool := range hook.Tools {
+					tools[i] = tool
+				}
+				entry["tools"] = tools
+			}
+			if hook.Timeout > 0 {
+				entry["timeout"] = hook.Timeout.Seconds()
+			}
+			list = append(list, entry)
+		}
+		data[event] = list
+	}
+	return data
+}
+
+// SetData updates the configuration from the provided data.
+// Events that are absent keep their current hooks.
+func (s *HooksSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	for event, value := range data {
+		if !isHookEvent(event) {
+			return fmt.Errorf("unknown hook event '%s' (expected one of %s)", event, strings.Join(HookEvents, ", "))
+		}
+
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid hooks for '%s': expected list, got %T", event, value)
+		}
+
+		hooks := make([]HookConfig, 0, len(list))
+		for i, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid hook %d for '%s': expected map, got %T", i, event, item)
+			}
+			hook, err := parseHookConfig(entry)
+			if err != nil {
+				return fmt.Errorf("invalid hook %d for '%s': %w", i, event, err)
+			}
+			hooks = append(hooks, hook)
+		}
+		s.hooks[event] = hooks
+	}
+
+	return nil
+}
+
+// parseHookConfig reads a hook from its config data
+func parseHookConfig(entry map[string]interface{}) (HookConfig, error) {
+	var hook HookConfig
+
+	command, ok := entry["command"].(string)
+	if !ok {
+		return hook, fmt.Errorf("missing or invalid command field")
+	}
+	hook.Command = strings.TrimSpace(command)
+
+	if value, ok := entry["tools"]; ok {
+		tools, ok := value.([]interface{})
+		if !ok {
+			return hook, fmt.Errorf("invalid tools: expected list, got %T", value)
+		}
+		for _, tool := range tools {
+			name, ok := tool.(string)
+			if !ok {
+				return hook, fmt.Errorf("invalid tool name: expected string, got %T", tool)
+			}
+			hook.Tools = append(hook.Tools, name)
+		}
+	}
+
+	if value, ok := entry["timeout"]; ok {
+		seconds, ok := value.(float64)
+		if !ok {
+			return hook, fmt.Errorf("invalid timeout: expected seconds, got %T", value)
+		}
+		hook.Timeout = time.Duration(seconds * float64(time.Second))
+	}
+
+	return hook, nil
+}
+
+// Validate validates the current configuration.
+func (s *HooksSection) Validate() error {
+	for event, hooks := range s.hooks {
+		toolEvent := event == "pre_tool" || event == "post_tool"
+		for i, hook := range hooks {
+			if hook.Command == "" {
+				return fmt.Errorf("hook %d for '%s' has no command", i, event)
+			}
+			if len(hook.Tools) > 0 && !toolEvent {
+				return fmt.Errorf("hook %d for '%s': tools only apply to pre_tool and post_tool hooks", i, event)
+			}
+			if hook.Timeout < 0 {
+				return fmt.Errorf("hook %d for '%s' has a negative timeout", i, event)
+			}
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (no hooks).
+func (s *HooksSection) Reset() {
+	s.hooks = make(map[string][]HookConfig)
+}
+
+// Hooks returns the hooks configured for event, in the order they run.
+func (s *HooksSection) Hooks(event string) []HookConfig {
+	return s.hooks[event]
+}
+
+// isHookEvent reports whether event is a known hook event
+func isHookEvent(event string) bool {
+	for _, known := range HookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Issue tracker providers
+const (
+	IssueTrackerGitHub = "github"
+	IssueTrackerJira   = "jira"
+)
+
+// envVarName matches valid environment variable names
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IssueTrackerSection configures the issue tracker behind the get_issue and
+// search_issues tools and /issue. Credentials are not stored in the config;
+// token_env and email_env name the environment variables they are read from:
+//
+//	"provider": "jira",
+//	"base_url": "https://example.atlassian.net",
+//	"project": "PROJ",
+//	"token_env": "JIRA_API_TOKEN",
+//	"email_env": "JIRA_EMAIL"
+type IssueTrackerSection struct {
+	provider   string // github, jira, or "" to detect GitHub from the origin remote
+	repository string // GitHub owner/name; "" = from the origin remote
+	baseURL    string // Jira site, or GitHub Enterprise API endpoint
+	project    string // Jira project key searches are limited to
+	tokenEnv   string // Variable holding the API token; "" = provider default
+	emailEnv   string // Variable holding the Jira account email; "" = JIRA_EMAIL
+}
+
+// NewIssueTrackerSection creates a new issue tracker section that detects GitHub repositories.
+func NewIssueTrackerSection() *IssueTrackerSection {
+	return &IssueTrackerSection{}
+}
+
+// ID returns the section identifier.
+func (s *IssueTrackerSection) ID() string {
+	return "issue_tracker"
+}
+
+// Title returns the section title.
+func (s *IssueTrackerSection) Title() string {
+	return "Issue Tracker"
+}
+
+// Description returns the section description.
+func (s *IssueTrackerSection) Description() string {
+	return "GitHub Issues or Jira tracker read by get_issue, search_issues and /issue. Credentials come from environment variables."
+}
+
+// Data returns the current configuration data.
+func (s *IssueTrackerSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"provider":   s.provider,
+		"repository": s.repository,
+		"base_url":   s.baseURL,
+		"project":    s.project,
+		"token_env":  s.tokenEnv,
+		"email_env":  s.emailEnv,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *IssueTrackerSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	for key, target := range map[string]*string{
+		"provider":   &s.provider,
+		"repository": &s.repository,
+		"base_url":   &s.baseURL,
+		"project":    &s.project,
+		"token_env":  &s.tokenEnv,
+		"email_env":  &s.emailEnv,
+	} {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+	s.provider = strings.ToLower(s.provider)
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *IssueTrackerSection) Validate() error {
+	switch s.provider {
+	case "", IssueTrackerGitHub:
+		if s.repository != "" && strings.Count(s.repository, "/") != 1 {
+			return fmt.Errorf("invalid repository %q: expected owner/name", s.repository)
+		}
+	case IssueTrackerJira:
+		if s.baseURL == "" {
+			return fmt.Errorf("jira provider requires base_url")
+		}
+	default:
+		return fmt.Errorf("invalid provider %q: must be github or jira", s.provider)
+	}
+
+	if s.baseURL != "" {
+		u, err := url.Parse(s.baseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid base_url %q: expected an http(s) URL", s.baseURL)
+		}
+	}
+	for key, name := range map[string]string{"token_env": s.tokenEnv, "email_env": s.emailEnv} {
+		if name != "" && !envVarName.MatchString(name) {
+			return fmt.Errorf("invalid %s %q: expected an environment variable name", key, name)
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (GitHub detected from the origin remote).
+func (s *IssueTrackerSection) Reset() {
+	s.provider = ""
+	s.repository = ""
+	s.baseURL = ""
+	s.project = ""
+	s.tokenEnv = ""
+	s.emailEnv = ""
+}
+
+// Provider returns the configured provider, or "" to detect GitHub from the origin remote.
+func (s *IssueTrackerSection) Provider() string {
+	return s.provider
+}
+
+// Repository returns the GitHub repository (owner/name), or "" to use the origin remote.
+func (s *IssueTrackerSection) Repository() string {
+	return s.repository
+}
+
+// BaseURL returns the Jira site or GitHub Enterprise API endpoint.
+func (s *IssueTrackerSection) BaseURL() string {
+	return s.baseURL
+}
+
+// Project returns the Jira project key searches are limited to.
+func (s *IssueTrackerSection) Project() string {
+	return s.project
+}
+
+// TokenEnv returns the name of the variable holding the API token, or "" for the provider default.
+func (s *IssueTrackerSection) TokenEnv() string {
+	return s.tokenEnv
+}
+
+// EmailEnv returns the name of the variable holding the Jira account email, or "" for JIRA_EMAIL.
+func (s *IssueTrackerSection) EmailEnv() string {
+	return s.emailEnv
+}
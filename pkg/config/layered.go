@@ -0,0 +1,116 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// LayeredStore overlays project settings on a base store. Reads return the
+// base data with the overlay's keys taking precedence. Writes go to the base
+// store, except that overlay values written back unchanged are not copied
+// into it, so saving the merged configuration never leaks project settings
+// into the global file.
+type LayeredStore struct {
+	base    Store
+	overlay map[string]map[string]interface{}
+	mu      sync.RWMutex
+}
+
+// NewLayeredStore creates a store that layers overlay (section ID to data) over base
+func NewLayeredStore(base Store, overlay map[string]map[string]interface{}) *LayeredStore {
+	return &LayeredStore{
+		base:    base,
+		overlay: overlay,
+	}
+}
+
+// Base returns the store that receives writes
+func (s *LayeredStore) Base() Store {
+	return s.base
+}
+
+// Load loads the base configuration from disk.
+func (s *LayeredStore) Load() error {
+	return s.base.Load()
+}
+
+// Save saves the base configuration to disk.
+func (s *LayeredStore) Save() error {
+	return s.base.Save()
+}
+
+// GetSection returns the base section data with the overlay applied.
+func (s *LayeredStore) GetSection(sectionID string) (map[string]interface{}, error) {
+	data, err := s.base.GetSection(sectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for k, v := range s.overlay[sectionID] {
+		data[k] = v
+	}
+	return data, nil
+}
+
+// SetSection stores section data in the base store. Keys still holding their
+// overlay value keep whatever the base store had for them.
+func (s *LayeredStore) SetSection(sectionID string, data map[string]interface{}) error {
+	base, err := s.base.GetSection(sectionID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	overlay := s.overlay[sectionID]
+	s.mu.RUnlock()
+
+	copy := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		copy[k] = v
+	}
+	for k, v := range overlay {
+		current, ok := copy[k]
+		if !ok || !reflect.DeepEqual(current, v) {
+			continue // Changed by the user: store the new value
+		}
+		if original, ok := base[k]; ok {
+			copy[k] = original
+		} else {
+			delete(copy, k)
+		}
+	}
+
+	return s.base.SetSection(sectionID, copy)
+}
+
+// GetAll returns all base data with the overlay applied.
+func (s *LayeredStore) GetAll() (map[string]map[string]interface{}, error) {
+	all, err := s.base.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sectionID, overlay := range s.overlay {
+		if all[sectionID] == nil {
+			all[sectionID] = make(map[string]interface{}, len(overlay))
+		}
+		for k, v := range overlay {
+			all[sectionID][k] = v
+		}
+	}
+	return all, nil
+}
+
+// SetAll stores all section data in the base store, as SetSection does for each section.
+func (s *LayeredStore) SetAll(data map[string]map[string]interface{}) error {
+	for sectionID, sectionData := range data {
+		if err := s.SetSection(sectionID, sectionData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
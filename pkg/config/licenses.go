@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// LicensesSection configures check_licenses and insert_license_headers: the
+// header every source file must start with, and the licenses dependencies
+// may use. The header is given without comment markers, which are added for
+// each file's language; {year} and {owner} are replaced when a header is
+// inserted, and any year or year range is accepted when checking:
+//
+//	"header": "Copyright {year} {owner}\nSPDX-License-Identifier: Apache-2.0",
+//	"owner": "Example Corp",
+//	"exclude": ["third_party/", "*.pb.go"],
+//	"allowed": ["MIT", "Apache-2.0", "BSD-3-Clause"]
+type LicensesSection struct {
+	header  string   // "" = headers aren't checked
+	owner   string   // Replaces {owner} in the header
+	exclude []string // Gitignore-style patterns of files that need no header
+	allowed []string // SPDX identifiers dependencies may use; empty = any
+}
+
+// NewLicensesSection creates a new licenses section that checks nothing.
+func NewLicensesSection() *LicensesSection {
+	return &LicensesSection{}
+}
+
+// ID returns the section identifier.
+func (s *LicensesSection) ID() string {
+	return "licenses"
+}
+
+// Title returns the section title.
+func (s *LicensesSection) Title() string {
+	return "Licenses"
+}
+
+// Description returns the section description.
+func (s *LicensesSection) Description() string {
+	return "Source file header template and allowed dependency licenses for check_licenses and insert_license_headers. Use {year} and {owner} in the header."
+}
+
+// Data returns the current configuration data.
+func (s *LicensesSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"header":  s.header,
+		"owner":   s.owner,
+		"exclude": stringList(s.exclude),
+		"allowed": stringList(s.allowed),
+	}
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *LicensesSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	for key, target := range map[string]*string{
+		"header": &s.header,
+		"owner":  &s.owner,
+	} {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+
+	for key, target := range map[string]*[]string{
+		"exclude": &s.exclude,
+		"allowed": &s.allowed,
+	} {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected list, got %T", key, value)
+		}
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			str, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("invalid entry in '%s': expected string, got %T", key, item)
+			}
+			if str = strings.TrimSpace(str); str != "" {
+				items = append(items, str)
+			}
+		}
+		*target = items
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *LicensesSection) Validate() error {
+	if strings.Contains(s.header, "{owner}") && s.owner == "" {
+		return fmt.Errorf("header uses {owner} but owner is not set")
+	}
+	for _, pattern := range s.exclude {
+		if _, err := path.Match(strings.TrimSuffix(strings.TrimPrefix(pattern, "!"), "/"), ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	for _, license := range s.allowed {
+		if strings.ContainsAny(license, " ()") {
+			return fmt.Errorf("invalid allowed license %q: expected a single SPDX identifier such as MIT", license)
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (nothing checked).
+func (s *LicensesSection) Reset() {
+	s.header = ""
+	s.owner = ""
+	s.exclude = nil
+	s.allowed = nil
+}
+
+// Header returns the header template, or "" when headers aren't checked.
+func (s *LicensesSection) Header() string {
+	return s.header
+}
+
+// Owner returns the value of {owner} in the header.
+func (s *LicensesSection) Owner() string {
+	return s.owner
+}
+
+// Exclude returns the gitignore-style patterns of files that need no header.
+func (s *LicensesSection) Exclude() []string {
+	return s.exclude
+}
+
+// Allowed returns the SPDX identifiers dependencies may use, or nil for any.
+func (s *LicensesSection) Allowed() []string {
+	return s.allowed
+}
+
+// stringList converts a string slice to the JSON representation of a list
+func stringList(items []string) []interface{} {
+	list := make([]interface{}, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	return list
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Notification payload formats
+const (
+	NotifyFormatAuto  = "auto"  // Slack for hooks.slack.com URLs, JSON otherwise
+	NotifyFormatSlack = "slack" // Slack incoming webhook message
+	NotifyFormatJSON  = "json"  // The summary as a JSON object
+)
+
+// NotificationsSection configures where task summaries are posted when the
+// agent completes a task. It is usually set per project in .forge/config.yaml,
+// with the URL in an environment variable so the webhook is not committed.
+type NotificationsSection struct {
+	webhookURL    string // Webhook URL ("" = use webhook_url_env)
+	webhookURLEnv string // Environment variable holding the webhook URL
+	format        string
+}
+
+// NewNotificationsSection creates a new notifications section with notifications disabled.
+func NewNotificationsSection() *NotificationsSection {
+	return &NotificationsSection{format: NotifyFormatAuto}
+}
+
+// ID returns the section identifier.
+func (s *NotificationsSection) ID() string {
+	return "notifications"
+}
+
+// Title returns the section title.
+func (s *NotificationsSection) Title() string {
+	return "Notifications"
+}
+
+// Description returns the section description.
+func (s *NotificationsSection) Description() string {
+	return "Post a summary (task, files changed, cost, duration) to a Slack or generic webhook (webhook_url or webhook_url_env) when a task completes."
+}
+
+// Data returns the current configuration data.
+func (s *NotificationsSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"webhook_url":     s.webhookURL,
+		"webhook_url_env": s.webhookURLEnv,
+		"format":          s.format,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *NotificationsSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	fields := map[string]*string{
+		"webhook_url":     &s.webhookURL,
+		"webhook_url_env": &s.webhookURLEnv,
+		"format":          &s.format,
+	}
+
+	for key, target := range fields {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+	if s.format == "" {
+		s.format = NotifyFormatAuto
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *NotificationsSection) Validate() error {
+	switch s.format {
+	case NotifyFormatAuto, NotifyFormatSlack, NotifyFormatJSON:
+	default:
+		return fmt.Errorf("format must be %q, %q or %q, got %q", NotifyFormatAuto, NotifyFormatSlack, NotifyFormatJSON, s.format)
+	}
+	if s.webhookURL != "" {
+		if err := validateWebhookURL(s.webhookURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (notifications disabled).
+func (s *NotificationsSection) Reset() {
+	s.webhookURL = ""
+	s.webhookURLEnv = ""
+	s.format = NotifyFormatAuto
+}
+
+// SecretKeys returns the keys left out of configuration bundles: a webhook
+// URL is a credential.
+func (s *NotificationsSection) SecretKeys() []string {
+	return []string{"webhook_url"}
+}
+
+// WebhookURL returns the webhook to post to, or "" when notifications are disabled.
+// A webhook_url_env naming an unset variable is an error.
+func (s *NotificationsSection) WebhookURL() (string, error) {
+	if s.webhookURL != "" {
+		return s.webhookURL, nil
+	}
+	if s.webhookURLEnv == "" {
+		return "", nil
+	}
+
+	webhook := strings.TrimSpace(os.Getenv(s.webhookURLEnv))
+	if webhook == "" {
+		return "", fmt.Errorf("environment variable %s for the notification webhook is not set", s.webhookURLEnv)
+	}
+	if err := validateWebhookURL(webhook); err != nil {
+		return "", fmt.Errorf("%s: %w", s.webhookURLEnv, err)
+	}
+	return webhook, nil
+}
+
+// Format returns the payload format: auto, slack or json.
+func (s *NotificationsSection) Format() string {
+	return s.format
+}
+
+// validateWebhookURL checks that webhook is an absolute http(s) URL
+func validateWebhookURL(webhook string) error {
+	parsed, err := url.Parse(webhook)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return fmt.Errorf("webhook URL must be an http(s) URL")
+	}
+	return nil
+}
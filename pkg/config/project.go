@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigPath is the per-repository configuration file, relative to the workspace root
+const ProjectConfigPath = ".forge/config.yaml"
+
+// Profile is a named set of model and commit settings a project offers, selected with -profile
+type Profile struct {
+	Model        string `yaml:"model,omitempty" json:"model,omitempty"`
+	BaseURL      string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
+	UtilityModel string `yaml:"utility_model,omitempty" json:"utility_model,omitempty"`
+
+	// Committer identity for /commit, overriding the commit_attribution section
+	CommitterName  string `yaml:"committer_name,omitempty" json:"committer_name,omitempty"`
+	CommitterEmail string `yaml:"committer_email,omitempty" json:"committer_email,omitempty"`
+}
+
+// ProjectConfig holds team settings committed to the repository in
+// .forge/config.yaml. They take precedence over the global config and are
+// overridden by command line flags and environment variables:
+//
+//	flags > environment > .forge/config.yaml > ~/.forge/config.json > defaults
+//
+// Besides the model, profiles and extra ignore patterns, any top-level key
+// naming a config section (auto_approval, command_whitelist, ...) is layered
+// over that section of the global config, key by key.
+type ProjectConfig struct {
+	// Path is the file the configuration was read from
+	Path string `yaml:"-" json:"-"`
+
+	Model    string             `yaml:"model,omitempty" json:"model,omitempty"`
+	Profile  string             `yaml:"profile,omitempty" json:"profile,omitempty"` // Profile used when -profile is not given
+	Profiles map[string]Profile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	Ignore   []string           `yaml:"ignore,omitempty" json:"ignore,omitempty"` // Patterns added to .gitignore and .forgeignore
+
+	// Sections overrides global config sections by section ID
+	Sections map[string]map[string]interface{} `yaml:",inline" json:"sections,omitempty"`
+}
+
+// LoadProjectConfig reads .forge/config.yaml from workspaceDir. It returns
+// nil without error when the workspace has no project config.
+func LoadProjectConfig(workspaceDir string) (*ProjectConfig, error) {
+	path := filepath.Join(workspaceDir, ProjectConfigPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+
+	project := &ProjectConfig{}
+	if err := yaml.Unmarshal(data, project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	project.Path = path
+
+	// Section data must look as if it came from the JSON config file
+	// (float64 numbers, []interface{} lists) for SetData to accept it
+	if len(project.Sections) > 0 {
+		encoded, err := json.Marshal(project.Sections)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		project.Sections = nil
+		if err := json.Unmarshal(encoded, &project.Sections); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	if project.Profile != "" {
+		if _, ok := project.Profiles[project.Profile]; !ok {
+			return nil, fmt.Errorf("%s: default profile %q is not defined under profiles", path, project.Profile)
+		}
+	}
+
+	return project, nil
+}
+
+// ResolveProfile returns the settings for the named profile, or for
+// the project's default profile when name is empty. Fields a profile leaves
+// empty fall back to the project-wide values.
+func (p *ProjectConfig) ResolveProfile(name string) (Profile, error) {
+	if p == nil {
+		if name != "" {
+			return Profile{}, fmt.Errorf("profile %q requested but the workspace has no %s", name, ProjectConfigPath)
+		}
+		return Profile{}, nil
+	}
+
+	if name == "" {
+		name = p.Profile
+	}
+	profile := Profile{}
+	if name != "" {
+		var ok bool
+		profile, ok = p.Profiles[name]
+		if !ok {
+			return Profile{}, fmt.Errorf("unknown profile %q; %s defines: %s", name, p.Path, p.profileNames())
+		}
+	}
+	if profile.Model == "" {
+		profile.Model = p.Model
+	}
+	return profile, nil
+}
+
+// profileNames lists the defined profiles for error messages
+func (p *ProjectConfig) profileNames() string {
+	if len(p.Profiles) == 0 {
+		return "(none)"
+	}
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := names[0]
+	for _, name := range names[1:] {
+		list += ", " + name
+	}
+	return list
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProviderSection configures how Forge connects to the LLM API: a timeout,
+// a proxy, a CA bundle and extra headers, for corporate networks and
+// gateways that expect more than the API key, and whether streamed
+// responses are asked for their token usage, for servers that reject it.
+type ProviderSection struct {
+	timeout     time.Duration     // Wait for response headers (0 = no limit)
+	proxy       string            // Proxy URL ("" = HTTPS_PROXY/HTTP_PROXY from the environment)
+	caBundle    string            // PEM file of extra trusted CAs
+	headers     map[string]string // Sent with every request
+	streamUsage bool              // Send stream_options.include_usage
+}
+
+// NewProviderSection creates a new provider section with the default connection.
+func NewProviderSection() *ProviderSection {
+	return &ProviderSection{streamUsage: true}
+}
+
+// ID returns the section identifier.
+func (s *ProviderSection) ID() string {
+	return "provider"
+}
+
+// Title returns the section title.
+func (s *ProviderSection) Title() string {
+	return "Provider Connection"
+}
+
+// Description returns the section description.
+func (s *ProviderSection) Description() string {
+	return "Connection to the LLM API: timeout (e.g. 2m), proxy URL, ca_bundle PEM file, extra headers, and stream_usage (false for servers that reject stream_options.include_usage)."
+}
+
+// Data returns the current configuration data.
+func (s *ProviderSection) Data() map[string]interface{} {
+	timeout := ""
+	if s.timeout > 0 {
+		timeout = s.timeout.String()
+	}
+	headers := make(map[string]interface{}, len(s.headers))
+	for name, value := range s.headers {
+		headers[name] = value
+	}
+	return map[string]interface{}{
+		"timeout":      timeout,
+		"proxy":        s.proxy,
+		"ca_bundle":    s.caBundle,
+		"headers":      headers,
+		"stream_usage": s.streamUsage,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *ProviderSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	fields := map[string]*string{
+		"proxy":     &s.proxy,
+		"ca_bundle": &s.caBundle,
+	}
+	for key, target := range fields {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+
+	if value, ok := data["timeout"]; ok {
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for 'timeout': expected a duration such as \"2m\", got %T", value)
+		}
+		s.timeout = 0
+		if str = strings.TrimSpace(str); str != "" {
+			timeout, err := time.ParseDuration(str)
+			if err != nil {
+				return fmt.Errorf("invalid timeout %q: %w", str, err)
+			}
+			s.timeout = timeout
+		}
+	}
+
+	if value, ok := data["stream_usage"]; ok {
+		enabled, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("invalid value type for 'stream_usage': expected boolean, got %T", value)
+		}
+		s.streamUsage = enabled
+	}
+
+	if value, ok := data["headers"]; ok {
+		entries, ok := value.(map[string]interface{})
+		if !ok && value != nil {
+			return fmt.Errorf("invalid value type for 'headers': expected a map of names to values, got %T", value)
+		}
+		s.headers = make(map[string]string, len(entries))
+		for name, entry := range entries {
+			str, ok := entry.(string)
+			if !ok {
+				return fmt.Errorf("invalid value type for header '%s': expected string, got %T", name, entry)
+			}
+			s.headers[name] = str
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *ProviderSection) Validate() error {
+	if s.timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", s.timeout)
+	}
+	if s.proxy != "" {
+		if parsed, err := url.Parse(s.proxy); err != nil || parsed.Host == "" {
+			return fmt.Errorf("proxy must be a URL such as http://proxy.example.com:8080, got %q", s.proxy)
+		}
+	}
+	for name := range s.headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration.
+func (s *ProviderSection) Reset() {
+	s.timeout = 0
+	s.proxy = ""
+	s.caBundle = ""
+	s.headers = nil
+	s.streamUsage = true
+}
+
+// SecretKeys returns the keys left out of configuration bundles: headers
+// usually carry gateway credentials.
+func (s *ProviderSection) SecretKeys() []string {
+	return []string{"headers"}
+}
+
+// Timeout returns how long a request waits for the response headers, or 0
+// for no limit.
+func (s *ProviderSection) Timeout() time.Duration {
+	return s.timeout
+}
+
+// Proxy returns the proxy URL, or "" to use the environment's.
+func (s *ProviderSection) Proxy() string {
+	return s.proxy
+}
+
+// CABundle returns the path of the PEM file of extra trusted CAs, or "".
+func (s *ProviderSection) CABundle() string {
+	return s.caBundle
+}
+
+// Headers returns the extra headers, by name.
+func (s *ProviderSection) Headers() map[string]string {
+	return s.headers
+}
+
+// StreamUsage reports whether streamed responses are asked for their token
+// usage.
+func (s *ProviderSection) StreamUsage() bool {
+	return s.streamUsage
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// What write_file and apply_diff do when a file they change no longer parses
+const (
+	SyntaxCheckOff    = "off"    // Nothing
+	SyntaxCheckReport = "report" // Keep the change and list the syntax errors in the tool result
+	SyntaxCheckRevert = "revert" // Leave the file as it was and fail the call
+)
+
+// SyntaxCheckSection configures the syntax check the file editing tools run
+// after each change, which catches a broken brace before the next build.
+type SyntaxCheckSection struct {
+	mode string
+}
+
+// NewSyntaxCheckSection creates a new syntax check section that reports errors.
+func NewSyntaxCheckSection() *SyntaxCheckSection {
+	return &SyntaxCheckSection{mode: SyntaxCheckReport}
+}
+
+// ID returns the section identifier.
+func (s *SyntaxCheckSection) ID() string {
+	return "syntax_check"
+}
+
+// Title returns the section title.
+func (s *SyntaxCheckSection) Title() string {
+	return "Syntax Check"
+}
+
+// Description returns the section description.
+func (s *SyntaxCheckSection) Description() string {
+	return "Parse files after write_file and apply_diff change them: report the syntax errors to the agent, revert the change, or off."
+}
+
+// Data returns the current configuration data.
+func (s *SyntaxCheckSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"mode": s.mode,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+func (s *SyntaxCheckSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	value, ok := data["mode"]
+	if !ok {
+		return nil
+	}
+	mode, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid value type for 'mode': expected string, got %T", value)
+	}
+	s.mode = strings.ToLower(strings.TrimSpace(mode))
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *SyntaxCheckSection) Validate() error {
+	switch s.mode {
+	case SyntaxCheckOff, SyntaxCheckReport, SyntaxCheckRevert:
+		return nil
+	}
+	return fmt.Errorf("mode must be %q, %q or %q, got %q", SyntaxCheckOff, SyntaxCheckReport, SyntaxCheckRevert, s.mode)
+}
+
+// Reset resets the section to default configuration (report).
+func (s *SyntaxCheckSection) Reset() {
+	s.mode = SyntaxCheckReport
+}
+
+// Mode returns what the file editing tools do about syntax errors.
+func (s *SyntaxCheckSection) Mode() string {
+	return s.mode
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SystemPromptSection manages how the built-in base system prompt is selected.
+// The base prompt can be pinned to a published version or replaced entirely,
+// either inline or from a file.
+//
+// To compare prompts before changing one, define variants. Each session
+// runs one, picked at random by weight unless -prompt-variant names it, and
+// records how each of its turns went to metrics_file (default
+// ~/.forge/prompt-metrics.jsonl):
+//
+//	"system_prompt": {
+//	  "variants": [
+//	    {"name": "control"},
+//	    {"name": "v2", "base_version": "2", "weight": 1}
+//	  ]
+//	}
+type SystemPromptSection struct {
+	baseVersion  string // Pinned base prompt version ("" = latest)
+	override     string // Inline replacement for the base prompt
+	overrideFile string // Path to a file containing a replacement base prompt
+
+	variants    []PromptVariant
+	metricsFile string // Where variant sessions record turn outcomes ("" = DefaultPromptMetricsFile)
+}
+
+// DefaultPromptMetricsFile is where sessions running a prompt variant record
+// their turn outcomes unless metrics_file is set, relative to the home
+// directory
+const DefaultPromptMetricsFile = ".forge/prompt-metrics.jsonl"
+
+// PromptVariant is a base prompt under comparison. A variant that sets
+// none of the prompt fields runs the section's own base prompt, as a control.
+type PromptVariant struct {
+	Name         string
+	BaseVersion  string
+	Override     string
+	OverrideFile string
+	Weight       float64 // Relative chance of being picked (0 = 1)
+}
+
+// NewSystemPromptSection creates a new system prompt section that tracks the latest base prompt.
+func NewSystemPromptSection() *SystemPromptSection {
+	return &SystemPromptSection{}
+}
+
+// ID returns the section identifier.
+func (s *SystemPromptSection) ID() string {
+	return "system_prompt"
+}
+
+// Title returns the section title.
+func (s *SystemPromptSection) Title() string {
+	return "System Prompt"
+}
+
+// Description returns the section description.
+func (s *SystemPromptSection) Description() string {
+	return "Pin the built-in base prompt to a version (base_version) or replace it entirely (override / override_file), or compare variants, recording each turn's outcome to metrics_file."
+}
+
+// Data returns the current configuration data.
+func (s *SystemPromptSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"base_version":  s.baseVersion,
+		"override":      s.override,
+		"override_file": s.overrideFile,
+		"variants":      s.variantData(),
+		"metrics_file":  s.metricsFile,
+	}
+}
+
+// variantData returns the variants as config data
+func (s *SystemPromptSection) variantData() []interface{} {
+	list := make([]interface{}, 0, len(s.variants))
+	for _, v := range s.variants {
+		entry := map[string]interface{}{"name": v.Name}
+		for key, value := range map[string]string{"base_version": v.BaseVersion, "override": v.Override, "override_file": v.OverrideFile} {
+			if value != "" {
+				entry[key] = value
+			}
+		}
+		if v.Weight > 0 {
+			entry["weight"] = v.Weight
+		}
+		list = append(list, entry)
+	}
+	return list
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *SystemPromptSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	fields := map[string]*string{
+		"base_version":  &s.baseVersion,
+		"override":      &s.override,
+		"override_file": &s.overrideFile,
+		"metrics_file":  &s.metricsFile,
+	}
+
+	for key, target := range fields {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+
+	if value, ok := data["variants"]; ok {
+		list, ok := value.([]interface{})
+		if !ok && value != nil {
+			return fmt.Errorf("invalid variants: expected list, got %T", value)
+		}
+		variants := make([]PromptVariant, 0, len(list))
+		for i, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("invalid variant %d: expected map, got %T", i, item)
+			}
+			variant, err := parsePromptVariant(entry)
+			if err != nil {
+				return fmt.Errorf("invalid variant %d: %w", i, err)
+			}
+			variants = append(variants, variant)
+		}
+		s.variants = variants
+	}
+
+	return nil
+}
+
+// parsePromptVariant reads a variant from its config data
+func parsePromptVariant(entry map[string]interface{}) (PromptVariant, error) {
+	var variant PromptVariant
+	fields := map[string]*string{
+		"name":          &variant.Name,
+		"base_version":  &variant.BaseVersion,
+		"override":      &variant.Override,
+		"override_file": &variant.OverrideFile,
+	}
+	for key, target := range fields {
+		value, ok := entry[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return variant, fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+	if value, ok := entry["weight"]; ok {
+		weight, ok := value.(float64)
+		if !ok {
+			return variant, fmt.Errorf("invalid weight: expected a number, got %T", value)
+		}
+		variant.Weight = weight
+	}
+	return variant, nil
+}
+
+// Validate validates the current configuration.
+func (s *SystemPromptSection) Validate() error {
+	if s.override != "" && s.overrideFile != "" {
+		return fmt.Errorf("only one of override and override_file may be set")
+	}
+	names := make(map[string]bool, len(s.variants))
+	for _, v := range s.variants {
+		if v.Name == "" {
+			return fmt.Errorf("every prompt variant needs a name")
+		}
+		if names[v.Name] {
+			return fmt.Errorf("prompt variant %q is defined twice", v.Name)
+		}
+		names[v.Name] = true
+		if v.Override != "" && v.OverrideFile != "" {
+			return fmt.Errorf("prompt variant %q: only one of override and override_file may be set", v.Name)
+		}
+		if v.Weight < 0 {
+			return fmt.Errorf("prompt variant %q: weight must not be negative", v.Name)
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (latest base prompt, no override).
+func (s *SystemPromptSection) Reset() {
+	s.baseVersion = ""
+	s.override = ""
+	s.overrideFile = ""
+	s.variants = nil
+	s.metricsFile = ""
+}
+
+// BaseVersion returns the pinned base prompt version, or "" to use the latest.
+func (s *SystemPromptSection) BaseVersion() string {
+	return s.baseVersion
+}
+
+// Override returns the replacement base prompt, reading override_file if configured.
+// Returns an empty string when the built-in base prompt should be used.
+func (s *SystemPromptSection) Override() (string, error) {
+	return readOverride(s.override, s.overrideFile)
+}
+
+// Variants returns the prompt variants under comparison, if any
+func (s *SystemPromptSection) Variants() []PromptVariant {
+	return append([]PromptVariant{}, s.variants...)
+}
+
+// MetricsFile returns the file variant sessions record turn outcomes to
+func (s *SystemPromptSection) MetricsFile() (string, error) {
+	if s.metricsFile != "" {
+		return s.metricsFile, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, DefaultPromptMetricsFile), nil
+}
+
+// PickVariant returns the variant called name, or one picked at random by
+// weight when name is empty. It returns nil when no variants are defined.
+func (s *SystemPromptSection) PickVariant(name string) (*PromptVariant, error) {
+	if name != "" {
+		for _, v := range s.variants {
+			if v.Name == name {
+				return &v, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown prompt variant %q", name)
+	}
+	if len(s.variants) == 0 {
+		return nil, nil
+	}
+
+	total := 0.0
+	for _, v := range s.variants {
+		total += v.weight()
+	}
+	pick := rand.Float64() * total
+	for _, v := range s.variants {
+		if pick -= v.weight(); pick < 0 {
+			return &v, nil
+		}
+	}
+	last := s.variants[len(s.variants)-1]
+	return &last, nil
+}
+
+// weight is the variant's relative chance of being picked
+func (v PromptVariant) weight() float64 {
+	if v.Weight == 0 {
+		return 1
+	}
+	return v.Weight
+}
+
+// IsControl reports whether the variant runs the section's own base prompt
+func (v PromptVariant) IsControl() bool {
+	return v.BaseVersion == "" && v.Override == "" && v.OverrideFile == ""
+}
+
+// PromptOverride returns the variant's replacement base prompt, reading
+// override_file if set, or "" if it uses a built-in base prompt
+func (v PromptVariant) PromptOverride() (string, error) {
+	return readOverride(v.Override, v.OverrideFile)
+}
+
+// readOverride returns the inline override, or the content of overrideFile
+func readOverride(override, overrideFile string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	if overrideFile == "" {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(overrideFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read base prompt override file: %w", err)
+	}
+
+	result := strings.TrimSpace(string(content))
+	if result == "" {
+		return "", fmt.Errorf("base prompt override file %s is empty", overrideFile)
+	}
+	return result, nil
+}
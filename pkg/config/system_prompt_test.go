@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPickVariant(t *testing.T) {
+	s := NewSystemPromptSection()
+	if v, err := s.PickVariant(""); v != nil || err != nil {
+		t.Fatalf("expected no variant without variants, got %v, %v", v, err)
+	}
+
+	err := s.SetData(map[string]interface{}{"variants": []interface{}{
+		map[string]interface{}{"name": "control"},
+		map[string]interface{}{"name": "v2", "base_version": "2", "weight": float64(0.0001)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.PickVariant("v2")
+	if err != nil || v.Name != "v2" || v.IsControl() {
+		t.Errorf("expected the named variant, got %+v, %v", v, err)
+	}
+	if _, err := s.PickVariant("v3"); err == nil || !strings.Contains(err.Error(), "v3") {
+		t.Errorf("expected an unknown variant to be rejected, got %v", err)
+	}
+
+	// The control has 10000 times the weight of v2
+	controls := 0
+	for range 100 {
+		if v, _ := s.PickVariant(""); v.IsControl() {
+			controls++
+		}
+	}
+	if controls < 95 {
+		t.Errorf("expected picks to follow the weights, got %d controls in 100", controls)
+	}
+
+	if err := s.SetData(map[string]interface{}{"variants": []interface{}{
+		map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "a"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(); err == nil {
+		t.Error("expected duplicate variant names to be rejected")
+	}
+}
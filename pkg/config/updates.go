@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Release channels for forge update
+const (
+	ChannelStable = "stable" // Full releases only
+	ChannelBeta   = "beta"   // Full releases and pre-releases
+)
+
+// UpdatesSection configures which releases forge update installs.
+type UpdatesSection struct {
+	channel string
+}
+
+// NewUpdatesSection creates a new updates section on the stable channel.
+func NewUpdatesSection() *UpdatesSection {
+	return &UpdatesSection{channel: ChannelStable}
+}
+
+// ID returns the section identifier.
+func (s *UpdatesSection) ID() string {
+	return "updates"
+}
+
+// Title returns the section title.
+func (s *UpdatesSection) Title() string {
+	return "Updates"
+}
+
+// Description returns the section description.
+func (s *UpdatesSection) Description() string {
+	return "Release channel for forge update: stable, or beta to include pre-releases."
+}
+
+// Data returns the current configuration data.
+func (s *UpdatesSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"channel": s.channel,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+func (s *UpdatesSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	value, ok := data["channel"]
+	if !ok {
+		return nil
+	}
+	channel, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid value type for 'channel': expected string, got %T", value)
+	}
+	s.channel = strings.ToLower(strings.TrimSpace(channel))
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *UpdatesSection) Validate() error {
+	if s.channel != ChannelStable && s.channel != ChannelBeta {
+		return fmt.Errorf("channel must be %q or %q, got %q", ChannelStable, ChannelBeta, s.channel)
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (stable channel).
+func (s *UpdatesSection) Reset() {
+	s.channel = ChannelStable
+}
+
+// Channel returns the configured release channel.
+func (s *UpdatesSection) Channel() string {
+	return s.channel
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UtilityModelSection configures a separate, typically cheaper model for
+// background work: context summarization and commit/PR message generation.
+// When no model is set, the main agent's provider is used.
+type UtilityModelSection struct {
+	model     string // Model name ("" = use the main model)
+	baseURL   string // API base URL ("" = same as the main provider)
+	apiKeyEnv string // Environment variable holding the API key ("" = same key as the main provider)
+}
+
+// NewUtilityModelSection creates a new utility model section that reuses the main model.
+func NewUtilityModelSection() *UtilityModelSection {
+	return &UtilityModelSection{}
+}
+
+// ID returns the section identifier.
+func (s *UtilityModelSection) ID() string {
+	return "utility_model"
+}
+
+// Title returns the section title.
+func (s *UtilityModelSection) Title() string {
+	return "Utility Model"
+}
+
+// Description returns the section description.
+func (s *UtilityModelSection) Description() string {
+	return "Use a cheaper model (model, optional base_url and api_key_env) for summarization and commit/PR messages."
+}
+
+// Data returns the current configuration data.
+func (s *UtilityModelSection) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"model":       s.model,
+		"base_url":    s.baseURL,
+		"api_key_env": s.apiKeyEnv,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+// Keys that are absent keep their current value.
+func (s *UtilityModelSection) SetData(data map[string]interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	fields := map[string]*string{
+		"model":       &s.model,
+		"base_url":    &s.baseURL,
+		"api_key_env": &s.apiKeyEnv,
+	}
+
+	for key, target := range fields {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value type for '%s': expected string, got %T", key, value)
+		}
+		*target = strings.TrimSpace(str)
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *UtilityModelSection) Validate() error {
+	if s.model == "" && (s.baseURL != "" || s.apiKeyEnv != "") {
+		return fmt.Errorf("model is required when base_url or api_key_env is set")
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (use the main model).
+func (s *UtilityModelSection) Reset() {
+	s.model = ""
+	s.baseURL = ""
+	s.apiKeyEnv = ""
+}
+
+// Model returns the utility model name, or "" to use the main model.
+func (s *UtilityModelSection) Model() string {
+	return s.model
+}
+
+// BaseURL returns the utility provider's base URL, or "" to use the main provider's.
+func (s *UtilityModelSection) BaseURL() string {
+	return s.baseURL
+}
+
+// APIKey returns the utility provider's API key from api_key_env, or "" to use
+// the main provider's key.
+func (s *UtilityModelSection) APIKey() (string, error) {
+	if s.apiKeyEnv == "" {
+		return "", nil
+	}
+	key := os.Getenv(s.apiKeyEnv)
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s for the utility model API key is not set", s.apiKeyEnv)
+	}
+	return key, nil
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WASMToolGrant is what a WebAssembly tool module is allowed to access
+type WASMToolGrant struct {
+	Read    bool     // Read files in the workspace
+	Write   bool     // Read and change files in the workspace
+	Network []string // Hosts it may send HTTP requests to ("*" = any)
+}
+
+// WASMToolsSection grants capabilities to WebAssembly tool modules, keyed by
+// the module's file name without .wasm:
+//
+//	"word-count": {"read": true},
+//	"docs-search": {"network": ["docs.example.com"]}
+//
+// Modules without an entry can't see files or use the network.
+type WASMToolsSection struct {
+	grants map[string]WASMToolGrant
+}
+
+// NewWASMToolsSection creates a new section that grants nothing.
+func NewWASMToolsSection() *WASMToolsSection {
+	return &WASMToolsSection{
+		grants: make(map[string]WASMToolGrant),
+	}
+}
+
+// ID returns the section identifier.
+func (s *WASMToolsSection) ID() string {
+	return "wasm_tools"
+}
+
+// Title returns the section title.
+func (s *WASMToolsSection) Title() string {
+	return "WebAssembly Tools"
+}
+
+// Description returns the section description.
+func (s *WASMToolsSection) Description() string {
+	return "Capabilities granted to WebAssembly tool modules in .forge/plugins: read, write, and network hosts."
+}
+
+// Data returns the current configuration data.
+func (s *WASMToolsSection) Data() map[string]interface{} {
+	data := make(map[string]interface{}, len(s.grants))
+	for name, grant := range s.grants {
+		entry := map[string]interface{}{}
+		if grant.Read {
+			entry["read"] = true
+		}
+		if grant.Write {
+			entry["write"] = true
+		}
+		if len(grant.Network) > 0 {
+			hosts := make([]interface{}, len(grant.Network))
+			for i, host := range grant.Network {
+				hosts[i] = host
+			}
+			entry["network"] = hosts
+		}
+		data[name] = entry
+	}
+	return data
+}
+
+// SetData updates the configuration from the provided data.
+// Modules that are absent keep their current grants.
+func (s *WASMToolsSection) SetData(data map[string]interface{}) error {
+	for name, value := range data {
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid grant for '%s': expected map, got %T", name, value)
+		}
+		grant, err := parseWASMToolGrant(entry)
+		if err != nil {
+			return fmt.Errorf("invalid grant for '%s': %w", name, err)
+		}
+		s.grants[name] = grant
+	}
+	return nil
+}
+
+// parseWASMToolGrant reads a module's grant from its config data
+func parseWASMToolGrant(entry map[string]interface{}) (WASMToolGrant, error) {
+	var grant WASMToolGrant
+	for key, value := range entry {
+		switch key {
+		case "read", "write":
+			allowed, ok := value.(bool)
+			if !ok {
+				return grant, fmt.Errorf("invalid %s: expected true or false, got %T", key, value)
+			}
+			if key == "read" {
+				grant.Read = allowed
+			} else {
+				grant.Write = allowed
+			}
+		case "network":
+			hosts, ok := value.([]interface{})
+			if !ok {
+				return grant, fmt.Errorf("invalid network: expected list of hosts, got %T", value)
+			}
+			for _, host := range hosts {
+				name, ok := host.(string)
+				if !ok {
+					return grant, fmt.Errorf("invalid network host: expected string, got %T", host)
+				}
+				grant.Network = append(grant.Network, strings.TrimSpace(name))
+			}
+		default:
+			return grant, fmt.Errorf("unknown capability '%s' (expected read, write or network)", key)
+		}
+	}
+	return grant, nil
+}
+
+// Validate validates the current configuration.
+func (s *WASMToolsSection) Validate() error {
+	for name, grant := range s.grants {
+		for _, host := range grant.Network {
+			if host == "" || strings.Contains(host, "/") {
+				return fmt.Errorf("wasm tool '%s': network entries must be host names, got %q", name, host)
+			}
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration (no grants).
+func (s *WASMToolsSection) Reset() {
+	s.grants = make(map[string]WASMToolGrant)
+}
+
+// Grants returns the capabilities granted to each module, by module name.
+func (s *WASMToolsSection) Grants() map[string]WASMToolGrant {
+	grants := make(map[string]WASMToolGrant, len(s.grants))
+	for name, grant := range s.grants {
+		grants[name] = grant
+	}
+	return grants
+}
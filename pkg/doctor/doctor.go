@@ -0,0 +1,475 @@
+// Package doctor diagnoses common Forge misconfigurations: a missing or
+// rejected API key, an unreachable or wrong endpoint, an unavailable model,
+// a missing git binary, an unwritable workspace, a limited terminal and an
+// invalid config file. Each check reports an actionable fix.
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	// StatusOK means the check passed
+	StatusOK Status = iota
+	// StatusWarn means Forge works, possibly with reduced functionality
+	StatusWarn
+	// StatusFail means Forge will not work until this is fixed
+	StatusFail
+)
+
+// String returns the label printed for a status
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "OK"
+	case StatusWarn:
+		return "WARN"
+	default:
+		return "FAIL"
+	}
+}
+
+// Result is the outcome of one diagnostic check
+type Result struct {
+	Name    string
+	Status  Status
+	Message string
+	Fix     string // What to do about a warning or failure
+}
+
+// Options describes the configuration to diagnose
+type Options struct {
+	APIKey       string
+	BaseURL      string // Defaults to DefaultBaseURL
+	Model        string
+	Headers      map[string]string // Sent with each API request besides the key
+	WorkspaceDir string
+
+	// HTTPClient is used for the API checks. Defaults to a client with a
+	// 15 second timeout.
+	HTTPClient *http.Client
+}
+
+// DefaultBaseURL is the API endpoint checked when Options.BaseURL is empty
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// Run performs all checks and returns their results in a fixed order
+func Run(ctx context.Context, opts Options) []Result {
+	opts = opts.withDefaults()
+	results := checkAPI(ctx, opts)
+	results = append(results,
+		checkGit(ctx, opts.WorkspaceDir),
+		checkWorkspace(opts.WorkspaceDir),
+		checkTerminal(),
+		checkConfig(),
+	)
+	return results
+}
+
+// CheckProvider performs only the checks of the API key, base URL and model,
+// for validating the configuration at startup
+func CheckProvider(ctx context.Context, opts Options) []Result {
+	return checkAPI(ctx, opts.withDefaults())
+}
+
+// withDefaults fills in the defaults of unset options
+func (o Options) withDefaults() Options {
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultBaseURL
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return o
+}
+
+// Failed reports whether any result is a failure
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Format renders results as plain text, one check per line with its fix
+// indented below
+func Format(results []Result) string {
+	var b strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&b, "[%-4s] %-12s %s\n", r.Status, r.Name, r.Message)
+		if r.Fix != "" && r.Status != StatusOK {
+			fmt.Fprintf(&b, "       %-12s → %s\n", "", r.Fix)
+		}
+	}
+	return b.String()
+}
+
+// checkAPI checks network reachability, the API key and the model with a
+// request to the models endpoint, or a one-token test completion where the
+// endpoint doesn't list models
+func checkAPI(ctx context.Context, opts Options) []Result {
+	network := Result{Name: "Network"}
+	apiKey := Result{Name: "API key"}
+	model := Result{Name: "Model"}
+
+	if opts.APIKey == "" {
+		apiKey.Status = StatusFail
+		apiKey.Message = "No API key configured"
+		apiKey.Fix = "Set OPENAI_API_KEY or pass -api-key"
+	}
+
+	endpoint := strings.TrimSuffix(opts.BaseURL, "/") + "/models"
+	host := opts.BaseURL
+	if u, err := url.Parse(opts.BaseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		network.Status = StatusFail
+		network.Message = fmt.Sprintf("Invalid base URL %q: %v", opts.BaseURL, err)
+		network.Fix = "Check OPENAI_BASE_URL or -base-url"
+		return skipRemaining(network, apiKey, model)
+	}
+	if opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.APIKey)
+	}
+	setHeaders(req, opts.Headers)
+
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		network.Status = StatusFail
+		network.Message = fmt.Sprintf("Cannot reach %s: %v", host, unwrapURLError(err))
+		network.Fix = "Check your connection, proxy settings (HTTPS_PROXY) and -base-url"
+		return skipRemaining(network, apiKey, model)
+	}
+	defer resp.Body.Close()
+	network.Message = fmt.Sprintf("Reached %s", host)
+
+	if opts.APIKey == "" {
+		return skipRemaining(network, apiKey, model)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		apiKey.Status = StatusFail
+		apiKey.Message = fmt.Sprintf("Rejected by %s (HTTP %d)", host, resp.StatusCode)
+		apiKey.Fix = "Check the key is current and belongs to this provider; -base-url selects the provider"
+		return skipRemaining(network, apiKey, model)
+	case resp.StatusCode != http.StatusOK:
+		// Some gateways don't list models; a test completion checks the key and model instead
+		return checkCompletion(ctx, opts, host, resp.StatusCode, network, apiKey, model)
+	}
+	apiKey.Message = "Accepted"
+
+	models, listed := decodeModels(resp.Body)
+	if !listed {
+		model.Status, model.Message, model.Fix = checkModelAnswers(ctx, opts, host)
+		return []Result{network, apiKey, model}
+	}
+	model.Status, model.Message, model.Fix = checkModelListed(models, opts.Model, host)
+	return []Result{network, apiKey, model}
+}
+
+// decodeModels reads the model IDs of a models endpoint response, and false
+// if it holds no model list
+func decodeModels(body io.Reader) ([]string, bool) {
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, 16<<20)).Decode(&list); err != nil || len(list.Data) == 0 {
+		return nil, false
+	}
+	ids := make([]string, len(list.Data))
+	for i, m := range list.Data {
+		ids[i] = m.ID
+	}
+	return ids, true
+}
+
+// checkModelListed looks for model in a models endpoint's list
+func checkModelListed(models []string, model, host string) (Status, string, string) {
+	for _, id := range models {
+		if id == model {
+			return StatusOK, fmt.Sprintf("%s is available", model), ""
+		}
+	}
+	return StatusFail, fmt.Sprintf("%s is not offered by %s (%d models listed)", model, host, len(models)),
+		"Pass -model with a model ID from the provider's model list"
+}
+
+// checkCompletion checks the key and model with a test completion, for
+// endpoints whose models endpoint returned modelsStatus instead of a list.
+// Both endpoints returning 404 means the base URL doesn't point at an
+// OpenAI-compatible API.
+func checkCompletion(ctx context.Context, opts Options, host string, modelsStatus int, network, apiKey, model Result) []Result {
+	status, detail, err := probeCompletion(ctx, opts)
+	switch {
+	case err != nil:
+		apiKey.Status = StatusWarn
+		apiKey.Message = fmt.Sprintf("Could not verify: models endpoint returned HTTP %d and a test completion failed: %v", modelsStatus, unwrapURLError(err))
+		apiKey.Fix = "Send a message to confirm the key works"
+		return skipRemaining(network, apiKey, model)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		apiKey.Status = StatusFail
+		apiKey.Message = fmt.Sprintf("Rejected by %s (HTTP %d)", host, status)
+		apiKey.Fix = "Check the key is current and belongs to this provider; -base-url selects the provider"
+		return skipRemaining(network, apiKey, model)
+	case status == http.StatusNotFound && modelsStatus == http.StatusNotFound && !mentionsModel(detail):
+		network.Status = StatusFail
+		network.Message = fmt.Sprintf("No OpenAI-compatible API at %s: its models and chat completions endpoints return HTTP 404", opts.BaseURL)
+		network.Fix = "Check -base-url or OPENAI_BASE_URL; OpenAI-compatible base URLs usually end in /v1"
+		return skipRemaining(network, apiKey, model)
+	}
+
+	apiKey.Message = "Accepted by a test completion"
+	model.Status, model.Message, model.Fix = completionModelResult(status, detail, opts.Model, host)
+	if model.Status == StatusWarn {
+		apiKey.Status = StatusWarn
+		apiKey.Message = fmt.Sprintf("Could not verify: models endpoint returned HTTP %d, a test completion HTTP %d", modelsStatus, status)
+		apiKey.Fix = "Send a message to confirm the key works"
+	}
+	return []Result{network, apiKey, model}
+}
+
+// checkModelAnswers checks the model with a test completion, for endpoints
+// that accept the key but don't list models
+func checkModelAnswers(ctx context.Context, opts Options, host string) (Status, string, string) {
+	status, detail, err := probeCompletion(ctx, opts)
+	if err != nil {
+		return StatusWarn, fmt.Sprintf("Could not verify %s: %s returned no model list and a test completion failed: %v", opts.Model, host, unwrapURLError(err)),
+			"Send a message to confirm the model is available"
+	}
+	return completionModelResult(status, detail, opts.Model, host)
+}
+
+// completionModelResult interprets a test completion's status for the model check
+func completionModelResult(status int, detail, model, host string) (Status, string, string) {
+	switch {
+	case status == http.StatusOK:
+		return StatusOK, fmt.Sprintf("%s answered a test completion", model), ""
+	case (status == http.StatusNotFound || status == http.StatusBadRequest) && mentionsModel(detail):
+		return StatusFail, fmt.Sprintf("%s was rejected by %s: %s", model, host, detail),
+			"Pass -model with a model ID the provider offers"
+	default:
+		message := fmt.Sprintf("Could not verify %s: a test completion returned HTTP %d", model, status)
+		if detail != "" {
+			message += ": " + detail
+		}
+		return StatusWarn, message, "Send a message to confirm the model is available"
+	}
+}
+
+// probeCompletion sends a one-token chat completion and returns its HTTP
+// status with the provider's error message, if any
+func probeCompletion(ctx context.Context, opts Options) (int, string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      opts.Model,
+		"messages":   []map[string]string{{"role": "user", "content": "ping"}},
+		"max_tokens": 1,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	endpoint := strings.TrimSuffix(opts.BaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+opts.APIKey)
+	setHeaders(req, opts.Headers)
+
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&reply)
+	return resp.StatusCode, reply.Error.Message, nil
+}
+
+// mentionsModel reports whether a provider error message is about the model
+func mentionsModel(detail string) bool {
+	return strings.Contains(strings.ToLower(detail), "model")
+}
+
+// skipRemaining marks checks that could not run because an earlier one failed
+func skipRemaining(results ...Result) []Result {
+	for i := range results {
+		if results[i].Message == "" {
+			results[i].Status = StatusWarn
+			results[i].Message = "Skipped: an earlier check failed"
+		}
+	}
+	return results
+}
+
+// unwrapURLError drops the repeated method and URL from HTTP client errors
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// checkGit checks that git is installed and the workspace is a repository
+func checkGit(ctx context.Context, workspaceDir string) Result {
+	result := Result{Name: "Git"}
+
+	path, err := exec.LookPath("git")
+	if err != nil {
+		result.Status = StatusWarn
+		result.Message = "git not found on PATH; /commit, /pr and change tracking are unavailable"
+		result.Fix = "Install git"
+		return result
+	}
+
+	cmd := exec.CommandContext(ctx, path, "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = workspaceDir
+	if out, err := cmd.Output(); err != nil || strings.TrimSpace(string(out)) != "true" {
+		result.Status = StatusWarn
+		result.Message = "Workspace is not a git repository; /commit, /pr and /changes are unavailable"
+		result.Fix = "Run git init in the workspace, or start Forge inside a repository"
+		return result
+	}
+
+	result.Message = "Installed; workspace is a repository"
+	return result
+}
+
+// checkWorkspace checks that the workspace exists and is writable
+func checkWorkspace(workspaceDir string) Result {
+	result := Result{Name: "Workspace"}
+
+	info, err := os.Stat(workspaceDir)
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("Cannot access %s: %v", workspaceDir, err)
+		result.Fix = "Pass an existing directory with -workspace"
+		return result
+	}
+	if !info.IsDir() {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("%s is not a directory", workspaceDir)
+		result.Fix = "Pass a directory with -workspace"
+		return result
+	}
+
+	probe, err := os.CreateTemp(workspaceDir, ".forge-doctor-*")
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("%s is not writable: %v", workspaceDir, err)
+		result.Fix = "Fix the directory permissions, or pick a workspace you own"
+		return result
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	result.Message = fmt.Sprintf("%s is readable and writable", workspaceDir)
+	return result
+}
+
+// checkTerminal checks that output goes to a terminal able to run the TUI
+func checkTerminal() Result {
+	result := Result{Name: "Terminal"}
+
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		result.Status = StatusWarn
+		result.Message = "Output is not a terminal"
+		result.Fix = "Run forge in an interactive terminal, or use -accessible for plain-text output"
+		return result
+	}
+
+	term := os.Getenv("TERM")
+	if term == "" || term == "dumb" {
+		result.Status = StatusWarn
+		result.Message = fmt.Sprintf("TERM=%q has no cursor or color support", term)
+		result.Fix = "Set TERM (e.g. xterm-256color), or use -accessible for plain-text output"
+		return result
+	}
+
+	colors := "256 colors"
+	switch {
+	case os.Getenv("COLORTERM") == "truecolor" || os.Getenv("COLORTERM") == "24bit":
+		colors = "true color"
+	case !strings.Contains(term, "256color"):
+		colors = "basic colors"
+	}
+	result.Message = fmt.Sprintf("TERM=%s (%s)", term, colors)
+	return result
+}
+
+// checkConfig checks that the config file parses and every section is valid
+func checkConfig() Result {
+	result := Result{Name: "Config"}
+
+	store, err := config.NewFileStore("")
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = err.Error()
+		result.Fix = "Fix the JSON in ~/.forge/config.json, or move it aside to start from defaults"
+		return result
+	}
+
+	if config.IsInitialized() {
+		for _, section := range config.Global().GetSections() {
+			if err := section.Validate(); err != nil {
+				result.Status = StatusFail
+				result.Message = fmt.Sprintf("Section %q is invalid: %v", section.ID(), err)
+				result.Fix = fmt.Sprintf("Correct %q in %s or reset it from /settings", section.ID(), store.Path())
+				return result
+			}
+		}
+		if utility := config.GetUtilityModel(); utility != nil {
+			if _, err := utility.APIKey(); err != nil {
+				result.Status = StatusFail
+				result.Message = err.Error()
+				result.Fix = "Export the variable named by utility_model.api_key_env, or clear it"
+				return result
+			}
+		}
+	}
+
+	if _, err := os.Stat(store.Path()); os.IsNotExist(err) {
+		result.Message = fmt.Sprintf("No config file; using defaults (%s)", store.Path())
+		return result
+	}
+	result.Message = fmt.Sprintf("%s is valid", store.Path())
+	return result
+}
+
+// setHeaders sets headers on req
+func setHeaders(req *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,215 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newModelsServer serves a models list, rejecting requests without the key
+func newModelsServer(t *testing.T, key string, models ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+key {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var ids []string
+		for _, m := range models {
+			ids = append(ids, `{"id":"`+m+`"}`)
+		}
+		w.Write([]byte(`{"data":[` + strings.Join(ids, ",") + `]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func resultsByName(results []Result) map[string]Result {
+	byName := make(map[string]Result)
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	return byName
+}
+
+func TestCheckAPI(t *testing.T) {
+	server := newModelsServer(t, "good-key", "model-a", "model-b")
+
+	tests := []struct {
+		name                       string
+		apiKey, model              string
+		network, keyStatus, listed Status
+	}{
+		{"valid", "good-key", "model-b", StatusOK, StatusOK, StatusOK},
+		{"unknown model", "good-key", "model-c", StatusOK, StatusOK, StatusFail},
+		{"rejected key", "bad-key", "model-a", StatusOK, StatusFail, StatusWarn},
+		{"missing key", "", "model-a", StatusOK, StatusFail, StatusWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := resultsByName(checkAPI(context.Background(), Options{
+				APIKey:     tt.apiKey,
+				BaseURL:    server.URL,
+				Model:      tt.model,
+				HTTPClient: server.Client(),
+			}))
+			if got := results["Network"].Status; got != tt.network {
+				t.Errorf("Network = %v, want %v", got, tt.network)
+			}
+			if got := results["API key"].Status; got != tt.keyStatus {
+				t.Errorf("API key = %v (%s), want %v", got, results["API key"].Message, tt.keyStatus)
+			}
+			if got := results["Model"].Status; got != tt.listed {
+				t.Errorf("Model = %v (%s), want %v", got, results["Model"].Message, tt.listed)
+			}
+		})
+	}
+}
+
+func TestCheckAPIUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	results := resultsByName(checkAPI(context.Background(), Options{
+		APIKey:     "key",
+		BaseURL:    baseURL,
+		Model:      "model-a",
+		HTTPClient: http.DefaultClient,
+	}))
+	if results["Network"].Status != StatusFail || results["Network"].Fix == "" {
+		t.Errorf("expected network failure with a fix, got %+v", results["Network"])
+	}
+	if !strings.HasPrefix(results["Model"].Message, "Skipped") {
+		t.Errorf("expected model check to be skipped, got %+v", results["Model"])
+	}
+}
+
+// newGatewayServer serves chat completions for the given models without
+// listing them, like gateways that don't implement the models endpoint
+func newGatewayServer(t *testing.T, key string, models ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+key {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Model     string `json:"model"`
+			MaxTokens int    `json:"max_tokens"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MaxTokens != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, m := range models {
+			if m == req.Model {
+				w.Write([]byte(`{"choices":[{"message":{"content":"p"}}]}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"message":"The model ` + req.Model + ` does not exist"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckProvider_TestCompletion(t *testing.T) {
+	server := newGatewayServer(t, "good-key", "model-a")
+
+	tests := []struct {
+		name                       string
+		apiKey, model, path        string
+		network, keyStatus, listed Status
+		message                    string
+	}{
+		{"valid", "good-key", "model-a", "/v1", StatusOK, StatusOK, StatusOK, "model-a answered a test completion"},
+		{"unknown model", "good-key", "model-c", "/v1", StatusOK, StatusOK, StatusFail, "The model model-c does not exist"},
+		{"rejected key", "bad-key", "model-a", "/v1", StatusOK, StatusFail, StatusWarn, "Skipped"},
+		{"wrong base URL", "good-key", "model-a", "", StatusFail, StatusWarn, StatusWarn, "Skipped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := resultsByName(CheckProvider(context.Background(), Options{
+				APIKey:     tt.apiKey,
+				BaseURL:    server.URL + tt.path,
+				Model:      tt.model,
+				HTTPClient: server.Client(),
+			}))
+			if len(results) != 3 {
+				t.Fatalf("expected only the provider checks, got %d results", len(results))
+			}
+			if got := results["Network"].Status; got != tt.network {
+				t.Errorf("Network = %v (%s), want %v", got, results["Network"].Message, tt.network)
+			}
+			if got := results["API key"].Status; got != tt.keyStatus {
+				t.Errorf("API key = %v (%s), want %v", got, results["API key"].Message, tt.keyStatus)
+			}
+			if got := results["Model"]; got.Status != tt.listed || !strings.Contains(got.Message, tt.message) {
+				t.Errorf("Model = %v (%s), want %v containing %q", got.Status, got.Message, tt.listed, tt.message)
+			}
+		})
+	}
+}
+
+func TestCheckWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	if r := checkWorkspace(dir); r.Status != StatusOK {
+		t.Errorf("expected writable temp dir to pass, got %+v", r)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected probe file to be removed, found %d entries", len(entries))
+	}
+
+	if r := checkWorkspace(filepath.Join(dir, "missing")); r.Status != StatusFail {
+		t.Errorf("expected missing workspace to fail, got %+v", r)
+	}
+}
+
+func TestCheckConfigInvalidJSON(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".forge"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".forge", "config.json"), []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := checkConfig(); r.Status != StatusFail {
+		t.Errorf("expected invalid config to fail, got %+v", r)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	out := Format([]Result{
+		{Name: "Git", Status: StatusOK, Message: "Installed", Fix: "ignored"},
+		{Name: "API key", Status: StatusFail, Message: "No API key configured", Fix: "Set OPENAI_API_KEY"},
+	})
+	if !strings.Contains(out, "[OK  ] Git") || !strings.Contains(out, "[FAIL] API key") {
+		t.Errorf("unexpected status lines:\n%s", out)
+	}
+	if strings.Contains(out, "ignored") || !strings.Contains(out, "→ Set OPENAI_API_KEY") {
+		t.Errorf("expected fixes only for failed checks:\n%s", out)
+	}
+	if !Failed([]Result{{Status: StatusWarn}, {Status: StatusFail}}) || Failed([]Result{{Status: StatusWarn}}) {
+		t.Error("Failed should report only failures")
+	}
+}
@@ -0,0 +1,209 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// writeFile creates path under dir with content
+func writeFile(t *testing.T, dir, path, content string) string {
+	t.Helper()
+	full := filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return full
+}
+
+const completion = `<tool><server_name>local</server_name><tool_name>task_completion</tool_name><arguments><result>Renamed the setting.</result></arguments></tool>`
+
+const scenarioYAML = `task: Rename the retries setting to attempts
+fixture: fixture
+timeout: 30s
+assertions:
+  - type: file_contains
+    path: config.txt
+    text: attempts=3
+  - type: file_not_contains
+    path: config.txt
+    text: retries
+  - type: command
+    command: grep -q attempts config.txt
+  - type: tool_called
+    tool: apply_diff
+  - type: result_contains
+    text: Renamed
+responses:
+  - <tool><server_name>local</server_name><tool_name>apply_diff</tool_name><arguments><path>config.txt</path><edits><edit><search>retries=3</search><replace>attempts=3</replace></edit></edits></arguments></tool>
+  - '` + completion + `'
+`
+
+func TestLoadScenario(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "fixture/config.txt", "retries=3\n")
+	path := writeFile(t, dir, "rename.yaml", scenarioYAML)
+
+	s, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+	if s.Name != "rename" {
+		t.Errorf("Name = %q, want the file name", s.Name)
+	}
+	if s.Timeout != 30*time.Second || len(s.Assertions) != 5 || len(s.Responses) != 2 || !s.Scripted() {
+		t.Errorf("unexpected scenario: %+v", s)
+	}
+	if s.fixtureDir() != filepath.Join(dir, "fixture") {
+		t.Errorf("fixtureDir() = %q, want it relative to the scenario file", s.fixtureDir())
+	}
+}
+
+func TestLoadScenario_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no task", "assertions: [{type: completed}]", "task is required"},
+		{"no assertions", "task: x", "at least one assertion"},
+		{"unknown assertion", "task: x\nassertions: [{type: compiles}]", `unknown assertion type "compiles"`},
+		{"missing field", "task: x\nassertions: [{type: file_contains, path: a.go}]", "requires text"},
+		{"missing fixture", "task: x\nfixture: nowhere\nassertions: [{type: completed}]", "fixture"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "s.yaml", tt.content)
+			_, err := LoadScenario(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadScenario() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadScenarios_DuplicateNames(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "name: same\ntask: x\nassertions: [{type: completed}]")
+	writeFile(t, dir, "b.yml", "name: same\ntask: y\nassertions: [{type: completed}]")
+	writeFile(t, dir, "notes.md", "not a scenario")
+
+	if _, err := LoadScenarios(dir); err == nil || !strings.Contains(err.Error(), `"same" is defined in both`) {
+		t.Errorf("LoadScenarios() error = %v, want duplicate name error", err)
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "fixture/config.txt", "retries=3\n")
+	s, err := LoadScenario(writeFile(t, dir, "rename.yaml", scenarioYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewRunner().Run(context.Background(), s)
+	if !result.Passed || result.Score != 1 {
+		t.Fatalf("expected the scenario to pass, got %+v", result)
+	}
+	if !result.Completed || result.Completion != "Renamed the setting." {
+		t.Errorf("completion = %v %q", result.Completed, result.Completion)
+	}
+	if strings.Join(result.ToolCalls, ",") != "apply_diff,task_completion" || result.Iterations != 2 {
+		t.Errorf("tool calls = %v, iterations = %d", result.ToolCalls, result.Iterations)
+	}
+
+	// The fixture itself is never changed
+	data, _ := os.ReadFile(filepath.Join(dir, "fixture", "config.txt"))
+	if string(data) != "retries=3\n" {
+		t.Errorf("fixture was modified: %q", data)
+	}
+}
+
+func TestRunner_RunScoresFailures(t *testing.T) {
+	s := &Scenario{
+		Name: "partial",
+		Task: "Create notes.txt",
+		Assertions: []Assertion{
+			{Type: AssertFileExists, Path: "notes.txt"},
+			{Type: AssertCommand, Command: "echo broken >&2; exit 3"},
+			{Type: AssertFileMissing, Path: "other.txt"},
+			{Type: AssertCompleted},
+		},
+		Responses: []string{
+			`<tool><server_name>local</server_name><tool_name>write_file</tool_name><arguments><path>notes.txt</path><content>hi</content></arguments></tool>`,
+		},
+	}
+
+	result := NewRunner(WithKeepWorkspaces(true)).Run(context.Background(), s)
+	defer os.RemoveAll(result.Workspace)
+
+	if result.Passed || result.Score != 0.5 {
+		t.Fatalf("expected half the assertions to hold, got score %v: %+v", result.Score, result.Checks)
+	}
+	if detail := result.Checks[1].Detail; !strings.Contains(detail, "broken") {
+		t.Errorf("expected the command's output in the failure, got %q", detail)
+	}
+	// The script ran out before task_completion
+	if len(result.Errors) == 0 || !strings.Contains(strings.Join(result.Errors, "\n"), ErrScriptExhausted.Error()) {
+		t.Errorf("expected the exhausted script to be reported, got %v", result.Errors)
+	}
+	if _, err := os.Stat(filepath.Join(result.Workspace, "notes.txt")); err != nil {
+		t.Errorf("expected the workspace to be kept: %v", err)
+	}
+
+	report := &Report{Results: []*Result{result}}
+	if report.Passed() || !strings.Contains(report.Markdown(), "| partial | FAIL | 50% |") {
+		t.Errorf("unexpected report:\n%s", report.Markdown())
+	}
+}
+
+// TestRepositoryScenarios replays the scripted scenarios in evals/, so changes
+// to parsing and tools are checked on every test run
+func TestRepositoryScenarios(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the scenarios run go test in their workspaces")
+	}
+	scenarios, err := LoadScenarios(filepath.Join("..", "..", "evals"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewRunner()
+	for _, s := range scenarios {
+		if !s.Scripted() {
+			continue
+		}
+		t.Run(s.Name, func(t *testing.T) {
+			result := runner.Run(context.Background(), s)
+			if !result.Passed {
+				t.Errorf("scenario failed:\n%s", (&Report{Results: []*Result{result}}).Markdown())
+			}
+		})
+	}
+}
+
+func TestResult_RecordsCompletionDetails(t *testing.T) {
+	completion, err := tools.NewTaskCompletionTool().ExecuteResult(context.Background(),
+		[]byte("<arguments><summary>Renamed</summary><result>Renamed the setting.</result><follow_ups><follow_up>Update the docs</follow_up></follow_ups></arguments>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result Result
+	result.record(types.NewToolResultEvent("task_completion", completion))
+	if !result.Completed || !strings.Contains(result.Completion, "Renamed the setting.") {
+		t.Errorf("completion = %v %q", result.Completed, result.Completion)
+	}
+	if result.Details == nil || result.Details.Summary != "Renamed" || result.Details.FollowUps[0] != "Update the docs" {
+		t.Errorf("expected the structured fields to be kept, got %+v", result.Details)
+	}
+}
@@ -0,0 +1,75 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// ErrScriptExhausted is returned when the agent asks the scripted provider
+// for more replies than the scenario has
+var ErrScriptExhausted = errors.New("scripted provider has no more responses")
+
+// ScriptedProvider is an LLM provider that replies with fixed responses in
+// order, so a scenario exercises parsing, tools and the agent loop without
+// a model
+type ScriptedProvider struct {
+	mu        sync.Mutex
+	responses []string
+	calls     int
+}
+
+// NewScriptedProvider creates a provider that returns responses in order
+func NewScriptedProvider(responses ...string) *ScriptedProvider {
+	return &ScriptedProvider{responses: responses}
+}
+
+// next returns the next response
+func (p *ScriptedProvider) next() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.calls >= len(p.responses) {
+		return "", ErrScriptExhausted
+	}
+	response := p.responses[p.calls]
+	p.calls++
+	return response, nil
+}
+
+// Calls returns the number of completions requested so far
+func (p *ScriptedProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// StreamCompletion streams the next response as a single chunk
+func (p *ScriptedProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	response, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	stream := make(chan *llm.StreamChunk, 3)
+	stream <- &llm.StreamChunk{Role: string(types.RoleAssistant)}
+	stream <- &llm.StreamChunk{Content: response}
+	stream <- &llm.StreamChunk{Finished: true}
+	close(stream)
+	return stream, nil
+}
+
+// Complete returns the next response
+func (p *ScriptedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	response, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	return types.NewAssistantMessage(response), nil
+}
+
+// GetModelInfo describes the scripted model
+func (p *ScriptedProvider) GetModelInfo() *types.ModelInfo {
+	return &types.ModelInfo{Name: "scripted"}
+}
@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Report holds the results of a run over several scenarios
+type Report struct {
+	Results []*Result `json:"results"`
+}
+
+// Passed reports whether every scenario passed
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Score is the mean score of the scenarios
+func (r *Report) Score() float64 {
+	if len(r.Results) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, result := range r.Results {
+		total += result.Score
+	}
+	return total / float64(len(r.Results))
+}
+
+// Markdown renders a summary table followed by the failed checks of each
+// scenario
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	passed := 0
+	for _, result := range r.Results {
+		if result.Passed {
+			passed++
+		}
+	}
+	fmt.Fprintf(&b, "# Eval Results\n\n")
+	fmt.Fprintf(&b, "%d of %d scenarios passed, score %.0f%%\n\n", passed, len(r.Results), r.Score()*100)
+
+	b.WriteString("| Scenario | Result | Score | Iterations | Tokens | Time |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "| %s | %s | %.0f%% | %d | %d | %s |\n",
+			result.Scenario, result.status(), result.Score*100, result.Iterations, result.Tokens, result.Duration.Round(time.Millisecond))
+	}
+
+	for _, result := range r.Results {
+		if result.Passed {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", result.Scenario)
+		if result.Error != "" {
+			fmt.Fprintf(&b, "- Run failed: %s\n", result.Error)
+		}
+		for _, check := range result.Checks {
+			if !check.Passed {
+				fmt.Fprintf(&b, "- `%s`: %s\n", check.Assertion, check.Detail)
+			}
+		}
+		for _, err := range result.Errors {
+			fmt.Fprintf(&b, "- Agent error: %s\n", err)
+		}
+		if len(result.ToolCalls) > 0 {
+			fmt.Fprintf(&b, "- Tools called: %s\n", strings.Join(result.ToolCalls, ", "))
+		}
+		if result.Workspace != "" {
+			fmt.Fprintf(&b, "- Workspace: %s\n", result.Workspace)
+		}
+	}
+	return b.String()
+}
+
+// JSON renders the report as indented JSON
+func (r *Report) JSON() (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode eval report: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// maxDetail bounds the command output kept in a failed check
+const maxDetail = 2000
+
+// Result is the outcome of one scenario
+type Result struct {
+	Scenario   string            `json:"scenario"`
+	Passed     bool              `json:"passed"` // Every assertion held and the run had no error
+	Score      float64           `json:"score"`  // Fraction of assertions that held
+	Checks     []Check           `json:"checks"`
+	ToolCalls  []string          `json:"tool_calls"`                   // Tools called, in order
+	Completed  bool              `json:"completed"`                    // The agent called task_completion
+	Completion string            `json:"completion,omitempty"`         // The task_completion result
+	Details    *tools.Completion `json:"completion_details,omitempty"` // Its structured fields, if the agent filled any in
+	Errors     []string          `json:"errors,omitempty"`             // Errors the agent reported during the turn
+	Iterations int               `json:"iterations"`                   // Model calls made
+	Tokens     int               `json:"tokens"`                       // Total tokens used, as reported or estimated
+	Duration   time.Duration     `json:"duration"`
+	Workspace  string            `json:"workspace,omitempty"` // Kept workspace, with WithKeepWorkspaces
+	Error      string            `json:"error,omitempty"`     // Why the run itself failed
+}
+
+// Check is the outcome of one assertion
+type Check struct {
+	Assertion string `json:"assertion"`
+	Passed    bool   `json:"passed"`
+	Detail    string `json:"detail,omitempty"` // Why it failed
+}
+
+// record updates the result from an event of the agent's turn
+func (r *Result) record(event *types.AgentEvent) {
+	switch event.Type {
+	case types.EventTypeToolCall:
+		r.ToolCalls = append(r.ToolCalls, event.ToolName)
+	case types.EventTypeToolResult:
+		if event.ToolName == "task_completion" {
+			r.Completed = true
+			r.Completion = fmt.Sprint(event.ToolOutput)
+			if result, ok := event.ToolOutput.(*tools.Result); ok {
+				if completion, ok := tools.CompletionFromResult(result); ok && completion.Structured() {
+					r.Details = completion
+				}
+			}
+		}
+	case types.EventTypeError:
+		if event.Error != nil {
+			r.Errors = append(r.Errors, event.Error.Error())
+		}
+	case types.EventTypeBudgetExceeded:
+		r.Errors = append(r.Errors, fmt.Sprintf("turn budget exceeded: %v", event.Metadata["limit"]))
+	case types.EventTypeApiCallStart:
+		r.Iterations++
+	case types.EventTypeTokenUsage:
+		if event.TokenUsage != nil {
+			r.Tokens += event.TokenUsage.TotalTokens
+		}
+	}
+}
+
+// score sets Score and Passed from the checks
+func (r *Result) score() {
+	passed := 0
+	for _, c := range r.Checks {
+		if c.Passed {
+			passed++
+		}
+	}
+	if len(r.Checks) > 0 {
+		r.Score = float64(passed) / float64(len(r.Checks))
+	}
+	r.Passed = r.Error == "" && passed == len(r.Checks)
+}
+
+// status is PASS or FAIL
+func (r *Result) status() string {
+	if r.Passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// check evaluates each assertion against the workspace and the turn
+func (r *Runner) check(ctx context.Context, dir string, assertions []Assertion, result *Result) []Check {
+	checks := make([]Check, len(assertions))
+	for i, a := range assertions {
+		detail := r.evaluate(ctx, dir, a, result)
+		checks[i] = Check{Assertion: a.String(), Passed: detail == "", Detail: detail}
+	}
+	return checks
+}
+
+// evaluate returns why the assertion failed, or "" if it holds
+func (r *Runner) evaluate(ctx context.Context, dir string, a Assertion, result *Result) string {
+	switch a.Type {
+	case AssertFileExists:
+		if _, err := os.Stat(filepath.Join(dir, a.Path)); err != nil {
+			return fmt.Sprintf("%s does not exist", a.Path)
+		}
+	case AssertFileMissing:
+		if _, err := os.Stat(filepath.Join(dir, a.Path)); err == nil {
+			return fmt.Sprintf("%s exists", a.Path)
+		}
+	case AssertFileContains, AssertFileNotContains:
+		data, err := os.ReadFile(filepath.Join(dir, a.Path))
+		if err != nil {
+			return fmt.Sprintf("failed to read %s: %v", a.Path, err)
+		}
+		contains := strings.Contains(string(data), a.Text)
+		if a.Type == AssertFileContains && !contains {
+			return fmt.Sprintf("%s does not contain %q", a.Path, a.Text)
+		}
+		if a.Type == AssertFileNotContains && contains {
+			return fmt.Sprintf("%s contains %q", a.Path, a.Text)
+		}
+	case AssertCommand:
+		return r.runCommand(ctx, dir, a.Command)
+	case AssertToolCalled:
+		for _, name := range result.ToolCalls {
+			if name == a.Tool {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%s was not called", a.Tool)
+	case AssertCompleted:
+		if !result.Completed {
+			return "the agent did not call task_completion"
+		}
+	case AssertResultContains:
+		if !result.Completed {
+			return "the agent did not call task_completion"
+		}
+		if !strings.Contains(result.Completion, a.Text) {
+			return fmt.Sprintf("result does not contain %q", a.Text)
+		}
+	}
+	return ""
+}
+
+// runCommand runs command with sh in dir and returns its output if it fails
+func (r *Runner) runCommand(ctx context.Context, dir, command string) string {
+	cmdCtx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "sh", "-c", command)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return ""
+	}
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		return fmt.Sprintf("timed out after %s", r.commandTimeout)
+	}
+	detail := strings.TrimSpace(string(output))
+	if len(detail) > maxDetail {
+		detail = "..." + detail[len(detail)-maxDetail:]
+	}
+	if detail == "" {
+		return err.Error()
+	}
+	return fmt.Sprintf("%v: %s", err, detail)
+}
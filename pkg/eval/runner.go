@@ -0,0 +1,269 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Scenario defaults
+const (
+	DefaultMaxIterations  = 25
+	DefaultTimeout        = 5 * time.Minute
+	DefaultCommandTimeout = 2 * time.Minute
+)
+
+// fileToolTimeout bounds the filesystem tools, as in the CLI
+const fileToolTimeout = 2 * time.Minute
+
+// ProviderFunc returns the provider a scenario runs against
+type ProviderFunc func(s *Scenario) (llm.Provider, error)
+
+// Scripted runs scenarios against a ScriptedProvider replaying their
+// responses. It is the Runner's default.
+func Scripted(s *Scenario) (llm.Provider, error) {
+	if !s.Scripted() {
+		return nil, fmt.Errorf("scenario %s has no scripted responses", s.Name)
+	}
+	return NewScriptedProvider(s.Responses...), nil
+}
+
+// Runner runs scenarios and scores their outcomes
+type Runner struct {
+	provider       ProviderFunc
+	agentOpts      []agent.AgentOption
+	commandTimeout time.Duration
+	keepWorkspaces bool
+	progress       io.Writer
+}
+
+// Option configures a Runner
+type Option func(*Runner)
+
+// WithProvider sets the provider each scenario runs against
+func WithProvider(provider ProviderFunc) Option {
+	return func(r *Runner) {
+		r.provider = provider
+	}
+}
+
+// WithAgentOptions adds options to every agent the runner creates, such as
+// the system prompt under test
+func WithAgentOptions(opts ...agent.AgentOption) Option {
+	return func(r *Runner) {
+		r.agentOpts = append(r.agentOpts, opts...)
+	}
+}
+
+// WithCommandTimeout bounds each command assertion
+func WithCommandTimeout(timeout time.Duration) Option {
+	return func(r *Runner) {
+		r.commandTimeout = timeout
+	}
+}
+
+// WithKeepWorkspaces keeps each scenario's workspace after the run and
+// records its path in the result, for inspecting failures
+func WithKeepWorkspaces(keep bool) Option {
+	return func(r *Runner) {
+		r.keepWorkspaces = keep
+	}
+}
+
+// WithProgress writes a line to w as each scenario starts and finishes
+func WithProgress(w io.Writer) Option {
+	return func(r *Runner) {
+		r.progress = w
+	}
+}
+
+// NewRunner creates a runner that uses the scripted provider by default
+func NewRunner(opts ...Option) *Runner {
+	r := &Runner{
+		provider:       Scripted,
+		commandTimeout: DefaultCommandTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RunAll runs scenarios one after another and returns their results
+func (r *Runner) RunAll(ctx context.Context, scenarios []*Scenario) *Report {
+	report := &Report{}
+	for _, s := range scenarios {
+		if ctx.Err() != nil {
+			break
+		}
+		if r.progress != nil {
+			fmt.Fprintf(r.progress, "Running %s...\n", s.Name)
+		}
+		result := r.Run(ctx, s)
+		if r.progress != nil {
+			fmt.Fprintf(r.progress, "%s %s (%.0f%%, %s)\n", result.status(), s.Name, result.Score*100, result.Duration.Round(time.Millisecond))
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Run runs one scenario in a fresh copy of its fixture and checks its
+// assertions. Failures to set up the run are reported in Result.Error.
+func (r *Runner) Run(ctx context.Context, s *Scenario) *Result {
+	start := time.Now()
+	result := &Result{Scenario: s.Name}
+	defer func() { result.Duration = time.Since(start) }()
+
+	dir, err := os.MkdirTemp("", "forge-eval-")
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create workspace: %v", err)
+		return result
+	}
+	if r.keepWorkspaces {
+		result.Workspace = dir
+	} else {
+		defer os.RemoveAll(dir)
+	}
+	if fixture := s.fixtureDir(); fixture != "" {
+		if err := copyDir(fixture, dir); err != nil {
+			result.Error = fmt.Sprintf("failed to copy fixture: %v", err)
+			return result
+		}
+	}
+
+	provider, err := r.provider(s)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	ag, err := r.newAgent(provider, dir, s)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := runTurn(runCtx, ag, s.Task, result); err != nil {
+		result.Error = err.Error()
+	}
+
+	result.Checks = r.check(ctx, dir, s.Assertions, result)
+	result.score()
+	return result
+}
+
+// newAgent creates an agent with the coding tools over dir
+func (r *Runner) newAgent(provider llm.Provider, dir string, s *Scenario) (*agent.DefaultAgent, error) {
+	guard, err := workspace.NewGuard(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace guard: %w", err)
+	}
+
+	maxIterations := s.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultMaxIterations
+	}
+	opts := append([]agent.AgentOption{agent.WithMaxTurns(maxIterations)}, r.agentOpts...)
+	ag := agent.NewDefaultAgent(provider, opts...)
+
+	fileTools := []tools.Tool{
+		coding.NewReadFileTool(guard),
+		coding.NewListFilesTool(guard),
+		coding.NewSearchFilesTool(guard),
+		coding.NewWriteFileTool(guard),
+		coding.NewApplyDiffTool(guard),
+	}
+	for _, tool := range fileTools {
+		if err := ag.RegisterTool(tool, agent.WithToolTimeout(fileToolTimeout)); err != nil {
+			return nil, fmt.Errorf("failed to register tool: %w", err)
+		}
+	}
+	if err := ag.RegisterTool(coding.NewExecuteCommandTool(guard)); err != nil {
+		return nil, fmt.Errorf("failed to register tool: %w", err)
+	}
+	return ag, nil
+}
+
+// runTurn sends task to the agent, approves every tool call, and records
+// the turn in result until it ends. It returns an error if the turn did
+// not end before ctx.
+func runTurn(ctx context.Context, ag *agent.DefaultAgent, task string, result *Result) error {
+	if err := ag.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+	channels := ag.GetChannels()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_ = ag.Shutdown(shutdownCtx)
+		for range channels.Event {
+			// Drain until the agent closes its channels
+		}
+	}()
+
+	channels.Input <- types.NewUserInput(task)
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("turn did not finish: %w", ctx.Err())
+		case event, ok := <-channels.Event:
+			if !ok {
+				return fmt.Errorf("agent stopped before the turn ended")
+			}
+			if event.Type == types.EventTypeToolApprovalRequest {
+				channels.Approval <- types.NewApprovalResponse(event.ApprovalID, types.ApprovalGranted)
+				continue
+			}
+			if event.Type == types.EventTypeTurnEnd {
+				return nil
+			}
+			result.record(event)
+		}
+	}
+}
+
+// copyDir copies the files under src into dst, keeping their modes
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil // Symlinks and devices could reach outside the workspace
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
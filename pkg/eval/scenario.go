@@ -0,0 +1,232 @@
+// Package eval runs the agent against scripted scenarios and scores the
+// outcome, so changes to prompts, parsing or tools can be checked for
+// behavioral regressions.
+//
+// A scenario is a YAML file describing a task, an optional fixture workspace
+// and assertions about the result:
+//
+//	name: add-greeting
+//	task: Add a Greet(name string) string function to greet.go
+//	fixture: fixtures/greeting     # Copied into a fresh workspace
+//	assertions:
+//	  - type: file_contains
+//	    path: greet.go
+//	    text: func Greet
+//	  - type: command
+//	    command: go test ./...
+//	  - type: completed
+//	responses:                     # Model replies for the scripted provider
+//	  - <tool>...</tool>
+//
+// The Runner copies the fixture into a temporary directory, runs one agent
+// turn on the task with the coding tools, approving every tool call, and
+// then checks each assertion against the workspace and the recorded turn.
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Assertion types
+const (
+	AssertFileExists      = "file_exists"       // Path exists
+	AssertFileMissing     = "file_missing"      // Path does not exist
+	AssertFileContains    = "file_contains"     // Path contains Text
+	AssertFileNotContains = "file_not_contains" // Path exists and does not contain Text
+	AssertCommand         = "command"           // Command exits 0 in the workspace, e.g. "go test ./..."
+	AssertToolCalled      = "tool_called"       // Tool was called at least once
+	AssertCompleted       = "completed"         // The agent called task_completion
+	AssertResultContains  = "result_contains"   // The task_completion result contains Text
+)
+
+// Scenario is one task for the agent and what must hold afterwards
+type Scenario struct {
+	Name          string        `yaml:"name"`
+	Description   string        `yaml:"description,omitempty"`
+	Task          string        `yaml:"task"`
+	Fixture       string        `yaml:"fixture,omitempty"` // Directory copied into the workspace, relative to the scenario file
+	Assertions    []Assertion   `yaml:"assertions"`
+	MaxIterations int           `yaml:"max_iterations,omitempty"` // 0 = DefaultMaxIterations
+	Timeout       time.Duration `yaml:"timeout,omitempty"`        // 0 = DefaultTimeout
+
+	// Responses are the model's replies, in order, for the scripted provider.
+	// Scenarios without responses can only run against a real provider.
+	Responses []string `yaml:"responses,omitempty"`
+
+	// Path is the file the scenario was read from
+	Path string `yaml:"-"`
+}
+
+// Assertion is a condition checked after the agent's turn
+type Assertion struct {
+	Type    string `yaml:"type"`
+	Path    string `yaml:"path,omitempty"`    // Workspace-relative file for file_* assertions
+	Text    string `yaml:"text,omitempty"`    // Substring for *_contains assertions
+	Command string `yaml:"command,omitempty"` // Shell command for command assertions
+	Tool    string `yaml:"tool,omitempty"`    // Tool name for tool_called assertions
+}
+
+// String describes the assertion for reports
+func (a Assertion) String() string {
+	switch a.Type {
+	case AssertFileExists, AssertFileMissing:
+		return fmt.Sprintf("%s %s", a.Type, a.Path)
+	case AssertFileContains, AssertFileNotContains:
+		return fmt.Sprintf("%s %s %q", a.Type, a.Path, a.Text)
+	case AssertCommand:
+		return fmt.Sprintf("command %q", a.Command)
+	case AssertToolCalled:
+		return fmt.Sprintf("tool_called %s", a.Tool)
+	case AssertResultContains:
+		return fmt.Sprintf("result_contains %q", a.Text)
+	default:
+		return a.Type
+	}
+}
+
+// validate reports the first field an assertion is missing
+func (a Assertion) validate() error {
+	var missing string
+	switch a.Type {
+	case AssertFileExists, AssertFileMissing:
+		if a.Path == "" {
+			missing = "path"
+		}
+	case AssertFileContains, AssertFileNotContains:
+		if a.Path == "" {
+			missing = "path"
+		} else if a.Text == "" {
+			missing = "text"
+		}
+	case AssertCommand:
+		if a.Command == "" {
+			missing = "command"
+		}
+	case AssertToolCalled:
+		if a.Tool == "" {
+			missing = "tool"
+		}
+	case AssertResultContains:
+		if a.Text == "" {
+			missing = "text"
+		}
+	case AssertCompleted:
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+	if missing != "" {
+		return fmt.Errorf("%s assertion requires %s", a.Type, missing)
+	}
+	return nil
+}
+
+// Scripted reports whether the scenario can run on the scripted provider
+func (s *Scenario) Scripted() bool {
+	return len(s.Responses) > 0
+}
+
+// fixtureDir returns the absolute fixture directory, or "" if there is none
+func (s *Scenario) fixtureDir() string {
+	if s.Fixture == "" || filepath.IsAbs(s.Fixture) {
+		return s.Fixture
+	}
+	return filepath.Join(filepath.Dir(s.Path), s.Fixture)
+}
+
+// validate checks that the scenario can be run
+func (s *Scenario) validate() error {
+	if strings.TrimSpace(s.Task) == "" {
+		return fmt.Errorf("task is required")
+	}
+	if len(s.Assertions) == 0 {
+		return fmt.Errorf("at least one assertion is required")
+	}
+	for i, a := range s.Assertions {
+		if err := a.validate(); err != nil {
+			return fmt.Errorf("assertion %d: %w", i+1, err)
+		}
+	}
+	if dir := s.fixtureDir(); dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("fixture: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("fixture %s is not a directory", dir)
+		}
+	}
+	return nil
+}
+
+// LoadScenario reads a scenario file. The name defaults to the file name
+// without its extension.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	s := &Scenario{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	s.Path = path
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// LoadScenarios reads the scenarios at paths. A directory contributes every
+// .yaml and .yml file directly inside it, in name order.
+func LoadScenarios(paths ...string) ([]*Scenario, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scenarios: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scenarios: %w", err)
+		}
+		var found []string
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				found = append(found, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+
+	scenarios := make([]*Scenario, 0, len(files))
+	seen := make(map[string]string)
+	for _, file := range files {
+		s, err := LoadScenario(file)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[s.Name]; ok {
+			return nil, fmt.Errorf("scenario %q is defined in both %s and %s", s.Name, other, file)
+		}
+		seen[s.Name] = file
+		scenarios = append(scenarios, s)
+	}
+	return scenarios, nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import "github.com/entrhq/forge/pkg/types"
+
+// Exit statuses for an error returned by Run, by the error's code
+const (
+	ExitOK              = 0   // The turn finished without error
+	ExitFailure         = 1   // An internal or unclassified error
+	ExitAuth            = 3   // The provider rejected the credentials
+	ExitRateLimited     = 4   // The provider's rate limit was exceeded
+	ExitContextOverflow = 5   // The conversation didn't fit in the context window
+	ExitProviderFailure = 6   // Any other provider failure
+	ExitAgentFailure    = 7   // Tools or the model's replies kept failing
+	ExitTimeout         = 8   // An operation timed out
+	ExitCanceled        = 130 // The run was interrupted
+)
+
+// exitCodes maps error codes to exit statuses
+var exitCodes = map[types.ErrorCode]int{
+	types.ErrorCodeAuth:            ExitAuth,
+	types.ErrorCodeRateLimited:     ExitRateLimited,
+	types.ErrorCodeContextOverflow: ExitContextOverflow,
+	types.ErrorCodeLLMFailure:      ExitProviderFailure,
+	types.ErrorCodeToolFailure:     ExitAgentFailure,
+	types.ErrorCodeInvalidResponse: ExitAgentFailure,
+	types.ErrorCodeTimeout:         ExitTimeout,
+	types.ErrorCodeCanceled:        ExitCanceled,
+}
+
+// ExitCode returns the process exit status for an error returned by Run,
+// chosen by its code (see types.ClassifyError)
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if code, ok := exitCodes[types.ClassifyError(err).Code]; ok {
+		return code
+	}
+	return ExitFailure
+}
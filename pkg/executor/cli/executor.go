@@ -41,8 +41,12 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/core"
+	"github.com/entrhq/forge/pkg/agent/risk"
+	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -55,9 +59,14 @@ type Executor struct {
 
 	// Display options
 	showThinking bool
+	accessible   bool
+	prompt       string // One-shot prompt; when set, Run exits after its turn
 
 	// State tracking
 	messageStartPrinted bool
+	streamedMessageTool string                 // Message tool whose reply was streamed, e.g. converse
+	approvals           chan *types.AgentEvent // Approval requests awaiting a user decision
+	turnErr             *types.AgentError      // Last error of the turn, cleared when a tool then succeeds
 }
 
 // ExecutorOption is a function that configures an Executor.
@@ -70,6 +79,24 @@ func WithShowThinking(show bool) ExecutorOption {
 	}
 }
 
+// WithAccessible enables screen-reader friendly output: status lines are plain
+// words without emoji, and busy state changes are announced as text.
+func WithAccessible(accessible bool) ExecutorOption {
+	return func(e *Executor) {
+		e.accessible = accessible
+	}
+}
+
+// WithPrompt makes Run send prompt as the only input and return once the
+// agent's turn ends, instead of reading a conversation from stdin. Tool
+// approvals are still read from stdin. If the turn ends on an error, Run
+// returns it wrapped; see ExitCode.
+func WithPrompt(prompt string) ExecutorOption {
+	return func(e *Executor) {
+		e.prompt = prompt
+	}
+}
+
 // WithWriter sets a custom output writer (default is os.Stdout).
 func WithWriter(w io.Writer) ExecutorOption {
 	return func(e *Executor) {
@@ -84,6 +111,7 @@ func NewExecutor(agent agent.Agent, opts ...ExecutorOption) *Executor {
 		reader:       bufio.NewReader(os.Stdin),
 		writer:       os.Stdout,
 		showThinking: true, // Show thinking by default
+		approvals:    make(chan *types.AgentEvent, 1),
 	}
 
 	for _, opt := range opts {
@@ -108,6 +136,20 @@ func (e *Executor) Run(ctx context.Context) error {
 	turnEnd := make(chan struct{}, 1)
 	go e.handleEvents(channels.Event, eventsDone, turnEnd)
 
+	if e.prompt != "" {
+		channels.Input <- types.NewUserInput(e.prompt)
+		e.waitForTurn(channels, turnEnd)
+		e.shutdown(ctx)
+		<-eventsDone
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if e.turnErr != nil {
+			return fmt.Errorf("turn failed: %w", e.turnErr)
+		}
+		return nil
+	}
+
 	// Print welcome message
 	fmt.Fprintln(e.writer, "Forge CLI Agent")
 	fmt.Fprintln(e.writer, "Type your message and press Enter. Type 'exit' or 'quit' to end the conversation.")
@@ -153,11 +195,75 @@ func (e *Executor) Run(ctx context.Context) error {
 		// Send input to agent
 		channels.Input <- types.NewUserInput(input)
 
-		// Wait for turn to complete
-		<-turnEnd
+		// Wait for turn to complete, answering any approval requests
+		e.waitForTurn(channels, turnEnd)
+	}
+}
+
+// waitForTurn blocks until the turn ends, prompting the user for each tool
+// approval requested in the meantime
+func (e *Executor) waitForTurn(channels *types.AgentChannels, turnEnd <-chan struct{}) {
+	for {
+		select {
+		case <-turnEnd:
+			return
+		case event := <-e.approvals:
+			channels.Approval <- e.PromptApproval(event)
+		}
+	}
+}
+
+// PromptApproval describes a pending tool execution and reads the user's decision.
+// Anything other than "y" or "yes" (including a read error) rejects the tool.
+func (e *Executor) PromptApproval(event *types.AgentEvent) *types.ApprovalResponse {
+	fmt.Fprintf(e.writer, "\n%sApproval required for tool: %s\n", e.marker("⏳"), event.ToolName)
+	if preview, ok := event.Preview.(*tools.ToolPreview); ok {
+		if preview.Title != "" {
+			fmt.Fprintln(e.writer, preview.Title)
+		}
+		if preview.Description != "" {
+			fmt.Fprintln(e.writer, preview.Description)
+		}
+		if preview.Content != "" {
+			fmt.Fprintln(e.writer, preview.Content)
+		}
+		if assessment, ok := preview.Metadata[risk.MetadataKey].(*risk.Assessment); ok {
+			fmt.Fprintln(e.writer, assessment.Summary())
+		}
+	}
+	if e.Confirm("Approve?") {
+		return types.NewApprovalResponse(event.ApprovalID, types.ApprovalGranted)
+	}
+	return types.NewApprovalResponse(event.ApprovalID, types.ApprovalRejected)
+}
+
+// Confirm asks a yes/no question on stdin. Anything other than "y" or "yes"
+// (including a read error) is no.
+func (e *Executor) Confirm(question string) bool {
+	fmt.Fprintf(e.writer, "%s (y/n): ", question)
+	answer, err := e.reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
 	}
 }
 
+// RenderEvent displays an event of a turn driven by someone other than Run,
+// such as a workflow runner. Approval requests and turn ends are left to the
+// caller.
+func (e *Executor) RenderEvent(event *types.AgentEvent) {
+	switch event.Type {
+	case types.EventTypeToolApprovalRequest, types.EventTypeTurnEnd:
+		return
+	}
+	e.handleEvent(event, nil)
+}
+
 // handleEvents processes events from the agent and renders them to the terminal.
 func (e *Executor) handleEvents(events <-chan *types.AgentEvent, done chan struct{}, turnEnd chan struct{}) {
 	defer close(done)
@@ -181,19 +287,37 @@ func (e *Executor) handleEvent(event *types.AgentEvent, turnEnd chan struct{}) {
 	case types.EventTypeToolCall:
 		e.handleToolCall(event.ToolName)
 	case types.EventTypeToolResult:
-		e.handleToolResult(event.ToolOutput)
+		e.turnErr = nil // The agent recovered from any earlier error
+		if core.StreamedReply(event) {
+			e.streamedMessageTool = event.ToolName
+		}
+		e.handleToolResult(event.ToolName, event.ToolOutput)
 	case types.EventTypeToolResultError:
 		e.handleToolResultError(event.ToolName, event.Error)
 	case types.EventTypeMessageStart:
-		e.handleMessageStart()
+		e.handleMessageStart(event)
 	case types.EventTypeMessageContent:
 		e.handleMessageContent(event.Content)
 	case types.EventTypeMessageEnd:
 		e.handleMessageEnd()
 	case types.EventTypeError:
-		e.handleError(event.Error)
+		e.handleError(event)
+	case types.EventTypeBudgetExceeded:
+		e.handleBudgetExceeded(event)
+	case types.EventTypeRateLimitWait:
+		e.handleRateLimitWait(types.EventRateLimitWait.Payload(event))
+	case types.EventTypeInjectionWarning:
+		e.handleInjectionWarning(event)
+	case types.EventTypeToolApprovalRequest:
+		e.approvals <- event
+	case types.EventTypeToolApprovalGranted:
+		fmt.Fprintf(e.writer, "%sApproved: %s\n", e.marker("✓"), event.ToolName)
+	case types.EventTypeToolApprovalRejected:
+		fmt.Fprintf(e.writer, "%sRejected: %s\n", e.marker("✗"), event.ToolName)
+	case types.EventTypeToolApprovalTimeout:
+		fmt.Fprintf(e.writer, "\n%sApproval timed out: %s\n", e.marker("⏱"), event.ToolName)
 	case types.EventTypeUpdateBusy:
-		// Could show a spinner here in the future
+		e.handleUpdateBusy(event.IsBusy)
 	case types.EventTypeTurnEnd:
 		e.handleTurnEnd(turnEnd)
 	}
@@ -217,24 +341,62 @@ func (e *Executor) handleThinkingEnd() {
 	}
 }
 
-func (e *Executor) handleToolCall(toolName string) {
-	fmt.Fprintf(e.writer, "\n🔧 Tool: %s\n", toolName)
+// marker returns an emoji status prefix, or nothing in accessible mode where
+// the words that follow carry the meaning on their own
+func (e *Executor) marker(emoji string) string {
+	if e.accessible {
+		return ""
+	}
+	return emoji + " "
 }
 
-func (e *Executor) handleToolResult(toolOutput interface{}) {
-	if result, ok := toolOutput.(string); ok {
-		fmt.Fprintf(e.writer, "✅ Result: %s\n", result)
+func (e *Executor) handleUpdateBusy(busy bool) {
+	// Without a spinner, announce busy state only for screen reader users
+	if !e.accessible {
+		return
+	}
+	if busy {
+		fmt.Fprintln(e.writer, "[Working...]")
 	} else {
-		fmt.Fprintf(e.writer, "✅ Result: %v\n", toolOutput)
+		fmt.Fprintln(e.writer, "[Ready]")
+	}
+}
+
+func (e *Executor) handleToolCall(toolName string) {
+	if core.StreamsAsMessage(toolName) {
+		return // The reply is printed as a message
+	}
+	fmt.Fprintf(e.writer, "\n%sTool: %s\n", e.marker("🔧"), toolName)
+}
+
+func (e *Executor) handleToolResult(toolName string, toolOutput interface{}) {
+	if core.StreamsAsMessage(toolName) && e.streamedMessageTool == toolName {
+		e.streamedMessageTool = ""
+		return // Already printed as it streamed
+	}
+	switch result := toolOutput.(type) {
+	case *tools.Result:
+		if !result.Success {
+			fmt.Fprintf(e.writer, "%sResult (failed): %s\n", e.marker("⚠"), result.Output)
+			return
+		}
+		fmt.Fprintf(e.writer, "%sResult: %s\n", e.marker("✅"), result.Output)
+	case string:
+		fmt.Fprintf(e.writer, "%sResult: %s\n", e.marker("✅"), result)
+	default:
+		fmt.Fprintf(e.writer, "%sResult: %v\n", e.marker("✅"), toolOutput)
 	}
 }
 
 func (e *Executor) handleToolResultError(toolName string, err error) {
-	fmt.Fprintf(e.writer, "❌ Tool Error (%s): %v\n", toolName, err)
+	fmt.Fprintf(e.writer, "%sTool Error (%s): %v\n", e.marker("❌"), toolName, err)
 }
 
-func (e *Executor) handleMessageStart() {
+func (e *Executor) handleMessageStart(event *types.AgentEvent) {
 	e.messageStartPrinted = false
+	if toolName, ok := event.Metadata["tool_name"].(string); ok {
+		e.streamedMessageTool = toolName
+	}
 }
 
 func (e *Executor) handleMessageContent(content string) {
@@ -249,8 +411,45 @@ func (e *Executor) handleMessageEnd() {
 	fmt.Fprintln(e.writer) // New line after message
 }
 
-func (e *Executor) handleError(err error) {
-	fmt.Fprintf(e.writer, "\n❌ Error: %v\n", err)
+func (e *Executor) handleError(event *types.AgentEvent) {
+	e.turnErr = event.AgentError()
+	fmt.Fprintf(e.writer, "\n%sError: %v\n", e.marker("❌"), event.Error)
+	if guidance := e.turnErr.Guidance("forge doctor"); guidance != "" {
+		fmt.Fprintf(e.writer, "%s\n", guidance)
+	}
+}
+
+func (e *Executor) handleBudgetExceeded(event *types.AgentEvent) {
+	fmt.Fprintf(e.writer, "\n%sTurn budget exceeded: %v %v of %v. Reply \"continue\" to grant another budget.\n",
+		e.marker("⏸"), event.Metadata["limit"], event.Metadata["used"], event.Metadata["max"])
+}
+
+// handleRateLimitWait reports the agent's own calls waiting for the rate
+// limit; background work waiting is not shown
+func (e *Executor) handleRateLimitWait(wait types.RateLimitPayload) {
+	if wait.Background {
+		return
+	}
+	fmt.Fprintf(e.writer, "\n%sWaiting %v for the provider's %s rate limit\n",
+		e.marker("⏳"), wait.Wait.Round(time.Second), rateLimitName(wait.Reason))
+}
+
+// rateLimitName names the limit a wait reason refers to
+func rateLimitName(reason string) string {
+	switch reason {
+	case types.RateLimitReasonRequests:
+		return "request"
+	case types.RateLimitReasonTokens:
+		return "token"
+	default:
+		return "retry-after"
+	}
+}
+
+func (e *Executor) handleInjectionWarning(event *types.AgentEvent) {
+	rules, _ := event.Metadata["rules"].([]string)
+	fmt.Fprintf(e.writer, "\n%sWarning: possible prompt injection in %s output (%s); further actions this turn need your approval\n",
+		e.marker("⚠"), event.ToolName, strings.Join(rules, ", "))
 }
 
 func (e *Executor) handleTurnEnd(turnEnd chan struct{}) {
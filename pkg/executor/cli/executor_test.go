@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/core"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestAccessibleOutputHasNoEmoji(t *testing.T) {
+	var out bytes.Buffer
+	e := NewExecutor(nil, WithWriter(&out), WithAccessible(true))
+	turnEnd := make(chan struct{}, 1)
+
+	e.handleEvent(types.NewUpdateBusyEvent(true), turnEnd)
+	e.handleEvent(types.NewToolCallEvent("read_file", nil), turnEnd)
+	e.handleEvent(types.NewToolResultErrorEvent("read_file", errors.New("not found")), turnEnd)
+	e.handleEvent(types.NewToolApprovalRejectedEvent("id", "write_file", ""), turnEnd)
+	e.handleEvent(types.NewUpdateBusyEvent(false), turnEnd)
+
+	got := out.String()
+	for _, want := range []string{"[Working...]", "Tool: read_file", "Tool Error (read_file): not found", "Rejected: write_file", "[Ready]"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+	for _, r := range got {
+		if r > 0x2000 {
+			t.Errorf("expected plain text output, found %q in:\n%s", r, got)
+			break
+		}
+	}
+}
+
+func TestPromptApproval(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		want   types.ApprovalDecision
+	}{
+		{"yes", "y\n", types.ApprovalGranted},
+		{"full word", "Yes\n", types.ApprovalGranted},
+		{"no", "n\n", types.ApprovalRejected},
+		{"empty", "\n", types.ApprovalRejected},
+		{"eof", "", types.ApprovalRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			e := NewExecutor(nil, WithWriter(&out), WithAccessible(true))
+			e.reader = bufio.NewReader(strings.NewReader(tt.answer))
+
+			preview := &tools.ToolPreview{Title: "Write main.go", Content: "package main"}
+			resp := e.PromptApproval(types.NewToolApprovalRequestEvent("id-1", "write_file", nil, preview))
+
+			if resp.ApprovalID != "id-1" || resp.Decision != tt.want {
+				t.Errorf("got %+v, want decision %v", resp, tt.want)
+			}
+			if !strings.Contains(out.String(), "Approval required for tool: write_file") ||
+				!strings.Contains(out.String(), "Write main.go") {
+				t.Errorf("expected textual approval prompt, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestHandleError_TracksTurnError(t *testing.T) {
+	var out bytes.Buffer
+	e := NewExecutor(nil, WithWriter(&out))
+	turnEnd := make(chan struct{}, 1)
+
+	rateLimited := types.WrapError(types.ErrorCodeRateLimited, errors.New("API request failed with status 429"))
+	e.handleEvent(types.NewErrorEvent(rateLimited), turnEnd)
+	if !strings.Contains(out.String(), "rate limiting") {
+		t.Errorf("expected rate limit guidance, got:\n%s", out.String())
+	}
+	if ExitCode(e.turnErr) != ExitRateLimited {
+		t.Errorf("ExitCode() = %d, want %d", ExitCode(e.turnErr), ExitRateLimited)
+	}
+
+	// A later successful tool call means the agent recovered
+	e.handleEvent(types.NewToolResultEvent("read_file", "ok"), turnEnd)
+	if e.turnErr != nil {
+		t.Errorf("expected the turn error to clear, got %v", e.turnErr)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"unclassified", errors.New("boom"), ExitFailure},
+		{"auth", types.WrapError(types.ErrorCodeAuth, errors.New("401")), ExitAuth},
+		{"wrapped", fmt.Errorf("turn failed: %w", types.WrapError(types.ErrorCodeContextOverflow, errors.New("400"))), ExitContextOverflow},
+		{"canceled", context.Canceled, ExitCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamedConverseReplyPrintedOnce(t *testing.T) {
+	var out bytes.Buffer
+	e := NewExecutor(nil, WithWriter(&out))
+	turnEnd := make(chan struct{}, 1)
+
+	start := types.NewMessageStartEvent()
+	start.Metadata["tool_name"] = "converse"
+	e.handleEvent(start, turnEnd)
+	e.handleEvent(types.NewMessageContentEvent("Hello there"), turnEnd)
+	e.handleEvent(types.NewMessageEndEvent(), turnEnd)
+	e.handleEvent(types.NewToolCallEvent("converse", nil), turnEnd)
+	e.handleEvent(types.NewToolResultEvent("converse", tools.TextResult("Hello there")), turnEnd)
+
+	got := out.String()
+	if strings.Count(got, "Hello there") != 1 {
+		t.Errorf("expected the reply once, got:\n%s", got)
+	}
+	if strings.Contains(got, "Tool: converse") {
+		t.Errorf("expected no tool line for the reply, got:\n%s", got)
+	}
+}
+
+func TestAcceptedProseReplyPrintedOnce(t *testing.T) {
+	var out bytes.Buffer
+	e := NewExecutor(nil, WithWriter(&out))
+	turnEnd := make(chan struct{}, 1)
+
+	e.handleEvent(types.NewMessageStartEvent(), turnEnd)
+	e.handleEvent(types.NewMessageContentEvent("Hello there"), turnEnd)
+	e.handleEvent(types.NewMessageEndEvent(), turnEnd)
+	e.handleEvent(types.NewToolCallEvent("converse", nil), turnEnd)
+	e.handleEvent(core.NewStreamedReplyEvent("converse", tools.TextResult("Hello there")), turnEnd)
+
+	if got := out.String(); strings.Count(got, "Hello there") != 1 {
+		t.Errorf("expected the reply once, got:\n%s", got)
+	}
+}
@@ -0,0 +1,324 @@
+// Package headless provides an executor that runs one prompt to completion
+// without a user, for scripts and CI.
+//
+// It never reads stdin. Tool calls the agent's approval policy doesn't
+// auto-approve (the auto-approval config and the command whitelist) are
+// rejected, with feedback telling the model to do without them. Progress
+// is logged to stderr and the result is printed to stdout, as text or
+// JSON, so a script can capture it; Run's error maps to an exit status
+// with ExitCode.
+//
+// Example usage:
+//
+//	executor := headless.NewExecutor(ag, "add a unit test for the tokenizer",
+//	    headless.WithFormat(headless.FormatJSON),
+//	)
+//	err := executor.Run(ctx)
+//	os.Exit(headless.ExitCode(err))
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/core"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/executor/cli"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// ExitIncomplete is the exit status of a run that ended before the task was
+// done without failing: the agent asked a question, exhausted its turn
+// budget, or stopped without a result. The other statuses are cli's.
+const ExitIncomplete = 9
+
+// ErrIncomplete is returned by Run when the turn ended before the task was
+// done without failing
+var ErrIncomplete = errors.New("the agent stopped before finishing")
+
+// ExitCode returns the process exit status for an error returned by Run:
+// ExitIncomplete for ErrIncomplete, otherwise the status cli.ExitCode gives
+// the error's code
+func ExitCode(err error) int {
+	if errors.Is(err, ErrIncomplete) {
+		return ExitIncomplete
+	}
+	return cli.ExitCode(err)
+}
+
+// deniedFeedback is sent to the model with each tool call that was not
+// auto-approved
+const deniedFeedback = "Not run: this is a non-interactive run, so only tool calls the configuration " +
+	"auto-approves (auto_approval and command_whitelist) can run, and nobody can approve this one. " +
+	"Do the task another way, or finish and report what is left for the user to do."
+
+// Format is how Run prints the outcome
+type Format string
+
+const (
+	FormatText Format = "text" // The result alone, as plain text (the default)
+	FormatJSON Format = "json" // The Outcome as a JSON object
+)
+
+// ParseFormat returns the format named s
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("format must be %q or %q, got %q", FormatText, FormatJSON, s)
+}
+
+// Status is how a run ended
+type Status string
+
+const (
+	StatusCompleted      Status = "completed"       // The agent called task_completion
+	StatusReplied        Status = "replied"         // The agent answered with converse
+	StatusQuestion       Status = "question"        // The agent asked a question nobody can answer
+	StatusBudgetExceeded Status = "budget_exceeded" // The turn exhausted a loop budget
+	StatusIncomplete     Status = "incomplete"      // The turn ended without a result
+	StatusFailed         Status = "failed"          // The turn ended on an error
+	StatusCanceled       Status = "canceled"        // The run was interrupted
+)
+
+// Outcome is the result of a run, as printed with FormatJSON
+type Outcome struct {
+	Status     Status            `json:"status"`
+	Result     string            `json:"result,omitempty"`     // The completion result, the reply, or the question
+	Completion *tools.Completion `json:"completion,omitempty"` // The completion's structured fields, if the agent filled any in
+	Error      string            `json:"error,omitempty"`      // Why the run failed or stopped
+	Denied     []string          `json:"denied,omitempty"`     // Tool calls rejected for not being auto-approved
+	ExitCode   int               `json:"exit_code"`
+}
+
+// Executor runs one prompt to completion without a user
+type Executor struct {
+	agent  agent.Agent
+	prompt string
+	format Format
+	output io.Writer // The outcome
+	log    io.Writer // Progress
+
+	// State of the turn
+	status     Status
+	result     string
+	completion *tools.Completion
+	turnErr    *types.AgentError // Last error of the turn, cleared when a tool then succeeds
+	denied     []string
+}
+
+// ExecutorOption is a function that configures an Executor.
+type ExecutorOption func(*Executor)
+
+// WithFormat sets how the outcome is printed (default FormatText)
+func WithFormat(format Format) ExecutorOption {
+	return func(e *Executor) {
+		e.format = format
+	}
+}
+
+// WithOutput sets where the outcome is printed (default os.Stdout)
+func WithOutput(w io.Writer) ExecutorOption {
+	return func(e *Executor) {
+		e.output = w
+	}
+}
+
+// WithLog sets where progress is logged (default os.Stderr)
+func WithLog(w io.Writer) ExecutorOption {
+	return func(e *Executor) {
+		e.log = w
+	}
+}
+
+// NewExecutor creates an executor that sends prompt to agent as the only
+// input
+func NewExecutor(agent agent.Agent, prompt string, opts ...ExecutorOption) *Executor {
+	e := &Executor{
+		agent:  agent,
+		prompt: prompt,
+		format: FormatText,
+		output: os.Stdout,
+		log:    os.Stderr,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Run sends the prompt, waits for the agent's turn to end, and prints the
+// outcome. It returns nil if the agent completed the task or replied,
+// ErrIncomplete wrapped if it stopped before finishing, and otherwise the
+// error that ended the turn; see ExitCode.
+func (e *Executor) Run(ctx context.Context) error {
+	if err := e.agent.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+	channels := e.agent.GetChannels()
+	channels.Input <- types.NewUserInput(e.prompt)
+
+	stopped := ""
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			break wait
+		case event, ok := <-channels.Event:
+			if !ok {
+				stopped = "the agent stopped before the turn ended"
+				break wait
+			}
+			if event.Type == types.EventTypeTurnEnd {
+				break wait
+			}
+			if resp := e.handleEvent(event); resp != nil {
+				channels.Approval <- resp
+			}
+		}
+	}
+	e.shutdown(ctx, channels)
+
+	outcome, err := e.outcome(ctx.Err(), stopped)
+	if reportErr := e.report(outcome); reportErr != nil && err == nil {
+		return fmt.Errorf("failed to print the outcome: %w", reportErr)
+	}
+	return err
+}
+
+// handleEvent records an event of the turn and logs progress. For an
+// approval request it returns the rejection to send.
+func (e *Executor) handleEvent(event *types.AgentEvent) *types.ApprovalResponse {
+	switch event.Type {
+	case types.EventTypeToolApprovalRequest:
+		return e.deny(event)
+	case types.EventTypeToolCall:
+		if !core.StreamsAsMessage(event.ToolName) {
+			fmt.Fprintf(e.log, "Tool: %s\n", event.ToolName)
+		}
+	case types.EventTypeToolResult:
+		e.turnErr = nil // The agent recovered from any earlier error
+		e.recordResult(event.ToolName, event.ToolOutput)
+	case types.EventTypeToolResultError:
+		fmt.Fprintf(e.log, "Tool error (%s): %v\n", event.ToolName, event.Error)
+	case types.EventTypeError:
+		e.turnErr = event.AgentError()
+		fmt.Fprintf(e.log, "Error: %v\n", event.Error)
+	case types.EventTypeBudgetExceeded:
+		budget := types.EventBudgetExceeded.Payload(event)
+		e.status = StatusBudgetExceeded
+		e.result = fmt.Sprintf("turn budget exceeded: %s of %s %s", budget.Used, budget.Max, budget.Limit)
+		fmt.Fprintf(e.log, "Turn budget exceeded: %s %s of %s\n", budget.Limit, budget.Used, budget.Max)
+	case types.EventTypeRateLimitWait:
+		if wait := types.EventRateLimitWait.Payload(event); !wait.Background {
+			fmt.Fprintf(e.log, "Waiting %v for the provider's rate limit\n", wait.Wait.Round(time.Second))
+		}
+	case types.EventTypeInjectionWarning:
+		fmt.Fprintf(e.log, "Warning: possible prompt injection in %s output; further actions this turn are denied\n", event.ToolName)
+	}
+	return nil
+}
+
+// recordResult keeps the result of a tool that ends the turn
+func (e *Executor) recordResult(toolName string, output interface{}) {
+	switch toolName {
+	case "task_completion":
+		e.status = StatusCompleted
+	case "converse":
+		e.status = StatusReplied
+	case "ask_question":
+		e.status = StatusQuestion
+	default:
+		return
+	}
+	e.result = strings.TrimSpace(fmt.Sprint(output))
+	if result, ok := output.(*tools.Result); ok {
+		if completion, ok := tools.CompletionFromResult(result); ok {
+			e.result = completion.Result
+			if completion.Structured() {
+				e.completion = completion
+			}
+		}
+	}
+}
+
+// deny rejects a tool call that the approval policy didn't auto-approve
+func (e *Executor) deny(event *types.AgentEvent) *types.ApprovalResponse {
+	call := event.ToolName
+	if preview, ok := event.Preview.(*tools.ToolPreview); ok && preview.Title != "" {
+		call += ": " + preview.Title
+	}
+	e.denied = append(e.denied, call)
+	fmt.Fprintf(e.log, "Denied (not auto-approved): %s\n", call)
+	return types.NewApprovalResponseWithFeedback(event.ApprovalID, types.ApprovalRejected, deniedFeedback)
+}
+
+// outcome returns how the run ended and the error Run returns for it, given
+// the context's error and why the agent stopped early, if it did
+func (e *Executor) outcome(ctxErr error, stopped string) (*Outcome, error) {
+	outcome := &Outcome{Status: e.status, Result: e.result, Completion: e.completion, Denied: e.denied}
+
+	var err error
+	switch {
+	case ctxErr != nil:
+		outcome.Status, err = StatusCanceled, ctxErr
+	case e.status == StatusCompleted || e.status == StatusReplied:
+	case e.status == StatusQuestion:
+		err = fmt.Errorf("%w: it asked a question", ErrIncomplete)
+	case e.status == StatusBudgetExceeded:
+		outcome.Result = ""
+		err = fmt.Errorf("%w: %s", ErrIncomplete, e.result)
+	case e.turnErr != nil:
+		outcome.Status, err = StatusFailed, fmt.Errorf("turn failed: %w", e.turnErr)
+	case stopped != "":
+		outcome.Status, err = StatusFailed, errors.New(stopped)
+	default:
+		outcome.Status, err = StatusIncomplete, fmt.Errorf("%w: the turn ended without a result", ErrIncomplete)
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	outcome.ExitCode = ExitCode(err)
+	return outcome, err
+}
+
+// report prints the outcome: the result to the output, or all of it as JSON
+func (e *Executor) report(outcome *Outcome) error {
+	if len(outcome.Denied) > 0 {
+		fmt.Fprintf(e.log, "%d tool call(s) were denied; allow them with auto_approval or command_whitelist in the configuration\n", len(outcome.Denied))
+	}
+	if e.format == FormatJSON {
+		enc := json.NewEncoder(e.output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(outcome)
+	}
+	if outcome.Result == "" {
+		return nil
+	}
+	_, err := fmt.Fprintln(e.output, outcome.Result)
+	return err
+}
+
+// shutdown stops the agent and discards the events it sends until it closes
+// its channels
+func (e *Executor) shutdown(ctx context.Context, channels *types.AgentChannels) {
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := e.agent.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(e.log, "Warning: shutdown error: %v\n", err)
+	}
+	for range channels.Event {
+		// Drain until the agent closes its channels
+	}
+}
@@ -0,0 +1,206 @@
+package headless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/executor/cli"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestOutcome(t *testing.T) {
+	completion := &tools.Result{Success: true, Output: "Added the test", Data: map[string]interface{}{
+		"completion": &tools.Completion{Result: "Added the test", FilesChanged: []string{"tokenizer_test.go"}},
+	}}
+	tests := []struct {
+		name       string
+		events     []*types.AgentEvent
+		ctxErr     error
+		wantStatus Status
+		wantResult string
+		wantExit   int
+	}{
+		{
+			name:       "completed",
+			events:     []*types.AgentEvent{types.NewToolCallEvent("write_file", nil), types.NewToolResultEvent("task_completion", completion)},
+			wantStatus: StatusCompleted,
+			wantResult: "Added the test",
+			wantExit:   cli.ExitOK,
+		},
+		{
+			name:       "replied",
+			events:     []*types.AgentEvent{types.NewToolResultEvent("converse", "The parser is in pkg/llm/parser.")},
+			wantStatus: StatusReplied,
+			wantResult: "The parser is in pkg/llm/parser.",
+			wantExit:   cli.ExitOK,
+		},
+		{
+			name:       "question",
+			events:     []*types.AgentEvent{types.NewToolResultEvent("ask_question", "Which tokenizer?")},
+			wantStatus: StatusQuestion,
+			wantResult: "Which tokenizer?",
+			wantExit:   ExitIncomplete,
+		},
+		{
+			name:       "budget exceeded",
+			events:     []*types.AgentEvent{types.NewBudgetExceededEvent("iterations", "50", "50")},
+			wantStatus: StatusBudgetExceeded,
+			wantExit:   ExitIncomplete,
+		},
+		{
+			name:       "failed",
+			events:     []*types.AgentEvent{types.NewErrorEvent(types.NewAgentError(types.ErrorCodeRateLimited, "rate limited"))},
+			wantStatus: StatusFailed,
+			wantExit:   cli.ExitRateLimited,
+		},
+		{
+			name: "recovered",
+			events: []*types.AgentEvent{
+				types.NewErrorEvent(errors.New("invalid XML")),
+				types.NewToolResultEvent("task_completion", "Done"),
+			},
+			wantStatus: StatusCompleted,
+			wantResult: "Done",
+			wantExit:   cli.ExitOK,
+		},
+		{
+			name:       "no result",
+			events:     []*types.AgentEvent{types.NewToolResultEvent("read_file", "package main")},
+			wantStatus: StatusIncomplete,
+			wantExit:   ExitIncomplete,
+		},
+		{
+			name:       "canceled",
+			events:     []*types.AgentEvent{types.NewToolCallEvent("read_file", nil)},
+			ctxErr:     context.Canceled,
+			wantStatus: StatusCanceled,
+			wantExit:   cli.ExitCanceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log bytes.Buffer
+			e := NewExecutor(nil, "task", WithLog(&log))
+			for _, event := range tt.events {
+				e.handleEvent(event)
+			}
+
+			outcome, err := e.outcome(tt.ctxErr, "")
+			if outcome.Status != tt.wantStatus || outcome.Result != tt.wantResult {
+				t.Errorf("expected %s %q, got %s %q", tt.wantStatus, tt.wantResult, outcome.Status, outcome.Result)
+			}
+			if outcome.ExitCode != tt.wantExit || ExitCode(err) != tt.wantExit {
+				t.Errorf("expected exit status %d, got %d (error %v)", tt.wantExit, outcome.ExitCode, err)
+			}
+			if (err == nil) != (outcome.Error == "") {
+				t.Errorf("expected the outcome's error to match %v, got %q", err, outcome.Error)
+			}
+		})
+	}
+}
+
+// proseProvider answers every request in prose, without a tool call
+type proseProvider struct {
+	reply string
+}
+
+func (p *proseProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	ch := make(chan *llm.StreamChunk, 2)
+	ch <- &llm.StreamChunk{Content: p.reply, Role: string(types.RoleAssistant)}
+	ch <- &llm.StreamChunk{Finished: true}
+	close(ch)
+	return ch, nil
+}
+
+func (p *proseProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	return types.NewAssistantMessage(p.reply), nil
+}
+
+func (p *proseProvider) GetModelInfo() *types.ModelInfo {
+	return &types.ModelInfo{Name: "prose"}
+}
+
+func TestRunAcceptsProseReply(t *testing.T) {
+	provider := &proseProvider{reply: "The parser is in pkg/llm/parser."}
+	ag := agent.NewDefaultAgent(provider, agent.WithNoToolCallPolicy(agent.NoToolCallConverse))
+
+	var out, log bytes.Buffer
+	e := NewExecutor(ag, "Where is the parser?", WithOutput(&out), WithLog(&log))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := e.Run(ctx); err != nil {
+		t.Fatalf("expected the reply to end the run, got %v (exit status %d)\n%s", err, ExitCode(err), log.String())
+	}
+	if out.String() != "The parser is in pkg/llm/parser.\n" {
+		t.Errorf("expected the reply on the output, got %q", out.String())
+	}
+}
+
+func TestDeniesApprovals(t *testing.T) {
+	var out, log bytes.Buffer
+	e := NewExecutor(nil, "task", WithOutput(&out), WithLog(&log), WithFormat(FormatJSON))
+
+	preview := &tools.ToolPreview{Title: "Overwrite main.go"}
+	resp := e.handleEvent(types.NewToolApprovalRequestEvent("approval-1", "write_file", nil, preview))
+	if resp == nil || resp.ApprovalID != "approval-1" || !resp.IsRejected() || !strings.Contains(resp.Feedback, "auto_approval") {
+		t.Fatalf("expected a rejection explaining auto-approval, got %+v", resp)
+	}
+	e.handleEvent(types.NewToolResultEvent("task_completion", "Left main.go unchanged"))
+
+	outcome, err := e.outcome(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.report(outcome); err != nil {
+		t.Fatal(err)
+	}
+	var got Outcome
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("expected a JSON outcome, got %q: %v", out.String(), err)
+	}
+	if got.Status != StatusCompleted || len(got.Denied) != 1 || got.Denied[0] != "write_file: Overwrite main.go" {
+		t.Errorf("expected the denied call in the outcome, got %+v", got)
+	}
+	if !strings.Contains(log.String(), "Denied (not auto-approved): write_file: Overwrite main.go") {
+		t.Errorf("expected the denial to be logged, got:\n%s", log.String())
+	}
+}
+
+func TestReportText(t *testing.T) {
+	var out, log bytes.Buffer
+	e := NewExecutor(nil, "task", WithOutput(&out), WithLog(&log))
+	e.handleEvent(types.NewToolCallEvent("read_file", nil))
+	e.handleEvent(types.NewToolResultEvent("task_completion", "Done"))
+
+	outcome, _ := e.outcome(nil, "")
+	if err := e.report(outcome); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Done\n" {
+		t.Errorf("expected only the result on the output, got %q", out.String())
+	}
+	if !strings.Contains(log.String(), "Tool: read_file") {
+		t.Errorf("expected progress on the log, got %q", log.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"text", "json"} {
+		if format, err := ParseFormat(s); err != nil || string(format) != s {
+			t.Errorf("ParseFormat(%q) = %q, %v", s, format, err)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,88 @@
+package approval
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/changelog"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// ChangelogRequest is a concrete implementation of ApprovalRequest for
+// changelog updates. It previews the proposed changelog as a diff and writes
+// it once approved.
+type ChangelogRequest struct {
+	proposal *changelog.Proposal
+	diff     string
+}
+
+// NewChangelogRequest creates a new changelog approval request
+func NewChangelogRequest(proposal *changelog.Proposal, diff string) *ChangelogRequest {
+	return &ChangelogRequest{
+		proposal: proposal,
+		diff:     diff,
+	}
+}
+
+// Title returns the approval dialog title
+func (c *ChangelogRequest) Title() string {
+	return "Changelog Preview"
+}
+
+// Content returns the formatted content for the changelog preview
+func (c *ChangelogRequest) Content() string {
+	var b strings.Builder
+	release := c.proposal.Release
+
+	// Show which commits the release covers
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render("Release:"))
+	b.WriteString("\n")
+	since := "the first commit"
+	if release.Since != "" {
+		since = release.Since
+	}
+	b.WriteString(fmt.Sprintf("  [%s]: %d commit(s) since %s\n\n", release.Version, len(release.Entries), since))
+
+	// Show diff with syntax highlighting
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render("Changes:"))
+	b.WriteString("\n")
+	highlightedDiff, err := syntax.HighlightDiff(c.diff, "markdown")
+	if err != nil {
+		b.WriteString(c.diff)
+	} else {
+		b.WriteString(highlightedDiff)
+	}
+
+	return b.String()
+}
+
+// OnApprove returns the command to execute when the user approves the update
+func (c *ChangelogRequest) OnApprove() tea.Cmd {
+	return func() tea.Msg {
+		err := c.proposal.Apply()
+		return types.OperationCompleteMsg{
+			Result:       fmt.Sprintf("Updated %s with %d commit(s)", filepath.Base(c.proposal.Path), len(c.proposal.Release.Entries)),
+			Err:          err,
+			SuccessTitle: "Changelog Updated",
+			SuccessIcon:  "📝",
+			ErrorTitle:   "Changelog Failed",
+			ErrorIcon:    "❌",
+		}
+	}
+}
+
+// OnReject returns the command to execute when the user rejects the update
+func (c *ChangelogRequest) OnReject() tea.Cmd {
+	return func() tea.Msg {
+		return types.ToastMsg{
+			Message: "Canceled",
+			Details: "/changelog command canceled",
+			Icon:    "ℹ️",
+			IsError: false,
+		}
+	}
+}
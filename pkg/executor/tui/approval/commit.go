@@ -14,22 +14,30 @@ import (
 // CommitRequest is a concrete implementation of ApprovalRequest for git commits.
 // It encapsulates all data needed to preview and execute a commit operation.
 type CommitRequest struct {
+	ctx          context.Context // Canceled by /stop and when the session ends
 	files        []string
 	message      string
 	diff         string
 	args         string
+	committer    string // "" when the commit is made as git's configured committer
 	slashHandler *slash.Handler
 }
 
-// NewCommitRequest creates a new commit approval request
-func NewCommitRequest(files []string, message, diff, args string, slashHandler *slash.Handler) *CommitRequest {
-	return &CommitRequest{
+// NewCommitRequest creates a new commit approval request. The commit is
+// created under ctx once approved.
+func NewCommitRequest(ctx context.Context, files []string, message, diff, args string, slashHandler *slash.Handler) *CommitRequest {
+	c := &CommitRequest{
+		ctx:          ctx,
 		files:        files,
 		message:      message,
 		diff:         diff,
 		args:         args,
 		slashHandler: slashHandler,
 	}
+	if slashHandler != nil {
+		c.committer = slashHandler.Committer(ctx)
+	}
+	return c
 }
 
 // Title returns the approval dialog title
@@ -59,6 +67,14 @@ func (c *CommitRequest) Content() string {
 		b.WriteString("\n\n")
 	}
 
+	// Show who the commit is attributed to, when it is not the user
+	if c.committer != "" {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render("Committer:"))
+		b.WriteString("\n")
+		b.WriteString(c.committer)
+		b.WriteString("\n\n")
+	}
+
 	// Show diff with syntax highlighting
 	if c.diff != "" {
 		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render("Changes:"))
@@ -86,8 +102,7 @@ func (c *CommitRequest) OnApprove() tea.Cmd {
 		},
 		// Then execute the commit
 		func() tea.Msg {
-			ctx := context.Background()
-			result, err := c.slashHandler.Execute(ctx, &slash.Command{
+			result, err := c.slashHandler.Execute(c.ctx, &slash.Command{
 				Name: "commit",
 				Arg:  c.args,
 			})
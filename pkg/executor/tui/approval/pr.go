@@ -13,6 +13,7 @@ import (
 // PRRequest is a concrete implementation of ApprovalRequest for pull requests.
 // It encapsulates all data needed to preview and execute a PR operation.
 type PRRequest struct {
+	ctx          context.Context // Canceled by /stop and when the session ends
 	branch       string
 	prTitle      string
 	prDesc       string
@@ -21,9 +22,11 @@ type PRRequest struct {
 	slashHandler *slash.Handler
 }
 
-// NewPRRequest creates a new PR approval request
-func NewPRRequest(branch, prTitle, prDesc, changes, args string, slashHandler *slash.Handler) *PRRequest {
+// NewPRRequest creates a new PR approval request. The PR is created under
+// ctx once approved.
+func NewPRRequest(ctx context.Context, branch, prTitle, prDesc, changes, args string, slashHandler *slash.Handler) *PRRequest {
 	return &PRRequest{
+		ctx:          ctx,
 		branch:       branch,
 		prTitle:      prTitle,
 		prDesc:       prDesc,
@@ -96,8 +99,7 @@ func (p *PRRequest) OnApprove() tea.Cmd {
 		},
 		// Then execute the PR creation
 		func() tea.Msg {
-			ctx := context.Background()
-			result, err := p.slashHandler.Execute(ctx, &slash.Command{
+			result, err := p.slashHandler.Execute(p.ctx, &slash.Command{
 				Name: "pr",
 				Arg:  p.args,
 			})
@@ -0,0 +1,111 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/release"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// ReleaseRequest is a concrete implementation of ApprovalRequest for one
+// step of a release. Approving it runs the step and then asks about the
+// next one, so every step is previewed before it changes anything.
+type ReleaseRequest struct {
+	ctx  context.Context // Canceled by /stop and when the session ends
+	plan *release.Plan
+	step int
+	done []string // What the earlier steps did
+}
+
+// NewReleaseRequest creates the approval request for the plan's first step
+func NewReleaseRequest(ctx context.Context, plan *release.Plan) *ReleaseRequest {
+	return &ReleaseRequest{
+		ctx:  ctx,
+		plan: plan,
+	}
+}
+
+// Title returns the approval dialog title
+func (r *ReleaseRequest) Title() string {
+	return fmt.Sprintf("Release %s: step %d of %d", r.plan.Tag, r.step+1, len(r.plan.Steps))
+}
+
+// Content returns the formatted content for the step's preview
+func (r *ReleaseRequest) Content() string {
+	var b strings.Builder
+	heading := lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink)
+
+	// Show the release and the steps already run
+	b.WriteString(heading.Render("Release:"))
+	b.WriteString("\n")
+	since := "the first commit"
+	if r.plan.Previous != "" {
+		since = r.plan.Previous
+	}
+	b.WriteString(fmt.Sprintf("  %s: %d commit(s) since %s\n", r.plan.Tag, len(r.plan.Release.Entries), since))
+	for _, done := range r.done {
+		b.WriteString("  ✓ " + done + "\n")
+	}
+	b.WriteString("\n")
+
+	// Show what this step does
+	step := r.plan.Steps[r.step]
+	b.WriteString(heading.Render(step.Title + ":"))
+	b.WriteString("\n")
+	if step.IsDiff {
+		highlightedDiff, err := syntax.HighlightDiff(step.Preview, "markdown")
+		if err == nil {
+			b.WriteString(highlightedDiff)
+			return b.String()
+		}
+	}
+	b.WriteString(step.Preview)
+
+	return b.String()
+}
+
+// OnApprove returns the command that runs the step, then asks about the
+// next one
+func (r *ReleaseRequest) OnApprove() tea.Cmd {
+	return func() tea.Msg {
+		done, err := r.plan.Steps[r.step].Run(r.ctx)
+		if err != nil {
+			return types.OperationCompleteMsg{
+				Err:        fmt.Errorf("%s step failed: %w", r.plan.Steps[r.step].Name, err),
+				ErrorTitle: "Release Failed",
+				ErrorIcon:  "❌",
+			}
+		}
+
+		next := &ReleaseRequest{ctx: r.ctx, plan: r.plan, step: r.step + 1, done: append(append([]string(nil), r.done...), done)}
+		if next.step < len(r.plan.Steps) {
+			return RequestMsg{Request: next}
+		}
+		return types.OperationCompleteMsg{
+			Result:       strings.Join(next.done, "\n"),
+			SuccessTitle: "Released " + r.plan.Tag,
+			SuccessIcon:  "🚀",
+		}
+	}
+}
+
+// OnReject returns the command to execute when the user rejects the step
+func (r *ReleaseRequest) OnReject() tea.Cmd {
+	return func() tea.Msg {
+		details := fmt.Sprintf("/release stopped before the %s step", r.plan.Steps[r.step].Name)
+		if len(r.done) > 0 {
+			details += "; done: " + strings.Join(r.done, ", ")
+		}
+		return types.ToastMsg{
+			Message: "Canceled",
+			Details: details,
+			Icon:    "ℹ️",
+			IsError: false,
+		}
+	}
+}
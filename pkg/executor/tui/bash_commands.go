@@ -15,6 +15,7 @@ import (
 
 // executeBashCommand executes a shell command directly, bypassing the agent
 func (m *model) executeBashCommand(command string) tea.Cmd {
+	cmdCtx := m.commandContext()
 	return func() tea.Msg {
 		// Get the tool from agent's tool registry
 		toolIface := m.agent.GetTool("execute_command")
@@ -41,10 +42,11 @@ func (m *model) executeBashCommand(command string) tea.Cmd {
 		// Prepare XML arguments for the tool
 		argsXML := fmt.Sprintf("<arguments><command>%s</command></arguments>", html.EscapeString(command))
 
-		// Create context with event emitter for streaming support
+		// Create context with event emitter for streaming support; /stop and
+		// Ctrl+C cancel the command through it
 		// Note: The execute_command tool will send its own CommandExecutionStart event
-		ctx := context.WithValue(context.Background(), coding.EventEmitterKey, coding.EventEmitter(func(event *types.AgentEvent) {
-			m.channels.Event <- event
+		ctx := context.WithValue(cmdCtx, coding.EventEmitterKey, coding.EventEmitter(func(event *types.AgentEvent) {
+			types.SendEvent(m.channels.Event, event, cmdCtx.Done())
 		}))
 
 		// Execute the tool
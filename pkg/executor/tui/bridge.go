@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/bridge"
+	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// bridgeSelectionMsg carries a selection sent by an IDE extension
+type bridgeSelectionMsg struct {
+	selection bridge.Selection
+}
+
+// mirrorApproval shows a proposed edit in connected IDE extensions. It
+// returns the function to call with the decision, or nil if nothing was sent.
+func (m *model) mirrorApproval(approvalID string, preview *tools.ToolPreview) func(*types.ApprovalResponse) {
+	if m.bridge == nil {
+		return nil
+	}
+	path, _ := preview.Metadata["file_path"].(string)
+	if path == "" {
+		return nil
+	}
+
+	diff := preview.Content
+	switch preview.Type {
+	case tools.PreviewTypeDiff:
+	case tools.PreviewTypeFileWrite:
+		diff = coding.GenerateUnifiedDiff("", preview.Content, path)
+	default:
+		return nil
+	}
+
+	language, _ := preview.Metadata["language"].(string)
+	m.bridge.ShowDiff(bridge.ShowDiff{
+		ID:       approvalID,
+		Path:     path,
+		Title:    preview.Title,
+		Diff:     diff,
+		Language: language,
+	})
+	return func(response *types.ApprovalResponse) {
+		m.bridge.ResolveDiff(approvalID, response.Decision == types.ApprovalGranted)
+	}
+}
+
+// handleBridgeSelection adds an editor selection to the input as a quoted
+// code block, for the user to ask about
+func (m *model) handleBridgeSelection(msg bridgeSelectionMsg) (tea.Model, tea.Cmd) {
+	block := formatSelection(msg.selection, m.workspaceDir)
+
+	input := m.textarea.Value()
+	if strings.TrimSpace(input) != "" {
+		input = strings.TrimRight(input, "\n") + "\n\n"
+	}
+	m.textarea.SetValue(input + block + "\n")
+	m.textarea.CursorEnd()
+	m.updateTextAreaHeight()
+
+	m.showToast("Selection added", fmt.Sprintf("From %s", selectionLocation(msg.selection, m.workspaceDir)), "📎", false)
+	return m, nil
+}
+
+// formatSelection renders a selection as a Markdown code block headed by its location
+func formatSelection(selection bridge.Selection, workspaceDir string) string {
+	text := strings.TrimRight(selection.Text, "\n")
+	return fmt.Sprintf("%s:\n```%s\n%s\n```", selectionLocation(selection, workspaceDir), selection.Language, text)
+}
+
+// selectionLocation describes where a selection is, relative to the workspace
+func selectionLocation(selection bridge.Selection, workspaceDir string) string {
+	location := relativePath(selection.Path, workspaceDir)
+	switch {
+	case selection.StartLine > 0 && selection.EndLine > selection.StartLine:
+		location += fmt.Sprintf(" lines %d-%d", selection.StartLine, selection.EndLine)
+	case selection.StartLine > 0:
+		location += fmt.Sprintf(" line %d", selection.StartLine)
+	}
+	return location
+}
+
+// relativePath returns path relative to the workspace when it is inside it
+func relativePath(path, workspaceDir string) string {
+	workspace, err := filepath.Abs(workspaceDir)
+	if err != nil || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(workspace, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
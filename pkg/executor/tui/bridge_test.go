@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/bridge"
+)
+
+func TestFormatSelection(t *testing.T) {
+	workspace := t.TempDir()
+	selection := bridge.Selection{
+		Path:      filepath.Join(workspace, "pkg", "a.go"),
+		StartLine: 3,
+		EndLine:   5,
+		Text:      "x := 1\ny := 2\n",
+		Language:  "go",
+	}
+
+	want := filepath.Join("pkg", "a.go") + " lines 3-5:\n```go\nx := 1\ny := 2\n```"
+	if got := formatSelection(selection, workspace); got != want {
+		t.Errorf("formatSelection() = %q, want %q", got, want)
+	}
+
+	selection.Path, selection.EndLine = "/elsewhere/b.go", 3
+	want = "/elsewhere/b.go line 3:\n```go\nx := 1\ny := 2\n```"
+	if got := formatSelection(selection, workspace); got != want {
+		t.Errorf("formatSelection() = %q, want %q", got, want)
+	}
+}
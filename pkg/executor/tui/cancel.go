@@ -0,0 +1,28 @@
+package tui
+
+import "context"
+
+// commandContext returns the context for work started by slash commands and
+// bash mode, such as generating a commit message, fetching an issue or
+// running a command. It is canceled by /stop and when the session ends.
+// Commands run outside Update, so callers take the context before returning
+// one.
+func (m *model) commandContext() context.Context {
+	if m.commandCtx == nil {
+		parent := m.sessionCtx
+		if parent == nil {
+			parent = context.Background()
+		}
+		m.commandCtx, m.cancelCommands = context.WithCancel(parent)
+	}
+	return m.commandCtx
+}
+
+// stopCommands cancels the slash command and bash mode work in progress.
+// Work started afterwards gets a fresh context.
+func (m *model) stopCommands() {
+	if m.cancelCommands != nil {
+		m.cancelCommands()
+	}
+	m.commandCtx, m.cancelCommands = nil, nil
+}
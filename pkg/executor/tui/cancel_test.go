@@ -0,0 +1,27 @@
+package tui
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStopCommand_CancelsCommandWork(t *testing.T) {
+	session, endSession := context.WithCancel(context.Background())
+	m := initialModel()
+	m.sessionCtx = session
+
+	ctx := m.commandContext()
+	handleStopCommand(&m, nil)
+	if ctx.Err() == nil {
+		t.Fatal("/stop left slash command work running")
+	}
+
+	next := m.commandContext()
+	if next.Err() != nil {
+		t.Fatal("work started after /stop should get a fresh context")
+	}
+	endSession()
+	if next.Err() == nil {
+		t.Fatal("command work outlived the session")
+	}
+}
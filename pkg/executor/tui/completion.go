@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// completionCardStyle frames a structured task_completion result
+var completionCardStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(mintGreen).
+	Padding(0, 1)
+
+// renderCompletionCard renders a task_completion result with structured
+// fields as a card: the summary as its title, the result, then the changed
+// files, commands to run and follow-ups as sections
+func renderCompletionCard(completion *tools.Completion, width int) string {
+	cardWidth := width - 4
+	if cardWidth < 40 {
+		cardWidth = 40
+	}
+	textWidth := cardWidth - 4 // Border and padding
+
+	var b strings.Builder
+	if completion.Summary != "" {
+		b.WriteString(headerStyle.Render(wordWrap("✓ "+completion.Summary, textWidth)) + "\n\n")
+	}
+	b.WriteString(toolResultStyle.Render(wordWrap(strings.TrimSpace(completion.Result), textWidth)))
+
+	section := func(title string, items []string, render func(string) string) {
+		if len(items) == 0 {
+			return
+		}
+		b.WriteString("\n\n" + toolStyle.Bold(true).Render(title))
+		for _, item := range items {
+			b.WriteString("\n" + wordWrap(render(item), textWidth))
+		}
+	}
+	section("Files changed", completion.FilesChanged, func(path string) string {
+		return "  " + fileRefStyle.Render(path)
+	})
+	section("Commands to run", completion.CommandsToRun, func(command string) string {
+		return "  " + bashPromptStyle.Render("$") + " " + command
+	})
+	section("Follow-ups", completion.FollowUps, func(followUp string) string {
+		return "  • " + followUp
+	})
+	if len(completion.FilesChanged) > 0 {
+		b.WriteString("\n\n" + fileRefHintStyle.Render(fmt.Sprintf("Ctrl+R to open the %d changed file(s)", len(completion.FilesChanged))))
+	}
+
+	return completionCardStyle.Width(cardWidth).Render(b.String())
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// stashDraft saves unsent input before the command palette takes over the
+// textarea, so choosing or canceling a command doesn't discard it
+func (m *model) stashDraft() {
+	value := m.textarea.Value()
+	if value != "" && !strings.HasPrefix(value, "/") {
+		m.draft = value
+	}
+}
+
+// restoreDraft puts a stashed draft back into the textarea. It returns false
+// if there was nothing to restore.
+func (m *model) restoreDraft() bool {
+	if m.draft == "" {
+		return false
+	}
+	m.textarea.SetValue(m.draft)
+	m.textarea.CursorEnd()
+	m.draft = ""
+	m.updateTextAreaHeight()
+	return true
+}
+
+// handleCtrlO handles Ctrl+O key press (open the full-screen compose editor)
+func (m *model) handleCtrlO() (tea.Model, tea.Cmd) {
+	compose := overlay.NewComposeOverlay(m.textarea.Value(), m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeCompose, compose)
+	return m, nil
+}
+
+// handleComposeSubmit sends a prompt written in compose mode as if it had been
+// typed into the input box
+func (m *model) handleComposeSubmit(msg tuitypes.ComposeSubmitMsg) (tea.Model, tea.Cmd) {
+	m.textarea.SetValue(msg.Content)
+	return m.handleEnter(nil, nil, nil)
+}
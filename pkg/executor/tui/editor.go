@@ -0,0 +1,295 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// fileRef is a file location mentioned by the agent's tool calls or results
+type fileRef struct {
+	path string // As given by the tool, usually relative to the workspace
+	line int    // 1-based; 0 for the start of the file
+}
+
+// editorClosedMsg reports that the editor opened by openInEditor exited
+type editorClosedMsg struct {
+	err error
+}
+
+// fileRefTools are the tools whose path argument names a file
+var fileRefTools = map[string]bool{
+	"read_file":  true,
+	"write_file": true,
+	"apply_diff": true,
+}
+
+// searchResultFile and searchResultMatch match the file headers and match
+// lines of search_files results
+var (
+	searchResultFile  = regexp.MustCompile(`(?m)^📄 (.+)$`)
+	searchResultMatch = regexp.MustCompile(`(?m)^▶ (\d+) \|`)
+)
+
+// lineRef matches a path:line argument
+var lineRef = regexp.MustCompile(`^(.+):(\d+)$`)
+
+// fileRefFromToolCall returns the file a tool call operates on
+func fileRefFromToolCall(toolName string, input map[string]interface{}) (fileRef, bool) {
+	if !fileRefTools[toolName] {
+		return fileRef{}, false
+	}
+	path, ok := input["path"].(string)
+	if !ok || path == "" {
+		return fileRef{}, false
+	}
+
+	ref := fileRef{path: path}
+	if line, ok := input["start_line"]; ok {
+		ref.line, _ = strconv.Atoi(fmt.Sprint(line))
+	}
+	return ref, true
+}
+
+// fileRefFromResult returns the first file a tool result references: its
+// first artifact, or for tools without artifacts, a file found in its output
+func fileRefFromResult(toolName string, result *tools.Result) (fileRef, bool) {
+	if refs := fileRefsFromArtifacts(result.Artifacts); len(refs) > 0 {
+		return refs[0], true
+	}
+	return fileRefFromToolResult(toolName, result.Output)
+}
+
+// fileRefsFromArtifacts returns the files artifacts reference, in order. A
+// diff without a line is referenced at its first change.
+func fileRefsFromArtifacts(artifacts []tools.Artifact) []fileRef {
+	var refs []fileRef
+	for _, artifact := range artifacts {
+		if artifact.Path == "" {
+			continue
+		}
+		ref := fileRef{path: artifact.Path, line: artifact.Line}
+		if artifact.Kind == tools.ArtifactDiff && ref.line == 0 {
+			if hunk := strings.Index(artifact.Diff, "\n@@ "); hunk >= 0 {
+				index := strings.Count(artifact.Diff[:hunk+1], "\n")
+				_, ref.line, _ = overlay.DiffLocation(artifact.Diff, index)
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// String formats the reference as path or path:line
+func (r fileRef) String() string {
+	if r.line > 0 {
+		return fmt.Sprintf("%s:%d", r.path, r.line)
+	}
+	return r.path
+}
+
+// fileRefFromToolResult returns the first match of a search_files result
+func fileRefFromToolResult(toolName, result string) (fileRef, bool) {
+	if toolName != "search_files" {
+		return fileRef{}, false
+	}
+	file := searchResultFile.FindStringSubmatchIndex(result)
+	if file == nil {
+		return fileRef{}, false
+	}
+
+	ref := fileRef{path: strings.TrimSpace(result[file[2]:file[3]])}
+	if match := searchResultMatch.FindStringSubmatch(result[file[1]:]); match != nil {
+		ref.line, _ = strconv.Atoi(match[1])
+	}
+	return ref, true
+}
+
+// parseFileRef parses an /open argument: a path, optionally followed by
+// :line. A file whose name really ends in :digits is taken as-is.
+func parseFileRef(arg, workspaceDir string) fileRef {
+	if match := lineRef.FindStringSubmatch(arg); match != nil {
+		path := arg
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workspaceDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			line, _ := strconv.Atoi(match[2])
+			return fileRef{path: match[1], line: line}
+		}
+	}
+	return fileRef{path: arg}
+}
+
+// editorName returns the user's editor: $VISUAL, then $EDITOR, then vi
+func editorName() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// editorCommand builds the command that opens path at line in editor, which
+// may include arguments (e.g. "code --wait"). Editors are told the line in
+// the form they understand; most terminal editors accept +line.
+func editorCommand(editor, path string, line int) *exec.Cmd {
+	fields := strings.Fields(editor)
+	name := strings.TrimSuffix(filepath.Base(fields[0]), ".exe")
+	args := fields[1:]
+
+	switch {
+	case line <= 0:
+		args = append(args, path)
+	case name == "code" || name == "code-insiders" || name == "codium" || name == "cursor" || name == "windsurf":
+		args = append(args, "--goto", fmt.Sprintf("%s:%d", path, line))
+	case name == "subl" || name == "zed" || name == "hx" || name == "helix":
+		args = append(args, fmt.Sprintf("%s:%d", path, line))
+	default:
+		args = append(args, fmt.Sprintf("+%d", line), path)
+	}
+	return exec.Command(fields[0], args...)
+}
+
+// openInEditor suspends the TUI and opens a file in the user's editor,
+// resuming when the editor exits. Relative paths are resolved against the
+// workspace.
+func (m *model) openInEditor(ref fileRef) tea.Cmd {
+	path := ref.path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workspaceDir, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		m.showToast("Cannot open file", err.Error(), "❌", true)
+		return nil
+	}
+	if info.IsDir() {
+		m.showToast("Cannot open file", ref.path+" is a directory", "❌", true)
+		return nil
+	}
+
+	return tea.ExecProcess(editorCommand(editorName(), path, ref.line), func(err error) tea.Msg {
+		return editorClosedMsg{err: err}
+	})
+}
+
+// handleCtrlG opens the file most recently referenced by a tool in the editor
+func (m *model) handleCtrlG() (tea.Model, tea.Cmd) {
+	if m.lastFileRef.path == "" {
+		m.showToast("No file", "No file has been referenced yet; use /open <path>", "ℹ️", false)
+		return m, nil
+	}
+	return m, m.openInEditor(m.lastFileRef)
+}
+
+// handleOpenFile opens a file requested by an overlay
+func (m *model) handleOpenFile(msg types.OpenFileMsg) (tea.Model, tea.Cmd) {
+	return m, m.openInEditor(fileRef{path: msg.Path, line: msg.Line})
+}
+
+// handleEditorClosed reports an editor that failed to start or exited with an error
+func (m *model) handleEditorClosed(msg editorClosedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.showToast("Editor failed", fmt.Sprintf("%s: %v (set $EDITOR to choose an editor)", editorName(), msg.err), "❌", true)
+	}
+	return m, nil
+}
+
+// maxFileRefs bounds the references kept for the references list
+const maxFileRefs = 200
+
+// maxInlineFileRefs is how many references are shown beneath a tool result
+const maxInlineFileRefs = 3
+
+// recordFileRefs adds the files a tool result referenced to the references
+// list, newest first. A file referenced again at the same line moves to the
+// front.
+func (m *model) recordFileRefs(toolName string, refs []fileRef) {
+	for _, ref := range refs {
+		for i, existing := range m.fileRefs {
+			if existing.Path == ref.path && existing.Line == ref.line {
+				m.fileRefs = append(m.fileRefs[:i], m.fileRefs[i+1:]...)
+				break
+			}
+		}
+		m.fileRefs = append([]overlay.Reference{{Path: ref.path, Line: ref.line, ToolName: toolName}}, m.fileRefs...)
+	}
+	if len(m.fileRefs) > maxFileRefs {
+		m.fileRefs = m.fileRefs[:maxFileRefs]
+	}
+}
+
+// formatFileRefs renders the files a tool result referenced, e.g.
+// "↳ main.go:12 • util.go (+2 more, Ctrl+R to open)"
+func formatFileRefs(refs []fileRef) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	shown := refs
+	if len(shown) > maxInlineFileRefs {
+		shown = shown[:maxInlineFileRefs]
+	}
+	names := make([]string, len(shown))
+	for i, ref := range shown {
+		names[i] = fileRefStyle.Render(ref.String())
+	}
+
+	hint := "Ctrl+R to open"
+	if more := len(refs) - len(shown); more > 0 {
+		hint = fmt.Sprintf("+%d more, %s", more, hint)
+	}
+	return "↳ " + strings.Join(names, " • ") + " " + fileRefHintStyle.Render("("+hint+")")
+}
+
+// handleCtrlR lists the files tools referenced, to view or edit one
+func (m *model) handleCtrlR() (tea.Model, tea.Cmd) {
+	refs := overlay.NewReferencesOverlay(m.fileRefs, m.width, m.height)
+	m.overlay.activate(types.OverlayModeReferences, refs)
+	return m, nil
+}
+
+// maxViewedFileSize bounds the files shown in the file viewer
+const maxViewedFileSize = 1 << 20
+
+// handleViewFile shows a file in the file viewer at a line. Relative paths
+// are resolved against the workspace.
+func (m *model) handleViewFile(msg types.ViewFileMsg) (tea.Model, tea.Cmd) {
+	path := msg.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workspaceDir, path)
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		m.showToast("Cannot view file", err.Error(), "❌", true)
+		return m, nil
+	case info.IsDir():
+		m.showToast("Cannot view file", msg.Path+" is a directory", "❌", true)
+		return m, nil
+	case info.Size() > maxViewedFileSize:
+		// Too large to show; the editor copes better
+		return m, m.openInEditor(fileRef{path: msg.Path, line: msg.Line})
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		m.showToast("Cannot view file", err.Error(), "❌", true)
+		return m, nil
+	}
+	viewer := overlay.NewFileViewOverlay(msg.Path, string(content), msg.Line, m.width, m.height)
+	m.overlay.activate(types.OverlayModeFileView, viewer)
+	return m, nil
+}
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		editor string
+		line   int
+		want   []string
+	}{
+		{"vim", 12, []string{"vim", "+12", "main.go"}},
+		{"nano", 0, []string{"nano", "main.go"}},
+		{"code --wait", 12, []string{"code", "--wait", "--goto", "main.go:12"}},
+		{"/usr/local/bin/subl", 3, []string{"/usr/local/bin/subl", "main.go:3"}},
+		{"hx", 7, []string{"hx", "main.go:7"}},
+	}
+	for _, tt := range tests {
+		if got := editorCommand(tt.editor, "main.go", tt.line).Args; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("editorCommand(%q, %d) = %v, want %v", tt.editor, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestEditorName(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := editorName(); got != "vi" {
+		t.Errorf("expected vi without $VISUAL or $EDITOR, got %q", got)
+	}
+
+	t.Setenv("EDITOR", "nano")
+	if got := editorName(); got != "nano" {
+		t.Errorf("expected $EDITOR, got %q", got)
+	}
+
+	t.Setenv("VISUAL", "code --wait")
+	if got := editorName(); got != "code --wait" {
+		t.Errorf("expected $VISUAL to take precedence, got %q", got)
+	}
+}
+
+func TestFileRefFromToolCall(t *testing.T) {
+	ref, ok := fileRefFromToolCall("read_file", map[string]interface{}{"path": "pkg/a.go", "start_line": "40"})
+	if !ok || ref != (fileRef{path: "pkg/a.go", line: 40}) {
+		t.Errorf("unexpected ref %+v, %v", ref, ok)
+	}
+
+	ref, ok = fileRefFromToolCall("apply_diff", map[string]interface{}{"path": "pkg/b.go"})
+	if !ok || ref != (fileRef{path: "pkg/b.go"}) {
+		t.Errorf("unexpected ref %+v, %v", ref, ok)
+	}
+
+	if _, ok := fileRefFromToolCall("list_files", map[string]interface{}{"path": "pkg"}); ok {
+		t.Error("expected no ref for a directory listing")
+	}
+}
+
+func TestFileRefFromToolResult(t *testing.T) {
+	result := "📄 pkg/agent/agent.go\n------\n  9 | func a() {\n▶ 10 | \treturn nil\n\nFound 1 matches"
+
+	ref, ok := fileRefFromToolResult("search_files", result)
+	if !ok || ref != (fileRef{path: "pkg/agent/agent.go", line: 10}) {
+		t.Errorf("unexpected ref %+v, %v", ref, ok)
+	}
+	if _, ok := fileRefFromToolResult("read_file", result); ok {
+		t.Error("expected only search_files results to be parsed")
+	}
+}
+
+func TestFileRefFromResult(t *testing.T) {
+	result := &tools.Result{
+		Output:    "📄 other.go\n▶ 3 | x",
+		Artifacts: []tools.Artifact{{Kind: tools.ArtifactFile, Path: "main.go", Line: 7}},
+	}
+	ref, ok := fileRefFromResult("search_files", result)
+	if !ok || ref != (fileRef{path: "main.go", line: 7}) {
+		t.Errorf("expected the first artifact, got %+v, %v", ref, ok)
+	}
+
+	result.Artifacts = nil
+	ref, ok = fileRefFromResult("search_files", result)
+	if !ok || ref != (fileRef{path: "other.go", line: 3}) {
+		t.Errorf("expected the ref parsed from the output without artifacts, got %+v, %v", ref, ok)
+	}
+}
+
+func TestFileRefsFromArtifacts(t *testing.T) {
+	refs := fileRefsFromArtifacts([]tools.Artifact{
+		{Kind: tools.ArtifactFile, Path: "main.go", Line: 3},
+		{Kind: tools.ArtifactDiff, Path: "util.go", Diff: "--- util.go\n+++ util.go\n@@ -7,2 +7,3 @@\n a\n+b\n c\n"},
+		{Kind: tools.ArtifactFile},
+	})
+	want := []fileRef{{path: "main.go", line: 3}, {path: "util.go", line: 7}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("got %+v, want %+v", refs, want)
+	}
+}
+
+func TestRecordFileRefs(t *testing.T) {
+	m := &model{}
+	m.recordFileRefs("read_file", []fileRef{{path: "a.go"}, {path: "b.go", line: 2}})
+	m.recordFileRefs("apply_diff", []fileRef{{path: "a.go"}})
+
+	var got []string
+	for _, ref := range m.fileRefs {
+		got = append(got, ref.String()+" "+ref.ToolName)
+	}
+	if want := []string{"a.go apply_diff", "b.go:2 read_file"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the newest references first without repeats, got %q", got)
+	}
+}
+
+func TestFormatFileRefs(t *testing.T) {
+	got := formatFileRefs([]fileRef{{path: "a.go", line: 1}, {path: "b.go"}, {path: "c.go"}, {path: "d.go"}})
+	for _, want := range []string{"a.go:1", "c.go", "+1 more", "Ctrl+R"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "d.go") {
+		t.Errorf("expected at most %d references inline, got %q", maxInlineFileRefs, got)
+	}
+}
+
+func TestParseFileRef(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "odd:12"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := parseFileRef("main.go:12", dir); got != (fileRef{path: "main.go", line: 12}) {
+		t.Errorf("unexpected ref %+v", got)
+	}
+	if got := parseFileRef("main.go", dir); got != (fileRef{path: "main.go"}) {
+		t.Errorf("unexpected ref %+v", got)
+	}
+	if got := parseFileRef("odd:12", dir); got != (fileRef{path: "odd:12"}) {
+		t.Errorf("expected an existing file name to be kept, got %+v", got)
+	}
+}
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// eventLogRecord is one line of a session event log: an agent event the TUI
+// received, or a message the user sent the agent
+type eventLogRecord struct {
+	Time  time.Time    `json:"time"`
+	Input string       `json:"input,omitempty"`
+	Event *loggedEvent `json:"event,omitempty"`
+}
+
+// loggedEvent is an agent event as an event log records it. Errors keep
+// their message and classification, and tool results and previews keep
+// their types, so the event renders as it did live.
+type loggedEvent struct {
+	Type                 types.AgentEventType        `json:"type"`
+	Content              string                      `json:"content,omitempty"`
+	ToolName             string                      `json:"tool_name,omitempty"`
+	ApprovalID           string                      `json:"approval_id,omitempty"`
+	IsBusy               bool                        `json:"is_busy,omitempty"`
+	Metadata             map[string]interface{}      `json:"metadata,omitempty"`
+	ToolInput            map[string]interface{}      `json:"tool_input,omitempty"`
+	Result               *tools.Result               `json:"result,omitempty"` // ToolOutput when it is a *tools.Result
+	Output               interface{}                 `json:"output,omitempty"` // Any other ToolOutput
+	Error                *loggedError                `json:"error,omitempty"`
+	Preview              *tools.ToolPreview          `json:"preview,omitempty"`
+	TokenUsage           *types.TokenUsage           `json:"token_usage,omitempty"`
+	CommandExecution     *types.CommandExecution     `json:"command_execution,omitempty"`
+	ContextSummarization *types.ContextSummarization `json:"context_summarization,omitempty"`
+	ApiCallInfo          *types.ApiCallInfo          `json:"api_call_info,omitempty"`
+	ToolExecution        *types.ToolExecution        `json:"tool_execution,omitempty"`
+}
+
+// loggedError is the error of a logged event. An error that was classified
+// when it happened keeps its code; others are classified again on replay.
+type loggedError struct {
+	Message   string                 `json:"message"`
+	Code      types.ErrorCode        `json:"code,omitempty"`
+	Retriable bool                   `json:"retriable,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// newLoggedEvent converts event for the event log
+func newLoggedEvent(event *types.AgentEvent) *loggedEvent {
+	logged := &loggedEvent{
+		Type:                 event.Type,
+		Content:              event.Content,
+		ToolName:             event.ToolName,
+		ApprovalID:           event.ApprovalID,
+		IsBusy:               event.IsBusy,
+		Metadata:             event.Metadata,
+		ToolInput:            event.ToolInput,
+		TokenUsage:           event.TokenUsage,
+		CommandExecution:     event.CommandExecution,
+		ContextSummarization: event.ContextSummarization,
+		ApiCallInfo:          event.ApiCallInfo,
+		ToolExecution:        event.ToolExecution,
+	}
+	if result, ok := event.ToolOutput.(*tools.Result); ok {
+		logged.Result = result
+	} else {
+		logged.Output = event.ToolOutput
+	}
+	if preview, ok := event.Preview.(*tools.ToolPreview); ok {
+		logged.Preview = preview
+	}
+	if event.Error != nil {
+		logged.Error = &loggedError{Message: event.Error.Error()}
+		var agentErr *types.AgentError
+		if errors.As(event.Error, &agentErr) {
+			logged.Error.Code = agentErr.Code
+			logged.Error.Retriable = agentErr.Retriable
+			logged.Error.Metadata = agentErr.Metadata
+		}
+	}
+	return logged
+}
+
+// agentEvent converts a logged event back to the agent event it records
+func (e *loggedEvent) agentEvent() *types.AgentEvent {
+	event := &types.AgentEvent{
+		Type:                 e.Type,
+		Content:              e.Content,
+		ToolName:             e.ToolName,
+		ApprovalID:           e.ApprovalID,
+		IsBusy:               e.IsBusy,
+		Metadata:             restoreStringLists(e.Metadata),
+		ToolInput:            e.ToolInput,
+		TokenUsage:           e.TokenUsage,
+		CommandExecution:     e.CommandExecution,
+		ContextSummarization: e.ContextSummarization,
+		ApiCallInfo:          e.ApiCallInfo,
+		ToolExecution:        e.ToolExecution,
+	}
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]interface{})
+	}
+	if e.Result != nil {
+		event.ToolOutput = e.Result
+	} else {
+		event.ToolOutput = e.Output
+	}
+	if e.Preview != nil {
+		event.Preview = e.Preview
+	}
+	if e.Error != nil {
+		cause := errors.New(e.Error.Message)
+		if e.Error.Code != "" {
+			// Without a message the error reads exactly as its cause did
+			event.Error = &types.AgentError{Code: e.Error.Code, Retriable: e.Error.Retriable, Metadata: e.Error.Metadata, Cause: cause}
+		} else {
+			event.Error = cause
+		}
+	}
+	return event
+}
+
+// restoreStringLists turns the lists of strings in metadata, which JSON
+// decodes as []interface{}, back into the []string the handlers expect
+func restoreStringLists(metadata map[string]interface{}) map[string]interface{} {
+	for key, value := range metadata {
+		list, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		strs := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		if len(strs) == len(list) {
+			metadata[key] = strs
+		}
+	}
+	return metadata
+}
+
+// eventLog records a session's events as JSON lines for forge inspect
+type eventLog struct {
+	enc *json.Encoder
+	err error // The first write error, after which nothing more is recorded
+}
+
+// newEventLog returns an event log that writes to w
+func newEventLog(w io.Writer) *eventLog {
+	return &eventLog{enc: json.NewEncoder(w)}
+}
+
+// recordEvent records an agent event the TUI received. A nil log records
+// nothing.
+func (l *eventLog) recordEvent(event *types.AgentEvent) {
+	if l == nil {
+		return
+	}
+	l.write(eventLogRecord{Time: time.Now(), Event: newLoggedEvent(event)})
+}
+
+// recordInput records a message the user sent the agent. A nil log records
+// nothing.
+func (l *eventLog) recordInput(input string) {
+	if l == nil {
+		return
+	}
+	l.write(eventLogRecord{Time: time.Now(), Input: input})
+}
+
+// write appends record to the log, giving up after the first failure
+func (l *eventLog) write(record eventLogRecord) {
+	if l.err != nil {
+		return
+	}
+	if err := l.enc.Encode(record); err != nil {
+		l.err = err
+		debugLog.Printf("Failed to record session event, recording stopped: %v", err)
+	}
+}
+
+// readEventLog reads the records of a session event log
+func readEventLog(r io.Reader) ([]eventLogRecord, error) {
+	dec := json.NewDecoder(r)
+	var records []eventLogRecord
+	for {
+		var record eventLogRecord
+		if err := dec.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		if record.Input == "" && record.Event == nil {
+			return records, fmt.Errorf("record %d: neither an event nor an input", len(records)+1)
+		}
+		records = append(records, record)
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/core"
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/executor/tui/overlay"
 	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -18,6 +20,7 @@ import (
 //nolint:gocyclo
 func (m *model) handleAgentEvent(event *types.AgentEvent) {
 	debugLog.Printf("handleAgentEvent called with event type: %s", event.Type)
+	searchOffset := m.viewport.YOffset
 
 	switch event.Type {
 	case types.EventTypeThinkingStart:
@@ -43,11 +46,35 @@ func (m *model) handleAgentEvent(event *types.AgentEvent) {
 
 	case types.EventTypeToolResult:
 		debugLog.Printf("Processing EventTypeToolResult")
+		m.clearRunningTool()
+		if core.StreamedReply(event) {
+			m.streamedMessageTool = event.ToolName
+		}
 		m.handleToolResult(event)
 
+	case types.EventTypeToolResultError:
+		debugLog.Printf("Processing EventTypeToolResultError")
+		m.clearRunningTool()
+
+	case types.EventTypeToolHeartbeat:
+		m.handleToolHeartbeat(event)
+		return // Heartbeats only touch the loading line, not the viewport
+
+	case types.EventTypeToolProgress:
+		m.handleToolProgress(event)
+		return // Progress only touches the lines beneath the loading indicator
+
+	case types.EventTypeRateLimitWait:
+		m.currentLoadingMessage = rateLimitMessage(types.EventRateLimitWait.Payload(event))
+		return // Rate limit waits only touch the loading line
+
+	case types.EventTypeRateLimitResume:
+		m.currentLoadingMessage = getRandomLoadingMessage()
+		return
+
 	case types.EventTypeMessageStart:
 		debugLog.Printf("Processing EventTypeMessageStart")
-		m.handleMessageStart()
+		m.handleMessageStart(event)
 
 	case types.EventTypeMessageContent:
 		debugLog.Printf("Processing EventTypeMessageContent: %s", event.Content)
@@ -81,7 +108,7 @@ func (m *model) handleAgentEvent(event *types.AgentEvent) {
 
 	case types.EventTypeToolApprovalRejected:
 		debugLog.Printf("Processing EventTypeToolApprovalRejected")
-		m.handleToolApprovalRejected()
+		m.handleToolApprovalRejected(event)
 
 	case types.EventTypeToolApprovalTimeout:
 		m.handleToolApprovalTimeout()
@@ -101,6 +128,11 @@ func (m *model) handleAgentEvent(event *types.AgentEvent) {
 	case types.EventTypeCommandExecutionComplete:
 		m.handleCommandExecutionComplete(event)
 
+	case types.EventTypeCommandExecutionFailed, types.EventTypeCommandExecutionCanceled:
+		if event.CommandExecution != nil {
+			delete(m.runningCommands, event.CommandExecution.ExecutionID)
+		}
+
 	case types.EventTypeContextSummarizationStart:
 		m.handleContextSummarizationStart(event)
 
@@ -109,10 +141,30 @@ func (m *model) handleAgentEvent(event *types.AgentEvent) {
 
 	case types.EventTypeContextSummarizationComplete:
 		m.handleContextSummarizationComplete(event)
+
+	case types.EventTypeContextSummarizationSkipped:
+		m.summarization.active = false
+		m.showToast("Context optimization skipped", "The conversation was left as it was", "⏭️", false)
+
+	case types.EventTypeContextSummarizationError:
+		m.summarization.active = false
+		m.showToast("Context optimization failed", types.EventContextSummarizationError.Payload(event).ErrorMessage, "🧠", true)
+
+	case types.EventTypeInjectionWarning:
+		m.handleInjectionWarning(event)
+
+	case types.EventTypeBudgetExceeded:
+		m.handleBudgetExceeded(event)
 	}
 
-	// Update viewport with current content
-	m.viewport.SetContent(m.content.String())
+	// Update viewport with current content; an open search keeps its
+	// highlighting and scroll position while new output arrives
+	if m.search.active {
+		m.viewport.SetYOffset(searchOffset)
+		m.refreshSearch(false)
+		return
+	}
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 }
 
@@ -127,24 +179,21 @@ func (m *model) handleThinkingContent(event *types.AgentEvent) {
 	if event.Content == "" {
 		return
 	}
-	// Buffer the thinking content
+	// Buffer the thinking content; it is drawn at the next stream frame
 	m.thinkingBuffer.WriteString(event.Content)
-	// Stream with "Thinking" label, content follows immediately
-	header := "💭 Thinking "
-	formatted := formatEntry("", m.thinkingBuffer.String(), thinkingStyle, m.width, false)
-	m.viewport.SetContent(m.content.String() + header + formatted)
-	m.viewport.GotoBottom()
+	m.streamDirty = true
 }
 
 func (m *model) handleThinkingEnd() {
 	if m.thinkingBuffer.Len() > 0 {
 		header := "💭 Thinking "
-		formatted := formatEntry("", m.thinkingBuffer.String(), thinkingStyle, m.width, false)
+		formatted := formatEntry("", m.thinkingBuffer.String(), thinkingStyle, m.transcriptWidth(), false)
 		m.content.WriteString(header + formatted)
 	}
 	m.content.WriteString("\n\n")
 	m.isThinking = false
 	m.thinkingBuffer.Reset()
+	m.streamDirty = false
 }
 
 // Tool event handlers
@@ -152,11 +201,16 @@ func (m *model) handleThinkingEnd() {
 func (m *model) handleToolCallStart(event *types.AgentEvent) {
 	// Check if we have early tool name detection in metadata
 	if toolName, ok := event.Metadata["tool_name"].(string); ok && toolName != "" && !m.toolNameDisplayed {
+		if core.StreamsAsMessage(toolName) {
+			// The reply is streamed as a message; don't announce the tool
+			m.toolNameDisplayed = true
+			return
+		}
 		// Display the tool name immediately when detected early
-		formatted := formatEntry("🔧 ", toolName, toolStyle, m.width, false)
+		formatted := formatEntry("🔧 ", toolName, toolStyle, m.transcriptWidth(), false)
 		m.content.WriteString(formatted)
 		m.content.WriteString("\n")
-		m.viewport.SetContent(m.content.String())
+		m.viewport.ShowTranscript("")
 		m.viewport.GotoBottom()
 		m.toolNameDisplayed = true
 	}
@@ -165,20 +219,60 @@ func (m *model) handleToolCallStart(event *types.AgentEvent) {
 
 func (m *model) handleToolCall(event *types.AgentEvent) {
 	// Only display if we haven't already shown it from early detection
-	if !m.toolNameDisplayed {
-		formatted := formatEntry("🔧 ", event.ToolName, toolStyle, m.width, false)
+	if !m.toolNameDisplayed && !core.StreamsAsMessage(event.ToolName) {
+		formatted := formatEntry("🔧 ", event.ToolName, toolStyle, m.transcriptWidth(), false)
 		m.content.WriteString(formatted)
 		m.content.WriteString("\n")
 	}
 	// Track tool call for result display
 	m.lastToolName = event.ToolName
+	m.activeTool = event.ToolName
+	if ref, ok := fileRefFromToolCall(event.ToolName, event.ToolInput); ok {
+		m.lastFileRef = ref
+		if m.bridge != nil {
+			m.bridge.OpenFile(ref.path, ref.line)
+		}
+	}
 	// Generate a simple cache key using timestamp + tool name
 	m.lastToolCallID = fmt.Sprintf("%d_%s", time.Now().UnixNano(), event.ToolName)
 	m.toolNameDisplayed = false // Reset for next tool call
 }
 
 func (m *model) handleToolResult(event *types.AgentEvent) {
-	resultStr := fmt.Sprintf("%v", event.ToolOutput)
+	result, ok := event.ToolOutput.(*tools.Result)
+	if !ok {
+		result = tools.TextResult(fmt.Sprintf("%v", event.ToolOutput))
+	}
+	resultStr := result.Output
+	if ref, ok := fileRefFromResult(m.lastToolName, result); ok {
+		m.lastFileRef = ref
+	}
+	refs := fileRefsFromArtifacts(result.Artifacts)
+	m.recordFileRefs(m.lastToolName, refs)
+
+	// A reply streamed as a message has been shown already; one that wasn't
+	// is shown the same way
+	if core.StreamsAsMessage(m.lastToolName) {
+		if m.streamedMessageTool != m.lastToolName {
+			m.content.WriteString(renderMarkdown(strings.TrimSpace(resultStr), m.transcriptWidth()))
+			m.content.WriteString("\n\n")
+		}
+		m.streamedMessageTool = ""
+		return
+	}
+
+	// A structured completion gets a card instead of plain text
+	if completion, ok := tools.CompletionFromResult(result); ok && completion.Structured() {
+		m.followUps = completion.FollowUps
+		m.content.WriteString(renderCompletionCard(completion, m.transcriptWidth()))
+		m.content.WriteString("\n\n")
+		return
+	}
+
+	marker := "    ✓ "
+	if !result.Success {
+		marker = "    ⚠ "
+	}
 
 	// Classify the tool result to determine display strategy
 	tier := m.resultClassifier.ClassifyToolResult(m.lastToolName, resultStr)
@@ -186,23 +280,23 @@ func (m *model) handleToolResult(event *types.AgentEvent) {
 	switch tier {
 	case TierFullInline:
 		// Display full result inline (loop-breaking tools)
-		formatted := formatEntry("    ✓ ", resultStr, toolResultStyle, m.width, false)
+		formatted := formatEntry(marker, resultStr, toolResultStyle, m.transcriptWidth(), false)
 		m.content.WriteString(formatted)
 
 	case TierSummaryWithPreview:
 		// Display summary + preview lines
-		summary := m.resultSummarizer.GenerateSummary(m.lastToolName, resultStr)
+		summary := m.resultSummarizer.SummarizeResult(m.lastToolName, result)
 		preview := m.resultClassifier.GetPreviewLines(resultStr)
 		displayText := summary + "\n" + preview
-		formatted := formatEntry("    ✓ ", displayText, toolResultStyle, m.width, false)
+		formatted := formatEntry(marker, displayText, toolResultStyle, m.transcriptWidth(), false)
 		m.content.WriteString(formatted)
 		// Cache the full result for viewing
 		m.resultCache.store(m.lastToolCallID, m.lastToolName, resultStr, summary)
 
 	case TierSummaryOnly:
 		// Display summary only
-		summary := m.resultSummarizer.GenerateSummary(m.lastToolName, resultStr)
-		formatted := formatEntry("    ✓ ", summary, toolResultStyle, m.width, false)
+		summary := m.resultSummarizer.SummarizeResult(m.lastToolName, result)
+		formatted := formatEntry(marker, summary, toolResultStyle, m.transcriptWidth(), false)
 		m.content.WriteString(formatted)
 		// Cache the full result for viewing
 		m.resultCache.store(m.lastToolCallID, m.lastToolName, resultStr, summary)
@@ -212,13 +306,23 @@ func (m *model) handleToolResult(event *types.AgentEvent) {
 		// Don't display anything inline
 	}
 
+	// List the files the result references, to open with Ctrl+R
+	if tier != TierOverlayOnly && len(refs) > 0 {
+		m.content.WriteString("\n")
+		m.content.WriteString(lipgloss.NewStyle().PaddingLeft(6).Width(m.transcriptWidth() - 4).Render(formatFileRefs(refs)))
+	}
+
 	m.content.WriteString("\n\n")
 }
 
 // Message event handlers
 
-func (m *model) handleMessageStart() {
+func (m *model) handleMessageStart(event *types.AgentEvent) {
 	m.messageBuffer.Reset()
+	// A message tool's reply, whose tool result needn't be shown again
+	if toolName, ok := event.Metadata["tool_name"].(string); ok {
+		m.streamedMessageTool = toolName
+	}
 }
 
 func (m *model) handleMessageContent(content string) bool {
@@ -226,13 +330,9 @@ func (m *model) handleMessageContent(content string) bool {
 		m.hasMessageContentStarted = true
 	}
 
-	// Buffer the message content
+	// Buffer the message content; it is drawn at the next stream frame
 	m.messageBuffer.WriteString(content)
-
-	// Stream message content as it arrives
-	formatted := formatEntry("", m.messageBuffer.String(), lipgloss.NewStyle(), m.width, false)
-	m.viewport.SetContent(m.content.String() + formatted)
-	m.viewport.GotoBottom()
+	m.streamDirty = true
 
 	return true
 }
@@ -240,24 +340,31 @@ func (m *model) handleMessageContent(content string) bool {
 func (m *model) handleMessageEnd() {
 	// Finalize message content (like thinking does)
 	if m.messageBuffer.Len() > 0 && m.hasMessageContentStarted {
-		formatted := formatEntry("", m.messageBuffer.String(), lipgloss.NewStyle(), m.width, false)
-		m.content.WriteString(formatted)
+		m.content.WriteString(renderMarkdown(m.messageBuffer.String(), m.transcriptWidth()))
 		m.content.WriteString("\n\n")
 		m.hasMessageContentStarted = false
 	}
 	m.messageBuffer.Reset()
+	m.streamDirty = false
 }
 
 // Error and state handlers
 
 func (m *model) handleError(event *types.AgentEvent) {
 	m.content.WriteString(errorStyle.Render(fmt.Sprintf("  ❌ Error: %v", event.Error)))
+	if guidance := event.AgentError().Guidance("/doctor"); guidance != "" {
+		m.content.WriteString("\n")
+		m.content.WriteString(tipsStyle.Render("     " + guidance))
+	}
 	m.content.WriteString("\n\n")
 }
 
 func (m *model) handleTurnEnd() {
 	// Turn end - clear busy state
 	m.agentBusy = false
+	m.runningToolExecID = ""
+	m.toolProgress = ""
+	m.usage.EndTurn()
 	m.recalculateLayout()
 }
 
@@ -275,28 +382,136 @@ func (m *model) handleUpdateBusy(event *types.AgentEvent) {
 	}
 }
 
+// handleToolHeartbeat shows elapsed time for a long-running tool and remembers
+// its execution ID so Esc can cancel it
+func (m *model) handleToolHeartbeat(event *types.AgentEvent) {
+	if event.ToolExecution == nil {
+		return
+	}
+	m.runningToolExecID = event.ToolExecution.ExecutionID
+
+	elapsed := event.ToolExecution.Elapsed.Round(time.Second)
+	if event.ToolExecution.Timeout > 0 {
+		m.currentLoadingMessage = fmt.Sprintf("Running %s... %v / %v (Esc to cancel tool)", event.ToolName, elapsed, event.ToolExecution.Timeout)
+	} else {
+		m.currentLoadingMessage = fmt.Sprintf("Running %s... %v (Esc to cancel tool)", event.ToolName, elapsed)
+	}
+}
+
+// rateLimitMessage describes a provider call waiting for the rate limit for
+// the loading line
+func rateLimitMessage(wait types.RateLimitPayload) string {
+	seconds := wait.Wait.Round(time.Second)
+	if seconds < time.Second {
+		seconds = time.Second
+	}
+	var message string
+	switch wait.Reason {
+	case types.RateLimitReasonRequests:
+		message = fmt.Sprintf("Waiting for the request rate limit to reset (%v)...", seconds)
+	case types.RateLimitReasonTokens:
+		message = fmt.Sprintf("Waiting for the token rate limit to reset (%v)...", seconds)
+	default:
+		message = fmt.Sprintf("Rate limited by the provider, retrying in %v...", seconds)
+	}
+	if wait.Background {
+		return "Background work: " + message
+	}
+	return message
+}
+
+// cancelRunningTool asks the agent to cancel the running tool without ending the turn
+func (m *model) cancelRunningTool() {
+	if m.runningToolExecID == "" || m.channels == nil {
+		return
+	}
+
+	select {
+	case m.channels.Cancel <- &types.CancellationRequest{ExecutionID: m.runningToolExecID}:
+		m.currentLoadingMessage = "Canceling tool..."
+	default:
+		// Cancel channel full - a cancellation is already pending
+	}
+	m.runningToolExecID = ""
+}
+
+// clearRunningTool forgets the running tool once it finishes and restores the loading message
+func (m *model) clearRunningTool() {
+	m.activeTool = ""
+	if m.runningToolExecID != "" {
+		m.runningToolExecID = ""
+		m.currentLoadingMessage = getRandomLoadingMessage()
+	}
+	m.setToolProgress("")
+}
+
+// handleToolProgress shows a running tool's latest phase description
+func (m *model) handleToolProgress(event *types.AgentEvent) {
+	m.setToolProgress(event.Content)
+}
+
+// setToolProgress updates the progress line, resizing the viewport when it appears or disappears
+func (m *model) setToolProgress(description string) {
+	shown := m.toolProgress != ""
+	m.toolProgress = description
+	if shown != (description != "") {
+		m.recalculateLayout()
+	}
+}
+
+// handleInjectionWarning shows suspected prompt injection found in a tool's output
+func (m *model) handleInjectionWarning(event *types.AgentEvent) {
+	rules, _ := event.Metadata["rules"].([]string)
+	excerpts, _ := event.Metadata["excerpts"].([]string)
+
+	message := fmt.Sprintf("Possible prompt injection in %s output (%s); further actions this turn need your approval",
+		event.ToolName, strings.Join(rules, ", "))
+	m.content.WriteString(formatEntry("  ⚠ ", message, errorStyle, m.transcriptWidth(), false))
+	m.content.WriteString("\n")
+	for _, excerpt := range excerpts {
+		m.content.WriteString(formatEntry("      ", excerpt, thinkingStyle, m.transcriptWidth(), false))
+		m.content.WriteString("\n")
+	}
+
+	m.showToast("Possible prompt injection", fmt.Sprintf("Suspicious instructions in %s output", event.ToolName), "⚠", true)
+}
+
+// handleBudgetExceeded reports that the turn stopped on a loop budget limit
+func (m *model) handleBudgetExceeded(event *types.AgentEvent) {
+	message := fmt.Sprintf("Turn budget exceeded: %v %v of %v. Use /continue to grant another budget and resume.",
+		event.Metadata["limit"], event.Metadata["used"], event.Metadata["max"])
+	m.content.WriteString(formatEntry("  ⏸ ", message, errorStyle, m.transcriptWidth(), false))
+	m.content.WriteString("\n\n")
+}
+
 // Tool approval handlers
 
 func (m *model) handleToolApprovalRequest(event *types.AgentEvent) {
 	// Show "Requesting approval" message before overlay
-	formatted := formatEntry("  ⏳ ", "Requesting tool approval...", toolStyle, m.width, false)
+	formatted := formatEntry("  ⏳ ", "Requesting tool approval...", toolStyle, m.transcriptWidth(), false)
 	m.content.WriteString(formatted)
 	m.content.WriteString("\n")
-	m.viewport.SetContent(m.content.String())
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 
 	// Handle tool approval request by showing overlay
 	if event.Preview != nil {
 		preview, ok := event.Preview.(*tools.ToolPreview)
 		if ok {
+			// Mirror the edit in connected IDE extensions
+			resolveMirror := m.mirrorApproval(event.ApprovalID, preview)
+
 			// Create response callback that will be called by the overlay
 			responseFunc := func(response *types.ApprovalResponse) {
 				// Send approval response to agent
 				m.channels.Approval <- response
+				if resolveMirror != nil {
+					resolveMirror(response)
+				}
 
 				// Close overlay and update viewport
 				m.overlay.deactivate()
-				m.viewport.SetContent(m.content.String())
+				m.viewport.ShowTranscript("")
 				m.viewport.GotoBottom()
 			}
 
@@ -305,6 +520,7 @@ func (m *model) handleToolApprovalRequest(event *types.AgentEvent) {
 				event.ApprovalID,
 				event.ToolName,
 				preview,
+				m.previewRenderers,
 				m.width,
 				m.height,
 				responseFunc,
@@ -316,21 +532,25 @@ func (m *model) handleToolApprovalRequest(event *types.AgentEvent) {
 
 func (m *model) handleToolApprovalGranted() {
 	// Approval granted - show confirmation
-	formatted := formatEntry("  ✓ ", "Tool approved - executing...", toolStyle, m.width, false)
+	formatted := formatEntry("  ✓ ", "Tool approved - executing...", toolStyle, m.transcriptWidth(), false)
 	m.content.WriteString(formatted)
 	m.content.WriteString("\n")
 }
 
-func (m *model) handleToolApprovalRejected() {
-	// Approval rejected - log it
-	formatted := formatEntry("  ✗ ", "Tool rejected by user", errorStyle, m.width, false)
+func (m *model) handleToolApprovalRejected(event *types.AgentEvent) {
+	// Approval rejected - log it along with any reason the user gave
+	message := "Tool rejected by user"
+	if feedback, ok := event.Metadata["feedback"].(string); ok && feedback != "" {
+		message = fmt.Sprintf("Tool rejected by user: %s", feedback)
+	}
+	formatted := formatEntry("  ✗ ", message, errorStyle, m.transcriptWidth(), false)
 	m.content.WriteString(formatted)
 	m.content.WriteString("\n")
 }
 
 func (m *model) handleToolApprovalTimeout() {
 	// Approval timeout - log it
-	formatted := formatEntry("  ⏱ ", "Tool approval timed out", errorStyle, m.width, false)
+	formatted := formatEntry("  ⏱ ", "Tool approval timed out", errorStyle, m.transcriptWidth(), false)
 	m.content.WriteString(formatted)
 	m.content.WriteString("\n")
 }
@@ -351,6 +571,7 @@ func (m *model) handleTokenUsage(event *types.AgentEvent) {
 		m.totalPromptTokens += event.TokenUsage.PromptTokens
 		m.totalCompletionTokens += event.TokenUsage.CompletionTokens
 		m.totalTokens += event.TokenUsage.TotalTokens
+		m.usage.RecordUsage(event.TokenUsage)
 	}
 }
 
@@ -359,10 +580,11 @@ func (m *model) handleTokenUsage(event *types.AgentEvent) {
 func (m *model) handleCommandExecutionStart(event *types.AgentEvent) {
 	// Show command execution started message
 	if event.CommandExecution != nil {
-		formatted := formatEntry("  🚀 ", fmt.Sprintf("Executing: %s", event.CommandExecution.Command), toolStyle, m.width, false)
+		m.runningCommands[event.CommandExecution.ExecutionID] = event.CommandExecution.Command
+		formatted := formatEntry("  🚀 ", fmt.Sprintf("Executing: %s", event.CommandExecution.Command), toolStyle, m.transcriptWidth(), false)
 		m.content.WriteString(formatted)
 		m.content.WriteString("\n")
-		m.viewport.SetContent(m.content.String())
+		m.viewport.ShowTranscript("")
 		m.viewport.GotoBottom()
 
 		// Create and activate command execution overlay
@@ -387,11 +609,12 @@ func (m *model) handleCommandExecutionOutput(event *types.AgentEvent) {
 func (m *model) handleCommandExecutionComplete(event *types.AgentEvent) {
 	// Show command completion status
 	if event.CommandExecution != nil {
+		delete(m.runningCommands, event.CommandExecution.ExecutionID)
 		if event.CommandExecution.ExitCode == 0 {
-			formatted := formatEntry("  ✓ ", "Command completed successfully", toolStyle, m.width, false)
+			formatted := formatEntry("  ✓ ", "Command completed successfully", toolStyle, m.transcriptWidth(), false)
 			m.content.WriteString(formatted)
 		} else {
-			formatted := formatEntry("  ✗ ", fmt.Sprintf("Command failed with exit code %d", event.CommandExecution.ExitCode), errorStyle, m.width, false)
+			formatted := formatEntry("  ✗ ", fmt.Sprintf("Command failed with exit code %d", event.CommandExecution.ExitCode), errorStyle, m.transcriptWidth(), false)
 			m.content.WriteString(formatted)
 		}
 		m.content.WriteString("\n")
@@ -443,3 +666,20 @@ func (m *model) handleContextSummarizationComplete(event *types.AgentEvent) {
 		m.currentContextTokens = newTokens
 	}
 }
+
+// warnDisplacedSession shows that another session is still using the
+// workspace, whose lock this session took over with -ignore-lock
+func (m *model) warnDisplacedSession(holder workspace.LockInfo) {
+	message := fmt.Sprintf("Another Forge session is using this workspace (%s). Its edits and this session's may overwrite each other.", holder)
+	m.content.WriteString(formatEntry("  ⚠ ", message, errorStyle, m.transcriptWidth(), false))
+	m.content.WriteString("\n\n")
+	m.showToast("Workspace in use", fmt.Sprintf("Another session: %s", holder), "⚠", true)
+}
+
+// noteReadOnly explains that the agent can't edit files or run commands in
+// an untrusted workspace
+func (m *model) noteReadOnly() {
+	message := "Read-only mode: this workspace is not trusted, so the agent can read and search files but not edit them or run commands. Restart with -trust to allow them."
+	m.content.WriteString(formatEntry("  🔒 ", message, thinkingStyle, m.transcriptWidth(), false))
+	m.content.WriteString("\n\n")
+}
@@ -15,16 +15,31 @@ package tui
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/entrhq/forge/pkg/agent"
 	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/release"
+	"github.com/entrhq/forge/pkg/agent/review"
+	"github.com/entrhq/forge/pkg/agent/seed"
 	"github.com/entrhq/forge/pkg/agent/slash"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/bridge"
 	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/doctor"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
 	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/issues"
 )
 
+// agentShutdownTimeout bounds how long leaving the TUI waits for the agent's
+// canceled turn to finish
+const agentShutdownTimeout = 5 * time.Second
+
 // Executor is a TUI-based executor that provides an interactive,
 // Gemini-style interface for agent interaction.
 type Executor struct {
@@ -32,15 +47,151 @@ type Executor struct {
 	program      *tea.Program
 	provider     llm.Provider
 	workspaceDir string
+	snapshot     *git.Snapshot
+	diagnostics  *doctor.Options
+	models       llm.ModelLister
+	currentModel string
+	attribution  git.Attribution
+	tracker      *git.ModificationTracker
+	reviewer     *review.Reviewer
+	messageStyle git.MessageStyle
+	issueTracker issues.Tracker
+	bridge       *bridge.Server
+	displaced    *workspace.LockInfo
+	readOnly     bool
+	renderers    overlay.PreviewRenderers
+	eventLog     io.Writer
+	importShare  float64
+}
+
+// ExecutorOption configures optional Executor behavior.
+type ExecutorOption func(*Executor)
+
+// WithWorkspaceSnapshot sets the session-start workspace snapshot used by
+// the /changes command.
+func WithWorkspaceSnapshot(snapshot *git.Snapshot) ExecutorOption {
+	return func(e *Executor) {
+		e.snapshot = snapshot
+	}
+}
+
+// WithDiagnostics sets the configuration checked by the /doctor command.
+func WithDiagnostics(opts doctor.Options) ExecutorOption {
+	return func(e *Executor) {
+		e.diagnostics = &opts
+	}
+}
+
+// WithModels sets the provider the /model command lists models from and the
+// model the session uses. Prices the provider publishes also price /cost.
+func WithModels(lister llm.ModelLister, current string) ExecutorOption {
+	return func(e *Executor) {
+		e.models = lister
+		e.currentModel = current
+	}
+}
+
+// WithCommitAttribution sets the committer identity and trailers used by
+// the /commit command.
+func WithCommitAttribution(attribution git.Attribution) ExecutorOption {
+	return func(e *Executor) {
+		e.attribution = attribution
+	}
+}
+
+// WithCommitMessageStyle sets the style of the messages /commit generates.
+func WithCommitMessageStyle(style git.MessageStyle) ExecutorOption {
+	return func(e *Executor) {
+		e.messageStyle = style
+	}
+}
+
+// WithModificationTracker sets the tracker of files changed this session,
+// shared with the agent; /commit clears it.
+func WithModificationTracker(tracker *git.ModificationTracker) ExecutorOption {
+	return func(e *Executor) {
+		e.tracker = tracker
+	}
+}
+
+// WithReviewer sets the reviewer used by the /review-diff command.
+func WithReviewer(reviewer *review.Reviewer) ExecutorOption {
+	return func(e *Executor) {
+		e.reviewer = reviewer
+	}
+}
+
+// WithIssueTracker sets the tracker the /issue command reads issues from.
+func WithIssueTracker(tracker issues.Tracker) ExecutorOption {
+	return func(e *Executor) {
+		e.issueTracker = tracker
+	}
+}
+
+// WithBridge sets the IDE bridge that mirrors diffs and opened files to
+// connected extensions and receives their selections.
+func WithBridge(server *bridge.Server) ExecutorOption {
+	return func(e *Executor) {
+		e.bridge = server
+	}
+}
+
+// WithDisplacedSession warns at startup that the session took over the
+// workspace lock of another session that is still running.
+func WithDisplacedSession(info workspace.LockInfo) ExecutorOption {
+	return func(e *Executor) {
+		e.displaced = &info
+	}
+}
+
+// WithReadOnly notes at startup that the workspace is untrusted, so the
+// agent can't edit files or run commands.
+func WithReadOnly() ExecutorOption {
+	return func(e *Executor) {
+		e.readOnly = true
+	}
+}
+
+// WithPreviewRenderer registers the renderer the approval overlay shows
+// previews of previewType with, replacing any built-in one. Tools with
+// previews that read better as something other than text or a diff, such
+// as a table of query results, return their own preview type and register
+// a renderer for it here.
+func WithPreviewRenderer(previewType tools.PreviewType, renderer overlay.PreviewRenderer) ExecutorOption {
+	return func(e *Executor) {
+		e.renderers[previewType] = renderer
+	}
+}
+
+// WithEventLog records the events the TUI receives, and the messages the
+// user sends, to w as JSON lines that forge inspect steps through.
+func WithEventLog(w io.Writer) ExecutorOption {
+	return func(e *Executor) {
+		e.eventLog = w
+	}
+}
+
+// WithImportShare sets the share of the context window a document imported
+// with /import may take (default seed.DefaultShare).
+func WithImportShare(share float64) ExecutorOption {
+	return func(e *Executor) {
+		e.importShare = share
+	}
 }
 
 // NewExecutor creates a new TUI executor for the given agent.
-func NewExecutor(agent agent.Agent, provider llm.Provider, workspaceDir string) *Executor {
-	return &Executor{
+func NewExecutor(agent agent.Agent, provider llm.Provider, workspaceDir string, opts ...ExecutorOption) *Executor {
+	e := &Executor{
 		agent:        agent,
 		provider:     provider,
 		workspaceDir: workspaceDir,
+		renderers:    overlay.DefaultPreviewRenderers(),
+		importShare:  seed.DefaultShare,
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	return e
 }
 
 // Run starts the TUI executor and blocks until the user exits.
@@ -49,8 +200,13 @@ func (e *Executor) Run(ctx context.Context) error {
 	initDebugLog()
 	debugLog.Printf("TUI Executor starting...")
 
+	// Everything the session starts ends with it: leaving the TUI cancels the
+	// agent's turn and any slash command work still running
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Start the agent first
-	if err := e.agent.Start(ctx); err != nil {
+	if err := e.agent.Start(sessionCtx); err != nil {
 		return fmt.Errorf("failed to start agent: %w", err)
 	}
 	debugLog.Printf("Agent started successfully")
@@ -65,16 +221,50 @@ func (e *Executor) Run(ctx context.Context) error {
 	m := initialModel()
 	m.agent = e.agent
 	m.channels = e.agent.GetChannels()
+	m.sessionCtx = sessionCtx
 	m.workspaceDir = e.workspaceDir
+	m.snapshot = e.snapshot
+	m.diagnostics = e.diagnostics
+	m.models = e.models
+	m.currentModel = e.currentModel
+	m.reviewer = e.reviewer
+	m.issueTracker = e.issueTracker
+	m.bridge = e.bridge
+	m.previewRenderers = e.renderers
+	m.importShare = e.importShare
+	if e.eventLog != nil {
+		m.eventLog = newEventLog(e.eventLog)
+	}
+	if e.readOnly {
+		m.noteReadOnly()
+	}
+	if e.displaced != nil {
+		m.warnDisplacedSession(*e.displaced)
+	}
 	debugLog.Printf("Model initialized, workspace: %s", e.workspaceDir)
 
+	// Load persistent input history for Up/Down recall
+	if historyPath, err := defaultHistoryPath(); err == nil {
+		history, loadErr := loadInputHistory(historyPath, maxHistoryEntries)
+		if loadErr != nil {
+			debugLog.Printf("Warning: failed to load input history: %v", loadErr)
+		}
+		m.history = history
+	}
+
+	m.tracker = e.tracker
+	if m.tracker == nil && e.workspaceDir != "" {
+		m.tracker = git.NewModificationTracker(e.workspaceDir)
+	}
+
 	// Initialize slash handler for git operations
 	if e.provider != nil && e.workspaceDir != "" {
 		llmClient := newLLMAdapter(e.provider)
-		tracker := git.NewModificationTracker()
-		m.commitGen = git.NewCommitMessageGenerator(llmClient)
+		m.commitGen = git.NewCommitMessageGenerator(llmClient, git.WithMessageStyle(e.messageStyle))
 		m.prGen = git.NewPRGenerator(llmClient)
-		m.slashHandler = slash.NewHandler(e.workspaceDir, tracker, m.commitGen, m.prGen)
+		m.releaseNotes = release.NewNotesGenerator(llmClient)
+		m.importer = seed.NewImporter(e.provider)
+		m.slashHandler = slash.NewHandler(e.workspaceDir, m.tracker, m.commitGen, m.prGen, e.attribution)
 	}
 
 	e.program = tea.NewProgram(
@@ -91,9 +281,32 @@ func (e *Executor) Run(ctx context.Context) error {
 		}
 	}()
 
-	if _, err := e.program.Run(); err != nil {
-		return fmt.Errorf("failed to run TUI program: %w", err)
+	if e.bridge != nil {
+		go func() {
+			// Forward IDE selections to the TUI
+			for selection := range e.bridge.Selections() {
+				e.program.Send(bridgeSelectionMsg{selection: selection})
+			}
+		}()
+	}
+
+	_, runErr := e.program.Run()
+	cancel()
+	e.shutdown(ctx)
+	if runErr != nil {
+		return fmt.Errorf("failed to run TUI program: %w", runErr)
 	}
 
 	return nil
 }
+
+// shutdown stops the agent, waiting a few seconds for the canceled turn to
+// finish so it doesn't outlive the session
+func (e *Executor) shutdown(ctx context.Context) {
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), agentShutdownTimeout)
+	defer cancel()
+
+	if err := e.agent.Shutdown(shutdownCtx); err != nil {
+		debugLog.Printf("Warning: agent shutdown: %v", err)
+	}
+}
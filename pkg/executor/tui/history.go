@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistoryEntries caps how many submitted inputs are kept in the history file
+const maxHistoryEntries = 1000
+
+// inputHistory is the list of previously submitted inputs, recalled with
+// Up/Down when the input is empty. Entries are deduplicated (re-submitting an
+// input moves it to the end) and persisted one JSON string per line so
+// multi-line inputs survive the round trip.
+type inputHistory struct {
+	entries    []string // Oldest first
+	path       string   // History file; empty keeps history in memory only
+	maxEntries int
+	cursor     int // Index of the recalled entry; len(entries) when not navigating
+}
+
+// newInputHistory creates an empty history that is persisted to path (if set)
+func newInputHistory(path string, maxEntries int) *inputHistory {
+	return &inputHistory{
+		path:       path,
+		maxEntries: maxEntries,
+	}
+}
+
+// loadInputHistory reads the history file at path. A missing file yields an
+// empty history; malformed lines are skipped.
+func loadInputHistory(path string, maxEntries int) (*inputHistory, error) {
+	h := newInputHistory(path, maxEntries)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return h, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var entry string
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		h.append(entry)
+	}
+	h.cursor = len(h.entries)
+
+	if err := scanner.Err(); err != nil {
+		return h, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return h, nil
+}
+
+// defaultHistoryPath returns ~/.forge/history
+func defaultHistoryPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".forge", "history"), nil
+}
+
+// add records a submitted input, resets navigation and persists the history
+func (h *inputHistory) add(entry string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+
+	h.append(entry)
+	h.cursor = len(h.entries)
+	return h.save()
+}
+
+// append adds entry at the end, dropping any earlier duplicate and the oldest
+// entries beyond the size cap
+func (h *inputHistory) append(entry string) {
+	for i, existing := range h.entries {
+		if existing == entry {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	h.entries = append(h.entries, entry)
+	if h.maxEntries > 0 && len(h.entries) > h.maxEntries {
+		h.entries = h.entries[len(h.entries)-h.maxEntries:]
+	}
+}
+
+// previous moves to the next older entry. Returns false at the oldest entry.
+func (h *inputHistory) previous() (string, bool) {
+	if h.cursor == 0 || len(h.entries) == 0 {
+		return "", false
+	}
+	h.cursor--
+	return h.entries[h.cursor], true
+}
+
+// next moves to the next newer entry. Moving past the newest entry leaves
+// navigation and returns an empty input. Returns false when not navigating.
+func (h *inputHistory) next() (string, bool) {
+	if !h.navigating() {
+		return "", false
+	}
+	h.cursor++
+	if h.cursor == len(h.entries) {
+		return "", true
+	}
+	return h.entries[h.cursor], true
+}
+
+// navigating reports whether an entry is currently recalled
+func (h *inputHistory) navigating() bool {
+	return h.cursor < len(h.entries)
+}
+
+// current returns the recalled entry, if any
+func (h *inputHistory) current() string {
+	if !h.navigating() {
+		return ""
+	}
+	return h.entries[h.cursor]
+}
+
+// reset leaves navigation without changing the entries
+func (h *inputHistory) reset() {
+	h.cursor = len(h.entries)
+}
+
+// save writes the history file atomically
+func (h *inputHistory) save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	var builder strings.Builder
+	for _, entry := range h.entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+		builder.Write(line)
+		builder.WriteString("\n")
+	}
+
+	tempPath := h.path + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(builder.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tempPath, h.path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+	return nil
+}
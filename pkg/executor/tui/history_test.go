@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInputHistoryDedupAndCap(t *testing.T) {
+	h := newInputHistory("", 3)
+	for _, entry := range []string{"one", "two", "one", "three", "  ", "four"} {
+		if err := h.add(entry); err != nil {
+			t.Fatalf("add(%q) error = %v", entry, err)
+		}
+	}
+
+	expected := []string{"one", "three", "four"}
+	if !reflect.DeepEqual(h.entries, expected) {
+		t.Errorf("entries = %v, want %v", h.entries, expected)
+	}
+}
+
+func TestInputHistoryNavigation(t *testing.T) {
+	h := newInputHistory("", 10)
+	_ = h.add("first")
+	_ = h.add("second")
+
+	if _, ok := h.next(); ok {
+		t.Error("next() should do nothing when not navigating")
+	}
+
+	steps := []struct {
+		move     func() (string, bool)
+		expected string
+		ok       bool
+	}{
+		{h.previous, "second", true},
+		{h.previous, "first", true},
+		{h.previous, "", false}, // Already at the oldest entry
+		{h.next, "second", true},
+		{h.next, "", true}, // Past the newest entry returns to an empty input
+	}
+	for i, step := range steps {
+		entry, ok := step.move()
+		if entry != step.expected || ok != step.ok {
+			t.Errorf("step %d = (%q, %v), want (%q, %v)", i, entry, ok, step.expected, step.ok)
+		}
+	}
+	if h.navigating() {
+		t.Error("navigation should end after moving past the newest entry")
+	}
+}
+
+func TestInputHistoryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".forge", "history")
+
+	h, err := loadInputHistory(path, 10)
+	if err != nil {
+		t.Fatalf("loadInputHistory() on missing file error = %v", err)
+	}
+	_ = h.add("fix the tests")
+	_ = h.add("line one\nline two")
+
+	loaded, err := loadInputHistory(path, 10)
+	if err != nil {
+		t.Fatalf("loadInputHistory() error = %v", err)
+	}
+	expected := []string{"fix the tests", "line one\nline two"}
+	if !reflect.DeepEqual(loaded.entries, expected) {
+		t.Errorf("loaded entries = %v, want %v", loaded.entries, expected)
+	}
+	if entry, _ := loaded.previous(); entry != "line one\nline two" {
+		t.Errorf("previous() after load = %q, want the newest entry", entry)
+	}
+}
@@ -5,9 +5,9 @@ import (
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
-	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/metrics"
 	"github.com/entrhq/forge/pkg/executor/tui/overlay"
 )
 
@@ -27,7 +27,8 @@ func initialModel() model {
 	ta.FocusedStyle.Prompt = lipgloss.NewStyle().Foreground(salmonPink)
 	ta.FocusedStyle.Text = lipgloss.NewStyle().Foreground(brightWhite)
 
-	vp := viewport.New(80, 20)
+	content := newTranscript()
+	vp := newTranscriptView(80, 20, content)
 	vp.Style = lipgloss.NewStyle().Padding(0, 2)
 
 	s := spinner.New()
@@ -47,24 +48,33 @@ func initialModel() model {
 	return model{
 		viewport:         vp,
 		textarea:         ta,
-		content:          &strings.Builder{},
+		content:          content,
 		thinkingBuffer:   &strings.Builder{},
 		messageBuffer:    &strings.Builder{},
 		overlay:          newOverlayState(),
 		commandPalette:   overlay.NewCommandPalette(cmdItems),
 		summarization:    &summarizationStatus{},
 		toast:            &toastNotification{},
+		search:           &transcriptSearch{},
+		history:          newInputHistory("", maxHistoryEntries),
 		spinner:          s,
 		agentBusy:        false,
 		resultClassifier: NewToolResultClassifier(),
 		resultSummarizer: NewToolResultSummarizer(),
 		resultCache:      newResultCache(20),
 		resultList:       overlay.NewResultListModel(),
+		usage:            metrics.NewCollector(nil),
+		runningCommands:  make(map[string]string),
 	}
 }
 
 // Init is the first function that will be called by Bubble Tea.
-// It returns commands to start the textarea blink animation and spinner.
+// It returns commands to start the textarea blink animation and spinner,
+// and to list the provider's models for /model and /cost.
 func (m *model) Init() tea.Cmd {
-	return tea.Batch(textarea.Blink, m.spinner.Tick)
+	cmds := []tea.Cmd{textarea.Blink, m.spinner.Tick}
+	if m.models != nil {
+		cmds = append(cmds, m.listModels(false, ""))
+	}
+	return tea.Batch(cmds...)
 }
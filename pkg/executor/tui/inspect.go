@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Inspect steps through a session event log recorded with WithEventLog,
+// showing the TUI as it was after each event, to reproduce what a user saw.
+// It blocks until the user quits.
+func Inspect(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	records, err := readEventLog(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read event log %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("event log %s has no events", path)
+	}
+
+	initDebugLog()
+	program := tea.NewProgram(newInspector(records), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := program.Run(); err != nil {
+		return fmt.Errorf("failed to run inspector: %w", err)
+	}
+	return nil
+}
+
+// inspector replays an event log into a fresh TUI model, one record at a
+// time. Stepping forward applies the next records to the current model;
+// stepping back replays the log from the start.
+type inspector struct {
+	records []eventLogRecord
+	pos     int    // Records replayed; 0 is the session before its first
+	view    *model // The TUI after the first pos records
+	width   int
+	height  int
+}
+
+// newInspector returns an inspector at the start of records
+func newInspector(records []eventLogRecord) *inspector {
+	return &inspector{records: records}
+}
+
+// Init implements tea.Model
+func (i *inspector) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (i *inspector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		i.width, i.height = msg.Width, msg.Height
+		i.seek(i.pos, true)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return i, tea.Quit
+		case "right", "l", " ":
+			i.seek(i.pos+1, false)
+		case "left", "h":
+			i.seek(i.pos-1, false)
+		case "]":
+			i.seek(i.nextTurn(), false)
+		case "[":
+			i.seek(i.previousTurn(), false)
+		case "home", "g":
+			i.seek(0, false)
+		case "end", "G":
+			i.seek(len(i.records), false)
+		case "up", "k", "down", "j", "pgup", "pgdown":
+			if i.view != nil {
+				i.view.viewport, _ = i.view.viewport.Update(msg)
+			}
+		}
+
+	case tea.MouseMsg:
+		if i.view != nil {
+			i.view.viewport, _ = i.view.viewport.Update(msg)
+		}
+	}
+	return i, nil
+}
+
+// seek shows the TUI after the first pos records, replaying from the start
+// when stepping back or when rebuild is set
+func (i *inspector) seek(pos int, rebuild bool) {
+	pos = max(0, min(pos, len(i.records)))
+	if i.view == nil || rebuild || pos < i.pos {
+		m := initialModel()
+		m.channels = types.NewAgentChannels(1) // Nothing reads them; the log stands in for the agent
+		m.Update(tea.WindowSizeMsg{Width: i.width, Height: max(i.height-1, 1)})
+		i.view, i.pos = &m, 0
+	}
+	for ; i.pos < pos; i.pos++ {
+		i.view.replayRecord(i.records[i.pos])
+	}
+	if i.view.streamDirty {
+		i.view.renderStream()
+	}
+}
+
+// nextTurn is the position just past the next turn end
+func (i *inspector) nextTurn() int {
+	for pos := i.pos + 1; pos < len(i.records); pos++ {
+		if isTurnEnd(i.records[pos-1]) {
+			return pos
+		}
+	}
+	return len(i.records)
+}
+
+// previousTurn is the position just past the turn end before the current
+// position
+func (i *inspector) previousTurn() int {
+	for pos := i.pos - 1; pos > 0; pos-- {
+		if isTurnEnd(i.records[pos-1]) {
+			return pos
+		}
+	}
+	return 0
+}
+
+// isTurnEnd reports whether record ends an agent turn
+func isTurnEnd(record eventLogRecord) bool {
+	return record.Event != nil && record.Event.Type == types.EventTypeTurnEnd
+}
+
+// replayRecord applies a recorded input or event to the model as the TUI
+// handled it live
+func (m *model) replayRecord(record eventLogRecord) {
+	if record.Event != nil {
+		m.Update(record.Event.agentEvent())
+		return
+	}
+	m.showUserMessage(record.Input)
+}
+
+// View implements tea.Model: the replayed TUI above a status line naming
+// the record shown
+func (i *inspector) View() string {
+	if i.view == nil {
+		return "Loading event log..."
+	}
+	status := fmt.Sprintf("Event %d/%d", i.pos, len(i.records))
+	if i.pos > 0 {
+		record := i.records[i.pos-1]
+		status += " · " + record.Time.Format("15:04:05.000") + " · " + describeRecord(record)
+	} else {
+		status += " · session start"
+	}
+	status = truncateLine(status, max(i.width-62, 20))
+	help := "←/→ step · [/] turn · g/G start/end · ↑/↓ scroll · q quit"
+	return i.view.View() + "\n" + headerStyle.Render(status) + "  " + tipsStyle.Render(help)
+}
+
+// describeRecord names a record for the inspector's status line
+func describeRecord(record eventLogRecord) string {
+	if record.Event == nil {
+		return "user message: " + record.Input
+	}
+	description := string(record.Event.Type)
+	if record.Event.ToolName != "" {
+		description += " (" + record.Event.ToolName + ")"
+	}
+	return description
+}
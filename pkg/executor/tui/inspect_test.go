@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// recordSession records a short session: a message, a tool call and its
+// result, a reply and a classified error
+func recordSession(t *testing.T) []eventLogRecord {
+	t.Helper()
+	var buf bytes.Buffer
+	l := newEventLog(&buf)
+	l.recordInput("List the files")
+	l.recordEvent(types.NewToolCallEvent("list_files", map[string]interface{}{"path": "."}))
+	l.recordEvent(types.NewToolResultEvent("list_files", &tools.Result{Success: true, Summary: "3 files", Output: "a.go\nb.go\nc.go"}))
+	l.recordEvent(types.NewMessageStartEvent())
+	l.recordEvent(types.NewMessageContentEvent("There are **three** files."))
+	l.recordEvent(types.NewMessageEndEvent())
+	l.recordEvent(types.NewTurnEndEvent())
+	l.recordInput("Again")
+	l.recordEvent(types.NewErrorEvent(&types.AgentError{Code: types.ErrorCodeRateLimited, Message: "slow down"}))
+	l.recordEvent(types.NewTurnEndEvent())
+
+	records, err := readEventLog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestEventLogRoundTrip(t *testing.T) {
+	records := recordSession(t)
+	if len(records) != 10 {
+		t.Fatalf("expected 10 records, got %d", len(records))
+	}
+	if records[0].Input != "List the files" {
+		t.Errorf("expected the first record to be the message, got %+v", records[0])
+	}
+
+	result := records[2].Event.agentEvent()
+	if r, ok := result.ToolOutput.(*tools.Result); !ok || r.Summary != "3 files" {
+		t.Errorf("expected the tool result to keep its type, got %#v", result.ToolOutput)
+	}
+
+	failed := records[8].Event.agentEvent()
+	var agentErr *types.AgentError
+	if !errors.As(failed.Error, &agentErr) || agentErr.Code != types.ErrorCodeRateLimited {
+		t.Errorf("expected the error to keep its code, got %#v", failed.Error)
+	}
+	if failed.Error.Error() != "rate_limited: slow down" {
+		t.Errorf("expected the error to read as it did, got %q", failed.Error.Error())
+	}
+
+	if _, err := readEventLog(strings.NewReader("{\"time\":\"2024-01-01T00:00:00Z\"}\n")); err == nil {
+		t.Error("expected a record without an event or input to be rejected")
+	}
+}
+
+func TestInspectorStepsThroughSession(t *testing.T) {
+	if debugLog == nil {
+		debugLog = log.New(io.Discard, "", 0)
+	}
+	i := newInspector(recordSession(t))
+	i.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	key := func(k string) {
+		i.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+
+	if i.pos != 0 || strings.Contains(stripANSI(i.View()), "List the files") {
+		t.Fatal("expected the inspector to start before the first record")
+	}
+
+	key("]")
+	if i.pos != 7 {
+		t.Fatalf("expected ] to move past the first turn, got position %d", i.pos)
+	}
+	first := stripANSI(i.View())
+	if !strings.Contains(first, "List the files") || !strings.Contains(first, "three") {
+		t.Errorf("expected the first turn to be shown, got\n%s", first)
+	}
+	if !strings.Contains(first, "Event 7/10") || !strings.Contains(first, "turn_end") {
+		t.Errorf("expected the status line to name the record, got\n%s", first)
+	}
+
+	key("G")
+	if !strings.Contains(stripANSI(i.View()), "slow down") {
+		t.Error("expected the end of the log to show the error")
+	}
+
+	// Stepping back replays the log, showing the same TUI as before
+	key("[")
+	if i.pos != 7 {
+		t.Fatalf("expected [ to move back to the end of the first turn, got position %d", i.pos)
+	}
+	if got := stripANSI(i.View()); strings.Contains(got, "slow down") || !strings.Contains(got, "three") {
+		t.Errorf("expected stepping back to undo the second turn, got\n%s", got)
+	}
+
+	key("h")
+	if i.pos != 6 {
+		t.Errorf("expected h to step back one record, got position %d", i.pos)
+	}
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+)
+
+var (
+	// inlineCodeStyle renders `code` spans in messages
+	inlineCodeStyle = lipgloss.NewStyle().Foreground(mintGreen)
+
+	// boldStyle renders **bold** spans in messages
+	boldStyle = lipgloss.NewStyle().Bold(true)
+
+	// inlineMarkdownPattern matches the inline spans renderMarkdown styles
+	inlineMarkdownPattern = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*")
+)
+
+// renderMarkdown renders the Markdown of an assistant message for the
+// transcript: fenced code blocks are highlighted and kept unwrapped,
+// headings and list bullets are styled, and inline code and bold spans are
+// styled after wrapping. A code block still being streamed is rendered as
+// code up to where it has arrived.
+func renderMarkdown(text string, width int) string {
+	wrapWidth := width - 4
+	if wrapWidth <= 0 {
+		wrapWidth = 80
+	}
+
+	var out []string
+	var code []string
+	inFence := false
+	lang := ""
+	flush := func() {
+		source := strings.Join(code, "\n")
+		highlighted, err := syntax.HighlightCode(source, lang)
+		if err != nil || lang == "" {
+			highlighted = source
+		}
+		out = append(out, strings.TrimRight(highlighted, "\n"))
+		code = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inFence {
+				flush()
+				inFence = false
+			} else {
+				inFence = true
+				lang = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			}
+			continue
+		}
+		if inFence {
+			code = append(code, line)
+			continue
+		}
+		out = append(out, renderMarkdownLine(line, wrapWidth))
+	}
+	if inFence && len(code) > 0 {
+		flush()
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderMarkdownLine renders a line outside code blocks
+func renderMarkdownLine(line string, width int) string {
+	trimmed := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(trimmed)]
+
+	if level := headingLevel(trimmed); level > 0 {
+		title := strings.TrimSpace(trimmed[level:])
+		return headerStyle.Render(wordWrap(title, width))
+	}
+	if strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") {
+		line = indent + "• " + trimmed[2:]
+	}
+
+	wrapped := strings.Split(wordWrap(line, width), "\n")
+	for i, l := range wrapped {
+		wrapped[i] = renderInlineMarkdown(l)
+	}
+	return strings.Join(wrapped, "\n")
+}
+
+// headingLevel returns the number of '#' an ATX heading starts with, or 0
+// if line isn't a heading
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && level < 6 && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level == len(line) || line[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// renderInlineMarkdown styles the inline code and bold spans of a line,
+// dropping their markers
+func renderInlineMarkdown(line string) string {
+	return inlineMarkdownPattern.ReplaceAllStringFunc(line, func(span string) string {
+		if strings.HasPrefix(span, "`") {
+			return inlineCodeStyle.Render(span[1 : len(span)-1])
+		}
+		return boldStyle.Render(span[2 : len(span)-2])
+	})
+}
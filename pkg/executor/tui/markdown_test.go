@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	text := "## Plan\n\nRun `go test` first, it is **important**.\n\n- one\n* two\n\n```go\nfunc main() {}\n```"
+	got := stripANSI(renderMarkdown(text, 100))
+
+	for _, want := range []string{"Plan\n", "Run go test first, it is important.", "• one\n• two", "func main() {}"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	for _, marker := range []string{"##", "`", "**", "```"} {
+		if strings.Contains(got, marker) {
+			t.Errorf("expected %q to be rendered away, got:\n%s", marker, got)
+		}
+	}
+}
+
+func TestRenderMarkdown_UnclosedFence(t *testing.T) {
+	// A code block still being streamed is shown as code so far
+	got := stripANSI(renderMarkdown("Here:\n```python\nprint('hi')", 100))
+	if !strings.Contains(got, "print('hi')") || strings.Contains(got, "```") {
+		t.Errorf("expected the partial code block without its fence, got:\n%s", got)
+	}
+}
+
+func TestConverseReplyShownAsMessage(t *testing.T) {
+	m := newStreamModel()
+
+	call := types.NewToolCallStartEvent()
+	call.Metadata["tool_name"] = "converse"
+	m.handleToolCallStart(call)
+	start := types.NewMessageStartEvent()
+	start.Metadata["tool_name"] = "converse"
+	m.handleMessageStart(start)
+	m.handleMessageContent("Use `make test`.")
+	m.handleMessageEnd()
+	m.handleToolCall(types.NewToolCallEvent("converse", nil))
+	m.handleToolResult(types.NewToolResultEvent("converse", tools.TextResult("Use `make test`.")))
+
+	got := stripANSI(m.content.String())
+	if strings.Count(got, "Use make test.") != 1 {
+		t.Errorf("expected the reply once, rendered as Markdown, got:\n%s", got[len(got)-200:])
+	}
+	if strings.Contains(got, "🔧") || strings.Contains(got, "✓") {
+		t.Errorf("expected no tool line or result for the reply, got:\n%s", got[len(got)-200:])
+	}
+}
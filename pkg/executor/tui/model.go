@@ -1,17 +1,26 @@
 package tui
 
 import (
+	"context"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
-	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/explain"
 	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/agent/release"
+	"github.com/entrhq/forge/pkg/agent/review"
+	"github.com/entrhq/forge/pkg/agent/seed"
 	"github.com/entrhq/forge/pkg/agent/slash"
+	"github.com/entrhq/forge/pkg/bridge"
+	"github.com/entrhq/forge/pkg/doctor"
 	"github.com/entrhq/forge/pkg/executor/tui/approval"
 	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/tools/issues"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -19,7 +28,7 @@ import (
 // It contains all components needed for the interactive terminal interface.
 type model struct {
 	// Bubble Tea components
-	viewport viewport.Model
+	viewport transcriptView
 	textarea textarea.Model
 	spinner  spinner.Model
 
@@ -32,9 +41,32 @@ type model struct {
 	workspaceDir string
 	commitGen    *git.CommitMessageGenerator
 	prGen        *git.PRGenerator
+	releaseNotes *release.NotesGenerator
+	importer     *seed.Importer           // Fits documents for /import; nil without a provider
+	importShare  float64                  // Share of the context window an imported document may take
+	snapshot     *git.Snapshot            // Workspace state at session start, for /changes
+	tracker      *git.ModificationTracker // Files changed this session, for the session panel
+	diagnostics  *doctor.Options          // Configuration checked by /doctor
+	reviewer     *review.Reviewer         // Reviews diff ranges for /review-diff
+	issueTracker issues.Tracker           // Source of /issue task definitions
+	bridge       *bridge.Server           // Connected IDE extensions; nil without --bridge
+	models       llm.ModelLister          // Lists the provider's models for /model; nil if it can't
+	currentModel string                   // Model the agent uses
+	modelList    []llm.Model              // The provider's models, once listed
+
+	// Renderers of tool previews in the approval overlay, by preview type
+	previewRenderers overlay.PreviewRenderers
+
+	// Records the session's events for forge inspect; nil unless -record
+	eventLog *eventLog
+
+	// Cancellation of work started outside the agent
+	sessionCtx     context.Context    // Ends when the TUI exits
+	commandCtx     context.Context    // Slash command and bash mode work; see commandContext
+	cancelCommands context.CancelFunc // Ends commandCtx, for /stop
 
 	// Content buffers
-	content        *strings.Builder
+	content        *transcript
 	thinkingBuffer *strings.Builder
 	messageBuffer  *strings.Builder
 
@@ -43,28 +75,41 @@ type model struct {
 	commandPalette *overlay.CommandPalette
 	summarization  *summarizationStatus
 	toast          *toastNotification
+	search         *transcriptSearch
+	history        *inputHistory
+	draft          string // Unsent input stashed while the command palette borrows the textarea
 
 	// Agent state
 	isThinking            bool
 	agentBusy             bool
 	bashMode              bool // Track if in bash mode
 	currentLoadingMessage string
-	toolNameDisplayed     bool // Track if we've already displayed the tool name
+	toolNameDisplayed     bool              // Track if we've already displayed the tool name
+	streamedMessageTool   string            // Message tool whose reply was streamed, e.g. converse
+	activeTool            string            // Tool called and not yet finished, for the session panel
+	runningCommands       map[string]string // Commands running, by execution ID, for the session panel
+	followUps             []string          // Follow-ups of the last structured task completion
+	runningToolExecID     string            // Execution ID of the tool currently running (from heartbeats)
+	toolProgress          string            // Latest phase description from the running tool
 
 	// Window dimensions
-	width  int
-	height int
-	ready  bool
+	width     int
+	height    int
+	ready     bool
+	showPanel bool // Session panel toggled on with Ctrl+T
 
 	// Message state
 	hasMessageContentStarted bool
+	streamDirty              bool // Streamed text has arrived since the last stream frame
+	streamFramePending       bool // A stream frame is scheduled
 
 	// Token usage tracking
-	totalPromptTokens     int // Cumulative input tokens across all API calls
-	totalCompletionTokens int // Cumulative output tokens across all API calls
-	totalTokens           int // Cumulative total tokens (input + output)
-	currentContextTokens  int // Current conversation context size
-	maxContextTokens      int // Maximum allowed context size
+	totalPromptTokens     int                // Cumulative input tokens across all API calls
+	totalCompletionTokens int                // Cumulative output tokens across all API calls
+	totalTokens           int                // Cumulative total tokens (input + output)
+	currentContextTokens  int                // Current conversation context size
+	maxContextTokens      int                // Maximum allowed context size
+	usage                 *metrics.Collector // Per-turn usage and cost for /cost
 
 	// Tool result display
 	resultClassifier *ToolResultClassifier
@@ -73,6 +118,8 @@ type model struct {
 	resultList       overlay.ResultListModel // Result history list overlay
 	lastToolCallID   string                  // Track the last tool call for 'v' shortcut
 	lastToolName     string                  // Track the last tool name
+	lastFileRef      fileRef                 // File most recently referenced by a tool, for Ctrl+G
+	fileRefs         []overlay.Reference     // Files tool results referenced, newest first, for Ctrl+R
 
 	// Application state
 	shouldQuit bool // Flag to trigger application exit
@@ -104,6 +151,44 @@ type operationCompleteMsg struct {
 	errorIcon    string
 }
 
+// doctorResultMsg carries the results of the /doctor checks
+type doctorResultMsg struct {
+	results []doctor.Result
+}
+
+// modelsListedMsg carries the provider's models; show opens them for /model,
+// filtered by filter
+type modelsListedMsg struct {
+	models []llm.Model
+	err    error
+	show   bool
+	filter string
+}
+
+// documentImportedMsg carries a document prepared by /import
+type documentImportedMsg struct {
+	doc *seed.Document
+	err error
+}
+
+// reviewResultMsg carries the outcome of a /review-diff review
+type reviewResultMsg struct {
+	result *review.Result
+	err    error
+}
+
+// moduleMapBuiltMsg carries the module map built by /explain
+type moduleMapBuiltMsg struct {
+	moduleMap *explain.Map
+	err       error
+}
+
+// issueLoadedMsg carries the issue fetched by /issue
+type issueLoadedMsg struct {
+	issue *issues.Issue
+	err   error
+}
+
 // toastMsg triggers a toast notification
 type toastMsg struct {
 	message string
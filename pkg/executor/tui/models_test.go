@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// stubLister lists fixed models
+type stubLister struct {
+	models []llm.Model
+	calls  int
+}
+
+func (s *stubLister) ListModels(ctx context.Context) ([]llm.Model, error) {
+	s.calls++
+	return s.models, nil
+}
+
+func TestModelCommand(t *testing.T) {
+	lister := &stubLister{models: []llm.Model{
+		{ID: "gateway/priced", ContextWindow: 128000, InputPrice: 2, OutputPrice: 8},
+		{ID: "gateway/unpriced"},
+	}}
+	m := initialModel()
+	m.sessionCtx = context.Background()
+	m.models = lister
+	m.currentModel = "gateway/priced"
+
+	cmd, ok := handleModelCommand(&m, []string{"priced"}).(tea.Cmd)
+	if !ok {
+		t.Fatal("expected /model to list the models in the background")
+	}
+	m.handleModelsListed(cmd().(modelsListedMsg))
+	if m.overlay.mode != tuitypes.OverlayModeModels {
+		t.Fatalf("expected the models overlay, got mode %v", m.overlay.mode)
+	}
+
+	// The list is kept for the next /model
+	m.overlay.mode = tuitypes.OverlayModeNone
+	if handleModelCommand(&m, nil) != nil || lister.calls != 1 || m.overlay.mode != tuitypes.OverlayModeModels {
+		t.Errorf("expected /model to reuse the listed models (%d calls)", lister.calls)
+	}
+
+	// Published prices price /cost
+	m.usage.RecordUsage(&types.TokenUsage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000, Model: "gateway/priced"})
+	if total := m.usage.Total(); !total.Priced || total.Cost != 10 {
+		t.Errorf("expected the published price to be used, got %+v", total)
+	}
+}
+
+func TestModelCommand_Unsupported(t *testing.T) {
+	m := initialModel()
+	if handleModelCommand(&m, nil) != nil || !m.toast.active {
+		t.Error("expected a toast when the provider can't list models")
+	}
+}
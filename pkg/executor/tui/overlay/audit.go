@@ -0,0 +1,151 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// AuditOverlay displays the workspace audit log and whether its hash chain is intact
+type AuditOverlay struct {
+	*BaseOverlay
+	title string
+}
+
+// NewAuditOverlay creates a new audit log overlay. verifyErr is the result of
+// verifying the entries' hash chain.
+func NewAuditOverlay(entries []audit.Entry, verifyErr error, width, height int) *AuditOverlay {
+	// Calculate overlay dimensions (80% of screen)
+	overlayWidth := int(float64(width) * 0.8)
+	overlayHeight := int(float64(height) * 0.8)
+
+	if overlayWidth < 60 {
+		overlayWidth = 60
+	}
+	if overlayHeight < 20 {
+		overlayHeight = 20
+	}
+
+	overlay := &AuditOverlay{
+		title: fmt.Sprintf("Audit Log (%s)", audit.Path),
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: overlayHeight - 6,
+		Content:        buildAuditContent(entries, verifyErr),
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		OnCustomKey: func(msg tea.KeyMsg, actions types.ActionHandler) (bool, tea.Cmd) {
+			// Allow 'q' to close the overlay
+			if msg.String() == "q" {
+				if overlay.BaseOverlay != nil {
+					return true, overlay.BaseOverlay.close(actions)
+				}
+			}
+			return false, nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// buildAuditContent formats the verification status followed by the entries, newest first
+func buildAuditContent(entries []audit.Entry, verifyErr error) string {
+	var b strings.Builder
+
+	if verifyErr != nil {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.ProgressRed).Render("✗ Tampering detected: " + verifyErr.Error()))
+	} else {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.ProgressGreen).Render(fmt.Sprintf("✓ Hash chain verified (%d entries)", len(entries))))
+	}
+	b.WriteString("\n\n")
+
+	muted := lipgloss.NewStyle().Foreground(types.MutedGray)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+
+		approvalColor := types.ProgressGreen
+		switch e.Approval {
+		case audit.ApprovalRejected, audit.ApprovalTimedOut, audit.ApprovalBlocked:
+			approvalColor = types.ProgressRed
+		case audit.ApprovalNone:
+			approvalColor = types.ProgressYellow
+		}
+		approval := lipgloss.NewStyle().Foreground(approvalColor).Render(e.Approval)
+
+		b.WriteString(fmt.Sprintf("#%d  %s  %s  %s\n", e.Seq, e.Time.Local().Format("2006-01-02 15:04:05"), lipgloss.NewStyle().Bold(true).Render(e.Action), approval))
+		b.WriteString(fmt.Sprintf("    %s: %s\n", e.Tool, e.Target))
+		if e.RiskLevel != "" {
+			stats := fmt.Sprintf("    +%d −%d · risk %s (%d)", e.Added, e.Removed, e.RiskLevel, e.Risk)
+			b.WriteString(riskStyle(e.RiskLevel).Render(stats) + "\n")
+		}
+		if e.Feedback != "" {
+			b.WriteString(muted.Render("    feedback: "+e.Feedback) + "\n")
+		}
+		if e.Error != "" {
+			b.WriteString(muted.Render("    error: "+e.Error) + "\n")
+		}
+		if e.Executed && e.Action != audit.ActionCommand {
+			b.WriteString(muted.Render(fmt.Sprintf("    sha256 %s → %s", shortHash(e.BeforeHash), shortHash(e.AfterHash))) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(entries) == 0 {
+		b.WriteString("No actions recorded yet.\n")
+	}
+
+	return b.String()
+}
+
+// shortHash abbreviates a content hash; an empty hash means the file did not exist
+func shortHash(hash string) string {
+	if hash == "" {
+		return "(none)"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// Update handles messages
+func (o *AuditOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the audit log header
+func (o *AuditOverlay) renderHeader() string {
+	return types.OverlayTitleStyle.Render(o.title)
+}
+
+// renderFooter renders the audit log footer
+func (o *AuditOverlay) renderFooter() string {
+	return types.OverlayHelpStyle.Render("↑/↓: scroll • q/esc: close")
+}
+
+// View renders the overlay
+func (o *AuditOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
@@ -0,0 +1,147 @@
+package overlay
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// ChangesOverlay displays all workspace changes since the session started
+type ChangesOverlay struct {
+	*BaseOverlay
+	diff string // Unhighlighted content, to locate the file under the cursor
+}
+
+// NewChangesOverlay creates a new workspace changes overlay
+func NewChangesOverlay(content string, width, height int) *ChangesOverlay {
+	// Calculate overlay dimensions (80% of screen)
+	overlayWidth := int(float64(width) * 0.8)
+	overlayHeight := int(float64(height) * 0.8)
+
+	if overlayWidth < 60 {
+		overlayWidth = 60
+	}
+	if overlayHeight < 20 {
+		overlayHeight = 20
+	}
+
+	overlay := &ChangesOverlay{diff: content}
+
+	// Highlight the diff portion; fall back to plain text on failure
+	if highlighted, err := syntax.HighlightDiff(content, ""); err == nil {
+		content = highlighted
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: overlayHeight - 6,
+		Content:        content,
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		OnCustomKey: func(msg tea.KeyMsg, actions types.ActionHandler) (bool, tea.Cmd) {
+			switch msg.String() {
+			case "q":
+				// Allow 'q' to close the overlay
+				if overlay.BaseOverlay != nil {
+					return true, overlay.BaseOverlay.close(actions)
+				}
+			case "e":
+				// Open the file shown at the top of the viewport in the editor
+				path, line, ok := DiffLocation(overlay.diff, overlay.Viewport().YOffset)
+				if !ok {
+					return true, nil
+				}
+				return true, func() tea.Msg {
+					return types.OpenFileMsg{Path: path, Line: line}
+				}
+			}
+			return false, nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// Update handles messages
+func (o *ChangesOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the changes header
+func (o *ChangesOverlay) renderHeader() string {
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(types.DiffHunkColor).
+		Render("Workspace Changes Since Session Start")
+}
+
+// renderFooter renders the changes footer
+func (o *ChangesOverlay) renderFooter() string {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Render("↑/↓: scroll • e: open in editor • q/esc: close")
+}
+
+// View renders the overlay
+func (o *ChangesOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
+
+// hunkHeader matches a unified diff hunk header, capturing the new-file start line
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// DiffLocation returns the file and new-file line number shown on line index
+// of a unified diff. Before a file's first hunk the line is 0; removed lines
+// map to the line that now takes their place. Deleted files have no location.
+func DiffLocation(diff string, index int) (string, int, bool) {
+	path, line, next, inHunk := "", 0, 0, false
+	for i, text := range strings.Split(diff, "\n") {
+		if i > index {
+			break
+		}
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			path, line, inHunk = "", 0, false
+			if at := strings.LastIndex(text, " b/"); at >= 0 {
+				path = text[at+3:]
+			}
+		case hunkHeader.MatchString(text):
+			next, _ = strconv.Atoi(hunkHeader.FindStringSubmatch(text)[1])
+			line, inHunk = next, true
+		case !inHunk:
+			if strings.HasPrefix(text, "+++ ") {
+				path = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+				if path == "/dev/null" {
+					path = ""
+				}
+			}
+		case strings.HasPrefix(text, "+"), strings.HasPrefix(text, " "):
+			line = next
+			next++
+		case strings.HasPrefix(text, "-"):
+			line = next
+		}
+	}
+	return path, line, path != ""
+}
@@ -0,0 +1,43 @@
+package overlay
+
+import "testing"
+
+const testDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -10,4 +10,5 @@ func main() {
+ 	a()
+-	b()
++	c()
++	d()
+ 	e()
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,1 +0,0 @@
+-package old`
+
+func TestDiffLocation(t *testing.T) {
+	tests := []struct {
+		index int
+		path  string
+		line  int
+		ok    bool
+	}{
+		{0, "main.go", 0, true},  // File header
+		{4, "main.go", 10, true}, // Hunk header
+		{5, "main.go", 10, true}, // Context
+		{6, "main.go", 11, true}, // Removed line
+		{8, "main.go", 12, true}, // Second added line
+		{9, "main.go", 13, true}, // Context after the change
+		{14, "", 0, false},       // Deleted file
+	}
+	for _, tt := range tests {
+		path, line, ok := DiffLocation(testDiff, tt.index)
+		if path != tt.path || line != tt.line || ok != tt.ok {
+			t.Errorf("DiffLocation(%d) = %q, %d, %v; want %q, %d, %v", tt.index, path, line, ok, tt.path, tt.line, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package overlay
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// ComposeOverlay is a full-screen editor for writing long, multi-line prompts.
+// Fenced code blocks in the draft are previewed with syntax highlighting.
+type ComposeOverlay struct {
+	editor      textarea.Model
+	width       int
+	height      int
+	showPreview bool
+}
+
+// NewComposeOverlay creates a compose editor pre-filled with the current draft
+func NewComposeOverlay(draft string, width, height int) *ComposeOverlay {
+	editor := textarea.New()
+	editor.Placeholder = "Write your prompt. Use ``` fences for code snippets."
+	editor.ShowLineNumbers = true
+	editor.CharLimit = 0
+	editor.MaxHeight = 0
+	editor.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	editor.SetValue(draft)
+	editor.Focus()
+
+	c := &ComposeOverlay{
+		editor:      editor,
+		width:       width,
+		height:      height,
+		showPreview: true,
+	}
+	c.resize()
+	return c
+}
+
+// resize fits the editor (and preview, if shown) to the overlay dimensions
+func (c *ComposeOverlay) resize() {
+	c.editor.SetWidth(c.width - 6)
+	editorHeight := c.height - 6
+	if c.showPreview {
+		editorHeight = (c.height - 8) / 2
+	}
+	if editorHeight < 3 {
+		editorHeight = 3
+	}
+	c.editor.SetHeight(editorHeight)
+}
+
+// Update handles messages
+func (c *ComposeOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+		c.height = msg.Height
+		c.resize()
+		return c, nil
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEsc, tea.KeyCtrlO:
+			// Close and keep the draft in the input box
+			if actions != nil {
+				actions.SetInput(c.editor.Value())
+				actions.SetCursorEnd()
+				actions.ClearOverlay()
+			}
+			return nil, nil
+
+		case tea.KeyCtrlS:
+			content := strings.TrimSpace(c.editor.Value())
+			if content == "" {
+				return c, nil
+			}
+			if actions != nil {
+				actions.SetInput("")
+				actions.ClearOverlay()
+			}
+			return nil, func() tea.Msg {
+				return types.ComposeSubmitMsg{Content: content}
+			}
+
+		case tea.KeyCtrlP:
+			c.showPreview = !c.showPreview
+			c.resize()
+			return c, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	c.editor, cmd = c.editor.Update(msg)
+	return c, cmd
+}
+
+// View renders the overlay
+func (c *ComposeOverlay) View() string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render("Compose"))
+	b.WriteString("\n\n")
+	b.WriteString(c.editor.View())
+	b.WriteString("\n")
+
+	if c.showPreview {
+		previewHeight := c.height - 8 - c.editor.Height()
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(types.MutedGray).Render("Preview"))
+		b.WriteString("\n")
+		b.WriteString(tailLines(renderComposePreview(c.editor.Value()), previewHeight))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Render("Ctrl+S: send • Ctrl+P: toggle preview • Esc/Ctrl+O: back to input (draft kept)"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(types.SalmonPink).
+		Padding(0, 1).
+		Width(c.width - 2).
+		Height(c.height - 2).
+		Render(b.String())
+}
+
+// Focused returns whether this overlay should handle input
+func (c *ComposeOverlay) Focused() bool {
+	return true
+}
+
+// Width returns the overlay width
+func (c *ComposeOverlay) Width() int {
+	return c.width
+}
+
+// Height returns the overlay height
+func (c *ComposeOverlay) Height() int {
+	return c.height
+}
+
+// renderComposePreview renders a draft with fenced code blocks syntax-highlighted
+func renderComposePreview(draft string) string {
+	var out, code strings.Builder
+	inFence := false
+	language := ""
+
+	for _, line := range strings.Split(draft, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if !inFence {
+				inFence = true
+				language = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+				code.Reset()
+			} else {
+				inFence = false
+				out.WriteString(highlightSnippet(code.String(), language))
+			}
+			out.WriteString(line + "\n")
+			continue
+		}
+
+		if inFence {
+			code.WriteString(line + "\n")
+		} else {
+			out.WriteString(line + "\n")
+		}
+	}
+
+	// Highlight an unterminated block as it is being typed
+	if inFence {
+		out.WriteString(highlightSnippet(code.String(), language))
+	}
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// highlightSnippet highlights code, falling back to the plain text
+func highlightSnippet(code, language string) string {
+	highlighted, err := syntax.HighlightCode(code, language)
+	if err != nil || highlighted == "" {
+		return code
+	}
+	if !strings.HasSuffix(highlighted, "\n") {
+		highlighted += "\n"
+	}
+	return highlighted
+}
+
+// tailLines returns the last n lines of s, so the end of the draft stays visible
+func tailLines(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,72 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+func TestRenderComposePreview(t *testing.T) {
+	draft := "Refactor this:\n```go\nfunc main() {}\n```\nthanks"
+	preview := renderComposePreview(draft)
+
+	if !strings.HasPrefix(preview, "Refactor this:\n```go\n") {
+		t.Errorf("prose and fences should be kept as-is, got %q", preview)
+	}
+	if !strings.HasSuffix(preview, "```\nthanks") {
+		t.Errorf("text after the code block should be kept, got %q", preview)
+	}
+	if !strings.Contains(preview, "\x1b[") {
+		t.Errorf("go code block should be syntax highlighted, got %q", preview)
+	}
+}
+
+func TestRenderComposePreview_UnterminatedFence(t *testing.T) {
+	preview := renderComposePreview("```python\nprint('hi')")
+	if !strings.Contains(preview, "print") {
+		t.Errorf("unterminated block should still be rendered, got %q", preview)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	if got := tailLines("a\nb\nc", 2); got != "b\nc" {
+		t.Errorf("tailLines() = %q, want %q", got, "b\nc")
+	}
+	if got := tailLines("a\nb", 5); got != "a\nb" {
+		t.Errorf("tailLines() = %q, want %q", got, "a\nb")
+	}
+	if got := tailLines("a", 0); got != "" {
+		t.Errorf("tailLines() = %q, want empty", got)
+	}
+}
+
+func TestComposeOverlay_Submit(t *testing.T) {
+	c := NewComposeOverlay("line one\nline two", 100, 40)
+
+	updated, cmd := c.Update(tea.KeyMsg{Type: tea.KeyCtrlS}, nil, nil)
+	if updated != nil {
+		t.Error("overlay should close on submit")
+	}
+	if cmd == nil {
+		t.Fatal("submit should return a command")
+	}
+
+	msg, ok := cmd().(types.ComposeSubmitMsg)
+	if !ok {
+		t.Fatalf("submit command produced %T, want ComposeSubmitMsg", cmd())
+	}
+	if msg.Content != "line one\nline two" {
+		t.Errorf("submitted content = %q", msg.Content)
+	}
+}
+
+func TestComposeOverlay_EmptySubmitIgnored(t *testing.T) {
+	c := NewComposeOverlay("   ", 100, 40)
+
+	updated, cmd := c.Update(tea.KeyMsg{Type: tea.KeyCtrlS}, nil, nil)
+	if updated == nil || cmd != nil {
+		t.Error("submitting an empty draft should keep the editor open")
+	}
+}
@@ -20,6 +20,7 @@ type ContextInfo struct {
 	// System prompt
 	SystemPromptTokens int
 	CustomInstructions bool
+	BasePromptVersion  string
 
 	// Tool system
 	ToolCount          int
@@ -97,6 +98,9 @@ func buildContextContent(info *ContextInfo) string {
 	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render("System"))
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf("  System Prompt:      %s tokens\n", formatTokenCount(info.SystemPromptTokens)))
+	if info.BasePromptVersion != "" {
+		b.WriteString(fmt.Sprintf("  Base Prompt:        %s\n", info.BasePromptVersion))
+	}
 	if info.CustomInstructions {
 		b.WriteString("  Custom Instructions: Yes\n")
 	} else {
@@ -0,0 +1,141 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// costModelWidth is the widest model name shown in the cost table
+const costModelWidth = 24
+
+// CostOverlay displays token usage and cost per turn in a modal dialog
+type CostOverlay struct {
+	*BaseOverlay
+	title string
+}
+
+// NewCostOverlay creates a new cost breakdown overlay
+func NewCostOverlay(turns []metrics.Turn, total metrics.Turn, width, height int) *CostOverlay {
+	content := buildCostContent(turns, total)
+
+	overlay := &CostOverlay{
+		title: "Session Cost",
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          80,
+		Height:         24,
+		ViewportWidth:  76,
+		ViewportHeight: 20,
+		Content:        content,
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// formatCost formats a dollar amount, or a dash when it could not be priced
+func formatCost(cost float64, priced bool) string {
+	if !priced && cost == 0 {
+		return "—"
+	}
+	if cost < 0.01 && cost > 0 {
+		return fmt.Sprintf("$%.4f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+// costRow formats one row of the cost table
+func costRow(label, model string, calls, in, out, cached int, cost string) string {
+	if len(model) > costModelWidth {
+		model = model[:costModelWidth-1] + "…"
+	}
+	return fmt.Sprintf("  %-6s %-*s %5d %8s %8s %8s %9s\n",
+		label, costModelWidth, model, calls,
+		formatTokenCount(in), formatTokenCount(out), formatTokenCount(cached), cost)
+}
+
+// buildCostContent formats the per-turn table and session total
+func buildCostContent(turns []metrics.Turn, total metrics.Turn) string {
+	var b strings.Builder
+
+	if len(turns) == 0 {
+		b.WriteString("No LLM calls yet this session.\n")
+		return b.String()
+	}
+
+	header := fmt.Sprintf("  %-6s %-*s %5s %8s %8s %8s %9s",
+		"Turn", costModelWidth, "Model", "Calls", "In", "Out", "Cached", "Cost")
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render(header))
+	b.WriteString("\n")
+
+	for _, turn := range turns {
+		b.WriteString(costRow(fmt.Sprintf("#%d", turn.Number), turn.Model, turn.Calls,
+			turn.PromptTokens, turn.CompletionTokens, turn.CachedTokens,
+			formatCost(turn.Cost, turn.Priced)))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(costRow("Total", "", total.Calls,
+		total.PromptTokens, total.CompletionTokens, total.CachedTokens,
+		formatCost(total.Cost, total.Priced))))
+
+	if !total.Priced {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(types.MutedGray).Render(
+			"  — no known price for this model; the total excludes those turns"))
+		b.WriteString("\n")
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(types.MutedGray).Render(
+		"  Costs are estimates from list prices."))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// Update handles messages for the cost overlay
+func (c *CostOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := c.BaseOverlay.Update(msg, actions)
+	c.BaseOverlay = updatedBase
+
+	if handled {
+		return c, cmd
+	}
+
+	// Handle Enter key to close (in addition to Esc)
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if keyMsg.Type == tea.KeyEnter {
+			return c, c.BaseOverlay.close(actions)
+		}
+	}
+
+	return c, nil
+}
+
+// renderHeader renders the cost overlay header
+func (c *CostOverlay) renderHeader() string {
+	return types.OverlayTitleStyle.Render(c.title)
+}
+
+// renderFooter renders the cost overlay footer
+func (c *CostOverlay) renderFooter() string {
+	return types.OverlayHelpStyle.Render("Press ESC or Enter to close • ↑/↓ to scroll")
+}
+
+// View renders the overlay
+func (c *CostOverlay) View() string {
+	return c.BaseOverlay.View(c.Width())
+}
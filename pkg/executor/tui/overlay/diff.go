@@ -1,13 +1,15 @@
 package overlay
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/risk"
 	"github.com/entrhq/forge/pkg/agent/tools"
-	"github.com/entrhq/forge/pkg/executor/tui/syntax"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
 	pkgtypes "github.com/entrhq/forge/pkg/types"
 )
@@ -19,15 +21,26 @@ const (
 	ApprovalChoiceReject
 )
 
+// keyFeedback opens the rejection reason input in the diff viewer
+const keyFeedback = "f"
+
 type DiffViewer struct {
 	*ApprovalOverlayBase
 	approvalID   string
 	toolName     string
 	preview      *tools.ToolPreview
+	assessment   *risk.Assessment // Size and risk of the change, nil if it isn't a file change
 	responseFunc func(*pkgtypes.ApprovalResponse)
+
+	// Rejection feedback input - when active, keystrokes go to the input
+	// and Enter rejects the tool call with the typed reason
+	feedbackMode  bool
+	feedbackInput textinput.Model
 }
 
-func NewDiffViewer(approvalID, toolName string, preview *tools.ToolPreview, width, height int, responseFunc func(*pkgtypes.ApprovalResponse)) *DiffViewer {
+// NewDiffViewer creates the approval overlay for a tool call, rendering its
+// preview with renderers; nil uses DefaultPreviewRenderers.
+func NewDiffViewer(approvalID, toolName string, preview *tools.ToolPreview, renderers PreviewRenderers, width, height int, responseFunc func(*pkgtypes.ApprovalResponse)) *DiffViewer {
 	// Make overlay wide - 90% of screen width
 	overlayWidth := max(int(float64(width)*0.9), 80)
 
@@ -37,36 +50,33 @@ func NewDiffViewer(approvalID, toolName string, preview *tools.ToolPreview, widt
 
 	// Calculate total overlay height
 	// Title (2) + subtitle (1) + spacing (1) + border (2) + buttons (2) + hints (1) = 9 lines
-	// Plus viewport height
+	// Plus viewport height, and the change's risk line if it has one
 	overlayHeight := viewportHeight + 9
+	assessment := previewAssessment(preview)
+	if assessment != nil {
+		overlayHeight++
+	}
+
+	feedbackInput := textinput.New()
+	feedbackInput.Placeholder = "Why are you rejecting this? (Enter to send, Esc to cancel)"
+	feedbackInput.Prompt = "Reason: "
+	feedbackInput.CharLimit = 500
+	feedbackInput.Width = overlayWidth - 16
 
 	viewer := &DiffViewer{
-		approvalID:   approvalID,
-		toolName:     toolName,
-		preview:      preview,
-		responseFunc: responseFunc,
+		approvalID:    approvalID,
+		toolName:      toolName,
+		preview:       preview,
+		assessment:    assessment,
+		responseFunc:  responseFunc,
+		feedbackInput: feedbackInput,
 	}
 
-	// Apply syntax highlighting to the diff content
-	content := ""
-	if preview != nil {
-		// Extract language from metadata
-		language := ""
-		if preview.Metadata != nil {
-			if lang, ok := preview.Metadata["language"].(string); ok {
-				language = lang
-			}
-		}
-
-		// Apply syntax highlighting
-		highlightedContent, err := syntax.HighlightDiff(preview.Content, language)
-		if err != nil {
-			// Fall back to original content if highlighting fails
-			content = preview.Content
-		} else {
-			content = highlightedContent
-		}
+	// Render the preview with its type's renderer, or as a diff
+	if renderers == nil {
+		renderers = DefaultPreviewRenderers()
 	}
+	content := renderers.Render(preview, overlayWidth-6)
 
 	// Configure approval overlay
 	approvalConfig := ApprovalOverlayConfig{
@@ -89,6 +99,16 @@ func NewDiffViewer(approvalID, toolName string, preview *tools.ToolPreview, widt
 }
 
 func (d *DiffViewer) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if d.feedbackMode {
+			return d, d.handleFeedbackKey(keyMsg)
+		}
+		if keyMsg.String() == keyFeedback {
+			d.feedbackMode = true
+			return d, d.feedbackInput.Focus()
+		}
+	}
+
 	updatedApproval, cmd := d.ApprovalOverlayBase.Update(msg, state, actions)
 	d.ApprovalOverlayBase = updatedApproval
 	return d, cmd
@@ -110,6 +130,34 @@ func (d *DiffViewer) handleReject() tea.Cmd {
 	return nil
 }
 
+// handleFeedbackKey routes keys to the rejection reason input
+func (d *DiffViewer) handleFeedbackKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEnter:
+		return d.handleRejectWithFeedback(d.feedbackInput.Value())
+	case keyEsc:
+		// Leave feedback mode without deciding; the diff stays up for review
+		d.feedbackMode = false
+		d.feedbackInput.Blur()
+		d.feedbackInput.Reset()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	d.feedbackInput, cmd = d.feedbackInput.Update(msg)
+	return cmd
+}
+
+// handleRejectWithFeedback sends a rejection response carrying the user's reason
+func (d *DiffViewer) handleRejectWithFeedback(feedback string) tea.Cmd {
+	d.feedbackMode = false
+	d.feedbackInput.Blur()
+	if d.responseFunc != nil {
+		d.responseFunc(pkgtypes.NewApprovalResponseWithFeedback(d.approvalID, pkgtypes.ApprovalRejected, strings.TrimSpace(feedback)))
+	}
+	return nil
+}
+
 // renderHeader renders the diff viewer header
 func (d *DiffViewer) renderHeader() string {
 	contentWidth := d.Width() - 6
@@ -128,9 +176,51 @@ func (d *DiffViewer) renderHeader() string {
 	header.WriteString("\n")
 	header.WriteString(strings.Repeat(" ", subtitlePadding) + types.OverlaySubtitleStyle.Render(subtitle))
 
+	// Give the approver a sense of the change's blast radius
+	if d.assessment != nil {
+		stats := d.assessment.Summary()
+		statsPadding := max(0, (contentWidth-lipgloss.Width(stats))/2)
+		header.WriteString("\n" + strings.Repeat(" ", statsPadding) + riskStyle(d.assessment.Level).Render(stats))
+	}
+
 	return header.String()
 }
 
+// riskStyle colors a risk level
+func riskStyle(level string) lipgloss.Style {
+	color := types.ProgressGreen
+	switch level {
+	case risk.LevelHigh:
+		color = types.ProgressRed
+	case risk.LevelMedium:
+		color = types.ProgressYellow
+	}
+	return lipgloss.NewStyle().Foreground(color)
+}
+
+// previewAssessment returns the risk assessment attached to a preview. A
+// preview read back from an event log holds it as decoded JSON.
+func previewAssessment(preview *tools.ToolPreview) *risk.Assessment {
+	if preview == nil {
+		return nil
+	}
+	switch value := preview.Metadata[risk.MetadataKey].(type) {
+	case *risk.Assessment:
+		return value
+	case map[string]interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		var assessment risk.Assessment
+		if json.Unmarshal(data, &assessment) != nil {
+			return nil
+		}
+		return &assessment
+	}
+	return nil
+}
+
 // renderFooter renders the diff viewer footer with buttons and hints
 func (d *DiffViewer) renderFooter() string {
 	contentWidth := d.Width() - 6
@@ -147,15 +237,24 @@ func (d *DiffViewer) renderFooter() string {
 	footer.WriteString(diffStyle.Render(d.Viewport().View()))
 	footer.WriteString("\n\n")
 
-	// Render buttons
-	buttonsRow := d.RenderButtons()
-	buttonsLen := lipgloss.Width(buttonsRow)
-	buttonsPadding := max(0, (contentWidth-buttonsLen)/2)
-	footer.WriteString(strings.Repeat(" ", buttonsPadding) + buttonsRow)
+	// Render buttons, or the reason input while collecting rejection feedback
+	if d.feedbackMode {
+		footer.WriteString("  " + d.feedbackInput.View())
+	} else {
+		buttonsRow := d.RenderButtons()
+		buttonsLen := lipgloss.Width(buttonsRow)
+		buttonsPadding := max(0, (contentWidth-buttonsLen)/2)
+		footer.WriteString(strings.Repeat(" ", buttonsPadding) + buttonsRow)
+	}
 	footer.WriteString("\n")
 
 	// Render hints
 	hints := d.RenderHints()
+	if d.feedbackMode {
+		hints = types.OverlayHelpStyle.Render("Enter to reject with reason • Esc to go back")
+	} else if hints != "" {
+		hints += types.OverlayHelpStyle.Render(" • f to reject with reason")
+	}
 	hintsLen := lipgloss.Width(hints)
 	hintsPadding := max(0, (contentWidth-hintsLen)/2)
 	footer.WriteString(strings.Repeat(" ", hintsPadding) + hints)
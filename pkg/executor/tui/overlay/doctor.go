@@ -0,0 +1,110 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/doctor"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// DoctorOverlay displays the results of the /doctor diagnostic checks
+type DoctorOverlay struct {
+	*BaseOverlay
+	title string
+}
+
+// NewDoctorOverlay creates a new diagnostics overlay
+func NewDoctorOverlay(results []doctor.Result, width, height int) *DoctorOverlay {
+	overlay := &DoctorOverlay{
+		title: "Forge Doctor",
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          80,
+		Height:         24,
+		ViewportWidth:  76,
+		ViewportHeight: 20,
+		Content:        buildDoctorContent(results),
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// buildDoctorContent formats each check with a colored status and its fix
+func buildDoctorContent(results []doctor.Result) string {
+	var b strings.Builder
+
+	for _, r := range results {
+		statusColor := types.ProgressGreen
+		switch r.Status {
+		case doctor.StatusWarn:
+			statusColor = types.ProgressYellow
+		case doctor.StatusFail:
+			statusColor = types.ProgressRed
+		}
+
+		status := lipgloss.NewStyle().Bold(true).Foreground(statusColor).Render(fmt.Sprintf("%-4s", r.Status))
+		name := lipgloss.NewStyle().Bold(true).Render(r.Name)
+		b.WriteString(fmt.Sprintf("%s  %s\n", status, name))
+		b.WriteString(fmt.Sprintf("      %s\n", r.Message))
+		if r.Fix != "" && r.Status != doctor.StatusOK {
+			b.WriteString(lipgloss.NewStyle().Foreground(types.MutedGray).Render("      → " + r.Fix))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if doctor.Failed(results) {
+		b.WriteString("Fix the items marked FAIL, then run /doctor again.\n")
+	} else {
+		b.WriteString("All required checks passed.\n")
+	}
+
+	return b.String()
+}
+
+// Update handles messages for the doctor overlay
+func (d *DoctorOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := d.BaseOverlay.Update(msg, actions)
+	d.BaseOverlay = updatedBase
+
+	if handled {
+		return d, cmd
+	}
+
+	// Handle Enter key to close (in addition to Esc)
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if keyMsg.Type == tea.KeyEnter {
+			return d, d.BaseOverlay.close(actions)
+		}
+	}
+
+	return d, nil
+}
+
+// renderHeader renders the doctor overlay header
+func (d *DoctorOverlay) renderHeader() string {
+	return types.OverlayTitleStyle.Render(d.title)
+}
+
+// renderFooter renders the doctor overlay footer
+func (d *DoctorOverlay) renderFooter() string {
+	return types.OverlayHelpStyle.Render("Press ESC or Enter to close • ↑/↓ to scroll")
+}
+
+// View renders the overlay
+func (d *DoctorOverlay) View() string {
+	return d.BaseOverlay.View(d.Width())
+}
@@ -0,0 +1,280 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/explain"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// explainDetailsLines is the height of the selected module's details below the tree
+const explainDetailsLines = 5
+
+// ExplainOverlay shows the workspace's module map as a tree that can be
+// expanded and collapsed, with the selected module's dependencies below it
+type ExplainOverlay struct {
+	*BaseOverlay
+	moduleMap *explain.Map
+	nodes     []explain.Node
+	collapsed map[string]bool
+	visible   []int // Indexes into nodes of the rows shown
+	cursor    int   // Index into visible
+	width     int
+}
+
+// NewExplainOverlay creates a module map overlay with every directory expanded
+func NewExplainOverlay(m *explain.Map, width, height int) *ExplainOverlay {
+	// Calculate overlay dimensions (80% of screen)
+	overlayWidth := int(float64(width) * 0.8)
+	overlayHeight := int(float64(height) * 0.8)
+
+	if overlayWidth < 60 {
+		overlayWidth = 60
+	}
+	if overlayHeight < 20 {
+		overlayHeight = 20
+	}
+
+	overlay := &ExplainOverlay{
+		moduleMap: m,
+		nodes:     m.Nodes(),
+		collapsed: make(map[string]bool),
+		width:     overlayWidth - 6,
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: overlayHeight - 6 - explainDetailsLines,
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		OnCustomKey:  overlay.handleKey,
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	overlay.refresh()
+	return overlay
+}
+
+// handleKey moves the selection and expands or collapses directories
+func (o *ExplainOverlay) handleKey(msg tea.KeyMsg, actions types.ActionHandler) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return true, o.BaseOverlay.close(actions)
+	case "up", "k":
+		o.move(-1)
+	case "down", "j":
+		o.move(1)
+	case keyEnter, " ":
+		if node, ok := o.Selected(); ok && o.hasChildren(node) {
+			o.collapsed[node.Path] = !o.collapsed[node.Path]
+			o.refresh()
+		}
+	case "right", "l":
+		if node, ok := o.Selected(); ok && o.collapsed[node.Path] {
+			o.collapsed[node.Path] = false
+			o.refresh()
+		}
+	case "left", "h":
+		node, ok := o.Selected()
+		if !ok {
+			return true, nil
+		}
+		if o.hasChildren(node) && !o.collapsed[node.Path] {
+			o.collapsed[node.Path] = true
+			o.refresh()
+			return true, nil
+		}
+		// Select the parent directory
+		for i := o.cursor - 1; i >= 0; i-- {
+			if parent := o.nodes[o.visible[i]]; strings.HasPrefix(node.Path, parent.Path+"/") {
+				o.move(i - o.cursor)
+				break
+			}
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// move moves the selection by delta, keeping it in view
+func (o *ExplainOverlay) move(delta int) {
+	o.cursor += delta
+	if o.cursor >= len(o.visible) {
+		o.cursor = len(o.visible) - 1
+	}
+	if o.cursor < 0 {
+		o.cursor = 0
+	}
+	o.refresh()
+
+	vp := o.Viewport()
+	switch {
+	case o.cursor < vp.YOffset:
+		vp.SetYOffset(o.cursor)
+	case o.cursor >= vp.YOffset+vp.Height:
+		vp.SetYOffset(o.cursor - vp.Height + 1)
+	}
+}
+
+// Selected returns the selected directory
+func (o *ExplainOverlay) Selected() (explain.Node, bool) {
+	if o.cursor < 0 || o.cursor >= len(o.visible) {
+		return explain.Node{}, false
+	}
+	return o.nodes[o.visible[o.cursor]], true
+}
+
+// hasChildren reports whether node has subdirectories in the tree
+func (o *ExplainOverlay) hasChildren(node explain.Node) bool {
+	if node.Path == "." {
+		return false // Top-level directories are shown beside the root, not in it
+	}
+	for _, other := range o.nodes {
+		if strings.HasPrefix(other.Path, node.Path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isHidden reports whether a directory above node is collapsed
+func (o *ExplainOverlay) isHidden(node explain.Node) bool {
+	for dir := range o.collapsed {
+		if o.collapsed[dir] && strings.HasPrefix(node.Path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh renders the tree with the selection marked
+func (o *ExplainOverlay) refresh() {
+	var selectedPath string
+	if node, ok := o.Selected(); ok {
+		selectedPath = node.Path
+	}
+
+	o.visible = o.visible[:0]
+	for i, node := range o.nodes {
+		if !o.isHidden(node) {
+			o.visible = append(o.visible, i)
+		}
+	}
+	// Keep the same directory selected when rows above it are hidden or shown
+	for i, index := range o.visible {
+		if o.nodes[index].Path == selectedPath {
+			o.cursor = i
+		}
+	}
+	if o.cursor >= len(o.visible) {
+		o.cursor = len(o.visible) - 1
+	}
+
+	if len(o.visible) == 0 {
+		o.SetContent("No source files found in the workspace.")
+		return
+	}
+
+	selected := lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink)
+	muted := lipgloss.NewStyle().Foreground(types.MutedGray)
+	var b strings.Builder
+	for i, index := range o.visible {
+		node := o.nodes[index]
+		toggle := "  "
+		if o.hasChildren(node) {
+			toggle = "▾ "
+			if o.collapsed[node.Path] {
+				toggle = "▸ "
+			}
+		}
+		name := strings.Repeat("  ", node.Depth) + toggle + node.Name + "/"
+
+		var info string
+		if node.Module != nil {
+			info = fmt.Sprintf("%s · %d files · %d lines", node.Module.Language, node.Module.Files, node.Module.Lines)
+			if n := len(node.Module.ImportedBy); n > 0 {
+				info += fmt.Sprintf(" · used by %d", n)
+			}
+		}
+
+		if i == o.cursor {
+			b.WriteString(selected.Render("▶ "+name) + "  " + muted.Render(info))
+		} else {
+			b.WriteString("  " + name + "  " + muted.Render(info))
+		}
+		b.WriteString("\n")
+	}
+	o.SetContent(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// renderDetails renders the selected module's dependencies, one line each
+func (o *ExplainOverlay) renderDetails() string {
+	muted := lipgloss.NewStyle().Foreground(types.MutedGray)
+	line := lipgloss.NewStyle().MaxWidth(o.width)
+
+	lines := make([]string, explainDetailsLines)
+	node, ok := o.Selected()
+	switch {
+	case !ok:
+	case node.Module == nil:
+		lines[1] = line.Render(node.Path + "/")
+		lines[2] = muted.Render("No source files of its own")
+	default:
+		module := node.Module
+		lines[1] = line.Render(fmt.Sprintf("%s/  %s, %d files, %d lines", module.Path, module.Language, module.Files, module.Lines))
+		lines[2] = line.Render(muted.Render("imports:     ") + joinOrNone(module.Imports))
+		lines[3] = line.Render(muted.Render("imported by: ") + joinOrNone(module.ImportedBy))
+		lines[4] = line.Render(muted.Render("external:    ") + joinOrNone(module.External))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinOrNone joins names with commas, or returns "none"
+func joinOrNone(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// Update handles messages
+func (o *ExplainOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the module map header
+func (o *ExplainOverlay) renderHeader() string {
+	return types.OverlayTitleStyle.Render(fmt.Sprintf("Module Map: %s (%d modules)", o.moduleMap.Name, len(o.moduleMap.Modules)))
+}
+
+// renderFooter renders the tree, the selected module's details and the help
+func (o *ExplainOverlay) renderFooter() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		o.Viewport().View(),
+		o.renderDetails(),
+		types.OverlayHelpStyle.Render("↑/↓: select • enter/←/→: collapse/expand • q/esc: close"))
+}
+
+// View renders the overlay
+func (o *ExplainOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
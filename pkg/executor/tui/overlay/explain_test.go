@@ -0,0 +1,70 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent/explain"
+)
+
+func TestExplainOverlay(t *testing.T) {
+	m := &explain.Map{Name: "example.com/app", Modules: []*explain.Module{
+		{Path: "cmd/app", Language: "Go", Files: 1, Imports: []string{"pkg/store"}},
+		{Path: "pkg/store", Language: "Go", Files: 2, ImportedBy: []string{"cmd/app"}, External: []string{"github.com/lib/pq"}},
+	}}
+	o := NewExplainOverlay(m, 100, 40)
+
+	key := func(k string) {
+		switch k {
+		case "down":
+			o.Update(tea.KeyMsg{Type: tea.KeyDown}, nil, nil)
+		case "left":
+			o.Update(tea.KeyMsg{Type: tea.KeyLeft}, nil, nil)
+		case "enter":
+			o.Update(tea.KeyMsg{Type: tea.KeyEnter}, nil, nil)
+		}
+	}
+	selected := func() string {
+		node, _ := o.Selected()
+		return node.Path
+	}
+
+	// cmd, cmd/app, pkg, pkg/store
+	if got := selected(); got != "cmd" {
+		t.Fatalf("expected the first directory selected, got %q", got)
+	}
+	key("enter")
+	key("down")
+	if got := selected(); got != "pkg" {
+		t.Errorf("expected collapsing cmd to hide cmd/app, got %q", got)
+	}
+	key("down")
+	if got := selected(); got != "pkg/store" {
+		t.Fatalf("expected pkg/store, got %q", got)
+	}
+
+	view := o.View()
+	for _, want := range []string{"Module Map: example.com/app (2 modules)", "▸ cmd/", "used by 1", "imported by: cmd/app", "external:    github.com/lib/pq"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	key("left")
+	if got := selected(); got != "pkg" {
+		t.Errorf("expected Left on a module to select its parent, got %q", got)
+	}
+	key("left")
+	if !strings.Contains(o.View(), "▸ pkg/") {
+		t.Error("expected Left on an expanded directory to collapse it")
+	}
+}
+
+func TestExplainOverlay_Empty(t *testing.T) {
+	o := NewExplainOverlay(&explain.Map{Name: "empty"}, 100, 40)
+	o.Update(tea.KeyMsg{Type: tea.KeyEnter}, nil, nil)
+	if !strings.Contains(o.View(), "No source files found") {
+		t.Error("expected the empty map to say so")
+	}
+}
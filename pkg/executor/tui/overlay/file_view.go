@@ -0,0 +1,142 @@
+package overlay
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// fileViewContextLines is how many lines are shown above the line a file is
+// opened at
+const fileViewContextLines = 5
+
+// FileViewOverlay shows a file with line numbers, scrolled to a line
+type FileViewOverlay struct {
+	*BaseOverlay
+	path string
+	line int
+}
+
+// NewFileViewOverlay creates a file viewer for content, the content of path,
+// scrolled to line (1-based; 0 for the start of the file), which is marked
+func NewFileViewOverlay(path, content string, line, width, height int) *FileViewOverlay {
+	// Calculate overlay dimensions (80% of screen)
+	overlayWidth := int(float64(width) * 0.8)
+	overlayHeight := int(float64(height) * 0.8)
+
+	if overlayWidth < 60 {
+		overlayWidth = 60
+	}
+	if overlayHeight < 20 {
+		overlayHeight = 20
+	}
+
+	overlay := &FileViewOverlay{path: path, line: line}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: overlayHeight - 6,
+		Content:        numberFileLines(path, content, line),
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		OnCustomKey: func(msg tea.KeyMsg, actions types.ActionHandler) (bool, tea.Cmd) {
+			switch msg.String() {
+			case "q":
+				if overlay.BaseOverlay != nil {
+					return true, overlay.BaseOverlay.close(actions)
+				}
+			case "e":
+				// Open the file in the editor at the marked line if it is in view,
+				// otherwise at the line at the top of the view
+				line := overlay.Viewport().YOffset + 1
+				if overlay.line >= line && overlay.line < line+overlay.Viewport().Height {
+					line = overlay.line
+				}
+				return true, func() tea.Msg {
+					return types.OpenFileMsg{Path: path, Line: line}
+				}
+			}
+			return false, nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	if line > fileViewContextLines {
+		overlay.Viewport().SetYOffset(line - 1 - fileViewContextLines)
+	}
+	return overlay
+}
+
+// numberFileLines highlights content for path's language and prefixes each
+// line with its number, marking line
+func numberFileLines(path, content string, line int) string {
+	language := strings.TrimPrefix(filepath.Ext(path), ".")
+	if highlighted, err := syntax.HighlightCode(content, language); err == nil {
+		content = highlighted
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	width := len(fmt.Sprint(len(lines)))
+	muted := lipgloss.NewStyle().Foreground(types.MutedGray)
+	marked := lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink)
+
+	var b strings.Builder
+	for i, text := range lines {
+		number := fmt.Sprintf("%*d", width, i+1)
+		if i+1 == line {
+			b.WriteString(marked.Render("▶ " + number))
+		} else {
+			b.WriteString(muted.Render("  " + number))
+		}
+		b.WriteString(" │ ")
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Update handles messages
+func (o *FileViewOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the file viewer header
+func (o *FileViewOverlay) renderHeader() string {
+	title := o.path
+	if o.line > 0 {
+		title = fmt.Sprintf("%s:%d", o.path, o.line)
+	}
+	return types.OverlayTitleStyle.Render(title)
+}
+
+// renderFooter renders the file viewer footer
+func (o *FileViewOverlay) renderFooter() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		o.Viewport().View(),
+		types.OverlayHelpStyle.Render("↑/↓: scroll • e: open in editor • q/esc: close"))
+}
+
+// View renders the overlay
+func (o *FileViewOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
@@ -0,0 +1,20 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNumberFileLines(t *testing.T) {
+	got := numberFileLines("NOTES", "one\ntwo\n", 2)
+	lines := strings.Split(got, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", got)
+	}
+	if !strings.Contains(lines[0], "1 │ one") || strings.Contains(lines[0], "▶") {
+		t.Errorf("unexpected first line %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "▶ 2") || !strings.HasSuffix(lines[1], "│ two") {
+		t.Errorf("expected the second line to be marked, got %q", lines[1])
+	}
+}
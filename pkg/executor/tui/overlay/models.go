@@ -0,0 +1,124 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// modelIDWidth is the widest model ID shown in the models table
+const modelIDWidth = 44
+
+// ModelsOverlay displays the provider's models for /model
+type ModelsOverlay struct {
+	*BaseOverlay
+	title string
+}
+
+// NewModelsOverlay creates a model list overlay, marking current
+func NewModelsOverlay(models []llm.Model, current, filter string, width, height int) *ModelsOverlay {
+	overlay := &ModelsOverlay{
+		title: "Models",
+	}
+	if filter != "" {
+		overlay.title = fmt.Sprintf("Models matching %q", filter)
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          80,
+		Height:         24,
+		ViewportWidth:  76,
+		ViewportHeight: 20,
+		Content:        buildModelsContent(models, current),
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// buildModelsContent formats the models as a table of ID, context window and
+// price per million tokens
+func buildModelsContent(models []llm.Model, current string) string {
+	var b strings.Builder
+
+	if current != "" {
+		b.WriteString(fmt.Sprintf("Current model: %s\n", lipgloss.NewStyle().Bold(true).Render(current)))
+		b.WriteString(lipgloss.NewStyle().Foreground(types.MutedGray).Render("Start forge with -model ID to use another model."))
+		b.WriteString("\n\n")
+	}
+	if len(models) == 0 {
+		b.WriteString("No models match.\n")
+		return b.String()
+	}
+
+	header := fmt.Sprintf("  %-*s %8s %9s %9s", modelIDWidth, "Model", "Context", "Input/M", "Output/M")
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(header))
+	b.WriteString("\n")
+	for _, m := range models {
+		marker := " "
+		if m.ID == current {
+			marker = "●"
+		}
+		id := m.ID
+		if len(id) > modelIDWidth {
+			id = id[:modelIDWidth-1] + "…"
+		}
+		window, input, output := "—", "—", "—"
+		if m.ContextWindow > 0 {
+			window = formatTokenCount(m.ContextWindow)
+		}
+		if m.Priced() {
+			input = fmt.Sprintf("$%.2f", m.InputPrice)
+			output = fmt.Sprintf("$%.2f", m.OutputPrice)
+		}
+		b.WriteString(fmt.Sprintf("%s %-*s %8s %9s %9s\n", marker, modelIDWidth, id, window, input, output))
+	}
+	b.WriteString(fmt.Sprintf("\n%d models. Prices are per million tokens, where the provider publishes them.\n", len(models)))
+	return b.String()
+}
+
+// Update handles messages for the models overlay
+func (o *ModelsOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	// Handle Enter key to close (in addition to Esc)
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if keyMsg.Type == tea.KeyEnter {
+			return o, o.BaseOverlay.close(actions)
+		}
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the models overlay header
+func (o *ModelsOverlay) renderHeader() string {
+	return types.OverlayTitleStyle.Render(o.title)
+}
+
+// renderFooter renders the models overlay footer
+func (o *ModelsOverlay) renderFooter() string {
+	return types.OverlayHelpStyle.Render("Press ESC or Enter to close • ↑/↓ to scroll")
+}
+
+// View renders the overlay
+func (o *ModelsOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
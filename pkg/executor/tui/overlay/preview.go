@@ -0,0 +1,232 @@
+package overlay
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// PreviewRenderer renders the content of a tool preview for the approval
+// overlay, fitted to width. A tool whose approval needs more than text or a
+// diff, such as a table of query results, uses its own preview type and
+// registers a renderer for it with the TUI executor's WithPreviewRenderer.
+type PreviewRenderer func(preview *tools.ToolPreview, width int) (string, error)
+
+// PreviewRenderers are the approval overlay's renderers by preview type
+type PreviewRenderers map[tools.PreviewType]PreviewRenderer
+
+// DefaultPreviewRenderers returns the renderers for the built-in preview
+// types that aren't shown as a diff: tables and trees
+func DefaultPreviewRenderers() PreviewRenderers {
+	return PreviewRenderers{
+		tools.PreviewTypeTable: RenderTablePreview,
+		tools.PreviewTypeTree:  RenderTreePreview,
+	}
+}
+
+// Render renders preview with the renderer for its type. Types without one
+// are highlighted as a diff, and a renderer that fails falls back to the
+// preview's plain content.
+func (r PreviewRenderers) Render(preview *tools.ToolPreview, width int) string {
+	if preview == nil {
+		return ""
+	}
+	if render, ok := r[preview.Type]; ok {
+		content, err := render(preview, width)
+		if err != nil {
+			return preview.Content
+		}
+		return content
+	}
+
+	language, _ := preview.Metadata["language"].(string)
+	highlighted, err := syntax.HighlightDiff(preview.Content, language)
+	if err != nil {
+		return preview.Content
+	}
+	return highlighted
+}
+
+// RenderTablePreview renders a PreviewTypeTable preview as aligned columns
+// under a header. Columns too wide for width are cut short.
+func RenderTablePreview(preview *tools.ToolPreview, width int) (string, error) {
+	columns, ok := stringSlice(preview.Metadata["columns"])
+	if !ok || len(columns) == 0 {
+		return "", fmt.Errorf("table preview has no columns")
+	}
+	var rows [][]string
+	if raw, ok := preview.Metadata["rows"].([][]string); ok {
+		rows = raw
+	} else if raw, ok := preview.Metadata["rows"].([]interface{}); ok {
+		for _, item := range raw {
+			row, ok := stringSlice(item)
+			if !ok {
+				return "", fmt.Errorf("table preview has a malformed row")
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	// Size each column to its widest cell, then shrink the widest columns
+	// until the table fits
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = lipgloss.Width(column)
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			widths[i] = max(widths[i], lipgloss.Width(row[i]))
+		}
+	}
+	const gap = 2
+	for total(widths)+gap*(len(widths)-1) > width {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 4 {
+			break
+		}
+		widths[widest]--
+	}
+
+	line := func(cells []string) string {
+		padded := make([]string, len(widths))
+		for i := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = fitCell(cells[i], widths[i])
+			}
+			padded[i] = cell + strings.Repeat(" ", widths[i]-lipgloss.Width(cell))
+		}
+		return strings.TrimRight(strings.Join(padded, strings.Repeat(" ", gap)), " ")
+	}
+
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render(line(columns)))
+	b.WriteString("\n" + types.OverlayHelpStyle.Render(strings.Repeat("─", min(width, total(widths)+gap*(len(widths)-1)))))
+	for _, row := range rows {
+		b.WriteString("\n" + line(row))
+	}
+	if len(rows) == 0 {
+		b.WriteString("\n" + types.OverlayHelpStyle.Render("(no rows)"))
+	}
+	return b.String(), nil
+}
+
+// RenderTreePreview renders a PreviewTypeTree preview's paths as a tree,
+// directories first
+func RenderTreePreview(preview *tools.ToolPreview, width int) (string, error) {
+	root := &treeNode{children: map[string]*treeNode{}}
+	for _, path := range strings.Split(preview.Content, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		isDir := strings.HasSuffix(path, "/")
+		node := root
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		for i, part := range parts {
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{name: part, children: map[string]*treeNode{}}
+				node.children[part] = child
+			}
+			if i < len(parts)-1 || isDir {
+				child.dir = true
+			}
+			node = child
+		}
+	}
+	if len(root.children) == 0 {
+		return "", fmt.Errorf("tree preview has no paths")
+	}
+
+	dirStyle := lipgloss.NewStyle().Bold(true).Foreground(types.MintGreen)
+	var lines []string
+	var walk func(node *treeNode, prefix string)
+	walk = func(node *treeNode, prefix string) {
+		children := node.sorted()
+		for i, child := range children {
+			branch, indent := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, indent = "└── ", "    "
+			}
+			name := child.name
+			if child.dir {
+				name = dirStyle.Render(name + "/")
+			}
+			lines = append(lines, prefix+branch+name)
+			walk(child, prefix+indent)
+		}
+	}
+	walk(root, "")
+	return strings.Join(lines, "\n"), nil
+}
+
+// treeNode is a file or directory of a tree preview
+type treeNode struct {
+	name     string
+	dir      bool
+	children map[string]*treeNode
+}
+
+// sorted returns the node's children, directories first, by name
+func (n *treeNode) sorted() []*treeNode {
+	children := make([]*treeNode, 0, len(n.children))
+	for _, child := range n.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].dir != children[j].dir {
+			return children[i].dir
+		}
+		return children[i].name < children[j].name
+	})
+	return children
+}
+
+// stringSlice converts a []string, or a []interface{} of strings as decoded
+// from JSON, to a []string
+func stringSlice(v interface{}) ([]string, bool) {
+	switch values := v.(type) {
+	case []string:
+		return values, true
+	case []interface{}:
+		strs := make([]string, len(values))
+		for i, value := range values {
+			strs[i] = fmt.Sprint(value)
+		}
+		return strs, true
+	}
+	return nil, false
+}
+
+// fitCell cuts a table cell to width, ending it with an ellipsis
+func fitCell(cell string, width int) string {
+	cell = strings.ReplaceAll(cell, "\n", " ")
+	if lipgloss.Width(cell) <= width {
+		return cell
+	}
+	runes := []rune(cell)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// total sums widths
+func total(widths []int) int {
+	sum := 0
+	for _, w := range widths {
+		sum += w
+	}
+	return sum
+}
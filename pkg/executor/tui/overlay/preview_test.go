@@ -0,0 +1,92 @@
+package overlay
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+func TestRenderTablePreview(t *testing.T) {
+	preview := &tools.ToolPreview{
+		Type: tools.PreviewTypeTable,
+		Metadata: map[string]interface{}{
+			"columns": []string{"id", "email"},
+			"rows":    [][]string{{"1", "ada@example.com"}, {"22", "grace@example.com"}},
+		},
+	}
+	got, err := RenderTablePreview(preview, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header, a rule and 2 rows, got %q", got)
+	}
+	if lines[0] != "id  email" || lines[2] != "1   ada@example.com" || lines[3] != "22  grace@example.com" {
+		t.Errorf("expected aligned columns, got %q", got)
+	}
+
+	// Rows decoded from JSON, cut to fit
+	preview.Metadata["rows"] = []interface{}{[]interface{}{"1", strings.Repeat("x", 40)}}
+	got, err = RenderTablePreview(preview, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if len([]rune(line)) > 20 {
+			t.Errorf("expected lines to fit in 20 columns, got %q", line)
+		}
+	}
+	if !strings.Contains(got, "…") {
+		t.Errorf("expected the long cell to be cut short, got %q", got)
+	}
+
+	if _, err := RenderTablePreview(&tools.ToolPreview{Type: tools.PreviewTypeTable}, 80); err == nil {
+		t.Error("expected an error for a table without columns")
+	}
+}
+
+func TestRenderTreePreview(t *testing.T) {
+	preview := &tools.ToolPreview{
+		Type:    tools.PreviewTypeTree,
+		Content: "app/go.mod\napp/cmd/main.go\napp/internal/\nREADME.md\n",
+	}
+	got, err := RenderTreePreview(preview, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"├── app/",
+		"│   ├── cmd/",
+		"│   │   └── main.go",
+		"│   ├── internal/",
+		"│   └── go.mod",
+		"└── README.md",
+	}, "\n")
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestPreviewRenderersRender(t *testing.T) {
+	renderers := DefaultPreviewRenderers()
+	renderers["query"] = func(preview *tools.ToolPreview, width int) (string, error) {
+		return "custom " + preview.Content, nil
+	}
+	renderers["broken"] = func(*tools.ToolPreview, int) (string, error) {
+		return "", errors.New("failed")
+	}
+
+	if got := renderers.Render(&tools.ToolPreview{Type: "query", Content: "SELECT 1"}, 80); got != "custom SELECT 1" {
+		t.Errorf("expected the registered renderer to be used, got %q", got)
+	}
+	if got := renderers.Render(&tools.ToolPreview{Type: "broken", Content: "plain"}, 80); got != "plain" {
+		t.Errorf("expected a failing renderer to fall back to the content, got %q", got)
+	}
+	diff := "--- a/x\n+++ b/x\n+added"
+	if got := renderers.Render(&tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: diff}, 80); !strings.Contains(got, "added") {
+		t.Errorf("expected a diff to be rendered, got %q", got)
+	}
+}
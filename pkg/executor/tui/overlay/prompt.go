@@ -0,0 +1,91 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// PromptOverlay shows the system prompt the model is sent
+type PromptOverlay struct {
+	*BaseOverlay
+	lines int
+}
+
+// NewPromptOverlay creates a system prompt overlay
+func NewPromptOverlay(prompt string, width, height int) *PromptOverlay {
+	// Calculate overlay dimensions (80% of screen)
+	overlayWidth := int(float64(width) * 0.8)
+	overlayHeight := int(float64(height) * 0.8)
+
+	if overlayWidth < 60 {
+		overlayWidth = 60
+	}
+	if overlayHeight < 20 {
+		overlayHeight = 20
+	}
+
+	overlay := &PromptOverlay{
+		lines: strings.Count(prompt, "\n") + 1,
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: overlayHeight - 6,
+		Content:        prompt,
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		OnCustomKey: func(msg tea.KeyMsg, actions types.ActionHandler) (bool, tea.Cmd) {
+			if msg.String() == "q" && overlay.BaseOverlay != nil {
+				return true, overlay.BaseOverlay.close(actions)
+			}
+			return false, nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// Update handles messages
+func (o *PromptOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the prompt header
+func (o *PromptOverlay) renderHeader() string {
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(types.DiffHunkColor).
+		Render(fmt.Sprintf("System Prompt (%d lines)", o.lines))
+}
+
+// renderFooter renders the prompt footer
+func (o *PromptOverlay) renderFooter() string {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Render("↑/↓: scroll • q/esc: close")
+}
+
+// View renders the overlay
+func (o *PromptOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
@@ -0,0 +1,177 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// Reference is a file location a tool referenced
+type Reference struct {
+	Path     string // Relative to the workspace, or absolute
+	Line     int    // 1-based; 0 for the start of the file
+	ToolName string // The tool whose result referenced it
+}
+
+// String formats the reference as path or path:line
+func (r Reference) String() string {
+	if r.Line > 0 {
+		return fmt.Sprintf("%s:%d", r.Path, r.Line)
+	}
+	return r.Path
+}
+
+// ReferencesOverlay lists the files tools referenced, to view one at its
+// line or open it in the editor
+type ReferencesOverlay struct {
+	*BaseOverlay
+	refs   []Reference
+	cursor int
+}
+
+// NewReferencesOverlay creates a references overlay, newest reference first
+func NewReferencesOverlay(refs []Reference, width, height int) *ReferencesOverlay {
+	// Calculate overlay dimensions (80% of screen)
+	overlayWidth := int(float64(width) * 0.8)
+	overlayHeight := int(float64(height) * 0.8)
+
+	if overlayWidth < 60 {
+		overlayWidth = 60
+	}
+	if overlayHeight < 20 {
+		overlayHeight = 20
+	}
+
+	overlay := &ReferencesOverlay{refs: refs}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: overlayHeight - 6,
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		OnCustomKey:  overlay.handleKey,
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	overlay.refresh()
+	return overlay
+}
+
+// handleKey moves the selection and acts on the selected reference
+func (o *ReferencesOverlay) handleKey(msg tea.KeyMsg, actions types.ActionHandler) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return true, o.BaseOverlay.close(actions)
+	case "up", "k":
+		o.move(-1)
+		return true, nil
+	case "down", "j":
+		o.move(1)
+		return true, nil
+	}
+
+	ref, ok := o.Selected()
+	if !ok {
+		return false, nil
+	}
+	switch msg.String() {
+	case keyEnter:
+		return true, func() tea.Msg {
+			return types.ViewFileMsg{Path: ref.Path, Line: ref.Line}
+		}
+	case "e":
+		return true, func() tea.Msg {
+			return types.OpenFileMsg{Path: ref.Path, Line: ref.Line}
+		}
+	}
+	return false, nil
+}
+
+// move moves the selection by delta, keeping it in view
+func (o *ReferencesOverlay) move(delta int) {
+	o.cursor += delta
+	if o.cursor < 0 {
+		o.cursor = 0
+	}
+	if o.cursor >= len(o.refs) {
+		o.cursor = len(o.refs) - 1
+	}
+	o.refresh()
+
+	vp := o.Viewport()
+	switch {
+	case o.cursor < vp.YOffset:
+		vp.SetYOffset(o.cursor)
+	case o.cursor >= vp.YOffset+vp.Height:
+		vp.SetYOffset(o.cursor - vp.Height + 1)
+	}
+}
+
+// Selected returns the selected reference
+func (o *ReferencesOverlay) Selected() (Reference, bool) {
+	if o.cursor < 0 || o.cursor >= len(o.refs) {
+		return Reference{}, false
+	}
+	return o.refs[o.cursor], true
+}
+
+// refresh renders the list with the selection marked
+func (o *ReferencesOverlay) refresh() {
+	if len(o.refs) == 0 {
+		o.SetContent("No files referenced yet. Files read, written or found by tools are listed here.")
+		return
+	}
+
+	selected := lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink)
+	muted := lipgloss.NewStyle().Foreground(types.MutedGray)
+	var b strings.Builder
+	for i, ref := range o.refs {
+		line := fmt.Sprintf("  %s  %s", ref.String(), muted.Render(ref.ToolName))
+		if i == o.cursor {
+			line = selected.Render("▶ "+ref.String()) + "  " + muted.Render(ref.ToolName)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	o.SetContent(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// Update handles messages
+func (o *ReferencesOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the references header
+func (o *ReferencesOverlay) renderHeader() string {
+	return types.OverlayTitleStyle.Render(fmt.Sprintf("File References (%d)", len(o.refs)))
+}
+
+// renderFooter renders the references footer
+func (o *ReferencesOverlay) renderFooter() string {
+	return lipgloss.JoinVertical(lipgloss.Left,
+		o.Viewport().View(),
+		types.OverlayHelpStyle.Render("↑/↓: select • enter: view • e: open in editor • q/esc: close"))
+}
+
+// View renders the overlay
+func (o *ReferencesOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
@@ -0,0 +1,53 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+func TestReferencesOverlay(t *testing.T) {
+	refs := []Reference{
+		{Path: "main.go", Line: 12, ToolName: "read_file"},
+		{Path: "util.go", ToolName: "write_file"},
+	}
+	o := NewReferencesOverlay(refs, 100, 40)
+
+	o.Update(tea.KeyMsg{Type: tea.KeyDown}, nil, nil)
+	if ref, _ := o.Selected(); ref.Path != "util.go" {
+		t.Fatalf("expected Down to select the second reference, got %+v", ref)
+	}
+	o.Update(tea.KeyMsg{Type: tea.KeyDown}, nil, nil)
+	if ref, _ := o.Selected(); ref.Path != "util.go" {
+		t.Errorf("expected the selection to stop at the last reference, got %+v", ref)
+	}
+	o.Update(tea.KeyMsg{Type: tea.KeyUp}, nil, nil)
+
+	_, cmd := o.Update(tea.KeyMsg{Type: tea.KeyEnter}, nil, nil)
+	if cmd == nil {
+		t.Fatal("expected Enter to view the selected reference")
+	}
+	if msg, ok := cmd().(types.ViewFileMsg); !ok || msg.Path != "main.go" || msg.Line != 12 {
+		t.Errorf("expected to view main.go at line 12, got %#v", cmd())
+	}
+
+	_, cmd = o.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, nil, nil)
+	if cmd == nil {
+		t.Fatal("expected e to open the selected reference")
+	}
+	if msg, ok := cmd().(types.OpenFileMsg); !ok || msg.Path != "main.go" || msg.Line != 12 {
+		t.Errorf("expected to open main.go at line 12, got %#v", cmd())
+	}
+}
+
+func TestReferencesOverlay_Empty(t *testing.T) {
+	o := NewReferencesOverlay(nil, 100, 40)
+	if _, cmd := o.Update(tea.KeyMsg{Type: tea.KeyEnter}, nil, nil); cmd != nil {
+		t.Error("expected Enter to do nothing without references")
+	}
+	if !strings.Contains(o.View(), "No files referenced yet") {
+		t.Error("expected the empty list to say so")
+	}
+}
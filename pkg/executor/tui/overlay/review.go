@@ -0,0 +1,131 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/review"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// ReviewOverlay displays the findings of a /review-diff review
+type ReviewOverlay struct {
+	*BaseOverlay
+	title string
+}
+
+// NewReviewOverlay creates a new review findings overlay
+func NewReviewOverlay(result *review.Result, width, height int) *ReviewOverlay {
+	// Calculate overlay dimensions (80% of screen)
+	overlayWidth := int(float64(width) * 0.8)
+	overlayHeight := int(float64(height) * 0.8)
+
+	if overlayWidth < 60 {
+		overlayWidth = 60
+	}
+	if overlayHeight < 20 {
+		overlayHeight = 20
+	}
+
+	overlay := &ReviewOverlay{
+		title: fmt.Sprintf("Code Review (%s)", result.Range),
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: overlayHeight - 6,
+		Content:        buildReviewContent(result, overlayWidth-6),
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			if actions != nil {
+				actions.ClearOverlay()
+			}
+			return nil
+		},
+		OnCustomKey: func(msg tea.KeyMsg, actions types.ActionHandler) (bool, tea.Cmd) {
+			// Allow 'q' to close the overlay
+			if msg.String() == "q" {
+				if overlay.BaseOverlay != nil {
+					return true, overlay.BaseOverlay.close(actions)
+				}
+			}
+			return false, nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// buildReviewContent formats the summaries followed by the findings, most severe first
+func buildReviewContent(result *review.Result, width int) string {
+	var b strings.Builder
+	muted := lipgloss.NewStyle().Foreground(types.MutedGray)
+	wrap := lipgloss.NewStyle().Width(width)
+
+	for _, summary := range result.Summary {
+		b.WriteString(wrap.Render(summary) + "\n\n")
+	}
+
+	if len(result.Findings) == 0 {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.ProgressGreen).Render("✓ No findings"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	for _, f := range result.Findings {
+		color := types.MutedGray
+		switch f.Severity {
+		case review.SeverityCritical:
+			color = types.ProgressRed
+		case review.SeverityMajor:
+			color = types.ProgressYellow
+		case review.SeverityMinor:
+			color = types.ProgressGreen
+		}
+		severity := lipgloss.NewStyle().Bold(true).Foreground(color).Render(fmt.Sprintf("%-8s", f.Severity))
+
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		b.WriteString(fmt.Sprintf("%s %s\n", severity, lipgloss.NewStyle().Bold(true).Render(location)))
+		b.WriteString(wrap.Render(f.Comment) + "\n\n")
+	}
+
+	b.WriteString(muted.Render("Export as Markdown or a GitHub review with: forge review -format markdown|github " + result.Range))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Update handles messages
+func (o *ReviewOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+	o.BaseOverlay = updatedBase
+
+	if handled {
+		return o, cmd
+	}
+
+	return o, nil
+}
+
+// renderHeader renders the review header
+func (o *ReviewOverlay) renderHeader() string {
+	return types.OverlayTitleStyle.Render(o.title)
+}
+
+// renderFooter renders the review footer
+func (o *ReviewOverlay) renderFooter() string {
+	return types.OverlayHelpStyle.Render("↑/↓: scroll • q/esc: close")
+}
+
+// View renders the overlay
+func (o *ReviewOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
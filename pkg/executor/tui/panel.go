@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/git"
+)
+
+const (
+	// panelWidth is the width of the session panel, border included
+	panelWidth = 38
+
+	// minPanelTerminalWidth is the narrowest terminal the session panel is
+	// shown in; below it the conversation keeps the whole width
+	minPanelTerminalWidth = 100
+
+	// gaugeWidth is the width of the panel's context gauge bar
+	gaugeWidth = 20
+)
+
+// panelStyle frames the session panel with a rule on its left
+var panelStyle = lipgloss.NewStyle().
+	Border(lipgloss.NormalBorder(), false, false, false, true).
+	BorderForeground(mutedGray).
+	Padding(0, 1)
+
+// panelRefreshedMsg redraws the session panel once the tracker has caught
+// up with git status
+type panelRefreshedMsg struct{}
+
+// panelVisible reports whether the session panel is shown: it is toggled on
+// and the terminal is wide enough
+func (m *model) panelVisible() bool {
+	return m.showPanel && m.width >= minPanelTerminalWidth
+}
+
+// transcriptWidth is the width the conversation is rendered at, which
+// leaves room for the session panel when it is shown
+func (m *model) transcriptWidth() int {
+	if m.panelVisible() {
+		return m.width - panelWidth
+	}
+	return m.width
+}
+
+// togglePanel shows or hides the session panel (Ctrl+T)
+func (m *model) togglePanel() (tea.Model, tea.Cmd) {
+	m.showPanel = !m.showPanel
+	if m.showPanel && m.width < minPanelTerminalWidth {
+		m.showToast("Session panel needs a wider terminal", fmt.Sprintf("It appears once the terminal is %d columns wide", minPanelTerminalWidth), "📐", false)
+	}
+	m.recalculateLayout()
+	return m, m.refreshPanel()
+}
+
+// refreshPanel brings the panel's changed files up to date with git status,
+// which also sees files changed by commands. It does nothing while the
+// panel is hidden.
+func (m *model) refreshPanel() tea.Cmd {
+	if !m.panelVisible() || m.tracker == nil {
+		return nil
+	}
+	tracker, ctx := m.tracker, m.sessionCtx
+	return func() tea.Msg {
+		if err := tracker.Refresh(ctx); err != nil {
+			debugLog.Printf("Failed to refresh changed files for the session panel: %v", err)
+		}
+		return panelRefreshedMsg{}
+	}
+}
+
+// renderPanel renders the session panel at height: what is running, the
+// files changed this session, the last task's follow-ups and token usage
+func (m *model) renderPanel(height int) string {
+	width := panelWidth - panelStyle.GetHorizontalFrameSize()
+	var sections []string
+	add := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		sections = append(sections, headerStyle.Render(title)+"\n"+strings.Join(lines, "\n"))
+	}
+
+	running := m.panelRunning(width)
+	followUps := m.panelFollowUps(width)
+	usage := m.panelUsage()
+
+	// Changed files get the rows the other sections leave: each section
+	// has a title and is followed by a blank line
+	rows := height - 2 // The files section's own title and blank line
+	for _, lines := range [][]string{running, followUps, usage} {
+		if len(lines) > 0 {
+			rows -= len(lines) + 2
+		}
+	}
+	files, total := m.panelFiles(width, rows)
+
+	add("Running", running)
+	add(fmt.Sprintf("Files changed (%d)", total), files)
+	add("Follow-ups", followUps)
+	add("Usage", usage)
+
+	if len(sections) == 0 {
+		sections = append(sections, tipsStyle.Render("Nothing to show yet"))
+	}
+	return panelStyle.
+		Width(panelWidth - panelStyle.GetHorizontalBorderSize()).
+		Height(height).
+		MaxHeight(height).
+		Render(strings.Join(sections, "\n\n"))
+}
+
+// panelRunning lists the running tool and commands
+func (m *model) panelRunning(width int) []string {
+	var lines []string
+	if m.activeTool != "" {
+		line := "⏳ " + m.activeTool
+		if m.toolProgress != "" {
+			line += ": " + m.toolProgress
+		}
+		lines = append(lines, truncateLine(line, width))
+	}
+
+	ids := make([]string, 0, len(m.runningCommands))
+	for id := range m.runningCommands {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		lines = append(lines, bashPromptStyle.Render("$")+" "+truncateLine(m.runningCommands[id], width-2))
+	}
+	return lines
+}
+
+// panelFiles lists up to rows of the files changed this session, newest
+// first, returning them with the number changed
+func (m *model) panelFiles(width, rows int) ([]string, int) {
+	if m.tracker == nil {
+		return nil, 0
+	}
+	mods := m.tracker.GetModifications()
+	sort.SliceStable(mods, func(i, j int) bool {
+		return mods[i].Timestamp.After(mods[j].Timestamp)
+	})
+
+	rows = max(rows, 1)
+	var lines []string
+	for i, mod := range mods {
+		if len(lines) == rows-1 && len(mods)-i > 1 {
+			lines = append(lines, tipsStyle.Render(fmt.Sprintf("… %d more (/changes)", len(mods)-i)))
+			break
+		}
+		marker := changeMarker(mod.Operation)
+		if mod.ByAgent {
+			marker = toolStyle.Render(marker)
+		}
+		lines = append(lines, marker+" "+truncateLine(mod.Path, width-2))
+	}
+	return lines, len(mods)
+}
+
+// changeMarker is the one-character marker of a file modification
+func changeMarker(operation string) string {
+	switch operation {
+	case git.OpCreate:
+		return "+"
+	case git.OpDelete:
+		return "-"
+	case git.OpRename:
+		return "→"
+	default:
+		return "~"
+	}
+}
+
+// panelFollowUps lists the follow-ups of the last structured task completion
+func (m *model) panelFollowUps(width int) []string {
+	lines := make([]string, 0, len(m.followUps))
+	for _, followUp := range m.followUps {
+		lines = append(lines, "• "+truncateLine(followUp, width-2))
+	}
+	return lines
+}
+
+// panelUsage renders the context gauge, token counts and cost
+func (m *model) panelUsage() []string {
+	if m.totalTokens == 0 {
+		return nil
+	}
+	var lines []string
+	if m.maxContextTokens > 0 {
+		ratio := float64(m.currentContextTokens) / float64(m.maxContextTokens)
+		if ratio > 1 {
+			ratio = 1
+		}
+		filled := int(ratio * gaugeWidth)
+		color := mintGreen
+		if ratio >= 0.8 {
+			color = lipgloss.Color("203")
+		}
+		bar := lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", filled)) +
+			tipsStyle.Render(strings.Repeat("░", gaugeWidth-filled))
+		lines = append(lines, fmt.Sprintf("Context %s %.0f%%", bar, ratio*100),
+			fmt.Sprintf("  %s of %s", formatTokenCount(m.currentContextTokens), formatTokenCount(m.maxContextTokens)))
+	}
+	lines = append(lines, fmt.Sprintf("Input   %s", formatTokenCount(m.totalPromptTokens)),
+		fmt.Sprintf("Output  %s", formatTokenCount(m.totalCompletionTokens)))
+	if total := m.usage.Total(); total.Calls > 0 {
+		cost := "—"
+		if total.Priced {
+			cost = fmt.Sprintf("$%.2f", total.Cost)
+		}
+		lines = append(lines, fmt.Sprintf("Cost    %s", cost))
+	}
+	return lines
+}
+
+// truncateLine shortens line to width cells, ending it with an ellipsis
+func truncateLine(line string, width int) string {
+	if lipgloss.Width(line) <= width {
+		return line
+	}
+	runes := []rune(line)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
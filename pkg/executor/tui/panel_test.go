@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestTogglePanel(t *testing.T) {
+	m := newStreamModel()
+	m.width = 120
+	m.recalculateLayout()
+	full := m.viewport.Width
+
+	m.togglePanel()
+	if !m.panelVisible() {
+		t.Fatal("expected the panel to be shown")
+	}
+	if m.viewport.Width != full-panelWidth {
+		t.Errorf("expected the conversation to make room for the panel, got width %d of %d", m.viewport.Width, full)
+	}
+
+	m.togglePanel()
+	if m.panelVisible() || m.viewport.Width != full {
+		t.Errorf("expected the conversation to take the whole width again, got %d", m.viewport.Width)
+	}
+}
+
+func TestTogglePanel_NarrowTerminal(t *testing.T) {
+	m := newStreamModel()
+	m.width = minPanelTerminalWidth - 1
+	m.togglePanel()
+	if m.panelVisible() || m.transcriptWidth() != m.width {
+		t.Error("expected the panel to stay hidden in a narrow terminal")
+	}
+
+	// It appears once the terminal is wide enough
+	m.width = minPanelTerminalWidth
+	if !m.panelVisible() {
+		t.Error("expected the panel once the terminal is wide enough")
+	}
+}
+
+func TestRenderPanel(t *testing.T) {
+	m := newStreamModel()
+	m.tracker = git.NewModificationTracker(t.TempDir())
+	m.tracker.Track("pkg/server.go", git.OpWrite)
+	m.tracker.Track("README.md", git.OpDiff)
+
+	m.handleToolCall(types.NewToolCallEvent("execute_command", nil))
+	m.runningCommands["exec-1"] = "go test ./..."
+	m.followUps = []string{"Add a changelog entry"}
+	m.totalTokens, m.totalPromptTokens, m.totalCompletionTokens = 1500, 1200, 300
+	m.currentContextTokens, m.maxContextTokens = 50000, 100000
+
+	panel := stripANSI(m.renderPanel(30))
+	for _, want := range []string{
+		"Running", "⏳ execute_command", "$ go test ./...",
+		"Files changed (2)", "~ README.md", "~ pkg/server.go",
+		"Follow-ups", "• Add a changelog entry",
+		"Usage", "50%", "50.0K of 100.0K", "Input   1.2K",
+	} {
+		if !strings.Contains(panel, want) {
+			t.Errorf("expected the panel to contain %q, got:\n%s", want, panel)
+		}
+	}
+	if lines := strings.Count(panel, "\n") + 1; lines != 30 {
+		t.Errorf("expected the panel to fill the 30 rows beside the conversation, got %d", lines)
+	}
+
+	m.handleCommandExecutionComplete(types.NewCommandExecutionCompleteEvent("exec-1", 0, "1s"))
+	m.clearRunningTool()
+	if panel := stripANSI(m.renderPanel(30)); strings.Contains(panel, "Running") {
+		t.Errorf("expected nothing running, got:\n%s", panel)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
 )
 
 // DisplayTier represents how a tool result should be displayed in the TUI
@@ -67,7 +69,7 @@ func (c *ToolResultClassifier) ClassifyToolResult(toolName string, result string
 
 	// Check for specific tools that should always be summary-only when large
 	switch toolName {
-	case "read_file", "search_files", "list_files":
+	case "read_file", "request_context", "search_files", "list_files":
 		if lineCount >= 50 {
 			return TierSummaryOnly
 		}
@@ -106,6 +108,16 @@ func NewToolResultSummarizer() *ToolResultSummarizer {
 	return &ToolResultSummarizer{}
 }
 
+// SummarizeResult returns a one-line summary for a tool result: the tool's
+// own summary if it gave one, otherwise one derived from its output by
+// GenerateSummary
+func (s *ToolResultSummarizer) SummarizeResult(toolName string, result *tools.Result) string {
+	if result.Summary == "" {
+		return s.GenerateSummary(toolName, result.Output)
+	}
+	return result.Summary + " [Ctrl+V to view]"
+}
+
 // GenerateSummary creates a one-line summary for a tool result
 func (s *ToolResultSummarizer) GenerateSummary(toolName string, result string) string {
 	lineCount := strings.Count(result, "\n") + 1
@@ -3,6 +3,8 @@ package tui
 import (
 	"strings"
 	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
 )
 
 func TestExtractFilename(t *testing.T) {
@@ -369,3 +371,33 @@ func TestGenerateSummary(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestSummarizeResult(t *testing.T) {
+	s := NewToolResultSummarizer()
+
+	got := s.SummarizeResult("apply_diff", &tools.Result{Summary: "Applied 2 edit(s) to main.go", Output: "done"})
+	if got != "Applied 2 edit(s) to main.go [Ctrl+V to view]" {
+		t.Errorf("expected the tool's own summary, got %q", got)
+	}
+
+	output := "package main\nfunc main() {}"
+	if got, want := s.SummarizeResult("custom", &tools.Result{Output: output}), s.GenerateSummary("custom", output); got != want {
+		t.Errorf("expected a summary derived from the output, got %q, want %q", got, want)
+	}
+}
+
+func TestRenderCompletionCard(t *testing.T) {
+	card := renderCompletionCard(&tools.Completion{
+		Summary:       "Fixed the parser",
+		Result:        "Nested lists parse again.",
+		FilesChanged:  []string{"parser.go"},
+		CommandsToRun: []string{"go test ./..."},
+		FollowUps:     []string{"Add a fuzz test"},
+	}, 80)
+
+	for _, want := range []string{"✓ Fixed the parser", "Nested lists parse again.", "Files changed", "parser.go", "$ go test ./...", "Follow-ups", "• Add a fuzz test", "Ctrl+R to open the 1 changed file(s)"} {
+		if !strings.Contains(card, want) {
+			t.Errorf("expected the card to contain %q, got:\n%s", want, card)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ansiPattern matches terminal escape sequences (colors, styles) in rendered content
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+var (
+	searchMatchStyle = lipgloss.NewStyle().
+				Foreground(darkBg).
+				Background(coralPink)
+
+	searchCurrentStyle = lipgloss.NewStyle().
+				Foreground(darkBg).
+				Background(mintGreen).
+				Bold(true)
+)
+
+// transcriptSearch holds the state of a Ctrl+F search over the session transcript
+type transcriptSearch struct {
+	active  bool
+	query   string
+	matches []int // Transcript line numbers containing the query
+	current int   // Index into matches of the focused match
+}
+
+// stripANSI removes terminal escape sequences so rendered lines can be matched as plain text
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// findMatches returns the indices of lines containing query (case-insensitive)
+func findMatches(lines []string, query string) []int {
+	if query == "" {
+		return nil
+	}
+
+	needle := strings.ToLower(query)
+	var matches []int
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(stripANSI(line)), needle) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// highlightMatches renders line as plain text with every occurrence of query
+// (case-insensitive) highlighted in style
+func highlightMatches(line, query string, style lipgloss.Style) string {
+	plain := stripANSI(line)
+	lower := strings.ToLower(plain)
+	needle := strings.ToLower(query)
+	if len(lower) != len(plain) || len(needle) != len(query) {
+		// Case folding changed byte offsets; fall back to exact matching
+		lower, needle = plain, query
+	}
+
+	var builder strings.Builder
+	for {
+		idx := strings.Index(lower, needle)
+		if idx < 0 || needle == "" {
+			builder.WriteString(plain)
+			return builder.String()
+		}
+		builder.WriteString(plain[:idx])
+		builder.WriteString(style.Render(plain[idx : idx+len(needle)]))
+		plain = plain[idx+len(needle):]
+		lower = lower[idx+len(needle):]
+	}
+}
+
+// startSearch enters transcript search mode
+func (m *model) startSearch() (tea.Model, tea.Cmd) {
+	m.search = &transcriptSearch{active: true}
+	m.refreshSearch(false)
+	return m, nil
+}
+
+// endSearch leaves search mode, restoring the unhighlighted transcript at the
+// current scroll position so the user stays where the search took them
+func (m *model) endSearch() (tea.Model, tea.Cmd) {
+	m.search = &transcriptSearch{}
+	offset := m.viewport.YOffset
+	m.viewport.ShowTranscript("")
+	m.viewport.SetYOffset(offset)
+	return m, nil
+}
+
+// handleSearchKey processes keyboard input while search mode is active
+func (m *model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return m.endSearch()
+
+	case tea.KeyEnter, tea.KeyDown, tea.KeyCtrlN:
+		m.stepSearch(1)
+
+	case tea.KeyUp, tea.KeyShiftTab, tea.KeyCtrlP:
+		m.stepSearch(-1)
+
+	case tea.KeyBackspace:
+		if runes := []rune(m.search.query); len(runes) > 0 {
+			m.search.query = string(runes[:len(runes)-1])
+			m.search.current = 0
+			m.refreshSearch(true)
+		}
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.search.query += string(msg.Runes)
+		m.search.current = 0
+		m.refreshSearch(true)
+	}
+
+	return m, nil
+}
+
+// stepSearch moves the focused match forward or backward, wrapping around
+func (m *model) stepSearch(delta int) {
+	if len(m.search.matches) == 0 {
+		return
+	}
+	n := len(m.search.matches)
+	m.search.current = ((m.search.current+delta)%n + n) % n
+	m.refreshSearch(true)
+}
+
+// refreshSearch recomputes matches against the transcript and renders it with
+// matches highlighted. When jump is true the viewport scrolls to center the
+// focused match.
+func (m *model) refreshSearch(jump bool) {
+	lines := strings.Split(m.content.String(), "\n")
+	m.search.matches = findMatches(lines, m.search.query)
+	if m.search.current >= len(m.search.matches) {
+		m.search.current = 0
+	}
+
+	focused := -1
+	if len(m.search.matches) > 0 {
+		focused = m.search.matches[m.search.current]
+	}
+	for _, idx := range m.search.matches {
+		style := searchMatchStyle
+		if idx == focused {
+			style = searchCurrentStyle
+		}
+		lines[idx] = highlightMatches(lines[idx], m.search.query, style)
+	}
+
+	offset := m.viewport.YOffset
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+	if jump && focused >= 0 {
+		offset = focused - m.viewport.Height/2
+		if offset < 0 {
+			offset = 0
+		}
+	}
+	m.viewport.SetYOffset(offset)
+}
+
+// buildSearchBox renders the search prompt shown in place of the input box
+func (m *model) buildSearchBox() string {
+	status := "no matches"
+	switch {
+	case m.search.query == "":
+		status = "type to search messages, tool names and file paths"
+	case len(m.search.matches) > 0:
+		status = fmt.Sprintf("%d of %d", m.search.current+1, len(m.search.matches))
+	}
+
+	line := lipgloss.NewStyle().Foreground(salmonPink).Render("Search: ") +
+		lipgloss.NewStyle().Foreground(brightWhite).Render(m.search.query) +
+		"  " + tipsStyle.Render(fmt.Sprintf("(%s) • Enter/↓ next • ↑ prev • Esc close", status))
+	return inputBoxStyle.Width(m.width - 4).Render(line)
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFindMatches(t *testing.T) {
+	lines := []string{
+		"You: where did it write the config?",
+		"\x1b[38;2;168;230;207m  🔧 write_file\x1b[0m",
+		"  Wrote pkg/config/loader.go",
+		"",
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []int
+	}{
+		{"empty query", "", nil},
+		{"tool name inside styled line", "write_file", []int{1}},
+		{"file path", "config/loader", []int{2}},
+		{"case insensitive", "WRITE", []int{0, 1}},
+		{"no match", "nonexistent", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := findMatches(lines, tt.query)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("findMatches(%q) = %v, want %v", tt.query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	// Wrap matches in brackets so the output is independent of terminal color support
+	style := lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+
+	result := highlightMatches("\x1b[1mFoo bar foo\x1b[0m", "foo", style)
+	if strings.Contains(result, "\x1b[1m") {
+		t.Errorf("highlightMatches should drop the line's original styling, got %q", result)
+	}
+	if !strings.Contains(result, "Foo") || !strings.Contains(result, " bar ") {
+		t.Errorf("highlightMatches should keep the line's text, got %q", result)
+	}
+	if strings.Count(stripANSI(result), "[") != 2 {
+		t.Errorf("highlightMatches should highlight every occurrence, got %q", result)
+	}
+}
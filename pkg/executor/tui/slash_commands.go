@@ -3,14 +3,27 @@ package tui
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/changelog"
+	"github.com/entrhq/forge/pkg/agent/explain"
 	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/metrics"
+	"github.com/entrhq/forge/pkg/agent/release"
+	"github.com/entrhq/forge/pkg/agent/seed"
+	"github.com/entrhq/forge/pkg/doctor"
 	"github.com/entrhq/forge/pkg/executor/tui/approval"
 	"github.com/entrhq/forge/pkg/executor/tui/overlay"
 	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/tools/issues"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -66,6 +79,24 @@ func init() {
 		MaxArgs:     0,
 	})
 
+	registerCommand(&SlashCommand{
+		Name:        "continue",
+		Description: "Resume the last task with a fresh turn budget",
+		Type:        CommandTypeAgent,
+		Handler:     handleContinueCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "skip-optimize",
+		Description: "Skip the running context optimization and keep the conversation as it is",
+		Type:        CommandTypeAgent,
+		Handler:     handleSkipOptimizeCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
 	registerCommand(&SlashCommand{
 		Name:             "commit",
 		Description:      "Create git commit from session changes",
@@ -86,6 +117,35 @@ func init() {
 		MaxArgs:          -1, // Unlimited for PR title
 	})
 
+	registerCommand(&SlashCommand{
+		Name:             "changelog",
+		Description:      "Update CHANGELOG.md from the commits since the last tag (optionally for a version)",
+		Type:             CommandTypeTUI,
+		Handler:          handleChangelogCommand,
+		RequiresApproval: true, // Changelog updates require approval
+		MinArgs:          0,
+		MaxArgs:          1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:             "release",
+		Description:      "Release the commits since the last tag: changelog commit, tag and, with 'github', the GitHub release",
+		Type:             CommandTypeTUI,
+		Handler:          handleReleaseCommand,
+		RequiresApproval: true, // Every release step requires approval
+		MinArgs:          0,
+		MaxArgs:          2,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "changes",
+		Description: "Show all workspace changes since session start",
+		Type:        CommandTypeTUI,
+		Handler:     handleChangesCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
 	registerCommand(&SlashCommand{
 		Name:        "settings",
 		Description: "Open settings configuration",
@@ -104,6 +164,114 @@ func init() {
 		MaxArgs:     0,
 	})
 
+	registerCommand(&SlashCommand{
+		Name:        "prompt",
+		Description: "Show the system prompt, including the detected environment",
+		Type:        CommandTypeTUI,
+		Handler:     handlePromptCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "cost",
+		Description: "Show token usage and cost per turn",
+		Type:        CommandTypeTUI,
+		Handler:     handleCostCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "model",
+		Description: "List the provider's models (optionally filtered) with context window and price",
+		Type:        CommandTypeTUI,
+		Handler:     handleModelCommand,
+		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "doctor",
+		Description: "Diagnose API key, network, model, git, workspace, terminal and config problems",
+		Type:        CommandTypeTUI,
+		Handler:     handleDoctorCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "audit",
+		Description: "Show the audit log of file changes and commands, and verify it",
+		Type:        CommandTypeTUI,
+		Handler:     handleAuditCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "review-diff",
+		Description: "Review the git diff for a range (default: branch vs base) and list findings",
+		Type:        CommandTypeTUI,
+		Handler:     handleReviewDiffCommand,
+		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "explain",
+		Description: "Map the workspace's modules and their dependencies as a browsable tree (read-only)",
+		Type:        CommandTypeTUI,
+		Handler:     handleExplainCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "docs",
+		Description: "Update doc comments and READMEs for the packages changed in a range (default: this branch)",
+		Type:        CommandTypeTUI,
+		Handler:     handleDocsCommand,
+		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "issue",
+		Description: "Load an issue's description and acceptance criteria as the task",
+		Type:        CommandTypeTUI,
+		Handler:     handleIssueCommand,
+		MinArgs:     1,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "import",
+		Description: "Import a document as context for the next turns, summarized if it doesn't fit",
+		Type:        CommandTypeTUI,
+		Handler:     handleImportCommand,
+		MinArgs:     1,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "open",
+		Description: "Open a file (path or path:line; default: the last one a tool used) in $EDITOR",
+		Type:        CommandTypeTUI,
+		Handler:     handleOpenCommand,
+		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "refs",
+		Description: "List the files tools referenced, to view one at its line or open it in $EDITOR",
+		Type:        CommandTypeTUI,
+		Handler:     handleRefsCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
 	registerCommand(&SlashCommand{
 		Name:        "bash",
 		Description: "Enter bash mode for running shell commands",
@@ -191,7 +359,7 @@ func executeSlashCommand(m *model, commandName string, args []string) (*model, t
 		case func() tea.Msg:
 			// Function that returns a message (also a tea.Cmd, but type switch needs explicit match)
 			// For long-running commands (commit, pr), wrap with busy indicator
-			if commandName == "commit" || commandName == "pr" {
+			if commandName == "commit" || commandName == "pr" || commandName == "release" || commandName == "review-diff" {
 				m.agentBusy = true
 				m.currentLoadingMessage = getRandomLoadingMessage()
 				m.recalculateLayout()
@@ -239,7 +407,12 @@ func handleHelpCommand(m *model, args []string) interface{} {
 	helpContent.WriteString("Keyboard Shortcuts:\n\n")
 	helpContent.WriteString("  Enter        Send message\n")
 	helpContent.WriteString("  Alt+Enter    New line\n")
+	helpContent.WriteString("  Up/Down      Recall previous inputs (when input is empty)\n")
 	helpContent.WriteString("  Ctrl+C       Exit\n")
+	helpContent.WriteString("  Ctrl+O       Compose a long prompt in a full-screen editor\n")
+	helpContent.WriteString("  Ctrl+F       Search the conversation\n")
+	helpContent.WriteString("  Ctrl+G       Open the file last used by a tool in $EDITOR\n")
+	helpContent.WriteString("  Ctrl+R       List the files tools referenced\n")
 	helpContent.WriteString("  Ctrl+D       Show command help\n\n")
 
 	helpContent.WriteString("Tips:\n\n")
@@ -254,8 +427,10 @@ func handleHelpCommand(m *model, args []string) interface{} {
 	return nil
 }
 
-// handleStopCommand stops the current agent operation
+// handleStopCommand stops the current agent operation, along with any slash
+// command or bash mode work still running
 func handleStopCommand(m *model, args []string) interface{} {
+	m.stopCommands()
 	if m.channels != nil {
 		// Send cancel input to agent
 		m.channels.Input <- types.NewCancelInput()
@@ -264,6 +439,40 @@ func handleStopCommand(m *model, args []string) interface{} {
 	return nil
 }
 
+// continueMessage is sent to the agent by /continue
+const continueMessage = "Continue where you left off."
+
+// handleContinueCommand resumes the previous task in a new turn, which grants the
+// agent a fresh loop budget after it stopped on a budget limit
+func handleContinueCommand(m *model, args []string) interface{} {
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish, or /stop it first", "⏳", true)
+		return nil
+	}
+	_, cmd := m.handleAgentMessage(continueMessage, nil, nil, nil)
+	return cmd
+}
+
+// handleSkipOptimizeCommand asks the agent to abandon the context
+// summarization in progress
+func handleSkipOptimizeCommand(m *model, args []string) interface{} {
+	if !m.summarization.active {
+		m.showToast("Nothing to skip", "No context optimization is running", "🧠", true)
+		return nil
+	}
+	if m.channels == nil {
+		return nil
+	}
+
+	select {
+	case m.channels.Cancel <- &types.CancellationRequest{ExecutionID: types.SummarizationExecutionID}:
+		m.showToast("Skipping", "Skipping context optimization", "⏭️", false)
+	default:
+		// Cancel channel full - a cancellation is already pending
+	}
+	return nil
+}
+
 // handleCommitCommand creates a git commit with preview
 func handleCommitCommand(m *model, args []string) interface{} {
 	if m.slashHandler == nil {
@@ -272,13 +481,12 @@ func handleCommitCommand(m *model, args []string) interface{} {
 	}
 
 	commitMessage := strings.Join(args, " ")
+	ctx := m.commandContext()
 
 	// Gather preview data and return approval request
 	return func() tea.Msg {
-		ctx := context.Background()
-
 		// Get modified files
-		files, err := git.GetModifiedFiles(m.workspaceDir)
+		files, err := git.GetModifiedFiles(ctx, m.workspaceDir)
 		if err != nil {
 			return toastMsg{
 				message: "Commit Failed",
@@ -298,7 +506,7 @@ func handleCommitCommand(m *model, args []string) interface{} {
 		}
 
 		// Get diff for preview
-		diff := getDiffForFiles(m.workspaceDir, files)
+		diff := getDiffForFiles(ctx, m.workspaceDir, files)
 
 		// Generate commit message if not provided
 		message := commitMessage
@@ -317,16 +525,16 @@ func handleCommitCommand(m *model, args []string) interface{} {
 
 		// Return approval request instead of command-specific message
 		return approvalRequestMsg{
-			request: approval.NewCommitRequest(files, message, diff, commitMessage, m.slashHandler),
+			request: approval.NewCommitRequest(ctx, files, m.slashHandler.CommitMessage(ctx, message), diff, commitMessage, m.slashHandler),
 		}
 	}
 }
 
 // getDiffForFiles gets the git diff for the specified files
-func getDiffForFiles(workingDir string, files []string) string {
+func getDiffForFiles(ctx context.Context, workingDir string, files []string) string {
 	// Try to get diff against HEAD first (for modified tracked files)
 	args := append([]string{"diff", "HEAD", "--"}, files...)
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = workingDir
 
 	output, err := cmd.Output()
@@ -334,7 +542,7 @@ func getDiffForFiles(workingDir string, files []string) string {
 		// If that fails (new files not in HEAD), try without HEAD
 		// This will show working directory changes
 		args = append([]string{"diff", "--"}, files...)
-		cmd = exec.Command("git", args...)
+		cmd = exec.CommandContext(ctx, "git", args...)
 		cmd.Dir = workingDir
 
 		output, err = cmd.Output()
@@ -349,7 +557,7 @@ func getDiffForFiles(workingDir string, files []string) string {
 	if len(output) == 0 {
 		// Get diff of what would be staged if we add these files
 		args = append([]string{"diff", "--no-index", "/dev/null", "--"}, files...)
-		cmd = exec.Command("git", args...)
+		cmd = exec.CommandContext(ctx, "git", args...)
 		cmd.Dir = workingDir
 
 		output, err = cmd.Output()
@@ -369,12 +577,11 @@ func handlePRCommand(m *model, args []string) interface{} {
 	}
 
 	prTitle := strings.Join(args, " ")
+	ctx := m.commandContext()
 
 	return func() tea.Msg {
-		ctx := context.Background()
-
 		// Get base branch
-		base, err := git.DetectBaseBranch(m.workspaceDir)
+		base, err := git.DetectBaseBranch(ctx, m.workspaceDir)
 		if err != nil {
 			return toastMsg{
 				message: "PR Failed",
@@ -385,7 +592,7 @@ func handlePRCommand(m *model, args []string) interface{} {
 		}
 
 		// Get current branch
-		head, err := getCurrentBranch(m.workspaceDir)
+		head, err := getCurrentBranch(ctx, m.workspaceDir)
 		if err != nil {
 			return toastMsg{
 				message: "PR Failed",
@@ -396,7 +603,7 @@ func handlePRCommand(m *model, args []string) interface{} {
 		}
 
 		// Get commits since base
-		commits, err := git.GetCommitsSinceBase(m.workspaceDir, base, head)
+		commits, err := git.GetCommitsSinceBase(ctx, m.workspaceDir, base, head)
 		if err != nil {
 			return toastMsg{
 				message: "PR Failed",
@@ -416,7 +623,7 @@ func handlePRCommand(m *model, args []string) interface{} {
 		}
 
 		// Get diff summary
-		diffSummary, err := git.GetDiffSummary(m.workspaceDir, base, head)
+		diffSummary, err := git.GetDiffSummary(ctx, m.workspaceDir, base, head)
 		if err != nil {
 			return toastMsg{
 				message: "PR Failed",
@@ -449,14 +656,91 @@ func handlePRCommand(m *model, args []string) interface{} {
 		// Return approval request instead of command-specific message
 		branchInfo := fmt.Sprintf("%s → %s", head, base)
 		return approvalRequestMsg{
-			request: approval.NewPRRequest(branchInfo, prContent.Title, prContent.Description, changesContent.String(), prTitle, m.slashHandler),
+			request: approval.NewPRRequest(ctx, branchInfo, prContent.Title, prContent.Description, changesContent.String(), prTitle, m.slashHandler),
+		}
+	}
+}
+
+// handleChangelogCommand proposes a CHANGELOG.md update for the commits
+// since the last tag, previewed as a diff
+func handleChangelogCommand(m *model, args []string) interface{} {
+	version := ""
+	if len(args) > 0 {
+		version = args[0]
+	}
+	ctx := m.commandContext()
+
+	return func() tea.Msg {
+		proposal, err := changelog.Propose(ctx, m.workspaceDir, changelog.Options{Version: version})
+		if err != nil {
+			return toastMsg{
+				message: "Changelog Failed",
+				details: err.Error(),
+				icon:    "❌",
+				isError: true,
+			}
+		}
+
+		if len(proposal.Release.Entries) == 0 {
+			return toastMsg{
+				message: "Nothing to Add",
+				details: "No commits since " + proposal.Release.Since,
+				icon:    "ℹ️",
+				isError: false,
+			}
+		}
+		if !proposal.Changed() {
+			return toastMsg{
+				message: "Changelog Up to Date",
+				details: changelog.DefaultFile + " already lists these commits",
+				icon:    "ℹ️",
+				isError: false,
+			}
+		}
+
+		diff := coding.GenerateUnifiedDiff(proposal.Original, proposal.Updated, changelog.DefaultFile)
+		return approvalRequestMsg{
+			request: approval.NewChangelogRequest(proposal, diff),
+		}
+	}
+}
+
+// handleReleaseCommand prepares a release and asks about its steps one by
+// one. Arguments are a version or a bump (major, minor or patch), and
+// "github" to also publish the GitHub release.
+func handleReleaseCommand(m *model, args []string) interface{} {
+	opts := release.Options{}
+	for _, arg := range args {
+		switch arg {
+		case "github":
+			opts.GitHub = true
+		case release.BumpMajor, release.BumpMinor, release.BumpPatch:
+			opts.Bump = arg
+		default:
+			opts.Version = arg
+		}
+	}
+	notes, ctx := m.releaseNotes, m.commandContext()
+
+	return func() tea.Msg {
+		plan, err := release.Prepare(ctx, m.workspaceDir, notes, opts)
+		if err != nil {
+			return toastMsg{
+				message: "Release Failed",
+				details: err.Error(),
+				icon:    "❌",
+				isError: true,
+			}
+		}
+		return approvalRequestMsg{
+			request: approval.NewReleaseRequest(ctx, plan),
 		}
 	}
 }
 
 // getCurrentBranch gets the current git branch name
-func getCurrentBranch(workingDir string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+func getCurrentBranch(ctx context.Context, workingDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = workingDir
 
 	output, err := cmd.Output()
@@ -498,6 +782,7 @@ func handleContextCommand(m *model, args []string) interface{} {
 	overlayInfo := &overlay.ContextInfo{
 		SystemPromptTokens:    contextInfo.SystemPromptTokens,
 		CustomInstructions:    contextInfo.CustomInstructions,
+		BasePromptVersion:     contextInfo.BasePromptVersion,
 		ToolCount:             contextInfo.ToolCount,
 		ToolTokens:            contextInfo.ToolTokens,
 		ToolNames:             contextInfo.ToolNames,
@@ -520,6 +805,319 @@ func handleContextCommand(m *model, args []string) interface{} {
 	return nil
 }
 
+// handleCostCommand shows token usage and cost for each turn of the session
+func handleCostCommand(m *model, args []string) interface{} {
+	costOverlay := overlay.NewCostOverlay(m.usage.Turns(), m.usage.Total(), m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeCost, costOverlay)
+
+	return nil
+}
+
+// handleDoctorCommand runs the diagnostic checks in the background; the
+// results open in an overlay when they arrive
+func handleDoctorCommand(m *model, args []string) interface{} {
+	opts := doctor.Options{WorkspaceDir: m.workspaceDir}
+	if m.diagnostics != nil {
+		opts = *m.diagnostics
+	}
+
+	m.showToast("Doctor", "Running diagnostics...", "🩺", false)
+	ctx := m.commandContext()
+	return func() tea.Msg {
+		return doctorResultMsg{results: doctor.Run(ctx, opts)}
+	}
+}
+
+// handleDoctorResult shows the /doctor results
+func (m *model) handleDoctorResult(msg doctorResultMsg) (tea.Model, tea.Cmd) {
+	doctorOverlay := overlay.NewDoctorOverlay(msg.results, m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeDoctor, doctorOverlay)
+	return m, nil
+}
+
+// handleModelCommand shows the provider's models, listing them first if
+// they haven't been yet
+func handleModelCommand(m *model, args []string) interface{} {
+	if m.models == nil {
+		m.showToast("Models", "This provider can't list its models", "❌", true)
+		return nil
+	}
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	if m.modelList != nil {
+		m.showModels(filter)
+		return nil
+	}
+	m.showToast("Models", "Listing models...", "📋", false)
+	return m.listModels(true, filter)
+}
+
+// listModels lists the provider's models in the background
+func (m *model) listModels(show bool, filter string) tea.Cmd {
+	lister := m.models
+	ctx := m.sessionCtx
+	if show {
+		ctx = m.commandContext()
+	}
+	return func() tea.Msg {
+		models, err := lister.ListModels(ctx)
+		return modelsListedMsg{models: models, err: err, show: show, filter: filter}
+	}
+}
+
+// handleModelsListed keeps the provider's models and the prices it publishes
+// for /cost, and shows them if /model asked for them
+func (m *model) handleModelsListed(msg modelsListedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		debugLog.Printf("Failed to list models: %v", msg.err)
+		if msg.show {
+			m.showToast("Error", fmt.Sprintf("Failed to list models: %v", msg.err), "❌", true)
+		}
+		return m, nil
+	}
+
+	m.modelList = msg.models
+	for _, listed := range msg.models {
+		if listed.Priced() {
+			m.usage.SetModelPrice(listed.ID, metrics.Price{Input: listed.InputPrice, Output: listed.OutputPrice})
+		}
+	}
+	if msg.show {
+		m.showModels(msg.filter)
+	}
+	return m, nil
+}
+
+// showModels opens the model list, filtered by filter
+func (m *model) showModels(filter string) {
+	modelsOverlay := overlay.NewModelsOverlay(llm.FilterModels(m.modelList, filter), m.currentModel, filter, m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeModels, modelsOverlay)
+}
+
+// systemPrompter is implemented by agents that can show their system prompt
+type systemPrompter interface {
+	SystemPrompt() string
+}
+
+// handlePromptCommand shows the system prompt the model is sent
+func handlePromptCommand(m *model, args []string) interface{} {
+	prompter, ok := m.agent.(systemPrompter)
+	if !ok {
+		m.showToast("Error", "System prompt not available", "❌", true)
+		return nil
+	}
+
+	promptOverlay := overlay.NewPromptOverlay(prompter.SystemPrompt(), m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModePrompt, promptOverlay)
+
+	return nil
+}
+
+// handleAuditCommand shows the workspace audit log and verifies its hash chain
+func handleAuditCommand(m *model, args []string) interface{} {
+	entries, err := audit.Read(filepath.Join(m.workspaceDir, audit.Path))
+	if err != nil {
+		m.showToast("Error", fmt.Sprintf("Failed to read audit log: %v", err), "❌", true)
+		return nil
+	}
+
+	anchorDir, verifyErr := audit.DefaultAnchorDir()
+	if verifyErr == nil {
+		verifyErr = audit.VerifyLog(m.workspaceDir, anchorDir, entries)
+	}
+	auditOverlay := overlay.NewAuditOverlay(entries, verifyErr, m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeAudit, auditOverlay)
+
+	return nil
+}
+
+// handleReviewDiffCommand reviews the diff for a range in the background; the
+// findings open in an overlay when the review finishes
+func handleReviewDiffCommand(m *model, args []string) interface{} {
+	if m.reviewer == nil {
+		m.showToast("Error", "Code review not available", "❌", true)
+		return nil
+	}
+
+	rangeSpec := ""
+	if len(args) > 0 {
+		rangeSpec = args[0]
+	}
+
+	reviewer, workspaceDir, ctx := m.reviewer, m.workspaceDir, m.commandContext()
+	return func() tea.Msg {
+		result, err := reviewer.ReviewRange(ctx, workspaceDir, rangeSpec)
+		return reviewResultMsg{result: result, err: err}
+	}
+}
+
+// handleReviewResult shows the /review-diff findings
+func (m *model) handleReviewResult(msg reviewResultMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.showToast("Review Failed", msg.err.Error(), "❌", true)
+		return m, nil
+	}
+
+	reviewOverlay := overlay.NewReviewOverlay(msg.result, m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeReview, reviewOverlay)
+	return m, nil
+}
+
+// handleExplainCommand maps the workspace's modules in the background; the
+// walk only reads files and never calls the LLM
+func handleExplainCommand(m *model, args []string) interface{} {
+	workspaceDir, ctx := m.workspaceDir, m.commandContext()
+	return func() tea.Msg {
+		guard, err := workspace.NewGuard(workspaceDir)
+		if err != nil {
+			return moduleMapBuiltMsg{err: err}
+		}
+		moduleMap, err := explain.BuildMap(ctx, guard)
+		return moduleMapBuiltMsg{moduleMap: moduleMap, err: err}
+	}
+}
+
+// handleModuleMapBuilt shows the /explain module map
+func (m *model) handleModuleMapBuilt(msg moduleMapBuiltMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.showToast("Explain Failed", msg.err.Error(), "❌", true)
+		return m, nil
+	}
+
+	explainOverlay := overlay.NewExplainOverlay(msg.moduleMap, m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeExplain, explainOverlay)
+	return m, nil
+}
+
+// handleDocsCommand starts a turn that updates the documentation of the
+// packages changed in a range, and only those
+func handleDocsCommand(m *model, args []string) interface{} {
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish, or /stop it first", "⏳", true)
+		return nil
+	}
+
+	rangeSpec := ""
+	if len(args) > 0 {
+		rangeSpec = args[0]
+	}
+	_, cmd := m.handleAgentMessage(coding.GenerateDocsPrompt(rangeSpec), nil, nil, nil)
+	return cmd
+}
+
+// handleIssueCommand fetches an issue in the background; when it arrives it
+// is sent to the agent as the task definition
+func handleIssueCommand(m *model, args []string) interface{} {
+	if m.issueTracker == nil {
+		m.showToast("Error", "No issue tracker configured (see the issue_tracker setting)", "❌", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish, or /stop it first", "⏳", true)
+		return nil
+	}
+
+	m.showToast("Issue", fmt.Sprintf("Fetching %s...", args[0]), "🎫", false)
+	tracker, id, ctx := m.issueTracker, args[0], m.commandContext()
+	return func() tea.Msg {
+		issue, err := tracker.Get(ctx, id)
+		return issueLoadedMsg{issue: issue, err: err}
+	}
+}
+
+// handleIssueLoaded starts a turn with the /issue issue as the task
+func (m *model) handleIssueLoaded(msg issueLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.showToast("Issue Failed", msg.err.Error(), "❌", true)
+		return m, nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish, then run /issue again", "⏳", true)
+		return m, nil
+	}
+
+	m.showToast("Issue", fmt.Sprintf("%s: %s", msg.issue.ID, msg.issue.Title), "🎫", false)
+	return m.handleAgentMessage(issues.TaskPrompt(msg.issue), nil, nil, nil)
+}
+
+// contextImporter is implemented by agents that take context between turns
+type contextImporter interface {
+	ImportContext(msg *types.Message)
+}
+
+// handleImportCommand fits a document into its share of the context window
+// in the background; when it is ready it is added to the conversation
+func handleImportCommand(m *model, args []string) interface{} {
+	if _, ok := m.agent.(contextImporter); !ok || m.importer == nil {
+		m.showToast("Error", "Importing documents not available", "❌", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish, or /stop it first", "⏳", true)
+		return nil
+	}
+
+	path := args[0]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workspaceDir, path)
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		m.showToast("Import Failed", err.Error(), "❌", true)
+		return nil
+	}
+
+	budget := seed.Budget(m.agent.GetContextInfo().MaxContextTokens, m.importShare)
+	m.showToast("Import", fmt.Sprintf("Fitting %s into %s tokens...", args[0], formatTokenCount(budget)), "📄", false)
+	importer, name, ctx := m.importer, args[0], m.commandContext()
+	return func() tea.Msg {
+		doc, err := importer.Prepare(ctx, name, string(text), budget)
+		return documentImportedMsg{doc: doc, err: err}
+	}
+}
+
+// handleDocumentImported adds the /import document to the conversation
+func (m *model) handleDocumentImported(msg documentImportedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.showToast("Import Failed", msg.err.Error(), "❌", true)
+		return m, nil
+	}
+	importer, ok := m.agent.(contextImporter)
+	if !ok {
+		return m, nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish, then run /import again", "⏳", true)
+		return m, nil
+	}
+
+	importer.ImportContext(msg.doc.Message())
+	m.content.WriteString(formatEntry("  📄 ", "Imported "+msg.doc.Summary(), toolStyle, m.transcriptWidth(), false))
+	m.content.WriteString("\n\n")
+	m.viewport.ShowTranscript("")
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// handleOpenCommand opens a file in the user's editor, suspending the TUI
+// until the editor exits
+func handleOpenCommand(m *model, args []string) interface{} {
+	if len(args) == 0 {
+		_, cmd := m.handleCtrlG()
+		return cmd
+	}
+	return m.openInEditor(parseFileRef(args[0], m.workspaceDir))
+}
+
+// handleRefsCommand lists the files tools referenced
+func handleRefsCommand(m *model, args []string) interface{} {
+	_, cmd := m.handleCtrlR()
+	return cmd
+}
+
 // handleBashCommand enters bash mode for running shell commands
 func handleBashCommand(m *model, args []string) interface{} {
 	m.bashMode = true
@@ -527,3 +1125,34 @@ func handleBashCommand(m *model, args []string) interface{} {
 	m.showToast("Bash Mode", "Entered bash mode. Commands will be executed directly. Type 'exit' or press Ctrl+C to return.", "🔧", false)
 	return nil
 }
+
+// handleChangesCommand shows the full workspace diff since session start,
+// including files changed indirectly by commands and scripts
+func handleChangesCommand(m *model, args []string) interface{} {
+	if m.snapshot == nil {
+		m.showToast("Error", "Workspace snapshot not available (not a git repository)", "❌", true)
+		return nil
+	}
+
+	changes, err := m.snapshot.ChangedFiles(m.commandContext())
+	if err != nil {
+		m.showToast("Error", fmt.Sprintf("Failed to compute changes: %v", err), "❌", true)
+		return nil
+	}
+
+	if len(changes) == 0 {
+		m.showToast("No Changes", "Workspace is unchanged since session start", "ℹ️", false)
+		return nil
+	}
+
+	diff, err := m.snapshot.Diff(m.commandContext())
+	if err != nil {
+		m.showToast("Error", fmt.Sprintf("Failed to compute diff: %v", err), "❌", true)
+		return nil
+	}
+
+	changesOverlay := overlay.NewChangesOverlay(coding.FormatWorkspaceChanges(changes, diff), m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeChanges, changesOverlay)
+
+	return nil
+}
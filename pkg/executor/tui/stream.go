@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamFrameInterval is how often streamed thinking and message text is
+// redrawn. Each redraw re-wraps the whole entry being streamed and resets
+// the viewport's content, so doing it for every token pegs a core with fast
+// models; tokens arriving within a frame are drawn together.
+const streamFrameInterval = time.Second / 30
+
+// streamFrameMsg redraws the text streamed since the last frame
+type streamFrameMsg struct{}
+
+// scheduleStreamFrame returns a command that redraws streamed text at the
+// next frame, or nil if nothing is waiting or a frame is already scheduled
+func (m *model) scheduleStreamFrame() tea.Cmd {
+	if !m.streamDirty || m.streamFramePending {
+		return nil
+	}
+	m.streamFramePending = true
+	return tea.Tick(streamFrameInterval, func(time.Time) tea.Msg {
+		return streamFrameMsg{}
+	})
+}
+
+// handleStreamFrame draws the text streamed since the last frame
+func (m *model) handleStreamFrame() (tea.Model, tea.Cmd) {
+	m.streamFramePending = false
+	if m.streamDirty {
+		m.renderStream()
+	}
+	return m, nil
+}
+
+// renderStream shows the transcript followed by the entry being streamed.
+// An open search keeps its view; the text appears once the search closes.
+func (m *model) renderStream() {
+	m.streamDirty = false
+	if m.search.active {
+		return
+	}
+	m.viewport.ShowTranscript(m.streamingEntry())
+	m.viewport.GotoBottom()
+}
+
+// streamingEntry formats the thinking or message text still being streamed
+func (m *model) streamingEntry() string {
+	switch {
+	case m.isThinking && m.thinkingBuffer.Len() > 0:
+		return "💭 Thinking " + formatEntry("", m.thinkingBuffer.String(), thinkingStyle, m.transcriptWidth(), false)
+	case m.messageBuffer.Len() > 0:
+		return renderMarkdown(m.messageBuffer.String(), m.transcriptWidth())
+	default:
+		return ""
+	}
+}
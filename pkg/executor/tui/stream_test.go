@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// newStreamModel returns a model with some transcript, as mid-session
+func newStreamModel() *model {
+	m := initialModel()
+	m.width = 100
+	m.viewport.Width, m.viewport.Height = 100, 30
+	for i := 0; i < 200; i++ {
+		m.content.WriteString(formatEntry("", "Earlier output line with enough words to wrap across the viewport once in a while.", thinkingStyle, m.width, false))
+		m.content.WriteString("\n")
+	}
+	return &m
+}
+
+func TestStreamFrame_CoalescesTokens(t *testing.T) {
+	m := newStreamModel()
+	before := m.viewport.TotalLineCount()
+
+	for _, token := range []string{"Hello", ", ", "world"} {
+		m.handleMessageContent(token)
+	}
+	if m.viewport.TotalLineCount() != before {
+		t.Fatal("expected tokens not to be drawn before the frame")
+	}
+
+	cmd := m.scheduleStreamFrame()
+	if cmd == nil {
+		t.Fatal("expected a frame to be scheduled")
+	}
+	if m.scheduleStreamFrame() != nil {
+		t.Error("expected only one frame to be scheduled at a time")
+	}
+
+	m.handleStreamFrame()
+	if !strings.Contains(m.viewport.View(), "Hello, world") {
+		t.Errorf("expected the streamed text after the frame, got:\n%s", m.viewport.View())
+	}
+	if m.streamDirty || m.streamFramePending {
+		t.Error("expected the frame to clear the pending state")
+	}
+	if m.scheduleStreamFrame() != nil {
+		t.Error("expected no frame without new text")
+	}
+}
+
+func TestStreamFrame_Thinking(t *testing.T) {
+	m := newStreamModel()
+	m.handleThinkingStart()
+	m.handleThinkingContent(&types.AgentEvent{Content: "Considering the parser"})
+	m.handleStreamFrame()
+
+	if !strings.Contains(m.viewport.View(), "Thinking Considering the parser") {
+		t.Errorf("expected the thinking text after the frame, got:\n%s", m.viewport.View())
+	}
+
+	m.handleThinkingEnd()
+	if m.streamDirty {
+		t.Error("expected the end of thinking to leave nothing to draw")
+	}
+}
+
+// BenchmarkStreamMessage measures drawing a 2,000-token reply into a
+// 200-line transcript. per-token is how streaming was drawn before frames;
+// per-frame draws once every 5 tokens, a model producing 150 tokens/s at
+// 30 frames/s.
+func BenchmarkStreamMessage(b *testing.B) {
+	tokens := strings.Fields(strings.Repeat("the quick brown fox jumps over the lazy dog ", 223))[:2000]
+
+	for _, bench := range []struct {
+		name            string
+		tokensPerRedraw int
+	}{
+		{"per-token", 1},
+		{"per-frame", 5},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m := newStreamModel()
+				for n, token := range tokens {
+					m.handleMessageContent(token + " ")
+					if (n+1)%bench.tokensPerRedraw == 0 {
+						m.renderStream()
+					}
+				}
+				m.renderStream()
+			}
+		})
+	}
+}
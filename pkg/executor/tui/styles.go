@@ -41,6 +41,13 @@ var (
 	toolResultStyle = lipgloss.NewStyle().
 			Foreground(brightWhite)
 
+	fileRefStyle = lipgloss.NewStyle().
+			Foreground(mintGreen).
+			Underline(true)
+
+	fileRefHintStyle = lipgloss.NewStyle().
+				Foreground(mutedGray)
+
 	errorStyle = lipgloss.NewStyle().
 			Foreground(salmonPink)
 
@@ -0,0 +1,96 @@
+package syntax
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// maxCachedHunks is how many highlighted hunks are kept. Diffs shown again,
+// such as a retried edit or the session's changes, only highlight hunks that
+// changed since.
+const maxCachedHunks = 512
+
+// hunkKey identifies a run of diff lines highlighted with a lexer
+type hunkKey struct {
+	sum   uint64
+	lexer string
+}
+
+// hunkCache keeps highlighted hunks, evicting the least recently used
+type hunkCache struct {
+	mu      sync.Mutex
+	hunks   map[hunkKey][]string
+	order   []hunkKey // LRU order (oldest first)
+	maxSize int
+}
+
+// highlighted is shared by every diff highlighted in the process
+var highlighted = newHunkCache(maxCachedHunks)
+
+// newHunkCache creates a hunk cache holding up to maxSize hunks
+func newHunkCache(maxSize int) *hunkCache {
+	return &hunkCache{
+		hunks:   make(map[hunkKey][]string),
+		order:   make([]hunkKey, 0, maxSize),
+		maxSize: maxSize,
+	}
+}
+
+// newHunkKey hashes the lines' markers and content
+func newHunkKey(lines []DiffLine, lexer string) hunkKey {
+	h := fnv.New64a()
+	for _, line := range lines {
+		h.Write([]byte(line.Marker))
+		h.Write([]byte(line.Content))
+		h.Write([]byte{'\n'})
+	}
+	return hunkKey{sum: h.Sum64(), lexer: lexer}
+}
+
+// get returns the highlighted lines of a hunk, marking it recently used
+func (c *hunkCache) get(key hunkKey) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lines, ok := c.hunks[key]
+	if ok {
+		c.touch(key)
+	}
+	return lines, ok
+}
+
+// store adds a highlighted hunk, evicting the oldest if necessary
+func (c *hunkCache) store(key hunkKey, lines []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.hunks[key]; exists {
+		c.hunks[key] = lines
+		c.touch(key)
+		return
+	}
+	if len(c.hunks) >= c.maxSize {
+		delete(c.hunks, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.hunks[key] = lines
+	c.order = append(c.order, key)
+}
+
+// touch moves key to the end of the LRU order. Must be called with the lock held.
+func (c *hunkCache) touch(key hunkKey) {
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, key)
+}
+
+// len returns the number of cached hunks
+func (c *hunkCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.hunks)
+}
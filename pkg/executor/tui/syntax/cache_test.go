@@ -0,0 +1,117 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// largeDiff returns a diff adding n lines of Go in hunks of 50
+func largeDiff(n int, tag string) string {
+	var b strings.Builder
+	b.WriteString("--- a/main.go\n+++ b/main.go\n")
+	for i := 0; i < n; i++ {
+		if i%50 == 0 {
+			fmt.Fprintf(&b, "@@ -%d,50 +%d,50 @@\n", i+1, i+1)
+		}
+		fmt.Fprintf(&b, "+\tif err := run(ctx, %d, %q); err != nil { // %s\n", i, tag, tag)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func TestHighlightDiff_ReusesHunks(t *testing.T) {
+	highlighted = newHunkCache(maxCachedHunks)
+	diff := largeDiff(200, "first")
+
+	first, err := HighlightDiff(diff, "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if highlighted.len() != 4 {
+		t.Fatalf("expected 4 cached hunks, got %d", highlighted.len())
+	}
+
+	second, _ := HighlightDiff(diff, "go")
+	if second != first {
+		t.Error("expected the cached highlighting to match")
+	}
+
+	// Changing one hunk highlights only that one
+	lines := strings.Split(diff, "\n")
+	lines[5] = "+\tchanged()"
+	changed, _ := HighlightDiff(strings.Join(lines, "\n"), "go")
+	if highlighted.len() != 5 {
+		t.Errorf("expected one more cached hunk, got %d", highlighted.len())
+	}
+	if !strings.Contains(changed, "changed") {
+		t.Error("expected the changed line in the output")
+	}
+
+	// The same code in another language is highlighted again
+	_, _ = HighlightDiff(diff, "python")
+	if highlighted.len() != 9 {
+		t.Errorf("expected hunks cached per lexer, got %d", highlighted.len())
+	}
+}
+
+func TestHighlightDiff_MatchesLineByLine(t *testing.T) {
+	highlighted = newHunkCache(maxCachedHunks)
+	diff := "@@ -1,4 +1,4 @@\n package main\n-// old comment\n+/* new\n+\n+comment */\n \tx := `raw`"
+	lines := parseDiffLines(diff)
+
+	out, _ := HighlightDiff(diff, "go")
+	got := strings.Split(out, "\n")
+	for i, line := range lines[1:] {
+		want := applyDiffColorToLine(line)
+		if line.Content != "" {
+			code, err := HighlightCode(line.Content, "go")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = decorateDiffLine(line, strings.TrimSuffix(code, "\n"))
+		}
+		if got[i+1] != want {
+			t.Errorf("line %d = %q, want %q", i+1, got[i+1], want)
+		}
+	}
+}
+
+func TestHunkCache_EvictsOldest(t *testing.T) {
+	c := newHunkCache(2)
+	a, b, d := hunkKey{sum: 1}, hunkKey{sum: 2}, hunkKey{sum: 3}
+	c.store(a, []string{"a"})
+	c.store(b, []string{"b"})
+	c.get(a)
+	c.store(d, []string{"d"})
+
+	if _, ok := c.get(b); ok {
+		t.Error("expected the least recently used hunk to be evicted")
+	}
+	if _, ok := c.get(a); !ok {
+		t.Error("expected the recently used hunk to be kept")
+	}
+}
+
+// BenchmarkHighlightDiff highlights a 2,000-line diff, uncached and with
+// one hunk changed since it was last shown
+func BenchmarkHighlightDiff(b *testing.B) {
+	diff := largeDiff(2000, "bench")
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			highlighted = newHunkCache(maxCachedHunks)
+			_, _ = HighlightDiff(diff, "go")
+		}
+	})
+
+	b.Run("one hunk changed", func(b *testing.B) {
+		highlighted = newHunkCache(maxCachedHunks)
+		_, _ = HighlightDiff(diff, "go")
+		lines := strings.Split(diff, "\n")
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			lines[5] = fmt.Sprintf("+\tchanged(%d)", i)
+			_, _ = HighlightDiff(strings.Join(lines, "\n"), "go")
+		}
+	})
+}
@@ -59,19 +59,29 @@ func HighlightDiff(diffContent, language string) (string, error) {
 		style = styles.Fallback
 	}
 
-	// Process each line
-	var result strings.Builder
-	for _, line := range lines {
-		highlightedLine, err := highlightDiffLine(line, lexer, formatter, style)
-		if err != nil {
-			// If highlighting fails, fall back to colored marker only
-			highlightedLine = applyDiffColorToLine(line)
+	// Headers are colored as they are; the code between them is highlighted
+	// a hunk at a time, reusing hunks highlighted before
+	result := make([]string, 0, len(lines))
+	for start := 0; start < len(lines); {
+		if isMarkerLine(lines[start]) {
+			result = append(result, applyDiffColorToLine(lines[start]))
+			start++
+			continue
 		}
-		result.WriteString(highlightedLine)
-		result.WriteString("\n")
+		end := start
+		for end < len(lines) && !isMarkerLine(lines[end]) {
+			end++
+		}
+		result = append(result, highlightHunk(lines[start:end], lexer, formatter, style)...)
+		start = end
 	}
 
-	return strings.TrimSuffix(result.String(), "\n"), nil
+	return strings.Join(result, "\n"), nil
+}
+
+// isMarkerLine reports whether line is a file or hunk header
+func isMarkerLine(line DiffLine) bool {
+	return line.Type == DiffLineHeader || line.Type == DiffLineHunk
 }
 
 // parseDiffLines parses unified diff content into structured lines
@@ -138,37 +148,73 @@ func parseDiffLines(diffContent string) []DiffLine {
 	return result
 }
 
-// highlightDiffLine highlights a single diff line
-func highlightDiffLine(line DiffLine, lexer chroma.Lexer, formatter chroma.Formatter, style *chroma.Style) (string, error) {
-	// For headers and hunks, just apply the appropriate color
-	switch line.Type {
-	case DiffLineHeader:
-		return lipgloss.NewStyle().Foreground(types.DiffHeaderColor).Render(line.Marker + line.Content), nil
-	case DiffLineHunk:
-		return lipgloss.NewStyle().Foreground(types.DiffHunkColor).Render(line.Marker + line.Content), nil
+// highlightHunk highlights the code lines of a hunk, or returns them from
+// the cache when the same lines were highlighted before
+func highlightHunk(lines []DiffLine, lexer chroma.Lexer, formatter chroma.Formatter, style *chroma.Style) []string {
+	key := newHunkKey(lines, lexer.Config().Name)
+	if cached, ok := highlighted.get(key); ok {
+		return cached
 	}
 
-	// For code lines (additions, deletions, context), highlight the code portion
-	if line.Content == "" {
-		return applyDiffColorToLine(line), nil
+	code := highlightCodeLines(lines, lexer, formatter, style)
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		if content, ok := code[i]; ok {
+			result[i] = decorateDiffLine(line, content)
+		} else {
+			// Fall back to colored marker only
+			result[i] = applyDiffColorToLine(line)
+		}
 	}
 
-	// Tokenize and highlight the code content
-	iterator, err := lexer.Tokenise(nil, line.Content)
-	if err != nil {
-		return "", err
+	highlighted.store(key, result)
+	return result
+}
+
+// highlightCodeLines highlights the content of each line, keyed by its
+// index. Lines are lexed on their own but formatted in one pass, since the
+// formatter builds its color table on every call. Lines that are empty or
+// fail to lex are left out.
+func highlightCodeLines(lines []DiffLine, lexer chroma.Lexer, formatter chroma.Formatter, style *chroma.Style) map[int]string {
+	var tokens []chroma.Token
+	var indices []int
+	for i, line := range lines {
+		if line.Content == "" {
+			continue
+		}
+		lineTokens, err := chroma.Tokenise(lexer, nil, line.Content)
+		if err != nil || len(lineTokens) == 0 {
+			continue
+		}
+		// End the line on its last token, so the formatter closes its
+		// color before the newline
+		lineTokens[len(lineTokens)-1].Value += "\n"
+		tokens = append(tokens, lineTokens...)
+		indices = append(indices, i)
+	}
+	if len(tokens) == 0 {
+		return nil
 	}
 
 	var buf bytes.Buffer
-	err = formatter.Format(&buf, style, iterator)
-	if err != nil {
-		return "", err
+	if err := formatter.Format(&buf, style, chroma.Literator(tokens...)); err != nil {
+		return nil
+	}
+	formatted := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(formatted) != len(indices) {
+		// A token carried a newline of its own; lines can't be told apart
+		return nil
 	}
 
-	highlightedContent := buf.String()
-	// Remove trailing newline if present
-	highlightedContent = strings.TrimSuffix(highlightedContent, "\n")
+	code := make(map[int]string, len(indices))
+	for j, i := range indices {
+		code[i] = formatted[j]
+	}
+	return code
+}
 
+// decorateDiffLine combines a code line's marker with its highlighted content
+func decorateDiffLine(line DiffLine, highlightedContent string) string {
 	// Build the complete line with marker, syntax-highlighted content, and background
 	var marker string
 	var contentWithBg string
@@ -202,7 +248,7 @@ func highlightDiffLine(line DiffLine, lexer chroma.Lexer, formatter chroma.Forma
 	}
 
 	// Combine marker with background-styled content
-	return marker + contentWithBg, nil
+	return marker + contentWithBg
 }
 
 // applyDiffColorsOnly applies only diff marker colors without syntax highlighting
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderRingSize is how many rendered transcript lines are kept for reuse.
+// It covers several screens, so scrolling back and forth near the same spot
+// and redrawing while output streams don't render a line twice.
+const renderRingSize = 512
+
+// transcript is the session's rendered output. It is kept as lines so that
+// appending output and drawing the visible part cost the same however long
+// the session has run, instead of rebuilding and splitting the whole string
+// on every event.
+type transcript struct {
+	lines      []string        // Complete lines
+	partial    strings.Builder // Text after the last newline
+	size       int
+	generation int // Incremented by Reset, so rendered lines can be told apart
+}
+
+// newTranscript creates an empty transcript
+func newTranscript() *transcript {
+	return &transcript{}
+}
+
+// WriteString appends rendered output
+func (t *transcript) WriteString(s string) (int, error) {
+	n := len(s)
+	t.size += n
+	if strings.Contains(s, "\r\n") {
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+	}
+	for {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			t.partial.WriteString(s)
+			return n, nil
+		}
+		t.partial.WriteString(s[:i])
+		t.lines = append(t.lines, t.partial.String())
+		t.partial.Reset()
+		s = s[i+1:]
+	}
+}
+
+// String returns the whole transcript
+func (t *transcript) String() string {
+	var b strings.Builder
+	b.Grow(t.size)
+	for _, line := range t.lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteString(t.partial.String())
+	return b.String()
+}
+
+// Len returns the number of bytes written
+func (t *transcript) Len() int {
+	return t.size
+}
+
+// Reset empties the transcript
+func (t *transcript) Reset() {
+	t.lines = nil
+	t.partial.Reset()
+	t.size = 0
+	t.generation++
+}
+
+// renderedLine is a transcript line fitted to the view's width
+type renderedLine struct {
+	index      int
+	generation int
+	text       string
+}
+
+// transcriptView shows the part of a transcript that fits its window. It
+// stands in for bubbles' viewport, whose SetContent splits and measures all
+// of its content on every call: here only visible lines are rendered, when
+// they are drawn, and complete lines are kept in a ring so redraws and
+// scrolling reuse them until the width changes.
+//
+// The view follows its transcript as output is written, and may show extra
+// lines after it, such as the entry being streamed. SetContent shows other
+// content instead, such as the transcript with search matches highlighted.
+type transcriptView struct {
+	Width           int
+	Height          int
+	YOffset         int
+	Style           lipgloss.Style
+	MouseWheelDelta int
+
+	source  *transcript
+	extra   []string // Lines continuing the source: the first joins its last line
+	content []string // Shown instead of the source when set
+
+	ring      []renderedLine
+	ringWidth int
+}
+
+// newTranscriptView creates a view of source
+func newTranscriptView(width, height int, source *transcript) transcriptView {
+	return transcriptView{
+		Width:           width,
+		Height:          height,
+		MouseWheelDelta: 3,
+		source:          source,
+		ring:            make([]renderedLine, renderRingSize),
+	}
+}
+
+// ShowTranscript shows the transcript followed by extra, which continues its
+// last line
+func (v *transcriptView) ShowTranscript(extra string) {
+	v.content = nil
+	v.extra = nil
+	if extra != "" {
+		v.extra = strings.Split(strings.ReplaceAll(extra, "\r\n", "\n"), "\n")
+	}
+	v.clampOffset()
+}
+
+// SetContent shows s instead of the transcript until ShowTranscript is called
+func (v *transcriptView) SetContent(s string) {
+	v.content = strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	v.extra = nil
+	v.clampOffset()
+}
+
+// TotalLineCount returns the number of lines shown and scrolled past
+func (v *transcriptView) TotalLineCount() int {
+	if v.content != nil {
+		return len(v.content)
+	}
+	return len(v.source.lines) + max(1, len(v.extra))
+}
+
+// line returns line i of what the view shows
+func (v *transcriptView) line(i int) string {
+	if v.content != nil {
+		return v.content[i]
+	}
+	complete := len(v.source.lines)
+	switch {
+	case i < complete:
+		return v.source.lines[i]
+	case i == complete && len(v.extra) > 0:
+		return v.source.partial.String() + v.extra[0]
+	case i == complete:
+		return v.source.partial.String()
+	default:
+		return v.extra[i-complete]
+	}
+}
+
+// visibleHeight is the number of lines that fit inside the view's frame
+func (v *transcriptView) visibleHeight() int {
+	return max(0, v.Height-v.Style.GetVerticalFrameSize())
+}
+
+// maxYOffset is the offset that shows the last line at the bottom
+func (v *transcriptView) maxYOffset() int {
+	return max(0, v.TotalLineCount()-v.visibleHeight())
+}
+
+// clampOffset keeps the offset within the content, which may have shrunk
+func (v *transcriptView) clampOffset() {
+	v.YOffset = min(max(v.YOffset, 0), v.maxYOffset())
+}
+
+// SetYOffset scrolls so that line n is at the top
+func (v *transcriptView) SetYOffset(n int) {
+	v.YOffset = n
+	v.clampOffset()
+}
+
+// GotoTop scrolls to the first line
+func (v *transcriptView) GotoTop() {
+	v.YOffset = 0
+}
+
+// GotoBottom scrolls to the last line
+func (v *transcriptView) GotoBottom() {
+	v.YOffset = v.maxYOffset()
+}
+
+// AtTop reports whether the first line is shown
+func (v *transcriptView) AtTop() bool {
+	return v.YOffset <= 0
+}
+
+// AtBottom reports whether the last line is shown
+func (v *transcriptView) AtBottom() bool {
+	return v.YOffset >= v.maxYOffset()
+}
+
+// ScrollUp scrolls up by n lines
+func (v *transcriptView) ScrollUp(n int) {
+	v.SetYOffset(v.YOffset - n)
+}
+
+// ScrollDown scrolls down by n lines
+func (v *transcriptView) ScrollDown(n int) {
+	v.SetYOffset(v.YOffset + n)
+}
+
+// Update scrolls with the mouse wheel. Keys are left to the model, which
+// uses them for the input.
+func (v transcriptView) Update(msg tea.Msg) (transcriptView, tea.Cmd) {
+	mouse, ok := msg.(tea.MouseMsg)
+	if !ok || mouse.Action != tea.MouseActionPress {
+		return v, nil
+	}
+	switch mouse.Button { //nolint:exhaustive
+	case tea.MouseButtonWheelUp:
+		v.ScrollUp(v.MouseWheelDelta)
+	case tea.MouseButtonWheelDown:
+		v.ScrollDown(v.MouseWheelDelta)
+	}
+	return v, nil
+}
+
+// View renders the visible lines, padded to fill the view. As with bubbles'
+// viewport, a line wider than the view wraps onto the rows below it.
+func (v *transcriptView) View() string {
+	width := max(0, v.Width-v.Style.GetHorizontalFrameSize())
+	height := v.visibleHeight()
+	if width != v.ringWidth {
+		v.ring = make([]renderedLine, renderRingSize)
+		v.ringWidth = width
+	}
+
+	v.clampOffset()
+	total := v.TotalLineCount()
+	rows := make([]string, 0, height)
+	for i := v.YOffset; i < total && len(rows) < height; i++ {
+		rows = append(rows, strings.Split(v.renderLine(i, width), "\n")...)
+	}
+	if len(rows) > height {
+		rows = rows[:height]
+	}
+	blank := strings.Repeat(" ", width)
+	for len(rows) < height {
+		rows = append(rows, blank)
+	}
+	return v.Style.UnsetWidth().UnsetHeight().Render(strings.Join(rows, "\n"))
+}
+
+// renderLine fits line i to width, reusing the ring for complete
+// transcript lines, which never change
+func (v *transcriptView) renderLine(i, width int) string {
+	cacheable := v.content == nil && i < len(v.source.lines)
+	slot := &v.ring[i%len(v.ring)]
+	if cacheable && slot.text != "" && slot.index == i && slot.generation == v.source.generation {
+		return slot.text
+	}
+
+	text := lipgloss.NewStyle().Width(width).Render(v.line(i))
+	if cacheable {
+		*slot = renderedLine{index: i, generation: v.source.generation, text: text}
+	}
+	return text
+}
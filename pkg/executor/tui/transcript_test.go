@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTranscript_WriteString(t *testing.T) {
+	tr := newTranscript()
+	_, _ = tr.WriteString("one\ntw")
+	_, _ = tr.WriteString("o\r\nthree")
+
+	if want := "one\ntwo\nthree"; tr.String() != want {
+		t.Errorf("String() = %q, want %q", tr.String(), want)
+	}
+	if len(tr.lines) != 2 || tr.partial.String() != "three" {
+		t.Errorf("expected two complete lines and a partial one, got %q + %q", tr.lines, tr.partial.String())
+	}
+
+	tr.Reset()
+	if tr.String() != "" || tr.Len() != 0 {
+		t.Errorf("expected an empty transcript after Reset, got %q", tr.String())
+	}
+}
+
+// numberedView returns a view of a transcript of n numbered lines
+func numberedView(n, height int) (*transcript, transcriptView) {
+	tr := newTranscript()
+	for i := 0; i < n; i++ {
+		_, _ = tr.WriteString(fmt.Sprintf("line %d\n", i))
+	}
+	return tr, newTranscriptView(20, height, tr)
+}
+
+func TestTranscriptView_ShowsVisibleLines(t *testing.T) {
+	tr, v := numberedView(100, 5)
+	v.GotoBottom()
+
+	rows := strings.Split(v.View(), "\n")
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(rows))
+	}
+	if strings.TrimSpace(rows[0]) != "line 96" || strings.TrimSpace(rows[3]) != "line 99" || strings.TrimSpace(rows[4]) != "" {
+		t.Errorf("unexpected rows: %q", rows)
+	}
+
+	// The view follows the transcript, with the streamed entry after it
+	_, _ = tr.WriteString("line 100\n")
+	v.ShowTranscript("streaming\nreply")
+	v.GotoBottom()
+	rows = strings.Split(v.View(), "\n")
+	if strings.TrimSpace(rows[3]) != "streaming" || strings.TrimSpace(rows[4]) != "reply" {
+		t.Errorf("expected the streamed entry at the bottom, got %q", rows)
+	}
+}
+
+func TestTranscriptView_SetContent(t *testing.T) {
+	_, v := numberedView(10, 3)
+	v.SetContent("a\nb")
+	if v.TotalLineCount() != 2 || !strings.HasPrefix(v.View(), "a") {
+		t.Errorf("expected the set content, got %q", v.View())
+	}
+
+	v.ShowTranscript("")
+	if v.TotalLineCount() != 11 {
+		t.Errorf("expected the transcript again, got %d lines", v.TotalLineCount())
+	}
+}
+
+func TestTranscriptView_WrapsLongLines(t *testing.T) {
+	tr := newTranscript()
+	_, _ = tr.WriteString("aaaa bbbb cccc dddd eeee ffff\nnext\n")
+	v := newTranscriptView(10, 4, tr)
+
+	rows := strings.Split(v.View(), "\n")
+	if strings.TrimSpace(rows[0]) != "aaaa bbbb" || strings.TrimSpace(rows[3]) != "next" {
+		t.Errorf("expected the long line to wrap, got %q", rows)
+	}
+}
+
+func TestTranscriptView_MouseWheel(t *testing.T) {
+	_, v := numberedView(100, 10)
+	v.GotoBottom()
+
+	v, _ = v.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	if v.YOffset != 88 {
+		t.Errorf("YOffset = %d, want 88", v.YOffset)
+	}
+	v, _ = v.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if !v.AtBottom() {
+		t.Error("expected to be back at the bottom")
+	}
+}
+
+// BenchmarkTranscriptEvent measures one agent event in a 20,000-line
+// session: appending a line, scrolling to the bottom and drawing the view,
+// with bubbles' viewport as before and with transcriptView.
+func BenchmarkTranscriptEvent(b *testing.B) {
+	const lines = 20000
+	entry := formatEntry("    ✓ ", "Read 120 lines from pkg/executor/tui/update.go", toolResultStyle, 120, false) + "\n"
+
+	b.Run("viewport", func(b *testing.B) {
+		var content strings.Builder
+		for i := 0; i < lines; i++ {
+			content.WriteString(entry)
+		}
+		vp := viewport.New(116, 40)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			content.WriteString(entry)
+			vp.SetContent(content.String())
+			vp.GotoBottom()
+			_ = vp.View()
+		}
+	})
+
+	b.Run("transcript", func(b *testing.B) {
+		tr := newTranscript()
+		for i := 0; i < lines; i++ {
+			_, _ = tr.WriteString(entry)
+		}
+		v := newTranscriptView(116, 40, tr)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = tr.WriteString(entry)
+			v.ShowTranscript("")
+			v.GotoBottom()
+			_ = v.View()
+		}
+	})
+}
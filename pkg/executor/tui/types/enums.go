@@ -22,4 +22,26 @@ const (
 	OverlayModeContext
 	// OverlayModeToolResult shows full tool result overlay
 	OverlayModeToolResult
+	// OverlayModeChanges shows the workspace changes since session start
+	OverlayModeChanges
+	// OverlayModeCompose shows the full-screen multi-line compose editor
+	OverlayModeCompose
+	// OverlayModeCost shows the per-turn token usage and cost breakdown
+	OverlayModeCost
+	// OverlayModeDoctor shows the /doctor diagnostic results
+	OverlayModeDoctor
+	// OverlayModeAudit shows the workspace audit log
+	OverlayModeAudit
+	// OverlayModeReview shows the findings of a /review-diff review
+	OverlayModeReview
+	// OverlayModeModels shows the provider's models for /model
+	OverlayModeModels
+	// OverlayModeReferences shows the files tools referenced this session
+	OverlayModeReferences
+	// OverlayModeFileView shows a file at a line
+	OverlayModeFileView
+	// OverlayModeExplain shows the workspace's module map for /explain
+	OverlayModeExplain
+	// OverlayModePrompt shows the system prompt for /prompt
+	OverlayModePrompt
 )
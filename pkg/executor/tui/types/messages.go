@@ -7,6 +7,18 @@ import (
 // AgentErrMsg represents an error from agent operations
 type AgentErrMsg struct{ Err error }
 
+// OpenFileMsg asks the TUI to open a file in the user's editor
+type OpenFileMsg struct {
+	Path string // Relative to the workspace, or absolute
+	Line int    // 1-based; 0 for the start of the file
+}
+
+// ViewFileMsg asks the TUI to show a file in the file viewer overlay
+type ViewFileMsg struct {
+	Path string // Relative to the workspace, or absolute
+	Line int    // 1-based line to scroll to; 0 for the start of the file
+}
+
 // SlashCommandCompleteMsg signals that a slash command has completed
 type SlashCommandCompleteMsg struct{}
 
@@ -56,6 +68,11 @@ type ToastMsg struct {
 	IsError bool
 }
 
+// ComposeSubmitMsg is sent when a prompt written in compose mode is submitted
+type ComposeSubmitMsg struct {
+	Content string
+}
+
 // ViewResultMsg is sent when a result is selected from the list
 type ViewResultMsg struct {
 	ResultID string
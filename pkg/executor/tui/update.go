@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/executor/tui/approval"
 	"github.com/entrhq/forge/pkg/executor/tui/overlay"
 	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
 	"github.com/entrhq/forge/pkg/types"
@@ -59,9 +60,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.commandPalette.IsActive() {
 		switch keyMsg.Type {
 		case tea.KeyEsc:
-			// Cancel command palette
+			// Cancel command palette, bringing back any draft it displaced
 			m.commandPalette.Deactivate()
 			m.textarea.Reset()
+			m.restoreDraft()
 			return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 		case tea.KeyUp:
 			// Navigate up in palette, don't update textarea
@@ -93,9 +95,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// For other keys, continue to textarea update below
 	}
 
-	// Only update textarea if no overlay or result list is active
+	// Only update textarea if no overlay, result list or search is active
 	// This prevents the textarea from capturing scroll events when an overlay is open
-	if !m.overlay.isActive() && !m.resultList.IsActive() {
+	if !m.overlay.isActive() && !m.resultList.IsActive() && !m.search.active {
 		// Store old textarea height to detect changes
 		oldHeight := m.textarea.Height()
 		m.textarea, tiCmd = m.textarea.Update(msg)
@@ -133,6 +135,10 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		debugLog.Printf("Received viewResultMsg")
 		return m.handleViewResult(msg)
 
+	case tuitypes.ComposeSubmitMsg:
+		debugLog.Printf("Received ComposeSubmitMsg")
+		return m.handleComposeSubmit(msg)
+
 	case tea.WindowSizeMsg:
 		debugLog.Printf("Received tea.WindowSizeMsg: width=%d, height=%d", msg.Width, msg.Height)
 		return m.handleWindowResize(msg)
@@ -166,6 +172,42 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			errorIcon:    msg.ErrorIcon,
 		})
 
+	case doctorResultMsg:
+		return m.handleDoctorResult(msg)
+
+	case modelsListedMsg:
+		return m.handleModelsListed(msg)
+
+	case reviewResultMsg:
+		return m.handleReviewResult(msg)
+
+	case moduleMapBuiltMsg:
+		return m.handleModuleMapBuilt(msg)
+
+	case issueLoadedMsg:
+		return m.handleIssueLoaded(msg)
+
+	case documentImportedMsg:
+		return m.handleDocumentImported(msg)
+
+	case tuitypes.OpenFileMsg:
+		return m.handleOpenFile(msg)
+
+	case tuitypes.ViewFileMsg:
+		return m.handleViewFile(msg)
+
+	case editorClosedMsg:
+		return m.handleEditorClosed(msg)
+
+	case bridgeSelectionMsg:
+		return m.handleBridgeSelection(msg)
+
+	case streamFrameMsg:
+		return m.handleStreamFrame()
+
+	case panelRefreshedMsg:
+		return m, nil // Redrawn with the refreshed files
+
 	case toastMsg:
 		debugLog.Printf("Received toastMsg: %s", msg.message)
 		return m.handleToast(msg)
@@ -191,8 +233,13 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		debugLog.Printf("Received approvalRequestMsg")
 		return m.handleApprovalRequest(msg)
 
+	case approval.RequestMsg:
+		// An approved request asking about its next step
+		return m.handleApprovalRequest(approvalRequestMsg{request: msg.Request})
+
 	case *types.AgentEvent:
 		debugLog.Printf("Received *types.AgentEvent: %s", msg.Type)
+		m.eventLog.recordEvent(msg)
 
 		// If overlay is active and it's a command execution event, forward to overlay
 		if m.overlay.isActive() && msg.IsCommandExecutionEvent() {
@@ -200,13 +247,17 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.overlay.overlay, overlayCmd = m.overlay.overlay.Update(msg, m, m)
 			// Still handle the event in the main model too
 			m.handleAgentEvent(msg)
-			return m, tea.Batch(tiCmd, vpCmd, overlayCmd, spinnerCmd)
+			return m, tea.Batch(tiCmd, vpCmd, overlayCmd, spinnerCmd, m.scheduleStreamFrame())
 		}
 
 		// Update viewport BEFORE handling event (important for streaming)
 		m.viewport, vpCmd = m.viewport.Update(msg)
 		m.handleAgentEvent(msg)
-		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+		var panelCmd tea.Cmd
+		if msg.Type == types.EventTypeTurnEnd {
+			panelCmd = m.refreshPanel() // Commands may have changed files
+		}
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd, m.scheduleStreamFrame(), panelCmd)
 
 	case tea.MouseMsg:
 		debugLog.Printf("Received tea.MouseMsg")
@@ -219,7 +270,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Check if overlay returned nil (signals to close)
 			if updatedOverlay == nil {
 				m.overlay.deactivate()
-				m.viewport.SetContent(m.content.String())
+				m.viewport.ShowTranscript("")
 				m.viewport.GotoBottom()
 				return m, overlayCmd
 			}
@@ -270,6 +321,9 @@ func (m *model) calculateViewportHeight() int {
 	loadingHeight := 0
 	if m.agentBusy {
 		loadingHeight = 1 // Loading indicator is a separate line when visible
+		if m.toolProgress != "" {
+			loadingHeight++ // Tool progress line beneath it
+		}
 	}
 
 	viewportHeight := m.height - headerHeight - inputHeight - statusBarHeight - loadingHeight
@@ -296,7 +350,7 @@ func (m *model) handleWindowResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	m.height = msg.Height
 
 	// Calculate and set viewport dimensions
-	m.viewport.Width = m.width - 4
+	m.viewport.Width = m.transcriptWidth() - 4
 	m.viewport.Height = m.calculateViewportHeight()
 	m.textarea.SetWidth(m.width - 8)
 	m.ready = true
@@ -333,7 +387,7 @@ func (m *model) handleOperationComplete(msg operationCompleteMsg) (tea.Model, te
 	// Close any active overlay after operation completes
 	if m.overlay.isActive() {
 		m.overlay.deactivate()
-		m.viewport.SetContent(m.content.String())
+		m.viewport.ShowTranscript("")
 		m.viewport.GotoBottom()
 	}
 
@@ -350,7 +404,7 @@ func (m *model) handleToast(msg toastMsg) (tea.Model, tea.Cmd) {
 	// This handles rejection cases where overlay should close
 	if m.overlay.isActive() {
 		m.overlay.deactivate()
-		m.viewport.SetContent(m.content.String())
+		m.viewport.ShowTranscript("")
 		m.viewport.GotoBottom()
 	}
 
@@ -361,7 +415,7 @@ func (m *model) handleToast(msg toastMsg) (tea.Model, tea.Cmd) {
 func (m *model) handleAgentError(msg agentErrMsg) (tea.Model, tea.Cmd) {
 	m.content.WriteString(errorStyle.Render(fmt.Sprintf("  ❌ Error: %v", msg.err)))
 	m.content.WriteString("\n\n")
-	m.viewport.SetContent(m.content.String())
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 	m.agentBusy = false
 	m.recalculateLayout()
@@ -375,7 +429,7 @@ func (m *model) handleBashCommandResult(msg bashCommandResultMsg) (tea.Model, te
 	m.content.WriteString(msg.result)
 	m.content.WriteString("\n\n")
 
-	m.viewport.SetContent(m.content.String())
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 
 	return m, nil
@@ -420,6 +474,11 @@ func (m *model) handleKeyPress(msg tea.KeyMsg, vpCmd, tiCmd, spinnerCmd tea.Cmd)
 		return m, tea.Batch(cmd, spinnerCmd)
 	}
 
+	// If transcript search is active, keys edit the query and navigate matches
+	if m.search.active {
+		return m.handleSearchKey(msg)
+	}
+
 	// Handle key presses based on type
 	switch msg.Type {
 	case tea.KeyEsc:
@@ -431,6 +490,11 @@ func (m *model) handleKeyPress(msg tea.KeyMsg, vpCmd, tiCmd, spinnerCmd tea.Cmd)
 			m.recalculateLayout()
 			return m, nil
 		}
+		// Otherwise it cancels a long-running tool (the turn keeps going)
+		if m.runningToolExecID != "" {
+			m.cancelRunningTool()
+			return m, spinnerCmd
+		}
 
 	case tea.KeyCtrlC:
 		return m.handleCtrlC()
@@ -447,6 +511,31 @@ func (m *model) handleKeyPress(msg tea.KeyMsg, vpCmd, tiCmd, spinnerCmd tea.Cmd)
 	case tea.KeyCtrlP:
 		return m.handleCtrlP()
 
+	case tea.KeyCtrlF:
+		return m.startSearch()
+
+	case tea.KeyCtrlO:
+		return m.handleCtrlO()
+
+	case tea.KeyCtrlG:
+		return m.handleCtrlG()
+
+	case tea.KeyCtrlR:
+		return m.handleCtrlR()
+
+	case tea.KeyCtrlT:
+		return m.togglePanel()
+
+	case tea.KeyUp:
+		if m.canRecallHistory() {
+			return m.recallHistory(m.history.previous())
+		}
+
+	case tea.KeyDown:
+		if m.history.navigating() && m.canRecallHistory() {
+			return m.recallHistory(m.history.next())
+		}
+
 	case tea.KeyEnter:
 		// Check if Alt is held down
 		if msg.Alt {
@@ -461,9 +550,11 @@ func (m *model) handleKeyPress(msg tea.KeyMsg, vpCmd, tiCmd, spinnerCmd tea.Cmd)
 	return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 }
 
-// handleCtrlC handles Ctrl+C key press (exit or exit bash mode)
+// handleCtrlC handles Ctrl+C key press (exit, or leave bash mode and stop
+// its running command)
 func (m *model) handleCtrlC() (tea.Model, tea.Cmd) {
 	if m.bashMode {
+		m.stopCommands()
 		m.bashMode = false
 		m.textarea.Reset()
 		m.updatePrompt()
@@ -496,6 +587,7 @@ func (m *model) handleCtrlK() (tea.Model, tea.Cmd) {
 	if m.commandPalette.IsActive() {
 		m.commandPalette.Deactivate()
 	} else {
+		m.stashDraft()
 		m.commandPalette.Activate()
 	}
 	return m, nil
@@ -506,11 +598,29 @@ func (m *model) handleCtrlP() (tea.Model, tea.Cmd) {
 	if m.commandPalette.IsActive() {
 		m.commandPalette.Deactivate()
 	} else {
+		m.stashDraft()
 		m.commandPalette.Activate()
 	}
 	return m, nil
 }
 
+// canRecallHistory reports whether Up/Down should navigate input history: the
+// input must be empty or still show an unedited recalled entry
+func (m *model) canRecallHistory() bool {
+	value := m.textarea.Value()
+	return value == "" || (m.history.navigating() && value == m.history.current())
+}
+
+// recallHistory replaces the input with a history entry
+func (m *model) recallHistory(entry string, ok bool) (tea.Model, tea.Cmd) {
+	if ok {
+		m.textarea.SetValue(entry)
+		m.textarea.CursorEnd()
+		m.updateTextAreaHeight()
+	}
+	return m, nil
+}
+
 // handleEnter handles Enter key press (send message or execute bash command)
 func (m *model) handleEnter(tiCmd, vpCmd, spinnerCmd tea.Cmd) (tea.Model, tea.Cmd) {
 	input := strings.TrimSpace(m.textarea.Value())
@@ -519,6 +629,11 @@ func (m *model) handleEnter(tiCmd, vpCmd, spinnerCmd tea.Cmd) (tea.Model, tea.Cm
 		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 	}
 
+	// Record the input for Up/Down recall
+	if err := m.history.add(input); err != nil {
+		debugLog.Printf("Warning: failed to save input history: %v", err)
+	}
+
 	// Handle bash mode
 	if m.bashMode {
 		return m.handleBashModeInput(input, tiCmd, vpCmd, spinnerCmd)
@@ -529,6 +644,9 @@ func (m *model) handleEnter(tiCmd, vpCmd, spinnerCmd tea.Cmd) (tea.Model, tea.Cm
 		return m.handleSlashCommand(input, tiCmd, vpCmd, spinnerCmd)
 	}
 
+	// Any other submission supersedes a draft stashed for the command palette
+	m.draft = ""
+
 	// Handle single-shot bash commands
 	if strings.HasPrefix(input, "!") {
 		return m.handleSingleShotBash(input, tiCmd, vpCmd, spinnerCmd)
@@ -554,7 +672,7 @@ func (m *model) handleBashModeInput(input string, tiCmd, vpCmd, spinnerCmd tea.C
 
 	// Clear the input area
 	m.textarea.Reset()
-	m.viewport.SetContent(m.content.String())
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 
 	// Execute command and return to event loop
@@ -577,6 +695,9 @@ func (m *model) handleSlashCommand(input string, tiCmd, vpCmd, spinnerCmd tea.Cm
 
 	// Execute slash command
 	updatedModel, cmd := executeSlashCommand(m, commandName, args)
+
+	// Bring back a draft the command palette displaced
+	m.restoreDraft()
 	return updatedModel, tea.Batch(tiCmd, vpCmd, spinnerCmd, cmd)
 }
 
@@ -594,7 +715,7 @@ func (m *model) handleSingleShotBash(input string, tiCmd, vpCmd, spinnerCmd tea.
 
 	// Clear the input area
 	m.textarea.Reset()
-	m.viewport.SetContent(m.content.String())
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 
 	// Execute command
@@ -603,34 +724,43 @@ func (m *model) handleSingleShotBash(input string, tiCmd, vpCmd, spinnerCmd tea.
 
 // handleAgentMessage processes regular agent messages
 func (m *model) handleAgentMessage(input string, tiCmd, vpCmd, spinnerCmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m.showUserMessage(input)
+	m.eventLog.recordInput(input)
+
+	// Send message to agent
+	userInput := types.NewUserInput(input)
+	debugLog.Printf("Sending user input to agent: %+v", userInput)
+	m.channels.Input <- userInput
+
+	return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+}
+
+// showUserMessage shows a message the user sent the agent, which is busy
+// with it from then on
+func (m *model) showUserMessage(input string) {
 	// Display user message
-	formatted := formatEntry("You: ", input, userStyle, m.width, true)
+	formatted := formatEntry("You: ", input, userStyle, m.transcriptWidth(), true)
 	// Strip any trailing newlines before adding our spacing
 	formatted = strings.TrimRight(formatted, "\n")
 	m.content.WriteString(formatted + "\n\n")
 
 	// Clear input
 	m.textarea.Reset()
-	m.viewport.SetContent(m.content.String())
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 
 	// Set agent busy
 	m.agentBusy = true
 	m.currentLoadingMessage = getRandomLoadingMessage()
 	m.recalculateLayout()
-
-	// Send message to agent
-	userInput := types.NewUserInput(input)
-	debugLog.Printf("Sending user input to agent: %+v", userInput)
-	m.channels.Input <- userInput
-
-	return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 }
 
 // recalculateLayout updates viewport content and scrolls to bottom
 func (m *model) recalculateLayout() {
-	// Update viewport height based on current state (including loading indicator)
+	// Update viewport size based on current state (including loading
+	// indicator and session panel)
+	m.viewport.Width = m.transcriptWidth() - 4
 	m.viewport.Height = m.calculateViewportHeight()
-	m.viewport.SetContent(m.content.String())
+	m.viewport.ShowTranscript("")
 	m.viewport.GotoBottom()
 }
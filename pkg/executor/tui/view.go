@@ -24,8 +24,11 @@ func (m *model) View() string {
 	inputBox := m.buildInputBox()
 	bottomBar := m.buildBottomBar()
 
-	// Build viewport section
+	// Build viewport section, with the session panel beside it when shown
 	viewportSection := m.viewport.View()
+	if m.panelVisible() {
+		viewportSection = lipgloss.JoinHorizontal(lipgloss.Top, viewportSection, "  ", m.renderPanel(m.viewport.Height))
+	}
 
 	// Assemble the base UI
 	baseView := m.assembleBaseView(header, tips, topStatus, viewportSection, loadingIndicator, inputBox, bottomBar)
@@ -50,7 +53,7 @@ func (m *model) buildTips() string {
 	if m.bashMode {
 		return tipsStyle.Render(`  Bash Mode: Commands execute directly • Type 'exit' or Ctrl+C to return • Enter to run`)
 	}
-	return tipsStyle.Render(`  Tips: Ask questions • Alt+Enter for new line • Enter to send • !cmd for bash • /bash for mode • Ctrl+V to view last tool result • Ctrl+L for result history • Ctrl+C to exit`)
+	return tipsStyle.Render(`  Tips: Ask questions • Alt+Enter for new line • Ctrl+O to compose • Enter to send • !cmd for bash • /bash for mode • Ctrl+V to view last tool result • Ctrl+L for result history • Ctrl+F to search • Ctrl+T for session panel • Ctrl+C to exit`)
 }
 
 // buildTopStatus renders the working directory status bar
@@ -72,11 +75,25 @@ func (m *model) buildLoadingIndicator() string {
 		Foreground(salmonPink).
 		Width(m.width-4).
 		Padding(0, 2)
-	return loadingStyle.Render(loadingMsg)
+	indicator := loadingStyle.Render(loadingMsg)
+
+	// Show what the running tool is doing beneath the spinner
+	if m.toolProgress != "" {
+		progressStyle := lipgloss.NewStyle().
+			Foreground(mutedGray).
+			Width(m.width-4).
+			MaxHeight(1).
+			Padding(0, 4)
+		indicator = lipgloss.JoinVertical(lipgloss.Left, indicator, progressStyle.Render("↳ "+m.toolProgress))
+	}
+	return indicator
 }
 
 // buildInputBox renders the text input area
 func (m *model) buildInputBox() string {
+	if m.search.active {
+		return m.buildSearchBox()
+	}
 	return inputBoxStyle.Width(m.width - 4).Render(m.textarea.View())
 }
 
@@ -201,7 +218,7 @@ func (m *model) renderSummarizationStatus() string {
 	var content strings.Builder
 
 	// Header line with brain icon and message
-	header := fmt.Sprintf("🧠 Optimizing context... [%s]", m.summarization.strategy)
+	header := fmt.Sprintf("🧠 Optimizing context... [%s] (/skip-optimize to skip)", m.summarization.strategy)
 	content.WriteString(header)
 	content.WriteString("\n")
 
@@ -0,0 +1,43 @@
+package llm
+
+// Middleware wraps a Provider with cross-cutting behavior such as logging,
+// redaction or caching, so it can be layered per deployment without changing
+// provider implementations. A middleware typically returns a type that embeds
+// the wrapped Provider and overrides only the calls it intercepts.
+type Middleware func(next Provider) Provider
+
+// Chain wraps provider with middlewares. The first middleware is the
+// outermost: it sees requests first and responses last.
+//
+// Example:
+//
+//	provider = llm.Chain(provider,
+//	    middleware.Logging(logger),
+//	    middleware.Redact(middleware.DefaultRedactionPatterns...),
+//	)
+func Chain(provider Provider, middlewares ...Middleware) Provider {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		provider = middlewares[i](provider)
+	}
+	return provider
+}
+
+// TapStream forwards every chunk from in to the returned channel, calling
+// onChunk before each one is forwarded and onClose once in is closed. Either
+// callback may be nil. Middlewares use it to observe streamed responses.
+func TapStream(in <-chan *StreamChunk, onChunk func(*StreamChunk), onClose func()) <-chan *StreamChunk {
+	out := make(chan *StreamChunk, cap(in))
+	go func() {
+		defer close(out)
+		for chunk := range in {
+			if onChunk != nil {
+				onChunk(chunk)
+			}
+			out <- chunk
+		}
+		if onClose != nil {
+			onClose()
+		}
+	}()
+	return out
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// cacheEntry is a completed response as stored on disk
+type cacheEntry struct {
+	Model   string        `json:"model"`
+	Created time.Time     `json:"created"`
+	Role    string        `json:"role"`
+	Chunks  []cachedChunk `json:"chunks"`
+}
+
+// cachedChunk is a run of consecutive response content of the same type
+type cachedChunk struct {
+	Type    llm.ContentType `json:"type,omitempty"`
+	Content string          `json:"content"`
+}
+
+// Cache returns a middleware that serves repeated requests from an on-disk
+// cache in dir. Entries are keyed by the model, its parameters (the provider's
+// model info) and the exact messages, so identical prompts always get the
+// response recorded the first time. Only successfully completed responses are
+// stored. Cache read and write failures fall through to the provider.
+//
+// The cache is intended for replay, demo and test runs, and for idempotent
+// calls such as context summarization that would otherwise be paid for again
+// in every session.
+func Cache(dir string) llm.Middleware {
+	return func(next llm.Provider) llm.Provider {
+		return &cachingProvider{Provider: next, dir: dir}
+	}
+}
+
+// cachingProvider answers from the cache before delegating to the wrapped provider
+type cachingProvider struct {
+	llm.Provider
+	dir string
+}
+
+// StreamCompletion replays a cached response, or streams from the provider and
+// records the response once it finishes
+func (p *cachingProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	key := p.key(messages)
+	if entry, ok := p.load(key); ok {
+		return replay(entry), nil
+	}
+
+	stream, err := p.Provider.StreamCompletion(ctx, messages)
+	if err != nil || stream == nil {
+		return stream, err
+	}
+
+	entry := &cacheEntry{Model: modelName(p.Provider)}
+	failed := false
+	return llm.TapStream(stream, func(chunk *llm.StreamChunk) {
+		if chunk.IsError() {
+			failed = true
+			return
+		}
+		if chunk.Role != "" {
+			entry.Role = chunk.Role
+		}
+		entry.append(chunk.Type, chunk.Content)
+	}, func() {
+		if !failed && ctx.Err() == nil {
+			p.store(key, entry)
+		}
+	}), nil
+}
+
+// Complete returns a cached response, or completes with the provider and records the response
+func (p *cachingProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	key := p.key(messages)
+	if entry, ok := p.load(key); ok {
+		return entry.message(), nil
+	}
+
+	response, err := p.Provider.Complete(ctx, messages)
+	if err != nil || response == nil {
+		return response, err
+	}
+
+	entry := &cacheEntry{Model: modelName(p.Provider), Role: string(response.Role)}
+	entry.append(llm.ContentTypeMessage, response.Content)
+	p.store(key, entry)
+	return response, nil
+}
+
+// key hashes the model, its parameters and the request messages
+func (p *cachingProvider) key(messages []*types.Message) string {
+	hash := sha256.New()
+	if info := p.Provider.GetModelInfo(); info != nil {
+		params, _ := json.Marshal(info.Metadata) // Map keys are marshaled in sorted order
+		fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", info.Provider, info.Name, params)
+	}
+	for _, msg := range messages {
+		fmt.Fprintf(hash, "%s\x00%d:%s\x00", msg.Role, len(msg.Content), msg.Content)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// path returns the cache file for key
+func (p *cachingProvider) path(key string) string {
+	return filepath.Join(p.dir, key+".json")
+}
+
+// load reads the cache entry for key, if present and readable
+func (p *cachingProvider) load(key string) (*cacheEntry, bool) {
+	data, err := os.ReadFile(p.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// store writes the cache entry for key atomically, through a temporary file
+// of its own so concurrent stores of the same key don't interleave. Failures
+// are ignored: a missing entry only costs a provider call next time.
+func (p *cachingProvider) store(key string, entry *cacheEntry) {
+	entry.Created = time.Now()
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return
+	}
+
+	tempFile, err := os.CreateTemp(p.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	tempPath := tempFile.Name()
+	_, err = tempFile.Write(data)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, p.path(key))
+	}
+	if err != nil {
+		os.Remove(tempPath)
+	}
+}
+
+// append adds content to the entry, merging it into the previous chunk when
+// the type is unchanged
+func (e *cacheEntry) append(contentType llm.ContentType, content string) {
+	if content == "" {
+		return
+	}
+	if n := len(e.Chunks); n > 0 && e.Chunks[n-1].Type == contentType {
+		e.Chunks[n-1].Content += content
+		return
+	}
+	e.Chunks = append(e.Chunks, cachedChunk{Type: contentType, Content: content})
+}
+
+// message returns the entry as a single message, accumulating all content
+// the way Complete does for a streamed response
+func (e *cacheEntry) message() *types.Message {
+	role := e.Role
+	if role == "" {
+		role = string(types.RoleAssistant)
+	}
+	content := ""
+	for _, chunk := range e.Chunks {
+		content += chunk.Content
+	}
+	return types.NewMessage(types.MessageRole(role), content)
+}
+
+// replay streams a cached response as the provider originally delivered it
+func replay(entry *cacheEntry) <-chan *llm.StreamChunk {
+	chunks := make(chan *llm.StreamChunk, len(entry.Chunks)+1)
+	for i, chunk := range entry.Chunks {
+		streamChunk := &llm.StreamChunk{Type: chunk.Type, Content: chunk.Content}
+		if i == 0 {
+			streamChunk.Role = entry.Role
+		}
+		chunks <- streamChunk
+	}
+	chunks <- &llm.StreamChunk{Finished: true}
+	close(chunks)
+	return chunks
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// countingProvider counts calls and streams a thinking chunk followed by a reply
+type countingProvider struct {
+	stubProvider
+	calls int
+}
+
+func (p *countingProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	p.calls++
+	if p.err != nil {
+		stream := make(chan *llm.StreamChunk, 1)
+		stream <- &llm.StreamChunk{Error: p.err}
+		close(stream)
+		return stream, nil
+	}
+	stream := make(chan *llm.StreamChunk, 4)
+	stream <- &llm.StreamChunk{Role: "assistant", Type: llm.ContentTypeThinking, Content: "hmm"}
+	stream <- &llm.StreamChunk{Content: p.reply[:2]}
+	stream <- &llm.StreamChunk{Content: p.reply[2:]}
+	stream <- &llm.StreamChunk{Finished: true}
+	close(stream)
+	return stream, nil
+}
+
+func (p *countingProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	p.calls++
+	return p.stubProvider.Complete(ctx, messages)
+}
+
+// drain collects a stream's chunks
+func drain(t *testing.T, provider llm.Provider, messages []*types.Message) []*llm.StreamChunk {
+	t.Helper()
+	stream, err := provider.StreamCompletion(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+	var chunks []*llm.StreamChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestCacheReplaysStream(t *testing.T) {
+	base := &countingProvider{stubProvider: stubProvider{reply: "hello"}}
+	provider := llm.Chain(base, Cache(t.TempDir()))
+	messages := []*types.Message{types.NewUserMessage("summarize this")}
+
+	drain(t, provider, messages)
+	replayed := drain(t, provider, messages)
+
+	if base.calls != 1 {
+		t.Errorf("expected one provider call, got %d", base.calls)
+	}
+	if len(replayed) != 3 {
+		t.Fatalf("expected thinking, message and finish chunks, got %d", len(replayed))
+	}
+	if !replayed[0].IsThinking() || replayed[0].Content != "hmm" || replayed[0].Role != "assistant" {
+		t.Errorf("unexpected first chunk: %+v", replayed[0])
+	}
+	if !replayed[1].IsMessage() || replayed[1].Content != "hello" {
+		t.Errorf("unexpected message chunk: %+v", replayed[1])
+	}
+	if !replayed[2].IsLast() {
+		t.Error("expected replay to end with a finished chunk")
+	}
+}
+
+func TestCacheKeyedByMessages(t *testing.T) {
+	base := &countingProvider{stubProvider: stubProvider{reply: "hello"}}
+	provider := llm.Chain(base, Cache(t.TempDir()))
+
+	drain(t, provider, []*types.Message{types.NewUserMessage("one")})
+	drain(t, provider, []*types.Message{types.NewUserMessage("two")})
+	drain(t, provider, []*types.Message{types.NewSystemMessage("one")})
+
+	if base.calls != 3 {
+		t.Errorf("expected distinct prompts to miss the cache, got %d provider calls", base.calls)
+	}
+}
+
+func TestCacheSkipsFailedStreams(t *testing.T) {
+	base := &countingProvider{stubProvider: stubProvider{err: errors.New("boom")}}
+	provider := llm.Chain(base, Cache(t.TempDir()))
+	messages := []*types.Message{types.NewUserMessage("hi")}
+
+	drain(t, provider, messages)
+	drain(t, provider, messages)
+
+	if base.calls != 2 {
+		t.Errorf("expected failed responses not to be cached, got %d provider calls", base.calls)
+	}
+}
+
+func TestCacheComplete(t *testing.T) {
+	dir := t.TempDir()
+	base := &countingProvider{stubProvider: stubProvider{reply: "summary"}}
+	messages := []*types.Message{types.NewUserMessage("summarize")}
+
+	first, err := llm.Chain(base, Cache(dir)).Complete(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	// A new middleware over the same directory models a later session
+	second, err := llm.Chain(base, Cache(dir)).Complete(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if base.calls != 1 {
+		t.Errorf("expected one provider call across sessions, got %d", base.calls)
+	}
+	if second.Content != first.Content || second.Role != types.RoleAssistant {
+		t.Errorf("expected cached %q, got %+v", first.Content, second)
+	}
+}
+
+// nilProvider completes without an error or a response
+type nilProvider struct {
+	stubProvider
+}
+
+func (p *nilProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	return nil, nil
+}
+
+func TestCacheCompleteWithoutResponse(t *testing.T) {
+	dir := t.TempDir()
+	response, err := llm.Chain(&nilProvider{}, Cache(dir)).Complete(context.Background(), []*types.Message{types.NewUserMessage("hi")})
+	if response != nil || err != nil {
+		t.Errorf("expected the provider's nil response to pass through, got %v, %v", response, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected nothing cached, got %d entries", len(entries))
+	}
+}
+
+func TestCacheConcurrentStores(t *testing.T) {
+	dir := t.TempDir()
+	cache := &cachingProvider{Provider: &stubProvider{}, dir: dir}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := &cacheEntry{Role: "assistant"}
+			entry.append(llm.ContentTypeMessage, "summary")
+			cache.store("key", entry)
+		}()
+	}
+	wg.Wait()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "key.json" {
+		t.Errorf("expected only the cache entry to remain, got %v", entries)
+	}
+	if entry, ok := cache.load("key"); !ok || entry.message().Content != "summary" {
+		t.Errorf("expected a complete entry, got %+v", entry)
+	}
+}
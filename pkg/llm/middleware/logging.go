@@ -0,0 +1,87 @@
+// Package middleware provides llm.Middleware implementations that layer
+// cross-cutting behavior over any llm.Provider.
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Logging returns a middleware that logs each completion request with its
+// size, duration and outcome. Message content is never logged.
+func Logging(logger *log.Logger) llm.Middleware {
+	return func(next llm.Provider) llm.Provider {
+		return &loggingProvider{Provider: next, logger: logger}
+	}
+}
+
+// loggingProvider logs calls before delegating to the wrapped provider
+type loggingProvider struct {
+	llm.Provider
+	logger *log.Logger
+}
+
+// StreamCompletion logs the request and, once the stream closes, the response
+func (p *loggingProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	start := time.Now()
+	p.logRequest("stream", messages)
+
+	stream, err := p.Provider.StreamCompletion(ctx, messages)
+	if err != nil {
+		p.logger.Printf("llm stream failed to start after %s: %v", time.Since(start).Round(time.Millisecond), err)
+		return nil, err
+	}
+
+	var chars int
+	var streamErr error
+	return llm.TapStream(stream, func(chunk *llm.StreamChunk) {
+		chars += len(chunk.Content)
+		if chunk.IsError() {
+			streamErr = chunk.Error
+		}
+	}, func() {
+		p.logResponse("stream", chars, time.Since(start), streamErr)
+	}), nil
+}
+
+// Complete logs the request and response
+func (p *loggingProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	start := time.Now()
+	p.logRequest("complete", messages)
+
+	response, err := p.Provider.Complete(ctx, messages)
+	chars := 0
+	if response != nil {
+		chars = len(response.Content)
+	}
+	p.logResponse("complete", chars, time.Since(start), err)
+	return response, err
+}
+
+func (p *loggingProvider) logRequest(call string, messages []*types.Message) {
+	chars := 0
+	for _, msg := range messages {
+		chars += len(msg.Content)
+	}
+	p.logger.Printf("llm %s request: model=%s messages=%d chars=%d", call, modelName(p.Provider), len(messages), chars)
+}
+
+func (p *loggingProvider) logResponse(call string, chars int, elapsed time.Duration, err error) {
+	if err != nil {
+		p.logger.Printf("llm %s failed after %s: %v", call, elapsed.Round(time.Millisecond), err)
+		return
+	}
+	p.logger.Printf("llm %s response: chars=%d duration=%s", call, chars, elapsed.Round(time.Millisecond))
+}
+
+// modelName returns the provider's model name, if it reports one
+func modelName(provider llm.Provider) string {
+	if info := provider.GetModelInfo(); info != nil {
+		return info.Name
+	}
+	return "unknown"
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// RateLimit returns a middleware that makes each call wait for limiter with
+// the given priority before it reaches the provider. A streamed call holds
+// its place until the stream closes. The limiter only knows the limit the
+// provider reports to it, such as with openai.WithRateLimiter.
+func RateLimit(limiter *llm.RateLimiter, priority llm.Priority) llm.Middleware {
+	return func(next llm.Provider) llm.Provider {
+		return &rateLimitedProvider{Provider: next, limiter: limiter, priority: priority}
+	}
+}
+
+// rateLimitedProvider waits for the limiter before delegating to the wrapped
+// provider
+type rateLimitedProvider struct {
+	llm.Provider
+	limiter  *llm.RateLimiter
+	priority llm.Priority
+}
+
+// StreamCompletion waits for the limiter, then starts the stream
+func (p *rateLimitedProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	release, err := p.limiter.Wait(ctx, p.priority)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := p.Provider.StreamCompletion(ctx, messages)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return llm.TapStream(stream, nil, release), nil
+}
+
+// Complete waits for the limiter, then completes the messages
+func (p *rateLimitedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	release, err := p.limiter.Wait(ctx, p.priority)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return p.Provider.Complete(ctx, messages)
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// RedactionPlaceholder replaces redacted text in outgoing messages
+const RedactionPlaceholder = "[REDACTED]"
+
+// DefaultRedactionPatterns match common secrets and personal data: email
+// addresses, provider API keys, AWS access keys, bearer tokens and private keys.
+var DefaultRedactionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	regexp.MustCompile(`\b(sk|pk|rk)-[A-Za-z0-9_-]{20,}\b`),
+	regexp.MustCompile(`\b(AKIA|ASIA)[A-Z0-9]{16}\b`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{20,}`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+}
+
+// Redact returns a middleware that scrubs text matching any of patterns from
+// outgoing message content before it reaches the provider. The caller's
+// messages are never modified.
+func Redact(patterns ...*regexp.Regexp) llm.Middleware {
+	return func(next llm.Provider) llm.Provider {
+		return &redactingProvider{Provider: next, patterns: patterns}
+	}
+}
+
+// redactingProvider scrubs messages before delegating to the wrapped provider
+type redactingProvider struct {
+	llm.Provider
+	patterns []*regexp.Regexp
+}
+
+// StreamCompletion streams a completion for the redacted messages
+func (p *redactingProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	return p.Provider.StreamCompletion(ctx, p.redact(messages))
+}
+
+// Complete completes the redacted messages
+func (p *redactingProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	return p.Provider.Complete(ctx, p.redact(messages))
+}
+
+// redact returns messages with matching text replaced. Messages that need no
+// changes are shared; changed ones are shallow copies.
+func (p *redactingProvider) redact(messages []*types.Message) []*types.Message {
+	redacted := make([]*types.Message, len(messages))
+	for i, msg := range messages {
+		content := msg.Content
+		for _, pattern := range p.patterns {
+			content = pattern.ReplaceAllString(content, RedactionPlaceholder)
+		}
+		if content == msg.Content {
+			redacted[i] = msg
+			continue
+		}
+		copied := *msg
+		copied.Content = content
+		redacted[i] = &copied
+	}
+	return redacted
+}
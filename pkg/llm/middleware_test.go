@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// namedProvider records the order in which wrapped providers are called
+type namedProvider struct {
+	Provider
+	name  string
+	calls *[]string
+}
+
+func (p *namedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	*p.calls = append(*p.calls, p.name)
+	if p.Provider == nil {
+		return types.NewAssistantMessage("done"), nil
+	}
+	return p.Provider.Complete(ctx, messages)
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	named := func(name string) Middleware {
+		return func(next Provider) Provider {
+			return &namedProvider{Provider: next, name: name, calls: &calls}
+		}
+	}
+
+	base := &namedProvider{name: "base", calls: &calls}
+	provider := Chain(base, named("outer"), named("inner"))
+
+	if _, err := provider.Complete(context.Background(), nil); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	want := []string{"outer", "inner", "base"}
+	if len(calls) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("expected calls %v, got %v", want, calls)
+			break
+		}
+	}
+}
+
+func TestChainWithoutMiddlewares(t *testing.T) {
+	var calls []string
+	base := &namedProvider{name: "base", calls: &calls}
+	if Chain(base) != Provider(base) {
+		t.Error("expected Chain with no middlewares to return the provider unchanged")
+	}
+}
+
+func TestTapStream(t *testing.T) {
+	in := make(chan *StreamChunk, 3)
+	in <- &StreamChunk{Content: "a"}
+	in <- &StreamChunk{Content: "b"}
+	in <- &StreamChunk{Finished: true}
+	close(in)
+
+	var seen string
+	closed := false
+	out := TapStream(in, func(chunk *StreamChunk) { seen += chunk.Content }, func() { closed = true })
+
+	var forwarded int
+	for range out {
+		forwarded++
+	}
+
+	if forwarded != 3 {
+		t.Errorf("expected 3 chunks forwarded, got %d", forwarded)
+	}
+	if seen != "ab" {
+		t.Errorf("expected onChunk to see %q, got %q", "ab", seen)
+	}
+	if !closed {
+		t.Error("expected onClose to run before the output closed")
+	}
+}
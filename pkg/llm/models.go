@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// Model describes a model a provider offers, with the metadata the provider
+// publishes for it
+type Model struct {
+	ID            string
+	Name          string  // Display name; empty if the provider gives none
+	ContextWindow int     // Tokens; zero if not published
+	InputPrice    float64 // US dollars per million prompt tokens; zero if not published
+	OutputPrice   float64 // US dollars per million completion tokens; zero if not published
+}
+
+// Priced reports whether the provider published the model's prices
+func (m Model) Priced() bool {
+	return m.InputPrice > 0 || m.OutputPrice > 0
+}
+
+// ModelLister is implemented by providers that can list the models they
+// offer. Check for it with a type assertion; providers wrapped by Chain
+// don't implement it.
+type ModelLister interface {
+	// ListModels returns the provider's models, sorted by ID
+	ListModels(ctx context.Context) ([]Model, error)
+}
+
+// FindModel returns the model with the given ID
+func FindModel(models []Model, id string) (Model, bool) {
+	for _, m := range models {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// FilterModels returns the models whose ID or name contains query, ignoring
+// case. An empty query returns all models.
+func FilterModels(models []Model, query string) []Model {
+	query = strings.ToLower(query)
+	var matched []Model
+	for _, m := range models {
+		if strings.Contains(strings.ToLower(m.ID), query) || strings.Contains(strings.ToLower(m.Name), query) {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
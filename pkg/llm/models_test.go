@@ -0,0 +1,34 @@
+package llm
+
+import "testing"
+
+func TestFilterModels(t *testing.T) {
+	models := []Model{
+		{ID: "anthropic/claude-sonnet-4", Name: "Anthropic: Claude Sonnet 4"},
+		{ID: "gpt-4o"},
+		{ID: "gpt-4o-mini"},
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"GPT-4O", 2},
+		{"sonnet", 1},
+		{"Anthropic: Claude", 1},
+		{"llama", 0},
+	}
+	for _, tt := range tests {
+		if got := FilterModels(models, tt.query); len(got) != tt.want {
+			t.Errorf("FilterModels(%q) returned %d models, want %d", tt.query, len(got), tt.want)
+		}
+	}
+
+	if m, ok := FindModel(models, "gpt-4o"); !ok || m.ID != "gpt-4o" {
+		t.Errorf("FindModel(gpt-4o) = %+v, %v", m, ok)
+	}
+	if _, ok := FindModel(models, "gpt-4"); ok {
+		t.Error("expected FindModel to match whole IDs only")
+	}
+}
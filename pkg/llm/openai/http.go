@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// HTTPConfig configures how a provider connects to its API, for networks
+// that go through a proxy or inspect TLS with their own CA
+type HTTPConfig struct {
+	// Timeout bounds how long a request waits for the response headers.
+	// Streamed responses are then bounded by the stream idle timeout. Zero
+	// waits indefinitely.
+	Timeout time.Duration
+
+	// Proxy is the URL of the proxy to send requests through. Empty uses
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY from the environment.
+	Proxy string
+
+	// CABundle is a PEM file of CA certificates to trust besides the
+	// system's
+	CABundle string
+}
+
+// NewHTTPClient creates an HTTP client for cfg
+func NewHTTPClient(cfg HTTPConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = cfg.Timeout
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.CABundle != "" {
+		pem, err := os.ReadFile(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", cfg.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// WithHTTPClient sends requests with client, e.g. one from NewHTTPClient
+// that goes through a proxy or trusts a corporate CA.
+func WithHTTPClient(client *http.Client) ProviderOption {
+	return func(p *Provider) {
+		p.httpClient = client
+	}
+}
@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String() // Absolute URL of a proxied request
+		w.Write([]byte(`{"data":[{"id":"gpt-4o"}]}`))
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(HTTPConfig{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	provider, err := NewProvider("test-key", WithBaseURL("http://api.example.invalid/v1"), WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if _, err := provider.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if proxied != "http://api.example.invalid/v1/models" {
+		t.Errorf("expected the request to go through the proxy, got %q", proxied)
+	}
+}
+
+func TestNewHTTPClient_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	// Without the server's CA the certificate is rejected
+	plain, err := NewHTTPClient(HTTPConfig{})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	if _, err := plain.Get(server.URL); err == nil {
+		t.Fatal("expected an unknown CA to be rejected")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewHTTPClient(HTTPConfig{CABundle: bundle})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the bundle's CA to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewHTTPClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(HTTPConfig{Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	if _, err := client.Get(server.URL); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected a timeout waiting for the response, got %v", err)
+	}
+}
+
+func TestNewHTTPClient_Invalid(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, cfg := range map[string]HTTPConfig{
+		"proxy without host": {Proxy: "proxy.example.com"},
+		"missing CA bundle":  {CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		"CA bundle not PEM":  {CABundle: empty},
+	} {
+		if _, err := NewHTTPClient(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
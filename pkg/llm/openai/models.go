@@ -0,0 +1,92 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// modelEntry is one model of a models endpoint response. OpenAI only sends
+// the ID; gateways such as OpenRouter add a name, context length and
+// per-token prices, and servers such as vLLM a maximum model length.
+type modelEntry struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	ContextLength int    `json:"context_length"`
+	ContextWindow int    `json:"context_window"`
+	MaxModelLen   int    `json:"max_model_len"`
+	Pricing       struct {
+		Prompt     string `json:"prompt"`
+		Completion string `json:"completion"`
+	} `json:"pricing"`
+}
+
+// ListModels returns the models the provider's models endpoint lists, with
+// the context window and prices where the endpoint publishes them.
+func (p *Provider) ListModels(ctx context.Context) ([]llm.Model, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	var list struct {
+		Data []modelEntry `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse models: %w", err)
+	}
+
+	models := make([]llm.Model, 0, len(list.Data))
+	for _, entry := range list.Data {
+		models = append(models, entry.model())
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// model converts the entry, taking whichever context length field is set
+// and converting per-token prices to per-million
+func (e modelEntry) model() llm.Model {
+	m := llm.Model{ID: e.ID, Name: e.Name}
+	for _, length := range []int{e.ContextLength, e.ContextWindow, e.MaxModelLen} {
+		if length > 0 {
+			m.ContextWindow = length
+			break
+		}
+	}
+	m.InputPrice = perMillion(e.Pricing.Prompt)
+	m.OutputPrice = perMillion(e.Pricing.Completion)
+	return m
+}
+
+// perMillion converts a per-token price string to dollars per million
+// tokens; unparsable and negative prices (OpenRouter uses -1 for variable
+// pricing) count as unpublished
+func perMillion(perToken string) float64 {
+	price, err := strconv.ParseFloat(perToken, 64)
+	if err != nil || price < 0 {
+		return 0
+	}
+	return price * 1_000_000
+}
@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[
+			{"id":"gpt-4o","object":"model","owned_by":"system"},
+			{"id":"anthropic/claude-sonnet-4","name":"Anthropic: Claude Sonnet 4","context_length":200000,
+			 "pricing":{"prompt":"0.000003","completion":"0.000015"}},
+			{"id":"openrouter/auto","context_length":2000000,"pricing":{"prompt":"-1","completion":"-1"}},
+			{"id":"local-llama","max_model_len":32768}
+		]}`))
+	}))
+	defer server.Close()
+
+	var lister llm.ModelLister = newTestProvider(t, server.URL)
+	models, err := lister.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+
+	want := []llm.Model{
+		{ID: "anthropic/claude-sonnet-4", Name: "Anthropic: Claude Sonnet 4", ContextWindow: 200000, InputPrice: 3, OutputPrice: 15},
+		{ID: "gpt-4o"},
+		{ID: "local-llama", ContextWindow: 32768},
+		{ID: "openrouter/auto", ContextWindow: 2000000},
+	}
+	if len(models) != len(want) {
+		t.Fatalf("got %d models, want %d: %+v", len(models), len(want), models)
+	}
+	for i := range want {
+		got := models[i]
+		// Per-token prices don't convert to exact floats
+		if got.ID != want[i].ID || got.Name != want[i].Name || got.ContextWindow != want[i].ContextWindow ||
+			!closeTo(got.InputPrice, want[i].InputPrice) || !closeTo(got.OutputPrice, want[i].OutputPrice) {
+			t.Errorf("models[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestListModels_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid key"}}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestProvider(t, server.URL).ListModels(context.Background())
+	agentErr, ok := types.IsAgentError(err)
+	if !ok || agentErr.Code != types.ErrorCodeAuth {
+		t.Errorf("expected an auth error, got %v", err)
+	}
+}
+
+func closeTo(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
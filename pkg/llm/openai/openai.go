@@ -49,6 +49,8 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/parser"
@@ -59,6 +61,13 @@ import (
 const (
 	// DefaultBaseURL is the default OpenAI API base URL
 	DefaultBaseURL = "https://api.openai.com/v1"
+
+	// DefaultStreamIdleTimeout is how long a stream may go without receiving
+	// any data (including SSE keep-alive comments) before it is considered stalled
+	DefaultStreamIdleTimeout = 60 * time.Second
+
+	// maxStreamReconnects bounds how many times a stalled stream is reopened
+	maxStreamReconnects = 2
 )
 
 // Provider implements the LLM provider interface for OpenAI-compatible APIs.
@@ -68,6 +77,12 @@ type Provider struct {
 	baseURL    string
 	model      string
 	modelInfo  *types.ModelInfo
+	headers    map[string]string
+	parser     parser.Config
+
+	streamIdleTimeout time.Duration
+	streamUsage       bool
+	rateLimiter       *llm.RateLimiter
 }
 
 // ProviderOption is a function that configures a Provider.
@@ -88,6 +103,57 @@ func WithBaseURL(baseURL string) ProviderOption {
 	}
 }
 
+// WithHeaders sends headers with every request, for gateways that expect
+// more than the API key (e.g. OpenRouter's app attribution headers).
+func WithHeaders(headers map[string]string) ProviderOption {
+	return func(p *Provider) {
+		if p.headers == nil {
+			p.headers = make(map[string]string)
+		}
+		for name, value := range headers {
+			p.headers[name] = value
+		}
+	}
+}
+
+// WithParserConfig sets how streamed content is split into thinking and
+// message content: the thinking tag names, whether thinking is passed on or
+// dropped, and how much of it is passed on. Defaults to parser.DefaultConfig.
+// Tool calls are always passed on as message content.
+func WithParserConfig(cfg parser.Config) ProviderOption {
+	return func(p *Provider) {
+		p.parser = cfg
+		p.parser.ExtractToolCalls = false
+	}
+}
+
+// WithStreamIdleTimeout sets how long a streaming response may go without
+// receiving data before it is treated as stalled and reconnected. Zero disables
+// stall detection.
+func WithStreamIdleTimeout(timeout time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.streamIdleTimeout = timeout
+	}
+}
+
+// WithStreamUsage sets whether streaming requests ask for token usage with
+// stream_options.include_usage (default true). Turn it off for servers that
+// reject the field; their calls then report no usage or cost.
+func WithStreamUsage(enabled bool) ProviderOption {
+	return func(p *Provider) {
+		p.streamUsage = enabled
+	}
+}
+
+// WithRateLimiter reports the rate limit headers of every response to
+// limiter, so calls scheduled with middleware.RateLimit wait for the limit
+// instead of failing with 429s.
+func WithRateLimiter(limiter *llm.RateLimiter) ProviderOption {
+	return func(p *Provider) {
+		p.rateLimiter = limiter
+	}
+}
+
 // NewProvider creates a new OpenAI provider with the given API key.
 //
 // If apiKey is empty, it will attempt to read from the OPENAI_API_KEY environment variable.
@@ -124,6 +190,10 @@ func NewProvider(apiKey string, opts ...ProviderOption) (*Provider, error) {
 		apiKey:     apiKey,
 		httpClient: &http.Client{},
 		baseURL:    DefaultBaseURL,
+		parser:     parser.DefaultConfig(),
+
+		streamIdleTimeout: DefaultStreamIdleTimeout,
+		streamUsage:       true,
 	}
 
 	// Apply options (may override baseURL via WithBaseURL)
@@ -166,19 +236,25 @@ func NewProvider(apiKey string, opts ...ProviderOption) (*Provider, error) {
 // This implementation uses raw HTTP streaming to handle SSE events directly,
 // which provides better compatibility with OpenAI-compatible APIs that may
 // include SSE comments or have slight format variations.
+//
+// A stream that receives no data for the idle timeout is considered stalled.
+// Stalled streams are reopened when nothing has been emitted yet, or resumed
+// with Last-Event-ID when the provider sends SSE event ids. Otherwise the
+// channel receives an error chunk wrapping llm.ErrStreamStalled.
 func (p *Provider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
-	resp, err := p.sendStreamRequest(ctx, messages)
+	resp, err := p.sendStreamRequest(ctx, messages, "")
 	if err != nil {
 		return nil, err
 	}
 
 	chunks := make(chan *llm.StreamChunk, 10)
-	go p.processStreamResponse(ctx, resp, chunks)
+	go p.processStreamResponse(ctx, messages, resp, chunks)
 	return chunks, nil
 }
 
-// sendStreamRequest creates and sends the HTTP request for streaming
-func (p *Provider) sendStreamRequest(ctx context.Context, messages []*types.Message) (*http.Response, error) {
+// sendStreamRequest creates and sends the HTTP request for streaming.
+// A non-empty lastEventID asks the provider to resume after that event.
+func (p *Provider) sendStreamRequest(ctx context.Context, messages []*types.Message, lastEventID string) (*http.Response, error) {
 	openaiMessages := convertToOpenAIMessages(messages)
 
 	reqBody := map[string]interface{}{
@@ -186,6 +262,11 @@ func (p *Provider) sendStreamRequest(ctx context.Context, messages []*types.Mess
 		"messages": openaiMessages,
 		"stream":   true,
 	}
+	if p.streamUsage {
+		reqBody["stream_options"] = map[string]interface{}{
+			"include_usage": true,
+		}
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
@@ -199,12 +280,22 @@ func (p *Provider) sendStreamRequest(ctx context.Context, messages []*types.Mess
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 	req.Header.Set("Accept", "text/event-stream")
+	p.setHeaders(req)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		err = fmt.Errorf("failed to send request: %w", err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, types.WrapError(types.ErrorCodeLLMFailure, err).WithRetriable(true)
+	}
+	if p.rateLimiter != nil {
+		p.rateLimiter.Observe(resp.StatusCode, resp.Header)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -213,24 +304,136 @@ func (p *Provider) sendStreamRequest(ctx context.Context, messages []*types.Mess
 		if readErr != nil {
 			return nil, fmt.Errorf("API request failed with status %d (failed to read error body: %w)", resp.StatusCode, readErr)
 		}
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, apiError(resp.StatusCode, body)
 	}
 
 	return resp, nil
 }
 
-// processStreamResponse processes the SSE stream and sends chunks to the channel
-func (p *Provider) processStreamResponse(ctx context.Context, resp *http.Response, chunks chan<- *llm.StreamChunk) {
+// contextOverflowMarkers are phrases providers use when a request exceeds the
+// model's context window
+var contextOverflowMarkers = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"context window",
+	"prompt is too long",
+}
+
+// apiError returns the error for a failed request as an AgentError coded by
+// the response status, with the status in its metadata. It wraps
+// types.ErrContextOverflow when the provider rejected the request for not
+// fitting in the context window.
+func apiError(status int, body []byte) error {
+	err := fmt.Errorf("API request failed with status %d: %s", status, string(body))
+
+	var agentErr *types.AgentError
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		agentErr = types.WrapError(types.ErrorCodeAuth, err)
+	case status == http.StatusTooManyRequests:
+		agentErr = types.WrapError(types.ErrorCodeRateLimited, err).WithRetriable(true)
+	case status >= http.StatusInternalServerError:
+		agentErr = types.WrapError(types.ErrorCodeLLMFailure, err).WithRetriable(true)
+	case isContextOverflow(status, body):
+		agentErr = types.WrapError(types.ErrorCodeContextOverflow, fmt.Errorf("%w: %w", types.ErrContextOverflow, err))
+	default:
+		agentErr = types.WrapError(types.ErrorCodeLLMFailure, err)
+	}
+	return agentErr.WithMetadata("status", status)
+}
+
+// isContextOverflow reports whether a failed response says the request
+// exceeded the context window
+func isContextOverflow(status int, body []byte) bool {
+	if status != http.StatusBadRequest && status != http.StatusRequestEntityTooLarge {
+		return false
+	}
+	lower := strings.ToLower(string(body))
+	for _, marker := range contextOverflowMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamState carries parsing state for one completion across reconnects
+type streamState struct {
+	firstChunk  bool
+	parser      *parser.Parser
+	lastEventID string // Most recent SSE event id, used to resume after a stall
+	emitted     bool   // Whether any content has been received and passed on
+	finished    bool   // Whether the model reported a stop finish reason
+}
+
+// processStreamResponse processes the SSE stream and sends chunks to the channel,
+// reconnecting when the stream stalls
+func (p *Provider) processStreamResponse(ctx context.Context, messages []*types.Message, resp *http.Response, chunks chan<- *llm.StreamChunk) {
 	defer close(chunks)
+
+	state := &streamState{
+		firstChunk: true,
+		parser:     parser.New(p.parser),
+	}
+
+	for reconnects := 0; ; reconnects++ {
+		if !p.readStream(ctx, resp, state, chunks) {
+			return
+		}
+
+		// Restarting is only safe if the caller has seen nothing yet, or the
+		// provider can resume from the last event it sent
+		canResume := !state.emitted || state.lastEventID != ""
+		if reconnects >= maxStreamReconnects || !canResume {
+			chunks <- &llm.StreamChunk{Error: stallError(fmt.Errorf("%w for %s", llm.ErrStreamStalled, p.streamIdleTimeout))}
+			return
+		}
+
+		next, err := p.sendStreamRequest(ctx, messages, state.lastEventID)
+		if err != nil {
+			if ctx.Err() != nil {
+				chunks <- &llm.StreamChunk{Error: ctx.Err()}
+			} else {
+				chunks <- &llm.StreamChunk{Error: stallError(fmt.Errorf("%w; reconnect failed: %v", llm.ErrStreamStalled, err))}
+			}
+			return
+		}
+		resp = next
+	}
+}
+
+// stallError marks a stream stall as a retriable provider failure
+func stallError(err error) error {
+	return types.WrapError(types.ErrorCodeLLMFailure, err).WithRetriable(true)
+}
+
+// readStream reads SSE lines from resp until the stream ends, fails or stalls.
+// It returns true only when the stream stalled and may be reconnected.
+func (p *Provider) readStream(ctx context.Context, resp *http.Response, state *streamState, chunks chan<- *llm.StreamChunk) bool {
 	defer resp.Body.Close()
 
-	scanner := bufio.NewScanner(resp.Body)
-	firstChunk := true
-	thinkingParser := parser.NewThinkingParser()
+	// Closing the body unblocks the scanner when no data arrives in time
+	var stalled atomic.Bool
+	resetDeadline := func() {}
+	if p.streamIdleTimeout > 0 {
+		watchdog := time.AfterFunc(p.streamIdleTimeout, func() {
+			stalled.Store(true)
+			resp.Body.Close()
+		})
+		defer watchdog.Stop()
+		resetDeadline = func() { watchdog.Reset(p.streamIdleTimeout) }
+	}
 
+	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
+		resetDeadline() // Any line, including keep-alive comments, shows the connection is alive
 		line := scanner.Text()
 
+		if id, ok := strings.CutPrefix(line, "id:"); ok {
+			state.lastEventID = strings.TrimSpace(id)
+			continue
+		}
+
 		if !p.isValidSSELine(line) {
 			continue
 		}
@@ -238,20 +441,30 @@ func (p *Provider) processStreamResponse(ctx context.Context, resp *http.Respons
 		data := strings.TrimPrefix(line, "data: ")
 
 		if data == "[DONE]" {
-			p.handleStreamEnd(ctx, thinkingParser, chunks)
-			return
+			p.handleStreamEnd(ctx, state.parser, chunks)
+			return false
 		}
 
-		if !p.processSSEChunk(ctx, data, &firstChunk, thinkingParser, chunks) {
-			return
+		if !p.processSSEChunk(ctx, data, state, chunks) {
+			return false
 		}
 	}
 
-	p.flushRemainingContent(ctx, thinkingParser, chunks)
+	if stalled.Load() && ctx.Err() == nil {
+		if state.finished {
+			// The response was complete; only the trailing usage or [DONE] went missing
+			p.handleStreamEnd(ctx, state.parser, chunks)
+			return false
+		}
+		return true
+	}
+
+	p.flushRemainingContent(ctx, state.parser, chunks)
 
 	if err := scanner.Err(); err != nil {
 		chunks <- &llm.StreamChunk{Error: fmt.Errorf("stream read error: %w", err)}
 	}
+	return false
 }
 
 // isValidSSELine checks if a line is a valid SSE data line
@@ -260,16 +473,14 @@ func (p *Provider) isValidSSELine(line string) bool {
 }
 
 // handleStreamEnd handles the [DONE] marker and flushes remaining content
-func (p *Provider) handleStreamEnd(ctx context.Context, thinkingParser *parser.ThinkingParser, chunks chan<- *llm.StreamChunk) {
-	p.flushRemainingContent(ctx, thinkingParser, chunks)
+func (p *Provider) handleStreamEnd(ctx context.Context, streamParser *parser.Parser, chunks chan<- *llm.StreamChunk) {
+	p.flushRemainingContent(ctx, streamParser, chunks)
 	chunks <- &llm.StreamChunk{Finished: true}
 }
 
-// flushRemainingContent flushes any buffered content from the thinking parser
-func (p *Provider) flushRemainingContent(ctx context.Context, thinkingParser *parser.ThinkingParser, chunks chan<- *llm.StreamChunk) {
-	thinking, message := thinkingParser.Flush()
-	p.sendChunkIfPresent(ctx, thinking, chunks)
-	p.sendChunkIfPresent(ctx, message, chunks)
+// flushRemainingContent flushes any buffered content from the stream parser
+func (p *Provider) flushRemainingContent(ctx context.Context, streamParser *parser.Parser, chunks chan<- *llm.StreamChunk) {
+	p.sendSegments(ctx, streamParser.Flush(), "", chunks)
 }
 
 // sendChunkIfPresent sends a chunk to the channel if it's not nil
@@ -287,7 +498,7 @@ func (p *Provider) sendChunkIfPresent(ctx context.Context, chunk *llm.StreamChun
 }
 
 // processSSEChunk processes a single SSE data chunk
-func (p *Provider) processSSEChunk(ctx context.Context, data string, firstChunk *bool, thinkingParser *parser.ThinkingParser, chunks chan<- *llm.StreamChunk) bool {
+func (p *Provider) processSSEChunk(ctx context.Context, data string, state *streamState, chunks chan<- *llm.StreamChunk) bool {
 	var chunk struct {
 		Choices []struct {
 			Delta struct {
@@ -296,12 +507,33 @@ func (p *Provider) processSSEChunk(ctx context.Context, data string, firstChunk
 			} `json:"delta"`
 			FinishReason *string `json:"finish_reason"`
 		} `json:"choices"`
+		Usage *struct {
+			PromptTokens        int `json:"prompt_tokens"`
+			CompletionTokens    int `json:"completion_tokens"`
+			TotalTokens         int `json:"total_tokens"`
+			PromptTokensDetails struct {
+				CachedTokens int `json:"cached_tokens"`
+			} `json:"prompt_tokens_details"`
+		} `json:"usage"`
 	}
 
 	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
 		return true // Skip malformed chunks silently
 	}
 
+	// Usage arrives on its own chunk (with no choices) after the finish reason
+	if chunk.Usage != nil {
+		usage := &llm.UsageInfo{
+			PromptTokens:       chunk.Usage.PromptTokens,
+			CompletionTokens:   chunk.Usage.CompletionTokens,
+			TotalTokens:        chunk.Usage.TotalTokens,
+			CachedPromptTokens: chunk.Usage.PromptTokensDetails.CachedTokens,
+		}
+		if !p.sendChunkIfPresent(ctx, &llm.StreamChunk{Usage: usage}, chunks) {
+			return false
+		}
+	}
+
 	if len(chunk.Choices) == 0 {
 		return true
 	}
@@ -309,46 +541,46 @@ func (p *Provider) processSSEChunk(ctx context.Context, data string, firstChunk
 	delta := chunk.Choices[0].Delta
 	streamChunk := &llm.StreamChunk{}
 
-	if *firstChunk && delta.Role != "" {
+	if state.firstChunk && delta.Role != "" {
 		streamChunk.Role = delta.Role
-		*firstChunk = false
+		state.firstChunk = false
 	}
 
 	if delta.Content != "" {
-		if !p.processContent(ctx, delta.Content, streamChunk.Role, thinkingParser, chunks) {
+		state.emitted = true
+		if !p.processContent(ctx, delta.Content, streamChunk.Role, state.parser, chunks) {
 			return false
 		}
 	}
 
-	return p.handleFinishReason(ctx, chunk.Choices[0].FinishReason, streamChunk, chunks)
+	return p.handleFinishReason(ctx, chunk.Choices[0].FinishReason, streamChunk, state, chunks)
 }
 
 // processContent parses and sends content chunks
-func (p *Provider) processContent(ctx context.Context, content, role string, thinkingParser *parser.ThinkingParser, chunks chan<- *llm.StreamChunk) bool {
-	thinkingChunk, messageChunk := thinkingParser.Parse(content)
+func (p *Provider) processContent(ctx context.Context, content, role string, streamParser *parser.Parser, chunks chan<- *llm.StreamChunk) bool {
+	return p.sendSegments(ctx, streamParser.Parse(content), role, chunks)
+}
 
-	if thinkingChunk != nil {
-		thinkingChunk.Role = role
-		if !p.sendChunkIfPresent(ctx, thinkingChunk, chunks) {
-			return false
+// sendSegments sends parsed thinking and message content in order
+func (p *Provider) sendSegments(ctx context.Context, segments []parser.Segment, role string, chunks chan<- *llm.StreamChunk) bool {
+	for _, segment := range segments {
+		chunk := &llm.StreamChunk{Content: segment.Content, Type: llm.ContentTypeMessage, Role: role}
+		if segment.Kind == parser.SegmentThinking {
+			chunk.Type = llm.ContentTypeThinking
 		}
-	}
-
-	if messageChunk != nil {
-		messageChunk.Role = role
-		if !p.sendChunkIfPresent(ctx, messageChunk, chunks) {
+		if !p.sendChunkIfPresent(ctx, chunk, chunks) {
 			return false
 		}
 	}
-
 	return true
 }
 
-// handleFinishReason handles the finish_reason field
-func (p *Provider) handleFinishReason(ctx context.Context, finishReason *string, streamChunk *llm.StreamChunk, chunks chan<- *llm.StreamChunk) bool {
+// handleFinishReason handles the finish_reason field. A stop is only recorded:
+// the final chunk is sent at [DONE] so the usage chunk that follows the finish
+// reason still reaches the caller.
+func (p *Provider) handleFinishReason(ctx context.Context, finishReason *string, streamChunk *llm.StreamChunk, state *streamState, chunks chan<- *llm.StreamChunk) bool {
 	if finishReason != nil && *finishReason == "stop" {
-		streamChunk.Finished = true
-		return p.sendChunkIfPresent(ctx, streamChunk, chunks)
+		state.finished = true
 	}
 
 	if streamChunk.Role != "" {
@@ -419,3 +651,11 @@ func convertToOpenAIMessages(messages []*types.Message) []openai.ChatCompletionM
 
 	return openaiMessages
 }
+
+// setHeaders sets the API key and the headers given with WithHeaders on req
+func (p *Provider) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+}
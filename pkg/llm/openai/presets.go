@@ -0,0 +1,173 @@
+package openai
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// LocalAPIKey is sent to local servers that don't check the API key, since
+// the provider requires one
+const LocalAPIKey = "local"
+
+// Preset holds what it takes to use a popular OpenAI-compatible gateway or
+// local server: its base URL, where its API key is usually kept, the headers
+// it expects and how it names models.
+type Preset struct {
+	Name        string
+	Description string
+	BaseURL     string
+
+	// APIKeyEnv is the environment variable the gateway's key is usually
+	// kept in. Empty for local servers, which don't check the key.
+	APIKeyEnv string
+
+	// DefaultModel is used when no model is given. Empty for local servers,
+	// which serve whichever model is loaded.
+	DefaultModel string
+
+	// Headers are sent with every request
+	Headers map[string]string
+
+	// ModelPrefix is prepended to model names that don't start with it
+	ModelPrefix string
+
+	// Vendors maps the start of a bare model name to the vendor qualifying
+	// it, for gateways that name models vendor/model (e.g. "claude-" ->
+	// "anthropic")
+	Vendors map[string]string
+}
+
+// presets are the built-in presets, by name
+var presets = map[string]Preset{
+	"openrouter": {
+		Name:         "openrouter",
+		Description:  "OpenRouter, models from every major vendor",
+		BaseURL:      "https://openrouter.ai/api/v1",
+		APIKeyEnv:    "OPENROUTER_API_KEY",
+		DefaultModel: "anthropic/claude-sonnet-4.5",
+		// Identifies the app on OpenRouter's rankings instead of an unnamed client
+		Headers: map[string]string{
+			"HTTP-Referer": "https://github.com/entrhq/forge",
+			"X-Title":      "Forge",
+		},
+		Vendors: map[string]string{
+			"gpt-":      "openai",
+			"o1":        "openai",
+			"o3":        "openai",
+			"o4":        "openai",
+			"claude-":   "anthropic",
+			"gemini-":   "google",
+			"llama-":    "meta-llama",
+			"mistral-":  "mistralai",
+			"codestral": "mistralai",
+			"deepseek-": "deepseek",
+			"qwen":      "qwen",
+			"grok-":     "x-ai",
+		},
+	},
+	"together": {
+		Name:         "together",
+		Description:  "Together AI, hosted open models",
+		BaseURL:      "https://api.together.xyz/v1",
+		APIKeyEnv:    "TOGETHER_API_KEY",
+		DefaultModel: "Qwen/Qwen3-Coder-480B-A35B-Instruct-FP8",
+	},
+	"groq": {
+		Name:         "groq",
+		Description:  "Groq, fast inference of open models",
+		BaseURL:      "https://api.groq.com/openai/v1",
+		APIKeyEnv:    "GROQ_API_KEY",
+		DefaultModel: "moonshotai/kimi-k2-instruct",
+	},
+	"fireworks": {
+		Name:         "fireworks",
+		Description:  "Fireworks AI, hosted open models",
+		BaseURL:      "https://api.fireworks.ai/inference/v1",
+		APIKeyEnv:    "FIREWORKS_API_KEY",
+		DefaultModel: "accounts/fireworks/models/qwen3-coder-480b-a35b-instruct",
+		ModelPrefix:  "accounts/fireworks/models/",
+	},
+	"lmstudio": {
+		Name:        "lmstudio",
+		Description: "LM Studio's local server",
+		BaseURL:     "http://localhost:1234/v1",
+	},
+	"vllm": {
+		Name:        "vllm",
+		Description: "A local vLLM server",
+		BaseURL:     "http://localhost:8000/v1",
+	},
+}
+
+// LookupPreset returns the built-in preset named name, ignoring case
+func LookupPreset(name string) (Preset, bool) {
+	preset, ok := presets[strings.ToLower(name)]
+	return preset, ok
+}
+
+// Presets returns the built-in presets, by name
+func Presets() []Preset {
+	list := make([]Preset, 0, len(presets))
+	for _, preset := range presets {
+		list = append(list, preset)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// PresetNames returns the names of the built-in presets, sorted
+func PresetNames() []string {
+	var names []string
+	for _, preset := range Presets() {
+		names = append(names, preset.Name)
+	}
+	return names
+}
+
+// Local reports whether the preset is for a local server, which needs no API
+// key and has no default model
+func (p Preset) Local() bool {
+	return p.APIKeyEnv == ""
+}
+
+// APIKey returns the key from the preset's environment variable if it is
+// set, and fallback otherwise. Local servers get LocalAPIKey without a
+// fallback.
+func (p Preset) APIKey(fallback string) string {
+	if !p.Local() {
+		if key := os.Getenv(p.APIKeyEnv); key != "" {
+			return key
+		}
+	}
+	if fallback == "" && p.Local() {
+		return LocalAPIKey
+	}
+	return fallback
+}
+
+// Model returns model as the gateway names it, e.g. "claude-sonnet-4.5" as
+// "anthropic/claude-sonnet-4.5" on OpenRouter or "llama-v3p1-8b-instruct" as
+// "accounts/fireworks/models/llama-v3p1-8b-instruct" on Fireworks. Names that
+// are already qualified are returned unchanged.
+func (p Preset) Model(model string) string {
+	if model == "" {
+		return model
+	}
+	if p.ModelPrefix != "" {
+		if strings.HasPrefix(model, p.ModelPrefix) {
+			return model
+		}
+		return p.ModelPrefix + model
+	}
+	if strings.Contains(model, "/") {
+		return model
+	}
+	lower := strings.ToLower(model)
+	for start, vendor := range p.Vendors {
+		if strings.HasPrefix(lower, start) {
+			return vendor + "/" + model
+		}
+	}
+	return model
+}
@@ -0,0 +1,93 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPresetModel(t *testing.T) {
+	openrouter, _ := LookupPreset("openrouter")
+	fireworks, _ := LookupPreset("fireworks")
+	groq, _ := LookupPreset("groq")
+
+	tests := []struct {
+		preset Preset
+		model  string
+		want   string
+	}{
+		{openrouter, "claude-sonnet-4.5", "anthropic/claude-sonnet-4.5"},
+		{openrouter, "gpt-4o", "openai/gpt-4o"},
+		{openrouter, "o3-mini", "openai/o3-mini"},
+		{openrouter, "anthropic/claude-sonnet-4.5", "anthropic/claude-sonnet-4.5"},
+		{openrouter, "some-unknown-model", "some-unknown-model"},
+		{fireworks, "llama-v3p1-8b-instruct", "accounts/fireworks/models/llama-v3p1-8b-instruct"},
+		{fireworks, "accounts/fireworks/models/llama-v3p1-8b-instruct", "accounts/fireworks/models/llama-v3p1-8b-instruct"},
+		{groq, "llama-3.3-70b-versatile", "llama-3.3-70b-versatile"},
+		{openrouter, "", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.preset.Model(tt.model); got != tt.want {
+			t.Errorf("%s: Model(%q) = %q, want %q", tt.preset.Name, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestPresetAPIKey(t *testing.T) {
+	groq, _ := LookupPreset("groq")
+	lmstudio, _ := LookupPreset("lmstudio")
+
+	t.Setenv("GROQ_API_KEY", "")
+	if got := groq.APIKey("sk-openai"); got != "sk-openai" {
+		t.Errorf("expected the fallback without GROQ_API_KEY, got %q", got)
+	}
+	t.Setenv("GROQ_API_KEY", "gsk-test")
+	if got := groq.APIKey("sk-openai"); got != "gsk-test" {
+		t.Errorf("expected GROQ_API_KEY, got %q", got)
+	}
+
+	if got := lmstudio.APIKey(""); got != LocalAPIKey {
+		t.Errorf("expected a placeholder key for a local server, got %q", got)
+	}
+	if got := lmstudio.APIKey("secret"); got != "secret" {
+		t.Errorf("expected a given key to be kept for a local server, got %q", got)
+	}
+}
+
+func TestLookupPreset(t *testing.T) {
+	if _, ok := LookupPreset("OpenRouter"); !ok {
+		t.Error("expected preset names to ignore case")
+	}
+	if _, ok := LookupPreset("nope"); ok {
+		t.Error("expected an unknown preset not to be found")
+	}
+	for _, preset := range Presets() {
+		if preset.BaseURL == "" {
+			t.Errorf("%s: expected a base URL", preset.Name)
+		}
+		if !preset.Local() && preset.DefaultModel == "" {
+			t.Errorf("%s: expected a hosted gateway to have a default model", preset.Name)
+		}
+	}
+}
+
+func TestWithHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL), WithHeaders(map[string]string{"X-Title": "Forge"}))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if _, err := provider.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if got.Get("X-Title") != "Forge" || got.Get("Authorization") != "Bearer test-key" {
+		t.Errorf("expected the API key and extra headers, got %v", got)
+	}
+}
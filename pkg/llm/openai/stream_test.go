@@ -0,0 +1,334 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/parser"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const testIdleTimeout = 100 * time.Millisecond
+
+// sseContent formats a content delta as an SSE data line
+func sseContent(content string) string {
+	return fmt.Sprintf(`data: {"choices":[{"delta":{"content":%q}}]}`, content)
+}
+
+// newStreamServer serves each request with the lines returned by respond. When
+// stall is true the handler then stops sending without closing the stream.
+func newStreamServer(t *testing.T, respond func(attempt int, r *http.Request) (lines []string, stall bool)) *httptest.Server {
+	t.Helper()
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lines, stall := respond(int(attempts.Add(1)), r)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, line := range lines {
+			fmt.Fprintf(w, "%s\n\n", line)
+		}
+		w.(http.Flusher).Flush()
+		if stall {
+			<-r.Context().Done()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// collectStream drains a completion stream into its content and final error
+func collectStream(t *testing.T, provider *Provider) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := provider.StreamCompletion(ctx, []*types.Message{types.NewUserMessage("hi")})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	var content strings.Builder
+	var streamErr error
+	for chunk := range stream {
+		if chunk.IsError() {
+			streamErr = chunk.Error
+		}
+		content.WriteString(chunk.Content)
+	}
+	return content.String(), streamErr
+}
+
+func newTestProvider(t *testing.T, baseURL string) *Provider {
+	t.Helper()
+	provider, err := NewProvider("test-key", WithBaseURL(baseURL), WithStreamIdleTimeout(testIdleTimeout))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	return provider
+}
+
+func TestStreamCompletion_ReconnectsWhenStalledBeforeContent(t *testing.T) {
+	server := newStreamServer(t, func(attempt int, r *http.Request) ([]string, bool) {
+		if attempt == 1 {
+			return nil, true
+		}
+		return []string{sseContent("hello"), "data: [DONE]"}, false
+	})
+
+	content, err := collectStream(t, newTestProvider(t, server.URL))
+	if err != nil {
+		t.Fatalf("expected reconnect to succeed, got %v", err)
+	}
+	if content != "hello" {
+		t.Errorf("expected content %q, got %q", "hello", content)
+	}
+}
+
+func TestStreamCompletion_ResumesWithLastEventID(t *testing.T) {
+	var resumedFrom string
+	server := newStreamServer(t, func(attempt int, r *http.Request) ([]string, bool) {
+		if attempt == 1 {
+			return []string{"id: 1", sseContent("hello ")}, true
+		}
+		resumedFrom = r.Header.Get("Last-Event-ID")
+		return []string{"id: 2", sseContent("world"), "data: [DONE]"}, false
+	})
+
+	content, err := collectStream(t, newTestProvider(t, server.URL))
+	if err != nil {
+		t.Fatalf("expected resume to succeed, got %v", err)
+	}
+	if content != "hello world" {
+		t.Errorf("expected content %q, got %q", "hello world", content)
+	}
+	if resumedFrom != "1" {
+		t.Errorf("expected Last-Event-ID 1, got %q", resumedFrom)
+	}
+}
+
+func TestStreamCompletion_StallAfterContentIsRetriableError(t *testing.T) {
+	var attempts atomic.Int32
+	server := newStreamServer(t, func(attempt int, r *http.Request) ([]string, bool) {
+		attempts.Add(1)
+		return []string{sseContent("partial")}, true
+	})
+
+	_, err := collectStream(t, newTestProvider(t, server.URL))
+	if err == nil || !llm.IsRetriable(err) {
+		t.Fatalf("expected retriable stall error, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected no reconnect without event ids, got %d requests", attempts.Load())
+	}
+}
+
+func TestStreamCompletion_KeepAliveCommentsPreventStall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		fmt.Fprintf(w, "%s\n\n", sseContent("slow "))
+		for i := 0; i < 4; i++ {
+			flusher.Flush()
+			time.Sleep(testIdleTimeout / 2)
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		fmt.Fprintf(w, "%s\n\ndata: [DONE]\n\n", sseContent("answer"))
+	}))
+	t.Cleanup(server.Close)
+
+	content, err := collectStream(t, newTestProvider(t, server.URL))
+	if err != nil {
+		t.Fatalf("expected keep-alives to hold the stream open, got %v", err)
+	}
+	if content != "slow answer" {
+		t.Errorf("expected content %q, got %q", "slow answer", content)
+	}
+}
+
+func TestStreamCompletion_GivesUpAfterMaxReconnects(t *testing.T) {
+	var attempts atomic.Int32
+	server := newStreamServer(t, func(attempt int, r *http.Request) ([]string, bool) {
+		attempts.Add(1)
+		return nil, true
+	})
+
+	_, err := collectStream(t, newTestProvider(t, server.URL))
+	if !llm.IsRetriable(err) {
+		t.Fatalf("expected retriable stall error, got %v", err)
+	}
+	if got := int(attempts.Load()); got != maxStreamReconnects+1 {
+		t.Errorf("expected %d requests, got %d", maxStreamReconnects+1, got)
+	}
+}
+
+func TestStreamCompletion_ReportsUsageAfterFinish(t *testing.T) {
+	server := newStreamServer(t, func(attempt int, r *http.Request) ([]string, bool) {
+		return []string{
+			sseContent("hi"),
+			`data: {"choices":[{"delta":{},"finish_reason":"stop"}]}`,
+			`data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15,"prompt_tokens_details":{"cached_tokens":8}}}`,
+			"data: [DONE]",
+		}, false
+	})
+
+	stream, err := newTestProvider(t, server.URL).StreamCompletion(context.Background(), []*types.Message{types.NewUserMessage("hi")})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	var usage *llm.UsageInfo
+	for chunk := range stream {
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if chunk.IsLast() && usage == nil {
+			t.Fatal("stream finished before the usage chunk")
+		}
+	}
+	if usage == nil {
+		t.Fatal("expected a usage chunk")
+	}
+	if usage.PromptTokens != 12 || usage.CompletionTokens != 3 || usage.TotalTokens != 15 || usage.CachedPromptTokens != 8 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestStreamCompletion_StreamUsageOption(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprint(enabled), func(t *testing.T) {
+			var body []byte
+			server := newStreamServer(t, func(attempt int, r *http.Request) ([]string, bool) {
+				body, _ = io.ReadAll(r.Body)
+				return []string{sseContent("hi"), "data: [DONE]"}, false
+			})
+
+			provider, err := NewProvider("test-key", WithBaseURL(server.URL), WithStreamUsage(enabled))
+			if err != nil {
+				t.Fatalf("NewProvider failed: %v", err)
+			}
+			if _, err := collectStream(t, provider); err != nil {
+				t.Fatalf("stream failed: %v", err)
+			}
+			if got := strings.Contains(string(body), "include_usage"); got != enabled {
+				t.Errorf("expected include_usage in the request to be %v, got body %s", enabled, body)
+			}
+		})
+	}
+}
+
+func TestStreamCompletion_APIErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		code      types.ErrorCode
+		retriable bool
+	}{
+		{"openai overflow", http.StatusBadRequest, `{"error":{"message":"This model's maximum context length is 128000 tokens.","code":"context_length_exceeded"}}`, types.ErrorCodeContextOverflow, false},
+		{"anthropic overflow", http.StatusBadRequest, `{"error":{"message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, types.ErrorCodeContextOverflow, false},
+		{"other bad request", http.StatusBadRequest, `{"error":{"message":"invalid model"}}`, types.ErrorCodeLLMFailure, false},
+		{"unauthorized", http.StatusUnauthorized, `{"error":{"message":"invalid api key"}}`, types.ErrorCodeAuth, false},
+		{"rate limit", http.StatusTooManyRequests, `{"error":{"message":"maximum context length"}}`, types.ErrorCodeRateLimited, true},
+		{"server error", http.StatusBadGateway, `bad gateway`, types.ErrorCodeLLMFailure, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, tt.body, tt.status)
+			}))
+			t.Cleanup(server.Close)
+
+			_, err := newTestProvider(t, server.URL).StreamCompletion(context.Background(), []*types.Message{types.NewUserMessage("hi")})
+			agentErr, ok := types.IsAgentError(err)
+			if !ok {
+				t.Fatalf("expected an AgentError, got %v", err)
+			}
+			if agentErr.Code != tt.code || agentErr.Retriable != tt.retriable {
+				t.Errorf("got %s (retriable %v), want %s (retriable %v)", agentErr.Code, agentErr.Retriable, tt.code, tt.retriable)
+			}
+			if agentErr.Metadata["status"] != tt.status {
+				t.Errorf("status metadata = %v, want %d", agentErr.Metadata["status"], tt.status)
+			}
+			if overflow := errors.Is(err, types.ErrContextOverflow); overflow != (tt.code == types.ErrorCodeContextOverflow) {
+				t.Errorf("errors.Is(err, ErrContextOverflow) = %v", overflow)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprintf("API request failed with status %d", tt.status)) {
+				t.Errorf("unexpected message %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestStreamCompletion_ReportsRateLimitHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-limit-requests", "500")
+		w.Header().Set("x-ratelimit-remaining-requests", "499")
+		w.Header().Set("x-ratelimit-reset-requests", "120ms")
+		w.Header().Set("Retry-After", "2")
+		http.Error(w, `{"error":{"message":"slow down"}}`, http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	limiter := llm.NewRateLimiter()
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if _, err := provider.StreamCompletion(context.Background(), []*types.Message{types.NewUserMessage("hi")}); err == nil {
+		t.Fatal("expected the 429 to fail the call")
+	}
+
+	status := limiter.Status()
+	if status.RequestLimit != 500 || status.RemainingRequests != 499 {
+		t.Errorf("requests = %d of %d, want 499 of 500", status.RemainingRequests, status.RequestLimit)
+	}
+	if wait := time.Until(status.RetryAt); wait < time.Second || wait > 2*time.Second {
+		t.Errorf("expected to retry in about 2s, got %v", wait)
+	}
+}
+
+func TestStreamCompletion_ParserConfig(t *testing.T) {
+	server := newStreamServer(t, func(attempt int, r *http.Request) ([]string, bool) {
+		return []string{sseContent("<thi"), sseContent("nk>plan</think>"), sseContent("answer"), "data: [DONE]"}, false
+	})
+
+	tests := []struct {
+		name         string
+		cfg          parser.Config
+		wantThinking string
+	}{
+		{name: "default tags", cfg: parser.DefaultConfig(), wantThinking: "plan"},
+		{name: "hidden", cfg: parser.Config{ThinkingTags: parser.DefaultThinkingTags, Thinking: parser.ThinkingSuppress}, wantThinking: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider("test-key", WithBaseURL(server.URL), WithParserConfig(tt.cfg))
+			if err != nil {
+				t.Fatalf("NewProvider failed: %v", err)
+			}
+			stream, err := provider.StreamCompletion(context.Background(), []*types.Message{types.NewUserMessage("hi")})
+			if err != nil {
+				t.Fatalf("StreamCompletion failed: %v", err)
+			}
+			var thinking, message strings.Builder
+			for chunk := range stream {
+				if chunk.IsThinking() {
+					thinking.WriteString(chunk.Content)
+				} else {
+					message.WriteString(chunk.Content)
+				}
+			}
+			if thinking.String() != tt.wantThinking || message.String() != "answer" {
+				t.Errorf("got thinking %q and message %q", thinking.String(), message.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultToolTag is the tag the agent's tool calls are wrapped in
+const DefaultToolTag = "tool"
+
+// DefaultThinkingTags are the tags models wrap their reasoning in: <thinking>
+// from Forge's prompt, <think> from reasoning models such as DeepSeek R1 and
+// Qwen served raw.
+var DefaultThinkingTags = []string{"thinking", "think"}
+
+// ThinkingTruncatedMarker ends the thinking surfaced once MaxThinkingTokens
+// is reached
+const ThinkingTruncatedMarker = "\n[…]"
+
+// charsPerToken estimates tokens from characters for MaxThinkingTokens
+const charsPerToken = 4
+
+// ThinkingMode is what the parser does with thinking content
+type ThinkingMode int
+
+const (
+	// ThinkingEmit returns thinking as SegmentThinking
+	ThinkingEmit ThinkingMode = iota
+	// ThinkingSuppress drops thinking content
+	ThinkingSuppress
+)
+
+// Config configures a Parser
+type Config struct {
+	// ThinkingTags are the names of the tags whose content is thinking; none
+	// to leave thinking in the text
+	ThinkingTags []string
+
+	// ToolTag is the name of the tag tool calls are wrapped in; empty to
+	// treat tool calls as text. Tags inside a tool call, or inside CDATA in
+	// it, are never parsed as thinking.
+	ToolTag string
+
+	// ExtractToolCalls returns tool calls as SegmentToolCallStart and
+	// SegmentToolCall. Otherwise they are returned as text, tags included,
+	// for a later stage to extract.
+	ExtractToolCalls bool
+
+	// Thinking is whether thinking is returned or dropped
+	Thinking ThinkingMode
+
+	// MaxThinkingTokens bounds the thinking returned per stream, ending it
+	// with ThinkingTruncatedMarker; 0 for no limit
+	MaxThinkingTokens int
+}
+
+// DefaultConfig returns the configuration of a provider's parser: thinking
+// in the default tags is returned, and tool calls pass through as text with
+// their content shielded from thinking tags.
+func DefaultConfig() Config {
+	return Config{
+		ThinkingTags: DefaultThinkingTags,
+		ToolTag:      DefaultToolTag,
+	}
+}
+
+// SegmentKind is the kind of content in a Segment
+type SegmentKind int
+
+const (
+	// SegmentText is message text
+	SegmentText SegmentKind = iota
+	// SegmentThinking is thinking content, without its tags
+	SegmentThinking
+	// SegmentToolCallStart signals an opening tool tag, before the call's
+	// content has arrived
+	SegmentToolCallStart
+	// SegmentToolCall is a complete tool call's content, without its tags
+	// and surrounding whitespace
+	SegmentToolCall
+)
+
+// Segment is a run of content of one kind
+type Segment struct {
+	Kind    SegmentKind
+	Content string
+}
+
+// block is the kind of tag the parser is inside
+type block int
+
+const (
+	blockNone block = iota
+	blockThinking
+	blockTool
+)
+
+// Parser splits a stream of model output into text, thinking and tool calls.
+// Tags may be split across chunks anywhere: a possible start of a tag is held
+// back until it either completes or can't be a tag anymore.
+type Parser struct {
+	cfg Config
+
+	block       block
+	thinkingTag string // Name of the open thinking tag
+	depth       int    // Nesting of the open thinking tag
+	cdata       bool   // Inside CDATA in a tool call
+	pending     string // Possible start of a tag
+
+	toolContent      strings.Builder
+	thinkingSurfaced int
+	truncated        bool
+
+	out     []Segment
+	current strings.Builder
+	kind    SegmentKind
+}
+
+// New creates a parser with cfg
+func New(cfg Config) *Parser {
+	return &Parser{cfg: cfg}
+}
+
+// Parse processes a chunk of the stream and returns its segments in order,
+// consecutive content of one kind merged
+func (p *Parser) Parse(content string) []Segment {
+	for _, r := range content {
+		p.pending += string(r)
+		p.match()
+	}
+	return p.take()
+}
+
+// Flush ends the stream: held back text is returned as content, and an
+// unclosed tool call is returned if it has content
+func (p *Parser) Flush() []Segment {
+	if p.pending != "" {
+		pending := p.pending
+		p.pending = ""
+		p.content(pending)
+	}
+	if p.block == blockTool && p.cfg.ExtractToolCalls {
+		if call := strings.TrimSpace(p.toolContent.String()); call != "" {
+			p.emit(SegmentToolCall, call)
+		}
+	}
+	p.endBlock()
+	return p.take()
+}
+
+// Reset clears the parser's state for a new stream
+func (p *Parser) Reset() {
+	p.endBlock()
+	p.pending = ""
+	p.thinkingSurfaced = 0
+	p.truncated = false
+	p.out = nil
+	p.current.Reset()
+}
+
+// InThinking reports whether the parser is inside a thinking tag
+func (p *Parser) InThinking() bool {
+	return p.block == blockThinking
+}
+
+// InToolCall reports whether the parser is inside a tool call
+func (p *Parser) InToolCall() bool {
+	return p.block == blockTool
+}
+
+// ToolContent returns the content of the tool call being parsed so far, for
+// picking out details such as the tool name before the call completes
+func (p *Parser) ToolContent() string {
+	return p.toolContent.String()
+}
+
+// match consumes pending up to the possible start of a tag
+func (p *Parser) match() {
+	for p.pending != "" {
+		tags := p.tags()
+		prefix := false
+		for _, tag := range tags {
+			if p.pending == tag {
+				p.pending = ""
+				p.tag(tag)
+				return
+			}
+			if strings.HasPrefix(tag, p.pending) {
+				prefix = true
+			}
+		}
+		if prefix {
+			return
+		}
+		// The first character can't start a tag
+		_, size := utf8.DecodeRuneInString(p.pending)
+		p.content(p.pending[:size])
+		p.pending = p.pending[size:]
+	}
+}
+
+// tags returns the tags recognized where the parser is
+func (p *Parser) tags() []string {
+	switch p.block {
+	case blockThinking:
+		return []string{"<" + p.thinkingTag + ">", "</" + p.thinkingTag + ">"}
+	case blockTool:
+		if p.cdata {
+			return []string{"]]>"}
+		}
+		return []string{"</" + p.cfg.ToolTag + ">", "<![CDATA["}
+	}
+	tags := make([]string, 0, len(p.cfg.ThinkingTags)+1)
+	for _, name := range p.cfg.ThinkingTags {
+		tags = append(tags, "<"+name+">")
+	}
+	if p.cfg.ToolTag != "" {
+		tags = append(tags, "<"+p.cfg.ToolTag+">")
+	}
+	return tags
+}
+
+// tag handles a complete tag
+func (p *Parser) tag(tag string) {
+	switch p.block {
+	case blockThinking:
+		if strings.HasPrefix(tag, "</") {
+			p.depth--
+			if p.depth == 0 {
+				p.endBlock()
+				return
+			}
+		} else {
+			p.depth++
+		}
+		p.content(tag) // A nested tag is part of the thinking
+
+	case blockTool:
+		switch tag {
+		case "<![CDATA[":
+			p.cdata = true
+			p.content(tag)
+		case "]]>":
+			p.cdata = false
+			p.content(tag)
+		default:
+			if p.cfg.ExtractToolCalls {
+				p.emit(SegmentToolCall, strings.TrimSpace(p.toolContent.String()))
+			} else {
+				p.emit(SegmentText, tag)
+			}
+			p.endBlock()
+		}
+
+	default:
+		name := strings.TrimSuffix(strings.TrimPrefix(tag, "<"), ">")
+		if name == p.cfg.ToolTag {
+			p.block = blockTool
+			if p.cfg.ExtractToolCalls {
+				p.emit(SegmentToolCallStart, "")
+			} else {
+				p.emit(SegmentText, tag)
+			}
+			return
+		}
+		p.block = blockThinking
+		p.thinkingTag = name
+		p.depth = 1
+	}
+}
+
+// endBlock returns the parser to text
+func (p *Parser) endBlock() {
+	p.block = blockNone
+	p.thinkingTag = ""
+	p.depth = 0
+	p.cdata = false
+	p.toolContent.Reset()
+}
+
+// content handles content of the block the parser is in
+func (p *Parser) content(text string) {
+	switch p.block {
+	case blockThinking:
+		p.thinking(text)
+	case blockTool:
+		if p.cfg.ExtractToolCalls {
+			p.toolContent.WriteString(text)
+		} else {
+			p.emit(SegmentText, text)
+		}
+	default:
+		p.emit(SegmentText, text)
+	}
+}
+
+// thinking returns thinking content unless it is suppressed or over the limit
+func (p *Parser) thinking(text string) {
+	if p.cfg.Thinking == ThinkingSuppress {
+		return
+	}
+	if limit := p.cfg.MaxThinkingTokens * charsPerToken; limit > 0 && p.thinkingSurfaced >= limit {
+		if !p.truncated {
+			p.truncated = true
+			p.emit(SegmentThinking, ThinkingTruncatedMarker)
+		}
+		return
+	}
+	p.thinkingSurfaced += utf8.RuneCountInString(text)
+	p.emit(SegmentThinking, text)
+}
+
+// emit adds content to the segments of the current chunk
+func (p *Parser) emit(kind SegmentKind, text string) {
+	mergeable := kind == SegmentText || kind == SegmentThinking
+	if mergeable && p.kind == kind && p.current.Len() > 0 {
+		p.current.WriteString(text)
+		return
+	}
+	p.endRun()
+	if !mergeable {
+		p.out = append(p.out, Segment{Kind: kind, Content: text})
+		return
+	}
+	p.kind = kind
+	p.current.WriteString(text)
+}
+
+// endRun moves the run of mergeable content into the segments
+func (p *Parser) endRun() {
+	if p.current.Len() == 0 {
+		return
+	}
+	p.out = append(p.out, Segment{Kind: p.kind, Content: p.current.String()})
+	p.current.Reset()
+}
+
+// take returns the segments parsed since the last call
+func (p *Parser) take() []Segment {
+	p.endRun()
+	out := p.out
+	p.out = nil
+	return out
+}
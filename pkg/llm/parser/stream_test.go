@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// agentConfig is the core's configuration: tool calls only
+var agentConfig = Config{ToolTag: DefaultToolTag, ExtractToolCalls: true}
+
+// parseAll feeds chunks to a parser with cfg and returns the segments,
+// merging text and thinking continued across chunks
+func parseAll(cfg Config, chunks ...string) []Segment {
+	p := New(cfg)
+	var segments []Segment
+	for _, chunk := range chunks {
+		segments = append(segments, p.Parse(chunk)...)
+	}
+	segments = append(segments, p.Flush()...)
+
+	var merged []Segment
+	for _, s := range segments {
+		if n := len(merged); n > 0 && merged[n-1].Kind == s.Kind && (s.Kind == SegmentText || s.Kind == SegmentThinking) {
+			merged[n-1].Content += s.Content
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// splits returns input cut at every position, and cut into single characters
+func splits(input string) [][]string {
+	runes := []rune(input)
+	var all [][]string
+	for i := 1; i < len(runes); i++ {
+		all = append(all, []string{string(runes[:i]), string(runes[i:])})
+	}
+	single := make([]string, len(runes))
+	for i, r := range runes {
+		single[i] = string(r)
+	}
+	return append(all, single)
+}
+
+func TestParser_SplitsAtEveryBoundary(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		input string
+		want  []Segment
+	}{
+		{
+			name:  "thinking then text",
+			cfg:   DefaultConfig(),
+			input: "<thinking>Let me think</thinking>The answer",
+			want:  []Segment{{SegmentThinking, "Let me think"}, {SegmentText, "The answer"}},
+		},
+		{
+			name:  "think tag of reasoning models",
+			cfg:   DefaultConfig(),
+			input: "<think>hmm</think>ok",
+			want:  []Segment{{SegmentThinking, "hmm"}, {SegmentText, "ok"}},
+		},
+		{
+			name:  "angle brackets in text",
+			cfg:   DefaultConfig(),
+			input: "if a < b && c > d { <thin }",
+			want:  []Segment{{SegmentText, "if a < b && c > d { <thin }"}},
+		},
+		{
+			name:  "closing tag of the other name stays thinking",
+			cfg:   DefaultConfig(),
+			input: "<think>a</thinking>b</think>c",
+			want:  []Segment{{SegmentThinking, "a</thinking>b"}, {SegmentText, "c"}},
+		},
+		{
+			name:  "nested thinking tags",
+			cfg:   DefaultConfig(),
+			input: "<thinking>a <thinking>b</thinking> c</thinking>d",
+			want:  []Segment{{SegmentThinking, "a <thinking>b</thinking> c"}, {SegmentText, "d"}},
+		},
+		{
+			name:  "tool tag inside thinking is thinking",
+			cfg:   agentConfig.with(DefaultThinkingTags),
+			input: "<thinking>call <tool>x</tool> next</thinking>done",
+			want:  []Segment{{SegmentThinking, "call <tool>x</tool> next"}, {SegmentText, "done"}},
+		},
+		{
+			name:  "tool call between text",
+			cfg:   agentConfig,
+			input: "Reading it.<tool>\n<tool_name>read_file</tool_name>\n</tool>Done",
+			want: []Segment{
+				{SegmentText, "Reading it."},
+				{SegmentToolCallStart, ""},
+				{SegmentToolCall, "<tool_name>read_file</tool_name>"},
+				{SegmentText, "Done"},
+			},
+		},
+		{
+			name:  "tags in CDATA are tool content",
+			cfg:   agentConfig.with(DefaultThinkingTags),
+			input: "<tool><content><![CDATA[<thinking>x</thinking></tool>]]></content></tool>",
+			want: []Segment{
+				{SegmentToolCallStart, ""},
+				{SegmentToolCall, "<content><![CDATA[<thinking>x</thinking></tool>]]></content>"},
+			},
+		},
+		{
+			name:  "tool_name outside a tool call is text",
+			cfg:   agentConfig,
+			input: "use <tool_name> and <tools>",
+			want:  []Segment{{SegmentText, "use <tool_name> and <tools>"}},
+		},
+		{
+			name:  "provider passes tool calls through, shielding their content",
+			cfg:   DefaultConfig(),
+			input: "a<tool><c><![CDATA[<think>]]></c><x><thinking></x></tool><think>t</think>",
+			want: []Segment{
+				{SegmentText, "a<tool><c><![CDATA[<think>]]></c><x><thinking></x></tool>"},
+				{SegmentThinking, "t"},
+			},
+		},
+		{
+			name:  "unclosed tool call is returned at the end",
+			cfg:   agentConfig,
+			input: "<tool> partial </to",
+			want:  []Segment{{SegmentToolCallStart, ""}, {SegmentToolCall, "partial </to"}},
+		},
+		{
+			name:  "partial tag at the end is text",
+			cfg:   DefaultConfig(),
+			input: "ends with <thin",
+			want:  []Segment{{SegmentText, "ends with <thin"}},
+		},
+		{
+			name:  "multibyte content",
+			cfg:   DefaultConfig(),
+			input: "<think>héllo ✓</think>naïve <b>",
+			want:  []Segment{{SegmentThinking, "héllo ✓"}, {SegmentText, "naïve <b>"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAll(tt.cfg, tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("whole input: got %q, want %q", got, tt.want)
+			}
+			for _, chunks := range splits(tt.input) {
+				if got := parseAll(tt.cfg, chunks...); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("chunks %q: got %q, want %q", chunks, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// with returns cfg with thinking tags
+func (cfg Config) with(tags []string) Config {
+	cfg.ThinkingTags = tags
+	return cfg
+}
+
+func TestParser_SegmentsInOrder(t *testing.T) {
+	p := New(DefaultConfig())
+	got := p.Parse("before<thinking>mid</thinking>after")
+	want := []Segment{{SegmentText, "before"}, {SegmentThinking, "mid"}, {SegmentText, "after"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParser_SuppressThinking(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Thinking = ThinkingSuppress
+	got := parseAll(cfg, "<thinking>sec", "ret</thinking>vis", "ible")
+	want := []Segment{{SegmentText, "visible"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParser_MaxThinkingTokens(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxThinkingTokens = 2 // 8 characters
+	got := parseAll(cfg, "<thinking>"+strings.Repeat("x", 20)+"</thinking>answer<thinking>more</thinking>")
+	want := []Segment{
+		{SegmentThinking, strings.Repeat("x", 8) + ThinkingTruncatedMarker},
+		{SegmentText, "answer"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParser_ToolContentSoFar(t *testing.T) {
+	p := New(agentConfig)
+	p.Parse("<tool><tool_name>read_file</tool_name><argu")
+	if !p.InToolCall() {
+		t.Fatal("expected the parser to be in a tool call")
+	}
+	if got := p.ToolContent(); got != "<tool_name>read_file</tool_name><argu" {
+		t.Errorf("ToolContent() = %q", got)
+	}
+
+	p.Reset()
+	if p.InToolCall() || p.ToolContent() != "" {
+		t.Error("expected Reset to clear the tool call")
+	}
+}
@@ -0,0 +1,328 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Priority orders provider calls that compete for the same rate limit.
+type Priority int
+
+const (
+	// PriorityForeground is for calls the user is waiting on, such as the
+	// agent loop. They only wait once the limit is exhausted.
+	PriorityForeground Priority = iota
+
+	// PriorityBackground is for calls that can be deferred, such as context
+	// summarization. They leave a reserve of the limit to foreground calls,
+	// yield to foreground calls that are waiting, and run a few at a time.
+	PriorityBackground
+)
+
+const (
+	// DefaultBackgroundReserve is the fraction of each limit that background
+	// calls leave to foreground calls
+	DefaultBackgroundReserve = 0.1
+
+	// DefaultMaxBackground is how many background calls may run at once
+	DefaultMaxBackground = 2
+
+	// defaultRetryAfter is how long calls wait after a 429 that doesn't say
+	// when to retry
+	defaultRetryAfter = time.Second
+)
+
+// RateLimitStatus is a provider's rate limit as last reported in its
+// response headers. Counts are -1 and times are zero when unknown.
+type RateLimitStatus struct {
+	RequestLimit      int
+	RemainingRequests int
+	RequestsReset     time.Time
+
+	TokenLimit      int
+	RemainingTokens int
+	TokensReset     time.Time
+
+	// RetryAt is when the provider asked to be called again after a 429
+	RetryAt time.Time
+}
+
+// RateLimiter schedules calls to one provider around its rate limit. The
+// provider reports its limit with Observe; middleware.RateLimit makes each
+// call Wait for its turn. Calls that have to wait are announced with rate
+// limit wait and resume events.
+type RateLimiter struct {
+	mu                sync.Mutex
+	status            RateLimitStatus
+	reserve           float64
+	background        chan struct{}
+	foregroundWaiting int
+	wake              chan struct{} // Closed and replaced when waiters should re-check
+	emit              func(*types.AgentEvent)
+}
+
+// RateLimiterOption configures a RateLimiter.
+type RateLimiterOption func(*RateLimiter)
+
+// WithBackgroundReserve sets the fraction of each limit that background
+// calls leave to foreground calls (default DefaultBackgroundReserve).
+func WithBackgroundReserve(fraction float64) RateLimiterOption {
+	return func(l *RateLimiter) {
+		l.reserve = fraction
+	}
+}
+
+// WithMaxBackground sets how many background calls may run at once
+// (default DefaultMaxBackground).
+func WithMaxBackground(n int) RateLimiterOption {
+	return func(l *RateLimiter) {
+		if n > 0 {
+			l.background = make(chan struct{}, n)
+		}
+	}
+}
+
+// NewRateLimiter creates a rate limiter that knows nothing of the limit
+// until the provider reports it.
+func NewRateLimiter(opts ...RateLimiterOption) *RateLimiter {
+	l := &RateLimiter{
+		status: RateLimitStatus{
+			RequestLimit:      -1,
+			RemainingRequests: -1,
+			TokenLimit:        -1,
+			RemainingTokens:   -1,
+		},
+		reserve:    DefaultBackgroundReserve,
+		background: make(chan struct{}, DefaultMaxBackground),
+		wake:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// SetEventEmitter sets the function rate limit wait and resume events are
+// sent to.
+func (l *RateLimiter) SetEventEmitter(emit func(*types.AgentEvent)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.emit = emit
+}
+
+// Status returns the rate limit as last reported by the provider.
+func (l *RateLimiter) Status() RateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.status
+}
+
+// Observe records the rate limit reported by a response. It reads the
+// x-ratelimit-* headers of OpenAI-compatible APIs, Anthropic's
+// anthropic-ratelimit-* headers and Retry-After. Headers that are absent
+// leave what is known unchanged.
+func (l *RateLimiter) Observe(statusCode int, header http.Header) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := &l.status
+	readCount(header, &s.RequestLimit, "x-ratelimit-limit-requests", "anthropic-ratelimit-requests-limit")
+	readCount(header, &s.RemainingRequests, "x-ratelimit-remaining-requests", "anthropic-ratelimit-requests-remaining")
+	readReset(header, now, &s.RequestsReset, "x-ratelimit-reset-requests", "anthropic-ratelimit-requests-reset")
+	readCount(header, &s.TokenLimit, "x-ratelimit-limit-tokens", "anthropic-ratelimit-tokens-limit")
+	readCount(header, &s.RemainingTokens, "x-ratelimit-remaining-tokens", "anthropic-ratelimit-tokens-remaining")
+	readReset(header, now, &s.TokensReset, "x-ratelimit-reset-tokens", "anthropic-ratelimit-tokens-reset")
+
+	if retryAt, ok := retryAfter(header, now); ok {
+		s.RetryAt = retryAt
+	} else if statusCode == http.StatusTooManyRequests {
+		s.RetryAt = now.Add(defaultRetryAfter)
+	}
+
+	l.broadcast()
+}
+
+// Wait blocks until a call of the given priority may be sent, then returns a
+// function the caller must call once the call has finished. It returns the
+// context's error if ctx is done first.
+func (l *RateLimiter) Wait(ctx context.Context, priority Priority) (release func(), err error) {
+	background := priority == PriorityBackground
+	release = func() {}
+	if background {
+		select {
+		case l.background <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-l.background }) }
+	}
+
+	announced := false
+	waiting := false
+	defer func() {
+		if waiting && !background {
+			l.mu.Lock()
+			l.foregroundWaiting--
+			l.broadcast()
+			l.mu.Unlock()
+		}
+	}()
+
+	for {
+		l.mu.Lock()
+		delay, reason := l.delay(priority, time.Now())
+		if delay == 0 {
+			if l.status.RemainingRequests > 0 {
+				l.status.RemainingRequests--
+			}
+			emit := l.emit
+			l.mu.Unlock()
+			if announced && emit != nil {
+				emit(types.NewRateLimitResumeEvent(background))
+			}
+			return release, nil
+		}
+		if !waiting && !background {
+			l.foregroundWaiting++
+		}
+		waiting = true
+		wake := l.wake
+		emit := l.emit
+		l.mu.Unlock()
+
+		if delay > 0 && !announced && emit != nil {
+			emit(types.NewRateLimitWaitEvent(delay, reason, background))
+			announced = true
+		}
+		if err := sleep(ctx, delay, wake); err != nil {
+			release()
+			return nil, err
+		}
+	}
+}
+
+// delay returns how long a call must wait before it may be sent, and why.
+// A negative delay means the call waits for a foreground call to go first.
+// Must be called with l.mu held.
+func (l *RateLimiter) delay(priority Priority, now time.Time) (time.Duration, string) {
+	s := l.status
+	if s.RetryAt.After(now) {
+		return s.RetryAt.Sub(now), types.RateLimitReasonRetryAfter
+	}
+
+	background := priority == PriorityBackground
+	if exhausted(s.RemainingRequests, s.RequestLimit, s.RequestsReset, now, l.reserveFor(background)) {
+		return s.RequestsReset.Sub(now), types.RateLimitReasonRequests
+	}
+	if exhausted(s.RemainingTokens, s.TokenLimit, s.TokensReset, now, l.reserveFor(background)) {
+		return s.TokensReset.Sub(now), types.RateLimitReasonTokens
+	}
+	if background && l.foregroundWaiting > 0 {
+		return -1, ""
+	}
+	return 0, ""
+}
+
+// reserveFor returns the fraction of a limit a call must leave unused
+func (l *RateLimiter) reserveFor(background bool) float64 {
+	if background {
+		return l.reserve
+	}
+	return 0
+}
+
+// broadcast wakes every waiting call to re-check the limit. Must be called
+// with l.mu held.
+func (l *RateLimiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// exhausted reports whether remaining is at or below reserve of limit until
+// a reset that hasn't happened yet. Unknown counts never exhaust.
+func exhausted(remaining, limit int, reset, now time.Time, reserve float64) bool {
+	if remaining < 0 || !reset.After(now) {
+		return false
+	}
+	threshold := 0
+	if limit > 0 {
+		threshold = int(math.Ceil(float64(limit) * reserve))
+	}
+	return remaining <= threshold
+}
+
+// sleep waits for delay, or until wake is closed when delay is negative or
+// something changes first
+func sleep(ctx context.Context, delay time.Duration, wake <-chan struct{}) error {
+	var timeout <-chan time.Time
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-timeout:
+		return nil
+	case <-wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readCount sets *dst from the first of keys present as an integer
+func readCount(header http.Header, dst *int, keys ...string) {
+	for _, key := range keys {
+		if n, err := strconv.Atoi(header.Get(key)); err == nil {
+			*dst = n
+			return
+		}
+	}
+}
+
+// readReset sets *dst from the first of keys present as a reset time:
+// either a duration such as "6m0s" (OpenAI) or an RFC 3339 time (Anthropic)
+func readReset(header http.Header, now time.Time, dst *time.Time, keys ...string) {
+	for _, key := range keys {
+		value := header.Get(key)
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err == nil {
+			*dst = now.Add(d)
+			return
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			*dst = t
+			return
+		}
+	}
+}
+
+// retryAfter returns when to retry according to retry-after-ms or
+// Retry-After, which is either seconds or an HTTP date
+func retryAfter(header http.Header, now time.Time) (time.Time, bool) {
+	if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil {
+		return now.Add(time.Duration(ms * float64(time.Millisecond))), true
+	}
+	value := header.Get("Retry-After")
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return now.Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,285 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestRateLimiter_Observe(t *testing.T) {
+	reset := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		check  func(t *testing.T, s RateLimitStatus)
+	}{
+		{
+			name: "openai",
+			header: map[string]string{
+				"x-ratelimit-limit-requests":     "500",
+				"x-ratelimit-remaining-requests": "499",
+				"x-ratelimit-reset-requests":     "6m0s",
+				"x-ratelimit-limit-tokens":       "30000",
+				"x-ratelimit-remaining-tokens":   "29000",
+				"x-ratelimit-reset-tokens":       "20ms",
+			},
+			check: func(t *testing.T, s RateLimitStatus) {
+				if s.RequestLimit != 500 || s.RemainingRequests != 499 || s.TokenLimit != 30000 || s.RemainingTokens != 29000 {
+					t.Errorf("unexpected counts: %+v", s)
+				}
+				if until := time.Until(s.RequestsReset); until < 5*time.Minute || until > 6*time.Minute {
+					t.Errorf("requests reset in %v, want about 6m", until)
+				}
+			},
+		},
+		{
+			name: "anthropic",
+			header: map[string]string{
+				"anthropic-ratelimit-requests-limit":     "50",
+				"anthropic-ratelimit-requests-remaining": "0",
+				"anthropic-ratelimit-requests-reset":     reset.Format(time.RFC3339),
+			},
+			check: func(t *testing.T, s RateLimitStatus) {
+				if s.RequestLimit != 50 || s.RemainingRequests != 0 || !s.RequestsReset.Equal(reset) {
+					t.Errorf("unexpected status: %+v", s)
+				}
+				if s.RemainingTokens != -1 {
+					t.Errorf("expected unreported tokens to stay unknown, got %d", s.RemainingTokens)
+				}
+			},
+		},
+		{
+			name:   "retry after seconds",
+			status: http.StatusTooManyRequests,
+			header: map[string]string{"Retry-After": "30"},
+			check: func(t *testing.T, s RateLimitStatus) {
+				if until := time.Until(s.RetryAt); until < 29*time.Second || until > 30*time.Second {
+					t.Errorf("retry in %v, want about 30s", until)
+				}
+			},
+		},
+		{
+			name:   "retry after date",
+			status: http.StatusTooManyRequests,
+			header: map[string]string{"Retry-After": reset.Format(http.TimeFormat)},
+			check: func(t *testing.T, s RateLimitStatus) {
+				if !s.RetryAt.Equal(reset) {
+					t.Errorf("RetryAt = %v, want %v", s.RetryAt, reset)
+				}
+			},
+		},
+		{
+			name:   "429 without retry after",
+			status: http.StatusTooManyRequests,
+			check: func(t *testing.T, s RateLimitStatus) {
+				if until := time.Until(s.RetryAt); until <= 0 || until > defaultRetryAfter {
+					t.Errorf("retry in %v, want up to %v", until, defaultRetryAfter)
+				}
+			},
+		},
+		{
+			name: "no headers",
+			check: func(t *testing.T, s RateLimitStatus) {
+				if s.RemainingRequests != -1 || !s.RetryAt.IsZero() {
+					t.Errorf("expected an unknown limit, got %+v", s)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			status := tt.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			limiter := NewRateLimiter()
+			limiter.Observe(status, header)
+			tt.check(t, limiter.Status())
+		})
+	}
+}
+
+// eventRecorder collects the events a limiter emits
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []*types.AgentEvent
+}
+
+func (r *eventRecorder) emit(event *types.AgentEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) eventTypes() []types.AgentEventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var got []types.AgentEventType
+	for _, event := range r.events {
+		got = append(got, event.Type)
+	}
+	return got
+}
+
+// exhaust reports a request limit of limit with remaining left until reset
+func exhaust(limiter *RateLimiter, limit, remaining int, reset time.Duration) {
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", strconv.Itoa(limit))
+	header.Set("x-ratelimit-remaining-requests", strconv.Itoa(remaining))
+	header.Set("x-ratelimit-reset-requests", reset.String())
+	limiter.Observe(http.StatusOK, header)
+}
+
+func TestRateLimiter_ForegroundWaitsForReset(t *testing.T) {
+	limiter := NewRateLimiter()
+	recorder := &eventRecorder{}
+	limiter.SetEventEmitter(recorder.emit)
+	exhaust(limiter, 10, 0, 100*time.Millisecond)
+
+	start := time.Now()
+	release, err := limiter.Wait(context.Background(), PriorityForeground)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	release()
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected to wait for the reset, returned after %v", elapsed)
+	}
+	got := recorder.eventTypes()
+	if len(got) != 2 || got[0] != types.EventTypeRateLimitWait || got[1] != types.EventTypeRateLimitResume {
+		t.Fatalf("events = %v, want wait then resume", got)
+	}
+	wait := types.EventRateLimitWait.Payload(recorder.events[0])
+	if wait.Reason != types.RateLimitReasonRequests || wait.Background || wait.Wait <= 0 {
+		t.Errorf("unexpected wait payload: %+v", wait)
+	}
+}
+
+func TestRateLimiter_BackgroundKeepsReserve(t *testing.T) {
+	limiter := NewRateLimiter(WithBackgroundReserve(0.2))
+	exhaust(limiter, 10, 3, time.Hour)
+
+	// Background calls may use the limit down to the reserve of 2
+	release, err := limiter.Wait(context.Background(), PriorityBackground)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Wait(ctx, PriorityBackground); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a background call to wait at the reserve, got %v", err)
+	}
+
+	// Foreground calls use the reserve
+	for i := 0; i < 2; i++ {
+		release, err := limiter.Wait(context.Background(), PriorityForeground)
+		if err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		release()
+	}
+	if remaining := limiter.Status().RemainingRequests; remaining != 0 {
+		t.Errorf("remaining requests = %d, want 0", remaining)
+	}
+}
+
+func TestRateLimiter_MaxBackground(t *testing.T) {
+	limiter := NewRateLimiter(WithMaxBackground(1))
+
+	release, err := limiter.Wait(context.Background(), PriorityBackground)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Wait(ctx, PriorityBackground); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a second background call to wait, got %v", err)
+	}
+	if _, err := limiter.Wait(context.Background(), PriorityForeground); err != nil {
+		t.Fatalf("expected foreground calls not to wait for background slots, got %v", err)
+	}
+
+	release()
+	release() // Releasing twice is harmless
+	release, err = limiter.Wait(context.Background(), PriorityBackground)
+	if err != nil {
+		t.Fatalf("Wait failed after release: %v", err)
+	}
+	release()
+}
+
+func TestRateLimiter_BackgroundYieldsToWaitingForeground(t *testing.T) {
+	limiter := NewRateLimiter()
+	limiter.mu.Lock()
+	limiter.foregroundWaiting = 1
+	limiter.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		release, err := limiter.Wait(context.Background(), PriorityBackground)
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected the background call to wait, returned %v", err)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	limiter.mu.Lock()
+	limiter.foregroundWaiting = 0
+	limiter.broadcast()
+	limiter.mu.Unlock()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the background call to go once the foreground call went")
+	}
+}
+
+func TestRateLimiter_RetryAfterHoldsEveryone(t *testing.T) {
+	limiter := NewRateLimiter()
+	recorder := &eventRecorder{}
+	limiter.SetEventEmitter(recorder.emit)
+	header := http.Header{}
+	header.Set("retry-after-ms", "60000")
+	limiter.Observe(http.StatusTooManyRequests, header)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Wait(ctx, PriorityForeground); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the foreground call to wait, got %v", err)
+	}
+	if got := recorder.eventTypes(); len(got) != 1 || got[0] != types.EventTypeRateLimitWait {
+		t.Fatalf("events = %v, want one wait", got)
+	}
+	if reason := types.EventRateLimitWait.Payload(recorder.events[0]).Reason; reason != types.RateLimitReasonRetryAfter {
+		t.Errorf("reason = %q, want %q", reason, types.RateLimitReasonRetryAfter)
+	}
+	if limiter.foregroundWaiting != 0 {
+		t.Errorf("expected a canceled wait to stop counting as waiting, got %d", limiter.foregroundWaiting)
+	}
+}
@@ -5,6 +5,7 @@ package tokenizer
 
 import (
 	"fmt"
+	"hash/fnv"
 	"sync"
 
 	"github.com/entrhq/forge/pkg/types"
@@ -14,35 +15,70 @@ import (
 // Tokenizer provides token counting functionality
 type Tokenizer struct {
 	encoding *tiktoken.Tiktoken
-	mu       sync.Mutex
+	counts   *countCache
 }
 
 // defaultEncoding is the encoding used for most modern models (GPT-4, Claude, etc.)
 const defaultEncoding = "cl100k_base"
 
+// countCacheSize is how many texts' token counts each generation of the
+// cache holds. The whole history is counted every iteration, so it should
+// comfortably exceed the number of messages in a long conversation.
+const countCacheSize = 4096
+
+var (
+	// sharedEncoding is the encoder shared by every Tokenizer. Building one
+	// indexes the whole vocabulary, so it is done once per process; encoding
+	// only reads it, so it is safe for concurrent use.
+	sharedEncoding *tiktoken.Tiktoken
+	sharedMu       sync.Mutex
+)
+
+// loadEncoding returns the shared encoder, building it on first use. A
+// failed load is retried by the next call.
+func loadEncoding() (*tiktoken.Tiktoken, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if sharedEncoding == nil {
+		encoding, err := tiktoken.GetEncoding(defaultEncoding)
+		if err != nil {
+			return nil, err
+		}
+		sharedEncoding = encoding
+	}
+	return sharedEncoding, nil
+}
+
 // New creates a new Tokenizer instance
 func New() (*Tokenizer, error) {
-	encoding, err := tiktoken.GetEncoding(defaultEncoding)
+	encoding, err := loadEncoding()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tiktoken encoding: %w", err)
 	}
 
 	return &Tokenizer{
 		encoding: encoding,
+		counts:   newCountCache(countCacheSize),
 	}, nil
 }
 
-// CountTokens counts the number of tokens in the given text
+// CountTokens counts the number of tokens in the given text. Counts are
+// remembered by a hash of the text, so the history and system prompt,
+// which are counted again every iteration, are only encoded once.
 func (t *Tokenizer) CountTokens(text string) int {
 	if text == "" {
 		return 0
 	}
 
-	t.mu.Lock()
-	defer t.mu.Unlock()
+	key := hashText(text)
+	if count, ok := t.counts.get(key); ok {
+		return count
+	}
 
-	tokens := t.encoding.Encode(text, nil, nil)
-	return len(tokens)
+	count := len(t.encoding.Encode(text, nil, nil))
+	t.counts.store(key, count)
+	return count
 }
 
 // CountMessageTokens counts tokens for a message with role overhead
@@ -75,3 +111,62 @@ func (t *Tokenizer) CountMessagesTokens(messages []*types.Message) int {
 	}
 	return total
 }
+
+// hashText returns the key a text's count is cached under
+func hashText(text string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return h.Sum64()
+}
+
+// countCache remembers token counts by text hash. It keeps two generations:
+// when the current one fills up it becomes the previous one, and counts
+// found there are moved back into the current one. Texts still in use stay
+// cached while ones no longer counted drop out, without tracking recency
+// on every lookup.
+type countCache struct {
+	mu       sync.Mutex
+	current  map[uint64]int
+	previous map[uint64]int
+	size     int
+}
+
+// newCountCache creates a cache holding up to size counts per generation
+func newCountCache(size int) *countCache {
+	return &countCache{
+		current: make(map[uint64]int, size),
+		size:    size,
+	}
+}
+
+// get returns the cached count for key
+func (c *countCache) get(key uint64) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if count, ok := c.current[key]; ok {
+		return count, true
+	}
+	count, ok := c.previous[key]
+	if ok {
+		c.add(key, count)
+	}
+	return count, ok
+}
+
+// store caches the count for key
+func (c *countCache) store(key uint64, count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(key, count)
+}
+
+// add puts a count in the current generation, starting a new one if it is
+// full. Must be called with the lock held.
+func (c *countCache) add(key uint64, count int) {
+	if len(c.current) >= c.size {
+		c.previous = c.current
+		c.current = make(map[uint64]int, c.size)
+	}
+	c.current[key] = count
+}
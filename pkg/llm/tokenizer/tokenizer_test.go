@@ -0,0 +1,162 @@
+package tokenizer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+	tiktoken "github.com/pkoukk/tiktoken-go"
+)
+
+// testBpeLoader stands in for the cl100k vocabulary, which is downloaded on
+// first use: every byte, plus every pair of lowercase letters so that words
+// merge into fewer tokens as they would with the real one
+type testBpeLoader struct{}
+
+func (testBpeLoader) LoadTiktokenBpe(string) (map[string]int, error) {
+	ranks := make(map[string]int)
+	for b := 0; b < 256; b++ {
+		ranks[string([]byte{byte(b)})] = b
+	}
+	for a := 'a'; a <= 'z'; a++ {
+		for b := 'a'; b <= 'z'; b++ {
+			ranks[string([]rune{a, b})] = len(ranks)
+		}
+	}
+	return ranks, nil
+}
+
+func TestMain(m *testing.M) {
+	tiktoken.SetBpeLoader(testBpeLoader{})
+	os.Exit(m.Run())
+}
+
+func newTestTokenizer(t testing.TB) *Tokenizer {
+	t.Helper()
+	tok, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create tokenizer: %v", err)
+	}
+	return tok
+}
+
+func TestNew_SharesEncoder(t *testing.T) {
+	a, b := newTestTokenizer(t), newTestTokenizer(t)
+	if a.encoding != b.encoding {
+		t.Error("Expected tokenizers to share the encoder")
+	}
+	if a.counts == b.counts {
+		t.Error("Expected each tokenizer to have its own count cache")
+	}
+}
+
+func TestCountTokens_Cached(t *testing.T) {
+	tok := newTestTokenizer(t)
+	text := "the quick brown fox"
+
+	want := len(tok.encoding.Encode(text, nil, nil))
+	if got := tok.CountTokens(text); got != want {
+		t.Fatalf("CountTokens() = %d, want %d", got, want)
+	}
+	if count, ok := tok.counts.get(hashText(text)); !ok || count != want {
+		t.Errorf("Expected the count to be cached, got %d, %v", count, ok)
+	}
+	if got := tok.CountTokens(text); got != want {
+		t.Errorf("Cached CountTokens() = %d, want %d", got, want)
+	}
+	if tok.CountTokens("") != 0 {
+		t.Error("Expected no tokens for empty text")
+	}
+}
+
+func TestCountMessagesTokens(t *testing.T) {
+	tok := newTestTokenizer(t)
+	messages := []*types.Message{
+		types.NewUserMessage("hello there"),
+		types.NewAssistantMessage("hi"),
+	}
+
+	want := 3
+	for _, msg := range messages {
+		want += 3 + len(tok.encoding.Encode(string(msg.Role), nil, nil)) + len(tok.encoding.Encode(msg.Content, nil, nil))
+	}
+	if got := tok.CountMessagesTokens(messages); got != want {
+		t.Errorf("CountMessagesTokens() = %d, want %d", got, want)
+	}
+	if tok.CountMessagesTokens(nil) != 0 {
+		t.Error("Expected no tokens for no messages")
+	}
+}
+
+func TestCountCache_KeepsRecentlyUsed(t *testing.T) {
+	c := newCountCache(2)
+	c.store(1, 10)
+	c.store(2, 20)
+	c.store(3, 30) // Starts a new generation; 1 and 2 are previous
+
+	if count, ok := c.get(1); !ok || count != 10 {
+		t.Fatalf("Expected 1 from the previous generation, got %d, %v", count, ok)
+	}
+	c.store(4, 40) // 3 and 1 become previous; 2 is dropped
+
+	if _, ok := c.get(2); ok {
+		t.Error("Expected the unused count to be dropped")
+	}
+	for _, key := range []uint64{1, 3, 4} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("Expected %d to be kept", key)
+		}
+	}
+}
+
+// history returns n alternating user and assistant messages of a few
+// hundred words each
+func history(n int) []*types.Message {
+	paragraph := strings.Repeat("the agent read the file and proposed a change to the handler ", 40)
+	messages := make([]*types.Message, n)
+	for i := range messages {
+		content := fmt.Sprintf("message %d: %s", i, paragraph)
+		if i%2 == 0 {
+			messages[i] = types.NewUserMessage(content)
+		} else {
+			messages[i] = types.NewAssistantMessage(content)
+		}
+	}
+	return messages
+}
+
+// BenchmarkCountMessagesTokens counts a 200-message history as the agent
+// does every iteration: encoding every message, and with the counts of the
+// earlier messages cached and one new message to encode
+func BenchmarkCountMessagesTokens(b *testing.B) {
+	messages := history(200)
+
+	b.Run("uncached", func(b *testing.B) {
+		tok := newTestTokenizer(b)
+		for i := 0; i < b.N; i++ {
+			tok.counts = newCountCache(countCacheSize)
+			tok.CountMessagesTokens(messages)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		tok := newTestTokenizer(b)
+		tok.CountMessagesTokens(messages)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			messages[len(messages)-1] = types.NewUserMessage(fmt.Sprintf("new message %d", i))
+			tok.CountMessagesTokens(messages)
+		}
+	})
+}
+
+// BenchmarkNew creates a tokenizer, as the agent and context manager each do
+func BenchmarkNew(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := New(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
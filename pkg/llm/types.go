@@ -1,5 +1,27 @@
 package llm
 
+import (
+	"errors"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// ErrStreamStalled indicates the provider stopped sending data partway through
+// a streamed completion and the stream could not be resumed. The request can
+// be retried.
+var ErrStreamStalled = errors.New("stream stalled: no data received from provider")
+
+// IsRetriable reports whether err is a transient failure, such as a stalled
+// stream or a rate limit, after which the same request may succeed if sent
+// again.
+func IsRetriable(err error) bool {
+	if errors.Is(err, ErrStreamStalled) {
+		return true
+	}
+	agentErr, ok := types.IsAgentError(err)
+	return ok && agentErr.Retriable
+}
+
 // ContentType indicates the type of content in a StreamChunk.
 type ContentType string
 
@@ -24,6 +46,10 @@ type UsageInfo struct {
 	// TotalTokens is the total number of tokens used (prompt + completion).
 	// Some providers may calculate this differently (e.g., including system tokens).
 	TotalTokens int
+
+	// CachedPromptTokens is the number of prompt tokens served from the
+	// provider's prompt cache. Zero if the provider doesn't report it.
+	CachedPromptTokens int
 }
 
 // StreamChunk represents a single chunk from an LLM streaming response.
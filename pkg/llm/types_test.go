@@ -2,7 +2,10 @@ package llm
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
 )
 
 func TestStreamChunk_IsError(t *testing.T) {
@@ -152,3 +155,18 @@ func TestStreamChunk_MultipleFlags(t *testing.T) {
 		}
 	})
 }
+
+func TestIsRetriable(t *testing.T) {
+	if !IsRetriable(fmt.Errorf("%w for 1m0s", ErrStreamStalled)) {
+		t.Error("expected wrapped stall error to be retriable")
+	}
+	if !IsRetriable(types.WrapError(types.ErrorCodeRateLimited, errors.New("429")).WithRetriable(true)) {
+		t.Error("expected a retriable AgentError to be retriable")
+	}
+	if IsRetriable(errors.New("API request failed with status 401")) {
+		t.Error("expected other errors not to be retriable")
+	}
+	if IsRetriable(nil) {
+		t.Error("expected nil not to be retriable")
+	}
+}
@@ -0,0 +1,112 @@
+// Package injection provides defenses against prompt injection through
+// tool-sourced content. Files, command output and fetched web pages may be
+// written by an attacker, so the agent wraps them in untrusted-content markers
+// and scans them for embedded instructions before they reach the model.
+package injection
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	untrustedOpenTag  = "<untrusted_content"
+	untrustedCloseTag = "</untrusted_content>"
+
+	// maxExcerptLength bounds the excerpt reported for each finding
+	maxExcerptLength = 120
+)
+
+// Finding is a passage in tool output that looks like an instruction to the agent.
+type Finding struct {
+	Rule    string // Short description of the matched rule
+	Excerpt string // The offending line, trimmed
+}
+
+// rule is a named pattern that indicates an embedded instruction
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// rules are checked in order; each rule reports at most one finding
+var rules = []rule{
+	{
+		name:    "override previous instructions",
+		pattern: regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b[^\n.]{0,40}\b(previous|prior|above|earlier|all|your|system)\b[^\n.]{0,20}\b(instructions?|prompts?|rules|directions|guidelines)\b`),
+	},
+	{
+		name:    "role reassignment",
+		pattern: regexp.MustCompile(`(?i)\byou are now\b|\bfrom now on,? you (will|must|are|should)\b|\bnew instructions\s*:`),
+	},
+	{
+		name:    "spoofed system or assistant message",
+		pattern: regexp.MustCompile(`(?i)</?(system|assistant|system_prompt|custom_instructions|tool_use_rules|untrusted_content)>|(?m)^\s*(system|assistant)\s*:\s`),
+	},
+	{
+		name:    "embedded tool call",
+		pattern: regexp.MustCompile(`(?i)<tool>\s*<server_name>|<tool_name>[^<]+</tool_name>`),
+	},
+	{
+		name:    "message addressed to the AI",
+		pattern: regexp.MustCompile(`(?i)\b(note|message|instructions?)\s+(to|for)\s+(the\s+)?(ai|assistant|llm|agent|language model|coding agent)\b`),
+	},
+	{
+		name:    "concealment from the user",
+		pattern: regexp.MustCompile(`(?i)\b(do not|don't|never)\s+(tell|inform|mention|reveal|show)\b[^\n]{0,30}\bthe user\b`),
+	},
+	{
+		name:    "credential exfiltration",
+		pattern: regexp.MustCompile(`(?i)\b(send|post|upload|exfiltrate|curl)\b[^\n]{0,60}\b(api[_ ]?keys?|tokens?|secrets?|credentials?|passwords?|\.env|ssh keys?)\b`),
+	},
+}
+
+// Wrap delimits tool-sourced content as untrusted data. Any closing marker
+// inside the content is neutralized so it cannot end the block early.
+func Wrap(source, content string) string {
+	content = strings.ReplaceAll(content, untrustedCloseTag, `<\/untrusted_content>`)
+	return fmt.Sprintf("%s source=%q>\n%s\n%s", untrustedOpenTag, source, content, untrustedCloseTag)
+}
+
+// Detect scans content for text that attempts to instruct the agent.
+func Detect(content string) []Finding {
+	var findings []Finding
+	for _, r := range rules {
+		loc := r.pattern.FindStringIndex(content)
+		if loc == nil {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:    r.name,
+			Excerpt: excerpt(content, loc[0], loc[1]),
+		})
+	}
+	return findings
+}
+
+// excerpt returns the line(s) containing content[start:end], trimmed to a readable length
+func excerpt(content string, start, end int) string {
+	lineStart := strings.LastIndex(content[:start], "\n") + 1
+	lineEnd := strings.Index(content[end:], "\n")
+	if lineEnd < 0 {
+		lineEnd = len(content)
+	} else {
+		lineEnd += end
+	}
+
+	line := strings.TrimSpace(content[lineStart:lineEnd])
+	if len(line) > maxExcerptLength {
+		line = line[:maxExcerptLength] + "..."
+	}
+	return line
+}
+
+// Rules returns the names of the given findings' rules.
+func Rules(findings []Finding) []string {
+	names := make([]string, len(findings))
+	for i, f := range findings {
+		names[i] = f.Rule
+	}
+	return names
+}
@@ -8,8 +8,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/entrhq/forge/pkg/types"
 )
 
+// protectedPath is the workspace file no tool may read or write, relative to
+// the root: the audit log (audit.Path), which records the tools' own
+// actions. Logs set aside after failing verification share its name as a
+// prefix and are protected too.
+const protectedPath = ".forge/audit.log"
+
 // Guard enforces workspace boundary restrictions on file paths.
 // It validates that all file operations remain within the workspace directory,
 // preventing path traversal attacks and unauthorized file access.
@@ -56,8 +64,9 @@ func NewGuard(workspaceDir string) (*Guard, error) {
 // Returns an error if:
 // - The path is empty
 // - The path contains invalid characters or patterns
-// - The resolved path is outside the workspace
+// - The resolved path is outside the workspace (wrapping types.ErrPathOutsideWorkspace)
 // - The path attempts directory traversal
+// - The path is the audit log, which tools may not access
 func (g *Guard) ValidatePath(path string) error {
 	if path == "" {
 		return fmt.Errorf("path cannot be empty")
@@ -71,12 +80,26 @@ func (g *Guard) ValidatePath(path string) error {
 
 	// Check if resolved path is within workspace
 	if !g.IsWithinWorkspace(resolvedPath) {
-		return fmt.Errorf("path '%s' is outside workspace boundaries", path)
+		return fmt.Errorf("path '%s' is %w", path, types.ErrPathOutsideWorkspace)
+	}
+	if g.isProtected(resolvedPath) {
+		return fmt.Errorf("path '%s' is the audit log, which tools may not read or write", path)
 	}
 
 	return nil
 }
 
+// isProtected reports whether absPath is the audit log or a log set aside
+// from it
+func (g *Guard) isProtected(absPath string) bool {
+	relPath, err := g.MakeRelative(absPath)
+	if err != nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	return relPath == protectedPath || strings.HasPrefix(relPath, protectedPath+".")
+}
+
 // ResolvePath converts a relative or absolute path to an absolute path
 // within the workspace context. It cleans the path and resolves any
 // symbolic links.
@@ -158,10 +181,11 @@ func (g *Guard) WorkspaceDir() string {
 }
 
 // MakeRelative converts an absolute path to a path relative to the workspace.
-// Returns an error if the path is not within the workspace.
+// Returns an error wrapping types.ErrPathOutsideWorkspace if the path is not
+// within the workspace.
 func (g *Guard) MakeRelative(absPath string) (string, error) {
 	if !g.IsWithinWorkspace(absPath) {
-		return "", fmt.Errorf("path '%s' is not within workspace", absPath)
+		return "", fmt.Errorf("path '%s' is %w", absPath, types.ErrPathOutsideWorkspace)
 	}
 
 	relPath, err := filepath.Rel(g.workspaceDir, absPath)
@@ -172,6 +196,18 @@ func (g *Guard) MakeRelative(absPath string) (string, error) {
 	return relPath, nil
 }
 
+// AddIgnorePatterns ignores paths matching the given gitignore-style patterns
+// in addition to the defaults, .gitignore and .forgeignore.
+func (g *Guard) AddIgnorePatterns(patterns []string) {
+	g.ignoreMatcher.AddPatterns(patterns, "project")
+}
+
+// ContentLimits returns the limits on which files are searched, as set by
+// .forgeignore directives
+func (g *Guard) ContentLimits() ContentLimits {
+	return g.ignoreMatcher.ContentLimits()
+}
+
 // ShouldIgnore checks if a path should be ignored based on loaded ignore patterns.
 // The path can be either absolute or relative - it will be converted to relative for matching.
 // Returns true if the path matches any ignore pattern (considering precedence and negation).
@@ -196,6 +232,12 @@ func (g *Guard) ShouldIgnore(path string) bool {
 		absPath = filepath.Join(g.workspaceDir, path)
 	}
 
+	// The audit log is skipped when listing and searching directories, as
+	// tools may not read it
+	if g.isProtected(absPath) {
+		return true
+	}
+
 	isDir := false
 	if info, err := os.Lstat(absPath); err == nil {
 		isDir = info.IsDir()
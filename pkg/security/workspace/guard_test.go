@@ -1,9 +1,12 @@
 package workspace
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
 )
 
 func TestNewGuard(t *testing.T) {
@@ -122,6 +125,26 @@ func TestGuard_ValidatePath(t *testing.T) {
 			path:    "subdir/../../outside.txt",
 			wantErr: true,
 		},
+		{
+			name:    "audit log",
+			path:    ".forge/audit.log",
+			wantErr: true,
+		},
+		{
+			name:    "audit log through a traversal",
+			path:    "subdir/../.forge/./audit.log",
+			wantErr: true,
+		},
+		{
+			name:    "audit log set aside",
+			path:    ".forge/audit.log.20261015T080000Z.invalid",
+			wantErr: true,
+		},
+		{
+			name:    "other forge file",
+			path:    ".forge/config.yaml",
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -368,7 +391,20 @@ func TestGuard_SymlinkSecurity(t *testing.T) {
 
 	// Attempting to access through the symlink should fail
 	err = guard.ValidatePath("link-to-outside/file.txt")
-	if err == nil {
-		t.Error("ValidatePath() should reject symlink pointing outside workspace")
+	if !errors.Is(err, types.ErrPathOutsideWorkspace) {
+		t.Errorf("ValidatePath() error = %v, want ErrPathOutsideWorkspace", err)
+	}
+}
+
+func TestGuard_AuditLogIsIgnored(t *testing.T) {
+	guard, err := NewGuard(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	if !guard.ShouldIgnore(".forge/audit.log") || !guard.ShouldIgnore(filepath.Join(guard.WorkspaceDir(), ".forge", "audit.log")) {
+		t.Error("expected the audit log to be skipped when listing and searching")
+	}
+	if guard.ShouldIgnore(".forge/workflows/release.yaml") {
+		t.Error("expected other .forge files not to be ignored")
 	}
 }
@@ -41,13 +41,14 @@ type ignorePattern struct {
 	negation bool   // True if this is a negation pattern (starts with !)
 	dirOnly  bool   // True if pattern only matches directories (ends with /)
 	isGlob   bool   // True if pattern contains glob characters
-	source   string // Source of pattern: "default", "gitignore", "forgeignore"
+	source   string // Source of pattern: "default", "gitignore", "forgeignore", "project"
 }
 
 // IgnoreMatcher handles pattern matching for file ignore rules.
 // It supports layered patterns from multiple sources with defined precedence.
 type IgnoreMatcher struct {
 	patterns []ignorePattern
+	limits   ContentLimits // Set by directives in .forgeignore and the project config
 }
 
 // NewIgnoreMatcher creates a new ignore matcher and loads patterns from all sources.
@@ -55,9 +56,13 @@ type IgnoreMatcher struct {
 // 1. Default hardcoded patterns
 // 2. .gitignore patterns (if file exists)
 // 3. .forgeignore patterns (if file exists)
+//
+// Lines in .forgeignore starting with @ are directives that set the content
+// limits instead, such as "@max-size 2MB" or "@skip-minified off".
 func NewIgnoreMatcher(workspaceDir string) (*IgnoreMatcher, error) {
 	m := &IgnoreMatcher{
 		patterns: make([]ignorePattern, 0),
+		limits:   DefaultContentLimits(),
 	}
 
 	// Load default patterns
@@ -84,6 +89,31 @@ func NewIgnoreMatcher(workspaceDir string) (*IgnoreMatcher, error) {
 	return m, nil
 }
 
+// AddPatterns adds gitignore-style patterns from another source, such as the
+// ignore list of a project config. They take precedence over the patterns
+// loaded so far. Empty lines and comments are skipped, and directives are
+// applied as in .forgeignore.
+func (m *IgnoreMatcher) AddPatterns(patterns []string, source string) {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if strings.HasPrefix(pattern, "@") {
+			if err := m.limits.applyDirective(pattern); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s ignore list: %v\n", source, err)
+			}
+			continue
+		}
+		m.addPattern(pattern, source)
+	}
+}
+
+// ContentLimits returns the limits on which files are searched
+func (m *IgnoreMatcher) ContentLimits() ContentLimits {
+	return m.limits
+}
+
 // loadDefaultPatterns loads the hardcoded default ignore patterns.
 func (m *IgnoreMatcher) loadDefaultPatterns() {
 	for _, pattern := range defaultIgnorePatterns {
@@ -111,6 +141,14 @@ func (m *IgnoreMatcher) loadPatternsFromFile(path, source string) error {
 			continue
 		}
 
+		// Apply directives, which only .forgeignore has
+		if source == "forgeignore" && strings.HasPrefix(line, "@") {
+			if err := m.limits.applyDirective(line); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s:%d: %v\n", filepath.Base(path), lineNum, err)
+			}
+			continue
+		}
+
 		// Add pattern with source information
 		m.addPattern(line, source)
 	}
@@ -186,6 +186,35 @@ func TestPatternPrecedence(t *testing.T) {
 	}
 }
 
+func TestAddPatterns(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// .forgeignore ignores generated files
+	if err := os.WriteFile(filepath.Join(tempDir, ".forgeignore"), []byte("*.gen.go"), 0644); err != nil {
+		t.Fatalf("Failed to create .forgeignore: %v", err)
+	}
+
+	matcher, err := NewIgnoreMatcher(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	matcher.AddPatterns([]string{"fixtures/", "", "# comment", "!keep.gen.go"}, "project")
+
+	if !matcher.ShouldIgnore("fixtures/data.json", false) {
+		t.Error("Expected fixtures/data.json to be ignored by the added pattern")
+	}
+	if !matcher.ShouldIgnore("api.gen.go", false) {
+		t.Error("Expected api.gen.go to still be ignored by .forgeignore")
+	}
+	// Added patterns come last, so their negations win
+	if matcher.ShouldIgnore("keep.gen.go", false) {
+		t.Error("Expected keep.gen.go to not be ignored due to the added negation")
+	}
+	if matcher.ShouldIgnore("# comment", false) {
+		t.Error("Expected comment lines to be skipped")
+	}
+}
+
 func TestDirectoryOnlyPatterns(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -327,3 +356,67 @@ test_*.go
 		})
 	}
 }
+
+func TestContentLimitDirectives(t *testing.T) {
+	tempDir := t.TempDir()
+	forgeignore := "# limits\n@max-size 256KB\n@skip-minified off\n@bogus 1\n*.tmp\n"
+	if err := os.WriteFile(filepath.Join(tempDir, ".forgeignore"), []byte(forgeignore), 0644); err != nil {
+		t.Fatalf("Failed to write .forgeignore: %v", err)
+	}
+
+	matcher, err := NewIgnoreMatcher(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+
+	limits := matcher.ContentLimits()
+	if limits.MaxFileSize != 256<<10 || limits.SkipMinified {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+	if !matcher.ShouldIgnore("a.tmp", false) {
+		t.Error("Expected patterns after directives to still apply")
+	}
+
+	// The project config's ignore list takes directives too
+	matcher.AddPatterns([]string{"@max-size off"}, "project")
+	if matcher.ContentLimits().MaxFileSize != 0 {
+		t.Errorf("Expected no size limit, got %d", matcher.ContentLimits().MaxFileSize)
+	}
+}
+
+func TestContentLimitsDefault(t *testing.T) {
+	matcher, err := NewIgnoreMatcher(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create matcher: %v", err)
+	}
+	if matcher.ContentLimits() != DefaultContentLimits() {
+		t.Errorf("Expected the default limits, got %+v", matcher.ContentLimits())
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"800", 800, false},
+		{"512KB", 512 << 10, false},
+		{"1.5 mb", 3 << 19, false},
+		{"2GB", 2 << 30, false},
+		{"off", 0, false},
+		{"big", 0, true},
+		{"-1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
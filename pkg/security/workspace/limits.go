@@ -0,0 +1,89 @@
+package workspace
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxFileSize is the size above which files are not searched unless
+// a .forgeignore directive changes it
+const DefaultMaxFileSize = 1 << 20
+
+// ContentLimits decides which files the search tools skip for their content
+// rather than their path. Binary files are always skipped.
+type ContentLimits struct {
+	MaxFileSize  int64 // Bytes; 0 means no limit
+	SkipMinified bool  // Skip minified bundles and source maps
+}
+
+// DefaultContentLimits returns the limits used without directives
+func DefaultContentLimits() ContentLimits {
+	return ContentLimits{
+		MaxFileSize:  DefaultMaxFileSize,
+		SkipMinified: true,
+	}
+}
+
+// applyDirective changes the limits from a directive line, such as
+// "@max-size 512KB" or "@skip-minified off"
+func (l *ContentLimits) applyDirective(line string) error {
+	name, value, _ := strings.Cut(strings.TrimPrefix(line, "@"), " ")
+	value = strings.TrimSpace(value)
+
+	switch name {
+	case "max-size":
+		size, err := parseSize(value)
+		if err != nil {
+			return fmt.Errorf("invalid @max-size %q: %w", value, err)
+		}
+		l.MaxFileSize = size
+	case "skip-minified":
+		skip, err := parseSwitch(value)
+		if err != nil {
+			return fmt.Errorf("invalid @skip-minified %q: %w", value, err)
+		}
+		l.SkipMinified = skip
+	default:
+		return fmt.Errorf("unknown directive @%s", name)
+	}
+	return nil
+}
+
+// parseSize parses a size such as "800", "512KB" or "2MB", in binary units.
+// "off" means no limit.
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "OFF" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a size such as 512KB or 2MB, or off")
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// parseSwitch parses "on" or "off"
+func parseSwitch(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "true", "yes":
+		return true, nil
+	case "off", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected on or off")
+}
@@ -0,0 +1,168 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockFile is the workspace-relative file recording the session that is
+// editing the workspace
+const LockFile = ".forge/lock"
+
+// LockInfo identifies the session holding a workspace lock
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// String describes the session, e.g. "pid 4242 on laptop, started 14:03 (5m ago)"
+func (i LockInfo) String() string {
+	return fmt.Sprintf("pid %d on %s, started %s (%s ago)",
+		i.PID, i.Host, i.StartedAt.Local().Format("15:04"), time.Since(i.StartedAt).Round(time.Second))
+}
+
+// LockedError reports that another live session holds the workspace lock
+type LockedError struct {
+	Holder LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("workspace is in use by another Forge session (%s)", e.Holder)
+}
+
+// Lock is an advisory lock on a workspace, held for the length of a session.
+// It only keeps out other Forge sessions; nothing stops other programs from
+// editing the workspace.
+type Lock struct {
+	path string
+	info LockInfo
+
+	// Displaced is the live session whose lock was overridden by a forced
+	// AcquireLock, nil if there was none
+	Displaced *LockInfo
+}
+
+// AcquireLock locks workspaceDir for this process. If another live session
+// holds the lock it returns a *LockedError, unless force is set, in which
+// case the lock is taken over and that session is reported in
+// Lock.Displaced. Locks left behind by sessions that have exited are
+// replaced silently.
+func AcquireLock(workspaceDir string, force bool) (*Lock, error) {
+	path := filepath.Join(workspaceDir, LockFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown host"
+	}
+	lock := &Lock{path: path, info: LockInfo{PID: os.Getpid(), Host: host, StartedAt: time.Now()}}
+	data, err := json.MarshalIndent(lock.info, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode workspace lock: %w", err)
+	}
+
+	// One retry: a stale or overridden lock is removed and created again,
+	// which fails only if another session took it in between
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := createExclusive(path, append(data, '\n'))
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return lock, nil
+		}
+
+		holder, err := readLock(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue // Released while we looked
+		case err != nil:
+			// An unreadable lock can't name a live session, so treat it as stale
+		case holder.Host == host && !processAlive(holder.PID):
+			// The session exited without releasing the lock
+		case force:
+			lock.Displaced = &holder
+		default:
+			return nil, &LockedError{Holder: holder}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale workspace lock: %w", err)
+		}
+	}
+
+	holder, err := readLock(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire workspace lock: %w", err)
+	}
+	return nil, &LockedError{Holder: holder}
+}
+
+// Release removes the lock, unless another session has since taken it over
+func (l *Lock) Release() error {
+	holder, err := readLock(l.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if holder.PID != l.info.PID || holder.Host != l.info.Host {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove workspace lock: %w", err)
+	}
+	return nil
+}
+
+// createExclusive creates path with data, reporting false if it already
+// exists. The data is written to a temporary file that is then linked into
+// place, so other sessions never see the lock empty or half written.
+func createExclusive(path string, data []byte) (bool, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return false, fmt.Errorf("failed to create workspace lock: %w", err)
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return false, fmt.Errorf("failed to write workspace lock: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return false, fmt.Errorf("failed to write workspace lock: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write workspace lock: %w", err)
+	}
+
+	if err := os.Link(tmpPath, path); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create workspace lock: %w", err)
+	}
+	return true, nil
+}
+
+// readLock reads the session recorded in a lock file
+func readLock(path string) (LockInfo, error) {
+	var info LockInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("invalid workspace lock %s: %w", path, err)
+	}
+	return info, nil
+}
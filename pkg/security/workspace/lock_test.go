@@ -0,0 +1,146 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeLock records a session in the workspace's lock file
+func writeLock(t *testing.T, dir string, info LockInfo) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".forge"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, LockFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := AcquireLock(dir, false)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	if lock.Displaced != nil {
+		t.Errorf("expected no displaced session, got %v", lock.Displaced)
+	}
+
+	// The same process counts as a live session
+	var locked *LockedError
+	if _, err := AcquireLock(dir, false); !errors.As(err, &locked) {
+		t.Fatalf("expected a LockedError, got %v", err)
+	}
+	if locked.Holder.PID != os.Getpid() {
+		t.Errorf("holder PID = %d, want %d", locked.Holder.PID, os.Getpid())
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LockFile)); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file to be removed, got %v", err)
+	}
+}
+
+func TestAcquireLock_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+
+	// Every attempt is from this live process, so exactly one may succeed;
+	// the others must never find the lock mid-write and take it as stale
+	const sessions = 16
+	var wg sync.WaitGroup
+	errs := make([]error, sessions)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = AcquireLock(dir, false)
+		}(i)
+	}
+	wg.Wait()
+
+	acquired := 0
+	for _, err := range errs {
+		var locked *LockedError
+		switch {
+		case err == nil:
+			acquired++
+		case !errors.As(err, &locked):
+			t.Errorf("expected a LockedError, got %v", err)
+		}
+	}
+	if acquired != 1 {
+		t.Errorf("expected exactly one session to acquire the lock, got %d", acquired)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, ".forge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "lock" {
+		t.Errorf("expected only the lock file to remain, got %v", entries)
+	}
+}
+
+func TestAcquireLock_Force(t *testing.T) {
+	dir := t.TempDir()
+	other := LockInfo{PID: os.Getpid(), Host: "elsewhere", StartedAt: time.Now().Add(-time.Hour)}
+	writeLock(t, dir, other)
+
+	if _, err := AcquireLock(dir, false); err == nil {
+		t.Fatal("expected a session on another host to hold the lock")
+	}
+
+	lock, err := AcquireLock(dir, true)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	if lock.Displaced == nil || lock.Displaced.Host != "elsewhere" {
+		t.Errorf("expected the other session to be displaced, got %v", lock.Displaced)
+	}
+}
+
+func TestAcquireLock_Stale(t *testing.T) {
+	dir := t.TempDir()
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+	// PIDs are well below this on every supported platform
+	writeLock(t, dir, LockInfo{PID: 1 << 30, Host: host, StartedAt: time.Now()})
+
+	lock, err := AcquireLock(dir, false)
+	if err != nil {
+		t.Fatalf("expected a stale lock to be replaced, got %v", err)
+	}
+	if lock.Displaced != nil {
+		t.Errorf("a stale lock should not be reported as displaced, got %v", lock.Displaced)
+	}
+}
+
+func TestLock_ReleaseKeepsTakenOverLock(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := AcquireLock(dir, false)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+
+	writeLock(t, dir, LockInfo{PID: 1, Host: "elsewhere", StartedAt: time.Now()})
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LockFile)); err != nil {
+		t.Errorf("expected the other session's lock to remain, got %v", err)
+	}
+}
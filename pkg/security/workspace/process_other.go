@@ -0,0 +1,20 @@
+//go:build !unix
+
+package workspace
+
+import "os"
+
+// processAlive reports whether a process with the given ID is running. On
+// Windows, finding a process opens a handle to it, which fails once it has
+// exited.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,115 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TrustDecision is the user's answer to whether a workspace may be edited
+// and run commands in
+type TrustDecision struct {
+	Trusted   bool      `json:"trusted"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// TrustStore records trust decisions by directory. A decision applies to
+// the directory and everything below it, unless a subdirectory has its own.
+type TrustStore struct {
+	path       string
+	workspaces map[string]TrustDecision
+}
+
+// DefaultTrustStorePath returns ~/.forge/trust.json
+func DefaultTrustStorePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".forge", "trust.json"), nil
+}
+
+// LoadTrustStore reads the trust store at path. A missing file is an empty
+// store.
+func LoadTrustStore(path string) (*TrustStore, error) {
+	s := &TrustStore{path: path, workspaces: make(map[string]TrustDecision)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Lookup returns the decision for dir, found on dir itself or its nearest
+// ancestor with one, and whether there was any
+func (s *TrustStore) Lookup(dir string) (TrustDecision, bool) {
+	path, err := canonicalDir(dir)
+	if err != nil {
+		return TrustDecision{}, false
+	}
+	for {
+		if decision, ok := s.workspaces[path]; ok {
+			return decision, true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return TrustDecision{}, false
+		}
+		path = parent
+	}
+}
+
+// Set records the decision for dir and saves the store
+func (s *TrustStore) Set(dir string, trusted bool) error {
+	path, err := canonicalDir(dir)
+	if err != nil {
+		return err
+	}
+	s.workspaces[path] = TrustDecision{Trusted: trusted, DecidedAt: time.Now().UTC()}
+	return s.save()
+}
+
+// save writes the store atomically
+func (s *TrustStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(s.path), err)
+	}
+	data, err := json.MarshalIndent(s.workspaces, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trust store: %w", err)
+	}
+
+	tempPath := s.path + ".tmp"
+	if err := os.WriteFile(tempPath, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write trust store: %w", err)
+	}
+	if err := os.Rename(tempPath, s.path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to save trust store: %w", err)
+	}
+	return nil
+}
+
+// canonicalDir returns dir as an absolute path with symlinks resolved, so a
+// workspace is trusted however it is reached
+func canonicalDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,62 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrustStore(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "trust.json")
+	root := t.TempDir()
+	child := filepath.Join(root, "vendor", "lib")
+	if err := os.MkdirAll(child, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := LoadTrustStore(storePath)
+	if err != nil {
+		t.Fatalf("LoadTrustStore failed: %v", err)
+	}
+	if _, ok := store.Lookup(root); ok {
+		t.Fatal("expected no decision in an empty store")
+	}
+
+	if err := store.Set(root, true); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set(filepath.Join(root, "vendor"), false); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	// Decisions persist and apply to subdirectories, the nearest one winning
+	store, err = LoadTrustStore(storePath)
+	if err != nil {
+		t.Fatalf("LoadTrustStore failed: %v", err)
+	}
+	if decision, ok := store.Lookup(root); !ok || !decision.Trusted {
+		t.Errorf("expected root to be trusted, got %+v, %v", decision, ok)
+	}
+	if decision, ok := store.Lookup(child); !ok || decision.Trusted {
+		t.Errorf("expected vendor/lib to be untrusted, got %+v, %v", decision, ok)
+	}
+}
+
+func TestTrustStore_Symlink(t *testing.T) {
+	store, err := LoadTrustStore(filepath.Join(t.TempDir(), "trust.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if err := store.Set(link, true); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if decision, ok := store.Lookup(dir); !ok || !decision.Trusted {
+		t.Errorf("expected the symlink target to be trusted, got %+v, %v", decision, ok)
+	}
+}
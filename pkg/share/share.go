@@ -0,0 +1,237 @@
+// Package share serves a read-only live view of a Forge session, so a
+// teammate can watch an agent work and advise without screen sharing.
+//
+// The session is read from the event log a session writes with -record, as
+// it grows. The server renders it as a plain HTML page that follows the log
+// over server-sent events:
+//
+//	GET /?token=T         the page
+//	GET /events?token=T   the log, one record per event
+//
+// Each event's data is one record of the log, its ID the record's line
+// number, so a browser that reconnects resumes where it stopped. An event
+// of type reset tells the page to start over: the log was truncated, as
+// when a new session records to the same file.
+//
+// Every request must carry the token the server was created with. Nothing
+// served can change the session: there are no inputs and no approvals.
+package share
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is how often the log is checked for new records
+	DefaultPollInterval = 250 * time.Millisecond
+
+	// keepAlive is how long a stream may stay idle before a comment is
+	// sent, so proxies and tunnels don't close it
+	keepAlive = 15 * time.Second
+)
+
+//go:embed page.html
+var page []byte
+
+// Server serves the live view of one event log. It is an http.Handler.
+type Server struct {
+	path     string
+	token    string
+	interval time.Duration
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithToken sets the access token. The default is a random one.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithPollInterval sets how often the log is checked for new records
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.interval = interval
+	}
+}
+
+// New returns a server for the event log at path, which must exist
+func New(path string, opts ...Option) (*Server, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	s := &Server{path: path, interval: DefaultPollInterval}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.token == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate access token: %w", err)
+		}
+		s.token = hex.EncodeToString(buf)
+	}
+	return s, nil
+}
+
+// Token returns the token requests must carry
+func (s *Server) Token() string {
+	return s.token
+}
+
+// URL returns the page's URL on the server at base, e.g.
+// "http://127.0.0.1:7433"
+func (s *Server) URL(base string) string {
+	return base + "/?token=" + s.token
+}
+
+// ServeHTTP serves the page and its event stream
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "the session view is read-only", http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(s.token)) != 1 {
+		http.Error(w, "missing or invalid token", http.StatusForbidden)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer") // The URL holds the token
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'")
+		_, _ = w.Write(page)
+	case "/events":
+		s.serveEvents(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveEvents streams the log's records from the one after Last-Event-ID
+// until the client goes away
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	skip, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no") // Don't let a reverse proxy hold events back
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	last := time.Now()
+	send := func(format string, args ...interface{}) error {
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		flusher.Flush()
+		last = time.Now()
+		return nil
+	}
+	idle := func() error {
+		if time.Since(last) < keepAlive {
+			return nil
+		}
+		return send(": keep-alive\n\n")
+	}
+	record := func(id int, line []byte) error {
+		return send("id: %d\ndata: %s\n\n", id, line)
+	}
+	reset := func() error {
+		return send("id: 0\nevent: reset\ndata: {}\n\n")
+	}
+
+	err := follow(r.Context(), s.path, s.interval, skip, record, reset, idle)
+	if err != nil && r.Context().Err() == nil {
+		message, _ := json.Marshal(err.Error())
+		_ = send("event: failure\ndata: %s\n\n", message)
+	}
+}
+
+// follow reads the log at path like tail -f, calling record with each
+// complete line after the first skip and its line number, reset when the
+// log is truncated, and idle each time it waits for more. It returns when
+// ctx is done or a callback fails.
+func follow(ctx context.Context, path string, interval time.Duration, skip int, record func(int, []byte) error, reset, idle func() error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var offset int64   // Bytes of the log consumed, partial line included
+	var partial []byte // A line still being written
+	line := 0
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		offset += int64(len(chunk))
+		if err == nil {
+			data := bytes.TrimSpace(append(partial, chunk...))
+			partial = nil
+			if len(data) == 0 {
+				continue
+			}
+			line++
+			if line > skip {
+				if err := record(line, data); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err != io.EOF {
+			return fmt.Errorf("failed to read event log: %w", err)
+		}
+		partial = append(partial, chunk...)
+
+		if err := idle(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if info, err := f.Stat(); err == nil && info.Size() < offset {
+			// A new recording replaced the log: start over
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to read event log: %w", err)
+			}
+			reader.Reset(f)
+			offset, partial, line, skip = 0, nil, 0, 0
+			if err := reset(); err != nil {
+				return err
+			}
+		}
+	}
+}
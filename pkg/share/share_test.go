@@ -0,0 +1,174 @@
+package share
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer serves a log holding content and returns the log's path
+func newTestServer(t *testing.T, content string) (*httptest.Server, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(path, WithToken("secret"), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return server, path
+}
+
+// openStream opens the event stream and returns a function reading its
+// next event, without comments, as its lines joined by newlines
+func openStream(t *testing.T, url, lastID string) func() string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/events?token=secret", nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %s %s", resp.Status, resp.Header.Get("Content-Type"))
+	}
+
+	events := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var lines []string
+		for scanner.Scan() {
+			switch line := scanner.Text(); {
+			case line == "" && len(lines) > 0:
+				events <- strings.Join(lines, "\n")
+				lines = nil
+			case line != "" && !strings.HasPrefix(line, ":"):
+				lines = append(lines, line)
+			}
+		}
+		close(events)
+	}()
+	return func() string {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return ""
+		}
+	}
+}
+
+func appendLog(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	server, _ := newTestServer(t, "")
+
+	for _, path := range []string{"/", "/events", "/?token=wrong"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected %s to be forbidden, got %s", path, resp.Status)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/?token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("expected the page, got %s %s", resp.Status, resp.Header.Get("Content-Type"))
+	}
+
+	resp, err = http.Post(server.URL+"/events?token=secret", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected the view to be read-only, got %s", resp.Status)
+	}
+}
+
+func TestServerStreamsLogAsItGrows(t *testing.T) {
+	server, path := newTestServer(t, "{\"input\":\"List the files\"}\n\n{\"event\":{\"type\":\"turn_end\"}}\n")
+	next := openStream(t, server.URL, "")
+
+	if got := next(); got != "id: 1\ndata: {\"input\":\"List the files\"}" {
+		t.Errorf("expected the first record, got %q", got)
+	}
+	if got := next(); got != "id: 2\ndata: {\"event\":{\"type\":\"turn_end\"}}" {
+		t.Errorf("expected blank lines to be skipped, got %q", got)
+	}
+
+	// A record is sent once the line is complete
+	appendLog(t, path, "{\"input\":")
+	time.Sleep(50 * time.Millisecond)
+	appendLog(t, path, "\"Again\"}\n")
+	if got := next(); got != "id: 3\ndata: {\"input\":\"Again\"}" {
+		t.Errorf("expected the appended record, got %q", got)
+	}
+
+	// A new recording to the same file starts the view over
+	if err := os.WriteFile(path, []byte("{\"input\":\"New session\"}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(); got != "id: 0\nevent: reset\ndata: {}" {
+		t.Errorf("expected a reset, got %q", got)
+	}
+	if got := next(); got != "id: 1\ndata: {\"input\":\"New session\"}" {
+		t.Errorf("expected the new recording, got %q", got)
+	}
+}
+
+func TestServerResumesAfterLastEventID(t *testing.T) {
+	server, _ := newTestServer(t, "{\"input\":\"one\"}\n{\"input\":\"two\"}\n{\"input\":\"three\"}\n")
+	next := openStream(t, server.URL, "2")
+	if got := next(); got != "id: 3\ndata: {\"input\":\"three\"}" {
+		t.Errorf("expected to resume after the last record seen, got %q", got)
+	}
+}
+
+func TestNewRequiresLog(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("expected a missing log to be rejected")
+	}
+	s, err := New(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Token()) != 32 {
+		t.Errorf("expected a random token, got %q", s.Token())
+	}
+	if got := s.URL("http://127.0.0.1:7433"); got != "http://127.0.0.1:7433/?token="+s.Token() {
+		t.Errorf("unexpected URL %q", got)
+	}
+}
@@ -0,0 +1,185 @@
+// Package ci provides the fetch_ci_logs tool, which finds the latest failing
+// CI run for a branch and reduces its logs to the failing steps and error
+// lines, so "fix the CI failure" tasks don't start with pasted logs.
+//
+// GitHub Actions is the only supported CI provider.
+package ci
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+)
+
+const (
+	// maxFailedJobs caps how many failed jobs have their logs downloaded
+	maxFailedJobs = 5
+
+	// maxErrorLines caps the error lines kept per job
+	maxErrorLines = 60
+
+	// contextLines is how many lines around an error line are kept
+	contextLines = 2
+
+	// tailLines is how many final log lines are kept when no error line is found
+	tailLines = 30
+)
+
+// Run is a failed CI workflow run
+type Run struct {
+	ID        int64
+	Workflow  string
+	Number    int
+	Branch    string
+	CommitSHA string
+	Event     string
+	URL       string
+	CreatedAt time.Time
+	Jobs      []Job // Failed jobs
+}
+
+// Job is a failed job of a run
+type Job struct {
+	Name        string
+	URL         string
+	Conclusion  string
+	FailedSteps []string
+	Errors      []string // Error lines with context, from the job log
+	LogError    string   // Why the log could not be read, if it couldn't
+}
+
+// Provider fetches failed runs from a CI service
+type Provider interface {
+	// LatestFailure returns the most recent failed run on branch, with its
+	// failed jobs and their error lines, or nil if no run failed
+	LatestFailure(ctx context.Context, branch string) (*Run, error)
+}
+
+var (
+	// logTimestamp matches the timestamp GitHub Actions prefixes log lines with
+	logTimestamp = regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(?:\.\d+)?Z ?`)
+
+	// ansiEscape matches terminal color codes
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+	// errorLine matches log lines that report a failure
+	errorLine = regexp.MustCompile(`(?i)##\[error\]|\berror\b|\bfailed\b|\bfailure\b|^--- FAIL|^FAIL\b|\bpanic:|\bfatal\b|\bexception\b|\btraceback\b|✗|✖`)
+
+	// noiseLine matches lines that mention errors without reporting one
+	noiseLine = regexp.MustCompile(`(?i)^##\[(?:group|endgroup)\]|\b0 (?:errors?|failures?|failed)\b|--fail|continue-on-error|error_?log`)
+)
+
+// ExtractErrors reduces a job log to its error lines, each with a little
+// surrounding context. Groups of lines are separated by "...". When no line
+// looks like an error, the end of the log is returned instead.
+func ExtractErrors(log string) []string {
+	lines := cleanLog(log)
+
+	keep := make([]bool, len(lines))
+	found := false
+	for i, line := range lines {
+		if !errorLine.MatchString(line) || noiseLine.MatchString(line) {
+			continue
+		}
+		found = true
+		for j := max(0, i-contextLines); j <= min(len(lines)-1, i+contextLines); j++ {
+			keep[j] = true
+		}
+	}
+	if !found {
+		return lines[max(0, len(lines)-tailLines):]
+	}
+
+	var excerpt []string
+	for i, line := range lines {
+		if !keep[i] {
+			continue
+		}
+		if i > 0 && !keep[i-1] && len(excerpt) > 0 {
+			excerpt = append(excerpt, "...")
+		}
+		if len(excerpt) >= maxErrorLines {
+			excerpt = append(excerpt, fmt.Sprintf("... (error lines truncated at %d)", maxErrorLines))
+			break
+		}
+		excerpt = append(excerpt, line)
+	}
+	return excerpt
+}
+
+// cleanLog splits a log into lines without timestamps, color codes,
+// trailing whitespace or blank lines
+func cleanLog(log string) []string {
+	var lines []string
+	for _, line := range strings.Split(log, "\n") {
+		line = logTimestamp.ReplaceAllString(line, "")
+		line = ansiEscape.ReplaceAllString(line, "")
+		line = strings.TrimRight(line, " \t\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// FormatRun renders a failed run for the model. head is the local HEAD
+// commit, used to point out when the run tested an older commit.
+func FormatRun(run *Run, head string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CI run %q #%d failed on branch %s (commit %s, %s, %s)\n",
+		run.Workflow, run.Number, run.Branch, shortSHA(run.CommitSHA), run.Event, run.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "URL: %s\n", run.URL)
+	if head != "" && run.CommitSHA != "" && !strings.HasPrefix(run.CommitSHA, head) && !strings.HasPrefix(head, run.CommitSHA) {
+		fmt.Fprintf(&b, "Note: local HEAD is %s; the failure may already be fixed or differ locally.\n", shortSHA(head))
+	}
+
+	if len(run.Jobs) == 0 {
+		b.WriteString("\nNo failed jobs were reported (the run may have been canceled or failed to start).")
+		return b.String()
+	}
+
+	for _, job := range run.Jobs {
+		fmt.Fprintf(&b, "\nJob %q: %s\n", job.Name, job.Conclusion)
+		if len(job.FailedSteps) > 0 {
+			fmt.Fprintf(&b, "Failed steps: %s\n", strings.Join(job.FailedSteps, ", "))
+		}
+		if job.URL != "" {
+			fmt.Fprintf(&b, "URL: %s\n", job.URL)
+		}
+		switch {
+		case job.LogError != "":
+			fmt.Fprintf(&b, "Log unavailable: %s\n", job.LogError)
+		case len(job.Errors) > 0:
+			b.WriteString("Errors:\n")
+			for _, line := range job.Errors {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// shortSHA abbreviates a commit hash
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// CurrentBranch returns the branch checked out in workspaceDir
+func CurrentBranch(ctx context.Context, workspaceDir string) (string, error) {
+	branch, err := git.Output(ctx, workspaceDir, nil, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "HEAD" {
+		return "", fmt.Errorf("HEAD is detached; pass a branch name")
+	}
+	return branch, nil
+}
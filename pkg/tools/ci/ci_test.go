@@ -0,0 +1,171 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/tools/github"
+)
+
+const testLog = "2024-05-01T10:00:00.0000000Z ##[group]Run go test ./...\n" +
+	"2024-05-01T10:00:01.0000000Z go: downloading example.com/mod v1.0.0\n" +
+	"2024-05-01T10:00:02.0000000Z ok  \texample.com/app/a\t0.1s\n" +
+	"2024-05-01T10:00:03.0000000Z === RUN   TestParse\n" +
+	"2024-05-01T10:00:03.1000000Z     parse_test.go:12: expected 3, got 4\n" +
+	"2024-05-01T10:00:03.2000000Z --- FAIL: TestParse (0.00s)\n" +
+	"2024-05-01T10:00:03.3000000Z \x1b[31mFAIL\x1b[0m\texample.com/app/b\t0.2s\n" +
+	"2024-05-01T10:00:04.0000000Z line 8\n" +
+	"2024-05-01T10:00:05.0000000Z line 9\n" +
+	"2024-05-01T10:00:06.0000000Z line 10\n" +
+	"2024-05-01T10:00:07.0000000Z line 11\n" +
+	"2024-05-01T10:00:07.5000000Z line 12\n" +
+	"2024-05-01T10:00:08.0000000Z ##[error]Process completed with exit code 1.\n"
+
+func TestExtractErrors(t *testing.T) {
+	got := strings.Join(ExtractErrors(testLog), "\n")
+	want := "=== RUN   TestParse\n" +
+		"    parse_test.go:12: expected 3, got 4\n" +
+		"--- FAIL: TestParse (0.00s)\n" +
+		"FAIL\texample.com/app/b\t0.2s\n" +
+		"line 8\n" +
+		"line 9\n" +
+		"...\n" +
+		"line 11\n" +
+		"line 12\n" +
+		"##[error]Process completed with exit code 1."
+	if got != want {
+		t.Errorf("ExtractErrors() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExtractErrors_NoErrorLines(t *testing.T) {
+	var log strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&log, "step output %d\n", i)
+	}
+
+	got := ExtractErrors(log.String())
+	if len(got) != tailLines || got[len(got)-1] != "step output 50" {
+		t.Errorf("expected the last %d lines, got %d ending %q", tailLines, len(got), got[len(got)-1])
+	}
+}
+
+func TestExtractErrors_Truncates(t *testing.T) {
+	var log strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&log, "error %d\n", i)
+	}
+
+	got := ExtractErrors(log.String())
+	if len(got) != maxErrorLines+1 || !strings.Contains(got[len(got)-1], "truncated") {
+		t.Errorf("expected %d lines and a truncation note, got %d", maxErrorLines, len(got))
+	}
+}
+
+// newActions serves a repository with one failed run whose "test" job failed
+func newActions(t *testing.T, logStatus int) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/acme/app/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("branch") != "feature" || r.URL.Query().Get("status") != "failure" {
+			_, _ = w.Write([]byte(`{"workflow_runs": []}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"workflow_runs": [{"id": 9, "name": "CI", "run_number": 31, "head_branch": "feature",
+			"head_sha": "abcdef1234567890", "event": "push", "html_url": "https://github.com/acme/app/actions/runs/9",
+			"created_at": "2024-05-01T10:00:00Z"}]}`))
+	})
+	mux.HandleFunc("/repos/acme/app/actions/runs/9/jobs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jobs": [
+			{"id": 1, "name": "lint", "conclusion": "success", "steps": []},
+			{"id": 2, "name": "test", "conclusion": "failure", "html_url": "https://github.com/acme/app/actions/runs/9/job/2",
+			 "steps": [{"name": "Checkout", "conclusion": "success"}, {"name": "Run tests", "conclusion": "failure"}]}
+		]}`))
+	})
+	mux.HandleFunc("/repos/acme/app/actions/jobs/2/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(logStatus)
+		_, _ = w.Write([]byte(testLog))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitHubActions_LatestFailure(t *testing.T) {
+	server := newActions(t, http.StatusOK)
+	provider := NewGitHubActions("acme/app", github.NewClient(github.WithAPI(server.URL), github.WithToken("secret")))
+
+	run, err := provider.LatestFailure(context.Background(), "feature")
+	if err != nil {
+		t.Fatalf("LatestFailure failed: %v", err)
+	}
+	if run.Workflow != "CI" || run.Number != 31 || len(run.Jobs) != 1 {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+
+	job := run.Jobs[0]
+	if job.Name != "test" || len(job.FailedSteps) != 1 || job.FailedSteps[0] != "Run tests" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if len(job.Errors) == 0 || job.LogError != "" {
+		t.Errorf("expected error lines from the log, got %v (%s)", job.Errors, job.LogError)
+	}
+}
+
+func TestGitHubActions_NoFailure(t *testing.T) {
+	server := newActions(t, http.StatusOK)
+	provider := NewGitHubActions("acme/app", github.NewClient(github.WithAPI(server.URL)))
+
+	run, err := provider.LatestFailure(context.Background(), "main")
+	if err != nil || run != nil {
+		t.Errorf("expected no run, got %+v, %v", run, err)
+	}
+}
+
+func TestGitHubActions_LogUnavailable(t *testing.T) {
+	server := newActions(t, http.StatusForbidden)
+	provider := NewGitHubActions("acme/app", github.NewClient(github.WithAPI(server.URL)))
+
+	run, err := provider.LatestFailure(context.Background(), "feature")
+	if err != nil {
+		t.Fatalf("LatestFailure failed: %v", err)
+	}
+	if !strings.Contains(run.Jobs[0].LogError, "requires a GitHub token") {
+		t.Errorf("expected a token hint, got %q", run.Jobs[0].LogError)
+	}
+}
+
+func TestFetchCILogsTool(t *testing.T) {
+	server := newActions(t, http.StatusOK)
+	tool := NewFetchCILogsTool(NewGitHubActions("acme/app", github.NewClient(github.WithAPI(server.URL))), t.TempDir())
+
+	result, err := tool.Execute(context.Background(), []byte("<arguments><branch>feature</branch></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	for _, want := range []string{`CI run "CI" #31 failed on branch feature (commit abcdef1`, `Job "test": failure`, "Failed steps: Run tests", "--- FAIL: TestParse"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+
+	result, err = tool.Execute(context.Background(), []byte("<arguments><branch>main</branch></arguments>"))
+	if err != nil || result != "No failed CI runs found for branch main" {
+		t.Errorf("unexpected result %q, %v", result, err)
+	}
+}
+
+func TestFormatRun_StaleCommit(t *testing.T) {
+	run := &Run{Workflow: "CI", Number: 1, Branch: "main", CommitSHA: "abcdef1234"}
+
+	if got := FormatRun(run, "1234567890"); !strings.Contains(got, "local HEAD is 1234567") {
+		t.Errorf("expected a stale commit note, got:\n%s", got)
+	}
+	if got := FormatRun(run, "abcdef1234"); strings.Contains(got, "local HEAD") {
+		t.Errorf("expected no note for the same commit, got:\n%s", got)
+	}
+}
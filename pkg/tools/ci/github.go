@@ -0,0 +1,143 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/entrhq/forge/pkg/tools/github"
+)
+
+// maxLogBytes caps how much of a job log is downloaded
+const maxLogBytes = 16 << 20
+
+// GitHubActions reads workflow runs from GitHub Actions
+type GitHubActions struct {
+	repo   string // owner/name
+	client *github.Client
+}
+
+// NewGitHubActions creates a provider for the workflow runs of repo
+// ("owner/name"). Downloading logs requires a client with a token, even
+// for public repositories; a nil client is an unauthenticated one.
+func NewGitHubActions(repo string, client *github.Client) *GitHubActions {
+	if client == nil {
+		client = github.NewClient()
+	}
+	return &GitHubActions{repo: repo, client: client}
+}
+
+// githubRun is a workflow run as returned by the GitHub API
+type githubRun struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	RunNumber  int       `json:"run_number"`
+	HeadBranch string    `json:"head_branch"`
+	HeadSHA    string    `json:"head_sha"`
+	Event      string    `json:"event"`
+	HTMLURL    string    `json:"html_url"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// githubJob is a workflow job as returned by the GitHub API
+type githubJob struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+	Steps      []struct {
+		Name       string `json:"name"`
+		Conclusion string `json:"conclusion"`
+	} `json:"steps"`
+}
+
+// failed reports whether a job or step conclusion is a failure
+func failed(conclusion string) bool {
+	switch conclusion {
+	case "failure", "timed_out", "startup_failure":
+		return true
+	}
+	return false
+}
+
+// LatestFailure returns the most recent failed workflow run on branch.
+func (g *GitHubActions) LatestFailure(ctx context.Context, branch string) (*Run, error) {
+	params := url.Values{}
+	params.Set("branch", branch)
+	params.Set("status", "failure")
+	params.Set("per_page", "1")
+
+	var runs struct {
+		WorkflowRuns []githubRun `json:"workflow_runs"`
+	}
+	if err := g.client.GetJSON(ctx, fmt.Sprintf("/repos/%s/actions/runs?%s", g.repo, params.Encode()), &runs); err != nil {
+		return nil, err
+	}
+	if len(runs.WorkflowRuns) == 0 {
+		return nil, nil
+	}
+
+	r := runs.WorkflowRuns[0]
+	run := &Run{
+		ID:        r.ID,
+		Workflow:  r.Name,
+		Number:    r.RunNumber,
+		Branch:    r.HeadBranch,
+		CommitSHA: r.HeadSHA,
+		Event:     r.Event,
+		URL:       r.HTMLURL,
+		CreatedAt: r.CreatedAt,
+	}
+
+	var jobs struct {
+		Jobs []githubJob `json:"jobs"`
+	}
+	if err := g.client.GetJSON(ctx, fmt.Sprintf("/repos/%s/actions/runs/%d/jobs?filter=latest&per_page=100", g.repo, r.ID), &jobs); err != nil {
+		return nil, err
+	}
+
+	for _, j := range jobs.Jobs {
+		if !failed(j.Conclusion) {
+			continue
+		}
+		if len(run.Jobs) == maxFailedJobs {
+			break
+		}
+
+		job := Job{Name: j.Name, URL: j.HTMLURL, Conclusion: j.Conclusion}
+		for _, step := range j.Steps {
+			if failed(step.Conclusion) {
+				job.FailedSteps = append(job.FailedSteps, step.Name)
+			}
+		}
+
+		log, err := g.getLog(ctx, j.ID)
+		if err != nil {
+			job.LogError = err.Error()
+		} else {
+			job.Errors = ExtractErrors(log)
+		}
+		run.Jobs = append(run.Jobs, job)
+	}
+	return run, nil
+}
+
+// getLog downloads the plain-text log of a job
+func (g *GitHubActions) getLog(ctx context.Context, jobID int64) (string, error) {
+	resp, err := g.client.Get(ctx, fmt.Sprintf("/repos/%s/actions/jobs/%d/logs", g.repo, jobID))
+	if err != nil {
+		if !g.client.Authenticated() {
+			return "", fmt.Errorf("%w (downloading logs requires a GitHub token)", err)
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	log, err := io.ReadAll(io.LimitReader(resp.Body, maxLogBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read job log: %w", err)
+	}
+	return string(log), nil
+}
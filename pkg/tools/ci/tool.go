@@ -0,0 +1,84 @@
+package ci
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// FetchCILogsTool finds the latest failing CI run for a branch and returns
+// its failing steps and error lines.
+type FetchCILogsTool struct {
+	provider     Provider
+	workspaceDir string
+}
+
+// NewFetchCILogsTool creates a new FetchCILogsTool reading from provider.
+// The branch defaults to the one checked out in workspaceDir.
+func NewFetchCILogsTool(provider Provider, workspaceDir string) *FetchCILogsTool {
+	return &FetchCILogsTool{provider: provider, workspaceDir: workspaceDir}
+}
+
+// Name returns the tool name.
+func (t *FetchCILogsTool) Name() string {
+	return "fetch_ci_logs"
+}
+
+// Description returns the tool description.
+func (t *FetchCILogsTool) Description() string {
+	return "Fetch the latest failing GitHub Actions run for a branch (default: the current branch): its failed jobs and steps, and the error lines from their logs with surrounding context. Use this to start on CI failures instead of asking for logs. Read-only."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *FetchCILogsTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"branch": map[string]interface{}{
+				"type":        "string",
+				"description": "Branch whose runs to check (default: the current branch)",
+			},
+		},
+		[]string{},
+	)
+}
+
+// Execute fetches and summarizes the failing run.
+func (t *FetchCILogsTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Branch  string   `xml:"branch"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	branch := input.Branch
+	if branch == "" {
+		current, err := CurrentBranch(ctx, t.workspaceDir)
+		if err != nil {
+			return "", err
+		}
+		branch = current
+	}
+
+	run, err := t.provider.LatestFailure(ctx, branch)
+	if err != nil {
+		return "", err
+	}
+	if run == nil {
+		return fmt.Sprintf("No failed CI runs found for branch %s", branch), nil
+	}
+
+	head, _ := git.Output(ctx, t.workspaceDir, nil, "rev-parse", "HEAD")
+	return FormatRun(run, strings.TrimSpace(head)), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *FetchCILogsTool) IsLoopBreaking() bool {
+	return false
+}
@@ -0,0 +1,167 @@
+// Package clipboard puts text on the user's clipboard for the
+// copy_to_clipboard tool. It uses the local clipboard command when there is
+// one (pbcopy on macOS, xclip under X11) and otherwise asks the terminal to
+// do it with an OSC 52 escape sequence, which also reaches the user's own
+// machine over SSH.
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// MaxOSC52Bytes caps the text sent through OSC 52; many terminals drop
+// longer sequences without saying so
+const MaxOSC52Bytes = 100 * 1024
+
+// Backend puts text on a clipboard
+type Backend interface {
+	// Name identifies the backend to the user, e.g. "pbcopy"
+	Name() string
+
+	// Available reports whether the backend can be used on this machine
+	Available() bool
+
+	// Copy replaces the clipboard's content with text
+	Copy(ctx context.Context, text string) error
+}
+
+// DefaultBackends returns the backends tried in order. Over SSH the local
+// commands would fill the remote machine's clipboard, so OSC 52 comes first.
+func DefaultBackends() []Backend {
+	local := []Backend{
+		&commandBackend{name: "pbcopy", args: []string{"pbcopy"}},
+		&commandBackend{name: "xclip", args: []string{"xclip", "-selection", "clipboard"}, env: "DISPLAY"},
+	}
+	osc52 := NewOSC52Backend(openTTY)
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		return append([]Backend{osc52}, local...)
+	}
+	return append(local, osc52)
+}
+
+// Copy puts text on the clipboard with the first available backend that
+// succeeds, returning its name
+func Copy(ctx context.Context, backends []Backend, text string) (string, error) {
+	var errs []error
+	for _, backend := range backends {
+		if !backend.Available() {
+			continue
+		}
+		err := backend.Copy(ctx, text)
+		if err == nil {
+			return backend.Name(), nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", backend.Name(), err))
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("no clipboard is available: install pbcopy or xclip, or use a terminal that supports OSC 52")
+	}
+	return "", fmt.Errorf("failed to copy to the clipboard: %w", errors.Join(errs...))
+}
+
+// Preferred returns the name of the backend Copy would try first, or ""
+// when none is available
+func Preferred(backends []Backend) string {
+	for _, backend := range backends {
+		if backend.Available() {
+			return backend.Name()
+		}
+	}
+	return ""
+}
+
+// commandBackend pipes the text into a clipboard command
+type commandBackend struct {
+	name string
+	args []string
+	env  string // Environment variable that must be set, e.g. DISPLAY for X11
+}
+
+func (b *commandBackend) Name() string { return b.name }
+
+func (b *commandBackend) Available() bool {
+	if b.env != "" && os.Getenv(b.env) == "" {
+		return false
+	}
+	_, err := exec.LookPath(b.args[0])
+	return err == nil
+}
+
+func (b *commandBackend) Copy(ctx context.Context, text string) error {
+	cmd := exec.CommandContext(ctx, b.args[0], b.args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// OSC52Backend writes the OSC 52 escape sequence that asks the terminal to
+// set its clipboard. The terminal gives no answer, so a terminal without
+// OSC 52 support ignores it silently.
+type OSC52Backend struct {
+	open func() (io.WriteCloser, error)
+	tmux bool
+}
+
+// NewOSC52Backend creates an OSC52Backend writing to the terminal open
+// returns. Inside tmux the sequence is wrapped so tmux passes it on.
+func NewOSC52Backend(open func() (io.WriteCloser, error)) *OSC52Backend {
+	return &OSC52Backend{open: open, tmux: os.Getenv("TMUX") != ""}
+}
+
+// Name returns the backend name.
+func (b *OSC52Backend) Name() string { return "OSC 52" }
+
+// Available reports whether the terminal can be opened.
+func (b *OSC52Backend) Available() bool {
+	w, err := b.open()
+	if err != nil {
+		return false
+	}
+	w.Close()
+	return true
+}
+
+// Copy writes the sequence for text to the terminal.
+func (b *OSC52Backend) Copy(ctx context.Context, text string) error {
+	if len(text) > MaxOSC52Bytes {
+		return fmt.Errorf("%d bytes is more than terminals accept through OSC 52 (%d)", len(text), MaxOSC52Bytes)
+	}
+	w, err := b.open()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	_, err = io.WriteString(w, osc52Sequence(text, b.tmux))
+	return err
+}
+
+// osc52Sequence returns the escape sequence that sets the clipboard to text
+func osc52Sequence(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		// tmux passes a DCS sequence on to the outer terminal, with each
+		// escape doubled
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// openTTY opens the controlling terminal, which the TUI shares
+func openTTY() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
@@ -0,0 +1,101 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeBackend records what it was asked to copy
+type fakeBackend struct {
+	name      string
+	available bool
+	err       error
+	copied    string
+}
+
+func (f *fakeBackend) Name() string    { return f.name }
+func (f *fakeBackend) Available() bool { return f.available }
+
+func (f *fakeBackend) Copy(ctx context.Context, text string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.copied = text
+	return nil
+}
+
+// nopCloser turns a buffer into the terminal an OSC52Backend writes to
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestCopy_FallsBack(t *testing.T) {
+	missing := &fakeBackend{name: "pbcopy"}
+	broken := &fakeBackend{name: "xclip", available: true, err: errors.New("can't open display")}
+	terminal := &fakeBackend{name: "OSC 52", available: true}
+
+	name, err := Copy(context.Background(), []Backend{missing, broken, terminal}, "go test ./...")
+	if err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if name != "OSC 52" || terminal.copied != "go test ./..." {
+		t.Errorf("expected the text copied with OSC 52, got %q via %s", terminal.copied, name)
+	}
+
+	if _, err := Copy(context.Background(), []Backend{missing}, "x"); err == nil || !strings.Contains(err.Error(), "no clipboard is available") {
+		t.Errorf("expected no clipboard to be available, got %v", err)
+	}
+	if _, err := Copy(context.Background(), []Backend{broken}, "x"); err == nil || !strings.Contains(err.Error(), "xclip: can't open display") {
+		t.Errorf("expected the backend's error, got %v", err)
+	}
+}
+
+func TestOSC52Backend(t *testing.T) {
+	var terminal bytes.Buffer
+	backend := &OSC52Backend{open: func() (io.WriteCloser, error) { return nopCloser{&terminal}, nil }}
+
+	if err := backend.Copy(context.Background(), "hello"); err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if got := terminal.String(); got != "\x1b]52;c;aGVsbG8=\a" {
+		t.Errorf("unexpected sequence %q", got)
+	}
+
+	if got := osc52Sequence("hello", true); got != "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\a\x1b\\" {
+		t.Errorf("unexpected tmux sequence %q", got)
+	}
+
+	if err := backend.Copy(context.Background(), strings.Repeat("x", MaxOSC52Bytes+1)); err == nil {
+		t.Error("expected text over the OSC 52 limit to be refused")
+	}
+}
+
+func TestCopyToClipboardTool(t *testing.T) {
+	backend := &fakeBackend{name: "pbcopy", available: true}
+	tool := NewCopyToClipboardTool(WithBackends(backend))
+	args := []byte("<arguments><text>curl -s https://example.com\nexit 0\n</text></arguments>")
+
+	preview, err := tool.GeneratePreview(context.Background(), args)
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if preview.Title != "Copy 2 lines to the clipboard" || !strings.HasSuffix(preview.Description, "using pbcopy") {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+
+	result, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "Copied 2 lines to the clipboard with pbcopy" || backend.copied != "curl -s https://example.com\nexit 0\n" {
+		t.Errorf("unexpected result %q, copied %q", result, backend.copied)
+	}
+
+	if _, err := tool.Execute(context.Background(), []byte("<arguments><text>  </text></arguments>")); err == nil {
+		t.Error("expected empty text to be refused")
+	}
+}
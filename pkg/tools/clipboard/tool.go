@@ -0,0 +1,127 @@
+package clipboard
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// CopyToClipboardTool puts a snippet, command or URL the user asked for on
+// their clipboard. It asks for approval with the text as the preview.
+type CopyToClipboardTool struct {
+	backends []Backend
+}
+
+// Option configures a CopyToClipboardTool
+type Option func(*CopyToClipboardTool)
+
+// WithBackends replaces the backends tried, DefaultBackends by default
+func WithBackends(backends ...Backend) Option {
+	return func(t *CopyToClipboardTool) {
+		t.backends = backends
+	}
+}
+
+// NewCopyToClipboardTool creates a new CopyToClipboardTool
+func NewCopyToClipboardTool(opts ...Option) *CopyToClipboardTool {
+	t := &CopyToClipboardTool{backends: DefaultBackends()}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Name returns the tool name.
+func (t *CopyToClipboardTool) Name() string {
+	return "copy_to_clipboard"
+}
+
+// Description returns the tool description.
+func (t *CopyToClipboardTool) Description() string {
+	return "Put text on the user's clipboard, such as a snippet, command or URL, when they ask you to copy something for them. Pass exactly the text to paste, without Markdown fences or commentary. The user approves the text first."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *CopyToClipboardTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"text": map[string]interface{}{
+				"type":        "string",
+				"description": "The exact text to put on the clipboard",
+			},
+		},
+		[]string{"text"},
+	)
+}
+
+// clipboardInput is the tool's arguments
+type clipboardInput struct {
+	XMLName xml.Name `xml:"arguments"`
+	Text    string   `xml:"text"`
+}
+
+// parse reads the arguments, rejecting empty text
+func parse(argsXML []byte) (string, error) {
+	var input clipboardInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(input.Text) == "" {
+		return "", fmt.Errorf("text is required")
+	}
+	return input.Text, nil
+}
+
+// Execute copies the text.
+func (t *CopyToClipboardTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	text, err := parse(argsXML)
+	if err != nil {
+		return "", err
+	}
+
+	backend, err := Copy(ctx, t.backends, text)
+	if err != nil {
+		return "", err
+	}
+	result := fmt.Sprintf("Copied %s to the clipboard with %s", describe(text), backend)
+	if backend == "OSC 52" {
+		result += " (the terminal sets the clipboard; terminals without OSC 52 support ignore it, so tell the user if they can't paste it)"
+	}
+	return result, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *CopyToClipboardTool) IsLoopBreaking() bool {
+	return false
+}
+
+// GeneratePreview implements the Previewable interface to show the text
+// before it replaces the clipboard.
+func (t *CopyToClipboardTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	text, err := parse(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	description := "This will replace your clipboard with the text below"
+	if backend := Preferred(t.backends); backend != "" {
+		description += ", using " + backend
+	}
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeClipboard,
+		Title:       fmt.Sprintf("Copy %s to the clipboard", describe(text)),
+		Description: description,
+		Content:     text,
+	}, nil
+}
+
+// describe sizes text for people, in lines or characters
+func describe(text string) string {
+	if lines := strings.Count(strings.TrimRight(text, "\n"), "\n") + 1; lines > 1 {
+		return fmt.Sprintf("%d lines", lines)
+	}
+	return fmt.Sprintf("%d characters", len([]rune(text)))
+}
@@ -3,8 +3,10 @@ package coding
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
@@ -13,13 +15,15 @@ import (
 
 // ApplyDiffTool applies search/replace operations to files for precise code editing.
 type ApplyDiffTool struct {
-	guard *workspace.Guard
+	guard   *workspace.Guard
+	options editOptions
 }
 
 // NewApplyDiffTool creates a new ApplyDiffTool with workspace security.
-func NewApplyDiffTool(guard *workspace.Guard) *ApplyDiffTool {
+func NewApplyDiffTool(guard *workspace.Guard, opts ...EditOption) *ApplyDiffTool {
 	return &ApplyDiffTool{
-		guard: guard,
+		guard:   guard,
+		options: newEditOptions(opts),
 	}
 }
 
@@ -28,9 +32,14 @@ func (t *ApplyDiffTool) Name() string {
 	return "apply_diff"
 }
 
+// Aliases returns the tool's deprecated names, from other agents' prompts.
+func (t *ApplyDiffTool) Aliases() []string {
+	return []string{"replace_in_file"}
+}
+
 // Description returns the tool description.
 func (t *ApplyDiffTool) Description() string {
-	return "Apply precise search/replace operations to files. Supports multiple edits in a single operation for surgical code changes."
+	return "Apply precise search/replace operations to files. Supports multiple edits in a single operation for surgical code changes. Every search text is matched against the file as it was before the call; overlapping edits are merged when unambiguous and otherwise rejected with both conflicting edits."
 }
 
 // Schema returns the JSON schema for the tool's input parameters.
@@ -59,6 +68,7 @@ func (t *ApplyDiffTool) Schema() map[string]interface{} {
 					"required": []string{"search", "replace"},
 				},
 			},
+			"output_format": outputFormatProperty(),
 		},
 		[]string{"path", "edits"},
 	)
@@ -66,84 +76,72 @@ func (t *ApplyDiffTool) Schema() map[string]interface{} {
 
 // Execute performs the search/replace operations on the file.
 func (t *ApplyDiffTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult performs the search/replace operations on the file, returning
+// the change as a diff artifact.
+func (t *ApplyDiffTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
 	var input struct {
-		XMLName xml.Name `xml:"arguments"`
-		Path    string   `xml:"path"`
-		Edits   []struct {
-			Search  string `xml:"search"`
-			Replace string `xml:"replace"`
-		} `xml:"edits>edit"`
+		XMLName      xml.Name   `xml:"arguments"`
+		Path         string     `xml:"path"`
+		Edits        []diffEdit `xml:"edits>edit"`
+		OutputFormat string     `xml:"output_format"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
 	}
 
 	if input.Path == "" {
-		return "", fmt.Errorf("path is required")
+		return nil, fmt.Errorf("path is required")
 	}
 
 	if len(input.Edits) == 0 {
-		return "", fmt.Errorf("at least one edit is required")
+		return nil, fmt.Errorf("at least one edit is required")
 	}
 
 	// Resolve path to absolute path
 	absPath, err := t.guard.ResolvePath(input.Path)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve path: %w", err)
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	// Validate path is within workspace
 	if validateErr := t.guard.ValidatePath(input.Path); validateErr != nil {
-		return "", fmt.Errorf("invalid path: %w", validateErr)
+		return nil, fmt.Errorf("invalid path: %w", validateErr)
 	}
 
 	// Read current file content
 	content, err := os.ReadFile(absPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	fileContent := string(content)
-	originalContent := fileContent
-
-	// Apply each edit in sequence
-	appliedEdits := 0
-	for i, edit := range input.Edits {
-		if edit.Search == "" {
-			return "", fmt.Errorf("edit %d: search text cannot be empty", i+1)
-		}
-
-		// Check if search text exists
-		if !strings.Contains(fileContent, edit.Search) {
-			return "", fmt.Errorf("edit %d: search text not found in file:\n%s", i+1, edit.Search)
-		}
-
-		// Count occurrences to warn about multiple matches
-		count := strings.Count(fileContent, edit.Search)
-		if count > 1 {
-			return "", fmt.Errorf("edit %d: search text appears %d times in file, must be unique", i+1, count)
+	originalContent := string(content)
+	fileContent, err := applyEdits(originalContent, input.Edits)
+	if err != nil {
+		var notFound *SearchNotFoundError
+		if errors.As(err, &notFound) {
+			notFound.Path = filepath.ToSlash(input.Path)
 		}
-
-		// Apply the replacement
-		fileContent = strings.Replace(fileContent, edit.Search, edit.Replace, 1)
-		appliedEdits++
+		return nil, err
 	}
+	appliedEdits := len(input.Edits)
 
 	// Only write if changes were made
 	if fileContent == originalContent {
-		return "No changes made to file", nil
-	}
-
-	// Write the modified content atomically
-	tmpPath := absPath + ".tmp"
-	if writeErr := os.WriteFile(tmpPath, []byte(fileContent), 0600); writeErr != nil {
-		return "", fmt.Errorf("failed to write temporary file: %w", writeErr)
-	}
-
-	if renameErr := os.Rename(tmpPath, absPath); renameErr != nil {
-		os.Remove(tmpPath)
-		return "", fmt.Errorf("failed to rename temporary file: %w", renameErr)
+		path := filepath.ToSlash(input.Path)
+		return newResult(format, "No changes made to file", fmt.Sprintf("No changes to %s", path), applyDiffJSONResult{
+			Path:         path,
+			EditsApplied: appliedEdits,
+			Changed:      false,
+		}, tools.Artifact{Kind: tools.ArtifactFile, Path: path})
 	}
 
 	// Get relative path for response
@@ -152,7 +150,40 @@ func (t *ApplyDiffTool) Execute(ctx context.Context, argsXML []byte) (string, er
 		relPath = input.Path
 	}
 
-	return fmt.Sprintf("Successfully applied %d edit(s) to %s", appliedEdits, relPath), nil
+	// Check that the file still parses
+	syntaxErrs, refuse := t.options.checkEdit(relPath, originalContent, fileContent, true)
+	if refuse {
+		return nil, syntaxRefusal(relPath, syntaxErrs)
+	}
+
+	// Write the modified content atomically
+	if _, writeErr := writeFileAtomic(ctx, absPath, strings.NewReader(fileContent)); writeErr != nil {
+		return nil, writeErr
+	}
+
+	path := filepath.ToSlash(relPath)
+	message := fmt.Sprintf("Successfully applied %d edit(s) to %s", appliedEdits, relPath)
+	summary := fmt.Sprintf("Applied %d edit(s) to %s", appliedEdits, path)
+	if len(syntaxErrs) > 0 {
+		message += syntaxReport(syntaxErrs)
+		summary += ", with syntax errors"
+	}
+	return newResult(format, message, summary,
+		applyDiffJSONResult{
+			Path:         path,
+			EditsApplied: appliedEdits,
+			Changed:      true,
+			SyntaxErrors: syntaxErrs,
+		},
+		tools.Artifact{Kind: tools.ArtifactDiff, Path: path, Diff: GenerateUnifiedDiff(originalContent, fileContent, path)})
+}
+
+// applyDiffJSONResult is the structured apply_diff result for output_format=json.
+type applyDiffJSONResult struct {
+	Path         string   `json:"path"`
+	EditsApplied int      `json:"edits_applied"`
+	Changed      bool     `json:"changed"`
+	SyntaxErrors []string `json:"syntax_errors,omitempty"`
 }
 
 // IsLoopBreaking returns whether this tool should break the agent loop.
@@ -188,12 +219,9 @@ func (t *ApplyDiffTool) XMLExample() string {
 // GeneratePreview implements the Previewable interface to show a diff preview.
 func (t *ApplyDiffTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
 	var input struct {
-		XMLName xml.Name `xml:"arguments"`
-		Path    string   `xml:"path"`
-		Edits   []struct {
-			Search  string `xml:"search"`
-			Replace string `xml:"replace"`
-		} `xml:"edits>edit"`
+		XMLName xml.Name   `xml:"arguments"`
+		Path    string     `xml:"path"`
+		Edits   []diffEdit `xml:"edits>edit"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
@@ -225,24 +253,9 @@ func (t *ApplyDiffTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 	}
 
 	originalContent := string(content)
-	modifiedContent := originalContent
-
-	// Apply edits to generate modified version
-	for i, edit := range input.Edits {
-		if edit.Search == "" {
-			return nil, fmt.Errorf("edit %d: search text cannot be empty", i+1)
-		}
-
-		if !strings.Contains(modifiedContent, edit.Search) {
-			return nil, fmt.Errorf("edit %d: search text not found in file", i+1)
-		}
-
-		count := strings.Count(modifiedContent, edit.Search)
-		if count > 1 {
-			return nil, fmt.Errorf("edit %d: search text appears %d times in file, must be unique", i+1, count)
-		}
-
-		modifiedContent = strings.Replace(modifiedContent, edit.Search, edit.Replace, 1)
+	modifiedContent, err := applyEdits(originalContent, input.Edits)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate diff
@@ -3,11 +3,10 @@ package coding
 import (
 	"context"
 	"encoding/xml"
-	"os"
-	"path/filepath"
+	"strings"
 	"testing"
 
-	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/workspacetest"
 )
 
 func TestApplyDiffXMLUnmarshal(t *testing.T) {
@@ -68,35 +67,17 @@ func TestApplyDiffXMLUnmarshal(t *testing.T) {
 }
 
 func TestApplyDiffToolWithXML(t *testing.T) {
-	// Create a temporary directory for testing
-	tmpDir, err := os.MkdirTemp("", "apply_diff_test")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tmpDir)
-
-	// Create a test file
-	testFile := filepath.Join(tmpDir, "test.go")
-	originalContent := `package main
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"test.go": `package main
 
 func oldFunction() {
 	return "old"
 }
 
 const oldValue = 42
-`
-	if writeErr := os.WriteFile(testFile, []byte(originalContent), 0644); writeErr != nil {
-		t.Fatalf("Failed to create test file: %v", writeErr)
-	}
-
-	// Create workspace guard
-	guard, err := workspace.NewGuard(tmpDir)
-	if err != nil {
-		t.Fatalf("Failed to create workspace guard: %v", err)
-	}
-
-	// Create tool
-	tool := NewApplyDiffTool(guard)
+`,
+	})
+	tool := NewApplyDiffTool(ws.Guard())
 
 	// Create XML input with multiple edits
 	xmlInput := `<arguments>
@@ -129,21 +110,36 @@ const oldValue = 42
 		t.Errorf("Expected result '%s', got '%s'", expectedMsg, result)
 	}
 
-	// Verify file content was updated
-	updatedContent, err := os.ReadFile(testFile)
-	if err != nil {
-		t.Fatalf("Failed to read updated file: %v", err)
-	}
-
-	expectedContent := `package main
+	ws.AssertFile("test.go", `package main
 
 func newFunction() {
 	return "new"
 }
 
 const newValue = 100
-`
-	if string(updatedContent) != expectedContent {
-		t.Errorf("File content mismatch.\nExpected:\n%s\nGot:\n%s", expectedContent, string(updatedContent))
+`)
+}
+
+func TestApplyDiffTool_SearchNotFound(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"pkg/test.go": "package main\n\nconst value = 42\n",
+	})
+	tool := NewApplyDiffTool(ws.Guard())
+
+	_, err := tool.Execute(context.Background(), []byte(`<arguments>
+	<path>pkg/test.go</path>
+	<edits>
+		<edit>
+			<search>const value = 41</search>
+			<replace>const value = 43</replace>
+		</edit>
+	</edits>
+</arguments>`))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	want := "Lines 1-3 of pkg/test.go as they are now (no line of the search text is in the file):\n1 | package main\n2 | \n3 | const value = 42"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected the error to contain %q, got:\n%s", want, err)
 	}
 }
@@ -0,0 +1,113 @@
+package coding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// newFileMode is the permission of files created by the coding tools
+const newFileMode fs.FileMode = 0o644
+
+// writeChunkSize is how much is written between cancellation checks
+const writeChunkSize = 64 * 1024
+
+// writeFileAtomic replaces path with the content of r. The content is
+// streamed to a temporary file in the same directory, synced to disk and
+// renamed over path, so path holds either its old or its new content even if
+// the write is cancelled or the process dies. An existing file keeps its
+// permissions and, where the platform allows, its owner; a symlink is
+// followed and its target replaced. It returns the number of bytes written.
+func writeFileAtomic(ctx context.Context, path string, r io.Reader) (int64, error) {
+	mode := newFileMode
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if info.IsDir() {
+			return 0, fmt.Errorf("%s is a directory", path)
+		}
+		mode = info.Mode().Perm()
+		if resolved, evalErr := filepath.EvalSymlinks(path); evalErr == nil {
+			path = resolved
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	written, err := copyWithContext(ctx, tmp, r)
+	if err != nil {
+		return written, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return written, fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return written, fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if info != nil {
+		// Best effort: only privileged users can give a file to someone else
+		_ = preserveOwner(tmp, info)
+	}
+	if err := tmp.Close(); err != nil {
+		return written, fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	// A write cancelled after the content is on disk still leaves path as it was
+	if err := ctx.Err(); err != nil {
+		return written, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return written, fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+	committed = true
+
+	// Persist the rename itself; not every platform can sync a directory
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return written, nil
+}
+
+// copyWithContext copies r to w in chunks, stopping when ctx is done
+func copyWithContext(ctx context.Context, w io.Writer, r io.Reader) (int64, error) {
+	buf := make([]byte, writeChunkSize)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			m, err := w.Write(buf[:n])
+			written += int64(m)
+			if err != nil {
+				return written, err
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
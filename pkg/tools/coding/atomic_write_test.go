@@ -0,0 +1,124 @@
+package coding
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileAtomic_PreservesMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.sh")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := writeFileAtomic(context.Background(), path, strings.NewReader("#!/bin/sh\n"))
+	if err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	if n != 10 {
+		t.Errorf("wrote %d bytes, want 10", n)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "#!/bin/sh\n" {
+		t.Errorf("content = %q", data)
+	}
+}
+
+func TestWriteFileAtomic_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.txt")
+	if _, err := writeFileAtomic(context.Background(), path, strings.NewReader("hello")); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != newFileMode {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), newFileMode)
+	}
+}
+
+func TestWriteFileAtomic_FollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+	if err := os.WriteFile(target, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	if _, err := writeFileAtomic(context.Background(), link, strings.NewReader("new")); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected the symlink to remain, got %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("target content = %q, want %q", data, "new")
+	}
+}
+
+// failingReader returns some content and then an error, like a stream cut off
+type failingReader struct {
+	sent bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.sent {
+		return 0, errors.New("stream interrupted")
+	}
+	r.sent = true
+	return copy(p, "partial"), nil
+}
+
+func TestWriteFileAtomic_InterruptedKeepsOriginal(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  func() context.Context
+		r    io.Reader
+	}{
+		{"reader error", context.Background, &failingReader{}},
+		{"cancelled", func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, strings.NewReader("new content")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file.txt")
+			if err := os.WriteFile(path, []byte("original"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := writeFileAtomic(tt.ctx(), path, tt.r); err == nil {
+				t.Fatal("expected an error")
+			}
+			if data, _ := os.ReadFile(path); string(data) != "original" {
+				t.Errorf("content = %q, want the original", data)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("expected the temporary file to be removed, found %d entries", len(entries))
+			}
+		})
+	}
+}
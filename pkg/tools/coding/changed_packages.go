@@ -0,0 +1,370 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// nonSourceLanguages are the detectLanguage results that are not source
+// code; changing only such files doesn't make a package need new docs
+var nonSourceLanguages = map[string]bool{
+	"text":     true,
+	"markdown": true,
+	"json":     true,
+	"yaml":     true,
+	"html":     true,
+	"css":      true,
+}
+
+// ChangedPackage is a directory with source files changed in a git diff
+// range, and the documentation it is missing
+type ChangedPackage struct {
+	Dir          string   `json:"dir"`               // Slash-separated, relative to the workspace; "." for the root
+	Files        []string `json:"files"`             // Changed source files, relative to Dir
+	Readme       string   `json:"readme"`            // The package's README, relative to Dir; empty if it has none
+	PackageDoc   bool     `json:"package_doc"`       // For Go packages, whether a file has a package comment
+	Undocumented []string `json:"undocumented"`      // Exported Go identifiers without a doc comment, as "file:line kind Name"
+	Deleted      bool     `json:"deleted,omitempty"` // The directory no longer exists
+}
+
+// ChangedPackagesTool lists the packages changed in a git diff range with the
+// documentation they lack, so docs can be updated for them only.
+type ChangedPackagesTool struct {
+	guard *workspace.Guard
+}
+
+// NewChangedPackagesTool creates a new ChangedPackagesTool with workspace security.
+func NewChangedPackagesTool(guard *workspace.Guard) *ChangedPackagesTool {
+	return &ChangedPackagesTool{
+		guard: guard,
+	}
+}
+
+// Name returns the tool name.
+func (t *ChangedPackagesTool) Name() string {
+	return "changed_packages"
+}
+
+// Description returns the tool description.
+func (t *ChangedPackagesTool) Description() string {
+	return "List the packages (directories) whose source files changed in a git diff range, with their changed files, README, and the exported Go identifiers in changed files that have no doc comment. Use it before generate_docs to find what needs documenting."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *ChangedPackagesTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"range": map[string]interface{}{
+				"type":        "string",
+				"description": "Git diff range, e.g. main..HEAD or a commit to diff the working tree against (default: the working tree against the merge base with main, master or develop)",
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{},
+	)
+}
+
+// Execute lists the changed packages.
+func (t *ChangedPackagesTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult lists the changed packages, with the packages as the data.
+func (t *ChangedPackagesTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		Range        string   `xml:"range"`
+		OutputFormat string   `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeSpec, packages, err := findChangedPackages(ctx, t.guard, strings.TrimSpace(input.Range))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := struct {
+		Range    string           `json:"range"`
+		Packages []ChangedPackage `json:"packages"`
+	}{Range: rangeSpec, Packages: packages}
+	return newResult(format, formatChangedPackages(rangeSpec, packages),
+		fmt.Sprintf("%d package(s) changed in %s", len(packages), rangeSpec), payload)
+}
+
+// IsLoopBreaking returns whether this tool should break the agent loop.
+func (t *ChangedPackagesTool) IsLoopBreaking() bool {
+	return false
+}
+
+// formatChangedPackages renders the packages for the model
+func formatChangedPackages(rangeSpec string, packages []ChangedPackage) string {
+	if len(packages) == 0 {
+		return fmt.Sprintf("No source files changed in %s.", rangeSpec)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d package(s) changed in %s:\n", len(packages), rangeSpec)
+	for _, pkg := range packages {
+		fmt.Fprintf(&b, "\n%s/\n", pkg.Dir)
+		if pkg.Deleted {
+			b.WriteString("  deleted; remove references to it from the docs of other packages\n")
+			continue
+		}
+		changed := strings.Join(pkg.Files, ", ")
+		if changed == "" {
+			changed = "only deleted files"
+		}
+		fmt.Fprintf(&b, "  changed: %s\n", changed)
+		readme := pkg.Readme
+		if readme == "" {
+			readme = "none"
+		}
+		fmt.Fprintf(&b, "  README: %s\n", readme)
+		if hasGoFile(pkg.Files) && !pkg.PackageDoc {
+			b.WriteString("  package comment: missing\n")
+		}
+		if len(pkg.Undocumented) > 0 {
+			b.WriteString("  undocumented:\n")
+			for _, ident := range pkg.Undocumented {
+				fmt.Fprintf(&b, "    %s\n", ident)
+			}
+		}
+	}
+	return b.String()
+}
+
+// findChangedPackages returns the range diffed, resolving the default, and
+// the packages with source files changed in it, sorted by directory. Ignored
+// paths are skipped.
+func findChangedPackages(ctx context.Context, guard *workspace.Guard, rangeSpec string) (string, []ChangedPackage, error) {
+	dir := guard.WorkspaceDir()
+	if rangeSpec == "" {
+		rangeSpec = defaultDocsRange(ctx, dir)
+	}
+	if strings.HasPrefix(rangeSpec, "-") {
+		return "", nil, fmt.Errorf("invalid range %q", rangeSpec)
+	}
+
+	output, err := git.Output(ctx, dir, nil, "diff", "--name-only", "--relative", rangeSpec, "--")
+	if err != nil {
+		return "", nil, err
+	}
+	files := splitLines(output)
+
+	// A single commit is diffed against the working tree, which includes
+	// files git doesn't track yet
+	if !strings.Contains(rangeSpec, "..") {
+		untracked, err := git.Output(ctx, dir, nil, "ls-files", "--others", "--exclude-standard")
+		if err != nil {
+			return "", nil, err
+		}
+		files = append(files, splitLines(untracked)...)
+	}
+
+	byDir := make(map[string]*ChangedPackage)
+	for _, file := range files {
+		if nonSourceLanguages[detectLanguage(file)] || guard.ShouldIgnore(file) {
+			continue
+		}
+		pkgDir := path.Dir(file)
+		pkg, ok := byDir[pkgDir]
+		if !ok {
+			pkg = &ChangedPackage{Dir: pkgDir}
+			byDir[pkgDir] = pkg
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file))); err == nil {
+			pkg.Files = append(pkg.Files, path.Base(file))
+		}
+	}
+
+	packages := make([]ChangedPackage, 0, len(byDir))
+	for _, pkg := range byDir {
+		absDir := filepath.Join(dir, filepath.FromSlash(pkg.Dir))
+		if info, err := os.Stat(absDir); err != nil || !info.IsDir() {
+			pkg.Deleted = true
+			pkg.Files = nil
+			packages = append(packages, *pkg)
+			continue
+		}
+		sort.Strings(pkg.Files)
+		pkg.Readme = findReadme(absDir)
+		pkg.PackageDoc = hasPackageDoc(absDir)
+		for _, file := range pkg.Files {
+			if strings.HasSuffix(file, ".go") && !strings.HasSuffix(file, "_test.go") {
+				pkg.Undocumented = append(pkg.Undocumented, undocumentedGoIdentifiers(filepath.Join(absDir, file))...)
+			}
+		}
+		packages = append(packages, *pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Dir < packages[j].Dir })
+	return rangeSpec, packages, nil
+}
+
+// defaultDocsRange is the merge base of HEAD with the base branch, so the
+// branch's commits and its uncommitted changes are both included; HEAD when
+// there is no base branch
+func defaultDocsRange(ctx context.Context, dir string) string {
+	base, err := git.DetectBaseBranch(ctx, dir)
+	if err != nil {
+		return "HEAD"
+	}
+	mergeBase, err := git.Output(ctx, dir, nil, "merge-base", "HEAD", base)
+	if err != nil || strings.TrimSpace(mergeBase) == "" {
+		return "HEAD"
+	}
+	return strings.TrimSpace(mergeBase)
+}
+
+// splitLines returns the non-empty lines of git output
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// isReadme reports whether name is a README file, e.g. README.md
+func isReadme(name string) bool {
+	return strings.EqualFold(strings.TrimSuffix(name, filepath.Ext(name)), "README")
+}
+
+// findReadme returns the name of the README in dir, or ""
+func findReadme(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isReadme(entry.Name()) {
+			return entry.Name()
+		}
+	}
+	return ""
+}
+
+// hasPackageDoc reports whether a non-test Go file in dir has a package comment
+func hasPackageDoc(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range matches {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err == nil && parsed.Doc != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// undocumentedGoIdentifiers returns the exported top-level identifiers of a
+// Go file that have no doc comment, as "file:line kind Name". Methods count
+// when their receiver type is exported; a comment on a grouped declaration
+// documents the whole group.
+func undocumentedGoIdentifiers(file string) []string {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+
+	name := filepath.Base(file)
+	var missing []string
+	report := func(pos token.Pos, kind, ident string) {
+		missing = append(missing, fmt.Sprintf("%s:%d %s %s", name, fset.Position(pos).Line, kind, ident))
+	}
+
+	for _, decl := range parsed.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil || !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil {
+				report(d.Pos(), "func", d.Name.Name)
+				continue
+			}
+			if recv := receiverTypeName(d.Recv); ast.IsExported(recv) {
+				report(d.Pos(), "method", recv+"."+d.Name.Name)
+			}
+		case *ast.GenDecl:
+			if d.Doc != nil || d.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Doc == nil && s.Name.IsExported() {
+						report(s.Pos(), "type", s.Name.Name)
+					}
+				case *ast.ValueSpec:
+					if s.Doc != nil || s.Comment != nil {
+						continue
+					}
+					for _, ident := range s.Names {
+						if ident.IsExported() {
+							report(ident.Pos(), d.Tok.String(), ident.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return missing
+}
+
+// receiverTypeName returns the name of a method's receiver type
+func receiverTypeName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// hasGoFile reports whether files includes a Go source file
+func hasGoFile(files []string) bool {
+	for _, file := range files {
+		if strings.HasSuffix(file, ".go") {
+			return true
+		}
+	}
+	return false
+}
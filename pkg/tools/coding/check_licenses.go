@@ -0,0 +1,292 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// Checks run by check_licenses
+const (
+	licenseCheckAll          = "all"
+	licenseCheckDependencies = "dependencies"
+	licenseCheckHeaders      = "headers"
+)
+
+// maxHeaderDiffs caps the header diffs shown to the model; the rest are listed by path
+const maxHeaderDiffs = 10
+
+// CheckLicensesTool inventories the licenses of the workspace's dependencies
+// against the allowed licenses, and checks its source files for the
+// configured license header, returning the diff that would add each
+// missing header. It changes nothing; insert_license_headers applies the
+// diffs.
+type CheckLicensesTool struct {
+	guard  *workspace.Guard
+	policy LicensePolicy
+	now    func() time.Time
+}
+
+// NewCheckLicensesTool creates a new CheckLicensesTool enforcing policy.
+func NewCheckLicensesTool(guard *workspace.Guard, policy LicensePolicy) *CheckLicensesTool {
+	return &CheckLicensesTool{
+		guard:  guard,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Name returns the tool name.
+func (t *CheckLicensesTool) Name() string {
+	return "check_licenses"
+}
+
+// Description returns the tool description.
+func (t *CheckLicensesTool) Description() string {
+	return "Check license compliance without changing anything: inventory the licenses of Go (go-licenses) and npm (license-checker) dependencies, flagging unknown licenses and those not in the configured allowed list, and check source files for the configured license header, returning the diff that would add each missing header. Apply the header diffs with insert_license_headers."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *CheckLicensesTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"check": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{licenseCheckAll, licenseCheckDependencies, licenseCheckHeaders},
+				"description": "What to check: dependency licenses, source file headers, or all (default)",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory whose source files are checked for headers (relative to workspace, default: the whole workspace)",
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{},
+	)
+}
+
+// Execute runs the checks.
+func (t *CheckLicensesTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult runs the checks, with the dependencies and header issues as
+// the data and a diff artifact per missing header.
+func (t *CheckLicensesTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		Check        string   `xml:"check"`
+		Path         string   `xml:"path"`
+		OutputFormat string   `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	check := strings.ToLower(strings.TrimSpace(input.Check))
+	switch check {
+	case "":
+		check = licenseCheckAll
+	case licenseCheckAll, licenseCheckDependencies, licenseCheckHeaders:
+	default:
+		return nil, fmt.Errorf("invalid check %q: must be all, dependencies or headers", input.Check)
+	}
+
+	report := licenseReport{Check: check}
+	var headers *headerCheck
+	if check != licenseCheckDependencies {
+		if checker := newHeaderChecker(t.policy, t.now().Year()); checker != nil {
+			root, err := resolveHeaderRoot(t.guard, input.Path)
+			if err != nil {
+				return nil, err
+			}
+			if headers, err = checker.check(ctx, t.guard, root); err != nil {
+				return nil, err
+			}
+			report.HeaderTemplate = strings.TrimSpace(t.policy.Header)
+			report.FilesChecked = headers.checked
+			report.HeaderIssues = headers.issues
+		} else if check == licenseCheckHeaders {
+			return nil, fmt.Errorf("no license header is configured; set header in the licenses section of the config")
+		}
+	}
+	if check != licenseCheckHeaders {
+		report.Dependencies, report.Scanners = inventoryLicenses(ctx, t.guard.WorkspaceDir(), t.policy.Allowed)
+		report.AllowedLicenses = t.policy.Allowed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var artifacts []tools.Artifact
+	if headers != nil {
+		for _, fix := range headers.fixes {
+			artifacts = append(artifacts, tools.Artifact{Kind: tools.ArtifactDiff, Path: fix.relPath, Diff: fix.diff})
+		}
+	}
+	return newResult(format, report.format(headers), report.summary(), report, artifacts...)
+}
+
+// IsLoopBreaking returns whether this tool should break the agent loop.
+func (t *CheckLicensesTool) IsLoopBreaking() bool {
+	return false
+}
+
+// licenseReport is the structured check_licenses result
+type licenseReport struct {
+	Check           string              `json:"check"`
+	AllowedLicenses []string            `json:"allowed_licenses,omitempty"`
+	Scanners        []LicenseScan       `json:"scanners,omitempty"`
+	Dependencies    []DependencyLicense `json:"dependencies,omitempty"`
+	HeaderTemplate  string              `json:"header_template,omitempty"`
+	FilesChecked    int                 `json:"files_checked"`
+	HeaderIssues    []HeaderIssue       `json:"header_issues,omitempty"`
+}
+
+// violations returns the dependencies whose license is unknown or not allowed
+func (r *licenseReport) violations() []DependencyLicense {
+	var violations []DependencyLicense
+	for _, dep := range r.Dependencies {
+		if !dep.Allowed {
+			violations = append(violations, dep)
+		}
+	}
+	return violations
+}
+
+// summary returns the one-line summary of the checks run
+func (r *licenseReport) summary() string {
+	var parts []string
+	if r.Check != licenseCheckHeaders {
+		parts = append(parts, fmt.Sprintf("%d dependencies, %d flagged", len(r.Dependencies), len(r.violations())))
+	}
+	if r.HeaderTemplate != "" {
+		parts = append(parts, fmt.Sprintf("%d of %d source file(s) without the license header", len(r.HeaderIssues), r.FilesChecked))
+	}
+	return "Licenses: " + strings.Join(parts, "; ")
+}
+
+// format renders the report for the model
+func (r *licenseReport) format(headers *headerCheck) string {
+	var b strings.Builder
+	b.WriteString(r.summary() + "\n")
+
+	if r.Check != licenseCheckHeaders {
+		b.WriteString("\nDependency scanners:\n")
+		for _, scan := range r.Scanners {
+			switch scan.Status {
+			case ScanOK:
+				fmt.Fprintf(&b, "  %s: %d dependencies\n", scan.Scanner, scan.Dependencies)
+			case ScanNotInstalled:
+				fmt.Fprintf(&b, "  %s: not run, %s\n", scan.Scanner, scan.Detail)
+			case ScanNotApplicable:
+				fmt.Fprintf(&b, "  %s: skipped, %s\n", scan.Scanner, scan.Detail)
+			default:
+				fmt.Fprintf(&b, "  %s: failed: %s\n", scan.Scanner, scan.Detail)
+			}
+		}
+
+		if len(r.Dependencies) > 0 {
+			counts := make(map[string]int)
+			for _, dep := range r.Dependencies {
+				counts[dep.License]++
+			}
+			licenses := make([]string, 0, len(counts))
+			for license := range counts {
+				licenses = append(licenses, license)
+			}
+			sort.Slice(licenses, func(i, j int) bool {
+				if counts[licenses[i]] != counts[licenses[j]] {
+					return counts[licenses[i]] > counts[licenses[j]]
+				}
+				return licenses[i] < licenses[j]
+			})
+			b.WriteString("\nDependencies by license:\n")
+			for _, license := range licenses {
+				fmt.Fprintf(&b, "  %s: %d\n", license, counts[license])
+			}
+		}
+
+		if violations := r.violations(); len(violations) > 0 {
+			allowed := "any known license"
+			if len(r.AllowedLicenses) > 0 {
+				allowed = strings.Join(r.AllowedLicenses, ", ")
+			}
+			fmt.Fprintf(&b, "\nFlagged dependencies (allowed: %s):\n", allowed)
+			for _, dep := range violations {
+				name := dep.Name
+				if dep.Version != "" {
+					name += "@" + dep.Version
+				}
+				fmt.Fprintf(&b, "  %s (%s) %s: %s\n", name, dep.Ecosystem, dep.License, dep.URL)
+			}
+		}
+	}
+
+	if r.HeaderTemplate == "" {
+		if r.Check == licenseCheckAll {
+			b.WriteString("\nHeaders: not checked, no license header is configured\n")
+		}
+		return strings.TrimRight(b.String(), "\n")
+	}
+
+	fmt.Fprintf(&b, "\nHeader template:\n%s\n", r.HeaderTemplate)
+	var different []string
+	for _, issue := range r.HeaderIssues {
+		if issue.Status == HeaderDifferent {
+			different = append(different, issue.Path)
+		}
+	}
+	if len(different) > 0 {
+		fmt.Fprintf(&b, "\n%d file(s) have a different copyright or license header and must be fixed by hand:\n", len(different))
+		for _, path := range different {
+			fmt.Fprintf(&b, "  %s\n", path)
+		}
+	}
+	if len(headers.fixes) > 0 {
+		fmt.Fprintf(&b, "\n%d file(s) are missing the header; insert_license_headers applies these diffs:\n", len(headers.fixes))
+		for i, fix := range headers.fixes {
+			if i == maxHeaderDiffs {
+				fmt.Fprintf(&b, "\n... and %d more file(s):\n", len(headers.fixes)-maxHeaderDiffs)
+				for _, rest := range headers.fixes[maxHeaderDiffs:] {
+					fmt.Fprintf(&b, "  %s\n", rest.relPath)
+				}
+				break
+			}
+			b.WriteString("\n" + fix.diff)
+		}
+	}
+	if len(r.HeaderIssues) == 0 {
+		b.WriteString("\nEvery source file has the header.\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// resolveHeaderRoot returns the absolute directory whose files are checked
+// for headers: path, or the whole workspace when it is empty
+func resolveHeaderRoot(guard *workspace.Guard, path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return guard.WorkspaceDir(), nil
+	}
+	if err := guard.ValidatePath(path); err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	root, err := guard.ResolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return root, nil
+}
@@ -0,0 +1,356 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+const (
+	// maxDraftBytes bounds the content of a file written in chunks
+	maxDraftBytes = 8 << 20
+
+	// draftTailLines is how many of a draft's last lines append_chunk
+	// returns, so the next chunk can carry on where it stopped
+	draftTailLines = 3
+)
+
+// FileDrafts holds the files being written in chunks with begin_file,
+// append_chunk and end_file, which share one FileDrafts. A file too large to
+// write in one response, whose write_file call would be cut off by the
+// output limit, is sent a chunk per call instead; nothing is written to the
+// workspace until end_file. It is safe for concurrent use.
+type FileDrafts struct {
+	mu     sync.Mutex
+	drafts map[string]*fileDraft // Absolute path → its draft
+}
+
+// fileDraft is a file's content as received so far
+type fileDraft struct {
+	chunks []string
+	size   int
+}
+
+// content joins the chunks, adding the newline a chunk ends without
+func (d *fileDraft) content() string {
+	var b strings.Builder
+	for i, chunk := range d.chunks {
+		if i > 0 && !strings.HasSuffix(d.chunks[i-1], "\n") {
+			b.WriteByte('\n')
+		}
+		b.WriteString(chunk)
+	}
+	return b.String()
+}
+
+// NewFileDrafts returns an empty set of drafts
+func NewFileDrafts() *FileDrafts {
+	return &FileDrafts{drafts: make(map[string]*fileDraft)}
+}
+
+// resolveDraftPath validates path and returns it absolute, as drafts are
+// keyed, and relative to the workspace, as it is reported
+func resolveDraftPath(guard *workspace.Guard, path string) (string, string, error) {
+	if path == "" {
+		return "", "", fmt.Errorf("missing required parameter: path")
+	}
+	if err := guard.ValidatePath(path); err != nil {
+		return "", "", fmt.Errorf("invalid path: %w", err)
+	}
+	absPath, err := guard.ResolvePath(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	relPath, err := guard.MakeRelative(absPath)
+	if err != nil || relPath == "" {
+		relPath = path
+	}
+	return absPath, relPath, nil
+}
+
+// BeginFileTool starts writing a file in chunks.
+type BeginFileTool struct {
+	guard  *workspace.Guard
+	drafts *FileDrafts
+}
+
+// NewBeginFileTool creates a BeginFileTool keeping its drafts in drafts.
+func NewBeginFileTool(guard *workspace.Guard, drafts *FileDrafts) *BeginFileTool {
+	return &BeginFileTool{guard: guard, drafts: drafts}
+}
+
+// Name returns the tool name.
+func (t *BeginFileTool) Name() string {
+	return "begin_file"
+}
+
+// Description returns the tool description.
+func (t *BeginFileTool) Description() string {
+	return "Start writing a file too large for one write_file call (more than about 500 lines), which would be cut off by your output limit. " +
+		"Then send its content in order with append_chunk, a few hundred lines per call, and finish with end_file, which writes the file after approval. " +
+		"Starting a file again discards the chunks sent so far."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *BeginFileTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path to the file to write (relative to workspace)",
+			},
+		},
+		[]string{"path"},
+	)
+}
+
+// Execute starts an empty draft of the file.
+func (t *BeginFileTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+	}
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	absPath, relPath, err := resolveDraftPath(t.guard, input.Path)
+	if err != nil {
+		return "", err
+	}
+
+	t.drafts.mu.Lock()
+	defer t.drafts.mu.Unlock()
+	t.drafts.drafts[absPath] = &fileDraft{}
+	return fmt.Sprintf("Started %s. Send its content with append_chunk, starting at index 1, then call end_file.", relPath), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *BeginFileTool) IsLoopBreaking() bool {
+	return false
+}
+
+// AppendChunkTool adds a chunk to a file started with begin_file.
+type AppendChunkTool struct {
+	guard  *workspace.Guard
+	drafts *FileDrafts
+}
+
+// NewAppendChunkTool creates an AppendChunkTool adding to the drafts in drafts.
+func NewAppendChunkTool(guard *workspace.Guard, drafts *FileDrafts) *AppendChunkTool {
+	return &AppendChunkTool{guard: guard, drafts: drafts}
+}
+
+// Name returns the tool name.
+func (t *AppendChunkTool) Name() string {
+	return "append_chunk"
+}
+
+// Description returns the tool description.
+func (t *AppendChunkTool) Description() string {
+	return "Add the next chunk of a file started with begin_file. Chunks are joined in order, as whole lines. " +
+		"Number them from 1; sending an index again replaces that chunk, so a chunk that was cut off can be resent. " +
+		"The result ends with the file's last lines so far, to continue from."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *AppendChunkTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path given to begin_file",
+			},
+			"index": map[string]interface{}{
+				"type":        "integer",
+				"description": "Position of the chunk in the file, from 1",
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "The chunk's lines",
+			},
+		},
+		[]string{"path", "index", "content"},
+	)
+}
+
+// Execute adds the chunk to the file's draft.
+func (t *AppendChunkTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+		Index   int      `xml:"index"`
+		Content string   `xml:"content"`
+	}
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	absPath, relPath, err := resolveDraftPath(t.guard, input.Path)
+	if err != nil {
+		return "", err
+	}
+	if input.Content == "" {
+		return "", fmt.Errorf("missing required parameter: content")
+	}
+
+	t.drafts.mu.Lock()
+	defer t.drafts.mu.Unlock()
+	draft, ok := t.drafts.drafts[absPath]
+	if !ok {
+		return "", fmt.Errorf("%s has not been started: call begin_file first", relPath)
+	}
+
+	next := len(draft.chunks) + 1
+	switch {
+	case input.Index == next:
+		if draft.size+len(input.Content) > maxDraftBytes {
+			return "", fmt.Errorf("%s would exceed %d MB; split it into smaller files", relPath, maxDraftBytes>>20)
+		}
+		draft.chunks = append(draft.chunks, input.Content)
+		draft.size += len(input.Content)
+	case input.Index >= 1 && input.Index < next:
+		old := draft.chunks[input.Index-1]
+		if draft.size-len(old)+len(input.Content) > maxDraftBytes {
+			return "", fmt.Errorf("%s would exceed %d MB; split it into smaller files", relPath, maxDraftBytes>>20)
+		}
+		draft.chunks[input.Index-1] = input.Content
+		draft.size += len(input.Content) - len(old)
+	default:
+		return "", fmt.Errorf("expected chunk %d of %s, got index %d", next, relPath, input.Index)
+	}
+
+	content := draft.content()
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	tail := lines[max(0, len(lines)-draftTailLines):]
+	return fmt.Sprintf("%s: chunk %d received; %d chunks, %d lines so far. The file ends with:\n%s",
+		relPath, input.Index, len(draft.chunks), countLines(content), strings.Join(tail, "\n")), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *AppendChunkTool) IsLoopBreaking() bool {
+	return false
+}
+
+// EndFileTool writes a file assembled from chunks, as write_file does.
+type EndFileTool struct {
+	guard   *workspace.Guard
+	drafts  *FileDrafts
+	options editOptions
+}
+
+// NewEndFileTool creates an EndFileTool writing the drafts in drafts.
+func NewEndFileTool(guard *workspace.Guard, drafts *FileDrafts, opts ...EditOption) *EndFileTool {
+	return &EndFileTool{guard: guard, drafts: drafts, options: newEditOptions(opts)}
+}
+
+// Name returns the tool name.
+func (t *EndFileTool) Name() string {
+	return "end_file"
+}
+
+// Description returns the tool description.
+func (t *EndFileTool) Description() string {
+	return "Finish a file started with begin_file: check that all its chunks arrived, then create or overwrite the file with them, as write_file does. " +
+		"If the user rejects the write, the chunks are kept, so the file can be fixed with append_chunk and ended again."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *EndFileTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path given to begin_file",
+			},
+			"chunks": map[string]interface{}{
+				"type":        "integer",
+				"description": "Number of chunks you sent, to check none is missing",
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{"path", "chunks"},
+	)
+}
+
+// endFileInput is the arguments of end_file
+type endFileInput struct {
+	XMLName      xml.Name `xml:"arguments"`
+	Path         string   `xml:"path"`
+	Chunks       int      `xml:"chunks"`
+	OutputFormat string   `xml:"output_format"`
+}
+
+// draft returns the content of the draft the call ends, once every chunk
+// has arrived
+func (t *EndFileTool) draft(argsXML []byte) (*endFileInput, string, error) {
+	var input endFileInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	absPath, relPath, err := resolveDraftPath(t.guard, input.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	t.drafts.mu.Lock()
+	defer t.drafts.mu.Unlock()
+	draft, ok := t.drafts.drafts[absPath]
+	if !ok {
+		return nil, "", fmt.Errorf("%s has not been started: call begin_file first", relPath)
+	}
+	if len(draft.chunks) == 0 {
+		return nil, "", fmt.Errorf("no chunks of %s have been sent: call append_chunk first", relPath)
+	}
+	if input.Chunks != len(draft.chunks) {
+		return nil, "", fmt.Errorf("%s has %d chunks, not %d: send the missing ones with append_chunk, or resend a chunk by its index", relPath, len(draft.chunks), input.Chunks)
+	}
+	return &input, draft.content(), nil
+}
+
+// Execute writes the assembled file.
+func (t *EndFileTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult writes the assembled file, returning it as an artifact. The
+// draft is discarded once written.
+func (t *EndFileTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	input, content, err := t.draft(argsXML)
+	if err != nil {
+		return nil, err
+	}
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := writeContent(ctx, t.guard, t.options, input.Path, content, format)
+	if err != nil {
+		return nil, err
+	}
+	if absPath, err := t.guard.ResolvePath(input.Path); err == nil {
+		t.drafts.mu.Lock()
+		delete(t.drafts.drafts, absPath)
+		t.drafts.mu.Unlock()
+	}
+	return result, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *EndFileTool) IsLoopBreaking() bool {
+	return false
+}
+
+// GeneratePreview implements the Previewable interface to show the assembled
+// file as write_file would.
+func (t *EndFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	input, content, err := t.draft(argsXML)
+	if err != nil {
+		return nil, err
+	}
+	return previewWrite(t.guard, input.Path, content)
+}
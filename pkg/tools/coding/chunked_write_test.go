@@ -0,0 +1,100 @@
+package coding
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// chunkedWriteTools returns begin_file, append_chunk and end_file sharing
+// one set of drafts
+func chunkedWriteTools(ws *workspacetest.Workspace, opts ...EditOption) (*BeginFileTool, *AppendChunkTool, *EndFileTool) {
+	drafts := NewFileDrafts()
+	return NewBeginFileTool(ws.Guard(), drafts), NewAppendChunkTool(ws.Guard(), drafts), NewEndFileTool(ws.Guard(), drafts, opts...)
+}
+
+func runChunkTool(t *testing.T, tool tools.Tool, args string) (string, error) {
+	t.Helper()
+	return tool.Execute(context.Background(), []byte("<arguments>"+args+"</arguments>"))
+}
+
+func mustRunChunkTool(t *testing.T, tool tools.Tool, args string) string {
+	t.Helper()
+	out, err := runChunkTool(t, tool, args)
+	if err != nil {
+		t.Fatalf("%s: %v", tool.Name(), err)
+	}
+	return out
+}
+
+func TestChunkedWrite(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": "package main\n"})
+	begin, appendChunk, end := chunkedWriteTools(ws)
+
+	mustRunChunkTool(t, begin, "<path>main.go</path>")
+	mustRunChunkTool(t, appendChunk, "<path>main.go</path><index>1</index><content>package main\n\nfunc a() {}</content>")
+	out := mustRunChunkTool(t, appendChunk, "<path>./main.go</path><index>2</index><content>func b() {\n\tprintln(\"oops\")</content>")
+	if !strings.HasSuffix(out, "2 chunks, 5 lines so far. The file ends with:\nfunc a() {}\nfunc b() {\n\tprintln(\"oops\")") {
+		t.Errorf("expected the progress and the last lines, got %q", out)
+	}
+
+	// A resent chunk replaces the one with its index
+	mustRunChunkTool(t, appendChunk, "<path>main.go</path><index>2</index><content>func b() {}\n</content>")
+	if _, err := runChunkTool(t, appendChunk, "<path>main.go</path><index>4</index><content>x</content>"); err == nil || !strings.Contains(err.Error(), "expected chunk 3") {
+		t.Errorf("expected a skipped chunk to be refused, got %v", err)
+	}
+	ws.AssertFile("main.go", "package main\n")
+
+	// Nothing is written while a chunk is missing
+	if _, err := runChunkTool(t, end, "<path>main.go</path><chunks>3</chunks>"); err == nil || !strings.Contains(err.Error(), "has 2 chunks, not 3") {
+		t.Errorf("expected a chunk count mismatch, got %v", err)
+	}
+
+	preview, err := end.GeneratePreview(context.Background(), []byte("<arguments><path>main.go</path><chunks>2</chunks></arguments>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Type != tools.PreviewTypeDiff || preview.Title != "Overwrite main.go" || !strings.Contains(preview.Content, "+func b() {}") {
+		t.Errorf("expected a diff of the assembled file, got %+v", preview)
+	}
+
+	out = mustRunChunkTool(t, end, "<path>main.go</path><chunks>2</chunks>")
+	if out != "File 'main.go' overwritten successfully" {
+		t.Errorf("expected write_file's result, got %q", out)
+	}
+	ws.AssertFile("main.go", "package main\n\nfunc a() {}\nfunc b() {}\n")
+
+	// The draft is gone once written
+	if _, err := runChunkTool(t, end, "<path>main.go</path><chunks>2</chunks>"); err == nil || !strings.Contains(err.Error(), "call begin_file first") {
+		t.Errorf("expected the draft to be discarded, got %v", err)
+	}
+}
+
+func TestChunkedWriteValidates(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{})
+	begin, appendChunk, end := chunkedWriteTools(ws, WithSyntaxCheck(SyntaxCheckRevert))
+
+	if _, err := runChunkTool(t, appendChunk, "<path>new.go</path><index>1</index><content>package main</content>"); err == nil {
+		t.Error("expected a chunk for a file not begun to be refused")
+	}
+	if _, err := runChunkTool(t, begin, "<path>../outside.go</path>"); err == nil {
+		t.Error("expected a path outside the workspace to be refused")
+	}
+
+	// A file cut off mid-function fails the syntax check instead of being written
+	mustRunChunkTool(t, begin, "<path>new.go</path>")
+	mustRunChunkTool(t, appendChunk, "<path>new.go</path><index>1</index><content>package main\n\nfunc main() {\n</content>")
+	if _, err := runChunkTool(t, end, "<path>new.go</path><chunks>1</chunks>"); err == nil {
+		t.Error("expected an incomplete file to be refused")
+	}
+	ws.AssertMissing("new.go")
+
+	// Beginning again starts over
+	mustRunChunkTool(t, begin, "<path>new.go</path>")
+	mustRunChunkTool(t, appendChunk, "<path>new.go</path><index>1</index><content>package main\n</content>")
+	mustRunChunkTool(t, end, "<path>new.go</path><chunks>1</chunks>")
+	ws.AssertFile("new.go", "package main\n")
+}
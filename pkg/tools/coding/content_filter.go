@@ -0,0 +1,158 @@
+package coding
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+const (
+	// sniffSize is how much of a file is read to tell whether it is binary
+	// or minified
+	sniffSize = 8192
+
+	// minifiedLineLength is the line length taken to mean a file is minified.
+	// Hand-written code and data rarely come close.
+	minifiedLineLength = 1000
+
+	// maxSkippedListed is how many skipped files a search names
+	maxSkippedListed = 10
+)
+
+// Reasons a file is skipped
+const (
+	skipBinary   = "binary"
+	skipMinified = "minified"
+	skipTooLarge = "too large"
+)
+
+// binaryExts are extensions of files that are never text
+var binaryExts = map[string]bool{
+	".exe": true, ".dll": true, ".so": true, ".dylib": true,
+	".bin": true, ".dat": true, ".db": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true,
+	".pdf": true, ".zip": true, ".tar": true, ".gz": true,
+	".mp3": true, ".mp4": true, ".avi": true, ".mov": true,
+	".o": true, ".a": true, ".pyc": true,
+}
+
+// skippedFile is a file a search did not read, and why
+type skippedFile struct {
+	Path   string
+	Reason string
+}
+
+// nameSkipReason returns why a file would be skipped judging only by its
+// name and size, or "" if it wouldn't be. It needs no read, so listings can
+// use it for every entry.
+func nameSkipReason(path string, size int64, limits workspace.ContentLimits) string {
+	if binaryExts[strings.ToLower(filepath.Ext(path))] {
+		return skipBinary
+	}
+	if limits.SkipMinified && isMinifiedName(filepath.Base(path)) {
+		return skipMinified
+	}
+	if limits.MaxFileSize > 0 && size > limits.MaxFileSize {
+		return skipTooLarge
+	}
+	return ""
+}
+
+// contentSkipReason returns why a file should not be searched, checking its
+// name and size and then the start of its content, or "" if it should be
+func contentSkipReason(path string, limits workspace.ContentLimits) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if reason := nameSkipReason(path, info.Size(), limits); reason != "" {
+		return reason
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	buf := make([]byte, sniffSize)
+	n, _ := file.Read(buf)
+	buf = buf[:n]
+
+	// Null bytes are common in binary files and rare in text
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return skipBinary
+	}
+	if limits.SkipMinified && hasLongLine(buf) {
+		return skipMinified
+	}
+	return ""
+}
+
+// isMinifiedName reports whether name is that of a minified bundle or a
+// source map
+func isMinifiedName(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, ".min.") || strings.HasSuffix(name, ".map")
+}
+
+// hasLongLine reports whether sample has a line of minifiedLineLength or
+// more, counting a line cut off by the end of the sample
+func hasLongLine(sample []byte) bool {
+	for len(sample) > 0 {
+		i := bytes.IndexByte(sample, '\n')
+		if i < 0 {
+			return len(sample) >= minifiedLineLength
+		}
+		if i >= minifiedLineLength {
+			return true
+		}
+		sample = sample[i+1:]
+	}
+	return false
+}
+
+// describeSkipReason explains a skip reason for results
+func describeSkipReason(reason string, limits workspace.ContentLimits) string {
+	if reason == skipTooLarge {
+		return "larger than " + formatFileSize(limits.MaxFileSize)
+	}
+	return reason
+}
+
+// summarizeSkipped describes the files a search skipped: how many for each
+// reason, naming the minified and oversized ones, which may be surprising.
+// Binary files are only counted.
+func summarizeSkipped(skipped []skippedFile, limits workspace.ContentLimits, relative func(string) string) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	var named []string
+	for _, file := range skipped {
+		counts[file.Reason]++
+		if file.Reason != skipBinary {
+			named = append(named, relative(file.Path))
+		}
+	}
+
+	var reasons []string
+	for _, reason := range []string{skipMinified, skipTooLarge, skipBinary} {
+		if counts[reason] > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d %s", counts[reason], describeSkipReason(reason, limits)))
+		}
+	}
+
+	summary := fmt.Sprintf("Skipped %d files (%s)", len(skipped), strings.Join(reasons, ", "))
+	if len(named) > maxSkippedListed {
+		summary += fmt.Sprintf(": %s and %d more", strings.Join(named[:maxSkippedListed], ", "), len(named)-maxSkippedListed)
+	} else if len(named) > 0 {
+		summary += ": " + strings.Join(named, ", ")
+	}
+	return summary + ". Use @max-size or @skip-minified in .forgeignore to change this."
+}
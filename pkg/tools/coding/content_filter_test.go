@@ -0,0 +1,102 @@
+package coding
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestContentSkipReason(t *testing.T) {
+	dir := t.TempDir()
+	limits := workspace.ContentLimits{MaxFileSize: 4096, SkipMinified: true}
+
+	files := map[string]struct {
+		content string
+		want    string
+	}{
+		"main.go":       {"package main\n\nfunc main() {}\n", ""},
+		"logo.png":      {"not really a png", skipBinary},
+		"blob":          {"abc\x00def", skipBinary},
+		"app.min.js":    {"var a=1;", skipMinified},
+		"app.js.map":    {`{"version":3}`, skipMinified},
+		"bundle.js":     {strings.Repeat("a", minifiedLineLength+1), skipMinified},
+		"large.txt":     {strings.Repeat("line\n", 1000), skipTooLarge},
+		"long_tail.txt": {"short\n" + strings.Repeat("b", 2*minifiedLineLength), skipMinified},
+	}
+	for name, f := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(f.content), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if got := contentSkipReason(path, limits); got != f.want {
+			t.Errorf("contentSkipReason(%s) = %q, want %q", name, got, f.want)
+		}
+	}
+
+	// Directives can turn the minified and size checks off
+	relaxed := workspace.ContentLimits{}
+	for _, name := range []string{"bundle.js", "large.txt", "app.min.js"} {
+		if got := contentSkipReason(filepath.Join(dir, name), relaxed); got != "" {
+			t.Errorf("Expected %s to be searched without limits, got %q", name, got)
+		}
+	}
+}
+
+func TestSearchFiles_ReportsSkipped(t *testing.T) {
+	_, tool := newSearchWorkspace(t, map[string]string{
+		".forgeignore":   "@max-size 2KB\n",
+		"main.go":        "needle\n",
+		"web/app.js":     strings.Repeat("needle;", 160),
+		"data/large.txt": strings.Repeat("needle\n", 500),
+		"img.png":        "needle",
+	})
+
+	out, err := tool.Execute(context.Background(), []byte(`<arguments><pattern>needle</pattern></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(out, "Found 1 matches") {
+		t.Errorf("Expected only main.go to be searched, got:\n%s", out)
+	}
+	for _, want := range []string{"Skipped 3 files", "1 minified", "1 larger than 2.0 KB", "1 binary", "data/large.txt", "web/app.js"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "img.png") {
+		t.Errorf("Expected binary files to be counted, not named:\n%s", out)
+	}
+
+	out, err = tool.Execute(context.Background(), []byte(`<arguments><pattern>needle</pattern><output_format>json</output_format></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var result searchFilesJSONResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	if len(result.Skipped) != 3 || result.Skipped[0].File != "data/large.txt" || result.Skipped[0].Reason != "larger than 2.0 KB" {
+		t.Errorf("Unexpected skipped files: %+v", result.Skipped)
+	}
+}
+
+func TestListFiles_AnnotatesSkipped(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"app.min.js": "var a=1;",
+		"main.go":    "package main\n",
+	})
+
+	out, err := NewListFilesTool(ws.Guard()).Execute(context.Background(), []byte(`<arguments></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(out, "app.min.js (8 B, minified, not searched)") || !strings.Contains(out, "main.go (13 B)\n") {
+		t.Errorf("Unexpected listing:\n%s", out)
+	}
+}
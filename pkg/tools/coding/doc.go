@@ -2,11 +2,17 @@
 //
 // This package implements the core coding tools used by the Forge TUI agent:
 //   - ReadFileTool: Read file contents with optional line ranges
+//   - RequestContextTool: Fetch several files and globs at once within a token budget
 //   - WriteFileTool: Create or overwrite files with validation
 //   - ListFilesTool: List directory contents with optional recursion
 //   - SearchFilesTool: Search files using regex patterns
 //   - ApplyDiffTool: Apply targeted edits using search/replace
+//   - ChangedPackagesTool: List the packages changed in a git range and their missing docs
+//   - GenerateDocsTool: Update doc comments and READMEs of changed packages only
+//   - CheckLicensesTool: Inventory dependency licenses and find files missing the license header
+//   - InsertLicenseHeadersTool: Insert the configured license header where it is missing
 //   - ExecuteCommandTool: Execute terminal commands with approval
+//   - GetRecentCommandsTool: List the commands run this session with their exit codes
 //
 // All tools enforce workspace-level security through the WorkspaceGuard,
 // preventing access to files outside the designated workspace directory.
@@ -14,4 +20,11 @@
 // Tools are designed to be reusable across different executors (TUI, CLI, API)
 // and integrate with the agent's event system for streaming updates and
 // approval workflows.
+//
+// Every tool accepts an optional output_format argument. The default "text"
+// format is tuned for the model; "json" returns a structured result for
+// machine consumers. Executors can change the default via OutputFormatKey.
+//
+// Long text results from read_file, list_files and search_files are paged via
+// tools.PaginateResult; the model fetches later pages with get_more.
 package coding
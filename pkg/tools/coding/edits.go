@@ -0,0 +1,287 @@
+package coding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// maxRegionTokens bounds the file excerpt of a SearchNotFoundError,
+	// estimated at 4 bytes per token
+	maxRegionTokens = 1000
+
+	// regionContext is how many lines around the closest match a
+	// SearchNotFoundError shows
+	regionContext = 3
+)
+
+// diffEdit is one search/replace operation of an apply_diff call
+type diffEdit struct {
+	Search  string `xml:"search"`
+	Replace string `xml:"replace"`
+}
+
+// ConflictingEdit is one side of an EditConflict
+type ConflictingEdit struct {
+	Index     int // 1-based position in the call
+	StartLine int // First line of the file the search text covers
+	EndLine   int // Last line of the file the search text covers
+	Search    string
+	Replace   string
+}
+
+// EditConflict reports two edits in one apply_diff call that change
+// overlapping text in a way that can't be combined safely. No edit of the
+// call is applied.
+type EditConflict struct {
+	First  ConflictingEdit // The edit that comes first in the call
+	Second ConflictingEdit
+	Reason string
+}
+
+// Error describes the conflict with both edits, so the model can combine them.
+func (c *EditConflict) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "edits %d and %d conflict: %s. No edits were applied.\n", c.First.Index, c.Second.Index, c.Reason)
+	for _, edit := range []ConflictingEdit{c.First, c.Second} {
+		fmt.Fprintf(&b, "\nedit %d (lines %d-%d):\n<<<<<<< search\n%s\n=======\n%s\n>>>>>>> replace\n",
+			edit.Index, edit.StartLine, edit.EndLine, edit.Search, edit.Replace)
+	}
+	b.WriteString("\nCombine the two into one edit, or choose search texts that don't overlap.")
+	return b.String()
+}
+
+// plannedEdit is an edit located in the original content
+type plannedEdit struct {
+	index      int // 0-based position in the call
+	start, end int // Byte range of the search text
+	replace    string
+}
+
+// applyEdits applies search/replace edits to content. Every search text is
+// located in the original content rather than in the output of the edits
+// before it, so edits can't match text another edit just inserted, and
+// their order in the call doesn't matter. Overlapping edits are combined
+// when that is unambiguous:
+//
+//   - an edit whose search text is not in the file but appears once in an
+//     earlier edit's replacement is applied to that replacement
+//   - an edit inside an earlier edit's search text is applied to that edit's
+//     replacement, if the replacement still contains it exactly once
+//   - an exact duplicate of an earlier edit is ignored
+//
+// Any other overlap returns an *EditConflict.
+func applyEdits(content string, edits []diffEdit) (string, error) {
+	var planned []*plannedEdit
+	for i, edit := range edits {
+		if edit.Search == "" {
+			return "", fmt.Errorf("edit %d: search text cannot be empty", i+1)
+		}
+
+		count := strings.Count(content, edit.Search)
+		if count > 1 {
+			return "", fmt.Errorf("edit %d: search text appears %d times in file, must be unique", i+1, count)
+		}
+		if count == 0 {
+			host, err := replacementContaining(content, planned, edit, i)
+			if err != nil {
+				return "", err
+			}
+			host.replace = strings.Replace(host.replace, edit.Search, edit.Replace, 1)
+			continue
+		}
+
+		start := strings.Index(content, edit.Search)
+		next := &plannedEdit{index: i, start: start, end: start + len(edit.Search), replace: edit.Replace}
+		merged, err := mergeOverlap(content, edits, planned, next)
+		if err != nil {
+			return "", err
+		}
+		if !merged {
+			planned = append(planned, next)
+		}
+	}
+
+	sort.Slice(planned, func(i, j int) bool { return planned[i].start < planned[j].start })
+
+	var b strings.Builder
+	offset := 0
+	for _, p := range planned {
+		b.WriteString(content[offset:p.start])
+		b.WriteString(p.replace)
+		offset = p.end
+	}
+	b.WriteString(content[offset:])
+	return b.String(), nil
+}
+
+// replacementContaining returns the earlier edit whose replacement contains
+// the search text of edit i exactly once
+func replacementContaining(content string, planned []*plannedEdit, edit diffEdit, i int) (*plannedEdit, error) {
+	var host *plannedEdit
+	for _, p := range planned {
+		switch strings.Count(p.replace, edit.Search) {
+		case 0:
+			continue
+		case 1:
+			if host != nil {
+				return nil, fmt.Errorf("edit %d: search text is not in the file and appears in the replacements of edits %d and %d, must be unique", i+1, host.index+1, p.index+1)
+			}
+			host = p
+		default:
+			return nil, fmt.Errorf("edit %d: search text is not in the file and appears more than once in the replacement of edit %d, must be unique", i+1, p.index+1)
+		}
+	}
+	if host == nil {
+		return nil, &SearchNotFoundError{Index: i + 1, Search: edit.Search, Region: closestRegion(content, edit.Search)}
+	}
+	return host, nil
+}
+
+// mergeOverlap checks next against the edits planned so far. It reports
+// whether next was combined into one of them, or a conflict if it overlaps
+// one in a way that can't be combined.
+func mergeOverlap(content string, edits []diffEdit, planned []*plannedEdit, next *plannedEdit) (bool, error) {
+	for _, p := range planned {
+		if next.end <= p.start || p.end <= next.start {
+			continue
+		}
+
+		search := edits[next.index].Search
+		switch {
+		case next.start == p.start && next.end == p.end && next.replace == edits[p.index].Replace:
+			// An exact duplicate
+			return true, nil
+		case next.start == p.start && next.end == p.end:
+			return false, conflict(content, edits, p, next, "both replace the same text differently")
+		case p.start <= next.start && next.end <= p.end:
+			if strings.Count(p.replace, search) != 1 {
+				return false, conflict(content, edits, p, next, fmt.Sprintf("edit %d changes text inside edit %d's search text, which edit %d's replacement does not keep exactly once", next.index+1, p.index+1, p.index+1))
+			}
+			p.replace = strings.Replace(p.replace, search, next.replace, 1)
+			return true, nil
+		case next.start <= p.start && p.end <= next.end:
+			return false, conflict(content, edits, p, next, fmt.Sprintf("edit %d changes text inside edit %d's search text", p.index+1, next.index+1))
+		default:
+			return false, conflict(content, edits, p, next, "their search texts partially overlap")
+		}
+	}
+	return false, nil
+}
+
+// conflict builds an EditConflict between two planned edits
+func conflict(content string, edits []diffEdit, first, second *plannedEdit, reason string) *EditConflict {
+	side := func(p *plannedEdit) ConflictingEdit {
+		return ConflictingEdit{
+			Index:     p.index + 1,
+			StartLine: strings.Count(content[:p.start], "\n") + 1,
+			EndLine:   strings.Count(content[:p.end-1], "\n") + 1,
+			Search:    edits[p.index].Search,
+			Replace:   edits[p.index].Replace,
+		}
+	}
+	return &EditConflict{First: side(first), Second: side(second), Reason: reason}
+}
+
+// FileRegion is a numbered range of lines of a file as it is now
+type FileRegion struct {
+	StartLine int
+	EndLine   int
+	Content   string // The lines, each prefixed with its number like read_file
+	Matched   bool   // Whether any line of the search text is in the region
+}
+
+// SearchNotFoundError reports an apply_diff edit whose search text is not in
+// the file. It carries the lines of the file closest to the search text as
+// they are now, so the model can fix the search text without reading the
+// file again. It wraps types.ErrSearchNotFound.
+type SearchNotFoundError struct {
+	Index  int // 1-based position in the call
+	Search string
+	Path   string // Set by the tool; empty from applyEdits
+	Region FileRegion
+}
+
+// Error describes the missing search text with the closest region of the file.
+func (e *SearchNotFoundError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "edit %d: search text not found in file:\n%s", e.Index, e.Search)
+	if e.Region.Content == "" {
+		return b.String()
+	}
+
+	file := "the file"
+	if e.Path != "" {
+		file = e.Path
+	}
+	closeness := "closest to the search text"
+	if !e.Region.Matched {
+		closeness = "no line of the search text is in the file"
+	}
+	fmt.Fprintf(&b, "\n\nLines %d-%d of %s as they are now (%s):\n%s",
+		e.Region.StartLine, e.Region.EndLine, file, closeness, e.Region.Content)
+	return b.String()
+}
+
+// Unwrap lets callers check for types.ErrSearchNotFound with errors.Is.
+func (e *SearchNotFoundError) Unwrap() error {
+	return types.ErrSearchNotFound
+}
+
+// closestRegion returns the lines of content most like search: the window of
+// as many lines as search that shares the most lines with it, ignoring
+// indentation, plus a few lines of context. Files with no line in common
+// with search get their first lines. The region is cut to maxRegionTokens.
+func closestRegion(content, search string) FileRegion {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	searchLines := strings.Split(strings.TrimSuffix(search, "\n"), "\n")
+
+	wanted := make(map[string]bool, len(searchLines))
+	for _, line := range searchLines {
+		if line = strings.TrimSpace(line); line != "" {
+			wanted[line] = true
+		}
+	}
+
+	// hits[i] counts the lines before line i that appear in search
+	hits := make([]int, len(lines)+1)
+	for i, line := range lines {
+		hits[i+1] = hits[i]
+		if wanted[strings.TrimSpace(line)] {
+			hits[i+1]++
+		}
+	}
+
+	window := min(len(searchLines), len(lines))
+	best, bestHits := 0, 0
+	for start := 0; start+window <= len(lines); start++ {
+		if n := hits[start+window] - hits[start]; n > bestHits {
+			best, bestHits = start, n
+		}
+	}
+
+	first := max(best-regionContext, 0)
+	last := min(best+window+regionContext, len(lines))
+	if bestHits == 0 {
+		first, last = 0, min(window+regionContext, len(lines))
+	}
+
+	var b strings.Builder
+	end := first
+	for ; end < last; end++ {
+		line := fmt.Sprintf("%d | %s\n", end+1, lines[end])
+		if end > first && b.Len()+len(line) > maxRegionTokens*4 {
+			break
+		}
+		b.WriteString(line)
+	}
+	return FileRegion{
+		StartLine: first + 1,
+		EndLine:   end,
+		Content:   strings.TrimSuffix(b.String(), "\n"),
+		Matched:   bestHits > 0,
+	}
+}
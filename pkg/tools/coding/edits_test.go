@@ -0,0 +1,237 @@
+package coding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const editsContent = `package main
+
+func greet() string {
+	return "hello"
+}
+
+func main() {
+	println(greet())
+}
+`
+
+func TestApplyEdits(t *testing.T) {
+	tests := []struct {
+		name  string
+		edits []diffEdit
+		want  string
+	}{
+		{
+			name: "independent edits in any order",
+			edits: []diffEdit{
+				{Search: "println(greet())", Replace: "println(greet(), 1)"},
+				{Search: `return "hello"`, Replace: `return "hi"`},
+			},
+			want: strings.NewReplacer("println(greet())", "println(greet(), 1)", `"hello"`, `"hi"`).Replace(editsContent),
+		},
+		{
+			name: "edit nested in an earlier edit",
+			edits: []diffEdit{
+				{Search: "func greet() string {\n\treturn \"hello\"\n}", Replace: "// greet says hello\nfunc greet() string {\n\treturn \"hello\"\n}"},
+				{Search: `return "hello"`, Replace: `return "hi"`},
+			},
+			want: strings.Replace(editsContent, "func greet() string {\n\treturn \"hello\"", "// greet says hello\nfunc greet() string {\n\treturn \"hi\"", 1),
+		},
+		{
+			name: "edit of text an earlier edit inserted",
+			edits: []diffEdit{
+				{Search: "func main() {", Replace: "func helper() {}\n\nfunc main() {"},
+				{Search: "func helper() {}", Replace: "func helper() int { return 1 }"},
+			},
+			want: strings.Replace(editsContent, "func main() {", "func helper() int { return 1 }\n\nfunc main() {", 1),
+		},
+		{
+			name: "duplicate edit",
+			edits: []diffEdit{
+				{Search: `return "hello"`, Replace: `return "hi"`},
+				{Search: `return "hello"`, Replace: `return "hi"`},
+			},
+			want: strings.Replace(editsContent, `"hello"`, `"hi"`, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyEdits(editsContent, tt.edits)
+			if err != nil {
+				t.Fatalf("applyEdits failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("applyEdits() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEdits_Conflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		edits  []diffEdit
+		reason string
+	}{
+		{
+			name: "same text replaced differently",
+			edits: []diffEdit{
+				{Search: `return "hello"`, Replace: `return "hi"`},
+				{Search: `return "hello"`, Replace: `return "hey"`},
+			},
+			reason: "both replace the same text differently",
+		},
+		{
+			name: "nested edit whose text the outer edit removes",
+			edits: []diffEdit{
+				{Search: "func greet() string {\n\treturn \"hello\"\n}", Replace: "func greet() string { return \"hi\" }"},
+				{Search: `return "hello"`, Replace: `return "hey"`},
+			},
+			reason: "edit 2 changes text inside edit 1's search text",
+		},
+		{
+			name: "later edit contains an earlier one",
+			edits: []diffEdit{
+				{Search: `return "hello"`, Replace: `return "hi"`},
+				{Search: "func greet() string {\n\treturn \"hello\"\n}", Replace: "func greet() string { return \"hey\" }"},
+			},
+			reason: "edit 1 changes text inside edit 2's search text",
+		},
+		{
+			name: "partial overlap",
+			edits: []diffEdit{
+				{Search: "\treturn \"hello\"\n}\n\nfunc main", Replace: "\treturn \"hi\"\n}\n\nfunc main"},
+				{Search: "}\n\nfunc main() {\n\tprintln", Replace: "}\n\nfunc main() {\n\tprint"},
+			},
+			reason: "their search texts partially overlap",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyEdits(editsContent, tt.edits)
+			var conflict *EditConflict
+			if !errors.As(err, &conflict) {
+				t.Fatalf("expected an EditConflict, got %v", err)
+			}
+			if conflict.First.Index != 1 || conflict.Second.Index != 2 {
+				t.Errorf("expected edits 1 and 2, got %d and %d", conflict.First.Index, conflict.Second.Index)
+			}
+			if !strings.HasPrefix(conflict.Reason, tt.reason) {
+				t.Errorf("reason = %q, want prefix %q", conflict.Reason, tt.reason)
+			}
+			if !strings.Contains(err.Error(), tt.edits[1].Search) || !strings.Contains(err.Error(), tt.edits[0].Replace) {
+				t.Errorf("expected both edits in the error:\n%s", err)
+			}
+		})
+	}
+}
+
+func TestApplyEdits_ConflictLines(t *testing.T) {
+	_, err := applyEdits(editsContent, []diffEdit{
+		{Search: "func greet() string {\n\treturn \"hello\"\n}", Replace: "func greet() {}"},
+		{Search: `return "hello"`, Replace: `return "hi"`},
+	})
+	var conflict *EditConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected an EditConflict, got %v", err)
+	}
+	if conflict.First.StartLine != 3 || conflict.First.EndLine != 5 {
+		t.Errorf("first edit covers lines %d-%d, want 3-5", conflict.First.StartLine, conflict.First.EndLine)
+	}
+	if conflict.Second.StartLine != 4 || conflict.Second.EndLine != 4 {
+		t.Errorf("second edit covers lines %d-%d, want 4-4", conflict.Second.StartLine, conflict.Second.EndLine)
+	}
+}
+
+func TestApplyEdits_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		edits []diffEdit
+		want  string
+	}{
+		{"empty search", []diffEdit{{Search: "", Replace: "x"}}, "edit 1: search text cannot be empty"},
+		{"not found", []diffEdit{{Search: "missing", Replace: "x"}}, "edit 1: search text not found in file"},
+		{"not unique", []diffEdit{{Search: "greet()", Replace: "x"}}, "edit 1: search text appears 2 times in file, must be unique"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyEdits(editsContent, tt.edits)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("expected error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestApplyEdits_SearchNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		region FileRegion
+	}{
+		{
+			name:   "stale indentation and text",
+			search: "func greet() string {\n    return \"hi\"\n}",
+			region: FileRegion{
+				StartLine: 1,
+				EndLine:   8,
+				Content:   "1 | package main\n2 | \n3 | func greet() string {\n4 | \treturn \"hello\"\n5 | }\n6 | \n7 | func main() {\n8 | \tprintln(greet())",
+				Matched:   true,
+			},
+		},
+		{
+			name:   "nothing in common",
+			search: "type server struct{}",
+			region: FileRegion{StartLine: 1, EndLine: 4, Content: "1 | package main\n2 | \n3 | func greet() string {\n4 | \treturn \"hello\"", Matched: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyEdits(editsContent, []diffEdit{{Search: tt.search, Replace: "x"}})
+			var notFound *SearchNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("expected a SearchNotFoundError, got %v", err)
+			}
+			if !errors.Is(err, types.ErrSearchNotFound) {
+				t.Error("expected the error to wrap types.ErrSearchNotFound")
+			}
+			if notFound.Region != tt.region {
+				t.Errorf("region = %+v, want %+v", notFound.Region, tt.region)
+			}
+		})
+	}
+}
+
+func TestClosestRegion_Budget(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "line %d of a long generated file\n", i)
+	}
+	search := "line 1000 of a long generated file\nline 1001 of a long generated file"
+
+	region := closestRegion(b.String(), search)
+	if region.StartLine != 998 {
+		t.Errorf("region starts at line %d, want 998", region.StartLine)
+	}
+	if !strings.Contains(region.Content, "1001 | line 1000 of") {
+		t.Errorf("expected the region to contain the closest line, got:\n%s", region.Content)
+	}
+
+	long := strings.Repeat("x", 300) + "\n"
+	region = closestRegion(strings.Repeat(long, 100), strings.Repeat(long, 50))
+	if len(region.Content) > maxRegionTokens*4 {
+		t.Errorf("region is %d bytes, want at most %d", len(region.Content), maxRegionTokens*4)
+	}
+	if region.EndLine-region.StartLine+1 != strings.Count(region.Content, "\n")+1 {
+		t.Errorf("lines %d-%d don't match the content", region.StartLine, region.EndLine)
+	}
+}
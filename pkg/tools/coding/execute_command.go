@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
@@ -56,6 +57,7 @@ func (t *ExecuteCommandTool) Schema() map[string]interface{} {
 				"type":        "string",
 				"description": "Working directory relative to workspace (default: workspace root)",
 			},
+			"output_format": outputFormatProperty(),
 		},
 		[]string{"command"},
 	)
@@ -66,10 +68,11 @@ func (t *ExecuteCommandTool) Schema() map[string]interface{} {
 //nolint:gocyclo // Complexity is acceptable for command execution logic
 func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
 	var input struct {
-		XMLName    xml.Name `xml:"arguments"`
-		Command    string   `xml:"command"`
-		Timeout    float64  `xml:"timeout"`
-		WorkingDir string   `xml:"working_dir"`
+		XMLName      xml.Name `xml:"arguments"`
+		Command      string   `xml:"command"`
+		Timeout      float64  `xml:"timeout"`
+		WorkingDir   string   `xml:"working_dir"`
+		OutputFormat string   `xml:"output_format"`
 	}
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
 		return "", fmt.Errorf("failed to parse input: %w", err)
@@ -80,6 +83,11 @@ func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (strin
 		return "", fmt.Errorf("command cannot be empty")
 	}
 
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return "", err
+	}
+
 	// Determine timeout
 	timeout := t.defaultTimeout
 	if input.Timeout > 0 {
@@ -158,6 +166,17 @@ func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (strin
 		}
 	}
 
+	if format == OutputFormatJSON {
+		return marshalJSONResult(executeCommandJSONResult{
+			Command:    input.Command,
+			Status:     commandStatus(execErr, execCtx.Err()),
+			ExitCode:   exitCode,
+			Stdout:     stdout,
+			Stderr:     stderr,
+			DurationMs: duration.Milliseconds(),
+		})
+	}
+
 	// Format response
 	var result string
 	if execErr != nil {
@@ -187,6 +206,31 @@ func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (strin
 	return result, nil
 }
 
+// executeCommandJSONResult is the structured execute_command result for output_format=json.
+type executeCommandJSONResult struct {
+	Command    string `json:"command"`
+	Status     string `json:"status"` // completed, failed, canceled or timed_out
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// commandStatus classifies a command's outcome for structured results.
+func commandStatus(execErr, ctxErr error) string {
+	if execErr == nil {
+		return "completed"
+	}
+	switch ctxErr {
+	case context.Canceled:
+		return "canceled"
+	case context.DeadlineExceeded:
+		return "timed_out"
+	default:
+		return "failed"
+	}
+}
+
 // runCommand executes the command and captures output
 func (t *ExecuteCommandTool) runCommand(cmd *exec.Cmd) (stdout, stderr string, exitCode int, err error) {
 	stdoutBytes, stderrBytes, err := t.captureOutput(cmd)
@@ -195,7 +239,8 @@ func (t *ExecuteCommandTool) runCommand(cmd *exec.Cmd) (stdout, stderr string, e
 
 	// Get exit code
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
 			exitCode = exitErr.ExitCode()
 		} else {
 			// Command failed to start or other error
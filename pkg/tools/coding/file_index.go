@@ -0,0 +1,122 @@
+package coding
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// racyModTime is how recently a directory may have changed before its
+// cached listing is distrusted. Filesystems record modification times at a
+// coarse granularity, so a file created in the same tick as the directory
+// was read would otherwise go unnoticed.
+const racyModTime = 2 * time.Second
+
+// fileIndex caches the workspace's files for searches, so each search does
+// not walk the whole tree again. Directory listings are kept with the
+// directory's modification time, which changes when files are created,
+// deleted or renamed in it; a lookup re-reads only directories that changed.
+type fileIndex struct {
+	guard *workspace.Guard
+
+	mu   sync.Mutex
+	dirs map[string]*indexedDir
+}
+
+// indexedDir is the cached listing of a directory, without ignored entries
+type indexedDir struct {
+	modTime time.Time
+	readAt  time.Time
+	entries []indexedEntry
+}
+
+// indexedEntry is a file or subdirectory in a cached listing
+type indexedEntry struct {
+	path  string
+	isDir bool
+}
+
+// newFileIndex creates an empty index of the guard's workspace
+func newFileIndex(guard *workspace.Guard) *fileIndex {
+	return &fileIndex{
+		guard: guard,
+		dirs:  make(map[string]*indexedDir),
+	}
+}
+
+// files returns the files under root that are in the workspace and not
+// ignored, in lexical order
+func (idx *fileIndex) files(ctx context.Context, root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !idx.guard.IsWithinWorkspace(root) || idx.guard.ShouldIgnore(root) {
+		return nil, nil
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var files []string
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, entry := range idx.listing(dir) {
+			if !entry.isDir {
+				files = append(files, entry.path)
+				continue
+			}
+			if err := walk(entry.path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// listing returns the cached entries of dir, reading it again if it changed.
+// Must be called with the lock held.
+func (idx *fileIndex) listing(dir string) []indexedEntry {
+	info, err := os.Stat(dir)
+	if err != nil {
+		delete(idx.dirs, dir)
+		return nil // Skip directories that went away or can't be read
+	}
+
+	cached, ok := idx.dirs[dir]
+	if ok && cached.modTime.Equal(info.ModTime()) && info.ModTime().Before(cached.readAt.Add(-racyModTime)) {
+		return cached.entries
+	}
+
+	readAt := time.Now()
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		delete(idx.dirs, dir)
+		return nil
+	}
+
+	entries := make([]indexedEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		path := filepath.Join(dir, dirEntry.Name())
+		if !idx.guard.IsWithinWorkspace(path) || idx.guard.ShouldIgnore(path) {
+			continue
+		}
+		entries = append(entries, indexedEntry{path: path, isDir: dirEntry.IsDir()})
+	}
+	idx.dirs[dir] = &indexedDir{modTime: info.ModTime(), readAt: readAt, entries: entries}
+	return entries
+}
@@ -0,0 +1,425 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// docUpdate is one file of a generate_docs call: search/replace edits to an
+// existing file, or the content of a new README
+type docUpdate struct {
+	Path    string     `xml:"path"`
+	Edits   []diffEdit `xml:"edits>edit"`
+	Content string     `xml:"content"`
+}
+
+// docChange is a docUpdate resolved against the file on disk
+type docChange struct {
+	absPath  string
+	relPath  string // Slash-separated, relative to the workspace
+	original string
+	updated  string
+}
+
+// GenerateDocsPrompt is the task of updating the documentation of the
+// packages changed in rangeSpec with changed_packages and generate_docs; an
+// empty rangeSpec uses the tools' default range
+func GenerateDocsPrompt(rangeSpec string) string {
+	scope := "the packages changed on this branch (changed_packages' default range)"
+	if rangeSpec != "" {
+		scope = fmt.Sprintf("the packages changed in %s (pass range %s to both tools)", rangeSpec, rangeSpec)
+	}
+	return fmt.Sprintf("Update the documentation of %s only. Call changed_packages to list them and what they lack, "+
+		"read the changed code, then call generate_docs once with every update: doc comments for undocumented "+
+		"or outdated exported identifiers, a package comment where one is missing, and README sections that no "+
+		"longer match the code. Follow the style of the existing docs, and don't touch packages that didn't change.", scope)
+}
+
+// GenerateDocsTool writes documentation for the packages changed in a git
+// diff range: doc comments in their source files and their READMEs. Files
+// outside the changed packages are refused, and edits to Go files may only
+// change comments. All files are previewed as one diff and written together.
+type GenerateDocsTool struct {
+	guard *workspace.Guard
+}
+
+// NewGenerateDocsTool creates a new GenerateDocsTool with workspace security.
+func NewGenerateDocsTool(guard *workspace.Guard) *GenerateDocsTool {
+	return &GenerateDocsTool{
+		guard: guard,
+	}
+}
+
+// Name returns the tool name.
+func (t *GenerateDocsTool) Name() string {
+	return "generate_docs"
+}
+
+// Description returns the tool description.
+func (t *GenerateDocsTool) Description() string {
+	return "Create or update documentation for the packages changed in a git diff range, in one call: doc comments in their source files (as search/replace edits) and README sections (as edits, or content for a new README). Only files in packages listed by changed_packages for the same range are accepted, and edits to Go files may only change comments. Every file is previewed as one diff for approval and written together."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *GenerateDocsTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"range": map[string]interface{}{
+				"type":        "string",
+				"description": "Git diff range the changed packages are taken from; use the one given to changed_packages (default: the working tree against the merge base with main, master or develop)",
+			},
+			"docs": map[string]interface{}{
+				"type":        "array",
+				"description": "Documentation updates, one per file",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Source file or README in a changed package (relative to workspace)",
+						},
+						"edits": map[string]interface{}{
+							"type":        "array",
+							"description": "Search/replace operations for an existing file, as for apply_diff",
+							"items": map[string]interface{}{
+								"type": "object",
+								"properties": map[string]interface{}{
+									"search":  map[string]interface{}{"type": "string"},
+									"replace": map[string]interface{}{"type": "string"},
+								},
+								"required": []string{"search", "replace"},
+							},
+						},
+						"content": map[string]interface{}{
+							"type":        "string",
+							"description": "Content of a README that doesn't exist yet",
+						},
+					},
+					"required": []string{"path"},
+				},
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{"docs"},
+	)
+}
+
+// Execute writes the documentation updates.
+func (t *GenerateDocsTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult writes the documentation updates, returning a diff artifact
+// per file. Every update is checked before any file is written.
+func (t *GenerateDocsTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	var input struct {
+		XMLName      xml.Name    `xml:"arguments"`
+		Range        string      `xml:"range"`
+		Docs         []docUpdate `xml:"docs>doc"`
+		OutputFormat string      `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := t.resolve(ctx, input.Range, input.Docs)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	var artifacts []tools.Artifact
+	for _, change := range changes {
+		if _, err := writeFileAtomic(ctx, change.absPath, strings.NewReader(change.updated)); err != nil {
+			if len(written) > 0 {
+				return nil, fmt.Errorf("%w (already written: %s)", err, strings.Join(written, ", "))
+			}
+			return nil, err
+		}
+		written = append(written, change.relPath)
+		artifacts = append(artifacts, tools.Artifact{
+			Kind: tools.ArtifactDiff,
+			Path: change.relPath,
+			Diff: GenerateUnifiedDiff(change.original, change.updated, change.relPath),
+		})
+	}
+
+	packages := make(map[string]bool)
+	for _, change := range changes {
+		packages[path.Dir(change.relPath)] = true
+	}
+	summary := fmt.Sprintf("Updated docs in %d file(s) across %d package(s)", len(written), len(packages))
+	return newResult(format,
+		fmt.Sprintf("%s: %s", summary, strings.Join(written, ", ")),
+		summary,
+		generateDocsJSONResult{Files: written, Packages: len(packages)},
+		artifacts...)
+}
+
+// generateDocsJSONResult is the structured generate_docs result for output_format=json.
+type generateDocsJSONResult struct {
+	Files    []string `json:"files"`
+	Packages int      `json:"packages"`
+}
+
+// IsLoopBreaking returns whether this tool should break the agent loop.
+func (t *GenerateDocsTool) IsLoopBreaking() bool {
+	return false
+}
+
+// XMLExample provides a concrete XML usage example for this tool.
+func (t *GenerateDocsTool) XMLExample() string {
+	return `<tool>
+<server_name>local</server_name>
+<tool_name>generate_docs</tool_name>
+<arguments>
+  <docs>
+    <doc>
+      <path>pkg/cache/cache.go</path>
+      <edits>
+        <edit>
+          <search><![CDATA[func Evict(key string) {]]></search>
+          <replace><![CDATA[// Evict removes key from the cache; evicting a missing key does nothing.
+func Evict(key string) {]]></replace>
+        </edit>
+      </edits>
+    </doc>
+    <doc>
+      <path>pkg/cache/README.md</path>
+      <content><![CDATA[# cache
+
+An in-memory LRU cache for API responses.
+]]></content>
+    </doc>
+  </docs>
+</arguments>
+</tool>`
+}
+
+// GeneratePreview implements the Previewable interface to show every file's
+// changes as one diff.
+func (t *GenerateDocsTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	var input struct {
+		XMLName xml.Name    `xml:"arguments"`
+		Range   string      `xml:"range"`
+		Docs    []docUpdate `xml:"docs>doc"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	changes, err := t.resolve(ctx, input.Range, input.Docs)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff strings.Builder
+	paths := make([]string, len(changes))
+	packages := make(map[string]bool)
+	for i, change := range changes {
+		diff.WriteString(GenerateUnifiedDiff(change.original, change.updated, change.relPath))
+		paths[i] = change.relPath
+		packages[path.Dir(change.relPath)] = true
+	}
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeDiff,
+		Title:       fmt.Sprintf("Update docs in %d file(s) across %d package(s)", len(changes), len(packages)),
+		Description: fmt.Sprintf("This will update the documentation in %s", strings.Join(paths, ", ")),
+		Content:     diff.String(),
+		Metadata: map[string]interface{}{
+			"file_path":  paths[0],
+			"language":   "diff",
+			"file_paths": paths,
+		},
+	}, nil
+}
+
+// resolve checks every update against the packages changed in rangeSpec and
+// applies it to the file's content in memory. Files that would not change
+// are left out; an update that changes nothing at all is an error.
+func (t *GenerateDocsTool) resolve(ctx context.Context, rangeSpec string, docs []docUpdate) ([]docChange, error) {
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("at least one doc update is required")
+	}
+
+	rangeSpec, packages, err := findChangedPackages(ctx, t.guard, strings.TrimSpace(rangeSpec))
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]bool, len(packages))
+	var dirs []string
+	for _, pkg := range packages {
+		if !pkg.Deleted {
+			changed[pkg.Dir] = true
+			dirs = append(dirs, pkg.Dir)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var changes []docChange
+	for _, doc := range docs {
+		change, err := t.resolveOne(doc, changed, dirs, rangeSpec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[change.relPath] {
+			return nil, fmt.Errorf("%s is updated more than once; combine its edits into one doc", change.relPath)
+		}
+		seen[change.relPath] = true
+		if change.updated != change.original {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("the updates change no file")
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].relPath < changes[j].relPath })
+	return changes, nil
+}
+
+// resolveOne checks one update and applies it in memory
+func (t *GenerateDocsTool) resolveOne(doc docUpdate, changed map[string]bool, dirs []string, rangeSpec string) (docChange, error) {
+	if doc.Path == "" {
+		return docChange{}, fmt.Errorf("path is required")
+	}
+	if err := t.guard.ValidatePath(doc.Path); err != nil {
+		return docChange{}, fmt.Errorf("invalid path: %w", err)
+	}
+	absPath, err := t.guard.ResolvePath(doc.Path)
+	if err != nil {
+		return docChange{}, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	relPath, err := t.guard.MakeRelative(absPath)
+	if err != nil {
+		return docChange{}, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	if !changed[path.Dir(relPath)] {
+		if len(dirs) == 0 {
+			return docChange{}, fmt.Errorf("%s: no source files changed in %s", relPath, rangeSpec)
+		}
+		return docChange{}, fmt.Errorf("%s is not in a package changed in %s; changed packages: %s", relPath, rangeSpec, strings.Join(dirs, ", "))
+	}
+	readme := isReadme(path.Base(relPath))
+	if !readme && nonSourceLanguages[detectLanguage(relPath)] {
+		return docChange{}, fmt.Errorf("%s is neither a source file nor a README", relPath)
+	}
+
+	change := docChange{absPath: absPath, relPath: relPath}
+	content, err := os.ReadFile(absPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if !readme {
+			return docChange{}, fmt.Errorf("%s does not exist; only a README can be created", relPath)
+		}
+		if len(doc.Edits) > 0 || strings.TrimSpace(doc.Content) == "" {
+			return docChange{}, fmt.Errorf("%s does not exist; give its content instead of edits", relPath)
+		}
+		change.updated = doc.Content
+		return change, nil
+	case err != nil:
+		return docChange{}, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+
+	if doc.Content != "" || len(doc.Edits) == 0 {
+		return docChange{}, fmt.Errorf("%s exists; give search/replace edits instead of content", relPath)
+	}
+	change.original = string(content)
+	change.updated, err = applyEdits(change.original, doc.Edits)
+	if err != nil {
+		var notFound *SearchNotFoundError
+		if errors.As(err, &notFound) {
+			notFound.Path = relPath
+		}
+		return docChange{}, fmt.Errorf("%s: %w", relPath, err)
+	}
+
+	if strings.HasSuffix(relPath, ".go") {
+		if err := checkCommentsOnly(change.original, change.updated); err != nil {
+			return docChange{}, fmt.Errorf("%s: %w", relPath, err)
+		}
+	}
+	return change, nil
+}
+
+// checkCommentsOnly returns an error unless updated differs from original Go
+// source in comments and whitespace only
+func checkCommentsOnly(original, updated string) error {
+	before, err := goCodeTokens(original)
+	if err != nil {
+		return fmt.Errorf("file does not parse: %w", err)
+	}
+	after, err := goCodeTokens(updated)
+	if err != nil {
+		return fmt.Errorf("edits break the file: %w", err)
+	}
+
+	for i := 0; i < len(before) && i < len(after); i++ {
+		if before[i].tok != after[i].tok || before[i].text != after[i].text {
+			return fmt.Errorf("edits change code, not just comments, at %q (line %d); only doc comments may change", after[i].text, after[i].line)
+		}
+	}
+	if len(before) != len(after) {
+		return fmt.Errorf("edits add or remove code; only doc comments may change")
+	}
+	return nil
+}
+
+// goToken is a Go token compared by checkCommentsOnly
+type goToken struct {
+	tok  token.Token
+	text string // The literal, or the token itself for operators and keywords
+	line int    // Reported in errors only; comments move code between lines
+}
+
+// goCodeTokens returns the tokens of Go source, without comments. Automatic
+// semicolons are kept without their text, since a line comment changes it.
+func goCodeTokens(src string) ([]goToken, error) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var errs scanner.ErrorList
+	var s scanner.Scanner
+	s.Init(file, []byte(src), func(pos token.Position, msg string) { errs.Add(pos, msg) }, 0)
+
+	var tokens []goToken
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch {
+		case tok == token.SEMICOLON:
+			lit = ""
+		case lit == "":
+			lit = tok.String()
+		}
+		tokens = append(tokens, goToken{tok: tok, text: lit, line: fset.Position(pos).Line})
+	}
+	if errs.Len() > 0 {
+		return nil, errs.Err()
+	}
+	return tokens, nil
+}
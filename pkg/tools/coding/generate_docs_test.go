@@ -0,0 +1,156 @@
+package coding
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// docsWorkspace has a committed tree with uncommitted changes to the cache
+// package and a new, untracked api package; store is unchanged
+func docsWorkspace(t *testing.T) *workspacetest.Workspace {
+	return workspacetest.New(t, workspacetest.Tree{
+		"cache/cache.go":   "package cache\n\n// Get returns the value for key\nfunc Get(key string) string { return \"\" }\n",
+		"cache/README.md":  "# cache\n\nGet values.\n",
+		"store/store.go":   "package store\n\nfunc Save() {}\n",
+		"docs/overview.md": "# Overview\n",
+	},
+		workspacetest.WithCommit("initial"),
+		workspacetest.WithChanges(workspacetest.Tree{
+			"cache/cache.go": "package cache\n\n// Get returns the value for key\nfunc Get(key string) string { return \"\" }\n\nfunc Evict(key string) {}\n\ntype Entry struct{}\n\nconst (\n\t// MaxSize bounds the cache\n\tMaxSize = 10\n\tMinSize = 1\n)\n",
+			"api/server.py":  "def serve():\n    pass\n",
+			"docs/guide.md":  "# Guide\n",
+		}))
+}
+
+func TestChangedPackagesTool(t *testing.T) {
+	ws := docsWorkspace(t)
+	result, err := NewChangedPackagesTool(ws.Guard()).Execute(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"2 package(s) changed in ",
+		"api/\n  changed: server.py\n  README: none\n",
+		"cache/\n  changed: cache.go\n  README: README.md\n  package comment: missing\n",
+		"cache.go:6 func Evict",
+		"cache.go:8 type Entry",
+		"cache.go:13 const MinSize",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected result to contain %q, got:\n%s", want, result)
+		}
+	}
+	for _, unwanted := range []string{"store/", "docs/", "func Get", "MaxSize"} {
+		if strings.Contains(result, unwanted) {
+			t.Errorf("expected result not to contain %q, got:\n%s", unwanted, result)
+		}
+	}
+}
+
+func TestGenerateDocsTool(t *testing.T) {
+	ws := docsWorkspace(t)
+	tool := NewGenerateDocsTool(ws.Guard())
+	args := []byte(`<arguments><docs>
+<doc><path>cache/cache.go</path><edits>
+  <edit><search>package cache</search><replace>// Package cache keeps values in memory.
+package cache</replace></edit>
+  <edit><search>func Evict(</search><replace>// Evict removes key
+func Evict(</replace></edit>
+</edits></doc>
+<doc><path>api/README.md</path><content># api
+
+Serves requests.
+</content></doc>
+</docs></arguments>`)
+
+	preview, err := tool.GeneratePreview(context.Background(), args)
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if preview.Type != tools.PreviewTypeDiff || preview.Title != "Update docs in 2 file(s) across 2 package(s)" {
+		t.Errorf("unexpected preview %q (%s)", preview.Title, preview.Type)
+	}
+	for _, want := range []string{"--- api/README.md", "+Serves requests.", "--- cache/cache.go", "+// Evict removes key"} {
+		if !strings.Contains(preview.Content, want) {
+			t.Errorf("expected the combined diff to contain %q, got:\n%s", want, preview.Content)
+		}
+	}
+	ws.AssertMissing("api/README.md")
+
+	result, err := tool.ExecuteResult(context.Background(), args)
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if result.Summary != "Updated docs in 2 file(s) across 2 package(s)" || len(result.Artifacts) != 2 {
+		t.Errorf("unexpected result %q with %d artifact(s)", result.Summary, len(result.Artifacts))
+	}
+	ws.AssertFile("api/README.md", "# api\n\nServes requests.\n")
+	ws.AssertContains("cache/cache.go", "// Package cache keeps values in memory.\npackage cache")
+	ws.AssertContains("cache/cache.go", "// Evict removes key\nfunc Evict(")
+}
+
+func TestGenerateDocsTool_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "unchanged package",
+			doc: `<doc><path>store/store.go</path><edits><edit><search>func Save</search><replace>// Save saves
+func Save</replace></edit></edits></doc>`,
+			want: "store/store.go is not in a package changed in HEAD; changed packages: api, cache",
+		},
+		{
+			name: "code change",
+			doc:  `<doc><path>cache/cache.go</path><edits><edit><search>MinSize = 1</search><replace>MinSize = 2</replace></edit></edits></doc>`,
+			want: `edits change code, not just comments, at "2"`,
+		},
+		{
+			name: "content for existing file",
+			doc:  `<doc><path>cache/README.md</path><content># cache</content></doc>`,
+			want: "cache/README.md exists; give search/replace edits instead of content",
+		},
+		{
+			name: "new source file",
+			doc: `<doc><path>cache/doc.go</path><content>// Package cache
+package cache</content></doc>`,
+			want: "cache/doc.go does not exist; only a README can be created",
+		},
+		{
+			name: "non-source file",
+			doc:  `<doc><path>cache/notes.txt</path><content>notes</content></doc>`,
+			want: "cache/notes.txt is neither a source file nor a README",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := docsWorkspace(t)
+			args := []byte("<arguments><range>HEAD</range><docs>" + tt.doc + "</docs></arguments>")
+			_, err := NewGenerateDocsTool(ws.Guard()).Execute(context.Background(), args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			ws.AssertFile("store/store.go", "package store\n\nfunc Save() {}\n")
+		})
+	}
+}
+
+func TestCheckCommentsOnly(t *testing.T) {
+	original := "package a\n\nfunc F() int { return 1 }\n"
+	if err := checkCommentsOnly(original, "// Package a does things.\npackage a\n\n// F returns one\nfunc F() int { return 1 } // one\n"); err != nil {
+		t.Errorf("comment-only change rejected: %v", err)
+	}
+	if err := checkCommentsOnly(original, "package a\n\nfunc F() int { return 1 }\nfunc G() {}\n"); err == nil {
+		t.Error("expected added code to be rejected")
+	}
+	if err := checkCommentsOnly(original, "package a\n\nfunc F() int { return 1 \n"); err == nil {
+		t.Error("expected a broken file to be rejected")
+	}
+}
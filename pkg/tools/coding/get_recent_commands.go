@@ -0,0 +1,233 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/audit"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// defaultRecentCommands is how many commands get_recent_commands returns by default
+const defaultRecentCommands = 20
+
+// GetRecentCommandsTool lists the commands the agent ran this session, from
+// the audit log, with their approval and exit code. It lets the model check
+// whether a command already failed before running it again, and answers
+// "what did you run?".
+type GetRecentCommandsTool struct {
+	log *audit.Log
+}
+
+// NewGetRecentCommandsTool creates a new GetRecentCommandsTool reading log.
+func NewGetRecentCommandsTool(log *audit.Log) *GetRecentCommandsTool {
+	return &GetRecentCommandsTool{log: log}
+}
+
+// Name returns the tool name.
+func (t *GetRecentCommandsTool) Name() string {
+	return "get_recent_commands"
+}
+
+// Description returns the tool description.
+func (t *GetRecentCommandsTool) Description() string {
+	return "List the commands you ran with execute_command this session, most recent first, with each one's exit code and whether it was approved, rejected or blocked. Commands that failed more than once are flagged. Check it before re-running a command that failed: running it unchanged will fail the same way."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *GetRecentCommandsTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of commands to return (default: %d)", defaultRecentCommands),
+			},
+			"failed_only": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Only return commands that exited non-zero or failed to run (default: false)",
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{},
+	)
+}
+
+// Execute lists the session's commands.
+func (t *GetRecentCommandsTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		Limit        int      `xml:"limit"`
+		FailedOnly   bool     `xml:"failed_only"`
+		OutputFormat string   `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return "", err
+	}
+	if input.Limit < 0 {
+		return "", fmt.Errorf("invalid limit %d: must be positive", input.Limit)
+	}
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultRecentCommands
+	}
+
+	history := recentCommands(t.log.Session())
+	commands := make([]RecentCommand, 0, limit)
+	for _, cmd := range history.commands {
+		if len(commands) == limit {
+			break
+		}
+		if input.FailedOnly && !cmd.Failed {
+			continue
+		}
+		commands = append(commands, cmd)
+	}
+
+	if format == OutputFormatJSON {
+		return marshalJSONResult(recentCommandsJSONResult{
+			Commands:         commands,
+			Total:            len(history.commands),
+			RepeatedFailures: history.repeatedFailures,
+		})
+	}
+	return formatRecentCommands(commands, history, input.FailedOnly), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *GetRecentCommandsTool) IsLoopBreaking() bool {
+	return false
+}
+
+// RecentCommand is a command the agent ran, or tried to run, this session
+type RecentCommand struct {
+	Seq      int       `json:"seq"` // Audit log sequence number
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"`
+	Approval string    `json:"approval"` // One of the audit Approval* decisions
+	Executed bool      `json:"executed"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Failed   bool      `json:"failed"` // Exited non-zero or failed to run
+	Runs     int       `json:"runs"`   // Times this exact command was run this session
+}
+
+// commandHistory is the session's commands, most recent first
+type commandHistory struct {
+	commands         []RecentCommand
+	repeatedFailures []string // Commands that failed more than once, most recent first
+}
+
+// recentCommands collects the command entries of the session's audit log
+func recentCommands(entries []audit.Entry) commandHistory {
+	var history commandHistory
+	runs := make(map[string]int)
+	failures := make(map[string]int)
+	for _, entry := range entries {
+		if entry.Action != audit.ActionCommand {
+			continue
+		}
+		cmd := RecentCommand{
+			Seq:      entry.Seq,
+			Time:     entry.Time,
+			Command:  entry.Target,
+			Approval: entry.Approval,
+			Executed: entry.Executed,
+			ExitCode: entry.ExitCode,
+			Error:    entry.Error,
+		}
+		if cmd.Executed {
+			cmd.Failed = cmd.Error != "" || (cmd.ExitCode != nil && *cmd.ExitCode != 0)
+			runs[cmd.Command]++
+			if cmd.Failed {
+				failures[cmd.Command]++
+			}
+		}
+		history.commands = append(history.commands, cmd)
+	}
+
+	// Most recent first, each with the count of its runs
+	for i, j := 0, len(history.commands)-1; i < j; i, j = i+1, j-1 {
+		history.commands[i], history.commands[j] = history.commands[j], history.commands[i]
+	}
+	seen := make(map[string]bool)
+	for i := range history.commands {
+		cmd := &history.commands[i]
+		cmd.Runs = runs[cmd.Command]
+		if failures[cmd.Command] > 1 && !seen[cmd.Command] {
+			history.repeatedFailures = append(history.repeatedFailures, cmd.Command)
+		}
+		seen[cmd.Command] = true
+	}
+	return history
+}
+
+// recentCommandsJSONResult is the structured get_recent_commands result for output_format=json.
+type recentCommandsJSONResult struct {
+	Commands         []RecentCommand `json:"commands"`
+	Total            int             `json:"total"`
+	RepeatedFailures []string        `json:"repeated_failures,omitempty"`
+}
+
+// formatRecentCommands renders one line per command, most recent first
+func formatRecentCommands(commands []RecentCommand, history commandHistory, failedOnly bool) string {
+	if len(history.commands) == 0 {
+		return "No commands were run this session"
+	}
+	if len(commands) == 0 {
+		return fmt.Sprintf("None of the %d command(s) run this session failed", len(history.commands))
+	}
+
+	var b strings.Builder
+	if failedOnly {
+		fmt.Fprintf(&b, "%d failed command(s), most recent first:\n", len(commands))
+	} else {
+		fmt.Fprintf(&b, "%d of %d command(s) this session, most recent first:\n", len(commands), len(history.commands))
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  #%d %s  %s  (%s)\n", cmd.Seq, cmd.Time.Local().Format("15:04:05"), cmd.Command, commandOutcome(cmd))
+	}
+
+	if len(history.repeatedFailures) > 0 {
+		b.WriteString("\nFailed more than once; don't run these again unchanged:\n")
+		for _, command := range history.repeatedFailures {
+			fmt.Fprintf(&b, "  %s\n", command)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// commandOutcome describes how a command ended and how it was approved
+func commandOutcome(cmd RecentCommand) string {
+	var parts []string
+	switch {
+	case !cmd.Executed:
+		parts = append(parts, "not run: "+strings.ReplaceAll(cmd.Approval, "_", " "))
+	case cmd.ExitCode != nil:
+		parts = append(parts, fmt.Sprintf("exit %d", *cmd.ExitCode))
+	case cmd.Error != "":
+		parts = append(parts, "error: "+cmd.Error)
+	default:
+		parts = append(parts, "exit code unknown")
+	}
+	if cmd.Executed {
+		switch cmd.Approval {
+		case audit.ApprovalAuto:
+			parts = append(parts, "auto-approved")
+		case audit.ApprovalUser:
+			parts = append(parts, "approved")
+		}
+	}
+	if cmd.Runs > 1 {
+		parts = append(parts, fmt.Sprintf("run %d times", cmd.Runs))
+	}
+	return strings.Join(parts, ", ")
+}
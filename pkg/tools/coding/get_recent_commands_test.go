@@ -0,0 +1,85 @@
+package coding
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/audit"
+)
+
+// commandLog returns an audit log with an earlier session's command and this
+// session's commands
+func commandLog(t *testing.T) *audit.Log {
+	dir, anchorDir := t.TempDir(), t.TempDir()
+	earlier, err := audit.Open(dir, anchorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := earlier.Append(audit.Entry{Action: audit.ActionCommand, Tool: "execute_command", Target: "make lint", Approval: audit.ApprovalUser, Executed: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := audit.Open(dir, anchorDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exit := func(code int) *int { return &code }
+	for _, entry := range []audit.Entry{
+		{Action: audit.ActionCommand, Target: "go test ./...", Approval: audit.ApprovalAuto, Executed: true, ExitCode: exit(1)},
+		{Action: audit.ActionWrite, Target: "main.go", Approval: audit.ApprovalUser, Executed: true},
+		{Action: audit.ActionCommand, Target: "rm -rf build", Approval: audit.ApprovalRejected},
+		{Action: audit.ActionCommand, Target: "go test ./...", Approval: audit.ApprovalAuto, Executed: true, ExitCode: exit(1)},
+		{Action: audit.ActionCommand, Target: "go build ./...", Approval: audit.ApprovalUser, Executed: true, ExitCode: exit(0)},
+	} {
+		if err := log.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return log
+}
+
+func TestGetRecentCommandsTool(t *testing.T) {
+	tool := NewGetRecentCommandsTool(commandLog(t))
+
+	result, err := tool.Execute(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	for _, want := range []string{
+		"4 of 4 command(s) this session, most recent first:\n  #6 ",
+		"go build ./...  (exit 0, approved)\n",
+		"rm -rf build  (not run: rejected)\n",
+		"go test ./...  (exit 1, auto-approved, run 2 times)\n",
+		"Failed more than once; don't run these again unchanged:\n  go test ./...",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected result to contain %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "make lint") || strings.Contains(result, "main.go") {
+		t.Errorf("expected only this session's commands, got:\n%s", result)
+	}
+
+	result, err = tool.Execute(context.Background(), []byte("<arguments><failed_only>true</failed_only><limit>1</limit></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.HasPrefix(result, "1 failed command(s), most recent first:\n  #5 ") {
+		t.Errorf("expected the most recent failure only, got:\n%s", result)
+	}
+}
+
+func TestGetRecentCommandsTool_NoCommands(t *testing.T) {
+	log, err := audit.Open(t.TempDir(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewGetRecentCommandsTool(log).Execute(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "No commands were run this session" {
+		t.Errorf("unexpected result %q", result)
+	}
+}
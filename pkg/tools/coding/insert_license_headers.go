@@ -0,0 +1,172 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// InsertLicenseHeadersTool adds the configured license header to the source
+// files that have none, as check_licenses reports them. Files with a
+// different copyright or license header are left alone. All files are
+// previewed as one diff and written together.
+type InsertLicenseHeadersTool struct {
+	guard  *workspace.Guard
+	policy LicensePolicy
+	now    func() time.Time
+}
+
+// NewInsertLicenseHeadersTool creates a new InsertLicenseHeadersTool enforcing policy.
+func NewInsertLicenseHeadersTool(guard *workspace.Guard, policy LicensePolicy) *InsertLicenseHeadersTool {
+	return &InsertLicenseHeadersTool{
+		guard:  guard,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Name returns the tool name.
+func (t *InsertLicenseHeadersTool) Name() string {
+	return "insert_license_headers"
+}
+
+// Description returns the tool description.
+func (t *InsertLicenseHeadersTool) Description() string {
+	return "Insert the configured license header at the top of every source file that has none (after a shebang or encoding line), as reported by check_licenses. Files with a different copyright or license header are not touched. Every file is previewed as one diff for approval and written together."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *InsertLicenseHeadersTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory whose source files get the header (relative to workspace, default: the whole workspace)",
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{},
+	)
+}
+
+// Execute inserts the missing headers.
+func (t *InsertLicenseHeadersTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult inserts the missing headers, returning a diff artifact per
+// file.
+func (t *InsertLicenseHeadersTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		Path         string   `xml:"path"`
+		OutputFormat string   `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	fixes, err := t.resolve(ctx, input.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	var artifacts []tools.Artifact
+	for _, fix := range fixes {
+		if _, err := writeFileAtomic(ctx, fix.absPath, strings.NewReader(fix.updated)); err != nil {
+			if len(written) > 0 {
+				return nil, fmt.Errorf("%w (already written: %s)", err, strings.Join(written, ", "))
+			}
+			return nil, err
+		}
+		written = append(written, fix.relPath)
+		artifacts = append(artifacts, tools.Artifact{Kind: tools.ArtifactDiff, Path: fix.relPath, Diff: fix.diff})
+	}
+
+	summary := fmt.Sprintf("Inserted the license header in %d file(s)", len(written))
+	return newResult(format,
+		fmt.Sprintf("%s: %s", summary, strings.Join(written, ", ")),
+		summary,
+		insertLicenseHeadersJSONResult{Files: written},
+		artifacts...)
+}
+
+// insertLicenseHeadersJSONResult is the structured insert_license_headers result for output_format=json.
+type insertLicenseHeadersJSONResult struct {
+	Files []string `json:"files"`
+}
+
+// IsLoopBreaking returns whether this tool should break the agent loop.
+func (t *InsertLicenseHeadersTool) IsLoopBreaking() bool {
+	return false
+}
+
+// GeneratePreview implements the Previewable interface to show every file's
+// header as one diff.
+func (t *InsertLicenseHeadersTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	fixes, err := t.resolve(ctx, input.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff strings.Builder
+	paths := make([]string, len(fixes))
+	for i, fix := range fixes {
+		diff.WriteString(fix.diff)
+		paths[i] = fix.relPath
+	}
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeDiff,
+		Title:       fmt.Sprintf("Insert the license header in %d file(s)", len(fixes)),
+		Description: fmt.Sprintf("This will add the license header to %s", strings.Join(paths, ", ")),
+		Content:     diff.String(),
+		Metadata: map[string]interface{}{
+			"file_path":  paths[0],
+			"language":   "diff",
+			"file_paths": paths,
+		},
+	}, nil
+}
+
+// resolve finds the files under path that are missing the header and
+// applies it to their content in memory
+func (t *InsertLicenseHeadersTool) resolve(ctx context.Context, path string) ([]headerFix, error) {
+	checker := newHeaderChecker(t.policy, t.now().Year())
+	if checker == nil {
+		return nil, fmt.Errorf("no license header is configured; set header in the licenses section of the config")
+	}
+	root, err := resolveHeaderRoot(t.guard, path)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := checker.check(ctx, t.guard, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers.fixes) == 0 {
+		return nil, fmt.Errorf("no source file is missing the license header (%d checked)", headers.checked)
+	}
+	return headers.fixes, nil
+}
@@ -0,0 +1,545 @@
+package coding
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// LicensePolicy is what check_licenses checks and insert_license_headers
+// enforces, usually from the licenses config section
+type LicensePolicy struct {
+	Header  string   // Header template without comment markers, using {year} and {owner}; "" = headers aren't checked
+	Owner   string   // Replaces {owner} in Header
+	Exclude []string // Gitignore-style patterns of files that need no header
+	Allowed []string // SPDX identifiers dependencies may use; empty = any known license
+}
+
+// Header statuses
+const (
+	HeaderMissing   = "missing"   // The file has no license header
+	HeaderDifferent = "different" // The file has a copyright or license header that doesn't match the template
+)
+
+// License scanner statuses
+const (
+	ScanOK            = "ok"
+	ScanNotInstalled  = "not_installed"
+	ScanNotApplicable = "not_applicable"
+	ScanFailed        = "failed"
+)
+
+// unknownLicense is the license of a dependency no license was found for
+const unknownLicense = "Unknown"
+
+// maxHeaderLines is how far into a file its license header is looked for
+const maxHeaderLines = 30
+
+// maxHeaderFileSize is the size above which files are not checked for a
+// header; such files are data, not source
+const maxHeaderFileSize = 1 << 20
+
+// licenseScanTimeout bounds each dependency license scanner's run
+const licenseScanTimeout = 5 * time.Minute
+
+// headerCommentPrefixes maps the extensions of source files that need a
+// header to the line comment it is written in
+var headerCommentPrefixes = map[string]string{
+	".go": "//", ".js": "//", ".jsx": "//", ".mjs": "//", ".cjs": "//", ".ts": "//", ".tsx": "//",
+	".java": "//", ".kt": "//", ".kts": "//", ".scala": "//", ".swift": "//", ".rs": "//", ".cs": "//",
+	".c": "//", ".h": "//", ".cc": "//", ".cpp": "//", ".hpp": "//", ".proto": "//",
+	".py": "#", ".rb": "#", ".sh": "#", ".bash": "#", ".pl": "#", ".tf": "#",
+	".sql": "--", ".lua": "--",
+}
+
+// yearPattern matches a copyright year or range, such as 2021 or 2019-2024
+const yearPattern = `\d{4}(?:\s*[-,–]\s*\d{4})*`
+
+// otherHeaderPattern recognizes a license header other than the template
+var otherHeaderPattern = regexp.MustCompile(`(?i)copyright|spdx-license-identifier|licensed under`)
+
+// generatedPattern marks generated Go files, which are regenerated rather than edited
+var generatedPattern = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// encodingPattern matches a Python or Ruby encoding declaration, which must stay on the first two lines
+var encodingPattern = regexp.MustCompile(`^#.*coding[:=]`)
+
+// HeaderIssue is a source file without the configured license header
+type HeaderIssue struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // HeaderMissing or HeaderDifferent
+}
+
+// headerFix is a header insertion resolved against the file on disk
+type headerFix struct {
+	absPath  string
+	relPath  string
+	original string
+	updated  string
+	diff     string
+}
+
+// headerCheck is the outcome of checking the workspace's source files
+type headerCheck struct {
+	checked int
+	issues  []HeaderIssue
+	fixes   []headerFix // One per HeaderMissing issue
+}
+
+// headerChecker checks and inserts the license header of a policy
+type headerChecker struct {
+	template []string // Header lines, without comment markers
+	owner    string
+	year     int
+	matcher  *regexp.Regexp
+	exclude  *workspace.IgnoreMatcher
+}
+
+// newHeaderChecker compiles the policy's header template. It returns nil
+// when the policy has no header.
+func newHeaderChecker(policy LicensePolicy, year int) *headerChecker {
+	header := strings.TrimSpace(policy.Header)
+	if header == "" {
+		return nil
+	}
+
+	c := &headerChecker{
+		template: strings.Split(header, "\n"),
+		owner:    policy.Owner,
+		year:     year,
+		exclude:  &workspace.IgnoreMatcher{},
+	}
+	c.exclude.AddPatterns(policy.Exclude, "licenses")
+
+	owner := `.+`
+	if policy.Owner != "" {
+		owner = strings.Join(strings.Fields(regexp.QuoteMeta(policy.Owner)), `\s+`)
+	}
+	var lines []string
+	for _, line := range c.template {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern := regexp.QuoteMeta(strings.Join(fields, " "))
+		pattern = strings.ReplaceAll(pattern, " ", `\s+`)
+		pattern = strings.ReplaceAll(pattern, `\{year\}`, yearPattern)
+		pattern = strings.ReplaceAll(pattern, `\{owner\}`, owner)
+		lines = append(lines, pattern)
+	}
+	c.matcher = regexp.MustCompile(`(?m)^` + strings.Join(lines, `\n`) + `$`)
+	return c
+}
+
+// check checks the source files under root, resolving an insertion for
+// each file that has no header
+func (c *headerChecker) check(ctx context.Context, guard *workspace.Guard, root string) (*headerCheck, error) {
+	files, err := newFileIndex(guard).files(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	result := &headerCheck{}
+	for _, absPath := range files {
+		prefix, ok := headerCommentPrefixes[strings.ToLower(filepath.Ext(absPath))]
+		if !ok {
+			continue
+		}
+		relPath, err := guard.MakeRelative(absPath)
+		if err != nil {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+		if c.exclude.ShouldIgnore(relPath, false) {
+			continue
+		}
+		info, err := os.Stat(absPath)
+		if err != nil || info.Size() > maxHeaderFileSize {
+			continue
+		}
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+
+		leading := leadingComments(string(content))
+		if generatedPattern.MatchString(leading) {
+			continue
+		}
+		result.checked++
+		if c.matcher.MatchString(commentText(leading)) {
+			continue
+		}
+		if otherHeaderPattern.MatchString(leading) {
+			result.issues = append(result.issues, HeaderIssue{Path: relPath, Status: HeaderDifferent})
+			continue
+		}
+
+		fix := headerFix{absPath: absPath, relPath: relPath, original: string(content)}
+		fix.updated, fix.diff = c.insert(fix.original, prefix, relPath)
+		result.issues = append(result.issues, HeaderIssue{Path: relPath, Status: HeaderMissing})
+		result.fixes = append(result.fixes, fix)
+	}
+	return result, nil
+}
+
+// insert adds the header to content, after a shebang or encoding line,
+// returning the updated content and the diff of the insertion
+func (c *headerChecker) insert(content, prefix, relPath string) (string, string) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	at := 0
+	if at < len(lines) && strings.HasPrefix(lines[at], "#!") {
+		at++
+	}
+	if prefix == "#" && at < len(lines) && encodingPattern.MatchString(lines[at]) {
+		at++
+	}
+
+	block := c.render(prefix)
+	if at < len(lines) {
+		block = append(block, "")
+	}
+
+	updated := make([]string, 0, len(lines)+len(block))
+	updated = append(updated, lines[:at]...)
+	updated = append(updated, block...)
+	updated = append(updated, lines[at:]...)
+
+	// A unified diff of the insertion, with up to three lines of context
+	before := lines[max(0, at-3):at]
+	after := lines[at:min(len(lines), at+3)]
+	start := at - len(before) + 1
+	if len(lines) == 0 {
+		start = 0
+	}
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", relPath, relPath)
+	fmt.Fprintf(&diff, "@@ -%d,%d +%d,%d @@\n", start, len(before)+len(after), max(start, 1), len(before)+len(block)+len(after))
+	for _, line := range before {
+		diff.WriteString(" " + line + "\n")
+	}
+	for _, line := range block {
+		diff.WriteString("+" + line + "\n")
+	}
+	for _, line := range after {
+		diff.WriteString(" " + line + "\n")
+	}
+
+	return strings.Join(updated, "\n") + "\n", diff.String()
+}
+
+// render returns the header's lines as comments with prefix, with the
+// placeholders replaced
+func (c *headerChecker) render(prefix string) []string {
+	replacer := strings.NewReplacer("{year}", strconv.Itoa(c.year), "{owner}", c.owner)
+	lines := make([]string, len(c.template))
+	for i, line := range c.template {
+		line = strings.TrimRight(replacer.Replace(line), " \t")
+		if line == "" {
+			lines[i] = prefix
+		} else {
+			lines[i] = prefix + " " + line
+		}
+	}
+	return lines
+}
+
+// leadingComments returns the first maxHeaderLines lines of content
+func leadingComments(content string) string {
+	lines := strings.SplitN(content, "\n", maxHeaderLines+1)
+	if len(lines) > maxHeaderLines {
+		lines = lines[:maxHeaderLines]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commentText strips the comment markers and blank lines from the leading
+// lines of a file, leaving the text a header template is matched against
+func commentText(leading string) string {
+	var text []string
+	for _, line := range strings.Split(leading, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "/*"), "*/")
+		for _, marker := range []string{"//", "#", "--", "*"} {
+			if strings.HasPrefix(line, marker) {
+				line = strings.TrimPrefix(line, marker)
+				break
+			}
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			text = append(text, strings.Join(fields, " "))
+		}
+	}
+	return strings.Join(text, "\n")
+}
+
+// DependencyLicense is the license of one dependency
+type DependencyLicense struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	License   string `json:"license"` // SPDX identifier or expression, or Unknown
+	URL       string `json:"url,omitempty"`
+	Ecosystem string `json:"ecosystem"` // go or npm
+	Allowed   bool   `json:"allowed"`   // Known, and permitted by the policy
+}
+
+// LicenseScan is the outcome of one dependency license scanner
+type LicenseScan struct {
+	Scanner      string `json:"scanner"`
+	Status       string `json:"status"`
+	Detail       string `json:"detail,omitempty"` // Why it didn't run, or how to install it
+	Dependencies int    `json:"dependencies"`
+}
+
+// licenseScanner runs a license inventory tool over one ecosystem's dependencies
+type licenseScanner struct {
+	name       string
+	ecosystem  string
+	executable string
+	install    string
+	manifest   string // File that must be in the workspace root
+	args       []string
+	parse      func(output []byte, workspaceDir string) ([]DependencyLicense, error)
+}
+
+// licenseScanners are the dependency license inventory tools run by check_licenses
+var licenseScanners = []licenseScanner{
+	{
+		name:       "go-licenses",
+		ecosystem:  "go",
+		executable: "go-licenses",
+		install:    "go install github.com/google/go-licenses@latest",
+		manifest:   "go.mod",
+		args:       []string{"report", "./..."},
+		parse:      parseGoLicenses,
+	},
+	{
+		name:       "license-checker",
+		ecosystem:  "npm",
+		executable: "license-checker",
+		install:    "npm install -g license-checker",
+		manifest:   "package.json",
+		args:       []string{"--json", "--production"},
+		parse:      parseLicenseChecker,
+	},
+}
+
+// inventoryLicenses runs the license scanners that apply to workspaceDir,
+// returning the dependencies sorted by name with Allowed set from allowed
+func inventoryLicenses(ctx context.Context, workspaceDir string, allowed []string) ([]DependencyLicense, []LicenseScan) {
+	var deps []DependencyLicense
+	scans := make([]LicenseScan, 0, len(licenseScanners))
+	for _, scanner := range licenseScanners {
+		scan := LicenseScan{Scanner: scanner.name}
+		found, err := scanner.run(ctx, workspaceDir)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			scan.Status, scan.Detail = ScanNotApplicable, fmt.Sprintf("no %s in the workspace", scanner.manifest)
+		case errors.Is(err, exec.ErrNotFound):
+			scan.Status = ScanNotInstalled
+			scan.Detail = fmt.Sprintf("%s not found on PATH; install with: %s", scanner.executable, scanner.install)
+		case err != nil:
+			scan.Status, scan.Detail = ScanFailed, err.Error()
+		default:
+			scan.Status, scan.Dependencies = ScanOK, len(found)
+			deps = append(deps, found...)
+		}
+		scans = append(scans, scan)
+	}
+
+	for i := range deps {
+		deps[i].Allowed = licenseAllowed(deps[i].License, allowed)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Ecosystem != deps[j].Ecosystem {
+			return deps[i].Ecosystem < deps[j].Ecosystem
+		}
+		return deps[i].Name < deps[j].Name
+	})
+	return deps, scans
+}
+
+// run runs the scanner, returning os.ErrNotExist when the workspace has no
+// manifest for it and exec.ErrNotFound when it isn't installed
+func (s licenseScanner) run(ctx context.Context, workspaceDir string) ([]DependencyLicense, error) {
+	if _, err := os.Stat(filepath.Join(workspaceDir, s.manifest)); err != nil {
+		return nil, os.ErrNotExist
+	}
+	executable, err := exec.LookPath(s.executable)
+	if err != nil {
+		return nil, exec.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, licenseScanTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, executable, s.args...)
+	cmd.Dir = workspaceDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// go-licenses exits non-zero when some licenses are unknown, after
+	// reporting the others; only fail when there is no report at all
+	if err := cmd.Run(); err != nil && stdout.Len() == 0 {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", licenseScanTimeout)
+		}
+		if msg := lastLine(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return s.parse(stdout.Bytes(), workspaceDir)
+}
+
+// parseGoLicenses parses the CSV of go-licenses report: package, license
+// URL and license, one package per line
+func parseGoLicenses(output []byte, workspaceDir string) ([]DependencyLicense, error) {
+	reader := csv.NewReader(bytes.NewReader(output))
+	reader.FieldsPerRecord = -1
+
+	var deps []DependencyLicense
+	seen := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse go-licenses report: %w", err)
+		}
+		if len(record) < 3 || seen[record[0]] {
+			continue
+		}
+		seen[record[0]] = true
+		dep := DependencyLicense{Name: record[0], License: strings.TrimSpace(record[2]), Ecosystem: "go"}
+		if url := strings.TrimSpace(record[1]); url != unknownLicense {
+			dep.URL = url
+		}
+		if dep.License == "" {
+			dep.License = unknownLicense
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// parseLicenseChecker parses license-checker's JSON report, keyed by
+// name@version, leaving out the workspace's own package
+func parseLicenseChecker(output []byte, workspaceDir string) ([]DependencyLicense, error) {
+	var report map[string]struct {
+		Licenses   interface{} `json:"licenses"`
+		Repository string      `json:"repository"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse license-checker report: %w", err)
+	}
+
+	var root struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if data, err := os.ReadFile(filepath.Join(workspaceDir, "package.json")); err == nil {
+		_ = json.Unmarshal(data, &root)
+	}
+
+	deps := make([]DependencyLicense, 0, len(report))
+	for key, entry := range report {
+		at := strings.LastIndex(key, "@")
+		if at <= 0 {
+			at = len(key)
+		}
+		dep := DependencyLicense{Name: key[:at], Version: strings.TrimPrefix(key[at:], "@"), URL: entry.Repository, Ecosystem: "npm"}
+		if dep.Name == root.Name && dep.Version == root.Version {
+			continue
+		}
+
+		// Several licenses found in one package all apply
+		switch licenses := entry.Licenses.(type) {
+		case string:
+			dep.License = licenses
+		case []interface{}:
+			var names []string
+			for _, license := range licenses {
+				if name, ok := license.(string); ok {
+					names = append(names, name)
+				}
+			}
+			dep.License = strings.Join(names, " AND ")
+		}
+		if dep.License == "" || strings.EqualFold(dep.License, "UNKNOWN") {
+			dep.License = unknownLicense
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// licenseOperators split an SPDX expression into alternatives and the
+// licenses that all apply
+var (
+	licenseOr  = regexp.MustCompile(`(?i)\s+OR\s+`)
+	licenseAnd = regexp.MustCompile(`(?i)\s+AND\s+`)
+)
+
+// licenseAllowed reports whether an SPDX license expression is permitted:
+// one of its alternatives must use allowed licenses only. With no allowed
+// list, any known license is permitted. License names guessed by
+// license-checker end in "*" and are matched without it.
+func licenseAllowed(license string, allowed []string) bool {
+	if license == "" || license == unknownLicense {
+		return false
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+
+	expression := strings.NewReplacer("(", "", ")", "", "*", "").Replace(license)
+	for _, alternative := range licenseOr.Split(expression, -1) {
+		permitted := true
+		for _, name := range licenseAnd.Split(alternative, -1) {
+			if !containsFold(allowed, strings.TrimSpace(name)) {
+				permitted = false
+				break
+			}
+		}
+		if permitted {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastLine returns the last non-empty line of output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,169 @@
+package coding
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// licensePolicy is the policy of the license tests' workspaces
+var licensePolicy = LicensePolicy{
+	Header:  "Copyright {year} Example Corp\nSPDX-License-Identifier: Apache-2.0",
+	Owner:   "Example Corp",
+	Exclude: []string{"third_party/"},
+	Allowed: []string{"MIT", "Apache-2.0"},
+}
+
+// licenseWorkspace has source files with the header, without it, with
+// another header, and generated or excluded
+func licenseWorkspace(t *testing.T) *workspacetest.Workspace {
+	return workspacetest.New(t, workspacetest.Tree{
+		"main.go":              "// Copyright 2019-2023 Example Corp\n// SPDX-License-Identifier: Apache-2.0\n\npackage main\n",
+		"cache/cache.go":       "package cache\n\nfunc Get() {}\n",
+		"scripts/deploy.sh":    "#!/bin/sh\necho deploy\n",
+		"web/app.ts":           "/*\n * Copyright 2020 Someone Else\n */\nexport {}\n",
+		"api/api.pb.go":        "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n",
+		"third_party/lib.go":   "package lib\n",
+		"docs/guide.md":        "# Guide\n",
+		"empty/doc.py":         "",
+		"tools/gen/encoded.py": "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\nprint('hi')\n",
+	})
+}
+
+// fixedYear stands in for time.Now so inserted headers have a known year
+func fixedYear() time.Time {
+	return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCheckLicensesTool_Headers(t *testing.T) {
+	ws := licenseWorkspace(t)
+	tool := NewCheckLicensesTool(ws.Guard(), licensePolicy)
+	tool.now = fixedYear
+
+	result, err := tool.ExecuteResult(context.Background(), []byte("<arguments><check>headers</check></arguments>"))
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if result.Summary != "Licenses: 5 of 6 source file(s) without the license header" {
+		t.Errorf("unexpected summary %q", result.Summary)
+	}
+	for _, want := range []string{
+		"1 file(s) have a different copyright or license header and must be fixed by hand:\n  web/app.ts\n",
+		"4 file(s) are missing the header",
+		"--- cache/cache.go\n+++ cache/cache.go\n@@ -1,3 +1,6 @@\n+// Copyright 2025 Example Corp\n+// SPDX-License-Identifier: Apache-2.0\n+\n package cache\n",
+		"@@ -1,2 +1,5 @@\n #!/bin/sh\n+# Copyright 2025 Example Corp\n+# SPDX-License-Identifier: Apache-2.0\n+\n echo deploy\n",
+		"@@ -0,0 +1,2 @@\n+# Copyright 2025 Example Corp\n",
+	} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, result.Output)
+		}
+	}
+	for _, unwanted := range []string{"main.go", "api.pb.go", "third_party", "guide.md", "Dependency scanners"} {
+		if strings.Contains(result.Output, unwanted) {
+			t.Errorf("expected output not to contain %q, got:\n%s", unwanted, result.Output)
+		}
+	}
+	if len(result.Artifacts) != 4 || result.Artifacts[0].Kind != tools.ArtifactDiff {
+		t.Errorf("expected a diff artifact per missing header, got %+v", result.Artifacts)
+	}
+	ws.AssertFile("cache/cache.go", "package cache\n\nfunc Get() {}\n")
+}
+
+func TestInsertLicenseHeadersTool(t *testing.T) {
+	ws := licenseWorkspace(t)
+	tool := NewInsertLicenseHeadersTool(ws.Guard(), licensePolicy)
+	tool.now = fixedYear
+
+	preview, err := tool.GeneratePreview(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if preview.Title != "Insert the license header in 4 file(s)" {
+		t.Errorf("unexpected preview title %q", preview.Title)
+	}
+
+	result, err := tool.ExecuteResult(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if result.Summary != "Inserted the license header in 4 file(s)" {
+		t.Errorf("unexpected summary %q", result.Summary)
+	}
+	ws.AssertFile("cache/cache.go", "// Copyright 2025 Example Corp\n// SPDX-License-Identifier: Apache-2.0\n\npackage cache\n\nfunc Get() {}\n")
+	ws.AssertFile("tools/gen/encoded.py", "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n# Copyright 2025 Example Corp\n# SPDX-License-Identifier: Apache-2.0\n\nprint('hi')\n")
+	ws.AssertFile("empty/doc.py", "# Copyright 2025 Example Corp\n# SPDX-License-Identifier: Apache-2.0\n")
+	ws.AssertFile("web/app.ts", "/*\n * Copyright 2020 Someone Else\n */\nexport {}\n")
+
+	// Every file now has a header, so there is nothing left to insert
+	if _, err := tool.Execute(context.Background(), []byte("<arguments></arguments>")); err == nil || !strings.Contains(err.Error(), "no source file is missing the license header") {
+		t.Errorf("expected nothing left to insert, got %v", err)
+	}
+}
+
+func TestCheckLicensesTool_NoHeaderConfigured(t *testing.T) {
+	ws := licenseWorkspace(t)
+	tool := NewCheckLicensesTool(ws.Guard(), LicensePolicy{})
+	if _, err := tool.Execute(context.Background(), []byte("<arguments><check>headers</check></arguments>")); err == nil {
+		t.Error("expected a headers check without a configured header to fail")
+	}
+}
+
+func TestParseGoLicenses(t *testing.T) {
+	report := "github.com/a/lib,https://github.com/a/lib/blob/HEAD/LICENSE,MIT\n" +
+		"github.com/a/lib/sub,https://github.com/a/lib/blob/HEAD/LICENSE,MIT\n" +
+		"github.com/b/gpl,https://github.com/b/gpl/blob/HEAD/COPYING,GPL-3.0\n" +
+		"github.com/c/none,Unknown,Unknown\n"
+	deps, err := parseGoLicenses([]byte(report), "")
+	if err != nil {
+		t.Fatalf("parseGoLicenses failed: %v", err)
+	}
+	if len(deps) != 4 || deps[2].License != "GPL-3.0" || deps[3].URL != "" || deps[3].License != unknownLicense {
+		t.Errorf("unexpected dependencies %+v", deps)
+	}
+}
+
+func TestParseLicenseChecker(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"package.json": `{"name": "web", "version": "1.0.0"}`})
+	report := `{
+  "web@1.0.0": {"licenses": "UNLICENSED"},
+  "@scope/ui@2.1.0": {"licenses": "MIT", "repository": "https://github.com/scope/ui"},
+  "dual@1.0.0": {"licenses": ["MIT", "CC-BY-4.0"]}
+}`
+	deps, err := parseLicenseChecker([]byte(report), ws.Path(""))
+	if err != nil {
+		t.Fatalf("parseLicenseChecker failed: %v", err)
+	}
+	byName := make(map[string]DependencyLicense)
+	for _, dep := range deps {
+		byName[dep.Name] = dep
+	}
+	if len(deps) != 2 || byName["@scope/ui"].Version != "2.1.0" || byName["dual"].License != "MIT AND CC-BY-4.0" {
+		t.Errorf("unexpected dependencies %+v", deps)
+	}
+}
+
+func TestLicenseAllowed(t *testing.T) {
+	allowed := []string{"MIT", "Apache-2.0"}
+	for license, want := range map[string]bool{
+		"MIT":                  true,
+		"mit":                  true,
+		"MIT*":                 true,
+		"GPL-3.0":              false,
+		"(MIT OR GPL-3.0)":     true,
+		"MIT AND GPL-3.0":      false,
+		"MIT AND Apache-2.0":   true,
+		unknownLicense:         false,
+		"GPL-2.0 OR LGPL-2.1+": false,
+	} {
+		if got := licenseAllowed(license, allowed); got != want {
+			t.Errorf("licenseAllowed(%q) = %v, want %v", license, got, want)
+		}
+	}
+	if !licenseAllowed("GPL-3.0", nil) || licenseAllowed(unknownLicense, nil) {
+		t.Error("expected any known license to be allowed without an allowed list")
+	}
+}
@@ -8,11 +8,16 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/security/workspace"
 )
 
+// progressEvery is how many files the directory-walking tools process between
+// progress reports
+const progressEvery = 250
+
 // ListFilesTool lists files and directories with optional recursion and filtering.
 type ListFilesTool struct {
 	guard *workspace.Guard
@@ -51,6 +56,7 @@ func (t *ListFilesTool) Schema() map[string]interface{} {
 				"type":        "string",
 				"description": "Optional glob pattern to filter files (e.g., '*.go', 'test_*.py')",
 			},
+			"output_format": outputFormatProperty(),
 		},
 		[]string{}, // No required fields - all optional
 	)
@@ -58,16 +64,27 @@ func (t *ListFilesTool) Schema() map[string]interface{} {
 
 // Execute lists files in the specified directory.
 func (t *ListFilesTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult lists the directory's entries.
+func (t *ListFilesTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
 	// Parse arguments
 	var input struct {
-		XMLName   xml.Name `xml:"arguments"`
-		Path      string   `xml:"path"`
-		Recursive bool     `xml:"recursive"`
-		Pattern   string   `xml:"pattern"`
+		XMLName      xml.Name `xml:"arguments"`
+		Path         string   `xml:"path"`
+		Recursive    bool     `xml:"recursive"`
+		Pattern      string   `xml:"pattern"`
+		OutputFormat string   `xml:"output_format"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
 	}
 
 	// Default to workspace root if no path provided
@@ -77,37 +94,49 @@ func (t *ListFilesTool) Execute(ctx context.Context, argsXML []byte) (string, er
 
 	// Validate path with workspace guard
 	if err := t.guard.ValidatePath(input.Path); err != nil {
-		return "", fmt.Errorf("invalid path: %w", err)
+		return nil, fmt.Errorf("invalid path: %w", err)
 	}
 
 	// Resolve to absolute path
 	absPath, err := t.guard.ResolvePath(input.Path)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve path: %w", err)
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	// Check if path exists and is a directory
 	info, err := os.Stat(absPath)
 	if err != nil {
-		return "", fmt.Errorf("path does not exist: %w", err)
+		return nil, fmt.Errorf("path does not exist: %w", err)
 	}
 	if !info.IsDir() {
-		return "", fmt.Errorf("path is not a directory: %s", input.Path)
+		return nil, fmt.Errorf("path is not a directory: %s", input.Path)
 	}
 
 	// List files
 	var entries []fileEntry
 	if input.Recursive {
-		entries, err = t.listRecursive(absPath, input.Pattern)
+		entries, err = t.listRecursive(ctx, absPath, input.Pattern)
 	} else {
 		entries, err = t.listDirectory(absPath, input.Pattern)
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to list files: %w", err)
+		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
 
-	// Format output
-	return t.formatEntries(entries)
+	// Format output; only the text output is paged
+	sortEntries(entries)
+	payload := t.entriesJSON(entries)
+	var text string
+	if format == OutputFormatText {
+		output, err := t.formatEntries(entries)
+		if err != nil {
+			return nil, err
+		}
+		text = tools.PaginateResult(ctx, output)
+	}
+
+	summary := fmt.Sprintf("Listed %d files and %d directories in %s", payload.TotalFiles, payload.TotalDirs, filepath.ToSlash(input.Path))
+	return newResult(format, text, summary, payload)
 }
 
 // IsLoopBreaking returns false as this tool doesn't break the agent loop.
@@ -117,9 +146,27 @@ func (t *ListFilesTool) IsLoopBreaking() bool {
 
 // fileEntry represents a file or directory entry.
 type fileEntry struct {
-	Path  string
-	IsDir bool
-	Size  int64
+	Path    string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+	Skipped string // Why search_files skips the file, if it does
+}
+
+// listFilesJSONResult is the structured list_files result for output_format=json.
+type listFilesJSONResult struct {
+	Entries    []listFilesJSONEntry `json:"entries"`
+	TotalFiles int                  `json:"total_files"`
+	TotalDirs  int                  `json:"total_dirs"`
+}
+
+// listFilesJSONEntry is a single entry in the structured list_files result.
+type listFilesJSONEntry struct {
+	Path    string    `json:"path"`
+	Type    string    `json:"type"` // "file" or "dir"
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Skipped string    `json:"skipped,omitempty"` // Why search_files skips the file, if it does
 }
 
 // listDirectory lists files in a single directory (non-recursive).
@@ -154,24 +201,29 @@ func (t *ListFilesTool) listDirectory(dirPath string, pattern string) ([]fileEnt
 			continue // Skip entries we can't stat
 		}
 
-		result = append(result, fileEntry{
-			Path:  fullPath,
-			IsDir: entry.IsDir(),
-			Size:  info.Size(),
-		})
+		result = append(result, t.newEntry(fullPath, info))
 	}
 
 	return result, nil
 }
 
-// listRecursive lists files recursively.
-func (t *ListFilesTool) listRecursive(rootPath string, pattern string) ([]fileEntry, error) {
+// listRecursive lists files recursively, reporting progress as it scans.
+func (t *ListFilesTool) listRecursive(ctx context.Context, rootPath string, pattern string) ([]fileEntry, error) {
 	var result []fileEntry
+	scanned := 0
 
 	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip entries with errors
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		scanned++
+		if scanned%progressEvery == 0 {
+			tools.ReportProgress(ctx, "scanning %d files…", scanned)
+		}
 
 		// Skip the root directory itself
 		if path == rootPath {
@@ -202,11 +254,7 @@ func (t *ListFilesTool) listRecursive(rootPath string, pattern string) ([]fileEn
 			}
 		}
 
-		result = append(result, fileEntry{
-			Path:  path,
-			IsDir: info.IsDir(),
-			Size:  info.Size(),
-		})
+		result = append(result, t.newEntry(path, info))
 
 		return nil
 	})
@@ -214,19 +262,39 @@ func (t *ListFilesTool) listRecursive(rootPath string, pattern string) ([]fileEn
 	return result, err
 }
 
-// formatEntries formats file entries into a readable string.
-func (t *ListFilesTool) formatEntries(entries []fileEntry) (string, error) {
-	if len(entries) == 0 {
-		return "No files found", nil
+// newEntry creates the entry for path, noting whether search_files skips
+// it. Only names and sizes are checked, so listings don't read every file.
+func (t *ListFilesTool) newEntry(path string, info os.FileInfo) fileEntry {
+	entry := fileEntry{
+		Path:    path,
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if !entry.IsDir {
+		limits := t.guard.ContentLimits()
+		if reason := nameSkipReason(path, entry.Size, limits); reason != "" {
+			entry.Skipped = describeSkipReason(reason, limits)
+		}
 	}
+	return entry
+}
 
-	// Sort entries: directories first, then by name
+// sortEntries sorts entries with directories first, then by name.
+func sortEntries(entries []fileEntry) {
 	sort.Slice(entries, func(i, j int) bool {
 		if entries[i].IsDir != entries[j].IsDir {
 			return entries[i].IsDir // Directories first
 		}
 		return entries[i].Path < entries[j].Path
 	})
+}
+
+// formatEntries formats file entries into a readable string.
+func (t *ListFilesTool) formatEntries(entries []fileEntry) (string, error) {
+	if len(entries) == 0 {
+		return "No files found", nil
+	}
 
 	var builder strings.Builder
 	var totalFiles, totalDirs int
@@ -244,6 +312,9 @@ func (t *ListFilesTool) formatEntries(entries []fileEntry) (string, error) {
 		} else {
 			// Format file size
 			sizeStr := formatFileSize(entry.Size)
+			if entry.Skipped != "" {
+				sizeStr += ", " + entry.Skipped + ", not searched"
+			}
 			builder.WriteString(fmt.Sprintf("📄 %s (%s)\n", relPath, sizeStr))
 			totalFiles++
 		}
@@ -255,6 +326,38 @@ func (t *ListFilesTool) formatEntries(entries []fileEntry) (string, error) {
 	return builder.String(), nil
 }
 
+// entriesJSON returns file entries as the structured result.
+func (t *ListFilesTool) entriesJSON(entries []fileEntry) listFilesJSONResult {
+	result := listFilesJSONResult{
+		Entries: make([]listFilesJSONEntry, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		relPath, err := t.guard.MakeRelative(entry.Path)
+		if err != nil {
+			relPath = filepath.Base(entry.Path)
+		}
+
+		entryType := "file"
+		if entry.IsDir {
+			entryType = "dir"
+			result.TotalDirs++
+		} else {
+			result.TotalFiles++
+		}
+
+		result.Entries = append(result.Entries, listFilesJSONEntry{
+			Path:    filepath.ToSlash(relPath),
+			Type:    entryType,
+			Size:    entry.Size,
+			ModTime: entry.ModTime,
+			Skipped: entry.Skipped,
+		})
+	}
+
+	return result
+}
+
 // formatFileSize formats a file size in bytes to a human-readable string.
 func formatFileSize(bytes int64) string {
 	const unit = 1024
@@ -0,0 +1,99 @@
+package coding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// Output formats supported by the coding tools' output_format parameter.
+const (
+	// OutputFormatText is the default human-readable output, tuned for the model.
+	OutputFormatText = "text"
+
+	// OutputFormatJSON produces structured results for machine consumers.
+	OutputFormatJSON = "json"
+)
+
+// OutputFormatKey is the context key an executor can set to change the default
+// output format (e.g. OutputFormatJSON for API consumers and exports).
+// An explicit output_format argument always takes precedence.
+const OutputFormatKey ContextKey = "output_format"
+
+// outputFormatProperty returns the schema entry for the shared output_format parameter
+func outputFormatProperty() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"enum":        []string{OutputFormatText, OutputFormatJSON},
+		"description": "Optional result format: 'text' (default, human-readable) or 'json' (structured)",
+	}
+}
+
+// resolveOutputFormat determines the output format from the tool argument,
+// falling back to the context default and then to text.
+func resolveOutputFormat(ctx context.Context, arg string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(arg))
+	if format == "" {
+		if ctxFormat, ok := ctx.Value(OutputFormatKey).(string); ok {
+			format = ctxFormat
+		}
+	}
+
+	switch format {
+	case "", OutputFormatText:
+		return OutputFormatText, nil
+	case OutputFormatJSON:
+		return OutputFormatJSON, nil
+	default:
+		return "", fmt.Errorf("invalid output_format %q: must be '%s' or '%s'", arg, OutputFormatText, OutputFormatJSON)
+	}
+}
+
+// marshalJSONResult renders a structured tool result as indented JSON
+func marshalJSONResult(result interface{}) (string, error) {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JSON result: %w", err)
+	}
+	return string(data), nil
+}
+
+// newResult builds a tool's structured result. The payload is the result's
+// JSON form: it becomes the Result's Data, and its Output when format is
+// OutputFormatJSON; otherwise text is the Output.
+func newResult(format, text, summary string, payload interface{}, artifacts ...tools.Artifact) (*tools.Result, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON result: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to encode JSON result: %w", err)
+	}
+
+	output := text
+	if format == OutputFormatJSON {
+		if output, err = marshalJSONResult(payload); err != nil {
+			return nil, err
+		}
+	}
+	return &tools.Result{
+		Success:   true,
+		Summary:   summary,
+		Output:    output,
+		Data:      fields,
+		Artifacts: artifacts,
+	}, nil
+}
+
+// resultOutput returns a structured result's Output, for the Execute method
+// of a tool implementing tools.ResultExecutor
+func resultOutput(result *tools.Result, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
@@ -0,0 +1,149 @@
+package coding
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestResolveOutputFormat(t *testing.T) {
+	jsonCtx := context.WithValue(context.Background(), OutputFormatKey, OutputFormatJSON)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{"default is text", context.Background(), "", OutputFormatText, false},
+		{"explicit json", context.Background(), "json", OutputFormatJSON, false},
+		{"case insensitive", context.Background(), " JSON ", OutputFormatJSON, false},
+		{"context default", jsonCtx, "", OutputFormatJSON, false},
+		{"argument overrides context", jsonCtx, "text", OutputFormatText, false},
+		{"invalid format", context.Background(), "yaml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveOutputFormat(tt.ctx, tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveOutputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveOutputFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilesJSONOutput(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"sub/":    "",
+		"main.go": "package main\n",
+	})
+
+	tool := NewListFilesTool(ws.Guard())
+	out, err := tool.Execute(context.Background(), []byte(`<arguments><path>.</path><output_format>json</output_format></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	var result listFilesJSONResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+
+	if result.TotalDirs != 1 || result.TotalFiles != 1 {
+		t.Fatalf("Expected 1 dir and 1 file, got %d dirs and %d files", result.TotalDirs, result.TotalFiles)
+	}
+
+	// Directories are listed first
+	if result.Entries[0].Path != "sub" || result.Entries[0].Type != "dir" {
+		t.Errorf("Unexpected first entry: %+v", result.Entries[0])
+	}
+	file := result.Entries[1]
+	if file.Path != "main.go" || file.Type != "file" || file.Size != 13 || file.ModTime.IsZero() {
+		t.Errorf("Unexpected file entry: %+v", file)
+	}
+}
+
+func TestReadFileJSONOutput(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"a.txt": "one\ntwo\nthree\n"})
+
+	tool := NewReadFileTool(ws.Guard())
+	args := []byte(`<arguments><path>a.txt</path><start_line>2</start_line><output_format>json</output_format></arguments>`)
+	out, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	var result readFileJSONResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+
+	if len(result.Lines) != 2 || result.Lines[0].Number != 2 || result.Lines[1].Text != "three" {
+		t.Errorf("Unexpected lines: %+v", result.Lines)
+	}
+
+	// Text output is unchanged by default
+	text, err := tool.Execute(context.Background(), []byte(`<arguments><path>a.txt</path></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if text != "1 | one\n2 | two\n3 | three" {
+		t.Errorf("Unexpected text output: %q", text)
+	}
+}
+
+func TestStructuredResults(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"main.go": "package main\n\nfunc old() {}\n",
+	})
+	ctx := context.Background()
+
+	diff, err := NewApplyDiffTool(ws.Guard()).ExecuteResult(ctx, []byte(
+		`<arguments><path>main.go</path><edits><edit><search>old</search><replace>renamed</replace></edit></edits></arguments>`))
+	if err != nil {
+		t.Fatalf("apply_diff failed: %v", err)
+	}
+	if !diff.Success || diff.Summary != "Applied 1 edit(s) to main.go" || diff.Output != "Successfully applied 1 edit(s) to main.go" {
+		t.Errorf("Unexpected apply_diff result: %+v", diff)
+	}
+	if diff.Data["edits_applied"] != float64(1) || diff.Data["changed"] != true {
+		t.Errorf("Unexpected apply_diff data: %v", diff.Data)
+	}
+	if len(diff.Artifacts) != 1 || diff.Artifacts[0].Kind != tools.ArtifactDiff || !strings.Contains(diff.Artifacts[0].Diff, "+func renamed() {}") {
+		t.Errorf("Expected the change as a diff artifact, got %+v", diff.Artifacts)
+	}
+
+	read, err := NewReadFileTool(ws.Guard()).ExecuteResult(ctx, []byte(`<arguments><path>main.go</path><start_line>3</start_line></arguments>`))
+	if err != nil {
+		t.Fatalf("read_file failed: %v", err)
+	}
+	if read.Summary != "Read lines 3-3 of main.go" {
+		t.Errorf("Unexpected read_file summary: %q", read.Summary)
+	}
+	if want := (tools.Artifact{Kind: tools.ArtifactFile, Path: "main.go", Line: 3}); len(read.Artifacts) != 1 || read.Artifacts[0] != want {
+		t.Errorf("Expected main.go at line 3 as an artifact, got %+v", read.Artifacts)
+	}
+
+	search, err := NewSearchFilesTool(ws.Guard()).ExecuteResult(ctx, []byte(`<arguments><pattern>renamed</pattern></arguments>`))
+	if err != nil {
+		t.Fatalf("search_files failed: %v", err)
+	}
+	if search.Summary != "Found 1 matches in 1 files" || search.Data["total_count"] != float64(1) {
+		t.Errorf("Unexpected search_files result: %q %v", search.Summary, search.Data)
+	}
+
+	// Execute returns the structured result's output
+	out, err := NewWriteFileTool(ws.Guard()).Execute(ctx, []byte(`<arguments><path>new.go</path><content>package main</content></arguments>`))
+	if err != nil || out != "File 'new.go' created successfully" {
+		t.Errorf("Execute() = %q, %v", out, err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package coding
+
+import (
+	"io/fs"
+	"os"
+)
+
+// preserveOwner is a no-op on platforms without Unix file ownership
+func preserveOwner(f *os.File, info fs.FileInfo) error {
+	return nil
+}
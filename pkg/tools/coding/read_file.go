@@ -6,6 +6,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
@@ -50,6 +51,7 @@ func (t *ReadFileTool) Schema() map[string]interface{} {
 				"type":        "integer",
 				"description": "Optional ending line number (1-based, inclusive)",
 			},
+			"output_format": outputFormatProperty(),
 		},
 		[]string{"path"},
 	)
@@ -57,44 +59,84 @@ func (t *ReadFileTool) Schema() map[string]interface{} {
 
 // Execute reads the file and returns its contents.
 func (t *ReadFileTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult reads the file, returning it as an artifact at the first
+// line read.
+func (t *ReadFileTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
 	var input struct {
-		XMLName   xml.Name `xml:"arguments"`
-		Path      string   `xml:"path"`
-		StartLine int      `xml:"start_line"`
-		EndLine   int      `xml:"end_line"`
+		XMLName      xml.Name `xml:"arguments"`
+		Path         string   `xml:"path"`
+		StartLine    int      `xml:"start_line"`
+		EndLine      int      `xml:"end_line"`
+		OutputFormat string   `xml:"output_format"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
 	}
 
 	if input.Path == "" {
-		return "", fmt.Errorf("missing required parameter: path")
+		return nil, fmt.Errorf("missing required parameter: path")
 	}
 
 	// Validate path with workspace guard
 	if err := t.guard.ValidatePath(input.Path); err != nil {
-		return "", fmt.Errorf("invalid path: %w", err)
+		return nil, fmt.Errorf("invalid path: %w", err)
 	}
 
 	// Resolve to absolute path
 	absPath, err := t.guard.ResolvePath(input.Path)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve path: %w", err)
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	// Check if file is ignored
 	if t.guard.ShouldIgnore(absPath) {
-		return "", fmt.Errorf("file '%s' is ignored by .gitignore, .forgeignore, or default patterns", input.Path)
+		return nil, fmt.Errorf("file '%s' is ignored by .gitignore, .forgeignore, or default patterns", input.Path)
 	}
 
 	// Read file
-	content, err := t.readFileWithLineNumbers(absPath, input.StartLine, input.EndLine)
+	lines, err := t.readFileLines(absPath, input.StartLine, input.EndLine)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return content, nil
+	path := filepath.ToSlash(input.Path)
+	artifact := tools.Artifact{Kind: tools.ArtifactFile, Path: path}
+	summary := fmt.Sprintf("Read 0 lines from %s", path)
+	if len(lines) > 0 {
+		artifact.Line = lines[0].Number
+		summary = fmt.Sprintf("Read lines %d-%d of %s", lines[0].Number, lines[len(lines)-1].Number, path)
+	}
+
+	// Only the text output is paged
+	var text string
+	if format == OutputFormatText {
+		text = tools.PaginateResult(ctx, formatNumberedLines(lines))
+	}
+	return newResult(format, text, summary, readFileJSONResult{
+		Path:  input.Path,
+		Lines: lines,
+	}, artifact)
+}
+
+// numberedLine is a single file line with its 1-based line number.
+type numberedLine struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+}
+
+// readFileJSONResult is the structured read_file result for output_format=json.
+type readFileJSONResult struct {
+	Path  string         `json:"path"`
+	Lines []numberedLine `json:"lines"`
 }
 
 // IsLoopBreaking returns false as this tool doesn't break the agent loop.
@@ -102,22 +144,22 @@ func (t *ReadFileTool) IsLoopBreaking() bool {
 	return false
 }
 
-// readFileWithLineNumbers reads a file and returns its contents with line numbers.
+// readFileLines reads a file and returns the requested lines with their numbers.
 // If startLine and endLine are both 0, reads the entire file.
 // Line numbers are 1-based and inclusive.
-func (t *ReadFileTool) readFileWithLineNumbers(path string, startLine, endLine int) (string, error) {
+func (t *ReadFileTool) readFileLines(path string, startLine, endLine int) ([]numberedLine, error) {
 	// Validate line range if specified
 	if err := t.validateLineRange(startLine, endLine); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	file, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer file.Close()
 
-	return t.scanAndFormatLines(file, startLine, endLine)
+	return t.scanLines(file, startLine, endLine)
 }
 
 // validateLineRange validates the start and end line numbers.
@@ -136,10 +178,10 @@ func (t *ReadFileTool) validateLineRange(startLine, endLine int) error {
 	return nil
 }
 
-// scanAndFormatLines scans the file and formats lines with line numbers.
-func (t *ReadFileTool) scanAndFormatLines(file *os.File, startLine, endLine int) (string, error) {
+// scanLines scans the file and collects the lines within the requested range.
+func (t *ReadFileTool) scanLines(file *os.File, startLine, endLine int) ([]numberedLine, error) {
 	scanner := bufio.NewScanner(file)
-	var builder strings.Builder
+	lines := make([]numberedLine, 0)
 	lineNum := 0
 	readAll := startLine == 0 && endLine == 0
 
@@ -156,24 +198,29 @@ func (t *ReadFileTool) scanAndFormatLines(file *os.File, startLine, endLine int)
 			break
 		}
 
-		// Write line with number
-		if builder.Len() > 0 {
-			builder.WriteString("\n")
-		}
-		builder.WriteString(fmt.Sprintf("%d | %s", lineNum, scanner.Text()))
+		lines = append(lines, numberedLine{Number: lineNum, Text: scanner.Text()})
 	}
 
 	if err := scanner.Err(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Check if we read any lines
-	if builder.Len() == 0 {
-		if !readAll && startLine > lineNum {
-			return "", fmt.Errorf("start_line %d exceeds file length (%d lines)", startLine, lineNum)
-		}
-		return "", nil // Empty file
+	if len(lines) == 0 && !readAll && startLine > lineNum {
+		return nil, fmt.Errorf("start_line %d exceeds file length (%d lines)", startLine, lineNum)
 	}
 
-	return builder.String(), nil
+	return lines, nil
+}
+
+// formatNumberedLines formats lines as "N | text", one per line.
+func formatNumberedLines(lines []numberedLine) string {
+	var builder strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fmt.Sprintf("%d | %s", line.Number, line.Text))
+	}
+	return builder.String()
 }
@@ -0,0 +1,419 @@
+package coding
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+const (
+	// defaultContextTokens is request_context's default token budget
+	defaultContextTokens = 16000
+
+	// maxContextTokens caps the budget the model may ask for
+	maxContextTokens = 64000
+
+	// largeFileTokens is the size above which a file is outlined rather than
+	// read in full, even when the budget would fit it
+	largeFileTokens = 8000
+
+	// maxContextFiles caps how many files one request resolves to
+	maxContextFiles = 50
+
+	// contextCharsPerToken estimates tokens from a file's size
+	contextCharsPerToken = 4
+)
+
+// outlinePattern matches the lines kept in a large file's outline:
+// declarations at the top level or one indent in, and Markdown headings
+var outlinePattern = regexp.MustCompile(`^(?:\s{0,4}(?:export\s+|pub(?:\([^)]*\))?\s+|public\s+|private\s+|protected\s+|static\s+|abstract\s+|async\s+|default\s+)*` +
+	`(?:func|type|class|def|interface|struct|enum|trait|impl|fn|function|const|var|let|module|namespace|package)\b|#{1,6}\s)`)
+
+// RequestContextTool gathers several files in one step: the model lists the
+// files and globs it needs and gets them back together within a token
+// budget. Files are read in full while they fit; large files are outlined
+// so the model can read the ranges it needs, and files past the budget are
+// listed as omitted. One call replaces a run of read_file calls, and the
+// budget is decided in one place.
+type RequestContextTool struct {
+	guard *workspace.Guard
+	index *fileIndex
+}
+
+// NewRequestContextTool creates a new RequestContextTool with workspace security.
+func NewRequestContextTool(guard *workspace.Guard) *RequestContextTool {
+	return &RequestContextTool{
+		guard: guard,
+		index: newFileIndex(guard),
+	}
+}
+
+// Name returns the tool name.
+func (t *RequestContextTool) Name() string {
+	return "request_context"
+}
+
+// Description returns the tool description.
+func (t *RequestContextTool) Description() string {
+	return fmt.Sprintf("Fetch several files at once when you know what you need, instead of calling read_file for each. "+
+		"List files and globs (e.g. 'pkg/auth/*.go', 'src/**/*.test.ts'); they are returned line-numbered, in the order given, within a token budget "+
+		"(default %d). Large files come back as an outline of their declarations with line numbers, to read in ranges with read_file; "+
+		"files past the budget are listed as omitted.", defaultContextTokens)
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *RequestContextTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"paths": map[string]interface{}{
+				"type":        "array",
+				"description": "Files or glob patterns to fetch (relative to workspace), most important first. '**' matches any number of directories.",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+			},
+			"max_tokens": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Approximate token budget for the whole result (default: %d, max: %d)", defaultContextTokens, maxContextTokens),
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{"paths"},
+	)
+}
+
+// Execute fetches the requested files.
+func (t *RequestContextTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult fetches the requested files, returning each included file
+// as an artifact.
+func (t *RequestContextTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		Paths        []string `xml:"paths>path"`
+		MaxTokens    int      `xml:"max_tokens"`
+		OutputFormat string   `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var requested []string
+	for _, path := range input.Paths {
+		if path = strings.TrimSpace(path); path != "" {
+			requested = append(requested, path)
+		}
+	}
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("missing required parameter: paths")
+	}
+	if input.MaxTokens < 0 {
+		return nil, fmt.Errorf("invalid max_tokens %d: must be positive", input.MaxTokens)
+	}
+	budget := input.MaxTokens
+	if budget == 0 {
+		budget = defaultContextTokens
+	}
+	budget = min(budget, maxContextTokens)
+
+	files, omitted, err := t.resolve(ctx, requested)
+	if err != nil {
+		return nil, err
+	}
+	payload := t.fetch(files, budget)
+	payload.Omitted = append(omitted, payload.Omitted...)
+
+	artifacts := make([]tools.Artifact, 0, len(payload.Files))
+	for _, file := range payload.Files {
+		artifacts = append(artifacts, tools.Artifact{Kind: tools.ArtifactFile, Path: file.Path})
+	}
+	summary := fmt.Sprintf("Fetched %d file(s), ~%d of %d tokens", len(payload.Files), payload.TokensUsed, payload.MaxTokens)
+	if len(payload.Omitted) > 0 {
+		summary += fmt.Sprintf(", %d omitted", len(payload.Omitted))
+	}
+	return newResult(format, formatContext(payload), summary, payload, artifacts...)
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *RequestContextTool) IsLoopBreaking() bool {
+	return false
+}
+
+// Ways a requested file is included
+const (
+	contextModeFull    = "full"
+	contextModeOutline = "outline"
+)
+
+// contextFile is a file included in a request_context result
+type contextFile struct {
+	Path       string         `json:"path"`
+	Mode       string         `json:"mode"` // contextModeFull or contextModeOutline
+	TotalLines int            `json:"total_lines"`
+	Tokens     int            `json:"tokens"` // Estimated tokens of the lines included
+	Lines      []numberedLine `json:"lines"`
+}
+
+// contextOmission is a requested file or pattern left out, and why
+type contextOmission struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// requestContextJSONResult is the structured request_context result for output_format=json.
+type requestContextJSONResult struct {
+	Files      []contextFile     `json:"files"`
+	Omitted    []contextOmission `json:"omitted,omitempty"`
+	TokensUsed int               `json:"tokens_used"`
+	MaxTokens  int               `json:"max_tokens"`
+}
+
+// resolve expands the requested paths and globs into workspace files, in
+// the order requested and without duplicates. Paths that can't be used are
+// returned as omissions rather than failing the whole request.
+func (t *RequestContextTool) resolve(ctx context.Context, requested []string) ([]string, []contextOmission, error) {
+	var files []string
+	var omitted []contextOmission
+	seen := make(map[string]bool)
+	add := func(absPath string) {
+		if seen[absPath] {
+			return
+		}
+		seen[absPath] = true
+		if len(files) == maxContextFiles {
+			rel, _ := t.guard.MakeRelative(absPath)
+			omitted = append(omitted, contextOmission{Path: filepath.ToSlash(rel), Reason: fmt.Sprintf("over the limit of %d files per request", maxContextFiles)})
+			return
+		}
+		files = append(files, absPath)
+	}
+
+	for _, path := range requested {
+		if !isGlob(path) {
+			absPath, reason := t.resolveFile(path)
+			if reason != "" {
+				omitted = append(omitted, contextOmission{Path: path, Reason: reason})
+				continue
+			}
+			add(absPath)
+			continue
+		}
+
+		matches, err := t.glob(ctx, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(matches) == 0 {
+			omitted = append(omitted, contextOmission{Path: path, Reason: "no files match"})
+		}
+		for _, match := range matches {
+			add(match)
+		}
+	}
+	return files, omitted, nil
+}
+
+// resolveFile resolves a requested file, or says why it can't be read
+func (t *RequestContextTool) resolveFile(path string) (string, string) {
+	if err := t.guard.ValidatePath(path); err != nil {
+		return "", fmt.Sprintf("invalid path: %v", err)
+	}
+	absPath, err := t.guard.ResolvePath(path)
+	if err != nil {
+		return "", fmt.Sprintf("failed to resolve path: %v", err)
+	}
+	if t.guard.ShouldIgnore(absPath) {
+		return "", "ignored by .gitignore, .forgeignore, or default patterns"
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", "does not exist"
+	}
+	if info.IsDir() {
+		return "", "is a directory; use a glob such as " + strings.TrimSuffix(filepath.ToSlash(path), "/") + "/*"
+	}
+	return absPath, ""
+}
+
+// glob returns the workspace files matching pattern, in lexical order
+func (t *RequestContextTool) glob(ctx context.Context, pattern string) ([]string, error) {
+	pattern = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(pattern)), "./")
+	if _, err := filepath.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+
+	// Walk only below the directories before the first wildcard
+	var base []string
+	for _, segment := range strings.Split(pattern, "/") {
+		if isGlob(segment) {
+			break
+		}
+		base = append(base, segment)
+	}
+	root := filepath.Join(t.guard.WorkspaceDir(), filepath.FromSlash(strings.Join(base, "/")))
+	if !t.guard.IsWithinWorkspace(root) {
+		return nil, nil
+	}
+	if _, err := os.Stat(root); err != nil {
+		return nil, nil
+	}
+
+	candidates, err := t.index.files(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, file := range candidates {
+		rel, err := t.guard.MakeRelative(file)
+		if err != nil {
+			continue
+		}
+		if matchPath(pattern, filepath.ToSlash(rel)) {
+			matches = append(matches, file)
+		}
+	}
+	return matches, nil
+}
+
+// fetch reads the files in order, within the token budget
+func (t *RequestContextTool) fetch(files []string, budget int) requestContextJSONResult {
+	result := requestContextJSONResult{Files: []contextFile{}, MaxTokens: budget}
+	for _, absPath := range files {
+		rel, _ := t.guard.MakeRelative(absPath)
+		path := filepath.ToSlash(rel)
+
+		data, err := os.ReadFile(absPath)
+		if err != nil {
+			result.Omitted = append(result.Omitted, contextOmission{Path: path, Reason: fmt.Sprintf("failed to read: %v", err)})
+			continue
+		}
+		if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+			result.Omitted = append(result.Omitted, contextOmission{Path: path, Reason: "binary file"})
+			continue
+		}
+
+		lines := splitNumberedLines(string(data))
+		file := contextFile{Path: path, Mode: contextModeFull, TotalLines: len(lines), Lines: lines, Tokens: estimateLineTokens(lines)}
+		remaining := budget - result.TokensUsed
+		if file.Tokens > remaining || file.Tokens > largeFileTokens {
+			file.Mode = contextModeOutline
+			file.Lines = outlineLines(lines)
+			file.Tokens = estimateLineTokens(file.Lines)
+		}
+		if file.Tokens > remaining || file.Mode == contextModeOutline && len(file.Lines) == 0 {
+			reason := fmt.Sprintf("over the token budget (~%d tokens)", estimateLineTokens(lines))
+			if file.Tokens <= remaining {
+				reason += " with nothing to outline; read it in ranges with read_file"
+			}
+			result.Omitted = append(result.Omitted, contextOmission{Path: path, Reason: reason})
+			continue
+		}
+		result.Files = append(result.Files, file)
+		result.TokensUsed += file.Tokens
+	}
+	return result
+}
+
+// splitNumberedLines splits text into numbered lines
+func splitNumberedLines(text string) []numberedLine {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return []numberedLine{}
+	}
+	raw := strings.Split(text, "\n")
+	lines := make([]numberedLine, len(raw))
+	for i, line := range raw {
+		lines[i] = numberedLine{Number: i + 1, Text: strings.TrimSuffix(line, "\r")}
+	}
+	return lines
+}
+
+// outlineLines returns the declaration and heading lines of a file
+func outlineLines(lines []numberedLine) []numberedLine {
+	outline := []numberedLine{}
+	for _, line := range lines {
+		if outlinePattern.MatchString(line.Text) {
+			outline = append(outline, line)
+		}
+	}
+	return outline
+}
+
+// estimateLineTokens estimates the tokens of lines as formatted by
+// formatNumberedLines
+func estimateLineTokens(lines []numberedLine) int {
+	chars := 0
+	for _, line := range lines {
+		chars += len(line.Text) + len(fmt.Sprint(line.Number)) + 4
+	}
+	return (chars + contextCharsPerToken - 1) / contextCharsPerToken
+}
+
+// formatContext renders the fetched files, each under a header, followed
+// by the omissions
+func formatContext(result requestContextJSONResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d file(s), ~%d of %d tokens", len(result.Files), result.TokensUsed, result.MaxTokens)
+	for _, file := range result.Files {
+		if file.Mode == contextModeOutline {
+			fmt.Fprintf(&b, "\n\n== %s (%d lines, outline: read ranges with read_file) ==\n", file.Path, file.TotalLines)
+		} else {
+			fmt.Fprintf(&b, "\n\n== %s (%d lines) ==\n", file.Path, file.TotalLines)
+		}
+		b.WriteString(formatNumberedLines(file.Lines))
+	}
+	if len(result.Omitted) > 0 {
+		b.WriteString("\n\nOmitted:")
+		for _, omission := range result.Omitted {
+			fmt.Fprintf(&b, "\n  %s: %s", omission.Path, omission.Reason)
+		}
+	}
+	return b.String()
+}
+
+// isGlob reports whether path has glob wildcards
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// matchPath matches a slash-separated path against a glob pattern, where
+// '**' matches any number of directories and other segments match as for
+// filepath.Match
+func matchPath(pattern, path string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	matched, err := filepath.Match(pattern[0], path[0])
+	return err == nil && matched && matchSegments(pattern[1:], path[1:])
+}
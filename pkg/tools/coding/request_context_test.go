@@ -0,0 +1,95 @@
+package coding
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestRequestContextTool(t *testing.T) {
+	large := "package store\n\n" + strings.Repeat("// filler line to make the file large enough to outline\n", 700) +
+		"type Store struct{}\n\nfunc (s *Store) Get(key string) string {\n\treturn key\n}\n"
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"main.go":              "package main\n\nfunc main() {}\n",
+		"pkg/auth/auth.go":     "package auth\n",
+		"pkg/auth/token.go":    "package auth\n\nconst ttl = 60\n",
+		"pkg/auth/sub/deep.go": "package sub\n",
+		"pkg/store/store.go":   large,
+	})
+	tool := NewRequestContextTool(ws.Guard())
+
+	result, err := tool.Execute(context.Background(), []byte(`<arguments>
+	<paths>
+		<path>main.go</path>
+		<path>pkg/auth/*.go</path>
+		<path>main.go</path>
+		<path>pkg/store/store.go</path>
+		<path>missing.go</path>
+		<path>pkg/none/*.go</path>
+	</paths>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"== main.go (3 lines) ==\n1 | package main\n2 | \n3 | func main() {}",
+		"== pkg/auth/auth.go (1 lines) ==",
+		"== pkg/auth/token.go (3 lines) ==\n1 | package auth\n2 | \n3 | const ttl = 60",
+		"== pkg/store/store.go (707 lines, outline: read ranges with read_file) ==\n1 | package store\n703 | type Store struct{}\n705 | func (s *Store) Get(key string) string {",
+		"Omitted:\n  missing.go: does not exist\n  pkg/none/*.go: no files match",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected result to contain %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Count(result, "== main.go") != 1 {
+		t.Errorf("expected main.go once, got:\n%s", result)
+	}
+	if strings.Contains(result, "deep.go") || strings.Contains(result, "filler") {
+		t.Errorf("expected no files outside the glob and an outline without the filler, got:\n%s", result)
+	}
+}
+
+func TestRequestContextTool_Budget(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"a.txt": strings.Repeat("first file\n", 20),
+		"b.txt": strings.Repeat("second file\n", 100),
+	})
+	tool := NewRequestContextTool(ws.Guard())
+
+	result, err := tool.Execute(context.Background(), []byte(`<arguments>
+	<paths><path>*.txt</path></paths>
+	<max_tokens>200</max_tokens>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(result, "== a.txt (20 lines) ==") {
+		t.Errorf("expected the first file in full, got:\n%s", result)
+	}
+	if !strings.Contains(result, "b.txt: over the token budget") {
+		t.Errorf("expected the second file to be omitted, got:\n%s", result)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/main.go", false},
+		{"pkg/**/*.go", "pkg/main.go", true},
+		{"pkg/**/*.go", "pkg/a/b/main.go", true},
+		{"**/*_test.go", "pkg/a/main_test.go", true},
+		{"pkg/*/main.go", "pkg/a/b/main.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -4,25 +4,42 @@ import (
 	"bufio"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/security/workspace"
 )
 
+const (
+	// searchTimeout bounds a single search, so a slow pattern over a large
+	// tree returns what it found instead of holding up the agent loop
+	searchTimeout = 30 * time.Second
+
+	// maxSearchMatches is how many matches a search collects before stopping
+	maxSearchMatches = 500
+
+	// cancelCheckLines is how many lines are scanned between checks of the
+	// search's deadline
+	cancelCheckLines = 1000
+)
+
 // SearchFilesTool searches for patterns in files using regular expressions.
 type SearchFilesTool struct {
 	guard *workspace.Guard
+	index *fileIndex
 }
 
 // NewSearchFilesTool creates a new SearchFilesTool with workspace security.
 func NewSearchFilesTool(guard *workspace.Guard) *SearchFilesTool {
 	return &SearchFilesTool{
 		guard: guard,
+		index: newFileIndex(guard),
 	}
 }
 
@@ -33,7 +50,7 @@ func (t *SearchFilesTool) Name() string {
 
 // Description returns the tool description.
 func (t *SearchFilesTool) Description() string {
-	return "Search for patterns in files using regular expressions. Returns matches with surrounding context lines."
+	return "Search for patterns in files using regular expressions. Returns matches with surrounding context lines. A search stops after 500 matches or 30 seconds and reports the matches found so far."
 }
 
 // Schema returns the JSON schema for the tool's input parameters.
@@ -56,6 +73,7 @@ func (t *SearchFilesTool) Schema() map[string]interface{} {
 				"type":        "integer",
 				"description": "Number of context lines to show before and after match (default: 2)",
 			},
+			"output_format": outputFormatProperty(),
 		},
 		[]string{"pattern"}, // pattern is required
 	)
@@ -63,6 +81,12 @@ func (t *SearchFilesTool) Schema() map[string]interface{} {
 
 // Execute searches for the pattern in files.
 func (t *SearchFilesTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult searches for the pattern, returning the first match in each
+// matching file as an artifact.
+func (t *SearchFilesTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
 	// Parse arguments
 	var input struct {
 		XMLName      xml.Name `xml:"arguments"`
@@ -70,14 +94,20 @@ func (t *SearchFilesTool) Execute(ctx context.Context, argsXML []byte) (string,
 		Pattern      string   `xml:"pattern"`
 		FilePattern  string   `xml:"file_pattern"`
 		ContextLines int      `xml:"context_lines"`
+		OutputFormat string   `xml:"output_format"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
 	}
 
 	if input.Pattern == "" {
-		return "", fmt.Errorf("missing required parameter: pattern")
+		return nil, fmt.Errorf("missing required parameter: pattern")
 	}
 
 	// Default to workspace root if no path provided
@@ -92,29 +122,48 @@ func (t *SearchFilesTool) Execute(ctx context.Context, argsXML []byte) (string,
 
 	// Validate path with workspace guard
 	if err := t.guard.ValidatePath(input.Path); err != nil {
-		return "", fmt.Errorf("invalid path: %w", err)
+		return nil, fmt.Errorf("invalid path: %w", err)
 	}
 
 	// Resolve to absolute path
 	absPath, err := t.guard.ResolvePath(input.Path)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve path: %w", err)
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	// Compile regex pattern
 	regex, err := regexp.Compile(input.Pattern)
 	if err != nil {
-		return "", fmt.Errorf("invalid regex pattern: %w", err)
+		return nil, fmt.Errorf("invalid regex pattern: %w", err)
 	}
 
 	// Search files
-	matches, err := t.searchDirectory(absPath, regex, input.FilePattern, input.ContextLines)
+	result, err := t.searchDirectory(ctx, absPath, regex, input.FilePattern, input.ContextLines)
 	if err != nil {
-		return "", fmt.Errorf("search failed: %w", err)
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	// Format output; only the text output is paged
+	payload := t.matchesJSON(result)
+	var text string
+	if format == OutputFormatText {
+		output, err := t.formatMatches(result)
+		if err != nil {
+			return nil, err
+		}
+		text = tools.PaginateResult(ctx, output)
 	}
 
-	// Format output
-	return t.formatMatches(matches)
+	var artifacts []tools.Artifact
+	seen := make(map[string]bool)
+	for _, match := range payload.Matches {
+		if !seen[match.File] {
+			seen[match.File] = true
+			artifacts = append(artifacts, tools.Artifact{Kind: tools.ArtifactFile, Path: match.File, Line: match.Line})
+		}
+	}
+	summary := fmt.Sprintf("Found %d matches in %d files", payload.TotalCount, len(artifacts))
+	return newResult(format, text, summary, payload, artifacts...)
 }
 
 // IsLoopBreaking returns false as this tool doesn't break the agent loop.
@@ -131,69 +180,115 @@ type searchMatch struct {
 	ContextFrom int      // Starting line number of context
 }
 
-// searchDirectory searches all files in a directory recursively.
-func (t *SearchFilesTool) searchDirectory(dirPath string, regex *regexp.Regexp, filePattern string, contextLines int) ([]searchMatch, error) {
-	var matches []searchMatch
+// searchResult is what a search found
+type searchResult struct {
+	matches []searchMatch
+	skipped []skippedFile // Files not read for their size or content
+	stopped string        // Why the search ended early, if it did
+}
 
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip entries with errors
-		}
+// searchFilesJSONResult is the structured search_files result for output_format=json.
+type searchFilesJSONResult struct {
+	Matches    []searchFilesJSONMatch `json:"matches"`
+	TotalCount int                    `json:"total_count"`
+	Stopped    string                 `json:"stopped,omitempty"` // Why the search ended early, if it did
+	Skipped    []searchFilesJSONSkip  `json:"skipped,omitempty"`
+}
 
-		// Skip directories
-		if info.IsDir() {
-			// Check if directory is within workspace
-			if !t.guard.IsWithinWorkspace(path) {
-				return filepath.SkipDir
-			}
-			// Skip ignored directories
-			if t.guard.ShouldIgnore(path) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
+// searchFilesJSONSkip is a file the search did not read, and why.
+type searchFilesJSONSkip struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
 
-		// Check if path is within workspace
-		if !t.guard.IsWithinWorkspace(path) {
-			return nil
-		}
+// searchFilesJSONMatch is a single match in the structured search_files result.
+type searchFilesJSONMatch struct {
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Text        string   `json:"text"`
+	ContextFrom int      `json:"context_from"`
+	Context     []string `json:"context,omitempty"` // Excludes the matching line itself
+}
 
-		// Skip ignored files
-		if t.guard.ShouldIgnore(path) {
-			return nil
-		}
+// searchDirectory searches all files in a directory recursively, reporting
+// progress as it goes. Binary files, minified ones and those over the size
+// limit are skipped and recorded. A search that reaches maxSearchMatches or
+// runs past searchTimeout ends early with the matches found so far and the
+// reason.
+func (t *SearchFilesTool) searchDirectory(ctx context.Context, dirPath string, regex *regexp.Regexp, filePattern string, contextLines int) (*searchResult, error) {
+	searchCtx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	result := &searchResult{}
+	files, err := t.index.files(searchCtx, dirPath)
+	if err != nil {
+		result.stopped = t.stopReason(ctx, searchCtx)
+		return result, t.searchError(ctx, err)
+	}
 
+	limits := t.guard.ContentLimits()
+	searched := 0
+	for _, path := range files {
 		// Apply file pattern filter if specified
 		if filePattern != "" {
 			matched, matchErr := filepath.Match(filePattern, filepath.Base(path))
 			if matchErr != nil {
-				return fmt.Errorf("invalid file pattern: %w", matchErr)
+				return nil, fmt.Errorf("invalid file pattern: %w", matchErr)
 			}
 			if !matched {
-				return nil
+				continue
 			}
 		}
 
-		// Skip binary files (simple heuristic)
-		if isBinaryFile(path) {
-			return nil
+		// Skip binary, minified and oversized files
+		if reason := contentSkipReason(path, limits); reason != "" {
+			result.skipped = append(result.skipped, skippedFile{Path: path, Reason: reason})
+			continue
 		}
 
 		// Search file
-		fileMatches, err := t.searchFile(path, regex, contextLines)
+		searched++
+		if searched%progressEvery == 0 {
+			tools.ReportProgress(ctx, "searched %d files, %d matches so far…", searched, len(result.matches))
+		}
+		fileMatches, err := t.searchFile(searchCtx, path, regex, contextLines, maxSearchMatches-len(result.matches))
+		result.matches = append(result.matches, fileMatches...)
+		if searchCtx.Err() != nil {
+			result.stopped = t.stopReason(ctx, searchCtx)
+			return result, t.searchError(ctx, searchCtx.Err())
+		}
 		if err != nil {
-			return nil // Skip files we can't read
+			continue // Skip files we can't read
+		}
+		if len(result.matches) >= maxSearchMatches {
+			result.stopped = fmt.Sprintf("reached the limit of %d matches", maxSearchMatches)
+			return result, nil
 		}
+	}
 
-		matches = append(matches, fileMatches...)
-		return nil
-	})
+	return result, nil
+}
+
+// stopReason describes why a search ended early when it ran out of time
+func (t *SearchFilesTool) stopReason(ctx, searchCtx context.Context) string {
+	if ctx.Err() == nil && errors.Is(searchCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Sprintf("timed out after %s", searchTimeout)
+	}
+	return ""
+}
 
-	return matches, err
+// searchError returns err unless it is the search's own timeout, which
+// ends the search with partial results instead
+func (t *SearchFilesTool) searchError(ctx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
 }
 
-// searchFile searches for pattern in a single file.
-func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, contextLines int) ([]searchMatch, error) {
+// searchFile searches for pattern in a single file, returning at most limit
+// matches. Matches found before ctx is done are returned with its error.
+func (t *SearchFilesTool) searchFile(ctx context.Context, filePath string, regex *regexp.Regexp, contextLines, limit int) ([]searchMatch, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -204,12 +299,22 @@ func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, cont
 	var lines []string
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
+	lastLine := -1 // Once the limit is reached, the last line needed for context
 
 	// Read all lines first (for context)
 	for scanner.Scan() {
 		lineNum++
+		if lineNum%cancelCheckLines == 0 && ctx.Err() != nil {
+			break
+		}
 		line := scanner.Text()
 		lines = append(lines, line)
+		if lastLine >= 0 {
+			if lineNum >= lastLine {
+				break
+			}
+			continue
+		}
 
 		// Check if line matches
 		if regex.MatchString(line) {
@@ -218,11 +323,6 @@ func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, cont
 			if contextFrom < 1 {
 				contextFrom = 1
 			}
-			contextTo := lineNum + contextLines
-			if contextTo > len(lines) {
-				contextTo = len(lines)
-			}
-			_ = contextTo // Will be used when adding context lines later
 
 			// Collect context lines (we'll update this after reading all lines)
 			match := searchMatch{
@@ -232,6 +332,12 @@ func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, cont
 				ContextFrom: contextFrom,
 			}
 			matches = append(matches, match)
+			if len(matches) >= limit {
+				lastLine = lineNum + contextLines
+				if lineNum >= lastLine {
+					break
+				}
+			}
 		}
 	}
 
@@ -255,13 +361,22 @@ func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, cont
 		}
 	}
 
-	return matches, nil
+	return matches, ctx.Err()
 }
 
 // formatMatches formats search matches into a readable string.
-func (t *SearchFilesTool) formatMatches(matches []searchMatch) (string, error) {
+func (t *SearchFilesTool) formatMatches(result *searchResult) (string, error) {
+	matches, stopped := result.matches, result.stopped
+	skipped := summarizeSkipped(result.skipped, t.guard.ContentLimits(), t.relativePath)
 	if len(matches) == 0 {
-		return "No matches found", nil
+		summary := "No matches found"
+		if stopped != "" {
+			summary += " before the search " + stopped
+		}
+		if skipped != "" {
+			summary += "\n" + skipped
+		}
+		return summary, nil
 	}
 
 	var builder strings.Builder
@@ -310,46 +425,54 @@ func (t *SearchFilesTool) formatMatches(matches []searchMatch) (string, error) {
 
 	// Add summary
 	builder.WriteString(fmt.Sprintf("Found %d matches", len(matches)))
+	if stopped != "" {
+		builder.WriteString(fmt.Sprintf(" (search stopped early: %s; narrow the path, file_pattern or pattern to see the rest)", stopped))
+	}
+	if skipped != "" {
+		builder.WriteString("\n" + skipped)
+	}
 
 	return builder.String(), nil
 }
 
-// isBinaryFile performs a simple check to determine if a file is binary.
-// This is a heuristic and may not be 100% accurate.
-func isBinaryFile(path string) bool {
-	// Check file extension first (common binary extensions)
-	ext := strings.ToLower(filepath.Ext(path))
-	binaryExts := map[string]bool{
-		".exe": true, ".dll": true, ".so": true, ".dylib": true,
-		".bin": true, ".dat": true, ".db": true,
-		".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true,
-		".pdf": true, ".zip": true, ".tar": true, ".gz": true,
-		".mp3": true, ".mp4": true, ".avi": true, ".mov": true,
-		".o": true, ".a": true, ".pyc": true,
-	}
-	if binaryExts[ext] {
-		return true
-	}
-
-	// Read first few bytes to check for binary content
-	file, err := os.Open(path)
-	if err != nil {
-		return false
+// matchesJSON returns search matches as the structured result.
+func (t *SearchFilesTool) matchesJSON(search *searchResult) searchFilesJSONResult {
+	result := searchFilesJSONResult{
+		Matches:    make([]searchFilesJSONMatch, 0, len(search.matches)),
+		TotalCount: len(search.matches),
+		Stopped:    search.stopped,
 	}
-	defer file.Close()
 
-	buf := make([]byte, 512)
-	n, err := file.Read(buf)
-	if err != nil {
-		return false
+	limits := t.guard.ContentLimits()
+	for _, file := range search.skipped {
+		result.Skipped = append(result.Skipped, searchFilesJSONSkip{
+			File:   filepath.ToSlash(t.relativePath(file.Path)),
+			Reason: describeSkipReason(file.Reason, limits),
+		})
 	}
 
-	// Check for null bytes (common in binary files)
-	for i := 0; i < n; i++ {
-		if buf[i] == 0 {
-			return true
+	for _, match := range search.matches {
+		relPath, err := t.guard.MakeRelative(match.FilePath)
+		if err != nil {
+			relPath = match.FilePath
 		}
+
+		result.Matches = append(result.Matches, searchFilesJSONMatch{
+			File:        filepath.ToSlash(relPath),
+			Line:        match.LineNumber,
+			Text:        match.Line,
+			ContextFrom: match.ContextFrom,
+			Context:     match.Context,
+		})
 	}
 
-	return false
+	return result
+}
+
+// relativePath returns path relative to the workspace for display
+func (t *SearchFilesTool) relativePath(path string) string {
+	if relPath, err := t.guard.MakeRelative(path); err == nil {
+		return relPath
+	}
+	return path
 }
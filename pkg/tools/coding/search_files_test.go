@@ -0,0 +1,137 @@
+package coding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// newSearchWorkspace creates a workspace holding files and a search tool for it
+func newSearchWorkspace(t *testing.T, files workspacetest.Tree) (string, *SearchFilesTool) {
+	t.Helper()
+	ws := workspacetest.New(t, files)
+	return ws.Dir, NewSearchFilesTool(ws.Guard())
+}
+
+// ageDirs sets every directory's modification time an hour back, so the
+// index trusts their cached listings
+func ageDirs(t *testing.T, root string) {
+	t.Helper()
+	old := time.Now().Add(-time.Hour)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			err = os.Chtimes(path, old, old)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to age dirs: %v", err)
+	}
+}
+
+func TestFileIndex_ReadsOnlyChangedDirectories(t *testing.T) {
+	dir, tool := newSearchWorkspace(t, map[string]string{
+		"a.go":         "package a\n",
+		"pkg/b.go":     "package b\n",
+		"pkg/sub/c.go": "package c\n",
+	})
+	ageDirs(t, dir)
+
+	files, err := tool.index.files(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("files failed: %v", err)
+	}
+	want := []string{"a.go", "pkg/b.go", "pkg/sub/c.go"}
+	if len(files) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, files)
+	}
+	for i, name := range want {
+		if files[i] != filepath.Join(dir, name) {
+			t.Errorf("files[%d] = %s, want %s", i, files[i], name)
+		}
+	}
+
+	sub := tool.index.dirs[filepath.Join(dir, "pkg", "sub")]
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "new.go"), []byte("package b\n"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	files, _ = tool.index.files(context.Background(), dir)
+	if len(files) != 4 || files[2] != filepath.Join(dir, "pkg", "new.go") {
+		t.Errorf("Expected the new file to be found, got %v", files)
+	}
+	if tool.index.dirs[filepath.Join(dir, "pkg", "sub")] != sub {
+		t.Error("Expected the unchanged directory's listing to be reused")
+	}
+}
+
+func TestFileIndex_SkipsIgnored(t *testing.T) {
+	_, tool := newSearchWorkspace(t, map[string]string{
+		".gitignore":        "build/\n",
+		"main.go":           "needle\n",
+		"build/out.go":      "needle\n",
+		"node_modules/x.js": "needle\n",
+	})
+
+	out, err := tool.Execute(context.Background(), []byte(`<arguments><pattern>needle</pattern></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(out, "main.go") || strings.Contains(out, "out.go") || strings.Contains(out, "x.js") {
+		t.Errorf("Expected only main.go to match, got:\n%s", out)
+	}
+}
+
+func TestSearchFiles_StopsAtMaxMatches(t *testing.T) {
+	var many strings.Builder
+	for i := 0; i < maxSearchMatches+100; i++ {
+		fmt.Fprintf(&many, "match %d\n", i)
+	}
+	_, tool := newSearchWorkspace(t, map[string]string{
+		"a.txt": many.String(),
+		"b.txt": "match in another file\n",
+	})
+
+	out, err := tool.Execute(context.Background(), []byte(`<arguments><pattern>match</pattern><output_format>json</output_format></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	var result searchFilesJSONResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	if result.TotalCount != maxSearchMatches || !strings.Contains(result.Stopped, "limit") {
+		t.Errorf("Expected %d matches and a stop reason, got %d and %q", maxSearchMatches, result.TotalCount, result.Stopped)
+	}
+
+	// Context after the last match is still included
+	last := result.Matches[len(result.Matches)-1]
+	if last.File != "a.txt" || len(last.Context) != 4 {
+		t.Errorf("Expected context around the last match, got %+v", last)
+	}
+}
+
+func TestSearchFile_StopsWhenDone(t *testing.T) {
+	dir, tool := newSearchWorkspace(t, map[string]string{
+		"big.txt": strings.Repeat("line\n", 10*cancelCheckLines),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	matches, err := tool.searchFile(ctx, filepath.Join(dir, "big.txt"), regexp.MustCompile("line"), 0, 10*cancelCheckLines)
+	if err != context.Canceled {
+		t.Fatalf("Expected the cancellation, got %v", err)
+	}
+	if len(matches) != cancelCheckLines-1 {
+		t.Errorf("Expected the matches before the first check, got %d", len(matches))
+	}
+}
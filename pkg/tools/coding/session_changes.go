@@ -0,0 +1,123 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// SessionChangesTool lists the files changed in the workspace, marking the
+// ones the agent changed itself, so the model can reason about what it has
+// already modified without re-reading files or diffs.
+type SessionChangesTool struct {
+	tracker *git.ModificationTracker
+}
+
+// NewSessionChangesTool creates a new SessionChangesTool backed by tracker.
+func NewSessionChangesTool(tracker *git.ModificationTracker) *SessionChangesTool {
+	return &SessionChangesTool{tracker: tracker}
+}
+
+// Name returns the tool name.
+func (t *SessionChangesTool) Name() string {
+	return "session_changes"
+}
+
+// Description returns the tool description.
+func (t *SessionChangesTool) Description() string {
+	return "List the files changed in the workspace (created, modified, deleted, renamed, or mode changed), marking those you changed this session. Cheaper than workspace_diff when you only need to know what changed."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *SessionChangesTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"output_format": outputFormatProperty(),
+		},
+		[]string{},
+	)
+}
+
+// Execute refreshes the tracker from git status and lists the changes.
+func (t *SessionChangesTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		OutputFormat string   `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return "", err
+	}
+
+	// Outside a git repository only the agent's own changes are known
+	note := ""
+	if refreshErr := t.tracker.Refresh(ctx); refreshErr != nil {
+		note = "Git status unavailable; showing only files changed by your tools."
+	}
+	changes := t.tracker.GetModifications()
+
+	if format == OutputFormatJSON {
+		return marshalJSONResult(sessionChangesJSONResult{Changes: changes, Note: note})
+	}
+
+	return formatSessionChanges(changes, note), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *SessionChangesTool) IsLoopBreaking() bool {
+	return false
+}
+
+// sessionChangesJSONResult is the structured session_changes result for output_format=json.
+type sessionChangesJSONResult struct {
+	Changes []*git.FileModification `json:"changes"`
+	Note    string                  `json:"note,omitempty"`
+}
+
+// formatSessionChanges renders one line per changed file
+func formatSessionChanges(changes []*git.FileModification, note string) string {
+	var builder strings.Builder
+	if note != "" {
+		builder.WriteString(note + "\n")
+	}
+	if len(changes) == 0 {
+		builder.WriteString("No changed files")
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("%d file(s) changed:\n", len(changes)))
+	for _, change := range changes {
+		path := change.Path
+		if change.OldPath != "" {
+			path = change.OldPath + " → " + change.Path
+		}
+
+		var details []string
+		if change.OldMode != "" {
+			details = append(details, fmt.Sprintf("mode %s → %s", change.OldMode, change.NewMode))
+		}
+		if change.ByAgent {
+			details = append(details, "by you")
+		}
+		if change.Staged {
+			details = append(details, "staged")
+		}
+
+		line := fmt.Sprintf("  %-7s %s", change.Operation, path)
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
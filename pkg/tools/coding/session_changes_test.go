@@ -0,0 +1,38 @@
+package coding
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+)
+
+func TestSessionChangesTool_WithoutGit(t *testing.T) {
+	tracker := git.NewModificationTracker(t.TempDir())
+	tracker.Track("main.go", git.OpWrite)
+
+	result, err := NewSessionChangesTool(tracker).Execute(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	for _, want := range []string{"Git status unavailable", "1 file(s) changed", "write   main.go (by you)"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected result to contain %q, got:\n%s", want, result)
+		}
+	}
+}
+
+func TestFormatSessionChanges(t *testing.T) {
+	got := formatSessionChanges([]*git.FileModification{
+		{Path: "pkg/new.go", OldPath: "old.go", Operation: git.OpRename, Staged: true},
+		{Path: "run.sh", Operation: git.OpMode, OldMode: "100644", NewMode: "100755"},
+	}, "")
+
+	want := "2 file(s) changed:\n" +
+		"  rename  old.go → pkg/new.go (staged)\n" +
+		"  mode    run.sh (mode 100644 → 100755)"
+	if got != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
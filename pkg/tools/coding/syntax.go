@@ -0,0 +1,252 @@
+package coding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"gopkg.in/yaml.v3"
+)
+
+// SyntaxCheck is what write_file and apply_diff do when a file they change
+// no longer parses
+type SyntaxCheck string
+
+const (
+	// SyntaxCheckOff skips the check
+	SyntaxCheckOff SyntaxCheck = "off"
+
+	// SyntaxCheckReport keeps the change and lists the syntax errors in the
+	// tool result, so the model fixes them before it moves on (the default)
+	SyntaxCheckReport SyntaxCheck = "report"
+
+	// SyntaxCheckRevert restores the file and fails the call. Files that
+	// didn't parse before the change are only reported on, as a multi-step
+	// edit may pass through broken states.
+	SyntaxCheckRevert SyntaxCheck = "revert"
+)
+
+// EditOption configures write_file and apply_diff
+type EditOption func(*editOptions)
+
+// editOptions holds the settings shared by the file editing tools
+type editOptions struct {
+	syntaxCheck SyntaxCheck
+}
+
+// WithSyntaxCheck sets what happens when an edit leaves a file that doesn't
+// parse, SyntaxCheckReport by default
+func WithSyntaxCheck(check SyntaxCheck) EditOption {
+	return func(o *editOptions) {
+		o.syntaxCheck = check
+	}
+}
+
+// newEditOptions applies opts over the defaults
+func newEditOptions(opts []EditOption) editOptions {
+	o := editOptions{syntaxCheck: SyntaxCheckReport}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// maxSyntaxErrors bounds the syntax errors reported for a file; the first
+// is usually the one to fix
+const maxSyntaxErrors = 5
+
+// bracketLanguages are the extensions checked for balanced brackets, the
+// lexer telling code from strings and comments. Languages with a parser of
+// their own are checked with it instead.
+var bracketLanguages = map[string]bool{
+	".js": true, ".jsx": true, ".mjs": true, ".cjs": true, ".ts": true, ".tsx": true,
+	".java": true, ".kt": true, ".scala": true, ".swift": true, ".cs": true,
+	".rs": true, ".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true,
+	".php": true, ".py": true,
+}
+
+// yamlLine finds the line number in a YAML error
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+// checkSyntax parses content as the language of path, returning up to
+// maxSyntaxErrors errors as "path:line:col: message". It returns nil when
+// the content parses or the language isn't one it checks: Go, JSON and YAML
+// are parsed; JavaScript, TypeScript, Python, Rust, Java and other C-like
+// languages are checked for balanced brackets.
+func checkSyntax(path, content string) []string {
+	ext := strings.ToLower(filepath.Ext(path))
+	var errs []string
+	switch {
+	case ext == ".go":
+		errs = goSyntaxErrors(path, content)
+	case ext == ".json":
+		errs = jsonSyntaxErrors(path, content)
+	case ext == ".yaml" || ext == ".yml":
+		errs = yamlSyntaxErrors(path, content)
+	case bracketLanguages[ext]:
+		errs = bracketErrors(path, content)
+	}
+	if len(errs) > maxSyntaxErrors {
+		errs = append(errs[:maxSyntaxErrors], fmt.Sprintf("and %d more", len(errs)-maxSyntaxErrors))
+	}
+	return errs
+}
+
+// goSyntaxErrors parses Go source, reporting the first error on each line
+func goSyntaxErrors(path, content string) []string {
+	_, err := parser.ParseFile(token.NewFileSet(), path, content, parser.SkipObjectResolution)
+	if err == nil {
+		return nil
+	}
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+	errs := make([]string, len(list))
+	for i, e := range list {
+		errs[i] = e.Error()
+	}
+	return errs
+}
+
+// jsonSyntaxErrors parses JSON. Files with comments are skipped, as they
+// are JSON with comments (tsconfig.json, VS Code settings), which is not
+// JSON.
+func jsonSyntaxErrors(path, content string) []string {
+	if strings.TrimSpace(content) == "" || strings.Contains(content, "//") || strings.Contains(content, "/*") {
+		return nil
+	}
+	var value interface{}
+	err := json.Unmarshal([]byte(content), &value)
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return nil
+	}
+	line, col := position(content, int(syntaxErr.Offset)-1) // Offset is just past the error
+	return []string{fmt.Sprintf("%s:%d:%d: %v", path, line, col, syntaxErr)}
+}
+
+// yamlSyntaxErrors parses each document of a YAML file. Files with template
+// actions are skipped, as they are templates (Helm charts) rendered before
+// they are YAML.
+func yamlSyntaxErrors(path, content string) []string {
+	if strings.Contains(content, "{{") {
+		return nil
+	}
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			message := strings.TrimPrefix(err.Error(), "yaml: ")
+			if m := yamlLine.FindStringSubmatch(message); m != nil {
+				message = strings.TrimPrefix(message, m[0]+": ")
+				return []string{fmt.Sprintf("%s:%s: %s", path, m[1], message)}
+			}
+			return []string{fmt.Sprintf("%s: %s", path, message)}
+		}
+	}
+}
+
+// bracket is an open bracket and where it is
+type bracket struct {
+	char      rune
+	line, col int
+}
+
+// bracketErrors checks that the brackets outside strings and comments are
+// balanced, reporting the first that isn't
+func bracketErrors(path, content string) []string {
+	lexer := lexers.Match(filepath.Base(path))
+	if lexer == nil {
+		return nil
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, content)
+	if err != nil {
+		return nil
+	}
+
+	closers := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	var open []bracket
+	line, col := 1, 1
+	for t := iterator(); t != chroma.EOF; t = iterator() {
+		code := !t.Type.InCategory(chroma.LiteralString) && !t.Type.InCategory(chroma.Comment) &&
+			t.Type != chroma.LiteralStringChar
+		for _, r := range t.Value {
+			if code {
+				switch r {
+				case '(', '[', '{':
+					open = append(open, bracket{r, line, col})
+				case ')', ']', '}':
+					if len(open) == 0 {
+						return []string{fmt.Sprintf("%s:%d:%d: unexpected '%c'", path, line, col, r)}
+					}
+					last := open[len(open)-1]
+					if last.char != closers[r] {
+						return []string{fmt.Sprintf("%s:%d:%d: '%c' closes '%c' opened at line %d", path, line, col, r, last.char, last.line)}
+					}
+					open = open[:len(open)-1]
+				}
+			}
+			if r == '\n' {
+				line, col = line+1, 1
+			} else {
+				col++
+			}
+		}
+	}
+	if len(open) > 0 {
+		first := open[len(open)-1]
+		return []string{fmt.Sprintf("%s:%d:%d: '%c' is never closed", path, first.line, first.col, first.char)}
+	}
+	return nil
+}
+
+// position returns the line and column of a byte offset
+func position(content string, offset int) (int, int) {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	before := content[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := offset - strings.LastIndex(before, "\n")
+	return line, col
+}
+
+// checkEdit checks the content an edit leaves at path, given the content
+// before it (empty for a new file). It returns the syntax errors to report,
+// and whether the edit is refused rather than written.
+func (o editOptions) checkEdit(path, before, after string, existed bool) ([]string, bool) {
+	if o.syntaxCheck == SyntaxCheckOff {
+		return nil, false
+	}
+	errs := checkSyntax(path, after)
+	if len(errs) == 0 {
+		return nil, false
+	}
+	refuse := o.syntaxCheck == SyntaxCheckRevert && (!existed || checkSyntax(path, before) == nil)
+	return errs, refuse
+}
+
+// syntaxRefusal is the error of an edit refused for its syntax errors
+func syntaxRefusal(path string, errs []string) error {
+	return fmt.Errorf("change not applied: it would leave %s with syntax errors:\n%s\n%s is unchanged; correct the change and try again",
+		path, strings.Join(errs, "\n"), path)
+}
+
+// syntaxReport is appended to the result of an edit that left syntax errors
+func syntaxReport(errs []string) string {
+	return "\n\nThe file has syntax errors now; fix them before you go on:\n" + strings.Join(errs, "\n")
+}
@@ -0,0 +1,94 @@
+package coding
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestCheckSyntax(t *testing.T) {
+	tests := []struct {
+		name, path, content, want string
+	}{
+		{"valid go", "main.go", "package main\n\nfunc main() {}\n", ""},
+		{"go missing brace", "main.go", "package main\n\nfunc main() {\n\tif true {\n\t}\n", "main.go:5:4: expected '}', found 'EOF'"},
+		{"valid json", "a.json", `{"a": [1, 2]}`, ""},
+		{"json trailing comma", "a.json", "{\n  \"a\": 1,\n}", "a.json:3:1: invalid character '}' looking for beginning of object key string"},
+		{"json with comments", "tsconfig.json", "{\n  // strict\n  \"strict\": true,\n}", ""},
+		{"valid yaml", "a.yaml", "a: 1\n---\nb: [1, 2]\n", ""},
+		{"bad yaml", "a.yml", "a: 1\nb: [1, 2\n", "a.yml:1: did not find expected ',' or ']'"},
+		{"yaml template", "chart.yaml", "a: {{ .Values.a }\n", ""},
+		{"js braces in strings and comments", "a.js", "// {\nconst s = \"}\" + '(' + `[`;\nfunction f() { return [1]; }\n", ""},
+		{"js unclosed", "a.ts", "function f() {\n  return (1;\n}\n", "a.ts:3:1: '}' closes '(' opened at line 2"},
+		{"python unexpected", "a.py", "def f():\n    return 1)\n", "a.py:2:13: unexpected ')'"},
+		{"rust never closed", "lib.rs", "fn main() {\n    let c = '{';\n", "lib.rs:1:11: '{' is never closed"},
+		{"unchecked language", "notes.md", "(((", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkSyntax(tt.path, tt.content)
+			if tt.want == "" {
+				if errs != nil {
+					t.Errorf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.HasPrefix(errs[0], tt.want) {
+				t.Errorf("expected an error starting %q, got %v", tt.want, errs)
+			}
+		})
+	}
+}
+
+const validGo = "package main\n\nfunc main() {\n\tprintln(1)\n}\n"
+
+func TestApplyDiffReportsSyntaxErrors(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": validGo})
+	tool := NewApplyDiffTool(ws.Guard())
+
+	result, err := tool.ExecuteResult(context.Background(), []byte(`<arguments><path>main.go</path><edits><edit>
+<search>println(1)
+}</search><replace>println(1)</replace></edit></edits></arguments>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Output, "syntax errors now") || !strings.Contains(result.Output, "main.go:4:13: expected '}', found 'EOF'") {
+		t.Errorf("expected the syntax error in the result, got %q", result.Output)
+	}
+	if !strings.HasSuffix(result.Summary, "with syntax errors") {
+		t.Errorf("expected the summary to mention the errors, got %q", result.Summary)
+	}
+	ws.AssertNotContains("main.go", "println(1)\n}")
+}
+
+func TestSyntaxCheckRevert(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"main.go": validGo, "broken.go": "package main\n\nfunc a() {\n"})
+	diff := NewApplyDiffTool(ws.Guard(), WithSyntaxCheck(SyntaxCheckRevert))
+	write := NewWriteFileTool(ws.Guard(), WithSyntaxCheck(SyntaxCheckRevert))
+
+	_, err := diff.Execute(context.Background(), []byte(`<arguments><path>main.go</path><edits><edit>
+<search>println(1)</search><replace>println(1</replace></edit></edits></arguments>`))
+	if err == nil || !strings.Contains(err.Error(), "change not applied") {
+		t.Fatalf("expected the edit to be refused, got %v", err)
+	}
+	ws.AssertFile("main.go", validGo)
+
+	if _, err := write.Execute(context.Background(), []byte("<arguments><path>new.go</path><content>package main\nfunc {</content></arguments>")); err == nil {
+		t.Error("expected a new file with syntax errors to be refused")
+	}
+	ws.AssertMissing("new.go")
+
+	// A file that was already broken is only reported on
+	out, err := diff.Execute(context.Background(), []byte(`<arguments><path>broken.go</path><edits><edit>
+<search>func a() {</search><replace>func b() {</replace></edit></edits></arguments>`))
+	if err != nil || !strings.Contains(out, "syntax errors now") {
+		t.Errorf("expected the edit to be applied with a report, got %q, %v", out, err)
+	}
+
+	off := NewWriteFileTool(ws.Guard(), WithSyntaxCheck(SyntaxCheckOff))
+	if out, err := off.Execute(context.Background(), []byte("<arguments><path>new.go</path><content>func {</content></arguments>")); err != nil || strings.Contains(out, "syntax") {
+		t.Errorf("expected no check when off, got %q, %v", out, err)
+	}
+}
@@ -0,0 +1,165 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// WorkspaceDiffTool reports every change made to the workspace since the
+// session started, regardless of whether the agent, a command it ran, or
+// some other process made it.
+type WorkspaceDiffTool struct {
+	guard    *workspace.Guard
+	snapshot *git.Snapshot
+}
+
+// NewWorkspaceDiffTool creates a new WorkspaceDiffTool that diffs against the
+// given session-start snapshot.
+func NewWorkspaceDiffTool(guard *workspace.Guard, snapshot *git.Snapshot) *WorkspaceDiffTool {
+	return &WorkspaceDiffTool{
+		guard:    guard,
+		snapshot: snapshot,
+	}
+}
+
+// Name returns the tool name.
+func (t *WorkspaceDiffTool) Name() string {
+	return "workspace_diff"
+}
+
+// Description returns the tool description.
+func (t *WorkspaceDiffTool) Description() string {
+	return "Show the complete diff of the workspace since the session started, including files changed indirectly by commands or scripts. Use stat_only for a file summary."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *WorkspaceDiffTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional file or directory to limit the diff to (relative to workspace)",
+			},
+			"stat_only": map[string]interface{}{
+				"type":        "boolean",
+				"description": "List changed files without the full diff (default: false)",
+			},
+			"output_format": outputFormatProperty(),
+		},
+		[]string{},
+	)
+}
+
+// Execute computes the diff against the session-start snapshot.
+func (t *WorkspaceDiffTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		Path         string   `xml:"path"`
+		StatOnly     bool     `xml:"stat_only"`
+		OutputFormat string   `xml:"output_format"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return "", err
+	}
+
+	if t.snapshot == nil {
+		return "", fmt.Errorf("no session snapshot available (workspace is not a git repository)")
+	}
+
+	var paths []string
+	if input.Path != "" {
+		if validateErr := t.guard.ValidatePath(input.Path); validateErr != nil {
+			return "", fmt.Errorf("invalid path: %w", validateErr)
+		}
+		paths = append(paths, input.Path)
+	}
+
+	changes, err := t.snapshot.ChangedFiles(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute changes: %w", err)
+	}
+
+	diff := ""
+	if !input.StatOnly && len(changes) > 0 {
+		diff, err = t.snapshot.Diff(ctx, paths...)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute diff: %w", err)
+		}
+	}
+
+	if input.Path != "" {
+		changes = filterChanges(changes, input.Path)
+	}
+
+	if format == OutputFormatJSON {
+		return marshalJSONResult(workspaceDiffJSONResult{
+			Since:   t.snapshot.TakenAt().Format(time.RFC3339),
+			Changes: changes,
+			Diff:    diff,
+		})
+	}
+
+	return FormatWorkspaceChanges(changes, diff), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *WorkspaceDiffTool) IsLoopBreaking() bool {
+	return false
+}
+
+// workspaceDiffJSONResult is the structured workspace_diff result for output_format=json.
+type workspaceDiffJSONResult struct {
+	Since   string           `json:"since"`
+	Changes []git.FileChange `json:"changes"`
+	Diff    string           `json:"diff,omitempty"`
+}
+
+// FormatWorkspaceChanges renders a change summary followed by the diff.
+// It is shared with the TUI's /changes command.
+func FormatWorkspaceChanges(changes []git.FileChange, diff string) string {
+	if len(changes) == 0 {
+		return "No changes since session start"
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%d file(s) changed since session start:\n", len(changes)))
+	for _, change := range changes {
+		builder.WriteString(fmt.Sprintf("  %-12s %s\n", change.Status, change.Path))
+	}
+
+	if diff != "" {
+		builder.WriteString("\n")
+		builder.WriteString(diff)
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// filterChanges keeps only the changes at or beneath path.
+func filterChanges(changes []git.FileChange, path string) []git.FileChange {
+	prefix := strings.TrimSuffix(strings.TrimPrefix(path, "./"), "/")
+	if prefix == "" || prefix == "." {
+		return changes
+	}
+
+	var filtered []git.FileChange
+	for _, change := range changes {
+		if change.Path == prefix || strings.HasPrefix(change.Path, prefix+"/") {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/security/workspace"
@@ -13,13 +14,15 @@ import (
 
 // WriteFileTool creates or overwrites files with workspace validation.
 type WriteFileTool struct {
-	guard *workspace.Guard
+	guard   *workspace.Guard
+	options editOptions
 }
 
 // NewWriteFileTool creates a new WriteFileTool with workspace security.
-func NewWriteFileTool(guard *workspace.Guard) *WriteFileTool {
+func NewWriteFileTool(guard *workspace.Guard, opts ...EditOption) *WriteFileTool {
 	return &WriteFileTool{
-		guard: guard,
+		guard:   guard,
+		options: newEditOptions(opts),
 	}
 }
 
@@ -28,6 +31,12 @@ func (t *WriteFileTool) Name() string {
 	return "write_file"
 }
 
+// Aliases returns the tool's deprecated names: write_to_file is what the
+// prompts' examples and other agents call it.
+func (t *WriteFileTool) Aliases() []string {
+	return []string{"write_to_file"}
+}
+
 // Description returns the tool description.
 func (t *WriteFileTool) Description() string {
 	return "Write content to a file, creating it if it doesn't exist or overwriting if it does. Automatically creates parent directories as needed."
@@ -45,6 +54,7 @@ func (t *WriteFileTool) Schema() map[string]interface{} {
 				"type":        "string",
 				"description": "Content to write to the file",
 			},
+			"output_format": outputFormatProperty(),
 		},
 		[]string{"path", "content"},
 	)
@@ -52,35 +62,52 @@ func (t *WriteFileTool) Schema() map[string]interface{} {
 
 // Execute writes content to the specified file.
 func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return resultOutput(t.ExecuteResult(ctx, argsXML))
+}
+
+// ExecuteResult writes content to the specified file, returning the written
+// file as an artifact.
+func (t *WriteFileTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
 	var input struct {
-		XMLName xml.Name `xml:"arguments"`
-		Path    string   `xml:"path"`
-		Content string   `xml:"content"`
+		XMLName      xml.Name `xml:"arguments"`
+		Path         string   `xml:"path"`
+		Content      string   `xml:"content"`
+		OutputFormat string   `xml:"output_format"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
-		return "", fmt.Errorf("invalid arguments: %w", err)
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	format, err := resolveOutputFormat(ctx, input.OutputFormat)
+	if err != nil {
+		return nil, err
 	}
 
 	if input.Path == "" {
-		return "", fmt.Errorf("missing required parameter: path")
+		return nil, fmt.Errorf("missing required parameter: path")
 	}
+	return writeContent(ctx, t.guard, t.options, input.Path, input.Content, format)
+}
 
+// writeContent creates or overwrites the file at path with content, as
+// write_file and end_file do, returning the written file as an artifact
+func writeContent(ctx context.Context, guard *workspace.Guard, options editOptions, path, content, format string) (*tools.Result, error) {
 	// Validate path with workspace guard
-	if err := t.guard.ValidatePath(input.Path); err != nil {
-		return "", fmt.Errorf("invalid path: %w", err)
+	if err := guard.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
 	}
 
 	// Resolve to absolute path
-	absPath, err := t.guard.ResolvePath(input.Path)
+	absPath, err := guard.ResolvePath(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve path: %w", err)
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	// Create parent directories if they don't exist
 	dir := filepath.Dir(absPath)
 	if mkdirErr := os.MkdirAll(dir, 0755); mkdirErr != nil {
-		return "", fmt.Errorf("failed to create directories: %w", mkdirErr)
+		return nil, fmt.Errorf("failed to create directories: %w", mkdirErr)
 	}
 
 	// Check if file exists
@@ -89,33 +116,67 @@ func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, er
 		fileExists = true
 	}
 
-	// Write file atomically using a temporary file
-	tmpPath := absPath + ".tmp"
-	if writeErr := os.WriteFile(tmpPath, []byte(input.Content), 0600); writeErr != nil {
-		return "", fmt.Errorf("failed to write temporary file: %w", writeErr)
+	// Get relative path for output message
+	relPath, err := guard.MakeRelative(absPath)
+	if err != nil {
+		relPath = path // Fallback to original path
 	}
 
-	// Rename temporary file to target file (atomic operation)
-	if renameErr := os.Rename(tmpPath, absPath); renameErr != nil {
-		// Clean up temporary file on error
-		os.Remove(tmpPath)
-		return "", fmt.Errorf("failed to rename temporary file: %w", renameErr)
+	// Check that the content still parses, comparing with the file it replaces
+	var previous []byte
+	if fileExists && options.syntaxCheck != SyntaxCheckOff {
+		previous, _ = os.ReadFile(absPath)
+	}
+	syntaxErrs, refuse := options.checkEdit(relPath, string(previous), content, fileExists)
+	if refuse {
+		return nil, syntaxRefusal(relPath, syntaxErrs)
 	}
 
-	// Get relative path for output message
-	relPath, err := t.guard.MakeRelative(absPath)
-	if err != nil {
-		relPath = input.Path // Fallback to original path
+	// Stream the content to a temporary file and rename it into place, so an
+	// interrupted write never leaves a truncated file
+	if _, writeErr := writeFileAtomic(ctx, absPath, strings.NewReader(content)); writeErr != nil {
+		return nil, writeErr
 	}
 
-	var message string
+	var message, summary string
+	lines := countLines(content)
 	if fileExists {
 		message = fmt.Sprintf("File '%s' overwritten successfully", relPath)
+		summary = fmt.Sprintf("Overwrote %s (%d lines)", relPath, lines)
 	} else {
 		message = fmt.Sprintf("File '%s' created successfully", relPath)
+		summary = fmt.Sprintf("Created %s (%d lines)", relPath, lines)
+	}
+	if len(syntaxErrs) > 0 {
+		message += syntaxReport(syntaxErrs)
+		summary += ", with syntax errors"
 	}
 
-	return message, nil
+	return newResult(format, message, summary, writeFileJSONResult{
+		Path:         filepath.ToSlash(relPath),
+		Created:      !fileExists,
+		Overwritten:  fileExists,
+		Bytes:        len(content),
+		SyntaxErrors: syntaxErrs,
+	}, tools.Artifact{Kind: tools.ArtifactFile, Path: filepath.ToSlash(relPath)})
+}
+
+// countLines counts content's lines, the last one with or without a newline
+func countLines(content string) int {
+	lines := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		lines++
+	}
+	return lines
+}
+
+// writeFileJSONResult is the structured write_file result for output_format=json.
+type writeFileJSONResult struct {
+	Path         string   `json:"path"`
+	Created      bool     `json:"created"`
+	Overwritten  bool     `json:"overwritten"`
+	Bytes        int      `json:"bytes"`
+	SyntaxErrors []string `json:"syntax_errors,omitempty"`
 }
 
 // IsLoopBreaking returns false as this tool doesn't break the agent loop.
@@ -138,14 +199,19 @@ func (t *WriteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 	if input.Path == "" {
 		return nil, fmt.Errorf("missing required parameter: path")
 	}
+	return previewWrite(t.guard, input.Path, input.Content)
+}
 
+// previewWrite shows what writing content to path would change: a diff
+// against the file it overwrites, or the new file's content
+func previewWrite(guard *workspace.Guard, path, content string) (*tools.ToolPreview, error) {
 	// Validate path
-	if err := t.guard.ValidatePath(input.Path); err != nil {
+	if err := guard.ValidatePath(path); err != nil {
 		return nil, fmt.Errorf("invalid path: %w", err)
 	}
 
 	// Resolve to absolute path
-	absPath, err := t.guard.ResolvePath(input.Path)
+	absPath, err := guard.ResolvePath(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
@@ -162,31 +228,31 @@ func (t *WriteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 			return nil, fmt.Errorf("failed to read existing file: %w", readErr)
 		}
 
-		relPath, relErr := t.guard.MakeRelative(absPath)
+		relPath, relErr := guard.MakeRelative(absPath)
 		if relErr != nil || relPath == "" {
-			relPath = input.Path
+			relPath = path
 		}
 
-		previewContent = GenerateUnifiedDiff(string(originalContent), input.Content, relPath)
+		previewContent = GenerateUnifiedDiff(string(originalContent), content, relPath)
 		previewType = tools.PreviewTypeDiff
 		title = fmt.Sprintf("Overwrite %s", relPath)
 		description = fmt.Sprintf("This will overwrite the existing file %s", relPath)
 	} else {
 		// File doesn't exist - show new content
-		relPath, relErr := t.guard.MakeRelative(absPath)
+		relPath, relErr := guard.MakeRelative(absPath)
 		if relErr != nil || relPath == "" {
-			relPath = input.Path
+			relPath = path
 		}
 
-		previewContent = input.Content
+		previewContent = content
 		previewType = tools.PreviewTypeFileWrite
 		title = fmt.Sprintf("Create new file %s", relPath)
 		description = fmt.Sprintf("This will create a new file at %s", relPath)
 	}
 
-	relPath, relErr := t.guard.MakeRelative(absPath)
+	relPath, relErr := guard.MakeRelative(absPath)
 	if relErr != nil || relPath == "" {
-		relPath = input.Path
+		relPath = path
 	}
 
 	language := detectLanguage(relPath)
@@ -199,7 +265,7 @@ func (t *WriteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 		Metadata: map[string]interface{}{
 			"file_path": relPath,
 			"language":  language,
-			"size":      len(input.Content),
+			"size":      len(content),
 		},
 	}, nil
 }
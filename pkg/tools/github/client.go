@@ -0,0 +1,112 @@
+// Package github is a client for the GitHub REST API, shared by the tools
+// that read from GitHub: the issue tracker and the CI log fetcher.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPI is the GitHub REST API endpoint
+const DefaultAPI = "https://api.github.com"
+
+// defaultTimeout bounds how long an API request may take
+const defaultTimeout = 30 * time.Second
+
+// Client makes authenticated requests to the GitHub REST API
+type Client struct {
+	apiURL string
+	token  string // "" for unauthenticated access to public repositories
+	client *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithAPI sets the API endpoint, e.g. https://github.example.com/api/v3 for
+// GitHub Enterprise
+func WithAPI(apiURL string) Option {
+	return func(c *Client) {
+		if apiURL != "" {
+			c.apiURL = strings.TrimRight(apiURL, "/")
+		}
+	}
+}
+
+// WithToken sets the token requests are authenticated with
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for API requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// NewClient creates a client for the public GitHub API, unauthenticated
+// unless WithToken is given
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		apiURL: DefaultAPI,
+		client: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Authenticated reports whether requests carry a token
+func (c *Client) Authenticated() bool {
+	return c.token != ""
+}
+
+// Get requests path, such as /repos/owner/name/issues/1, from the API,
+// following redirects. A response other than 200 OK is returned as an error
+// with the start of its body. The caller closes the body of a successful
+// response.
+func (c *Client) Get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "forge")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GitHub returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// GetJSON requests path from the API and decodes the JSON response into v
+func (c *Client) GetJSON(ctx context.Context, path string, v interface{}) error {
+	resp, err := c.Get(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	return nil
+}
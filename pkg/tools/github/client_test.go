@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_GetJSON(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/repos/acme/app" {
+			http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"full_name": "acme/app"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPI(server.URL+"/"), WithToken("secret"))
+	var repo struct {
+		FullName string `json:"full_name"`
+	}
+	if err := client.GetJSON(context.Background(), "/repos/acme/app", &repo); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+	if repo.FullName != "acme/app" || auth != "Bearer secret" {
+		t.Errorf("expected an authenticated request for acme/app, got %q with %q", repo.FullName, auth)
+	}
+
+	err := client.GetJSON(context.Background(), "/repos/acme/missing", &repo)
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("expected the status and message in the error, got %v", err)
+	}
+}
+
+func TestClient_Unauthenticated(t *testing.T) {
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Values("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPI(server.URL))
+	if client.Authenticated() {
+		t.Error("expected a client without a token to be unauthenticated")
+	}
+	if err := client.GetJSON(context.Background(), "/rate_limit", &struct{}{}); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+	if len(auth) != 0 {
+		t.Errorf("expected no Authorization header, got %v", auth)
+	}
+}
@@ -0,0 +1,148 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/tools/github"
+)
+
+// githubIssueRef matches "123", "#123", "owner/repo#123" and issue or pull
+// request URLs such as https://github.com/owner/repo/issues/123
+var githubIssueRef = regexp.MustCompile(`^(?:(?:https?://[^/]+/)?([\w.-]+/[\w.-]+)(?:#|/issues/|/pull/))?#?(\d+)$`)
+
+// GitHub reads issues from a GitHub repository
+type GitHub struct {
+	repo   string // owner/name
+	client *github.Client
+}
+
+// NewGitHub creates a tracker for the issues of repo ("owner/name"). A nil
+// client is an unauthenticated one, which can read public repositories.
+func NewGitHub(repo string, client *github.Client) *GitHub {
+	if client == nil {
+		client = github.NewClient()
+	}
+	return &GitHub{repo: repo, client: client}
+}
+
+// Provider returns ProviderGitHub.
+func (g *GitHub) Provider() string {
+	return ProviderGitHub
+}
+
+// githubIssue is an issue as returned by the GitHub API
+type githubIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+	Body    string `json:"body"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Assignee *struct {
+		Login string `json:"login"`
+	} `json:"assignee"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// issue converts the API representation
+func (i *githubIssue) issue() *Issue {
+	issue := &Issue{
+		ID:     fmt.Sprintf("#%d", i.Number),
+		Title:  i.Title,
+		State:  i.State,
+		URL:    i.HTMLURL,
+		Type:   "issue",
+		Author: i.User.Login,
+		Body:   i.Body,
+	}
+	if i.PullRequest != nil {
+		issue.Type = "pull request"
+	}
+	if i.Assignee != nil {
+		issue.Assignee = i.Assignee.Login
+	}
+	for _, label := range i.Labels {
+		issue.Labels = append(issue.Labels, label.Name)
+	}
+	return issue
+}
+
+// Get returns an issue by number ("123", "#123"), by "owner/repo#123" or by URL.
+func (g *GitHub) Get(ctx context.Context, id string) (*Issue, error) {
+	match := githubIssueRef.FindStringSubmatch(strings.TrimSpace(id))
+	if match == nil {
+		return nil, fmt.Errorf("invalid GitHub issue %q: use a number such as 123, owner/repo#123, or an issue URL", id)
+	}
+	repo := g.repo
+	if match[1] != "" {
+		repo = match[1]
+	}
+	if repo == "" {
+		return nil, fmt.Errorf("no GitHub repository configured: set issue_tracker.repository or use owner/repo#%s", match[2])
+	}
+
+	var result githubIssue
+	if err := g.client.GetJSON(ctx, fmt.Sprintf("/repos/%s/issues/%s", repo, match[2]), &result); err != nil {
+		return nil, err
+	}
+	issue := result.issue()
+	if repo != g.repo {
+		issue.ID = repo + issue.ID
+	}
+	return issue, nil
+}
+
+// Search returns issues and pull requests in the repository matching query,
+// which may use GitHub search qualifiers such as is:open or label:bug.
+func (g *GitHub) Search(ctx context.Context, query string, limit int) ([]*Issue, error) {
+	if g.repo == "" {
+		return nil, fmt.Errorf("no GitHub repository configured: set issue_tracker.repository")
+	}
+
+	params := url.Values{}
+	params.Set("q", fmt.Sprintf("repo:%s %s", g.repo, query))
+	params.Set("per_page", fmt.Sprint(limit))
+
+	var result struct {
+		Items []githubIssue `json:"items"`
+	}
+	if err := g.client.GetJSON(ctx, "/search/issues?"+params.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	found := make([]*Issue, 0, len(result.Items))
+	for i := range result.Items {
+		found = append(found, result.Items[i].issue())
+	}
+	return found, nil
+}
+
+// githubRemote matches the owner/name of github.com remotes in HTTPS and SSH form
+var githubRemote = regexp.MustCompile(`github\.com[:/]([\w.-]+/[\w.-]+?)(?:\.git)?/?$`)
+
+// DetectGitHubRepo returns the owner/name of the github.com repository that
+// the workspace's origin remote points to, or "" if it has none
+func DetectGitHubRepo(ctx context.Context, workspaceDir string) string {
+	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
+	cmd.Dir = workspaceDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	match := githubRemote.FindStringSubmatch(strings.TrimSpace(string(output)))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
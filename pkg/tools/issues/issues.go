@@ -0,0 +1,136 @@
+// Package issues provides read-only tools for GitHub Issues and Jira, so the
+// agent can pull an issue's description and acceptance criteria into context
+// instead of relying on tickets pasted into the chat.
+//
+// Credentials are read from environment variables named in the
+// issue_tracker config section and are never written to config files.
+package issues
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tracker providers
+const (
+	ProviderGitHub = "github"
+	ProviderJira   = "jira"
+)
+
+const (
+	// defaultTimeout bounds how long a tracker request may take
+	defaultTimeout = 30 * time.Second
+
+	// defaultSearchLimit is how many issues search_issues returns by default
+	defaultSearchLimit = 10
+
+	// maxSearchLimit caps how many issues one search may return
+	maxSearchLimit = 50
+)
+
+// Issue is an issue or ticket from a tracker
+type Issue struct {
+	ID       string   `json:"id"` // "#123" on GitHub, "PROJ-123" on Jira
+	Title    string   `json:"title"`
+	State    string   `json:"state"`
+	URL      string   `json:"url"`
+	Type     string   `json:"type,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Assignee string   `json:"assignee,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+	Body     string   `json:"body,omitempty"`
+}
+
+// Tracker fetches issues from an issue tracker
+type Tracker interface {
+	// Provider returns the tracker's provider, ProviderGitHub or ProviderJira
+	Provider() string
+
+	// Get returns the issue with the given ID or URL
+	Get(ctx context.Context, id string) (*Issue, error)
+
+	// Search returns up to limit open and closed issues matching query
+	Search(ctx context.Context, query string, limit int) ([]*Issue, error)
+}
+
+// acceptanceHeading matches the start of an acceptance criteria section in
+// Markdown ("## Acceptance Criteria"), Jira wiki markup ("h3. Acceptance
+// criteria") or plain text ("Acceptance criteria:")
+var acceptanceHeading = regexp.MustCompile(`(?im)^\s*(?:#{1,6}\s*|h[1-6]\.\s*|\*\*|\*)?acceptance criteria\b.*$`)
+
+// sectionHeading matches any Markdown or Jira heading
+var sectionHeading = regexp.MustCompile(`(?m)^\s*(?:#{1,6}\s+|h[1-6]\.\s+)\S`)
+
+// AcceptanceCriteria returns the acceptance criteria section of an issue
+// body, without its heading, or "" if the body has none.
+func AcceptanceCriteria(body string) string {
+	loc := acceptanceHeading.FindStringIndex(body)
+	if loc == nil {
+		return ""
+	}
+
+	rest := body[loc[1]:]
+	if next := sectionHeading.FindStringIndex(rest); next != nil {
+		rest = rest[:next[0]]
+	}
+	return strings.TrimSpace(rest)
+}
+
+// FormatIssue renders an issue for the model, with its acceptance criteria
+// called out when the body has them
+func FormatIssue(issue *Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", issue.ID, issue.Title)
+
+	details := []string{"State: " + issue.State}
+	if issue.Type != "" {
+		details = append(details, "Type: "+issue.Type)
+	}
+	if issue.Author != "" {
+		details = append(details, "Author: "+issue.Author)
+	}
+	if issue.Assignee != "" {
+		details = append(details, "Assignee: "+issue.Assignee)
+	}
+	if len(issue.Labels) > 0 {
+		details = append(details, "Labels: "+strings.Join(issue.Labels, ", "))
+	}
+	b.WriteString(strings.Join(details, " | ") + "\n")
+	if issue.URL != "" {
+		fmt.Fprintf(&b, "URL: %s\n", issue.URL)
+	}
+
+	body := strings.TrimSpace(issue.Body)
+	if body == "" {
+		b.WriteString("\n(No description)")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\nDescription:\n%s", body)
+
+	if criteria := AcceptanceCriteria(body); criteria != "" {
+		fmt.Fprintf(&b, "\n\nAcceptance criteria:\n%s", criteria)
+	}
+	return b.String()
+}
+
+// FormatIssueList renders search results one issue per line
+func FormatIssueList(issues []*Issue, query string) string {
+	if len(issues) == 0 {
+		return fmt.Sprintf("No issues match %q", query)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d issue(s) matching %q:\n", len(issues), query)
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "  %s [%s] %s\n", issue.ID, issue.State, issue.Title)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// TaskPrompt turns an issue into a task definition for the agent
+func TaskPrompt(issue *Issue) string {
+	return fmt.Sprintf("Work on this issue. Treat its description and acceptance criteria as the task definition, and check your work against the acceptance criteria when you are done.\n\n%s", FormatIssue(issue))
+}
@@ -0,0 +1,263 @@
+package issues
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/tools/github"
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+// newAPI serves body for every request and records the requests it received
+func newAPI(t *testing.T, status int, body string) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+const githubIssueJSON = `{
+	"number": 42,
+	"title": "Retry uploads",
+	"state": "open",
+	"html_url": "https://github.com/acme/app/issues/42",
+	"body": "Uploads fail on flaky networks.\n\n## Acceptance Criteria\n- Retries 3 times\n- Logs each retry\n\n## Notes\nSee #12",
+	"user": {"login": "alice"},
+	"assignee": {"login": "bob"},
+	"labels": [{"name": "bug"}]
+}`
+
+func TestAcceptanceCriteria(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"markdown", "Intro\n## Acceptance Criteria\n- a\n- b\n## Notes\nx", "- a\n- b"},
+		{"jira", "h2. Context\ntext\nh3. Acceptance criteria\n* a\nh3. Other\ny", "* a"},
+		{"plain", "Do it.\nAcceptance criteria:\n1. works", "1. works"},
+		{"bold", "**Acceptance Criteria**\n- a", "- a"},
+		{"none", "Just a description", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AcceptanceCriteria(tt.body); got != tt.want {
+				t.Errorf("AcceptanceCriteria() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHub_Get(t *testing.T) {
+	server, requests := newAPI(t, http.StatusOK, githubIssueJSON)
+	tracker := NewGitHub("acme/app", github.NewClient(github.WithAPI(server.URL), github.WithToken("secret")))
+
+	issue, err := tracker.Get(context.Background(), "#42")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if issue.ID != "#42" || issue.Title != "Retry uploads" || issue.Assignee != "bob" || issue.Type != "issue" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+	if len(issue.Labels) != 1 || issue.Labels[0] != "bug" {
+		t.Errorf("expected the bug label, got %v", issue.Labels)
+	}
+
+	req := (*requests)[0]
+	if req.URL.Path != "/repos/acme/app/issues/42" {
+		t.Errorf("unexpected path %q", req.URL.Path)
+	}
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("expected the token to be sent, got %q", req.Header.Get("Authorization"))
+	}
+}
+
+func TestGitHub_GetOtherRepository(t *testing.T) {
+	server, requests := newAPI(t, http.StatusOK, githubIssueJSON)
+	tracker := NewGitHub("acme/app", github.NewClient(github.WithAPI(server.URL)))
+
+	for _, id := range []string{"acme/lib#42", "https://github.com/acme/lib/issues/42"} {
+		issue, err := tracker.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", id, err)
+		}
+		if issue.ID != "acme/lib#42" {
+			t.Errorf("Get(%q): expected ID acme/lib#42, got %q", id, issue.ID)
+		}
+	}
+	if (*requests)[0].URL.Path != "/repos/acme/lib/issues/42" {
+		t.Errorf("unexpected path %q", (*requests)[0].URL.Path)
+	}
+	if (*requests)[0].Header.Get("Authorization") != "" {
+		t.Error("expected no Authorization header without a token")
+	}
+}
+
+func TestGitHub_GetErrors(t *testing.T) {
+	server, _ := newAPI(t, http.StatusNotFound, `{"message":"Not Found"}`)
+	tracker := NewGitHub("acme/app", github.NewClient(github.WithAPI(server.URL)))
+
+	if _, err := tracker.Get(context.Background(), "PROJ-1"); err == nil || !strings.Contains(err.Error(), "invalid GitHub issue") {
+		t.Errorf("expected an invalid ID error, got %v", err)
+	}
+	if _, err := tracker.Get(context.Background(), "7"); err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("expected the API error, got %v", err)
+	}
+	if _, err := NewGitHub("", nil).Get(context.Background(), "7"); err == nil || !strings.Contains(err.Error(), "no GitHub repository") {
+		t.Errorf("expected a missing repository error, got %v", err)
+	}
+}
+
+func TestGitHub_Search(t *testing.T) {
+	server, requests := newAPI(t, http.StatusOK, `{"items": [`+githubIssueJSON+`]}`)
+	tracker := NewGitHub("acme/app", github.NewClient(github.WithAPI(server.URL)))
+
+	found, err := tracker.Search(context.Background(), "upload is:open", 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != "#42" {
+		t.Fatalf("unexpected results: %+v", found)
+	}
+
+	query := (*requests)[0].URL.Query()
+	if query.Get("q") != "repo:acme/app upload is:open" || query.Get("per_page") != "5" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+const jiraIssueJSON = `{
+	"key": "PROJ-7",
+	"fields": {
+		"summary": "Export to CSV",
+		"description": "Users need CSV.\nh3. Acceptance Criteria\n* Has a header row",
+		"labels": ["export"],
+		"reporter": {"displayName": "Alice"},
+		"assignee": null,
+		"status": {"name": "To Do"},
+		"issuetype": {"name": "Story"}
+	}
+}`
+
+func TestJira_Get(t *testing.T) {
+	server, requests := newAPI(t, http.StatusOK, jiraIssueJSON)
+	tracker := NewJira(server.URL+"/", WithJiraCredentials("me@example.com", "token"))
+
+	issue, err := tracker.Get(context.Background(), server.URL+"/browse/proj-7")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if issue.ID != "PROJ-7" || issue.State != "To Do" || issue.Type != "Story" || issue.Author != "Alice" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+	if issue.URL != server.URL+"/browse/PROJ-7" {
+		t.Errorf("unexpected URL %q", issue.URL)
+	}
+
+	req := (*requests)[0]
+	if req.URL.Path != "/rest/api/2/issue/PROJ-7" {
+		t.Errorf("unexpected path %q", req.URL.Path)
+	}
+	if user, pass, ok := req.BasicAuth(); !ok || user != "me@example.com" || pass != "token" {
+		t.Errorf("expected basic auth, got %q", req.Header.Get("Authorization"))
+	}
+}
+
+func TestJira_Search(t *testing.T) {
+	server, requests := newAPI(t, http.StatusOK, `{"issues": [`+jiraIssueJSON+`]}`)
+	tracker := NewJira(server.URL, WithJiraCredentials("", "pat"), WithJiraProject("PROJ"))
+
+	if _, err := tracker.Search(context.Background(), "csv export", 3); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err := tracker.Search(context.Background(), "assignee = currentUser()", 3); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	text := (*requests)[0]
+	if got := text.URL.Query().Get("jql"); got != `project = "PROJ" AND text ~ "csv export" ORDER BY updated DESC` {
+		t.Errorf("unexpected JQL for a text query: %q", got)
+	}
+	if text.Header.Get("Authorization") != "Bearer pat" {
+		t.Errorf("expected a bearer token, got %q", text.Header.Get("Authorization"))
+	}
+	if got := (*requests)[1].URL.Query().Get("jql"); got != "assignee = currentUser()" {
+		t.Errorf("expected JQL to pass through, got %q", got)
+	}
+}
+
+func TestGetIssueTool(t *testing.T) {
+	server, _ := newAPI(t, http.StatusOK, githubIssueJSON)
+	tool := NewGetIssueTool(NewGitHub("acme/app", github.NewClient(github.WithAPI(server.URL))))
+
+	result, err := tool.Execute(context.Background(), []byte("<arguments><id>42</id></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	for _, want := range []string{"#42: Retry uploads", "Labels: bug", "Acceptance criteria:\n- Retries 3 times\n- Logs each retry"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in result:\n%s", want, result)
+		}
+	}
+
+	if _, err := tool.Execute(context.Background(), []byte("<arguments></arguments>")); err == nil {
+		t.Error("expected an error for a missing id")
+	}
+}
+
+func TestSearchIssuesTool(t *testing.T) {
+	server, requests := newAPI(t, http.StatusOK, `{"items": []}`)
+	tool := NewSearchIssuesTool(NewGitHub("acme/app", github.NewClient(github.WithAPI(server.URL))))
+
+	result, err := tool.Execute(context.Background(), []byte("<arguments><query>crash</query><limit>500</limit></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != `No issues match "crash"` {
+		t.Errorf("unexpected result %q", result)
+	}
+	if got := (*requests)[0].URL.Query().Get("per_page"); got != "50" {
+		t.Errorf("expected the limit to be capped at 50, got %s", got)
+	}
+}
+
+func TestGitHubRemote(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/acme/app.git": "acme/app",
+		"git@github.com:acme/app.git":     "acme/app",
+		"https://github.com/acme/app":     "acme/app",
+		"https://gitlab.com/acme/app.git": "",
+	}
+	for remote, want := range tests {
+		got := ""
+		if match := githubRemote.FindStringSubmatch(remote); match != nil {
+			got = match[1]
+		}
+		if got != want {
+			t.Errorf("%s: got %q, want %q", remote, got, want)
+		}
+	}
+}
+
+func TestDetectGitHubRepo(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{}, workspacetest.WithGit())
+	ws.Git("remote", "add", "origin", "git@github.com:acme/app.git")
+
+	if got := DetectGitHubRepo(context.Background(), ws.Dir); got != "acme/app" {
+		t.Errorf("expected the origin remote's repository, got %q", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := DetectGitHubRepo(ctx, ws.Dir); got != "" {
+		t.Errorf("expected nothing once the context is canceled, got %q", got)
+	}
+}
@@ -0,0 +1,192 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// jiraIssueRef matches "PROJ-123" and browse URLs such as
+// https://example.atlassian.net/browse/PROJ-123
+var jiraIssueRef = regexp.MustCompile(`^(?:https?://\S+/browse/)?([A-Za-z][A-Za-z0-9_]*-\d+)$`)
+
+// jiraFields are the issue fields requested from Jira
+const jiraFields = "summary,description,status,issuetype,labels,reporter,assignee"
+
+// Jira reads issues from a Jira site
+type Jira struct {
+	baseURL string
+	email   string // "" to send token as a bearer token (Jira Server/Data Center)
+	token   string
+	project string // Key of the project searches are limited to; "" for all
+	client  *http.Client
+}
+
+// JiraOption configures a Jira tracker
+type JiraOption func(*Jira)
+
+// WithJiraCredentials sets the credentials: an Atlassian account email with
+// an API token, or an empty email with a personal access token.
+func WithJiraCredentials(email, token string) JiraOption {
+	return func(j *Jira) {
+		j.email = email
+		j.token = token
+	}
+}
+
+// WithJiraProject limits searches to the project with the given key
+func WithJiraProject(project string) JiraOption {
+	return func(j *Jira) {
+		j.project = project
+	}
+}
+
+// WithJiraHTTPClient sets the HTTP client used for API requests
+func WithJiraHTTPClient(client *http.Client) JiraOption {
+	return func(j *Jira) {
+		j.client = client
+	}
+}
+
+// NewJira creates a tracker for the Jira site at baseURL, e.g.
+// https://example.atlassian.net
+func NewJira(baseURL string, opts ...JiraOption) *Jira {
+	j := &Jira{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// Provider returns ProviderJira.
+func (j *Jira) Provider() string {
+	return ProviderJira
+}
+
+// jiraUser is a reporter or assignee
+type jiraUser struct {
+	DisplayName string `json:"displayName"`
+}
+
+// jiraIssue is an issue as returned by the Jira REST API v2
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string    `json:"summary"`
+		Description string    `json:"description"` // Wiki markup in API v2
+		Labels      []string  `json:"labels"`
+		Reporter    *jiraUser `json:"reporter"`
+		Assignee    *jiraUser `json:"assignee"`
+		Status      struct {
+			Name string `json:"name"`
+		} `json:"status"`
+		IssueType struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+	} `json:"fields"`
+}
+
+// issue converts the API representation
+func (j *Jira) issue(i *jiraIssue) *Issue {
+	issue := &Issue{
+		ID:     i.Key,
+		Title:  i.Fields.Summary,
+		State:  i.Fields.Status.Name,
+		URL:    j.baseURL + "/browse/" + i.Key,
+		Type:   i.Fields.IssueType.Name,
+		Labels: i.Fields.Labels,
+		Body:   i.Fields.Description,
+	}
+	if i.Fields.Reporter != nil {
+		issue.Author = i.Fields.Reporter.DisplayName
+	}
+	if i.Fields.Assignee != nil {
+		issue.Assignee = i.Fields.Assignee.DisplayName
+	}
+	return issue
+}
+
+// Get returns an issue by key ("PROJ-123") or browse URL.
+func (j *Jira) Get(ctx context.Context, id string) (*Issue, error) {
+	match := jiraIssueRef.FindStringSubmatch(strings.TrimSpace(id))
+	if match == nil {
+		return nil, fmt.Errorf("invalid Jira issue %q: use a key such as PROJ-123 or a browse URL", id)
+	}
+
+	var result jiraIssue
+	path := fmt.Sprintf("/rest/api/2/issue/%s?fields=%s", url.PathEscape(strings.ToUpper(match[1])), jiraFields)
+	if err := j.get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+	return j.issue(&result), nil
+}
+
+// Search returns issues whose text matches query, most recently updated
+// first. A query containing a JQL operator such as "=" or "~" is used as JQL.
+func (j *Jira) Search(ctx context.Context, query string, limit int) ([]*Issue, error) {
+	jql := query
+	if !strings.ContainsAny(query, "=~") {
+		jql = fmt.Sprintf("text ~ %q", query)
+		if j.project != "" {
+			jql = fmt.Sprintf("project = %q AND %s", j.project, jql)
+		}
+		jql += " ORDER BY updated DESC"
+	}
+
+	params := url.Values{}
+	params.Set("jql", jql)
+	params.Set("maxResults", fmt.Sprint(limit))
+	params.Set("fields", jiraFields)
+
+	var result struct {
+		Issues []jiraIssue `json:"issues"`
+	}
+	if err := j.get(ctx, "/rest/api/2/search?"+params.Encode(), &result); err != nil {
+		return nil, err
+	}
+
+	found := make([]*Issue, 0, len(result.Issues))
+	for i := range result.Issues {
+		found = append(found, j.issue(&result.Issues[i]))
+	}
+	return found, nil
+}
+
+// get requests path from the API and decodes the JSON response into v
+func (j *Jira) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Jira request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "forge")
+	switch {
+	case j.email != "":
+		req.SetBasicAuth(j.email, j.token)
+	case j.token != "":
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("jira returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode Jira response: %w", err)
+	}
+	return nil
+}
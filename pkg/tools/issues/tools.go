@@ -0,0 +1,160 @@
+package issues
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// GetIssueTool fetches one issue with its description and acceptance criteria.
+type GetIssueTool struct {
+	tracker Tracker
+}
+
+// NewGetIssueTool creates a new GetIssueTool reading from tracker.
+func NewGetIssueTool(tracker Tracker) *GetIssueTool {
+	return &GetIssueTool{tracker: tracker}
+}
+
+// Name returns the tool name.
+func (t *GetIssueTool) Name() string {
+	return "get_issue"
+}
+
+// Description returns the tool description.
+func (t *GetIssueTool) Description() string {
+	return fmt.Sprintf("Fetch an issue from the project's %s tracker: title, state, labels, full description with links, and acceptance criteria. Read-only.", providerName(t.tracker.Provider()))
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *GetIssueTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": issueIDDescription(t.tracker.Provider()),
+			},
+		},
+		[]string{"id"},
+	)
+}
+
+// Execute fetches the issue.
+func (t *GetIssueTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		ID      string   `xml:"id"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if input.ID == "" {
+		return "", fmt.Errorf("missing required parameter: id")
+	}
+
+	issue, err := t.tracker.Get(ctx, input.ID)
+	if err != nil {
+		return "", err
+	}
+	return FormatIssue(issue), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *GetIssueTool) IsLoopBreaking() bool {
+	return false
+}
+
+// SearchIssuesTool searches the tracker for issues.
+type SearchIssuesTool struct {
+	tracker Tracker
+}
+
+// NewSearchIssuesTool creates a new SearchIssuesTool reading from tracker.
+func NewSearchIssuesTool(tracker Tracker) *SearchIssuesTool {
+	return &SearchIssuesTool{tracker: tracker}
+}
+
+// Name returns the tool name.
+func (t *SearchIssuesTool) Name() string {
+	return "search_issues"
+}
+
+// Description returns the tool description.
+func (t *SearchIssuesTool) Description() string {
+	return fmt.Sprintf("Search the project's %s tracker for issues, e.g. to find related or duplicate reports. Returns IDs, states and titles; use get_issue for details. Read-only.", providerName(t.tracker.Provider()))
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *SearchIssuesTool) Schema() map[string]interface{} {
+	query := "Search text. GitHub search qualifiers such as is:open or label:bug may be used."
+	if t.tracker.Provider() == ProviderJira {
+		query = "Search text, or a JQL query such as: status = \"In Progress\" AND assignee = currentUser()"
+	}
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": query,
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of issues to return (default %d, max %d)", defaultSearchLimit, maxSearchLimit),
+			},
+		},
+		[]string{"query"},
+	)
+}
+
+// Execute runs the search.
+func (t *SearchIssuesTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Query   string   `xml:"query"`
+		Limit   int      `xml:"limit"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if input.Query == "" {
+		return "", fmt.Errorf("missing required parameter: query")
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	found, err := t.tracker.Search(ctx, input.Query, limit)
+	if err != nil {
+		return "", err
+	}
+	return FormatIssueList(found, input.Query), nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *SearchIssuesTool) IsLoopBreaking() bool {
+	return false
+}
+
+// providerName returns the display name of a tracker provider
+func providerName(provider string) string {
+	if provider == ProviderJira {
+		return "Jira"
+	}
+	return "GitHub Issues"
+}
+
+// issueIDDescription describes the issue IDs a provider accepts
+func issueIDDescription(provider string) string {
+	if provider == ProviderJira {
+		return "Issue key such as PROJ-123, or a browse URL"
+	}
+	return "Issue number such as 123 or #123, owner/repo#123 for another repository, or an issue URL"
+}
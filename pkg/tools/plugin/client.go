@@ -0,0 +1,282 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// initializeTimeout bounds how long a plugin may take to describe its tools
+	initializeTimeout = 10 * time.Second
+
+	// shutdownTimeout bounds how long a plugin may take to exit once asked
+	shutdownTimeout = 2 * time.Second
+
+	// maxStderr caps how much of a plugin's stderr is kept for error messages
+	maxStderr = 4096
+)
+
+// Plugin is a running plugin process
+type Plugin struct {
+	name  string
+	path  string
+	tools []ToolSpec
+
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+	stderr  *tailBuffer
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *response
+	done    chan struct{} // Closed when the plugin has exited
+	err     error         // Why the plugin stopped; set before done is closed
+	waitErr error         // Result of waiting for the process; set before done is closed
+}
+
+// Start runs the plugin executable at path in workspace and initializes it
+func Start(ctx context.Context, path, workspace string) (*Plugin, error) {
+	cmd := exec.Command(path) //nolint:gosec // Plugins are trusted executables
+	cmd.Dir = workspace
+	cmd.Env = append(os.Environ(), "FORGE_WORKSPACE="+workspace)
+	stderr := &tailBuffer{max: maxStderr}
+	cmd.Stderr = stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", path, err)
+	}
+
+	p := &Plugin{
+		name:    filepath.Base(path),
+		path:    path,
+		cmd:     cmd,
+		stdin:   stdin,
+		stderr:  stderr,
+		pending: make(map[int64]chan *response),
+		done:    make(chan struct{}),
+	}
+	go p.read(stdout)
+
+	if err := p.initialize(ctx, workspace); err != nil {
+		p.kill()
+		<-p.done
+		return nil, fmt.Errorf("failed to initialize plugin %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// initialize exchanges protocol versions and reads the plugin's tools
+func (p *Plugin) initialize(ctx context.Context, workspace string) error {
+	ctx, cancel := context.WithTimeout(ctx, initializeTimeout)
+	defer cancel()
+
+	var result InitializeResult
+	err := p.call(ctx, MethodInitialize, InitializeParams{ProtocolVersion: ProtocolVersion, Workspace: workspace}, &result)
+	if err != nil {
+		return err
+	}
+	if result.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d (want %d)", result.ProtocolVersion, ProtocolVersion)
+	}
+	for _, spec := range result.Tools {
+		if spec.Name == "" {
+			return fmt.Errorf("plugin declared a tool without a name")
+		}
+	}
+	if result.Name != "" {
+		p.name = result.Name
+	}
+	p.tools = result.Tools
+	return nil
+}
+
+// Name returns the plugin's name
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Path returns the plugin's executable
+func (p *Plugin) Path() string {
+	return p.path
+}
+
+// Close asks the plugin to shut down and waits for it to exit, killing it if
+// it doesn't within shutdownTimeout
+func (p *Plugin) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// A plugin that doesn't understand shutdown still exits when stdin closes
+	_ = p.call(ctx, MethodShutdown, nil, nil)
+	p.stdin.Close()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		p.kill()
+		<-p.done
+		return nil
+	}
+	if p.waitErr != nil {
+		return fmt.Errorf("plugin %s: %w", p.name, p.waitErr)
+	}
+	return nil
+}
+
+// kill stops the plugin without asking
+func (p *Plugin) kill() {
+	p.stdin.Close()
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+	}
+}
+
+// call sends a request and decodes its result into result, which may be nil
+func (p *Plugin) call(ctx context.Context, method string, params, result interface{}) error {
+	replies := make(chan *response, 1)
+
+	p.mu.Lock()
+	if p.err != nil {
+		p.mu.Unlock()
+		return p.err
+	}
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = replies
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+
+	if err := p.send(request{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-replies:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		_ = p.send(request{JSONRPC: "2.0", Method: MethodCancel, Params: CancelParams{ID: id}})
+		return ctx.Err()
+	}
+}
+
+// send writes one message to the plugin
+func (p *Plugin) send(msg request) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", msg.Method, err)
+	}
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		return p.exitError(err)
+	}
+	return nil
+}
+
+// read delivers the plugin's responses to their calls until stdout closes,
+// then waits for the plugin to exit
+func (p *Plugin) read(stdout io.Reader) {
+	decoder := json.NewDecoder(stdout)
+	var err error
+	for {
+		var resp response
+		if err = decoder.Decode(&resp); err != nil {
+			break
+		}
+		if resp.ID == nil {
+			continue // Notifications from plugins are not used yet
+		}
+
+		p.mu.Lock()
+		replies, ok := p.pending[*resp.ID]
+		p.mu.Unlock()
+		if ok {
+			replies <- &resp
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		err = errors.New("exited")
+	} else {
+		err = fmt.Errorf("invalid output: %w", err)
+		p.kill()
+	}
+
+	// Waiting also finishes copying stderr, which exitError reports
+	waitErr := p.cmd.Wait()
+	p.mu.Lock()
+	p.err = p.exitError(err)
+	p.waitErr = waitErr
+	p.mu.Unlock()
+	close(p.done)
+}
+
+// exitError describes the plugin stopping with err, including the end of its
+// stderr, which usually says why. It names the executable, since the name
+// the plugin gives itself may not be known yet.
+func (p *Plugin) exitError(err error) error {
+	name := filepath.Base(p.path)
+	if tail := strings.TrimSpace(p.stderr.String()); tail != "" {
+		return fmt.Errorf("plugin %s %w: %s", name, err, tail)
+	}
+	return fmt.Errorf("plugin %s %w", name, err)
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+}
+
+func (b *tailBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Write(data)
+	if extra := b.buf.Len() - b.max; extra > 0 {
+		b.buf.Next(extra)
+	}
+	return len(data), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
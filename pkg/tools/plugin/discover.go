@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Dir is where plugins are discovered, relative to the workspace and to the
+// user's home directory
+const Dir = ".forge/plugins"
+
+// Discover returns the plugin executables in dirs: the executable files
+// directly inside each, in name order. Hidden files, WebAssembly modules and
+// directories that don't exist are skipped.
+func Discover(dirs ...string) ([]string, error) {
+	return discover(dirs, func(path string, info os.FileInfo) bool {
+		return !isWASM(path) && isExecutable(path, info)
+	})
+}
+
+// DiscoverWASM returns the WebAssembly modules in dirs: the .wasm files
+// directly inside each, in name order.
+func DiscoverWASM(dirs ...string) ([]string, error) {
+	return discover(dirs, func(path string, _ os.FileInfo) bool {
+		return isWASM(path)
+	})
+}
+
+// discover returns the regular files directly inside dirs that match
+func discover(dirs []string, match func(path string, info os.FileInfo) bool) ([]string, error) {
+	var paths []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+		}
+
+		var found []string
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path) // Follow symlinks to installed plugins
+			if err != nil || !info.Mode().IsRegular() || !match(path, info) {
+				continue
+			}
+			found = append(found, path)
+		}
+		sort.Strings(found)
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+// isWASM reports whether path names a WebAssembly module
+func isWASM(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".wasm")
+}
+
+// isExecutable reports whether the file at path can be run as a plugin
+func isExecutable(path string, info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// Load discovers and starts the plugins in dirs. Plugins that fail to start
+// are skipped; their failures are returned together as the error, alongside
+// the plugins that did start.
+func Load(ctx context.Context, workspace string, dirs ...string) ([]*Plugin, error) {
+	paths, err := Discover(dirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []*Plugin
+	var errs []error
+	for _, path := range paths {
+		p, err := Start(ctx, path, workspace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, errors.Join(errs...)
+}
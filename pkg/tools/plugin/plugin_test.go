@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// helperEnv makes the test binary act as a plugin instead of running tests
+const helperEnv = "FORGE_PLUGIN_TEST_HELPER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(helperEnv) == "1" {
+		servePlugin()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// servePlugin implements a plugin with an echo tool and a previewed write
+// tool. The mode variable selects a misbehaviour.
+func servePlugin() {
+	mode := os.Getenv(helperEnv + "_MODE")
+	if mode == "crash" {
+		fmt.Fprintln(os.Stderr, "missing API key")
+		os.Exit(1)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var req struct {
+			ID     *int64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+		reply := map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID}
+
+		var call CallParams
+		_ = json.Unmarshal(req.Params, &call)
+		switch req.Method {
+		case MethodInitialize:
+			version := ProtocolVersion
+			if mode == "old" {
+				version = 0
+			}
+			reply["result"] = InitializeResult{
+				ProtocolVersion: version,
+				Name:            "test",
+				Tools: []ToolSpec{
+					{
+						Name:        "echo",
+						Description: "Echo the arguments",
+						Schema: tools.BaseToolSchema(map[string]interface{}{
+							"count": map[string]interface{}{"type": "integer"},
+							"loud":  map[string]interface{}{"type": "boolean"},
+							"words": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						}, nil),
+					},
+					{Name: "write", Description: "Write a file", Preview: true, TimeoutSeconds: 30},
+				},
+			}
+		case MethodExecute:
+			if call.Tool == "write" {
+				reply["error"] = RPCError{Code: 1, Message: "disk full"}
+				break
+			}
+			args, _ := json.Marshal(call.Arguments)
+			reply["result"] = ExecuteResult{Output: string(args)}
+		case MethodPreview:
+			reply["result"] = PreviewResult{Type: "file_write", Content: "new contents"}
+		case MethodShutdown:
+			reply["result"] = nil
+			_ = out.Encode(reply)
+			return
+		}
+		_ = out.Encode(reply)
+	}
+}
+
+// writePlugin installs a script in dir that runs the test binary as a plugin
+func writePlugin(t *testing.T, dir, name, mode string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+	script := fmt.Sprintf("#!/bin/sh\n%s=1 %s_MODE=%s exec %q\n", helperEnv, helperEnv, mode, os.Args[0])
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func startTestPlugin(t *testing.T) *Plugin {
+	t.Helper()
+	path := writePlugin(t, t.TempDir(), "forge-test", "")
+	p, err := Start(context.Background(), path, t.TempDir())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if err := p.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	})
+	return p
+}
+
+func TestPlugin_Execute(t *testing.T) {
+	p := startTestPlugin(t)
+	if p.Name() != "test" {
+		t.Errorf("Name() = %q, want the name the plugin gave", p.Name())
+	}
+
+	pluginTools := p.Tools()
+	if len(pluginTools) != 2 {
+		t.Fatalf("Tools() returned %d tools, want 2", len(pluginTools))
+	}
+	echo := pluginTools[0]
+	if _, ok := echo.(tools.Previewable); ok {
+		t.Error("echo did not declare preview support but is previewable")
+	}
+
+	args := []byte("<arguments><count>3</count><loud>true</loud><words><word>a</word><word>b</word></words><note>x</note></arguments>")
+	output, err := echo.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("plugin output %q is not JSON: %v", output, err)
+	}
+	want := map[string]interface{}{"count": 3.0, "loud": true, "words": []interface{}{"a", "b"}, "note": "x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("plugin received %v, want %v", got, want)
+	}
+}
+
+func TestPlugin_PreviewAndError(t *testing.T) {
+	p := startTestPlugin(t)
+	write := p.Tools()[1]
+	if Timeout(write).Seconds() != 30 {
+		t.Errorf("Timeout() = %v, want 30s", Timeout(write))
+	}
+
+	previewable, ok := write.(tools.Previewable)
+	if !ok {
+		t.Fatal("write declared preview support but is not previewable")
+	}
+	preview, err := previewable.GeneratePreview(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("GeneratePreview() error = %v", err)
+	}
+	if preview.Type != tools.PreviewTypeFileWrite || preview.Content != "new contents" || preview.Title == "" {
+		t.Errorf("preview = %+v", preview)
+	}
+
+	_, err = write.Execute(context.Background(), []byte("<arguments></arguments>"))
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Execute() error = %v, want the plugin's error", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "good", "")
+	writePlugin(t, dir, "crashes", "crash")
+	writePlugin(t, dir, "old", "old")
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins, err := Load(context.Background(), t.TempDir(), dir, filepath.Join(dir, "missing"))
+	for _, p := range plugins {
+		defer p.Close()
+	}
+
+	if len(plugins) != 1 || filepath.Base(plugins[0].Path()) != "good" {
+		t.Errorf("Load() started %d plugins, want only good", len(plugins))
+	}
+	if err == nil {
+		t.Fatal("Load() reported no error for the failing plugins")
+	}
+	for _, want := range []string{"missing API key", "unsupported protocol version"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error %q does not mention %q", err, want)
+		}
+	}
+}
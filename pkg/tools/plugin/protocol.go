@@ -0,0 +1,110 @@
+// Package plugin runs tools provided by external executables, so teams can
+// add tools in any language without recompiling Forge.
+//
+// A plugin is an executable that speaks JSON-RPC 2.0 on its stdin and stdout,
+// one message per line. Forge starts each plugin once per session in the
+// workspace directory and sends:
+//
+//   - initialize, answered with the plugin's tools and their JSON schemas
+//   - execute, to run one of its tools with the call's arguments
+//   - preview, for tools that declared preview support, to describe what a
+//     call will change before the user approves it
+//   - shutdown, before closing the plugin's stdin at the end of the session
+//
+// When a call is canceled Forge sends a cancel notification with the call's
+// id. Anything the plugin writes to stderr is kept for error messages.
+//
+// A plugin may instead be a WebAssembly module, which speaks the same
+// protocol but runs sandboxed with only the capabilities granted to it; see
+// Module.
+package plugin
+
+import "encoding/json"
+
+// ProtocolVersion is the version of the protocol described in this package.
+// A plugin answering initialize with a different version is not loaded.
+const ProtocolVersion = 1
+
+// Methods sent to plugins
+const (
+	MethodInitialize = "initialize"
+	MethodExecute    = "execute"
+	MethodPreview    = "preview"
+	MethodShutdown   = "shutdown"
+	MethodCancel     = "cancel" // Notification: stop working on a call
+)
+
+// InitializeParams are sent with initialize
+type InitializeParams struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Workspace       string `json:"workspace"`
+}
+
+// InitializeResult describes a plugin and its tools
+type InitializeResult struct {
+	ProtocolVersion int        `json:"protocol_version"`
+	Name            string     `json:"name,omitempty"` // Defaults to the executable's name
+	Tools           []ToolSpec `json:"tools"`
+}
+
+// ToolSpec describes a tool provided by a plugin
+type ToolSpec struct {
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Schema         map[string]interface{} `json:"schema"`                    // JSON schema of the arguments object
+	Preview        bool                   `json:"preview,omitempty"`         // Calls are previewed and need approval
+	TimeoutSeconds int                    `json:"timeout_seconds,omitempty"` // 0 = the agent's default
+}
+
+// CallParams are sent with execute and preview
+type CallParams struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// ExecuteResult is a tool's output, returned to the agent
+type ExecuteResult struct {
+	Output string `json:"output"`
+}
+
+// PreviewResult describes what a call will do, shown when asking for
+// approval. Type is one of "diff", "command" or "file_write".
+type PreviewResult struct {
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description,omitempty"`
+	Content     string                 `json:"content"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CancelParams are sent with the cancel notification
+type CancelParams struct {
+	ID int64 `json:"id"`
+}
+
+// request is a JSON-RPC request, or a notification when ID is nil
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is an error returned by a plugin. A tool that fails returns its
+// reason as the message, which the agent sees as the tool's error.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
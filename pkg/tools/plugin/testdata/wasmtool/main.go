@@ -0,0 +1,82 @@
+// Command wasmtool is a WebAssembly tool module used by the plugin tests.
+// Build it with GOOS=wasip1 GOARCH=wasm.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"unsafe"
+)
+
+//go:wasmimport forge http_request
+func httpRequest(ptr, size uint32) uint32
+
+//go:wasmimport forge http_response
+func httpResponse(ptr, size uint32) uint32
+
+type request struct {
+	ID     int64  `json:"id"`
+	Method string `json:"method"`
+	Params struct {
+		Tool      string            `json:"tool"`
+		Arguments map[string]string `json:"arguments"`
+	} `json:"params"`
+}
+
+func main() {
+	line, _ := bufio.NewReader(os.Stdin).ReadBytes('\n')
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		os.Stderr.WriteString("bad request\n")
+		os.Exit(1)
+	}
+
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "initialize":
+		reply["result"] = map[string]interface{}{
+			"protocol_version": 1,
+			"tools": []map[string]interface{}{
+				{"name": "read_text", "description": "Read a file"},
+				{"name": "write_text", "description": "Write a file", "preview": true},
+				{"name": "fetch", "description": "Fetch a URL"},
+				{"name": "spin", "description": "Never return"},
+			},
+		}
+	case "preview":
+		reply["result"] = map[string]interface{}{"type": "file_write", "content": req.Params.Arguments["content"]}
+	case "execute":
+		output, err := execute(req.Params.Tool, req.Params.Arguments)
+		if err != nil {
+			reply["error"] = map[string]interface{}{"code": 1, "message": err.Error()}
+		} else {
+			reply["result"] = map[string]interface{}{"output": output}
+		}
+	}
+	json.NewEncoder(os.Stdout).Encode(reply)
+}
+
+func execute(tool string, args map[string]string) (string, error) {
+	switch tool {
+	case "read_text":
+		data, err := os.ReadFile(args["path"])
+		return string(data), err
+	case "write_text":
+		return "written", os.WriteFile(args["path"], []byte(args["content"]), 0o644)
+	case "fetch":
+		return fetch(args["url"]), nil
+	case "spin":
+		for {
+		}
+	}
+	return "", nil
+}
+
+func fetch(url string) string {
+	req, _ := json.Marshal(map[string]string{"url": url})
+	size := httpRequest(uint32(uintptr(unsafe.Pointer(&req[0]))), uint32(len(req)))
+	buf := make([]byte, size)
+	n := httpResponse(uint32(uintptr(unsafe.Pointer(&buf[0]))), size)
+	return string(buf[:n])
+}
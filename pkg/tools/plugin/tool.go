@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// caller sends protocol requests to a plugin process or WebAssembly module
+type caller interface {
+	call(ctx context.Context, method string, params, result interface{}) error
+}
+
+// Tool is a tool provided by a plugin
+type Tool struct {
+	plugin caller
+	source string // Name of the plugin, for descriptions
+	spec   ToolSpec
+}
+
+// previewTool is a plugin tool whose calls are previewed and approved before
+// they run, like the built-in tools that change files
+type previewTool struct {
+	*Tool
+}
+
+// Tools returns the plugin's tools. Tools that declared preview support
+// implement tools.Previewable.
+func (p *Plugin) Tools() []tools.Tool {
+	return newTools(p, p.name, p.tools)
+}
+
+// newTools creates the tools described by specs, called through plugin
+func newTools(plugin caller, source string, specs []ToolSpec) []tools.Tool {
+	result := make([]tools.Tool, 0, len(specs))
+	for _, spec := range specs {
+		tool := &Tool{plugin: plugin, source: source, spec: spec}
+		if spec.Preview {
+			result = append(result, previewTool{tool})
+		} else {
+			result = append(result, tool)
+		}
+	}
+	return result
+}
+
+// Timeout returns how long a call may run, or 0 for the agent's default
+func Timeout(tool tools.Tool) time.Duration {
+	switch t := tool.(type) {
+	case *Tool:
+		return time.Duration(t.spec.TimeoutSeconds) * time.Second
+	case previewTool:
+		return time.Duration(t.spec.TimeoutSeconds) * time.Second
+	}
+	return 0
+}
+
+// Name returns the tool name.
+func (t *Tool) Name() string {
+	return t.spec.Name
+}
+
+// Description returns the tool description, noting the plugin it comes from.
+func (t *Tool) Description() string {
+	return fmt.Sprintf("%s (provided by the %s plugin)", t.spec.Description, t.source)
+}
+
+// Schema returns the JSON schema declared by the plugin.
+func (t *Tool) Schema() map[string]interface{} {
+	if t.spec.Schema == nil {
+		return tools.BaseToolSchema(map[string]interface{}{}, nil)
+	}
+	return t.spec.Schema
+}
+
+// Execute sends the call to the plugin and returns its output.
+func (t *Tool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	params, err := t.callParams(argsXML)
+	if err != nil {
+		return "", err
+	}
+
+	var result ExecuteResult
+	if err := t.plugin.call(ctx, MethodExecute, params, &result); err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// IsLoopBreaking returns false; plugins can't end the agent's turn.
+func (t *Tool) IsLoopBreaking() bool {
+	return false
+}
+
+// GeneratePreview asks the plugin to describe what the call will do.
+func (t previewTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	params, err := t.callParams(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	var result PreviewResult
+	if err := t.plugin.call(ctx, MethodPreview, params, &result); err != nil {
+		return nil, err
+	}
+	if result.Title == "" {
+		result.Title = fmt.Sprintf("%s (%s plugin)", t.spec.Name, t.source)
+	}
+	return &tools.ToolPreview{
+		Type:        tools.PreviewType(result.Type),
+		Title:       result.Title,
+		Description: result.Description,
+		Content:     result.Content,
+		Metadata:    result.Metadata,
+	}, nil
+}
+
+// callParams decodes a call's arguments for the plugin
+func (t *Tool) callParams(argsXML []byte) (CallParams, error) {
+	args, err := tools.ArgumentsMap(argsXML)
+	if err != nil {
+		return CallParams{}, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return CallParams{Tool: t.spec.Name, Arguments: coerceArguments(args, t.spec.Schema)}, nil
+}
+
+// coerceArguments converts arguments decoded from XML, where every value is
+// text, to the types the schema declares for them, so plugins receive the
+// JSON they described. Values that don't convert are passed on as they are
+// for the plugin to reject.
+func coerceArguments(args map[string]interface{}, schema map[string]interface{}) map[string]interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	for name, value := range args {
+		property, _ := properties[name].(map[string]interface{})
+		if property != nil {
+			args[name] = coerceValue(value, property)
+		}
+	}
+	return args
+}
+
+// coerceValue converts one argument to the type in its schema
+func coerceValue(value interface{}, schema map[string]interface{}) interface{} {
+	kind, _ := schema["type"].(string)
+
+	if kind == "array" {
+		items, _ := schema["items"].(map[string]interface{})
+		var list []interface{}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			// <paths><path>a</path><path>b</path></paths> decodes as {"path": [a, b]}
+			if len(v) != 1 {
+				return value
+			}
+			for _, inner := range v {
+				if l, ok := inner.([]interface{}); ok {
+					list = l
+				} else {
+					list = []interface{}{inner}
+				}
+			}
+		case []interface{}:
+			list = v
+		case string:
+			if v == "" {
+				return []interface{}{}
+			}
+			return value
+		default:
+			return value
+		}
+		if items != nil {
+			for i := range list {
+				list[i] = coerceValue(list[i], items)
+			}
+		}
+		return list
+	}
+
+	if kind == "object" {
+		if m, ok := value.(map[string]interface{}); ok {
+			return coerceArguments(m, schema)
+		}
+		return value
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return value
+	}
+	switch kind {
+	case "integer":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	}
+	return value
+}
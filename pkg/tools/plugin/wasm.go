@@ -0,0 +1,420 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// wasmMemoryLimitPages caps a module's memory at 256 MiB (64 KiB pages)
+	wasmMemoryLimitPages = 4096
+
+	// maxWASMOutput caps how much a module may write to stdout in one call
+	maxWASMOutput = 8 << 20
+
+	// httpTimeout bounds each HTTP request a module makes
+	httpTimeout = 30 * time.Second
+
+	// maxHTTPResponse caps the body of an HTTP response passed to a module
+	maxHTTPResponse = 4 << 20
+)
+
+// Capabilities are what a WebAssembly module may access. Modules have none
+// unless the user grants them: without Read, the module sees no files at all.
+type Capabilities struct {
+	Read    bool     // See the workspace, mounted as the module's root directory
+	Write   bool     // Also change files in the workspace
+	Network []string // Hosts the module may send HTTP requests to; "*" allows any
+}
+
+// allowsHost reports whether the module may send requests to host
+func (c Capabilities) allowsHost(host string) bool {
+	for _, allowed := range c.Network {
+		if allowed == "*" || strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// WASMConfig configures how WebAssembly modules are loaded
+type WASMConfig struct {
+	Workspace string
+	Grants    map[string]Capabilities // By module name: the file name without .wasm
+	CacheDir  string                  // Where compiled modules are cached ("" = not cached)
+}
+
+// Module is a tool plugin compiled to WebAssembly. Each call runs a fresh
+// instance of the module as a WASI command: the request is its stdin and the
+// response its stdout, as one line of the plugin protocol each. Modules can
+// only reach the workspace and the network through the capabilities granted
+// to them, which makes them safe to run from untrusted sources.
+//
+// The network is reached through the host function forge.http_request(ptr,
+// len) -> size, which takes a JSON request {"method", "url", "headers",
+// "body"} and returns the size of the JSON response {"status", "headers",
+// "body"} or {"error"}, which forge.http_response(ptr, len) -> written then
+// copies into the module's memory.
+type Module struct {
+	name      string
+	path      string
+	caps      Capabilities
+	workspace string
+	tools     []ToolSpec
+
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	stderr   *tailBuffer
+	client   *http.Client
+}
+
+// StartWASM compiles the module at path and initializes it with caps
+func StartWASM(ctx context.Context, path string, caps Capabilities, cfg WASMConfig) (*Module, error) {
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module %s: %w", path, err)
+	}
+
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(wasmMemoryLimitPages)
+	if cfg.CacheDir != "" {
+		if cache, err := wazero.NewCompilationCacheWithDir(cfg.CacheDir); err == nil {
+			runtimeConfig = runtimeConfig.WithCompilationCache(cache)
+		}
+	}
+
+	m := &Module{
+		name:      strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		path:      path,
+		caps:      caps,
+		workspace: cfg.Workspace,
+		runtime:   wazero.NewRuntimeWithConfig(ctx, runtimeConfig),
+		stderr:    &tailBuffer{max: maxStderr},
+	}
+	m.client = &http.Client{
+		Timeout: httpTimeout,
+		CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+			if !caps.allowsHost(req.URL.Hostname()) {
+				return fmt.Errorf("redirect to %s not granted", req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+
+	if err := m.instantiateHost(ctx); err != nil {
+		m.runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to prepare module %s: %w", path, err)
+	}
+	m.compiled, err = m.runtime.CompileModule(ctx, binary)
+	if err != nil {
+		m.runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile module %s: %w", path, err)
+	}
+
+	if err := m.initialize(ctx); err != nil {
+		m.runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to initialize module %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// LoadWASM discovers and starts the WebAssembly modules in dirs, granting
+// each the capabilities configured for its name. Modules that fail to start
+// are skipped; their failures are returned together as the error, alongside
+// the modules that did start.
+func LoadWASM(ctx context.Context, cfg WASMConfig, dirs ...string) ([]*Module, error) {
+	paths, err := DiscoverWASM(dirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []*Module
+	var errs []error
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		m, err := StartWASM(ctx, path, cfg.Grants[name], cfg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		modules = append(modules, m)
+	}
+	return modules, errors.Join(errs...)
+}
+
+// initialize reads the module's tools
+func (m *Module) initialize(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, initializeTimeout)
+	defer cancel()
+
+	var result InitializeResult
+	err := m.call(ctx, MethodInitialize, InitializeParams{ProtocolVersion: ProtocolVersion, Workspace: "/"}, &result)
+	if err != nil {
+		return err
+	}
+	if result.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d (want %d)", result.ProtocolVersion, ProtocolVersion)
+	}
+	for _, spec := range result.Tools {
+		if spec.Name == "" {
+			return fmt.Errorf("module declared a tool without a name")
+		}
+	}
+	if result.Name != "" {
+		m.name = result.Name
+	}
+	m.tools = result.Tools
+	return nil
+}
+
+// Name returns the module's name
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Path returns the module's file
+func (m *Module) Path() string {
+	return m.path
+}
+
+// Capabilities returns what the module was granted
+func (m *Module) Capabilities() Capabilities {
+	return m.caps
+}
+
+// Tools returns the module's tools. Tools that declared preview support
+// implement tools.Previewable.
+func (m *Module) Tools() []tools.Tool {
+	return newTools(m, m.name, m.tools)
+}
+
+// Close releases the module's runtime
+func (m *Module) Close() error {
+	return m.runtime.Close(context.Background())
+}
+
+// call runs a fresh instance of the module with one request
+func (m *Module) call(ctx context.Context, method string, params, result interface{}) error {
+	id := int64(1)
+	data, err := json.Marshal(request{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	stdout := &limitedBuffer{max: maxWASMOutput}
+	config := wazero.NewModuleConfig().
+		WithName(""). // Anonymous, so calls can run concurrently
+		WithArgs(m.name).
+		WithStdin(bytes.NewReader(append(data, '\n'))).
+		WithStdout(stdout).
+		WithStderr(m.stderr).
+		WithFSConfig(m.fsConfig()).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+
+	instance, err := m.runtime.InstantiateModule(withHTTPState(ctx), m.compiled, config)
+	if instance != nil {
+		instance.Close(ctx)
+	}
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 0 {
+		err = nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return m.exitError(err)
+	}
+
+	var resp response
+	if err := json.NewDecoder(bytes.NewReader(stdout.Bytes())).Decode(&resp); err != nil {
+		if stdout.overflow {
+			return m.exitError(fmt.Errorf("wrote more than %d bytes", maxWASMOutput))
+		}
+		return m.exitError(fmt.Errorf("answered without a response: %w", err))
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
+
+// fsConfig mounts the workspace as the module's root directory, if granted
+func (m *Module) fsConfig() wazero.FSConfig {
+	config := wazero.NewFSConfig()
+	switch {
+	case m.caps.Write:
+		config = config.WithDirMount(m.workspace, "/")
+	case m.caps.Read:
+		config = config.WithReadOnlyDirMount(m.workspace, "/")
+	}
+	return config
+}
+
+// exitError describes the module failing with err, including the end of its
+// stderr
+func (m *Module) exitError(err error) error {
+	name := filepath.Base(m.path)
+	if tail := strings.TrimSpace(m.stderr.String()); tail != "" {
+		return fmt.Errorf("module %s %w: %s", name, err, tail)
+	}
+	return fmt.Errorf("module %s %w", name, err)
+}
+
+// instantiateHost provides WASI and Forge's host functions to the module
+func (m *Module) instantiateHost(ctx context.Context) error {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, m.runtime); err != nil {
+		return err
+	}
+	_, err := m.runtime.NewHostModuleBuilder("forge").
+		NewFunctionBuilder().WithFunc(m.httpRequest).Export("http_request").
+		NewFunctionBuilder().WithFunc(m.httpResponse).Export("http_response").
+		Instantiate(ctx)
+	return err
+}
+
+// httpState holds the response to a module's last HTTP request until it
+// copies it out
+type httpState struct {
+	response []byte
+}
+
+type httpStateKey struct{}
+
+// withHTTPState gives a call its own HTTP response slot
+func withHTTPState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, httpStateKey{}, &httpState{})
+}
+
+// httpRequestMessage is the JSON a module passes to forge.http_request
+type httpRequestMessage struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// httpResponseMessage is the JSON forge.http_response copies to a module
+type httpResponseMessage struct {
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// httpRequest implements forge.http_request: it sends the request at
+// [ptr, ptr+size) and returns the size of the response
+func (m *Module) httpRequest(ctx context.Context, mod api.Module, ptr, size uint32) uint32 {
+	state, _ := ctx.Value(httpStateKey{}).(*httpState)
+	if state == nil {
+		return 0
+	}
+
+	var reply httpResponseMessage
+	if data, ok := mod.Memory().Read(ptr, size); !ok {
+		reply.Error = "request is outside the module's memory"
+	} else {
+		reply = m.doHTTP(ctx, data)
+	}
+	state.response, _ = json.Marshal(reply)
+	return uint32(len(state.response))
+}
+
+// httpResponse implements forge.http_response: it copies the last response
+// to [ptr, ptr+size) and returns the number of bytes copied
+func (m *Module) httpResponse(ctx context.Context, mod api.Module, ptr, size uint32) uint32 {
+	state, _ := ctx.Value(httpStateKey{}).(*httpState)
+	if state == nil {
+		return 0
+	}
+	n := min(size, uint32(len(state.response)))
+	if !mod.Memory().Write(ptr, state.response[:n]) {
+		return 0
+	}
+	return n
+}
+
+// doHTTP sends a module's request if its host is granted
+func (m *Module) doHTTP(ctx context.Context, data []byte) httpResponseMessage {
+	var msg httpRequestMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return httpResponseMessage{Error: "invalid request: " + err.Error()}
+	}
+	target, err := url.Parse(msg.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return httpResponseMessage{Error: fmt.Sprintf("invalid URL %q", msg.URL)}
+	}
+	if !m.caps.allowsHost(target.Hostname()) {
+		return httpResponseMessage{Error: fmt.Sprintf("network access to %s not granted", target.Hostname())}
+	}
+
+	method := msg.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(msg.Body))
+	if err != nil {
+		return httpResponseMessage{Error: err.Error()}
+	}
+	for key, value := range msg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return httpResponseMessage{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponse))
+	if err != nil {
+		return httpResponseMessage{Error: err.Error()}
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for key := range resp.Header {
+		headers[key] = resp.Header.Get(key)
+	}
+	return httpResponseMessage{Status: resp.StatusCode, Headers: headers, Body: string(body)}
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	if room := b.max - b.Len(); len(data) > room {
+		b.overflow = true
+		b.Buffer.Write(data[:max(room, 0)])
+		return 0, fmt.Errorf("output exceeds %d bytes", b.max)
+	}
+	return b.Buffer.Write(data)
+}
@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+var (
+	wasmBuild     sync.Once
+	wasmBuildPath string
+	wasmBuildErr  error
+)
+
+// buildWASMTool compiles testdata/wasmtool once per test run
+func buildWASMTool(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("building a WebAssembly module is slow")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available to build the test module")
+	}
+
+	wasmBuild.Do(func() {
+		dir, err := os.MkdirTemp("", "forge-wasm-test")
+		if err != nil {
+			wasmBuildErr = err
+			return
+		}
+		wasmBuildPath = filepath.Join(dir, "wasmtool.wasm")
+		cmd := exec.Command(goTool, "build", "-o", wasmBuildPath, "./testdata/wasmtool")
+		cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			wasmBuildErr = fmt.Errorf("%w: %s", err, output)
+		}
+	})
+	if wasmBuildErr != nil {
+		t.Fatalf("failed to build test module: %v", wasmBuildErr)
+	}
+	return wasmBuildPath
+}
+
+// wasmCacheDir shares compiled modules between tests
+func wasmCacheDir(modulePath string) string {
+	return filepath.Join(filepath.Dir(modulePath), "cache")
+}
+
+// startWASMTool starts the test module in a new workspace with caps
+func startWASMTool(t *testing.T, caps Capabilities) (*Module, map[string]tools.Tool, string) {
+	t.Helper()
+	path := buildWASMTool(t)
+	workspace := t.TempDir()
+	m, err := StartWASM(context.Background(), path, caps, WASMConfig{Workspace: workspace, CacheDir: wasmCacheDir(path)})
+	if err != nil {
+		t.Fatalf("StartWASM() error = %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	byName := make(map[string]tools.Tool)
+	for _, tool := range m.Tools() {
+		byName[tool.Name()] = tool
+	}
+	return m, byName, workspace
+}
+
+func TestWASM_FileCapabilities(t *testing.T) {
+	tests := []struct {
+		name      string
+		caps      Capabilities
+		wantRead  bool
+		wantWrite bool
+	}{
+		{name: "none", caps: Capabilities{}},
+		{name: "read", caps: Capabilities{Read: true}, wantRead: true},
+		{name: "write", caps: Capabilities{Write: true}, wantRead: true, wantWrite: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, moduleTools, workspace := startWASMTool(t, tt.caps)
+			if err := os.WriteFile(filepath.Join(workspace, "notes.txt"), []byte("hello"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := moduleTools["read_text"].Execute(context.Background(), []byte("<arguments><path>notes.txt</path></arguments>"))
+			if gotRead := err == nil && output == "hello"; gotRead != tt.wantRead {
+				t.Errorf("read: output %q, error %v; want read = %v", output, err, tt.wantRead)
+			}
+
+			_, err = moduleTools["write_text"].Execute(context.Background(), []byte("<arguments><path>out.txt</path><content>x</content></arguments>"))
+			_, statErr := os.Stat(filepath.Join(workspace, "out.txt"))
+			if gotWrite := err == nil && statErr == nil; gotWrite != tt.wantWrite {
+				t.Errorf("write: error %v; want write = %v", err, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestWASM_Preview(t *testing.T) {
+	_, moduleTools, _ := startWASMTool(t, Capabilities{})
+	previewable, ok := moduleTools["write_text"].(tools.Previewable)
+	if !ok {
+		t.Fatal("write_text declared preview support but is not previewable")
+	}
+	preview, err := previewable.GeneratePreview(context.Background(), []byte("<arguments><path>a</path><content>new</content></arguments>"))
+	if err != nil {
+		t.Fatalf("GeneratePreview() error = %v", err)
+	}
+	if preview.Content != "new" {
+		t.Errorf("preview content = %q, want %q", preview.Content, "new")
+	}
+}
+
+func TestWASM_Network(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "pong")
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	args := []byte("<arguments><url>" + server.URL + "</url></arguments>")
+
+	_, denied, _ := startWASMTool(t, Capabilities{})
+	output, err := denied["fetch"].Execute(context.Background(), args)
+	if err != nil || !strings.Contains(output, "not granted") {
+		t.Errorf("without network: output %q, error %v; want access denied", output, err)
+	}
+
+	_, granted, _ := startWASMTool(t, Capabilities{Network: []string{serverURL.Hostname()}})
+	output, err = granted["fetch"].Execute(context.Background(), args)
+	if err != nil || !strings.Contains(output, `"body":"pong"`) {
+		t.Errorf("with network: output %q, error %v; want the server's response", output, err)
+	}
+}
+
+func TestWASM_Canceled(t *testing.T) {
+	_, moduleTools, _ := startWASMTool(t, Capabilities{})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := moduleTools["spin"].Execute(ctx, []byte("<arguments></arguments>"))
+	if err != context.DeadlineExceeded {
+		t.Errorf("Execute() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLoadWASM_Grants(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile(buildWASMTool(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Modules are loaded whether or not they are executable
+	for name, mode := range map[string]os.FileMode{"reader.wasm": 0o644, "plain.wasm": 0o755} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modules, err := LoadWASM(context.Background(), WASMConfig{
+		Workspace: t.TempDir(),
+		CacheDir:  wasmCacheDir(buildWASMTool(t)),
+		Grants:    map[string]Capabilities{"reader": {Read: true}},
+	}, dir)
+	if err != nil {
+		t.Fatalf("LoadWASM() error = %v", err)
+	}
+	defer func() {
+		for _, m := range modules {
+			m.Close()
+		}
+	}()
+
+	if len(modules) != 2 {
+		t.Fatalf("LoadWASM() loaded %d modules, want 2", len(modules))
+	}
+	for _, m := range modules {
+		if want := m.Name() == "reader"; m.Capabilities().Read != want {
+			t.Errorf("module %s read capability = %v, want %v", m.Name(), m.Capabilities().Read, want)
+		}
+	}
+	if paths, _ := Discover(dir); len(paths) != 0 {
+		t.Errorf("Discover() treated modules as executables: %v", paths)
+	}
+}
@@ -0,0 +1,409 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultScanners returns the scanners audit_workspace runs
+func DefaultScanners() []Scanner {
+	return []Scanner{Govulncheck{}, NpmAudit{}, Gitleaks{}}
+}
+
+// Govulncheck finds known vulnerabilities in a Go module's dependencies and
+// standard library, and whether the vulnerable code is called
+type Govulncheck struct{}
+
+// Name returns the scanner name
+func (Govulncheck) Name() string { return "govulncheck" }
+
+// Executable returns the program the scanner runs
+func (Govulncheck) Executable() string { return "govulncheck" }
+
+// Install returns how to install govulncheck
+func (Govulncheck) Install() string { return "go install golang.org/x/vuln/cmd/govulncheck@latest" }
+
+// Applies reports whether the workspace is a Go module
+func (Govulncheck) Applies(workspaceDir string) (bool, string) {
+	return hasFile(workspaceDir, "go.mod")
+}
+
+// Scan runs govulncheck over every package of the module
+func (g Govulncheck) Scan(ctx context.Context, workspaceDir string) ([]Finding, error) {
+	stdout, err := run(ctx, workspaceDir, "govulncheck", "-format", "json", "./...")
+	findings, parseErr := parseGovulncheck(stdout, workspaceDir)
+	if err != nil && len(findings) == 0 {
+		// It reports vulnerabilities with exit status 0, so a failure
+		// without findings, such as a package that doesn't build, is real
+		return nil, err
+	}
+	return findings, parseErr
+}
+
+// govulncheckMessage is one object of govulncheck's JSON stream
+type govulncheckMessage struct {
+	OSV *struct {
+		ID               string `json:"id"`
+		Summary          string `json:"summary"`
+		DatabaseSpecific struct {
+			URL string `json:"url"`
+		} `json:"database_specific"`
+	} `json:"osv"`
+	Finding *struct {
+		OSV          string `json:"osv"`
+		FixedVersion string `json:"fixed_version"`
+		Trace        []struct {
+			Module   string `json:"module"`
+			Version  string `json:"version"`
+			Package  string `json:"package"`
+			Function string `json:"function"`
+			Position *struct {
+				Filename string `json:"filename"`
+				Line     int    `json:"line"`
+			} `json:"position"`
+		} `json:"trace"`
+	} `json:"finding"`
+}
+
+// parseGovulncheck turns govulncheck's JSON stream into one finding per
+// vulnerability. Vulnerable code the module calls is high severity; a
+// vulnerable module or package it only depends on is low.
+func parseGovulncheck(data []byte, workspaceDir string) ([]Finding, error) {
+	type advisory struct{ summary, url string }
+	advisories := make(map[string]advisory)
+	byID := make(map[string]*Finding)
+	var order []string
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var msg govulncheckMessage
+		if err := decoder.Decode(&msg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read govulncheck output: %w", err)
+		}
+
+		if msg.OSV != nil {
+			advisories[msg.OSV.ID] = advisory{summary: msg.OSV.Summary, url: msg.OSV.DatabaseSpecific.URL}
+		}
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+
+		vulnerable := msg.Finding.Trace[0]
+		finding := Finding{
+			Scanner:  "govulncheck",
+			Severity: SeverityLow,
+			ID:       msg.Finding.OSV,
+			Package:  vulnerable.Module,
+			Version:  vulnerable.Version,
+		}
+		if vulnerable.Package != "" {
+			finding.Package = vulnerable.Package
+		}
+		if msg.Finding.FixedVersion != "" {
+			finding.Fix = "upgrade " + vulnerable.Module + " to " + msg.Finding.FixedVersion
+		}
+		if vulnerable.Function != "" {
+			finding.Severity = SeverityHigh
+			// The last frame with a position is the module's own call
+			for i := len(msg.Finding.Trace) - 1; i >= 0; i-- {
+				if pos := msg.Finding.Trace[i].Position; pos != nil && pos.Filename != "" {
+					finding.File = relativePath(workspaceDir, pos.Filename)
+					finding.Line = pos.Line
+					break
+				}
+			}
+		}
+
+		// Keep the most severe finding of each vulnerability
+		if existing, ok := byID[finding.ID]; ok {
+			if rankOf(finding.Severity) < rankOf(existing.Severity) {
+				*existing = finding
+			}
+			continue
+		}
+		byID[finding.ID] = &finding
+		order = append(order, finding.ID)
+	}
+
+	findings := make([]Finding, 0, len(order))
+	for _, id := range order {
+		finding := *byID[id]
+		finding.Title = advisories[id].summary
+		finding.URL = advisories[id].url
+		if finding.URL == "" {
+			finding.URL = "https://pkg.go.dev/vuln/" + id
+		}
+		if finding.Severity == SeverityLow {
+			finding.Title += " (vulnerable code not called)"
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// NpmAudit finds known vulnerabilities in a Node.js project's locked
+// dependencies
+type NpmAudit struct{}
+
+// Name returns the scanner name
+func (NpmAudit) Name() string { return "npm-audit" }
+
+// Executable returns the program the scanner runs
+func (NpmAudit) Executable() string { return "npm" }
+
+// Install returns how to install npm
+func (NpmAudit) Install() string { return "install Node.js, which includes npm (https://nodejs.org)" }
+
+// Applies reports whether the workspace has an npm lockfile
+func (NpmAudit) Applies(workspaceDir string) (bool, string) {
+	if ok, _ := hasFile(workspaceDir, "package.json"); !ok {
+		return false, "no package.json"
+	}
+	if ok, _ := hasFile(workspaceDir, "package-lock.json"); ok {
+		return true, ""
+	}
+	if ok, _ := hasFile(workspaceDir, "npm-shrinkwrap.json"); ok {
+		return true, ""
+	}
+	return false, "no package-lock.json; npm audit needs a lockfile (npm install --package-lock-only)"
+}
+
+// Scan runs npm audit, which exits non-zero when it finds vulnerabilities
+func (n NpmAudit) Scan(ctx context.Context, workspaceDir string) ([]Finding, error) {
+	stdout, err := run(ctx, workspaceDir, "npm", "audit", "--json")
+	if err != nil && len(stdout) == 0 {
+		return nil, err
+	}
+	return parseNpmAudit(stdout)
+}
+
+// npmAuditReport is the part of npm audit's JSON report that is used
+type npmAuditReport struct {
+	Vulnerabilities map[string]struct {
+		Name         string            `json:"name"`
+		Via          []json.RawMessage `json:"via"` // Advisories, or names of vulnerable dependencies
+		FixAvailable json.RawMessage   `json:"fixAvailable"`
+	} `json:"vulnerabilities"`
+	Error *struct {
+		Summary string `json:"summary"`
+	} `json:"error"`
+}
+
+// npmAdvisory is an advisory in a vulnerability's "via" list
+type npmAdvisory struct {
+	Source   int    `json:"source"`
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Severity string `json:"severity"`
+	Range    string `json:"range"`
+}
+
+// parseNpmAudit turns npm audit's report into one finding per advisory.
+// Packages that are only vulnerable through a dependency add no findings
+// of their own.
+func parseNpmAudit(data []byte) ([]Finding, error) {
+	var report npmAuditReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to read npm audit output: %w", err)
+	}
+	if report.Error != nil {
+		return nil, fmt.Errorf("npm audit failed: %s", report.Error.Summary)
+	}
+
+	seen := make(map[string]bool)
+	var findings []Finding
+	for _, vuln := range report.Vulnerabilities {
+		for _, raw := range vuln.Via {
+			var advisory npmAdvisory
+			if json.Unmarshal(raw, &advisory) != nil || advisory.Title == "" {
+				continue // The name of a vulnerable dependency
+			}
+
+			id := advisory.URL[strings.LastIndex(advisory.URL, "/")+1:]
+			if id == "" {
+				id = fmt.Sprintf("npm-%d", advisory.Source)
+			}
+			if seen[id+advisory.Name] {
+				continue
+			}
+			seen[id+advisory.Name] = true
+
+			findings = append(findings, Finding{
+				Scanner:  "npm-audit",
+				Severity: normalizeSeverity(advisory.Severity),
+				ID:       id,
+				Title:    advisory.Title,
+				Package:  advisory.Name,
+				Version:  advisory.Range,
+				Fix:      npmFix(vuln.FixAvailable),
+				URL:      advisory.URL,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// npmFix describes a vulnerability's fixAvailable field
+func npmFix(raw json.RawMessage) string {
+	var available bool
+	if json.Unmarshal(raw, &available) == nil {
+		if available {
+			return "npm audit fix"
+		}
+		return "no fix available"
+	}
+	var upgrade struct {
+		Name          string `json:"name"`
+		Version       string `json:"version"`
+		IsSemVerMajor bool   `json:"isSemVerMajor"`
+	}
+	if json.Unmarshal(raw, &upgrade) != nil || upgrade.Name == "" {
+		return ""
+	}
+	fix := fmt.Sprintf("upgrade %s to %s", upgrade.Name, upgrade.Version)
+	if upgrade.IsSemVerMajor {
+		fix += " (major version)"
+	}
+	return fix
+}
+
+// Gitleaks finds secrets such as API keys and passwords committed to the
+// repository or present in its files
+type Gitleaks struct{}
+
+// Name returns the scanner name
+func (Gitleaks) Name() string { return "gitleaks" }
+
+// Executable returns the program the scanner runs
+func (Gitleaks) Executable() string { return "gitleaks" }
+
+// Install returns how to install gitleaks
+func (Gitleaks) Install() string {
+	return "go install github.com/zricethezav/gitleaks/v8@latest, or see https://github.com/gitleaks/gitleaks#installing"
+}
+
+// Applies reports true: any workspace can hold secrets
+func (Gitleaks) Applies(workspaceDir string) (bool, string) {
+	return true, ""
+}
+
+// Scan runs gitleaks over the git history, or the files outside a git
+// repository. Secrets are redacted in its report.
+func (g Gitleaks) Scan(ctx context.Context, workspaceDir string) ([]Finding, error) {
+	report, err := os.CreateTemp("", "forge-gitleaks-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gitleaks report: %w", err)
+	}
+	report.Close()
+	defer os.Remove(report.Name())
+
+	args := []string{"detect", "--source", ".", "--no-banner", "--redact", "--exit-code", "0",
+		"--report-format", "json", "--report-path", report.Name()}
+	if ok, _ := hasFile(workspaceDir, ".git"); !ok {
+		args = append(args, "--no-git")
+	}
+	if _, err := run(ctx, workspaceDir, "gitleaks", args...); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(report.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gitleaks report: %w", err)
+	}
+	return parseGitleaks(data)
+}
+
+// gitleaksLeak is one leak in gitleaks' JSON report
+type gitleaksLeak struct {
+	Description string `json:"Description"`
+	RuleID      string `json:"RuleID"`
+	File        string `json:"File"`
+	StartLine   int    `json:"StartLine"`
+	Commit      string `json:"Commit"`
+}
+
+// parseGitleaks turns gitleaks' report into critical findings. The secrets
+// themselves are left out.
+func parseGitleaks(data []byte) ([]Finding, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var leaks []gitleaksLeak
+	if err := json.Unmarshal(data, &leaks); err != nil {
+		return nil, fmt.Errorf("failed to read gitleaks report: %w", err)
+	}
+
+	findings := make([]Finding, 0, len(leaks))
+	for _, leak := range leaks {
+		fix := "remove the secret and rotate it"
+		if leak.Commit != "" {
+			commit := leak.Commit
+			if len(commit) > 12 {
+				commit = commit[:12]
+			}
+			fix = "rotate the secret; it is in the git history since commit " + commit
+		}
+		findings = append(findings, Finding{
+			Scanner:  "gitleaks",
+			Severity: SeverityCritical,
+			ID:       leak.RuleID,
+			Title:    leak.Description,
+			Fix:      fix,
+			File:     filepath.ToSlash(leak.File),
+			Line:     leak.StartLine,
+		})
+	}
+	return findings, nil
+}
+
+// run runs a scanner in workspaceDir and returns its stdout. A non-zero
+// exit is returned as an error along with the output, since some scanners
+// exit non-zero when they find problems.
+func run(ctx context.Context, workspaceDir, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = workspaceDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if len(detail) > 500 {
+			detail = detail[:500] + "..."
+		}
+		return stdout.Bytes(), fmt.Errorf("%s failed: %w: %s", name, err, detail)
+	}
+	return stdout.Bytes(), nil
+}
+
+// hasFile reports whether name exists in dir
+func hasFile(dir, name string) (bool, string) {
+	if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+		return false, "no " + name
+	}
+	return true, ""
+}
+
+// relativePath returns path relative to workspaceDir when it is inside it
+func relativePath(workspaceDir, path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	if abs, err := filepath.Abs(workspaceDir); err == nil {
+		if rel, err := filepath.Rel(abs, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
@@ -0,0 +1,172 @@
+// Package security provides the audit_workspace tool, which runs the
+// dependency and secret scanners installed on the machine (govulncheck,
+// npm audit and gitleaks) over the workspace and normalizes their reports
+// into one list of findings, most severe first.
+//
+// Scanners that aren't installed, or don't apply to the workspace, are
+// reported as such rather than failing the audit, so the model can say what
+// was and wasn't checked.
+package security
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Finding severities, most severe first
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// severityRank orders severities for sorting and filtering
+var severityRank = map[string]int{
+	SeverityCritical: 0,
+	SeverityHigh:     1,
+	SeverityMedium:   2,
+	SeverityLow:      3,
+}
+
+// Scanner statuses
+const (
+	StatusOK            = "ok"             // The scanner ran
+	StatusNotInstalled  = "not_installed"  // Its executable isn't on PATH
+	StatusNotApplicable = "not_applicable" // The workspace has nothing for it to scan
+	StatusFailed        = "failed"         // It ran but its report couldn't be read
+)
+
+// DefaultTimeout bounds each scanner's run
+const DefaultTimeout = 5 * time.Minute
+
+// Finding is one problem a scanner reported
+type Finding struct {
+	Scanner  string `json:"scanner"`
+	Severity string `json:"severity"`
+	ID       string `json:"id"` // Advisory ID such as GO-2024-2687 or GHSA-..., or the secret rule
+	Title    string `json:"title"`
+	Package  string `json:"package,omitempty"` // Affected module or package
+	Version  string `json:"version,omitempty"` // Affected version or range
+	Fix      string `json:"fix,omitempty"`     // How to fix it, e.g. "upgrade to v1.2.3"
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Report is the outcome of one scanner
+type Report struct {
+	Scanner  string    `json:"scanner"`
+	Status   string    `json:"status"`
+	Detail   string    `json:"detail,omitempty"` // Why it didn't run, or how to install it
+	Findings []Finding `json:"findings"`
+}
+
+// Scanner runs one security tool over a workspace
+type Scanner interface {
+	// Name identifies the scanner in reports and the tool's arguments
+	Name() string
+
+	// Executable is the program that must be on PATH
+	Executable() string
+
+	// Install tells the user how to install the executable
+	Install() string
+
+	// Applies reports whether the workspace has anything to scan, and why not
+	Applies(workspaceDir string) (bool, string)
+
+	// Scan runs the scanner and returns its findings
+	Scan(ctx context.Context, workspaceDir string) ([]Finding, error)
+}
+
+// Audit runs each scanner over workspaceDir, each bounded by timeout
+func Audit(ctx context.Context, workspaceDir string, scanners []Scanner, timeout time.Duration) []Report {
+	reports := make([]Report, 0, len(scanners))
+	for _, scanner := range scanners {
+		report := Report{Scanner: scanner.Name(), Findings: []Finding{}}
+		if ok, why := scanner.Applies(workspaceDir); !ok {
+			report.Status, report.Detail = StatusNotApplicable, why
+			reports = append(reports, report)
+			continue
+		}
+		if _, err := exec.LookPath(scanner.Executable()); err != nil {
+			report.Status = StatusNotInstalled
+			report.Detail = fmt.Sprintf("%s not found on PATH; install with: %s", scanner.Executable(), scanner.Install())
+			reports = append(reports, report)
+			continue
+		}
+
+		scanCtx, cancel := context.WithTimeout(ctx, timeout)
+		findings, err := scanner.Scan(scanCtx, workspaceDir)
+		if err != nil && scanCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		cancel()
+
+		if err != nil {
+			report.Status, report.Detail = StatusFailed, err.Error()
+		} else {
+			report.Status = StatusOK
+			if findings != nil {
+				report.Findings = findings
+			}
+			sortFindings(report.Findings)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// AtLeast reports whether severity is minimum or more severe. Unknown
+// severities count as low.
+func AtLeast(severity, minimum string) bool {
+	rank, ok := severityRank[severity]
+	if !ok {
+		rank = severityRank[SeverityLow]
+	}
+	return rank <= severityRank[minimum]
+}
+
+// sortFindings orders findings most severe first, then by ID and location
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if rankOf(a.Severity) != rankOf(b.Severity) {
+			return rankOf(a.Severity) < rankOf(b.Severity)
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+}
+
+// rankOf returns the sort rank of severity; unknown severities sort last
+func rankOf(severity string) int {
+	if rank, ok := severityRank[severity]; ok {
+		return rank
+	}
+	return len(severityRank)
+}
+
+// normalizeSeverity maps a scanner's severity onto the common scale
+func normalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "moderate", "medium":
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
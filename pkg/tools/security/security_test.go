@@ -0,0 +1,159 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeScanner returns canned findings; it "runs" sh so it counts as installed
+type fakeScanner struct {
+	name       string
+	executable string
+	applies    bool
+	findings   []Finding
+	err        error
+}
+
+func (f fakeScanner) Name() string { return f.name }
+
+func (f fakeScanner) Executable() string {
+	if f.executable != "" {
+		return f.executable
+	}
+	return "sh"
+}
+
+func (f fakeScanner) Install() string { return "install " + f.name }
+
+func (f fakeScanner) Applies(workspaceDir string) (bool, string) {
+	if !f.applies {
+		return false, "nothing to scan"
+	}
+	return true, ""
+}
+
+func (f fakeScanner) Scan(ctx context.Context, workspaceDir string) ([]Finding, error) {
+	return f.findings, f.err
+}
+
+func TestParseGovulncheck(t *testing.T) {
+	stream := `{"config":{"scanner_name":"govulncheck"}}
+{"osv":{"id":"GO-2024-0001","summary":"Panic in parser","database_specific":{"url":"https://pkg.go.dev/vuln/GO-2024-0001"}}}
+{"osv":{"id":"GO-2024-0002","summary":"Leak in client"}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v1.2.3","trace":[{"module":"example.com/parser","version":"v1.2.0"}]}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v1.2.3","trace":[
+  {"module":"example.com/parser","version":"v1.2.0","package":"example.com/parser","function":"Parse"},
+  {"module":"example.com/app","package":"example.com/app","function":"main","position":{"filename":"/work/main.go","line":12}}
+]}}
+{"finding":{"osv":"GO-2024-0002","fixed_version":"v0.5.0","trace":[{"module":"example.com/client","version":"v0.4.0","package":"example.com/client"}]}}
+`
+	findings, err := parseGovulncheck([]byte(stream), "/work")
+	if err != nil {
+		t.Fatalf("parseGovulncheck failed: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected one finding per vulnerability, got %+v", findings)
+	}
+
+	called := findings[0]
+	if called.Severity != SeverityHigh || called.File != "main.go" || called.Line != 12 || called.Fix != "upgrade example.com/parser to v1.2.3" || called.Title != "Panic in parser" {
+		t.Errorf("unexpected finding for called code: %+v", called)
+	}
+	imported := findings[1]
+	if imported.Severity != SeverityLow || imported.Package != "example.com/client" || imported.URL != "https://pkg.go.dev/vuln/GO-2024-0002" || !strings.HasSuffix(imported.Title, "(vulnerable code not called)") {
+		t.Errorf("unexpected finding for imported code: %+v", imported)
+	}
+}
+
+func TestParseNpmAudit(t *testing.T) {
+	report := `{"vulnerabilities":{
+  "lodash":{"name":"lodash","via":[{"source":1,"name":"lodash","title":"Prototype pollution","url":"https://github.com/advisories/GHSA-aaaa","severity":"high","range":"<4.17.21"}],"fixAvailable":true},
+  "request":{"name":"request","via":["tough-cookie"],"fixAvailable":{"name":"request","version":"3.0.0","isSemVerMajor":true}},
+  "tough-cookie":{"name":"tough-cookie","via":[{"source":2,"name":"tough-cookie","title":"ReDoS","url":"https://github.com/advisories/GHSA-bbbb","severity":"moderate","range":"<4.1.3"}],"fixAvailable":{"name":"request","version":"3.0.0","isSemVerMajor":true}}
+}}`
+	findings, err := parseNpmAudit([]byte(report))
+	if err != nil {
+		t.Fatalf("parseNpmAudit failed: %v", err)
+	}
+	sortFindings(findings)
+	if len(findings) != 2 {
+		t.Fatalf("expected one finding per advisory, got %+v", findings)
+	}
+	if f := findings[0]; f.ID != "GHSA-aaaa" || f.Severity != SeverityHigh || f.Fix != "npm audit fix" || f.Version != "<4.17.21" {
+		t.Errorf("unexpected lodash finding: %+v", f)
+	}
+	if f := findings[1]; f.ID != "GHSA-bbbb" || f.Severity != SeverityMedium || f.Fix != "upgrade request to 3.0.0 (major version)" {
+		t.Errorf("unexpected tough-cookie finding: %+v", f)
+	}
+
+	if _, err := parseNpmAudit([]byte(`{"error":{"summary":"lockfile is invalid"}}`)); err == nil || !strings.Contains(err.Error(), "lockfile is invalid") {
+		t.Errorf("expected npm's error to be reported, got %v", err)
+	}
+}
+
+func TestParseGitleaks(t *testing.T) {
+	report := `[{"Description":"AWS Access Key","RuleID":"aws-access-token","File":"config/dev.env","StartLine":3,"Secret":"REDACTED","Commit":"0123456789abcdef"}]`
+	findings, err := parseGitleaks([]byte(report))
+	if err != nil {
+		t.Fatalf("parseGitleaks failed: %v", err)
+	}
+	want := Finding{Scanner: "gitleaks", Severity: SeverityCritical, ID: "aws-access-token", Title: "AWS Access Key",
+		Fix: "rotate the secret; it is in the git history since commit 0123456789ab", File: "config/dev.env", Line: 3}
+	if len(findings) != 1 || findings[0] != want {
+		t.Errorf("unexpected findings %+v", findings)
+	}
+}
+
+func TestAuditWorkspaceTool(t *testing.T) {
+	tool := NewAuditWorkspaceTool(t.TempDir(), WithScanners(
+		fakeScanner{name: "deps", applies: true, findings: []Finding{
+			{Scanner: "deps", Severity: SeverityLow, ID: "LOW-1", Title: "minor issue"},
+			{Scanner: "deps", Severity: SeverityHigh, ID: "HIGH-1", Title: "bad issue", Package: "lib", Version: "v1.0.0", Fix: "upgrade lib to v1.0.1"},
+		}},
+		fakeScanner{name: "secrets", applies: true, findings: []Finding{
+			{Scanner: "secrets", Severity: SeverityCritical, ID: "api-key", Title: "API key", File: "app.env", Line: 2},
+		}},
+		fakeScanner{name: "missing", executable: "forge-no-such-scanner", applies: true},
+		fakeScanner{name: "npm", applies: false},
+		fakeScanner{name: "broken", applies: true, err: errors.New("exit status 2")},
+	))
+
+	result, err := tool.ExecuteResult(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if result.Summary != "3 finding(s) from 2 of 5 scanner(s): 1 critical, 1 high, 1 low" {
+		t.Errorf("unexpected summary %q", result.Summary)
+	}
+	for _, want := range []string{
+		"missing: not run, forge-no-such-scanner not found on PATH; install with: install missing",
+		"npm: skipped, nothing to scan",
+		"broken: failed: exit status 2",
+		"[CRITICAL] secrets api-key: API key\n  location: app.env:2",
+		"[HIGH] deps HIGH-1: bad issue\n  package: lib v1.0.0\n  fix: upgrade lib to v1.0.1",
+	} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, result.Output)
+		}
+	}
+	if strings.Index(result.Output, "[CRITICAL]") > strings.Index(result.Output, "[LOW]") {
+		t.Error("expected findings ordered most severe first")
+	}
+	if len(result.Artifacts) != 1 || result.Artifacts[0].Path != "app.env" || result.Artifacts[0].Line != 2 {
+		t.Errorf("expected the secret's file as an artifact, got %+v", result.Artifacts)
+	}
+
+	filtered, err := tool.ExecuteResult(context.Background(), []byte("<arguments><scanner>deps</scanner><min_severity>high</min_severity></arguments>"))
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if filtered.Summary != "1 finding(s) from 1 of 1 scanner(s): 1 high" {
+		t.Errorf("unexpected filtered summary %q", filtered.Summary)
+	}
+
+	if _, err := tool.Execute(context.Background(), []byte("<arguments><scanner>trivy</scanner></arguments>")); err == nil {
+		t.Error("expected an unknown scanner to be rejected")
+	}
+}
@@ -0,0 +1,249 @@
+package security
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// maxListed caps the findings listed for the model; the rest are counted
+const maxListed = 40
+
+// AuditWorkspaceTool runs the installed dependency and secret scanners over
+// the workspace and reports their findings in one schema.
+type AuditWorkspaceTool struct {
+	workspaceDir string
+	scanners     []Scanner
+	timeout      time.Duration
+}
+
+// Option configures an AuditWorkspaceTool
+type Option func(*AuditWorkspaceTool)
+
+// WithScanners replaces the scanners run, DefaultScanners by default
+func WithScanners(scanners ...Scanner) Option {
+	return func(t *AuditWorkspaceTool) {
+		t.scanners = scanners
+	}
+}
+
+// WithTimeout bounds each scanner's run, DefaultTimeout by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(t *AuditWorkspaceTool) {
+		t.timeout = timeout
+	}
+}
+
+// NewAuditWorkspaceTool creates a new AuditWorkspaceTool scanning workspaceDir
+func NewAuditWorkspaceTool(workspaceDir string, opts ...Option) *AuditWorkspaceTool {
+	t := &AuditWorkspaceTool{
+		workspaceDir: workspaceDir,
+		scanners:     DefaultScanners(),
+		timeout:      DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Name returns the tool name.
+func (t *AuditWorkspaceTool) Name() string {
+	return "audit_workspace"
+}
+
+// Description returns the tool description.
+func (t *AuditWorkspaceTool) Description() string {
+	return "Run a security audit of the workspace with the scanners installed on this machine: govulncheck (known vulnerabilities in Go dependencies, and whether the vulnerable code is called), npm audit (vulnerable npm dependencies) and gitleaks (secrets in files and git history, redacted). Findings from all scanners are listed most severe first with their fix; scanners that aren't installed or don't apply are reported as such. Read-only; scans can take minutes."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *AuditWorkspaceTool) Schema() map[string]interface{} {
+	names := make([]string, len(t.scanners))
+	for i, scanner := range t.scanners {
+		names[i] = scanner.Name()
+	}
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"scanner": map[string]interface{}{
+				"type":        "string",
+				"enum":        names,
+				"description": "Run only this scanner (default: all of them)",
+			},
+			"min_severity": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow},
+				"description": "Leave out findings less severe than this (default: low, i.e. list everything)",
+			},
+		},
+		[]string{},
+	)
+}
+
+// Execute runs the audit.
+func (t *AuditWorkspaceTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	result, err := t.ExecuteResult(ctx, argsXML)
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// ExecuteResult runs the audit, with the scanner reports as the data and
+// the files of findings as artifacts.
+func (t *AuditWorkspaceTool) ExecuteResult(ctx context.Context, argsXML []byte) (*tools.Result, error) {
+	var input struct {
+		XMLName     xml.Name `xml:"arguments"`
+		Scanner     string   `xml:"scanner"`
+		MinSeverity string   `xml:"min_severity"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	minimum := strings.ToLower(strings.TrimSpace(input.MinSeverity))
+	if minimum == "" {
+		minimum = SeverityLow
+	}
+	if _, ok := severityRank[minimum]; !ok {
+		return nil, fmt.Errorf("invalid min_severity %q: use critical, high, medium or low", input.MinSeverity)
+	}
+
+	scanners := t.scanners
+	if name := strings.TrimSpace(input.Scanner); name != "" {
+		scanners = nil
+		for _, scanner := range t.scanners {
+			if scanner.Name() == name {
+				scanners = []Scanner{scanner}
+			}
+		}
+		if scanners == nil {
+			return nil, fmt.Errorf("unknown scanner %q", name)
+		}
+	}
+
+	reports := Audit(ctx, t.workspaceDir, scanners, t.timeout)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i := range reports {
+		kept := reports[i].Findings[:0]
+		for _, finding := range reports[i].Findings {
+			if AtLeast(finding.Severity, minimum) {
+				kept = append(kept, finding)
+			}
+		}
+		reports[i].Findings = kept
+	}
+
+	result := &tools.Result{
+		Success: true,
+		Summary: summarize(reports),
+		Output:  formatReports(reports),
+		Data:    map[string]interface{}{"reports": reports, "min_severity": minimum},
+	}
+	for _, report := range reports {
+		for _, finding := range report.Findings {
+			if finding.File != "" {
+				result.Artifacts = append(result.Artifacts, tools.Artifact{Kind: tools.ArtifactFile, Path: finding.File, Line: finding.Line})
+			}
+		}
+	}
+	return result, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *AuditWorkspaceTool) IsLoopBreaking() bool {
+	return false
+}
+
+// summarize returns the one-line summary: the findings by severity and the
+// scanners that ran
+func summarize(reports []Report) string {
+	counts := make(map[string]int)
+	total, ran := 0, 0
+	for _, report := range reports {
+		if report.Status == StatusOK {
+			ran++
+		}
+		for _, finding := range report.Findings {
+			counts[finding.Severity]++
+			total++
+		}
+	}
+
+	var parts []string
+	for _, severity := range []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow} {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	summary := fmt.Sprintf("%d finding(s) from %d of %d scanner(s)", total, ran, len(reports))
+	if len(parts) > 0 {
+		summary += ": " + strings.Join(parts, ", ")
+	}
+	return summary
+}
+
+// formatReports renders the audit for the model: each scanner's status,
+// then the findings of all scanners, most severe first
+func formatReports(reports []Report) string {
+	var b strings.Builder
+	b.WriteString("Security audit: " + summarize(reports) + "\n\nScanners:\n")
+
+	var findings []Finding
+	for _, report := range reports {
+		switch report.Status {
+		case StatusOK:
+			fmt.Fprintf(&b, "  %s: %d finding(s)\n", report.Scanner, len(report.Findings))
+		case StatusNotInstalled:
+			fmt.Fprintf(&b, "  %s: not run, %s\n", report.Scanner, report.Detail)
+		case StatusNotApplicable:
+			fmt.Fprintf(&b, "  %s: skipped, %s\n", report.Scanner, report.Detail)
+		default:
+			fmt.Fprintf(&b, "  %s: failed: %s\n", report.Scanner, report.Detail)
+		}
+		findings = append(findings, report.Findings...)
+	}
+
+	if len(findings) == 0 {
+		b.WriteString("\nNo findings.")
+		return b.String()
+	}
+
+	sortFindings(findings)
+	b.WriteString("\nFindings:\n")
+	for i, finding := range findings {
+		if i == maxListed {
+			fmt.Fprintf(&b, "\n... %d more finding(s) not listed; use min_severity or scanner to narrow the audit\n", len(findings)-maxListed)
+			break
+		}
+		fmt.Fprintf(&b, "\n[%s] %s %s: %s\n", strings.ToUpper(finding.Severity), finding.Scanner, finding.ID, finding.Title)
+		if finding.Package != "" {
+			pkg := finding.Package
+			if finding.Version != "" {
+				pkg += " " + finding.Version
+			}
+			fmt.Fprintf(&b, "  package: %s\n", pkg)
+		}
+		if finding.File != "" {
+			location := finding.File
+			if finding.Line > 0 {
+				location += fmt.Sprintf(":%d", finding.Line)
+			}
+			fmt.Fprintf(&b, "  location: %s\n", location)
+		}
+		if finding.Fix != "" {
+			fmt.Fprintf(&b, "  fix: %s\n", finding.Fix)
+		}
+		if finding.URL != "" {
+			fmt.Fprintf(&b, "  details: %s\n", finding.URL)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
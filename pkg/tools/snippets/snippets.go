@@ -0,0 +1,222 @@
+// Package snippets provides the get_snippet tool: a library of approved
+// boilerplate, such as an error handling pattern or a test scaffold, that
+// the agent fills in instead of writing its own variant each session.
+//
+// A snippet is a YAML file in a workspace's .forge/snippets directory or in
+// ~/.forge/snippets, named after the file:
+//
+//	description: Wrap an error with the operation that failed
+//	language: go
+//	variables:
+//	  op: ""      # Empty default: must be given
+//	  err: err
+//	template: |
+//	  if {{.err}} != nil {
+//	      return fmt.Errorf("failed to {{.op}}: %w", {{.err}})
+//	  }
+//
+// Templates are Go templates over the variables. A snippet may also suggest
+// the file it belongs in with a path, which is a template too.
+package snippets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is where a workspace's snippets live, relative to its root. The
+// user's own snippets are in the same directory under their home.
+const Dir = ".forge/snippets"
+
+// variableName matches the variable names a template can refer to as
+// {{.name}}
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Snippet is a named code template
+type Snippet struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Language    string            `yaml:"language,omitempty"`
+	Variables   map[string]string `yaml:"variables,omitempty"` // Defaults; an empty default makes the variable required
+	Path        string            `yaml:"path,omitempty"`      // Template of the file the snippet belongs in, if any
+	Template    string            `yaml:"template"`
+
+	// File is the file the snippet was read from
+	File string `yaml:"-"`
+}
+
+// validate checks that the snippet can be rendered
+func (s *Snippet) validate() error {
+	if strings.TrimSpace(s.Template) == "" {
+		return fmt.Errorf("template is required")
+	}
+	for name := range s.Variables {
+		if !variableName.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q: use letters, digits and underscores", name)
+		}
+	}
+	for _, text := range []string{s.Template, s.Path} {
+		if _, err := parse(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads a snippet file. The name defaults to the file name without its
+// extension.
+func Load(path string) (*Snippet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet: %w", err)
+	}
+
+	s := &Snippet{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	s.File = path
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Find returns the snippet called name from the first of dirs that has it
+func Find(name string, dirs ...string) (*Snippet, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid snippet name %q", name)
+	}
+	snippets, err := List(dirs...)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(snippets))
+	for _, s := range snippets {
+		if s.Name == name {
+			return s, nil
+		}
+		names = append(names, s.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("snippet %q not found: there are no snippets", name)
+	}
+	return nil, fmt.Errorf("snippet %q not found; available: %s", name, strings.Join(names, ", "))
+}
+
+// List returns the snippets in dirs, in name order. A snippet in an earlier
+// directory hides one of the same name in a later one; directories that
+// don't exist are skipped.
+func List(dirs ...string) ([]*Snippet, error) {
+	byName := make(map[string]*Snippet)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snippets: %w", err)
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			s, err := Load(filepath.Join(dirs[i], entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			byName[s.Name] = s
+		}
+	}
+
+	snippets := make([]*Snippet, 0, len(byName))
+	for _, s := range byName {
+		snippets = append(snippets, s)
+	}
+	sort.Slice(snippets, func(i, j int) bool { return snippets[i].Name < snippets[j].Name })
+	return snippets, nil
+}
+
+// Required returns the variables without a default, in name order
+func (s *Snippet) Required() []string {
+	var required []string
+	for name, value := range s.Variables {
+		if value == "" {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+	return required
+}
+
+// Rendered is a snippet filled in with its variables
+type Rendered struct {
+	Code string
+	Path string // Suggested file, empty if the snippet has none
+}
+
+// Render fills in the snippet with values set over the variables' defaults.
+// It fails if a value is set for a variable the snippet doesn't have, or a
+// variable without a default is not set.
+func (s *Snippet) Render(values map[string]string) (*Rendered, error) {
+	vars := make(map[string]string, len(s.Variables))
+	for name, value := range s.Variables {
+		vars[name] = value
+	}
+	for name, value := range values {
+		if _, ok := s.Variables[name]; !ok {
+			return nil, fmt.Errorf("snippet %s has no variable %q", s.Name, name)
+		}
+		vars[name] = value
+	}
+	var missing []string
+	for name, value := range vars {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("snippet %s requires variable %s", s.Name, strings.Join(missing, ", "))
+	}
+
+	code, err := execute(s.Template, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render snippet %s: %w", s.Name, err)
+	}
+	path, err := execute(s.Path, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the path of snippet %s: %w", s.Name, err)
+	}
+	return &Rendered{Code: code, Path: path}, nil
+}
+
+// parse parses a snippet template
+func parse(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(text)
+}
+
+// execute renders text with vars
+func execute(text string, vars map[string]string) (string, error) {
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,142 @@
+package snippets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSnippet writes a snippet file to dir
+func writeSnippet(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const errorWrap = `description: Wrap an error with the operation that failed
+language: go
+variables:
+  op: ""
+  err: err
+template: |
+  if {{.err}} != nil {
+  	return fmt.Errorf("failed to {{.op}}: %w", {{.err}})
+  }
+`
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	writeSnippet(t, dir, "error-wrap.yaml", errorWrap)
+	s, err := Load(filepath.Join(dir, "error-wrap.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "error-wrap" || s.Language != "go" {
+		t.Errorf("expected the name from the file, got %+v", s)
+	}
+	if got := s.Required(); len(got) != 1 || got[0] != "op" {
+		t.Errorf("expected op to be required, got %v", got)
+	}
+
+	rendered, err := s.Render(map[string]string{"op": "read config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rendered.Code, `if err != nil {`) || !strings.Contains(rendered.Code, `"failed to read config: %w", err)`) {
+		t.Errorf("unexpected code %q", rendered.Code)
+	}
+
+	if _, err := s.Render(nil); err == nil || !strings.Contains(err.Error(), "requires variable op") {
+		t.Errorf("expected a missing variable to be reported, got %v", err)
+	}
+	if _, err := s.Render(map[string]string{"op": "x", "typo": "y"}); err == nil || !strings.Contains(err.Error(), `no variable "typo"`) {
+		t.Errorf("expected an unknown variable to be rejected, got %v", err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"empty.yaml":   "description: nothing\n",
+		"badvar.yaml":  "variables:\n  my-var: x\ntemplate: x\n",
+		"badtmpl.yaml": "template: \"{{.x\"\n",
+		"badpath.yaml": "path: \"{{end}}\"\ntemplate: x\n",
+		"notyaml.yaml": "template: [\n",
+	} {
+		writeSnippet(t, dir, name, content)
+		if _, err := Load(filepath.Join(dir, name)); err == nil {
+			t.Errorf("expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestFindAndList(t *testing.T) {
+	workspace, user := t.TempDir(), t.TempDir()
+	writeSnippet(t, user, "error-wrap.yaml", errorWrap)
+	writeSnippet(t, user, "table-test.yml", "description: user's\ntemplate: user\n")
+	writeSnippet(t, workspace, "table-test.yaml", "description: project's\nvariables:\n  fn: \"\"\npath: \"{{.fn}}_test.go\"\ntemplate: \"func Test{{.fn}}(t *testing.T) {}\"\n")
+	writeSnippet(t, workspace, "README.md", "not a snippet")
+
+	snippets, err := List(workspace, user, filepath.Join(user, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippets) != 2 || snippets[0].Name != "error-wrap" || snippets[1].Description != "project's" {
+		t.Fatalf("expected the workspace snippet to hide the user's, got %+v", snippets)
+	}
+
+	s, err := Find("table-test", workspace, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := s.Render(map[string]string{"fn": "Parse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Path != "Parse_test.go" || rendered.Code != "func TestParse(t *testing.T) {}" {
+		t.Errorf("unexpected rendering %+v", rendered)
+	}
+
+	if _, err := Find("missing", workspace, user); err == nil || !strings.Contains(err.Error(), "available: error-wrap, table-test") {
+		t.Errorf("expected the available snippets to be named, got %v", err)
+	}
+	if _, err := Find("../secret", workspace); err == nil {
+		t.Error("expected a path to be rejected as a name")
+	}
+}
+
+func TestGetSnippetTool(t *testing.T) {
+	dir := t.TempDir()
+	writeSnippet(t, dir, "error-wrap.yaml", errorWrap)
+	tool := NewGetSnippetTool(dir)
+	if !strings.Contains(tool.Description(), "Snippets: error-wrap.") {
+		t.Errorf("expected the description to name the snippets, got %q", tool.Description())
+	}
+
+	list, err := tool.Execute(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(list, "- error-wrap (go): Wrap an error") || !strings.Contains(list, `err (default "err"), op (required)`) {
+		t.Errorf("unexpected list %q", list)
+	}
+
+	args := "<arguments><name>error-wrap</name><variables><variable><name>op</name><value>open file</value></variable></variables></arguments>"
+	out, err := tool.Execute(context.Background(), []byte(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "```go\nif err != nil {") || !strings.Contains(out, "failed to open file") {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	if out, err := NewGetSnippetTool(t.TempDir()).Execute(context.Background(), []byte("<arguments></arguments>")); err != nil || !strings.Contains(out, "no snippets") {
+		t.Errorf("expected an empty library to be reported, got %q, %v", out, err)
+	}
+}
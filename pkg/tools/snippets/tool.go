@@ -0,0 +1,168 @@
+package snippets
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// GetSnippetTool fills in a snippet from the library for the agent to use.
+// It only reads, so it runs without approval.
+type GetSnippetTool struct {
+	dirs        []string
+	description string
+}
+
+// NewGetSnippetTool creates a GetSnippetTool over the snippets in dirs, the
+// earlier directories taking precedence. The snippets found now are named
+// in the tool's description; ones added later are found when used.
+func NewGetSnippetTool(dirs ...string) *GetSnippetTool {
+	description := "Get a snippet from the project's library of approved code templates, such as error handling patterns and test scaffolds, filled in with your variables. When a snippet fits what you are writing, use it rather than writing your own variant, so code stays consistent across sessions. Call without a name to list the snippets with their descriptions and variables."
+	if snippets, err := List(dirs...); err == nil && len(snippets) > 0 {
+		names := make([]string, len(snippets))
+		for i, s := range snippets {
+			names[i] = s.Name
+		}
+		description += " Snippets: " + strings.Join(names, ", ") + "."
+	}
+	return &GetSnippetTool{dirs: dirs, description: description}
+}
+
+// Name returns the tool name.
+func (t *GetSnippetTool) Name() string {
+	return "get_snippet"
+}
+
+// Description returns the tool description.
+func (t *GetSnippetTool) Description() string {
+	return t.description
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *GetSnippetTool) Schema() map[string]interface{} {
+	return tools.BaseToolSchema(
+		map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Snippet to fill in; omit to list the snippets",
+			},
+			"variables": map[string]interface{}{
+				"type":        "array",
+				"description": "Values of the snippet's variables; variables without a default are required",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Variable name",
+						},
+						"value": map[string]interface{}{
+							"type":        "string",
+							"description": "Variable value",
+						},
+					},
+					"required": []string{"name", "value"},
+				},
+			},
+		},
+		nil,
+	)
+}
+
+// Execute lists the snippets, or fills in the one named.
+func (t *GetSnippetTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	var input struct {
+		XMLName   xml.Name `xml:"arguments"`
+		Name      string   `xml:"name"`
+		Variables []struct {
+			Name  string `xml:"name"`
+			Value string `xml:"value"`
+		} `xml:"variables>variable"`
+	}
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return t.list()
+	}
+	s, err := Find(name, t.dirs...)
+	if err != nil {
+		return "", err
+	}
+	values := make(map[string]string, len(input.Variables))
+	for _, v := range input.Variables {
+		values[strings.TrimSpace(v.Name)] = v.Value
+	}
+	rendered, err := s.Render(values)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Snippet %s", s.Name)
+	if s.Description != "" {
+		fmt.Fprintf(&b, ": %s", s.Description)
+	}
+	b.WriteString("\n")
+	if rendered.Path != "" {
+		fmt.Fprintf(&b, "Suggested file: %s\n", rendered.Path)
+	}
+	fmt.Fprintf(&b, "\n```%s\n%s\n```\n\nUse the code as given, adapting only what the surrounding code requires.", s.Language, strings.TrimRight(rendered.Code, "\n"))
+	return b.String(), nil
+}
+
+// list describes the snippets and their variables
+func (t *GetSnippetTool) list() (string, error) {
+	snippets, err := List(t.dirs...)
+	if err != nil {
+		return "", err
+	}
+	if len(snippets) == 0 {
+		return fmt.Sprintf("There are no snippets. They are added as YAML files in %s.", Dir), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d snippets:\n", len(snippets))
+	for _, s := range snippets {
+		fmt.Fprintf(&b, "\n- %s", s.Name)
+		if s.Language != "" {
+			fmt.Fprintf(&b, " (%s)", s.Language)
+		}
+		if s.Description != "" {
+			fmt.Fprintf(&b, ": %s", s.Description)
+		}
+		if vars := describeVariables(s.Variables); vars != "" {
+			fmt.Fprintf(&b, "\n  variables: %s", vars)
+		}
+	}
+	return b.String(), nil
+}
+
+// describeVariables lists variables in name order, with their defaults or
+// as required
+func describeVariables(vars map[string]string) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if vars[name] == "" {
+			names[i] = name + " (required)"
+		} else {
+			names[i] = fmt.Sprintf("%s (default %q)", name, vars[name])
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *GetSnippetTool) IsLoopBreaking() bool {
+	return false
+}
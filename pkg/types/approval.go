@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // ApprovalDecision represents a user's decision on a tool approval request.
 type ApprovalDecision string
@@ -23,6 +26,10 @@ type ApprovalResponse struct {
 
 	// Timestamp when the decision was made
 	Timestamp time.Time
+
+	// Feedback is optional free-form text explaining the decision.
+	// It is typically supplied with a rejection so the agent can adjust its next proposal.
+	Feedback string
 }
 
 // NewApprovalResponse creates a new approval response.
@@ -34,6 +41,13 @@ func NewApprovalResponse(approvalID string, decision ApprovalDecision) *Approval
 	}
 }
 
+// NewApprovalResponseWithFeedback creates a new approval response carrying user feedback.
+func NewApprovalResponseWithFeedback(approvalID string, decision ApprovalDecision, feedback string) *ApprovalResponse {
+	resp := NewApprovalResponse(approvalID, decision)
+	resp.Feedback = feedback
+	return resp
+}
+
 // IsGranted returns true if the approval was granted.
 func (r *ApprovalResponse) IsGranted() bool {
 	return r.Decision == ApprovalGranted
@@ -43,3 +57,8 @@ func (r *ApprovalResponse) IsGranted() bool {
 func (r *ApprovalResponse) IsRejected() bool {
 	return r.Decision == ApprovalRejected
 }
+
+// HasFeedback returns true if the response carries non-empty feedback text.
+func (r *ApprovalResponse) HasFeedback() bool {
+	return strings.TrimSpace(r.Feedback) != ""
+}
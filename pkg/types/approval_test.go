@@ -83,3 +83,27 @@ func TestApprovalResponse_IsRejected(t *testing.T) {
 		})
 	}
 }
+
+func TestApprovalResponse_HasFeedback(t *testing.T) {
+	tests := []struct {
+		name     string
+		feedback string
+		want     bool
+	}{
+		{"empty", "", false},
+		{"whitespace only", "   \n", false},
+		{"with reason", "use the existing helper instead", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewApprovalResponseWithFeedback("test-789", ApprovalRejected, tt.feedback)
+			if resp.Feedback != tt.feedback {
+				t.Errorf("Feedback = %q, want %q", resp.Feedback, tt.feedback)
+			}
+			if got := resp.HasFeedback(); got != tt.want {
+				t.Errorf("HasFeedback() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
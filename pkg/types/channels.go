@@ -50,8 +50,35 @@ func (c *AgentChannels) Close() {
 	close(c.Done)
 }
 
+// SendEvent sends event on ch. A droppable event is sent only if ch has room,
+// so a stalled consumer can't block the sender; any other event waits for
+// room until done is closed. It reports whether the event was sent.
+func SendEvent(ch chan<- *AgentEvent, event *AgentEvent, done <-chan struct{}) bool {
+	if event.IsDroppable() {
+		select {
+		case ch <- event:
+			return true
+		default:
+			return false
+		}
+	}
+
+	select {
+	case ch <- event:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// SummarizationExecutionID skips a running context summarization when sent
+// in a CancellationRequest. The agent carries on with the conversation as it
+// was.
+const SummarizationExecutionID = "context_summarization"
+
 // CancellationRequest represents a request to cancel a running command.
 type CancellationRequest struct {
-	// ExecutionID is the unique identifier of the command execution to cancel.
+	// ExecutionID is the unique identifier of the command execution to cancel,
+	// or SummarizationExecutionID.
 	ExecutionID string
 }
@@ -2,6 +2,7 @@ package types
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNewAgentChannels(t *testing.T) {
@@ -120,3 +121,58 @@ func TestAgentChannelsDifferentBufferSizes(t *testing.T) {
 		})
 	}
 }
+
+func TestSendEvent_SlowConsumer(t *testing.T) {
+	events := make(chan *AgentEvent, 1)
+	done := make(chan struct{})
+	heartbeat := NewToolHeartbeatEvent("exec-1", "search_files", time.Second, time.Minute)
+
+	if !SendEvent(events, heartbeat, done) {
+		t.Fatal("SendEvent() should send while the channel has room")
+	}
+
+	// The consumer is stalled: progress is dropped without blocking
+	if SendEvent(events, NewToolProgressEvent("search_files", "searched 250 files"), done) {
+		t.Error("SendEvent() should drop a droppable event when the channel is full")
+	}
+
+	// A critical event waits until the consumer catches up
+	sent := make(chan bool)
+	go func() {
+		sent <- SendEvent(events, NewTurnEndEvent(), done)
+	}()
+	select {
+	case <-sent:
+		t.Fatal("SendEvent() should wait for room to send a critical event")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if got := <-events; got != heartbeat {
+		t.Errorf("received %v, want the heartbeat", got.Type)
+	}
+	if !<-sent {
+		t.Error("SendEvent() should send the critical event once there is room")
+	}
+	if got := <-events; got.Type != EventTypeTurnEnd {
+		t.Errorf("received %v, want turn_end", got.Type)
+	}
+}
+
+func TestSendEvent_GivesUpWhenDone(t *testing.T) {
+	events := make(chan *AgentEvent)
+	done := make(chan struct{})
+
+	sent := make(chan bool)
+	go func() {
+		sent <- SendEvent(events, NewTurnEndEvent(), done)
+	}()
+	close(done)
+
+	select {
+	case ok := <-sent:
+		if ok {
+			t.Error("SendEvent() should report the event was not sent")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendEvent() should stop waiting once done is closed")
+	}
+}
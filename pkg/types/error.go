@@ -1,17 +1,53 @@
 package types
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // ErrorCode defines specific error types that can occur in the agent framework.
 type ErrorCode string
 
 const (
-	ErrorCodeLLMFailure   ErrorCode = "llm_failure"   // ErrorCodeLLMFailure indicates an error occurred while calling the LLM provider.
-	ErrorCodeShutdown     ErrorCode = "shutdown"      // ErrorCodeShutdown indicates the agent is shutting down.
-	ErrorCodeInvalidInput ErrorCode = "invalid_input" // ErrorCodeInvalidInput indicates the input provided was invalid.
-	ErrorCodeTimeout      ErrorCode = "timeout"       // ErrorCodeTimeout indicates an operation timed out.
-	ErrorCodeCanceled     ErrorCode = "canceled"      // ErrorCodeCanceled indicates an operation was canceled.
-	ErrorCodeInternal     ErrorCode = "internal"      // ErrorCodeInternal indicates an internal error occurred.
+	ErrorCodeLLMFailure      ErrorCode = "llm_failure"      // ErrorCodeLLMFailure indicates an error occurred while calling the LLM provider.
+	ErrorCodeShutdown        ErrorCode = "shutdown"         // ErrorCodeShutdown indicates the agent is shutting down.
+	ErrorCodeInvalidInput    ErrorCode = "invalid_input"    // ErrorCodeInvalidInput indicates the input provided was invalid.
+	ErrorCodeTimeout         ErrorCode = "timeout"          // ErrorCodeTimeout indicates an operation timed out.
+	ErrorCodeCanceled        ErrorCode = "canceled"         // ErrorCodeCanceled indicates an operation was canceled.
+	ErrorCodeInternal        ErrorCode = "internal"         // ErrorCodeInternal indicates an internal error occurred.
+	ErrorCodeRateLimited     ErrorCode = "rate_limited"     // ErrorCodeRateLimited indicates the LLM provider rejected a request for exceeding a rate limit.
+	ErrorCodeAuth            ErrorCode = "auth_failure"     // ErrorCodeAuth indicates the LLM provider rejected the credentials.
+	ErrorCodeContextOverflow ErrorCode = "context_overflow" // ErrorCodeContextOverflow indicates a request didn't fit in the model's context window.
+	ErrorCodeInvalidResponse ErrorCode = "invalid_response" // ErrorCodeInvalidResponse indicates the model's reply had no usable tool call.
+	ErrorCodeToolFailure     ErrorCode = "tool_failure"     // ErrorCodeToolFailure indicates a tool failed or could not be run.
+)
+
+// Sentinel errors for failures callers commonly need to tell apart. Errors
+// returned by the framework wrap these, so check for them with errors.Is
+// rather than by matching messages.
+var (
+	// ErrPathOutsideWorkspace is returned when a path resolves outside the
+	// workspace the tools are confined to
+	ErrPathOutsideWorkspace = errors.New("outside workspace boundaries")
+
+	// ErrToolNotFound is returned when the model calls a tool that isn't registered
+	ErrToolNotFound = errors.New("unknown tool")
+
+	// ErrToolTimeout is returned when a tool runs past its timeout and is canceled
+	ErrToolTimeout = errors.New("tool execution timed out")
+
+	// ErrSearchNotFound is returned when an apply_diff search text is not in
+	// the file it edits
+	ErrSearchNotFound = errors.New("search text not found")
+
+	// ErrApprovalTimeout is set on approval timeout events when nobody
+	// answers an approval request in time
+	ErrApprovalTimeout = errors.New("approval request timed out")
+
+	// ErrContextOverflow is returned when a request doesn't fit in the
+	// model's context window
+	ErrContextOverflow = errors.New("context window exceeded")
 )
 
 // AgentError represents a structured error from the agent framework.
@@ -27,10 +63,17 @@ type AgentError struct {
 
 	// Code is the specific error code.
 	Code ErrorCode
+
+	// Retriable reports whether the same request may succeed if sent again.
+	Retriable bool
 }
 
-// Error implements the error interface.
+// Error implements the error interface. An AgentError without a message
+// reads as its cause.
 func (e *AgentError) Error() string {
+	if e.Message == "" && e.Cause != nil {
+		return e.Cause.Error()
+	}
 	if e.Cause != nil {
 		return fmt.Sprintf("%s: %s (caused by: %v)", e.Code, e.Message, e.Cause)
 	}
@@ -51,6 +94,40 @@ func (e *AgentError) WithMetadata(key string, value interface{}) *AgentError {
 	return e
 }
 
+// WithRetriable marks whether the failed request may succeed if retried and
+// returns the error for chaining.
+func (e *AgentError) WithRetriable(retriable bool) *AgentError {
+	e.Retriable = retriable
+	return e
+}
+
+// Guidance suggests what the user can do about the error, by its code, or
+// returns "" for errors the agent recovers from on its own. doctorCommand is
+// how the user runs the provider diagnostics in the current executor, e.g.
+// "forge doctor" or "/doctor". A nil error gets no guidance.
+func (e *AgentError) Guidance(doctorCommand string) string {
+	if e == nil {
+		return ""
+	}
+	if breaker, _ := e.Metadata["circuit_breaker"].(bool); breaker {
+		return "The agent stopped after repeated failures. Check the errors above, then rephrase or narrow the request."
+	}
+	switch e.Code {
+	case ErrorCodeRateLimited:
+		return "The provider is rate limiting requests. Wait a moment, then try again."
+	case ErrorCodeAuth:
+		return "The provider rejected the API key. Check OPENAI_API_KEY or -api-key, and run " + doctorCommand + " to test the connection."
+	case ErrorCodeContextOverflow:
+		return "The conversation no longer fits in the model's context window. Start a new session, or narrow the request."
+	case ErrorCodeLLMFailure:
+		if e.Retriable {
+			return "This is usually temporary. Try again."
+		}
+		return "Run " + doctorCommand + " to check the provider configuration."
+	}
+	return ""
+}
+
 // NewAgentError creates a new AgentError with the given code and message.
 func NewAgentError(code ErrorCode, message string) *AgentError {
 	return &AgentError{
@@ -70,15 +147,58 @@ func NewAgentErrorWithCause(code ErrorCode, message string, cause error) *AgentE
 	}
 }
 
-// IsAgentError checks if an error is an AgentError and returns it.
-func IsAgentError(err error) (*AgentError, bool) {
+// WrapError creates an AgentError with the given code around err. It has no
+// message of its own, so it reads exactly as err does.
+func WrapError(code ErrorCode, err error) *AgentError {
+	return &AgentError{
+		Code:     code,
+		Cause:    err,
+		Metadata: make(map[string]interface{}),
+	}
+}
+
+// ClassifyError returns err as an AgentError. If err is an AgentError it is
+// returned as is. If it wraps one, the result keeps err's message and takes
+// the wrapped error's code, metadata and retriable flag. Otherwise the code is
+// inferred from the sentinel errors err wraps, defaulting to
+// ErrorCodeInternal. It returns nil for a nil error.
+func ClassifyError(err error) *AgentError {
 	if err == nil {
-		return nil, false
+		return nil
 	}
 
-	if agentErr, ok := err.(*AgentError); ok {
-		return agentErr, true
+	if agentErr, ok := IsAgentError(err); ok {
+		if agentErr == err {
+			return agentErr
+		}
+		classified := WrapError(agentErr.Code, err).WithRetriable(agentErr.Retriable)
+		for k, v := range agentErr.Metadata {
+			classified.Metadata[k] = v
+		}
+		return classified
+	}
+
+	code := ErrorCodeInternal
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = ErrorCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrApprovalTimeout):
+		code = ErrorCodeTimeout
+	case errors.Is(err, ErrContextOverflow):
+		code = ErrorCodeContextOverflow
+	case errors.Is(err, ErrToolNotFound):
+		code = ErrorCodeInvalidResponse
+	case errors.Is(err, ErrPathOutsideWorkspace), errors.Is(err, ErrToolTimeout), errors.Is(err, ErrSearchNotFound):
+		code = ErrorCodeToolFailure
 	}
+	return WrapError(code, err)
+}
 
+// IsAgentError checks if an error is, or wraps, an AgentError and returns it.
+func IsAgentError(err error) (*AgentError, bool) {
+	var agentErr *AgentError
+	if errors.As(err, &agentErr) {
+		return agentErr, true
+	}
 	return nil, false
 }
@@ -1,7 +1,10 @@
 package types
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -138,6 +141,12 @@ func TestIsAgentError(t *testing.T) {
 			wantErr:   true,
 			wantAgent: true,
 		},
+		{
+			name:      "wrapped agent error",
+			err:       fmt.Errorf("turn failed: %w", NewAgentError(ErrorCodeInternal, "test")),
+			wantErr:   true,
+			wantAgent: true,
+		},
 		{
 			name:      "standard error",
 			err:       errors.New("standard error"),
@@ -164,3 +173,91 @@ func TestIsAgentError(t *testing.T) {
 		})
 	}
 }
+
+func TestSentinelErrors_ThroughAgentError(t *testing.T) {
+	cause := fmt.Errorf("path '../etc' is %w", ErrPathOutsideWorkspace)
+	err := NewAgentErrorWithCause(ErrorCodeInvalidInput, "read_file failed", cause)
+
+	if !errors.Is(err, ErrPathOutsideWorkspace) {
+		t.Error("expected the AgentError to match its cause's sentinel")
+	}
+	if errors.Is(err, ErrToolNotFound) {
+		t.Error("expected the AgentError not to match an unrelated sentinel")
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	cause := errors.New("API request failed with status 429")
+	err := WrapError(ErrorCodeRateLimited, cause).WithRetriable(true)
+
+	if err.Error() != cause.Error() {
+		t.Errorf("Error() = %q, want the cause's message %q", err.Error(), cause.Error())
+	}
+	if !err.Retriable || !errors.Is(err, cause) {
+		t.Errorf("expected a retriable error wrapping its cause, got %+v", err)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	rateLimited := WrapError(ErrorCodeRateLimited, errors.New("429")).WithRetriable(true).WithMetadata("status", 429)
+
+	tests := []struct {
+		name      string
+		err       error
+		code      ErrorCode
+		retriable bool
+	}{
+		{"agent error", rateLimited, ErrorCodeRateLimited, true},
+		{"wrapped agent error", fmt.Errorf("failed to start completion: %w", rateLimited), ErrorCodeRateLimited, true},
+		{"canceled", fmt.Errorf("stream read error: %w", context.Canceled), ErrorCodeCanceled, false},
+		{"context overflow", fmt.Errorf("%w: status 400", ErrContextOverflow), ErrorCodeContextOverflow, false},
+		{"unknown tool", fmt.Errorf("%w: frobnicate", ErrToolNotFound), ErrorCodeInvalidResponse, false},
+		{"tool timeout", fmt.Errorf("tool execution failed: %w", ErrToolTimeout), ErrorCodeToolFailure, false},
+		{"search not found", fmt.Errorf("edit 1: %w", ErrSearchNotFound), ErrorCodeToolFailure, false},
+		{"plain", errors.New("boom"), ErrorCodeInternal, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if got.Code != tt.code || got.Retriable != tt.retriable {
+				t.Errorf("ClassifyError() = %s (retriable %v), want %s (retriable %v)", got.Code, got.Retriable, tt.code, tt.retriable)
+			}
+			if got.Error() != tt.err.Error() {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.err.Error())
+			}
+			if !errors.Is(got, tt.err) {
+				t.Error("expected the classified error to wrap the original")
+			}
+		})
+	}
+
+	if ClassifyError(fmt.Errorf("x: %w", rateLimited)).Metadata["status"] != 429 {
+		t.Error("expected metadata to carry over from the wrapped AgentError")
+	}
+	if ClassifyError(nil) != nil {
+		t.Error("expected nil for a nil error")
+	}
+}
+
+func TestAgentErrorGuidance(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *AgentError
+		want string
+	}{
+		{"auth", NewAgentError(ErrorCodeAuth, "401"), "run /doctor to test the connection"},
+		{"retriable", NewAgentError(ErrorCodeLLMFailure, "502").WithRetriable(true), "usually temporary"},
+		{"provider", NewAgentError(ErrorCodeLLMFailure, "400"), "Run /doctor to check"},
+		{"circuit breaker", NewAgentError(ErrorCodeToolFailure, "failed").WithMetadata("circuit_breaker", true), "repeated failures"},
+		{"recoverable", NewAgentError(ErrorCodeInvalidResponse, "no tool call"), ""},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.err.Guidance("/doctor")
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("Guidance() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
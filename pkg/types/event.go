@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // AgentEventType defines the type of event emitted by the agent.
 type AgentEventType string
 
@@ -16,6 +18,8 @@ const (
 	EventTypeToolCall                     AgentEventType = "tool_call"                      // EventTypeToolCall indicates the agent is calling a tool.
 	EventTypeToolResult                   AgentEventType = "tool_result"                    // EventTypeToolResult indicates a successful tool call result.
 	EventTypeToolResultError              AgentEventType = "tool_result_error"              // EventTypeToolResultError indicates a tool call resulted in an error.
+	EventTypeToolHeartbeat                AgentEventType = "tool_heartbeat"                 // EventTypeToolHeartbeat indicates a tool is still running.
+	EventTypeToolProgress                 AgentEventType = "tool_progress"                  // EventTypeToolProgress carries a running tool's current phase description.
 	EventTypeNoToolCall                   AgentEventType = "no_tool_call"                   // EventTypeNoToolCall indicates the agent decided not to call any tools.
 	EventTypeApiCallStart                 AgentEventType = "api_call_start"                 // EventTypeApiCallStart indicates the agent is making an API call.
 	EventTypeApiCallEnd                   AgentEventType = "api_call_end"                   // EventTypeApiCallEnd indicates an API call has completed.
@@ -37,6 +41,11 @@ const (
 	EventTypeContextSummarizationProgress AgentEventType = "context_summarization_progress" // EventTypeContextSummarizationProgress indicates progress during context summarization.
 	EventTypeContextSummarizationComplete AgentEventType = "context_summarization_complete" // EventTypeContextSummarizationComplete indicates context summarization finished successfully.
 	EventTypeContextSummarizationError    AgentEventType = "context_summarization_error"    // EventTypeContextSummarizationError indicates an error occurred during context summarization.
+	EventTypeContextSummarizationSkipped  AgentEventType = "context_summarization_skipped"  // EventTypeContextSummarizationSkipped indicates the user skipped a running context summarization.
+	EventTypeInjectionWarning             AgentEventType = "injection_warning"              // EventTypeInjectionWarning indicates tool output contained suspected embedded instructions.
+	EventTypeBudgetExceeded               AgentEventType = "budget_exceeded"                // EventTypeBudgetExceeded indicates the turn was stopped because it exhausted a loop budget.
+	EventTypeRateLimitWait                AgentEventType = "rate_limit_wait"                // EventTypeRateLimitWait indicates a provider call is waiting for the rate limit to reset.
+	EventTypeRateLimitResume              AgentEventType = "rate_limit_resume"              // EventTypeRateLimitResume indicates a call that waited for the rate limit has been sent.
 )
 
 // AgentEvent represents an event emitted by the agent during execution.
@@ -83,6 +92,9 @@ type AgentEvent struct {
 
 	// ApiCallInfo contains API call information (for API call events).
 	ApiCallInfo *ApiCallInfo
+
+	// ToolExecution contains running tool information (for tool heartbeat events).
+	ToolExecution *ToolExecution
 }
 
 // TokenUsage contains token usage statistics from an LLM API call.
@@ -95,6 +107,13 @@ type TokenUsage struct {
 
 	// TotalTokens is the total number of tokens used (prompt + completion).
 	TotalTokens int
+
+	// CachedTokens is the number of prompt tokens served from the provider's
+	// prompt cache. Zero if the provider doesn't report it.
+	CachedTokens int
+
+	// Model is the model that served the call, if known.
+	Model string
 }
 
 // ContextSummarization contains information about context summarization.
@@ -160,6 +179,19 @@ type ApiCallInfo struct {
 	MaxContextTokens int
 }
 
+// ToolExecution contains information about a tool that is currently executing.
+type ToolExecution struct {
+	// ExecutionID identifies this tool run. Sending a CancellationRequest with
+	// this ID cancels the tool without stopping the rest of the turn.
+	ExecutionID string
+
+	// Elapsed is how long the tool has been running.
+	Elapsed time.Duration
+
+	// Timeout is the configured execution timeout (zero if none).
+	Timeout time.Duration
+}
+
 // NewThinkingStartEvent creates a thinking start event.
 func NewThinkingStartEvent() *AgentEvent {
 	return &AgentEvent{
@@ -265,6 +297,31 @@ func NewToolResultErrorEvent(toolName string, err error) *AgentEvent {
 	}
 }
 
+// NewToolHeartbeatEvent creates a tool heartbeat event for a tool that is still running.
+func NewToolHeartbeatEvent(executionID, toolName string, elapsed, timeout time.Duration) *AgentEvent {
+	return &AgentEvent{
+		Type:     EventTypeToolHeartbeat,
+		ToolName: toolName,
+		ToolExecution: &ToolExecution{
+			ExecutionID: executionID,
+			Elapsed:     elapsed,
+			Timeout:     timeout,
+		},
+		Metadata: make(map[string]interface{}),
+	}
+}
+
+// NewToolProgressEvent creates an event describing the current phase of a running
+// tool (e.g. "scanning 1204 files…").
+func NewToolProgressEvent(toolName, description string) *AgentEvent {
+	return &AgentEvent{
+		Type:     EventTypeToolProgress,
+		ToolName: toolName,
+		Content:  description,
+		Metadata: make(map[string]interface{}),
+	}
+}
+
 // NewNoToolCallEvent creates a no tool call event.
 func NewNoToolCallEvent() *AgentEvent {
 	return &AgentEvent{
@@ -318,7 +375,8 @@ func NewTurnEndEvent() *AgentEvent {
 	}
 }
 
-// NewErrorEvent creates an error event.
+// NewErrorEvent creates an error event. Wrap err with WrapError to give it a
+// code; otherwise the event's AgentError infers one.
 func NewErrorEvent(err error) *AgentEvent {
 	return &AgentEvent{
 		Type:     EventTypeError,
@@ -327,6 +385,12 @@ func NewErrorEvent(err error) *AgentEvent {
 	}
 }
 
+// AgentError returns the event's error classified by ClassifyError, with its
+// code, metadata and retriable flag, or nil if the event has no error.
+func (e *AgentEvent) AgentError() *AgentError {
+	return ClassifyError(e.Error)
+}
+
 // NewToolApprovalRequestEvent creates a tool approval request event.
 func NewToolApprovalRequestEvent(approvalID, toolName string, toolInput map[string]interface{}, preview interface{}) *AgentEvent {
 	return &AgentEvent{
@@ -339,12 +403,14 @@ func NewToolApprovalRequestEvent(approvalID, toolName string, toolInput map[stri
 	}
 }
 
-// NewToolApprovalTimeoutEvent creates a tool approval timeout event.
+// NewToolApprovalTimeoutEvent creates a tool approval timeout event. Its
+// Error is ErrApprovalTimeout.
 func NewToolApprovalTimeoutEvent(approvalID, toolName string) *AgentEvent {
 	return &AgentEvent{
 		Type:       EventTypeToolApprovalTimeout,
 		ApprovalID: approvalID,
 		ToolName:   toolName,
+		Error:      ErrApprovalTimeout,
 		Metadata:   make(map[string]interface{}),
 	}
 }
@@ -360,12 +426,17 @@ func NewToolApprovalGrantedEvent(approvalID, toolName string) *AgentEvent {
 }
 
 // NewToolApprovalRejectedEvent creates a tool approval rejected event.
-func NewToolApprovalRejectedEvent(approvalID, toolName string) *AgentEvent {
+// Any feedback supplied by the user is stored in Metadata["feedback"].
+func NewToolApprovalRejectedEvent(approvalID, toolName, feedback string) *AgentEvent {
+	metadata := make(map[string]interface{})
+	if feedback != "" {
+		metadata["feedback"] = feedback
+	}
 	return &AgentEvent{
 		Type:       EventTypeToolApprovalRejected,
 		ApprovalID: approvalID,
 		ToolName:   toolName,
-		Metadata:   make(map[string]interface{}),
+		Metadata:   metadata,
 	}
 }
 
@@ -448,6 +519,59 @@ func NewCommandExecutionCanceledEvent(executionID string, duration string) *Agen
 	}
 }
 
+// NewInjectionWarningEvent creates an event warning that a tool's output contained
+// text that looks like instructions to the agent (possible prompt injection).
+func NewInjectionWarningEvent(toolName string, rules, excerpts []string) *AgentEvent {
+	return &AgentEvent{
+		Type:     EventTypeInjectionWarning,
+		ToolName: toolName,
+		Metadata: map[string]interface{}{
+			"rules":    rules,
+			"excerpts": excerpts,
+		},
+	}
+}
+
+// NewBudgetExceededEvent creates an event reporting that the current turn was
+// stopped after exhausting one of its loop budgets. limit names the budget
+// ("iterations", "tool_calls" or "duration"); used and max describe its usage.
+func NewBudgetExceededEvent(limit, used, max string) *AgentEvent {
+	return &AgentEvent{
+		Type: EventTypeBudgetExceeded,
+		Metadata: map[string]interface{}{
+			"limit": limit,
+			"used":  used,
+			"max":   max,
+		},
+	}
+}
+
+// NewRateLimitWaitEvent creates an event reporting that a provider call is
+// held back for wait because of the provider's rate limit. reason is one of
+// the RateLimitReason constants; background is true for deferrable calls
+// such as summarization.
+func NewRateLimitWaitEvent(wait time.Duration, reason string, background bool) *AgentEvent {
+	return &AgentEvent{
+		Type: EventTypeRateLimitWait,
+		Metadata: map[string]interface{}{
+			"wait":       wait,
+			"reason":     reason,
+			"background": background,
+		},
+	}
+}
+
+// NewRateLimitResumeEvent creates an event reporting that a call announced
+// by a rate limit wait event has been sent.
+func NewRateLimitResumeEvent(background bool) *AgentEvent {
+	return &AgentEvent{
+		Type: EventTypeRateLimitResume,
+		Metadata: map[string]interface{}{
+			"background": background,
+		},
+	}
+}
+
 // WithMetadata adds metadata to the event and returns the event for chaining.
 func (e *AgentEvent) WithMetadata(key string, value interface{}) *AgentEvent {
 	if e.Metadata == nil {
@@ -476,6 +600,7 @@ func (e *AgentEvent) IsToolEvent() bool {
 	return e.Type == EventTypeToolCall ||
 		e.Type == EventTypeToolResult ||
 		e.Type == EventTypeToolResultError ||
+		e.Type == EventTypeToolHeartbeat ||
 		e.Type == EventTypeNoToolCall
 }
 
@@ -485,6 +610,16 @@ func (e *AgentEvent) IsApiEvent() bool {
 		e.Type == EventTypeApiCallEnd
 }
 
+// IsDroppable returns true if this event only reports progress that a later
+// event supersedes, such as a heartbeat. Droppable events are dropped when
+// the consumer falls behind instead of holding up the agent; all others are
+// delivered.
+func (e *AgentEvent) IsDroppable() bool {
+	return e.Type == EventTypeToolHeartbeat ||
+		e.Type == EventTypeToolProgress ||
+		e.Type == EventTypeContextSummarizationProgress
+}
+
 // IsContentEvent returns true if this event contains text content.
 func (e *AgentEvent) IsContentEvent() bool {
 	return e.Type == EventTypeThinkingContent ||
@@ -568,10 +703,23 @@ func NewContextSummarizationErrorEvent(strategy string, err error) *AgentEvent {
 	}
 }
 
+// NewContextSummarizationSkippedEvent creates an event reporting that a
+// running context summarization was skipped and the conversation left as it was.
+func NewContextSummarizationSkippedEvent(strategy string) *AgentEvent {
+	return &AgentEvent{
+		Type: EventTypeContextSummarizationSkipped,
+		ContextSummarization: &ContextSummarization{
+			Strategy: strategy,
+		},
+		Metadata: make(map[string]interface{}),
+	}
+}
+
 // IsContextSummarizationEvent returns true if this is any context summarization-related event.
 func (e *AgentEvent) IsContextSummarizationEvent() bool {
 	return e.Type == EventTypeContextSummarizationStart ||
 		e.Type == EventTypeContextSummarizationProgress ||
 		e.Type == EventTypeContextSummarizationComplete ||
-		e.Type == EventTypeContextSummarizationError
+		e.Type == EventTypeContextSummarizationError ||
+		e.Type == EventTypeContextSummarizationSkipped
 }
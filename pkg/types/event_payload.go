@@ -0,0 +1,318 @@
+package types
+
+import "time"
+
+// EventKind pairs an event type with the typed payload its events carry, so
+// consumers can read an event without checking which of AgentEvent's
+// optional fields it fills in. Use it with Subscribe, or call Payload on
+// events read from the channel.
+type EventKind[P any] struct {
+	Type    AgentEventType
+	payload func(*AgentEvent) P
+}
+
+// Payload returns the payload of event, which must be of this kind's type
+func (k EventKind[P]) Payload(event *AgentEvent) P {
+	return k.payload(event)
+}
+
+// Marker is the payload of events that carry nothing beyond their type,
+// such as EventTurnEnd.
+type Marker struct{}
+
+// TextPayload is streamed thinking, message or tool call text.
+type TextPayload struct {
+	Text string
+}
+
+// ToolCallPayload describes a tool the agent is calling.
+type ToolCallPayload struct {
+	ToolName string
+	Input    map[string]interface{}
+}
+
+// ToolResultPayload is a tool's successful result.
+type ToolResultPayload struct {
+	ToolName string
+	Output   interface{}
+}
+
+// ToolErrorPayload is the error a tool call failed with.
+type ToolErrorPayload struct {
+	ToolName string
+	Err      error
+}
+
+// ToolHeartbeatPayload reports a tool that is still running.
+type ToolHeartbeatPayload struct {
+	ToolName    string
+	ExecutionID string // Cancels the tool when sent in a CancellationRequest
+	Elapsed     time.Duration
+	Timeout     time.Duration // Zero if the tool has none
+}
+
+// ToolProgressPayload describes a running tool's current phase.
+type ToolProgressPayload struct {
+	ToolName    string
+	Description string
+}
+
+// APICallPayload describes a call to the LLM provider. The token counts are
+// only set when the call starts.
+type APICallPayload struct {
+	APIName          string
+	ContextTokens    int
+	MaxContextTokens int
+}
+
+// ToolsUpdatePayload lists the agent's available tools.
+type ToolsUpdatePayload struct {
+	Tools []string
+}
+
+// BusyPayload reports whether the agent is working on a turn.
+type BusyPayload struct {
+	Busy bool
+}
+
+// ErrorPayload is an error that occurred during agent processing. Code,
+// Retriable and Metadata classify it, as ClassifyError does.
+type ErrorPayload struct {
+	Err       error
+	Code      ErrorCode
+	Retriable bool
+	Metadata  map[string]interface{}
+}
+
+// ApprovalRequestPayload asks for approval to run a tool. The decision is
+// sent on the Approval channel with ApprovalID.
+type ApprovalRequestPayload struct {
+	ApprovalID string
+	ToolName   string
+	Input      map[string]interface{}
+	Preview    interface{}
+}
+
+// ApprovalPayload reports the outcome of an approval request. Feedback is
+// only set when the user rejected the tool call with a reason.
+type ApprovalPayload struct {
+	ApprovalID string
+	ToolName   string
+	Feedback   string
+}
+
+// CommandPayload describes a command run by execute_command. Err is only set
+// when the command failed.
+type CommandPayload struct {
+	CommandExecution
+	Err error
+}
+
+// InjectionWarningPayload reports tool output that looked like instructions
+// to the agent.
+type InjectionWarningPayload struct {
+	ToolName string
+	Rules    []string
+	Excerpts []string
+}
+
+// BudgetExceededPayload reports which loop budget stopped the turn.
+type BudgetExceededPayload struct {
+	Limit string // "iterations", "tool_calls" or "duration"
+	Used  string
+	Max   string
+}
+
+// Reasons a provider call waits for the rate limit
+const (
+	RateLimitReasonRetryAfter = "retry_after" // The provider answered 429 and said when to retry
+	RateLimitReasonRequests   = "requests"    // The request limit is used up until it resets
+	RateLimitReasonTokens     = "tokens"      // The token limit is used up until it resets
+)
+
+// RateLimitPayload reports a provider call held back by the rate limit.
+// Wait and Reason are only set on EventRateLimitWait.
+type RateLimitPayload struct {
+	Wait       time.Duration
+	Reason     string // One of the RateLimitReason constants
+	Background bool   // A deferrable call, such as summarization
+}
+
+// Event kinds, one for each event type.
+var (
+	EventThinkingStart                = markerKind(EventTypeThinkingStart)
+	EventThinkingContent              = textKind(EventTypeThinkingContent)
+	EventThinkingEnd                  = markerKind(EventTypeThinkingEnd)
+	EventToolCallStart                = markerKind(EventTypeToolCallStart)
+	EventToolCallContent              = textKind(EventTypeToolCallContent)
+	EventToolCallEnd                  = markerKind(EventTypeToolCallEnd)
+	EventMessageStart                 = markerKind(EventTypeMessageStart)
+	EventMessageContent               = textKind(EventTypeMessageContent)
+	EventMessageEnd                   = markerKind(EventTypeMessageEnd)
+	EventToolCall                     = EventKind[ToolCallPayload]{EventTypeToolCall, toolCallPayload}
+	EventToolResult                   = EventKind[ToolResultPayload]{EventTypeToolResult, toolResultPayload}
+	EventToolResultError              = EventKind[ToolErrorPayload]{EventTypeToolResultError, toolErrorPayload}
+	EventToolHeartbeat                = EventKind[ToolHeartbeatPayload]{EventTypeToolHeartbeat, toolHeartbeatPayload}
+	EventToolProgress                 = EventKind[ToolProgressPayload]{EventTypeToolProgress, toolProgressPayload}
+	EventNoToolCall                   = markerKind(EventTypeNoToolCall)
+	EventAPICallStart                 = EventKind[APICallPayload]{EventTypeApiCallStart, apiCallPayload}
+	EventAPICallEnd                   = EventKind[APICallPayload]{EventTypeApiCallEnd, apiCallPayload}
+	EventToolsUpdate                  = EventKind[ToolsUpdatePayload]{EventTypeToolsUpdate, toolsUpdatePayload}
+	EventUpdateBusy                   = EventKind[BusyPayload]{EventTypeUpdateBusy, busyPayload}
+	EventTurnEnd                      = markerKind(EventTypeTurnEnd)
+	EventError                        = EventKind[ErrorPayload]{EventTypeError, errorPayload}
+	EventToolApprovalRequest          = EventKind[ApprovalRequestPayload]{EventTypeToolApprovalRequest, approvalRequestPayload}
+	EventToolApprovalTimeout          = approvalKind(EventTypeToolApprovalTimeout)
+	EventToolApprovalGranted          = approvalKind(EventTypeToolApprovalGranted)
+	EventToolApprovalRejected         = approvalKind(EventTypeToolApprovalRejected)
+	EventTokenUsage                   = EventKind[TokenUsage]{EventTypeTokenUsage, tokenUsagePayload}
+	EventCommandExecutionStart        = commandKind(EventTypeCommandExecutionStart)
+	EventCommandOutput                = commandKind(EventTypeCommandOutput)
+	EventCommandExecutionComplete     = commandKind(EventTypeCommandExecutionComplete)
+	EventCommandExecutionFailed       = commandKind(EventTypeCommandExecutionFailed)
+	EventCommandExecutionCanceled     = commandKind(EventTypeCommandExecutionCanceled)
+	EventContextSummarizationStart    = summarizationKind(EventTypeContextSummarizationStart)
+	EventContextSummarizationProgress = summarizationKind(EventTypeContextSummarizationProgress)
+	EventContextSummarizationComplete = summarizationKind(EventTypeContextSummarizationComplete)
+	EventContextSummarizationError    = summarizationKind(EventTypeContextSummarizationError)
+	EventContextSummarizationSkipped  = summarizationKind(EventTypeContextSummarizationSkipped)
+	EventInjectionWarning             = EventKind[InjectionWarningPayload]{EventTypeInjectionWarning, injectionWarningPayload}
+	EventBudgetExceeded               = EventKind[BudgetExceededPayload]{EventTypeBudgetExceeded, budgetExceededPayload}
+	EventRateLimitWait                = EventKind[RateLimitPayload]{EventTypeRateLimitWait, rateLimitPayload}
+	EventRateLimitResume              = EventKind[RateLimitPayload]{EventTypeRateLimitResume, rateLimitPayload}
+)
+
+func markerKind(t AgentEventType) EventKind[Marker] {
+	return EventKind[Marker]{t, func(*AgentEvent) Marker { return Marker{} }}
+}
+
+func textKind(t AgentEventType) EventKind[TextPayload] {
+	return EventKind[TextPayload]{t, func(e *AgentEvent) TextPayload { return TextPayload{Text: e.Content} }}
+}
+
+func approvalKind(t AgentEventType) EventKind[ApprovalPayload] {
+	return EventKind[ApprovalPayload]{t, approvalPayload}
+}
+
+func commandKind(t AgentEventType) EventKind[CommandPayload] {
+	return EventKind[CommandPayload]{t, commandPayload}
+}
+
+func summarizationKind(t AgentEventType) EventKind[ContextSummarization] {
+	return EventKind[ContextSummarization]{t, summarizationPayload}
+}
+
+func toolCallPayload(e *AgentEvent) ToolCallPayload {
+	return ToolCallPayload{ToolName: e.ToolName, Input: e.ToolInput}
+}
+
+func toolResultPayload(e *AgentEvent) ToolResultPayload {
+	return ToolResultPayload{ToolName: e.ToolName, Output: e.ToolOutput}
+}
+
+func toolErrorPayload(e *AgentEvent) ToolErrorPayload {
+	return ToolErrorPayload{ToolName: e.ToolName, Err: e.Error}
+}
+
+func toolHeartbeatPayload(e *AgentEvent) ToolHeartbeatPayload {
+	p := ToolHeartbeatPayload{ToolName: e.ToolName}
+	if e.ToolExecution != nil {
+		p.ExecutionID = e.ToolExecution.ExecutionID
+		p.Elapsed = e.ToolExecution.Elapsed
+		p.Timeout = e.ToolExecution.Timeout
+	}
+	return p
+}
+
+func toolProgressPayload(e *AgentEvent) ToolProgressPayload {
+	return ToolProgressPayload{ToolName: e.ToolName, Description: e.Content}
+}
+
+func apiCallPayload(e *AgentEvent) APICallPayload {
+	p := APICallPayload{}
+	p.APIName, _ = e.Metadata["api_name"].(string)
+	if e.ApiCallInfo != nil {
+		p.ContextTokens = e.ApiCallInfo.ContextTokens
+		p.MaxContextTokens = e.ApiCallInfo.MaxContextTokens
+	}
+	return p
+}
+
+func toolsUpdatePayload(e *AgentEvent) ToolsUpdatePayload {
+	tools, _ := e.Metadata["tools"].([]string)
+	return ToolsUpdatePayload{Tools: tools}
+}
+
+func busyPayload(e *AgentEvent) BusyPayload {
+	return BusyPayload{Busy: e.IsBusy}
+}
+
+func errorPayload(e *AgentEvent) ErrorPayload {
+	p := ErrorPayload{Err: e.Error}
+	if agentErr := e.AgentError(); agentErr != nil {
+		p.Code = agentErr.Code
+		p.Retriable = agentErr.Retriable
+		p.Metadata = agentErr.Metadata
+	}
+	return p
+}
+
+func approvalRequestPayload(e *AgentEvent) ApprovalRequestPayload {
+	return ApprovalRequestPayload{
+		ApprovalID: e.ApprovalID,
+		ToolName:   e.ToolName,
+		Input:      e.ToolInput,
+		Preview:    e.Preview,
+	}
+}
+
+func approvalPayload(e *AgentEvent) ApprovalPayload {
+	p := ApprovalPayload{ApprovalID: e.ApprovalID, ToolName: e.ToolName}
+	p.Feedback, _ = e.Metadata["feedback"].(string)
+	return p
+}
+
+func tokenUsagePayload(e *AgentEvent) TokenUsage {
+	if e.TokenUsage == nil {
+		return TokenUsage{}
+	}
+	return *e.TokenUsage
+}
+
+func commandPayload(e *AgentEvent) CommandPayload {
+	p := CommandPayload{Err: e.Error}
+	if e.CommandExecution != nil {
+		p.CommandExecution = *e.CommandExecution
+	}
+	return p
+}
+
+func summarizationPayload(e *AgentEvent) ContextSummarization {
+	if e.ContextSummarization == nil {
+		return ContextSummarization{}
+	}
+	return *e.ContextSummarization
+}
+
+func injectionWarningPayload(e *AgentEvent) InjectionWarningPayload {
+	p := InjectionWarningPayload{ToolName: e.ToolName}
+	p.Rules, _ = e.Metadata["rules"].([]string)
+	p.Excerpts, _ = e.Metadata["excerpts"].([]string)
+	return p
+}
+
+func budgetExceededPayload(e *AgentEvent) BudgetExceededPayload {
+	p := BudgetExceededPayload{}
+	p.Limit, _ = e.Metadata["limit"].(string)
+	p.Used, _ = e.Metadata["used"].(string)
+	p.Max, _ = e.Metadata["max"].(string)
+	return p
+}
+
+func rateLimitPayload(e *AgentEvent) RateLimitPayload {
+	p := RateLimitPayload{}
+	p.Wait, _ = e.Metadata["wait"].(time.Duration)
+	p.Reason, _ = e.Metadata["reason"].(string)
+	p.Background, _ = e.Metadata["background"].(bool)
+	return p
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestAgentEventType(t *testing.T) {
@@ -312,6 +313,16 @@ func TestAgentEventHelpers(t *testing.T) {
 			isContent:  false,
 			isError:    false,
 		},
+		{
+			name:       "tool_heartbeat",
+			event:      NewToolHeartbeatEvent("exec-1", "test", time.Second, time.Minute),
+			isThinking: false,
+			isMessage:  false,
+			isTool:     true,
+			isApi:      false,
+			isContent:  false,
+			isError:    false,
+		},
 		{
 			name:       "api_call_start",
 			event:      NewApiCallStartEvent("test", 1000, 2000),
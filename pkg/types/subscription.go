@@ -0,0 +1,70 @@
+package types
+
+import "sync"
+
+// Subscriptions dispatches events to handlers registered for their type,
+// alongside the Event channel. The zero value has no subscribers and is
+// ready to use.
+type Subscriptions struct {
+	mu       sync.RWMutex
+	handlers map[AgentEventType][]subscriber
+	nextID   int
+}
+
+type subscriber struct {
+	id     int
+	handle func(*AgentEvent)
+}
+
+// Subscribe registers handler to receive the payload of every event of
+// kind's type that s publishes. Handlers run on the publishing goroutine,
+// in the order they subscribed, so they must return quickly and must not
+// wait on the agent. Events published from several goroutines, such as tool
+// heartbeats, may reach a handler concurrently. The returned function
+// removes the subscription; it is safe to call more than once.
+func Subscribe[P any](s *Subscriptions, kind EventKind[P], handler func(P)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.handlers == nil {
+		s.handlers = make(map[AgentEventType][]subscriber)
+	}
+	s.nextID++
+	id := s.nextID
+	s.handlers[kind.Type] = append(s.handlers[kind.Type], subscriber{
+		id:     id,
+		handle: func(e *AgentEvent) { handler(kind.Payload(e)) },
+	})
+
+	return func() { s.remove(kind.Type, id) }
+}
+
+// remove drops subscription id from eventType's handlers
+func (s *Subscriptions) remove(eventType AgentEventType, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := s.handlers[eventType]
+	for i, sub := range subs {
+		if sub.id == id {
+			// Copy rather than splice, since Publish may be iterating subs.
+			rest := make([]subscriber, 0, len(subs)-1)
+			rest = append(rest, subs[:i]...)
+			s.handlers[eventType] = append(rest, subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish calls the handlers subscribed to event's type. Handlers may
+// subscribe and unsubscribe while it runs; the changes apply from the next
+// event.
+func (s *Subscriptions) Publish(event *AgentEvent) {
+	s.mu.RLock()
+	subs := s.handlers[event.Type]
+	s.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.handle(event)
+	}
+}
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscribe_TypedPayload(t *testing.T) {
+	var s Subscriptions
+	var calls []ToolCallPayload
+	Subscribe(&s, EventToolCall, func(p ToolCallPayload) {
+		calls = append(calls, p)
+	})
+
+	input := map[string]interface{}{"path": "main.go"}
+	s.Publish(NewToolCallEvent("read_file", input))
+	s.Publish(NewMessageContentEvent("not a tool call"))
+
+	want := []ToolCallPayload{{ToolName: "read_file", Input: input}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+}
+
+func TestSubscribe_Unsubscribe(t *testing.T) {
+	var s Subscriptions
+	var first, second int
+	unsubscribe := Subscribe(&s, EventTurnEnd, func(Marker) { first++ })
+	Subscribe(&s, EventTurnEnd, func(Marker) { second++ })
+
+	s.Publish(NewTurnEndEvent())
+	unsubscribe()
+	unsubscribe()
+	s.Publish(NewTurnEndEvent())
+
+	if first != 1 || second != 2 {
+		t.Errorf("first = %d, second = %d; want 1 and 2", first, second)
+	}
+}
+
+func TestSubscribe_UnsubscribeDuringPublish(t *testing.T) {
+	var s Subscriptions
+	var calls int
+	var unsubscribe func()
+	unsubscribe = Subscribe(&s, EventTurnEnd, func(Marker) {
+		calls++
+		unsubscribe()
+	})
+	Subscribe(&s, EventTurnEnd, func(Marker) { calls++ })
+
+	s.Publish(NewTurnEndEvent())
+	s.Publish(NewTurnEndEvent())
+
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestEventKindPayload(t *testing.T) {
+	failure := errors.New("exit status 1")
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{
+			name: "tool error",
+			got:  EventToolResultError.Payload(NewToolResultErrorEvent("execute_command", failure)),
+			want: ToolErrorPayload{ToolName: "execute_command", Err: failure},
+		},
+		{
+			name: "error",
+			got:  EventError.Payload(NewErrorEvent(WrapError(ErrorCodeAuth, failure).WithMetadata("status", 401))),
+			want: ErrorPayload{
+				Err:      WrapError(ErrorCodeAuth, failure).WithMetadata("status", 401),
+				Code:     ErrorCodeAuth,
+				Metadata: map[string]interface{}{"status": 401},
+			},
+		},
+		{
+			name: "api call",
+			got:  EventAPICallStart.Payload(NewApiCallStartEvent("openai", 100, 1000)),
+			want: APICallPayload{APIName: "openai", ContextTokens: 100, MaxContextTokens: 1000},
+		},
+		{
+			name: "rejection feedback",
+			got:  EventToolApprovalRejected.Payload(NewToolApprovalRejectedEvent("a1", "write_file", "wrong file")),
+			want: ApprovalPayload{ApprovalID: "a1", ToolName: "write_file", Feedback: "wrong file"},
+		},
+		{
+			name: "heartbeat",
+			got:  EventToolHeartbeat.Payload(NewToolHeartbeatEvent("exec-1", "run_tests", time.Second, time.Minute)),
+			want: ToolHeartbeatPayload{ToolName: "run_tests", ExecutionID: "exec-1", Elapsed: time.Second, Timeout: time.Minute},
+		},
+		{
+			name: "budget",
+			got:  EventBudgetExceeded.Payload(NewBudgetExceededEvent("iterations", "50", "50")),
+			want: BudgetExceededPayload{Limit: "iterations", Used: "50", Max: "50"},
+		},
+		{
+			name: "rate limit wait",
+			got:  EventRateLimitWait.Payload(NewRateLimitWaitEvent(12*time.Second, "requests", true)),
+			want: RateLimitPayload{Wait: 12 * time.Second, Reason: "requests", Background: true},
+		},
+		{
+			name: "missing token usage",
+			got:  EventTokenUsage.Payload(&AgentEvent{Type: EventTypeTokenUsage}),
+			want: TokenUsage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("payload = %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+}
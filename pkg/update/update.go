@@ -0,0 +1,369 @@
+// Package update implements forge's self-update: it finds the newest GitHub
+// release on a channel, verifies the release checksums and their signature
+// and replaces the running binary.
+//
+// Releases are expected to carry one binary per platform named by
+// BinaryAssetName, a sha256sum-format ChecksumsAsset covering them, and
+// SignatureAsset: a base64 Ed25519 signature of the checksums file. An
+// updater without a public key refuses to install anything unless told to
+// trust the checksums alone with WithoutSignature.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+const (
+	// DefaultRepository is the GitHub repository releases are fetched from
+	DefaultRepository = "entrhq/forge"
+
+	// DefaultAPIURL is the GitHub REST API endpoint
+	DefaultAPIURL = "https://api.github.com"
+
+	// ChecksumsAsset lists the sha256 checksum of every release binary
+	ChecksumsAsset = "checksums.txt"
+
+	// SignatureAsset is the base64 Ed25519 signature of ChecksumsAsset
+	SignatureAsset = "checksums.txt.sig"
+
+	// maxBinarySize bounds downloads so a bad asset can't fill the disk
+	maxBinarySize = 512 << 20
+)
+
+var (
+	// ErrNoRelease is returned when the channel has no published release
+	ErrNoRelease = errors.New("no release found")
+
+	// ErrChecksumMismatch is returned when a download doesn't match its checksum
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrBadSignature is returned when the checksums signature is missing or invalid
+	ErrBadSignature = errors.New("invalid checksums signature")
+
+	// ErrNoPublicKey is returned when there is no key to check the signature
+	// with and unsigned releases were not allowed
+	ErrNoPublicKey = errors.New("no release public key to verify the release with")
+)
+
+// Release is a published GitHub release
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	HTMLURL    string  `json:"html_url"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// asset returns the named asset, if the release has it
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Updater checks for and installs forge releases
+type Updater struct {
+	repository string
+	apiURL     string
+	client     *http.Client
+	publicKey  ed25519.PublicKey
+	unsigned   bool // Install on checksums alone when there is no public key
+	goos       string
+	goarch     string
+}
+
+// Option configures an Updater
+type Option func(*Updater)
+
+// WithRepository sets the "owner/name" GitHub repository to update from
+func WithRepository(repository string) Option {
+	return func(u *Updater) {
+		u.repository = repository
+	}
+}
+
+// WithAPIURL sets the GitHub API endpoint, e.g. for GitHub Enterprise
+func WithAPIURL(apiURL string) Option {
+	return func(u *Updater) {
+		u.apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for API calls and downloads
+func WithHTTPClient(client *http.Client) Option {
+	return func(u *Updater) {
+		u.client = client
+	}
+}
+
+// WithPublicKey requires a valid SignatureAsset signed by key before
+// installing a release
+func WithPublicKey(key ed25519.PublicKey) Option {
+	return func(u *Updater) {
+		u.publicKey = key
+	}
+}
+
+// WithoutSignature lets an updater without a public key install releases
+// verified by their checksums alone. The checksums come from the same
+// release as the binary, so they catch corrupt downloads but not a
+// tampered release.
+func WithoutSignature() Option {
+	return func(u *Updater) {
+		u.unsigned = true
+	}
+}
+
+// WithPlatform overrides the target operating system and architecture
+func WithPlatform(goos, goarch string) Option {
+	return func(u *Updater) {
+		u.goos = goos
+		u.goarch = goarch
+	}
+}
+
+// NewUpdater creates an updater for the current platform
+func NewUpdater(opts ...Option) *Updater {
+	u := &Updater{
+		repository: DefaultRepository,
+		apiURL:     DefaultAPIURL,
+		client:     &http.Client{Timeout: 5 * time.Minute},
+		goos:       runtime.GOOS,
+		goarch:     runtime.GOARCH,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// BinaryAssetName returns the release asset name for a platform
+func BinaryAssetName(goos, goarch string) string {
+	name := fmt.Sprintf("forge_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest returns the newest non-draft release on channel. The stable channel
+// skips pre-releases; the beta channel includes them.
+func (u *Updater) Latest(ctx context.Context, channel string) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=50", u.apiURL, u.repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch releases: GitHub returned HTTP %d", resp.StatusCode)
+	}
+
+	var releases []*Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to decode releases: %w", err)
+	}
+
+	var latest *Release
+	for _, r := range releases {
+		if r.Draft || (r.Prerelease && channel != config.ChannelBeta) {
+			continue
+		}
+		if latest == nil || CompareVersions(r.TagName, latest.TagName) > 0 {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w on the %s channel of %s", ErrNoRelease, channel, u.repository)
+	}
+	return latest, nil
+}
+
+// Apply downloads release's binary for this platform, verifies it and
+// replaces the file at executable with it
+func (u *Updater) Apply(ctx context.Context, release *Release, executable string) error {
+	assetName := BinaryAssetName(u.goos, u.goarch)
+	binary, ok := release.asset(assetName)
+	if !ok {
+		return fmt.Errorf("release %s has no build for %s/%s (expected asset %s)", release.TagName, u.goos, u.goarch, assetName)
+	}
+
+	checksums, err := u.verifiedChecksums(ctx, release)
+	if err != nil {
+		return err
+	}
+	want, ok := checksums[assetName]
+	if !ok {
+		return fmt.Errorf("%s does not list %s", ChecksumsAsset, assetName)
+	}
+
+	// Download next to the executable so the final rename stays on one filesystem
+	tempFile, err := os.CreateTemp(filepath.Dir(executable), ".forge-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file (is %s writable?): %w", filepath.Dir(executable), err)
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath) // No-op once renamed into place
+
+	hash := sha256.New()
+	err = u.download(ctx, binary.URL, io.MultiWriter(tempFile, hash), maxBinarySize)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", assetName, err)
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, assetName, want, got)
+	}
+
+	if err := os.Chmod(tempPath, 0755); err != nil {
+		return fmt.Errorf("failed to make binary executable: %w", err)
+	}
+	return replaceExecutable(executable, tempPath)
+}
+
+// verifiedChecksums downloads the checksums file, checks its signature unless
+// unsigned releases are allowed, and parses it into asset name → sha256 hex
+func (u *Updater) verifiedChecksums(ctx context.Context, release *Release) (map[string]string, error) {
+	if u.publicKey == nil && !u.unsigned {
+		return nil, ErrNoPublicKey
+	}
+
+	asset, ok := release.asset(ChecksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.TagName, ChecksumsAsset)
+	}
+
+	var checksums bytes.Buffer
+	if err := u.download(ctx, asset.URL, &checksums, 1<<20); err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+
+	if u.publicKey != nil {
+		sigAsset, ok := release.asset(SignatureAsset)
+		if !ok {
+			return nil, fmt.Errorf("%w: release %s has no %s", ErrBadSignature, release.TagName, SignatureAsset)
+		}
+		var encoded bytes.Buffer
+		if err := u.download(ctx, sigAsset.URL, &encoded, 4096); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", SignatureAsset, err)
+		}
+		signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded.String()))
+		if err != nil || !ed25519.Verify(u.publicKey, checksums.Bytes(), signature) {
+			return nil, fmt.Errorf("%w for release %s", ErrBadSignature, release.TagName)
+		}
+	}
+
+	return parseChecksums(checksums.Bytes()), nil
+}
+
+// parseChecksums parses sha256sum output ("<hex>  <name>" per line)
+func parseChecksums(data []byte) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// download writes the body at url to w, failing if it exceeds limit bytes
+func (u *Updater) download(ctx context.Context, url string, w io.Writer, limit int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("larger than %d bytes", limit)
+	}
+	return nil
+}
+
+// replaceExecutable moves newPath over executable. The old binary is moved
+// aside first, which also works for a running executable on Windows, and is
+// restored if the swap fails.
+func replaceExecutable(executable, newPath string) error {
+	oldPath := executable + ".old"
+	os.Remove(oldPath) // Left over from a previous update on Windows
+
+	if err := os.Rename(executable, oldPath); err != nil {
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(newPath, executable); err != nil {
+		if restoreErr := os.Rename(oldPath, executable); restoreErr != nil {
+			return fmt.Errorf("failed to install new binary: %w (restoring the old binary also failed: %v; it is at %s)", err, restoreErr, oldPath)
+		}
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	os.Remove(oldPath) // Fails harmlessly on Windows while the old binary runs
+	return nil
+}
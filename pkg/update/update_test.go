@@ -0,0 +1,230 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"v1.10.0", "v1.9.9", 1},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.3.0-beta.1", "v1.3.0", -1},
+		{"v1.3.0-beta.2", "v1.3.0-beta.10", -1},
+		{"v1.3.0-beta", "v1.3.0-alpha", 1},
+		{"v1.3.0-beta.1", "v1.3.0-beta", 1},
+		{"v1.3.0+build.5", "v1.3.0", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// releaseServer serves a GitHub releases listing and release assets
+type releaseServer struct {
+	*httptest.Server
+	releases []*Release
+	files    map[string][]byte
+}
+
+func newReleaseServer(t *testing.T) *releaseServer {
+	t.Helper()
+	rs := &releaseServer{files: make(map[string][]byte)}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/"+DefaultRepository+"/releases" {
+			json.NewEncoder(w).Encode(rs.releases)
+			return
+		}
+		data, ok := rs.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+// addRelease publishes a release with the given asset contents
+func (rs *releaseServer) addRelease(tag string, prerelease bool, assets map[string][]byte) *Release {
+	release := &Release{TagName: tag, Prerelease: prerelease}
+	for name, data := range assets {
+		path := fmt.Sprintf("/download/%s/%s", tag, name)
+		rs.files[path] = data
+		release.Assets = append(release.Assets, Asset{Name: name, URL: rs.URL + path})
+	}
+	rs.releases = append(rs.releases, release)
+	return release
+}
+
+func (rs *releaseServer) updater(opts ...Option) *Updater {
+	opts = append([]Option{WithAPIURL(rs.URL), WithHTTPClient(rs.Client()), WithPlatform("linux", "amd64")}, opts...)
+	return NewUpdater(opts...)
+}
+
+func checksumLine(name string, data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+}
+
+func TestLatestChannels(t *testing.T) {
+	rs := newReleaseServer(t)
+	rs.addRelease("v1.1.0", false, nil)
+	rs.addRelease("v1.2.0-beta.1", true, nil)
+	rs.addRelease("v1.0.0", false, nil)
+	rs.releases = append(rs.releases, &Release{TagName: "v9.0.0", Draft: true})
+
+	stable, err := rs.updater().Latest(context.Background(), config.ChannelStable)
+	if err != nil || stable.TagName != "v1.1.0" {
+		t.Errorf("stable channel: got %v, %v; want v1.1.0", stable, err)
+	}
+
+	beta, err := rs.updater().Latest(context.Background(), config.ChannelBeta)
+	if err != nil || beta.TagName != "v1.2.0-beta.1" {
+		t.Errorf("beta channel: got %v, %v; want v1.2.0-beta.1", beta, err)
+	}
+}
+
+func TestApplyReplacesBinary(t *testing.T) {
+	rs := newReleaseServer(t)
+	binary := []byte("new forge binary")
+	assetName := BinaryAssetName("linux", "amd64")
+	release := rs.addRelease("v1.1.0", false, map[string][]byte{
+		assetName:      binary,
+		ChecksumsAsset: checksumLine(assetName, binary),
+	})
+
+	executable := filepath.Join(t.TempDir(), "forge")
+	if err := os.WriteFile(executable, []byte("old forge binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rs.updater(WithoutSignature()).Apply(context.Background(), release, executable); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	got, err := os.ReadFile(executable)
+	if err != nil || string(got) != string(binary) {
+		t.Errorf("expected executable to be replaced, got %q (%v)", got, err)
+	}
+	if _, err := os.Stat(executable + ".old"); !os.IsNotExist(err) {
+		t.Error("expected the old binary to be removed")
+	}
+}
+
+func TestApplyRejectsChecksumMismatch(t *testing.T) {
+	rs := newReleaseServer(t)
+	assetName := BinaryAssetName("linux", "amd64")
+	release := rs.addRelease("v1.1.0", false, map[string][]byte{
+		assetName:      []byte("tampered binary"),
+		ChecksumsAsset: checksumLine(assetName, []byte("published binary")),
+	})
+
+	executable := filepath.Join(t.TempDir(), "forge")
+	if err := os.WriteFile(executable, []byte("old forge binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := rs.updater(WithoutSignature()).Apply(context.Background(), release, executable)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if got, _ := os.ReadFile(executable); string(got) != "old forge binary" {
+		t.Error("expected the installed binary to be left untouched")
+	}
+}
+
+func TestApplyVerifiesSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary := []byte("new forge binary")
+	assetName := BinaryAssetName("linux", "amd64")
+	checksums := checksumLine(assetName, binary)
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, checksums)))
+
+	tests := []struct {
+		name    string
+		assets  map[string][]byte
+		wantErr error
+	}{
+		{"valid signature", map[string][]byte{assetName: binary, ChecksumsAsset: checksums, SignatureAsset: signature}, nil},
+		{"missing signature", map[string][]byte{assetName: binary, ChecksumsAsset: checksums}, ErrBadSignature},
+		{"wrong signature", map[string][]byte{assetName: binary, ChecksumsAsset: checksums, SignatureAsset: []byte(base64.StdEncoding.EncodeToString(make([]byte, 64)))}, ErrBadSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := newReleaseServer(t)
+			release := rs.addRelease("v1.1.0", false, tt.assets)
+			executable := filepath.Join(t.TempDir(), "forge")
+			if err := os.WriteFile(executable, []byte("old"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := rs.updater(WithPublicKey(publicKey)).Apply(context.Background(), release, executable)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("expected success, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestApplyRequiresPublicKey(t *testing.T) {
+	rs := newReleaseServer(t)
+	binary := []byte("new forge binary")
+	assetName := BinaryAssetName("linux", "amd64")
+	release := rs.addRelease("v1.1.0", false, map[string][]byte{
+		assetName:      binary,
+		ChecksumsAsset: checksumLine(assetName, binary),
+	})
+
+	executable := filepath.Join(t.TempDir(), "forge")
+	if err := os.WriteFile(executable, []byte("old forge binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := rs.updater().Apply(context.Background(), release, executable)
+	if !errors.Is(err, ErrNoPublicKey) {
+		t.Fatalf("expected an updater without a key to refuse, got %v", err)
+	}
+	if got, _ := os.ReadFile(executable); string(got) != "old forge binary" {
+		t.Error("expected the installed binary to be left untouched")
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(nil)
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey))
+	if err != nil || !key.Equal(publicKey) {
+		t.Errorf("ParsePublicKey round trip failed: %v", err)
+	}
+	if _, err := ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("expected an error for a short key")
+	}
+}
@@ -0,0 +1,127 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Source is something a Watcher polls for changes
+type Source interface {
+	// String names the source in logs, e.g. "path docs/api"
+	String() string
+
+	// State returns a fingerprint of the source that changes when it does
+	State(ctx context.Context) (string, error)
+}
+
+// skipDirs are directories a PathSource never looks into: git's own data,
+// and Forge's, which changes on every run
+var skipDirs = map[string]bool{".git": true, ".forge": true}
+
+// PathSource watches the files under a path in a workspace
+type PathSource struct {
+	root string
+	path string
+}
+
+// NewPathSource watches path, a file or directory relative to root. Files are
+// compared by size and modification time; .git and .forge directories are
+// skipped.
+func NewPathSource(root, path string) *PathSource {
+	return &PathSource{root: root, path: path}
+}
+
+func (s *PathSource) String() string {
+	return "path " + s.path
+}
+
+// State hashes the name, size and modification time of every file under the
+// path. A path that doesn't exist has the state of an empty directory, so
+// creating a file there is a change.
+func (s *PathSource) State(ctx context.Context) (string, error) {
+	hash := sha256.New()
+	full := filepath.Join(s.root, s.path)
+	err := filepath.WalkDir(full, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == full && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != full && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(full, path)
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to scan %s: %w", s.path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// RefSource watches the commit a git ref points to
+type RefSource struct {
+	dir   string
+	ref   string
+	fetch bool
+}
+
+// NewRefSource watches ref, such as a branch or origin/main, in the
+// repository at dir. With fetch, each poll runs git fetch first so that
+// remote refs move when changes land upstream.
+func NewRefSource(dir, ref string, fetch bool) *RefSource {
+	return &RefSource{dir: dir, ref: ref, fetch: fetch}
+}
+
+func (s *RefSource) String() string {
+	return "ref " + s.ref
+}
+
+// State returns the commit the ref points to
+func (s *RefSource) State(ctx context.Context) (string, error) {
+	if s.fetch {
+		if _, err := s.git(ctx, "fetch", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+	return s.git(ctx, "rev-parse", "--verify", "--quiet", s.ref+"^{commit}")
+}
+
+// git runs a git command in the repository and returns its trimmed output
+func (s *RefSource) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = s.dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], msg)
+		}
+		if args[0] == "rev-parse" {
+			return "", fmt.Errorf("unknown git ref %q", s.ref)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
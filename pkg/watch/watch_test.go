@@ -0,0 +1,224 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/workspacetest"
+)
+
+func TestPathSource(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{
+		"api/types.go":  "package api\n",
+		"docs/index.md": "# Docs\n",
+	})
+	ctx := context.Background()
+	source := NewPathSource(ws.Dir, "api")
+
+	before, err := source.State(ctx)
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+	ws.Write(workspacetest.Tree{"docs/index.md": "# Changed\n", "api/.forge/lock": "{}"})
+	if state, _ := source.State(ctx); state != before {
+		t.Error("expected changes outside the path and in .forge to be ignored")
+	}
+	ws.Write(workspacetest.Tree{"api/client.go": "package api\n"})
+	if state, _ := source.State(ctx); state == before {
+		t.Error("expected a new file to change the state")
+	}
+
+	missing := NewPathSource(ws.Dir, "generated")
+	empty, err := missing.State(ctx)
+	if err != nil {
+		t.Fatalf("expected a missing path to have a state, got %v", err)
+	}
+	ws.Write(workspacetest.Tree{"generated/out.txt": "x"})
+	if state, _ := missing.State(ctx); state == empty {
+		t.Error("expected creating the path to change the state")
+	}
+}
+
+func TestRefSource(t *testing.T) {
+	ws := workspacetest.New(t, workspacetest.Tree{"a.txt": "a\n"}, workspacetest.WithGit())
+	ws.Commit("Add a")
+	ctx := context.Background()
+	source := NewRefSource(ws.Dir, "HEAD", false)
+
+	before, err := source.State(ctx)
+	if err != nil || before != ws.Git("rev-parse", "HEAD") {
+		t.Fatalf("State() = %q, %v; want the HEAD commit", before, err)
+	}
+	ws.Write(workspacetest.Tree{"a.txt": "b\n"})
+	if state, _ := source.State(ctx); state != before {
+		t.Error("expected uncommitted changes not to move the ref")
+	}
+	ws.Commit("Change a")
+	if state, _ := source.State(ctx); state == before {
+		t.Error("expected a commit to move the ref")
+	}
+
+	if _, err := NewRefSource(ws.Dir, "no-such-branch", false).State(ctx); err == nil || !strings.Contains(err.Error(), "no-such-branch") {
+		t.Errorf("expected an unknown ref to fail, got %v", err)
+	}
+}
+
+// fakeSource is a source whose state is set by the test
+type fakeSource struct {
+	mu    sync.Mutex
+	state string
+	err   error
+}
+
+func (s *fakeSource) String() string { return "fake" }
+
+func (s *fakeSource) State(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, s.err
+}
+
+func (s *fakeSource) set(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+}
+
+// syncBuffer is a bytes.Buffer safe for the watcher's logger
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	source := &fakeSource{state: "v1"}
+	var logs syncBuffer
+	w := NewWatcher([]Source{source},
+		WithInterval(time.Millisecond),
+		WithSettle(0),
+		WithLogger(log.New(&logs, "", 0)),
+	)
+
+	var mu sync.Mutex
+	var changes []Change
+	busy := true
+	trigger := func(ctx context.Context, change Change) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if busy {
+			busy = false
+			return fmt.Errorf("%w: workspace locked", ErrBusy)
+		}
+		changes = append(changes, change)
+		// The run's own edits don't trigger another run
+		source.set("v3")
+		return errors.New("turn failed")
+	}
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(changes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx, trigger) }()
+
+	waitFor(t, "the baseline", func() bool { return strings.Contains(logs.String(), "Watching") })
+	source.set("v2")
+	waitFor(t, "the run", func() bool { return count() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if count() != 1 {
+		t.Errorf("expected one run, got %d", count())
+	}
+	if c := changes[0]; c.Source != "fake" || c.Before != "v1" || c.After != "v2" {
+		t.Errorf("unexpected change: %+v", c)
+	}
+	for _, want := range []string{"Watching fake", "fake changed (v1 -> v2), running", "Not run: busy: workspace locked", "Run failed after 0s: turn failed"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected logs to contain %q, got:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestWatcher_Settle(t *testing.T) {
+	source := &fakeSource{state: "v1"}
+	var logs syncBuffer
+	w := NewWatcher([]Source{source},
+		WithInterval(time.Millisecond),
+		WithSettle(20*time.Millisecond),
+		WithLogger(log.New(&logs, "", 0)),
+	)
+
+	runs := make(chan Change, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx, func(ctx context.Context, change Change) error {
+		runs <- change
+		return nil
+	})
+
+	// A burst of changes within the settle period triggers one run
+	waitFor(t, "the baseline", func() bool { return strings.Contains(logs.String(), "Watching") })
+	for _, state := range []string{"v2", "v3", "v4"} {
+		source.set(state)
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case change := <-runs:
+		if change.After != "v4" {
+			t.Errorf("expected the run to see the settled state, got %+v", change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the run")
+	}
+	select {
+	case change := <-runs:
+		t.Errorf("expected a single run, got another for %+v", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWatcher_BaselineError(t *testing.T) {
+	source := &fakeSource{err: errors.New("unknown git ref")}
+	err := NewWatcher([]Source{source}).Run(context.Background(), func(context.Context, Change) error { return nil })
+	if err == nil {
+		t.Error("expected an unreadable source to fail Run")
+	}
+}
@@ -0,0 +1,206 @@
+// Package watch runs work when a path or git ref in a workspace changes,
+// such as regenerating docs when an API package is edited or running a
+// workflow when a branch moves.
+//
+// A Watcher polls its sources rather than subscribing to filesystem events,
+// so it works the same for files, local refs and fetched remote refs, and
+// on network filesystems. Runs never overlap: changes that land during a run
+// are picked up by the first poll after it.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// Defaults for NewWatcher
+const (
+	DefaultInterval = 30 * time.Second
+	DefaultSettle   = 5 * time.Second
+)
+
+// ErrBusy is returned, wrapped, by a trigger that can't run yet, such as
+// because another session holds the workspace lock. The change is kept and
+// the trigger retried at the next poll.
+var ErrBusy = errors.New("busy")
+
+// Change describes the source change that triggered a run
+type Change struct {
+	Source string // Source.String() of the source that changed
+	Before string // Its state before the change
+	After  string // Its state when the run started
+}
+
+// String describes the change for logs
+func (c Change) String() string {
+	return fmt.Sprintf("%s changed (%s -> %s)", c.Source, short(c.Before), short(c.After))
+}
+
+// Trigger runs the work for a change
+type Trigger func(ctx context.Context, change Change) error
+
+// Watcher polls sources and runs a trigger when one changes
+type Watcher struct {
+	sources  []Source
+	interval time.Duration
+	settle   time.Duration
+	logger   *log.Logger
+}
+
+// Option configures a Watcher
+type Option func(*Watcher)
+
+// WithInterval sets how often sources are polled
+func WithInterval(interval time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = interval
+	}
+}
+
+// WithSettle waits until a changed source has stayed the same for settle
+// before running, so a burst of edits or a checkout triggers one run. Zero
+// runs as soon as a change is seen.
+func WithSettle(settle time.Duration) Option {
+	return func(w *Watcher) {
+		w.settle = settle
+	}
+}
+
+// WithLogger logs changes and the outcome of each run to logger. By default
+// nothing is logged.
+func WithLogger(logger *log.Logger) Option {
+	return func(w *Watcher) {
+		w.logger = logger
+	}
+}
+
+// NewWatcher creates a watcher of sources
+func NewWatcher(sources []Source, opts ...Option) *Watcher {
+	w := &Watcher{
+		sources:  sources,
+		interval: DefaultInterval,
+		settle:   DefaultSettle,
+		logger:   log.New(io.Discard, "", 0),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run polls the sources until ctx is canceled, calling trigger for each
+// change. The sources' states when Run starts are the baseline; it fails if
+// any can't be read then. Later read errors and trigger errors are logged
+// and watching continues. After each run the baseline is read again, so
+// changes the run makes to the watched sources don't trigger another.
+func (w *Watcher) Run(ctx context.Context, trigger Trigger) error {
+	states := make([]string, len(w.sources))
+	for i, source := range w.sources {
+		state, err := source.State(ctx)
+		if err != nil {
+			return err
+		}
+		states[i] = state
+		w.logger.Printf("Watching %s", source)
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		change, ok := w.poll(ctx, states)
+		if !ok {
+			continue
+		}
+		w.logger.Printf("%s, running", change)
+		start := time.Now()
+		err := trigger(ctx, change)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, ErrBusy):
+			w.logger.Printf("Not run: %v; retrying in %s", err, w.interval)
+			continue
+		case err != nil:
+			w.logger.Printf("Run failed after %s: %v", time.Since(start).Round(time.Second), err)
+		default:
+			w.logger.Printf("Run finished in %s", time.Since(start).Round(time.Second))
+		}
+		w.rebase(ctx, states)
+	}
+}
+
+// poll returns the first source whose state differs from states, once it
+// has settled
+func (w *Watcher) poll(ctx context.Context, states []string) (Change, bool) {
+	for i, source := range w.sources {
+		state, err := source.State(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Printf("Failed to read %s: %v", source, err)
+			}
+			continue
+		}
+		if state == states[i] {
+			continue
+		}
+		if state, err = w.settled(ctx, source, state); err != nil {
+			if ctx.Err() == nil {
+				w.logger.Printf("Failed to read %s: %v", source, err)
+			}
+			return Change{}, false
+		}
+		return Change{Source: source.String(), Before: states[i], After: state}, true
+	}
+	return Change{}, false
+}
+
+// settled waits until source has kept the same state for the settle period
+// and returns it
+func (w *Watcher) settled(ctx context.Context, source Source, state string) (string, error) {
+	if w.settle <= 0 {
+		return state, nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(w.settle):
+		}
+		next, err := source.State(ctx)
+		if err != nil {
+			return "", err
+		}
+		if next == state {
+			return state, nil
+		}
+		state = next
+	}
+}
+
+// rebase reads the state of every source into states, keeping the previous
+// state of any that can't be read
+func (w *Watcher) rebase(ctx context.Context, states []string) {
+	for i, source := range w.sources {
+		if state, err := source.State(ctx); err == nil {
+			states[i] = state
+		}
+	}
+}
+
+// short abbreviates a state for logs
+func short(state string) string {
+	if len(state) > 12 {
+		return state[:12]
+	}
+	return state
+}
@@ -0,0 +1,185 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AgentsDir is where a workspace's agent definitions live, relative to its
+// root. The user's own definitions are in the same directory under their
+// home.
+const AgentsDir = ".forge/agents"
+
+// AgentDef is an agent definition: a role that workflow steps name with
+// agent, given as a YAML file named after the role:
+//
+//	description: Plans changes without editing code
+//	tools: [read_file, list_files, search_files]  # Empty for all
+//	instructions: |
+//	  You are the architect. Explore the code and write a plan ...
+//
+// The instructions are added to the system prompt for the steps that name
+// the role, which may use only its tools unless they list their own.
+type AgentDef struct {
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description,omitempty"`
+	Instructions string   `yaml:"instructions"`
+	Tools        []string `yaml:"tools,omitempty"`
+
+	// Path is the file the definition was read from; empty for built-ins
+	Path string `yaml:"-"`
+}
+
+// readOnlyTools are the tools of roles that look at the workspace without
+// changing it
+var readOnlyTools = []string{"read_file", "list_files", "search_files"}
+
+// builtinAgents are the roles of forge pipeline, used when no definition
+// file of the same name is found
+var builtinAgents = map[string]*AgentDef{
+	"architect": {
+		Name:        "architect",
+		Description: "Explores the code and writes an implementation plan, without editing",
+		Tools:       readOnlyTools,
+		Instructions: `You are the architect. Read the code the task touches and design the change before anyone writes it. Don't edit files.
+
+Call task_completion with the plan as the result, in Markdown:
+- Approach: the design in a few sentences, and the alternatives you rejected and why
+- Changes: each file to create or change, and what changes in it
+- Tests: the tests to add or update
+- Risks: what could break, and open questions for the user
+
+Be specific enough that another engineer can implement the plan without redoing your research: name the functions, types and files involved.`,
+	},
+	"implementer": {
+		Name:        "implementer",
+		Description: "Implements an approved plan and verifies it",
+		Instructions: `You are the implementer. Carry out the approved plan you are given, following the code's existing conventions. Don't redesign: where the plan turns out to be wrong, make the smallest change that works and say why.
+
+Build and run the tests before you finish. Call task_completion with a summary of what you changed, each deviation from the plan, and the commands you ran to verify it, listing the files changed.`,
+	},
+	"reviewer": {
+		Name:        "reviewer",
+		Description: "Reviews the changes against the plan and reports findings, without editing",
+		Tools:       append(append([]string{}, readOnlyTools...), "workspace_diff", "execute_command"),
+		Instructions: `You are the reviewer. Review the changes you are given against the task and its plan, as you would a teammate's pull request. Read the surrounding code where the diff alone doesn't tell you whether a change is right. You may run the tests, but don't edit files: report problems rather than fixing them.
+
+Call task_completion with your review as the result, in Markdown: a verdict (approve, or request changes) on the first line, then one finding per bullet as "path:line: [severity] problem and suggested fix", with severity blocker, major or minor. Leave out style nits and praise.`,
+	},
+}
+
+// LoadAgent reads an agent definition file. The name defaults to the file
+// name without its extension.
+func LoadAgent(path string) (*AgentDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent definition: %w", err)
+	}
+
+	def := &AgentDef{}
+	if err := yaml.Unmarshal(data, def); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	def.Path = path
+	if def.Name == "" {
+		def.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if strings.TrimSpace(def.Instructions) == "" {
+		return nil, fmt.Errorf("%s: instructions are required", path)
+	}
+	return def, nil
+}
+
+// FindAgent returns the agent definition called name from the first of dirs
+// that has a name.yaml or name.yml file, or the built-in role of that name
+func FindAgent(name string, dirs ...string) (*AgentDef, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid agent name %q", name)
+	}
+	for _, dir := range dirs {
+		for _, ext := range []string{".yaml", ".yml"} {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return LoadAgent(path)
+			}
+		}
+	}
+	if def, ok := builtinAgents[name]; ok {
+		return def, nil
+	}
+	return nil, fmt.Errorf("agent %q not found in %s, and there is no built-in agent of that name", name, strings.Join(dirs, ", "))
+}
+
+// ListAgents returns the agent definitions in dirs and the built-in roles,
+// in name order. A definition in an earlier directory hides one of the same
+// name in a later one, and any definition file hides a built-in role.
+func ListAgents(dirs ...string) ([]*AgentDef, error) {
+	byName := make(map[string]*AgentDef, len(builtinAgents))
+	for name, def := range builtinAgents {
+		byName[name] = def
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent definitions: %w", err)
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			def, err := LoadAgent(filepath.Join(dirs[i], entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			byName[def.Name] = def
+		}
+	}
+
+	defs := make([]*AgentDef, 0, len(byName))
+	for _, def := range byName {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+// Pipeline returns the workflow of forge pipeline: the architect plans the
+// task, the implementer carries out the plan, and the reviewer reviews the
+// resulting changes against it. It has one input, task.
+func Pipeline() *Workflow {
+	return &Workflow{
+		Name:        "pipeline",
+		Description: "Plan, implement and review a change with a separate agent for each stage",
+		Inputs:      map[string]string{"task": ""},
+		Steps: []Step{
+			{
+				Name:   "plan",
+				Agent:  "architect",
+				Prompt: "Plan this task:\n\n{{.Inputs.task}}",
+			},
+			{
+				Name:  "implement",
+				Agent: "implementer",
+				Prompt: "Implement the approved plan for this task.\n\nTask:\n{{.Inputs.task}}\n\n" +
+					"<plan>\n{{.Results.plan}}\n</plan>",
+			},
+			{
+				Name:  "review",
+				Agent: "reviewer",
+				Prompt: "Review the changes made for this task against its plan.\n\nTask:\n{{.Inputs.task}}\n\n" +
+					"<plan>\n{{.Results.plan}}\n</plan>\n\n" +
+					"<implementation_summary>\n{{.Results.implement}}\n</implementation_summary>\n\n" +
+					"<diff>\n{{.Diff}}\n</diff>",
+			},
+		},
+	}
+}
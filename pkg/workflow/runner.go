@@ -0,0 +1,405 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// DefaultCommandTimeout bounds each command criterion
+const DefaultCommandTimeout = 5 * time.Minute
+
+// maxDetail bounds the command output kept in a failed check
+const maxDetail = 2000
+
+// maxDiff bounds the diff a prompt is given as {{.Diff}}
+const maxDiff = 60000
+
+// noDiff is {{.Diff}} when the workspace changes can't be diffed
+const noDiff = "(The workspace is not a git repository, so its changes can't be shown. Read the files the steps before changed.)"
+
+// ErrStepDeclined is returned when the user declines to run a step
+var ErrStepDeclined = errors.New("step declined")
+
+// Agent is the agent a workflow runs on. agent.DefaultAgent implements it.
+type Agent interface {
+	agent.Agent
+
+	// SetAllowedTools limits the tools the agent may use; without names it
+	// allows all of them
+	SetAllowedTools(names ...string)
+
+	// SetRole adds a role's instructions to the system prompt; an empty name
+	// clears the role
+	SetRole(name, instructions string)
+}
+
+// Runner runs workflows as a sequence of agent turns
+type Runner struct {
+	workDir        string
+	agentDirs      []string
+	commandTimeout time.Duration
+	approveStep    func(step Step) bool
+	approveTool    func(event *types.AgentEvent) *types.ApprovalResponse
+	onEvent        func(event *types.AgentEvent)
+	progress       io.Writer
+}
+
+// Option configures a Runner
+type Option func(*Runner)
+
+// WithStepApprover asks approve before each step, with its rendered prompt;
+// the run stops with ErrStepDeclined if it returns false. By default every
+// step runs.
+func WithStepApprover(approve func(step Step) bool) Option {
+	return func(r *Runner) {
+		r.approveStep = approve
+	}
+}
+
+// WithToolApprover answers the agent's tool approval requests. By default
+// every request is rejected.
+func WithToolApprover(approve func(event *types.AgentEvent) *types.ApprovalResponse) Option {
+	return func(r *Runner) {
+		r.approveTool = approve
+	}
+}
+
+// WithEventHandler passes every other event of the agent's turns to handle,
+// such as to display them
+func WithEventHandler(handle func(event *types.AgentEvent)) Option {
+	return func(r *Runner) {
+		r.onEvent = handle
+	}
+}
+
+// WithAgentDirs sets the directories agent definitions named by steps are
+// read from, the first taking precedence. Built-in roles are used for
+// names none of them define.
+func WithAgentDirs(dirs ...string) Option {
+	return func(r *Runner) {
+		r.agentDirs = dirs
+	}
+}
+
+// WithCommandTimeout bounds each command criterion
+func WithCommandTimeout(timeout time.Duration) Option {
+	return func(r *Runner) {
+		r.commandTimeout = timeout
+	}
+}
+
+// WithProgress writes a line to w as each step starts and finishes
+func WithProgress(w io.Writer) Option {
+	return func(r *Runner) {
+		r.progress = w
+	}
+}
+
+// NewRunner creates a runner that checks criteria in workDir
+func NewRunner(workDir string, opts ...Option) *Runner {
+	r := &Runner{
+		workDir:        workDir,
+		commandTimeout: DefaultCommandTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Result is the outcome of a workflow run
+type Result struct {
+	Workflow string
+	Steps    []StepResult
+	Passed   bool // Every step ran and its criteria held
+}
+
+// StepResult is the outcome of one step
+type StepResult struct {
+	Name       string
+	Agent      string // The agent definition that ran the step, if any
+	Passed     bool
+	Checks     []Check
+	ToolCalls  []string          // Tools called, in order
+	Completion string            // The task_completion result, if the agent called it
+	Details    *tools.Completion // Its structured fields, if the agent filled any in
+	Completed  bool
+	Errors     []string // Errors the agent reported during the turn
+	Diff       string   // The workspace changes the step made, if they could be diffed
+	Duration   time.Duration
+}
+
+// Check is the outcome of one criterion
+type Check struct {
+	Criterion string
+	Passed    bool
+	Detail    string // Why it failed
+}
+
+// Run runs the workflow's steps in order on ag, which must not be started,
+// with inputs bound by Workflow.Bind. It stops at the first step that is
+// declined, fails its criteria or can't finish, and returns the steps run
+// so far with an error saying why.
+func (r *Runner) Run(ctx context.Context, ag Agent, w *Workflow, inputs map[string]string) (*Result, error) {
+	result := &Result{Workflow: w.Name}
+
+	// Unknown agents fail the run before any step has changed the workspace
+	agents := make(map[string]*AgentDef)
+	for _, step := range w.Steps {
+		if step.Agent == "" || agents[step.Agent] != nil {
+			continue
+		}
+		def, err := FindAgent(step.Agent, r.agentDirs...)
+		if err != nil {
+			return result, fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		agents[step.Agent] = def
+	}
+
+	if err := ag.Start(ctx); err != nil {
+		return result, fmt.Errorf("failed to start agent: %w", err)
+	}
+	channels := ag.GetChannels()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_ = ag.Shutdown(shutdownCtx)
+		for range channels.Event {
+			// Drain until the agent closes its channels
+		}
+	}()
+	defer ag.SetAllowedTools()
+	defer ag.SetRole("", "")
+
+	// Snapshots of the workspace when the workflow and the current step
+	// started; nil outside a git repository
+	start, _ := git.TakeSnapshot(ctx, r.workDir)
+	stepStart, _ := git.TakeSnapshot(ctx, r.workDir)
+
+	data := templateData{Inputs: inputs, Results: make(map[string]string), Diff: truncateDiff("")}
+	if start == nil {
+		data.Diff = noDiff
+	}
+	for i := range w.Steps {
+		step, err := w.Steps[i].render(data)
+		if err != nil {
+			return result, fmt.Errorf("step %q: %w", w.Steps[i].Name, err)
+		}
+		if r.approveStep != nil && !r.approveStep(step) {
+			return result, fmt.Errorf("%w: %s", ErrStepDeclined, step.Name)
+		}
+		r.report("Step %d/%d: %s\n", i+1, len(w.Steps), step.Name)
+
+		began := time.Now()
+		stepResult := StepResult{Name: step.Name, Agent: step.Agent}
+		allowed := step.Tools
+		if def := agents[step.Agent]; def != nil {
+			ag.SetRole(def.Name, def.Instructions)
+			if len(allowed) == 0 {
+				allowed = def.Tools
+			}
+		} else {
+			ag.SetRole("", "")
+		}
+		ag.SetAllowedTools(allowed...)
+		err = r.runTurn(ctx, channels, step.Prompt, &stepResult)
+		if stepStart != nil {
+			stepResult.Diff, _ = stepStart.DiffAndAdvance(ctx)
+		}
+		if err == nil {
+			criteria := step.Success
+			if len(criteria) == 0 {
+				criteria = []Criterion{{Type: CriterionCompleted}}
+			}
+			stepResult.Checks = r.check(ctx, criteria, &stepResult)
+		}
+		stepResult.Passed = err == nil && passed(stepResult.Checks)
+		stepResult.Duration = time.Since(began)
+		result.Steps = append(result.Steps, stepResult)
+
+		if err != nil {
+			return result, fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		if !stepResult.Passed {
+			r.report("FAIL %s\n", step.Name)
+			return result, fmt.Errorf("step %q did not meet its success criteria", step.Name)
+		}
+		r.report("PASS %s (%s)\n", step.Name, stepResult.Duration.Round(time.Second))
+		data.Previous = stepResult.Completion
+		data.Results[step.Name] = stepResult.Completion
+		if start != nil {
+			diff, err := start.Diff(ctx)
+			if err != nil {
+				return result, fmt.Errorf("failed to diff the workspace: %w", err)
+			}
+			data.Diff = truncateDiff(diff)
+		}
+	}
+
+	result.Passed = true
+	return result, nil
+}
+
+// runTurn sends prompt to the agent and records the turn in result until it
+// ends, answering tool approval requests
+func (r *Runner) runTurn(ctx context.Context, channels *types.AgentChannels, prompt string, result *StepResult) error {
+	channels.Input <- types.NewUserInput(prompt)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-channels.Event:
+			if !ok {
+				return fmt.Errorf("agent stopped before the turn ended")
+			}
+			switch event.Type {
+			case types.EventTypeToolApprovalRequest:
+				channels.Approval <- r.answer(event)
+				continue
+			case types.EventTypeTurnEnd:
+				return nil
+			}
+			result.record(event)
+			if r.onEvent != nil {
+				r.onEvent(event)
+			}
+		}
+	}
+}
+
+// answer returns the response to a tool approval request
+func (r *Runner) answer(event *types.AgentEvent) *types.ApprovalResponse {
+	if r.approveTool == nil {
+		return types.NewApprovalResponse(event.ApprovalID, types.ApprovalRejected)
+	}
+	return r.approveTool(event)
+}
+
+// record updates the result from an event of the step's turn
+func (s *StepResult) record(event *types.AgentEvent) {
+	switch event.Type {
+	case types.EventTypeToolCall:
+		s.ToolCalls = append(s.ToolCalls, event.ToolName)
+	case types.EventTypeToolResult:
+		if event.ToolName == "task_completion" {
+			s.Completed = true
+			s.Completion = fmt.Sprint(event.ToolOutput)
+			if result, ok := event.ToolOutput.(*tools.Result); ok {
+				if completion, ok := tools.CompletionFromResult(result); ok && completion.Structured() {
+					s.Details = completion
+				}
+			}
+		}
+	case types.EventTypeError:
+		if event.Error != nil {
+			s.Errors = append(s.Errors, event.Error.Error())
+		}
+	}
+}
+
+// check evaluates each criterion against the workspace and the turn
+func (r *Runner) check(ctx context.Context, criteria []Criterion, result *StepResult) []Check {
+	checks := make([]Check, len(criteria))
+	for i, c := range criteria {
+		detail := r.evaluate(ctx, c, result)
+		checks[i] = Check{Criterion: c.String(), Passed: detail == "", Detail: detail}
+	}
+	return checks
+}
+
+// evaluate returns why the criterion failed, or "" if it holds
+func (r *Runner) evaluate(ctx context.Context, c Criterion, result *StepResult) string {
+	switch c.Type {
+	case CriterionFileExists:
+		if _, err := os.Stat(filepath.Join(r.workDir, c.Path)); err != nil {
+			return fmt.Sprintf("%s does not exist", c.Path)
+		}
+	case CriterionFileContains:
+		data, err := os.ReadFile(filepath.Join(r.workDir, c.Path))
+		if err != nil {
+			return fmt.Sprintf("failed to read %s: %v", c.Path, err)
+		}
+		if !strings.Contains(string(data), c.Text) {
+			return fmt.Sprintf("%s does not contain %q", c.Path, c.Text)
+		}
+	case CriterionCommand:
+		return r.runCommand(ctx, c.Command)
+	case CriterionCompleted:
+		if !result.Completed {
+			return "the agent did not call task_completion"
+		}
+	case CriterionResultContains:
+		if !result.Completed {
+			return "the agent did not call task_completion"
+		}
+		if !strings.Contains(result.Completion, c.Text) {
+			return fmt.Sprintf("result does not contain %q", c.Text)
+		}
+	}
+	return ""
+}
+
+// runCommand runs command with sh in the workspace and returns its output
+// if it fails
+func (r *Runner) runCommand(ctx context.Context, command string) string {
+	cmdCtx, cancel := context.WithTimeout(ctx, r.commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "sh", "-c", command)
+	cmd.Dir = r.workDir
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return ""
+	}
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		return fmt.Sprintf("timed out after %s", r.commandTimeout)
+	}
+	detail := strings.TrimSpace(string(output))
+	if len(detail) > maxDetail {
+		detail = "..." + detail[len(detail)-maxDetail:]
+	}
+	if detail == "" {
+		return err.Error()
+	}
+	return fmt.Sprintf("%v: %s", err, detail)
+}
+
+// report writes a progress line
+func (r *Runner) report(format string, args ...interface{}) {
+	if r.progress != nil {
+		fmt.Fprintf(r.progress, format, args...)
+	}
+}
+
+// truncateDiff cuts diff to maxDiff bytes at a line break, noting the cut
+func truncateDiff(diff string) string {
+	if diff == "" {
+		return "(No changes)"
+	}
+	if len(diff) <= maxDiff {
+		return diff
+	}
+	cut := strings.LastIndex(diff[:maxDiff], "\n")
+	return diff[:cut+1] + fmt.Sprintf("[Diff cut short: %d more bytes. Use workspace_diff or read the files for the rest.]\n", len(diff)-cut-1)
+}
+
+// passed reports whether every check passed
+func passed(checks []Check) bool {
+	for _, c := range checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,331 @@
+// Package workflow runs recurring multi-step chores as a sequence of agent
+// turns defined in YAML.
+//
+// A workflow is a YAML file in a workspace's .forge/workflows directory or
+// in ~/.forge/workflows, named after the file:
+//
+//	description: Prepare a release
+//	inputs:
+//	  version: ""                  # Empty default: must be given with -set
+//	  branch: main
+//	steps:
+//	  - name: changelog
+//	    prompt: Add a CHANGELOG.md entry for {{.Inputs.version}}
+//	    tools: [read_file, apply_diff]  # Tools the step may use; empty for all
+//	    success:
+//	      - type: file_contains
+//	        path: CHANGELOG.md
+//	        text: "{{.Inputs.version}}"
+//	  - name: tests
+//	    prompt: Run the tests and fix any failures
+//	    success:
+//	      - type: command
+//	        command: go test ./...
+//
+// A step may name an agent definition (see AgentDef) to run it in that
+// role, so that stages such as planning, implementing and reviewing are
+// each done by an agent with its own instructions and tools:
+//
+//   - name: review
+//     agent: reviewer
+//     prompt: "Review this change against the plan:\n{{.Results.plan}}\n{{.Diff}}"
+//
+// Prompts and criteria are Go templates over the inputs and the artifacts
+// of the steps before: the previous step's task_completion result
+// ({{.Previous}}), each step's result by name ({{.Results.plan}}), and the
+// diff of the workspace changes since the workflow started ({{.Diff}}). The
+// Runner asks before each step, runs it as one agent turn limited to the
+// step's tools, and stops at the first step whose success criteria don't
+// hold.
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is where a workspace's workflows live, relative to its root. The
+// user's own workflows are in the same directory under their home.
+const Dir = ".forge/workflows"
+
+// Criterion types
+const (
+	CriterionCommand        = "command"         // Command exits 0 in the workspace, e.g. "go test ./..."
+	CriterionFileExists     = "file_exists"     // Path exists
+	CriterionFileContains   = "file_contains"   // Path contains Text
+	CriterionCompleted      = "completed"       // The agent called task_completion
+	CriterionResultContains = "result_contains" // The task_completion result contains Text
+)
+
+// Workflow is an ordered list of steps, each run as one agent turn
+type Workflow struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Inputs      map[string]string `yaml:"inputs,omitempty"` // Defaults; an empty default makes the input required
+	Steps       []Step            `yaml:"steps"`
+
+	// Path is the file the workflow was read from
+	Path string `yaml:"-"`
+}
+
+// Step is one turn of a workflow
+type Step struct {
+	Name    string      `yaml:"name"`
+	Agent   string      `yaml:"agent,omitempty"`   // Agent definition whose role runs the step; empty for none
+	Prompt  string      `yaml:"prompt"`            // Template of the turn's input
+	Tools   []string    `yaml:"tools,omitempty"`   // Tools the agent may use; empty for the role's, or all
+	Success []Criterion `yaml:"success,omitempty"` // Checked after the turn; all must hold (default: completed)
+}
+
+// Criterion is a condition checked after a step's turn
+type Criterion struct {
+	Type    string `yaml:"type"`
+	Path    string `yaml:"path,omitempty"`    // Workspace-relative file for file_* criteria
+	Text    string `yaml:"text,omitempty"`    // Substring for *_contains criteria
+	Command string `yaml:"command,omitempty"` // Shell command for command criteria
+}
+
+// String describes the criterion for reports
+func (c Criterion) String() string {
+	switch c.Type {
+	case CriterionFileExists:
+		return fmt.Sprintf("%s %s", c.Type, c.Path)
+	case CriterionFileContains:
+		return fmt.Sprintf("%s %s %q", c.Type, c.Path, c.Text)
+	case CriterionCommand:
+		return fmt.Sprintf("command %q", c.Command)
+	case CriterionResultContains:
+		return fmt.Sprintf("result_contains %q", c.Text)
+	default:
+		return c.Type
+	}
+}
+
+// validate reports the first field a criterion is missing
+func (c Criterion) validate() error {
+	var missing string
+	switch c.Type {
+	case CriterionFileExists:
+		if c.Path == "" {
+			missing = "path"
+		}
+	case CriterionFileContains:
+		if c.Path == "" {
+			missing = "path"
+		} else if c.Text == "" {
+			missing = "text"
+		}
+	case CriterionCommand:
+		if c.Command == "" {
+			missing = "command"
+		}
+	case CriterionResultContains:
+		if c.Text == "" {
+			missing = "text"
+		}
+	case CriterionCompleted:
+	default:
+		return fmt.Errorf("unknown criterion type %q", c.Type)
+	}
+	if missing != "" {
+		return fmt.Errorf("%s criterion requires %s", c.Type, missing)
+	}
+	return nil
+}
+
+// validate checks that the workflow can be run
+func (w *Workflow) validate() error {
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	seen := make(map[string]bool)
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step %d", i+1)
+		}
+		if strings.ContainsAny(step.Agent, `/\`) {
+			return fmt.Errorf("step %q: invalid agent name %q", step.Name, step.Agent)
+		}
+		if seen[step.Name] {
+			return fmt.Errorf("step %q is defined twice", step.Name)
+		}
+		seen[step.Name] = true
+		if strings.TrimSpace(step.Prompt) == "" {
+			return fmt.Errorf("step %q: prompt is required", step.Name)
+		}
+		for _, text := range step.templates() {
+			if _, err := parse(text); err != nil {
+				return fmt.Errorf("step %q: %w", step.Name, err)
+			}
+		}
+		for j, c := range step.Success {
+			if err := c.validate(); err != nil {
+				return fmt.Errorf("step %q: criterion %d: %w", step.Name, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Load reads a workflow file. The name defaults to the file name without
+// its extension.
+func Load(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow: %w", err)
+	}
+
+	w := &Workflow{}
+	if err := yaml.Unmarshal(data, w); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	w.Path = path
+	if w.Name == "" {
+		w.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := w.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Find returns the workflow called name from the first of dirs that has a
+// name.yaml or name.yml file
+func Find(name string, dirs ...string) (*Workflow, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid workflow name %q", name)
+	}
+	for _, dir := range dirs {
+		for _, ext := range []string{".yaml", ".yml"} {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return Load(path)
+			}
+		}
+	}
+	return nil, fmt.Errorf("workflow %q not found in %s", name, strings.Join(dirs, ", "))
+}
+
+// List returns the workflows in dirs, in name order. A workflow in an
+// earlier directory hides one of the same name in a later one; directories
+// that don't exist are skipped.
+func List(dirs ...string) ([]*Workflow, error) {
+	byName := make(map[string]*Workflow)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read workflows: %w", err)
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			w, err := Load(filepath.Join(dirs[i], entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			byName[w.Name] = w
+		}
+	}
+
+	workflows := make([]*Workflow, 0, len(byName))
+	for _, w := range byName {
+		workflows = append(workflows, w)
+	}
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].Name < workflows[j].Name })
+	return workflows, nil
+}
+
+// Bind returns the workflow's inputs with values set over the defaults. It
+// fails if a value is set for an input the workflow doesn't have, or an
+// input without a default is not set.
+func (w *Workflow) Bind(values map[string]string) (map[string]string, error) {
+	inputs := make(map[string]string, len(w.Inputs))
+	for name, value := range w.Inputs {
+		inputs[name] = value
+	}
+	for name, value := range values {
+		if _, ok := w.Inputs[name]; !ok {
+			return nil, fmt.Errorf("workflow %s has no input %q", w.Name, name)
+		}
+		inputs[name] = value
+	}
+
+	var missing []string
+	for name, value := range inputs {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("workflow %s requires input %s", w.Name, strings.Join(missing, ", "))
+	}
+	return inputs, nil
+}
+
+// templateData is what prompts and criteria are rendered with
+type templateData struct {
+	Inputs   map[string]string
+	Previous string            // The previous step's task_completion result
+	Results  map[string]string // The task_completion result of each step run, by name
+	Diff     string            // The workspace changes since the workflow started
+}
+
+// templates returns the step's template fields
+func (s *Step) templates() []string {
+	texts := []string{s.Prompt}
+	for _, c := range s.Success {
+		texts = append(texts, c.Path, c.Text, c.Command)
+	}
+	return texts
+}
+
+// render returns the step with its prompt and criteria rendered
+func (s *Step) render(data templateData) (Step, error) {
+	rendered := *s
+	var err error
+	if rendered.Prompt, err = execute(s.Prompt, data); err != nil {
+		return rendered, err
+	}
+	rendered.Success = make([]Criterion, len(s.Success))
+	for i, c := range s.Success {
+		for _, field := range []*string{&c.Path, &c.Text, &c.Command} {
+			if *field, err = execute(*field, data); err != nil {
+				return rendered, err
+			}
+		}
+		rendered.Success[i] = c
+	}
+	return rendered, nil
+}
+
+// parse parses a prompt or criterion template
+func parse(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(text)
+}
+
+// execute renders text with data
+func execute(text string, data templateData) (string, error) {
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,394 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/eval"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// writeFile creates path under dir with content
+func writeFile(t *testing.T, dir, path, content string) string {
+	t.Helper()
+	full := filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return full
+}
+
+// completion is a model reply calling task_completion with result
+func completion(result string) string {
+	return `<tool><server_name>local</server_name><tool_name>task_completion</tool_name><arguments><result>` + result + `</result></arguments></tool>`
+}
+
+const releaseYAML = `description: Prepare a release
+inputs:
+  version: ""
+  branch: main
+steps:
+  - name: changelog
+    prompt: Add a changelog entry for {{.Inputs.version}} on {{.Inputs.branch}}
+    tools: [read_file]
+    success:
+      - type: result_contains
+        text: "{{.Inputs.version}}"
+  - name: tag
+    prompt: "Tag the release. Changelog: {{.Previous}}"
+    success:
+      - type: command
+        command: test -f {{.Inputs.version}}.tag
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "release-prep.yaml", releaseYAML)
+
+	w, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if w.Name != "release-prep" || len(w.Steps) != 2 || w.Steps[0].Tools[0] != "read_file" {
+		t.Errorf("unexpected workflow: %+v", w)
+	}
+
+	inputs, err := w.Bind(map[string]string{"version": "1.2.0"})
+	if err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	step, err := w.Steps[0].render(templateData{Inputs: inputs})
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if step.Prompt != "Add a changelog entry for 1.2.0 on main" || step.Success[0].Text != "1.2.0" {
+		t.Errorf("unexpected rendered step: %+v", step)
+	}
+	if w.Steps[0].Prompt == step.Prompt {
+		t.Error("expected rendering to leave the workflow's template unchanged")
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no steps", "description: x", "at least one step"},
+		{"no prompt", "steps: [{name: a}]", "prompt is required"},
+		{"duplicate step", "steps: [{name: a, prompt: x}, {name: a, prompt: y}]", "defined twice"},
+		{"bad template", "steps: [{prompt: '{{.Inputs'}]", "step 1"},
+		{"unknown criterion", "steps: [{prompt: x, success: [{type: compiles}]}]", `unknown criterion type "compiles"`},
+		{"missing field", "steps: [{prompt: x, success: [{type: command}]}]", "requires command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "w.yaml", tt.content)
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	w := &Workflow{Name: "release", Inputs: map[string]string{"version": "", "branch": "main"}}
+
+	if _, err := w.Bind(nil); err == nil || !strings.Contains(err.Error(), "requires input version") {
+		t.Errorf("expected a missing input to fail, got %v", err)
+	}
+	if _, err := w.Bind(map[string]string{"version": "1", "colour": "red"}); err == nil || !strings.Contains(err.Error(), `no input "colour"`) {
+		t.Errorf("expected an unknown input to fail, got %v", err)
+	}
+	inputs, err := w.Bind(map[string]string{"version": "1", "branch": "release"})
+	if err != nil || inputs["version"] != "1" || inputs["branch"] != "release" {
+		t.Errorf("Bind() = %v, %v", inputs, err)
+	}
+}
+
+func TestFindAndList(t *testing.T) {
+	project, user := t.TempDir(), t.TempDir()
+	writeFile(t, project, "release-prep.yaml", "description: project\nsteps: [{prompt: x}]")
+	writeFile(t, user, "release-prep.yml", "description: user\nsteps: [{prompt: x}]")
+	writeFile(t, user, "triage.yaml", "steps: [{prompt: x}]")
+	writeFile(t, user, "notes.txt", "not a workflow")
+
+	w, err := Find("release-prep", project, user)
+	if err != nil || w.Description != "project" {
+		t.Errorf("Find() = %+v, %v; want the project's workflow", w, err)
+	}
+	if _, err := Find("missing", project, user); err == nil {
+		t.Error("expected a missing workflow to fail")
+	}
+	if _, err := Find("../triage", project); err == nil {
+		t.Error("expected a name with a path to fail")
+	}
+
+	workflows, err := List(project, user, filepath.Join(user, "missing"))
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(workflows) != 2 || workflows[0].Description != "project" || workflows[1].Name != "triage" {
+		t.Errorf("unexpected workflows: %+v", workflows)
+	}
+}
+
+// recordingProvider is a scripted provider that records the last user
+// message of each request
+type recordingProvider struct {
+	*eval.ScriptedProvider
+	mu      sync.Mutex
+	prompts []string
+	systems []string // The system prompt of each request
+}
+
+func (p *recordingProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	p.mu.Lock()
+	if len(messages) > 0 && messages[0].Role == types.RoleSystem {
+		p.systems = append(p.systems, messages[0].Content)
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == types.RoleUser {
+			p.prompts = append(p.prompts, messages[i].Content)
+			break
+		}
+	}
+	p.mu.Unlock()
+	return p.ScriptedProvider.StreamCompletion(ctx, messages)
+}
+
+// probeTool is a tool that should not be called
+type probeTool struct{}
+
+func (probeTool) Name() string                   { return "probe" }
+func (probeTool) Description() string            { return "test tool" }
+func (probeTool) Schema() map[string]interface{} { return tools.BaseToolSchema(nil, nil) }
+func (probeTool) IsLoopBreaking() bool           { return false }
+func (probeTool) Execute(ctx context.Context, argsXML []byte) (string, error) {
+	return "ok", nil
+}
+
+func TestRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Load(writeFile(t, t.TempDir(), "release-prep.yaml", releaseYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs, _ := w.Bind(map[string]string{"version": "1.2.0"})
+
+	provider := &recordingProvider{ScriptedProvider: eval.NewScriptedProvider(
+		// The changelog step may only read files
+		`<tool><server_name>local</server_name><tool_name>probe</tool_name><arguments></arguments></tool>`,
+		completion("Added 1.2.0 to the changelog"),
+		completion("Tagged"),
+	)}
+	ag := agent.NewDefaultAgent(provider)
+	if err := ag.RegisterTool(probeTool{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var approved []string
+	runner := NewRunner(dir, WithStepApprover(func(step Step) bool {
+		approved = append(approved, step.Prompt)
+		return true
+	}))
+	result, err := runner.Run(context.Background(), ag, w, inputs)
+
+	// The tag step fails because its command finds no tag file
+	if err == nil || !strings.Contains(err.Error(), `step "tag" did not meet its success criteria`) {
+		t.Fatalf("Run() error = %v, want the tag step to fail", err)
+	}
+	if result.Passed || len(result.Steps) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	changelog := result.Steps[0]
+	if !changelog.Passed || changelog.Completion != "Added 1.2.0 to the changelog" {
+		t.Errorf("unexpected changelog step: %+v", changelog)
+	}
+	if len(changelog.ToolCalls) != 1 || changelog.ToolCalls[0] != "task_completion" || len(changelog.Errors) == 0 {
+		t.Errorf("expected probe to be unavailable in the changelog step, got calls %v, errors %v", changelog.ToolCalls, changelog.Errors)
+	}
+	tag := result.Steps[1]
+	if tag.Passed || len(tag.Checks) != 1 || tag.Checks[0].Criterion != `command "test -f 1.2.0.tag"` {
+		t.Errorf("unexpected tag step: %+v", tag)
+	}
+
+	if len(approved) != 2 || !strings.Contains(approved[1], "Changelog: Added 1.2.0 to the changelog") {
+		t.Errorf("expected each step to be approved with its rendered prompt, got %q", approved)
+	}
+	if last := provider.prompts[len(provider.prompts)-1]; !strings.Contains(last, "Tag the release") {
+		t.Errorf("expected the tag step's prompt to be sent, got %q", last)
+	}
+}
+
+func TestRunner_Declined(t *testing.T) {
+	w := &Workflow{Name: "w", Steps: []Step{{Name: "only", Prompt: "x"}}}
+	provider := eval.NewScriptedProvider(completion("done"))
+
+	runner := NewRunner(t.TempDir(), WithStepApprover(func(Step) bool { return false }))
+	result, err := runner.Run(context.Background(), agent.NewDefaultAgent(provider), w, nil)
+	if !errors.Is(err, ErrStepDeclined) {
+		t.Errorf("Run() error = %v, want ErrStepDeclined", err)
+	}
+	if len(result.Steps) != 0 || provider.Calls() != 0 {
+		t.Error("expected a declined step not to run")
+	}
+}
+
+func TestFindAndListAgents(t *testing.T) {
+	project, user := t.TempDir(), t.TempDir()
+	writeFile(t, project, "reviewer.yaml", "description: strict\ninstructions: Block on any missing test.")
+	writeFile(t, user, "reviewer.yml", "instructions: Be lenient.")
+	writeFile(t, user, "security.yaml", "tools: [read_file]\ninstructions: Look for injection.")
+
+	def, err := FindAgent("reviewer", project, user)
+	if err != nil || def.Description != "strict" || def.Path == "" {
+		t.Errorf("FindAgent() = %+v, %v; want the project's definition", def, err)
+	}
+	def, err = FindAgent("architect", project, user)
+	if err != nil || def.Path != "" || !strings.Contains(def.Instructions, "Don't edit files") {
+		t.Errorf("FindAgent() = %+v, %v; want the built-in architect", def, err)
+	}
+	if _, err := FindAgent("missing", project); err == nil {
+		t.Error("expected a missing agent to fail")
+	}
+	if _, err := FindAgent("../security", project); err == nil {
+		t.Error("expected a name with a path to fail")
+	}
+
+	writeFile(t, user, "empty.yaml", "description: no instructions")
+	if _, err := FindAgent("empty", user); err == nil || !strings.Contains(err.Error(), "instructions are required") {
+		t.Errorf("expected a definition without instructions to fail, got %v", err)
+	}
+	if err := os.Remove(filepath.Join(user, "empty.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	defs, err := ListAgents(project, user)
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	var names []string
+	for _, def := range defs {
+		names = append(names, def.Name)
+	}
+	if strings.Join(names, ",") != "architect,implementer,reviewer,security" || defs[2].Description != "strict" {
+		t.Errorf("unexpected agents: %v", names)
+	}
+}
+
+func TestPipelineIsValid(t *testing.T) {
+	w := Pipeline()
+	if err := w.validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range w.Steps {
+		if _, err := FindAgent(step.Agent); err != nil {
+			t.Errorf("step %q: %v", step.Name, err)
+		}
+	}
+	if _, err := w.Bind(nil); err == nil {
+		t.Error("expected the pipeline to require a task")
+	}
+}
+
+// initRepo makes dir a git repository with one commit
+func initRepo(t *testing.T, dir string) {
+	t.Helper()
+	writeFile(t, dir, "main.go", "package main\n")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestRunner_AgentsHandOffArtifacts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	initRepo(t, dir)
+	agentDir := t.TempDir()
+	writeFile(t, agentDir, "reviewer.yaml", "instructions: Only check the docs.")
+
+	provider := &recordingProvider{ScriptedProvider: eval.NewScriptedProvider(
+		completion("Add a greeting to main.go"),
+		completion("Added the greeting"),
+		completion("approve"),
+	)}
+	ag := agent.NewDefaultAgent(provider)
+
+	var approved []Step
+	runner := NewRunner(dir, WithAgentDirs(agentDir), WithStepApprover(func(step Step) bool {
+		approved = append(approved, step)
+		if step.Name == "implement" {
+			// Stands in for the implementer's edit
+			writeFile(t, dir, "main.go", "package main\n\n// Hello greets\nfunc Hello() {}\n")
+		}
+		return true
+	}))
+	result, err := runner.Run(context.Background(), ag, Pipeline(), map[string]string{"task": "add a greeting"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Passed || len(result.Steps) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if !strings.Contains(approved[1].Prompt, "<plan>\nAdd a greeting to main.go\n</plan>") {
+		t.Errorf("expected the implementer to be given the plan, got %q", approved[1].Prompt)
+	}
+	review := approved[2].Prompt
+	if !strings.Contains(review, "+// Hello greets") || !strings.Contains(review, "Added the greeting") {
+		t.Errorf("expected the reviewer to be given the diff and the implementation summary, got %q", review)
+	}
+	if result.Steps[0].Diff != "" || !strings.Contains(result.Steps[1].Diff, "+func Hello() {}") || result.Steps[1].Agent != "implementer" {
+		t.Errorf("expected each step's own changes to be recorded, got %+v", result.Steps)
+	}
+
+	if len(provider.systems) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(provider.systems))
+	}
+	if !strings.Contains(provider.systems[0], `<agent_role name="architect">`) || strings.Contains(provider.systems[0], "implementer") {
+		t.Error("expected the plan step to run as the architect")
+	}
+	if !strings.Contains(provider.systems[2], "Only check the docs.") {
+		t.Error("expected the reviewer's definition file to replace the built-in role")
+	}
+	if strings.Contains(ag.SystemPrompt(), "<agent_role") {
+		t.Error("expected the role to be cleared after the run")
+	}
+}
+
+func TestRunner_UnknownAgent(t *testing.T) {
+	w := &Workflow{Name: "w", Steps: []Step{{Name: "only", Agent: "missing", Prompt: "x"}}}
+	provider := eval.NewScriptedProvider(completion("done"))
+
+	_, err := NewRunner(t.TempDir()).Run(context.Background(), agent.NewDefaultAgent(provider), w, nil)
+	if err == nil || !strings.Contains(err.Error(), `agent "missing" not found`) {
+		t.Errorf("Run() error = %v, want the unknown agent reported", err)
+	}
+	if provider.Calls() != 0 {
+		t.Error("expected no step to run")
+	}
+}
@@ -0,0 +1,108 @@
+package workspacetest
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// AssertFile fails the test unless the file's content is want
+func (w *Workspace) AssertFile(path, want string) {
+	w.t.Helper()
+	data, err := os.ReadFile(w.Path(path))
+	if err != nil {
+		w.t.Errorf("%s: %v", path, err)
+		return
+	}
+	if string(data) != want {
+		w.t.Errorf("%s content mismatch\ngot:\n%s\nwant:\n%s", path, data, want)
+	}
+}
+
+// AssertContains fails the test unless the file contains substr
+func (w *Workspace) AssertContains(path, substr string) {
+	w.t.Helper()
+	data, err := os.ReadFile(w.Path(path))
+	if err != nil {
+		w.t.Errorf("%s: %v", path, err)
+		return
+	}
+	if !strings.Contains(string(data), substr) {
+		w.t.Errorf("%s does not contain %q, got:\n%s", path, substr, data)
+	}
+}
+
+// AssertNotContains fails the test if the file is missing or contains substr
+func (w *Workspace) AssertNotContains(path, substr string) {
+	w.t.Helper()
+	data, err := os.ReadFile(w.Path(path))
+	if err != nil {
+		w.t.Errorf("%s: %v", path, err)
+		return
+	}
+	if strings.Contains(string(data), substr) {
+		w.t.Errorf("%s contains %q, got:\n%s", path, substr, data)
+	}
+}
+
+// AssertExists fails the test unless path exists
+func (w *Workspace) AssertExists(path string) {
+	w.t.Helper()
+	if _, err := os.Stat(w.Path(path)); err != nil {
+		w.t.Errorf("expected %s to exist: %v", path, err)
+	}
+}
+
+// AssertMissing fails the test if path exists
+func (w *Workspace) AssertMissing(path string) {
+	w.t.Helper()
+	if _, err := os.Stat(w.Path(path)); err == nil {
+		w.t.Errorf("expected %s not to exist", path)
+	}
+}
+
+// AssertMode fails the test unless the file's permissions are mode
+func (w *Workspace) AssertMode(path string, mode os.FileMode) {
+	w.t.Helper()
+	info, err := os.Stat(w.Path(path))
+	if err != nil {
+		w.t.Errorf("%s: %v", path, err)
+		return
+	}
+	if info.Mode().Perm() != mode {
+		w.t.Errorf("%s mode = %v, want %v", path, info.Mode().Perm(), mode)
+	}
+}
+
+// AssertTree fails the test unless the workspace holds exactly the files in
+// want, outside .git. Directory entries in want are ignored.
+func (w *Workspace) AssertTree(want Tree) {
+	w.t.Helper()
+	got := w.Files()
+
+	var paths []string
+	for path := range want {
+		if !strings.HasSuffix(path, "/") {
+			paths = append(paths, path)
+		}
+	}
+	for path := range got {
+		if _, ok := want[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		wantContent, wanted := want[path]
+		gotContent, exists := got[path]
+		switch {
+		case !exists:
+			w.t.Errorf("missing file %s", path)
+		case !wanted:
+			w.t.Errorf("unexpected file %s", path)
+		case gotContent != wantContent:
+			w.t.Errorf("%s content mismatch\ngot:\n%s\nwant:\n%s", path, gotContent, wantContent)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package workspacetest
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// requireGit skips the test if git is not installed
+func requireGit(t testing.TB) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+}
+
+// git runs git in the workspace and returns its untrimmed output
+func (w *Workspace) git(args ...string) string {
+	w.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = w.Dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		w.t.Fatalf("workspacetest: git %s failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+// Git runs git in the workspace and returns its trimmed output
+func (w *Workspace) Git(args ...string) string {
+	w.t.Helper()
+	return strings.TrimSpace(w.git(args...))
+}
+
+// Commit stages every change and commits it with message
+func (w *Workspace) Commit(message string) {
+	w.t.Helper()
+	w.Git("add", "-A")
+	w.Git("commit", "-q", "--allow-empty", "-m", message)
+}
+
+// Status returns the porcelain status code of each changed path, such as
+// " M" for a modified file or "??" for an untracked one
+func (w *Workspace) Status() map[string]string {
+	w.t.Helper()
+	status := make(map[string]string)
+	for _, line := range strings.Split(w.git("status", "--porcelain", "--untracked-files=all"), "\n") {
+		if len(line) > 3 {
+			status[line[3:]] = line[:2]
+		}
+	}
+	return status
+}
+
+// AssertCommits fails the test unless the branch's commit subjects are
+// subjects, newest first
+func (w *Workspace) AssertCommits(subjects ...string) {
+	w.t.Helper()
+	got := []string{}
+	if w.hasCommits() {
+		got = strings.Split(w.Git("log", "--format=%s"), "\n")
+	}
+	if subjects == nil {
+		subjects = []string{}
+	}
+	if !reflect.DeepEqual(got, subjects) {
+		w.t.Errorf("commits = %q, want %q", got, subjects)
+	}
+}
+
+// hasCommits reports whether the current branch has any commits
+func (w *Workspace) hasCommits() bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD")
+	cmd.Dir = w.Dir
+	return cmd.Run() == nil
+}
+
+// AssertClean fails the test if the working tree has uncommitted changes
+func (w *Workspace) AssertClean() {
+	w.t.Helper()
+	if status := w.Status(); len(status) > 0 {
+		w.t.Errorf("expected a clean working tree, got changes: %v", status)
+	}
+}
@@ -0,0 +1,244 @@
+// Package workspacetest builds temporary workspaces for tests from
+// declarative file trees, optionally as git repositories, and checks the
+// files and history they end up with.
+//
+//	ws := workspacetest.New(t, workspacetest.Tree{
+//		"go.mod":      "module example.com/app\n",
+//		"main.go":     "package main\n",
+//		"testdata/":   "", // Empty directory
+//	}, workspacetest.WithCommit("initial"))
+//
+//	tool := coding.NewWriteFileTool(ws.Guard())
+//	...
+//	ws.AssertFile("main.go", "package main\n\nfunc main() {}\n")
+//	ws.AssertCommits("initial")
+//
+// Paths are slash-separated and relative to the workspace root. Every
+// helper fails the test on error, so tests don't check errors themselves.
+package workspacetest
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// The identity commits are made with in git workspaces
+const (
+	AuthorName  = "Test"
+	AuthorEmail = "test@example.com"
+)
+
+// Tree maps workspace paths to file contents. A path ending in "/" is an
+// empty directory.
+type Tree map[string]string
+
+// Workspace is a temporary directory populated from a Tree. It is removed
+// when the test ends.
+type Workspace struct {
+	Dir string
+	t   testing.TB
+}
+
+// options configure New
+type options struct {
+	git     bool
+	branch  string
+	commit  string
+	modes   map[string]os.FileMode
+	changes Tree
+	removed []string
+}
+
+// Option configures a workspace created by New
+type Option func(*options)
+
+// WithGit makes the workspace a git repository, with AuthorName and
+// AuthorEmail as its identity. The tree is left untracked. Tests that need
+// git are skipped where it is not installed.
+func WithGit() Option {
+	return func(o *options) {
+		o.git = true
+	}
+}
+
+// WithCommit makes the workspace a git repository and commits the tree
+// with message
+func WithCommit(message string) Option {
+	return func(o *options) {
+		o.git = true
+		o.commit = message
+	}
+}
+
+// WithBranch sets the name of the initial branch (default main)
+func WithBranch(name string) Option {
+	return func(o *options) {
+		o.git = true
+		o.branch = name
+	}
+}
+
+// WithMode sets a file's permissions (default 0644)
+func WithMode(path string, mode os.FileMode) Option {
+	return func(o *options) {
+		o.modes[path] = mode
+	}
+}
+
+// WithChanges writes changes after the tree is committed, leaving them as
+// uncommitted changes in the working tree
+func WithChanges(changes Tree) Option {
+	return func(o *options) {
+		o.changes = changes
+	}
+}
+
+// WithRemoved deletes paths after the tree is committed, leaving them as
+// uncommitted deletions
+func WithRemoved(paths ...string) Option {
+	return func(o *options) {
+		o.removed = append(o.removed, paths...)
+	}
+}
+
+// New creates a workspace holding tree
+func New(t testing.TB, tree Tree, opts ...Option) *Workspace {
+	t.Helper()
+	o := &options{branch: "main", modes: make(map[string]os.FileMode)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Resolve symlinks (macOS /var → /private/var) so paths compare equal
+	// to the ones tools report
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("workspacetest: %v", err)
+	}
+	w := &Workspace{Dir: dir, t: t}
+
+	if o.git {
+		requireGit(t)
+		w.Git("init", "-q")
+		w.Git("symbolic-ref", "HEAD", "refs/heads/"+o.branch)
+		w.Git("config", "user.name", AuthorName)
+		w.Git("config", "user.email", AuthorEmail)
+		w.Git("config", "commit.gpgsign", "false")
+	}
+
+	w.Write(tree)
+	for path, mode := range o.modes {
+		if err := os.Chmod(w.Path(path), mode); err != nil {
+			t.Fatalf("workspacetest: %v", err)
+		}
+	}
+
+	if o.commit != "" {
+		w.Commit(o.commit)
+	}
+	w.Write(o.changes)
+	for _, path := range o.removed {
+		w.Remove(path)
+	}
+	return w
+}
+
+// Path returns the absolute path of a workspace path
+func (w *Workspace) Path(path string) string {
+	return filepath.Join(w.Dir, filepath.FromSlash(path))
+}
+
+// Guard returns a workspace guard for the workspace, as the coding tools use
+func (w *Workspace) Guard() *workspace.Guard {
+	w.t.Helper()
+	guard, err := workspace.NewGuard(w.Dir)
+	if err != nil {
+		w.t.Fatalf("workspacetest: failed to create guard: %v", err)
+	}
+	return guard
+}
+
+// Write creates or overwrites the files in tree
+func (w *Workspace) Write(tree Tree) {
+	w.t.Helper()
+	paths := make([]string, 0, len(tree))
+	for path := range tree {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/") {
+			if err := os.MkdirAll(w.Path(path), 0o755); err != nil {
+				w.t.Fatalf("workspacetest: %v", err)
+			}
+			continue
+		}
+		w.WriteFile(path, tree[path])
+	}
+}
+
+// WriteFile creates or overwrites a file, creating its parent directories
+func (w *Workspace) WriteFile(path, content string) {
+	w.t.Helper()
+	full := w.Path(path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		w.t.Fatalf("workspacetest: %v", err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		w.t.Fatalf("workspacetest: %v", err)
+	}
+}
+
+// Remove deletes a file or directory
+func (w *Workspace) Remove(path string) {
+	w.t.Helper()
+	if err := os.RemoveAll(w.Path(path)); err != nil {
+		w.t.Fatalf("workspacetest: %v", err)
+	}
+}
+
+// Read returns a file's content
+func (w *Workspace) Read(path string) string {
+	w.t.Helper()
+	data, err := os.ReadFile(w.Path(path))
+	if err != nil {
+		w.t.Fatalf("workspacetest: %v", err)
+	}
+	return string(data)
+}
+
+// Files returns every file in the workspace outside .git
+func (w *Workspace) Files() Tree {
+	w.t.Helper()
+	files := make(Tree)
+	err := filepath.WalkDir(w.Dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(w.Dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		w.t.Fatalf("workspacetest: %v", err)
+	}
+	return files
+}
@@ -0,0 +1,122 @@
+package workspacetest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// recorder captures assertion failures instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// failures runs assert against ws with a recorder and returns its failures
+func failures(t *testing.T, ws *Workspace, assert func(*Workspace)) []string {
+	rec := &recorder{TB: t}
+	assert(&Workspace{Dir: ws.Dir, t: rec})
+	return rec.errors
+}
+
+func TestNew(t *testing.T) {
+	ws := New(t, Tree{
+		"main.go":         "package main\n",
+		"pkg/util/a.go":   "package util\n",
+		"testdata/":       "",
+		"scripts/run.sh":  "#!/bin/sh\n",
+		"docs/README.md":  "# Docs\n",
+		"docs/guide/x.md": "x\n",
+	}, WithMode("scripts/run.sh", 0o755))
+
+	ws.AssertFile("pkg/util/a.go", "package util\n")
+	ws.AssertMode("scripts/run.sh", 0o755)
+	ws.AssertExists("testdata")
+	ws.AssertMissing(".git")
+	if info, err := os.Stat(ws.Path("testdata")); err != nil || !info.IsDir() {
+		t.Errorf("expected testdata/ to be a directory: %v", err)
+	}
+
+	ws.WriteFile("main.go", "package main\n\nfunc main() {}\n")
+	ws.Remove("docs")
+	ws.AssertTree(Tree{
+		"main.go":        "package main\n\nfunc main() {}\n",
+		"pkg/util/a.go":  "package util\n",
+		"scripts/run.sh": "#!/bin/sh\n",
+		"testdata/":      "",
+	})
+}
+
+func TestAssertions_Fail(t *testing.T) {
+	ws := New(t, Tree{"a.txt": "alpha\n", "b.txt": "beta\n"})
+
+	tests := []struct {
+		name   string
+		assert func(*Workspace)
+		want   string
+	}{
+		{"content", func(w *Workspace) { w.AssertFile("a.txt", "gamma\n") }, "a.txt content mismatch"},
+		{"contains", func(w *Workspace) { w.AssertContains("a.txt", "beta") }, `a.txt does not contain "beta"`},
+		{"not contains", func(w *Workspace) { w.AssertNotContains("b.txt", "beta") }, `b.txt contains "beta"`},
+		{"exists", func(w *Workspace) { w.AssertExists("c.txt") }, "expected c.txt to exist"},
+		{"missing", func(w *Workspace) { w.AssertMissing("a.txt") }, "expected a.txt not to exist"},
+		{"tree extra", func(w *Workspace) { w.AssertTree(Tree{"a.txt": "alpha\n"}) }, "unexpected file b.txt"},
+		{"tree missing", func(w *Workspace) {
+			w.AssertTree(Tree{"a.txt": "alpha\n", "b.txt": "beta\n", "c.txt": ""})
+		}, "missing file c.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := failures(t, ws, tt.assert)
+			if len(errors) != 1 || !strings.Contains(errors[0], tt.want) {
+				t.Errorf("failures = %q, want one containing %q", errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Git(t *testing.T) {
+	ws := New(t, Tree{
+		"main.go":   "package main\n",
+		"remove.go": "package main\n",
+	}, WithCommit("initial"), WithBranch("develop"),
+		WithChanges(Tree{"main.go": "package app\n", "new.go": "package app\n"}),
+		WithRemoved("remove.go"))
+
+	if branch := ws.Git("branch", "--show-current"); branch != "develop" {
+		t.Errorf("branch = %q, want develop", branch)
+	}
+	if author := ws.Git("log", "-1", "--format=%an <%ae>"); author != AuthorName+" <"+AuthorEmail+">" {
+		t.Errorf("author = %q", author)
+	}
+	ws.AssertCommits("initial")
+
+	status := ws.Status()
+	want := map[string]string{"main.go": " M", "new.go": "??", "remove.go": " D"}
+	if fmt.Sprint(status) != fmt.Sprint(want) {
+		t.Errorf("Status() = %v, want %v", status, want)
+	}
+	if errors := failures(t, ws, (*Workspace).AssertClean); len(errors) != 1 {
+		t.Errorf("expected AssertClean to fail on a dirty tree, got %q", errors)
+	}
+
+	ws.Commit("update")
+	ws.AssertClean()
+	ws.AssertCommits("update", "initial")
+	if errors := failures(t, ws, func(w *Workspace) { w.AssertCommits("initial") }); len(errors) != 1 {
+		t.Errorf("expected AssertCommits to fail, got %q", errors)
+	}
+}
+
+func TestNew_GitWithoutCommits(t *testing.T) {
+	ws := New(t, Tree{"a.txt": "a\n"}, WithGit())
+	ws.AssertCommits()
+	if status := ws.Status(); status["a.txt"] != "??" {
+		t.Errorf("expected the tree to be untracked, got %v", status)
+	}
+}